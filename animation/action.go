@@ -0,0 +1,360 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// LoopMode is the behavior of an action at the end of its clip
+type LoopMode int
+
+// Loop modes of the actions
+const (
+	LoopOnce     LoopMode = iota // plays the clip once and stops at its end
+	LoopRepeat                   // plays the clip again from its start
+	LoopPingPong                 // plays the clip backwards and forwards alternately
+)
+
+// BlendMode is the way the values of an action are combined with the
+// values of the other actions of the mixer
+type BlendMode int
+
+// Blend modes of the actions
+const (
+	BlendNormal   BlendMode = iota // values blended by weight with the other normal actions
+	BlendAdditive                  // differences to the first keyframes added to the blended values
+)
+
+// Action plays a clip in a mixer with its own time, speed, loop mode and weight.
+// Actions are created by the Action and Play methods of the mixer.
+type Action struct {
+	mixer      *Mixer            // mixer of this action
+	clip       *Clip             // clip played
+	bindings   []*binding        // bound properties of the tracks of the clip
+	time       float32           // time played, which is twice the duration for ping pong loops
+	speed      float32           // speed of the time, negative to play backwards
+	weight     float32           // weight of the values of the clip in the blend
+	loop       LoopMode          // loop mode
+	blend      BlendMode         // blend mode
+	fade       float32           // factor of the weight changed by the fades
+	fadeRate   float32           // change of the fade factor per second
+	fadeStop   bool              // action stops when faded out
+	playing    bool              // action is playing
+	paused     bool              // playing action is paused
+	ending     bool              // action finished in the current update and applies its last values
+	onFinished func(*Action)     // called when a LoopOnce action reaches the end of its clip
+	value      []float32         // value of the track being evaluated
+	ref        []float32         // value of the first keyframe of the track being evaluated
+	moved      bool              // root motion computed in the last update
+	motionPos  math32.Vector3    // root motion translation of the last update
+	motionRot  math32.Quaternion // root motion rotation of the last update
+}
+
+// Clip returns the clip played by this action
+func (a *Action) Clip() *Clip {
+
+	return a.clip
+}
+
+// Mixer returns the mixer of this action
+func (a *Action) Mixer() *Mixer {
+
+	return a.mixer
+}
+
+// Play starts playing this action from its current time, resuming it if
+// paused, and returns a pointer to it. A LoopOnce action which reached
+// the end of its clip starts again from its start.
+func (a *Action) Play() *Action {
+
+	if !a.playing && a.loop == LoopOnce {
+		if a.speed >= 0 && a.time >= a.clip.duration {
+			a.time = 0
+		} else if a.speed < 0 && a.time <= 0 {
+			a.time = a.clip.duration
+		}
+	}
+	a.playing = true
+	a.paused = false
+	return a
+}
+
+// Stop stops this action and sets its time to the start of its clip.
+// The animated nodes keep their last values. Fades in progress are cancelled.
+func (a *Action) Stop() {
+
+	a.playing = false
+	a.paused = false
+	a.time = 0
+	a.fade = 1
+	a.fadeRate = 0
+	a.fadeStop = false
+}
+
+// SetPaused sets if this action is paused. A paused action keeps
+// applying the values of its current time.
+func (a *Action) SetPaused(state bool) {
+
+	a.paused = state
+}
+
+// Paused returns if this action is paused
+func (a *Action) Paused() bool {
+
+	return a.paused
+}
+
+// Playing returns if this action is playing, even if paused
+func (a *Action) Playing() bool {
+
+	return a.playing
+}
+
+// SetSpeed sets the speed of this action, which multiplies the time elapsed,
+// as 2 to play twice as fast or -1 to play backwards. The default is 1.
+func (a *Action) SetSpeed(speed float32) *Action {
+
+	a.speed = speed
+	return a
+}
+
+// Speed returns the speed of this action
+func (a *Action) Speed() float32 {
+
+	return a.speed
+}
+
+// SetLoop sets the loop mode of this action. The default is LoopRepeat.
+func (a *Action) SetLoop(mode LoopMode) *Action {
+
+	a.loop = mode
+	return a
+}
+
+// Loop returns the loop mode of this action
+func (a *Action) Loop() LoopMode {
+
+	return a.loop
+}
+
+// SetWeight sets the weight of the values of this action in the blend
+// of the actions of the mixer, from 0 to 1. The default is 1.
+func (a *Action) SetWeight(weight float32) *Action {
+
+	a.weight = weight
+	return a
+}
+
+// Weight returns the weight of this action
+func (a *Action) Weight() float32 {
+
+	return a.weight
+}
+
+// EffectiveWeight returns the weight of this action multiplied by
+// the factor of its fade in progress
+func (a *Action) EffectiveWeight() float32 {
+
+	return a.weight * a.fade
+}
+
+// SetBlendMode sets the blend mode of this action. The default is BlendNormal.
+// The values of BlendAdditive actions are not blended with the values of the
+// other actions but their differences to the first keyframes of their tracks,
+// multiplied by their weights, are added to the blended values, as to lean
+// or aim a character playing a locomotion clip. Rotation differences are
+// composed and the scale differences are multiplied.
+func (a *Action) SetBlendMode(mode BlendMode) *Action {
+
+	a.blend = mode
+	return a
+}
+
+// BlendMode returns the blend mode of this action
+func (a *Action) BlendMode() BlendMode {
+
+	return a.blend
+}
+
+// FadeIn plays this action with its weight increasing from 0 to its
+// full weight in the specified time in seconds
+func (a *Action) FadeIn(duration float32) *Action {
+
+	a.Play()
+	a.fade = 0
+	a.fadeStop = false
+	a.setFadeRate(1, duration)
+	return a
+}
+
+// FadeOut decreases the weight of this action to 0 in the specified
+// time in seconds, from its current fade factor, and then stops it
+func (a *Action) FadeOut(duration float32) *Action {
+
+	a.fadeStop = true
+	a.setFadeRate(-1, duration)
+	return a
+}
+
+// CrossFadeTo fades out this action and fades in the specified action,
+// from the start of its clip, in the specified time in seconds.
+// Returns the specified action.
+func (a *Action) CrossFadeTo(other *Action, duration float32) *Action {
+
+	if other == a {
+		return a
+	}
+	other.Stop()
+	other.FadeIn(duration)
+	a.FadeOut(duration)
+	return other
+}
+
+// Fading returns if a fade of this action is in progress
+func (a *Action) Fading() bool {
+
+	return a.fadeRate != 0
+}
+
+// setFadeRate sets the change of the fade factor per second for a
+// fade in the specified direction with the specified duration
+func (a *Action) setFadeRate(dir, duration float32) {
+
+	if duration <= 0 {
+		a.fade = math32.Clamp(dir, 0, 1)
+		a.fadeRate = 0
+		a.endFade()
+		return
+	}
+	a.fadeRate = dir / duration
+}
+
+// updateFade advances the fade in progress by the specified seconds
+func (a *Action) updateFade(delta float32) {
+
+	if a.fadeRate == 0 {
+		return
+	}
+	a.fade += a.fadeRate * delta
+	if a.fade > 0 && a.fade < 1 {
+		return
+	}
+	a.fade = math32.Clamp(a.fade, 0, 1)
+	a.fadeRate = 0
+	a.endFade()
+}
+
+// endFade stops this action if it faded out to be stopped
+func (a *Action) endFade() {
+
+	if a.fade == 0 && a.fadeStop {
+		a.Stop()
+	}
+}
+
+// SetTime sets the time of this action in seconds from the start of its clip
+func (a *Action) SetTime(time float32) *Action {
+
+	a.time = time
+	return a
+}
+
+// Time returns the time of this action in seconds from the start of its clip
+func (a *Action) Time() float32 {
+
+	d := a.clip.duration
+	if a.loop == LoopPingPong && a.time > d {
+		return 2*d - a.time
+	}
+	return a.time
+}
+
+// SetOnFinished sets the function called when this action, in the
+// LoopOnce mode, reaches the end of its clip and stops
+func (a *Action) SetOnFinished(cb func(*Action)) {
+
+	a.onFinished = cb
+}
+
+// advance advances the time of this action by the specified seconds
+func (a *Action) advance(delta float32) {
+
+	d := a.clip.duration
+	from := a.time
+	a.time += delta * a.speed
+	if a.mixer.root.enabled {
+		a.computeRootMotion(from, a.time)
+	}
+	switch a.loop {
+	case LoopOnce:
+		if a.time >= 0 && a.time <= d {
+			return
+		}
+		a.time = math32.Clamp(a.time, 0, d)
+		a.playing = false
+		a.ending = true
+		if a.onFinished != nil {
+			a.onFinished(a)
+		}
+	case LoopRepeat:
+		a.time = wrap(a.time, d)
+	case LoopPingPong:
+		a.time = wrap(a.time, 2*d)
+	}
+}
+
+// accumulate adds the values of the tracks of this action at its
+// current time to the blended values of their bound properties
+func (a *Action) accumulate() {
+
+	if len(a.bindings) != len(a.clip.tracks) {
+		a.bindings = make([]*binding, len(a.clip.tracks))
+	}
+	time := a.Time()
+	weight := a.EffectiveWeight()
+	for i, t := range a.clip.tracks {
+		b := a.bindings[i]
+		if b == nil {
+			b = a.mixer.bind(t)
+			a.bindings[i] = b
+		}
+		if b.inode == nil || len(t.times) == 0 {
+			continue
+		}
+		if cap(a.value) < t.size {
+			a.value = make([]float32, t.size)
+		}
+		v := a.value[:t.size]
+		t.Evaluate(time, v)
+		if a.blend == BlendNormal && a.isRoot(t) {
+			stripRoot(t, v)
+		}
+		if a.blend == BlendAdditive {
+			if cap(a.ref) < t.size {
+				a.ref = make([]float32, t.size)
+			}
+			ref := a.ref[:t.size]
+			t.Evaluate(t.times[0], ref)
+			b.addDelta(v, ref, weight)
+			continue
+		}
+		b.add(v, weight)
+	}
+}
+
+// wrap returns the specified time wrapped in the interval from 0 to the
+// specified period
+func wrap(time, period float32) float32 {
+
+	if period <= 0 {
+		return 0
+	}
+	time = math32.Mod(time, period)
+	if time < 0 {
+		time += period
+	}
+	return time
+}
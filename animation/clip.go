@@ -0,0 +1,66 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+// Clip is a named set of tracks which animate the nodes of a hierarchy
+// together, as the walk cycle of a character
+type Clip struct {
+	name     string   // name of the clip
+	tracks   []*Track // tracks of the clip
+	duration float32  // duration in seconds
+}
+
+// NewClip creates and returns a pointer to a new clip with the specified
+// name and tracks, whose duration is the duration of its longest track
+func NewClip(name string, tracks ...*Track) *Clip {
+
+	c := &Clip{name: name}
+	for _, t := range tracks {
+		c.AddTrack(t)
+	}
+	return c
+}
+
+// Name returns the name of this clip
+func (c *Clip) Name() string {
+
+	return c.name
+}
+
+// SetName sets the name of this clip
+func (c *Clip) SetName(name string) {
+
+	c.name = name
+}
+
+// AddTrack adds the specified track to this clip, extending
+// the duration of the clip to the duration of the track
+func (c *Clip) AddTrack(t *Track) *Clip {
+
+	c.tracks = append(c.tracks, t)
+	if d := t.Duration(); d > c.duration {
+		c.duration = d
+	}
+	return c
+}
+
+// Tracks returns the tracks of this clip
+func (c *Clip) Tracks() []*Track {
+
+	return c.tracks
+}
+
+// SetDuration sets the duration of this clip in seconds, which is the
+// period of its loops. The default is the duration of its longest track.
+func (c *Clip) SetDuration(duration float32) {
+
+	c.duration = duration
+}
+
+// Duration returns the duration of this clip in seconds
+func (c *Clip) Duration() float32 {
+
+	return c.duration
+}
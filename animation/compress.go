@@ -0,0 +1,242 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Quantization of the rotations: each quaternion is stored as its three smallest
+// components, in the range of plus or minus 1/sqrt(2), with 15 bits each, and
+// the index of its largest component in the high bits of the first two.
+const (
+	quantMax   = 0x7FFF
+	quantRange = 0.70710678 // largest value of the three smallest components
+)
+
+// Compress reduces the memory used by the tracks of this clip, as the clips
+// imported with a keyframe for each frame, and returns the number of keyframes
+// removed. The keyframes which can be interpolated from the remaining keyframes
+// with an error up to the specified tolerance, or angle tolerance in radians
+// for the rotations, are removed and the rotations are quantized if requested.
+// Loaders should call it for the clips they create when requested.
+func (c *Clip) Compress(tolerance, angleTolerance float32, quantize bool) int {
+
+	removed := 0
+	for _, t := range c.tracks {
+		if t.prop == Rotation {
+			removed += t.Reduce(angleTolerance)
+			if quantize {
+				t.Quantize()
+			}
+		} else {
+			removed += t.Reduce(tolerance)
+		}
+	}
+	return removed
+}
+
+// ByteSize returns the number of bytes used by the keyframes of the tracks of this clip
+func (c *Clip) ByteSize() int {
+
+	size := 0
+	for _, t := range c.tracks {
+		size += t.ByteSize()
+	}
+	return size
+}
+
+// ByteSize returns the number of bytes used by the keyframes of this track
+func (t *Track) ByteSize() int {
+
+	return 4*len(t.times) + 4*len(t.values) + 2*len(t.quant)
+}
+
+// Reduce removes the keyframes of this track which can be interpolated from the
+// remaining keyframes with an error up to the specified tolerance, which is the
+// angle in radians for the rotations and the distance for the other values, and
+// returns the number of keyframes removed. A track whose values are all equal
+// within the tolerance keeps only its first keyframe. The tracks with the Cubic
+// interpolation are not reduced. A quantized track is quantized again.
+func (t *Track) Reduce(tolerance float32) int {
+
+	count := len(t.times)
+	if count < 2 || t.interp == Cubic {
+		return 0
+	}
+	quantized := t.quant != nil
+	values := t.Values()
+	size := t.size
+	key := func(i int) []float32 { return values[i*size : (i+1)*size] }
+
+	// Constant track
+	constant := true
+	for i := 1; i < count && constant; i++ {
+		constant = t.distance(key(0), key(i)) <= tolerance
+	}
+	keep := make([]int, 0, count)
+	keep = append(keep, 0)
+	if !constant {
+		buf := make([]float32, size)
+		for i := 1; i < count-1; i++ {
+			if !t.interpolates(values, keep[len(keep)-1], i+1, tolerance, buf) {
+				keep = append(keep, i)
+			}
+		}
+		keep = append(keep, count-1)
+	}
+	if len(keep) == count {
+		return 0
+	}
+
+	times := make([]float32, 0, len(keep))
+	reduced := make([]float32, 0, len(keep)*size)
+	for _, i := range keep {
+		times = append(times, t.times[i])
+		reduced = append(reduced, key(i)...)
+	}
+	t.times = times
+	t.values = reduced
+	t.quant = nil
+	if quantized {
+		t.Quantize()
+	}
+	return count - len(keep)
+}
+
+// Quantize stores the rotations of this track, if it is a Rotation track without
+// the Cubic interpolation, with 6 bytes instead of 16 for each keyframe, with
+// an error of less than 0.0002 radians. Returns if the track is quantized.
+func (t *Track) Quantize() bool {
+
+	if t.quant != nil {
+		return true
+	}
+	if t.prop != Rotation || t.interp == Cubic {
+		return false
+	}
+	t.quant = make([]uint16, 3*len(t.times))
+	for i := range t.times {
+		quantize(t.values[i*4:i*4+4], t.quant[i*3:i*3+3])
+	}
+	t.values = nil
+	return true
+}
+
+// Quantized returns if the rotations of this track are quantized
+func (t *Track) Quantized() bool {
+
+	return t.quant != nil
+}
+
+// decode returns the decoded values of this quantized track
+func (t *Track) decode() []float32 {
+
+	values := make([]float32, 4*len(t.times))
+	for i := range t.times {
+		dequantize(t.quant[i*3:i*3+3], values[i*4:i*4+4])
+	}
+	return values
+}
+
+// interpolates returns if the keyframes between the specified first and last
+// keyframes are interpolated from them with an error up to the specified tolerance
+func (t *Track) interpolates(values []float32, first, last int, tolerance float32, buf []float32) bool {
+
+	size := t.size
+	v0 := values[first*size : (first+1)*size]
+	v1 := values[last*size : (last+1)*size]
+	for j := first + 1; j < last; j++ {
+		vj := values[j*size : (j+1)*size]
+		if t.interp == Step {
+			copy(buf, v0)
+		} else {
+			s := (t.times[j] - t.times[first]) / (t.times[last] - t.times[first])
+			if t.prop == Rotation {
+				var qa, qb math32.Quaternion
+				qa.Set(v0[0], v0[1], v0[2], v0[3])
+				qb.Set(v1[0], v1[1], v1[2], v1[3])
+				qa.Slerp(&qb, s)
+				buf[0], buf[1], buf[2], buf[3] = qa.X(), qa.Y(), qa.Z(), qa.W()
+			} else {
+				for i := range buf {
+					buf[i] = v0[i] + (v1[i]-v0[i])*s
+				}
+			}
+		}
+		if t.distance(buf, vj) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// distance returns the angle in radians between the specified rotations
+// of a Rotation track or the distance between the specified values
+func (t *Track) distance(a, b []float32) float32 {
+
+	if t.prop == Rotation {
+		// Angle of the rotation from a to b, more precise than the acos of their dot product
+		var qa, qb math32.Quaternion
+		qa.Set(a[0], a[1], a[2], a[3])
+		qb.Set(b[0], b[1], b[2], b[3])
+		qa.Conjugate().Multiply(&qb)
+		sin := math32.Sqrt(qa.X()*qa.X() + qa.Y()*qa.Y() + qa.Z()*qa.Z())
+		return 2 * math32.Atan2(sin, math32.Abs(qa.W()))
+	}
+	var sum float32
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math32.Sqrt(sum)
+}
+
+// quantize stores the specified quaternion components in the specified 3 words
+func quantize(q []float32, out []uint16) {
+
+	var v [4]float32
+	copy(v[:], q)
+	normalize(v[:])
+	largest := 0
+	for i := 1; i < 4; i++ {
+		if math32.Abs(v[i]) > math32.Abs(v[largest]) {
+			largest = i
+		}
+	}
+	sign := float32(1)
+	if v[largest] < 0 {
+		sign = -1
+	}
+	k := 0
+	for i := 0; i < 4; i++ {
+		if i == largest {
+			continue
+		}
+		f := (math32.Clamp(v[i]*sign/quantRange, -1, 1) + 1) / 2
+		out[k] = uint16(f*quantMax + 0.5)
+		k++
+	}
+	out[0] |= uint16(largest>>1) << 15
+	out[1] |= uint16(largest&1) << 15
+}
+
+// dequantize sets the specified quaternion components from the specified 3 words
+func dequantize(in []uint16, q []float32) {
+
+	largest := int(in[0]>>15)<<1 | int(in[1]>>15)
+	var sum float32
+	k := 0
+	for i := 0; i < 4; i++ {
+		if i == largest {
+			continue
+		}
+		f := float32(in[k]&quantMax) / quantMax
+		q[i] = (f*2 - 1) * quantRange
+		sum += q[i] * q[i]
+		k++
+	}
+	q[largest] = math32.Sqrt(math32.Max(0, 1-sum))
+}
@@ -0,0 +1,26 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package animation implements the keyframe animation of the nodes of a scene.
+//
+// A Track contains the keyframes of a property of a node: its position,
+// rotation, scale or morph target weights. A Clip is a named set of tracks
+// which animate a hierarchy of nodes, as the walk cycle of a character.
+// A Mixer is a component attached to the root node of the hierarchy which
+// plays clips through actions, with their own time, speed, loop mode and
+// weight, and applies the blended result to the nodes for each frame.
+// Actions can be faded in and out, crossfaded and layered additively over
+// the other actions, as an aim offset over a locomotion clip.
+// A StateMachine plays the clips of the states of an animation graph in a
+// mixer and crossfades between them by the conditions of its transitions.
+// An IKChain rotates a chain of bones after the animation so its end
+// reaches a target, as for foot placement. The motion of the root bone of a
+// character can be extracted from its clips to move the node of the mixer
+// and a Retargeter converts the clips of a rig to clips of another rig.
+// The clips with a keyframe for each frame can be compressed, removing the
+// keyframes which are interpolated from the others and quantizing the rotations.
+//
+// A Tweener plays tweens, the transitions of node and material properties
+// to target values with easing curves, in sequences and parallel groups.
+package animation
@@ -0,0 +1,395 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// IKSolver is the algorithm used by an IK chain to reach its target
+type IKSolver int
+
+// Solvers of the IK chains
+const (
+	SolverTwoBone IKSolver = iota // analytic solver of chains with two bones, as legs and arms
+	SolverFABRIK                  // iterative Forward And Backward Reaching solver of chains of any length
+	SolverCCD                     // iterative Cyclic Coordinate Descent solver of chains of any length
+)
+
+// IKChain is a component which rotates a chain of bones so its end bone, the
+// node it is attached to, reaches a target, as for foot placement, a head looking
+// at a point or an arm reaching an object. The bones of the chain are the end
+// bone and the specified number of its ancestors, and only the rotations of the
+// ancestors are changed. An optional pole sets the side the chain bends to, as
+// the direction of a knee or an elbow.
+// When the end bone is a descendant of the node of an animation mixer its chain
+// is solved after the mixer applied its clips for each update.
+type IKChain struct {
+	core.Component                  // Embedded component
+	solver         IKSolver         // solver of the chain
+	length         int              // number of bones rotated by the solver
+	target         core.INode       // target node or nil
+	targetPos      math32.Vector3   // target world position if no target node
+	pole           core.INode       // pole node or nil
+	polePos        math32.Vector3   // pole world position if no pole node
+	hasPole        bool             // chain has a pole
+	iterations     int              // maximum number of iterations of the iterative solvers
+	tolerance      float32          // distance to the target at which the iterative solvers stop
+	weight         float32          // weight of the solved rotations
+	bones          []*core.Node     // bones of the chain from its root to its end
+	pos            []math32.Vector3 // world positions of the bones being solved
+}
+
+// NewIKChain creates and returns a pointer to a new IK chain with the specified
+// solver which rotates the specified number of ancestors of the bone it is
+// attached to. The SolverTwoBone requires a length of 2.
+func NewIKChain(solver IKSolver, length int) *IKChain {
+
+	if length < 1 || (solver == SolverTwoBone && length != 2) {
+		panic("animation.NewIKChain: invalid chain length")
+	}
+	ik := new(IKChain)
+	ik.solver = solver
+	ik.length = length
+	ik.iterations = 10
+	ik.tolerance = 0.001
+	ik.weight = 1
+	return ik
+}
+
+// Solver returns the solver of this chain
+func (ik *IKChain) Solver() IKSolver {
+
+	return ik.solver
+}
+
+// Length returns the number of bones rotated by the solver
+func (ik *IKChain) Length() int {
+
+	return ik.length
+}
+
+// SetTarget sets the node whose world position is the target of this chain
+func (ik *IKChain) SetTarget(target core.INode) {
+
+	ik.target = target
+}
+
+// SetTargetPosition sets the world position of the target of this chain
+// and clears its target node
+func (ik *IKChain) SetTargetPosition(pos *math32.Vector3) {
+
+	ik.target = nil
+	ik.targetPos = *pos
+}
+
+// Target returns the target node of this chain or nil
+func (ik *IKChain) Target() core.INode {
+
+	return ik.target
+}
+
+// SetPole sets the node whose world position is the pole of this chain
+func (ik *IKChain) SetPole(pole core.INode) {
+
+	ik.pole = pole
+	ik.hasPole = pole != nil
+}
+
+// SetPolePosition sets the world position of the pole of this chain
+// and clears its pole node
+func (ik *IKChain) SetPolePosition(pos *math32.Vector3) {
+
+	ik.pole = nil
+	ik.polePos = *pos
+	ik.hasPole = true
+}
+
+// ClearPole removes the pole of this chain
+func (ik *IKChain) ClearPole() {
+
+	ik.pole = nil
+	ik.hasPole = false
+}
+
+// SetIterations sets the maximum number of iterations of the FABRIK
+// and CCD solvers. The default is 10.
+func (ik *IKChain) SetIterations(iterations int) {
+
+	ik.iterations = iterations
+}
+
+// Iterations returns the maximum number of iterations of the iterative solvers
+func (ik *IKChain) Iterations() int {
+
+	return ik.iterations
+}
+
+// SetTolerance sets the distance to the target at which the FABRIK and
+// CCD solvers stop iterating. The default is 0.001.
+func (ik *IKChain) SetTolerance(tolerance float32) {
+
+	ik.tolerance = tolerance
+}
+
+// Tolerance returns the distance to the target at which the iterative solvers stop
+func (ik *IKChain) Tolerance() float32 {
+
+	return ik.tolerance
+}
+
+// SetWeight sets the weight of the solved rotations blended with the
+// rotations of the bones before solving, from 0 to 1. The default is 1.
+func (ik *IKChain) SetWeight(weight float32) {
+
+	ik.weight = weight
+}
+
+// Weight returns the weight of the solved rotations
+func (ik *IKChain) Weight() float32 {
+
+	return ik.weight
+}
+
+// Bones returns the bones of this chain from its root to its end
+// or nil if the end bone doesn't have enough ancestors
+func (ik *IKChain) Bones() []*core.Node {
+
+	n := ik.Node()
+	if n == nil {
+		return nil
+	}
+	if len(ik.bones) == ik.length+1 && ik.bones[ik.length] == n {
+		return ik.bones
+	}
+	bones := make([]*core.Node, ik.length+1)
+	bones[ik.length] = n
+	for i := ik.length - 1; i >= 0; i-- {
+		parent := bones[i+1].Parent()
+		if parent == nil {
+			return nil
+		}
+		bones[i] = parent.GetNode()
+	}
+	ik.bones = bones
+	return bones
+}
+
+// Update satisfies the IComponent interface and solves the chain
+func (ik *IKChain) Update(delta float32) {
+
+	ik.Solve()
+}
+
+// Solve rotates the bones of this chain so its end reaches its target
+func (ik *IKChain) Solve() {
+
+	bones := ik.Bones()
+	if bones == nil || ik.weight <= 0 {
+		return
+	}
+	var target, pole math32.Vector3
+	if ik.target != nil {
+		ik.target.GetNode().WorldPosition(&target)
+	} else {
+		target = ik.targetPos
+	}
+	if ik.pole != nil {
+		ik.pole.GetNode().WorldPosition(&pole)
+	} else {
+		pole = ik.polePos
+	}
+
+	// World positions and lengths of the bones
+	if len(ik.pos) != len(bones) {
+		ik.pos = make([]math32.Vector3, len(bones))
+	}
+	p := ik.pos
+	for i, b := range bones {
+		b.WorldPosition(&p[i])
+	}
+	lengths := make([]float32, ik.length)
+	for i := range lengths {
+		lengths[i] = p[i].DistanceTo(&p[i+1])
+	}
+
+	switch ik.solver {
+	case SolverTwoBone:
+		ik.solveTwoBone(p, lengths, &target, &pole)
+	case SolverFABRIK:
+		ik.solveFABRIK(p, lengths, &target)
+	case SolverCCD:
+		ik.solveCCD(p, &target)
+	}
+	if ik.hasPole && ik.solver != SolverTwoBone {
+		for i := 1; i < ik.length; i++ {
+			bendToPole(p[i-1], &p[i], p[i+1], &pole)
+		}
+	}
+	ik.apply(bones, p)
+}
+
+// solveTwoBone sets the positions of the middle and end bones of a two
+// bone chain to reach the target, bending to the pole if set or else
+// in the current plane of the chain
+func (ik *IKChain) solveTwoBone(p []math32.Vector3, lengths []float32, target, pole *math32.Vector3) {
+
+	l1, l2 := lengths[0], lengths[1]
+	var axis math32.Vector3
+	axis.SubVectors(target, &p[0])
+	d := axis.Length()
+	if d < 1e-6 {
+		return
+	}
+	axis.DivideScalar(d)
+	d = math32.Clamp(d, math32.Abs(l1-l2)+1e-4, l1+l2-1e-4)
+
+	// Bend direction perpendicular to the axis
+	var bend math32.Vector3
+	if ik.hasPole {
+		bend.SubVectors(pole, &p[0])
+	} else {
+		bend.SubVectors(&p[1], &p[0])
+	}
+	bend.ProjectOnPlane(&axis)
+	if bend.LengthSq() < 1e-12 {
+		bend.Set(0, 1, 0)
+		if math32.Abs(axis.Y) > 0.9 {
+			bend.Set(1, 0, 0)
+		}
+		bend.ProjectOnPlane(&axis)
+	}
+	bend.Normalize()
+
+	cos := math32.Clamp((l1*l1+d*d-l2*l2)/(2*l1*d), -1, 1)
+	sin := math32.Sqrt(1 - cos*cos)
+	var a, b math32.Vector3
+	a.Copy(&axis).MultiplyScalar(l1 * cos)
+	b.Copy(&bend).MultiplyScalar(l1 * sin)
+	p[1].Copy(&p[0]).Add(&a).Add(&b)
+	p[2].Copy(&axis).MultiplyScalar(d).Add(&p[0])
+}
+
+// solveFABRIK sets the positions of the bones of the chain with
+// forward and backward passes which keep the lengths of the bones
+func (ik *IKChain) solveFABRIK(p []math32.Vector3, lengths []float32, target *math32.Vector3) {
+
+	n := len(p) - 1
+	root := p[0]
+	var total float32
+	for _, l := range lengths {
+		total += l
+	}
+	var dir math32.Vector3
+
+	// Target out of reach: straightens the chain to the target
+	if root.DistanceTo(target) >= total {
+		dir.SubVectors(target, &root).Normalize()
+		for i := 1; i <= n; i++ {
+			p[i].Copy(&dir).MultiplyScalar(lengths[i-1]).Add(&p[i-1])
+		}
+		return
+	}
+	for iter := 0; iter < ik.iterations; iter++ {
+		if p[n].DistanceTo(target) <= ik.tolerance {
+			break
+		}
+		// Forward pass from the end at the target
+		p[n] = *target
+		for i := n - 1; i >= 0; i-- {
+			dir.SubVectors(&p[i], &p[i+1]).Normalize()
+			p[i].Copy(&dir).MultiplyScalar(lengths[i]).Add(&p[i+1])
+		}
+		// Backward pass from the root at its position
+		p[0] = root
+		for i := 1; i <= n; i++ {
+			dir.SubVectors(&p[i], &p[i-1]).Normalize()
+			p[i].Copy(&dir).MultiplyScalar(lengths[i-1]).Add(&p[i-1])
+		}
+	}
+}
+
+// solveCCD sets the positions of the bones of the chain rotating each
+// bone, from the end to the root, to point the end to the target
+func (ik *IKChain) solveCCD(p []math32.Vector3, target *math32.Vector3) {
+
+	n := len(p) - 1
+	var toEnd, toTarget, v math32.Vector3
+	var q math32.Quaternion
+	for iter := 0; iter < ik.iterations; iter++ {
+		if p[n].DistanceTo(target) <= ik.tolerance {
+			break
+		}
+		for i := n - 1; i >= 0; i-- {
+			toEnd.SubVectors(&p[n], &p[i])
+			toTarget.SubVectors(target, &p[i])
+			if toEnd.LengthSq() < 1e-12 || toTarget.LengthSq() < 1e-12 {
+				continue
+			}
+			q.SetFromUnitVectors(toEnd.Normalize(), toTarget.Normalize())
+			for j := i + 1; j <= n; j++ {
+				v.SubVectors(&p[j], &p[i]).ApplyQuaternion(&q)
+				p[j].AddVectors(&p[i], &v)
+			}
+		}
+	}
+}
+
+// bendToPole rotates the specified joint position around the line between the
+// positions of the previous and next joints to the side of the specified pole
+func bendToPole(prev math32.Vector3, joint *math32.Vector3, next math32.Vector3, pole *math32.Vector3) {
+
+	var axis, vj, vp math32.Vector3
+	axis.SubVectors(&next, &prev)
+	if axis.LengthSq() < 1e-12 {
+		return
+	}
+	axis.Normalize()
+	vj.SubVectors(joint, &prev).ProjectOnPlane(&axis)
+	vp.SubVectors(pole, &prev).ProjectOnPlane(&axis)
+	if vj.LengthSq() < 1e-12 || vp.LengthSq() < 1e-12 {
+		return
+	}
+	var cross math32.Vector3
+	cross.CrossVectors(&vj, &vp)
+	angle := math32.Atan2(cross.Dot(&axis), vj.Dot(&vp))
+	var q math32.Quaternion
+	q.SetFromAxisAngle(&axis, angle)
+	joint.Sub(&prev).ApplyQuaternion(&q).Add(&prev)
+}
+
+// apply rotates the bones of the chain, from its root, so their
+// children are at the specified solved world positions
+func (ik *IKChain) apply(bones []*core.Node, p []math32.Vector3) {
+
+	var from, to, cur, start math32.Vector3
+	var rot, world, parent, local, old math32.Quaternion
+	for i := 0; i < ik.length; i++ {
+		b := bones[i]
+		b.WorldPosition(&start)
+		bones[i+1].WorldPosition(&cur)
+		from.SubVectors(&cur, &start)
+		to.SubVectors(&p[i+1], &start)
+		if from.LengthSq() < 1e-12 || to.LengthSq() < 1e-12 {
+			continue
+		}
+		rot.SetFromUnitVectors(from.Normalize(), to.Normalize())
+
+		// New local rotation from the rotated world rotation
+		b.WorldQuaternion(&world)
+		world.MultiplyQuaternions(&rot, &world)
+		parent.SetIdentity()
+		if b.Parent() != nil {
+			b.Parent().GetNode().WorldQuaternion(&parent)
+		}
+		local.MultiplyQuaternions(parent.Inverse(), &world)
+		if ik.weight < 1 {
+			old = b.Quaternion()
+			local.SlerpQuaternions(&old, &local, ik.weight)
+		}
+		b.SetQuaternionQuat(&local)
+	}
+}
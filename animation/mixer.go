@@ -0,0 +1,368 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"strings"
+)
+
+// IMorphable is the interface of the nodes with morph target weights, as
+// the graphic.MorphMesh, which are animated by the Weights tracks.
+// SetWeights must copy the weights.
+type IMorphable interface {
+	Weights() []float32
+	SetWeights(weights []float32)
+}
+
+// Mixer is a component which plays clips on the hierarchy of the node it is
+// attached to. Each clip is played by an Action of the mixer. For each update
+// the playing actions are advanced and their values are blended by their weights
+// and applied to the animated nodes. If the sum of the weights of the actions
+// animating a property is less than 1, the remaining weight is given to the
+// value of the property before it was first animated by the mixer.
+// The values of the additive actions are then added to the blended values.
+// The tracks find their nodes by name, or by path if the name contains a slash,
+// from the node of the mixer and the nodes not found are ignored.
+type Mixer struct {
+	core.Component                         // Embedded component
+	actions        []*Action               // actions of the mixer
+	bindings       map[bindingKey]*binding // bound properties by target and property
+	timeScale      float32                 // scale of the time of all the actions
+	root           rootMotion              // root motion state
+}
+
+// bindingKey identifies an animated property of a node
+type bindingKey struct {
+	target string
+	prop   Property
+}
+
+// binding is an animated property of a node with the blended value
+// of the actions for the current update
+type binding struct {
+	inode  core.INode // animated node or nil if not found
+	prop   Property   // animated property
+	rest   []float32  // value of the property before it was animated
+	accum  []float32  // weighted sum of the values of the actions
+	weight float32    // sum of the weights of the values
+	delta  []float32  // combined differences of the additive actions
+	added  bool       // additive actions changed the delta
+}
+
+// NewMixer creates and returns a pointer to a new mixer which
+// must be attached to the root node of the animated hierarchy
+func NewMixer() *Mixer {
+
+	m := new(Mixer)
+	m.bindings = make(map[bindingKey]*binding)
+	m.timeScale = 1
+	m.root.rot.SetIdentity()
+	return m
+}
+
+// Action returns the action of this mixer which plays the specified clip,
+// creating it if necessary. New actions are stopped.
+func (m *Mixer) Action(clip *Clip) *Action {
+
+	for _, a := range m.actions {
+		if a.clip == clip {
+			return a
+		}
+	}
+	a := &Action{mixer: m, clip: clip, speed: 1, weight: 1, fade: 1, loop: LoopRepeat}
+	m.actions = append(m.actions, a)
+	return a
+}
+
+// Play plays the action of the specified clip from its current time
+// and returns the action
+func (m *Mixer) Play(clip *Clip) *Action {
+
+	return m.Action(clip).Play()
+}
+
+// Actions returns the actions of this mixer
+func (m *Mixer) Actions() []*Action {
+
+	return m.actions
+}
+
+// RemoveAction stops and removes the action of the specified clip.
+// Returns true if found or false otherwise
+func (m *Mixer) RemoveAction(clip *Clip) bool {
+
+	for i, a := range m.actions {
+		if a.clip == clip {
+			a.Stop()
+			copy(m.actions[i:], m.actions[i+1:])
+			m.actions[len(m.actions)-1] = nil
+			m.actions = m.actions[:len(m.actions)-1]
+			return true
+		}
+	}
+	return false
+}
+
+// StopAll stops all the actions of this mixer
+func (m *Mixer) StopAll() {
+
+	for _, a := range m.actions {
+		a.Stop()
+	}
+}
+
+// SetTimeScale sets the scale of the time of all the actions of
+// this mixer, as 0.5 for slow motion. The default is 1.
+func (m *Mixer) SetTimeScale(scale float32) {
+
+	m.timeScale = scale
+}
+
+// TimeScale returns the scale of the time of all the actions of this mixer
+func (m *Mixer) TimeScale() float32 {
+
+	return m.timeScale
+}
+
+// Rebind drops the nodes found for the tracks, which are found again at
+// the next update, as needed when the animated hierarchy changes.
+// The current values of their properties become their values before
+// they were animated.
+func (m *Mixer) Rebind() {
+
+	m.bindings = make(map[bindingKey]*binding)
+	for _, a := range m.actions {
+		a.bindings = nil
+	}
+}
+
+// Update satisfies the IComponent interface. It advances the playing
+// actions by the specified time in seconds and applies them to the nodes.
+func (m *Mixer) Update(delta float32) {
+
+	delta *= m.timeScale
+	for _, a := range m.actions {
+		if !a.playing {
+			continue
+		}
+		if !a.paused {
+			a.advance(delta)
+		}
+		a.updateFade(delta)
+	}
+	if m.root.enabled {
+		m.updateRootMotion()
+	}
+	m.Apply()
+}
+
+// Apply applies the current values of the playing actions
+// to the nodes without advancing the actions
+func (m *Mixer) Apply() {
+
+	if m.Node() == nil {
+		return
+	}
+	for _, b := range m.bindings {
+		b.weight = 0
+		b.added = false
+		for i := range b.accum {
+			b.accum[i] = 0
+		}
+	}
+	for _, a := range m.actions {
+		ending := a.ending
+		a.ending = false
+		if (!a.playing && !ending) || a.EffectiveWeight() <= 0 {
+			continue
+		}
+		a.accumulate()
+	}
+	for _, b := range m.bindings {
+		if b.weight > 0 || b.added {
+			b.apply()
+		}
+	}
+}
+
+// bind returns the binding of the property of the specified track
+func (m *Mixer) bind(t *Track) *binding {
+
+	key := bindingKey{t.target, t.prop}
+	if b, ok := m.bindings[key]; ok {
+		return b
+	}
+	b := &binding{prop: t.prop}
+	b.inode = findTarget(m.Node(), t.target)
+	if b.inode != nil {
+		b.rest = b.get()
+		if b.rest != nil {
+			b.accum = make([]float32, len(b.rest))
+		}
+	}
+	if b.accum == nil {
+		b.inode = nil
+	}
+	m.bindings[key] = b
+	return b
+}
+
+// findTarget returns the node with the specified name or path
+// of the target of a track from the specified root node or nil
+func findTarget(root *core.Node, target string) core.INode {
+
+	switch {
+	case target == "":
+		return root
+	case strings.Contains(target, "/"):
+		return root.FindPath(target)
+	default:
+		return root.FindByName(target)
+	}
+}
+
+// get returns the current value of the property of this binding
+// or nil if the node doesn't have the property
+func (b *binding) get() []float32 {
+
+	n := b.inode.GetNode()
+	switch b.prop {
+	case Position:
+		v := n.Position()
+		return []float32{v.X, v.Y, v.Z}
+	case Rotation:
+		q := n.Quaternion()
+		return []float32{q.X(), q.Y(), q.Z(), q.W()}
+	case Scale:
+		v := n.Scale()
+		return []float32{v.X, v.Y, v.Z}
+	case Weights:
+		if im, ok := b.inode.(IMorphable); ok {
+			return append([]float32(nil), im.Weights()...)
+		}
+	}
+	return nil
+}
+
+// add adds the specified value with the specified weight to the
+// blended value of this binding. The rotations are added in the
+// hemisphere of the blended value so they don't cancel each other.
+func (b *binding) add(value []float32, weight float32) {
+
+	if b.prop == Rotation && b.weight > 0 {
+		dot := b.accum[0]*value[0] + b.accum[1]*value[1] + b.accum[2]*value[2] + b.accum[3]*value[3]
+		if dot < 0 {
+			weight = -weight
+		}
+	}
+	n := len(b.accum)
+	if len(value) < n {
+		n = len(value)
+	}
+	for i := 0; i < n; i++ {
+		b.accum[i] += value[i] * weight
+	}
+	b.weight += math32.Abs(weight)
+}
+
+// addDelta adds the difference between the specified value and reference
+// value, multiplied by the specified weight, to the combined differences
+// of the additive actions. Rotation differences are composed and scale
+// differences are multiplied.
+func (b *binding) addDelta(value, ref []float32, weight float32) {
+
+	if b.delta == nil {
+		b.delta = make([]float32, len(b.accum))
+	}
+	if !b.added {
+		b.added = true
+		switch b.prop {
+		case Rotation:
+			b.delta[0], b.delta[1], b.delta[2], b.delta[3] = 0, 0, 0, 1
+		case Scale:
+			b.delta[0], b.delta[1], b.delta[2] = 1, 1, 1
+		default:
+			for i := range b.delta {
+				b.delta[i] = 0
+			}
+		}
+	}
+	switch b.prop {
+	case Rotation:
+		var q, r, id, d math32.Quaternion
+		q.Set(value[0], value[1], value[2], value[3])
+		r.Set(ref[0], ref[1], ref[2], ref[3])
+		q.MultiplyQuaternions(r.Inverse(), &q)
+		id.SetIdentity()
+		id.Slerp(&q, weight)
+		d.Set(b.delta[0], b.delta[1], b.delta[2], b.delta[3])
+		d.Multiply(&id)
+		b.delta[0], b.delta[1], b.delta[2], b.delta[3] = d.X(), d.Y(), d.Z(), d.W()
+	case Scale:
+		for i := 0; i < 3; i++ {
+			if ref[i] != 0 {
+				b.delta[i] *= 1 + (value[i]/ref[i]-1)*weight
+			}
+		}
+	default:
+		n := len(b.delta)
+		if len(value) < n {
+			n = len(value)
+		}
+		for i := 0; i < n; i++ {
+			b.delta[i] += (value[i] - ref[i]) * weight
+		}
+	}
+}
+
+// apply sets the property of the node of this binding with its blended value,
+// completed with the value before the property was animated, and the
+// differences of the additive actions
+func (b *binding) apply() {
+
+	if b.weight < 1 {
+		b.add(b.rest, 1-b.weight)
+	}
+	v := b.accum
+	if b.prop == Rotation {
+		normalize(v)
+	} else if b.weight != 1 {
+		for i := range v {
+			v[i] /= b.weight
+		}
+	}
+	if b.added {
+		switch b.prop {
+		case Rotation:
+			var q, d math32.Quaternion
+			q.Set(v[0], v[1], v[2], v[3])
+			d.Set(b.delta[0], b.delta[1], b.delta[2], b.delta[3])
+			q.Multiply(&d)
+			q.Normalize()
+			v[0], v[1], v[2], v[3] = q.X(), q.Y(), q.Z(), q.W()
+		case Scale:
+			for i := range v {
+				v[i] *= b.delta[i]
+			}
+		default:
+			for i := range v {
+				v[i] += b.delta[i]
+			}
+		}
+	}
+	n := b.inode.GetNode()
+	switch b.prop {
+	case Position:
+		n.SetPosition(v[0], v[1], v[2])
+	case Rotation:
+		n.SetQuaternion(v[0], v[1], v[2], v[3])
+	case Scale:
+		n.SetScale(v[0], v[1], v[2])
+	case Weights:
+		b.inode.(IMorphable).SetWeights(v)
+	}
+}
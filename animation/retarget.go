@@ -0,0 +1,315 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// Retargeter converts the clips authored for a source rig, as a humanoid
+// skeleton, to clips which animate a target rig with other proportions and
+// rest poses. The bones of the rigs are matched by a bone map and the rest
+// poses of the rigs are their poses when the retargeter is created.
+// The rotations of the mapped bones are converted so the target bones rotate
+// from their rest poses as the source bones rotate from theirs, in the space
+// of the rigs, and the translations of the topmost mapped bones, as the hips,
+// are scaled by the proportion of their heights. The translations of the other
+// bones keep their rest values and the scale and weights tracks are dropped.
+type Retargeter struct {
+	source  *rig              // source rig
+	target  *rig              // target rig
+	boneMap map[string]string // target bones by source bones
+	pairs   []bonePair        // mapped bones in the order of the target rig
+	scale   float32           // scale of the translations
+	rate    float32           // samples per second of the retargeted clips
+}
+
+// bonePair is a source bone and the target bone it drives
+type bonePair struct {
+	src  int    // index of the source bone
+	dst  int    // index of the target bone
+	name string // name or path of the target bone in the tracks
+	top  bool   // source bone has no mapped ancestors
+}
+
+// rig is a hierarchy of bones with its rest pose
+type rig struct {
+	root      *core.Node          // root node of the rig, which is not a bone
+	bones     []*core.Node        // bones with the parents before their children
+	parent    []int               // index of the parent of each bone or -1
+	index     map[*core.Node]int  // index of each bone
+	restRot   []math32.Quaternion // rest rotation of each bone
+	restPos   []math32.Vector3    // rest position of each bone
+	restWorld []math32.Quaternion // rest rotation of each bone in the space of the root
+	restOrig  []math32.Vector3    // rest position of each bone in the space of the root
+}
+
+// NewRetargeter creates and returns a pointer to a new retargeter from the rig with
+// the specified source root node to the rig with the specified target root node, in
+// their rest poses, with the specified map of the names or paths of the target bones
+// by the names or paths of the source bones, relative to the roots as in the tracks.
+// The bones not found are ignored.
+func NewRetargeter(source, target core.INode, boneMap map[string]string) *Retargeter {
+
+	rt := new(Retargeter)
+	rt.source = newRig(source.GetNode())
+	rt.target = newRig(target.GetNode())
+	rt.boneMap = boneMap
+	rt.rate = 30
+	rt.scale = 1
+	for name, tname := range boneMap {
+		src := rt.source.find(name)
+		dst := rt.target.find(tname)
+		if src < 0 || dst < 0 {
+			continue
+		}
+		rt.pairs = append(rt.pairs, bonePair{src: src, dst: dst, name: tname})
+	}
+	// Target order so the parents are converted before their children
+	sort.Slice(rt.pairs, func(i, j int) bool { return rt.pairs[i].dst < rt.pairs[j].dst })
+	mapped := make(map[int]bool)
+	for _, p := range rt.pairs {
+		mapped[p.src] = true
+	}
+	for i := range rt.pairs {
+		p := &rt.pairs[i]
+		p.top = true
+		for a := rt.source.parent[p.src]; a >= 0; a = rt.source.parent[a] {
+			if mapped[a] {
+				p.top = false
+				break
+			}
+		}
+	}
+
+	// Translation scale from the heights of the first topmost bones
+	for _, p := range rt.pairs {
+		if !p.top {
+			continue
+		}
+		hs := rt.source.restOrig[p.src].Y
+		ht := rt.target.restOrig[p.dst].Y
+		if math32.Abs(hs) < 1e-6 {
+			hs = rt.source.restOrig[p.src].Length()
+			ht = rt.target.restOrig[p.dst].Length()
+		}
+		if math32.Abs(hs) > 1e-6 {
+			rt.scale = ht / hs
+		}
+		break
+	}
+	return rt
+}
+
+// BoneMap returns the map of the target bones by the source bones
+func (rt *Retargeter) BoneMap() map[string]string {
+
+	return rt.boneMap
+}
+
+// SetTranslationScale sets the scale of the translations of the topmost bones,
+// which is by default the height of the first target topmost bone divided by
+// the height of its source bone
+func (rt *Retargeter) SetTranslationScale(scale float32) {
+
+	rt.scale = scale
+}
+
+// TranslationScale returns the scale of the translations of the topmost bones
+func (rt *Retargeter) TranslationScale() float32 {
+
+	return rt.scale
+}
+
+// SetSampleRate sets the number of keyframes per second of the retargeted
+// clips, which are sampled from the source clips. The default is 30.
+func (rt *Retargeter) SetSampleRate(rate float32) {
+
+	rt.rate = rate
+}
+
+// SampleRate returns the number of keyframes per second of the retargeted clips
+func (rt *Retargeter) SampleRate() float32 {
+
+	return rt.rate
+}
+
+// Retarget creates and returns a pointer to a new clip, with the name of the
+// specified clip of the source rig, which animates the target rig
+func (rt *Retargeter) Retarget(clip *Clip) *Clip {
+
+	src := rt.source
+	dst := rt.target
+
+	// Tracks of the source bones
+	rotTracks := make([]*Track, len(src.bones))
+	posTracks := make([]*Track, len(src.bones))
+	for _, t := range clip.tracks {
+		inode := findTarget(src.root, t.target)
+		if inode == nil {
+			continue
+		}
+		i, ok := src.index[inode.GetNode()]
+		if !ok || len(t.times) == 0 {
+			continue
+		}
+		switch t.prop {
+		case Rotation:
+			rotTracks[i] = t
+		case Position:
+			posTracks[i] = t
+		}
+	}
+	// Bones rotated by their tracks or by the tracks of their ancestors
+	animated := make([]bool, len(src.bones))
+	for i := range src.bones {
+		animated[i] = rotTracks[i] != nil || (src.parent[i] >= 0 && animated[src.parent[i]])
+	}
+
+	// Times of the samples
+	count := 1
+	if clip.duration > 0 && rt.rate > 0 {
+		count = int(math32.Ceil(clip.duration*rt.rate)) + 1
+	}
+	times := make([]float32, count)
+	for k := range times {
+		if count > 1 {
+			times[k] = clip.duration * float32(k) / float32(count-1)
+		}
+	}
+
+	rotValues := make([][]float32, len(rt.pairs))
+	posValues := make([][]float32, len(rt.pairs))
+	srcWorld := make([]math32.Quaternion, len(src.bones))
+	dstWorld := make([]math32.Quaternion, len(dst.bones))
+	driver := make([]int, len(dst.bones))
+	for i := range driver {
+		driver[i] = -1
+	}
+	for i, p := range rt.pairs {
+		driver[p.dst] = i
+	}
+	var v [4]float32
+	var q, inv math32.Quaternion
+	for _, time := range times {
+		// World rotations of the source bones
+		for i := range src.bones {
+			q = src.restRot[i]
+			if rotTracks[i] != nil {
+				rotTracks[i].Evaluate(time, v[:])
+				q.Set(v[0], v[1], v[2], v[3])
+			}
+			if pi := src.parent[i]; pi >= 0 {
+				q.MultiplyQuaternions(&srcWorld[pi], &q)
+			}
+			srcWorld[i] = q
+		}
+		// Local rotations of the target bones from the world rotations of their source
+		// bones relative to their rest, applied to the target rest world rotations
+		for i := range dst.bones {
+			var parent math32.Quaternion
+			parent.SetIdentity()
+			if pi := dst.parent[i]; pi >= 0 {
+				parent = dstWorld[pi]
+			}
+			pidx := driver[i]
+			if pidx < 0 || !animated[rt.pairs[pidx].src] {
+				dstWorld[i].MultiplyQuaternions(&parent, &dst.restRot[i])
+				continue
+			}
+			s := rt.pairs[pidx].src
+			inv = src.restWorld[s]
+			inv.Inverse()
+			q.MultiplyQuaternions(&srcWorld[s], &inv)
+			q.Multiply(&dst.restWorld[i])
+			dstWorld[i] = q
+			parent.Inverse()
+			q.MultiplyQuaternions(&parent, &q)
+			q.Normalize()
+			rotValues[pidx] = append(rotValues[pidx], q.X(), q.Y(), q.Z(), q.W())
+		}
+		// Translations of the topmost bones from their rest positions
+		for i, p := range rt.pairs {
+			if !p.top || posTracks[p.src] == nil {
+				continue
+			}
+			posTracks[p.src].Evaluate(time, v[:])
+			var d math32.Vector3
+			d.Set(v[0], v[1], v[2]).Sub(&src.restPos[p.src])
+			if pi := src.parent[p.src]; pi >= 0 {
+				d.ApplyQuaternion(&src.restWorld[pi])
+			}
+			if pi := dst.parent[p.dst]; pi >= 0 {
+				inv = dst.restWorld[pi]
+				d.ApplyQuaternion(inv.Inverse())
+			}
+			d.MultiplyScalar(rt.scale).Add(&dst.restPos[p.dst])
+			posValues[i] = append(posValues[i], d.X, d.Y, d.Z)
+		}
+	}
+
+	out := NewClip(clip.name)
+	out.SetDuration(clip.duration)
+	for i, p := range rt.pairs {
+		if rotValues[i] != nil {
+			out.AddTrack(NewRotationTrack(p.name, Linear, times, rotValues[i]))
+		}
+		if posValues[i] != nil {
+			out.AddTrack(NewPositionTrack(p.name, Linear, times, posValues[i]))
+		}
+	}
+	return out
+}
+
+// newRig creates and returns a pointer to a new rig with the bones
+// below the specified root node in their current pose
+func newRig(root *core.Node) *rig {
+
+	r := &rig{root: root, index: make(map[*core.Node]int)}
+	root.Traverse(func(inode core.INode) bool {
+		n := inode.GetNode()
+		if n == root {
+			return true
+		}
+		parent := -1
+		if pi, ok := r.index[n.Parent().GetNode()]; ok {
+			parent = pi
+		}
+		r.index[n] = len(r.bones)
+		r.bones = append(r.bones, n)
+		r.parent = append(r.parent, parent)
+
+		rot := n.Quaternion()
+		pos := n.Position()
+		world := rot
+		orig := pos
+		if parent >= 0 {
+			world.MultiplyQuaternions(&r.restWorld[parent], &rot)
+			orig.ApplyQuaternion(&r.restWorld[parent]).Add(&r.restOrig[parent])
+		}
+		r.restRot = append(r.restRot, rot)
+		r.restPos = append(r.restPos, pos)
+		r.restWorld = append(r.restWorld, world)
+		r.restOrig = append(r.restOrig, orig)
+		return true
+	})
+	return r
+}
+
+// find returns the index of the bone with the specified
+// name or path or -1 if not found
+func (r *rig) find(name string) int {
+
+	inode := findTarget(r.root, name)
+	if inode == nil {
+		return -1
+	}
+	if i, ok := r.index[inode.GetNode()]; ok {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,253 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// rootMotion is the root motion state of a mixer
+type rootMotion struct {
+	bone    string                                              // name or path of the root bone as in the tracks
+	enabled bool                                                // root motion is extracted
+	pos     math32.Vector3                                      // translation of the last update
+	rot     math32.Quaternion                                   // rotation of the last update
+	cb      func(delta *math32.Vector3, rot *math32.Quaternion) // receives the motion instead of the node of the mixer
+}
+
+// EnableRootMotion extracts the motion of the root bone of the animated
+// character, with the specified name or path as in the tracks of the clips,
+// and applies it to the node of the mixer, so walk cycles move the character
+// instead of moving the root bone away from it. The horizontal translation of
+// the root bone, in its parent XZ plane, and its rotation about the parent Y axis
+// are removed from the pose, where they keep their values of the first keyframes,
+// and the changes of each update, blended by the weights of the actions, move
+// and turn the node of the mixer. The root bone should be a child of the
+// node of the mixer or of a node with its orientation.
+func (m *Mixer) EnableRootMotion(bone string) {
+
+	m.root.bone = bone
+	m.root.enabled = true
+	m.root.rot.SetIdentity()
+}
+
+// DisableRootMotion stops extracting the motion of the root bone,
+// which is again animated by the clips
+func (m *Mixer) DisableRootMotion() {
+
+	m.root.enabled = false
+	m.root.pos.Set(0, 0, 0)
+	m.root.rot.SetIdentity()
+}
+
+// RootMotionEnabled returns if the root motion is extracted
+func (m *Mixer) RootMotionEnabled() bool {
+
+	return m.root.enabled
+}
+
+// RootMotionBone returns the name or path of the root bone whose motion is extracted
+func (m *Mixer) RootMotionBone() string {
+
+	return m.root.bone
+}
+
+// SetOnRootMotion sets the function which receives the root motion of each
+// update, in the space of the node of the mixer, instead of the node, as a
+// character controller which moves the character checking collisions.
+// A nil function applies the motion to the node of the mixer.
+func (m *Mixer) SetOnRootMotion(cb func(delta *math32.Vector3, rot *math32.Quaternion)) {
+
+	m.root.cb = cb
+}
+
+// RootMotion returns the translation and the rotation of the
+// root motion of the last update
+func (m *Mixer) RootMotion() (math32.Vector3, math32.Quaternion) {
+
+	return m.root.pos, m.root.rot
+}
+
+// updateRootMotion blends the root motion of the actions advanced
+// in the last update and applies it
+func (m *Mixer) updateRootMotion() {
+
+	var pos math32.Vector3
+	var rot [4]float32
+	var weight float32
+	for _, a := range m.actions {
+		w := a.EffectiveWeight()
+		moved := a.moved
+		a.moved = false
+		if !moved || a.blend != BlendNormal || w <= 0 {
+			continue
+		}
+		var p math32.Vector3
+		p.Copy(&a.motionPos).MultiplyScalar(w)
+		pos.Add(&p)
+		q := []float32{a.motionRot.X(), a.motionRot.Y(), a.motionRot.Z(), a.motionRot.W()}
+		if q[0]*rot[0]+q[1]*rot[1]+q[2]*rot[2]+q[3]*rot[3] < 0 {
+			w = -w
+		}
+		for i := range rot {
+			rot[i] += q[i] * w
+		}
+		weight += math32.Abs(w)
+	}
+	if weight > 1 {
+		pos.DivideScalar(weight)
+	} else {
+		// The remaining weight has no motion
+		rot[3] += 1 - weight
+	}
+	normalize(rot[:])
+	m.root.pos = pos
+	m.root.rot.Set(rot[0], rot[1], rot[2], rot[3])
+	if m.root.cb != nil {
+		m.root.cb(&m.root.pos, &m.root.rot)
+		return
+	}
+	n := m.Node()
+	if n == nil {
+		return
+	}
+	q := n.Quaternion()
+	s := n.Scale()
+	var d math32.Vector3
+	d.MultiplyVectors(&pos, &s).ApplyQuaternion(&q)
+	p := n.Position()
+	n.SetPositionVec(p.Add(&d))
+	q.Multiply(&m.root.rot)
+	n.SetQuaternionQuat(&q)
+}
+
+// isRoot returns if the specified track animates the root motion
+// extracted by the mixer of this action
+func (a *Action) isRoot(t *Track) bool {
+
+	r := &a.mixer.root
+	return r.enabled && t.target == r.bone && (t.prop == Position || t.prop == Rotation)
+}
+
+// stripRoot removes the root motion from the specified evaluated value of the
+// specified root track, keeping the values of the first keyframe
+func stripRoot(t *Track, v []float32) {
+
+	var buf [4]float32
+	ref := t.value(0, buf[:])
+	if t.prop == Position {
+		v[0] = ref[0]
+		v[2] = ref[2]
+		return
+	}
+	var q, y0, yt math32.Quaternion
+	q.Set(v[0], v[1], v[2], v[3])
+	yaw(ref, &y0)
+	yaw(v, &yt)
+	y0.Multiply(yt.Inverse()).Multiply(&q)
+	v[0], v[1], v[2], v[3] = y0.X(), y0.Y(), y0.Z(), y0.W()
+}
+
+// yaw sets the specified quaternion with the rotation about
+// the Y axis of the specified quaternion components
+func yaw(v []float32, result *math32.Quaternion) {
+
+	var q math32.Quaternion
+	q.Set(v[0], v[1], v[2], v[3])
+	q.SwingTwist(&math32.Vector3{0, 1, 0}, nil, result)
+}
+
+// computeRootMotion sets the root motion of this action when its time
+// advances from the specified time to the specified time, before they
+// are wrapped by its loop mode
+func (a *Action) computeRootMotion(from, to float32) {
+
+	var pt, rt *Track
+	for _, t := range a.clip.tracks {
+		if !a.isRoot(t) || len(t.times) == 0 {
+			continue
+		}
+		if t.prop == Position {
+			pt = t
+		} else {
+			rt = t
+		}
+	}
+	a.motionPos.Set(0, 0, 0)
+	a.motionRot.SetIdentity()
+	a.moved = pt != nil || rt != nil
+	d := a.clip.duration
+	if !a.moved || from == to || d <= 0 {
+		return
+	}
+	if a.loop == LoopOnce {
+		a.addRootMotion(pt, rt, from, math32.Clamp(to, 0, d))
+		return
+	}
+
+	// Splits the time interval in the intervals of the loops of the clip
+	k0 := int(math32.Floor(from / d))
+	k1 := int(math32.Floor(to / d))
+	step := 1
+	if to < from {
+		step = -1
+	}
+	for k := k0; ; k += step {
+		start, end := float32(0), d
+		if step < 0 {
+			start, end = d, 0
+		}
+		if k == k0 {
+			start = from - float32(k0)*d
+		}
+		if k == k1 {
+			end = to - float32(k1)*d
+		}
+		// Odd loops of ping pong actions play backwards
+		if a.loop == LoopPingPong && k%2 != 0 {
+			start, end = d-start, d-end
+		}
+		a.addRootMotion(pt, rt, start, end)
+		if k == k1 {
+			break
+		}
+	}
+}
+
+// addRootMotion adds to the root motion of this action the motion of the
+// specified position and rotation tracks, which may be nil, from the
+// specified clip time to the specified clip time
+func (a *Action) addRootMotion(pt, rt *Track, from, to float32) {
+
+	var v0, v1 [4]float32
+	var y0, y1, yref, inv math32.Quaternion
+	y0.SetIdentity()
+	y1.SetIdentity()
+	yref.SetIdentity()
+	if rt != nil {
+		rt.Evaluate(from, v0[:])
+		yaw(v0[:], &y0)
+		rt.Evaluate(to, v1[:])
+		yaw(v1[:], &y1)
+		yaw(rt.value(0, v0[:]), &yref)
+	}
+	inv = y0
+	inv.Inverse()
+
+	// Translation in the space of the node, facing as the first keyframe
+	var dp math32.Vector3
+	if pt != nil {
+		pt.Evaluate(from, v0[:])
+		pt.Evaluate(to, v1[:])
+		dp.Set(v1[0]-v0[0], 0, v1[2]-v0[2])
+		dp.ApplyQuaternion(&inv).ApplyQuaternion(&yref)
+	}
+	dp.ApplyQuaternion(&a.motionRot)
+	a.motionPos.Add(&dp)
+
+	// Rotation about the Y axis
+	inv.Multiply(&y1)
+	a.motionRot.Multiply(&inv)
+}
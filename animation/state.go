@@ -0,0 +1,420 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/core"
+	"sort"
+)
+
+// OnStateChange is the event dispatched by a StateMachine when it enters a new state
+const OnStateChange = "animation.OnStateChange"
+
+// State is a state of a state machine which plays a clip, or blends several
+// clips by the value of a parameter of the state machine, as a walk and a run
+// clip blended by the speed of a character.
+type State struct {
+	name       string    // name of the state
+	actions    []*Action // actions of the clips of the state
+	thresholds []float32 // values of the parameter of the clips of a blend state in ascending order
+	param      string    // name of the parameter of a blend state
+	speed      float32   // speed of the actions of the state
+	loop       LoopMode  // loop mode of the actions of the state
+}
+
+// Transition is a transition between two states of a state machine which
+// crossfades their clips when its condition is true
+type Transition struct {
+	from     *State                   // state the transition starts from or nil for any state
+	to       *State                   // state the transition goes to
+	duration float32                  // duration of the crossfade in seconds
+	exitTime float32                  // normalized time of the from state from which the transition is possible or -1
+	cond     func(*StateMachine) bool // condition of the transition or nil for none
+}
+
+// StateMachine is a component which plays the clips of the states of an animation
+// graph, as the locomotion of a character, in a mixer. At each update it takes the
+// first of the transitions of its current state whose condition is true and
+// crossfades to the clips of the new state. The conditions normally test
+// the float, bool and trigger parameters set by the game logic. It is normally
+// attached to the node of its mixer, before the mixer so its changes are applied
+// in the same frame, and dispatches OnStateChange with a pointer to itself
+// when it enters a new state.
+type StateMachine struct {
+	core.Component                     // Embedded component
+	core.Dispatcher                    // Embedded event dispatcher
+	mixer           *Mixer             // mixer playing the clips of the states
+	states          []*State           // states in the order they were added
+	transitions     []*Transition      // transitions in the order they were added
+	current         *State             // current state or nil if not started
+	previous        *State             // previous state or nil
+	floats          map[string]float32 // float parameters by name
+	bools           map[string]bool    // bool parameters by name
+	triggers        map[string]bool    // triggers set and not yet used by name
+}
+
+// NewStateMachine creates and returns a pointer to a new state machine
+// which plays the clips of its states in the specified mixer
+func NewStateMachine(mixer *Mixer) *StateMachine {
+
+	sm := new(StateMachine)
+	sm.Dispatcher.Initialize()
+	sm.mixer = mixer
+	sm.floats = make(map[string]float32)
+	sm.bools = make(map[string]bool)
+	sm.triggers = make(map[string]bool)
+	return sm
+}
+
+// Mixer returns the mixer of this state machine
+func (sm *StateMachine) Mixer() *Mixer {
+
+	return sm.mixer
+}
+
+// AddState adds a state with the specified name which plays the specified clip
+// and returns a pointer to it. The first state added is the initial state.
+func (sm *StateMachine) AddState(name string, clip *Clip) *State {
+
+	s := &State{name: name, speed: 1, loop: LoopRepeat}
+	s.actions = []*Action{sm.mixer.Action(clip)}
+	sm.states = append(sm.states, s)
+	return s
+}
+
+// AddBlendState adds a state with the specified name which blends the specified
+// clips by the value of the float parameter with the specified name and returns a
+// pointer to it. Each clip has its full weight when the parameter has its threshold
+// value and the two clips with the nearest thresholds are blended in between.
+// The blended clips are synchronized so they should have the same cycle, as the
+// steps of a walk and a run. The thresholds must be in ascending order.
+func (sm *StateMachine) AddBlendState(name, param string, clips []*Clip, thresholds []float32) *State {
+
+	if len(clips) == 0 || len(clips) != len(thresholds) {
+		panic("animation.AddBlendState: number of thresholds does not match the number of clips")
+	}
+	s := &State{name: name, param: param, speed: 1, loop: LoopRepeat}
+	s.thresholds = thresholds
+	for _, clip := range clips {
+		s.actions = append(s.actions, sm.mixer.Action(clip))
+	}
+	sm.states = append(sm.states, s)
+	return s
+}
+
+// State returns the state with the specified name or nil if not found
+func (sm *StateMachine) State(name string) *State {
+
+	for _, s := range sm.states {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// States returns the states of this state machine
+func (sm *StateMachine) States() []*State {
+
+	return sm.states
+}
+
+// AddTransition adds a transition from the state with the specified name, or from
+// any state if empty, to the state with the specified name, which crossfades their
+// clips in the specified time in seconds when the specified condition is true.
+// A nil condition is always true. Returns a pointer to the transition and
+// panics if a state is not found.
+func (sm *StateMachine) AddTransition(from, to string, duration float32, cond func(*StateMachine) bool) *Transition {
+
+	t := &Transition{duration: duration, exitTime: -1, cond: cond}
+	if from != "" {
+		t.from = sm.State(from)
+		if t.from == nil {
+			panic("animation.AddTransition: state not found: " + from)
+		}
+	}
+	t.to = sm.State(to)
+	if t.to == nil {
+		panic("animation.AddTransition: state not found: " + to)
+	}
+	sm.transitions = append(sm.transitions, t)
+	return t
+}
+
+// Transitions returns the transitions of this state machine
+func (sm *StateMachine) Transitions() []*Transition {
+
+	return sm.transitions
+}
+
+// SetState enters the state with the specified name, or the first state if empty,
+// crossfading from the current state in the specified time in seconds, ignoring
+// the transitions. The state machine starts in its first state if not set
+// before its first update. Returns false if the state is not found.
+func (sm *StateMachine) SetState(name string, duration float32) bool {
+
+	var s *State
+	if name == "" && len(sm.states) > 0 {
+		s = sm.states[0]
+	} else {
+		s = sm.State(name)
+	}
+	if s == nil {
+		return false
+	}
+	sm.enter(s, duration)
+	return true
+}
+
+// Current returns the current state or nil if not started
+func (sm *StateMachine) Current() *State {
+
+	return sm.current
+}
+
+// Previous returns the state before the current state or nil
+func (sm *StateMachine) Previous() *State {
+
+	return sm.previous
+}
+
+// SetFloat sets the value of the float parameter with the specified name
+func (sm *StateMachine) SetFloat(name string, value float32) {
+
+	sm.floats[name] = value
+}
+
+// Float returns the value of the float parameter with the specified name or 0 if not set
+func (sm *StateMachine) Float(name string) float32 {
+
+	return sm.floats[name]
+}
+
+// SetBool sets the value of the bool parameter with the specified name
+func (sm *StateMachine) SetBool(name string, value bool) {
+
+	sm.bools[name] = value
+}
+
+// Bool returns the value of the bool parameter with the specified name or false if not set
+func (sm *StateMachine) Bool(name string) bool {
+
+	return sm.bools[name]
+}
+
+// SetTrigger sets the trigger with the specified name, as for a jump,
+// which is reset when it is used by a condition
+func (sm *StateMachine) SetTrigger(name string) {
+
+	sm.triggers[name] = true
+}
+
+// ResetTrigger resets the trigger with the specified name
+func (sm *StateMachine) ResetTrigger(name string) {
+
+	delete(sm.triggers, name)
+}
+
+// Trigger returns if the trigger with the specified name is set and resets it
+func (sm *StateMachine) Trigger(name string) bool {
+
+	if !sm.triggers[name] {
+		return false
+	}
+	delete(sm.triggers, name)
+	return true
+}
+
+// Update satisfies the IComponent interface. It starts the first state if not
+// started, takes the first possible transition from the current state and
+// updates the weights of the clips of a blend state.
+func (sm *StateMachine) Update(delta float32) {
+
+	if sm.current == nil {
+		if !sm.SetState("", 0) {
+			return
+		}
+	}
+	for _, t := range sm.transitions {
+		if t.to == sm.current || (t.from != nil && t.from != sm.current) {
+			continue
+		}
+		if t.exitTime >= 0 && sm.current.NormalizedTime() < t.exitTime {
+			continue
+		}
+		if t.cond != nil && !t.cond(sm) {
+			continue
+		}
+		sm.enter(t.to, t.duration)
+		break
+	}
+	sm.current.blend(sm)
+}
+
+// enter enters the specified state crossfading from the current
+// state in the specified time in seconds
+func (sm *StateMachine) enter(s *State, duration float32) {
+
+	if sm.current != nil {
+		for _, a := range sm.current.actions {
+			if !s.has(a) {
+				a.FadeOut(duration)
+			}
+		}
+	}
+	for _, a := range s.actions {
+		a.SetLoop(s.loop).SetSpeed(s.speed)
+		if a.playing && a.Fading() {
+			// Fading out from a recent state: fades in again from its current weight
+			a.fadeStop = false
+			a.setFadeRate(1, duration)
+			continue
+		}
+		a.Stop()
+		a.FadeIn(duration)
+	}
+	sm.previous = sm.current
+	sm.current = s
+	s.blend(sm)
+	sm.Dispatch(OnStateChange, sm)
+}
+
+// SetExitTime sets the normalized time of the clips of the from state, as 0.9
+// for 90% of their duration, before which the transition is not possible.
+// Returns a pointer to this transition.
+func (t *Transition) SetExitTime(time float32) *Transition {
+
+	t.exitTime = time
+	return t
+}
+
+// ExitTime returns the exit time of this transition or -1 if not set
+func (t *Transition) ExitTime() float32 {
+
+	return t.exitTime
+}
+
+// From returns the state this transition starts from or nil for any state
+func (t *Transition) From() *State {
+
+	return t.from
+}
+
+// To returns the state this transition goes to
+func (t *Transition) To() *State {
+
+	return t.to
+}
+
+// Duration returns the duration of the crossfade of this transition in seconds
+func (t *Transition) Duration() float32 {
+
+	return t.duration
+}
+
+// Name returns the name of this state
+func (s *State) Name() string {
+
+	return s.name
+}
+
+// Actions returns the actions of the clips of this state
+func (s *State) Actions() []*Action {
+
+	return s.actions
+}
+
+// SetSpeed sets the speed of the clips of this state from the next time
+// it is entered. The default is 1.
+func (s *State) SetSpeed(speed float32) *State {
+
+	s.speed = speed
+	return s
+}
+
+// SetLoop sets the loop mode of the clips of this state from the next
+// time it is entered. The default is LoopRepeat.
+func (s *State) SetLoop(mode LoopMode) *State {
+
+	s.loop = mode
+	return s
+}
+
+// NormalizedTime returns the time of the clip with the highest weight
+// of this state divided by its duration, from 0 to 1
+func (s *State) NormalizedTime() float32 {
+
+	a := s.leader()
+	d := a.clip.duration
+	if d <= 0 {
+		return 1
+	}
+	return a.Time() / d
+}
+
+// has returns if the specified action plays a clip of this state
+func (s *State) has(a *Action) bool {
+
+	for _, sa := range s.actions {
+		if sa == a {
+			return true
+		}
+	}
+	return false
+}
+
+// leader returns the action of this state with the highest weight
+func (s *State) leader() *Action {
+
+	lead := s.actions[0]
+	for _, a := range s.actions[1:] {
+		if a.weight > lead.weight {
+			lead = a
+		}
+	}
+	return lead
+}
+
+// blend sets the weights of the clips of this state, if it is a blend state,
+// by the value of its parameter and synchronizes them with the clip with the
+// highest weight
+func (s *State) blend(sm *StateMachine) {
+
+	if len(s.actions) < 2 {
+		return
+	}
+	p := sm.floats[s.param]
+	th := s.thresholds
+	for _, a := range s.actions {
+		a.weight = 0
+	}
+	next := sort.Search(len(th), func(i int) bool { return th[i] > p })
+	switch {
+	case next == 0:
+		s.actions[0].weight = 1
+	case next == len(th):
+		s.actions[len(th)-1].weight = 1
+	default:
+		f := (p - th[next-1]) / (th[next] - th[next-1])
+		s.actions[next-1].weight = 1 - f
+		s.actions[next].weight = f
+	}
+
+	// Speeds of the clips so they play their cycles in the blended duration
+	var dur float32
+	for _, a := range s.actions {
+		dur += a.weight * a.clip.duration
+	}
+	lead := s.leader()
+	phase := s.NormalizedTime()
+	for _, a := range s.actions {
+		if dur > 0 {
+			a.speed = s.speed * a.clip.duration / dur
+		}
+		if a != lead {
+			a.SetTime(phase * a.clip.duration)
+		}
+	}
+}
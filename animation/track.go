@@ -0,0 +1,259 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// Property is the property of a node animated by a track
+type Property int
+
+// Properties of the nodes animated by the tracks
+const (
+	Position Property = iota // position as a Vector3
+	Rotation                 // rotation as a Quaternion
+	Scale                    // scale as a Vector3
+	Weights                  // weights of the morph targets of an IMorphable node
+)
+
+// Interpolation is the interpolation of the values of a track between its keyframes
+type Interpolation int
+
+// Interpolations of the tracks
+const (
+	Step   Interpolation = iota // value of the previous keyframe
+	Linear                      // linear interpolation, spherical for rotations
+	Cubic                       // cubic Hermite spline with in and out tangents for each keyframe
+)
+
+// Track is a sequence of keyframes of a property of a node.
+// The values of the keyframes are stored one after the other and for the
+// Cubic interpolation each keyframe has its in tangent, its value and its out
+// tangent, as in glTF. Rotations are quaternions stored as x, y, z and w.
+type Track struct {
+	target string        // name or path of the animated node
+	prop   Property      // animated property
+	interp Interpolation // interpolation between keyframes
+	times  []float32     // times of the keyframes in seconds in ascending order
+	values []float32     // values of the keyframes or nil if quantized
+	quant  []uint16      // quantized rotations of the keyframes or nil
+	size   int           // number of components of each value
+}
+
+// NewTrack creates and returns a pointer to a new track of the specified property
+// of the node with the specified name or path, relative to the root of the mixer
+// (empty for the root), with the specified interpolation, times and values.
+// The number of values must match the number of times.
+func NewTrack(target string, prop Property, interp Interpolation, times, values []float32) *Track {
+
+	t := &Track{target: target, prop: prop, interp: interp, times: times, values: values}
+	per := len(times)
+	if interp == Cubic {
+		per *= 3
+	}
+	switch prop {
+	case Position, Scale:
+		t.size = 3
+	case Rotation:
+		t.size = 4
+	case Weights:
+		if per > 0 {
+			t.size = len(values) / per
+		}
+	}
+	if per > 0 && (t.size == 0 || len(values) != per*t.size) {
+		panic("animation.NewTrack: number of values does not match the number of times")
+	}
+	return t
+}
+
+// NewPositionTrack creates and returns a pointer to a new track of the position
+// of the specified node with 3 values for each keyframe
+func NewPositionTrack(target string, interp Interpolation, times, values []float32) *Track {
+
+	return NewTrack(target, Position, interp, times, values)
+}
+
+// NewRotationTrack creates and returns a pointer to a new track of the rotation
+// of the specified node with a quaternion for each keyframe
+func NewRotationTrack(target string, interp Interpolation, times, values []float32) *Track {
+
+	return NewTrack(target, Rotation, interp, times, values)
+}
+
+// NewScaleTrack creates and returns a pointer to a new track of the scale
+// of the specified node with 3 values for each keyframe
+func NewScaleTrack(target string, interp Interpolation, times, values []float32) *Track {
+
+	return NewTrack(target, Scale, interp, times, values)
+}
+
+// NewWeightsTrack creates and returns a pointer to a new track of the morph
+// target weights of the specified node, as a graphic.MorphMesh, with a weight
+// of each target for each keyframe. The values have the layout of the outputs
+// of the glTF samplers of weights animations, which are imported as these
+// tracks by the loader/gltf package.
+func NewWeightsTrack(target string, interp Interpolation, times, values []float32) *Track {
+
+	return NewTrack(target, Weights, interp, times, values)
+}
+
+// Target returns the name or path of the node animated by this track
+func (t *Track) Target() string {
+
+	return t.target
+}
+
+// Property returns the property animated by this track
+func (t *Track) Property() Property {
+
+	return t.prop
+}
+
+// Interpolation returns the interpolation of this track
+func (t *Track) Interpolation() Interpolation {
+
+	return t.interp
+}
+
+// Times returns the times of the keyframes of this track
+func (t *Track) Times() []float32 {
+
+	return t.times
+}
+
+// Values returns the values of the keyframes of this track,
+// decoded in a new slice if the track is quantized
+func (t *Track) Values() []float32 {
+
+	if t.quant != nil {
+		return t.decode()
+	}
+	return t.values
+}
+
+// Size returns the number of components of the values of this track
+func (t *Track) Size() int {
+
+	return t.size
+}
+
+// KeyCount returns the number of keyframes of this track
+func (t *Track) KeyCount() int {
+
+	return len(t.times)
+}
+
+// Duration returns the time of the last keyframe of this track
+func (t *Track) Duration() float32 {
+
+	if len(t.times) == 0 {
+		return 0
+	}
+	return t.times[len(t.times)-1]
+}
+
+// value returns the value of the keyframe with the specified index,
+// decoded in the specified slice if the track is quantized
+func (t *Track) value(idx int, buf []float32) []float32 {
+
+	if t.quant != nil {
+		dequantize(t.quant[idx*3:idx*3+3], buf)
+		return buf[:4]
+	}
+	if t.interp == Cubic {
+		start := (3*idx + 1) * t.size
+		return t.values[start : start+t.size]
+	}
+	return t.values[idx*t.size : (idx+1)*t.size]
+}
+
+// tangent returns the in or out tangent of the cubic keyframe with the specified index
+func (t *Track) tangent(idx int, out bool) []float32 {
+
+	start := 3 * idx * t.size
+	if out {
+		start += 2 * t.size
+	}
+	return t.values[start : start+t.size]
+}
+
+// Evaluate sets the specified slice, with at least Size elements,
+// with the value of this track at the specified time in seconds.
+// The times before the first keyframe and after the last one
+// have the values of the first and last keyframes.
+func (t *Track) Evaluate(time float32, out []float32) {
+
+	count := len(t.times)
+	if count == 0 {
+		return
+	}
+	// Index of the first keyframe after the time
+	var buf0, buf1 [4]float32
+	next := sort.Search(count, func(i int) bool { return t.times[i] > time })
+	if next == 0 {
+		copy(out, t.value(0, buf0[:]))
+		return
+	}
+	if next == count {
+		copy(out, t.value(count-1, buf0[:]))
+		return
+	}
+	prev := next - 1
+	if t.interp == Step {
+		copy(out, t.value(prev, buf0[:]))
+		return
+	}
+	dt := t.times[next] - t.times[prev]
+	s := (time - t.times[prev]) / dt
+	v0 := t.value(prev, buf0[:])
+	v1 := t.value(next, buf1[:])
+
+	if t.interp == Linear {
+		if t.prop == Rotation {
+			var qa, qb math32.Quaternion
+			qa.Set(v0[0], v0[1], v0[2], v0[3])
+			qb.Set(v1[0], v1[1], v1[2], v1[3])
+			qa.Slerp(&qb, s)
+			out[0], out[1], out[2], out[3] = qa.X(), qa.Y(), qa.Z(), qa.W()
+			return
+		}
+		for i := 0; i < t.size; i++ {
+			out[i] = v0[i] + (v1[i]-v0[i])*s
+		}
+		return
+	}
+
+	// Cubic Hermite spline with the tangents scaled by the keyframes interval
+	b0 := t.tangent(prev, true)
+	a1 := t.tangent(next, false)
+	s2 := s * s
+	s3 := s2 * s
+	h00 := 2*s3 - 3*s2 + 1
+	h10 := (s3 - 2*s2 + s) * dt
+	h01 := -2*s3 + 3*s2
+	h11 := (s3 - s2) * dt
+	for i := 0; i < t.size; i++ {
+		out[i] = h00*v0[i] + h10*b0[i] + h01*v1[i] + h11*a1[i]
+	}
+	if t.prop == Rotation {
+		normalize(out[:4])
+	}
+}
+
+// normalize normalizes the specified quaternion components
+func normalize(q []float32) {
+
+	l := math32.Sqrt(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])
+	if l == 0 {
+		q[0], q[1], q[2], q[3] = 0, 0, 0, 1
+		return
+	}
+	for i := range q {
+		q[i] /= l
+	}
+}
@@ -0,0 +1,593 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/math32/easing"
+)
+
+// IColorable is the interface of the materials whose color can be tweened,
+// as the material.Standard
+type IColorable interface {
+	Color() math32.Color
+	SetColor(color *math32.Color)
+}
+
+// IFadable is the interface of the materials whose opacity can be tweened,
+// as the material.Standard
+type IFadable interface {
+	Opacity() float32
+	SetOpacity(opacity float32)
+}
+
+// Tweener is a component which advances tweens, the transitions of node and
+// material properties and other values from their current values to target
+// values during a time using an easing curve, as for the motion of the game
+// objects or the feedback of the user interface. It is normally attached to
+// the scene root. Tweens start at the next update after they are created and
+// may be grouped in sequences and parallel groups. Finished tweens are removed
+// and the tweens of a node are stopped when it leaves the scene. Starting a
+// tween stops the tweens of the same property of the same target.
+type Tweener struct {
+	core.Component          // Embedded component
+	tweens         []*Tween // tweens not in groups
+	timeScale      float32  // scale of the time of all the tweens
+}
+
+// Tween is a transition of a value, a group of tweens played in sequence or in
+// parallel, a wait or a function call, created by a Tweener. Its methods
+// return a pointer to the tween so they can be chained.
+type Tween struct {
+	tweener  *Tweener          // tweener advancing this tween
+	kind     tweenKind         // kind of this tween
+	target   interface{}       // target whose property is changed or nil
+	key      string            // name of the property changed
+	node     *core.Node        // node which stops this tween when it leaves the scene or nil
+	attached bool              // node was seen in a scene
+	get      func(v []float32) // reads the current value
+	set      func(v []float32) // sets the value
+	from     []float32         // start value
+	to       []float32         // end value
+	cur      []float32         // current value
+	rotation bool              // value is a quaternion interpolated spherically
+	duration float32           // duration of the transition in seconds
+	delay    float32           // time before the start in seconds
+	wait     float32           // time waited for the start
+	time     float32           // time since the start of the current cycle
+	easing   easing.Func       // easing curve or nil for linear
+	repeat   int               // number of repeats or -1 for forever
+	cycle    int               // number of the current cycle
+	yoyo     bool              // repeats change the value backwards and forwards
+	children []*Tween          // tweens of a group
+	index    int               // index of the current tween of a sequence
+	grouped  bool              // tween is in a group
+	started  bool              // tween started
+	done     bool              // tween finished or stopped
+	onStart  func(*Tween)      // called when the tween starts
+	onUpdate func(*Tween)      // called when the value changes
+	onDone   func(*Tween)      // called when the tween finishes
+}
+
+// tweenKind is the kind of a tween
+type tweenKind int
+
+const (
+	tweenValue tweenKind = iota
+	tweenSequence
+	tweenParallel
+)
+
+// NewTweener creates and returns a pointer to a new tweener
+func NewTweener() *Tweener {
+
+	tr := new(Tweener)
+	tr.timeScale = 1
+	return tr
+}
+
+// SetTimeScale sets the scale of the time of all the tweens of this tweener,
+// as 0.5 for slow motion. The default is 1.
+func (tr *Tweener) SetTimeScale(scale float32) {
+
+	tr.timeScale = scale
+}
+
+// TimeScale returns the scale of the time of all the tweens of this tweener
+func (tr *Tweener) TimeScale() float32 {
+
+	return tr.timeScale
+}
+
+// Tweens returns the tweens of this tweener which are not in groups
+func (tr *Tweener) Tweens() []*Tween {
+
+	return tr.tweens
+}
+
+// Stop stops the tweens of the specified target, which is a node or a material,
+// without calling their done functions
+func (tr *Tweener) Stop(target interface{}) {
+
+	if inode, ok := target.(core.INode); ok {
+		target = inode.GetNode()
+	}
+	tr.each(func(tw *Tween) {
+		if tw.target == target {
+			tw.done = true
+		}
+	})
+}
+
+// StopAll stops all the tweens without calling their done functions
+func (tr *Tweener) StopAll() {
+
+	for _, tw := range tr.tweens {
+		tw.done = true
+	}
+	tr.tweens = nil
+}
+
+// Update satisfies the IComponent interface. It advances the tweens
+// by the specified time in seconds and removes the finished ones.
+func (tr *Tweener) Update(delta float32) {
+
+	delta *= tr.timeScale
+	count := 0
+	for i := 0; i < len(tr.tweens); i++ {
+		tw := tr.tweens[i]
+		if !tw.grouped && !tw.done {
+			tw.advance(delta)
+		}
+		if !tw.grouped && !tw.done {
+			tr.tweens[count] = tw
+			count++
+		}
+	}
+	for i := count; i < len(tr.tweens); i++ {
+		tr.tweens[i] = nil
+	}
+	tr.tweens = tr.tweens[:count]
+}
+
+// Move creates and returns a pointer to a tween which moves the specified
+// node to the specified position in the specified time in seconds
+func (tr *Tweener) Move(inode core.INode, pos *math32.Vector3, duration float32) *Tween {
+
+	n := inode.GetNode()
+	tw := tr.values(n, "position", []float32{pos.X, pos.Y, pos.Z}, duration)
+	tw.get = func(v []float32) {
+		p := n.Position()
+		v[0], v[1], v[2] = p.X, p.Y, p.Z
+	}
+	tw.set = func(v []float32) { n.SetPosition(v[0], v[1], v[2]) }
+	return tw
+}
+
+// Scale creates and returns a pointer to a tween which scales the specified
+// node to the specified scale in the specified time in seconds
+func (tr *Tweener) Scale(inode core.INode, scale *math32.Vector3, duration float32) *Tween {
+
+	n := inode.GetNode()
+	tw := tr.values(n, "scale", []float32{scale.X, scale.Y, scale.Z}, duration)
+	tw.get = func(v []float32) {
+		s := n.Scale()
+		v[0], v[1], v[2] = s.X, s.Y, s.Z
+	}
+	tw.set = func(v []float32) { n.SetScale(v[0], v[1], v[2]) }
+	return tw
+}
+
+// Rotate creates and returns a pointer to a tween which rotates the specified
+// node to the specified rotation in the specified time in seconds
+func (tr *Tweener) Rotate(inode core.INode, q *math32.Quaternion, duration float32) *Tween {
+
+	n := inode.GetNode()
+	tw := tr.values(n, "rotation", []float32{q.X(), q.Y(), q.Z(), q.W()}, duration)
+	tw.rotation = true
+	tw.get = func(v []float32) {
+		q := n.Quaternion()
+		v[0], v[1], v[2], v[3] = q.X(), q.Y(), q.Z(), q.W()
+	}
+	tw.set = func(v []float32) { n.SetQuaternion(v[0], v[1], v[2], v[3]) }
+	return tw
+}
+
+// Color creates and returns a pointer to a tween which changes the color of
+// the specified material to the specified color in the specified time in seconds
+func (tr *Tweener) Color(mat IColorable, color *math32.Color, duration float32) *Tween {
+
+	tw := tr.values(mat, "color", []float32{color.R, color.G, color.B}, duration)
+	tw.get = func(v []float32) {
+		c := mat.Color()
+		v[0], v[1], v[2] = c.R, c.G, c.B
+	}
+	tw.set = func(v []float32) { mat.SetColor(&math32.Color{v[0], v[1], v[2]}) }
+	return tw
+}
+
+// Fade creates and returns a pointer to a tween which changes the opacity of
+// the specified material to the specified opacity in the specified time in seconds
+func (tr *Tweener) Fade(mat IFadable, opacity float32, duration float32) *Tween {
+
+	tw := tr.values(mat, "opacity", []float32{opacity}, duration)
+	tw.get = func(v []float32) { v[0] = mat.Opacity() }
+	tw.set = func(v []float32) { mat.SetOpacity(v[0]) }
+	return tw
+}
+
+// Float creates and returns a pointer to a tween which changes a float value,
+// read and set by the specified functions, to the specified value in the
+// specified time in seconds
+func (tr *Tweener) Float(get func() float32, set func(float32), to float32, duration float32) *Tween {
+
+	tw := tr.values(nil, "", []float32{to}, duration)
+	tw.get = func(v []float32) { v[0] = get() }
+	tw.set = func(v []float32) { set(v[0]) }
+	return tw
+}
+
+// Vector3 creates and returns a pointer to a tween which changes a vector,
+// read and set by the specified functions, to the specified vector in the
+// specified time in seconds
+func (tr *Tweener) Vector3(get func() math32.Vector3, set func(*math32.Vector3), to *math32.Vector3, duration float32) *Tween {
+
+	tw := tr.values(nil, "", []float32{to.X, to.Y, to.Z}, duration)
+	tw.get = func(v []float32) {
+		p := get()
+		v[0], v[1], v[2] = p.X, p.Y, p.Z
+	}
+	tw.set = func(v []float32) { set(&math32.Vector3{v[0], v[1], v[2]}) }
+	return tw
+}
+
+// Wait creates and returns a pointer to a tween which only waits
+// the specified time in seconds, as a pause in a sequence
+func (tr *Tweener) Wait(duration float32) *Tween {
+
+	return tr.values(nil, "", nil, duration)
+}
+
+// Call creates and returns a pointer to a tween which calls the
+// specified function, as an event in a sequence
+func (tr *Tweener) Call(cb func()) *Tween {
+
+	tw := tr.values(nil, "", nil, 0)
+	tw.onDone = func(*Tween) { cb() }
+	return tw
+}
+
+// Sequence creates and returns a pointer to a tween which plays the
+// specified tweens one after the other
+func (tr *Tweener) Sequence(tweens ...*Tween) *Tween {
+
+	return tr.group(tweenSequence, tweens)
+}
+
+// Parallel creates and returns a pointer to a tween which plays the
+// specified tweens at the same time and finishes when all finished
+func (tr *Tweener) Parallel(tweens ...*Tween) *Tween {
+
+	return tr.group(tweenParallel, tweens)
+}
+
+// values creates and adds a tween of the specified values
+func (tr *Tweener) values(target interface{}, key string, to []float32, duration float32) *Tween {
+
+	tw := &Tween{tweener: tr, kind: tweenValue, target: target, key: key, to: to, duration: duration}
+	tw.from = make([]float32, len(to))
+	tw.cur = make([]float32, len(to))
+	if n, ok := target.(*core.Node); ok {
+		tw.node = n
+		tw.attached = n.Attached()
+	}
+	tr.tweens = append(tr.tweens, tw)
+	return tw
+}
+
+// group creates and adds a group of the specified kind with the specified tweens
+func (tr *Tweener) group(kind tweenKind, tweens []*Tween) *Tween {
+
+	tw := &Tween{tweener: tr, kind: kind, children: tweens}
+	for _, child := range tweens {
+		child.grouped = true
+	}
+	tr.tweens = append(tr.tweens, tw)
+	return tw
+}
+
+// each calls the specified function for all the tweens of this tweener and their children
+func (tr *Tweener) each(cb func(tw *Tween)) {
+
+	var visit func(tw *Tween)
+	visit = func(tw *Tween) {
+		cb(tw)
+		for _, child := range tw.children {
+			visit(child)
+		}
+	}
+	for _, tw := range tr.tweens {
+		if !tw.grouped {
+			visit(tw)
+		}
+	}
+}
+
+// SetEasing sets the easing curve of this tween, as one of the functions of
+// the math32/easing package or the Value method of a math32/curve Easing,
+// which maps the fraction of its time to the fraction of the change of its
+// value. The default is linear.
+func (tw *Tween) SetEasing(ease easing.Func) *Tween {
+
+	tw.easing = ease
+	return tw
+}
+
+// SetDelay sets the time in seconds before this tween starts
+func (tw *Tween) SetDelay(delay float32) *Tween {
+
+	tw.delay = delay
+	return tw
+}
+
+// SetRepeat sets the number of times this tween is played again after it
+// finishes, or -1 to repeat it forever until stopped
+func (tw *Tween) SetRepeat(count int) *Tween {
+
+	tw.repeat = count
+	return tw
+}
+
+// SetYoyo sets if the repeats of this tween change its value back to its start
+// value and then forwards again alternately. It is ignored for groups.
+func (tw *Tween) SetYoyo(state bool) *Tween {
+
+	tw.yoyo = state
+	return tw
+}
+
+// SetFrom sets the start value of this tween instead of the value of the
+// property when it starts. The value must have the size of the end value.
+func (tw *Tween) SetFrom(values ...float32) *Tween {
+
+	copy(tw.from, values)
+	tw.get = nil
+	return tw
+}
+
+// SetOnStart sets the function called when this tween starts after its delay
+func (tw *Tween) SetOnStart(cb func(*Tween)) *Tween {
+
+	tw.onStart = cb
+	return tw
+}
+
+// SetOnUpdate sets the function called when this tween changes its value
+func (tw *Tween) SetOnUpdate(cb func(*Tween)) *Tween {
+
+	tw.onUpdate = cb
+	return tw
+}
+
+// SetOnDone sets the function called when this tween finishes
+func (tw *Tween) SetOnDone(cb func(*Tween)) *Tween {
+
+	tw.onDone = cb
+	return tw
+}
+
+// Target returns the node or material whose property is changed by this tween or nil
+func (tw *Tween) Target() interface{} {
+
+	return tw.target
+}
+
+// Value returns the current value of this tween
+func (tw *Tween) Value() []float32 {
+
+	return tw.cur
+}
+
+// Done returns if this tween finished or was stopped
+func (tw *Tween) Done() bool {
+
+	return tw.done
+}
+
+// Stop stops this tween without calling its done function
+func (tw *Tween) Stop() {
+
+	tw.done = true
+}
+
+// Finish sets the end values of this tween and of the remaining tweens of a group,
+// ignoring the repeats, and finishes it calling the done functions
+func (tw *Tween) Finish() {
+
+	if tw.done {
+		return
+	}
+	if !tw.started {
+		tw.start()
+	}
+	switch tw.kind {
+	case tweenValue:
+		tw.apply(1)
+	case tweenSequence:
+		for ; tw.index < len(tw.children); tw.index++ {
+			tw.children[tw.index].Finish()
+		}
+	case tweenParallel:
+		for _, child := range tw.children {
+			child.Finish()
+		}
+	}
+	tw.finish()
+}
+
+// advance advances this tween by the specified time in seconds and
+// returns the time remaining after it finished
+func (tw *Tween) advance(delta float32) float32 {
+
+	if tw.done {
+		return delta
+	}
+	if tw.node != nil {
+		if tw.node.Attached() {
+			tw.attached = true
+		} else if tw.attached {
+			tw.done = true
+			return delta
+		}
+	}
+	if tw.wait < tw.delay {
+		tw.wait += delta
+		if tw.wait < tw.delay {
+			return 0
+		}
+		delta = tw.wait - tw.delay
+	}
+	if !tw.started {
+		tw.start()
+	}
+	for {
+		left, finished := tw.step(delta)
+		if !finished || tw.done {
+			return 0
+		}
+		if tw.repeat >= 0 && tw.cycle >= tw.repeat {
+			tw.finish()
+			return left
+		}
+		// Repeats forever without consuming time would never return
+		if tw.repeat < 0 && left >= delta {
+			return 0
+		}
+		tw.cycle++
+		tw.restart()
+		delta = left
+	}
+}
+
+// step advances the current cycle of this tween by the specified time in seconds
+// and returns the time remaining after it finished and if it finished
+func (tw *Tween) step(delta float32) (float32, bool) {
+
+	switch tw.kind {
+	case tweenSequence:
+		for tw.index < len(tw.children) {
+			child := tw.children[tw.index]
+			delta = child.advance(delta)
+			if !child.done {
+				return 0, false
+			}
+			tw.index++
+		}
+		return delta, true
+	case tweenParallel:
+		left := delta
+		finished := true
+		for _, child := range tw.children {
+			if child.done {
+				continue
+			}
+			l := child.advance(delta)
+			if !child.done {
+				finished = false
+			} else if l < left {
+				left = l
+			}
+		}
+		return left, finished
+	}
+	tw.time += delta
+	if tw.duration <= 0 || tw.time >= tw.duration {
+		tw.apply(1)
+		return tw.time - tw.duration, true
+	}
+	tw.apply(tw.time / tw.duration)
+	return 0, false
+}
+
+// start reads the start value of this tween and stops the
+// other tweens of the same property of its target
+func (tw *Tween) start() {
+
+	tw.started = true
+	if tw.kind == tweenValue {
+		if tw.get != nil {
+			tw.get(tw.from)
+		}
+		if tw.target != nil {
+			tw.tweener.each(func(other *Tween) {
+				if other != tw && other.started && other.target == tw.target && other.key == tw.key {
+					other.done = true
+				}
+			})
+		}
+	}
+	if tw.onStart != nil {
+		tw.onStart(tw)
+	}
+}
+
+// restart restarts this tween for a new cycle
+func (tw *Tween) restart() {
+
+	tw.time = 0
+	tw.index = 0
+	if tw.kind == tweenValue && tw.yoyo {
+		tw.from, tw.to = tw.to, tw.from
+	}
+	for _, child := range tw.children {
+		child.reset()
+	}
+}
+
+// reset resets this tween of a group, and its children, to play it again
+func (tw *Tween) reset() {
+
+	tw.started = false
+	tw.done = false
+	tw.wait = 0
+	tw.cycle = 0
+	tw.restart()
+}
+
+// apply sets the value of this tween at the specified fraction of its duration
+func (tw *Tween) apply(f float32) {
+
+	if len(tw.to) == 0 {
+		return
+	}
+	if tw.easing != nil {
+		f = tw.easing(f)
+	}
+	if tw.rotation {
+		var qa, qb math32.Quaternion
+		qa.Set(tw.from[0], tw.from[1], tw.from[2], tw.from[3])
+		qb.Set(tw.to[0], tw.to[1], tw.to[2], tw.to[3])
+		qa.Slerp(&qb, f)
+		tw.cur[0], tw.cur[1], tw.cur[2], tw.cur[3] = qa.X(), qa.Y(), qa.Z(), qa.W()
+	} else {
+		for i := range tw.cur {
+			tw.cur[i] = tw.from[i] + (tw.to[i]-tw.from[i])*f
+		}
+	}
+	tw.set(tw.cur)
+	if tw.onUpdate != nil {
+		tw.onUpdate(tw)
+	}
+}
+
+// finish finishes this tween and calls its done function
+func (tw *Tween) finish() {
+
+	tw.done = true
+	if tw.onDone != nil {
+		tw.onDone(tw)
+	}
+}
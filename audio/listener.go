@@ -56,7 +56,7 @@ func (l *Listener) Gain() float32 {
 
 // Render is called by the renderer at each frame
 // Updates the OpenAL position and orientation of this listener
-func (l *Listener) Render(gl *gls.GLS) {
+func (l *Listener) Render(gl gls.IDevice) {
 
 	// Sets the listener source world position
 	var wpos math32.Vector3
@@ -292,7 +292,7 @@ func (p *Player) SetRolloffFactor(rfactor float32) {
 // Render satisfies the INode interface.
 // It is called by renderer at every frame and is used to
 // update the audio source position and direction
-func (p *Player) Render(gl *gls.GLS) {
+func (p *Player) Render(gl gls.IDevice) {
 
 	// Sets the player source world position
 	var wpos math32.Vector3
@@ -26,6 +26,7 @@ type Camera struct {
 	target     math32.Vector3 // camera target in world coordinates
 	up         math32.Vector3 // camera Up vector
 	viewMatrix math32.Matrix4 // last calculated view matrix
+	cullMask   core.Layers    // layers of the nodes rendered by this camera
 }
 
 // Initialize initializes the base camera.
@@ -35,6 +36,7 @@ func (cam *Camera) Initialize() {
 	cam.Node.Init()
 	cam.target.Set(0, 0, 0)
 	cam.up.Set(0, 1, 0)
+	cam.cullMask = core.AllLayers
 	cam.SetDirection(0, 0, -1)
 	cam.updateQuaternion()
 }
@@ -80,6 +82,19 @@ func (cam *Camera) SetUp(up *math32.Vector3) {
 	cam.up = *up
 }
 
+// SetCullMask sets the layers of the nodes rendered by this camera.
+// By default the camera renders all the layers.
+func (cam *Camera) SetCullMask(mask core.Layers) {
+
+	cam.cullMask = mask
+}
+
+// CullMask returns the layers of the nodes rendered by this camera
+func (cam *Camera) CullMask() core.Layers {
+
+	return cam.cullMask
+}
+
 // SetPosition sets this camera world position
 // This method overrides the Node method to update
 // the camera quaternion, because changing the camera position
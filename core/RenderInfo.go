@@ -9,6 +9,8 @@ import (
 )
 
 type RenderInfo struct {
-	ViewMatrix math32.Matrix4 // Current camera view matrix
-	ProjMatrix math32.Matrix4 // Current camera projection matrix
+	ViewMatrix   math32.Matrix4 // Current camera view matrix
+	ProjMatrix   math32.Matrix4 // Current camera projection matrix
+	OIT          bool           // Order independent transparency pass
+	DepthPrepass bool           // Depth buffer was filled by a depth pre-pass
 }
@@ -0,0 +1,117 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// IBounded is the interface for nodes which have a local bounding box,
+// such as graphics, and which can be culled by the renderer.
+// LocalBoundingBox returns false if the node bounds are unknown.
+type IBounded interface {
+	LocalBoundingBox() (math32.Box3, bool)
+}
+
+// nodeBounds contains the cached world bounds of a node
+type nodeBounds struct {
+	neverCull bool           // node must never be culled
+	valid     bool           // cached sphere is valid for the matrix and local box below
+	has       bool           // node has its own bounds
+	matrix    math32.Matrix4 // world matrix used to calculate the cached sphere
+	local     math32.Box3    // local box used to calculate the cached sphere
+	sphere    math32.Sphere  // world bounding sphere of this node
+	subtree   math32.Box3    // world bounding box of this node and its visible descendants
+	cullable  bool           // subtree can be culled as a whole
+	count     int            // number of bounded nodes in the subtree
+}
+
+// SetNeverCull sets if this node should never be culled by the renderer.
+// This is normally used for nodes whose geometry is transformed by
+// their shaders, such as skyboxes and GUI panels.
+func (n *Node) SetNeverCull(state bool) {
+
+	n.bounds.neverCull = state
+}
+
+// NeverCull returns the state of this node never cull flag
+func (n *Node) NeverCull() bool {
+
+	return n.bounds.neverCull
+}
+
+// WorldBoundingSphere returns the cached world bounding sphere of this node
+// and true, or false if this node has no bounds.
+// The value is updated by UpdateWorldBounds().
+func (n *Node) WorldBoundingSphere() (math32.Sphere, bool) {
+
+	return n.bounds.sphere, n.bounds.has
+}
+
+// SubtreeBoundingBox returns the cached world bounding box of this node
+// and of all its visible descendants, and if the subtree may be culled as a whole.
+// The value is updated by UpdateWorldBounds().
+func (n *Node) SubtreeBoundingBox() (math32.Box3, bool) {
+
+	return n.bounds.subtree, n.bounds.cullable
+}
+
+// SubtreeBoundedCount returns the number of nodes with bounds in this node subtree
+func (n *Node) SubtreeBoundedCount() int {
+
+	return n.bounds.count
+}
+
+// UpdateWorldBounds updates the cached world bounds of the specified node and
+// all of its descendants. It must be called after UpdateMatrixWorld().
+// The world bounding sphere of a node is only recalculated if its world matrix
+// or local bounding box changed since the last update.
+// A subtree may be culled as a whole only if all its nodes are either
+// bounded nodes which may be culled or plain group nodes.
+func UpdateWorldBounds(inode INode) {
+
+	n := inode.GetNode()
+	b := &n.bounds
+	b.has = false
+	b.count = 0
+	b.subtree.MakeEmpty()
+	_, group := inode.(*Node)
+	b.cullable = group
+
+	// Updates this node own bounds
+	ib, ok := inode.(IBounded)
+	if ok {
+		local, ok := ib.LocalBoundingBox()
+		if ok {
+			if !b.valid || b.matrix != n.matrixWorld || b.local != local {
+				local.GetBoundingSphere(&b.sphere)
+				b.sphere.ApplyMatrix4(&n.matrixWorld)
+				b.matrix = n.matrixWorld
+				b.local = local
+				b.valid = true
+			}
+			b.has = true
+			b.count = 1
+			b.cullable = !b.neverCull
+			b.sphere.GetBoundingBox(&b.subtree)
+		}
+	}
+
+	// Updates children bounds and joins them to this node subtree bounds
+	for _, ichild := range n.children {
+		UpdateWorldBounds(ichild)
+		child := ichild.GetNode()
+		if !child.visible {
+			continue
+		}
+		b.count += child.bounds.count
+		if !child.bounds.cullable {
+			b.cullable = false
+		}
+		if !child.bounds.subtree.Empty() {
+			b.subtree.Union(&child.bounds.subtree)
+		}
+	}
+}
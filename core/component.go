@@ -0,0 +1,221 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"reflect"
+)
+
+// IComponent is the interface of the components which add behavior to the
+// nodes they are attached to. The component types normally embed a Component
+// and override the methods of the lifecycle they need:
+// Start is called once before the first update of the component,
+// Update is called for each frame with the time elapsed since the previous
+// frame in seconds and OnEvent is called for the events dispatched to the node.
+// OnAttach and OnDetach are called when the node enters and leaves a scene
+// or when the component is added to or removed from a node in a scene,
+// even if the component is disabled, to acquire and release resources.
+type IComponent interface {
+	GetComponent() *Component
+	Start()
+	Update(delta float32)
+	OnEvent(evname string, ev interface{})
+	OnAttach()
+	OnDetach()
+}
+
+// Component is the base component which is normally embedded in other component types
+type Component struct {
+	node     *Node // node this component is attached to
+	disabled bool  // disabled components are not started, updated or sent events
+	started  bool  // Start was called
+}
+
+// GetComponent satisfies the IComponent interface and returns
+// a pointer to the embedded Component
+func (c *Component) GetComponent() *Component {
+
+	return c
+}
+
+// Node returns the node this component is attached to or nil if not attached
+func (c *Component) Node() *Node {
+
+	return c.node
+}
+
+// SetEnabled sets if this component is enabled. Components are enabled by default.
+func (c *Component) SetEnabled(state bool) {
+
+	c.disabled = !state
+}
+
+// Enabled returns if this component is enabled
+func (c *Component) Enabled() bool {
+
+	return !c.disabled
+}
+
+// Started returns if Start was already called for this component
+// since it was attached to its node
+func (c *Component) Started() bool {
+
+	return c.started
+}
+
+// Start satisfies the IComponent interface and does nothing
+func (c *Component) Start() {
+}
+
+// Update satisfies the IComponent interface and does nothing
+func (c *Component) Update(delta float32) {
+}
+
+// OnEvent satisfies the IComponent interface and does nothing
+func (c *Component) OnEvent(evname string, ev interface{}) {
+}
+
+// OnAttach satisfies the IComponent interface and does nothing
+func (c *Component) OnAttach() {
+}
+
+// OnDetach satisfies the IComponent interface and does nothing
+func (c *Component) OnDetach() {
+}
+
+// AddComponent attaches the specified component to this node, detaching
+// it from its previous node, and returns a pointer to this node.
+// The component is started again before its next update.
+func (n *Node) AddComponent(ic IComponent) *Node {
+
+	c := ic.GetComponent()
+	if c.node != nil {
+		c.node.RemoveComponent(ic)
+	}
+	c.node = n
+	c.started = false
+	n.components = append(n.components, ic)
+	if n.attached {
+		ic.OnAttach()
+	}
+	return n
+}
+
+// RemoveComponent detaches the specified component from this node.
+// Returns true if found or false otherwise
+func (n *Node) RemoveComponent(ic IComponent) bool {
+
+	for pos, current := range n.components {
+		if current == ic {
+			copy(n.components[pos:], n.components[pos+1:])
+			n.components[len(n.components)-1] = nil
+			n.components = n.components[:len(n.components)-1]
+			if n.attached {
+				ic.OnDetach()
+			}
+			ic.GetComponent().node = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Components returns the list of the components attached to this node
+func (n *Node) Components() []IComponent {
+
+	return n.components
+}
+
+// Component returns the first component attached to this node with
+// the same type as the specified sample or nil if not found
+func (n *Node) Component(sample IComponent) IComponent {
+
+	typ := reflect.TypeOf(sample)
+	for _, ic := range n.components {
+		if reflect.TypeOf(ic) == typ {
+			return ic
+		}
+	}
+	return nil
+}
+
+// FindComponents looks in this node and all its children for the nodes
+// with a component of the same type as the specified sample and returns them
+func (n *Node) FindComponents(sample IComponent) []INode {
+
+	return n.FindAll(func(inode INode) bool {
+		return inode.GetNode().Component(sample) != nil
+	})
+}
+
+// UpdateComponents updates the enabled components of this node and of all its
+// children with the specified time in seconds elapsed since the previous update,
+// starting the components not yet started. It is normally called once for each
+// frame by the application loop for the scene before rendering it.
+// The components and nodes added while updating may be updated in the same call.
+func (n *Node) UpdateComponents(delta float32) {
+
+	for i := 0; i < len(n.components); i++ {
+		ic := n.components[i]
+		c := ic.GetComponent()
+		if c.disabled {
+			continue
+		}
+		if !c.started {
+			c.started = true
+			ic.Start()
+			// The component may have been removed by Start
+			if c.node != n {
+				continue
+			}
+		}
+		ic.Update(delta)
+	}
+	for i := 0; i < len(n.children); i++ {
+		n.children[i].GetNode().UpdateComponents(delta)
+	}
+}
+
+// Dispatch dispatches the specified event and data to all the subscribers
+// of this node and then, if not cancelled, to the OnEvent method of its
+// enabled components. Returns true if the propagation was cancelled by a subscriber.
+func (n *Node) Dispatch(evname string, ev interface{}) bool {
+
+	if n.Dispatcher.Dispatch(evname, ev) {
+		return true
+	}
+	for i := 0; i < len(n.components); i++ {
+		ic := n.components[i]
+		if !ic.GetComponent().disabled {
+			ic.OnEvent(evname, ev)
+		}
+	}
+	return false
+}
+
+// Broadcast dispatches the specified event and data to this node and all its children,
+// as the events from the window the components of a scene need to receive
+func (n *Node) Broadcast(evname string, ev interface{}) {
+
+	n.Dispatch(evname, ev)
+	for i := 0; i < len(n.children); i++ {
+		n.children[i].GetNode().Broadcast(evname, ev)
+	}
+}
+
+// Bubble dispatches the specified event and data to this node and then to its
+// parents up to the root of its hierarchy, until the propagation is cancelled
+// by a subscriber which consumes the event. Returns true if it was cancelled.
+func (n *Node) Bubble(evname string, ev interface{}) bool {
+
+	for node := n; ; node = node.parent.GetNode() {
+		if node.Dispatch(evname, ev) {
+			return true
+		}
+		if node.parent == nil {
+			return false
+		}
+	}
+}
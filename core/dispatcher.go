@@ -9,6 +9,7 @@ import ()
 type Dispatcher struct {
 	evmap  map[string][]subscription // maps event name to subcriptions list
 	cancel bool                      // flag informing cancelled dispatch
+	seq    int                       // sequence number of the last subscription
 }
 
 type IDispatcher interface {
@@ -23,8 +24,11 @@ type IDispatcher interface {
 type Callback func(string, interface{})
 
 type subscription struct {
-	id interface{}
-	cb func(string, interface{})
+	id       interface{}
+	cb       func(string, interface{})
+	priority int  // subscriptions with higher priority are called first
+	once     bool // subscription removed when called
+	seq      int  // sequence number identifying the subscription
 }
 
 // NewEventDispatcher creates and returns a pointer to an Event Dispatcher
@@ -55,13 +59,68 @@ func (ed *Dispatcher) Subscribe(evname string, cb Callback) {
 func (ed *Dispatcher) SubscribeID(evname string, id interface{}, cb Callback) {
 
 	//log.Debug("Dispatcher(%p).SubscribeID:%s (%v)", ed, evname, id)
-	ed.evmap[evname] = append(ed.evmap[evname], subscription{id, cb})
+	ed.subscribe(evname, subscription{id: id, cb: cb})
+}
+
+// SubscribePriority subscribes to receive events with the given name with the
+// specified priority and id. The subscriptions with higher priority are called
+// first and the subscriptions with the same priority in the order they were made.
+// The subscriptions made with the other functions have priority 0.
+func (ed *Dispatcher) SubscribePriority(evname string, id interface{}, priority int, cb Callback) {
+
+	ed.subscribe(evname, subscription{id: id, cb: cb, priority: priority})
+}
+
+// SubscribeOnce subscribes to receive only the next event with the given name.
+// The subscription is removed before it is called.
+func (ed *Dispatcher) SubscribeOnce(evname string, cb Callback) {
+
+	ed.SubscribeOnceID(evname, nil, cb)
+}
+
+// SubscribeOnceID subscribes to receive only the next event with the given name.
+// The function accepts a unique id to be used to unsubscribe before the event.
+func (ed *Dispatcher) SubscribeOnceID(evname string, id interface{}, cb Callback) {
+
+	ed.subscribe(evname, subscription{id: id, cb: cb, once: true})
+}
+
+// subscribe inserts the specified subscription in the list of the subscriptions
+// of the specified event after the subscriptions with the same or higher priority.
+// The lists are never changed in place, so they can be changed by the
+// subscribers while they are dispatched.
+func (ed *Dispatcher) subscribe(evname string, sub subscription) {
+
+	ed.seq++
+	sub.seq = ed.seq
+	subs := ed.evmap[evname]
+	pos := len(subs)
+	for pos > 0 && subs[pos-1].priority < sub.priority {
+		pos--
+	}
+	// Appending does not change the subscriptions being dispatched
+	if pos == len(subs) {
+		ed.evmap[evname] = append(subs, sub)
+		return
+	}
+	list := make([]subscription, 0, len(subs)+1)
+	list = append(list, subs[:pos]...)
+	list = append(list, sub)
+	list = append(list, subs[pos:]...)
+	ed.evmap[evname] = list
 }
 
 // Unsubscribe unsubscribes from the specified event and subscription id
 // Returns the number of subscriptions found.
 func (ed *Dispatcher) UnsubscribeID(evname string, id interface{}) int {
 
+	return ed.unsubscribe(evname, func(sub *subscription) bool { return sub.id == id })
+}
+
+// unsubscribe removes the subscriptions of the specified event for which
+// the specified function returns true and returns their number
+func (ed *Dispatcher) unsubscribe(evname string, match func(*subscription) bool) int {
+
 	// Get list of subscribers for this event
 	// If not found, nothing to do
 	subs, ok := ed.evmap[evname]
@@ -69,21 +128,20 @@ func (ed *Dispatcher) UnsubscribeID(evname string, id interface{}) int {
 		return 0
 	}
 
-	// Remove all subscribers with the specified id for this event
+	// Keeps the subscribers which do not match in a new list
 	found := 0
-	pos := 0
-	for pos < len(subs) {
-		if subs[pos].id == id {
-			copy(subs[pos:], subs[pos+1:])
-			subs[len(subs)-1] = subscription{}
-			subs = subs[:len(subs)-1]
+	list := make([]subscription, 0, len(subs))
+	for i := range subs {
+		if match(&subs[i]) {
 			found++
 		} else {
-			pos++
+			list = append(list, subs[i])
 		}
 	}
+	if found > 0 {
+		ed.evmap[evname] = list
+	}
 	//log.Debug("Dispatcher(%p).UnsubscribeID:%s (%p): %v",ed, evname, id, found)
-	ed.evmap[evname] = subs
 	return found
 }
 
@@ -97,16 +155,27 @@ func (ed *Dispatcher) Dispatch(evname string, ev interface{}) bool {
 		return false
 	}
 
-	// Dispatch to all subscribers
+	// Dispatch to all subscribers, removing the once subscriptions before
+	// they are called. The cancel flag of an outer dispatch is restored.
 	//log.Debug("Dispatcher(%p).Dispatch:%s", ed, evname)
+	outer := ed.cancel
 	ed.cancel = false
 	for i := 0; i < len(subs); i++ {
+		if subs[i].once {
+			seq := subs[i].seq
+			if ed.unsubscribe(evname, func(sub *subscription) bool { return sub.seq == seq }) == 0 {
+				// Already called by a nested dispatch or unsubscribed
+				continue
+			}
+		}
 		subs[i].cb(evname, ev)
 		if ed.cancel {
 			break
 		}
 	}
-	return ed.cancel
+	cancelled := ed.cancel
+	ed.cancel = outer
+	return cancelled
 }
 
 // ClearSubscriptions clear all subscriptions from this dispatcher
@@ -116,7 +185,8 @@ func (ed *Dispatcher) ClearSubscriptions() {
 	//log.Debug("Dispatcher(%p).ClearSubscriptions: %d", ed, len(ed.evmap))
 }
 
-// CancelDispatch cancels the propagation of the current event.
+// CancelDispatch cancels the propagation of the current event, which is
+// consumed by the calling subscriber.
 // No more subscribers will be called for this event dispatch.
 func (ed *Dispatcher) CancelDispatch() {
 
@@ -0,0 +1,52 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+// Layers is a bit mask of up to 32 rendering layers.
+// The nodes are assigned to layers and the cameras render only the
+// nodes in the layers of their cull masks, so different cameras, as
+// minimap or first person weapon cameras, see different subsets of a scene.
+type Layers uint32
+
+// Predefined layer masks
+const (
+	LayerDefault Layers = 1 << 0     // layer of the new nodes
+	AllLayers    Layers = 0xFFFFFFFF // all the layers
+)
+
+// Layer returns the mask with only the specified layer from 0 to 31
+func Layer(idx int) Layers {
+
+	return 1 << uint(idx)
+}
+
+// Has returns if this mask has any of the layers of the specified mask
+func (l Layers) Has(other Layers) bool {
+
+	return l&other != 0
+}
+
+// SetLayers sets the layers of this node. New nodes are in LayerDefault.
+// The layers are not inherited by the children of the node.
+func (n *Node) SetLayers(layers Layers) {
+
+	n.layers = layers
+}
+
+// Layers returns the layers of this node
+func (n *Node) Layers() Layers {
+
+	return n.layers
+}
+
+// SetLayer sets if this node is in the specified layer from 0 to 31
+func (n *Node) SetLayer(idx int, state bool) {
+
+	if state {
+		n.layers |= Layer(idx)
+	} else {
+		n.layers &^= Layer(idx)
+	}
+}
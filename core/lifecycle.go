@@ -0,0 +1,109 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+// Node lifecycle events
+const (
+	OnAttach            = "core.OnAttach"            // node entered a scene (INode)
+	OnDetach            = "core.OnDetach"            // node left a scene (INode)
+	OnDescendantAdded   = "core.OnDescendantAdded"   // node added to the subtree of the node (INode)
+	OnDescendantRemoved = "core.OnDescendantRemoved" // node removed from the subtree of the node (INode)
+)
+
+// SetSceneRoot sets if this node is the root of a scene.
+// The nodes in the hierarchy of a scene root are attached to the scene.
+// When a node enters a scene, the OnAttach event is dispatched to its
+// subscribers and the OnAttach method of its components is called, for the
+// parents before their children. When a node leaves a scene, the OnDetach
+// method of its components is called and the OnDetach event is dispatched
+// to its subscribers, for the children before their parents.
+// A node moved to another parent in the same scene leaves it and enters it again.
+func (n *Node) SetSceneRoot(state bool) {
+
+	n.sceneRoot = state
+	if state {
+		n.attach(n)
+		return
+	}
+	if n.parent == nil || !n.parent.GetNode().attached {
+		n.detach(n)
+	}
+}
+
+// SceneRoot returns if this node is the root of a scene
+func (n *Node) SceneRoot() bool {
+
+	return n.sceneRoot
+}
+
+// Attached returns if this node is in the hierarchy of a scene root
+func (n *Node) Attached() bool {
+
+	return n.attached
+}
+
+// attach attaches the specified node, whose embedded Node
+// is this node, and its children to the scene of its parent
+func (n *Node) attach(inode INode) {
+
+	if n.attached {
+		return
+	}
+	n.attached = true
+	n.Dispatcher.Dispatch(OnAttach, inode)
+	for i := 0; i < len(n.components); i++ {
+		n.components[i].OnAttach()
+	}
+	for i := 0; i < len(n.children); i++ {
+		ichild := n.children[i]
+		ichild.GetNode().attach(ichild)
+	}
+}
+
+// detach detaches the specified node, whose embedded Node is this node,
+// and its children from their scene, except the nested scene roots
+func (n *Node) detach(inode INode) {
+
+	if !n.attached || n.sceneRoot {
+		return
+	}
+	for i := len(n.children) - 1; i >= 0; i-- {
+		ichild := n.children[i]
+		ichild.GetNode().detach(ichild)
+	}
+	for i := len(n.components) - 1; i >= 0; i-- {
+		n.components[i].OnDetach()
+	}
+	n.attached = false
+	n.Dispatcher.Dispatch(OnDetach, inode)
+}
+
+// childAdded attaches the specified child just added to this node if this node
+// is attached and dispatches OnDescendantAdded to this node and its parents
+func (n *Node) childAdded(ichild INode) {
+
+	if n.attached {
+		ichild.GetNode().attach(ichild)
+	}
+	for node := n; ; node = node.parent.GetNode() {
+		node.Dispatch(OnDescendantAdded, ichild)
+		if node.parent == nil {
+			break
+		}
+	}
+}
+
+// childRemoved detaches the specified child just removed from this node
+// and dispatches OnDescendantRemoved to this node and its parents
+func (n *Node) childRemoved(ichild INode) {
+
+	ichild.GetNode().detach(ichild)
+	for node := n; ; node = node.parent.GetNode() {
+		node.Dispatch(OnDescendantRemoved, ichild)
+		if node.parent == nil {
+			break
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"time"
+)
+
+// Loop drives the updates of an application loop with a variable update
+// for each frame and fixed updates at a constant rate, as needed by physics
+// and deterministic gameplay. The fixed updates run as many times as needed
+// to catch up with the elapsed time and the remaining fraction of a fixed
+// step is available as the interpolation alpha, so rendering can interpolate
+// the states of the last two fixed updates for smooth motion.
+type Loop struct {
+	step     float64             // fixed step in seconds
+	accum    float64             // time not yet consumed by fixed updates
+	alpha    float32             // fraction of a fixed step in the accumulator
+	maxDelta float32             // maximum elapsed time of a frame
+	maxSteps int                 // maximum number of fixed updates of a frame
+	ticks    uint64              // number of fixed updates
+	last     time.Time           // time of the last tick
+	fixed    func(step float32)  // fixed update callback
+	update   func(delta float32) // variable update callback
+}
+
+// IFixedUpdater is the interface of the components which are
+// updated by FixedUpdateComponents at each fixed step
+type IFixedUpdater interface {
+	FixedUpdate(step float32)
+}
+
+// NewLoop creates and returns a pointer to a new loop with
+// the specified rate of fixed updates per second
+func NewLoop(rate float32) *Loop {
+
+	l := new(Loop)
+	l.SetRate(rate)
+	l.maxDelta = 0.25
+	l.maxSteps = 8
+	return l
+}
+
+// SetRate sets the number of fixed updates per second
+func (l *Loop) SetRate(rate float32) {
+
+	l.step = 1 / float64(rate)
+}
+
+// Rate returns the number of fixed updates per second
+func (l *Loop) Rate() float32 {
+
+	return float32(1 / l.step)
+}
+
+// Step returns the time in seconds of each fixed update
+func (l *Loop) Step() float32 {
+
+	return float32(l.step)
+}
+
+// SetLimits sets the maximum time in seconds of a frame and the maximum number of
+// fixed updates of a frame. The time above these limits is dropped, slowing down
+// the application instead of spending even more time to catch up after long frames.
+// The default values are 0.25 seconds and 8 updates.
+func (l *Loop) SetLimits(maxDelta float32, maxSteps int) {
+
+	l.maxDelta = maxDelta
+	l.maxSteps = maxSteps
+}
+
+// SetFixedUpdate sets the function called for each fixed update with the fixed step
+func (l *Loop) SetFixedUpdate(cb func(step float32)) {
+
+	l.fixed = cb
+}
+
+// SetUpdate sets the function called for each frame after the fixed updates
+// with the time elapsed since the previous frame
+func (l *Loop) SetUpdate(cb func(delta float32)) {
+
+	l.update = cb
+}
+
+// Alpha returns the fraction of a fixed step elapsed since the last fixed update,
+// from 0 to 1, to interpolate the states of the last two fixed updates
+func (l *Loop) Alpha() float32 {
+
+	return l.alpha
+}
+
+// Ticks returns the number of fixed updates since the loop was created
+func (l *Loop) Ticks() uint64 {
+
+	return l.ticks
+}
+
+// Tick advances this loop by the time elapsed since the previous tick,
+// which is zero for the first one, and returns it.
+// It is normally called once for each frame by the application loop.
+func (l *Loop) Tick() float32 {
+
+	now := time.Now()
+	var delta float32
+	if !l.last.IsZero() {
+		delta = float32(now.Sub(l.last).Seconds())
+	}
+	l.last = now
+	l.Advance(delta)
+	return delta
+}
+
+// Advance advances this loop by the specified time in seconds, calling the
+// fixed update for each fixed step elapsed and then the variable update
+func (l *Loop) Advance(delta float32) {
+
+	if delta > l.maxDelta {
+		delta = l.maxDelta
+	}
+	l.accum += float64(delta)
+	for steps := 0; l.accum >= l.step; steps++ {
+		if steps == l.maxSteps {
+			// Drops the whole steps which could not be run
+			l.accum -= float64(int(l.accum/l.step)) * l.step
+			break
+		}
+		if l.fixed != nil {
+			l.fixed(float32(l.step))
+		}
+		l.accum -= l.step
+		l.ticks++
+	}
+	l.alpha = float32(l.accum / l.step)
+	if l.update != nil {
+		l.update(delta)
+	}
+}
+
+// Reset drops the time not yet consumed by the fixed updates
+// and makes the next tick elapse no time, as after a pause
+func (l *Loop) Reset() {
+
+	l.accum = 0
+	l.alpha = 0
+	l.last = time.Time{}
+}
+
+// FixedUpdateComponents calls the FixedUpdate method with the specified
+// fixed step of the enabled and started components of this node and of all
+// its children which implement IFixedUpdater. It is normally set as the
+// fixed update of the application Loop.
+func (n *Node) FixedUpdateComponents(step float32) {
+
+	for i := 0; i < len(n.components); i++ {
+		ic := n.components[i]
+		c := ic.GetComponent()
+		if c.disabled || !c.started {
+			continue
+		}
+		if fu, ok := ic.(IFixedUpdater); ok {
+			fu.FixedUpdate(step)
+		}
+	}
+	for i := 0; i < len(n.children); i++ {
+		n.children[i].GetNode().FixedUpdateComponents(step)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// Metadata is a store of typed values by key, as the gameplay data of the
+// nodes of a scene. The values are strings, float64 numbers, ints, bools
+// and math32 Vector2, Vector3 and Vector4, which are copied by value.
+// Unlike the node user data, the metadata of a node is saved with it
+// and copied to the nodes cloned or instantiated from prefabs.
+type Metadata struct {
+	values map[string]interface{}
+}
+
+// Metadata returns a pointer to the metadata of this node
+func (n *Node) Metadata() *Metadata {
+
+	return &n.metadata
+}
+
+// Set sets the value with the specified key, which must be of one of the
+// supported types. Returns an error if the type is not supported.
+func (m *Metadata) Set(key string, value interface{}) error {
+
+	switch value.(type) {
+	case string, float64, int, bool, math32.Vector2, math32.Vector3, math32.Vector4:
+	default:
+		return fmt.Errorf("metadata type %T of key %q not supported", value, key)
+	}
+	if m.values == nil {
+		m.values = make(map[string]interface{})
+	}
+	m.values[key] = value
+	return nil
+}
+
+// Value returns the value with the specified key or nil if not found
+func (m *Metadata) Value(key string) interface{} {
+
+	return m.values[key]
+}
+
+// Has returns if there is a value with the specified key
+func (m *Metadata) Has(key string) bool {
+
+	_, ok := m.values[key]
+	return ok
+}
+
+// Delete removes the value with the specified key
+func (m *Metadata) Delete(key string) {
+
+	delete(m.values, key)
+}
+
+// Clear removes all the values
+func (m *Metadata) Clear() {
+
+	m.values = nil
+}
+
+// Len returns the number of values
+func (m *Metadata) Len() int {
+
+	return len(m.values)
+}
+
+// Keys returns the keys of the values in sorted order
+func (m *Metadata) Keys() []string {
+
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Copy replaces the values with the values of the specified metadata
+func (m *Metadata) Copy(other *Metadata) {
+
+	m.values = nil
+	for key, value := range other.values {
+		m.Set(key, value)
+	}
+}
+
+// Equals returns if the specified metadata has the same values
+func (m *Metadata) Equals(other *Metadata) bool {
+
+	if len(m.values) != len(other.values) {
+		return false
+	}
+	for key, value := range m.values {
+		if ov, ok := other.values[key]; !ok || ov != value {
+			return false
+		}
+	}
+	return true
+}
+
+// SetString sets the string with the specified key
+func (m *Metadata) SetString(key string, v string) {
+
+	m.Set(key, v)
+}
+
+// String returns the string with the specified key or "" if not found
+func (m *Metadata) String(key string) string {
+
+	v, _ := m.values[key].(string)
+	return v
+}
+
+// SetNumber sets the number with the specified key
+func (m *Metadata) SetNumber(key string, v float64) {
+
+	m.Set(key, v)
+}
+
+// Number returns the number with the specified key, converting ints,
+// or 0 if not found
+func (m *Metadata) Number(key string) float64 {
+
+	switch v := m.values[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// SetInt sets the int with the specified key
+func (m *Metadata) SetInt(key string, v int) {
+
+	m.Set(key, v)
+}
+
+// Int returns the int with the specified key or 0 if not found
+func (m *Metadata) Int(key string) int {
+
+	v, _ := m.values[key].(int)
+	return v
+}
+
+// SetBool sets the bool with the specified key
+func (m *Metadata) SetBool(key string, v bool) {
+
+	m.Set(key, v)
+}
+
+// Bool returns the bool with the specified key or false if not found
+func (m *Metadata) Bool(key string) bool {
+
+	v, _ := m.values[key].(bool)
+	return v
+}
+
+// SetVector2 sets the Vector2 with the specified key
+func (m *Metadata) SetVector2(key string, v *math32.Vector2) {
+
+	m.Set(key, *v)
+}
+
+// Vector2 returns the Vector2 with the specified key or a zero vector if not found
+func (m *Metadata) Vector2(key string) math32.Vector2 {
+
+	v, _ := m.values[key].(math32.Vector2)
+	return v
+}
+
+// SetVector3 sets the Vector3 with the specified key
+func (m *Metadata) SetVector3(key string, v *math32.Vector3) {
+
+	m.Set(key, *v)
+}
+
+// Vector3 returns the Vector3 with the specified key or a zero vector if not found
+func (m *Metadata) Vector3(key string) math32.Vector3 {
+
+	v, _ := m.values[key].(math32.Vector3)
+	return v
+}
+
+// SetVector4 sets the Vector4 with the specified key
+func (m *Metadata) SetVector4(key string, v *math32.Vector4) {
+
+	m.Set(key, *v)
+}
+
+// Vector4 returns the Vector4 with the specified key or a zero vector if not found
+func (m *Metadata) Vector4(key string) math32.Vector4 {
+
+	v, _ := m.values[key].(math32.Vector4)
+	return v
+}
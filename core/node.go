@@ -14,7 +14,7 @@ type INode interface {
 	GetNode() *Node
 	UpdateMatrixWorld()
 	Raycast(*Raycaster, *[]Intersect)
-	Render(gs *gls.GLS)
+	Render(gs gls.IDevice)
 	Dispose()
 }
 
@@ -33,7 +33,21 @@ type Node struct {
 	parent      INode             // Parent node
 	children    []INode           // Array with node children
 	userData    interface{}       // Generic user data
-}
+	components  []IComponent      // Attached components
+	tags        []string          // Tags for queries
+	bounds      nodeBounds        // Cached world bounds used for culling
+	matrixDirty bool              // Local matrix must be composed from the transform
+	worldDirty  bool              // World matrix must be updated
+	worldStamp  uint64            // Stamp of the last update of the world matrix
+	parentStamp uint64            // Stamp of the parent world matrix used in the last update
+	sceneRoot   bool              // Node is the root of a scene
+	attached    bool              // Node is in the hierarchy of a scene root
+	layers      Layers            // Rendering layers
+	metadata    Metadata          // Typed user metadata
+}
+
+// worldStamps is the counter which generates the stamps of the world matrices updates
+var worldStamps uint64
 
 // NewNode creates and returns a pointer to a new Node
 func NewNode() *Node {
@@ -55,6 +69,9 @@ func (n *Node) Init() {
 	n.direction.Set(0, 0, 1)
 	n.matrix.Identity()
 	n.matrixWorld.Identity()
+	n.matrixDirty = true
+	n.worldDirty = true
+	n.layers = LayerDefault
 	n.children = make([]INode, 0)
 	n.visible = true
 }
@@ -71,7 +88,7 @@ func (n *Node) Raycast(rc *Raycaster, intersects *[]Intersect) {
 }
 
 // Render satisfies the INode interface
-func (n *Node) Render(gs *gls.GLS) {
+func (n *Node) Render(gs gls.IDevice) {
 }
 
 // Dispose satisfies the INode interface
@@ -98,21 +115,9 @@ func (n *Node) LoaderID() string {
 // Returns nil if not found
 func (n *Node) FindLoaderID(id string) INode {
 
-	var finder func(parent INode, id string) INode
-	finder = func(parent INode, id string) INode {
-		pnode := parent.GetNode()
-		if pnode.loaderID == id {
-			return parent
-		}
-		for _, child := range pnode.children {
-			found := finder(child, id)
-			if found != nil {
-				return found
-			}
-		}
-		return nil
-	}
-	return finder(n, id)
+	return n.Find(func(inode INode) bool {
+		return inode.GetNode().loaderID == id
+	})
 }
 
 // SetName set an option name for the node.
@@ -132,30 +137,35 @@ func (n *Node) Name() string {
 func (n *Node) SetPosition(x, y, z float32) {
 
 	n.position.Set(x, y, z)
+	n.matrixDirty = true
 }
 
 // SetPositionVec sets this node position from the specified vector pointer
 func (n *Node) SetPositionVec(vpos *math32.Vector3) {
 
 	n.position = *vpos
+	n.matrixDirty = true
 }
 
 // SetPositionX sets the x coordinate of this node position
 func (n *Node) SetPositionX(x float32) {
 
 	n.position.X = x
+	n.matrixDirty = true
 }
 
 // SetPositionY sets the y coordinate of this node position
 func (n *Node) SetPositionY(y float32) {
 
 	n.position.Y = y
+	n.matrixDirty = true
 }
 
 // SetPositionZ sets the z coordinate of this node position
 func (n *Node) SetPositionZ(z float32) {
 
 	n.position.Z = z
+	n.matrixDirty = true
 }
 
 // Position returns the current node position as a vector
@@ -170,6 +180,7 @@ func (n *Node) SetRotation(x, y, z float32) {
 
 	n.rotation.Set(x, y, z)
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // SetRotationX sets the x rotation angle in radians
@@ -178,6 +189,7 @@ func (n *Node) SetRotationX(x float32) {
 
 	n.rotation.X = x
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // SetRotationY sets the y rotation angle in radians
@@ -186,6 +198,7 @@ func (n *Node) SetRotationY(y float32) {
 
 	n.rotation.Y = y
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // SetRotationZ sets the z rotation angle in radians
@@ -194,6 +207,7 @@ func (n *Node) SetRotationZ(z float32) {
 
 	n.rotation.Z = z
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // AddRotationX adds to the current rotation x coordinate in radians
@@ -202,6 +216,7 @@ func (n *Node) AddRotationX(x float32) {
 
 	n.rotation.X += x
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // AddRotationY adds to the current rotation y coordinate in radians
@@ -210,6 +225,7 @@ func (n *Node) AddRotationY(y float32) {
 
 	n.rotation.Y += y
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // AddRotationZ adds to the current rotation z coordinate in radians
@@ -218,6 +234,7 @@ func (n *Node) AddRotationZ(z float32) {
 
 	n.rotation.Z += z
 	n.quaternion.SetFromEuler(&n.rotation)
+	n.matrixDirty = true
 }
 
 // Rotation returns the current rotation
@@ -230,18 +247,21 @@ func (n *Node) Rotation() math32.Vector3 {
 func (n *Node) SetQuaternion(x, y, z, w float32) {
 
 	n.quaternion.Set(x, y, z, w)
+	n.matrixDirty = true
 }
 
 // SetQuaternionQuat sets this node quaternion from the specified quaternion pointer
 func (n *Node) SetQuaternionQuat(q *math32.Quaternion) {
 
 	n.quaternion = *q
+	n.matrixDirty = true
 }
 
 // QuaternionMult multiplies the quaternion by the specified quaternion
 func (n *Node) QuaternionMult(q *math32.Quaternion) {
 
 	n.quaternion.Multiply(q)
+	n.matrixDirty = true
 }
 
 // Quaternion returns the current quaternion
@@ -254,30 +274,35 @@ func (n *Node) Quaternion() math32.Quaternion {
 func (n *Node) SetScale(x, y, z float32) {
 
 	n.scale.Set(x, y, z)
+	n.matrixDirty = true
 }
 
 // SetScaleVec sets this node scale from a pointer to a Vector3
 func (n *Node) SetScaleVec(scale *math32.Vector3) {
 
 	n.scale = *scale
+	n.matrixDirty = true
 }
 
 // SetScaleX sets the X scale of this node
 func (n *Node) SetScaleX(sx float32) {
 
 	n.scale.X = sx
+	n.matrixDirty = true
 }
 
 // SetScaleY sets the Y scale of this node
 func (n *Node) SetScaleY(sy float32) {
 
 	n.scale.Y = sy
+	n.matrixDirty = true
 }
 
 // SetScaleZ sets the Z scale of this node
 func (n *Node) SetScaleZ(sz float32) {
 
 	n.scale.Z = sz
+	n.matrixDirty = true
 }
 
 // Scale returns the current scale
@@ -304,10 +329,13 @@ func (n *Node) Direction() math32.Vector3 {
 	return n.direction
 }
 
-// SetMatrix sets this node local transformation matrix
+// SetMatrix sets this node local transformation matrix, which is used
+// until this node position, rotation or scale is changed
 func (n *Node) SetMatrix(m *math32.Matrix4) {
 
 	n.matrix = *m
+	n.matrixDirty = false
+	n.worldDirty = true
 }
 
 // Matrix returns a copy of this node local transformation matrix
@@ -328,11 +356,11 @@ func (n *Node) Visible() bool {
 	return n.visible
 }
 
-// WorldPosition updates this node world matrix and gets
+// WorldPosition updates the world matrix of this node and gets
 // the current world position vector.
 func (n *Node) WorldPosition(result *math32.Vector3) {
 
-	n.UpdateMatrixWorld()
+	n.UpdateWorldMatrix()
 	result.SetFromMatrixPosition(&n.matrixWorld)
 }
 
@@ -342,7 +370,7 @@ func (n *Node) WorldQuaternion(result *math32.Quaternion) {
 
 	var position math32.Vector3
 	var scale math32.Vector3
-	n.UpdateMatrixWorld()
+	n.UpdateWorldMatrix()
 	n.matrixWorld.Decompose(&position, result, &scale)
 }
 
@@ -361,7 +389,7 @@ func (n *Node) WorldScale(result *math32.Vector3) {
 
 	var position math32.Vector3
 	var quaternion math32.Quaternion
-	n.UpdateMatrixWorld()
+	n.UpdateWorldMatrix()
 	n.matrixWorld.Decompose(&position, &quaternion, result)
 }
 
@@ -386,22 +414,58 @@ func (n *Node) MatrixWorld() math32.Matrix4 {
 func (n *Node) UpdateMatrix() {
 
 	n.matrix.Compose(&n.position, &n.quaternion, &n.scale)
+	n.matrixDirty = false
+	n.worldDirty = true
 }
 
-// UpdateMatrixWorld updates this node world transform matrix and of all its children
+// UpdateMatrixWorld updates this node world transform matrix and of all its children.
+// Only the matrices of the nodes whose transform or parent world matrix changed
+// since the previous update are computed.
 func (n *Node) UpdateMatrixWorld() {
 
-	n.UpdateMatrix()
+	n.updateWorld()
+	// Update this Node children matrices
+	for _, ichild := range n.children {
+		ichild.UpdateMatrixWorld()
+	}
+}
+
+// UpdateWorldMatrix updates the world matrix of this node and of its parents,
+// but not of its children, for queries of the world transform of this
+// node out of the update of the whole scene before rendering.
+func (n *Node) UpdateWorldMatrix() {
+
+	if n.parent != nil {
+		n.parent.GetNode().UpdateWorldMatrix()
+	}
+	n.updateWorld()
+}
+
+// updateWorld updates the world matrix of this node if its transform
+// changed or its parent world matrix was updated since its last update.
+// The parent world matrix must be already updated.
+func (n *Node) updateWorld() {
+
+	if n.matrixDirty {
+		n.UpdateMatrix()
+	}
+	var pstamp uint64
+	if n.parent != nil {
+		pstamp = n.parent.GetNode().worldStamp
+	}
+	if !n.worldDirty && pstamp == n.parentStamp {
+		return
+	}
 	if n.parent == nil {
 		n.matrixWorld = n.matrix
 	} else {
 		parent := n.parent.GetNode()
 		n.matrixWorld.MultiplyMatrices(&parent.matrixWorld, &n.matrix)
 	}
-	// Update this Node children matrices
-	for _, ichild := range n.children {
-		ichild.UpdateMatrixWorld()
-	}
+	n.worldDirty = false
+	n.parentStamp = pstamp
+	worldStamps++
+	n.worldStamp = worldStamps
 }
 
 // SetParent sets this node parent
@@ -437,6 +501,7 @@ func (n *Node) Add(ichild INode) *Node {
 	}
 	child.parent = n
 	n.children = append(n.children, ichild)
+	n.childAdded(ichild)
 	return n
 }
 
@@ -450,6 +515,7 @@ func (n *Node) Remove(ichild INode) bool {
 			n.children[len(n.children)-1] = nil
 			n.children = n.children[:len(n.children)-1]
 			ichild.GetNode().parent = nil
+			n.childRemoved(ichild)
 			return true
 		}
 	}
@@ -462,6 +528,7 @@ func (n *Node) RemoveAll(recurs bool) {
 	for pos, ichild := range n.children {
 		n.children[pos] = nil
 		ichild.GetNode().parent = nil
+		n.childRemoved(ichild)
 		if recurs {
 			ichild.GetNode().RemoveAll(recurs)
 		}
@@ -476,6 +543,7 @@ func (n *Node) DisposeChildren(recurs bool) {
 	for pos, ichild := range n.children {
 		n.children[pos] = nil
 		ichild.GetNode().parent = nil
+		n.childRemoved(ichild)
 		if recurs {
 			ichild.GetNode().DisposeChildren(true)
 		}
@@ -0,0 +1,524 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/math32/intersect"
+	"sort"
+)
+
+// Octree is a dynamic spatial index of nodes by their world bounds.
+// It finds the nodes inside a camera frustum, hit by a ray, inside a box or
+// sphere or nearest to a point without checking all the nodes of a scene,
+// as needed for culling, picking and gameplay queries.
+// The bounds of a node are the world bounding sphere of its local bounding
+// box if it is an IBounded node or else its world position.
+// The nodes are kept in the smallest octant which contains their bounds and
+// the nodes outside the bounds of the octree are kept in its root octant.
+type Octree struct {
+	root     *octant                // root octant with the bounds of the octree
+	maxDepth int                    // maximum depth of the octants
+	maxItems int                    // number of nodes of a leaf octant above which it is split
+	entries  map[INode]*octreeEntry // entries of the indexed nodes
+}
+
+// octant is a node of the octree with a box which contains
+// the bounds of its entries and of its children entries
+type octant struct {
+	box      math32.Box3    // bounds of the octant
+	parent   *octant        // parent octant or nil for the root
+	children []*octant      // eight children octants or nil for leaves
+	entries  []*octreeEntry // entries which don't fit in a child octant
+	count    int            // number of entries of this octant and its descendants
+	depth    int            // depth of this octant, 0 for the root
+}
+
+// octreeEntry contains the cached world bounds of an indexed node
+type octreeEntry struct {
+	inode  INode         // indexed node
+	valid  bool          // bounds were calculated
+	stamp  uint64        // world matrix stamp of the node used to calculate the bounds
+	local  math32.Box3   // local box of the node used to calculate the bounds
+	sphere math32.Sphere // world bounding sphere of the node
+	box    math32.Box3   // box of the world bounding sphere
+	octant *octant       // octant which contains this entry
+}
+
+// octreeHit is a node intersected by a ray at a distance
+type octreeHit struct {
+	distance float32
+	inode    INode
+}
+
+// NewOctree creates and returns a pointer to a new empty octree with the
+// specified bounds, which should contain the bounds of most of its nodes.
+func NewOctree(bounds *math32.Box3) *Octree {
+
+	o := new(Octree)
+	o.root = &octant{box: *bounds}
+	o.maxDepth = 8
+	o.maxItems = 8
+	o.entries = make(map[INode]*octreeEntry)
+	return o
+}
+
+// SetLimits sets the maximum depth of the octants of this octree and the
+// number of nodes of a leaf octant above which it is split into eight children.
+// The default values are 8 and 8. They are used by the next changes of the octree.
+func (o *Octree) SetLimits(maxDepth, maxItems int) {
+
+	o.maxDepth = maxDepth
+	o.maxItems = maxItems
+}
+
+// Bounds returns the bounds of this octree
+func (o *Octree) Bounds() math32.Box3 {
+
+	return o.root.box
+}
+
+// Len returns the number of nodes in this octree
+func (o *Octree) Len() int {
+
+	return len(o.entries)
+}
+
+// Has returns if the specified node is in this octree
+func (o *Octree) Has(inode INode) bool {
+
+	_, ok := o.entries[inode]
+	return ok
+}
+
+// Add adds the specified node to this octree with its current world bounds.
+// If the node is already in the octree its bounds are updated.
+func (o *Octree) Add(inode INode) {
+
+	if o.entries[inode] != nil {
+		o.UpdateNode(inode)
+		return
+	}
+	e := &octreeEntry{inode: inode}
+	e.update()
+	o.entries[inode] = e
+	o.insert(e)
+}
+
+// Remove removes the specified node from this octree.
+// Returns true if found or false otherwise
+func (o *Octree) Remove(inode INode) bool {
+
+	e := o.entries[inode]
+	if e == nil {
+		return false
+	}
+	o.remove(e)
+	delete(o.entries, inode)
+	return true
+}
+
+// Clear removes all the nodes from this octree
+func (o *Octree) Clear() {
+
+	o.root = &octant{box: o.root.box}
+	o.entries = make(map[INode]*octreeEntry)
+}
+
+// UpdateNode updates the world bounds of the specified node in this octree
+// and moves it to another octant if needed.
+// Returns false if the node is not in the octree.
+func (o *Octree) UpdateNode(inode INode) bool {
+
+	e := o.entries[inode]
+	if e == nil {
+		return false
+	}
+	if e.update() {
+		o.relocate(e)
+	}
+	return true
+}
+
+// Update updates the world bounds of all the nodes of this octree.
+// It is normally called once for each frame after the nodes were moved.
+// Only the nodes whose world matrix or local bounds changed are moved.
+func (o *Octree) Update() {
+
+	for _, e := range o.entries {
+		if e.update() {
+			o.relocate(e)
+		}
+	}
+}
+
+// QueryFrustum appends to the specified slice the nodes whose
+// bounds intersect the specified frustum and returns it
+func (o *Octree) QueryFrustum(frustum *math32.Frustum, result []INode) []INode {
+
+	return o.root.query(
+		func(box *math32.Box3) math32.Containment {
+			return frustum.ClassifyBox(box)
+		},
+		func(e *octreeEntry) bool {
+			return frustum.IntersectsSphere(&e.sphere)
+		},
+		result)
+}
+
+// QueryBox appends to the specified slice the nodes whose
+// bounds intersect the specified box and returns it
+func (o *Octree) QueryBox(box *math32.Box3, result []INode) []INode {
+
+	return o.root.query(
+		func(obox *math32.Box3) math32.Containment {
+			if !box.IsIntersectionBox(obox) {
+				return math32.Outside
+			}
+			if box.ContainsBox(obox) {
+				return math32.Inside
+			}
+			return math32.Intersects
+		},
+		func(e *octreeEntry) bool {
+			return box.DistanceToPoint(&e.sphere.Center) <= e.sphere.Radius
+		},
+		result)
+}
+
+// QuerySphere appends to the specified slice the nodes whose bounds
+// intersect the sphere with the specified center and radius and returns it
+func (o *Octree) QuerySphere(center *math32.Vector3, radius float32, result []INode) []INode {
+
+	return o.root.query(
+		func(box *math32.Box3) math32.Containment {
+			if box.DistanceToPoint(center) > radius {
+				return math32.Outside
+			}
+			var far math32.Vector3
+			far.X = math32.Max(center.X-box.Min.X, box.Max.X-center.X)
+			far.Y = math32.Max(center.Y-box.Min.Y, box.Max.Y-center.Y)
+			far.Z = math32.Max(center.Z-box.Min.Z, box.Max.Z-center.Z)
+			if far.Length() <= radius {
+				return math32.Inside
+			}
+			return math32.Intersects
+		},
+		func(e *octreeEntry) bool {
+			return e.distanceTo(center) <= radius
+		},
+		result)
+}
+
+// QueryRay appends to the specified slice the nodes whose bounds are
+// intersected by the specified ray up to the specified distance from its
+// origin, sorted by the distance to their bounds, closest first, and returns it.
+func (o *Octree) QueryRay(ray *math32.Ray, far float32, result []INode) []INode {
+
+	dir := ray.Direction()
+	scale := dir.Length()
+	var hits []octreeHit
+	o.root.queryRay(ray, scale, far, &hits)
+	sort.Slice(hits, func(i, j int) bool {
+		return hits[i].distance < hits[j].distance
+	})
+	for _, hit := range hits {
+		result = append(result, hit.inode)
+	}
+	return result
+}
+
+// Nearest returns the node whose bounds are nearest to the specified point
+// for which the specified filter returns true, or any node if the filter is nil,
+// and the distance from the point to its bounds, which is zero if they contain it.
+// Returns nil and infinity if not found.
+func (o *Octree) Nearest(point *math32.Vector3, filter func(inode INode) bool) (INode, float32) {
+
+	var best INode
+	dist := math32.Inf(1)
+	o.root.nearest(point, filter, &best, &dist)
+	return best, dist
+}
+
+// insert inserts the specified entry in the smallest octant which contains it
+func (o *Octree) insert(e *octreeEntry) {
+
+	oc := o.root
+	for {
+		oc.count++
+		child := oc.childFor(&e.box)
+		if child == nil {
+			break
+		}
+		oc = child
+	}
+	oc.entries = append(oc.entries, e)
+	e.octant = oc
+	if oc.children == nil && len(oc.entries) > o.maxItems && oc.depth < o.maxDepth {
+		o.split(oc)
+	}
+}
+
+// remove removes the specified entry from its octant and merges
+// the octants which became too sparse with their children
+func (o *Octree) remove(e *octreeEntry) {
+
+	oc := e.octant
+	for pos, current := range oc.entries {
+		if current == e {
+			last := len(oc.entries) - 1
+			oc.entries[pos] = oc.entries[last]
+			oc.entries[last] = nil
+			oc.entries = oc.entries[:last]
+			break
+		}
+	}
+	e.octant = nil
+	for p := oc; p != nil; p = p.parent {
+		p.count--
+	}
+	// Merges at half the split threshold to avoid splitting it again soon
+	for p := oc; p != nil; p = p.parent {
+		if p.children != nil && p.count <= o.maxItems/2 {
+			p.merge()
+		}
+	}
+}
+
+// relocate moves the specified entry, whose bounds changed,
+// to the smallest octant which contains it if needed
+func (o *Octree) relocate(e *octreeEntry) {
+
+	oc := e.octant
+	if oc.childFor(&e.box) == nil && (oc.parent == nil || oc.box.ContainsBox(&e.box)) {
+		return
+	}
+	o.remove(e)
+	o.insert(e)
+}
+
+// split creates the children of the specified leaf octant and
+// moves to them the entries which fit in them
+func (o *Octree) split(oc *octant) {
+
+	center := oc.box.Center(nil)
+	oc.children = make([]*octant, 8)
+	for i := range oc.children {
+		child := &octant{parent: oc, depth: oc.depth + 1}
+		child.box = oc.box
+		if i&1 == 0 {
+			child.box.Max.X = center.X
+		} else {
+			child.box.Min.X = center.X
+		}
+		if i&2 == 0 {
+			child.box.Max.Y = center.Y
+		} else {
+			child.box.Min.Y = center.Y
+		}
+		if i&4 == 0 {
+			child.box.Max.Z = center.Z
+		} else {
+			child.box.Min.Z = center.Z
+		}
+		oc.children[i] = child
+	}
+	entries := oc.entries
+	oc.entries = nil
+	for _, e := range entries {
+		child := oc.childFor(&e.box)
+		if child == nil {
+			oc.entries = append(oc.entries, e)
+			continue
+		}
+		child.entries = append(child.entries, e)
+		child.count++
+		e.octant = child
+	}
+	for _, child := range oc.children {
+		if len(child.entries) > o.maxItems && child.depth < o.maxDepth {
+			o.split(child)
+		}
+	}
+}
+
+// merge moves the entries of the descendants of this octant to it and removes its children
+func (oc *octant) merge() {
+
+	for _, child := range oc.children {
+		child.merge()
+		for _, e := range child.entries {
+			e.octant = oc
+		}
+		oc.entries = append(oc.entries, child.entries...)
+	}
+	oc.children = nil
+}
+
+// childFor returns the child of this octant which contains
+// the specified box or nil if there is none
+func (oc *octant) childFor(box *math32.Box3) *octant {
+
+	if oc.children == nil || !oc.box.ContainsBox(box) {
+		return nil
+	}
+	center := oc.box.Center(nil)
+	idx := 0
+	if box.Min.X >= center.X {
+		idx |= 1
+	} else if box.Max.X > center.X {
+		return nil
+	}
+	if box.Min.Y >= center.Y {
+		idx |= 2
+	} else if box.Max.Y > center.Y {
+		return nil
+	}
+	if box.Min.Z >= center.Z {
+		idx |= 4
+	} else if box.Max.Z > center.Z {
+		return nil
+	}
+	return oc.children[idx]
+}
+
+// query appends to the specified slice the nodes of the entries of this
+// octant and its descendants for which test returns true and returns it.
+// The children octants classified as outside are skipped and all the
+// nodes of the children octants classified as inside are appended.
+func (oc *octant) query(classify func(box *math32.Box3) math32.Containment, test func(e *octreeEntry) bool, result []INode) []INode {
+
+	for _, e := range oc.entries {
+		if test(e) {
+			result = append(result, e.inode)
+		}
+	}
+	for _, child := range oc.children {
+		if child.count == 0 {
+			continue
+		}
+		switch classify(&child.box) {
+		case math32.Outside:
+		case math32.Inside:
+			result = child.appendAll(result)
+		default:
+			result = child.query(classify, test, result)
+		}
+	}
+	return result
+}
+
+// appendAll appends to the specified slice the nodes of
+// this octant and of its descendants and returns it
+func (oc *octant) appendAll(result []INode) []INode {
+
+	for _, e := range oc.entries {
+		result = append(result, e.inode)
+	}
+	for _, child := range oc.children {
+		if child.count > 0 {
+			result = child.appendAll(result)
+		}
+	}
+	return result
+}
+
+// queryRay appends to the specified hits the entries of this octant and its
+// descendants intersected by the specified ray up to the specified distance.
+// The scale is the length of the direction of the ray.
+func (oc *octant) queryRay(ray *math32.Ray, scale, far float32, hits *[]octreeHit) {
+
+	for _, e := range oc.entries {
+		t, ok := intersect.RaySphere(ray, &e.sphere)
+		if ok && t*scale <= far {
+			*hits = append(*hits, octreeHit{t * scale, e.inode})
+		}
+	}
+	for _, child := range oc.children {
+		if child.count == 0 {
+			continue
+		}
+		t, ok := intersect.RayAABB(ray, &child.box)
+		if ok && t*scale <= far {
+			child.queryRay(ray, scale, far, hits)
+		}
+	}
+}
+
+// nearest updates the specified best node and its distance with the nearest
+// node to the specified point of this octant and its descendants accepted by
+// the filter. The children octants are visited from the closest to the point
+// and those farther than the best distance are skipped.
+func (oc *octant) nearest(point *math32.Vector3, filter func(inode INode) bool, best *INode, dist *float32) {
+
+	for _, e := range oc.entries {
+		d := e.distanceTo(point)
+		if d < *dist && (filter == nil || filter(e.inode)) {
+			*best = e.inode
+			*dist = d
+		}
+	}
+	if oc.children == nil {
+		return
+	}
+	// Sorts the children with entries by their distance to the point
+	var order [8]octreeHit
+	var octants [8]*octant
+	count := 0
+	for _, child := range oc.children {
+		if child.count == 0 {
+			continue
+		}
+		d := child.box.DistanceToPoint(point)
+		pos := count
+		for pos > 0 && order[pos-1].distance > d {
+			order[pos] = order[pos-1]
+			octants[pos] = octants[pos-1]
+			pos--
+		}
+		order[pos].distance = d
+		octants[pos] = child
+		count++
+	}
+	for i := 0; i < count; i++ {
+		if order[i].distance >= *dist {
+			break
+		}
+		octants[i].nearest(point, filter, best, dist)
+	}
+}
+
+// update calculates the world bounds of the node of this entry.
+// Returns false if its world matrix and local bounds didn't change
+// since they were last calculated.
+func (e *octreeEntry) update() bool {
+
+	n := e.inode.GetNode()
+	n.UpdateWorldMatrix()
+	var local math32.Box3
+	if ib, ok := e.inode.(IBounded); ok {
+		box, ok := ib.LocalBoundingBox()
+		if ok {
+			local = box
+		}
+	}
+	if e.valid && e.stamp == n.worldStamp && e.local == local {
+		return false
+	}
+	local.GetBoundingSphere(&e.sphere)
+	e.sphere.ApplyMatrix4(&n.matrixWorld)
+	e.sphere.GetBoundingBox(&e.box)
+	e.stamp = n.worldStamp
+	e.local = local
+	e.valid = true
+	return true
+}
+
+// distanceTo returns the distance from the specified point to the
+// bounds of this entry, which is zero if they contain the point
+func (e *octreeEntry) distanceTo(point *math32.Vector3) float32 {
+
+	return math32.Max(0, e.sphere.Center.DistanceTo(point)-e.sphere.Radius)
+}
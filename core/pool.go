@@ -0,0 +1,219 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+// Pool keeps released objects to reuse them instead of allocating new ones,
+// avoiding the garbage collection pressure of objects frequently created and
+// discarded, as bullets, particles and rows of lists.
+// Unlike sync.Pool the objects are never dropped by the garbage collector,
+// so they can own resources which must be released, as OpenGL objects.
+type Pool struct {
+	idle    []interface{}         // released objects ready to be reused
+	create  func() interface{}    // creates a new object
+	reset   func(obj interface{}) // resets a released object or nil
+	dispose func(obj interface{}) // disposes an object dropped by the pool or nil
+	max     int                   // maximum number of idle objects or 0 for no limit
+	created int                   // number of objects created
+}
+
+// NewPool creates and returns a pointer to a new pool which
+// creates its objects with the specified function
+func NewPool(create func() interface{}) *Pool {
+
+	p := new(Pool)
+	p.Init(create)
+	return p
+}
+
+// Init initializes this pool with the specified function to create its objects.
+// It is used by other pool types which embed this pool.
+func (p *Pool) Init(create func() interface{}) {
+
+	p.idle = nil
+	p.create = create
+	p.created = 0
+}
+
+// SetReset sets the function called for the objects released to this
+// pool to reset their state before they are reused
+func (p *Pool) SetReset(reset func(obj interface{})) {
+
+	p.reset = reset
+}
+
+// SetDispose sets the function called for the objects dropped by this
+// pool, when it is full or cleared, to release their resources
+func (p *Pool) SetDispose(dispose func(obj interface{})) {
+
+	p.dispose = dispose
+}
+
+// SetMax sets the maximum number of idle objects kept by this pool.
+// The objects released to a full pool are disposed. 0 means no limit.
+func (p *Pool) SetMax(max int) {
+
+	p.max = max
+	p.trim()
+}
+
+// Max returns the maximum number of idle objects kept by this pool
+func (p *Pool) Max() int {
+
+	return p.max
+}
+
+// Get returns an idle object of this pool or a new object if there are none
+func (p *Pool) Get() interface{} {
+
+	if len(p.idle) == 0 {
+		p.created++
+		return p.create()
+	}
+	last := len(p.idle) - 1
+	obj := p.idle[last]
+	p.idle[last] = nil
+	p.idle = p.idle[:last]
+	return obj
+}
+
+// Put releases the specified object to this pool to be reused.
+// The object must not be used after it is released.
+func (p *Pool) Put(obj interface{}) {
+
+	if p.reset != nil {
+		p.reset(obj)
+	}
+	if p.max > 0 && len(p.idle) >= p.max {
+		if p.dispose != nil {
+			p.dispose(obj)
+		}
+		return
+	}
+	p.idle = append(p.idle, obj)
+}
+
+// Prewarm creates objects until this pool has the specified number of
+// idle objects, so they are not allocated while the application runs
+func (p *Pool) Prewarm(count int) {
+
+	for len(p.idle) < count && (p.max == 0 || len(p.idle) < p.max) {
+		p.created++
+		obj := p.create()
+		if p.reset != nil {
+			p.reset(obj)
+		}
+		p.idle = append(p.idle, obj)
+	}
+}
+
+// Idle returns the number of idle objects of this pool
+func (p *Pool) Idle() int {
+
+	return len(p.idle)
+}
+
+// Created returns the number of objects created by this pool
+func (p *Pool) Created() int {
+
+	return p.created
+}
+
+// Clear disposes all the idle objects of this pool
+func (p *Pool) Clear() {
+
+	max := p.max
+	p.max = -1
+	p.trim()
+	p.max = max
+}
+
+// trim disposes the idle objects above the maximum of this pool
+func (p *Pool) trim() {
+
+	if p.max == 0 {
+		return
+	}
+	keep := p.max
+	if keep < 0 {
+		keep = 0
+	}
+	for i := keep; i < len(p.idle); i++ {
+		if p.dispose != nil {
+			p.dispose(p.idle[i])
+		}
+		p.idle[i] = nil
+	}
+	if keep < len(p.idle) {
+		p.idle = p.idle[:keep]
+	}
+}
+
+// NodePool is a pool of nodes, as meshes or sprites, which are detached from
+// their scene when released and attached to a parent when reused, so the
+// scene events and the component hooks run as for new nodes.
+// The released nodes are not disposed, so the OpenGL objects of their
+// geometries and materials are reused instead of allocated again.
+// By default the transform of the released nodes is reset and they are
+// made visible. The nodes dropped by a full pool are disposed.
+type NodePool struct {
+	Pool                  // Embedded pool
+	resetNode func(INode) // resets a released node
+}
+
+// NewNodePool creates and returns a pointer to a new pool of
+// nodes which creates its nodes with the specified function
+func NewNodePool(create func() INode) *NodePool {
+
+	p := new(NodePool)
+	p.Pool.Init(func() interface{} { return create() })
+	p.resetNode = ResetNode
+	p.Pool.SetReset(func(obj interface{}) {
+		inode := obj.(INode)
+		if parent := inode.GetNode().Parent(); parent != nil {
+			parent.GetNode().Remove(inode)
+		}
+		if p.resetNode != nil {
+			p.resetNode(inode)
+		}
+	})
+	p.Pool.SetDispose(func(obj interface{}) { obj.(INode).Dispose() })
+	return p
+}
+
+// SetResetNode sets the function which resets the nodes released to this pool,
+// after they are removed from their parent. The default is ResetNode.
+func (p *NodePool) SetResetNode(reset func(INode)) {
+
+	p.resetNode = reset
+}
+
+// Get returns an idle node of this pool or a new node and adds it to
+// the specified parent, if not nil, attaching it to the parent scene
+func (p *NodePool) Get(parent INode) INode {
+
+	inode := p.Pool.Get().(INode)
+	if parent != nil {
+		parent.GetNode().Add(inode)
+	}
+	return inode
+}
+
+// Put removes the specified node from its parent, detaching it from
+// its scene, and releases it to this pool to be reused
+func (p *NodePool) Put(inode INode) {
+
+	p.Pool.Put(inode)
+}
+
+// ResetNode resets the transform of the specified node
+// to the identity and makes it visible
+func ResetNode(inode INode) {
+
+	n := inode.GetNode()
+	n.SetPosition(0, 0, 0)
+	n.SetQuaternion(0, 0, 0, 1)
+	n.SetScale(1, 1, 1)
+	n.SetVisible(true)
+}
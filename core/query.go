@@ -0,0 +1,178 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"strings"
+)
+
+// Traverse calls the specified function for this node and all its children,
+// with the parents before their children, until the function returns false
+func (n *Node) Traverse(cb func(inode INode) bool) {
+
+	n.traverse(n, cb)
+}
+
+// traverse calls the specified function for the specified node, whose
+// embedded Node is this node, and all its children until the function
+// returns false. Returns false if the traversal was stopped.
+func (n *Node) traverse(inode INode, cb func(inode INode) bool) bool {
+
+	if !cb(inode) {
+		return false
+	}
+	for i := 0; i < len(n.children); i++ {
+		ichild := n.children[i]
+		if !ichild.GetNode().traverse(ichild, cb) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the first of this node and all its children for which
+// the specified predicate returns true or nil if not found
+func (n *Node) Find(pred func(inode INode) bool) INode {
+
+	var found INode
+	n.Traverse(func(inode INode) bool {
+		if pred(inode) {
+			found = inode
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// FindAll returns all of this node and its children for
+// which the specified predicate returns true
+func (n *Node) FindAll(pred func(inode INode) bool) []INode {
+
+	var found []INode
+	n.Traverse(func(inode INode) bool {
+		if pred(inode) {
+			found = append(found, inode)
+		}
+		return true
+	})
+	return found
+}
+
+// FindByName looks in this node and all its children for
+// a node with the specified name and if found returns it.
+// Returns nil if not found
+func (n *Node) FindByName(name string) INode {
+
+	return n.Find(func(inode INode) bool {
+		return inode.GetNode().name == name
+	})
+}
+
+// FindPath returns the node with the specified path or nil if not found.
+// The path contains the names of the nodes separated by slashes, as
+// "/level/enemies/boss", where each name selects the first child with the
+// name and the names "." and ".." select the node and its parent.
+// The paths starting with a slash are from the root of the hierarchy of
+// this node and the others are from this node.
+func (n *Node) FindPath(path string) INode {
+
+	var current INode = n
+	if strings.HasPrefix(path, "/") {
+		for current.GetNode().parent != nil {
+			current = current.GetNode().parent
+		}
+	}
+	for _, name := range strings.Split(path, "/") {
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			current = current.GetNode().parent
+		default:
+			current = current.GetNode().child(name)
+		}
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// child returns the first child of this node with the specified name or nil if not found
+func (n *Node) child(name string) INode {
+
+	for _, ichild := range n.children {
+		if ichild.GetNode().name == name {
+			return ichild
+		}
+	}
+	return nil
+}
+
+// Path returns the path of this node from the root of its hierarchy,
+// with the names of the nodes after the root each preceded by a slash
+func (n *Node) Path() string {
+
+	var names []string
+	for node := n; node.parent != nil; node = node.parent.GetNode() {
+		names = append(names, node.name)
+	}
+	var sb strings.Builder
+	for i := len(names) - 1; i >= 0; i-- {
+		sb.WriteString("/")
+		sb.WriteString(names[i])
+	}
+	return sb.String()
+}
+
+// AddTag adds the specified tags to this node and returns a pointer to this node
+func (n *Node) AddTag(tags ...string) *Node {
+
+	for _, tag := range tags {
+		if !n.HasTag(tag) {
+			n.tags = append(n.tags, tag)
+		}
+	}
+	return n
+}
+
+// RemoveTag removes the specified tag from this node.
+// Returns true if found or false otherwise
+func (n *Node) RemoveTag(tag string) bool {
+
+	for pos, current := range n.tags {
+		if current == tag {
+			n.tags = append(n.tags[:pos], n.tags[pos+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag returns if this node has the specified tag
+func (n *Node) HasTag(tag string) bool {
+
+	for _, current := range n.tags {
+		if current == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags returns the list of the tags of this node in the order they were added
+func (n *Node) Tags() []string {
+
+	return n.tags
+}
+
+// FindByTag returns all of this node and its children with the specified tag
+func (n *Node) FindByTag(tag string) []INode {
+
+	return n.FindAll(func(inode INode) bool {
+		return inode.GetNode().HasTag(tag)
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"sync"
+)
+
+// EventQueue is a thread safe queue of events which are posted by any
+// goroutine, as the workers loading resources, and dispatched by the
+// goroutine of the application loop
+type EventQueue struct {
+	mutex  sync.Mutex
+	events []queuedEvent // events posted
+	spare  []queuedEvent // buffer reused by the next dispatch
+}
+
+// queuedEvent is an event posted to a queue with its target dispatcher
+type queuedEvent struct {
+	target IDispatcher
+	evname string
+	ev     interface{}
+}
+
+// defaultQueue is the event queue of the application loop
+var defaultQueue = NewEventQueue()
+
+// NewEventQueue creates and returns a pointer to a new empty event queue
+func NewEventQueue() *EventQueue {
+
+	return new(EventQueue)
+}
+
+// Post adds the specified event and data to this queue to be dispatched
+// later by the specified dispatcher. It may be called from any goroutine.
+func (q *EventQueue) Post(target IDispatcher, evname string, ev interface{}) {
+
+	q.mutex.Lock()
+	q.events = append(q.events, queuedEvent{target, evname, ev})
+	q.mutex.Unlock()
+}
+
+// Len returns the number of events waiting in this queue
+func (q *EventQueue) Len() int {
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.events)
+}
+
+// Dispatch dispatches the events waiting in this queue in the order they were
+// posted and returns their number. The events posted while dispatching are
+// dispatched by the next call. It must be called from a single goroutine,
+// normally once for each frame by the application loop.
+func (q *EventQueue) Dispatch() int {
+
+	q.mutex.Lock()
+	events := q.events
+	q.events = q.spare[:0]
+	q.mutex.Unlock()
+
+	for i := range events {
+		events[i].target.Dispatch(events[i].evname, events[i].ev)
+		events[i] = queuedEvent{}
+	}
+
+	q.mutex.Lock()
+	q.spare = events[:0]
+	q.mutex.Unlock()
+	return len(events)
+}
+
+// PostEvent adds the specified event and data to the event queue of the
+// application loop to be dispatched later by the specified dispatcher.
+// It may be called from any goroutine.
+func PostEvent(target IDispatcher, evname string, ev interface{}) {
+
+	defaultQueue.Post(target, evname, ev)
+}
+
+// DispatchEvents dispatches the events waiting in the event queue of the
+// application loop and returns their number. It is normally called once
+// for each frame by the application loop.
+func DispatchEvents() int {
+
+	return defaultQueue.Dispatch()
+}
@@ -49,6 +49,22 @@ type Intersect struct {
 	// index in the positions buffer of the vertex intersected
 	// or the first vertex of the insersected face.
 	Index uint32
+	// Indices of the vertices of the intersected face
+	Face [3]uint32
+	// Barycentric coordinates of the intersection point in the intersected
+	// face, which are the weights of its vertices to interpolate their attributes
+	Barycentric math32.Vector3
+	// Texture coordinates of the intersection point if HasUV is true
+	UV math32.Vector2
+	// Normal in world coordinates of the intersected surface at the
+	// intersection point, interpolated from the vertex normals if the
+	// geometry has them or the normal of the face otherwise
+	Normal math32.Vector3
+	// If the geometry of the intersected face has texture coordinates
+	HasUV bool
+	// Index of the intersected instance of graphics
+	// with several instances or 0 otherwise
+	Instance int
 }
 
 // New creates and returns a pointer to a new raycaster object
@@ -90,6 +106,14 @@ func (rc *Raycaster) IntersectObjects(inodes []INode, recursive bool) []Intersec
 	return intersects
 }
 
+// IntersectOctree checks intersections between this raycaster and the nodes
+// of the specified octree whose bounds are intersected by its ray, without
+// their children. Intersections are returned sorted by distance, closest first.
+func (rc *Raycaster) IntersectOctree(o *Octree) []Intersect {
+
+	return rc.IntersectObjects(o.QueryRay(&rc.Ray, rc.Far, nil), false)
+}
+
 func (rc *Raycaster) intersectObject(inode INode, intersects *[]Intersect, recursive bool) {
 
 	node := inode.GetNode()
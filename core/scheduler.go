@@ -0,0 +1,405 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"runtime"
+)
+
+// Scheduler runs timers and coroutines driven by the updates of the
+// application loop, in the time of the application which advances only
+// when it is updated, so they are paused with it.
+// Coroutines are functions which wait for some time, some frames or
+// a condition between their steps, as scripted sequences, without
+// state machines. The function of a coroutine runs in its own goroutine,
+// but only one coroutine runs at a time and only while the goroutine which
+// updates the scheduler waits for it, so they can change the scene as the
+// components. They must not call OpenGL or the window, which are bound to
+// the locked thread of the application loop, except through Coroutine.Call.
+type Scheduler struct {
+	state      *schedState   // state shared with the coroutines
+	nextID     int           // next timer id
+	timers     []*schedTimer // active timers in the order they were set
+	coroutines []*Coroutine  // running coroutines in the order they were started
+}
+
+// schedState is the state of a scheduler shared with its coroutines,
+// which do not reference the scheduler so it may be garbage collected
+// while their goroutines wait
+type schedState struct {
+	time    float64    // time in seconds of all the updates
+	frame   uint64     // number of updates
+	current *Coroutine // coroutine whose function is running or nil
+}
+
+// schedTimer is a timer of a scheduler
+type schedTimer struct {
+	id     int     // timer id or 0 if cancelled
+	due    float64 // scheduler time when the timer expires
+	period float64 // period of periodic timers
+	repeat bool    // periodic timer
+	cb     func()  // callback function
+}
+
+// Coroutine is a function run by a scheduler which may wait between its steps
+// by calling the Wait methods of the Coroutine passed to it.
+type Coroutine struct {
+	state   *schedState   // state of the scheduler
+	resume  chan bool     // resumes the coroutine or stops it if false
+	yield   chan struct{} // signals the coroutine waits, calls or finished
+	cond    func() bool   // condition to resume the coroutine
+	call    func()        // function to call in the goroutine of the scheduler or nil
+	running bool          // the coroutine is running a step
+	calling bool          // the coroutine waits for the call of a function
+	stopped bool          // the coroutine was stopped while running
+	done    bool          // the coroutine finished or was stopped
+	panic   interface{}   // value of a panic of the coroutine
+}
+
+// defaultScheduler is the scheduler of the application loop
+var defaultScheduler = NewScheduler()
+
+// NewScheduler creates and returns a pointer to a new scheduler.
+// The coroutines still waiting when the scheduler is garbage collected
+// are stopped, unless their functions reference the scheduler.
+func NewScheduler() *Scheduler {
+
+	s := new(Scheduler)
+	s.state = new(schedState)
+	s.nextID = 1
+	runtime.SetFinalizer(s, (*Scheduler).Clear)
+	return s
+}
+
+// Time returns the time in seconds of all the updates of this scheduler
+func (s *Scheduler) Time() float64 {
+
+	return s.state.time
+}
+
+// Frame returns the number of updates of this scheduler
+func (s *Scheduler) Frame() uint64 {
+
+	return s.state.frame
+}
+
+// After sets a timer which calls the specified function once after
+// the specified delay in seconds and returns its id to cancel it
+func (s *Scheduler) After(delay float32, cb func()) int {
+
+	return s.setTimer(delay, 0, false, cb)
+}
+
+// Every sets a timer which calls the specified function periodically with
+// the specified period in seconds and returns its id to cancel it.
+// A period not greater than zero calls the function at each update.
+func (s *Scheduler) Every(period float32, cb func()) int {
+
+	if period < 0 {
+		period = 0
+	}
+	return s.setTimer(period, period, true, cb)
+}
+
+// Cancel cancels the timer with the specified id.
+// Returns true if found or false otherwise
+func (s *Scheduler) Cancel(id int) bool {
+
+	for _, t := range s.timers {
+		if t.id == id && id != 0 {
+			t.id = 0
+			return true
+		}
+	}
+	return false
+}
+
+// Start starts a coroutine which runs the specified function. The function
+// runs until its first wait before Start returns and then each time the
+// scheduler is updated and its wait is over, until it returns.
+func (s *Scheduler) Start(fn func(co *Coroutine)) *Coroutine {
+
+	co := &Coroutine{state: s.state}
+	co.resume = make(chan bool)
+	co.yield = make(chan struct{})
+	go co.run(fn)
+	s.coroutines = append(s.coroutines, co)
+	co.step(true)
+	return co
+}
+
+// Update advances the time of this scheduler by the specified seconds,
+// calls the expired timers and resumes the coroutines whose wait is over.
+// It is normally called once for each frame by the application loop.
+func (s *Scheduler) Update(delta float32) {
+
+	st := s.state
+	st.time += float64(delta)
+	st.frame++
+
+	// Calls the expired timers set before this update
+	count := len(s.timers)
+	for i := 0; i < count; i++ {
+		t := s.timers[i]
+		if t.id == 0 || t.due > st.time {
+			continue
+		}
+		if t.repeat {
+			t.due += t.period
+		} else {
+			t.id = 0
+		}
+		t.cb()
+	}
+	pos := 0
+	for _, t := range s.timers {
+		if t.id != 0 {
+			s.timers[pos] = t
+			pos++
+		}
+	}
+	for i := pos; i < len(s.timers); i++ {
+		s.timers[i] = nil
+	}
+	s.timers = s.timers[:pos]
+
+	// Resumes the coroutines started before this update
+	count = len(s.coroutines)
+	for i := 0; i < count; i++ {
+		co := s.coroutines[i]
+		if !co.done && co.cond() {
+			co.step(true)
+		}
+	}
+	pos = 0
+	for _, co := range s.coroutines {
+		if !co.done {
+			s.coroutines[pos] = co
+			pos++
+		}
+	}
+	for i := pos; i < len(s.coroutines); i++ {
+		s.coroutines[i] = nil
+	}
+	s.coroutines = s.coroutines[:pos]
+}
+
+// Clear cancels all the timers and stops all the coroutines of this scheduler.
+// When called from a coroutine, it returns and the coroutines which are
+// running, as the caller, stop at their next wait.
+func (s *Scheduler) Clear() {
+
+	for _, t := range s.timers {
+		t.id = 0
+	}
+	for _, co := range append([]*Coroutine(nil), s.coroutines...) {
+		co.halt()
+	}
+}
+
+// setTimer sets a timer with the specified delay and period
+func (s *Scheduler) setTimer(delay, period float32, repeat bool, cb func()) int {
+
+	t := &schedTimer{id: s.nextID, due: s.state.time + float64(delay), period: float64(period), repeat: repeat, cb: cb}
+	s.nextID++
+	s.timers = append(s.timers, t)
+	return t.id
+}
+
+// WaitSeconds waits until the scheduler time advanced by the specified seconds.
+// It must only be called by the function of this coroutine.
+func (co *Coroutine) WaitSeconds(seconds float32) {
+
+	st := co.state
+	until := st.time + float64(seconds)
+	co.wait(func() bool { return st.time >= until })
+}
+
+// WaitFrames waits for the specified number of updates of the scheduler.
+// It must only be called by the function of this coroutine.
+func (co *Coroutine) WaitFrames(frames int) {
+
+	st := co.state
+	until := st.frame + uint64(frames)
+	co.wait(func() bool { return st.frame >= until })
+}
+
+// WaitUntil waits until the specified function returns true, which is
+// checked once for each update of the scheduler.
+// It must only be called by the function of this coroutine.
+func (co *Coroutine) WaitUntil(cond func() bool) {
+
+	co.wait(cond)
+}
+
+// Yield waits for the next update of the scheduler.
+// It must only be called by the function of this coroutine.
+func (co *Coroutine) Yield() {
+
+	co.WaitFrames(1)
+}
+
+// Call calls the specified function in the goroutine which updates the
+// scheduler, as the locked thread of the application loop, and returns
+// when it returns. It is used by coroutines to call OpenGL or the window.
+// It must only be called by the function of this coroutine.
+func (co *Coroutine) Call(fn func()) {
+
+	co.check("Call")
+	co.call = fn
+	co.yield <- struct{}{}
+	if !<-co.resume || co.stopped {
+		runtime.Goexit()
+	}
+}
+
+// Stop stops this coroutine while it waits. Called from the function of
+// this coroutine, it stops the function as a return. Called from another
+// coroutine which this one started, it stops this coroutine at its next wait.
+func (co *Coroutine) Stop() {
+
+	if co == co.state.current && !co.calling {
+		runtime.Goexit()
+	}
+	co.halt()
+}
+
+// Done returns if this coroutine finished or was stopped
+func (co *Coroutine) Done() bool {
+
+	return co.done
+}
+
+// halt stops this coroutine if it waits or at its next wait if it is running
+func (co *Coroutine) halt() {
+
+	if co.done {
+		return
+	}
+	if co.running {
+		co.stopped = true
+		return
+	}
+	co.step(false)
+}
+
+// run runs the specified function of this coroutine in its goroutine
+func (co *Coroutine) run(fn func(co *Coroutine)) {
+
+	defer func() {
+		co.panic = recover()
+		co.done = true
+		co.running = false
+		co.yield <- struct{}{}
+	}()
+	if !<-co.resume {
+		return
+	}
+	fn(co)
+}
+
+// step resumes this coroutine, or stops it if resume is false, and
+// waits until it waits again or finishes, calling the functions it
+// passes to Call. The panics of the coroutine are raised again in the
+// goroutine of the scheduler.
+func (co *Coroutine) step(resume bool) {
+
+	prev := co.state.current
+	co.state.current = co
+	defer func() { co.state.current = prev }()
+	co.running = true
+	co.resume <- resume
+	<-co.yield
+	for co.call != nil {
+		co.callFunc()
+		<-co.yield
+	}
+	if co.panic != nil {
+		p := co.panic
+		co.panic = nil
+		panic(p)
+	}
+}
+
+// callFunc calls the function passed to Call by this coroutine and resumes
+// it, or stops it if the function panics before the panic is raised again
+func (co *Coroutine) callFunc() {
+
+	fn := co.call
+	co.call = nil
+	co.calling = true
+	ok := false
+	defer func() {
+		co.calling = false
+		co.resume <- ok
+		if !ok {
+			<-co.yield
+			co.panic = nil
+		}
+	}()
+	fn()
+	ok = true
+}
+
+// wait suspends this coroutine until the specified condition is true
+func (co *Coroutine) wait(cond func() bool) {
+
+	co.check("wait")
+	if co.stopped {
+		runtime.Goexit()
+	}
+	co.cond = cond
+	co.running = false
+	co.yield <- struct{}{}
+	if !<-co.resume {
+		runtime.Goexit()
+	}
+}
+
+// check panics if the function of this coroutine is not the one running
+func (co *Coroutine) check(op string) {
+
+	if co != co.state.current || co.calling {
+		panic("core: coroutine " + op + " called outside of its function")
+	}
+}
+
+// After sets a timer of the scheduler of the application loop which calls
+// the specified function once after the specified delay in seconds and
+// returns its id to cancel it
+func After(delay float32, cb func()) int {
+
+	return defaultScheduler.After(delay, cb)
+}
+
+// Every sets a timer of the scheduler of the application loop which calls
+// the specified function periodically with the specified period in seconds
+// and returns its id to cancel it
+func Every(period float32, cb func()) int {
+
+	return defaultScheduler.Every(period, cb)
+}
+
+// CancelTimer cancels the timer of the scheduler of the application loop
+// with the specified id. Returns true if found or false otherwise
+func CancelTimer(id int) bool {
+
+	return defaultScheduler.Cancel(id)
+}
+
+// StartCoroutine starts a coroutine in the scheduler of the
+// application loop which runs the specified function
+func StartCoroutine(fn func(co *Coroutine)) *Coroutine {
+
+	return defaultScheduler.Start(fn)
+}
+
+// UpdateScheduler updates the scheduler of the application loop with the
+// specified seconds elapsed since the previous update. It is normally
+// called once for each frame by the application loop.
+func UpdateScheduler(delta float32) {
+
+	defaultScheduler.Update(delta)
+}
@@ -0,0 +1,273 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serial
+
+import (
+	"fmt"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// registerBuiltins registers the node, material and
+// user data types of the engine in the specified Serializer
+func registerBuiltins(s *Serializer) {
+
+	// Nodes and graphics
+	s.Register("Node", core.NewNode(), nil, func(d *Decoder) (core.INode, error) {
+		return core.NewNode(), nil
+	})
+	s.Register("Mesh", new(graphic.Mesh), nil, func(d *Decoder) (core.INode, error) {
+		igeom, err := graphicGeometry(d)
+		if err != nil {
+			return nil, err
+		}
+		return graphic.NewMesh(igeom, nil), nil
+	})
+	s.Register("Lines", new(graphic.Lines), nil, func(d *Decoder) (core.INode, error) {
+		igeom, err := graphicGeometry(d)
+		if err != nil {
+			return nil, err
+		}
+		return graphic.NewLines(igeom, d.Material(0)), nil
+	})
+	s.Register("LineStrip", new(graphic.LineStrip), nil, func(d *Decoder) (core.INode, error) {
+		igeom, err := graphicGeometry(d)
+		if err != nil {
+			return nil, err
+		}
+		return graphic.NewLineStrip(igeom, d.Material(0)), nil
+	})
+	s.Register("Points", new(graphic.Points), nil, func(d *Decoder) (core.INode, error) {
+		igeom, err := graphicGeometry(d)
+		if err != nil {
+			return nil, err
+		}
+		return graphic.NewPoints(igeom, nil), nil
+	})
+
+	// Lights
+	s.Register("AmbientLight", new(light.Ambient),
+		func(e *Encoder, inode core.INode) error {
+			l := inode.(*light.Ambient)
+			saveLight(e, l.Color(), l.Intensity())
+			return nil
+		},
+		func(d *Decoder) (core.INode, error) {
+			color := d.Color("color")
+			return light.NewAmbient(&color, d.Float("intensity")), nil
+		})
+	s.Register("DirectionalLight", new(light.Directional),
+		func(e *Encoder, inode core.INode) error {
+			l := inode.(*light.Directional)
+			saveLight(e, l.Color(), l.Intensity())
+			return nil
+		},
+		func(d *Decoder) (core.INode, error) {
+			color := d.Color("color")
+			return light.NewDirectional(&color, d.Float("intensity")), nil
+		})
+	s.Register("PointLight", new(light.Point),
+		func(e *Encoder, inode core.INode) error {
+			l := inode.(*light.Point)
+			saveLight(e, l.Color(), l.Intensity())
+			e.SetFloat("linearDecay", l.LinearDecay())
+			e.SetFloat("quadraticDecay", l.QuadraticDecay())
+			return nil
+		},
+		func(d *Decoder) (core.INode, error) {
+			color := d.Color("color")
+			l := light.NewPoint(&color, d.Float("intensity"))
+			l.SetLinearDecay(d.Float("linearDecay"))
+			l.SetQuadraticDecay(d.Float("quadraticDecay"))
+			return l, nil
+		})
+	s.Register("SpotLight", new(light.Spot),
+		func(e *Encoder, inode core.INode) error {
+			l := inode.(*light.Spot)
+			saveLight(e, l.Color(), l.Intensity())
+			dir := l.Direction(nil)
+			e.SetVector3("direction", &dir)
+			e.SetFloat("cutoffAngle", l.CutoffAngle())
+			e.SetFloat("angularDecay", l.AngularDecay())
+			e.SetFloat("linearDecay", l.LinearDecay())
+			e.SetFloat("quadraticDecay", l.QuadraticDecay())
+			return nil
+		},
+		func(d *Decoder) (core.INode, error) {
+			color := d.Color("color")
+			l := light.NewSpot(&color, d.Float("intensity"))
+			dir := d.Vector3("direction")
+			l.SetDirection(&dir)
+			l.SetCutoffAngle(d.Float("cutoffAngle"))
+			l.SetAngularDecay(d.Float("angularDecay"))
+			l.SetLinearDecay(d.Float("linearDecay"))
+			l.SetQuadraticDecay(d.Float("quadraticDecay"))
+			return l, nil
+		})
+
+	// Cameras
+	s.Register("PerspectiveCamera", new(camera.Perspective),
+		func(e *Encoder, inode core.INode) error {
+			cam := inode.(*camera.Perspective)
+			saveCamera(e, &cam.Camera)
+			e.SetFloat("fov", cam.Fov())
+			e.SetFloat("aspect", cam.Aspect())
+			e.SetFloat("near", cam.Near())
+			e.SetFloat("far", cam.Far())
+			return nil
+		},
+		func(d *Decoder) (core.INode, error) {
+			cam := camera.NewPerspective(d.Float("fov"), d.Float("aspect"), d.Float("near"), d.Float("far"))
+			loadCamera(d, &cam.Camera)
+			return cam, nil
+		})
+	s.Register("OrthographicCamera", new(camera.Orthographic),
+		func(e *Encoder, inode core.INode) error {
+			cam := inode.(*camera.Orthographic)
+			saveCamera(e, &cam.Camera)
+			e.SetFloats("planes", planes(cam.Planes())...)
+			e.SetFloat("zoom", cam.Zoom())
+			return nil
+		},
+		func(d *Decoder) (core.INode, error) {
+			p := d.FloatArray("planes")
+			if len(p) != 6 {
+				return nil, fmt.Errorf("serial: invalid orthographic camera planes")
+			}
+			cam := camera.NewOrthographic(p[0], p[1], p[2], p[3], p[4], p[5])
+			cam.SetZoom(d.Float("zoom"))
+			loadCamera(d, &cam.Camera)
+			return cam, nil
+		})
+
+	// Materials
+	s.RegisterMaterial("Basic", new(material.Basic), nil, func(d *Decoder) (material.IMaterial, error) {
+		return material.NewBasic(), nil
+	})
+	s.RegisterMaterial("Standard", new(material.Standard),
+		func(e *Encoder, imat material.IMaterial) error {
+			saveStandard(e, imat.(*material.Standard))
+			return nil
+		},
+		func(d *Decoder) (material.IMaterial, error) {
+			color := d.Color("color")
+			ms := material.NewStandard(&color)
+			loadStandard(d, ms)
+			return ms, nil
+		})
+	s.RegisterMaterial("Phong", new(material.Phong),
+		func(e *Encoder, imat material.IMaterial) error {
+			saveStandard(e, &imat.(*material.Phong).Standard)
+			return nil
+		},
+		func(d *Decoder) (material.IMaterial, error) {
+			color := d.Color("color")
+			mp := material.NewPhong(&color)
+			loadStandard(d, &mp.Standard)
+			return mp, nil
+		})
+	s.RegisterMaterial("Point", new(material.Point),
+		func(e *Encoder, imat material.IMaterial) error {
+			pm := imat.(*material.Point)
+			color := pm.EmissiveColor()
+			e.SetColor("color", &color)
+			e.SetFloat("size", pm.Size())
+			e.SetFloat("opacity", pm.Opacity())
+			e.SetFloat("rotationZ", pm.RotationZ())
+			return nil
+		},
+		func(d *Decoder) (material.IMaterial, error) {
+			color := d.Color("color")
+			pm := material.NewPoint(&color)
+			pm.SetSize(d.Float("size"))
+			pm.SetOpacity(d.Float("opacity"))
+			pm.SetRotationZ(d.Float("rotationZ"))
+			return pm, nil
+		})
+
+	// User data
+	s.RegisterUserData("string", "")
+	s.RegisterUserData("bool", false)
+	s.RegisterUserData("int", 0)
+	s.RegisterUserData("float32", float32(0))
+	s.RegisterUserData("float64", float64(0))
+	s.RegisterUserData("map", map[string]interface{}{})
+}
+
+// graphicGeometry returns the geometry of the graphic being
+// loaded or an error if it has no geometry
+func graphicGeometry(d *Decoder) (geometry.IGeometry, error) {
+
+	if d.Geometry() == nil {
+		return nil, fmt.Errorf("serial: graphic without geometry")
+	}
+	return d.Geometry(), nil
+}
+
+// saveLight saves the specified color and intensity of a light
+func saveLight(e *Encoder, color math32.Color, intensity float32) {
+
+	e.SetColor("color", &color)
+	e.SetFloat("intensity", intensity)
+}
+
+// saveCamera saves the target and up vector of the specified camera
+func saveCamera(e *Encoder, cam *camera.Camera) {
+
+	target := cam.Target()
+	up := cam.Up()
+	e.SetVector3("target", &target)
+	e.SetVector3("up", &up)
+}
+
+// loadCamera sets the target and up vector of the specified camera.
+// Its quaternion is set afterwards by the Serializer as saved.
+func loadCamera(d *Decoder, cam *camera.Camera) {
+
+	up := d.Vector3("up")
+	target := d.Vector3("target")
+	cam.SetUp(&up)
+	cam.LookAt(&target)
+}
+
+// planes returns the specified camera planes as a slice
+func planes(left, right, top, bottom, near, far float32) []float32 {
+
+	return []float32{left, right, top, bottom, near, far}
+}
+
+// saveStandard saves the colors, shininess and opacity of the specified standard material
+func saveStandard(e *Encoder, ms *material.Standard) {
+
+	color := ms.Color()
+	ambient := ms.AmbientColor()
+	specular := ms.SpecularColor()
+	emissive := ms.EmissiveColor()
+	e.SetColor("color", &color)
+	e.SetColor("ambient", &ambient)
+	e.SetColor("specular", &specular)
+	e.SetColor("emissive", &emissive)
+	e.SetFloat("shininess", ms.Shininess())
+	e.SetFloat("opacity", ms.Opacity())
+}
+
+// loadStandard sets the properties of the specified standard material,
+// created with the saved diffuse color
+func loadStandard(d *Decoder, ms *material.Standard) {
+
+	ambient := d.Color("ambient")
+	specular := d.Color("specular")
+	emissive := d.Color("emissive")
+	ms.SetAmbientColor(&ambient)
+	ms.SetSpecularColor(&specular)
+	ms.SetEmissiveColor(&emissive)
+	ms.SetShininess(d.Float("shininess"))
+	ms.SetOpacity(d.Float("opacity"))
+}
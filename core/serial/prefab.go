@@ -0,0 +1,490 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serial
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/g3n/engine/core"
+	"io"
+	"reflect"
+)
+
+// Field is a bit mask of the fields of the nodes of a prefab instance
+// which may be overridden
+type Field int
+
+// Fields of the nodes of the prefab instances
+const (
+	FieldPosition   Field = 1 << iota   // position
+	FieldRotation                       // rotation and quaternion
+	FieldScale                          // scale
+	FieldVisible                        // visibility
+	FieldUserData                       // user data
+	FieldProperties                     // type, tags, layers, specific properties, geometry and materials
+	FieldMetadata                       // metadata
+	AllFields       Field = 1<<iota - 1 // all the fields
+)
+
+// Prefab is a template of a node hierarchy which is instantiated many
+// times. The instances keep the fields of their nodes which were changed
+// or marked as overridden and take the other fields from the prefab when
+// it is redefined.
+//
+// The nodes of the instances correspond to the nodes of the prefab by their
+// paths, the names of the nodes from the root each preceded by a slash and
+// followed by their index in brackets if a previous sibling has the same name,
+// as "/body/wheel" and "/body/wheel[1]". The path of the root is empty.
+type Prefab struct {
+	s         *Serializer
+	doc       *document
+	instances []*Instance
+}
+
+// Instance is a node hierarchy created from a prefab
+type Instance struct {
+	prefab *Prefab
+	doc    *document             // prefab definition last synced with
+	root   core.INode            // root node
+	nodes  map[string]core.INode // nodes created from the prefab by path
+	marked map[string]Field      // fields marked as overridden by path
+}
+
+// pathRecord is a saved node with its path and the path of its parent
+type pathRecord struct {
+	path   string
+	parent string
+	rec    *nodeRecord
+}
+
+// NewPrefab creates and returns a pointer to a new Prefab
+// defined by the hierarchy of the specified node
+func (s *Serializer) NewPrefab(inode core.INode) (*Prefab, error) {
+
+	p := &Prefab{s: s}
+	if err := p.Define(inode); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// LoadPrefab reads a prefab saved in any format from the specified reader
+func (s *Serializer) LoadPrefab(r io.Reader) (*Prefab, error) {
+
+	doc, err := readDocument(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Prefab{s: s, doc: doc}, nil
+}
+
+// Save writes the definition of this prefab to the specified writer
+// in the specified format
+func (p *Prefab) Save(w io.Writer, format Format) error {
+
+	return writeDocument(w, p.doc, format)
+}
+
+// Define sets the definition of this prefab from the current state of the
+// hierarchy of the specified node and syncs the instances of the prefab
+func (p *Prefab) Define(inode core.INode) error {
+
+	st := p.s.newSaveState()
+	root, err := st.saveNode(inode)
+	if err != nil {
+		return err
+	}
+	st.doc.Root = root
+	p.doc = &st.doc
+	for _, inst := range p.instances {
+		if err := inst.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Instances returns the instances of this prefab
+func (p *Prefab) Instances() []*Instance {
+
+	return p.instances
+}
+
+// Instantiate creates and returns a new instance of this prefab
+func (p *Prefab) Instantiate() (*Instance, error) {
+
+	st, err := p.s.newLoadState(p.doc)
+	if err != nil {
+		return nil, err
+	}
+	inst := &Instance{
+		prefab: p,
+		doc:    p.doc,
+		nodes:  make(map[string]core.INode),
+		marked: make(map[string]Field),
+	}
+	for _, pr := range walkRecords(p.doc.Root) {
+		inode, err := st.loadRecord(pr.rec)
+		if err != nil {
+			return nil, err
+		}
+		if pr.path == "" {
+			inst.root = inode
+		} else {
+			inst.nodes[pr.parent].GetNode().Add(inode)
+		}
+		inst.nodes[pr.path] = inode
+	}
+	p.instances = append(p.instances, inst)
+	return inst, nil
+}
+
+// Prefab returns the prefab of this instance
+func (inst *Instance) Prefab() *Prefab {
+
+	return inst.prefab
+}
+
+// Root returns the root node of this instance
+func (inst *Instance) Root() core.INode {
+
+	return inst.root
+}
+
+// Node returns the node of this instance created from the node of the
+// prefab with the specified path or nil if not found
+func (inst *Instance) Node(path string) core.INode {
+
+	return inst.nodes[path]
+}
+
+// Path returns the path of the specified node of this instance and
+// if it was created from the prefab
+func (inst *Instance) Path(inode core.INode) (string, bool) {
+
+	for path, n := range inst.nodes {
+		if n == inode {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Override marks the specified fields of the specified node of this instance
+// as overridden, so they are kept even if equal to the prefab fields
+func (inst *Instance) Override(inode core.INode, fields Field) error {
+
+	path, ok := inst.Path(inode)
+	if !ok {
+		return fmt.Errorf("serial: node %q not created from the prefab", inode.GetNode().Name())
+	}
+	inst.marked[path] |= fields
+	return nil
+}
+
+// Overrides returns the fields of the specified node of this instance which
+// are marked as overridden or differ from the prefab definition
+func (inst *Instance) Overrides(inode core.INode) (Field, error) {
+
+	path, ok := inst.Path(inode)
+	if !ok {
+		return 0, fmt.Errorf("serial: node %q not created from the prefab", inode.GetNode().Name())
+	}
+	changed, err := inst.changed(inode, findRecord(inst.doc.Root, path))
+	if err != nil {
+		return 0, err
+	}
+	return inst.marked[path] | changed, nil
+}
+
+// Revert clears the overrides of the specified fields of the specified node
+// of this instance and sets them from the prefab definition. The node is
+// replaced by a new node if its type or properties are reverted.
+func (inst *Instance) Revert(inode core.INode, fields Field) error {
+
+	path, ok := inst.Path(inode)
+	if !ok {
+		return fmt.Errorf("serial: node %q not created from the prefab", inode.GetNode().Name())
+	}
+	inst.marked[path] &^= fields
+	rec := findRecord(inst.doc.Root, path)
+	if fields&FieldProperties != 0 {
+		changed, err := inst.changed(inode, rec)
+		if err != nil {
+			return err
+		}
+		if changed&FieldProperties != 0 {
+			st, err := inst.prefab.s.newLoadState(inst.doc)
+			if err != nil {
+				return err
+			}
+			if inode, err = inst.replace(st, path, rec); err != nil {
+				return err
+			}
+		}
+	}
+	return inst.prefab.s.setFields(inode.GetNode(), rec, fields&^FieldProperties)
+}
+
+// Sync updates this instance to the current definition of its prefab.
+// The fields of the nodes which are not overridden are set from the prefab,
+// the nodes added to the prefab are created and the nodes removed from the
+// prefab are removed. The nodes added to the instance are kept.
+func (inst *Instance) Sync() error {
+
+	def := inst.prefab.doc
+	if def == inst.doc {
+		return nil
+	}
+
+	// The overrides are found against the definition last synced with
+	old := make(map[string]*nodeRecord)
+	for _, pr := range walkRecords(inst.doc.Root) {
+		old[pr.path] = pr.rec
+	}
+	overrides := make(map[string]Field)
+	for path, inode := range inst.nodes {
+		changed, err := inst.changed(inode, old[path])
+		if err != nil {
+			return err
+		}
+		overrides[path] = inst.marked[path] | changed
+	}
+
+	st, err := inst.prefab.s.newLoadState(def)
+	if err != nil {
+		return err
+	}
+	synced := make(map[string]bool)
+	for _, pr := range walkRecords(def.Root) {
+		synced[pr.path] = true
+		inode, ok := inst.nodes[pr.path]
+		if !ok {
+			inode, err := st.loadRecord(pr.rec)
+			if err != nil {
+				return err
+			}
+			inst.nodes[pr.parent].GetNode().Add(inode)
+			inst.nodes[pr.path] = inode
+			continue
+		}
+		fields := AllFields &^ overrides[pr.path]
+		if fields&FieldProperties != 0 && !sameProperties(inst.doc, old[pr.path], def, pr.rec) {
+			if inode, err = inst.replace(st, pr.path, pr.rec); err != nil {
+				return err
+			}
+		}
+		if err := inst.prefab.s.setFields(inode.GetNode(), pr.rec, fields&^FieldProperties); err != nil {
+			return err
+		}
+	}
+
+	// Removes the nodes removed from the prefab
+	for path, inode := range inst.nodes {
+		if synced[path] {
+			continue
+		}
+		if parent := inode.GetNode().Parent(); parent != nil {
+			parent.GetNode().Remove(inode)
+		}
+		delete(inst.nodes, path)
+		delete(inst.marked, path)
+	}
+	inst.doc = def
+	return nil
+}
+
+// Detach removes this instance from the instances of its prefab,
+// so it is no longer synced. Its nodes are not changed.
+func (inst *Instance) Detach() {
+
+	for i, other := range inst.prefab.instances {
+		if other == inst {
+			copy(inst.prefab.instances[i:], inst.prefab.instances[i+1:])
+			inst.prefab.instances[len(inst.prefab.instances)-1] = nil
+			inst.prefab.instances = inst.prefab.instances[:len(inst.prefab.instances)-1]
+			return
+		}
+	}
+}
+
+// changed returns the fields of the specified node of this instance
+// which differ from the specified saved node
+func (inst *Instance) changed(inode core.INode, rec *nodeRecord) (Field, error) {
+
+	st := inst.prefab.s.newSaveState()
+	cur, err := st.saveRecord(inode)
+	if err != nil {
+		return 0, err
+	}
+	var fields Field
+	if cur.Position != rec.Position {
+		fields |= FieldPosition
+	}
+	if cur.Rotation != rec.Rotation || cur.Quaternion != rec.Quaternion {
+		fields |= FieldRotation
+	}
+	if cur.Scale != rec.Scale {
+		fields |= FieldScale
+	}
+	if cur.Hidden != rec.Hidden {
+		fields |= FieldVisible
+	}
+	if !sameUserData(cur.UserData, rec.UserData) {
+		fields |= FieldUserData
+	}
+	if !reflect.DeepEqual(cur.Metadata, rec.Metadata) {
+		fields |= FieldMetadata
+	}
+	if !sameProperties(&st.doc, cur, inst.doc, rec) {
+		fields |= FieldProperties
+	}
+	return fields, nil
+}
+
+// replace replaces the node of this instance with the specified path by a
+// new node created from the specified saved node. The new node takes the
+// place, the fields of the embedded Node and the children of the old node,
+// which is disposed.
+func (inst *Instance) replace(st *loadState, path string, rec *nodeRecord) (core.INode, error) {
+
+	iold := inst.nodes[path]
+	inode, err := st.loadRecord(rec)
+	if err != nil {
+		return nil, err
+	}
+	n := inode.GetNode()
+	old := iold.GetNode()
+	pos := old.Position()
+	scale := old.Scale()
+	rot := old.Rotation()
+	q := old.Quaternion()
+	n.SetPositionVec(&pos)
+	n.SetScaleVec(&scale)
+	n.SetRotation(rot.X, rot.Y, rot.Z)
+	n.SetQuaternionQuat(&q)
+	n.SetVisible(old.Visible())
+	n.SetUserData(old.UserData())
+	n.Metadata().Copy(old.Metadata())
+
+	for len(old.Children()) > 0 {
+		n.Add(old.Children()[0])
+	}
+	// Takes the place of the old node in its parent
+	if parent := old.Parent(); parent != nil {
+		children := parent.GetNode().Children()
+		for i := range children {
+			if children[i] == iold {
+				children[i] = inode
+				n.SetParent(parent)
+				old.SetParent(nil)
+				break
+			}
+		}
+	}
+	iold.Dispose()
+
+	inst.nodes[path] = inode
+	if path == "" {
+		inst.root = inode
+	}
+	return inode, nil
+}
+
+// walkRecords returns the specified saved node and its descendants
+// with their paths, with the parents before their children
+func walkRecords(root *nodeRecord) []pathRecord {
+
+	list := []pathRecord{{"", "", root}}
+	for i := 0; i < len(list); i++ {
+		pr := list[i]
+		for j, child := range pr.rec.Children {
+			list = append(list, pathRecord{childPath(pr.path, pr.rec.Children, j), pr.path, child})
+		}
+	}
+	return list
+}
+
+// findRecord returns the descendant of the specified saved node
+// with the specified path or nil if not found
+func findRecord(root *nodeRecord, path string) *nodeRecord {
+
+	for _, pr := range walkRecords(root) {
+		if pr.path == path {
+			return pr.rec
+		}
+	}
+	return nil
+}
+
+// childPath returns the path of the child with the specified index
+// of the specified siblings of the node with the specified path
+func childPath(parent string, siblings []*nodeRecord, idx int) string {
+
+	name := siblings[idx].Name
+	count := 0
+	for _, sibling := range siblings[:idx] {
+		if sibling.Name == name {
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Sprintf("%s/%s[%d]", parent, name, count)
+	}
+	return parent + "/" + name
+}
+
+// sameUserData returns if the specified saved user data are equal
+func sameUserData(a, b *userDataRecord) bool {
+
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	// The values may be indented differently
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a.Value) != nil || json.Compact(&cb, b.Value) != nil {
+		return false
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+// sameProperties returns if the specified saved nodes of the specified
+// documents have the same type, tags, layers, properties, geometry and materials
+func sameProperties(da *document, a *nodeRecord, db *document, b *nodeRecord) bool {
+
+	if a.Type != b.Type || !reflect.DeepEqual(a.Tags, b.Tags) || !reflect.DeepEqual(a.Layers, b.Layers) || !reflect.DeepEqual(a.Props, b.Props) || len(a.Materials) != len(b.Materials) {
+		return false
+	}
+	if geometryName(da, a.Geometry) != geometryName(db, b.Geometry) {
+		return false
+	}
+	for i, ua := range a.Materials {
+		ub := b.Materials[i]
+		if ua.Start != ub.Start || ua.Count != ub.Count {
+			return false
+		}
+		if ua.Index < 0 || ua.Index >= len(da.Materials) || ub.Index < 0 || ub.Index >= len(db.Materials) {
+			return false
+		}
+		if !reflect.DeepEqual(da.Materials[ua.Index], db.Materials[ub.Index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// geometryName returns the name of the geometry with the specified
+// index plus one in the specified document or the empty string if none
+func geometryName(doc *document, idx int) string {
+
+	if idx < 1 || idx > len(doc.Geometries) {
+		return ""
+	}
+	return doc.Geometries[idx-1]
+}
@@ -0,0 +1,193 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package serial
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Properties are the named values of a node or material which are
+// specific to its type, saved and loaded by the functions of the type
+type Properties struct {
+	Floats  map[string][]float32 `json:",omitempty"`
+	Ints    map[string]int       `json:",omitempty"`
+	Bools   map[string]bool      `json:",omitempty"`
+	Strings map[string]string    `json:",omitempty"`
+}
+
+// Encoder receives the properties of the node or material being saved
+type Encoder struct {
+	Properties
+}
+
+// Decoder contains the properties of the node or material being loaded
+// and, for the graphics, their geometry and materials
+type Decoder struct {
+	Properties
+	geom geometry.IGeometry
+	mats []MaterialRange
+}
+
+// MaterialRange is a material of a graphic with the index of the first
+// element and the number of elements of the geometry it renders
+type MaterialRange struct {
+	Material material.IMaterial
+	Start    int
+	Count    int
+}
+
+// SetFloat sets the named float property
+func (p *Properties) SetFloat(name string, v float32) {
+
+	p.SetFloats(name, v)
+}
+
+// Float returns the named float property or 0 if not set
+func (p *Properties) Float(name string) float32 {
+
+	if v := p.Floats[name]; len(v) > 0 {
+		return v[0]
+	}
+	return 0
+}
+
+// SetFloats sets the named float array property
+func (p *Properties) SetFloats(name string, v ...float32) {
+
+	if p.Floats == nil {
+		p.Floats = make(map[string][]float32)
+	}
+	p.Floats[name] = v
+}
+
+// FloatArray returns the named float array property or nil if not set
+func (p *Properties) FloatArray(name string) []float32 {
+
+	return p.Floats[name]
+}
+
+// SetVector3 sets the named vector property
+func (p *Properties) SetVector3(name string, v *math32.Vector3) {
+
+	p.SetFloats(name, v.X, v.Y, v.Z)
+}
+
+// Vector3 returns the named vector property or the zero vector if not set
+func (p *Properties) Vector3(name string) math32.Vector3 {
+
+	var v math32.Vector3
+	if a := p.Floats[name]; len(a) >= 3 {
+		v.FromArray(a, 0)
+	}
+	return v
+}
+
+// SetColor sets the named color property
+func (p *Properties) SetColor(name string, c *math32.Color) {
+
+	p.SetFloats(name, c.R, c.G, c.B)
+}
+
+// Color returns the named color property or black if not set
+func (p *Properties) Color(name string) math32.Color {
+
+	var c math32.Color
+	if a := p.Floats[name]; len(a) >= 3 {
+		c.R, c.G, c.B = a[0], a[1], a[2]
+	}
+	return c
+}
+
+// SetInt sets the named integer property
+func (p *Properties) SetInt(name string, v int) {
+
+	if p.Ints == nil {
+		p.Ints = make(map[string]int)
+	}
+	p.Ints[name] = v
+}
+
+// Int returns the named integer property or 0 if not set
+func (p *Properties) Int(name string) int {
+
+	return p.Ints[name]
+}
+
+// SetBool sets the named boolean property
+func (p *Properties) SetBool(name string, v bool) {
+
+	if p.Bools == nil {
+		p.Bools = make(map[string]bool)
+	}
+	p.Bools[name] = v
+}
+
+// Bool returns the named boolean property or false if not set
+func (p *Properties) Bool(name string) bool {
+
+	return p.Bools[name]
+}
+
+// SetString sets the named string property
+func (p *Properties) SetString(name string, v string) {
+
+	if p.Strings == nil {
+		p.Strings = make(map[string]string)
+	}
+	p.Strings[name] = v
+}
+
+// String returns the named string property or the empty string if not set
+func (p *Properties) String(name string) string {
+
+	return p.Strings[name]
+}
+
+// Has returns if the named property of any kind is set
+func (p *Properties) Has(name string) bool {
+
+	if _, ok := p.Floats[name]; ok {
+		return true
+	}
+	if _, ok := p.Ints[name]; ok {
+		return true
+	}
+	if _, ok := p.Bools[name]; ok {
+		return true
+	}
+	_, ok := p.Strings[name]
+	return ok
+}
+
+// empty returns if no property is set
+func (p *Properties) empty() bool {
+
+	return len(p.Floats) == 0 && len(p.Ints) == 0 && len(p.Bools) == 0 && len(p.Strings) == 0
+}
+
+// Geometry returns the geometry of the graphic being loaded
+// or nil if it has no geometry
+func (d *Decoder) Geometry() geometry.IGeometry {
+
+	return d.geom
+}
+
+// Materials returns the materials of the graphic being loaded
+func (d *Decoder) Materials() []MaterialRange {
+
+	return d.mats
+}
+
+// Material returns the material at the specified index of the
+// graphic being loaded or nil if there is no such material
+func (d *Decoder) Material(idx int) material.IMaterial {
+
+	if idx < 0 || idx >= len(d.mats) {
+		return nil
+	}
+	return d.mats[idx].Material
+}
@@ -0,0 +1,789 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package serial saves hierarchies of nodes to JSON or to a compact binary
+// format and loads them back, with their transforms, visibility, tags, user data,
+// metadata, materials, lights and cameras.
+//
+// The node and material types are saved by name from the registry of the
+// Serializer, which contains the types of the engine and where the
+// applications register their own types, with the functions which save and
+// load their specific properties. The user data types must also be registered.
+//
+// The geometries are saved by reference, using their names, and must be
+// added to the Serializer by the application before loading. The textures
+// of the materials are not saved.
+//
+// A Prefab is a saved hierarchy instantiated many times, whose instances
+// keep their overridden fields when the prefab is redefined.
+package serial
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"io"
+	"reflect"
+)
+
+// Format is the format of the saved hierarchies
+type Format int
+
+// Formats of the saved hierarchies
+const (
+	JSON   Format = iota // indented JSON text
+	Binary               // gob encoding preceded by the binary magic
+)
+
+// version is the current version of the saved documents
+const version = 1
+
+// binaryMagic starts the documents saved in the binary format
+const binaryMagic = "G3NB"
+
+// NodeSaveFunc is the type of the functions which save the properties
+// specific to a node type
+type NodeSaveFunc func(e *Encoder, inode core.INode) error
+
+// NodeLoadFunc is the type of the functions which create a node of a type
+// from its specific properties. The properties of the embedded Node,
+// the materials of the graphics not added by the function and the
+// children are set by the Serializer afterwards.
+type NodeLoadFunc func(d *Decoder) (core.INode, error)
+
+// MaterialSaveFunc is the type of the functions which save the properties
+// specific to a material type
+type MaterialSaveFunc func(e *Encoder, imat material.IMaterial) error
+
+// MaterialLoadFunc is the type of the functions which create a material
+// of a type from its specific properties. The properties of the embedded
+// Material are set by the Serializer afterwards.
+type MaterialLoadFunc func(d *Decoder) (material.IMaterial, error)
+
+// Serializer saves and loads node hierarchies using its registry of types
+// and its library of geometries
+type Serializer struct {
+	nodes     map[reflect.Type]*nodeCodec
+	nodeNames map[string]*nodeCodec
+	mats      map[reflect.Type]*materialCodec
+	matNames  map[string]*materialCodec
+	userData  map[reflect.Type]string
+	userNames map[string]reflect.Type
+	geoms     map[string]geometry.IGeometry
+	geomNames map[*geometry.Geometry]string
+}
+
+// nodeCodec contains the registered name and functions of a node type
+type nodeCodec struct {
+	name string
+	typ  reflect.Type
+	save NodeSaveFunc
+	load NodeLoadFunc
+}
+
+// materialCodec contains the registered name and functions of a material type
+type materialCodec struct {
+	name string
+	typ  reflect.Type
+	save MaterialSaveFunc
+	load MaterialLoadFunc
+}
+
+// document is the saved form of a node hierarchy
+type document struct {
+	Version    int
+	Geometries []string          `json:",omitempty"`
+	Materials  []*materialRecord `json:",omitempty"`
+	Root       *nodeRecord
+}
+
+// nodeRecord is the saved form of a node
+type nodeRecord struct {
+	Type       string
+	Name       string `json:",omitempty"`
+	LoaderID   string `json:",omitempty"`
+	Position   [3]float32
+	Rotation   [3]float32
+	Quaternion [4]float32
+	Scale      [3]float32
+	Direction  [3]float32
+	Hidden     bool            `json:",omitempty"`
+	Tags       []string        `json:",omitempty"`
+	Layers     *core.Layers    `json:",omitempty"` // nil for the default layer
+	Geometry   int             `json:",omitempty"` // index in the geometries plus one, 0 for none
+	Materials  []materialUse   `json:",omitempty"`
+	Props      *Properties     `json:",omitempty"`
+	UserData   *userDataRecord `json:",omitempty"`
+	Metadata   *metadataRecord `json:",omitempty"`
+	Children   []*nodeRecord   `json:",omitempty"`
+}
+
+// materialUse is the saved form of a material of a graphic
+type materialUse struct {
+	Index int // index in the materials
+	Start int
+	Count int
+}
+
+// materialRecord is the saved form of a material
+type materialRecord struct {
+	Type          string
+	Shader        string
+	UseLights     int
+	Side          int
+	Blending      int
+	Wireframe     bool
+	DepthMask     bool
+	DepthTest     bool
+	Transparent   bool
+	LineWidth     float32
+	PolygonOffset [2]float32
+	Props         *Properties `json:",omitempty"`
+}
+
+// userDataRecord is the saved form of the user data of a node,
+// whose value is always encoded as JSON
+type userDataRecord struct {
+	Type  string
+	Value json.RawMessage
+}
+
+// metadataRecord is the saved form of the metadata of a node
+type metadataRecord struct {
+	Strings map[string]string    `json:",omitempty"`
+	Numbers map[string]float64   `json:",omitempty"`
+	Ints    map[string]int       `json:",omitempty"`
+	Bools   map[string]bool      `json:",omitempty"`
+	Vectors map[string][]float32 `json:",omitempty"` // Vector2, Vector3 or Vector4 by length
+}
+
+// NewSerializer creates and returns a pointer to a new Serializer
+// with the node, material and user data types of the engine registered
+func NewSerializer() *Serializer {
+
+	s := new(Serializer)
+	s.nodes = make(map[reflect.Type]*nodeCodec)
+	s.nodeNames = make(map[string]*nodeCodec)
+	s.mats = make(map[reflect.Type]*materialCodec)
+	s.matNames = make(map[string]*materialCodec)
+	s.userData = make(map[reflect.Type]string)
+	s.userNames = make(map[string]reflect.Type)
+	s.geoms = make(map[string]geometry.IGeometry)
+	s.geomNames = make(map[*geometry.Geometry]string)
+	registerBuiltins(s)
+	return s
+}
+
+// Register registers the node type of the specified sample with the specified
+// name and functions, replacing the type previously registered with the name.
+// The save function may be nil if the type has no specific properties.
+func (s *Serializer) Register(name string, sample core.INode, save NodeSaveFunc, load NodeLoadFunc) {
+
+	if old := s.nodeNames[name]; old != nil {
+		delete(s.nodes, old.typ)
+	}
+	codec := &nodeCodec{name, reflect.TypeOf(sample), save, load}
+	s.nodes[codec.typ] = codec
+	s.nodeNames[name] = codec
+}
+
+// RegisterMaterial registers the material type of the specified sample with
+// the specified name and functions, replacing the type previously registered
+// with the name. The save function may be nil if the type has no specific properties.
+func (s *Serializer) RegisterMaterial(name string, sample material.IMaterial, save MaterialSaveFunc, load MaterialLoadFunc) {
+
+	if old := s.matNames[name]; old != nil {
+		delete(s.mats, old.typ)
+	}
+	codec := &materialCodec{name, reflect.TypeOf(sample), save, load}
+	s.mats[codec.typ] = codec
+	s.matNames[name] = codec
+}
+
+// RegisterUserData registers the type of the specified sample with the
+// specified name as a type of the user data of the nodes.
+// The values of the type are saved and loaded with the json package.
+func (s *Serializer) RegisterUserData(name string, sample interface{}) {
+
+	typ := reflect.TypeOf(sample)
+	if old, ok := s.userNames[name]; ok {
+		delete(s.userData, old)
+	}
+	s.userData[typ] = name
+	s.userNames[name] = typ
+}
+
+// AddGeometry adds the specified geometry to the library of this Serializer
+// with the specified name. The saved graphics refer to their geometries by
+// the names in the library or else by the names of the geometries.
+// The loaded graphics share the geometries of the library,
+// holding a reference each.
+func (s *Serializer) AddGeometry(name string, igeom geometry.IGeometry) {
+
+	if old, ok := s.geoms[name]; ok {
+		delete(s.geomNames, old.GetGeometry())
+	}
+	s.geoms[name] = igeom
+	s.geomNames[igeom.GetGeometry()] = name
+}
+
+// Save writes the hierarchy of the specified node to the specified writer
+// in the specified format
+func (s *Serializer) Save(w io.Writer, inode core.INode, format Format) error {
+
+	st := s.newSaveState()
+	root, err := st.saveNode(inode)
+	if err != nil {
+		return err
+	}
+	st.doc.Root = root
+	return writeDocument(w, &st.doc, format)
+}
+
+// Load reads a hierarchy saved in any format from the specified reader
+// and returns its root node
+func (s *Serializer) Load(r io.Reader) (core.INode, error) {
+
+	doc, err := readDocument(r)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.newLoadState(doc)
+	if err != nil {
+		return nil, err
+	}
+	return st.loadNode(doc.Root)
+}
+
+// Clone creates and returns a copy of the hierarchy of the specified node,
+// as saved and loaded again, with the same properties, user data and
+// metadata. The copies share the geometries of the original nodes, which
+// must have names or be in the library of this Serializer, and have new materials.
+func (s *Serializer) Clone(inode core.INode) (core.INode, error) {
+
+	sst := s.newSaveState()
+	root, err := sst.saveNode(inode)
+	if err != nil {
+		return nil, err
+	}
+	sst.doc.Root = root
+	lst := &loadState{s: s, doc: &sst.doc, mats: make([]material.IMaterial, len(sst.doc.Materials))}
+	lst.geoms = make([]geometry.IGeometry, len(sst.doc.Geometries))
+	for geom, idx := range sst.geoms {
+		lst.geoms[idx] = geom
+	}
+	return lst.loadNode(root)
+}
+
+// writeDocument writes the specified document to the specified writer
+// in the specified format
+func writeDocument(w io.Writer, doc *document, format Format) error {
+
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case Binary:
+		if _, err := io.WriteString(w, binaryMagic); err != nil {
+			return err
+		}
+		return gob.NewEncoder(w).Encode(doc)
+	}
+	return fmt.Errorf("serial: invalid format %d", format)
+}
+
+// readDocument reads a document saved in any format from the specified reader
+func readDocument(r io.Reader) (*document, error) {
+
+	doc := new(document)
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(binaryMagic))
+	if err == nil && string(head) == binaryMagic {
+		br.Discard(len(binaryMagic))
+		err = gob.NewDecoder(br).Decode(doc)
+	} else {
+		err = json.NewDecoder(br).Decode(doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if doc.Version < 1 || doc.Version > version {
+		return nil, fmt.Errorf("serial: unsupported version %d", doc.Version)
+	}
+	if doc.Root == nil {
+		return nil, fmt.Errorf("serial: no root node")
+	}
+	return doc, nil
+}
+
+// saveState contains the document being saved and the indices
+// of the geometries and materials already saved
+type saveState struct {
+	s     *Serializer
+	doc   document
+	geoms map[*geometry.Geometry]int
+	mats  map[*material.Material]int
+}
+
+// newSaveState creates and returns a pointer to the state
+// for saving a new document
+func (s *Serializer) newSaveState() *saveState {
+
+	st := &saveState{s: s, geoms: make(map[*geometry.Geometry]int), mats: make(map[*material.Material]int)}
+	st.doc.Version = version
+	return st
+}
+
+// saveNode returns the saved form of the hierarchy of the specified node
+func (st *saveState) saveNode(inode core.INode) (*nodeRecord, error) {
+
+	rec, err := st.saveRecord(inode)
+	if err != nil {
+		return nil, err
+	}
+	for _, ichild := range inode.GetNode().Children() {
+		child, err := st.saveNode(ichild)
+		if err != nil {
+			return nil, err
+		}
+		rec.Children = append(rec.Children, child)
+	}
+	return rec, nil
+}
+
+// saveRecord returns the saved form of the specified node without its children
+func (st *saveState) saveRecord(inode core.INode) (*nodeRecord, error) {
+
+	codec := st.s.nodes[reflect.TypeOf(inode)]
+	if codec == nil {
+		return nil, fmt.Errorf("serial: node type %T not registered", inode)
+	}
+	n := inode.GetNode()
+	rec := &nodeRecord{Type: codec.name, Name: n.Name(), LoaderID: n.LoaderID(), Hidden: !n.Visible()}
+	pos := n.Position()
+	pos.ToArray(rec.Position[:], 0)
+	rot := n.Rotation()
+	rot.ToArray(rec.Rotation[:], 0)
+	q := n.Quaternion()
+	rec.Quaternion = [4]float32{q.X(), q.Y(), q.Z(), q.W()}
+	scale := n.Scale()
+	scale.ToArray(rec.Scale[:], 0)
+	dir := n.Direction()
+	dir.ToArray(rec.Direction[:], 0)
+	if len(n.Tags()) > 0 {
+		rec.Tags = append([]string(nil), n.Tags()...)
+	}
+	if layers := n.Layers(); layers != core.LayerDefault {
+		rec.Layers = &layers
+	}
+
+	// Geometry and materials of graphics
+	if igr, ok := inode.(graphic.IGraphic); ok {
+		idx, err := st.saveGeometry(igr)
+		if err != nil {
+			return nil, err
+		}
+		rec.Geometry = idx + 1
+		grmats := igr.GetGraphic().Materials()
+		for i := range grmats {
+			imat := grmats[i].GetMaterial()
+			if imat == nil {
+				continue
+			}
+			idx, err := st.saveMaterial(imat)
+			if err != nil {
+				return nil, err
+			}
+			start, count := grmats[i].Range()
+			rec.Materials = append(rec.Materials, materialUse{idx, start, count})
+		}
+	}
+
+	// Properties specific to the type
+	if codec.save != nil {
+		var e Encoder
+		if err := codec.save(&e, inode); err != nil {
+			return nil, err
+		}
+		if !e.empty() {
+			rec.Props = &e.Properties
+		}
+	}
+
+	ud, err := st.s.saveUserData(n)
+	if err != nil {
+		return nil, err
+	}
+	rec.UserData = ud
+	rec.Metadata = saveMetadata(n.Metadata())
+	return rec, nil
+}
+
+// saveMetadata returns the saved form of the specified
+// metadata or nil if the metadata is empty
+func saveMetadata(m *core.Metadata) *metadataRecord {
+
+	if m.Len() == 0 {
+		return nil
+	}
+	rec := new(metadataRecord)
+	for _, key := range m.Keys() {
+		switch v := m.Value(key).(type) {
+		case string:
+			if rec.Strings == nil {
+				rec.Strings = make(map[string]string)
+			}
+			rec.Strings[key] = v
+		case float64:
+			if rec.Numbers == nil {
+				rec.Numbers = make(map[string]float64)
+			}
+			rec.Numbers[key] = v
+		case int:
+			if rec.Ints == nil {
+				rec.Ints = make(map[string]int)
+			}
+			rec.Ints[key] = v
+		case bool:
+			if rec.Bools == nil {
+				rec.Bools = make(map[string]bool)
+			}
+			rec.Bools[key] = v
+		case math32.Vector2:
+			rec.setVector(key, v.X, v.Y)
+		case math32.Vector3:
+			rec.setVector(key, v.X, v.Y, v.Z)
+		case math32.Vector4:
+			rec.setVector(key, v.X, v.Y, v.Z, v.W)
+		}
+	}
+	return rec
+}
+
+// setVector sets the vector with the specified key and components
+func (rec *metadataRecord) setVector(key string, v ...float32) {
+
+	if rec.Vectors == nil {
+		rec.Vectors = make(map[string][]float32)
+	}
+	rec.Vectors[key] = v
+}
+
+// loadMetadata sets the specified metadata from the specified saved metadata
+func loadMetadata(m *core.Metadata, rec *metadataRecord) error {
+
+	m.Clear()
+	if rec == nil {
+		return nil
+	}
+	for key, v := range rec.Strings {
+		m.SetString(key, v)
+	}
+	for key, v := range rec.Numbers {
+		m.SetNumber(key, v)
+	}
+	for key, v := range rec.Ints {
+		m.SetInt(key, v)
+	}
+	for key, v := range rec.Bools {
+		m.SetBool(key, v)
+	}
+	for key, v := range rec.Vectors {
+		switch len(v) {
+		case 2:
+			m.SetVector2(key, &math32.Vector2{v[0], v[1]})
+		case 3:
+			m.SetVector3(key, &math32.Vector3{v[0], v[1], v[2]})
+		case 4:
+			m.SetVector4(key, &math32.Vector4{v[0], v[1], v[2], v[3]})
+		default:
+			return fmt.Errorf("serial: invalid vector length %d of metadata key %q", len(v), key)
+		}
+	}
+	return nil
+}
+
+// saveUserData returns the saved form of the user data of the
+// specified node or nil if it has no user data
+func (s *Serializer) saveUserData(n *core.Node) (*userDataRecord, error) {
+
+	data := n.UserData()
+	if data == nil {
+		return nil, nil
+	}
+	name, ok := s.userData[reflect.TypeOf(data)]
+	if !ok {
+		return nil, fmt.Errorf("serial: user data type %T of node %q not registered", data, n.Name())
+	}
+	value, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &userDataRecord{name, value}, nil
+}
+
+// saveGeometry returns the index of the reference to the geometry
+// of the specified graphic, adding it if necessary
+func (st *saveState) saveGeometry(igr graphic.IGraphic) (int, error) {
+
+	geom := igr.GetGeometry()
+	if idx, ok := st.geoms[geom]; ok {
+		return idx, nil
+	}
+	name, ok := st.s.geomNames[geom]
+	if !ok {
+		name = geom.Name()
+	}
+	if name == "" {
+		return 0, fmt.Errorf("serial: geometry of node %q has no name", igr.GetNode().Name())
+	}
+	idx := len(st.doc.Geometries)
+	st.doc.Geometries = append(st.doc.Geometries, name)
+	st.geoms[geom] = idx
+	return idx, nil
+}
+
+// saveMaterial returns the index of the specified material,
+// saving it if necessary
+func (st *saveState) saveMaterial(imat material.IMaterial) (int, error) {
+
+	mat := imat.GetMaterial()
+	if idx, ok := st.mats[mat]; ok {
+		return idx, nil
+	}
+	codec := st.s.mats[reflect.TypeOf(imat)]
+	if codec == nil {
+		return 0, fmt.Errorf("serial: material type %T not registered", imat)
+	}
+	rec := &materialRecord{
+		Type:        codec.name,
+		Shader:      mat.Shader(),
+		UseLights:   int(mat.UseLights()),
+		Side:        int(mat.Side()),
+		Blending:    int(mat.Blending()),
+		Wireframe:   mat.Wireframe(),
+		DepthMask:   mat.DepthMask(),
+		DepthTest:   mat.DepthTest(),
+		Transparent: mat.Transparent(),
+		LineWidth:   mat.LineWidth(),
+	}
+	rec.PolygonOffset[0], rec.PolygonOffset[1] = mat.PolygonOffset()
+	if codec.save != nil {
+		var e Encoder
+		if err := codec.save(&e, imat); err != nil {
+			return 0, err
+		}
+		if !e.empty() {
+			rec.Props = &e.Properties
+		}
+	}
+	idx := len(st.doc.Materials)
+	st.doc.Materials = append(st.doc.Materials, rec)
+	st.mats[mat] = idx
+	return idx, nil
+}
+
+// loadState contains the document being loaded
+// and its geometries and materials already created
+type loadState struct {
+	s     *Serializer
+	doc   *document
+	geoms []geometry.IGeometry
+	mats  []material.IMaterial
+}
+
+// newLoadState creates and returns a pointer to the state for loading
+// the nodes of the specified document, whose geometries must be in
+// the library of this Serializer
+func (s *Serializer) newLoadState(doc *document) (*loadState, error) {
+
+	st := &loadState{s: s, doc: doc, mats: make([]material.IMaterial, len(doc.Materials))}
+	for _, name := range doc.Geometries {
+		igeom, ok := s.geoms[name]
+		if !ok {
+			return nil, fmt.Errorf("serial: geometry %q not found", name)
+		}
+		st.geoms = append(st.geoms, igeom)
+	}
+	return st, nil
+}
+
+// loadNode creates and returns the hierarchy of the specified saved node
+func (st *loadState) loadNode(rec *nodeRecord) (core.INode, error) {
+
+	inode, err := st.loadRecord(rec)
+	if err != nil {
+		return nil, err
+	}
+	for _, crec := range rec.Children {
+		child, err := st.loadNode(crec)
+		if err != nil {
+			return nil, err
+		}
+		inode.GetNode().Add(child)
+	}
+	return inode, nil
+}
+
+// loadRecord creates and returns the specified saved node without its children
+func (st *loadState) loadRecord(rec *nodeRecord) (core.INode, error) {
+
+	codec := st.s.nodeNames[rec.Type]
+	if codec == nil {
+		return nil, fmt.Errorf("serial: node type %q not registered", rec.Type)
+	}
+	d := new(Decoder)
+	if rec.Props != nil {
+		d.Properties = *rec.Props
+	}
+
+	// Each graphic holds a reference to its geometry and materials
+	if rec.Geometry > 0 {
+		if rec.Geometry > len(st.geoms) {
+			return nil, fmt.Errorf("serial: invalid geometry index %d", rec.Geometry-1)
+		}
+		d.geom = st.geoms[rec.Geometry-1]
+		d.geom.GetGeometry().Incref()
+	}
+	for _, use := range rec.Materials {
+		imat, err := st.material(use.Index)
+		if err != nil {
+			return nil, err
+		}
+		d.mats = append(d.mats, MaterialRange{imat, use.Start, use.Count})
+	}
+
+	inode, err := codec.load(d)
+	if err != nil {
+		return nil, err
+	}
+	if inode == nil {
+		return nil, fmt.Errorf("serial: no node loaded for type %q", rec.Type)
+	}
+
+	// Adds the materials not added by the load function
+	if igr, ok := inode.(graphic.IGraphic); ok {
+		gr := igr.GetGraphic()
+		for i := len(gr.Materials()); i < len(d.mats); i++ {
+			gr.AddMaterial(igr, d.mats[i].Material, d.mats[i].Start, d.mats[i].Count)
+		}
+	}
+
+	n := inode.GetNode()
+	n.SetName(rec.Name)
+	n.SetLoaderID(rec.LoaderID)
+	n.AddTag(rec.Tags...)
+	if rec.Layers != nil {
+		n.SetLayers(*rec.Layers)
+	}
+	var v math32.Vector3
+	n.SetDirectionv(v.FromArray(rec.Direction[:], 0))
+	if err := st.s.setFields(n, rec, AllFields); err != nil {
+		return nil, err
+	}
+	return inode, nil
+}
+
+// setFields sets the specified fields of the embedded Node
+// of a node from the specified saved node
+func (s *Serializer) setFields(n *core.Node, rec *nodeRecord, fields Field) error {
+
+	var v math32.Vector3
+	if fields&FieldPosition != 0 {
+		n.SetPositionVec(v.FromArray(rec.Position[:], 0))
+	}
+	if fields&FieldRotation != 0 {
+		// The rotation sets the quaternion which is then set as saved
+		n.SetRotation(rec.Rotation[0], rec.Rotation[1], rec.Rotation[2])
+		n.SetQuaternion(rec.Quaternion[0], rec.Quaternion[1], rec.Quaternion[2], rec.Quaternion[3])
+	}
+	if fields&FieldScale != 0 {
+		n.SetScaleVec(v.FromArray(rec.Scale[:], 0))
+	}
+	if fields&FieldVisible != 0 {
+		n.SetVisible(!rec.Hidden)
+	}
+	if fields&FieldMetadata != 0 {
+		if err := loadMetadata(n.Metadata(), rec.Metadata); err != nil {
+			return err
+		}
+	}
+	if fields&FieldUserData == 0 {
+		return nil
+	}
+	if rec.UserData == nil {
+		n.SetUserData(nil)
+		return nil
+	}
+	typ, ok := s.userNames[rec.UserData.Type]
+	if !ok {
+		return fmt.Errorf("serial: user data type %q not registered", rec.UserData.Type)
+	}
+	data := reflect.New(typ)
+	if err := json.Unmarshal(rec.UserData.Value, data.Interface()); err != nil {
+		return err
+	}
+	n.SetUserData(data.Elem().Interface())
+	return nil
+}
+
+// material returns the material with the specified index in the document,
+// creating it on first use. Each use after the first increments its
+// reference count.
+func (st *loadState) material(idx int) (material.IMaterial, error) {
+
+	if idx < 0 || idx >= len(st.mats) {
+		return nil, fmt.Errorf("serial: invalid material index %d", idx)
+	}
+	if imat := st.mats[idx]; imat != nil {
+		imat.GetMaterial().Incref()
+		return imat, nil
+	}
+	imat, err := st.loadMaterial(st.doc.Materials[idx])
+	if err != nil {
+		return nil, err
+	}
+	st.mats[idx] = imat
+	return imat, nil
+}
+
+// loadMaterial creates and returns the specified saved material
+func (st *loadState) loadMaterial(rec *materialRecord) (material.IMaterial, error) {
+
+	codec := st.s.matNames[rec.Type]
+	if codec == nil {
+		return nil, fmt.Errorf("serial: material type %q not registered", rec.Type)
+	}
+	d := new(Decoder)
+	if rec.Props != nil {
+		d.Properties = *rec.Props
+	}
+	imat, err := codec.load(d)
+	if err != nil {
+		return nil, err
+	}
+	if imat == nil {
+		return nil, fmt.Errorf("serial: no material loaded for type %q", rec.Type)
+	}
+
+	mat := imat.GetMaterial()
+	mat.SetShader(rec.Shader)
+	mat.SetUseLights(material.UseLights(rec.UseLights))
+	mat.SetSide(material.Side(rec.Side))
+	mat.SetBlending(material.Blending(rec.Blending))
+	mat.SetWireframe(rec.Wireframe)
+	mat.SetDepthMask(rec.DepthMask)
+	mat.SetDepthTest(rec.DepthTest)
+	mat.SetTransparent(rec.Transparent)
+	mat.SetLineWidth(rec.LineWidth)
+	mat.SetPolygonOffset(rec.PolygonOffset[0], rec.PolygonOffset[1])
+	return imat, nil
+}
@@ -0,0 +1,563 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// OnUndoChange is the event dispatched by an UndoStack when commands
+// are done, undone, redone or cleared (*UndoStack)
+const OnUndoChange = "core.OnUndoChange"
+
+// ICommand is the interface of the reversible modifications recorded by an UndoStack
+type ICommand interface {
+	Name() string // name of the command shown by the tools, as "Move Cube"
+	Do()          // does or redoes the modification
+	Undo()        // undoes the modification
+}
+
+// IMergeCommand is the interface of the commands which may absorb the command
+// done just after them, as the many changes of a value dragged with a slider,
+// so they are undone at once
+type IMergeCommand interface {
+	ICommand
+	Merge(next ICommand) bool // absorbs the specified command already done or returns false
+}
+
+// UndoStack records the commands done to modify a scene so they can be undone
+// and redone in order. Commands done between Begin and End are grouped and
+// undone and redone at once. It dispatches OnUndoChange when it changes.
+type UndoStack struct {
+	Dispatcher                 // Embedded event dispatcher
+	undo       []ICommand      // commands which can be undone, the most recent last
+	redo       []ICommand      // commands which can be redone, the most recent last
+	groups     []*CommandGroup // open groups, the innermost last
+	limit      int             // maximum number of commands which can be undone or 0
+	clean      int             // number of undo commands of the clean state or -1
+	merging    bool            // the last command may absorb the next one
+}
+
+// NewUndoStack creates and returns a pointer to a new empty undo stack
+func NewUndoStack() *UndoStack {
+
+	us := new(UndoStack)
+	us.Dispatcher.Initialize()
+	return us
+}
+
+// SetLimit sets the maximum number of commands which can be undone, dropping the
+// oldest ones when more commands are done. 0 means no limit, which is the default.
+func (us *UndoStack) SetLimit(limit int) {
+
+	us.limit = limit
+	us.trim()
+}
+
+// Limit returns the maximum number of commands which can be undone
+func (us *UndoStack) Limit() int {
+
+	return us.limit
+}
+
+// Do does the specified command and records it to be undone
+func (us *UndoStack) Do(cmd ICommand) {
+
+	cmd.Do()
+	us.Record(cmd)
+}
+
+// Record records the specified command, which was already done, to be
+// undone. The commands which were undone can't be redone anymore.
+func (us *UndoStack) Record(cmd ICommand) {
+
+	if len(us.groups) > 0 {
+		g := us.groups[len(us.groups)-1]
+		g.add(cmd)
+		return
+	}
+	us.redo = nil
+	if us.clean > len(us.undo) {
+		us.clean = -1
+	}
+	if us.merging && len(us.undo) > 0 && len(us.undo) != us.clean {
+		if mc, ok := us.undo[len(us.undo)-1].(IMergeCommand); ok && mc.Merge(cmd) {
+			us.Dispatch(OnUndoChange, us)
+			return
+		}
+	}
+	us.undo = append(us.undo, cmd)
+	us.merging = true
+	us.trim()
+	us.Dispatch(OnUndoChange, us)
+}
+
+// Begin opens a group with the specified name which records the commands done
+// until the matching End, which are undone and redone at once. Groups may be nested.
+func (us *UndoStack) Begin(name string) {
+
+	us.groups = append(us.groups, NewCommandGroup(name))
+}
+
+// End closes the group opened by the last Begin and records it,
+// unless no commands were done in the group
+func (us *UndoStack) End() {
+
+	if len(us.groups) == 0 {
+		panic("UndoStack.End: no open group")
+	}
+	g := us.groups[len(us.groups)-1]
+	us.groups = us.groups[:len(us.groups)-1]
+	if len(g.cmds) == 0 {
+		return
+	}
+	us.Record(g)
+	us.merging = false
+}
+
+// Seal stops merging the next command done with the last one, as at the end
+// of the drag of a value, so they are undone separately
+func (us *UndoStack) Seal() {
+
+	us.merging = false
+}
+
+// Undo undoes the last command done or redone.
+// Returns false if there is no command to undo.
+func (us *UndoStack) Undo() bool {
+
+	if len(us.undo) == 0 || len(us.groups) > 0 {
+		return false
+	}
+	cmd := us.undo[len(us.undo)-1]
+	us.undo[len(us.undo)-1] = nil
+	us.undo = us.undo[:len(us.undo)-1]
+	cmd.Undo()
+	us.redo = append(us.redo, cmd)
+	us.merging = false
+	us.Dispatch(OnUndoChange, us)
+	return true
+}
+
+// Redo redoes the last command undone.
+// Returns false if there is no command to redo.
+func (us *UndoStack) Redo() bool {
+
+	if len(us.redo) == 0 || len(us.groups) > 0 {
+		return false
+	}
+	cmd := us.redo[len(us.redo)-1]
+	us.redo[len(us.redo)-1] = nil
+	us.redo = us.redo[:len(us.redo)-1]
+	cmd.Do()
+	us.undo = append(us.undo, cmd)
+	us.merging = false
+	us.Dispatch(OnUndoChange, us)
+	return true
+}
+
+// CanUndo returns if there is a command to undo
+func (us *UndoStack) CanUndo() bool {
+
+	return len(us.undo) > 0
+}
+
+// CanRedo returns if there is a command to redo
+func (us *UndoStack) CanRedo() bool {
+
+	return len(us.redo) > 0
+}
+
+// UndoName returns the name of the command to undo or an empty string
+func (us *UndoStack) UndoName() string {
+
+	if len(us.undo) == 0 {
+		return ""
+	}
+	return us.undo[len(us.undo)-1].Name()
+}
+
+// RedoName returns the name of the command to redo or an empty string
+func (us *UndoStack) RedoName() string {
+
+	if len(us.redo) == 0 {
+		return ""
+	}
+	return us.redo[len(us.redo)-1].Name()
+}
+
+// SetClean marks the current state as clean, as when the scene is saved
+func (us *UndoStack) SetClean() {
+
+	us.clean = len(us.undo)
+	us.merging = false
+	us.Dispatch(OnUndoChange, us)
+}
+
+// IsClean returns if the current state is the state marked clean,
+// after the commands done since then were undone or redone
+func (us *UndoStack) IsClean() bool {
+
+	return us.clean == len(us.undo)
+}
+
+// Clear removes all the commands of this stack and marks the current state clean
+func (us *UndoStack) Clear() {
+
+	us.undo = nil
+	us.redo = nil
+	us.groups = nil
+	us.clean = 0
+	us.merging = false
+	us.Dispatch(OnUndoChange, us)
+}
+
+// trim drops the oldest commands above the limit
+func (us *UndoStack) trim() {
+
+	if us.limit <= 0 || len(us.undo) <= us.limit {
+		return
+	}
+	drop := len(us.undo) - us.limit
+	copy(us.undo, us.undo[drop:])
+	for i := us.limit; i < len(us.undo); i++ {
+		us.undo[i] = nil
+	}
+	us.undo = us.undo[:us.limit]
+	us.clean -= drop
+	if us.clean < 0 {
+		us.clean = -1
+	}
+}
+
+// CommandGroup is a command which does a sequence of commands
+// in order and undoes them in the reverse order
+type CommandGroup struct {
+	name string     // name of the group
+	cmds []ICommand // commands of the group in order
+}
+
+// NewCommandGroup creates and returns a pointer to a new group
+// with the specified name and commands
+func NewCommandGroup(name string, cmds ...ICommand) *CommandGroup {
+
+	return &CommandGroup{name: name, cmds: cmds}
+}
+
+// Name satisfies the ICommand interface
+func (g *CommandGroup) Name() string {
+
+	return g.name
+}
+
+// Do satisfies the ICommand interface
+func (g *CommandGroup) Do() {
+
+	for _, cmd := range g.cmds {
+		cmd.Do()
+	}
+}
+
+// Undo satisfies the ICommand interface
+func (g *CommandGroup) Undo() {
+
+	for i := len(g.cmds) - 1; i >= 0; i-- {
+		g.cmds[i].Undo()
+	}
+}
+
+// Commands returns the commands of this group
+func (g *CommandGroup) Commands() []ICommand {
+
+	return g.cmds
+}
+
+// add adds the specified command already done to this
+// group, merging it with the last command if possible
+func (g *CommandGroup) add(cmd ICommand) {
+
+	if len(g.cmds) > 0 {
+		if mc, ok := g.cmds[len(g.cmds)-1].(IMergeCommand); ok && mc.Merge(cmd) {
+			return
+		}
+	}
+	g.cmds = append(g.cmds, cmd)
+}
+
+// FuncCommand is a command which calls functions to do and undo it
+type FuncCommand struct {
+	name string
+	do   func()
+	undo func()
+}
+
+// NewFuncCommand creates and returns a pointer to a new command with the
+// specified name which calls the specified functions to do and undo it
+func NewFuncCommand(name string, do, undo func()) *FuncCommand {
+
+	return &FuncCommand{name: name, do: do, undo: undo}
+}
+
+// Name satisfies the ICommand interface
+func (c *FuncCommand) Name() string {
+
+	return c.name
+}
+
+// Do satisfies the ICommand interface
+func (c *FuncCommand) Do() {
+
+	c.do()
+}
+
+// Undo satisfies the ICommand interface
+func (c *FuncCommand) Undo() {
+
+	c.undo()
+}
+
+// ValueCommand is a command which sets a property to a new value and
+// restores its old value. Consecutive commands with the same name and
+// key are merged, as the changes of a value dragged with a slider.
+type ValueCommand struct {
+	name     string
+	key      interface{}
+	set      func(v interface{})
+	oldValue interface{}
+	newValue interface{}
+}
+
+// NewValueCommand creates and returns a pointer to a new command with the
+// specified name which sets a property with the specified function from
+// the specified old value to the specified new value. The key identifies the
+// object of the property to merge only the changes of the same object.
+func NewValueCommand(name string, key interface{}, set func(v interface{}), oldValue, newValue interface{}) *ValueCommand {
+
+	return &ValueCommand{name: name, key: key, set: set, oldValue: oldValue, newValue: newValue}
+}
+
+// Name satisfies the ICommand interface
+func (c *ValueCommand) Name() string {
+
+	return c.name
+}
+
+// Do satisfies the ICommand interface
+func (c *ValueCommand) Do() {
+
+	c.set(c.newValue)
+}
+
+// Undo satisfies the ICommand interface
+func (c *ValueCommand) Undo() {
+
+	c.set(c.oldValue)
+}
+
+// Merge satisfies the IMergeCommand interface
+func (c *ValueCommand) Merge(next ICommand) bool {
+
+	nc, ok := next.(*ValueCommand)
+	if !ok || nc.name != c.name || nc.key != c.key {
+		return false
+	}
+	c.newValue = nc.newValue
+	return true
+}
+
+// nodeTransform is the local transform of a node
+type nodeTransform struct {
+	position   math32.Vector3
+	rotation   math32.Vector3
+	quaternion math32.Quaternion
+	scale      math32.Vector3
+}
+
+// get sets this transform from the specified node
+func (t *nodeTransform) get(n *Node) {
+
+	t.position = n.position
+	t.rotation = n.rotation
+	t.quaternion = n.quaternion
+	t.scale = n.scale
+}
+
+// set sets the transform of the specified node from this transform
+func (t *nodeTransform) set(n *Node) {
+
+	n.position = t.position
+	n.rotation = t.rotation
+	n.quaternion = t.quaternion
+	n.scale = t.scale
+	n.matrixDirty = true
+}
+
+// TransformCommand is a command which changes the position, rotation and scale
+// of a node. Consecutive commands with the same name and node are merged,
+// as the changes of a node dragged with a gizmo.
+type TransformCommand struct {
+	name   string
+	inode  INode
+	change func(n *Node)
+	before nodeTransform
+	after  nodeTransform
+	done   bool
+}
+
+// NewTransformCommand creates and returns a pointer to a new command with
+// the specified name which changes the transform of the specified node with
+// the specified function, as n.SetPosition(x, y, z). The transform before and
+// after the change is saved to undo and redo the command.
+func NewTransformCommand(name string, inode INode, change func(n *Node)) *TransformCommand {
+
+	return &TransformCommand{name: name, inode: inode, change: change}
+}
+
+// Name satisfies the ICommand interface
+func (c *TransformCommand) Name() string {
+
+	return c.name
+}
+
+// Do satisfies the ICommand interface
+func (c *TransformCommand) Do() {
+
+	n := c.inode.GetNode()
+	if c.done {
+		c.after.set(n)
+		return
+	}
+	c.before.get(n)
+	c.change(n)
+	c.after.get(n)
+	c.done = true
+}
+
+// Undo satisfies the ICommand interface
+func (c *TransformCommand) Undo() {
+
+	c.before.set(c.inode.GetNode())
+}
+
+// Merge satisfies the IMergeCommand interface
+func (c *TransformCommand) Merge(next ICommand) bool {
+
+	nc, ok := next.(*TransformCommand)
+	if !ok || nc.name != c.name || nc.inode != c.inode {
+		return false
+	}
+	c.after = nc.after
+	return true
+}
+
+// AddCommand is a command which adds a node to a parent node
+type AddCommand struct {
+	name   string
+	parent INode
+	child  INode
+	prev   INode // previous parent of the child or nil
+	index  int   // index of the child in its previous parent
+}
+
+// NewAddCommand creates and returns a pointer to a new command which
+// adds the specified child node to the specified parent node. When undone
+// the child is removed, or moved back to its previous parent.
+func NewAddCommand(parent, child INode) *AddCommand {
+
+	return &AddCommand{name: "Add " + child.GetNode().Name(), parent: parent, child: child}
+}
+
+// Name satisfies the ICommand interface
+func (c *AddCommand) Name() string {
+
+	return c.name
+}
+
+// Do satisfies the ICommand interface
+func (c *AddCommand) Do() {
+
+	c.prev = c.child.GetNode().Parent()
+	if c.prev != nil {
+		c.index = childIndex(c.prev, c.child)
+	}
+	c.parent.GetNode().Add(c.child)
+}
+
+// Undo satisfies the ICommand interface
+func (c *AddCommand) Undo() {
+
+	c.parent.GetNode().Remove(c.child)
+	if c.prev != nil {
+		insertChild(c.prev, c.child, c.index)
+	}
+}
+
+// RemoveCommand is a command which removes a node from its parent node.
+// The node is not disposed, so it can be added back when the command is undone.
+type RemoveCommand struct {
+	name   string
+	child  INode
+	parent INode // parent of the child when removed
+	index  int   // index of the child in its parent
+}
+
+// NewRemoveCommand creates and returns a pointer to a new command
+// which removes the specified node from its parent. When undone the node
+// is added back to its parent at the same position of its children.
+func NewRemoveCommand(child INode) *RemoveCommand {
+
+	return &RemoveCommand{name: "Remove " + child.GetNode().Name(), child: child}
+}
+
+// Name satisfies the ICommand interface
+func (c *RemoveCommand) Name() string {
+
+	return c.name
+}
+
+// Do satisfies the ICommand interface
+func (c *RemoveCommand) Do() {
+
+	c.parent = c.child.GetNode().Parent()
+	if c.parent == nil {
+		return
+	}
+	c.index = childIndex(c.parent, c.child)
+	c.parent.GetNode().Remove(c.child)
+}
+
+// Undo satisfies the ICommand interface
+func (c *RemoveCommand) Undo() {
+
+	if c.parent != nil {
+		insertChild(c.parent, c.child, c.index)
+	}
+}
+
+// childIndex returns the index of the specified child in
+// the children of the specified parent or -1 if not found
+func childIndex(parent, child INode) int {
+
+	for i, ichild := range parent.GetNode().children {
+		if ichild == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertChild adds the specified child to the specified parent
+// and moves it to the specified index of the parent children
+func insertChild(parent, child INode, index int) {
+
+	n := parent.GetNode()
+	n.Add(child)
+	last := len(n.children) - 1
+	if index < 0 || index >= last {
+		return
+	}
+	copy(n.children[index+1:], n.children[index:last])
+	n.children[index] = child
+}
@@ -12,18 +12,20 @@ import (
 // Interface for all geometries
 type IGeometry interface {
 	GetGeometry() *Geometry
-	RenderSetup(gs *gls.GLS)
+	RenderSetup(gs gls.IDevice)
 	Dispose()
 }
 
 type Geometry struct {
 	refcount            int             // Current number of references
+	name                string          // Optional name used to label the OpenGL objects
 	vbos                []*gls.VBO      // Array of VBOs
 	groups              []Group         // Array geometry groups
 	indices             math32.ArrayU32 // Buffer with indices
-	gs                  *gls.GLS        // Pointer to gl context. Valid after first render setup
+	gs                  gls.IDevice     // Graphics device. Valid after first render setup
 	handleVAO           uint32          // Handle to OpenGL VAO
 	handleIndices       uint32          // Handle to OpenGL buffer for indices
+	sharedVAOs          []sharedVAO     // VAOs of other contexts sharing the buffers
 	updateIndices       bool            // Flag to indicate that indices must be transferred
 	boundingBox         math32.Box3     // Last calculated bounding box
 	boundingBoxValid    bool            // Indicates if last calculated bounding box is valid
@@ -31,6 +33,13 @@ type Geometry struct {
 	boundingSphereValid bool            // Indicates if last calculated bounding sphere is valid
 }
 
+// sharedVAO is the VAO of the geometry in another OpenGL context which
+// shares the buffers with the context where it was first rendered
+type sharedVAO struct {
+	gs     gls.IDevice
+	handle uint32
+}
+
 // Geometry group object
 type Group struct {
 	Start    int    // Index of first element of the group
@@ -55,9 +64,23 @@ func (g *Geometry) Init() {
 	g.gs = nil
 	g.handleVAO = 0
 	g.handleIndices = 0
+	g.sharedVAOs = nil
 	g.updateIndices = true
 }
 
+// SetName sets the name of this geometry, which is used as the
+// label of its OpenGL objects shown by graphics debuggers
+func (g *Geometry) SetName(name string) {
+
+	g.name = name
+}
+
+// Name returns the name of this geometry
+func (g *Geometry) Name() string {
+
+	return g.name
+}
+
 // Incref increments the reference count for this geometry
 // and returns a pointer to the geometry.
 // It should be used when this geometry is shared by another
@@ -79,8 +102,8 @@ func (g *Geometry) Dispose() {
 	}
 
 	if g.gs != nil {
-		g.gs.DeleteVertexArrays(g.handleVAO)
-		g.gs.DeleteBuffers(g.handleIndices)
+		g.gs.DestroyVertexArray(g.handleVAO)
+		g.gs.DestroyBuffer(g.handleIndices)
 	}
 	g.Init()
 }
@@ -121,10 +144,24 @@ func (g *Geometry) GroupAt(idx int) *Group {
 func (g *Geometry) SetIndices(indices math32.ArrayU32) {
 
 	g.indices = indices
+	g.updateIndices = true
 	g.boundingBoxValid = false
 	g.boundingSphereValid = false
 }
 
+// ClearBuffers empties the VBO buffers and the indices of this geometry keeping
+// their capacity and OpenGL objects, so they can be filled again with new data
+// without allocations, as done for the geometries reused by a Pool.
+func (g *Geometry) ClearBuffers() {
+
+	for _, vbo := range g.vbos {
+		buffer := vbo.Buffer()
+		*buffer = (*buffer)[:0]
+		vbo.Update()
+	}
+	g.SetIndices(g.indices[:0])
+}
+
 // Indices returns this geometry indices array
 func (g *Geometry) Indices() math32.ArrayU32 {
 
@@ -149,6 +186,16 @@ func (g *Geometry) VBO(attrib string) *gls.VBO {
 	return nil
 }
 
+// VBOAt returns a pointer to this geometry VBO at the specified index
+// or nil if the index is out of range.
+func (g *Geometry) VBOAt(idx int) *gls.VBO {
+
+	if idx < 0 || idx >= len(g.vbos) {
+		return nil
+	}
+	return g.vbos[idx]
+}
+
 // Returns the number of items in the first VBO
 // (The number of items should be same for all VBOs)
 // An item is a complete vertex position (3 floats) for example
@@ -232,6 +279,15 @@ func (g *Geometry) BoundingSphere() math32.Sphere {
 	return g.boundingSphere
 }
 
+// InvalidateBounds marks the bounding box and sphere of this geometry to be
+// computed again, as needed when the positions of its vertices are changed
+// in the buffer of their VBO
+func (g *Geometry) InvalidateBounds() {
+
+	g.boundingBoxValid = false
+	g.boundingSphereValid = false
+}
+
 // ApplyMatrix multiplies each of the geometry position vertices
 // by the specified matrix and apply the correspondent normal
 // transform matrix to the geometry normal vectors.
@@ -252,6 +308,7 @@ func (g *Geometry) ApplyMatrix(m *math32.Matrix4) {
 		positions.SetVector3(i, &vertex)
 	}
 	vboPos.Update()
+	g.InvalidateBounds()
 
 	// Get normals buffer
 	vboNormals := g.VBO("VertexNormal")
@@ -272,19 +329,26 @@ func (g *Geometry) ApplyMatrix(m *math32.Matrix4) {
 }
 
 // RenderSetup is called by the renderer before drawing the geometry
-func (g *Geometry) RenderSetup(gs *gls.GLS) {
+func (g *Geometry) RenderSetup(gs gls.IDevice) {
 
 	// First time initialization
-	if g.gs == nil {
+	first := g.gs == nil
+	if first {
 		// Generates VAO and binds it
-		g.handleVAO = gs.GenVertexArray()
-		gs.BindVertexArray(g.handleVAO)
+		g.handleVAO = gs.CreateVertexArray()
 		// Generates VBO for indices
-		g.handleIndices = gs.GenBuffer()
+		g.handleIndices = gs.CreateBuffer()
 		// Saves pointer to gl indicating initialization was done.
 		g.gs = gs
 	}
 
+	// The buffers are shared by the contexts of other windows,
+	// but each context needs its own VAO
+	if gs != g.gs {
+		g.renderSetupShared(gs)
+		return
+	}
+
 	// Update VBOs
 	gs.BindVertexArray(g.handleVAO)
 	for _, vbo := range g.vbos {
@@ -293,8 +357,57 @@ func (g *Geometry) RenderSetup(gs *gls.GLS) {
 
 	// Updates Indices buffer if necessary
 	if g.indices.Size() > 0 && g.updateIndices {
-		gs.BindBuffer(gls.ELEMENT_ARRAY_BUFFER, g.handleIndices)
-		gs.BufferData(gls.ELEMENT_ARRAY_BUFFER, g.indices.Bytes(), g.indices, gls.STATIC_DRAW)
+		gs.UploadBuffer(gls.IndexBuffer, g.handleIndices, g.indices.Bytes(), g.indices, gls.STATIC_DRAW)
 		g.updateIndices = false
 	}
+
+	// Labels the OpenGL objects, which exist once bound, for graphics debuggers
+	if first && g.name != "" {
+		gs.ObjectLabel(gls.VERTEX_ARRAY, g.handleVAO, g.name)
+		if g.indices.Size() > 0 {
+			gs.ObjectLabel(gls.BUFFER, g.handleIndices, g.name+" indices")
+		}
+		for _, vbo := range g.vbos {
+			if vbo.Handle() != 0 && vbo.AttribCount() > 0 {
+				gs.ObjectLabel(gls.BUFFER, vbo.Handle(), g.name+" "+vbo.AttribAt(0).Name)
+			}
+		}
+	}
+}
+
+// renderSetupShared binds the VAO of this geometry in the specified context,
+// which shares the buffers with the context where it was first rendered,
+// creating it if necessary. The VAOs of the other contexts are released
+// with their contexts.
+func (g *Geometry) renderSetupShared(gs gls.IDevice) {
+
+	var vao *sharedVAO
+	for i := range g.sharedVAOs {
+		if g.sharedVAOs[i].gs == gs {
+			vao = &g.sharedVAOs[i]
+			break
+		}
+	}
+	first := vao == nil
+	if first {
+		g.sharedVAOs = append(g.sharedVAOs, sharedVAO{gs: gs, handle: gs.CreateVertexArray()})
+		vao = &g.sharedVAOs[len(g.sharedVAOs)-1]
+	}
+	gs.BindVertexArray(vao.handle)
+	for _, vbo := range g.vbos {
+		vbo.Transfer(gs)
+		if first && vbo.Handle() != 0 {
+			vbo.SetAttribs(gs)
+		}
+	}
+
+	// The index buffer binding is kept by the VAO
+	if g.indices.Size() > 0 {
+		if g.updateIndices {
+			gs.UploadBuffer(gls.IndexBuffer, g.handleIndices, g.indices.Bytes(), g.indices, gls.STATIC_DRAW)
+			g.updateIndices = false
+		} else if first {
+			gs.BindIndexBuffer(g.handleIndices)
+		}
+	}
 }
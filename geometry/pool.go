@@ -0,0 +1,42 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/core"
+)
+
+// Pool is a pool of geometries with the same vertex attributes, as the rows of
+// text of a list or the trails of particles, which are frequently rebuilt.
+// The buffers of the released geometries are emptied keeping their capacity
+// and their OpenGL objects, so the data of a reused geometry is built without
+// allocations and uploaded to the same OpenGL buffers.
+// The geometries dropped by a full pool are disposed.
+type Pool struct {
+	core.Pool // Embedded pool
+}
+
+// NewPool creates and returns a pointer to a new pool of geometries
+// which creates its geometries with the specified function
+func NewPool(create func() *Geometry) *Pool {
+
+	p := new(Pool)
+	p.Pool.Init(func() interface{} { return create() })
+	p.Pool.SetReset(func(obj interface{}) { obj.(*Geometry).ClearBuffers() })
+	p.Pool.SetDispose(func(obj interface{}) { obj.(*Geometry).Dispose() })
+	return p
+}
+
+// Get returns an idle geometry of this pool with empty buffers or a new geometry
+func (p *Pool) Get() *Geometry {
+
+	return p.Pool.Get().(*Geometry)
+}
+
+// Put releases the specified geometry to this pool to be reused
+func (p *Pool) Put(g *Geometry) {
+
+	p.Pool.Put(g)
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"fmt"
+	"github.com/g3n/engine/gls/gl"
+)
+
+// ComputeSupported returns if the current OpenGL context supports compute
+// shaders, which requires OpenGL 4.3, the ARB_compute_shader extension or
+// OpenGL ES 3.1. Compute shaders are not supported by WebGL.
+func (gs *GLS) ComputeSupported() bool {
+
+	if gs.compute == capUndef {
+		gs.compute = capDisabled
+		if gl.ComputeSupported() {
+			gs.compute = capEnabled
+		}
+	}
+	return gs.compute == capEnabled
+}
+
+// NewComputeProgram creates and builds a program with the specified compute
+// shader source and defines. The source must start with a #version directive
+// which supports compute shaders, such as "430" or "310 es", and must declare
+// the work group size with a layout qualifier.
+// The program is activated with UseProgram() and executed with DispatchCompute().
+func (gs *GLS) NewComputeProgram(source string, defines map[string]interface{}) (*Program, error) {
+
+	if !gs.ComputeSupported() {
+		return nil, fmt.Errorf("Compute shaders not supported by %s %s", gl.Backend, gs.GetString(VERSION))
+	}
+	prog := gs.NewProgram()
+	prog.AddShader(COMPUTE_SHADER, source, defines)
+	err := prog.Build()
+	if err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
+
+// DispatchCompute launches the specified number of work groups
+// of the compute shader of the current program.
+func (gs *GLS) DispatchCompute(numGroupsX, numGroupsY, numGroupsZ uint32) {
+
+	gl.DispatchCompute(numGroupsX, numGroupsY, numGroupsZ)
+	gs.checkError("DispatchCompute")
+}
+
+// DispatchComputeIndirect launches work groups of the compute shader of the
+// current program, with the number of work groups read from the specified
+// byte offset of the buffer bound to DISPATCH_INDIRECT_BUFFER.
+func (gs *GLS) DispatchComputeIndirect(offset uint32) {
+
+	gl.DispatchComputeIndirect(offset)
+	gs.checkError("DispatchComputeIndirect")
+}
+
+// MemoryBarrier orders the memory transactions issued before this call
+// relative to those issued after it, for the specified barrier bits such as
+// SHADER_STORAGE_BARRIER_BIT or SHADER_IMAGE_ACCESS_BARRIER_BIT.
+// It must be called between a compute dispatch and the commands which use its results.
+func (gs *GLS) MemoryBarrier(barriers uint32) {
+
+	gl.MemoryBarrier(barriers)
+	gs.checkError("MemoryBarrier")
+}
+
+// BindImageTexture binds a level of the specified texture to an image unit,
+// for image load and store operations in shaders.
+// The access must be READ_ONLY, WRITE_ONLY or READ_WRITE and the format
+// must match the format qualifier of the image uniform, such as RGBA32F.
+func (gs *GLS) BindImageTexture(unit, tex uint32, level int32, layered bool, layer int32, access, format uint32) {
+
+	gl.BindImageTexture(unit, tex, level, layered, layer, access, format)
+	gs.checkError("BindImageTexture")
+}
@@ -750,6 +750,7 @@ const (
 	MAX_TEXTURE_BUFFER_SIZE                                    = 0x8C2B
 	MAX_TEXTURE_IMAGE_UNITS                                    = 0x8872
 	MAX_TEXTURE_LOD_BIAS                                       = 0x84FD
+	MAX_TEXTURE_MAX_ANISOTROPY                                 = 0x84FF
 	MAX_TEXTURE_SIZE                                           = 0x0D33
 	MAX_TRANSFORM_FEEDBACK_BUFFERS                             = 0x8E70
 	MAX_TRANSFORM_FEEDBACK_INTERLEAVED_COMPONENTS              = 0x8C8A
@@ -1266,6 +1267,7 @@ const (
 	TEXTURE_LOD_BIAS                                           = 0x8501
 	TEXTURE_MAG_FILTER                                         = 0x2800
 	TEXTURE_MAX_LEVEL                                          = 0x813D
+	TEXTURE_MAX_ANISOTROPY                                     = 0x84FE
 	TEXTURE_MAX_LOD                                            = 0x813B
 	TEXTURE_MIN_FILTER                                         = 0x2801
 	TEXTURE_MIN_LOD                                            = 0x813A
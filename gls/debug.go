@@ -0,0 +1,91 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+)
+
+// DebugSupported returns if the current OpenGL context supports the debug
+// output, debug groups and object labels of the KHR_debug extension,
+// which are part of OpenGL 4.3. They are not supported by WebGL.
+func (gs *GLS) DebugSupported() bool {
+
+	if gs.debug == capUndef {
+		gs.debug = capDisabled
+		if gl.DebugSupported() {
+			gs.debug = capEnabled
+		}
+	}
+	return gs.debug == capEnabled
+}
+
+// EnableDebugOutput enables or disables the debug output of the OpenGL
+// context, whose messages are written into the logger of this package with
+// the level corresponding to their severity. The messages are generated
+// synchronously so they are logged by the call which caused them.
+// Most drivers only generate detailed messages for debug contexts.
+func (gs *GLS) EnableDebugOutput(state bool) {
+
+	if !gs.DebugSupported() {
+		return
+	}
+	if !state {
+		gs.Disable(DEBUG_OUTPUT)
+		return
+	}
+	gl.DebugMessageCallback(logDebugMessage)
+	gs.Enable(DEBUG_OUTPUT)
+	gs.Enable(DEBUG_OUTPUT_SYNCHRONOUS)
+}
+
+// PushDebugGroup starts a named group of commands, such as a render pass,
+// which is shown by debuggers such as RenderDoc and apitrace.
+// Each call must be matched by a call to PopDebugGroup().
+func (gs *GLS) PushDebugGroup(name string) {
+
+	if !gs.DebugSupported() {
+		return
+	}
+	gl.PushDebugGroup(DEBUG_SOURCE_APPLICATION, 0, name)
+	gs.checkError("PushDebugGroup")
+}
+
+// PopDebugGroup ends the last group of commands started by PushDebugGroup()
+func (gs *GLS) PopDebugGroup() {
+
+	if !gs.DebugSupported() {
+		return
+	}
+	gl.PopDebugGroup()
+	gs.checkError("PopDebugGroup")
+}
+
+// ObjectLabel sets the label shown by debuggers for the specified object.
+// The identifier is the type of the object, such as BUFFER, TEXTURE,
+// PROGRAM, VERTEX_ARRAY or FRAMEBUFFER, and the name is its handle.
+func (gs *GLS) ObjectLabel(identifier, name uint32, label string) {
+
+	if label == "" || !gs.DebugSupported() {
+		return
+	}
+	gl.ObjectLabel(identifier, name, label)
+	gs.checkError("ObjectLabel")
+}
+
+// logDebugMessage writes a message of the debug output into the logger
+func logDebugMessage(source, xtype, id, severity uint32, message string) {
+
+	switch severity {
+	case DEBUG_SEVERITY_HIGH:
+		log.Error("GL debug (id:%d): %s", id, message)
+	case DEBUG_SEVERITY_MEDIUM:
+		log.Warn("GL debug (id:%d): %s", id, message)
+	case DEBUG_SEVERITY_LOW:
+		log.Info("GL debug (id:%d): %s", id, message)
+	default:
+		log.Debug("GL debug (id:%d): %s", id, message)
+	}
+}
@@ -0,0 +1,606 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+)
+
+// IDevice is the interface of a graphics device used by the scene layer
+// (geometries, textures, materials and graphics) and by the renderer to
+// create resources and issue drawing commands, without calling the functions
+// of a specific graphics API. GLS implements it for OpenGL, OpenGL ES and
+// WebGL and other implementations may be provided for other APIs such as
+// Vulkan or Metal. Resources are referenced by the uint32 handles returned
+// by the device. The commands are executed when called, as by OpenGL, and
+// not recorded in command buffers, so an implementation for an API with
+// command buffers must record and submit them itself. The renderer, its
+// shader manager and its passes (order independent transparency, occlusion
+// culling, render targets, stereo views and the profiler) only use the device.
+type IDevice interface {
+	// Backend returns the name of the graphics API used by the device
+	Backend() string
+
+	// CreateBuffer creates a new empty buffer and returns its handle
+	CreateBuffer() uint32
+	// UploadBuffer replaces the contents of the specified buffer
+	UploadBuffer(kind BufferKind, buf uint32, size int, data interface{}, usage uint32)
+	// DestroyBuffer releases the specified buffer
+	DestroyBuffer(buf uint32)
+	// BindIndexBuffer binds the specified index buffer to the current vertex array
+	BindIndexBuffer(buf uint32)
+	// BindUniformBuffer binds the specified uniform buffer to a uniform block binding point
+	BindUniformBuffer(binding uint32, buf uint32)
+
+	// CreateVertexArray creates a new vertex array, which keeps the bindings
+	// of the vertex attributes and index buffer of a geometry, and returns its handle.
+	CreateVertexArray() uint32
+	// BindVertexArray selects the vertex array used by the next commands
+	BindVertexArray(vao uint32)
+	// SetVertexAttrib binds a vertex attribute of the current vertex array
+	// to a region of the specified buffer
+	SetVertexAttrib(buf uint32, location uint32, size int32, stride int32, offset uint32)
+	// DestroyVertexArray releases the specified vertex array
+	DestroyVertexArray(vao uint32)
+
+	// CreateTexture creates a new empty texture and returns its handle
+	CreateTexture() uint32
+	// UploadTexture sets the storage and image data of the specified texture
+	UploadTexture(tex uint32, desc *TextureDesc, data interface{})
+	// UploadTextureLayer replaces the image data of one layer of the specified
+	// array texture or of one slice of the specified 3D texture
+	UploadTextureLayer(tex uint32, desc *TextureDesc, layer int, data interface{})
+	// SetTextureSampler sets the sampling parameters of the specified texture
+	SetTextureSampler(tex uint32, desc *TextureDesc)
+	// BindTextureUnit binds the specified texture to a texture unit
+	BindTextureUnit(unit int, tex uint32)
+	// BindTextureUnitTarget binds the specified texture of the specified
+	// target, such as TEXTURE_2D_ARRAY or TEXTURE_3D, to a texture unit
+	BindTextureUnitTarget(unit int, target uint32, tex uint32)
+	// GenerateMipmap generates the mipmaps of the texture bound to the specified target
+	GenerateMipmap(target uint32)
+	// DestroyTexture releases the specified texture
+	DestroyTexture(tex uint32)
+	// Sampler returns the handle of a sampler with the specified state,
+	// creating it the first time, or 0 if samplers are not supported.
+	Sampler(state *SamplerState) uint32
+	// BindSampler binds the specified sampler to a texture unit or unbinds it if 0
+	BindSampler(unit, sampler uint32)
+
+	// CreateProgram compiles and links a shader program from the
+	// specified description and returns its handle
+	CreateProgram(desc *ProgramDesc) (uint32, error)
+	// BindProgram selects the program used by the next commands
+	BindProgram(prog uint32)
+	// DestroyProgram releases the specified program
+	DestroyProgram(prog uint32)
+	// UniformLocation returns the location of the named uniform
+	// of the current program or -1 if it is not used by the program
+	UniformLocation(name string) int32
+	// AttribLocation returns the location of the named vertex attribute
+	// of the current program or -1 if it is not used by the program
+	AttribLocation(name string) int32
+	// Uniform1i sets the value of an int uniform of the current program
+	Uniform1i(location int32, v0 int32)
+	// Uniform1f sets the value of a float uniform of the current program
+	Uniform1f(location int32, v0 float32)
+	// Uniform2f sets the value of a vec2 uniform of the current program
+	Uniform2f(location int32, v0, v1 float32)
+	// Uniform3f sets the value of a vec3 uniform of the current program
+	Uniform3f(location int32, v0, v1, v2 float32)
+	// Uniform4f sets the value of a vec4 uniform of the current program
+	Uniform4f(location int32, v0, v1, v2, v3 float32)
+	// UniformMatrix3fv sets the values of mat3 uniforms of the current program
+	UniformMatrix3fv(location int32, count int32, transpose bool, v []float32)
+	// UniformMatrix4fv sets the values of mat4 uniforms of the current program
+	UniformMatrix4fv(location int32, count int32, transpose bool, v []float32)
+
+	// CreateFramebuffer creates a new framebuffer and returns its handle
+	CreateFramebuffer() uint32
+	// BindFramebuffer binds the specified framebuffer, or the default framebuffer
+	// if 0, to the DRAW_FRAMEBUFFER or READ_FRAMEBUFFER target or to both (FRAMEBUFFER)
+	BindFramebuffer(target uint32, fbo uint32)
+	// FramebufferTexture2D attaches a level of the specified texture to
+	// an attachment of the framebuffer bound to the specified target
+	FramebufferTexture2D(target, attachment, textarget uint32, tex uint32, level int32)
+	// FramebufferRenderbuffer attaches the specified renderbuffer to an
+	// attachment of the framebuffer bound to the specified target
+	FramebufferRenderbuffer(target, attachment uint32, rbo uint32)
+	// CheckFramebufferStatus returns FRAMEBUFFER_COMPLETE if the framebuffer
+	// bound to the specified target can be used or the reason it can't
+	CheckFramebufferStatus(target uint32) uint32
+	// ReadBuffer selects the color attachment of the read framebuffer copied by BlitFramebuffer
+	ReadBuffer(src uint32)
+	// DrawBuffers selects the color attachments of the draw framebuffer written by the next commands
+	DrawBuffers(bufs ...uint32)
+	// BlitFramebuffer copies a rectangle of the read framebuffer to a rectangle of the draw framebuffer
+	BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32)
+	// DestroyFramebuffer releases the specified framebuffer
+	DestroyFramebuffer(fbo uint32)
+	// CreateRenderbuffer creates a new renderbuffer with storage of the specified
+	// format, size and number of samples, or not multisampled if 0, and returns its handle
+	CreateRenderbuffer(iformat uint32, width, height, samples int32) uint32
+	// DestroyRenderbuffer releases the specified renderbuffer
+	DestroyRenderbuffer(rbo uint32)
+	// CreateMultisampleTexture creates a new multisample texture with storage of
+	// the specified format, size and number of samples and returns its handle,
+	// or 0 if multisample textures are not supported. It is released by DestroyTexture.
+	CreateMultisampleTexture(iformat uint32, width, height, samples int32) uint32
+
+	// CreateQuery creates a new query and returns its handle
+	CreateQuery() uint32
+	// BeginQuery starts the specified query of a target such as ANY_SAMPLES_PASSED
+	BeginQuery(target uint32, query uint32)
+	// EndQuery ends the active query of the specified target
+	EndQuery(target uint32)
+	// QueryCounter records into the specified query the time
+	// when the previous commands completed (target TIMESTAMP)
+	QueryCounter(query uint32, target uint32)
+	// QueryAvailable returns if the result of the specified query is available
+	QueryAvailable(query uint32) bool
+	// QueryResult returns the result of the specified query, waiting for it if not available
+	QueryResult(query uint32) uint64
+	// DestroyQuery releases the specified query
+	DestroyQuery(query uint32)
+
+	// GetInteger returns the value of an integer parameter such as MAX_DRAW_BUFFERS
+	GetInteger(pname uint32) int32
+	// Viewport sets the position and size of the viewport in pixels
+	Viewport(x, y, width, height int32)
+	// GetViewport returns the position and size of the current viewport in pixels
+	GetViewport() (x, y, width, height int32)
+	// SetScissor enables the scissor test with the specified rectangle in pixels or disables it
+	SetScissor(enabled bool, x, y, width, height int32)
+	// ColorMask sets which color components are written by the next commands
+	ColorMask(red, green, blue, alpha bool)
+	// DepthMask sets if the depth buffer is written by the next commands
+	DepthMask(flag bool)
+	// ClearColor sets the color used by Clear
+	ClearColor(r, g, b, a float32)
+	// GetClearColor returns the color used by Clear
+	GetClearColor() (r, g, b, a float32)
+	// Clear clears the buffers of the draw framebuffer selected by a
+	// mask of COLOR_BUFFER_BIT, DEPTH_BUFFER_BIT and STENCIL_BUFFER_BIT
+	Clear(mask int)
+	// ClearBuffer clears a draw buffer of the draw framebuffer to the specified values
+	ClearBuffer(buffer uint32, drawbuffer int32, value ...float32)
+	// SetPipelineState sets the fixed function states for the next draw commands
+	SetPipelineState(ps *PipelineState)
+	// Draw draws count vertices of the current vertex array starting at first
+	Draw(mode uint32, first, count int)
+	// DrawIndexed draws count indices of the current vertex array starting at first
+	DrawIndexed(mode uint32, first, count int)
+	// DrawIndexedIndirect draws the commands of the specified indirect buffer
+	// with the current vertex array
+	DrawIndexedIndirect(mode uint32, ib *IndirectBuffer)
+
+	// ObjectLabel sets the label of a resource shown by graphics debuggers
+	ObjectLabel(identifier, name uint32, label string)
+	// PushDebugGroup starts a named group of commands for graphics debuggers
+	PushDebugGroup(name string)
+	// PopDebugGroup ends the last group of commands started by PushDebugGroup
+	PopDebugGroup()
+}
+
+// GLS is the OpenGL implementation of IDevice
+var _ IDevice = (*GLS)(nil)
+
+// BufferKind specifies the kind of data stored in a device buffer
+type BufferKind int
+
+// Kinds of device buffers
+const (
+	VertexBuffer  BufferKind = iota // Vertex attributes
+	IndexBuffer                     // 32 bits vertex indices
+	UniformBuffer                   // Uniform block data
+	StorageBuffer                   // Shader storage block data
+)
+
+// PipelineState contains the fixed function states used to draw primitives.
+// Materials fill a PipelineState from their properties so they do not
+// depend on the state functions of the graphics API.
+type PipelineState struct {
+	CullFace            bool    // Culls the back faces
+	FrontFace           uint32  // Winding of the front faces: CCW or CW
+	DepthTest           bool    // Depth test enabled
+	DepthMask           bool    // Depth buffer writes enabled
+	DepthFunc           uint32  // Depth test function
+	Wireframe           bool    // Polygons are rasterized as lines
+	PolygonOffsetFactor float32 // Polygon offset factor
+	PolygonOffsetUnits  float32 // Polygon offset units
+	LineWidth           float32 // Width of rasterized lines
+	Blend               bool    // Blending enabled
+	BlendEquationRGB    uint32  // Blend equation of the color components
+	BlendEquationAlpha  uint32  // Blend equation of the alpha component
+	BlendSrcRGB         uint32  // Source blend factor of the color components
+	BlendDstRGB         uint32  // Destination blend factor of the color components
+	BlendSrcAlpha       uint32  // Source blend factor of the alpha component
+	BlendDstAlpha       uint32  // Destination blend factor of the alpha component
+}
+
+// SetBlendFunc sets the same source and destination blend
+// factors for the color and alpha components
+func (ps *PipelineState) SetBlendFunc(src, dst uint32) {
+
+	ps.BlendSrcRGB = src
+	ps.BlendDstRGB = dst
+	ps.BlendSrcAlpha = src
+	ps.BlendDstAlpha = dst
+}
+
+// ProgramDesc describes a shader program created by a device
+type ProgramDesc struct {
+	Name          string            // Name of the program shown by graphics debuggers
+	Vertex        string            // Source of the vertex shader
+	Fragment      string            // Source of the fragment shader
+	Outputs       []string          // Names of the fragment shader outputs in draw buffer order
+	UniformBlocks map[string]uint32 // Binding points of the uniform blocks by name
+}
+
+// TextureDesc describes the storage and sampling of a 2D texture,
+// of an array of 2D textures or of a 3D texture
+type TextureDesc struct {
+	Target         uint32 // Texture target: TEXTURE_2D (if not set), TEXTURE_2D_ARRAY or TEXTURE_3D
+	Width          int32  // Width in texels
+	Height         int32  // Height in texels
+	Depth          int32  // Number of layers of array textures or depth in texels of 3D textures
+	InternalFormat int32  // Internal format of the texture storage
+	Format         uint32 // Format of the supplied image data
+	Type           uint32 // Type of the components of the supplied image data
+	Mipmaps        bool   // Generate mipmaps after uploading the image data
+	MagFilter      uint32 // Magnification filter
+	MinFilter      uint32 // Minification filter
+	WrapS          uint32 // Wrap mode of the S coordinate
+	WrapT          uint32 // Wrap mode of the T coordinate
+	WrapR          uint32 // Wrap mode of the R coordinate of 3D textures
+}
+
+// bufferTargets maps buffer kinds to OpenGL buffer targets
+var bufferTargets = [...]uint32{
+	VertexBuffer:  ARRAY_BUFFER,
+	IndexBuffer:   ELEMENT_ARRAY_BUFFER,
+	UniformBuffer: UNIFORM_BUFFER,
+	StorageBuffer: SHADER_STORAGE_BUFFER,
+}
+
+// textureTarget returns the target of the texture described by desc
+func (desc *TextureDesc) textureTarget() uint32 {
+
+	if desc.Target == 0 {
+		return TEXTURE_2D
+	}
+	return desc.Target
+}
+
+// Backend returns the name of the OpenGL API of the current build
+func (gs *GLS) Backend() string {
+
+	return gl.Backend
+}
+
+// CreateBuffer creates a new OpenGL buffer object and returns its handle
+func (gs *GLS) CreateBuffer() uint32 {
+
+	return gs.GenBuffer()
+}
+
+// UploadBuffer binds the specified buffer to the target of its kind
+// and creates its data store with the specified data.
+func (gs *GLS) UploadBuffer(kind BufferKind, buf uint32, size int, data interface{}, usage uint32) {
+
+	target := bufferTargets[kind]
+	gs.BindBuffer(int(target), buf)
+	gs.BufferData(target, size, data, usage)
+}
+
+// DestroyBuffer deletes the specified OpenGL buffer object
+func (gs *GLS) DestroyBuffer(buf uint32) {
+
+	gs.DeleteBuffers(buf)
+}
+
+// BindIndexBuffer binds the specified buffer to the ELEMENT_ARRAY_BUFFER
+// target of the current vertex array object
+func (gs *GLS) BindIndexBuffer(buf uint32) {
+
+	gs.BindBuffer(ELEMENT_ARRAY_BUFFER, buf)
+}
+
+// BindUniformBuffer binds the specified buffer to the
+// specified binding point of the UNIFORM_BUFFER target
+func (gs *GLS) BindUniformBuffer(binding uint32, buf uint32) {
+
+	gs.BindBufferBase(UNIFORM_BUFFER, binding, buf)
+}
+
+// CreateVertexArray creates a new OpenGL vertex array object, binds it and returns its handle
+func (gs *GLS) CreateVertexArray() uint32 {
+
+	vao := gs.GenVertexArray()
+	gs.BindVertexArray(vao)
+	return vao
+}
+
+// SetVertexAttrib enables the vertex attribute at the specified location
+// of the current vertex array and sets its layout in the specified buffer.
+func (gs *GLS) SetVertexAttrib(buf uint32, location uint32, size int32, stride int32, offset uint32) {
+
+	gs.BindBuffer(ARRAY_BUFFER, buf)
+	gs.EnableVertexAttribArray(location)
+	gs.VertexAttribPointer(location, size, FLOAT, false, stride, offset)
+}
+
+// DestroyVertexArray deletes the specified OpenGL vertex array object
+func (gs *GLS) DestroyVertexArray(vao uint32) {
+
+	gs.DeleteVertexArrays(vao)
+}
+
+// CreateTexture creates a new OpenGL texture object and returns its handle
+func (gs *GLS) CreateTexture() uint32 {
+
+	return gs.GenTexture()
+}
+
+// UploadTexture binds the specified texture to the current texture unit and
+// specifies its image, generating its mipmaps if requested.
+// The data of array and 3D textures contains the images of all their layers or slices.
+func (gs *GLS) UploadTexture(tex uint32, desc *TextureDesc, data interface{}) {
+
+	target := desc.textureTarget()
+	gs.BindTexture(int(target), tex)
+	if target == TEXTURE_2D_ARRAY || target == TEXTURE_3D {
+		gs.TexImage3D(target, 0, desc.InternalFormat, desc.Width, desc.Height, desc.Depth, desc.Format, desc.Type, data)
+	} else {
+		gs.TexImage2D(target, 0, desc.InternalFormat, desc.Width, desc.Height, 0, desc.Format, desc.Type, data)
+	}
+	if desc.Mipmaps {
+		gs.GenerateMipmap(target)
+	}
+}
+
+// UploadTextureLayer binds the specified array or 3D texture to the current
+// texture unit and replaces the image of the specified layer or slice,
+// regenerating its mipmaps if requested. The storage of the texture must
+// have been specified by UploadTexture().
+func (gs *GLS) UploadTextureLayer(tex uint32, desc *TextureDesc, layer int, data interface{}) {
+
+	target := desc.textureTarget()
+	gs.BindTexture(int(target), tex)
+	gs.TexSubImage3D(target, 0, 0, 0, int32(layer), desc.Width, desc.Height, 1, desc.Format, desc.Type, data)
+	if desc.Mipmaps {
+		gs.GenerateMipmap(target)
+	}
+}
+
+// SetTextureSampler binds the specified texture to the current texture unit
+// and sets its filters and wrap modes.
+func (gs *GLS) SetTextureSampler(tex uint32, desc *TextureDesc) {
+
+	target := desc.textureTarget()
+	gs.BindTexture(int(target), tex)
+	gs.TexParameteri(target, TEXTURE_MAG_FILTER, int32(desc.MagFilter))
+	gs.TexParameteri(target, TEXTURE_MIN_FILTER, int32(desc.MinFilter))
+	gs.TexParameteri(target, TEXTURE_WRAP_S, int32(desc.WrapS))
+	gs.TexParameteri(target, TEXTURE_WRAP_T, int32(desc.WrapT))
+	if target == TEXTURE_3D {
+		gs.TexParameteri(target, TEXTURE_WRAP_R, int32(desc.WrapR))
+	}
+}
+
+// BindTextureUnit makes the specified texture unit active and binds the specified texture to it
+func (gs *GLS) BindTextureUnit(unit int, tex uint32) {
+
+	gs.BindTextureUnitTarget(unit, TEXTURE_2D, tex)
+}
+
+// BindTextureUnitTarget makes the specified texture unit active and binds
+// the specified texture of the specified target to it. The sampler object
+// bound to the unit, if any, is unbound so the texture is sampled with its
+// own parameters.
+func (gs *GLS) BindTextureUnitTarget(unit int, target uint32, tex uint32) {
+
+	gs.ActiveTexture(uint32(TEXTURE0 + unit))
+	gs.BindTexture(int(target), tex)
+	gs.BindSampler(uint32(unit), 0)
+}
+
+// DestroyTexture deletes the specified OpenGL texture object
+func (gs *GLS) DestroyTexture(tex uint32) {
+
+	gs.DeleteTextures(tex)
+}
+
+// CreateProgram compiles and links an OpenGL program object from the
+// specified description and returns its handle
+func (gs *GLS) CreateProgram(desc *ProgramDesc) (uint32, error) {
+
+	prog := gs.NewProgram()
+	prog.AddShader(VERTEX_SHADER, desc.Vertex, nil)
+	prog.AddShader(FRAGMENT_SHADER, desc.Fragment, nil)
+	for i, name := range desc.Outputs {
+		prog.BindFragDataLocation(uint32(i), name)
+	}
+	err := prog.Build()
+	if err != nil {
+		return 0, err
+	}
+	if desc.Name != "" {
+		gs.ObjectLabel(PROGRAM, prog.Handle(), desc.Name)
+	}
+	for name, binding := range desc.UniformBlocks {
+		prog.SetUniformBlockBinding(name, binding)
+	}
+	gs.progHandles[prog.Handle()] = prog
+	return prog.Handle(), nil
+}
+
+// BindProgram makes the program with the specified handle the current program
+func (gs *GLS) BindProgram(prog uint32) {
+
+	gs.UseProgram(gs.progHandles[prog])
+}
+
+// DestroyProgram deletes the OpenGL program object with the specified handle
+func (gs *GLS) DestroyProgram(prog uint32) {
+
+	p := gs.progHandles[prog]
+	if p == nil {
+		return
+	}
+	delete(gs.progHandles, prog)
+	delete(gs.programs, p)
+	if gs.Prog == p {
+		gs.Prog = nil
+		gs.program = uintUndef
+	}
+	gl.DeleteProgram(prog)
+	gs.checkError("DeleteProgram")
+}
+
+// UniformLocation returns the location of the named uniform of the current program
+func (gs *GLS) UniformLocation(name string) int32 {
+
+	return gs.Prog.GetUniformLocation(name)
+}
+
+// AttribLocation returns the location of the named vertex attribute of the current program
+func (gs *GLS) AttribLocation(name string) int32 {
+
+	return gs.Prog.GetAttribLocation(name)
+}
+
+// CreateFramebuffer creates a new OpenGL framebuffer object and returns its handle
+func (gs *GLS) CreateFramebuffer() uint32 {
+
+	return gs.GenFramebuffer()
+}
+
+// DestroyFramebuffer deletes the specified OpenGL framebuffer object
+func (gs *GLS) DestroyFramebuffer(fbo uint32) {
+
+	gs.DeleteFramebuffers(fbo)
+}
+
+// CreateRenderbuffer creates a new OpenGL renderbuffer object,
+// specifies its storage and returns its handle
+func (gs *GLS) CreateRenderbuffer(iformat uint32, width, height, samples int32) uint32 {
+
+	rbo := gs.GenRenderbuffer()
+	gs.BindRenderbuffer(rbo)
+	if samples > 0 {
+		gs.RenderbufferStorageMultisample(samples, iformat, width, height)
+	} else {
+		gs.RenderbufferStorage(iformat, width, height)
+	}
+	gs.BindRenderbuffer(0)
+	return rbo
+}
+
+// DestroyRenderbuffer deletes the specified OpenGL renderbuffer object
+func (gs *GLS) DestroyRenderbuffer(rbo uint32) {
+
+	gs.DeleteRenderbuffers(rbo)
+}
+
+// CreateMultisampleTexture creates a new OpenGL multisample texture, binds it
+// to the current texture unit and specifies its storage, if supported
+func (gs *GLS) CreateMultisampleTexture(iformat uint32, width, height, samples int32) uint32 {
+
+	if !gs.MultisampleTextureSupported() {
+		return 0
+	}
+	tex := gs.GenTexture()
+	gs.BindTexture(TEXTURE_2D_MULTISAMPLE, tex)
+	gs.TexImage2DMultisample(samples, iformat, width, height, true)
+	return tex
+}
+
+// CreateQuery creates a new OpenGL query object and returns its handle
+func (gs *GLS) CreateQuery() uint32 {
+
+	return gs.GenQuery()
+}
+
+// QueryAvailable returns if the result of the specified query is available
+func (gs *GLS) QueryAvailable(query uint32) bool {
+
+	return gs.GetQueryObjectui(query, QUERY_RESULT_AVAILABLE) != 0
+}
+
+// QueryResult returns the result of the specified query
+func (gs *GLS) QueryResult(query uint32) uint64 {
+
+	return gs.GetQueryObjectui64(query, QUERY_RESULT)
+}
+
+// DestroyQuery deletes the specified OpenGL query object
+func (gs *GLS) DestroyQuery(query uint32) {
+
+	gs.DeleteQueries(query)
+}
+
+// SetScissor enables the scissor test with the specified box or disables it
+func (gs *GLS) SetScissor(enabled bool, x, y, width, height int32) {
+
+	if !enabled {
+		gs.Disable(SCISSOR_TEST)
+		return
+	}
+	gs.Enable(SCISSOR_TEST)
+	gs.Scissor(x, y, width, height)
+}
+
+// SetPipelineState sets the OpenGL states from the specified pipeline state.
+// Only the states which changed are sent to OpenGL.
+func (gs *GLS) SetPipelineState(ps *PipelineState) {
+
+	if ps.CullFace {
+		gs.Enable(CULL_FACE)
+	} else {
+		gs.Disable(CULL_FACE)
+	}
+	gs.FrontFace(ps.FrontFace)
+	if ps.DepthTest {
+		gs.Enable(DEPTH_TEST)
+	} else {
+		gs.Disable(DEPTH_TEST)
+	}
+	gs.DepthMask(ps.DepthMask)
+	gs.DepthFunc(ps.DepthFunc)
+	if ps.Wireframe {
+		gs.PolygonMode(FRONT_AND_BACK, LINE)
+	} else {
+		gs.PolygonMode(FRONT_AND_BACK, FILL)
+	}
+	gs.PolygonOffset(ps.PolygonOffsetFactor, ps.PolygonOffsetUnits)
+	gs.LineWidth(ps.LineWidth)
+	if !ps.Blend {
+		gs.Disable(BLEND)
+		return
+	}
+	gs.Enable(BLEND)
+	gs.BlendEquationSeparate(ps.BlendEquationRGB, ps.BlendEquationAlpha)
+	gs.BlendFuncSeparate(ps.BlendSrcRGB, ps.BlendDstRGB, ps.BlendSrcAlpha, ps.BlendDstAlpha)
+}
+
+// Draw draws count vertices of the current vertex array starting at first
+func (gs *GLS) Draw(mode uint32, first, count int) {
+
+	gs.DrawArrays(mode, int32(first), int32(count))
+}
+
+// DrawIndexed draws count indices of the current vertex array starting at first
+func (gs *GLS) DrawIndexed(mode uint32, first, count int) {
+
+	gs.DrawElements(mode, int32(count), UNSIGNED_INT, 4*uint32(first))
+}
+
+// DrawIndexedIndirect draws the commands of the specified indirect buffer
+// with a single glMultiDrawElementsIndirect call if it is supported
+func (gs *GLS) DrawIndexedIndirect(mode uint32, ib *IndirectBuffer) {
+
+	ib.Draw(gs, mode)
+}
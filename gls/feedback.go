@@ -0,0 +1,198 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+)
+
+// TransformFeedback captures the output variables of the vertex shader into
+// buffer objects, which may then be used as the vertex attributes of the next
+// draw calls. This allows simulations such as GPU particle systems and mesh
+// processing to run on the GPU without compute shaders.
+//
+// The captured variables are set with Program.SetTransformFeedbackVaryings()
+// before the program is built. The capture buffers are set with SetBuffer()
+// and the vertices drawn between Begin() and End() are captured into them.
+// The number of primitives written by the last capture is returned by
+// PrimitivesWritten() and the captured vertices are drawn by Draw().
+type TransformFeedback struct {
+	gs       *GLS     // OpenGL state
+	handle   uint32   // transform feedback object handle
+	query    uint32   // primitives written query handle
+	buffers  []uint32 // capture buffers by binding index
+	mode     uint32   // primitive mode of the current or last capture
+	active   bool     // capture in progress
+	pending  bool     // primitives written query result not read yet
+	written  int      // number of primitives written by the last capture
+	discard  bool     // discards the rasterization of the captured primitives
+	discards bool     // rasterizer discard enabled by the current capture
+}
+
+// NewTransformFeedback creates and returns a pointer to a new transform feedback object
+func (gs *GLS) NewTransformFeedback() *TransformFeedback {
+
+	tf := new(TransformFeedback)
+	tf.gs = gs
+	tf.handle = gl.GenTransformFeedback()
+	gs.checkError("GenTransformFeedback")
+	tf.query = gs.GenQuery()
+	tf.buffers = make([]uint32, 0)
+	return tf
+}
+
+// SetBuffer sets the buffer which receives the captured variables of the
+// specified binding index. With INTERLEAVED_ATTRIBS only the index 0 is used
+// and with SEPARATE_ATTRIBS the index is the position of the variable
+// in the list of captured variables of the program.
+// The buffer must be large enough for all the captured vertices.
+func (tf *TransformFeedback) SetBuffer(index int, buf uint32) {
+
+	for len(tf.buffers) <= index {
+		tf.buffers = append(tf.buffers, 0)
+	}
+	tf.buffers[index] = buf
+	tf.bind()
+	tf.gs.BindBufferBase(TRANSFORM_FEEDBACK_BUFFER, uint32(index), buf)
+	tf.unbind()
+}
+
+// Buffer returns the buffer of the specified binding index or 0 if not set
+func (tf *TransformFeedback) Buffer(index int) uint32 {
+
+	if index >= len(tf.buffers) {
+		return 0
+	}
+	return tf.buffers[index]
+}
+
+// SetRasterizerDiscard sets if the captured primitives are discarded before
+// rasterization, when only the captured data is needed. Default is false.
+func (tf *TransformFeedback) SetRasterizerDiscard(state bool) {
+
+	tf.discard = state
+}
+
+// Begin starts capturing the vertices of the next draw calls, which must use
+// the specified primitive mode: POINTS, LINES or TRIANGLES.
+// The current program must have transform feedback varyings.
+func (tf *TransformFeedback) Begin(primitiveMode uint32) {
+
+	if tf.active {
+		return
+	}
+	tf.bind()
+	if tf.discard {
+		tf.gs.Enable(RASTERIZER_DISCARD)
+		tf.discards = true
+	}
+	tf.gs.BeginQuery(TRANSFORM_FEEDBACK_PRIMITIVES_WRITTEN, tf.query)
+	gl.BeginTransformFeedback(primitiveMode)
+	tf.gs.checkError("BeginTransformFeedback")
+	tf.mode = primitiveMode
+	tf.active = true
+}
+
+// Pause suspends the capture, so vertices may be drawn without being captured
+func (tf *TransformFeedback) Pause() {
+
+	gl.PauseTransformFeedback()
+	tf.gs.checkError("PauseTransformFeedback")
+}
+
+// Resume resumes the capture suspended by Pause()
+func (tf *TransformFeedback) Resume() {
+
+	gl.ResumeTransformFeedback()
+	tf.gs.checkError("ResumeTransformFeedback")
+}
+
+// End ends the current capture
+func (tf *TransformFeedback) End() {
+
+	if !tf.active {
+		return
+	}
+	gl.EndTransformFeedback()
+	tf.gs.checkError("EndTransformFeedback")
+	tf.gs.EndQuery(TRANSFORM_FEEDBACK_PRIMITIVES_WRITTEN)
+	if tf.discards {
+		tf.gs.Disable(RASTERIZER_DISCARD)
+		tf.discards = false
+	}
+	tf.unbind()
+	tf.active = false
+	tf.pending = true
+}
+
+// Active returns if a capture is in progress
+func (tf *TransformFeedback) Active() bool {
+
+	return tf.active
+}
+
+// PrimitivesWritten returns the number of primitives written into the buffers
+// by the last capture. It waits for the capture to complete.
+func (tf *TransformFeedback) PrimitivesWritten() int {
+
+	if tf.pending {
+		tf.written = int(tf.gs.GetQueryObjectui(tf.query, QUERY_RESULT))
+		tf.pending = false
+	}
+	return tf.written
+}
+
+// VerticesWritten returns the number of vertices written into the buffers
+// by the last capture. It waits for the capture to complete.
+func (tf *TransformFeedback) VerticesWritten() int {
+
+	count := tf.PrimitivesWritten()
+	switch tf.mode {
+	case LINES:
+		return count * 2
+	case TRIANGLES:
+		return count * 3
+	}
+	return count
+}
+
+// Draw draws with the specified mode the vertices captured by the last
+// capture, using the current vertex array which must read its attributes
+// from the capture buffers.
+func (tf *TransformFeedback) Draw(mode uint32) {
+
+	count := tf.VerticesWritten()
+	if count == 0 {
+		return
+	}
+	tf.gs.Draw(mode, 0, count)
+}
+
+// Dispose deletes the OpenGL objects of this transform feedback
+func (tf *TransformFeedback) Dispose() {
+
+	if tf.handle == 0 {
+		return
+	}
+	gl.DeleteTransformFeedbacks([]uint32{tf.handle})
+	tf.gs.checkError("DeleteTransformFeedbacks")
+	tf.gs.DeleteQueries(tf.query)
+	tf.handle = 0
+	tf.query = 0
+}
+
+// bind binds this transform feedback object
+func (tf *TransformFeedback) bind() {
+
+	gl.BindTransformFeedback(TRANSFORM_FEEDBACK, tf.handle)
+	tf.gs.checkError("BindTransformFeedback")
+}
+
+// unbind binds the default transform feedback object
+func (tf *TransformFeedback) unbind() {
+
+	gl.BindTransformFeedback(TRANSFORM_FEEDBACK, 0)
+	tf.gs.checkError("BindTransformFeedback")
+}
@@ -0,0 +1,24 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gl is the thin layer between the gls package and the OpenGL API
+// of the target platform. Its functions have the names and semantics of the
+// OpenGL functions, with Go types for strings, slices and buffer offsets.
+//
+// The backend is selected by build tags:
+//
+//	default: OpenGL 3.3 core profile (desktop)
+//	gles3:   OpenGL ES 3.0 (Android, embedded Linux)
+//	js:      WebGL 2.0 (WebAssembly in the browser)
+//
+// The OpenGL ES 3.0 backend has no geometry shaders, no wireframe polygon mode
+// and no timer queries, and its shaders use GLSL ES 3.00 with explicit precision.
+// Compute shaders and shader storage buffers require OpenGL 4.3 or OpenGL ES 3.1
+// contexts, multisample textures require OpenGL ES 3.1 and multi-draw indirect
+// rendering requires OpenGL 4.3 or the EXT_multi_draw_indirect extension of
+// OpenGL ES. The WebGL 2.0 backend has the same restrictions and supports
+// none of them.
+// It references the WebGL objects by handles, so the gls package API is the
+// same for all backends.
+package gl
@@ -0,0 +1,900 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !gles3 && !js
+// +build !gles3,!js
+
+package gl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"strings"
+	"unsafe"
+)
+
+// Backend is the name of the OpenGL API implemented by this backend
+const Backend = "OpenGL 3.3 core"
+
+// GLSLVersion is the version of the shading language used in the #version directive
+const GLSLVersion = "330 core"
+
+// GLSLHeader contains the declarations inserted after the #version directive of all shaders
+const GLSLHeader = ""
+
+// Init initializes the OpenGL functions of the current context
+func Init() error {
+
+	return gl.Init()
+}
+
+// GetString returns the string value of the specified parameter
+func GetString(name uint32) string {
+
+	return gl.GoStr(gl.GetString(name))
+}
+
+// GetInteger returns the integer value of the specified parameter
+func GetInteger(pname uint32) int32 {
+
+	var data int32
+	gl.GetIntegerv(pname, &data)
+	return data
+}
+
+func ActiveTexture(texture uint32) {
+
+	gl.ActiveTexture(texture)
+}
+
+func AttachShader(program, shader uint32) {
+
+	gl.AttachShader(program, shader)
+}
+
+func BeginQuery(target, id uint32) {
+
+	gl.BeginQuery(target, id)
+}
+
+func BindBuffer(target, buffer uint32) {
+
+	gl.BindBuffer(target, buffer)
+}
+
+func BindFramebuffer(target, framebuffer uint32) {
+
+	gl.BindFramebuffer(target, framebuffer)
+}
+
+func BindRenderbuffer(target, renderbuffer uint32) {
+
+	gl.BindRenderbuffer(target, renderbuffer)
+}
+
+func BindTexture(target, texture uint32) {
+
+	gl.BindTexture(target, texture)
+}
+
+func BindVertexArray(array uint32) {
+
+	gl.BindVertexArray(array)
+}
+
+func BlendEquation(mode uint32) {
+
+	gl.BlendEquation(mode)
+}
+
+func BlendEquationSeparate(modeRGB, modeAlpha uint32) {
+
+	gl.BlendEquationSeparate(modeRGB, modeAlpha)
+}
+
+func BlendFunc(sfactor, dfactor uint32) {
+
+	gl.BlendFunc(sfactor, dfactor)
+}
+
+func BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha uint32) {
+
+	gl.BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha)
+}
+
+func BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+
+	gl.BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func CheckFramebufferStatus(target uint32) uint32 {
+
+	return gl.CheckFramebufferStatus(target)
+}
+
+func Clear(mask uint32) {
+
+	gl.Clear(mask)
+}
+
+func ClearColor(red, green, blue, alpha float32) {
+
+	gl.ClearColor(red, green, blue, alpha)
+}
+
+func ClearStencil(s int32) {
+
+	gl.ClearStencil(s)
+}
+
+func ColorMask(red, green, blue, alpha bool) {
+
+	gl.ColorMask(red, green, blue, alpha)
+}
+
+func CompileShader(shader uint32) {
+
+	gl.CompileShader(shader)
+}
+
+func CreateProgram() uint32 {
+
+	return gl.CreateProgram()
+}
+
+func CreateShader(xtype uint32) uint32 {
+
+	return gl.CreateShader(xtype)
+}
+
+func CullFace(mode uint32) {
+
+	gl.CullFace(mode)
+}
+
+func DeleteProgram(program uint32) {
+
+	gl.DeleteProgram(program)
+}
+
+func DeleteShader(shader uint32) {
+
+	gl.DeleteShader(shader)
+}
+
+func DepthFunc(xfunc uint32) {
+
+	gl.DepthFunc(xfunc)
+}
+
+func DepthMask(flag bool) {
+
+	gl.DepthMask(flag)
+}
+
+func DrawArrays(mode uint32, first, count int32) {
+
+	gl.DrawArrays(mode, first, count)
+}
+
+func EnableVertexAttribArray(index uint32) {
+
+	gl.EnableVertexAttribArray(index)
+}
+
+func EndQuery(target uint32) {
+
+	gl.EndQuery(target)
+}
+
+func FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer uint32) {
+
+	gl.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func FramebufferTexture2D(target, attachment, textarget, texture uint32, level int32) {
+
+	gl.FramebufferTexture2D(target, attachment, textarget, texture, level)
+}
+
+func FrontFace(mode uint32) {
+
+	gl.FrontFace(mode)
+}
+
+func GenerateMipmap(target uint32) {
+
+	gl.GenerateMipmap(target)
+}
+
+func GetError() uint32 {
+
+	return gl.GetError()
+}
+
+func LineWidth(width float32) {
+
+	gl.LineWidth(width)
+}
+
+func LinkProgram(program uint32) {
+
+	gl.LinkProgram(program)
+}
+
+func PolygonOffset(factor, units float32) {
+
+	gl.PolygonOffset(factor, units)
+}
+
+func ReadBuffer(src uint32) {
+
+	gl.ReadBuffer(src)
+}
+
+func RenderbufferStorage(target, internalformat uint32, width, height int32) {
+
+	gl.RenderbufferStorage(target, internalformat, width, height)
+}
+
+func RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width, height int32) {
+
+	gl.RenderbufferStorageMultisample(target, samples, internalformat, width, height)
+}
+
+func Scissor(x, y, width, height int32) {
+
+	gl.Scissor(x, y, width, height)
+}
+
+func TexParameteri(target, pname uint32, param int32) {
+
+	gl.TexParameteri(target, pname, param)
+}
+
+func TexStorage2D(target uint32, levels int32, internalformat uint32, width, height int32) {
+
+	gl.TexStorage2D(target, levels, internalformat, width, height)
+}
+
+func Uniform1f(location int32, v0 float32) {
+
+	gl.Uniform1f(location, v0)
+}
+
+func Uniform1i(location, v0 int32) {
+
+	gl.Uniform1i(location, v0)
+}
+
+func Uniform2f(location int32, v0, v1 float32) {
+
+	gl.Uniform2f(location, v0, v1)
+}
+
+func Uniform3f(location int32, v0, v1, v2 float32) {
+
+	gl.Uniform3f(location, v0, v1, v2)
+}
+
+func Uniform4f(location int32, v0, v1, v2, v3 float32) {
+
+	gl.Uniform4f(location, v0, v1, v2, v3)
+}
+
+func UseProgram(program uint32) {
+
+	gl.UseProgram(program)
+}
+
+func Viewport(x, y, width, height int32) {
+
+	gl.Viewport(x, y, width, height)
+}
+
+func Enable(cap uint32) {
+
+	gl.Enable(cap)
+}
+
+func Disable(cap uint32) {
+
+	gl.Disable(cap)
+}
+
+func ClearDepth(depth float32) {
+
+	gl.ClearDepth(float64(depth))
+}
+
+func PolygonMode(face, mode uint32) {
+
+	gl.PolygonMode(face, mode)
+}
+
+func QueryCounter(id, target uint32) {
+
+	gl.QueryCounter(id, target)
+}
+
+// GetQueryObjectui64 returns the 64 bits value of the specified parameter of a query object
+func GetQueryObjectui64(id, pname uint32) uint64 {
+
+	var result uint64
+	gl.GetQueryObjectui64v(id, pname, &result)
+	return result
+}
+
+// BindFragDataLocation binds a fragment shader output variable to a color number
+func BindFragDataLocation(program, color uint32, name string) {
+
+	gl.BindFragDataLocation(program, color, gl.Str(name+"\x00"))
+}
+
+// GetQueryObjectui returns the value of the specified parameter of a query object
+func GetQueryObjectui(id, pname uint32) uint32 {
+
+	var result uint32
+	gl.GetQueryObjectuiv(id, pname, &result)
+	return result
+}
+
+func GenBuffer() uint32 {
+
+	var buf uint32
+	gl.GenBuffers(1, &buf)
+	return buf
+}
+
+func GenFramebuffer() uint32 {
+
+	var fbo uint32
+	gl.GenFramebuffers(1, &fbo)
+	return fbo
+}
+
+func GenQuery() uint32 {
+
+	var query uint32
+	gl.GenQueries(1, &query)
+	return query
+}
+
+func GenRenderbuffer() uint32 {
+
+	var rbo uint32
+	gl.GenRenderbuffers(1, &rbo)
+	return rbo
+}
+
+func GenTexture() uint32 {
+
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	return tex
+}
+
+func GenVertexArray() uint32 {
+
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	return vao
+}
+
+func DeleteBuffers(buffers []uint32) {
+
+	gl.DeleteBuffers(int32(len(buffers)), &buffers[0])
+}
+
+func DeleteFramebuffers(framebuffers []uint32) {
+
+	gl.DeleteFramebuffers(int32(len(framebuffers)), &framebuffers[0])
+}
+
+func DeleteQueries(ids []uint32) {
+
+	gl.DeleteQueries(int32(len(ids)), &ids[0])
+}
+
+func DeleteRenderbuffers(renderbuffers []uint32) {
+
+	gl.DeleteRenderbuffers(int32(len(renderbuffers)), &renderbuffers[0])
+}
+
+func DeleteTextures(textures []uint32) {
+
+	gl.DeleteTextures(int32(len(textures)), &textures[0])
+}
+
+func DeleteVertexArrays(arrays []uint32) {
+
+	gl.DeleteVertexArrays(int32(len(arrays)), &arrays[0])
+}
+
+func DrawBuffers(bufs []uint32) {
+
+	gl.DrawBuffers(int32(len(bufs)), &bufs[0])
+}
+
+// BufferData creates the data store of the buffer bound to the specified target.
+// The data must be a pointer, a slice or an array of numbers.
+func BufferData(target uint32, size int, data interface{}, usage uint32) {
+
+	gl.BufferData(target, size, gl.Ptr(data), usage)
+}
+
+// TexImage2D specifies a two-dimensional texture image.
+// The data must be nil, a pointer, a slice or an array of numbers.
+func TexImage2D(target uint32, level, internalformat, width, height, border int32, format, xtype uint32, data interface{}) {
+
+	gl.TexImage2D(target, level, internalformat, width, height, border, format, xtype, gl.Ptr(data))
+}
+
+// TexImage3D specifies a three-dimensional or array texture image.
+// The data must be nil, a pointer, a slice or an array of numbers.
+func TexImage3D(target uint32, level, internalformat, width, height, depth, border int32, format, xtype uint32, data interface{}) {
+
+	gl.TexImage3D(target, level, internalformat, width, height, depth, border, format, xtype, gl.Ptr(data))
+}
+
+// TexSubImage3D replaces a region of a three-dimensional or array texture image
+func TexSubImage3D(target uint32, level, xoffset, yoffset, zoffset, width, height, depth int32, format, xtype uint32, data interface{}) {
+
+	gl.TexSubImage3D(target, level, xoffset, yoffset, zoffset, width, height, depth, format, xtype, gl.Ptr(data))
+}
+
+func ClearBufferfv(buffer uint32, drawbuffer int32, value []float32) {
+
+	gl.ClearBufferfv(buffer, drawbuffer, &value[0])
+}
+
+// DrawElements renders primitives from the element array buffer starting at the specified byte offset
+func DrawElements(mode uint32, count int32, xtype uint32, offset uint32) {
+
+	gl.DrawElements(mode, count, xtype, gl.PtrOffset(int(offset)))
+}
+
+// VertexAttribPointer specifies the layout of a vertex attribute in the current array buffer
+func VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uint32) {
+
+	gl.VertexAttribPointer(index, size, xtype, normalized, stride, gl.PtrOffset(int(offset)))
+}
+
+func UniformMatrix3fv(location, count int32, transpose bool, value []float32) {
+
+	gl.UniformMatrix3fv(location, count, transpose, &value[0])
+}
+
+func UniformMatrix4fv(location, count int32, transpose bool, value []float32) {
+
+	gl.UniformMatrix4fv(location, count, transpose, &value[0])
+}
+
+// ShaderSource sets the source code of the specified shader
+func ShaderSource(shader uint32, source string) {
+
+	csource, free := gl.Strs(source + "\x00")
+	gl.ShaderSource(shader, 1, csource, nil)
+	free()
+}
+
+func GetShaderiv(shader, pname uint32) int32 {
+
+	var param int32
+	gl.GetShaderiv(shader, pname, &param)
+	return param
+}
+
+// GetShaderInfoLog returns the information log of the specified shader
+func GetShaderInfoLog(shader uint32) string {
+
+	length := GetShaderiv(shader, gl.INFO_LOG_LENGTH)
+	if length == 0 {
+		return ""
+	}
+	log := strings.Repeat("\x00", int(length+1))
+	gl.GetShaderInfoLog(shader, length, nil, gl.Str(log))
+	return strings.TrimRight(log, "\x00")
+}
+
+func GetProgramiv(program, pname uint32) int32 {
+
+	var param int32
+	gl.GetProgramiv(program, pname, &param)
+	return param
+}
+
+// GetProgramInfoLog returns the information log of the specified program
+func GetProgramInfoLog(program uint32) string {
+
+	length := GetProgramiv(program, gl.INFO_LOG_LENGTH)
+	if length == 0 {
+		return ""
+	}
+	log := strings.Repeat("\x00", int(length+1))
+	gl.GetProgramInfoLog(program, length, nil, gl.Str(log))
+	return strings.TrimRight(log, "\x00")
+}
+
+func GetAttribLocation(program uint32, name string) int32 {
+
+	return gl.GetAttribLocation(program, gl.Str(name+"\x00"))
+}
+
+func GetFragDataLocation(program uint32, name string) int32 {
+
+	return gl.GetFragDataLocation(program, gl.Str(name+"\x00"))
+}
+
+func GetUniformLocation(program uint32, name string) int32 {
+
+	return gl.GetUniformLocation(program, gl.Str(name+"\x00"))
+}
+
+func GetUniformBlockIndex(program uint32, name string) uint32 {
+
+	return gl.GetUniformBlockIndex(program, gl.Str(name+"\x00"))
+}
+
+func GetActiveUniformBlockiv(program, index, pname uint32) int32 {
+
+	var param int32
+	gl.GetActiveUniformBlockiv(program, index, pname, &param)
+	return param
+}
+
+// GetUniformIndices returns the indices of the specified uniforms
+func GetUniformIndices(program uint32, names []string) []uint32 {
+
+	cnames := make([]string, len(names))
+	for i, name := range names {
+		cnames[i] = name + "\x00"
+	}
+	unames, free := gl.Strs(cnames...)
+	defer free()
+	indices := make([]uint32, len(names))
+	gl.GetUniformIndices(program, int32(len(names)), unames, &indices[0])
+	return indices
+}
+
+// GetActiveUniformsiv returns the specified parameter of the uniforms with the specified indices
+func GetActiveUniformsiv(program uint32, indices []uint32, pname uint32) []int32 {
+
+	params := make([]int32, len(indices))
+	gl.GetActiveUniformsiv(program, int32(len(indices)), &indices[0], pname, &params[0])
+	return params
+}
+
+// ComputeSupported returns if the current context supports compute shaders:
+// OpenGL 4.3 or the ARB_compute_shader extension.
+func ComputeSupported() bool {
+
+	major := GetInteger(gl.MAJOR_VERSION)
+	minor := GetInteger(gl.MINOR_VERSION)
+	if major > 4 || major == 4 && minor >= 3 {
+		return true
+	}
+	return extensionSupported("GL_ARB_compute_shader")
+}
+
+func DispatchCompute(numGroupsX, numGroupsY, numGroupsZ uint32) {
+
+	gl.DispatchCompute(numGroupsX, numGroupsY, numGroupsZ)
+}
+
+// DispatchComputeIndirect launches compute work groups with the parameters stored
+// at the specified byte offset of the buffer bound to DISPATCH_INDIRECT_BUFFER
+func DispatchComputeIndirect(offset uint32) {
+
+	gl.DispatchComputeIndirect(int(offset))
+}
+
+func MemoryBarrier(barriers uint32) {
+
+	gl.MemoryBarrier(barriers)
+}
+
+func BindImageTexture(unit, texture uint32, level int32, layered bool, layer int32, access, format uint32) {
+
+	gl.BindImageTexture(unit, texture, level, layered, layer, access, format)
+}
+
+// BindBufferBase binds a buffer to the specified index of an indexed buffer target
+func BindBufferBase(target, index, buffer uint32) {
+
+	gl.BindBufferBase(target, index, buffer)
+}
+
+// UniformBlockBinding assigns a binding point to the specified uniform block of a program
+func UniformBlockBinding(program, blockIndex, binding uint32) {
+
+	gl.UniformBlockBinding(program, blockIndex, binding)
+}
+
+// SSBOSupported returns if shader storage buffer objects are supported,
+// which requires OpenGL 4.3 or the GL_ARB_shader_storage_buffer_object extension
+func SSBOSupported() bool {
+
+	major := GetInteger(gl.MAJOR_VERSION)
+	minor := GetInteger(gl.MINOR_VERSION)
+	if major > 4 || major == 4 && minor >= 3 {
+		return true
+	}
+	return extensionSupported("GL_ARB_shader_storage_buffer_object")
+}
+
+// extensionSupported returns if the named extension is supported by the current context
+func extensionSupported(name string) bool {
+
+	count := GetInteger(gl.NUM_EXTENSIONS)
+	for i := int32(0); i < count; i++ {
+		if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i))) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ShaderStorageBlockBinding assigns a binding point to the specified shader storage block of a program
+func ShaderStorageBlockBinding(program, blockIndex, binding uint32) {
+
+	gl.ShaderStorageBlockBinding(program, blockIndex, binding)
+}
+
+// BufferSubData replaces size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset.
+// The data must be a pointer, a slice or an array of numbers.
+func BufferSubData(target uint32, offset, size int, data interface{}) {
+
+	gl.BufferSubData(target, offset, size, gl.Ptr(data))
+}
+
+// GetProgramInterfaceiv returns a parameter of the specified interface of a program
+func GetProgramInterfaceiv(program, programInterface, pname uint32) int32 {
+
+	var param int32
+	gl.GetProgramInterfaceiv(program, programInterface, pname, &param)
+	return param
+}
+
+// GetProgramResourceIndex returns the index of the named resource of
+// the specified interface of a program or INVALID_INDEX if not found
+func GetProgramResourceIndex(program, programInterface uint32, name string) uint32 {
+
+	return gl.GetProgramResourceIndex(program, programInterface, gl.Str(name+"\x00"))
+}
+
+// GetProgramResourceName returns the name of the specified resource of a program
+func GetProgramResourceName(program, programInterface, index uint32) string {
+
+	length := GetProgramResourceiv(program, programInterface, index, []uint32{gl.NAME_LENGTH})[0]
+	if length == 0 {
+		return ""
+	}
+	name := strings.Repeat("\x00", int(length+1))
+	gl.GetProgramResourceName(program, programInterface, index, length, nil, gl.Str(name))
+	return strings.TrimRight(name, "\x00")
+}
+
+// GetProgramResourceiv returns the values of the specified properties
+// of a resource of a program, one value per property
+func GetProgramResourceiv(program, programInterface, index uint32, props []uint32) []int32 {
+
+	params := make([]int32, len(props))
+	gl.GetProgramResourceiv(program, programInterface, index, int32(len(props)), &props[0], int32(len(params)), nil, &params[0])
+	return params
+}
+
+// GenTransformFeedback creates a transform feedback object and returns its handle
+func GenTransformFeedback() uint32 {
+
+	var id uint32
+	gl.GenTransformFeedbacks(1, &id)
+	return id
+}
+
+func DeleteTransformFeedbacks(ids []uint32) {
+
+	gl.DeleteTransformFeedbacks(int32(len(ids)), &ids[0])
+}
+
+func BindTransformFeedback(target, id uint32) {
+
+	gl.BindTransformFeedback(target, id)
+}
+
+func BeginTransformFeedback(primitiveMode uint32) {
+
+	gl.BeginTransformFeedback(primitiveMode)
+}
+
+func EndTransformFeedback() {
+
+	gl.EndTransformFeedback()
+}
+
+func PauseTransformFeedback() {
+
+	gl.PauseTransformFeedback()
+}
+
+func ResumeTransformFeedback() {
+
+	gl.ResumeTransformFeedback()
+}
+
+// TransformFeedbackVaryings specifies the vertex shader outputs
+// captured by transform feedback for the specified program
+func TransformFeedbackVaryings(program uint32, varyings []string, bufferMode uint32) {
+
+	cnames := make([]string, len(varyings))
+	for i, name := range varyings {
+		cnames[i] = name + "\x00"
+	}
+	vnames, free := gl.Strs(cnames...)
+	gl.TransformFeedbackVaryings(program, int32(len(varyings)), vnames, bufferMode)
+	free()
+}
+
+// DebugCallback is the type of the functions which receive the messages of the debug output
+type DebugCallback func(source, xtype, id, severity uint32, message string)
+
+// DebugSupported returns if the debug output, debug groups and object labels
+// are supported, which requires OpenGL 4.3 or the GL_KHR_debug extension
+func DebugSupported() bool {
+
+	major := GetInteger(gl.MAJOR_VERSION)
+	minor := GetInteger(gl.MINOR_VERSION)
+	if major > 4 || major == 4 && minor >= 3 {
+		return true
+	}
+	return extensionSupported("GL_KHR_debug")
+}
+
+// DebugMessageCallback sets the function which receives the messages of the debug output
+func DebugMessageCallback(callback DebugCallback) {
+
+	gl.DebugMessageCallback(func(source, xtype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+		callback(source, xtype, id, severity, message)
+	}, nil)
+}
+
+// PushDebugGroup pushes a named debug group into the command stream
+func PushDebugGroup(source, id uint32, message string) {
+
+	gl.PushDebugGroup(source, id, int32(len(message)), gl.Str(message+"\x00"))
+}
+
+// PopDebugGroup pops the last debug group pushed into the command stream
+func PopDebugGroup() {
+
+	gl.PopDebugGroup()
+}
+
+// ObjectLabel sets the label of the specified object shown by debuggers
+func ObjectLabel(identifier, name uint32, label string) {
+
+	gl.ObjectLabel(identifier, name, int32(len(label)), gl.Str(label+"\x00"))
+}
+
+// MultisampleTextureSupported returns if the current context supports
+// multisample textures, which are part of OpenGL 3.2.
+func MultisampleTextureSupported() bool {
+
+	return true
+}
+
+// TexImage2DMultisample establishes the storage of a multisample texture
+func TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width, height int32, fixedsamplelocations bool) {
+
+	gl.TexImage2DMultisample(target, samples, internalformat, width, height, fixedsamplelocations)
+}
+
+// ReadPixels reads a block of pixels of the current read framebuffer into data
+func ReadPixels(x, y, width, height int32, format, xtype uint32, data interface{}) {
+
+	gl.ReadPixels(x, y, width, height, format, xtype, gl.Ptr(data))
+}
+
+// ReadPixelsOffset reads a block of pixels of the current read framebuffer into
+// the buffer bound to PIXEL_PACK_BUFFER starting at the specified byte offset
+func ReadPixelsOffset(x, y, width, height int32, format, xtype uint32, offset int) {
+
+	gl.ReadPixels(x, y, width, height, format, xtype, gl.PtrOffset(offset))
+}
+
+// PixelStorei sets a pixel storage mode such as PACK_ALIGNMENT
+func PixelStorei(pname uint32, param int32) {
+
+	gl.PixelStorei(pname, param)
+}
+
+// FenceSync inserts a new fence sync object into the command stream and returns its handle
+func FenceSync(condition, flags uint32) uintptr {
+
+	return gl.FenceSync(condition, flags)
+}
+
+// ClientWaitSync waits at most timeout nanoseconds for the specified fence sync object to be signaled
+func ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+
+	return gl.ClientWaitSync(sync, flags, timeout)
+}
+
+// DeleteSync deletes the specified fence sync object
+func DeleteSync(sync uintptr) {
+
+	gl.DeleteSync(sync)
+}
+
+// GetBufferSubData copies size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset into data
+func GetBufferSubData(target uint32, offset, size int, data interface{}) {
+
+	gl.GetBufferSubData(target, offset, size, gl.Ptr(data))
+}
+
+// MultiDrawIndirectSupported returns if the current context supports
+// glMultiDrawElementsIndirect: OpenGL 4.3 or the ARB_multi_draw_indirect extension.
+func MultiDrawIndirectSupported() bool {
+
+	major := GetInteger(gl.MAJOR_VERSION)
+	minor := GetInteger(gl.MINOR_VERSION)
+	if major > 4 || major == 4 && minor >= 3 {
+		return true
+	}
+	return extensionSupported("GL_ARB_multi_draw_indirect")
+}
+
+// MultiDrawElementsIndirect draws the indexed primitives described by drawcount
+// commands stored in the buffer bound to DRAW_INDIRECT_BUFFER starting at the
+// specified byte offset
+func MultiDrawElementsIndirect(mode, xtype uint32, offset int, drawcount, stride int32) {
+
+	gl.MultiDrawElementsIndirect(mode, xtype, gl.PtrOffset(offset), drawcount, stride)
+}
+
+// GenSampler creates and returns a new sampler object
+func GenSampler() uint32 {
+
+	var sampler uint32
+	gl.GenSamplers(1, &sampler)
+	return sampler
+}
+
+// DeleteSamplers deletes the specified sampler objects
+func DeleteSamplers(samplers []uint32) {
+
+	gl.DeleteSamplers(int32(len(samplers)), &samplers[0])
+}
+
+// BindSampler binds the specified sampler object to a texture unit,
+// overriding the sampling parameters of the texture bound to the unit
+func BindSampler(unit, sampler uint32) {
+
+	gl.BindSampler(unit, sampler)
+}
+
+// SamplerParameteri sets an integer parameter of a sampler object
+func SamplerParameteri(sampler, pname uint32, param int32) {
+
+	gl.SamplerParameteri(sampler, pname, param)
+}
+
+// SamplerParameterf sets a float parameter of a sampler object
+func SamplerParameterf(sampler, pname uint32, param float32) {
+
+	gl.SamplerParameterf(sampler, pname, param)
+}
+
+// MaxAnisotropy returns the maximum degree of anisotropic filtering supported
+// by the current context, which is 1 if anisotropic filtering is not supported.
+func MaxAnisotropy() float32 {
+
+	if !extensionSupported("GL_ARB_texture_filter_anisotropic") && !extensionSupported("GL_EXT_texture_filter_anisotropic") {
+		return 1
+	}
+	var max float32
+	gl.GetFloatv(0x84FF, &max) // MAX_TEXTURE_MAX_ANISOTROPY
+	return max
+}
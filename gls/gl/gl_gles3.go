@@ -0,0 +1,902 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build gles3 && !js
+// +build gles3,!js
+
+package gl
+
+import (
+	"github.com/go-gl/gl/v3.1/gles2"
+	"strings"
+	"unsafe"
+)
+
+// Backend is the name of the OpenGL API implemented by this backend
+const Backend = "OpenGL ES 3.0"
+
+// GLSLVersion is the version of the shading language used in the #version directive
+const GLSLVersion = "300 es"
+
+// GLSLHeader contains the declarations inserted after the #version directive of all shaders.
+// OpenGL ES fragment shaders have no default float precision.
+const GLSLHeader = "precision highp float;\nprecision highp int;\nprecision highp sampler2D;\nprecision highp sampler2DArray;\nprecision highp sampler3D;\n"
+
+// Capabilities of desktop OpenGL which are always enabled or not available in OpenGL ES
+const (
+	programPointSize = 0x8642
+	multisample      = 0x809D
+)
+
+// Init initializes the OpenGL functions of the current context
+func Init() error {
+
+	return gles2.Init()
+}
+
+// GetString returns the string value of the specified parameter
+func GetString(name uint32) string {
+
+	return gles2.GoStr(gles2.GetString(name))
+}
+
+// GetInteger returns the integer value of the specified parameter
+func GetInteger(pname uint32) int32 {
+
+	var data int32
+	gles2.GetIntegerv(pname, &data)
+	return data
+}
+
+func ActiveTexture(texture uint32) {
+
+	gles2.ActiveTexture(texture)
+}
+
+func AttachShader(program, shader uint32) {
+
+	gles2.AttachShader(program, shader)
+}
+
+func BeginQuery(target, id uint32) {
+
+	gles2.BeginQuery(target, id)
+}
+
+func BindBuffer(target, buffer uint32) {
+
+	gles2.BindBuffer(target, buffer)
+}
+
+func BindFramebuffer(target, framebuffer uint32) {
+
+	gles2.BindFramebuffer(target, framebuffer)
+}
+
+func BindRenderbuffer(target, renderbuffer uint32) {
+
+	gles2.BindRenderbuffer(target, renderbuffer)
+}
+
+func BindTexture(target, texture uint32) {
+
+	gles2.BindTexture(target, texture)
+}
+
+func BindVertexArray(array uint32) {
+
+	gles2.BindVertexArray(array)
+}
+
+func BlendEquation(mode uint32) {
+
+	gles2.BlendEquation(mode)
+}
+
+func BlendEquationSeparate(modeRGB, modeAlpha uint32) {
+
+	gles2.BlendEquationSeparate(modeRGB, modeAlpha)
+}
+
+func BlendFunc(sfactor, dfactor uint32) {
+
+	gles2.BlendFunc(sfactor, dfactor)
+}
+
+func BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha uint32) {
+
+	gles2.BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha)
+}
+
+func BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+
+	gles2.BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func CheckFramebufferStatus(target uint32) uint32 {
+
+	return gles2.CheckFramebufferStatus(target)
+}
+
+func Clear(mask uint32) {
+
+	gles2.Clear(mask)
+}
+
+func ClearColor(red, green, blue, alpha float32) {
+
+	gles2.ClearColor(red, green, blue, alpha)
+}
+
+func ClearStencil(s int32) {
+
+	gles2.ClearStencil(s)
+}
+
+func ColorMask(red, green, blue, alpha bool) {
+
+	gles2.ColorMask(red, green, blue, alpha)
+}
+
+func CompileShader(shader uint32) {
+
+	gles2.CompileShader(shader)
+}
+
+func CreateProgram() uint32 {
+
+	return gles2.CreateProgram()
+}
+
+func CreateShader(xtype uint32) uint32 {
+
+	return gles2.CreateShader(xtype)
+}
+
+func CullFace(mode uint32) {
+
+	gles2.CullFace(mode)
+}
+
+func DeleteProgram(program uint32) {
+
+	gles2.DeleteProgram(program)
+}
+
+func DeleteShader(shader uint32) {
+
+	gles2.DeleteShader(shader)
+}
+
+func DepthFunc(xfunc uint32) {
+
+	gles2.DepthFunc(xfunc)
+}
+
+func DepthMask(flag bool) {
+
+	gles2.DepthMask(flag)
+}
+
+func DrawArrays(mode uint32, first, count int32) {
+
+	gles2.DrawArrays(mode, first, count)
+}
+
+func EnableVertexAttribArray(index uint32) {
+
+	gles2.EnableVertexAttribArray(index)
+}
+
+func EndQuery(target uint32) {
+
+	gles2.EndQuery(target)
+}
+
+func FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer uint32) {
+
+	gles2.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func FramebufferTexture2D(target, attachment, textarget, texture uint32, level int32) {
+
+	gles2.FramebufferTexture2D(target, attachment, textarget, texture, level)
+}
+
+func FrontFace(mode uint32) {
+
+	gles2.FrontFace(mode)
+}
+
+func GenerateMipmap(target uint32) {
+
+	gles2.GenerateMipmap(target)
+}
+
+func GetError() uint32 {
+
+	return gles2.GetError()
+}
+
+func LineWidth(width float32) {
+
+	gles2.LineWidth(width)
+}
+
+func LinkProgram(program uint32) {
+
+	gles2.LinkProgram(program)
+}
+
+func PolygonOffset(factor, units float32) {
+
+	gles2.PolygonOffset(factor, units)
+}
+
+func ReadBuffer(src uint32) {
+
+	gles2.ReadBuffer(src)
+}
+
+func RenderbufferStorage(target, internalformat uint32, width, height int32) {
+
+	gles2.RenderbufferStorage(target, internalformat, width, height)
+}
+
+func RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width, height int32) {
+
+	gles2.RenderbufferStorageMultisample(target, samples, internalformat, width, height)
+}
+
+func Scissor(x, y, width, height int32) {
+
+	gles2.Scissor(x, y, width, height)
+}
+
+func TexParameteri(target, pname uint32, param int32) {
+
+	gles2.TexParameteri(target, pname, param)
+}
+
+func TexStorage2D(target uint32, levels int32, internalformat uint32, width, height int32) {
+
+	gles2.TexStorage2D(target, levels, internalformat, width, height)
+}
+
+func Uniform1f(location int32, v0 float32) {
+
+	gles2.Uniform1f(location, v0)
+}
+
+func Uniform1i(location, v0 int32) {
+
+	gles2.Uniform1i(location, v0)
+}
+
+func Uniform2f(location int32, v0, v1 float32) {
+
+	gles2.Uniform2f(location, v0, v1)
+}
+
+func Uniform3f(location int32, v0, v1, v2 float32) {
+
+	gles2.Uniform3f(location, v0, v1, v2)
+}
+
+func Uniform4f(location int32, v0, v1, v2, v3 float32) {
+
+	gles2.Uniform4f(location, v0, v1, v2, v3)
+}
+
+func UseProgram(program uint32) {
+
+	gles2.UseProgram(program)
+}
+
+func Viewport(x, y, width, height int32) {
+
+	gles2.Viewport(x, y, width, height)
+}
+
+// Enable enables the specified capability, ignoring the
+// capabilities which are always enabled in OpenGL ES
+func Enable(cap uint32) {
+
+	if cap == programPointSize || cap == multisample {
+		return
+	}
+	gles2.Enable(cap)
+}
+
+// Disable disables the specified capability, ignoring the
+// capabilities which are always enabled in OpenGL ES
+func Disable(cap uint32) {
+
+	if cap == programPointSize || cap == multisample {
+		return
+	}
+	gles2.Disable(cap)
+}
+
+func ClearDepth(depth float32) {
+
+	gles2.ClearDepthf(depth)
+}
+
+// PolygonMode is not supported by OpenGL ES, so polygons are always filled
+func PolygonMode(face, mode uint32) {
+}
+
+// QueryCounter is not supported by OpenGL ES 3.0 and does nothing
+func QueryCounter(id, target uint32) {
+}
+
+// GetQueryObjectui64 is not supported by OpenGL ES 3.0 and returns 0
+func GetQueryObjectui64(id, pname uint32) uint64 {
+
+	return 0
+}
+
+// BindFragDataLocation is not supported by OpenGL ES. The location of
+// fragment shader outputs must be specified with layout qualifiers.
+func BindFragDataLocation(program, color uint32, name string) {
+}
+
+// GetQueryObjectui returns the value of the specified parameter of a query object
+func GetQueryObjectui(id, pname uint32) uint32 {
+
+	var result uint32
+	gles2.GetQueryObjectuiv(id, pname, &result)
+	return result
+}
+
+func GenBuffer() uint32 {
+
+	var buf uint32
+	gles2.GenBuffers(1, &buf)
+	return buf
+}
+
+func GenFramebuffer() uint32 {
+
+	var fbo uint32
+	gles2.GenFramebuffers(1, &fbo)
+	return fbo
+}
+
+func GenQuery() uint32 {
+
+	var query uint32
+	gles2.GenQueries(1, &query)
+	return query
+}
+
+func GenRenderbuffer() uint32 {
+
+	var rbo uint32
+	gles2.GenRenderbuffers(1, &rbo)
+	return rbo
+}
+
+func GenTexture() uint32 {
+
+	var tex uint32
+	gles2.GenTextures(1, &tex)
+	return tex
+}
+
+func GenVertexArray() uint32 {
+
+	var vao uint32
+	gles2.GenVertexArrays(1, &vao)
+	return vao
+}
+
+func DeleteBuffers(buffers []uint32) {
+
+	gles2.DeleteBuffers(int32(len(buffers)), &buffers[0])
+}
+
+func DeleteFramebuffers(framebuffers []uint32) {
+
+	gles2.DeleteFramebuffers(int32(len(framebuffers)), &framebuffers[0])
+}
+
+func DeleteQueries(ids []uint32) {
+
+	gles2.DeleteQueries(int32(len(ids)), &ids[0])
+}
+
+func DeleteRenderbuffers(renderbuffers []uint32) {
+
+	gles2.DeleteRenderbuffers(int32(len(renderbuffers)), &renderbuffers[0])
+}
+
+func DeleteTextures(textures []uint32) {
+
+	gles2.DeleteTextures(int32(len(textures)), &textures[0])
+}
+
+func DeleteVertexArrays(arrays []uint32) {
+
+	gles2.DeleteVertexArrays(int32(len(arrays)), &arrays[0])
+}
+
+func DrawBuffers(bufs []uint32) {
+
+	gles2.DrawBuffers(int32(len(bufs)), &bufs[0])
+}
+
+// BufferData creates the data store of the buffer bound to the specified target.
+// The data must be a pointer, a slice or an array of numbers.
+func BufferData(target uint32, size int, data interface{}, usage uint32) {
+
+	gles2.BufferData(target, size, gles2.Ptr(data), usage)
+}
+
+// TexImage2D specifies a two-dimensional texture image.
+// The data must be nil, a pointer, a slice or an array of numbers.
+func TexImage2D(target uint32, level, internalformat, width, height, border int32, format, xtype uint32, data interface{}) {
+
+	gles2.TexImage2D(target, level, internalformat, width, height, border, format, xtype, gles2.Ptr(data))
+}
+
+// TexImage3D specifies a three-dimensional or array texture image.
+// The data must be nil, a pointer, a slice or an array of numbers.
+func TexImage3D(target uint32, level, internalformat, width, height, depth, border int32, format, xtype uint32, data interface{}) {
+
+	gles2.TexImage3D(target, level, internalformat, width, height, depth, border, format, xtype, gles2.Ptr(data))
+}
+
+// TexSubImage3D replaces a region of a three-dimensional or array texture image
+func TexSubImage3D(target uint32, level, xoffset, yoffset, zoffset, width, height, depth int32, format, xtype uint32, data interface{}) {
+
+	gles2.TexSubImage3D(target, level, xoffset, yoffset, zoffset, width, height, depth, format, xtype, gles2.Ptr(data))
+}
+
+func ClearBufferfv(buffer uint32, drawbuffer int32, value []float32) {
+
+	gles2.ClearBufferfv(buffer, drawbuffer, &value[0])
+}
+
+// DrawElements renders primitives from the element array buffer starting at the specified byte offset
+func DrawElements(mode uint32, count int32, xtype uint32, offset uint32) {
+
+	gles2.DrawElements(mode, count, xtype, gles2.PtrOffset(int(offset)))
+}
+
+// VertexAttribPointer specifies the layout of a vertex attribute in the current array buffer
+func VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uint32) {
+
+	gles2.VertexAttribPointer(index, size, xtype, normalized, stride, gles2.PtrOffset(int(offset)))
+}
+
+func UniformMatrix3fv(location, count int32, transpose bool, value []float32) {
+
+	gles2.UniformMatrix3fv(location, count, transpose, &value[0])
+}
+
+func UniformMatrix4fv(location, count int32, transpose bool, value []float32) {
+
+	gles2.UniformMatrix4fv(location, count, transpose, &value[0])
+}
+
+// ShaderSource sets the source code of the specified shader
+func ShaderSource(shader uint32, source string) {
+
+	csource, free := gles2.Strs(source + "\x00")
+	gles2.ShaderSource(shader, 1, csource, nil)
+	free()
+}
+
+func GetShaderiv(shader, pname uint32) int32 {
+
+	var param int32
+	gles2.GetShaderiv(shader, pname, &param)
+	return param
+}
+
+// GetShaderInfoLog returns the information log of the specified shader
+func GetShaderInfoLog(shader uint32) string {
+
+	length := GetShaderiv(shader, gles2.INFO_LOG_LENGTH)
+	if length == 0 {
+		return ""
+	}
+	log := strings.Repeat("\x00", int(length+1))
+	gles2.GetShaderInfoLog(shader, length, nil, gles2.Str(log))
+	return strings.TrimRight(log, "\x00")
+}
+
+func GetProgramiv(program, pname uint32) int32 {
+
+	var param int32
+	gles2.GetProgramiv(program, pname, &param)
+	return param
+}
+
+// GetProgramInfoLog returns the information log of the specified program
+func GetProgramInfoLog(program uint32) string {
+
+	length := GetProgramiv(program, gles2.INFO_LOG_LENGTH)
+	if length == 0 {
+		return ""
+	}
+	log := strings.Repeat("\x00", int(length+1))
+	gles2.GetProgramInfoLog(program, length, nil, gles2.Str(log))
+	return strings.TrimRight(log, "\x00")
+}
+
+func GetAttribLocation(program uint32, name string) int32 {
+
+	return gles2.GetAttribLocation(program, gles2.Str(name+"\x00"))
+}
+
+func GetFragDataLocation(program uint32, name string) int32 {
+
+	return gles2.GetFragDataLocation(program, gles2.Str(name+"\x00"))
+}
+
+func GetUniformLocation(program uint32, name string) int32 {
+
+	return gles2.GetUniformLocation(program, gles2.Str(name+"\x00"))
+}
+
+func GetUniformBlockIndex(program uint32, name string) uint32 {
+
+	return gles2.GetUniformBlockIndex(program, gles2.Str(name+"\x00"))
+}
+
+func GetActiveUniformBlockiv(program, index, pname uint32) int32 {
+
+	var param int32
+	gles2.GetActiveUniformBlockiv(program, index, pname, &param)
+	return param
+}
+
+// GetUniformIndices returns the indices of the specified uniforms
+func GetUniformIndices(program uint32, names []string) []uint32 {
+
+	cnames := make([]string, len(names))
+	for i, name := range names {
+		cnames[i] = name + "\x00"
+	}
+	unames, free := gles2.Strs(cnames...)
+	defer free()
+	indices := make([]uint32, len(names))
+	gles2.GetUniformIndices(program, int32(len(names)), unames, &indices[0])
+	return indices
+}
+
+// GetActiveUniformsiv returns the specified parameter of the uniforms with the specified indices
+func GetActiveUniformsiv(program uint32, indices []uint32, pname uint32) []int32 {
+
+	params := make([]int32, len(indices))
+	gles2.GetActiveUniformsiv(program, int32(len(indices)), &indices[0], pname, &params[0])
+	return params
+}
+
+// ComputeSupported returns if the current context supports compute shaders,
+// which requires OpenGL ES 3.1.
+func ComputeSupported() bool {
+
+	major := GetInteger(gles2.MAJOR_VERSION)
+	minor := GetInteger(gles2.MINOR_VERSION)
+	return major > 3 || major == 3 && minor >= 1
+}
+
+func DispatchCompute(numGroupsX, numGroupsY, numGroupsZ uint32) {
+
+	gles2.DispatchCompute(numGroupsX, numGroupsY, numGroupsZ)
+}
+
+// DispatchComputeIndirect launches compute work groups with the parameters stored
+// at the specified byte offset of the buffer bound to DISPATCH_INDIRECT_BUFFER
+func DispatchComputeIndirect(offset uint32) {
+
+	gles2.DispatchComputeIndirect(int(offset))
+}
+
+func MemoryBarrier(barriers uint32) {
+
+	gles2.MemoryBarrier(barriers)
+}
+
+func BindImageTexture(unit, texture uint32, level int32, layered bool, layer int32, access, format uint32) {
+
+	gles2.BindImageTexture(unit, texture, level, layered, layer, access, format)
+}
+
+// BindBufferBase binds a buffer to the specified index of an indexed buffer target
+func BindBufferBase(target, index, buffer uint32) {
+
+	gles2.BindBufferBase(target, index, buffer)
+}
+
+// UniformBlockBinding assigns a binding point to the specified uniform block of a program
+func UniformBlockBinding(program, blockIndex, binding uint32) {
+
+	gles2.UniformBlockBinding(program, blockIndex, binding)
+}
+
+// SSBOSupported returns if shader storage buffer objects are supported,
+// which requires OpenGL ES 3.1
+func SSBOSupported() bool {
+
+	return ComputeSupported()
+}
+
+// ShaderStorageBlockBinding does nothing as OpenGL ES does not allow changing
+// the binding points of shader storage blocks, which must be set in the shader
+// with the binding layout qualifier.
+func ShaderStorageBlockBinding(program, blockIndex, binding uint32) {
+}
+
+// BufferSubData replaces size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset.
+// The data must be a pointer, a slice or an array of numbers.
+func BufferSubData(target uint32, offset, size int, data interface{}) {
+
+	gles2.BufferSubData(target, offset, size, gles2.Ptr(data))
+}
+
+// GetProgramInterfaceiv returns a parameter of the specified interface of a program
+func GetProgramInterfaceiv(program, programInterface, pname uint32) int32 {
+
+	var param int32
+	gles2.GetProgramInterfaceiv(program, programInterface, pname, &param)
+	return param
+}
+
+// GetProgramResourceIndex returns the index of the named resource of
+// the specified interface of a program or INVALID_INDEX if not found
+func GetProgramResourceIndex(program, programInterface uint32, name string) uint32 {
+
+	return gles2.GetProgramResourceIndex(program, programInterface, gles2.Str(name+"\x00"))
+}
+
+// GetProgramResourceName returns the name of the specified resource of a program
+func GetProgramResourceName(program, programInterface, index uint32) string {
+
+	length := GetProgramResourceiv(program, programInterface, index, []uint32{gles2.NAME_LENGTH})[0]
+	if length == 0 {
+		return ""
+	}
+	name := strings.Repeat("\x00", int(length+1))
+	gles2.GetProgramResourceName(program, programInterface, index, length, nil, gles2.Str(name))
+	return strings.TrimRight(name, "\x00")
+}
+
+// GetProgramResourceiv returns the values of the specified properties
+// of a resource of a program, one value per property
+func GetProgramResourceiv(program, programInterface, index uint32, props []uint32) []int32 {
+
+	params := make([]int32, len(props))
+	gles2.GetProgramResourceiv(program, programInterface, index, int32(len(props)), &props[0], int32(len(params)), nil, &params[0])
+	return params
+}
+
+// GenTransformFeedback creates a transform feedback object and returns its handle
+func GenTransformFeedback() uint32 {
+
+	var id uint32
+	gles2.GenTransformFeedbacks(1, &id)
+	return id
+}
+
+func DeleteTransformFeedbacks(ids []uint32) {
+
+	gles2.DeleteTransformFeedbacks(int32(len(ids)), &ids[0])
+}
+
+func BindTransformFeedback(target, id uint32) {
+
+	gles2.BindTransformFeedback(target, id)
+}
+
+func BeginTransformFeedback(primitiveMode uint32) {
+
+	gles2.BeginTransformFeedback(primitiveMode)
+}
+
+func EndTransformFeedback() {
+
+	gles2.EndTransformFeedback()
+}
+
+func PauseTransformFeedback() {
+
+	gles2.PauseTransformFeedback()
+}
+
+func ResumeTransformFeedback() {
+
+	gles2.ResumeTransformFeedback()
+}
+
+// TransformFeedbackVaryings specifies the vertex shader outputs
+// captured by transform feedback for the specified program
+func TransformFeedbackVaryings(program uint32, varyings []string, bufferMode uint32) {
+
+	cnames := make([]string, len(varyings))
+	for i, name := range varyings {
+		cnames[i] = name + "\x00"
+	}
+	vnames, free := gles2.Strs(cnames...)
+	gles2.TransformFeedbackVaryings(program, int32(len(varyings)), vnames, bufferMode)
+	free()
+}
+
+// DebugCallback is the type of the functions which receive the messages of the debug output
+type DebugCallback func(source, xtype, id, severity uint32, message string)
+
+// DebugSupported returns if the debug output, debug groups and object labels
+// are supported, which requires the GL_KHR_debug extension
+func DebugSupported() bool {
+
+	return extensionSupported("GL_KHR_debug")
+}
+
+// DebugMessageCallback sets the function which receives the messages of the debug output
+func DebugMessageCallback(callback DebugCallback) {
+
+	gles2.DebugMessageCallbackKHR(func(source, xtype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+		callback(source, xtype, id, severity, message)
+	}, nil)
+}
+
+// PushDebugGroup pushes a named debug group into the command stream
+func PushDebugGroup(source, id uint32, message string) {
+
+	gles2.PushDebugGroupKHR(source, id, int32(len(message)), gles2.Str(message+"\x00"))
+}
+
+// PopDebugGroup pops the last debug group pushed into the command stream
+func PopDebugGroup() {
+
+	gles2.PopDebugGroupKHR()
+}
+
+// ObjectLabel sets the label of the specified object shown by debuggers
+func ObjectLabel(identifier, name uint32, label string) {
+
+	gles2.ObjectLabelKHR(identifier, name, int32(len(label)), gles2.Str(label+"\x00"))
+}
+
+// MultisampleTextureSupported returns if the current context supports
+// multisample textures, which requires OpenGL ES 3.1.
+func MultisampleTextureSupported() bool {
+
+	major := GetInteger(gles2.MAJOR_VERSION)
+	minor := GetInteger(gles2.MINOR_VERSION)
+	return major > 3 || major == 3 && minor >= 1
+}
+
+// TexImage2DMultisample establishes the immutable storage of a multisample texture
+func TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width, height int32, fixedsamplelocations bool) {
+
+	gles2.TexStorage2DMultisample(target, samples, internalformat, width, height, fixedsamplelocations)
+}
+
+// ReadPixels reads a block of pixels of the current read framebuffer into data
+func ReadPixels(x, y, width, height int32, format, xtype uint32, data interface{}) {
+
+	gles2.ReadPixels(x, y, width, height, format, xtype, gles2.Ptr(data))
+}
+
+// ReadPixelsOffset reads a block of pixels of the current read framebuffer into
+// the buffer bound to PIXEL_PACK_BUFFER starting at the specified byte offset
+func ReadPixelsOffset(x, y, width, height int32, format, xtype uint32, offset int) {
+
+	gles2.ReadPixels(x, y, width, height, format, xtype, gles2.PtrOffset(offset))
+}
+
+// PixelStorei sets a pixel storage mode such as PACK_ALIGNMENT
+func PixelStorei(pname uint32, param int32) {
+
+	gles2.PixelStorei(pname, param)
+}
+
+// FenceSync inserts a new fence sync object into the command stream and returns its handle
+func FenceSync(condition, flags uint32) uintptr {
+
+	return gles2.FenceSync(condition, flags)
+}
+
+// ClientWaitSync waits at most timeout nanoseconds for the specified fence sync object to be signaled
+func ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+
+	return gles2.ClientWaitSync(sync, flags, timeout)
+}
+
+// DeleteSync deletes the specified fence sync object
+func DeleteSync(sync uintptr) {
+
+	gles2.DeleteSync(sync)
+}
+
+// GetBufferSubData copies size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset into data
+// OpenGL ES has no glGetBufferSubData so the buffer is mapped for reading.
+func GetBufferSubData(target uint32, offset, size int, data interface{}) {
+
+	ptr := gles2.MapBufferRange(target, offset, size, gles2.MAP_READ_BIT)
+	if ptr == nil {
+		return
+	}
+	copy(unsafe.Slice((*byte)(gles2.Ptr(data)), size), unsafe.Slice((*byte)(ptr), size))
+	gles2.UnmapBuffer(target)
+}
+
+// MultiDrawIndirectSupported returns if the current context supports
+// glMultiDrawElementsIndirect, which requires the EXT_multi_draw_indirect extension.
+func MultiDrawIndirectSupported() bool {
+
+	return extensionSupported("GL_EXT_multi_draw_indirect")
+}
+
+// MultiDrawElementsIndirect draws the indexed primitives described by drawcount
+// commands stored in the buffer bound to DRAW_INDIRECT_BUFFER starting at the
+// specified byte offset
+func MultiDrawElementsIndirect(mode, xtype uint32, offset int, drawcount, stride int32) {
+
+	gles2.MultiDrawElementsIndirectEXT(mode, xtype, gles2.PtrOffset(offset), drawcount, stride)
+}
+
+// GenSampler creates and returns a new sampler object
+func GenSampler() uint32 {
+
+	var sampler uint32
+	gles2.GenSamplers(1, &sampler)
+	return sampler
+}
+
+// DeleteSamplers deletes the specified sampler objects
+func DeleteSamplers(samplers []uint32) {
+
+	gles2.DeleteSamplers(int32(len(samplers)), &samplers[0])
+}
+
+// BindSampler binds the specified sampler object to a texture unit,
+// overriding the sampling parameters of the texture bound to the unit
+func BindSampler(unit, sampler uint32) {
+
+	gles2.BindSampler(unit, sampler)
+}
+
+// SamplerParameteri sets an integer parameter of a sampler object
+func SamplerParameteri(sampler, pname uint32, param int32) {
+
+	gles2.SamplerParameteri(sampler, pname, param)
+}
+
+// SamplerParameterf sets a float parameter of a sampler object
+func SamplerParameterf(sampler, pname uint32, param float32) {
+
+	gles2.SamplerParameterf(sampler, pname, param)
+}
+
+// MaxAnisotropy returns the maximum degree of anisotropic filtering supported
+// by the current context, which is 1 if anisotropic filtering is not supported.
+func MaxAnisotropy() float32 {
+
+	if !extensionSupported("GL_EXT_texture_filter_anisotropic") {
+		return 1
+	}
+	var max float32
+	gles2.GetFloatv(0x84FF, &max) // MAX_TEXTURE_MAX_ANISOTROPY
+	return max
+}
+
+// extensionSupported returns if the named extension is supported by the current context
+func extensionSupported(name string) bool {
+
+	count := GetInteger(gles2.NUM_EXTENSIONS)
+	for i := int32(0); i < count; i++ {
+		if gles2.GoStr(gles2.GetStringi(gles2.EXTENSIONS, uint32(i))) == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1117 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js
+// +build js
+
+package gl
+
+import (
+	"errors"
+	"reflect"
+	"syscall/js"
+	"unsafe"
+)
+
+// Backend is the name of the OpenGL API implemented by this backend
+const Backend = "WebGL 2.0"
+
+// GLSLVersion is the version of the shading language used in the #version directive
+const GLSLVersion = "300 es"
+
+// GLSLHeader contains the declarations inserted after the #version directive of all shaders.
+// GLSL ES fragment shaders have no default float precision.
+const GLSLHeader = "precision highp float;\nprecision highp int;\nprecision highp sampler2D;\nprecision highp sampler2DArray;\nprecision highp sampler3D;\n"
+
+// OpenGL constants used by this backend
+const (
+	programPointSize = 0x8642
+	multisample      = 0x809D
+	infoLogLength    = 0x8B84
+	unsignedShort    = 0x1403
+	unsignedInt      = 0x1405
+	float            = 0x1406
+	halfFloat        = 0x140B
+	invalidIndex     = 0xFFFFFFFF
+)
+
+// handleProperty is the name of the property set in the WebGL objects
+// with their handle, to find the handle of objects returned by WebGL.
+const handleProperty = "g3nHandle"
+
+var (
+	ctx       js.Value   // WebGL2 rendering context
+	objects   []js.Value // WebGL objects by handle (handle 0 is always null)
+	freed     []uint32   // handles of deleted objects available for reuse
+	locations []js.Value // uniform locations by location number
+	locIndex  map[uniformKey]int32
+	scratch   js.Value // reusable array buffer to transfer data to WebGL
+)
+
+// uniformKey identifies a uniform location of a program
+type uniformKey struct {
+	program uint32
+	name    string
+}
+
+// SetContext sets the WebGL2 rendering context to be used by Init().
+// If not set, Init() gets the context from the first canvas of the document,
+// creating the canvas if necessary.
+func SetContext(context js.Value) {
+
+	ctx = context
+}
+
+// Context returns the current WebGL2 rendering context
+func Context() js.Value {
+
+	return ctx
+}
+
+// Init initializes the WebGL2 rendering context
+func Init() error {
+
+	if ctx.Truthy() {
+		return initState()
+	}
+	doc := js.Global().Get("document")
+	if !doc.Truthy() {
+		return errors.New("WebGL2 requires a browser document")
+	}
+	canvas := doc.Call("querySelector", "canvas")
+	if !canvas.Truthy() {
+		canvas = doc.Call("createElement", "canvas")
+		doc.Get("body").Call("appendChild", canvas)
+	}
+	attribs := map[string]interface{}{"antialias": true, "stencil": true}
+	ctx = canvas.Call("getContext", "webgl2", attribs)
+	if !ctx.Truthy() {
+		return errors.New("WebGL2 not supported")
+	}
+	return initState()
+}
+
+// initState initializes the objects tables
+func initState() error {
+
+	objects = []js.Value{js.Null()}
+	freed = freed[0:0]
+	locations = locations[0:0]
+	locIndex = make(map[uniformKey]int32)
+	return nil
+}
+
+// newObject stores the specified WebGL object and returns its handle
+func newObject(obj js.Value) uint32 {
+
+	if !obj.Truthy() {
+		return 0
+	}
+	var handle uint32
+	if len(freed) > 0 {
+		handle = freed[len(freed)-1]
+		freed = freed[:len(freed)-1]
+		objects[handle] = obj
+	} else {
+		handle = uint32(len(objects))
+		objects = append(objects, obj)
+	}
+	obj.Set(handleProperty, handle)
+	return handle
+}
+
+// object returns the WebGL object with the specified handle
+func object(handle uint32) js.Value {
+
+	if handle == 0 || int(handle) >= len(objects) {
+		return js.Null()
+	}
+	return objects[handle]
+}
+
+// deleteObject calls the specified WebGL delete function for the object
+// with the specified handle and releases the handle
+func deleteObject(fname string, handle uint32) {
+
+	if handle == 0 || int(handle) >= len(objects) {
+		return
+	}
+	ctx.Call(fname, objects[handle])
+	objects[handle] = js.Null()
+	freed = append(freed, handle)
+}
+
+// toInt converts the specified value returned by WebGL to an integer.
+// Objects are converted to their handles.
+func toInt(v js.Value) int32 {
+
+	switch v.Type() {
+	case js.TypeBoolean:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case js.TypeNumber:
+		return int32(v.Int())
+	case js.TypeObject:
+		if h := v.Get(handleProperty); h.Type() == js.TypeNumber {
+			return int32(h.Int())
+		}
+		if v.Get("length").Type() == js.TypeNumber && v.Length() > 0 {
+			return toInt(v.Index(0))
+		}
+	}
+	return 0
+}
+
+// bytesOf returns the bytes of the specified data, which must be a pointer,
+// a slice or an array of numbers, limited to size if greater than zero.
+func bytesOf(data interface{}, size int) []byte {
+
+	if data == nil {
+		return nil
+	}
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if size <= 0 {
+			size = int(v.Type().Elem().Size())
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(v.Pointer())), size)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		n := v.Len() * int(v.Type().Elem().Size())
+		if size > 0 && size < n {
+			n = size
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(v.Pointer())), n)
+	case reflect.Array:
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return bytesOf(ptr.Interface(), size)
+	}
+	panic("gl: unsupported data type: " + v.Type().String())
+}
+
+// jsBytes copies the specified bytes to the scratch buffer and returns a
+// view of the copied bytes with the typed array type for the specified
+// OpenGL data type.
+func jsBytes(b []byte, xtype uint32) js.Value {
+
+	if b == nil {
+		return js.Null()
+	}
+	if !scratch.Truthy() || scratch.Get("byteLength").Int() < len(b) {
+		size := 4096
+		for size < len(b) {
+			size *= 2
+		}
+		scratch = js.Global().Get("ArrayBuffer").New(size)
+	}
+	u8 := js.Global().Get("Uint8Array").New(scratch, 0, len(b))
+	js.CopyBytesToJS(u8, b)
+	switch xtype {
+	case float:
+		return js.Global().Get("Float32Array").New(scratch, 0, len(b)/4)
+	case unsignedInt:
+		return js.Global().Get("Uint32Array").New(scratch, 0, len(b)/4)
+	case unsignedShort, halfFloat:
+		return js.Global().Get("Uint16Array").New(scratch, 0, len(b)/2)
+	}
+	return u8
+}
+
+// jsFloats returns a Float32Array with the specified values
+func jsFloats(values []float32) js.Value {
+
+	return jsBytes(bytesOf(values, 0), float)
+}
+
+// jsUints returns an array with the specified values
+func jsUints(values []uint32) js.Value {
+
+	arr := make([]interface{}, len(values))
+	for i, v := range values {
+		arr[i] = v
+	}
+	return js.ValueOf(arr)
+}
+
+// uniformLocation returns the WebGL uniform location object with the specified location number
+func uniformLocation(loc int32) js.Value {
+
+	if loc < 0 || int(loc) >= len(locations) {
+		return js.Null()
+	}
+	return locations[loc]
+}
+
+func GetError() uint32 {
+
+	return uint32(ctx.Call("getError").Int())
+}
+
+// GetString returns the string value of the specified parameter
+func GetString(name uint32) string {
+
+	v := ctx.Call("getParameter", name)
+	if v.Type() != js.TypeString {
+		return ""
+	}
+	return v.String()
+}
+
+// GetInteger returns the integer value of the specified parameter.
+// Bound objects are returned as their handles.
+func GetInteger(pname uint32) int32 {
+
+	return toInt(ctx.Call("getParameter", pname))
+}
+
+func ActiveTexture(texture uint32) {
+
+	ctx.Call("activeTexture", texture)
+}
+
+func AttachShader(program, shader uint32) {
+
+	ctx.Call("attachShader", object(program), object(shader))
+}
+
+func BeginQuery(target, id uint32) {
+
+	ctx.Call("beginQuery", target, object(id))
+}
+
+func BindBuffer(target, buffer uint32) {
+
+	ctx.Call("bindBuffer", target, object(buffer))
+}
+
+func BindFramebuffer(target, framebuffer uint32) {
+
+	ctx.Call("bindFramebuffer", target, object(framebuffer))
+}
+
+func BindRenderbuffer(target, renderbuffer uint32) {
+
+	ctx.Call("bindRenderbuffer", target, object(renderbuffer))
+}
+
+func BindTexture(target, texture uint32) {
+
+	ctx.Call("bindTexture", target, object(texture))
+}
+
+func BindVertexArray(array uint32) {
+
+	ctx.Call("bindVertexArray", object(array))
+}
+
+func BlendEquation(mode uint32) {
+
+	ctx.Call("blendEquation", mode)
+}
+
+func BlendEquationSeparate(modeRGB, modeAlpha uint32) {
+
+	ctx.Call("blendEquationSeparate", modeRGB, modeAlpha)
+}
+
+func BlendFunc(sfactor, dfactor uint32) {
+
+	ctx.Call("blendFunc", sfactor, dfactor)
+}
+
+func BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha uint32) {
+
+	ctx.Call("blendFuncSeparate", srcRGB, dstRGB, srcAlpha, dstAlpha)
+}
+
+func BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask, filter uint32) {
+
+	ctx.Call("blitFramebuffer", srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func CheckFramebufferStatus(target uint32) uint32 {
+
+	return uint32(ctx.Call("checkFramebufferStatus", target).Int())
+}
+
+func Clear(mask uint32) {
+
+	ctx.Call("clear", mask)
+}
+
+func ClearColor(red, green, blue, alpha float32) {
+
+	ctx.Call("clearColor", red, green, blue, alpha)
+}
+
+func ClearDepth(depth float32) {
+
+	ctx.Call("clearDepth", depth)
+}
+
+func ClearStencil(s int32) {
+
+	ctx.Call("clearStencil", s)
+}
+
+func ColorMask(red, green, blue, alpha bool) {
+
+	ctx.Call("colorMask", red, green, blue, alpha)
+}
+
+func CompileShader(shader uint32) {
+
+	ctx.Call("compileShader", object(shader))
+}
+
+func CreateProgram() uint32 {
+
+	return newObject(ctx.Call("createProgram"))
+}
+
+func CreateShader(xtype uint32) uint32 {
+
+	return newObject(ctx.Call("createShader", xtype))
+}
+
+func CullFace(mode uint32) {
+
+	ctx.Call("cullFace", mode)
+}
+
+// DeleteProgram deletes the specified program and its cached uniform locations
+func DeleteProgram(program uint32) {
+
+	for key, loc := range locIndex {
+		if key.program == program {
+			locations[loc] = js.Null()
+			delete(locIndex, key)
+		}
+	}
+	deleteObject("deleteProgram", program)
+}
+
+func DeleteShader(shader uint32) {
+
+	deleteObject("deleteShader", shader)
+}
+
+func DepthFunc(xfunc uint32) {
+
+	ctx.Call("depthFunc", xfunc)
+}
+
+func DepthMask(flag bool) {
+
+	ctx.Call("depthMask", flag)
+}
+
+// Enable enables the specified capability, ignoring the
+// capabilities which are always enabled in WebGL
+func Enable(cap uint32) {
+
+	if cap == programPointSize || cap == multisample {
+		return
+	}
+	ctx.Call("enable", cap)
+}
+
+// Disable disables the specified capability, ignoring the
+// capabilities which are always enabled in WebGL
+func Disable(cap uint32) {
+
+	if cap == programPointSize || cap == multisample {
+		return
+	}
+	ctx.Call("disable", cap)
+}
+
+func DrawArrays(mode uint32, first, count int32) {
+
+	ctx.Call("drawArrays", mode, first, count)
+}
+
+func EnableVertexAttribArray(index uint32) {
+
+	ctx.Call("enableVertexAttribArray", index)
+}
+
+func EndQuery(target uint32) {
+
+	ctx.Call("endQuery", target)
+}
+
+func FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer uint32) {
+
+	ctx.Call("framebufferRenderbuffer", target, attachment, renderbuffertarget, object(renderbuffer))
+}
+
+func FramebufferTexture2D(target, attachment, textarget, texture uint32, level int32) {
+
+	ctx.Call("framebufferTexture2D", target, attachment, textarget, object(texture), level)
+}
+
+func FrontFace(mode uint32) {
+
+	ctx.Call("frontFace", mode)
+}
+
+func GenerateMipmap(target uint32) {
+
+	ctx.Call("generateMipmap", target)
+}
+
+func LineWidth(width float32) {
+
+	ctx.Call("lineWidth", width)
+}
+
+func LinkProgram(program uint32) {
+
+	ctx.Call("linkProgram", object(program))
+}
+
+// PolygonMode is not supported by WebGL, so polygons are always filled
+func PolygonMode(face, mode uint32) {
+}
+
+func PolygonOffset(factor, units float32) {
+
+	ctx.Call("polygonOffset", factor, units)
+}
+
+func ReadBuffer(src uint32) {
+
+	ctx.Call("readBuffer", src)
+}
+
+func RenderbufferStorage(target, internalformat uint32, width, height int32) {
+
+	ctx.Call("renderbufferStorage", target, internalformat, width, height)
+}
+
+func RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width, height int32) {
+
+	ctx.Call("renderbufferStorageMultisample", target, samples, internalformat, width, height)
+}
+
+func Scissor(x, y, width, height int32) {
+
+	ctx.Call("scissor", x, y, width, height)
+}
+
+func TexParameteri(target, pname uint32, param int32) {
+
+	ctx.Call("texParameteri", target, pname, param)
+}
+
+func TexStorage2D(target uint32, levels int32, internalformat uint32, width, height int32) {
+
+	ctx.Call("texStorage2D", target, levels, internalformat, width, height)
+}
+
+func Uniform1f(location int32, v0 float32) {
+
+	ctx.Call("uniform1f", uniformLocation(location), v0)
+}
+
+func Uniform1i(location, v0 int32) {
+
+	ctx.Call("uniform1i", uniformLocation(location), v0)
+}
+
+func Uniform2f(location int32, v0, v1 float32) {
+
+	ctx.Call("uniform2f", uniformLocation(location), v0, v1)
+}
+
+func Uniform3f(location int32, v0, v1, v2 float32) {
+
+	ctx.Call("uniform3f", uniformLocation(location), v0, v1, v2)
+}
+
+func Uniform4f(location int32, v0, v1, v2, v3 float32) {
+
+	ctx.Call("uniform4f", uniformLocation(location), v0, v1, v2, v3)
+}
+
+func UseProgram(program uint32) {
+
+	ctx.Call("useProgram", object(program))
+}
+
+func Viewport(x, y, width, height int32) {
+
+	ctx.Call("viewport", x, y, width, height)
+}
+
+// QueryCounter is not supported by WebGL 2.0 and does nothing
+func QueryCounter(id, target uint32) {
+}
+
+// GetQueryObjectui returns the value of the specified parameter of a query object
+func GetQueryObjectui(id, pname uint32) uint32 {
+
+	return uint32(toInt(ctx.Call("getQueryParameter", object(id), pname)))
+}
+
+// GetQueryObjectui64 returns the 64 bits value of the specified parameter of a query object
+func GetQueryObjectui64(id, pname uint32) uint64 {
+
+	v := ctx.Call("getQueryParameter", object(id), pname)
+	if v.Type() != js.TypeNumber {
+		return 0
+	}
+	return uint64(v.Float())
+}
+
+// BindFragDataLocation is not supported by WebGL. The location of
+// fragment shader outputs must be specified with layout qualifiers.
+func BindFragDataLocation(program, color uint32, name string) {
+}
+
+func GenBuffer() uint32 {
+
+	return newObject(ctx.Call("createBuffer"))
+}
+
+func GenFramebuffer() uint32 {
+
+	return newObject(ctx.Call("createFramebuffer"))
+}
+
+func GenQuery() uint32 {
+
+	return newObject(ctx.Call("createQuery"))
+}
+
+func GenRenderbuffer() uint32 {
+
+	return newObject(ctx.Call("createRenderbuffer"))
+}
+
+func GenTexture() uint32 {
+
+	return newObject(ctx.Call("createTexture"))
+}
+
+func GenVertexArray() uint32 {
+
+	return newObject(ctx.Call("createVertexArray"))
+}
+
+func DeleteBuffers(buffers []uint32) {
+
+	for _, h := range buffers {
+		deleteObject("deleteBuffer", h)
+	}
+}
+
+func DeleteFramebuffers(framebuffers []uint32) {
+
+	for _, h := range framebuffers {
+		deleteObject("deleteFramebuffer", h)
+	}
+}
+
+func DeleteQueries(ids []uint32) {
+
+	for _, h := range ids {
+		deleteObject("deleteQuery", h)
+	}
+}
+
+func DeleteRenderbuffers(renderbuffers []uint32) {
+
+	for _, h := range renderbuffers {
+		deleteObject("deleteRenderbuffer", h)
+	}
+}
+
+func DeleteTextures(textures []uint32) {
+
+	for _, h := range textures {
+		deleteObject("deleteTexture", h)
+	}
+}
+
+func DeleteVertexArrays(arrays []uint32) {
+
+	for _, h := range arrays {
+		deleteObject("deleteVertexArray", h)
+	}
+}
+
+func DrawBuffers(bufs []uint32) {
+
+	ctx.Call("drawBuffers", jsUints(bufs))
+}
+
+// BufferData creates the data store of the buffer bound to the specified target.
+// The data must be a pointer, a slice or an array of numbers.
+func BufferData(target uint32, size int, data interface{}, usage uint32) {
+
+	b := bytesOf(data, size)
+	if b == nil {
+		ctx.Call("bufferData", target, size, usage)
+		return
+	}
+	ctx.Call("bufferData", target, jsBytes(b, 0), usage)
+}
+
+// TexImage2D specifies a two-dimensional texture image.
+// The data must be nil, a pointer, a slice or an array of numbers.
+func TexImage2D(target uint32, level, internalformat, width, height, border int32, format, xtype uint32, data interface{}) {
+
+	pixels := jsBytes(bytesOf(data, 0), xtype)
+	ctx.Call("texImage2D", target, level, internalformat, width, height, border, format, xtype, pixels)
+}
+
+func TexImage3D(target uint32, level, internalformat, width, height, depth, border int32, format, xtype uint32, data interface{}) {
+
+	pixels := jsBytes(bytesOf(data, 0), xtype)
+	ctx.Call("texImage3D", target, level, internalformat, width, height, depth, border, format, xtype, pixels)
+}
+
+func TexSubImage3D(target uint32, level, xoffset, yoffset, zoffset, width, height, depth int32, format, xtype uint32, data interface{}) {
+
+	pixels := jsBytes(bytesOf(data, 0), xtype)
+	ctx.Call("texSubImage3D", target, level, xoffset, yoffset, zoffset, width, height, depth, format, xtype, pixels)
+}
+
+func ClearBufferfv(buffer uint32, drawbuffer int32, value []float32) {
+
+	ctx.Call("clearBufferfv", buffer, drawbuffer, jsFloats(value))
+}
+
+// DrawElements renders primitives from the element array buffer starting at the specified byte offset
+func DrawElements(mode uint32, count int32, xtype uint32, offset uint32) {
+
+	ctx.Call("drawElements", mode, count, xtype, offset)
+}
+
+// VertexAttribPointer specifies the layout of a vertex attribute in the current array buffer
+func VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uint32) {
+
+	ctx.Call("vertexAttribPointer", index, size, xtype, normalized, stride, offset)
+}
+
+func UniformMatrix3fv(location, count int32, transpose bool, value []float32) {
+
+	ctx.Call("uniformMatrix3fv", uniformLocation(location), transpose, jsFloats(value[:9*count]))
+}
+
+func UniformMatrix4fv(location, count int32, transpose bool, value []float32) {
+
+	ctx.Call("uniformMatrix4fv", uniformLocation(location), transpose, jsFloats(value[:16*count]))
+}
+
+// ShaderSource sets the source code of the specified shader
+func ShaderSource(shader uint32, source string) {
+
+	ctx.Call("shaderSource", object(shader), source)
+}
+
+// GetShaderiv returns the specified parameter of the specified shader.
+// The length of the information log is calculated from the log itself,
+// as WebGL does not support querying it.
+func GetShaderiv(shader, pname uint32) int32 {
+
+	if pname == infoLogLength {
+		return logLength(GetShaderInfoLog(shader))
+	}
+	return toInt(ctx.Call("getShaderParameter", object(shader), pname))
+}
+
+// GetShaderInfoLog returns the information log of the specified shader
+func GetShaderInfoLog(shader uint32) string {
+
+	v := ctx.Call("getShaderInfoLog", object(shader))
+	if v.Type() != js.TypeString {
+		return ""
+	}
+	return v.String()
+}
+
+// GetProgramiv returns the specified parameter of the specified program.
+// The length of the information log is calculated from the log itself,
+// as WebGL does not support querying it.
+func GetProgramiv(program, pname uint32) int32 {
+
+	if pname == infoLogLength {
+		return logLength(GetProgramInfoLog(program))
+	}
+	return toInt(ctx.Call("getProgramParameter", object(program), pname))
+}
+
+// GetProgramInfoLog returns the information log of the specified program
+func GetProgramInfoLog(program uint32) string {
+
+	v := ctx.Call("getProgramInfoLog", object(program))
+	if v.Type() != js.TypeString {
+		return ""
+	}
+	return v.String()
+}
+
+// logLength returns the length of the specified log including the null terminator
+func logLength(log string) int32 {
+
+	if len(log) == 0 {
+		return 0
+	}
+	return int32(len(log) + 1)
+}
+
+func GetAttribLocation(program uint32, name string) int32 {
+
+	return int32(ctx.Call("getAttribLocation", object(program), name).Int())
+}
+
+func GetFragDataLocation(program uint32, name string) int32 {
+
+	return int32(ctx.Call("getFragDataLocation", object(program), name).Int())
+}
+
+// GetUniformLocation returns the location number of the specified uniform
+// or -1 if not found. WebGL uniform location objects are kept by this
+// backend and referenced by their location numbers.
+func GetUniformLocation(program uint32, name string) int32 {
+
+	key := uniformKey{program, name}
+	if loc, ok := locIndex[key]; ok {
+		return loc
+	}
+	v := ctx.Call("getUniformLocation", object(program), name)
+	if !v.Truthy() {
+		return -1
+	}
+	loc := int32(len(locations))
+	locations = append(locations, v)
+	locIndex[key] = loc
+	return loc
+}
+
+func GetUniformBlockIndex(program uint32, name string) uint32 {
+
+	return uint32(ctx.Call("getUniformBlockIndex", object(program), name).Int())
+}
+
+func GetActiveUniformBlockiv(program, index, pname uint32) int32 {
+
+	return toInt(ctx.Call("getActiveUniformBlockParameter", object(program), index, pname))
+}
+
+// GetUniformIndices returns the indices of the specified uniforms
+func GetUniformIndices(program uint32, names []string) []uint32 {
+
+	jnames := make([]interface{}, len(names))
+	for i, name := range names {
+		jnames[i] = name
+	}
+	v := ctx.Call("getUniformIndices", object(program), jnames)
+	indices := make([]uint32, len(names))
+	for i := range indices {
+		indices[i] = invalidIndex
+		if v.Truthy() && i < v.Length() {
+			indices[i] = uint32(v.Index(i).Int())
+		}
+	}
+	return indices
+}
+
+// GetActiveUniformsiv returns the specified parameter of the uniforms with the specified indices
+func GetActiveUniformsiv(program uint32, indices []uint32, pname uint32) []int32 {
+
+	v := ctx.Call("getActiveUniforms", object(program), jsUints(indices), pname)
+	params := make([]int32, len(indices))
+	for i := range params {
+		if v.Truthy() && i < v.Length() {
+			params[i] = toInt(v.Index(i))
+		}
+	}
+	return params
+}
+
+// ComputeSupported returns false as WebGL 2.0 does not support compute shaders
+func ComputeSupported() bool {
+
+	return false
+}
+
+// DispatchCompute is not supported by WebGL 2.0 and does nothing
+func DispatchCompute(numGroupsX, numGroupsY, numGroupsZ uint32) {
+}
+
+// DispatchComputeIndirect is not supported by WebGL 2.0 and does nothing
+func DispatchComputeIndirect(offset uint32) {
+}
+
+// MemoryBarrier is not supported by WebGL 2.0 and does nothing
+func MemoryBarrier(barriers uint32) {
+}
+
+// BindImageTexture is not supported by WebGL 2.0 and does nothing
+func BindImageTexture(unit, texture uint32, level int32, layered bool, layer int32, access, format uint32) {
+}
+
+// BindBufferBase binds a buffer to the specified index of an indexed buffer target
+func BindBufferBase(target, index, buffer uint32) {
+
+	ctx.Call("bindBufferBase", target, index, object(buffer))
+}
+
+// UniformBlockBinding assigns a binding point to the specified uniform block of a program
+func UniformBlockBinding(program, blockIndex, binding uint32) {
+
+	ctx.Call("uniformBlockBinding", object(program), blockIndex, binding)
+}
+
+// SSBOSupported returns false as WebGL 2.0 does not support shader storage buffer objects
+func SSBOSupported() bool {
+
+	return false
+}
+
+// ShaderStorageBlockBinding is not supported by WebGL 2.0 and does nothing
+func ShaderStorageBlockBinding(program, blockIndex, binding uint32) {
+}
+
+// BufferSubData replaces size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset.
+// The data must be a pointer, a slice or an array of numbers.
+func BufferSubData(target uint32, offset, size int, data interface{}) {
+
+	b := bytesOf(data, size)
+	if b == nil {
+		return
+	}
+	ctx.Call("bufferSubData", target, offset, jsBytes(b, 0))
+}
+
+// GetProgramInterfaceiv is not supported by WebGL 2.0 and returns 0
+func GetProgramInterfaceiv(program, programInterface, pname uint32) int32 {
+
+	return 0
+}
+
+// GetProgramResourceIndex is not supported by WebGL 2.0 and returns INVALID_INDEX
+func GetProgramResourceIndex(program, programInterface uint32, name string) uint32 {
+
+	return invalidIndex
+}
+
+// GetProgramResourceName is not supported by WebGL 2.0 and returns an empty string
+func GetProgramResourceName(program, programInterface, index uint32) string {
+
+	return ""
+}
+
+// GetProgramResourceiv is not supported by WebGL 2.0 and returns zero values
+func GetProgramResourceiv(program, programInterface, index uint32, props []uint32) []int32 {
+
+	return make([]int32, len(props))
+}
+
+// GenTransformFeedback creates a transform feedback object and returns its handle
+func GenTransformFeedback() uint32 {
+
+	return newObject(ctx.Call("createTransformFeedback"))
+}
+
+func DeleteTransformFeedbacks(ids []uint32) {
+
+	for _, h := range ids {
+		deleteObject("deleteTransformFeedback", h)
+	}
+}
+
+func BindTransformFeedback(target, id uint32) {
+
+	ctx.Call("bindTransformFeedback", target, object(id))
+}
+
+func BeginTransformFeedback(primitiveMode uint32) {
+
+	ctx.Call("beginTransformFeedback", primitiveMode)
+}
+
+func EndTransformFeedback() {
+
+	ctx.Call("endTransformFeedback")
+}
+
+func PauseTransformFeedback() {
+
+	ctx.Call("pauseTransformFeedback")
+}
+
+func ResumeTransformFeedback() {
+
+	ctx.Call("resumeTransformFeedback")
+}
+
+// TransformFeedbackVaryings specifies the vertex shader outputs
+// captured by transform feedback for the specified program
+func TransformFeedbackVaryings(program uint32, varyings []string, bufferMode uint32) {
+
+	jnames := make([]interface{}, len(varyings))
+	for i, name := range varyings {
+		jnames[i] = name
+	}
+	ctx.Call("transformFeedbackVaryings", object(program), jnames, bufferMode)
+}
+
+// DebugCallback is the type of the functions which receive the messages of the debug output
+type DebugCallback func(source, xtype, id, severity uint32, message string)
+
+// DebugSupported returns false as WebGL 2.0 has no debug output, debug groups or object labels
+func DebugSupported() bool {
+
+	return false
+}
+
+// DebugMessageCallback is not supported by WebGL 2.0 and does nothing
+func DebugMessageCallback(callback DebugCallback) {
+}
+
+// PushDebugGroup is not supported by WebGL 2.0 and does nothing
+func PushDebugGroup(source, id uint32, message string) {
+}
+
+// PopDebugGroup is not supported by WebGL 2.0 and does nothing
+func PopDebugGroup() {
+}
+
+// ObjectLabel is not supported by WebGL 2.0 and does nothing
+func ObjectLabel(identifier, name uint32, label string) {
+}
+
+// MultisampleTextureSupported returns false as WebGL 2.0 does not support multisample textures
+func MultisampleTextureSupported() bool {
+
+	return false
+}
+
+// TexImage2DMultisample is not supported by WebGL 2.0 and does nothing
+func TexImage2DMultisample(target uint32, samples int32, internalformat uint32, width, height int32, fixedsamplelocations bool) {
+}
+
+// ReadPixels reads a block of pixels of the current read framebuffer into data
+func ReadPixels(x, y, width, height int32, format, xtype uint32, data interface{}) {
+
+	b := bytesOf(data, 0)
+	if b == nil {
+		return
+	}
+	pixels := jsBytes(b, xtype)
+	ctx.Call("readPixels", x, y, width, height, format, xtype, pixels)
+	js.CopyBytesToGo(b, js.Global().Get("Uint8Array").New(pixels.Get("buffer"), 0, len(b)))
+}
+
+// ReadPixelsOffset reads a block of pixels of the current read framebuffer into
+// the buffer bound to PIXEL_PACK_BUFFER starting at the specified byte offset
+func ReadPixelsOffset(x, y, width, height int32, format, xtype uint32, offset int) {
+
+	ctx.Call("readPixels", x, y, width, height, format, xtype, offset)
+}
+
+func PixelStorei(pname uint32, param int32) {
+
+	ctx.Call("pixelStorei", pname, param)
+}
+
+// FenceSync inserts a new fence sync object into the command stream and returns its handle
+func FenceSync(condition, flags uint32) uintptr {
+
+	return uintptr(newObject(ctx.Call("fenceSync", condition, flags)))
+}
+
+// ClientWaitSync checks if the specified fence sync object is signaled.
+// WebGL does not allow waiting so the timeout should be 0.
+func ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+
+	return uint32(ctx.Call("clientWaitSync", object(uint32(sync)), flags, timeout).Int())
+}
+
+func DeleteSync(sync uintptr) {
+
+	deleteObject("deleteSync", uint32(sync))
+}
+
+// GetBufferSubData copies size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset into data
+func GetBufferSubData(target uint32, offset, size int, data interface{}) {
+
+	b := bytesOf(data, size)
+	if b == nil {
+		return
+	}
+	dst := jsBytes(b, 0)
+	ctx.Call("getBufferSubData", target, offset, dst)
+	js.CopyBytesToGo(b, dst)
+}
+
+// MultiDrawIndirectSupported returns false as WebGL 2.0 does not support indirect draws
+func MultiDrawIndirectSupported() bool {
+
+	return false
+}
+
+// MultiDrawElementsIndirect is not supported by WebGL 2.0 and does nothing
+func MultiDrawElementsIndirect(mode, xtype uint32, offset int, drawcount, stride int32) {
+}
+
+// GenSampler creates and returns a new sampler object
+func GenSampler() uint32 {
+
+	return newObject(ctx.Call("createSampler"))
+}
+
+// DeleteSamplers deletes the specified sampler objects
+func DeleteSamplers(samplers []uint32) {
+
+	for _, h := range samplers {
+		deleteObject("deleteSampler", h)
+	}
+}
+
+// BindSampler binds the specified sampler object to a texture unit,
+// overriding the sampling parameters of the texture bound to the unit
+func BindSampler(unit, sampler uint32) {
+
+	ctx.Call("bindSampler", unit, object(sampler))
+}
+
+// SamplerParameteri sets an integer parameter of a sampler object
+func SamplerParameteri(sampler, pname uint32, param int32) {
+
+	ctx.Call("samplerParameteri", object(sampler), pname, param)
+}
+
+// SamplerParameterf sets a float parameter of a sampler object
+func SamplerParameterf(sampler, pname uint32, param float32) {
+
+	ctx.Call("samplerParameterf", object(sampler), pname, param)
+}
+
+// MaxAnisotropy enables the EXT_texture_filter_anisotropic extension and
+// returns the maximum degree of anisotropic filtering, which is 1 if the
+// extension is not supported.
+func MaxAnisotropy() float32 {
+
+	if !ctx.Call("getExtension", "EXT_texture_filter_anisotropic").Truthy() {
+		return 1
+	}
+	return float32(ctx.Call("getParameter", 0x84FF).Float()) // MAX_TEXTURE_MAX_ANISOTROPY
+}
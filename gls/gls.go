@@ -6,8 +6,8 @@
 package gls
 
 import (
+	"github.com/g3n/engine/gls/gl"
 	"github.com/g3n/engine/util/logger"
-	"github.com/go-gl/gl/v3.3-core/gl"
 	"math"
 )
 
@@ -20,19 +20,29 @@ type GLS struct {
 		Vaos     int // Number of Vertex Array Objects
 		Vbos     int // Number of Vertex Buffer Objects
 		Textures int // Number of Textures
+		Fbos     int // Number of Frame Buffer Objects
+		Rbos     int // Number of Render Buffer Objects
+		Queries  int // Number of Query Objects
 	}
-	Prog               *Program          // Current active program
-	programs           map[*Program]bool // Programs cache
-	checkErrors        bool              // Check openGL API errors flag
+	Prog               *Program            // Current active program
+	programs           map[*Program]bool   // Programs cache
+	progHandles        map[uint32]*Program // Programs created by CreateProgram by handle
+	checkErrors        bool                // Check openGL API errors flag
 	viewportX          int32
 	viewportY          int32
 	viewportWidth      int32
 	viewportHeight     int32
+	clearColor         [4]float32
 	lineWidth          float32
 	sideView           int
 	depthFunc          uint32
 	depthMask          int
 	capabilities       map[int]int
+	compute            int // compute shaders support (capUndef, capDisabled or capEnabled)
+	ssbo               int // shader storage buffer objects support (capUndef, capDisabled or capEnabled)
+	debug              int // debug output and labels support (capUndef, capDisabled or capEnabled)
+	msTexture          int // multisample textures support (capUndef, capDisabled or capEnabled)
+	multiDraw          int // multi-draw indirect support (capUndef, capDisabled or capEnabled)
 	blendEquation      uint32
 	blendSrc           uint32
 	blendDst           uint32
@@ -42,6 +52,34 @@ type GLS struct {
 	blendSrcAlpha      uint32
 	blendDstRGB        uint32
 	blendDstAlpha      uint32
+	program            uint32                    // handle of the current program
+	activeTexture      uint32                    // active texture unit
+	textures           map[textureBinding]uint32 // textures bound to each unit and target
+	buffers            map[uint32]uint32         // buffers bound to each target
+	bufferBases        map[bufferBinding]uint32  // buffers bound to each indexed target binding point
+	vao                uint32                    // current vertex array object
+	drawFramebuffer    uint32                    // framebuffer bound to DRAW_FRAMEBUFFER
+	readFramebuffer    uint32                    // framebuffer bound to READ_FRAMEBUFFER
+	frontFace          uint32
+	polygonMode        int
+	polygonOffset      [2]float32
+	colorMask          uint32
+	viewportSet        bool
+	samplers           map[uint32]uint32       // sampler objects bound to each texture unit
+	samplerObjects     map[SamplerState]uint32 // shared sampler objects by sampling state
+	maxAnisotropy      float32                 // maximum degree of anisotropy (0 = unknown)
+}
+
+// textureBinding is the key of the textures bound to each texture unit and target
+type textureBinding struct {
+	unit   uint32
+	target uint32
+}
+
+// bufferBinding is the key of the buffers bound to each indexed buffer target binding point
+type bufferBinding struct {
+	target uint32
+	index  uint32
 }
 
 const (
@@ -62,6 +100,10 @@ const (
 	DoubleSide
 )
 
+// GLSLVersion is the version of the shading language of the current
+// OpenGL backend, to be used in the #version directive of the shaders.
+const GLSLVersion = gl.GLSLVersion
+
 // Package logger
 var log = logger.New("GLS", logger.Default)
 
@@ -101,13 +143,25 @@ func (gs *GLS) CheckErrors() bool {
 // Reset resets the internal state kept of the OpenGL
 func (gs *GLS) Reset() {
 
+	gs.programs = make(map[*Program]bool)
+	gs.progHandles = make(map[uint32]*Program)
+	gs.Prog = nil
+	gs.samplerObjects = make(map[SamplerState]uint32)
+	gs.maxAnisotropy = 0
+	gs.Invalidate()
+}
+
+// Invalidate discards the OpenGL state kept to skip redundant calls,
+// so the next calls which set the state are always sent to OpenGL.
+// It must be called after external code, such as another library sharing
+// the OpenGL context, changed the state without using this object.
+func (gs *GLS) Invalidate() {
+
 	gs.lineWidth = 0.0
 	gs.sideView = uintUndef
 	gs.depthFunc = 0
 	gs.depthMask = uintUndef
 	gs.capabilities = make(map[int]int)
-	gs.programs = make(map[*Program]bool)
-	gs.Prog = nil
 
 	gs.blendEquation = uintUndef
 	gs.blendSrc = uintUndef
@@ -118,48 +172,155 @@ func (gs *GLS) Reset() {
 	gs.blendSrcAlpha = uintUndef
 	gs.blendDstRGB = uintUndef
 	gs.blendDstAlpha = uintUndef
+
+	gs.program = uintUndef
+	gs.activeTexture = uintUndef
+	gs.textures = make(map[textureBinding]uint32)
+	gs.buffers = make(map[uint32]uint32)
+	gs.bufferBases = make(map[bufferBinding]uint32)
+	gs.vao = uintUndef
+	gs.drawFramebuffer = uintUndef
+	gs.readFramebuffer = uintUndef
+	gs.frontFace = uintUndef
+	gs.polygonMode = uintUndef
+	gs.polygonOffset = [2]float32{float32(math.NaN()), float32(math.NaN())}
+	gs.colorMask = uintUndef
+	gs.viewportSet = false
+	gs.samplers = make(map[uint32]uint32)
 }
 
 func (gs *GLS) SetDefaultState() {
 
-	gl.ClearColor(0, 0, 0, 1)
+	gs.ClearColor(0, 0, 0, 1)
 	gl.ClearDepth(1)
 	gl.ClearStencil(0)
-	gs.Enable(gl.DEPTH_TEST)
-	gs.DepthFunc(gl.LEQUAL)
-	gl.FrontFace(gl.CCW)
-	gl.CullFace(gl.BACK)
-	gs.Enable(gl.CULL_FACE)
-	gs.Enable(gl.BLEND)
-	gs.BlendEquation(gl.FUNC_ADD)
-	gs.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-	gs.Enable(gl.VERTEX_PROGRAM_POINT_SIZE)
-	gs.Enable(gl.PROGRAM_POINT_SIZE)
-	gs.Enable(gl.MULTISAMPLE)
+	gs.Enable(DEPTH_TEST)
+	gs.DepthFunc(LEQUAL)
+	gs.FrontFace(CCW)
+	gl.CullFace(BACK)
+	gs.Enable(CULL_FACE)
+	gs.Enable(BLEND)
+	gs.BlendEquation(FUNC_ADD)
+	gs.BlendFunc(SRC_ALPHA, ONE_MINUS_SRC_ALPHA)
+	gs.Enable(VERTEX_PROGRAM_POINT_SIZE)
+	gs.Enable(PROGRAM_POINT_SIZE)
+	gs.Enable(MULTISAMPLE)
 }
 
 func (gs *GLS) ActiveTexture(texture uint32) {
 
+	if gs.activeTexture == texture {
+		return
+	}
 	gl.ActiveTexture(texture)
 	gs.checkError("ActiveTexture")
+	gs.activeTexture = texture
+}
+
+// BeginQuery starts the specified query object for the specified target
+// such as gls.ANY_SAMPLES_PASSED or gls.TIME_ELAPSED
+func (gs *GLS) BeginQuery(target uint32, query uint32) {
+
+	gl.BeginQuery(target, query)
+	gs.checkError("BeginQuery")
 }
 
 func (gs *GLS) BindBuffer(target int, vbo uint32) {
 
+	// The transform feedback buffer binding is kept by the transform feedback object
+	cached := uint32(target) != TRANSFORM_FEEDBACK_BUFFER
+	if cached {
+		if bound, ok := gs.buffers[uint32(target)]; ok && bound == vbo {
+			return
+		}
+	}
 	gl.BindBuffer(uint32(target), vbo)
 	gs.checkError("BindBuffer")
+	if cached {
+		gs.buffers[uint32(target)] = vbo
+	}
+}
+
+// BindBufferBase binds a buffer object to the specified binding point
+// of an indexed buffer target such as UNIFORM_BUFFER.
+// The buffer is also bound to the generic binding point of the target.
+func (gs *GLS) BindBufferBase(target, index, buffer uint32) {
+
+	// The transform feedback buffer bindings are kept by the transform feedback object
+	if target == TRANSFORM_FEEDBACK_BUFFER {
+		gl.BindBufferBase(target, index, buffer)
+		gs.checkError("BindBufferBase")
+		return
+	}
+	key := bufferBinding{target, index}
+	if bound, ok := gs.bufferBases[key]; ok && bound == buffer {
+		return
+	}
+	gl.BindBufferBase(target, index, buffer)
+	gs.checkError("BindBufferBase")
+	gs.bufferBases[key] = buffer
+	gs.buffers[target] = buffer
+}
+
+func (gs *GLS) BindFramebuffer(target uint32, fbo uint32) {
+
+	switch target {
+	case DRAW_FRAMEBUFFER:
+		if gs.drawFramebuffer == fbo {
+			return
+		}
+	case READ_FRAMEBUFFER:
+		if gs.readFramebuffer == fbo {
+			return
+		}
+	default:
+		if gs.drawFramebuffer == fbo && gs.readFramebuffer == fbo {
+			return
+		}
+	}
+	gl.BindFramebuffer(target, fbo)
+	gs.checkError("BindFramebuffer")
+	if target != READ_FRAMEBUFFER {
+		gs.drawFramebuffer = fbo
+	}
+	if target != DRAW_FRAMEBUFFER {
+		gs.readFramebuffer = fbo
+	}
+}
+
+func (gs *GLS) BindRenderbuffer(rbo uint32) {
+
+	gl.BindRenderbuffer(RENDERBUFFER, rbo)
+	gs.checkError("BindRenderbuffer")
 }
 
 func (gs *GLS) BindTexture(target int, tex uint32) {
 
+	// The bindings are only known after the active texture unit was set
+	cached := gs.activeTexture != uintUndef
+	key := textureBinding{gs.activeTexture, uint32(target)}
+	if cached {
+		if bound, ok := gs.textures[key]; ok && bound == tex {
+			return
+		}
+	}
 	gl.BindTexture(uint32(target), tex)
 	gs.checkError("BindTexture")
+	if cached {
+		gs.textures[key] = tex
+	}
 }
 
 func (gs *GLS) BindVertexArray(vao uint32) {
 
+	if gs.vao == vao {
+		return
+	}
 	gl.BindVertexArray(vao)
 	gs.checkError("BindVertexArray")
+	gs.vao = vao
+	// The index buffer binding is kept by the vertex array object
+	delete(gs.buffers, ELEMENT_ARRAY_BUFFER)
 }
 
 func (gs *GLS) BlendEquation(mode uint32) {
@@ -208,15 +369,45 @@ func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32,
 	gs.blendDstAlpha = dstAlpha
 }
 
+func (gs *GLS) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+
+	gl.BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+	gs.checkError("BlitFramebuffer")
+}
+
 func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint32) {
 
-	gl.BufferData(target, size, gl.Ptr(data), usage)
+	gl.BufferData(target, size, data, usage)
 	gs.checkError("BufferData")
 }
 
+// BufferSubData replaces size bytes of the data store of the buffer bound
+// to the specified target starting at the specified byte offset.
+func (gs *GLS) BufferSubData(target uint32, offset, size int, data interface{}) {
+
+	gl.BufferSubData(target, offset, size, data)
+	gs.checkError("BufferSubData")
+}
+
+// CheckFramebufferStatus returns the completeness status of the
+// framebuffer currently bound to the specified target.
+func (gs *GLS) CheckFramebufferStatus(target uint32) uint32 {
+
+	status := gl.CheckFramebufferStatus(target)
+	gs.checkError("CheckFramebufferStatus")
+	return status
+}
+
 func (gs *GLS) ClearColor(r, g, b, a float32) {
 
 	gl.ClearColor(r, g, b, a)
+	gs.clearColor = [4]float32{r, g, b, a}
+}
+
+// GetClearColor returns the last color set by ClearColor
+func (gs *GLS) GetClearColor() (r, g, b, a float32) {
+
+	return gs.clearColor[0], gs.clearColor[1], gs.clearColor[2], gs.clearColor[3]
 }
 
 func (gs *GLS) Clear(mask int) {
@@ -224,23 +415,126 @@ func (gs *GLS) Clear(mask int) {
 	gl.Clear(uint32(mask))
 }
 
+// ClearBuffer clears the specified draw buffer of the current framebuffer
+// to the specified values. For color buffers the value must have 4 elements.
+func (gs *GLS) ClearBuffer(buffer uint32, drawbuffer int32, value ...float32) {
+
+	gl.ClearBufferfv(buffer, drawbuffer, value)
+	gs.checkError("ClearBuffer")
+}
+
+// ClientWaitSync waits at most timeout nanoseconds for the specified fence
+// sync object to be signaled and returns ALREADY_SIGNALED, CONDITION_SATISFIED,
+// TIMEOUT_EXPIRED or WAIT_FAILED. A zero timeout only checks the fence.
+func (gs *GLS) ClientWaitSync(sync uintptr, timeout uint64) uint32 {
+
+	status := gl.ClientWaitSync(sync, SYNC_FLUSH_COMMANDS_BIT, timeout)
+	gs.checkError("ClientWaitSync")
+	return status
+}
+
+// ColorMask enables or disables writing of the color components into the framebuffer
+func (gs *GLS) ColorMask(red, green, blue, alpha bool) {
+
+	var mask uint32
+	for i, flag := range [4]bool{red, green, blue, alpha} {
+		if flag {
+			mask |= 1 << uint(i)
+		}
+	}
+	if gs.colorMask == mask {
+		return
+	}
+	gl.ColorMask(red, green, blue, alpha)
+	gs.checkError("ColorMask")
+	gs.colorMask = mask
+}
+
 func (gs *GLS) DeleteBuffers(vbos ...uint32) {
 
-	gl.DeleteBuffers(int32(len(vbos)), &vbos[0])
+	gl.DeleteBuffers(vbos)
 	gs.checkError("DeleteBuffers")
+
+	// Forgets the bindings of the deleted buffers, whose handles may be reused
+	for _, vbo := range vbos {
+		for target, bound := range gs.buffers {
+			if bound == vbo {
+				delete(gs.buffers, target)
+			}
+		}
+		for key, bound := range gs.bufferBases {
+			if bound == vbo {
+				delete(gs.bufferBases, key)
+			}
+		}
+	}
+}
+
+func (gs *GLS) DeleteFramebuffers(fbos ...uint32) {
+
+	gl.DeleteFramebuffers(fbos)
+	gs.checkError("DeleteFramebuffers")
+	gs.Stats.Fbos -= len(fbos)
+
+	// Forgets the bindings of the deleted framebuffers, whose handles may be reused
+	for _, fbo := range fbos {
+		if gs.drawFramebuffer == fbo {
+			gs.drawFramebuffer = uintUndef
+		}
+		if gs.readFramebuffer == fbo {
+			gs.readFramebuffer = uintUndef
+		}
+	}
+}
+
+func (gs *GLS) DeleteRenderbuffers(rbos ...uint32) {
+
+	gl.DeleteRenderbuffers(rbos)
+	gs.checkError("DeleteRenderbuffers")
+	gs.Stats.Rbos -= len(rbos)
+}
+
+func (gs *GLS) DeleteQueries(queries ...uint32) {
+
+	gl.DeleteQueries(queries)
+	gs.checkError("DeleteQueries")
+	gs.Stats.Queries -= len(queries)
+}
+
+// DeleteSync deletes the specified fence sync object
+func (gs *GLS) DeleteSync(sync uintptr) {
+
+	gl.DeleteSync(sync)
+	gs.checkError("DeleteSync")
 }
 
 func (gs *GLS) DeleteTextures(tex ...uint32) {
 
-	gl.DeleteTextures(int32(len(tex)), &tex[0])
+	gl.DeleteTextures(tex)
 	gs.checkError("DeleteTextures")
 	gs.Stats.Textures -= len(tex)
+
+	// Forgets the bindings of the deleted textures, whose handles may be reused
+	for _, t := range tex {
+		for key, bound := range gs.textures {
+			if bound == t {
+				delete(gs.textures, key)
+			}
+		}
+	}
 }
 
 func (gs *GLS) DeleteVertexArrays(vaos ...uint32) {
 
-	gl.DeleteVertexArrays(int32(len(vaos)), &vaos[0])
+	gl.DeleteVertexArrays(vaos)
 	gs.checkError("DeleteVertexArrays")
+
+	// Forgets the binding of a deleted vertex array, whose handle may be reused
+	for _, vao := range vaos {
+		if gs.vao == vao {
+			gs.vao = uintUndef
+		}
+	}
 }
 
 func (gs *GLS) DepthFunc(mode uint32) {
@@ -276,12 +570,40 @@ func (gs *GLS) DrawArrays(mode uint32, first int32, count int32) {
 	gs.checkError("DrawArrays")
 }
 
+// DrawBuffers specifies the list of color buffers to be drawn into.
+// An empty list selects gls.NONE.
+func (gs *GLS) DrawBuffers(bufs ...uint32) {
+
+	if len(bufs) == 0 {
+		gl.DrawBuffers([]uint32{NONE})
+	} else {
+		gl.DrawBuffers(bufs)
+	}
+	gs.checkError("DrawBuffers")
+}
+
 func (gs *GLS) DrawElements(mode uint32, count int32, itype uint32, start uint32) {
 
-	gl.DrawElements(mode, int32(count), itype, gl.PtrOffset(int(start)))
+	gl.DrawElements(mode, int32(count), itype, start)
 	gs.checkError("DrawElements")
 }
 
+// EndQuery ends the active query object for the specified target
+func (gs *GLS) EndQuery(target uint32) {
+
+	gl.EndQuery(target)
+	gs.checkError("EndQuery")
+}
+
+// FenceSync inserts a new fence sync object into the command stream, which is
+// signaled when all the previous commands are completed, and returns its handle
+func (gs *GLS) FenceSync() uintptr {
+
+	sync := gl.FenceSync(SYNC_GPU_COMMANDS_COMPLETE, 0)
+	gs.checkError("FenceSync")
+	return sync
+}
+
 func (gs *GLS) Enable(cap int) {
 
 	if gs.capabilities[cap] == capEnabled {
@@ -308,21 +630,60 @@ func (gs *GLS) Disable(cap int) {
 	gs.capabilities[cap] = capDisabled
 }
 
+func (gs *GLS) FramebufferRenderbuffer(target, attachment uint32, rbo uint32) {
+
+	gl.FramebufferRenderbuffer(target, attachment, RENDERBUFFER, rbo)
+	gs.checkError("FramebufferRenderbuffer")
+}
+
+func (gs *GLS) FramebufferTexture2D(target, attachment, textarget uint32, tex uint32, level int32) {
+
+	gl.FramebufferTexture2D(target, attachment, textarget, tex, level)
+	gs.checkError("FramebufferTexture2D")
+}
+
 func (gs *GLS) FrontFace(mode uint32) {
 
+	if gs.frontFace == mode {
+		return
+	}
 	gl.FrontFace(mode)
 	gs.checkError("FrontFace")
+	gs.frontFace = mode
 }
 
 func (gs *GLS) GenBuffer() uint32 {
 
-	var buf uint32
-	gl.GenBuffers(1, &buf)
+	buf := gl.GenBuffer()
 	gs.checkError("GenBuffers")
 	gs.Stats.Vbos++
 	return buf
 }
 
+func (gs *GLS) GenFramebuffer() uint32 {
+
+	fbo := gl.GenFramebuffer()
+	gs.checkError("GenFramebuffers")
+	gs.Stats.Fbos++
+	return fbo
+}
+
+func (gs *GLS) GenQuery() uint32 {
+
+	query := gl.GenQuery()
+	gs.checkError("GenQueries")
+	gs.Stats.Queries++
+	return query
+}
+
+func (gs *GLS) GenRenderbuffer() uint32 {
+
+	rbo := gl.GenRenderbuffer()
+	gs.checkError("GenRenderbuffers")
+	gs.Stats.Rbos++
+	return rbo
+}
+
 func (gs *GLS) GenerateMipmap(target uint32) {
 
 	gl.GenerateMipmap(target)
@@ -331,8 +692,7 @@ func (gs *GLS) GenerateMipmap(target uint32) {
 
 func (gs *GLS) GenTexture() uint32 {
 
-	var tex uint32
-	gl.GenTextures(1, &tex)
+	tex := gl.GenTexture()
 	gs.checkError("GenTextures")
 	gs.Stats.Textures++
 	return tex
@@ -340,17 +700,49 @@ func (gs *GLS) GenTexture() uint32 {
 
 func (gs *GLS) GenVertexArray() uint32 {
 
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
+	vao := gl.GenVertexArray()
 	gs.checkError("GenVertexArrays")
 	gs.Stats.Vaos++
 	return vao
 }
 
+// GetInteger returns the integer value of the specified OpenGL parameter
+func (gs *GLS) GetInteger(pname uint32) int32 {
+
+	data := gl.GetInteger(pname)
+	gs.checkError("GetInteger")
+	return data
+}
+
+// GetBufferSubData copies size bytes of the data store of the buffer bound to
+// the specified target, starting at the specified byte offset, into data
+func (gs *GLS) GetBufferSubData(target uint32, offset, size int, data interface{}) {
+
+	gl.GetBufferSubData(target, offset, size, data)
+	gs.checkError("GetBufferSubData")
+}
+
+// GetQueryObjectui returns the specified parameter of the specified query object,
+// such as gls.QUERY_RESULT_AVAILABLE or gls.QUERY_RESULT
+func (gs *GLS) GetQueryObjectui(query uint32, pname uint32) uint32 {
+
+	result := gl.GetQueryObjectui(query, pname)
+	gs.checkError("GetQueryObjectui")
+	return result
+}
+
+// GetQueryObjectui64 returns the specified 64 bits parameter of the specified
+// query object, normally gls.QUERY_RESULT of timer queries in nanoseconds
+func (gs *GLS) GetQueryObjectui64(query uint32, pname uint32) uint64 {
+
+	result := gl.GetQueryObjectui64(query, pname)
+	gs.checkError("GetQueryObjectui64")
+	return result
+}
+
 func (gs *GLS) GetString(name uint32) string {
 
-	cstr := gl.GetString(name)
-	return gl.GoStr(cstr)
+	return gl.GetString(name)
 }
 
 func (gs *GLS) GetViewport() (x, y, width, height int32) {
@@ -368,12 +760,76 @@ func (gs *GLS) LineWidth(width float32) {
 	gs.lineWidth = width
 }
 
+// MultisampleTextureSupported returns if the current OpenGL context supports
+// multisample textures, which requires OpenGL 3.2 or OpenGL ES 3.1.
+// They are not supported by WebGL.
+func (gs *GLS) MultisampleTextureSupported() bool {
+
+	if gs.msTexture == capUndef {
+		gs.msTexture = capDisabled
+		if gl.MultisampleTextureSupported() {
+			gs.msTexture = capEnabled
+		}
+	}
+	return gs.msTexture == capEnabled
+}
+
+// ReadPixels reads a block of pixels of the current read framebuffer into data,
+// waiting for all the previous commands to complete.
+// Use a PixelReader to read pixels without blocking.
+func (gs *GLS) ReadPixels(x, y, width, height int32, format, xtype uint32, data interface{}) {
+
+	gl.ReadPixels(x, y, width, height, format, xtype, data)
+	gs.checkError("ReadPixels")
+}
+
+// ReadBuffer selects the color buffer source for pixel read and blit operations
+func (gs *GLS) ReadBuffer(src uint32) {
+
+	gl.ReadBuffer(src)
+	gs.checkError("ReadBuffer")
+}
+
+// QueryCounter records the GPU time into the specified query object
+// when all previous commands have been completed. The target must be gls.TIMESTAMP.
+func (gs *GLS) QueryCounter(query uint32, target uint32) {
+
+	gl.QueryCounter(query, target)
+	gs.checkError("QueryCounter")
+}
+
+// PixelStorei sets a pixel storage mode such as PACK_ALIGNMENT or UNPACK_ALIGNMENT
+func (gs *GLS) PixelStorei(pname uint32, param int32) {
+
+	gl.PixelStorei(pname, param)
+	gs.checkError("PixelStorei")
+}
+
+func (gs *GLS) RenderbufferStorage(iformat uint32, width, height int32) {
+
+	gl.RenderbufferStorage(RENDERBUFFER, iformat, width, height)
+	gs.checkError("RenderbufferStorage")
+}
+
+func (gs *GLS) RenderbufferStorageMultisample(samples int32, iformat uint32, width, height int32) {
+
+	gl.RenderbufferStorageMultisample(RENDERBUFFER, samples, iformat, width, height)
+	gs.checkError("RenderbufferStorageMultisample")
+}
+
+// Scissor sets the scissor box used when SCISSOR_TEST is enabled
+func (gs *GLS) Scissor(x, y, width, height int32) {
+
+	gl.Scissor(x, y, width, height)
+	gs.checkError("Scissor")
+}
+
 func (gs *GLS) SetDepthTest(mode bool) {
 
 	if mode {
-		gs.Enable(gl.DEPTH_TEST)
+		gs.Enable(DEPTH_TEST)
 	} else {
-		gs.Disable(gl.DEPTH_TEST)
+		gs.Disable(DEPTH_TEST)
 	}
 }
 
@@ -385,15 +841,15 @@ func (gs *GLS) SetSideView(mode int) {
 	switch mode {
 	// Default: show only the front size
 	case FrontSide:
-		gs.Enable(gl.CULL_FACE)
-		gl.FrontFace(gl.CCW)
+		gs.Enable(CULL_FACE)
+		gs.FrontFace(CCW)
 	// Show only the back side
 	case BackSide:
-		gs.Enable(gl.CULL_FACE)
-		gl.FrontFace(gl.CW)
+		gs.Enable(CULL_FACE)
+		gs.FrontFace(CW)
 	// Show both sides
 	case DoubleSide:
-		gs.Disable(gl.CULL_FACE)
+		gs.Disable(CULL_FACE)
 	default:
 		panic("SetSideView() invalid mode")
 	}
@@ -402,10 +858,34 @@ func (gs *GLS) SetSideView(mode int) {
 
 func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32, height int32, border int32, format uint32, itype uint32, data interface{}) {
 
-	gl.TexImage2D(uint32(target), int32(level), int32(iformat), int32(width), int32(height), int32(border), uint32(format), uint32(itype), gl.Ptr(data))
+	gl.TexImage2D(uint32(target), int32(level), int32(iformat), int32(width), int32(height), int32(border), uint32(format), uint32(itype), data)
 	gs.checkError("TexImage2D")
 }
 
+// TexImage2DMultisample establishes the storage of the multisample texture
+// bound to TEXTURE_2D_MULTISAMPLE. If fixed is true all the texels use the
+// same sample locations. The storage cannot be changed later.
+func (gs *GLS) TexImage2DMultisample(samples int32, iformat uint32, width, height int32, fixed bool) {
+
+	gl.TexImage2DMultisample(TEXTURE_2D_MULTISAMPLE, samples, iformat, width, height, fixed)
+	gs.checkError("TexImage2DMultisample")
+}
+
+// TexImage3D specifies the image of all the layers of an array texture
+// or all the slices of a 3D texture.
+func (gs *GLS) TexImage3D(target uint32, level int32, iformat int32, width, height, depth int32, format uint32, itype uint32, data interface{}) {
+
+	gl.TexImage3D(target, level, iformat, width, height, depth, 0, format, itype, data)
+	gs.checkError("TexImage3D")
+}
+
+// TexSubImage3D replaces a region of the image of an array texture or 3D texture
+func (gs *GLS) TexSubImage3D(target uint32, level int32, xoffset, yoffset, zoffset, width, height, depth int32, format uint32, itype uint32, data interface{}) {
+
+	gl.TexSubImage3D(target, level, xoffset, yoffset, zoffset, width, height, depth, format, itype, data)
+	gs.checkError("TexSubImage3D")
+}
+
 func (gs *GLS) TexStorage2D(target int, levels int, iformat int, width, height int) {
 
 	gl.TexStorage2D(uint32(target), int32(levels), uint32(iformat), int32(width), int32(height))
@@ -420,14 +900,27 @@ func (gs *GLS) TexParameteri(target uint32, pname uint32, param int32) {
 
 func (gs *GLS) PolygonMode(face, mode int) {
 
+	// Only the mode of both faces is kept
+	if face == FRONT_AND_BACK && gs.polygonMode == mode {
+		return
+	}
 	gl.PolygonMode(uint32(face), uint32(mode))
 	gs.checkError("PolygonMode")
+	if face == FRONT_AND_BACK {
+		gs.polygonMode = mode
+	} else {
+		gs.polygonMode = uintUndef
+	}
 }
 
 func (gs *GLS) PolygonOffset(factor float32, units float32) {
 
+	if gs.polygonOffset[0] == factor && gs.polygonOffset[1] == units {
+		return
+	}
 	gl.PolygonOffset(factor, units)
 	gs.checkError("PolygonOffset")
+	gs.polygonOffset = [2]float32{factor, units}
 }
 
 func (gs *GLS) Uniform1i(location int32, v0 int32) {
@@ -462,13 +955,13 @@ func (gs *GLS) Uniform4f(location int32, v0, v1, v2, v3 float32) {
 
 func (gs *GLS) UniformMatrix3fv(location int32, count int32, transpose bool, v []float32) {
 
-	gl.UniformMatrix3fv(location, count, transpose, &v[0])
+	gl.UniformMatrix3fv(location, count, transpose, v)
 	gs.checkError("UniformMatrix3fv")
 }
 
 func (gs *GLS) UniformMatrix4fv(location int32, count int32, transpose bool, v []float32) {
 
-	gl.UniformMatrix4fv(location, count, transpose, &v[0])
+	gl.UniformMatrix4fv(location, count, transpose, v)
 	gs.checkError("UniformMatrix4fv")
 }
 
@@ -478,9 +971,13 @@ func (gs *GLS) UseProgram(prog *Program) {
 	if prog.handle == 0 {
 		panic("Invalid program")
 	}
+	if gs.Prog == prog && gs.program == prog.handle {
+		return
+	}
 	gl.UseProgram(prog.handle)
 	gs.checkError("UseProgram")
 	gs.Prog = prog
+	gs.program = prog.handle
 
 	// Inserts program in cache if not already there.
 	if !gs.programs[prog] {
@@ -491,18 +988,23 @@ func (gs *GLS) UseProgram(prog *Program) {
 
 func (gs *GLS) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uint32) {
 
-	gl.VertexAttribPointer(index, size, xtype, normalized, stride, gl.PtrOffset(int(offset)))
+	gl.VertexAttribPointer(index, size, xtype, normalized, stride, offset)
 	gs.checkError("VertexAttribPointer")
 }
 
 func (gs *GLS) Viewport(x, y, width, height int32) {
 
+	if gs.viewportSet && gs.viewportX == x && gs.viewportY == y &&
+		gs.viewportWidth == width && gs.viewportHeight == height {
+		return
+	}
 	gl.Viewport(x, y, width, height)
 	gs.checkError("Viewport")
 	gs.viewportX = x
 	gs.viewportY = y
 	gs.viewportWidth = width
 	gs.viewportHeight = height
+	gs.viewportSet = true
 }
 
 // checkError checks the error code of the previously called OpenGL function
@@ -0,0 +1,163 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+)
+
+// MultiDrawIndirectSupported returns if the current OpenGL context supports
+// multi-draw indirect rendering, which requires OpenGL 4.3, the
+// ARB_multi_draw_indirect extension or the EXT_multi_draw_indirect extension
+// of OpenGL ES. It is not supported by WebGL.
+func (gs *GLS) MultiDrawIndirectSupported() bool {
+
+	if gs.multiDraw == capUndef {
+		gs.multiDraw = capDisabled
+		if gl.MultiDrawIndirectSupported() {
+			gs.multiDraw = capEnabled
+		}
+	}
+	return gs.multiDraw == capEnabled
+}
+
+// DrawElementsIndirectCommand is the command of an indexed indirect draw,
+// with the memory layout required by glMultiDrawElementsIndirect.
+type DrawElementsIndirectCommand struct {
+	Count         uint32 // Number of indices
+	InstanceCount uint32 // Number of instances
+	FirstIndex    uint32 // Index of the first index in the index buffer
+	BaseVertex    int32  // Value added to the indices
+	BaseInstance  uint32 // First instance of the instanced vertex attributes
+}
+
+// IndirectBuffer contains a list of indexed draw commands which are stored in a
+// draw indirect buffer and drawn with a single glMultiDrawElementsIndirect call,
+// collapsing the draws of many ranges of the same vertex array with the same
+// program and states into one call. If multi-draw indirect rendering is not
+// supported the commands are drawn one by one, ignoring their BaseVertex,
+// InstanceCount and BaseInstance.
+type IndirectBuffer struct {
+	gs     *GLS                          // OpenGL state used to create the buffer
+	handle uint32                        // OpenGL buffer handle
+	cmds   []DrawElementsIndirectCommand // draw commands
+	size   int                           // size in bytes of the data store of the buffer
+	update bool                          // commands must be transferred
+}
+
+// NewIndirectBuffer creates and returns a pointer to a new empty indirect buffer
+func NewIndirectBuffer() *IndirectBuffer {
+
+	ib := new(IndirectBuffer)
+	ib.Init()
+	return ib
+}
+
+// Init initializes this empty indirect buffer
+func (ib *IndirectBuffer) Init() {
+
+	ib.gs = nil
+	ib.handle = 0
+	ib.cmds = make([]DrawElementsIndirectCommand, 0)
+	ib.size = 0
+	ib.update = true
+}
+
+// Reset removes all the commands of this buffer
+func (ib *IndirectBuffer) Reset() {
+
+	ib.cmds = ib.cmds[0:0]
+	ib.update = true
+}
+
+// Add appends the specified command to this buffer
+func (ib *IndirectBuffer) Add(cmd DrawElementsIndirectCommand) {
+
+	ib.cmds = append(ib.cmds, cmd)
+	ib.update = true
+}
+
+// AddRange appends a command which draws count indices starting at the
+// specified first index, extending the last command if the ranges are
+// contiguous, as for the consecutive meshes of a batch.
+func (ib *IndirectBuffer) AddRange(first, count int) {
+
+	if n := len(ib.cmds); n > 0 {
+		last := &ib.cmds[n-1]
+		if last.BaseVertex == 0 && last.InstanceCount == 1 && int(last.FirstIndex+last.Count) == first {
+			last.Count += uint32(count)
+			ib.update = true
+			return
+		}
+	}
+	ib.Add(DrawElementsIndirectCommand{Count: uint32(count), InstanceCount: 1, FirstIndex: uint32(first)})
+}
+
+// Len returns the number of commands of this buffer
+func (ib *IndirectBuffer) Len() int {
+
+	return len(ib.cmds)
+}
+
+// CommandAt returns a pointer to the command at the specified index
+func (ib *IndirectBuffer) CommandAt(idx int) *DrawElementsIndirectCommand {
+
+	ib.update = true
+	return &ib.cmds[idx]
+}
+
+// Transfer creates the OpenGL buffer object if necessary, transfers the
+// commands of this buffer if they were changed and binds the buffer to
+// DRAW_INDIRECT_BUFFER.
+func (ib *IndirectBuffer) Transfer(gs *GLS) {
+
+	if ib.gs == nil {
+		ib.gs = gs
+		ib.handle = gs.GenBuffer()
+		ib.update = true
+	}
+	gs.BindBuffer(DRAW_INDIRECT_BUFFER, ib.handle)
+	if !ib.update || len(ib.cmds) == 0 {
+		return
+	}
+	size := len(ib.cmds) * 20
+	if size > ib.size {
+		gs.BufferData(DRAW_INDIRECT_BUFFER, size, ib.cmds, DYNAMIC_DRAW)
+		ib.size = size
+	} else {
+		gs.BufferSubData(DRAW_INDIRECT_BUFFER, 0, size, ib.cmds)
+	}
+	ib.update = false
+}
+
+// Draw draws the commands of this buffer with the specified primitive mode,
+// the current vertex array with 32 bits indices, program and states.
+func (ib *IndirectBuffer) Draw(gs *GLS, mode uint32) {
+
+	if len(ib.cmds) == 0 {
+		return
+	}
+	if !gs.MultiDrawIndirectSupported() {
+		for i := range ib.cmds {
+			cmd := &ib.cmds[i]
+			gs.DrawElements(mode, int32(cmd.Count), UNSIGNED_INT, 4*cmd.FirstIndex)
+		}
+		return
+	}
+	ib.Transfer(gs)
+	gl.MultiDrawElementsIndirect(mode, UNSIGNED_INT, 0, int32(len(ib.cmds)), 0)
+	gs.checkError("MultiDrawElementsIndirect")
+}
+
+// Dispose deletes the OpenGL buffer object of this buffer
+func (ib *IndirectBuffer) Dispose() {
+
+	if ib.gs != nil {
+		ib.gs.DeleteBuffers(ib.handle)
+		ib.gs = nil
+		ib.handle = 0
+		ib.size = 0
+	}
+}
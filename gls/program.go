@@ -8,8 +8,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/g3n/engine/gls/gl"
 	"github.com/g3n/engine/math32"
-	"github.com/go-gl/gl/v3.3-core/gl"
 	"io"
 	"strconv"
 	"strings"
@@ -23,6 +23,9 @@ type Program struct {
 	handle     uint32
 	shaders    []shaderInfo
 	uniforms   map[string]int32
+	fragOuts   map[string]uint32
+	varyings   []string // vertex outputs captured by transform feedback
+	bufferMode uint32   // transform feedback buffer mode
 	Specs      interface{}
 }
 
@@ -35,8 +38,9 @@ type shaderInfo struct {
 
 // Map shader types to names
 var shaderNames = map[uint32]string{
-	gl.VERTEX_SHADER:   "Vertex Shader",
-	gl.FRAGMENT_SHADER: "Fragment Shader",
+	VERTEX_SHADER:   "Vertex Shader",
+	FRAGMENT_SHADER: "Fragment Shader",
+	COMPUTE_SHADER:  "Compute Shader",
 }
 
 // NewProgram creates a new empty shader program object.
@@ -48,6 +52,7 @@ func (gs *GLS) NewProgram() *Program {
 
 	prog.shaders = make([]shaderInfo, 0)
 	prog.uniforms = make(map[string]int32)
+	prog.fragOuts = make(map[string]uint32)
 	prog.ShowSource = true
 	return prog
 }
@@ -62,6 +67,31 @@ func (prog *Program) AddShader(stype uint32, source string, defines map[string]i
 	prog.shaders = append(prog.shaders, shaderInfo{stype, source, defines, 0})
 }
 
+// BindFragDataLocation binds the specified fragment shader output variable
+// to the specified color number (the index of the draw buffer).
+// This is used with multiple render targets and must be done before the program is built.
+func (prog *Program) BindFragDataLocation(color uint32, name string) {
+
+	if prog.handle != 0 {
+		log.Fatal("Program already built")
+	}
+	prog.fragOuts[name] = color
+}
+
+// SetTransformFeedbackVaryings sets the names of the vertex shader output
+// variables which are captured into buffers by transform feedback, and
+// the buffer mode: INTERLEAVED_ATTRIBS to capture all the variables into a
+// single buffer or SEPARATE_ATTRIBS to capture each variable into its own buffer.
+// This must be done before the program is built.
+func (prog *Program) SetTransformFeedbackVaryings(varyings []string, bufferMode uint32) {
+
+	if prog.handle != 0 {
+		log.Fatal("Program already built")
+	}
+	prog.varyings = varyings
+	prog.bufferMode = bufferMode
+}
+
 // Build builds the program compiling and linking the previously supplied shaders.
 func (prog *Program) Build() error {
 
@@ -112,7 +142,7 @@ func (prog *Program) Build() error {
 		}
 		deftext := strings.Join(deflines, "\n")
 		// Compile shader
-		shader, err := CompileShader(sinfo.stype, sinfo.source+deftext)
+		shader, err := CompileShader(sinfo.stype, insertHeader(sinfo.source)+deftext)
 		if err != nil {
 			gl.DeleteProgram(prog.handle)
 			prog.handle = 0
@@ -127,15 +157,21 @@ func (prog *Program) Build() error {
 		gl.AttachShader(prog.handle, shader)
 	}
 
+	// Binds fragment shader outputs to color numbers
+	for name, color := range prog.fragOuts {
+		gl.BindFragDataLocation(prog.handle, color, name)
+	}
+
+	// Sets the outputs captured by transform feedback
+	if len(prog.varyings) > 0 {
+		gl.TransformFeedbackVaryings(prog.handle, prog.varyings, prog.bufferMode)
+	}
+
 	// Link program and checks for errors
 	gl.LinkProgram(prog.handle)
-	var status int32
-	gl.GetProgramiv(prog.handle, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(prog.handle, gl.INFO_LOG_LENGTH, &logLength)
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(prog.handle, logLength, nil, gl.Str(log))
+	status := gl.GetProgramiv(prog.handle, LINK_STATUS)
+	if status == FALSE {
+		log := gl.GetProgramInfoLog(prog.handle)
 		prog.handle = 0
 		return fmt.Errorf("Error linking program: %v", log)
 	}
@@ -153,8 +189,7 @@ func (prog *Program) Handle() uint32 {
 // to contain the data for the uniform block specified by its index.
 func (prog *Program) GetActiveUniformBlockSize(ubindex uint32) int32 {
 
-	var uboSize int32
-	gl.GetActiveUniformBlockiv(prog.handle, ubindex, gl.UNIFORM_BLOCK_DATA_SIZE, &uboSize)
+	uboSize := gl.GetActiveUniformBlockiv(prog.handle, ubindex, UNIFORM_BLOCK_DATA_SIZE)
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -168,8 +203,7 @@ func (prog *Program) GetActiveUniformBlockSize(ubindex uint32) int32 {
 // specified by its indices
 func (prog *Program) GetActiveUniformsiv(indices []uint32, pname uint32) []int32 {
 
-	data := make([]int32, len(indices))
-	gl.GetActiveUniformsiv(prog.handle, int32(len(indices)), &indices[0], pname, &data[0])
+	data := gl.GetActiveUniformsiv(prog.handle, indices, pname)
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -183,16 +217,26 @@ func (prog *Program) GetActiveUniformsiv(indices []uint32, pname uint32) []int32
 // in this program. This location is internally cached.
 func (prog *Program) GetAttribLocation(name string) int32 {
 
-	loc := gl.GetAttribLocation(prog.handle, gl.Str(name+"\x00"))
+	loc := gl.GetAttribLocation(prog.handle, name)
 	prog.gs.checkError("GetAttribLocation")
 	return loc
 }
 
+// GetFragDataLocation returns the color number to which the specified
+// fragment shader output variable was bound when the program was linked
+// or -1 if the name is not an output variable.
+func (prog *Program) GetFragDataLocation(name string) int32 {
+
+	loc := gl.GetFragDataLocation(prog.handle, name)
+	prog.gs.checkError("GetFragDataLocation")
+	return loc
+}
+
 // GetUniformBlockIndex returns the index of the named uniform block.
-// If the supplied name is not valid, the function returns gl.INVALID_INDEX
+// If the supplied name is not valid, the function returns INVALID_INDEX
 func (prog *Program) GetUniformBlockIndex(name string) uint32 {
 
-	index := gl.GetUniformBlockIndex(prog.handle, gl.Str(name+"\x00"))
+	index := gl.GetUniformBlockIndex(prog.handle, name)
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -202,26 +246,33 @@ func (prog *Program) GetUniformBlockIndex(name string) uint32 {
 	return index
 }
 
+// SetUniformBlockBinding assigns the specified binding point to the named
+// uniform block of this program, so it reads the data of the uniform buffer
+// bound to this binding point. Returns false if the program has no active
+// uniform block with the specified name.
+func (prog *Program) SetUniformBlockBinding(name string, binding uint32) bool {
+
+	index := prog.GetUniformBlockIndex(name)
+	if index == INVALID_INDEX {
+		return false
+	}
+	gl.UniformBlockBinding(prog.handle, index, binding)
+	prog.gs.checkError("UniformBlockBinding")
+	return true
+}
+
 // GetUniformIndices returns the indices for each specified named
 // uniform. If an specified name is not valid the corresponding
-// index value will be gl.INVALID_INDEX
+// index value will be INVALID_INDEX
 func (prog *Program) GetUniformIndices(names []string) []uint32 {
 
-	// Add C terminators to uniform names
-	for _, s := range names {
-		s += "\x00"
-	}
-	unames, freefunc := gl.Strs(names...)
-
-	indices := make([]uint32, len(names))
-	gl.GetUniformIndices(prog.handle, int32(len(names)), unames, &indices[0])
+	indices := gl.GetUniformIndices(prog.handle, names)
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
 			log.Fatal("GetUniformIndices() error: %d", ecode)
 		}
 	}
-	freefunc()
 	return indices
 }
 
@@ -235,7 +286,7 @@ func (prog *Program) GetUniformLocation(name string) int32 {
 		return loc
 	}
 	// Get location from GL
-	loc = gl.GetUniformLocation(prog.handle, gl.Str(name+"\x00"))
+	loc = gl.GetUniformLocation(prog.handle, name)
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -319,7 +370,7 @@ func (prog *Program) SetUniformVector4(loc int32, v *math32.Vector4) {
 // its location with the values from the specified Matrix3.
 func (prog *Program) SetUniformMatrix3(loc int32, m *math32.Matrix3) {
 
-	gl.UniformMatrix3fv(loc, 1, false, &m[0])
+	gl.UniformMatrix3fv(loc, 1, false, m[:])
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -332,7 +383,7 @@ func (prog *Program) SetUniformMatrix3(loc int32, m *math32.Matrix3) {
 // its location with the values from the specified Matrix4.
 func (prog *Program) SetUniformMatrix4(loc int32, m *math32.Matrix4) {
 
-	gl.UniformMatrix4fv(loc, 1, false, &m[0])
+	gl.UniformMatrix4fv(loc, 1, false, m[:])
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -416,7 +467,7 @@ func (prog *Program) SetUniformVector4ByName(name string, v *math32.Vector4) {
 // The specified name location is cached internally.
 func (prog *Program) SetUniformMatrix3ByName(name string, m *math32.Matrix3) {
 
-	gl.UniformMatrix3fv(prog.GetUniformLocation(name), 1, false, &m[0])
+	gl.UniformMatrix3fv(prog.GetUniformLocation(name), 1, false, m[:])
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -430,7 +481,7 @@ func (prog *Program) SetUniformMatrix3ByName(name string, m *math32.Matrix3) {
 // The location of the name is cached internally.
 func (prog *Program) SetUniformMatrix4ByName(name string, m *math32.Matrix4) {
 
-	gl.UniformMatrix4fv(prog.GetUniformLocation(name), 1, false, &m[0])
+	gl.UniformMatrix4fv(prog.GetUniformLocation(name), 1, false, m[:])
 	if prog.gs.CheckErrors() {
 		ecode := gl.GetError()
 		if ecode != 0 {
@@ -477,24 +528,16 @@ func CompileShader(stype uint32, source string) (uint32, error) {
 		return 0, fmt.Errorf("Error creating shader")
 	}
 
-	// Allocates C string to store the source
-	csource, freeSource := gl.Strs(source + "\x00")
-	defer freeSource()
-
 	// Set shader source and compile it
-	gl.ShaderSource(shader, 1, csource, nil)
+	gl.ShaderSource(shader, source)
 	gl.CompileShader(shader)
 
 	// Get the shader compiler log
-	var logLength int32
-	gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-	slog := strings.Repeat("\x00", int(logLength+1))
-	gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(slog))
+	slog := gl.GetShaderInfoLog(shader)
 
 	// Get the shader compile status
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
+	status := gl.GetShaderiv(shader, COMPILE_STATUS)
+	if status == FALSE {
 		return shader, fmt.Errorf("%s", slog)
 	}
 
@@ -506,6 +549,26 @@ func CompileShader(stype uint32, source string) (uint32, error) {
 	return shader, nil
 }
 
+// insertHeader inserts the shader declarations required by the
+// current OpenGL backend after the #version directive of the
+// specified shader source, if any.
+func insertHeader(source string) string {
+
+	if gl.GLSLHeader == "" {
+		return source
+	}
+	pos := strings.Index(source, "#version")
+	if pos < 0 {
+		return source
+	}
+	end := strings.Index(source[pos:], "\n")
+	if end < 0 {
+		return source + "\n" + gl.GLSLHeader
+	}
+	end += pos + 1
+	return source[:end] + gl.GLSLHeader + source[end:]
+}
+
 // FormatSource returns the supplied program source code with
 // line numbers prepended.
 func FormatSource(source string) string {
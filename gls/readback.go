@@ -0,0 +1,180 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+	"math"
+)
+
+// PixelReader reads blocks of pixels of the current read framebuffer without
+// stalling the pipeline as ReadPixels() does, for screenshots, video capture
+// and GPU picking. Read() starts copying the pixels into a pixel buffer object
+// and inserts a fence into the command stream. Poll() returns the pixels of the
+// oldest read once the fence is signaled, normally one or more frames later,
+// or nil if they are not available yet. Several reads may be pending, up to
+// the number of buffers of the reader.
+type PixelReader struct {
+	gs     *GLS            // OpenGL state
+	format uint32          // format of the pixels, such as RGBA
+	xtype  uint32          // type of the pixel components, such as UNSIGNED_BYTE
+	slots  []pixelReadback // pending and free reads
+	first  int             // index of the oldest pending read
+	count  int             // number of pending reads
+}
+
+// pixelReadback is a read of a PixelReader
+type pixelReadback struct {
+	pbo    uint32    // pixel buffer object handle
+	size   int       // size in bytes of the data store of the buffer
+	sync   uintptr   // fence signaled when the pixels were copied
+	pixels PixelData // position and size of the block of pixels
+}
+
+// PixelData contains a block of pixels read by a PixelReader
+type PixelData struct {
+	X      int    // Horizontal position of the lower left pixel of the block
+	Y      int    // Vertical position of the lower left pixel of the block
+	Width  int    // Width of the block in pixels
+	Height int    // Height of the block in pixels
+	Data   []byte // Pixels ordered by row from the bottom row, without padding
+}
+
+// NewPixelReader creates and returns a pointer to a new pixel reader of
+// pixels with the specified format and type, such as RGBA and UNSIGNED_BYTE,
+// which can have up to the specified number of pending reads.
+func (gs *GLS) NewPixelReader(format, xtype uint32, buffers int) *PixelReader {
+
+	if buffers < 1 {
+		buffers = 1
+	}
+	pr := new(PixelReader)
+	pr.gs = gs
+	pr.format = format
+	pr.xtype = xtype
+	pr.slots = make([]pixelReadback, buffers)
+	return pr
+}
+
+// Read starts reading the specified block of pixels of the current read
+// framebuffer. Returns false if all the buffers of the reader have pending reads.
+func (pr *PixelReader) Read(x, y, width, height int) bool {
+
+	if pr.count == len(pr.slots) {
+		return false
+	}
+	slot := &pr.slots[(pr.first+pr.count)%len(pr.slots)]
+	size := width * height * pixelSize(pr.format, pr.xtype)
+	gs := pr.gs
+	if slot.pbo == 0 {
+		slot.pbo = gs.GenBuffer()
+	}
+	gs.BindBuffer(PIXEL_PACK_BUFFER, slot.pbo)
+	if slot.size < size {
+		gs.BufferData(PIXEL_PACK_BUFFER, size, nil, STREAM_READ)
+		slot.size = size
+	}
+	gs.PixelStorei(PACK_ALIGNMENT, 1)
+	gl.ReadPixelsOffset(int32(x), int32(y), int32(width), int32(height), pr.format, pr.xtype, 0)
+	gs.checkError("ReadPixels")
+	gs.BindBuffer(PIXEL_PACK_BUFFER, 0)
+	slot.sync = gs.FenceSync()
+	slot.pixels = PixelData{X: x, Y: y, Width: width, Height: height}
+	pr.count++
+	return true
+}
+
+// Poll returns the pixels of the oldest pending read if they are available or nil
+func (pr *PixelReader) Poll() *PixelData {
+
+	return pr.poll(0)
+}
+
+// Wait returns the pixels of the oldest pending read, waiting for them if
+// necessary, or nil if there are no pending reads.
+func (pr *PixelReader) Wait() *PixelData {
+
+	return pr.poll(math.MaxUint64)
+}
+
+// Pending returns the number of pending reads
+func (pr *PixelReader) Pending() int {
+
+	return pr.count
+}
+
+// Dispose deletes the buffers and fences of this reader discarding its pending reads
+func (pr *PixelReader) Dispose() {
+
+	for i := range pr.slots {
+		slot := &pr.slots[i]
+		if slot.sync != 0 {
+			pr.gs.DeleteSync(slot.sync)
+			slot.sync = 0
+		}
+		if slot.pbo != 0 {
+			pr.gs.DeleteBuffers(slot.pbo)
+			slot.pbo = 0
+			slot.size = 0
+		}
+	}
+	pr.first = 0
+	pr.count = 0
+}
+
+// poll returns the pixels of the oldest pending read waiting at most
+// timeout nanoseconds for them or nil if they are not available
+func (pr *PixelReader) poll(timeout uint64) *PixelData {
+
+	if pr.count == 0 {
+		return nil
+	}
+	slot := &pr.slots[pr.first]
+	status := pr.gs.ClientWaitSync(slot.sync, timeout)
+	if status != ALREADY_SIGNALED && status != CONDITION_SATISFIED {
+		return nil
+	}
+	pr.gs.DeleteSync(slot.sync)
+	slot.sync = 0
+
+	// Copies the pixels from the buffer
+	pixels := slot.pixels
+	pixels.Data = make([]byte, pixels.Width*pixels.Height*pixelSize(pr.format, pr.xtype))
+	if len(pixels.Data) > 0 {
+		pr.gs.BindBuffer(PIXEL_PACK_BUFFER, slot.pbo)
+		pr.gs.GetBufferSubData(PIXEL_PACK_BUFFER, 0, len(pixels.Data), pixels.Data)
+		pr.gs.BindBuffer(PIXEL_PACK_BUFFER, 0)
+	}
+	pr.first = (pr.first + 1) % len(pr.slots)
+	pr.count--
+	return &pixels
+}
+
+// pixelSize returns the size in bytes of a pixel with the specified format and type
+func pixelSize(format, xtype uint32) int {
+
+	var components int
+	switch format {
+	case RED, RED_INTEGER, DEPTH_COMPONENT, ALPHA:
+		components = 1
+	case RG, RG_INTEGER:
+		components = 2
+	case RGB, RGB_INTEGER:
+		components = 3
+	case RGBA, RGBA_INTEGER:
+		components = 4
+	default:
+		panic("PixelReader: unsupported pixel format")
+	}
+	switch xtype {
+	case UNSIGNED_BYTE, BYTE:
+		return components
+	case UNSIGNED_SHORT, SHORT, HALF_FLOAT:
+		return components * 2
+	case UNSIGNED_INT, INT, FLOAT:
+		return components * 4
+	}
+	panic("PixelReader: unsupported pixel type")
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+)
+
+// SamplerState describes how a texture is sampled, independently of the
+// texture, so the same texture may be sampled in different ways.
+// Zero values keep the OpenGL defaults of sampler objects.
+type SamplerState struct {
+	MagFilter   uint32  // Magnification filter
+	MinFilter   uint32  // Minification filter
+	WrapS       uint32  // Wrap mode of the S coordinate
+	WrapT       uint32  // Wrap mode of the T coordinate
+	WrapR       uint32  // Wrap mode of the R coordinate
+	Anisotropy  float32 // Maximum degree of anisotropic filtering (values above 1 enable it)
+	CompareFunc uint32  // Depth comparison function of shadow samplers (0 = no comparison)
+}
+
+// NewSamplerState creates and returns a pointer to a new sampler state
+// with linear mipmapped filtering and repeat wrap modes.
+func NewSamplerState() *SamplerState {
+
+	return &SamplerState{
+		MagFilter: LINEAR,
+		MinFilter: LINEAR_MIPMAP_LINEAR,
+		WrapS:     REPEAT,
+		WrapT:     REPEAT,
+		WrapR:     REPEAT,
+	}
+}
+
+// Sampler returns the handle of the sampler object with the specified state,
+// creating it if necessary. Sampler objects are shared by all the users of
+// the same state and are only deleted by DeleteSamplerObjects().
+func (gs *GLS) Sampler(state *SamplerState) uint32 {
+
+	if sampler, ok := gs.samplerObjects[*state]; ok {
+		return sampler
+	}
+	sampler := gl.GenSampler()
+	gs.checkError("GenSampler")
+	params := [...]struct {
+		pname uint32
+		value uint32
+	}{
+		{TEXTURE_MAG_FILTER, state.MagFilter},
+		{TEXTURE_MIN_FILTER, state.MinFilter},
+		{TEXTURE_WRAP_S, state.WrapS},
+		{TEXTURE_WRAP_T, state.WrapT},
+		{TEXTURE_WRAP_R, state.WrapR},
+	}
+	for _, p := range params {
+		if p.value != 0 {
+			gl.SamplerParameteri(sampler, p.pname, int32(p.value))
+		}
+	}
+	if state.Anisotropy > 1 && gs.MaxAnisotropy() > 1 {
+		aniso := state.Anisotropy
+		if aniso > gs.maxAnisotropy {
+			aniso = gs.maxAnisotropy
+		}
+		gl.SamplerParameterf(sampler, TEXTURE_MAX_ANISOTROPY, aniso)
+	}
+	if state.CompareFunc != 0 {
+		gl.SamplerParameteri(sampler, TEXTURE_COMPARE_MODE, COMPARE_REF_TO_TEXTURE)
+		gl.SamplerParameteri(sampler, TEXTURE_COMPARE_FUNC, int32(state.CompareFunc))
+	}
+	gs.checkError("SamplerParameter")
+	gs.samplerObjects[*state] = sampler
+	return sampler
+}
+
+// BindSampler binds the specified sampler object to the specified texture
+// unit, overriding the sampling parameters of the textures bound to the unit.
+// A sampler of 0 unbinds the sampler object of the unit.
+func (gs *GLS) BindSampler(unit, sampler uint32) {
+
+	if bound, ok := gs.samplers[unit]; ok && bound == sampler {
+		return
+	}
+	gl.BindSampler(unit, sampler)
+	gs.checkError("BindSampler")
+	gs.samplers[unit] = sampler
+}
+
+// DeleteSamplerObjects deletes all the sampler objects created by Sampler()
+func (gs *GLS) DeleteSamplerObjects() {
+
+	if len(gs.samplerObjects) == 0 {
+		return
+	}
+	handles := make([]uint32, 0, len(gs.samplerObjects))
+	for _, sampler := range gs.samplerObjects {
+		handles = append(handles, sampler)
+	}
+	gl.DeleteSamplers(handles)
+	gs.checkError("DeleteSamplers")
+	gs.samplerObjects = make(map[SamplerState]uint32)
+	gs.samplers = make(map[uint32]uint32)
+}
+
+// MaxAnisotropy returns the maximum degree of anisotropic filtering
+// supported by the current context, which is 1 if it is not supported.
+func (gs *GLS) MaxAnisotropy() float32 {
+
+	if gs.maxAnisotropy == 0 {
+		gs.maxAnisotropy = gl.MaxAnisotropy()
+		gs.checkError("MaxAnisotropy")
+	}
+	return gs.maxAnisotropy
+}
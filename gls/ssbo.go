@@ -0,0 +1,238 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"github.com/g3n/engine/gls/gl"
+	"reflect"
+)
+
+// SSBOSupported returns if the current OpenGL context supports shader
+// storage buffer objects, which requires OpenGL 4.3, the
+// ARB_shader_storage_buffer_object extension or OpenGL ES 3.1.
+// They are not supported by WebGL.
+func (gs *GLS) SSBOSupported() bool {
+
+	if gs.ssbo == capUndef {
+		gs.ssbo = capDisabled
+		if gl.SSBOSupported() {
+			gs.ssbo = capEnabled
+		}
+	}
+	return gs.ssbo == capEnabled
+}
+
+// SSBO is a shader storage buffer object which contains the data of a
+// shader storage block ("buffer" block). Unlike uniform buffers, shader
+// storage buffers may be much larger, may contain a runtime sized array as
+// their last member and may be written by the shaders, such as the lights of
+// a clustered lighting pass or the joint matrices of skinned meshes.
+// The data is a slice of numbers or structs whose memory layout must match
+// the std430 layout of the shader storage block, for example by using
+// only 4 component vectors and matrices.
+type SSBO struct {
+	gs      *GLS        // OpenGL state used to create the buffer
+	name    string      // Shader storage block name
+	binding uint32      // Binding point
+	handle  uint32      // OpenGL buffer handle
+	usage   uint32      // Buffer usage hint
+	data    interface{} // Buffer data
+	size    int         // Buffer data size in bytes
+	update  bool        // Data must be transferred
+}
+
+// NewSSBO creates and returns a pointer to a new shader storage buffer
+// for the named shader storage block and the specified binding point.
+func NewSSBO(name string, binding uint32) *SSBO {
+
+	sb := new(SSBO)
+	sb.Init(name, binding)
+	return sb
+}
+
+// Init initializes this shader storage buffer for the named shader
+// storage block and the specified binding point.
+func (sb *SSBO) Init(name string, binding uint32) {
+
+	sb.gs = nil
+	sb.name = name
+	sb.binding = binding
+	sb.handle = 0
+	sb.usage = DYNAMIC_DRAW
+	sb.data = nil
+	sb.size = 0
+	sb.update = true
+}
+
+// Name returns the name of the shader storage block of this buffer
+func (sb *SSBO) Name() string {
+
+	return sb.name
+}
+
+// Binding returns the binding point of this buffer
+func (sb *SSBO) Binding() uint32 {
+
+	return sb.binding
+}
+
+// Handle returns the OpenGL handle of this buffer or 0 if not transferred yet
+func (sb *SSBO) Handle() uint32 {
+
+	return sb.handle
+}
+
+// SetUsage sets the usage hint of this buffer, such as DYNAMIC_DRAW
+// for data updated by the application or DYNAMIC_COPY for data written
+// by the shaders. The default is DYNAMIC_DRAW.
+func (sb *SSBO) SetUsage(usage uint32) {
+
+	sb.usage = usage
+	sb.update = true
+}
+
+// SetData sets the data of this buffer, which must be a slice
+// of numbers or structs. The data is transferred by Transfer().
+func (sb *SSBO) SetData(data interface{}) {
+
+	sb.data = data
+	sb.size = dataSize(data)
+	sb.update = true
+}
+
+// SetSize sets the size in bytes of this buffer without data,
+// for buffers which are filled by the shaders.
+func (sb *SSBO) SetSize(size int) {
+
+	sb.data = nil
+	sb.size = size
+	sb.update = true
+}
+
+// Size returns the current size in bytes of this buffer
+func (sb *SSBO) Size() int {
+
+	return sb.size
+}
+
+// Data returns the current data of this buffer
+func (sb *SSBO) Data() interface{} {
+
+	return sb.data
+}
+
+// Update replaces part of the data of this buffer already transferred,
+// starting at the specified byte offset, with the specified slice.
+func (sb *SSBO) Update(gs *GLS, offset int, data interface{}) {
+
+	if sb.gs == nil || sb.update {
+		sb.Transfer(gs)
+	}
+	gs.BindBuffer(SHADER_STORAGE_BUFFER, sb.handle)
+	gs.BufferSubData(SHADER_STORAGE_BUFFER, offset, dataSize(data), data)
+}
+
+// Transfer creates the OpenGL buffer object if necessary, transfers the data
+// of this buffer if it was changed and binds the buffer to its binding point.
+func (sb *SSBO) Transfer(gs *GLS) {
+
+	if sb.size == 0 {
+		return
+	}
+	first := sb.gs == nil
+	if first {
+		sb.gs = gs
+		sb.handle = gs.CreateBuffer()
+		sb.update = true
+	}
+	if sb.update {
+		gs.UploadBuffer(StorageBuffer, sb.handle, sb.size, sb.data, sb.usage)
+		sb.update = false
+	}
+	if first {
+		gs.ObjectLabel(BUFFER, sb.handle, sb.name)
+	}
+	gs.BindBufferBase(SHADER_STORAGE_BUFFER, sb.binding, sb.handle)
+}
+
+// Dispose deletes the OpenGL buffer object of this buffer
+func (sb *SSBO) Dispose() {
+
+	if sb.gs != nil {
+		sb.gs.DestroyBuffer(sb.handle)
+		sb.gs = nil
+		sb.handle = 0
+	}
+}
+
+// BufferBlockInfo describes an active shader storage block of a program
+type BufferBlockInfo struct {
+	Name     string // Block name
+	Index    uint32 // Block index in the program
+	Binding  uint32 // Current binding point
+	DataSize int    // Minimum size in bytes of the buffer, excluding a runtime sized array
+}
+
+// ShaderStorageBlocks returns the descriptions of the active shader storage
+// blocks of this program or nil if shader storage buffers are not supported.
+func (prog *Program) ShaderStorageBlocks() []BufferBlockInfo {
+
+	if !prog.gs.SSBOSupported() {
+		return nil
+	}
+	count := gl.GetProgramInterfaceiv(prog.handle, SHADER_STORAGE_BLOCK, ACTIVE_RESOURCES)
+	prog.gs.checkError("GetProgramInterfaceiv")
+	props := []uint32{BUFFER_BINDING, BUFFER_DATA_SIZE}
+	blocks := make([]BufferBlockInfo, 0, count)
+	for i := uint32(0); i < uint32(count); i++ {
+		params := gl.GetProgramResourceiv(prog.handle, SHADER_STORAGE_BLOCK, i, props)
+		prog.gs.checkError("GetProgramResourceiv")
+		name := gl.GetProgramResourceName(prog.handle, SHADER_STORAGE_BLOCK, i)
+		prog.gs.checkError("GetProgramResourceName")
+		blocks = append(blocks, BufferBlockInfo{
+			Name:     name,
+			Index:    i,
+			Binding:  uint32(params[0]),
+			DataSize: int(params[1]),
+		})
+	}
+	return blocks
+}
+
+// SetShaderStorageBlockBinding assigns the specified binding point to the
+// named shader storage block of this program. Returns false if the program
+// has no active shader storage block with the specified name.
+// With OpenGL ES the binding point can only be set in the shader
+// with the binding layout qualifier and this call has no effect.
+func (prog *Program) SetShaderStorageBlockBinding(name string, binding uint32) bool {
+
+	if !prog.gs.SSBOSupported() {
+		return false
+	}
+	index := gl.GetProgramResourceIndex(prog.handle, SHADER_STORAGE_BLOCK, name)
+	prog.gs.checkError("GetProgramResourceIndex")
+	if index == INVALID_INDEX {
+		return false
+	}
+	gl.ShaderStorageBlockBinding(prog.handle, index, binding)
+	prog.gs.checkError("ShaderStorageBlockBinding")
+	return true
+}
+
+// dataSize returns the size in bytes of the specified slice, array or pointer
+func dataSize(data interface{}) int {
+
+	if data == nil {
+		return 0
+	}
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Len() * int(v.Type().Elem().Size())
+	case reflect.Ptr:
+		return int(v.Type().Elem().Size())
+	}
+	return 0
+}
@@ -0,0 +1,199 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/g3n/engine/math32"
+	"math"
+	"reflect"
+)
+
+// Std140 encodes Go values into a byte buffer using the std140 layout rules
+// of uniform blocks, so the fields of a Go struct may be declared in the same
+// order as the members of a "layout(std140) uniform" block and transferred
+// in a single uniform buffer.
+//
+// The supported field types and their corresponding GLSL types are:
+//
+//	float32              float
+//	int32, int           int
+//	uint32               uint
+//	bool                 bool
+//	math32.Vector2       vec2
+//	math32.Vector3       vec3
+//	math32.Color         vec3
+//	math32.Vector4       vec4
+//	math32.Color4        vec4
+//	math32.Quaternion    vec4
+//	math32.Matrix3       mat3
+//	math32.Matrix4       mat4
+//	arrays and slices    arrays with the slice length (elements aligned to 16 bytes)
+//	structs              structs (aligned and padded to 16 bytes)
+//
+// Unexported fields are also encoded.
+type Std140 struct {
+	buf []byte
+}
+
+var (
+	typeVector2    = reflect.TypeOf(math32.Vector2{})
+	typeVector3    = reflect.TypeOf(math32.Vector3{})
+	typeVector4    = reflect.TypeOf(math32.Vector4{})
+	typeColor      = reflect.TypeOf(math32.Color{})
+	typeColor4     = reflect.TypeOf(math32.Color4{})
+	typeQuaternion = reflect.TypeOf(math32.Quaternion{})
+	typeMatrix3    = reflect.TypeOf(math32.Matrix3{})
+	typeMatrix4    = reflect.TypeOf(math32.Matrix4{})
+)
+
+// Std140Size returns the size in bytes of the std140 encoding
+// of the specified struct or pointer to struct.
+func Std140Size(v interface{}) (int, error) {
+
+	var enc Std140
+	err := enc.Encode(v)
+	if err != nil {
+		return 0, err
+	}
+	return len(enc.buf), nil
+}
+
+// Reset clears the encoded data keeping the allocated buffer
+func (enc *Std140) Reset() {
+
+	enc.buf = enc.buf[0:0]
+}
+
+// Bytes returns the encoded data
+func (enc *Std140) Bytes() []byte {
+
+	return enc.buf
+}
+
+// Encode appends the std140 encoding of the specified struct or pointer
+// to struct, whose fields correspond to the members of a uniform block.
+func (enc *Std140) Encode(v interface{}) error {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("std140: cannot encode %s as uniform block", rv.Type())
+	}
+	return enc.encode(rv)
+}
+
+// encode appends the encoding of the specified value at its std140 alignment
+func (enc *Std140) encode(rv reflect.Value) error {
+
+	switch rv.Type() {
+	case typeVector2:
+		enc.align(8)
+		enc.putFloats(rv, 2)
+		return nil
+	case typeVector3, typeColor:
+		enc.align(16)
+		enc.putFloats(rv, 3)
+		return nil
+	case typeVector4, typeColor4, typeQuaternion:
+		enc.align(16)
+		enc.putFloats(rv, 4)
+		return nil
+	case typeMatrix3:
+		// Each column is stored as a vec4
+		enc.align(16)
+		for col := 0; col < 3; col++ {
+			for row := 0; row < 3; row++ {
+				enc.putFloat(float32(rv.Index(col*3 + row).Float()))
+			}
+			enc.putFloat(0)
+		}
+		return nil
+	case typeMatrix4:
+		enc.align(16)
+		for i := 0; i < 16; i++ {
+			enc.putFloat(float32(rv.Index(i).Float()))
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Float32:
+		enc.align(4)
+		enc.putFloat(float32(rv.Float()))
+	case reflect.Int32, reflect.Int:
+		enc.align(4)
+		enc.putUint(uint32(int32(rv.Int())))
+	case reflect.Uint32:
+		enc.align(4)
+		enc.putUint(uint32(rv.Uint()))
+	case reflect.Bool:
+		enc.align(4)
+		if rv.Bool() {
+			enc.putUint(1)
+		} else {
+			enc.putUint(0)
+		}
+	case reflect.Array, reflect.Slice:
+		// The stride of array elements is rounded up to the size of a vec4.
+		// Empty slices correspond to block members which are not declared.
+		if rv.Len() == 0 {
+			return nil
+		}
+		for i := 0; i < rv.Len(); i++ {
+			enc.align(16)
+			err := enc.encode(rv.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		enc.align(16)
+	case reflect.Struct:
+		enc.align(16)
+		for i := 0; i < rv.NumField(); i++ {
+			err := enc.encode(rv.Field(i))
+			if err != nil {
+				return err
+			}
+		}
+		enc.align(16)
+	default:
+		return fmt.Errorf("std140: unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+// align appends zero bytes until the buffer length is a multiple of n
+func (enc *Std140) align(n int) {
+
+	for len(enc.buf)%n != 0 {
+		enc.buf = append(enc.buf, 0)
+	}
+}
+
+// putFloats appends the first count float32 fields of the specified struct
+func (enc *Std140) putFloats(rv reflect.Value, count int) {
+
+	for i := 0; i < count; i++ {
+		enc.putFloat(float32(rv.Field(i).Float()))
+	}
+}
+
+// putFloat appends a little endian float32
+func (enc *Std140) putFloat(v float32) {
+
+	enc.putUint(math.Float32bits(v))
+}
+
+// putUint appends a little endian uint32
+func (enc *Std140) putUint(v uint32) {
+
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	enc.buf = append(enc.buf, b[:]...)
+}
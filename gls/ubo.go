@@ -0,0 +1,120 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+// Binding points of the uniform blocks shared by the engine shaders
+const (
+	CameraBlockBinding   = 0 // Camera block with the view and projection matrices
+	LightsBlockBinding   = 1 // Lights block with the parameters of the scene lights
+	MaterialBlockBinding = 2 // Material block with the colors of the current material
+)
+
+// UBO is a uniform buffer object which contains the data of a
+// uniform block encoded with the std140 layout. Once transferred it is bound
+// to its binding point and its data is shared by all the programs whose
+// uniform block of the same name is assigned to this binding point,
+// instead of setting each uniform of each program individually.
+type UBO struct {
+	gs      IDevice // Device used to create the buffer
+	name    string  // Uniform block name
+	binding uint32  // Binding point
+	handle  uint32  // OpenGL buffer handle
+	enc     Std140  // Encoded block data
+	update  bool    // Data must be transferred
+}
+
+// NewUBO creates and returns a pointer to a new uniform buffer
+// for the named uniform block and the specified binding point.
+func NewUBO(name string, binding uint32) *UBO {
+
+	ub := new(UBO)
+	ub.Init(name, binding)
+	return ub
+}
+
+// Init initializes this uniform buffer for the named uniform block
+// and the specified binding point.
+func (ub *UBO) Init(name string, binding uint32) {
+
+	ub.gs = nil
+	ub.name = name
+	ub.binding = binding
+	ub.handle = 0
+	ub.enc.Reset()
+	ub.update = true
+}
+
+// Name returns the name of the uniform block of this buffer
+func (ub *UBO) Name() string {
+
+	return ub.name
+}
+
+// Binding returns the binding point of this buffer
+func (ub *UBO) Binding() uint32 {
+
+	return ub.binding
+}
+
+// Set encodes the specified struct or pointer to struct with the std140
+// layout as the new data of this buffer. The struct fields must be declared
+// in the same order as the members of the uniform block.
+func (ub *UBO) Set(v interface{}) error {
+
+	ub.enc.Reset()
+	err := ub.enc.Encode(v)
+	if err != nil {
+		return err
+	}
+	ub.update = true
+	return nil
+}
+
+// SetBytes sets the data of this buffer already encoded with the std140 layout
+func (ub *UBO) SetBytes(data []byte) {
+
+	ub.enc.buf = append(ub.enc.buf[0:0], data...)
+	ub.update = true
+}
+
+// Bytes returns the current data of this buffer
+func (ub *UBO) Bytes() []byte {
+
+	return ub.enc.Bytes()
+}
+
+// Transfer creates the OpenGL buffer object if necessary, transfers the data
+// of this buffer if it was changed and binds the buffer to its binding point.
+func (ub *UBO) Transfer(gs IDevice) {
+
+	data := ub.enc.Bytes()
+	if len(data) == 0 {
+		return
+	}
+	first := ub.gs == nil
+	if first {
+		ub.gs = gs
+		ub.handle = gs.CreateBuffer()
+		ub.update = true
+	}
+	if ub.update {
+		gs.UploadBuffer(UniformBuffer, ub.handle, len(data), data, DYNAMIC_DRAW)
+		ub.update = false
+	}
+	if first {
+		gs.ObjectLabel(BUFFER, ub.handle, ub.name)
+	}
+	gs.BindUniformBuffer(ub.binding, ub.handle)
+}
+
+// Dispose deletes the OpenGL buffer object of this buffer
+func (ub *UBO) Dispose() {
+
+	if ub.gs != nil {
+		ub.gs.DestroyBuffer(ub.handle)
+		ub.gs = nil
+		ub.handle = 0
+	}
+}
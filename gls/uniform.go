@@ -20,15 +20,15 @@ type Uniform struct {
 
 // Location returns the current location of the uniform
 // for the current active program
-func (uni *Uniform) Location(gs *GLS) int32 {
+func (uni *Uniform) Location(gs IDevice) int32 {
 
-	loc := gs.Prog.GetUniformLocation(uni.name)
+	loc := gs.UniformLocation(uni.name)
 	return loc
 }
 
 // Location returns the current location of the uniform
 // for the current active program and index
-func (uni *Uniform) LocationIdx(gs *GLS, idx int) int32 {
+func (uni *Uniform) LocationIdx(gs IDevice, idx int) int32 {
 
 	// Rebuilds uniform indexed name if necessary
 	if uni.nameidx == "" || uni.idx != idx {
@@ -36,7 +36,7 @@ func (uni *Uniform) LocationIdx(gs *GLS, idx int) int32 {
 		uni.idx = idx
 	}
 	//log.Debug("Location(%s, %d)", uni.name, idx)
-	loc := gs.Prog.GetUniformLocation(uni.nameidx)
+	loc := gs.UniformLocation(uni.nameidx)
 	return loc
 }
 
@@ -70,12 +70,12 @@ func (uni *Uniform1i) Get() int32 {
 	return uni.v0
 }
 
-func (uni *Uniform1i) Transfer(gs *GLS) {
+func (uni *Uniform1i) Transfer(gs IDevice) {
 
 	gs.Uniform1i(uni.Location(gs), uni.v0)
 }
 
-func (uni *Uniform1i) TransferIdx(gs *GLS, idx int) {
+func (uni *Uniform1i) TransferIdx(gs IDevice, idx int) {
 
 	gs.Uniform1i(uni.LocationIdx(gs, idx), uni.v0)
 }
@@ -110,12 +110,12 @@ func (uni *Uniform1f) Get() float32 {
 	return uni.v0
 }
 
-func (uni *Uniform1f) Transfer(gs *GLS) {
+func (uni *Uniform1f) Transfer(gs IDevice) {
 
 	gs.Uniform1f(uni.Location(gs), uni.v0)
 }
 
-func (uni *Uniform1f) TransferIdx(gs *GLS, idx int) {
+func (uni *Uniform1f) TransferIdx(gs IDevice, idx int) {
 
 	gs.Uniform1f(uni.LocationIdx(gs, idx), uni.v0)
 }
@@ -163,12 +163,12 @@ func (uni *Uniform2f) GetVector2() math32.Vector2 {
 	return math32.Vector2{uni.v0, uni.v1}
 }
 
-func (uni *Uniform2f) Transfer(gs *GLS) {
+func (uni *Uniform2f) Transfer(gs IDevice) {
 
 	gs.Uniform2f(uni.Location(gs), uni.v0, uni.v1)
 }
 
-func (uni *Uniform2f) TransferIdx(gs *GLS, idx int) {
+func (uni *Uniform2f) TransferIdx(gs IDevice, idx int) {
 
 	gs.Uniform2f(uni.LocationIdx(gs, idx), uni.v0, uni.v1)
 }
@@ -231,14 +231,14 @@ func (uni *Uniform3f) GetColor() math32.Color {
 	return math32.Color{uni.v0, uni.v1, uni.v2}
 }
 
-func (uni *Uniform3f) Transfer(gl *GLS) {
+func (uni *Uniform3f) Transfer(gl IDevice) {
 
 	loc := uni.Location(gl)
 	gl.Uniform3f(loc, uni.v0, uni.v1, uni.v2)
 	//log.Debug("Uniform3f: %s (%v) -> %v,%v,%v", uni.name, loc, uni.v0, uni.v1, uni.v2)
 }
 
-func (uni *Uniform3f) TransferIdx(gl *GLS, idx int) {
+func (uni *Uniform3f) TransferIdx(gl IDevice, idx int) {
 
 	loc := uni.LocationIdx(gl, idx)
 	gl.Uniform3f(loc, uni.v0, uni.v1, uni.v2)
@@ -307,13 +307,13 @@ func (uni *Uniform4f) GetColor4() math32.Color4 {
 	return math32.Color4{uni.v0, uni.v1, uni.v2, uni.v3}
 }
 
-func (uni *Uniform4f) Transfer(gl *GLS) {
+func (uni *Uniform4f) Transfer(gl IDevice) {
 
 	//log.Debug("Uniform4f.Transfer: %s %d", uni.name, uni.Location(gl))
 	gl.Uniform4f(uni.Location(gl), uni.v0, uni.v1, uni.v2, uni.v3)
 }
 
-func (uni *Uniform4f) TransferIdx(gl *GLS, idx int) {
+func (uni *Uniform4f) TransferIdx(gl IDevice, idx int) {
 
 	gl.Uniform4f(uni.LocationIdx(gl, idx), uni.v0, uni.v1, uni.v2, uni.v3)
 }
@@ -349,12 +349,12 @@ func (uni *UniformMatrix3f) GetMatrix3() math32.Matrix3 {
 	return uni.v
 }
 
-func (uni *UniformMatrix3f) Transfer(gl *GLS) {
+func (uni *UniformMatrix3f) Transfer(gl IDevice) {
 
 	gl.UniformMatrix3fv(uni.Location(gl), 1, false, uni.v[0:9])
 }
 
-func (uni *UniformMatrix3f) TransferIdx(gl *GLS, idx int) {
+func (uni *UniformMatrix3f) TransferIdx(gl IDevice, idx int) {
 
 	gl.UniformMatrix3fv(uni.LocationIdx(gl, idx), 1, false, uni.v[0:9])
 }
@@ -390,12 +390,12 @@ func (uni *UniformMatrix4f) GetMatrix4() math32.Matrix4 {
 	return uni.v
 }
 
-func (uni *UniformMatrix4f) Transfer(gl *GLS) {
+func (uni *UniformMatrix4f) Transfer(gl IDevice) {
 
 	gl.UniformMatrix4fv(uni.Location(gl), 1, false, uni.v[0:16])
 }
 
-func (uni *UniformMatrix4f) TransferIdx(gl *GLS, idx int) {
+func (uni *UniformMatrix4f) TransferIdx(gl IDevice, idx int) {
 
 	gl.UniformMatrix4fv(uni.LocationIdx(gl, idx), 1, false, uni.v[0:16])
 }
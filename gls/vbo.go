@@ -11,7 +11,7 @@ import (
 
 // VBO abstracts an OpenGL Vertex Buffer Object
 type VBO struct {
-	gs      *GLS
+	gs      IDevice
 	handle  uint32          // OpenGL handle for this VBO
 	usage   uint32          // Expected usage patter of the buffer
 	update  bool            // Update flag
@@ -97,14 +97,48 @@ func (vbo *VBO) Buffer() *math32.ArrayF32 {
 	return &vbo.buffer
 }
 
+// Handle returns the OpenGL handle of this VBO or 0 if not transferred yet
+func (vbo *VBO) Handle() uint32 {
+
+	return vbo.handle
+}
+
 // Updates sets the update flag to force the VBO update
 func (vbo *VBO) Update() {
 
 	vbo.update = true
 }
 
+// SetAttribs enables the attributes of this VBO in the current vertex array
+// object and sets their stride and offset in its buffer. It is called by
+// Transfer the first time and by the geometries which set up the vertex array
+// objects of other OpenGL contexts sharing the buffer.
+func (vbo *VBO) SetAttribs(gs IDevice) {
+
+	// Calculates stride
+	elsize := int32(unsafe.Sizeof(float32(0)))
+	var stride int32 = 0
+	for _, attrib := range vbo.attribs {
+		stride += elsize * attrib.ItemSize
+	}
+	// For each attribute
+	var items uint32 = 0
+	var offset uint32 = 0
+	for _, attrib := range vbo.attribs {
+		// Get attribute location in the current program
+		loc := gs.AttribLocation(attrib.Name)
+		if loc < 0 {
+			continue
+		}
+		// Enables attribute and sets its stride and offset in the buffer
+		gs.SetVertexAttrib(vbo.handle, uint32(loc), attrib.ItemSize, stride, offset)
+		items += uint32(attrib.ItemSize)
+		offset = uint32(elsize) * items
+	}
+}
+
 // Transfer is called internally and transfer the data in the VBO buffer to OpenGL if necessary
-func (vbo *VBO) Transfer(gs *GLS) {
+func (vbo *VBO) Transfer(gs IDevice) {
 
 	// If the VBO buffer is empty, ignore
 	if vbo.buffer.Bytes() == 0 {
@@ -113,36 +147,14 @@ func (vbo *VBO) Transfer(gs *GLS) {
 
 	// First time initialization
 	if vbo.gs == nil {
-		vbo.handle = gs.GenBuffer()
-		gs.BindBuffer(ARRAY_BUFFER, vbo.handle)
-		// Calculates stride
-		elsize := int32(unsafe.Sizeof(float32(0)))
-		var stride int32 = 0
-		for _, attrib := range vbo.attribs {
-			stride += elsize * attrib.ItemSize
-		}
-		// For each attribute
-		var items uint32 = 0
-		var offset uint32 = 0
-		for _, attrib := range vbo.attribs {
-			// Get attribute location in the current program
-			loc := gs.Prog.GetAttribLocation(attrib.Name)
-			if loc < 0 {
-				continue
-			}
-			// Enables attribute and sets its stride and offset in the buffer
-			gs.EnableVertexAttribArray(uint32(loc))
-			gs.VertexAttribPointer(uint32(loc), attrib.ItemSize, FLOAT, false, stride, offset)
-			items += uint32(attrib.ItemSize)
-			offset = uint32(elsize) * items
-		}
+		vbo.handle = gs.CreateBuffer()
+		vbo.SetAttribs(gs)
 		vbo.gs = gs // this indicates that the vbo was initialized
 	}
 	if !vbo.update {
 		return
 	}
 	// Transfer the VBO data to OpenGL
-	gs.BindBuffer(ARRAY_BUFFER, vbo.handle)
-	gs.BufferData(ARRAY_BUFFER, vbo.buffer.Bytes(), &vbo.buffer[0], vbo.usage)
+	gs.UploadBuffer(VertexBuffer, vbo.handle, vbo.buffer.Bytes(), &vbo.buffer[0], vbo.usage)
 	vbo.update = false
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/g3n/engine/geometry"
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
 )
 
 // Graphic is a Node which has a visible representation in the scene.
@@ -16,11 +17,12 @@ import (
 // It is the base type used by other graphics such as lines, line_strip,
 // points and meshes.
 type Graphic struct {
-	core.Node                     // Embedded Node
-	igeom      geometry.IGeometry // Associated IGeometry
-	materials  []GraphicMaterial  // Materials
-	mode       uint32             // OpenGL primitive
-	renderable bool               // Renderable flag
+	core.Node                      // Embedded Node
+	igeom      geometry.IGeometry  // Associated IGeometry
+	materials  []GraphicMaterial   // Materials
+	mode       uint32              // OpenGL primitive
+	renderable bool                // Renderable flag
+	indirect   *gls.IndirectBuffer // Optional indirect draw commands
 }
 
 // GraphicMaterial specifies the material to be used for
@@ -40,7 +42,7 @@ type IGraphic interface {
 	GetGeometry() *geometry.Geometry
 	Renderable() bool
 	SetRenderable(bool)
-	RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo)
+	RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo)
 }
 
 // Init initializes a Graphic type embedded in another type
@@ -92,6 +94,33 @@ func (gr *Graphic) Renderable() bool {
 	return gr.renderable
 }
 
+// SetIndirect sets the buffer of indirect draw commands used to draw the
+// indexed geometry of this graphic instead of the ranges of its materials,
+// or nil to draw the ranges of the materials.
+func (gr *Graphic) SetIndirect(buf *gls.IndirectBuffer) {
+
+	gr.indirect = buf
+}
+
+// Indirect returns the buffer of indirect draw commands of this graphic or nil
+func (gr *Graphic) Indirect() *gls.IndirectBuffer {
+
+	return gr.indirect
+}
+
+// LocalBoundingBox satisfies the core.IBounded interface and returns the
+// bounding box of this graphic geometry in local coordinates.
+// Returns false if the geometry has no separate vertex position buffer.
+func (gr *Graphic) LocalBoundingBox() (math32.Box3, bool) {
+
+	geom := gr.igeom.GetGeometry()
+	vbo := geom.VBO("VertexPosition")
+	if vbo == nil || vbo.AttribCount() != 1 {
+		return math32.Box3{}, false
+	}
+	return geom.BoundingBox(), true
+}
+
 // Add material for the specified subset of vertices.
 // If the material applies to all vertices, start and count must be 0.
 func (gr *Graphic) AddMaterial(igr IGraphic, imat material.IMaterial, start, count int) {
@@ -142,19 +171,62 @@ func (grmat *GraphicMaterial) GetMaterial() material.IMaterial {
 	return grmat.imat
 }
 
+// Range returns the index of the first element and the number of elements of the
+// geometry rendered with this GraphicMaterial. A count of 0 means all elements.
+func (grmat *GraphicMaterial) Range() (start, count int) {
+
+	return grmat.start, grmat.count
+}
+
+// GetGraphic returns the graphic which contains this GraphicMaterial
+func (grmat *GraphicMaterial) GetGraphic() IGraphic {
+
+	return grmat.igraphic
+}
+
 // Render is called by the renderer to render this graphic material
-func (grmat *GraphicMaterial) Render(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (grmat *GraphicMaterial) Render(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Setup the associated material (set states and transfer material uniforms and textures)
 	grmat.imat.RenderSetup(gs)
 
-	// Setup the associated geometry (set VAO and transfer VBOS)
-	gr := grmat.igraphic.GetGraphic()
-	gr.igeom.RenderSetup(gs)
+	// In the order independent transparency pass all fragments are accumulated
+	// with additive blending and the depth buffer is only tested.
+	// After a depth pre-pass only the fragments with the pre-pass depth are shaded.
+	if rinfo.OIT || rinfo.DepthPrepass {
+		var ps gls.PipelineState
+		grmat.imat.GetMaterial().PipelineState(&ps)
+		ps.DepthMask = false
+		if rinfo.OIT {
+			ps.Blend = true
+			ps.BlendEquationRGB = gls.FUNC_ADD
+			ps.BlendEquationAlpha = gls.FUNC_ADD
+			ps.BlendSrcRGB = gls.ONE
+			ps.BlendDstRGB = gls.ONE
+			ps.BlendSrcAlpha = gls.ZERO
+			ps.BlendDstAlpha = gls.ONE_MINUS_SRC_ALPHA
+		}
+		if rinfo.DepthPrepass {
+			ps.DepthFunc = gls.EQUAL
+		}
+		gs.SetPipelineState(&ps)
+	}
 
 	// Setup current graphic (transfer matrices)
 	grmat.igraphic.RenderSetup(gs, rinfo)
 
+	// Setup geometry and draw
+	grmat.RenderGeometry(gs)
+}
+
+// RenderGeometry sets up the geometry of the graphic of this graphic material
+// and draws its elements with the current program and states.
+func (grmat *GraphicMaterial) RenderGeometry(gs gls.IDevice) {
+
+	// Setup the associated geometry (set VAO and transfer VBOS)
+	gr := grmat.igraphic.GetGraphic()
+	gr.igeom.RenderSetup(gs)
+
 	// Get the number of vertices for the current material
 	count := grmat.count
 
@@ -162,15 +234,19 @@ func (grmat *GraphicMaterial) Render(gs *gls.GLS, rinfo *core.RenderInfo) {
 	indices := geom.Indices()
 	// Indexed geometry
 	if indices.Size() > 0 {
+		if gr.indirect != nil {
+			gs.DrawIndexedIndirect(gr.mode, gr.indirect)
+			return
+		}
 		if count == 0 {
 			count = indices.Size()
 		}
-		gs.DrawElements(gr.mode, int32(count), gls.UNSIGNED_INT, 4*uint32(grmat.start))
+		gs.DrawIndexed(gr.mode, grmat.start, count)
 		// Non indexed geometry
 	} else {
 		if count == 0 {
 			count = geom.Items()
 		}
-		gs.DrawArrays(gr.mode, int32(grmat.start), int32(count))
+		gs.Draw(gr.mode, grmat.start, count)
 	}
 }
@@ -29,7 +29,7 @@ func NewLineStrip(igeom geometry.IGeometry, imat material.IMaterial) *LineStrip
 }
 
 // RenderSetup is called by the engine before drawing this geometry
-func (l *LineStrip) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (l *LineStrip) RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Calculates model view projection matrix and updates uniform
 	mw := l.MatrixWorld()
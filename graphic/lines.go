@@ -33,7 +33,7 @@ func NewLines(igeom geometry.IGeometry, imat material.IMaterial) *Lines {
 }
 
 // RenderSetup is called by the engine before drawing this geometry
-func (l *Lines) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (l *Lines) RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Calculates model view projection matrix and updates uniform
 	mw := l.MatrixWorld()
@@ -13,10 +13,13 @@ import (
 )
 
 type Mesh struct {
-	Graphic                     // Embedded graphic
-	mvm     gls.UniformMatrix4f // Model view matrix uniform
-	mvpm    gls.UniformMatrix4f // Model view projection matrix uniform
-	nm      gls.UniformMatrix3f // Normal matrix uniform
+	Graphic                                       // Embedded graphic
+	mvm     gls.UniformMatrix4f                   // Model view matrix uniform
+	mvpm    gls.UniformMatrix4f                   // Model view projection matrix uniform
+	nm      gls.UniformMatrix3f                   // Normal matrix uniform
+	deform  func(idx uint32, pos *math32.Vector3) // deforms the vertex positions when raycasting
+	dbounds *math32.Box3                          // bounds of the deformed vertex positions
+	inst    []math32.Matrix4                      // model matrices of the instances when raycasting
 }
 
 // NewMesh creates and returns a pointer to a mesh with the specified geometry and material
@@ -58,7 +61,7 @@ func (m *Mesh) AddGroupMaterial(imat material.IMaterial, gindex int) {
 // RenderSetup is called by the engine before drawing the mesh geometry
 // It is responsible to updating the current shader uniforms with
 // the model matrices.
-func (m *Mesh) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (m *Mesh) RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Calculates model view matrix and updates uniform
 	mw := m.MatrixWorld()
@@ -80,32 +83,76 @@ func (m *Mesh) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 	m.nm.Transfer(gs)
 }
 
+// SetRaycastDeformer sets the function which deforms the position of the vertex
+// with the specified index, in model coordinates, when raycasting this mesh, as the
+// CPU skinning of a skinned mesh, so picking matches the rendered pose.
+// Only the vertices of the faces tested are deformed.
+// If bounds is not nil it must contain all the deformed positions and the rays which
+// don't intersect it are rejected. Otherwise all the faces of the mesh are tested.
+func (m *Mesh) SetRaycastDeformer(deform func(idx uint32, pos *math32.Vector3), bounds *math32.Box3) {
+
+	m.deform = deform
+	m.dbounds = bounds
+}
+
+// SetRaycastInstances sets the model matrices, relative to this mesh, of the
+// instances drawn by this mesh, so raycasting tests each instance and sets the
+// index of the intersected instance in the intersects. Nil tests the mesh once.
+func (m *Mesh) SetRaycastInstances(matrices []math32.Matrix4) {
+
+	m.inst = matrices
+}
+
 // Raycast checks intersections between this geometry and the specified raycaster
 // and if any found appends it to the specified intersects array.
 func (m *Mesh) Raycast(rc *core.Raycaster, intersects *[]core.Intersect) {
 
-	// Transform this mesh geometry bounding sphere from model
-	// to world coordinates and checks intersection with raycaster
-	geom := m.GetGeometry()
-	sphere := geom.BoundingSphere()
 	matrixWorld := m.MatrixWorld()
-	sphere.ApplyMatrix4(&matrixWorld)
-	if !rc.IsIntersectionSphere(&sphere) {
+	if len(m.inst) == 0 {
+		m.raycastInstance(rc, &matrixWorld, 0, intersects)
 		return
 	}
+	var mw math32.Matrix4
+	for i := range m.inst {
+		mw.MultiplyMatrices(&matrixWorld, &m.inst[i])
+		m.raycastInstance(rc, &mw, i, intersects)
+	}
+}
+
+// raycastInstance checks intersections between the instance of this mesh with the
+// specified world matrix and the specified raycaster and appends them to intersects
+func (m *Mesh) raycastInstance(rc *core.Raycaster, matrixWorld *math32.Matrix4, instance int, intersects *[]core.Intersect) {
+
+	// Transform this mesh geometry bounding sphere from model
+	// to world coordinates and checks intersection with raycaster.
+	// The bounds of the geometry don't apply to deformed vertices.
+	geom := m.GetGeometry()
+	if m.deform == nil {
+		sphere := geom.BoundingSphere()
+		sphere.ApplyMatrix4(matrixWorld)
+		if !rc.IsIntersectionSphere(&sphere) {
+			return
+		}
+	}
 
 	// Copy ray and transform to model coordinates
 	// This ray will will also be used to check intersects with
 	// the geometry, as is much less expensive to transform the
 	// ray to model coordinates than the geometry to world coordinates.
 	var inverseMatrix math32.Matrix4
-	inverseMatrix.GetInverse(&matrixWorld, true)
+	inverseMatrix.GetInverse(matrixWorld, true)
 	var ray math32.Ray
 	ray.Copy(&rc.Ray).ApplyMatrix4(&inverseMatrix)
-	bbox := geom.BoundingBox()
-	if !ray.IsIntersectionBox(&bbox) {
+	if m.deform == nil {
+		bbox := geom.BoundingBox()
+		if !ray.IsIntersectionBox(&bbox) {
+			return
+		}
+	} else if m.dbounds != nil && !ray.IsIntersectionBox(m.dbounds) {
 		return
 	}
+	var normalMatrix math32.Matrix3
+	normalMatrix.GetNormalMatrix(matrixWorld)
 
 	// Local function to check the intersection of the ray from the raycaster with
 	// the specified face defined by three poins.
@@ -126,7 +173,7 @@ func (m *Mesh) Raycast(rc *core.Raycaster, intersects *[]core.Intersect) {
 
 		// Transform intersection point from model to world coordinates
 		var intersectionPointWorld = *point
-		intersectionPointWorld.ApplyMatrix4(&matrixWorld)
+		intersectionPointWorld.ApplyMatrix4(matrixWorld)
 
 		// Calculates the distance from the ray origin to intersection point
 		origin := rc.Ray.Origin()
@@ -141,58 +188,66 @@ func (m *Mesh) Raycast(rc *core.Raycaster, intersects *[]core.Intersect) {
 			Distance: distance,
 			Point:    intersectionPointWorld,
 			Object:   m,
+			Instance: instance,
 		}
 	}
 
 	// Get buffer with position vertices
-	vboPos := geom.VBO("VertexPosition")
-	if vboPos == nil {
+	positions, ok := findAttrib(geom, "VertexPosition")
+	if !ok {
 		panic("mesh.Raycast(): VertexPosition VBO not found")
 	}
-	positions := vboPos.Buffer()
 	indices := geom.Indices()
 
 	var vA math32.Vector3
 	var vB math32.Vector3
 	var vC math32.Vector3
 
+	// Local function to get the face position vectors,
+	// deformed if the mesh has a deformer
+	getFace := func(face [3]uint32) {
+
+		positions.vector3(face[0], &vA)
+		positions.vector3(face[1], &vB)
+		positions.vector3(face[2], &vC)
+		if m.deform != nil {
+			m.deform(face[0], &vA)
+			m.deform(face[1], &vB)
+			m.deform(face[2], &vC)
+		}
+	}
+
 	// Geometry has indexed vertices
 	if indices.Size() > 0 {
 		for i := 0; i < indices.Size(); i += 3 {
 			// Get face indices
-			a := indices[i]
-			b := indices[i+1]
-			c := indices[i+2]
-			// Get face position vectors
-			positions.GetVector3(int(3*a), &vA)
-			positions.GetVector3(int(3*b), &vB)
-			positions.GetVector3(int(3*c), &vC)
+			face := [3]uint32{indices[i], indices[i+1], indices[i+2]}
+			getFace(face)
 			// Checks intersection of the ray with this face
 			mat := m.GetMaterial(i).GetMaterial()
 			var point math32.Vector3
 			intersect := checkIntersection(mat, &vA, &vB, &vC, &point)
 			if intersect != nil {
 				intersect.Index = uint32(i)
+				setSurface(geom, face, &vA, &vB, &vC, &point, &normalMatrix, intersect)
 				*intersects = append(*intersects, *intersect)
 			}
 		}
 		// Geometry has NO indexed vertices
 	} else {
-		for i := 0; i < positions.Size(); i += 9 {
+		count := positions.buffer.Size() / positions.stride
+		for i := 0; i+2 < count; i += 3 {
 			// Get face indices
-			a := i / 3
-			b := a + 1
-			c := a + 2
-			// Set face position vectors
-			positions.GetVector3(int(3*a), &vA)
-			positions.GetVector3(int(3*b), &vB)
-			positions.GetVector3(int(3*c), &vC)
+			a := uint32(i)
+			face := [3]uint32{a, a + 1, a + 2}
+			getFace(face)
 			// Checks intersection of the ray with this face
-			mat := m.GetMaterial(i).GetMaterial()
+			mat := m.GetMaterial(3 * i).GetMaterial()
 			var point math32.Vector3
 			intersect := checkIntersection(mat, &vA, &vB, &vC, &point)
 			if intersect != nil {
-				intersect.Index = uint32(a)
+				intersect.Index = a
+				setSurface(geom, face, &vA, &vB, &vC, &point, &normalMatrix, intersect)
 				*intersects = append(*intersects, *intersect)
 			}
 		}
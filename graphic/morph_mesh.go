@@ -0,0 +1,171 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// MorphMesh is a mesh with morph targets, also called blend shapes, as the
+// expressions of a face. Each morph target has displacements of the positions
+// and optionally of the normals of the vertices of the mesh which are added
+// to the base vertices multiplied by the weight of the target.
+// The vertices are morphed when the weights change, in the buffers of the
+// geometry, so the geometry must not be shared with other meshes.
+// The weights of a MorphMesh are animated by the Weights tracks of the
+// animation package.
+type MorphMesh struct {
+	Mesh                         // Embedded mesh
+	targets     []morphTarget    // morph targets
+	weights     []float32        // weights of the morph targets
+	basePos     []math32.Vector3 // positions of the vertices without morphing
+	baseNormals []math32.Vector3 // normals of the vertices without morphing or nil
+}
+
+// morphTarget is a morph target of a MorphMesh
+type morphTarget struct {
+	name      string          // name of the target
+	positions math32.ArrayF32 // displacements of the positions of the vertices
+	normals   math32.ArrayF32 // displacements of the normals of the vertices or nil
+}
+
+// NewMorphMesh creates and returns a pointer to a new morph mesh with the
+// specified geometry and material. The current positions and normals
+// of the geometry are its base vertices.
+func NewMorphMesh(igeom geometry.IGeometry, imat material.IMaterial) *MorphMesh {
+
+	m := new(MorphMesh)
+	m.Init(igeom, imat)
+	return m
+}
+
+// Init initializes this morph mesh with the specified geometry and material
+func (m *MorphMesh) Init(igeom geometry.IGeometry, imat material.IMaterial) {
+
+	m.Mesh.Init(igeom, imat)
+	geom := igeom.GetGeometry()
+	if va, ok := findAttrib(geom, "VertexPosition"); ok {
+		m.basePos = make([]math32.Vector3, len(va.buffer)/va.stride)
+		for i := range m.basePos {
+			va.vector3(uint32(i), &m.basePos[i])
+		}
+	}
+	if va, ok := findAttrib(geom, "VertexNormal"); ok {
+		m.baseNormals = make([]math32.Vector3, len(va.buffer)/va.stride)
+		for i := range m.baseNormals {
+			va.vector3(uint32(i), &m.baseNormals[i])
+		}
+	}
+}
+
+// AddMorphTarget adds a morph target with the specified name and displacements
+// of the positions, and optionally of the normals, of the vertices, with 3 values
+// for each vertex as in glTF, and returns its index. Its weight is 0.
+func (m *MorphMesh) AddMorphTarget(name string, positions, normals math32.ArrayF32) int {
+
+	if len(positions) != 3*len(m.basePos) || (normals != nil && len(normals) != 3*len(m.basePos)) {
+		panic("graphic.AddMorphTarget: number of displacements does not match the number of vertices")
+	}
+	m.targets = append(m.targets, morphTarget{name, positions, normals})
+	m.weights = append(m.weights, 0)
+	return len(m.targets) - 1
+}
+
+// MorphTargetCount returns the number of morph targets of this mesh
+func (m *MorphMesh) MorphTargetCount() int {
+
+	return len(m.targets)
+}
+
+// MorphTargetName returns the name of the morph target with the specified index
+func (m *MorphMesh) MorphTargetName(idx int) string {
+
+	return m.targets[idx].name
+}
+
+// MorphTargetIndex returns the index of the morph target with
+// the specified name or -1 if not found
+func (m *MorphMesh) MorphTargetIndex(name string) int {
+
+	for i := range m.targets {
+		if m.targets[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetWeights sets the weights of the morph targets, in the order they were added,
+// copying the specified slice, and morphs the vertices. The targets without a
+// weight in the slice keep their weights.
+func (m *MorphMesh) SetWeights(weights []float32) {
+
+	copy(m.weights, weights)
+	m.morph()
+}
+
+// Weights returns the weights of the morph targets
+func (m *MorphMesh) Weights() []float32 {
+
+	return m.weights
+}
+
+// SetWeight sets the weight of the morph target with the specified index and morphs the vertices
+func (m *MorphMesh) SetWeight(idx int, weight float32) {
+
+	m.weights[idx] = weight
+	m.morph()
+}
+
+// Weight returns the weight of the morph target with the specified index
+func (m *MorphMesh) Weight(idx int) float32 {
+
+	return m.weights[idx]
+}
+
+// morph sets the positions and normals of the vertices in the buffers of the
+// geometry from the base vertices and the weighted displacements of the targets
+func (m *MorphMesh) morph() {
+
+	geom := m.GetGeometry()
+	if va, ok := findAttrib(geom, "VertexPosition"); ok {
+		m.blend(&va, m.basePos, false)
+		geom.VBO("VertexPosition").Update()
+		geom.InvalidateBounds()
+	}
+	if va, ok := findAttrib(geom, "VertexNormal"); ok && m.baseNormals != nil {
+		m.blend(&va, m.baseNormals, true)
+		geom.VBO("VertexNormal").Update()
+	}
+}
+
+// blend sets the specified attribute of the vertices with the specified base
+// values plus the weighted displacements of the positions or normals of the targets
+func (m *MorphMesh) blend(va *vertexAttrib, base []math32.Vector3, normals bool) {
+
+	var v math32.Vector3
+	for i := range base {
+		v = base[i]
+		for t := range m.targets {
+			w := m.weights[t]
+			d := m.targets[t].positions
+			if normals {
+				d = m.targets[t].normals
+			}
+			if w == 0 || d == nil {
+				continue
+			}
+			v.X += d[3*i] * w
+			v.Y += d[3*i+1] * w
+			v.Z += d[3*i+2] * w
+		}
+		if normals {
+			v.Normalize()
+		}
+		va.buffer.SetVector3(i*va.stride+va.offset, &v)
+	}
+}
@@ -31,7 +31,7 @@ func NewPoints(igeom geometry.IGeometry, imat material.IMaterial) *Points {
 }
 
 // RenderSetup is called by the engine before rendering this graphic
-func (p *Points) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (p *Points) RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Calculates model view projection matrix and updates uniform
 	mw := p.MatrixWorld()
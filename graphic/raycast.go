@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// vertexAttrib locates the values of a vertex attribute in the buffer of a VBO,
+// which may have other attributes interleaved
+type vertexAttrib struct {
+	buffer math32.ArrayF32 // buffer of the VBO with the attribute
+	offset int             // offset of the attribute in the values of each vertex
+	stride int             // number of values of each vertex
+}
+
+// findAttrib finds the vertex attribute with the specified name in the
+// VBOs of the specified geometry. Returns false if not found.
+func findAttrib(geom *geometry.Geometry, name string) (vertexAttrib, bool) {
+
+	var va vertexAttrib
+	vbo := geom.VBO(name)
+	if vbo == nil {
+		return va, false
+	}
+	for i := 0; i < vbo.AttribCount(); i++ {
+		attr := vbo.AttribAt(i)
+		if attr.Name == name {
+			va.offset = va.stride
+		}
+		va.stride += int(attr.ItemSize)
+	}
+	va.buffer = *vbo.Buffer()
+	return va, true
+}
+
+// vector3 sets the specified vector with the attribute of the vertex with the specified index
+func (va *vertexAttrib) vector3(idx uint32, v *math32.Vector3) {
+
+	va.buffer.GetVector3(int(idx)*va.stride+va.offset, v)
+}
+
+// vector2 sets the specified vector with the attribute of the vertex with the specified index
+func (va *vertexAttrib) vector2(idx uint32, v *math32.Vector2) {
+
+	va.buffer.GetVector2(int(idx)*va.stride+va.offset, v)
+}
+
+// setSurface sets the face, barycentric coordinates, texture coordinates and normal
+// of the specified intersect with the face of the specified geometry with the specified
+// vertex indices and positions, at the specified point, all in model coordinates.
+// The normal is transformed to world coordinates by the specified normal matrix.
+func setSurface(geom *geometry.Geometry, face [3]uint32, pA, pB, pC, point *math32.Vector3, nm *math32.Matrix3, in *core.Intersect) {
+
+	in.Face = face
+	math32.BarycoordFromPoint(point, pA, pB, pC, &in.Barycentric)
+	w := in.Barycentric
+
+	// Interpolates the texture coordinates of the vertices
+	if va, ok := findAttrib(geom, "VertexTexcoord"); ok {
+		var uvA, uvB, uvC math32.Vector2
+		va.vector2(face[0], &uvA)
+		va.vector2(face[1], &uvB)
+		va.vector2(face[2], &uvC)
+		in.UV.X = uvA.X*w.X + uvB.X*w.Y + uvC.X*w.Z
+		in.UV.Y = uvA.Y*w.X + uvB.Y*w.Y + uvC.Y*w.Z
+		in.HasUV = true
+	}
+
+	// Interpolates the normals of the vertices or uses the normal of the face
+	if va, ok := findAttrib(geom, "VertexNormal"); ok {
+		var nA, nB, nC math32.Vector3
+		va.vector3(face[0], &nA)
+		va.vector3(face[1], &nB)
+		va.vector3(face[2], &nC)
+		in.Normal.Set(
+			nA.X*w.X+nB.X*w.Y+nC.X*w.Z,
+			nA.Y*w.X+nB.Y*w.Y+nC.Y*w.Z,
+			nA.Z*w.X+nB.Z*w.Y+nC.Z*w.Z,
+		)
+	} else {
+		math32.Normal(pA, pB, pC, &in.Normal)
+	}
+	in.Normal.ApplyMatrix3(nm).Normalize()
+}
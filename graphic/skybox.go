@@ -33,6 +33,7 @@ func NewSkybox(data SkyboxData) (*Skybox, error) {
 
 	geom := geometry.NewBox(50, 50, 50, 1, 1, 1)
 	skybox.Graphic.Init(geom, gls.TRIANGLES)
+	skybox.SetNeverCull(true)
 
 	for i := 0; i < 6; i++ {
 		tex, err := texture.NewTexture2DFromImage(data.DirAndPrefix + data.Suffixes[i] + "." + data.Extension)
@@ -57,7 +58,7 @@ func NewSkybox(data SkyboxData) (*Skybox, error) {
 // RenderSetup is called by the engine before drawing the skybox geometry
 // It is responsible to updating the current shader uniforms with
 // the model matrices.
-func (skybox *Skybox) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (skybox *Skybox) RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// TODO
 	// Disable writes to the depth buffer (call glDepthMask(GL_FALSE)).
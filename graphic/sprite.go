@@ -55,7 +55,7 @@ func NewSprite(width, height float32, imat material.IMaterial) *Sprite {
 	return s
 }
 
-func (s *Sprite) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+func (s *Sprite) RenderSetup(gs gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Calculates model view matrix
 	mw := s.MatrixWorld()
@@ -112,28 +112,26 @@ func (s *Sprite) Raycast(rc *core.Raycaster, intersects *[]core.Intersect) {
 
 	// Get buffer with vertices and uvs
 	geom := s.GetGeometry()
-	vboPos := geom.VBO("VertexPosition")
-	if vboPos == nil {
+	positions, ok := findAttrib(geom, "VertexPosition")
+	if !ok {
 		panic("sprite.Raycast(): VertexPosition VBO not found")
 	}
 	// Get vertex positions, transform to camera coordinates and
 	// checks intersection with ray
-	buffer := vboPos.Buffer()
 	indices := geom.Indices()
 	var v1 math32.Vector3
 	var v2 math32.Vector3
 	var v3 math32.Vector3
 	var point math32.Vector3
+	var face [3]uint32
 	intersect := false
 	for i := 0; i < indices.Size(); i += 3 {
-		pos := indices[i]
-		buffer.GetVector3(int(pos*5), &v1)
+		face = [3]uint32{indices[i], indices[i+1], indices[i+2]}
+		positions.vector3(face[0], &v1)
 		v1.ApplyMatrix4(&mv)
-		pos = indices[i+1]
-		buffer.GetVector3(int(pos*5), &v2)
+		positions.vector3(face[1], &v2)
 		v2.ApplyMatrix4(&mv)
-		pos = indices[i+2]
-		buffer.GetVector3(int(pos*5), &v3)
+		positions.vector3(face[2], &v3)
 		v3.ApplyMatrix4(&mv)
 		if ray.IntersectTriangle(&v1, &v2, &v3, false, &point) {
 			intersect = true
@@ -152,10 +150,24 @@ func (s *Sprite) Raycast(rc *core.Raycaster, intersects *[]core.Intersect) {
 		return
 	}
 
-	// Appends intersection to received parameter.
-	*intersects = append(*intersects, core.Intersect{
+	// The sprite always faces the camera, so its normal
+	// in camera coordinates points to the positive Z axis
+	in := core.Intersect{
 		Distance: distance,
-		Point:    point,
 		Object:   s,
-	})
+	}
+	var nm math32.Matrix3
+	nm.GetNormalMatrix(&rc.ViewMatrix)
+	nm.Transpose()
+	setSurface(geom, face, &v1, &v2, &v3, &point, &nm, &in)
+	in.Normal.Set(0, 0, 1).ApplyMatrix3(&nm).Normalize()
+
+	// Transforms intersection point from camera to world coordinates
+	var viewInverse math32.Matrix4
+	viewInverse.GetInverse(&rc.ViewMatrix, false)
+	in.Point = point
+	in.Point.ApplyMatrix4(&viewInverse)
+
+	// Appends intersection to received parameter.
+	*intersects = append(*intersects, in)
 }
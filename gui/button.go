@@ -67,6 +67,7 @@ func NewButton(text string) *Button {
 	b.Panel.Subscribe(OnMouseDown, b.onMouse)
 	b.Panel.Subscribe(OnCursorEnter, b.onCursor)
 	b.Panel.Subscribe(OnCursorLeave, b.onCursor)
+	b.Panel.Subscribe(OnShortcut, func(name string, ev interface{}) { b.Dispatch(OnClick, nil) })
 	b.Panel.Subscribe(OnEnable, func(name string, ev interface{}) { b.update() })
 	b.Panel.Subscribe(OnResize, func(name string, ev interface{}) { b.recalc() })
 
@@ -163,14 +164,15 @@ func (b *Button) onMouse(evname string, ev interface{}) {
 func (b *Button) onKey(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
-	if evname == OnKeyDown && kev.Keycode == window.KeyEnter {
+	activate := kev.Keycode == window.KeyEnter || kev.Keycode == window.KeySpace
+	if evname == OnKeyDown && activate {
 		b.pressed = true
 		b.update()
 		b.Dispatch(OnClick, nil)
 		b.root.StopPropagation(Stop3D)
 		return
 	}
-	if evname == OnKeyUp && kev.Keycode == window.KeyEnter {
+	if evname == OnKeyUp && activate {
 		b.pressed = false
 		b.update()
 		b.root.StopPropagation(Stop3D)
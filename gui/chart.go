@@ -0,0 +1,909 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+	"image"
+	"math"
+	"strconv"
+)
+
+// Chart is a panel which plots one or more series of points as lines,
+// bars or scatter points over a pair of axes with ticks, an optional
+// grid, a title and a legend. The axes ranges are calculated from the
+// data unless fixed ranges are set. Series may limit their number of
+// points so that appending new points scrolls the chart, which is useful
+// to show telemetry or profiling data in real time.
+// The chart is drawn into a texture which is redrawn when its data,
+// its size or its style change.
+type Chart struct {
+	Panel                       // Embedded panel
+	styles   *ChartStyle        // pointer to current style
+	title    string             // chart title
+	series   []*ChartSeries     // chart series in drawing order
+	xaxis    ChartAxis          // horizontal axis
+	yaxis    ChartAxis          // vertical axis
+	legend   bool               // show legend
+	grid     bool               // show grid lines at the ticks
+	updating int                // BeginUpdate() nesting level
+	tex      *texture.Texture2D // texture with the drawn chart
+}
+
+// ChartType is the type of the plot of a chart series
+type ChartType int
+
+// Types of chart series
+const (
+	ChartLine    ChartType = iota // points connected by lines
+	ChartBar                      // vertical bars from zero to the points
+	ChartScatter                  // unconnected points
+)
+
+// ChartSeries is a named series of points of a chart
+type ChartSeries struct {
+	chart     *Chart           // chart which contains this series
+	name      string           // name shown in the legend
+	kind      ChartType        // plot type
+	color     math32.Color4    // plot color
+	points    []math32.Vector2 // points in data coordinates
+	maxPoints int              // maximum number of points or 0 for no limit
+	visible   bool             // series is drawn
+}
+
+// ChartAxis is a horizontal or vertical axis of a chart
+type ChartAxis struct {
+	chart  *Chart                 // chart which contains this axis
+	title  string                 // axis title
+	auto   bool                   // range is calculated from the data
+	min    float32                // fixed minimum value
+	max    float32                // fixed maximum value
+	ticks  int                    // approximate number of tick intervals
+	format func(v float32) string // tick labels formatter or nil
+	start  float32                // current start of the range
+	end    float32                // current end of the range
+	step   float32                // current interval between ticks
+}
+
+// ChartStyle describes the style of the Chart
+type ChartStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color4   // chart background color
+	PlotColor   math32.Color4   // plot area background color
+	AxisColor   math32.Color4   // axes and ticks color
+	GridColor   math32.Color4   // grid lines color
+	FgColor     math32.Color4   // title, labels and legend text color
+	FontSize    float64         // text font size
+	Spacing     float32         // spacing between the chart elements
+	TickSize    float32         // length of the tick marks
+	LineWidth   float32         // width of the lines of line series
+	PointSize   float32         // diameter of the points of scatter series
+	BarWidth    float32         // fraction of the horizontal space between points filled by bars
+	Colors      []math32.Color4 // colors assigned to the new series in turn
+}
+
+// NewChart creates and returns a pointer to a new chart
+// with the specified width and height in pixels
+func NewChart(width, height float32) *Chart {
+
+	c := new(Chart)
+	c.Panel.Initialize(width, height)
+	c.styles = &StyleDefault.Chart
+	c.xaxis.initialize(c)
+	c.yaxis.initialize(c)
+	c.legend = true
+	c.grid = true
+	c.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { c.changed() })
+	c.update()
+	c.Redraw()
+	return c
+}
+
+// SetStyles sets the style of this chart
+func (c *Chart) SetStyles(s *ChartStyle) {
+
+	c.styles = s
+	c.update()
+	c.Redraw()
+}
+
+// SetTitle sets the title shown above the chart
+func (c *Chart) SetTitle(title string) {
+
+	c.title = title
+	c.changed()
+}
+
+// Title returns the title of the chart
+func (c *Chart) Title() string {
+
+	return c.title
+}
+
+// SetLegend sets if the legend with the names of the series is shown
+func (c *Chart) SetLegend(state bool) {
+
+	c.legend = state
+	c.changed()
+}
+
+// Legend returns if the legend is shown
+func (c *Chart) Legend() bool {
+
+	return c.legend
+}
+
+// SetGrid sets if the grid lines at the axes ticks are shown
+func (c *Chart) SetGrid(state bool) {
+
+	c.grid = state
+	c.changed()
+}
+
+// Grid returns if the grid lines are shown
+func (c *Chart) Grid() bool {
+
+	return c.grid
+}
+
+// XAxis returns a pointer to the horizontal axis of the chart
+func (c *Chart) XAxis() *ChartAxis {
+
+	return &c.xaxis
+}
+
+// YAxis returns a pointer to the vertical axis of the chart
+func (c *Chart) YAxis() *ChartAxis {
+
+	return &c.yaxis
+}
+
+// AddSeries creates a new empty series with the specified name and type,
+// appends it to the chart and returns a pointer to it.
+// The series color is the next color of the style colors.
+func (c *Chart) AddSeries(name string, kind ChartType) *ChartSeries {
+
+	s := &ChartSeries{chart: c, name: name, kind: kind, visible: true}
+	s.color = math32.Color4{0, 0, 0, 1}
+	if len(c.styles.Colors) > 0 {
+		s.color = c.styles.Colors[len(c.series)%len(c.styles.Colors)]
+	}
+	c.series = append(c.series, s)
+	c.changed()
+	return s
+}
+
+// RemoveSeries removes the specified series from the chart
+// and returns true if it was found
+func (c *Chart) RemoveSeries(s *ChartSeries) bool {
+
+	for i, cs := range c.series {
+		if cs == s {
+			copy(c.series[i:], c.series[i+1:])
+			c.series[len(c.series)-1] = nil
+			c.series = c.series[:len(c.series)-1]
+			s.chart = nil
+			c.changed()
+			return true
+		}
+	}
+	return false
+}
+
+// SeriesCount returns the number of series of the chart
+func (c *Chart) SeriesCount() int {
+
+	return len(c.series)
+}
+
+// SeriesAt returns the series at the specified position
+// or nil if the position is invalid
+func (c *Chart) SeriesAt(pos int) *ChartSeries {
+
+	if pos < 0 || pos >= len(c.series) {
+		return nil
+	}
+	return c.series[pos]
+}
+
+// BeginUpdate suspends the redrawing of the chart until EndUpdate
+// is called, so that many changes, as appending points to several
+// series, cause a single redraw. Calls may be nested.
+func (c *Chart) BeginUpdate() {
+
+	c.updating++
+}
+
+// EndUpdate ends an update started by BeginUpdate and redraws the chart
+// if this is the outermost update
+func (c *Chart) EndUpdate() {
+
+	if c.updating == 0 {
+		return
+	}
+	c.updating--
+	if c.updating == 0 {
+		c.Redraw()
+	}
+}
+
+// Redraw draws the chart into its texture
+func (c *Chart) Redraw() {
+
+	width := int(c.ContentWidth())
+	height := int(c.ContentHeight())
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s := c.styles
+	canvas := text.NewCanvas(width, height, &s.BgColor)
+	c.draw(canvas)
+
+	// Creates texture if it doesn't exist
+	if c.tex == nil {
+		c.tex = texture.NewTexture2DFromRGBA(canvas.RGBA)
+		c.tex.SetMagFilter(gls.NEAREST)
+		c.tex.SetMinFilter(gls.NEAREST)
+		c.Panel.Material().AddTexture(c.tex)
+		// Otherwise update texture with new image
+	} else {
+		c.tex.SetFromRGBA(canvas.RGBA)
+	}
+}
+
+// changed is called when the chart data or options change
+// and redraws the chart if it is not being updated
+func (c *Chart) changed() {
+
+	if c.updating == 0 {
+		c.Redraw()
+	}
+}
+
+// update updates the visual state of the chart panel
+func (c *Chart) update() {
+
+	c.SetBordersFrom(&c.styles.Border)
+	c.SetPaddingsFrom(&c.styles.Paddings)
+	c.SetBordersColor4(&c.styles.BorderColor)
+	c.SetColor4(&c.styles.BgColor)
+}
+
+// draw draws the chart elements in the specified canvas
+func (c *Chart) draw(canvas *text.Canvas) {
+
+	s := c.styles
+	width := canvas.RGBA.Bounds().Dx()
+	height := canvas.RGBA.Bounds().Dy()
+	cc := &chartCanvas{img: canvas.RGBA, clip: canvas.RGBA.Bounds()}
+
+	// Sets the font shared with the labels
+	font := StyleDefault.Font
+	font.SetSize(s.FontSize)
+	font.SetDPI(72)
+	font.SetLineSpacing(1.0)
+	font.SetFgColor4(&s.FgColor)
+	font.SetBgColor4(&math32.Color4{0, 0, 0, 0})
+	_, lineHeight := font.MeasureText("0")
+	spacing := int(s.Spacing)
+	tick := int(s.TickSize)
+
+	// Title
+	top := 0
+	if c.title != "" {
+		tw, _ := font.MeasureText(c.title)
+		canvas.DrawText((width-tw)/2, top, c.title, font)
+		top += lineHeight + spacing
+	}
+
+	// Legend with the visible series centered below the title
+	visible := []*ChartSeries{}
+	for _, cs := range c.series {
+		if cs.visible {
+			visible = append(visible, cs)
+		}
+	}
+	if c.legend && len(visible) > 0 {
+		swatch := lineHeight
+		widths := make([]int, len(visible))
+		total := 0
+		for i, cs := range visible {
+			widths[i], _ = font.MeasureText(cs.name)
+			total += swatch + spacing/2 + widths[i]
+		}
+		total += 2 * spacing * (len(visible) - 1)
+		x := (width - total) / 2
+		for i, cs := range visible {
+			cc.swatch(cs.kind, x, top, swatch, &cs.color, s.LineWidth)
+			x += swatch + spacing/2
+			canvas.DrawText(x, top, cs.name, font)
+			x += widths[i] + 2*spacing
+		}
+		top += lineHeight + spacing
+	}
+
+	// The vertical axis title is shown above the tick labels
+	if c.yaxis.title != "" {
+		canvas.DrawText(0, top, c.yaxis.title, font)
+		top += lineHeight + spacing/2
+	}
+	top += lineHeight / 2
+
+	// Calculates the axes ranges and their tick labels
+	c.updateRanges()
+	xticks := c.xaxis.tickValues()
+	yticks := c.yaxis.tickValues()
+	ylabels := make([]string, len(yticks))
+	ylabelWidth := 0
+	for i, v := range yticks {
+		ylabels[i] = c.yaxis.label(v)
+		w, _ := font.MeasureText(ylabels[i])
+		if w > ylabelWidth {
+			ylabelWidth = w
+		}
+	}
+	xlabels := make([]string, len(xticks))
+	lastWidth := 0
+	for i, v := range xticks {
+		xlabels[i] = c.xaxis.label(v)
+		lastWidth, _ = font.MeasureText(xlabels[i])
+	}
+
+	// Plot area
+	left := ylabelWidth + tick + spacing/2
+	right := width - 1 - lastWidth/2
+	bottom := height - 1 - tick - spacing/2 - lineHeight
+	if c.xaxis.title != "" {
+		bottom -= lineHeight + spacing/2
+	}
+	if right-left < 2 || bottom-top < 2 {
+		return
+	}
+	plot := image.Rect(left, top, right+1, bottom+1)
+	px := func(x float32) float32 {
+		return float32(left) + (x-c.xaxis.start)/(c.xaxis.end-c.xaxis.start)*float32(right-left)
+	}
+	py := func(y float32) float32 {
+		return float32(bottom) - (y-c.yaxis.start)/(c.yaxis.end-c.yaxis.start)*float32(bottom-top)
+	}
+	cc.fillRect(left, top, right+1, bottom+1, &s.PlotColor)
+
+	// Grid, ticks and tick labels
+	for i, v := range yticks {
+		y := int(math32.Round(py(v)))
+		if c.grid {
+			cc.fillRect(left+1, y, right+1, y+1, &s.GridColor)
+		}
+		cc.fillRect(left-tick, y, left, y+1, &s.AxisColor)
+		w, _ := font.MeasureText(ylabels[i])
+		canvas.DrawText(left-tick-spacing/2-w, y-lineHeight/2-lineHeight/6, ylabels[i], font)
+	}
+	for i, v := range xticks {
+		x := int(math32.Round(px(v)))
+		if c.grid {
+			cc.fillRect(x, top, x+1, bottom, &s.GridColor)
+		}
+		cc.fillRect(x, bottom+1, x+1, bottom+1+tick, &s.AxisColor)
+		w, _ := font.MeasureText(xlabels[i])
+		canvas.DrawText(x-w/2, bottom+1+tick+spacing/2-lineHeight/6, xlabels[i], font)
+	}
+	if c.xaxis.title != "" {
+		tw, _ := font.MeasureText(c.xaxis.title)
+		canvas.DrawText((left+right-tw)/2, height-lineHeight-lineHeight/6, c.xaxis.title, font)
+	}
+
+	// Series clipped to the plot area
+	cc.clip = plot
+	bars := 0
+	for _, cs := range visible {
+		if cs.kind == ChartBar {
+			bars++
+		}
+	}
+	slot := c.barSlot() * s.BarWidth
+	bar := 0
+	y0 := py(math32.Clamp(0, c.yaxis.start, c.yaxis.end))
+	for _, cs := range visible {
+		switch cs.kind {
+		case ChartLine:
+			for i := 1; i < len(cs.points); i++ {
+				p0 := cs.points[i-1]
+				p1 := cs.points[i]
+				cc.line(px(p0.X), py(p0.Y), px(p1.X), py(p1.Y), s.LineWidth, &cs.color)
+			}
+			if len(cs.points) == 1 {
+				p := cs.points[0]
+				cc.disc(px(p.X), py(p.Y), s.LineWidth, &cs.color)
+			}
+		case ChartScatter:
+			for _, p := range cs.points {
+				cc.disc(px(p.X), py(p.Y), s.PointSize, &cs.color)
+			}
+		case ChartBar:
+			bw := slot / float32(bars)
+			offset := -slot/2 + float32(bar)*bw
+			for _, p := range cs.points {
+				x0 := int(math32.Round(px(p.X + offset)))
+				x1 := int(math32.Round(px(p.X + offset + bw)))
+				if x1 <= x0 {
+					x1 = x0 + 1
+				}
+				y1 := py(p.Y)
+				ya := int(math32.Round(math32.Min(y0, y1)))
+				yb := int(math32.Round(math32.Max(y0, y1)))
+				cc.fillRect(x0, ya, x1, yb+1, &cs.color)
+			}
+			bar++
+		}
+	}
+
+	// Axes lines
+	cc.clip = canvas.RGBA.Bounds()
+	cc.fillRect(left, top, left+1, bottom+1, &s.AxisColor)
+	cc.fillRect(left, bottom, right+1, bottom+1, &s.AxisColor)
+}
+
+// updateRanges updates the current ranges of the axes
+// from the points of the visible series
+func (c *Chart) updateRanges() {
+
+	var xmin, xmax, ymin, ymax float32
+	found := false
+	hasBars := false
+	for _, cs := range c.series {
+		if !cs.visible {
+			continue
+		}
+		if cs.kind == ChartBar {
+			hasBars = true
+		}
+		for _, p := range cs.points {
+			if !found {
+				xmin, xmax, ymin, ymax = p.X, p.X, p.Y, p.Y
+				found = true
+				continue
+			}
+			xmin = math32.Min(xmin, p.X)
+			xmax = math32.Max(xmax, p.X)
+			ymin = math32.Min(ymin, p.Y)
+			ymax = math32.Max(ymax, p.Y)
+		}
+	}
+	// Bars start at zero and must fit horizontally
+	if found && hasBars {
+		half := c.barSlot() / 2
+		xmin -= half
+		xmax += half
+		ymin = math32.Min(ymin, 0)
+		ymax = math32.Max(ymax, 0)
+	}
+	c.xaxis.updateRange(xmin, xmax, found)
+	c.yaxis.updateRange(ymin, ymax, found)
+}
+
+// barSlot returns the smallest horizontal distance between
+// the points of the visible bar series or 1 if there is none
+func (c *Chart) barSlot() float32 {
+
+	slot := float32(0)
+	for _, cs := range c.series {
+		if !cs.visible || cs.kind != ChartBar {
+			continue
+		}
+		for i := 1; i < len(cs.points); i++ {
+			d := math32.Abs(cs.points[i].X - cs.points[i-1].X)
+			if d > 0 && (slot == 0 || d < slot) {
+				slot = d
+			}
+		}
+	}
+	if slot == 0 {
+		slot = 1
+	}
+	return slot
+}
+
+// Name returns the name of this series
+func (s *ChartSeries) Name() string {
+
+	return s.name
+}
+
+// SetName sets the name of this series shown in the legend
+func (s *ChartSeries) SetName(name string) {
+
+	s.name = name
+	s.changed()
+}
+
+// Type returns the plot type of this series
+func (s *ChartSeries) Type() ChartType {
+
+	return s.kind
+}
+
+// SetType sets the plot type of this series
+func (s *ChartSeries) SetType(kind ChartType) {
+
+	s.kind = kind
+	s.changed()
+}
+
+// Color returns the plot color of this series
+func (s *ChartSeries) Color() math32.Color4 {
+
+	return s.color
+}
+
+// SetColor sets the plot color of this series
+func (s *ChartSeries) SetColor(color *math32.Color4) {
+
+	s.color = *color
+	s.changed()
+}
+
+// Visible returns if this series is drawn
+func (s *ChartSeries) Visible() bool {
+
+	return s.visible
+}
+
+// SetVisible sets if this series is drawn and shown in the legend
+func (s *ChartSeries) SetVisible(state bool) {
+
+	s.visible = state
+	s.changed()
+}
+
+// MaxPoints returns the maximum number of points of this series
+func (s *ChartSeries) MaxPoints() int {
+
+	return s.maxPoints
+}
+
+// SetMaxPoints sets the maximum number of points of this series.
+// When points are added beyond this number the oldest ones are removed,
+// so the chart scrolls if its horizontal axis is auto ranged.
+// Zero removes the limit.
+func (s *ChartSeries) SetMaxPoints(max int) {
+
+	s.maxPoints = max
+	s.trim()
+	s.changed()
+}
+
+// Add appends a point with the specified coordinates to this series
+func (s *ChartSeries) Add(x, y float32) {
+
+	s.AddPoints(math32.Vector2{x, y})
+}
+
+// AddY appends a point with the specified vertical coordinate to this series.
+// Its horizontal coordinate is the coordinate of the last point plus one
+// or zero for the first point.
+func (s *ChartSeries) AddY(y float32) {
+
+	x := float32(0)
+	if len(s.points) > 0 {
+		x = s.points[len(s.points)-1].X + 1
+	}
+	s.AddPoints(math32.Vector2{x, y})
+}
+
+// AddPoints appends the specified points to this series
+func (s *ChartSeries) AddPoints(points ...math32.Vector2) {
+
+	s.points = append(s.points, points...)
+	s.trim()
+	s.changed()
+}
+
+// SetPoints replaces the points of this series by a copy of the specified points
+func (s *ChartSeries) SetPoints(points []math32.Vector2) {
+
+	s.points = append([]math32.Vector2(nil), points...)
+	s.trim()
+	s.changed()
+}
+
+// Points returns the points of this series which must not be changed
+func (s *ChartSeries) Points() []math32.Vector2 {
+
+	return s.points
+}
+
+// Len returns the number of points of this series
+func (s *ChartSeries) Len() int {
+
+	return len(s.points)
+}
+
+// Clear removes all the points of this series
+func (s *ChartSeries) Clear() {
+
+	s.points = s.points[:0]
+	s.changed()
+}
+
+// trim removes the oldest points beyond the maximum number of points
+func (s *ChartSeries) trim() {
+
+	if s.maxPoints <= 0 || len(s.points) <= s.maxPoints {
+		return
+	}
+	n := copy(s.points, s.points[len(s.points)-s.maxPoints:])
+	s.points = s.points[:n]
+}
+
+// changed redraws the chart of this series
+func (s *ChartSeries) changed() {
+
+	if s.chart != nil {
+		s.chart.changed()
+	}
+}
+
+// initialize initializes this axis as an auto ranged axis of the specified chart
+func (a *ChartAxis) initialize(c *Chart) {
+
+	a.chart = c
+	a.auto = true
+	a.ticks = 5
+	a.start = 0
+	a.end = 1
+	a.step = 0.2
+}
+
+// Title returns the title of this axis
+func (a *ChartAxis) Title() string {
+
+	return a.title
+}
+
+// SetTitle sets the title of this axis
+func (a *ChartAxis) SetTitle(title string) {
+
+	a.title = title
+	a.chart.changed()
+}
+
+// SetRange sets a fixed range for this axis
+func (a *ChartAxis) SetRange(min, max float32) {
+
+	if max < min {
+		min, max = max, min
+	}
+	a.auto = false
+	a.min = min
+	a.max = max
+	a.chart.changed()
+}
+
+// SetAutoRange sets the range of this axis to be calculated from the
+// points of the visible series and rounded to its ticks. This is the default.
+func (a *ChartAxis) SetAutoRange() {
+
+	a.auto = true
+	a.chart.changed()
+}
+
+// AutoRange returns if the range of this axis is calculated from the data
+func (a *ChartAxis) AutoRange() bool {
+
+	return a.auto
+}
+
+// Range returns the current start and end of the range of this axis
+func (a *ChartAxis) Range() (float32, float32) {
+
+	a.chart.updateRanges()
+	return a.start, a.end
+}
+
+// SetTicks sets the approximate number of intervals between the ticks
+// of this axis. The tick values are rounded to multiples of 1, 2 or 5
+// times a power of ten.
+func (a *ChartAxis) SetTicks(count int) {
+
+	if count < 1 {
+		count = 1
+	}
+	a.ticks = count
+	a.chart.changed()
+}
+
+// Ticks returns the approximate number of intervals between the ticks of this axis
+func (a *ChartAxis) Ticks() int {
+
+	return a.ticks
+}
+
+// SetFormatter sets the function which returns the label of the ticks of
+// this axis. If nil, the values are shown with the decimals of the tick step.
+func (a *ChartAxis) SetFormatter(format func(v float32) string) {
+
+	a.format = format
+	a.chart.changed()
+}
+
+// updateRange updates the current range and ticks step of this axis from
+// the specified minimum and maximum data values if they were found
+func (a *ChartAxis) updateRange(min, max float32, found bool) {
+
+	if !a.auto {
+		a.start = a.min
+		a.end = a.max
+		if a.end == a.start {
+			a.end = a.start + 1
+		}
+		a.step = chartNiceStep(a.end-a.start, a.ticks)
+		return
+	}
+	if !found {
+		min, max = 0, 1
+	}
+	if min == max {
+		d := math32.Abs(min) / 10
+		if d == 0 {
+			d = 1
+		}
+		min -= d
+		max += d
+	}
+	a.step = chartNiceStep(max-min, a.ticks)
+	a.start = math32.Floor(min/a.step) * a.step
+	a.end = math32.Ceil(max/a.step) * a.step
+}
+
+// tickValues returns the values of the ticks inside the current range
+func (a *ChartAxis) tickValues() []float32 {
+
+	values := []float32{}
+	eps := a.step / 1000
+	first := math.Ceil(float64((a.start - eps) / a.step))
+	for i := first; ; i++ {
+		v := float32(i) * a.step
+		if v > a.end+eps || len(values) > 1000 {
+			break
+		}
+		// Avoids showing "-0"
+		if math32.Abs(v) < eps {
+			v = 0
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// label returns the label of the tick with the specified value
+func (a *ChartAxis) label(v float32) string {
+
+	if a.format != nil {
+		return a.format(v)
+	}
+	decimals := int(-math.Floor(math.Log10(float64(a.step))))
+	if decimals < 0 {
+		decimals = 0
+	}
+	return strconv.FormatFloat(float64(v), 'f', decimals, 32)
+}
+
+// chartNiceStep returns the interval between ticks which divides the
+// specified span in about the specified number of intervals and which
+// is 1, 2 or 5 times a power of ten
+func chartNiceStep(span float32, count int) float32 {
+
+	raw := float64(span) / float64(count)
+	if raw <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	f := raw / exp
+	var nice float64
+	switch {
+	case f < 1.5:
+		nice = 1
+	case f < 3:
+		nice = 2
+	case f < 7:
+		nice = 5
+	default:
+		nice = 10
+	}
+	return float32(nice * exp)
+}
+
+// chartCanvas draws the primitives of a chart blending them over an image
+type chartCanvas struct {
+	img  *image.RGBA     // destination image
+	clip image.Rectangle // pixels outside this rectangle are not changed
+}
+
+// blend blends the specified color with the specified coverage
+// over the pixel at the specified position
+func (cc *chartCanvas) blend(x, y int, c *math32.Color4, coverage float32) {
+
+	if !image.Pt(x, y).In(cc.clip) {
+		return
+	}
+	a := c.A * coverage
+	if a <= 0 {
+		return
+	}
+	// The image has premultiplied alpha
+	p := cc.img.Pix[cc.img.PixOffset(x, y):]
+	p[0] = uint8(c.R*a*255 + float32(p[0])*(1-a))
+	p[1] = uint8(c.G*a*255 + float32(p[1])*(1-a))
+	p[2] = uint8(c.B*a*255 + float32(p[2])*(1-a))
+	p[3] = uint8(a*255 + float32(p[3])*(1-a))
+}
+
+// fillRect fills the rectangle from (x0,y0) to (x1,y1) exclusive
+func (cc *chartCanvas) fillRect(x0, y0, x1, y1 int, c *math32.Color4) {
+
+	r := image.Rect(x0, y0, x1, y1).Intersect(cc.clip)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cc.blend(x, y, c, 1)
+		}
+	}
+}
+
+// line draws an antialiased line with the specified width
+// between the centers of the specified pixels
+func (cc *chartCanvas) line(x0, y0, x1, y1, width float32, c *math32.Color4) {
+
+	half := math32.Max(width, 1) / 2
+	r := image.Rect(
+		int(math32.Floor(math32.Min(x0, x1)-half-1)),
+		int(math32.Floor(math32.Min(y0, y1)-half-1)),
+		int(math32.Ceil(math32.Max(x0, x1)+half+1)),
+		int(math32.Ceil(math32.Max(y0, y1)+half+1)),
+	).Intersect(cc.clip)
+	dx := x1 - x0
+	dy := y1 - y0
+	len2 := dx*dx + dy*dy
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			// Distance from the pixel to the segment
+			t := float32(0)
+			if len2 > 0 {
+				t = math32.Clamp(((float32(x)-x0)*dx+(float32(y)-y0)*dy)/len2, 0, 1)
+			}
+			ex := float32(x) - (x0 + t*dx)
+			ey := float32(y) - (y0 + t*dy)
+			d := math32.Sqrt(ex*ex + ey*ey)
+			cc.blend(x, y, c, math32.Clamp(half+0.5-d, 0, 1))
+		}
+	}
+}
+
+// disc draws an antialiased disc with the specified diameter
+// centered at the specified pixel
+func (cc *chartCanvas) disc(cx, cy, size float32, c *math32.Color4) {
+
+	cc.line(cx, cy, cx, cy, size, c)
+}
+
+// swatch draws the legend symbol of a series of the specified type
+// in the square with the specified top left corner and size
+func (cc *chartCanvas) swatch(kind ChartType, x, y, size int, c *math32.Color4, lineWidth float32) {
+
+	inset := size / 4
+	switch kind {
+	case ChartLine:
+		cy := float32(y + size/2)
+		cc.line(float32(x), cy, float32(x+size-1), cy, lineWidth, c)
+	case ChartBar:
+		cc.fillRect(x+inset, y+inset, x+size-inset, y+size-inset, c)
+	case ChartScatter:
+		cc.disc(float32(x+size/2), float32(y+size/2), float32(size-2*inset), c)
+	}
+}
@@ -85,6 +85,11 @@ func newCheckRadio(check bool, text string) *CheckRadio {
 	cb.Panel.Subscribe(OnCursorEnter, cb.onCursor)
 	cb.Panel.Subscribe(OnCursorLeave, cb.onCursor)
 	cb.Panel.Subscribe(OnMouseDown, cb.onMouse)
+	cb.Panel.Subscribe(OnShortcut, func(evname string, ev interface{}) {
+		cb.toggleState()
+		cb.update()
+		cb.Dispatch(OnClick, nil)
+	})
 	cb.Panel.Subscribe(OnEnable, func(evname string, ev interface{}) { cb.update() })
 
 	// Creates label
@@ -202,7 +207,7 @@ func (cb *CheckRadio) onCursor(evname string, ev interface{}) {
 func (cb *CheckRadio) onKey(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
-	if evname == OnKeyDown && kev.Keycode == window.KeyEnter {
+	if evname == OnKeyDown && (kev.Keycode == window.KeyEnter || kev.Keycode == window.KeySpace) {
 		cb.toggleState()
 		cb.update()
 		cb.Dispatch(OnClick, nil)
@@ -0,0 +1,189 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"errors"
+	"github.com/g3n/engine/window"
+	"image"
+)
+
+// Clipboard is the interface of the clipboards used by the widgets to
+// cut, copy and paste data. The data is identified by its format, which is
+// a MIME type as ClipboardFormatText. All clipboards support text, while
+// other formats as images are only supported by some clipboards, which
+// return ErrClipboardFormat for the formats they can't keep.
+type Clipboard interface {
+	SetData(format string, data interface{}) error
+	Data(format string) (interface{}, error)
+}
+
+// Clipboard data formats
+const (
+	ClipboardFormatText  = "text/plain" // data is a string
+	ClipboardFormatImage = "image/png"  // data is an image.Image
+)
+
+// Clipboard errors
+var (
+	ErrClipboardFormat = errors.New("clipboard format not supported")
+	ErrClipboardEmpty  = errors.New("clipboard has no data of the requested format")
+)
+
+// MemoryClipboard is a clipboard which keeps data of any format in memory,
+// so it is only shared by the widgets of the application.
+type MemoryClipboard struct {
+	data map[string]interface{} // data by format
+}
+
+// WindowClipboard is the clipboard of the window system, which is shared
+// with other applications and supports text and, if the window implements
+// window.IClipboardImage, images.
+type WindowClipboard struct {
+	win window.IWindow
+}
+
+var clipboard Clipboard = NewMemoryClipboard() // current clipboard
+var clipboardCustom = false                    // clipboard set by the application
+
+// NewMemoryClipboard creates and returns a pointer to a new empty memory clipboard
+func NewMemoryClipboard() *MemoryClipboard {
+
+	c := new(MemoryClipboard)
+	c.data = make(map[string]interface{})
+	return c
+}
+
+// SetData replaces the data of the clipboard by the specified data of the specified format
+func (c *MemoryClipboard) SetData(format string, data interface{}) error {
+
+	c.data = map[string]interface{}{format: data}
+	return nil
+}
+
+// Data returns the data of the clipboard with the specified format
+func (c *MemoryClipboard) Data(format string) (interface{}, error) {
+
+	data, ok := c.data[format]
+	if !ok {
+		return nil, ErrClipboardEmpty
+	}
+	return data, nil
+}
+
+// NewWindowClipboard creates and returns a pointer to the clipboard of the specified window
+func NewWindowClipboard(win window.IWindow) *WindowClipboard {
+
+	return &WindowClipboard{win}
+}
+
+// SetData replaces the data of the clipboard by the specified data of the
+// specified format, which must be text or, if supported by the window, an image
+func (c *WindowClipboard) SetData(format string, data interface{}) error {
+
+	switch format {
+	case ClipboardFormatText:
+		text, ok := data.(string)
+		if !ok {
+			return ErrClipboardFormat
+		}
+		c.win.SetClipboardString(text)
+		return nil
+	case ClipboardFormatImage:
+		img, ok := data.(image.Image)
+		wci, supported := c.win.(window.IClipboardImage)
+		if !ok || !supported {
+			return ErrClipboardFormat
+		}
+		return wci.SetClipboardImage(img)
+	}
+	return ErrClipboardFormat
+}
+
+// Data returns the data of the clipboard with the specified format, which
+// must be text or, if supported by the window, an image
+func (c *WindowClipboard) Data(format string) (interface{}, error) {
+
+	switch format {
+	case ClipboardFormatText:
+		text, err := c.win.GetClipboardString()
+		if err != nil {
+			return nil, ErrClipboardEmpty
+		}
+		return text, nil
+	case ClipboardFormatImage:
+		wci, ok := c.win.(window.IClipboardImage)
+		if !ok {
+			return nil, ErrClipboardFormat
+		}
+		img, err := wci.GetClipboardImage()
+		if err != nil {
+			return nil, ErrClipboardEmpty
+		}
+		return img, nil
+	}
+	return nil, ErrClipboardFormat
+}
+
+// SetClipboard sets the clipboard used by the widgets.
+// By default it is a memory clipboard until a gui root panel is created,
+// which sets the clipboard of its window.
+func SetClipboard(c Clipboard) {
+
+	clipboard = c
+	clipboardCustom = true
+}
+
+// CurrentClipboard returns the clipboard used by the widgets
+func CurrentClipboard() Clipboard {
+
+	return clipboard
+}
+
+// SetClipboardText sets the text of the current clipboard
+func SetClipboardText(text string) error {
+
+	return clipboard.SetData(ClipboardFormatText, text)
+}
+
+// GetClipboardText returns the text of the current clipboard
+// or an empty string if it has no text
+func GetClipboardText() string {
+
+	data, err := clipboard.Data(ClipboardFormatText)
+	if err != nil {
+		return ""
+	}
+	text, _ := data.(string)
+	return text
+}
+
+// SetClipboardImage sets the image of the current clipboard, which
+// returns ErrClipboardFormat if it doesn't support images
+func SetClipboardImage(img image.Image) error {
+
+	return clipboard.SetData(ClipboardFormatImage, img)
+}
+
+// GetClipboardImage returns the image of the current clipboard
+// or nil if it has no image
+func GetClipboardImage() image.Image {
+
+	data, err := clipboard.Data(ClipboardFormatImage)
+	if err != nil {
+		return nil
+	}
+	img, _ := data.(image.Image)
+	return img
+}
+
+// setWindowClipboard sets the clipboard of the specified window as the
+// current clipboard unless the application has set its own clipboard
+func setWindowClipboard(win window.IWindow) {
+
+	if !clipboardCustom {
+		clipboard = NewWindowClipboard(win)
+	}
+}
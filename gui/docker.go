@@ -0,0 +1,831 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"encoding/json"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+/*********************************************
+
+ Docker panel
+ +-----------------------------------------+
+ | Window A      |s| Tab B | Tab C |       |
+ |               |p|-----------------------|
+ |               |a| Window B              |
+ |               |c|                       |
+ |               |e|=======spacer==========|
+ |               |r| Window D              |
+ |               | |                       |
+ +-----------------------------------------+
+
+ The docked windows fill the docker panel and are organized
+ in a tree of horizontal and vertical splits whose leaves
+ contain one window or several windows shown as tabs.
+ Floating windows are shown over the docked windows.
+
+*********************************************/
+
+// Docker is a panel which manages a set of named windows which may float
+// over its area or be docked to its edges, to the edges of other docked
+// windows or tabbed together with other docked windows. Windows are docked
+// by dragging them by their title bars or their tabs over the drop areas
+// or programmatically. The sizes of the docked windows are changed by
+// dragging the spacers between them. The layout of the windows may be saved
+// and restored by name. OnDockChange is dispatched when the layout changes.
+type Docker struct {
+	Panel                            // Embedded panel
+	styles     *DockerStyle          // pointer to current style
+	tree       *dockNode             // root node of the docked windows or nil
+	windows    map[string]*Window    // windows by name
+	names      map[*Window]string    // names of windows
+	nodes      map[*Window]*dockNode // leaf nodes of docked windows
+	resizable  map[*Window]Resizable // resizable borders of docked windows when floating
+	preview    Panel                 // drop area preview panel
+	dragWin    *Window               // window being dragged
+	dragX      float32               // initial cursor x of the window drag
+	dragY      float32               // initial cursor y of the window drag
+	dragging   bool                  // window drag passed the start threshold
+	spacerNode *dockNode             // split node whose spacer is being dragged
+	spacerLast float32               // last cursor position of the spacer drag
+}
+
+// DockerStyle describes the style of a Docker
+type DockerStyle struct {
+	SpacerSize        float32
+	SpacerColor       math32.Color4
+	SpacerBorderColor math32.Color4
+	TabBorder         BorderSizes
+	TabPaddings       BorderSizes
+	TabBorderColor    math32.Color4
+	TabBgColor        math32.Color4
+	TabActiveColor    math32.Color4
+	TabFgColor        math32.Color4
+	PreviewColor      math32.Color4
+}
+
+// DockerLayout is the serializable layout of the windows of a Docker
+type DockerLayout struct {
+	Root     *DockerNode      `json:"root,omitempty"`     // Tree of docked windows
+	Floating []DockerFloating `json:"floating,omitempty"` // Floating windows
+}
+
+// DockerNode is a node of the tree of docked windows of a DockerLayout,
+// which is a split with two children or a leaf with tabbed windows
+type DockerNode struct {
+	Split    string        `json:"split,omitempty"`    // "h" for side by side children, "v" for stacked children
+	Ratio    float32       `json:"ratio,omitempty"`    // Fraction of the split size used by the first child
+	Children []*DockerNode `json:"children,omitempty"` // Children of a split
+	Windows  []string      `json:"windows,omitempty"`  // Names of the windows of a leaf
+	Active   int           `json:"active,omitempty"`   // Index of the visible window of a leaf
+}
+
+// DockerFloating is the position and size of a floating window of a DockerLayout
+type DockerFloating struct {
+	Name   string  `json:"name"`
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+// dockNode is a node of the tree of docked windows
+type dockNode struct {
+	parent   *dockNode    // parent split or nil for the root node
+	split    int          // split direction or dockSplitNone for leaves
+	children [2]*dockNode // children of splits
+	ratio    float32      // fraction of the split size used by the first child
+	spacer   *Panel       // spacer panel of splits
+	windows  []*Window    // windows of leaves
+	active   int          // visible window of leaves
+	tabs     *Panel       // tab bar of leaves with several windows
+	x        float32      // last position and size of the node in the docker content area
+	y        float32
+	width    float32
+	height   float32
+}
+
+// Split directions of dock nodes
+const (
+	dockSplitNone = iota
+	dockSplitH
+	dockSplitV
+)
+
+const (
+	dockEdgeZone      = 24  // size in pixels of the areas at the docker edges which dock windows to its edges
+	dockEdgeFraction  = 0.3 // fraction of the size of docked windows which dock windows to their edges
+	dockDragThreshold = 5   // cursor displacement in pixels which starts a window drag
+)
+
+// NewDocker creates and returns a pointer to a new docker panel
+// with the specified dimensions and without windows
+func NewDocker(width, height float32) *Docker {
+
+	d := new(Docker)
+	d.Panel.Initialize(width, height)
+	d.styles = &StyleDefault.Docker
+	d.windows = make(map[string]*Window)
+	d.names = make(map[*Window]string)
+	d.nodes = make(map[*Window]*dockNode)
+	d.resizable = make(map[*Window]Resizable)
+
+	d.preview.Initialize(0, 0)
+	d.preview.SetVisible(false)
+	d.Panel.Add(&d.preview)
+
+	d.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { d.recalc() })
+	d.update()
+	return d
+}
+
+// SetStyles sets the docker style overriding the default style
+func (d *Docker) SetStyles(ds *DockerStyle) {
+
+	d.styles = ds
+	d.update()
+	d.recalc()
+}
+
+// AddWindow adds the specified window to this docker as a floating window
+// with the specified name, which identifies the window in the other methods
+// and in saved layouts. The window must have a title to be dragged.
+func (d *Docker) AddWindow(name string, w *Window) {
+
+	if old := d.windows[name]; old != nil {
+		d.RemoveWindow(name)
+	}
+	d.windows[name] = w
+	d.names[w] = name
+	w.docker = d
+	d.Panel.Add(w)
+	d.recalc()
+}
+
+// RemoveWindow undocks and removes the window with the specified name
+func (d *Docker) RemoveWindow(name string) {
+
+	w := d.windows[name]
+	if w == nil {
+		return
+	}
+	d.undock(w)
+	d.Panel.Remove(w)
+	delete(d.windows, name)
+	delete(d.names, w)
+	w.docker = nil
+	d.recalc()
+	d.Dispatch(OnDockChange, nil)
+}
+
+// Window returns the window with the specified name or nil
+func (d *Docker) Window(name string) *Window {
+
+	return d.windows[name]
+}
+
+// Dock docks the window with the specified name to the specified edge
+// (DockTop, DockRight, DockBottom or DockLeft) of the docked window with the
+// target name or, with DockCenter, as a tab together with it. An empty target
+// docks the window to the edges of the docker. The window is undocked first
+// if necessary.
+func (d *Docker) Dock(name, target string, edge int) {
+
+	w := d.windows[name]
+	if w == nil || edge < DockTop || edge > DockCenter {
+		return
+	}
+	var node *dockNode
+	if tw := d.windows[target]; tw != nil && tw != w {
+		node = d.nodes[tw]
+	}
+	d.dockAt(w, node, edge)
+}
+
+// Float undocks the window with the specified name keeping its current
+// position and size
+func (d *Docker) Float(name string) {
+
+	w := d.windows[name]
+	if w == nil || d.nodes[w] == nil {
+		return
+	}
+	d.undock(w)
+	d.recalc()
+	d.Dispatch(OnDockChange, nil)
+}
+
+// Docked returns if the window with the specified name is docked
+func (d *Docker) Docked(name string) bool {
+
+	w := d.windows[name]
+	return w != nil && d.nodes[w] != nil
+}
+
+// SetActive shows the docked window with the specified name
+// if it is tabbed together with other windows
+func (d *Docker) SetActive(name string) {
+
+	w := d.windows[name]
+	if w == nil {
+		return
+	}
+	n := d.nodes[w]
+	if n == nil {
+		w.SetForeground()
+		return
+	}
+	for i, nw := range n.windows {
+		if nw == w && n.active != i {
+			n.active = i
+			d.updateTabs(n)
+			d.recalc()
+			d.Dispatch(OnDockChange, nil)
+		}
+	}
+}
+
+// Layout returns the current layout of the windows of this docker
+func (d *Docker) Layout() *DockerLayout {
+
+	l := new(DockerLayout)
+	l.Root = d.saveNode(d.tree)
+	for _, iobj := range d.Children() {
+		w, ok := iobj.(*Window)
+		if !ok || d.nodes[w] != nil || d.names[w] == "" {
+			continue
+		}
+		pos := w.Position()
+		l.Floating = append(l.Floating, DockerFloating{d.names[w], pos.X, pos.Y, w.Width(), w.Height()})
+	}
+	return l
+}
+
+// SetLayout docks and positions the windows of this docker as specified
+// by the layout. Windows which are not in the layout are kept floating and
+// windows of the layout which were not added to the docker are ignored.
+func (d *Docker) SetLayout(l *DockerLayout) {
+
+	for w := range d.nodes {
+		d.undock(w)
+	}
+	d.tree = d.loadNode(l.Root, nil)
+	for _, f := range l.Floating {
+		w := d.windows[f.Name]
+		if w == nil || d.nodes[w] != nil {
+			continue
+		}
+		w.SetPosition(f.X, f.Y)
+		w.SetSize(f.Width, f.Height)
+	}
+	d.recalc()
+	d.Dispatch(OnDockChange, nil)
+}
+
+// SaveLayout returns the current layout of the windows encoded in JSON
+func (d *Docker) SaveLayout() ([]byte, error) {
+
+	return json.MarshalIndent(d.Layout(), "", "  ")
+}
+
+// LoadLayout sets the layout of the windows from the specified JSON
+// encoded layout previously returned by SaveLayout
+func (d *Docker) LoadLayout(data []byte) error {
+
+	var l DockerLayout
+	err := json.Unmarshal(data, &l)
+	if err != nil {
+		return err
+	}
+	d.SetLayout(&l)
+	return nil
+}
+
+// saveNode returns the serializable layout of the specified node
+func (d *Docker) saveNode(n *dockNode) *DockerNode {
+
+	if n == nil {
+		return nil
+	}
+	dn := new(DockerNode)
+	if n.split == dockSplitNone {
+		for _, w := range n.windows {
+			dn.Windows = append(dn.Windows, d.names[w])
+		}
+		dn.Active = n.active
+		return dn
+	}
+	dn.Split = "h"
+	if n.split == dockSplitV {
+		dn.Split = "v"
+	}
+	dn.Ratio = n.ratio
+	dn.Children = []*DockerNode{d.saveNode(n.children[0]), d.saveNode(n.children[1])}
+	return dn
+}
+
+// loadNode creates and returns the dock node of the specified serialized
+// layout node with the specified parent. Nodes without existing windows
+// are removed from the tree.
+func (d *Docker) loadNode(dn *DockerNode, parent *dockNode) *dockNode {
+
+	if dn == nil {
+		return nil
+	}
+	if len(dn.Children) == 2 {
+		n := &dockNode{parent: parent, split: dockSplitH, ratio: dn.Ratio}
+		if dn.Split == "v" {
+			n.split = dockSplitV
+		}
+		c0 := d.loadNode(dn.Children[0], n)
+		c1 := d.loadNode(dn.Children[1], n)
+		if c0 == nil || c1 == nil {
+			if c0 == nil {
+				c0 = c1
+			}
+			if c0 != nil {
+				c0.parent = parent
+			}
+			return c0
+		}
+		n.children = [2]*dockNode{c0, c1}
+		n.ratio = math32.Clamp(n.ratio, 0.05, 0.95)
+		n.spacer = d.newSpacer(n)
+		return n
+	}
+	n := &dockNode{parent: parent}
+	for _, name := range dn.Windows {
+		w := d.windows[name]
+		if w != nil && d.nodes[w] == nil {
+			n.windows = append(n.windows, w)
+			d.setDocked(w, n)
+		}
+	}
+	if len(n.windows) == 0 {
+		return nil
+	}
+	n.active = dn.Active
+	if n.active < 0 || n.active >= len(n.windows) {
+		n.active = 0
+	}
+	d.updateTabs(n)
+	return n
+}
+
+// dockAt docks the specified window to the specified edge of the specified
+// leaf node, or of the root node if nil, or as a tab in the leaf
+func (d *Docker) dockAt(w *Window, node *dockNode, edge int) {
+
+	d.undock(w)
+	leaf := &dockNode{windows: []*Window{w}}
+	d.setDocked(w, leaf)
+	if d.tree == nil {
+		d.tree = leaf
+	} else {
+		if node == nil {
+			node = d.tree
+		}
+		if edge == DockCenter {
+			for node.split != dockSplitNone {
+				node = node.children[0]
+			}
+			d.nodes[w] = node
+			node.windows = append(node.windows, w)
+			node.active = len(node.windows) - 1
+		} else {
+			d.split(node, leaf, edge, w)
+		}
+	}
+	d.updateTabs(d.nodes[w])
+	d.recalc()
+	d.Dispatch(OnDockChange, nil)
+}
+
+// split replaces the specified node by a split with the node and the
+// specified new leaf at the specified edge, which initially uses the
+// size of the specified window if possible
+func (d *Docker) split(node, leaf *dockNode, edge int, w *Window) {
+
+	s := &dockNode{parent: node.parent}
+	size := node.width
+	wsize := w.Width()
+	s.split = dockSplitH
+	if edge == DockTop || edge == DockBottom {
+		size = node.height
+		wsize = w.Height()
+		s.split = dockSplitV
+	}
+	ratio := float32(0.5)
+	if size > 0 {
+		ratio = math32.Clamp(wsize/size, 0.2, 0.5)
+	}
+	if edge == DockLeft || edge == DockTop {
+		s.children = [2]*dockNode{leaf, node}
+		s.ratio = ratio
+	} else {
+		s.children = [2]*dockNode{node, leaf}
+		s.ratio = 1 - ratio
+	}
+	d.replace(node, s)
+	node.parent = s
+	leaf.parent = s
+	s.spacer = d.newSpacer(s)
+}
+
+// replace replaces the specified node by another in its parent or as the root node
+func (d *Docker) replace(old, n *dockNode) {
+
+	n.parent = old.parent
+	if old.parent == nil {
+		d.tree = n
+		return
+	}
+	if old.parent.children[0] == old {
+		old.parent.children[0] = n
+	} else {
+		old.parent.children[1] = n
+	}
+}
+
+// setDocked sets the specified window as docked in the specified leaf
+func (d *Docker) setDocked(w *Window, n *dockNode) {
+
+	d.nodes[w] = n
+	d.resizable[w] = w.resizable
+	w.resizable = 0
+}
+
+// undock removes the specified window from its leaf node, removing the
+// node from the tree if it becomes empty
+func (d *Docker) undock(w *Window) {
+
+	n := d.nodes[w]
+	if n == nil {
+		return
+	}
+	delete(d.nodes, w)
+	w.resizable = d.resizable[w]
+	delete(d.resizable, w)
+	w.SetVisible(true)
+	for i, nw := range n.windows {
+		if nw == w {
+			n.windows = append(n.windows[:i], n.windows[i+1:]...)
+			break
+		}
+	}
+	if n.active >= len(n.windows) {
+		n.active = len(n.windows) - 1
+	}
+	if len(n.windows) > 0 {
+		d.updateTabs(n)
+		return
+	}
+
+	// Removes the empty leaf replacing its parent split by its sibling
+	if n.tabs != nil {
+		d.Panel.Remove(n.tabs)
+		n.tabs = nil
+	}
+	p := n.parent
+	if p == nil {
+		d.tree = nil
+		return
+	}
+	d.Panel.Remove(p.spacer)
+	sibling := p.children[0]
+	if sibling == n {
+		sibling = p.children[1]
+	}
+	d.replace(p, sibling)
+}
+
+// newSpacer creates and returns the spacer panel of the specified split node
+func (d *Docker) newSpacer(n *dockNode) *Panel {
+
+	sp := NewPanel(0, 0)
+	if n.split == dockSplitH {
+		sp.SetBorders(0, 1, 0, 1)
+	} else {
+		sp.SetBorders(1, 0, 1, 0)
+	}
+	sp.SetBordersColor4(&d.styles.SpacerBorderColor)
+	sp.SetColor4(&d.styles.SpacerColor)
+	sp.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		d.spacerNode = n
+		d.spacerLast = mev.Xpos
+		if n.split == dockSplitV {
+			d.spacerLast = mev.Ypos
+		}
+		d.root.SetMouseFocus(sp)
+		d.root.StopPropagation(Stop3D)
+	})
+	sp.Subscribe(OnMouseUp, func(evname string, ev interface{}) {
+		d.spacerNode = nil
+		d.root.SetMouseFocus(nil)
+		d.root.SetCursorNormal()
+		d.root.StopPropagation(Stop3D)
+	})
+	sp.Subscribe(OnCursorEnter, func(evname string, ev interface{}) {
+		if n.split == dockSplitH {
+			d.root.SetCursorHResize()
+		} else {
+			d.root.SetCursorVResize()
+		}
+	})
+	sp.Subscribe(OnCursorLeave, func(evname string, ev interface{}) {
+		if d.spacerNode == nil {
+			d.root.SetCursorNormal()
+		}
+	})
+	sp.Subscribe(OnCursor, func(evname string, ev interface{}) {
+		if d.spacerNode != n {
+			return
+		}
+		cev := ev.(*window.CursorEvent)
+		pos, size := cev.Xpos, n.width
+		if n.split == dockSplitV {
+			pos, size = cev.Ypos, n.height
+		}
+		size -= d.styles.SpacerSize
+		if size > 0 {
+			n.ratio = math32.Clamp(n.ratio+(pos-d.spacerLast)/size, 0.05, 0.95)
+			d.spacerLast = pos
+			d.recalc()
+		}
+		d.root.StopPropagation(Stop3D)
+	})
+	d.Panel.Add(sp)
+	return sp
+}
+
+// updateTabs rebuilds the tab bar of the specified leaf node, which
+// is only shown if the leaf has several windows
+func (d *Docker) updateTabs(n *dockNode) {
+
+	if n.tabs != nil {
+		d.Panel.Remove(n.tabs)
+		n.tabs = nil
+	}
+	for i, w := range n.windows {
+		w.SetVisible(i == n.active)
+	}
+	if len(n.windows) < 2 {
+		return
+	}
+	s := d.styles
+	n.tabs = NewPanel(0, 0)
+	var x, height float32
+	for i, w := range n.windows {
+		tab := NewLabel(d.title(w))
+		tab.SetBordersFrom(&s.TabBorder)
+		tab.SetPaddingsFrom(&s.TabPaddings)
+		tab.SetBordersColor4(&s.TabBorderColor)
+		tab.SetColor4(&s.TabFgColor)
+		tab.Panel.SetColor4(&s.TabBgColor)
+		if i == n.active {
+			tab.Panel.SetColor4(&s.TabActiveColor)
+		}
+		tab.SetPosition(x, 0)
+		x += tab.Width()
+		height = math32.Max(height, tab.Height())
+		tw := w
+		tab.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+			mev := ev.(*window.MouseEvent)
+			if mev.Button != window.MouseButtonLeft {
+				return
+			}
+			d.SetActive(d.names[tw])
+			d.startDrag(tw, mev.Xpos, mev.Ypos)
+			d.root.SetMouseFocus(n.tabs)
+			d.root.StopPropagation(Stop3D)
+		})
+		n.tabs.Add(tab)
+	}
+	n.tabs.SetHeight(height)
+	n.tabs.Subscribe(OnMouseUp, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		d.root.SetMouseFocus(nil)
+		d.endDrag(mev.Xpos, mev.Ypos)
+	})
+	n.tabs.Subscribe(OnCursor, func(evname string, ev interface{}) {
+		cev := ev.(*window.CursorEvent)
+		d.moveDrag(cev.Xpos, cev.Ypos)
+	})
+	d.Panel.Add(n.tabs)
+}
+
+// title returns the title of the specified window or its name
+func (d *Docker) title(w *Window) string {
+
+	if w.title != nil {
+		return w.title.label.Text()
+	}
+	return d.names[w]
+}
+
+// startDrag starts dragging the specified window from the specified cursor position
+func (d *Docker) startDrag(w *Window, x, y float32) {
+
+	d.dragWin = w
+	d.dragX = x
+	d.dragY = y
+	d.dragging = false
+}
+
+// moveDrag shows the drop area of the dragged window at the specified cursor position
+func (d *Docker) moveDrag(x, y float32) {
+
+	if d.dragWin == nil {
+		return
+	}
+	if !d.dragging {
+		if math32.Abs(x-d.dragX)+math32.Abs(y-d.dragY) < dockDragThreshold {
+			return
+		}
+		d.dragging = true
+	}
+	node, edge, ok := d.dropTarget(x, y, d.dragWin)
+	if !ok {
+		d.preview.SetVisible(false)
+		return
+	}
+	px, py, pw, ph := float32(0), float32(0), d.ContentWidth(), d.ContentHeight()
+	if node != nil {
+		px, py, pw, ph = node.x, node.y, node.width, node.height
+	}
+	switch edge {
+	case DockTop:
+		ph *= dockEdgeFraction
+	case DockBottom:
+		py += ph * (1 - dockEdgeFraction)
+		ph *= dockEdgeFraction
+	case DockLeft:
+		pw *= dockEdgeFraction
+	case DockRight:
+		px += pw * (1 - dockEdgeFraction)
+		pw *= dockEdgeFraction
+	}
+	d.preview.SetPosition(px, py)
+	d.preview.SetSize(pw, ph)
+	d.preview.SetVisible(true)
+	d.preview.SetForeground()
+}
+
+// endDrag finishes dragging the window at the specified cursor position,
+// docking it at the drop area under the cursor if any. Docked windows
+// dropped outside drop areas are undocked.
+func (d *Docker) endDrag(x, y float32) {
+
+	w := d.dragWin
+	d.dragWin = nil
+	d.preview.SetVisible(false)
+	if w == nil || !d.dragging {
+		return
+	}
+	d.dragging = false
+	node, edge, ok := d.dropTarget(x, y, w)
+	if ok {
+		d.dockAt(w, node, edge)
+		return
+	}
+	if d.nodes[w] == nil {
+		return
+	}
+	d.undock(w)
+	cx := x - d.pospix.X - d.content.X
+	cy := y - d.pospix.Y - d.content.Y
+	w.SetPosition(cx-w.Width()/2, cy-dockDragThreshold)
+	d.recalc()
+	d.Dispatch(OnDockChange, nil)
+}
+
+// dropTarget returns the leaf node, or nil for the docker edges, and the
+// edge where the specified window would be docked if dropped at the
+// specified cursor position and if there is a drop area there.
+func (d *Docker) dropTarget(x, y float32, w *Window) (*dockNode, int, bool) {
+
+	cx := x - d.pospix.X - d.content.X
+	cy := y - d.pospix.Y - d.content.Y
+	width := d.ContentWidth()
+	height := d.ContentHeight()
+	if cx < 0 || cy < 0 || cx >= width || cy >= height {
+		return nil, 0, false
+	}
+
+	// Edges of the docker, unless the window is the only docked window
+	if d.tree == nil || d.tree.split != dockSplitNone || len(d.tree.windows) > 1 || d.tree.windows[0] != w {
+		edge := 0
+		if cy < dockEdgeZone {
+			edge = DockTop
+		} else if cy >= height-dockEdgeZone {
+			edge = DockBottom
+		} else if cx < dockEdgeZone {
+			edge = DockLeft
+		} else if cx >= width-dockEdgeZone {
+			edge = DockRight
+		}
+		if edge != 0 {
+			if d.tree == nil {
+				return nil, DockCenter, true
+			}
+			return nil, edge, true
+		}
+	}
+	if d.tree == nil {
+		return nil, 0, false
+	}
+
+	// Edges or center of the leaf under the cursor, unless the leaf only
+	// contains the window
+	n := d.tree
+	for n.split != dockSplitNone {
+		c := n.children[1]
+		if n.split == dockSplitH && cx < c.x || n.split == dockSplitV && cy < c.y {
+			c = n.children[0]
+		}
+		n = c
+	}
+	if len(n.windows) == 1 && n.windows[0] == w || n.width <= 0 || n.height <= 0 {
+		return nil, 0, false
+	}
+	rx := (cx - n.x) / n.width
+	ry := (cy - n.y) / n.height
+	edge, dist := DockLeft, rx
+	if 1-rx < dist {
+		edge, dist = DockRight, 1-rx
+	}
+	if ry < dist {
+		edge, dist = DockTop, ry
+	}
+	if 1-ry < dist {
+		edge, dist = DockBottom, 1-ry
+	}
+	if dist >= dockEdgeFraction/2 {
+		edge = DockCenter
+	}
+	if edge == DockCenter && d.nodes[w] == n {
+		return nil, 0, false
+	}
+	return n, edge, true
+}
+
+// recalc recalculates the positions and sizes of the docked windows
+// and keeps the floating windows over them
+func (d *Docker) recalc() {
+
+	if d.tree != nil {
+		d.layoutNode(d.tree, 0, 0, d.ContentWidth(), d.ContentHeight())
+	}
+	for _, iobj := range d.Children() {
+		if w, ok := iobj.(*Window); ok && d.nodes[w] == nil {
+			w.SetForeground()
+		}
+	}
+	if d.preview.Visible() {
+		d.preview.SetForeground()
+	}
+}
+
+// layoutNode sets the position and size of the specified node and its children
+func (d *Docker) layoutNode(n *dockNode, x, y, width, height float32) {
+
+	n.x, n.y, n.width, n.height = x, y, width, height
+	if n.split == dockSplitNone {
+		var tabsHeight float32
+		if n.tabs != nil {
+			n.tabs.SetPosition(x, y)
+			n.tabs.SetWidth(width)
+			tabsHeight = n.tabs.Height()
+		}
+		for _, w := range n.windows {
+			w.SetPosition(x, y+tabsHeight)
+			w.SetSize(width, math32.Max(height-tabsHeight, 0))
+		}
+		return
+	}
+	ss := d.styles.SpacerSize
+	if n.split == dockSplitH {
+		w0 := math32.Round(math32.Max(width-ss, 0) * n.ratio)
+		d.layoutNode(n.children[0], x, y, w0, height)
+		n.spacer.SetPosition(x+w0, y)
+		n.spacer.SetSize(ss, height)
+		d.layoutNode(n.children[1], x+w0+ss, y, math32.Max(width-w0-ss, 0), height)
+		return
+	}
+	h0 := math32.Round(math32.Max(height-ss, 0) * n.ratio)
+	d.layoutNode(n.children[0], x, y, width, h0)
+	n.spacer.SetPosition(x, y+h0)
+	n.spacer.SetSize(width, ss)
+	d.layoutNode(n.children[1], x, y+h0+ss, width, math32.Max(height-h0-ss, 0))
+}
+
+// update updates the visual state
+func (d *Docker) update() {
+
+	d.preview.SetColor4(&d.styles.PreviewColor)
+}
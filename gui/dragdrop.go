@@ -0,0 +1,322 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// DragData is the payload of a drag and drop operation
+type DragData struct {
+	Source IPanel      // Panel where the drag started
+	Type   string      // Application defined type of the value, which drop targets may check to accept it
+	Value  interface{} // Dragged value
+	Text   string      // Text of the default ghost panel
+	Ghost  IPanel      // Optional panel which follows the cursor while dragging (default is a label with Text)
+}
+
+// DragEvent is the event dispatched to drag sources and drop targets
+// during drag and drop operations.
+// OnDragEnter and OnDragOver handlers of drop targets may change Accept to
+// accept or reject the payload, which is initially accepted if the accept
+// function of the target returns true. For OnDragEnd, Accept reports if the
+// payload was dropped and Target is the panel where it was dropped.
+type DragEvent struct {
+	Data   *DragData // Payload
+	Xpos   float32   // Cursor position
+	Ypos   float32
+	Target IPanel // Current drop target or nil
+	Accept bool   // Drop target accepts the payload
+}
+
+// DragStyle describes the style of the default ghost panel
+type DragStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4 // Border color when not over a drop target
+	AcceptColor math32.Color4 // Border color over drop targets which accept the payload
+	RejectColor math32.Color4 // Border color over drop targets which reject the payload
+	BgColor     math32.Color4
+	FgColor     math32.Color4
+}
+
+// dragSource is a panel registered as a source of drag operations
+type dragSource struct {
+	start   func(x, y float32) *DragData // returns the payload of a drag started at the cursor position
+	pressed bool                         // left mouse button pressed over the panel
+	pressX  float32                      // cursor position when the button was pressed
+	pressY  float32
+}
+
+// dropTarget is a panel registered as a drop target
+type dropTarget struct {
+	accept func(data *DragData) bool // returns if the payload is accepted
+}
+
+// dragOp is the state of the current drag and drop operation of a root panel
+type dragOp struct {
+	data   *DragData // payload
+	ghost  IPanel    // panel which follows the cursor
+	label  *Label    // default ghost label or nil
+	focus  Panel     // panel with the mouse and key focus which receives the drag events
+	target IPanel    // current drop target or nil
+	accept bool      // current drop target accepts the payload
+}
+
+const (
+	dragThreshold = 4  // cursor displacement in pixels which starts a drag operation
+	dragGhostDX   = 12 // position of the ghost panel relative to the cursor
+	dragGhostDY   = 12
+)
+
+var dragSources = map[*Panel]*dragSource{} // registered drag sources
+var dropTargets = map[*Panel]*dropTarget{} // registered drop targets
+
+// SetDragSource sets the specified panel as a source of drag operations.
+// When the cursor is moved over the panel with the left mouse button pressed,
+// the specified function is called with the position where the button was
+// pressed and a drag operation starts if it returns a payload.
+// OnDragStart and OnDragEnd are dispatched to the source panel.
+func SetDragSource(ipan IPanel, start func(x, y float32) *DragData) {
+
+	pan := ipan.GetPanel()
+	if ds := dragSources[pan]; ds != nil {
+		ds.start = start
+		return
+	}
+	ds := &dragSource{start: start}
+	dragSources[pan] = ds
+	pan.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		if dragSources[pan] != ds || mev.Button != window.MouseButtonLeft {
+			return
+		}
+		ds.pressed = true
+		ds.pressX = mev.Xpos
+		ds.pressY = mev.Ypos
+	})
+	pan.Subscribe(OnMouseUp, func(evname string, ev interface{}) { ds.pressed = false })
+	pan.Subscribe(OnCursorLeave, func(evname string, ev interface{}) { ds.pressed = false })
+	pan.Subscribe(OnCursor, func(evname string, ev interface{}) {
+		cev := ev.(*window.CursorEvent)
+		if !ds.pressed || dragSources[pan] != ds || pan.root == nil || pan.root.drag != nil {
+			return
+		}
+		if math32.Abs(cev.Xpos-ds.pressX)+math32.Abs(cev.Ypos-ds.pressY) < dragThreshold {
+			return
+		}
+		ds.pressed = false
+		data := ds.start(ds.pressX, ds.pressY)
+		if data == nil {
+			return
+		}
+		if data.Source == nil {
+			data.Source = ipan
+		}
+		pan.root.StartDrag(data, cev.Xpos, cev.Ypos)
+	})
+}
+
+// ClearDragSource removes the specified panel from the drag sources
+func ClearDragSource(ipan IPanel) {
+
+	delete(dragSources, ipan.GetPanel())
+}
+
+// SetDropTarget sets the specified panel as a drop target which accepts the
+// payloads for which the specified function returns true, or all payloads if
+// it is nil. OnDragEnter, OnDragOver, OnDragLeave and OnDrop are dispatched to
+// the target panel with DragEvent parameters.
+func SetDropTarget(ipan IPanel, accept func(data *DragData) bool) {
+
+	dropTargets[ipan.GetPanel()] = &dropTarget{accept: accept}
+}
+
+// ClearDropTarget removes the specified panel from the drop targets
+func ClearDropTarget(ipan IPanel) {
+
+	delete(dropTargets, ipan.GetPanel())
+}
+
+// StartDrag starts a drag and drop operation with the specified payload
+// at the specified cursor position. It is called by drag sources and may
+// be called directly to start drag operations from other events.
+func (r *Root) StartDrag(data *DragData, x, y float32) {
+
+	if r.drag != nil {
+		r.CancelDrag()
+	}
+	r.hideTooltip()
+	op := new(dragOp)
+	op.data = data
+	op.ghost = data.Ghost
+	if op.ghost == nil {
+		s := &StyleDefault.Drag
+		op.label = NewLabel(data.Text)
+		op.label.SetBordersFrom(&s.Border)
+		op.label.SetPaddingsFrom(&s.Paddings)
+		op.label.SetBordersColor4(&s.BorderColor)
+		op.label.SetColor4(&s.FgColor)
+		op.label.Panel.SetColor4(&s.BgColor)
+		op.ghost = op.label
+	}
+	r.Add(op.ghost)
+	op.ghost.GetPanel().SetForeground()
+
+	// The focus panel receives all the mouse and key events while dragging
+	op.focus.Initialize(0, 0)
+	op.focus.Subscribe(OnCursor, func(evname string, ev interface{}) {
+		cev := ev.(*window.CursorEvent)
+		r.dragMove(cev.Xpos, cev.Ypos)
+		r.StopPropagation(Stop3D)
+	})
+	op.focus.Subscribe(OnMouseUp, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		r.dragEnd(mev.Xpos, mev.Ypos)
+		r.StopPropagation(Stop3D)
+	})
+	op.focus.Subscribe(OnKeyDown, func(evname string, ev interface{}) {
+		if ev.(*window.KeyEvent).Keycode == window.KeyEscape {
+			r.CancelDrag()
+		}
+		r.StopPropagation(Stop3D)
+	})
+	r.drag = op
+	r.SetMouseFocus(&op.focus)
+	r.SetKeyFocus(&op.focus)
+	if data.Source != nil {
+		data.Source.GetPanel().Dispatch(OnDragStart, &DragEvent{Data: data, Xpos: x, Ypos: y})
+	}
+	r.dragMove(x, y)
+}
+
+// Dragging returns if there is a drag and drop operation in progress
+func (r *Root) Dragging() bool {
+
+	return r.drag != nil
+}
+
+// CancelDrag cancels the current drag and drop operation without dropping its payload
+func (r *Root) CancelDrag() {
+
+	op := r.drag
+	if op == nil {
+		return
+	}
+	if op.target != nil {
+		op.target.GetPanel().Dispatch(OnDragLeave, &DragEvent{Data: op.data, Target: op.target})
+	}
+	r.finishDrag(nil, 0, 0)
+}
+
+// dragMove moves the ghost panel to the specified cursor position and
+// dispatches the events of the drop targets under the cursor
+func (r *Root) dragMove(x, y float32) {
+
+	op := r.drag
+	op.ghost.GetPanel().SetPosition(x+dragGhostDX, y+dragGhostDY)
+	target := r.dropTargetAt(x, y)
+	ev := &DragEvent{Data: op.data, Xpos: x, Ypos: y, Target: target}
+	if target != op.target {
+		if op.target != nil {
+			op.target.GetPanel().Dispatch(OnDragLeave, ev)
+		}
+		op.target = target
+		op.accept = false
+		if target != nil {
+			dt := dropTargets[target.GetPanel()]
+			ev.Accept = dt.accept == nil || dt.accept(op.data)
+			target.GetPanel().Dispatch(OnDragEnter, ev)
+			op.accept = ev.Accept
+		}
+	}
+	if target != nil {
+		ev.Accept = op.accept
+		target.GetPanel().Dispatch(OnDragOver, ev)
+		op.accept = ev.Accept
+	}
+
+	// Accept/reject feedback
+	if target != nil && op.accept {
+		r.SetCursorDrag()
+	} else {
+		r.SetCursorNormal()
+	}
+	if op.label != nil {
+		s := &StyleDefault.Drag
+		color := &s.BorderColor
+		if target != nil {
+			color = &s.RejectColor
+			if op.accept {
+				color = &s.AcceptColor
+			}
+		}
+		op.label.SetBordersColor4(color)
+	}
+}
+
+// dragEnd drops the payload of the current drag operation at the specified
+// cursor position if there is a drop target which accepts it there
+func (r *Root) dragEnd(x, y float32) {
+
+	op := r.drag
+	r.dragMove(x, y)
+	var target IPanel
+	if op.target != nil && op.accept {
+		target = op.target
+		target.GetPanel().Dispatch(OnDrop, &DragEvent{Data: op.data, Xpos: x, Ypos: y, Target: target, Accept: true})
+	} else if op.target != nil {
+		op.target.GetPanel().Dispatch(OnDragLeave, &DragEvent{Data: op.data, Xpos: x, Ypos: y, Target: op.target})
+	}
+	r.finishDrag(target, x, y)
+}
+
+// finishDrag removes the ghost panel, restores the event processing and
+// dispatches OnDragEnd to the source of the current drag operation
+func (r *Root) finishDrag(target IPanel, x, y float32) {
+
+	op := r.drag
+	r.drag = nil
+	r.Panel.Remove(op.ghost)
+	r.SetMouseFocus(nil)
+	if r.keyFocus == IPanel(&op.focus) {
+		r.ClearKeyFocus()
+	}
+	r.SetCursorNormal()
+	if op.data.Source != nil {
+		op.data.Source.GetPanel().Dispatch(OnDragEnd, &DragEvent{Data: op.data, Xpos: x, Ypos: y, Target: target, Accept: target != nil})
+	}
+}
+
+// dropTargetAt returns the foreground drop target which contains
+// the specified screen position or nil
+func (r *Root) dropTargetAt(x, y float32) IPanel {
+
+	var found IPanel
+	var check func(ipan IPanel)
+	check = func(ipan IPanel) {
+		pan := ipan.GetPanel()
+		if !pan.Visible() || !pan.Enabled() || ipan == r.drag.ghost {
+			return
+		}
+		if dropTargets[pan] != nil && pan.ContainsPosition(x, y) {
+			if found == nil || pan.pospix.Z < found.GetPanel().pospix.Z {
+				found = ipan
+			}
+		}
+		for _, child := range pan.Children() {
+			if cpan, ok := child.(IPanel); ok {
+				check(cpan)
+			}
+		}
+	}
+	for _, iobj := range r.Children() {
+		if ipan, ok := iobj.(IPanel); ok {
+			check(ipan)
+		}
+	}
+	return found
+}
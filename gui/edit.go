@@ -19,10 +19,15 @@ type Edit struct {
 	placeHolder string // place holder string
 	text        string // current edit text
 	col         int    // current column
+	selCol      int    // column of the selection anchor (equal to col if nothing selected)
+	dragging    bool   // selecting text with the mouse
 	focus       bool   // key focus flag
 	cursorOver  bool
 	blinkID     int
 	caretOn     bool
+	preedit     string // input method composition text
+	preCaret    int    // caret position in the composition text
+	selColor    math32.Color
 	styles      *EditStyles
 }
 
@@ -34,6 +39,7 @@ type EditStyle struct {
 	BgAlpha     float32
 	FgColor     math32.Color
 	HolderColor math32.Color
+	SelColor    math32.Color
 }
 
 type EditStyles struct {
@@ -63,21 +69,29 @@ func NewEdit(width int, placeHolder string) *Edit {
 
 	ed.Label.initialize("", StyleDefault.Font)
 	ed.Label.Subscribe(OnKeyDown, ed.onKey)
-	ed.Label.Subscribe(OnChar, ed.onChar)
+	ed.Label.Subscribe(OnText, ed.onText)
+	ed.Label.Subscribe(OnPreedit, ed.onPreedit)
 	ed.Label.Subscribe(OnMouseDown, ed.onMouse)
+	ed.Label.Subscribe(OnMouseUp, ed.onMouse)
+	ed.Label.Subscribe(OnCursor, ed.onCursor)
 	ed.Label.Subscribe(OnCursorEnter, ed.onCursor)
 	ed.Label.Subscribe(OnCursorLeave, ed.onCursor)
 	ed.Label.Subscribe(OnEnable, func(evname string, ev interface{}) { ed.update() })
+	ed.Label.Subscribe(OnScale, func(evname string, ev interface{}) { ed.update() })
 
 	ed.update()
 	return ed
 }
 
 // SetText sets this edit text
-func (ed *Edit) SetText(text string) *Edit {
+func (ed *Edit) SetText(msg string) *Edit {
 
 	// Remove new lines from text
-	ed.text = strings.Replace(text, "\n", "", -1)
+	ed.text = strings.Replace(msg, "\n", "", -1)
+	if ed.col > text.StrCount(ed.text) {
+		ed.col = text.StrCount(ed.text)
+	}
+	ed.selCol = ed.col
 	ed.update()
 	return ed
 }
@@ -108,8 +122,21 @@ func (ed *Edit) SetStyles(es *EditStyles) {
 func (ed *Edit) LostKeyFocus() {
 
 	ed.focus = false
+	ed.preedit = ""
 	ed.update()
 	ed.root.ClearTimeout(ed.blinkID)
+	ed.Dispatch(OnFocusLost, nil)
+}
+
+// focusIn sets the key focus to this edit and starts blinking the caret
+func (ed *Edit) focusIn() {
+
+	ed.root.SetKeyFocus(ed)
+	if !ed.focus && ed.root.HasKeyFocus(ed) {
+		ed.focus = true
+		ed.blinkID = ed.root.SetInterval(750*time.Millisecond, nil, ed.blink)
+		ed.update()
+	}
 }
 
 // CursorPos sets the position of the cursor at the
@@ -117,34 +144,32 @@ func (ed *Edit) LostKeyFocus() {
 func (ed *Edit) CursorPos(col int) {
 
 	if col <= text.StrCount(ed.text) {
-		ed.col = col
-		ed.redraw(ed.focus)
+		ed.moveCursor(col, false)
 	}
 }
 
 // CursorLeft moves the edit cursor one character left if possible
 func (ed *Edit) CursorLeft() {
 
-	if ed.col > 0 {
-		ed.col--
-		ed.redraw(ed.focus)
-	}
+	ed.moveCursor(ed.col-1, false)
 }
 
 // CursorRight moves the edit cursor one character right if possible
 func (ed *Edit) CursorRight() {
 
-	if ed.col < text.StrCount(ed.text) {
-		ed.col++
-		ed.redraw(ed.focus)
-	}
+	ed.moveCursor(ed.col+1, false)
 }
 
-// CursorBack deletes the character at left of the cursor if possible
+// CursorBack deletes the selected text or
+// the character at left of the cursor if possible
 func (ed *Edit) CursorBack() {
 
+	if ed.deleteSelection() {
+		return
+	}
 	if ed.col > 0 {
 		ed.col--
+		ed.selCol = ed.col
 		ed.text = text.StrRemove(ed.text, ed.col)
 		ed.redraw(ed.focus)
 		ed.Dispatch(OnChange, nil)
@@ -154,20 +179,22 @@ func (ed *Edit) CursorBack() {
 // CursorHome moves the edit cursor to the beginning of the text
 func (ed *Edit) CursorHome() {
 
-	ed.col = 0
-	ed.redraw(ed.focus)
+	ed.moveCursor(0, false)
 }
 
 // CursorEnd moves the edit cursor to the end of the text
 func (ed *Edit) CursorEnd() {
 
-	ed.col = text.StrCount(ed.text)
-	ed.redraw(ed.focus)
+	ed.moveCursor(text.StrCount(ed.text), false)
 }
 
-// CursorDelete deletes the character at the right of the cursor if possible
+// CursorDelete deletes the selected text or
+// the character at the right of the cursor if possible
 func (ed *Edit) CursorDelete() {
 
+	if ed.deleteSelection() {
+		return
+	}
 	if ed.col < text.StrCount(ed.text) {
 		ed.text = text.StrRemove(ed.text, ed.col)
 		ed.redraw(ed.focus)
@@ -176,33 +203,113 @@ func (ed *Edit) CursorDelete() {
 }
 
 // CursorInput inserts the specified string at the current cursor position
+// replacing the selected text if any
 func (ed *Edit) CursorInput(s string) {
 
-	if text.StrCount(ed.text) >= ed.MaxLength {
-		return
-	}
-
 	// Set new text with included input
-	var newText string
-	if ed.col < text.StrCount(ed.text) {
-		newText = text.StrInsert(ed.text, s, ed.col)
-	} else {
-		newText = ed.text + s
+	start, end := ed.selection()
+	prefix := text.StrPrefix(ed.text, start)
+	rest := ed.text[len(text.StrPrefix(ed.text, end)):]
+	if text.StrCount(prefix)+text.StrCount(s)+text.StrCount(rest) > ed.MaxLength {
+		return
 	}
+	newText := prefix + s + rest
 
 	// Checks if new text exceeds edit width
-	width, _ := ed.Label.font.MeasureText(newText)
-	if float32(width)+editMarginX+float32(1) >= ed.Label.ContentWidth() {
+	if ed.Label.textWidth(newText)+editMarginX+float32(1) >= ed.Label.ContentWidth() {
 		return
 	}
 
 	ed.text = newText
-	ed.col++
+	ed.col = start + text.StrCount(s)
+	ed.selCol = ed.col
 
 	ed.Dispatch(OnChange, nil)
 	ed.redraw(ed.focus)
 }
 
+// SelectAll selects all the text
+func (ed *Edit) SelectAll() {
+
+	ed.selCol = 0
+	ed.moveCursor(text.StrCount(ed.text), true)
+}
+
+// SelectedText returns the currently selected text
+func (ed *Edit) SelectedText() string {
+
+	start, end := ed.selection()
+	prefix := text.StrPrefix(ed.text, start)
+	return text.StrPrefix(ed.text, end)[len(prefix):]
+}
+
+// Copy copies the selected text to the clipboard
+func (ed *Edit) Copy() {
+
+	if ed.selCol != ed.col {
+		SetClipboardText(ed.SelectedText())
+	}
+}
+
+// Cut copies the selected text to the clipboard and deletes it
+func (ed *Edit) Cut() {
+
+	ed.Copy()
+	ed.deleteSelection()
+}
+
+// Paste inserts the text of the clipboard at the cursor
+// position replacing the selected text if any
+func (ed *Edit) Paste() {
+
+	s := strings.Replace(GetClipboardText(), "\n", "", -1)
+	if s != "" {
+		ed.CursorInput(s)
+	}
+}
+
+// selection returns the start and end columns of the selected text
+func (ed *Edit) selection() (int, int) {
+
+	if ed.selCol < ed.col {
+		return ed.selCol, ed.col
+	}
+	return ed.col, ed.selCol
+}
+
+// moveCursor moves the cursor to the specified column
+// extending the selection if specified
+func (ed *Edit) moveCursor(col int, extend bool) {
+
+	if col < 0 {
+		col = 0
+	}
+	if count := text.StrCount(ed.text); col > count {
+		col = count
+	}
+	ed.col = col
+	if !extend {
+		ed.selCol = col
+	}
+	ed.redraw(ed.focus)
+}
+
+// deleteSelection deletes the selected text returning false if nothing is selected
+func (ed *Edit) deleteSelection() bool {
+
+	if ed.selCol == ed.col {
+		return false
+	}
+	start, end := ed.selection()
+	prefix := text.StrPrefix(ed.text, start)
+	ed.text = prefix + ed.text[len(text.StrPrefix(ed.text, end)):]
+	ed.col = start
+	ed.selCol = start
+	ed.redraw(ed.focus)
+	ed.Dispatch(OnChange, nil)
+	return true
+}
+
 // redraw redraws the text showing the caret if specified
 func (ed *Edit) redraw(caret bool) {
 
@@ -210,22 +317,66 @@ func (ed *Edit) redraw(caret bool) {
 	if !caret {
 		line = -1
 	}
-	ed.Label.setTextCaret(ed.text, editMarginX, ed.width, line, ed.col)
+	// Shows the input method composition text underlined at the cursor
+	// and the selected text over the selection color
+	msg := ed.text
+	col := ed.col
+	var marks []labelMark
+	if ed.preedit != "" {
+		prefix := text.StrPrefix(ed.text, ed.col)
+		msg = prefix + ed.preedit + ed.text[len(prefix):]
+		col += ed.preCaret
+		marks = append(marks, labelMark{start: ed.col, end: ed.col + text.StrCount(ed.preedit), under: true})
+	} else if ed.focus && ed.selCol != ed.col {
+		start, end := ed.selection()
+		mark := labelMark{start: start, end: end}
+		mark.color.FromColor(&ed.selColor, 1)
+		marks = append(marks, mark)
+	}
+	ed.Label.setTextCaret(msg, editMarginX, ed.width, line, col, marks...)
+
+	// Informs the window of the cursor position for the input method candidates
+	if ed.focus && ed.root != nil {
+		width := ed.Label.textWidth(text.StrPrefix(msg, col))
+		x := ed.pospix.X + ed.content.X + editMarginX + width
+		ed.root.SetIMECursorRect(x, ed.pospix.Y+ed.content.Y, 1, ed.ContentHeight())
+	}
 }
 
 // onKey receives subscribed key events
 func (ed *Edit) onKey(evname string, ev interface{}) {
 
+	// Keys are handled by the input method while composing
+	if ed.preedit != "" {
+		return
+	}
 	kev := ev.(*window.KeyEvent)
+	sel := kev.Mods&window.ModShift != 0
+	if kev.Mods&window.ModControl != 0 {
+		switch window.LayoutKey(kev.W, kev.Keycode, kev.Scancode) {
+		case window.KeyA:
+			ed.SelectAll()
+		case window.KeyC:
+			ed.Copy()
+		case window.KeyX:
+			ed.Cut()
+		case window.KeyV:
+			ed.Paste()
+		default:
+			return
+		}
+		ed.root.StopPropagation(Stop3D)
+		return
+	}
 	switch kev.Keycode {
 	case window.KeyLeft:
-		ed.CursorLeft()
+		ed.moveCursor(ed.col-1, sel)
 	case window.KeyRight:
-		ed.CursorRight()
+		ed.moveCursor(ed.col+1, sel)
 	case window.KeyHome:
-		ed.CursorHome()
+		ed.moveCursor(0, sel)
 	case window.KeyEnd:
-		ed.CursorEnd()
+		ed.moveCursor(text.StrCount(ed.text), sel)
 	case window.KeyBackspace:
 		ed.CursorBack()
 	case window.KeyDelete:
@@ -236,38 +387,45 @@ func (ed *Edit) onKey(evname string, ev interface{}) {
 	ed.root.StopPropagation(Stop3D)
 }
 
-// onChar receives subscribed char events
-func (ed *Edit) onChar(evname string, ev interface{}) {
+// onText receives subscribed text input events
+func (ed *Edit) onText(evname string, ev interface{}) {
 
-	cev := ev.(*window.CharEvent)
-	ed.CursorInput(string(cev.Char))
+	tev := ev.(*window.TextEvent)
+	ed.CursorInput(tev.Text)
 }
 
-// onMouseEvent receives subscribed mouse down events
-func (ed *Edit) onMouse(evname string, ev interface{}) {
+// onPreedit receives subscribed input method composition events
+func (ed *Edit) onPreedit(evname string, ev interface{}) {
 
-	e := ev.(*window.MouseEvent)
-	if e.Button != window.MouseButtonLeft {
-		return
+	pev := ev.(*window.PreeditEvent)
+	ed.preedit = pev.Text
+	ed.preCaret = pev.Caret
+	if ed.preCaret < 0 || ed.preCaret > text.StrCount(ed.preedit) {
+		ed.preCaret = text.StrCount(ed.preedit)
 	}
+	ed.redraw(ed.focus)
+	ed.root.StopPropagation(Stop3D)
+}
 
-	// Set key focus to this panel
-	ed.root.SetKeyFocus(ed)
+// onMouseEvent receives subscribed mouse button events
+func (ed *Edit) onMouse(evname string, ev interface{}) {
 
-	// Find clicked column
-	var nchars int
-	for nchars = 1; nchars <= text.StrCount(ed.text); nchars++ {
-		width, _ := ed.Label.font.MeasureText(text.StrPrefix(ed.text, nchars))
-		posx := e.Xpos - ed.pospix.X
-		if posx < editMarginX+float32(width) {
-			break
+	e := ev.(*window.MouseEvent)
+	if evname == OnMouseUp {
+		if ed.dragging {
+			ed.dragging = false
+			ed.root.SetMouseFocus(nil)
 		}
+		return
 	}
-	if !ed.focus {
-		ed.focus = true
-		ed.blinkID = ed.root.SetInterval(750*time.Millisecond, nil, ed.blink)
+	if e.Button != window.MouseButtonLeft {
+		return
 	}
-	ed.CursorPos(nchars - 1)
+
+	ed.focusIn()
+	ed.moveCursor(ed.columnAt(e.Xpos), e.Mods&window.ModShift != 0)
+	ed.dragging = true
+	ed.root.SetMouseFocus(ed)
 	ed.root.StopPropagation(Stop3D)
 }
 
@@ -286,6 +444,25 @@ func (ed *Edit) onCursor(evname string, ev interface{}) {
 		ed.root.StopPropagation(Stop3D)
 		return
 	}
+	if evname == OnCursor && ed.dragging {
+		cev := ev.(*window.CursorEvent)
+		ed.moveCursor(ed.columnAt(cev.Xpos), true)
+		ed.root.StopPropagation(Stop3D)
+	}
+}
+
+// columnAt returns the text column nearest to the specified screen x coordinate
+func (ed *Edit) columnAt(x float32) int {
+
+	var nchars int
+	for nchars = 1; nchars <= text.StrCount(ed.text); nchars++ {
+		width := ed.Label.textWidth(text.StrPrefix(ed.text, nchars))
+		posx := x - ed.pospix.X
+		if posx < editMarginX+width {
+			break
+		}
+	}
+	return nchars - 1
 }
 
 // blink blinks the caret
@@ -328,6 +505,7 @@ func (ed *Edit) applyStyle(s *EditStyle) {
 	ed.SetPaddingsFrom(&s.Paddings)
 	ed.Label.SetColor(&s.FgColor)
 	ed.Label.SetBgColor(&s.BgColor)
+	ed.selColor = s.SelColor
 	//ed.Label.SetBgAlpha(s.BgAlpha)
 
 	if !ed.focus && len(ed.text) == 0 && len(ed.placeHolder) > 0 {
@@ -20,10 +20,34 @@ const (
 	OnKeyDown     = window.OnKeyDown    // key is pressed
 	OnKeyUp       = window.OnKeyUp      // key is released
 	OnChar        = window.OnChar       // key is pressed and has unicode
+	OnText        = window.OnText       // text typed (independent of the keys)
+	OnPreedit     = window.OnPreedit    // input method composition text changed
 	OnResize      = "gui.OnResize"      // panel size changed (no parameters)
 	OnEnable      = "gui.OnEnable"      // panel enabled state changed (no parameters)
+	OnFocusLost   = "gui.OnFocusLost"   // edit lost the key focus (no parameters)
 	OnChange      = "gui.OnChange"      // onChange is emitted by List, DropDownList, CheckBox and Edit
 	OnScroll      = window.OnScroll     // scroll event
 	OnChild       = "gui.OnChild"       // child added to or removed from panel
 	OnRadioGroup  = "gui.OnRadioGroup"  // radio button from a group changed state
+	OnTableSort   = "gui.OnTableSort"   // table rows sorted by a column (no parameters)
+	OnTableEdit   = "gui.OnTableEdit"   // table cell value changed by its editor (TableEditEvent)
+	OnDockChange  = "gui.OnDockChange"  // docker windows layout changed (no parameters)
+	OnDragStart   = "gui.OnDragStart"   // drag operation started from a drag source (DragEvent)
+	OnDragEnter   = "gui.OnDragEnter"   // cursor dragging a payload entered a drop target (DragEvent)
+	OnDragOver    = "gui.OnDragOver"    // cursor dragging a payload moved over a drop target (DragEvent)
+	OnDragLeave   = "gui.OnDragLeave"   // cursor dragging a payload left a drop target (DragEvent)
+	OnDrop        = "gui.OnDrop"        // payload dropped over a drop target (DragEvent)
+	OnDragEnd     = "gui.OnDragEnd"     // drag operation finished or cancelled (DragEvent)
+	OnAccept      = "gui.OnAccept"      // dialog accepted (dialog specific parameter)
+	OnCancel      = "gui.OnCancel"      // dialog cancelled (no parameters)
+	OnLink        = "gui.OnLink"        // link clicked in a RichLabel (link target string)
+	OnScale       = "gui.OnScale"       // panel effective scale factor changed (no parameters)
+	OnTabClose    = "gui.OnTabClose"    // tab close button clicked (TabCloseEvent)
+	OnTabMove     = "gui.OnTabMove"     // tab dragged to another position (moved tab)
+	OnShortcut    = "gui.OnShortcut"    // registered keyboard shortcut pressed (Shortcut)
+	OnTap         = window.OnTap        // touch tapped (window.GestureEvent)
+	OnLongPress   = window.OnLongPress  // touch long pressed (window.GestureEvent)
+	OnPan         = window.OnPan        // touch moved (window.GestureEvent)
+	OnPinch       = window.OnPinch      // two touches moved (window.GestureEvent)
+	OnFileDrop    = window.OnFileDrop   // files dropped from the system (window.FileDropEvent)
 )
@@ -0,0 +1,625 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"github.com/g3n/engine/gui/assets"
+	"github.com/g3n/engine/window"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/***************************************
+
+ FileDialog
+ +-------------------------------------------+
+ | Title                                     |
+ +-------------------------------------------+
+ | [^] [+] /current/directory                |
+ | +-----------+ +-------------------------+ |
+ | | directory | | file list               | |
+ | | tree      | |                         | |
+ | +-----------+ +-------------------------+ |
+ | File name: [                ] [filter  v] |
+ | status              [  OK  ] [ Cancel ]   |
+ +-------------------------------------------+
+
+****************************************/
+
+// FileDialog is a window which allows the user to choose a file to open or save.
+// When the user accepts the dialog it dispatches OnAccept with the chosen
+// file path as parameter and when the user cancels the dialog it dispatches OnCancel.
+// The dialog is not closed automatically: the application should remove it
+// from its parent when handling these events.
+type FileDialog struct {
+	Window                                 // Embedded window
+	mode       FileDialogMode              // open or save
+	dir        string                      // current directory
+	filters    []FileFilter                // file filters
+	filter     int                         // index of current filter
+	showHidden bool                        // show hidden files and directories
+	up         *Button                     // parent directory button
+	mkdir      *Button                     // new folder button
+	path       *Label                      // current directory label
+	tree       *Tree                       // directory tree
+	list       *List                       // list of files of the current directory
+	nameLabel  *Label                      // file name label
+	name       *Edit                       // file name edit
+	filterDD   *DropDown                   // filters drop down
+	status     *Label                      // status and error messages
+	ok         *Button                     // accept button
+	cancel     *Button                     // cancel button
+	nodes      map[*TreeNode]string        // directory of each tree node
+	loaded     map[*TreeNode]bool          // tree nodes with loaded children
+	entries    map[*ImageLabel]os.FileInfo // file information of each list item
+	lastItem   *ImageLabel                 // last clicked list item
+	lastClick  time.Time                   // time of the last click over a list item
+}
+
+// FileDialogMode specifies if a file dialog is used to open or save files
+type FileDialogMode int
+
+const (
+	FileDialogOpen = FileDialogMode(iota) // Choose an existing file to open
+	FileDialogSave                        // Choose a new or existing file to save
+)
+
+// FileFilter specifies the name and the file name patterns of a file dialog filter
+type FileFilter struct {
+	Name     string   // Name shown to the user
+	Patterns []string // Patterns as used by filepath.Match (ex: "*.png"). No patterns match all files.
+}
+
+const (
+	fileDialogSpacing     = 4    // spacing between the internal panels in pixels
+	fileDialogTreeWidth   = 0.35 // width of the directory tree relative to the client width
+	fileDialogDoubleClick = 400  // maximum interval between double clicks in milliseconds
+)
+
+// NewFileDialog creates and returns a pointer to a new file dialog with the
+// specified mode and dimensions which shows the current working directory
+func NewFileDialog(mode FileDialogMode, width, height float32) *FileDialog {
+
+	fd := new(FileDialog)
+	fd.Window.Initialize(width, height)
+	fd.mode = mode
+	fd.nodes = make(map[*TreeNode]string)
+	fd.loaded = make(map[*TreeNode]bool)
+	fd.entries = make(map[*ImageLabel]os.FileInfo)
+	if mode == FileDialogSave {
+		fd.SetTitle("Save File")
+	} else {
+		fd.SetTitle("Open File")
+	}
+
+	// Toolbar
+	fd.up = NewButton("")
+	fd.up.SetIcon(assets.ArrowUpward)
+	fd.up.Subscribe(OnClick, func(evname string, ev interface{}) {
+		fd.setDir(filepath.Dir(fd.dir), true)
+	})
+	fd.client.Add(fd.up)
+	fd.mkdir = NewButton("")
+	fd.mkdir.SetIcon(assets.CreateNewFolder)
+	fd.mkdir.Subscribe(OnClick, func(evname string, ev interface{}) {
+		if _, err := fd.NewFolder(""); err != nil {
+			fd.setStatus(err.Error())
+		}
+	})
+	fd.client.Add(fd.mkdir)
+	fd.path = NewLabel("")
+	fd.client.Add(fd.path)
+
+	// Directory tree and file list
+	fd.tree = NewTree(0, 0)
+	fd.tree.Subscribe(OnChange, fd.onTreeChange)
+	fd.client.Add(fd.tree)
+	fd.list = NewVList(0, 0)
+	fd.list.Subscribe(OnChange, fd.onListChange)
+	fd.client.Add(fd.list)
+
+	// File name and filters
+	fd.nameLabel = NewLabel("File name:")
+	fd.client.Add(fd.nameLabel)
+	fd.name = NewEdit(0, "")
+	fd.name.MaxLength = 255
+	fd.name.Subscribe(OnKeyDown, func(evname string, ev interface{}) {
+		if ev.(*window.KeyEvent).Keycode == window.KeyEnter {
+			fd.Accept()
+		}
+	})
+	fd.client.Add(fd.name)
+	fd.filterDD = NewDropDown(0, NewImageLabel(""))
+	fd.filterDD.Subscribe(OnChange, func(evname string, ev interface{}) {
+		pos := fd.filterDD.list.ItemPosition(fd.filterDD.Selected())
+		if pos >= 0 && pos != fd.filter {
+			fd.filter = pos
+			fd.Refresh()
+		}
+	})
+	fd.client.Add(fd.filterDD)
+	fd.SetFilters(FileFilter{Name: "All files"})
+
+	// Status and buttons
+	fd.status = NewLabel("")
+	fd.client.Add(fd.status)
+	if mode == FileDialogSave {
+		fd.ok = NewButton("Save")
+	} else {
+		fd.ok = NewButton("Open")
+	}
+	fd.ok.Subscribe(OnClick, func(evname string, ev interface{}) { fd.Accept() })
+	fd.client.Add(fd.ok)
+	fd.cancel = NewButton("Cancel")
+	fd.cancel.Subscribe(OnClick, func(evname string, ev interface{}) { fd.Cancel() })
+	fd.client.Add(fd.cancel)
+
+	fd.client.Subscribe(OnResize, func(evname string, ev interface{}) { fd.recalc() })
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = string(filepath.Separator)
+	}
+	fd.SetDir(dir)
+	fd.recalc()
+	return fd
+}
+
+// Mode returns the mode of this dialog
+func (fd *FileDialog) Mode() FileDialogMode {
+
+	return fd.mode
+}
+
+// SetDir sets the current directory of this dialog
+func (fd *FileDialog) SetDir(dir string) error {
+
+	return fd.setDir(dir, true)
+}
+
+// Dir returns the current directory of this dialog
+func (fd *FileDialog) Dir() string {
+
+	return fd.dir
+}
+
+// SetFilters sets the file filters of this dialog and selects the first one.
+// If no filters are specified all the files are shown.
+func (fd *FileDialog) SetFilters(filters ...FileFilter) {
+
+	fd.filters = filters
+	fd.filter = 0
+	for fd.filterDD.list.Len() > 0 {
+		fd.filterDD.RemoveAt(fd.filterDD.list.Len() - 1)
+	}
+	for _, f := range filters {
+		text := f.Name
+		if len(f.Patterns) > 0 {
+			text += " (" + strings.Join(f.Patterns, ", ") + ")"
+		}
+		fd.filterDD.Add(NewImageLabel(text))
+	}
+	fd.filterDD.SetVisible(len(filters) > 0)
+	if len(filters) > 0 {
+		fd.selectFilter(0)
+	}
+	fd.Refresh()
+}
+
+// SetFilter selects the filter at the specified position
+func (fd *FileDialog) SetFilter(pos int) {
+
+	if pos < 0 || pos >= len(fd.filters) {
+		return
+	}
+	fd.selectFilter(pos)
+}
+
+// Filter returns the position of the selected filter or -1 if there are no filters
+func (fd *FileDialog) Filter() int {
+
+	if len(fd.filters) == 0 {
+		return -1
+	}
+	return fd.filter
+}
+
+// SetShowHidden sets if hidden files and directories,
+// with names starting with a dot, are shown
+func (fd *FileDialog) SetShowHidden(state bool) {
+
+	fd.showHidden = state
+	fd.Refresh()
+}
+
+// SetFileName sets the file name shown in the edit box
+func (fd *FileDialog) SetFileName(name string) {
+
+	fd.name.SetText(name)
+}
+
+// FileName returns the file name in the edit box
+func (fd *FileDialog) FileName() string {
+
+	return strings.TrimSpace(fd.name.Text())
+}
+
+// Path returns the path of the file name in the edit box
+// relative to the current directory or an empty string
+func (fd *FileDialog) Path() string {
+
+	name := fd.FileName()
+	if name == "" {
+		return ""
+	}
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Join(fd.dir, name)
+}
+
+// Refresh reads the contents of the current directory again
+func (fd *FileDialog) Refresh() error {
+
+	if fd.dir == "" {
+		return nil
+	}
+	return fd.setDir(fd.dir, false)
+}
+
+// NewFolder creates a directory with the specified name in the current
+// directory, or with a unique "New folder" name if the name is empty,
+// changes the current directory to it and returns its path.
+func (fd *FileDialog) NewFolder(name string) (string, error) {
+
+	if name == "" {
+		name = "New folder"
+		for i := 2; ; i++ {
+			if _, err := os.Stat(filepath.Join(fd.dir, name)); os.IsNotExist(err) {
+				break
+			}
+			name = fmt.Sprintf("New folder (%d)", i)
+		}
+	}
+	path := filepath.Join(fd.dir, name)
+	err := os.Mkdir(path, 0755)
+	if err != nil {
+		return "", err
+	}
+	// Reloads the children of the current directory tree node
+	for node, dir := range fd.nodes {
+		if dir == fd.dir && fd.loaded[node] {
+			fd.loadNode(node)
+		}
+	}
+	return path, fd.setDir(path, true)
+}
+
+// Accept validates the file name in the edit box and dispatches OnAccept with
+// its path. If the file name is a directory it becomes the current directory.
+func (fd *FileDialog) Accept() {
+
+	path := fd.Path()
+	if path == "" {
+		fd.setStatus("Enter a file name")
+		return
+	}
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		fd.name.SetText("")
+		fd.setDir(path, true)
+		return
+	}
+	switch fd.mode {
+	case FileDialogOpen:
+		if err != nil {
+			fd.setStatus("File not found: " + fd.FileName())
+			return
+		}
+	case FileDialogSave:
+		if _, err := os.Stat(filepath.Dir(path)); err != nil {
+			fd.setStatus("Directory not found: " + filepath.Dir(path))
+			return
+		}
+		// Appends the extension of the current filter if the name has no extension
+		if filepath.Ext(path) == "" && fd.filter < len(fd.filters) {
+			for _, pattern := range fd.filters[fd.filter].Patterns {
+				ext := filepath.Ext(pattern)
+				if strings.HasPrefix(pattern, "*.") && !strings.ContainsAny(ext, "*?[") {
+					path += ext
+					break
+				}
+			}
+		}
+	}
+	fd.setStatus("")
+	fd.Dispatch(OnAccept, path)
+}
+
+// Cancel dispatches OnCancel
+func (fd *FileDialog) Cancel() {
+
+	fd.Dispatch(OnCancel, nil)
+}
+
+// setDir changes the current directory, reloads the file list and
+// optionally expands and selects the directory in the tree
+func (fd *FileDialog) setDir(dir string, syncTree bool) error {
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		fd.setStatus(err.Error())
+		return err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		fd.setStatus(err.Error())
+		return err
+	}
+	fd.dir = dir
+	fd.path.SetText(dir)
+	fd.setStatus("")
+
+	// Directories first, then the files which match the current filter
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].IsDir() && !infos[j].IsDir() })
+	for fd.list.Len() > 0 {
+		fd.list.RemoveAt(fd.list.Len() - 1)
+	}
+	fd.entries = make(map[*ImageLabel]os.FileInfo)
+	fd.lastItem = nil
+	for _, info := range infos {
+		if !fd.showHidden && strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		if !info.IsDir() && !fd.matchFilter(info.Name()) {
+			continue
+		}
+		item := NewImageLabel(info.Name())
+		if info.IsDir() {
+			item.SetIcon(assets.Folder)
+		} else {
+			item.SetIcon(assets.InsertDriveFile)
+		}
+		item.Subscribe(OnMouseDown, func(evname string, ev interface{}) { fd.onItemMouse(item, ev.(*window.MouseEvent)) })
+		fd.entries[item] = info
+		fd.list.Add(item)
+	}
+	if syncTree {
+		fd.selectTreeDir(dir)
+	}
+	return nil
+}
+
+// matchFilter returns if the specified file name matches the current filter
+func (fd *FileDialog) matchFilter(name string) bool {
+
+	if fd.filter >= len(fd.filters) || len(fd.filters[fd.filter].Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range fd.filters[fd.filter].Patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTreeDir builds the tree nodes of all the parent directories
+// of the specified directory, expands them and selects its node
+func (fd *FileDialog) selectTreeDir(dir string) {
+
+	// Parent directories from the root
+	dirs := []string{dir}
+	for parent := filepath.Dir(dir); parent != dirs[0]; parent = filepath.Dir(parent) {
+		dirs = append([]string{parent}, dirs...)
+	}
+
+	// Root node
+	var node *TreeNode
+	for n, d := range fd.nodes {
+		if n.parNode == nil && d == dirs[0] {
+			node = n
+		}
+	}
+	if node == nil {
+		for fd.tree.Len() > 0 {
+			fd.tree.RemoveAt(fd.tree.Len() - 1)
+		}
+		fd.nodes = make(map[*TreeNode]string)
+		fd.loaded = make(map[*TreeNode]bool)
+		node = fd.tree.AddNode(dirs[0])
+		fd.addNode(node, dirs[0])
+	}
+
+	// Expands the nodes down to the directory
+	for _, d := range dirs[1:] {
+		if !fd.loaded[node] {
+			fd.loadNode(node)
+		}
+		node.SetExpanded(true)
+		var child *TreeNode
+		for _, item := range node.items {
+			if n, ok := item.(*TreeNode); ok && fd.nodes[n] == d {
+				child = n
+				break
+			}
+		}
+		if child == nil {
+			break
+		}
+		node = child
+	}
+	// Selects the node without dispatching OnChange
+	if pos := fd.tree.ItemPosition(node); pos >= 0 {
+		fd.tree.setSelection(fd.tree.items[pos].(*ListItem), true, true, false)
+	}
+}
+
+// addNode registers a new tree node for the specified directory
+func (fd *FileDialog) addNode(node *TreeNode, dir string) {
+
+	fd.nodes[node] = dir
+	node.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+		if node.expanded && !fd.loaded[node] {
+			fd.loadNode(node)
+		}
+	})
+}
+
+// loadNode reads the subdirectories of the directory of the
+// specified tree node and adds their nodes as its children
+func (fd *FileDialog) loadNode(node *TreeNode) {
+
+	for node.Len() > 0 {
+		child := node.items[node.Len()-1].(*TreeNode)
+		fd.removeNode(child)
+		node.Remove(child)
+	}
+	fd.loaded[node] = true
+	dir := fd.nodes[node]
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		if !info.IsDir() || (!fd.showHidden && strings.HasPrefix(info.Name(), ".")) {
+			continue
+		}
+		fd.addNode(node.AddNode(info.Name()), filepath.Join(dir, info.Name()))
+	}
+}
+
+// removeNode unregisters the specified tree node and its children
+func (fd *FileDialog) removeNode(node *TreeNode) {
+
+	for _, item := range node.items {
+		if n, ok := item.(*TreeNode); ok {
+			fd.removeNode(n)
+		}
+	}
+	delete(fd.nodes, node)
+	delete(fd.loaded, node)
+}
+
+// onTreeChange is called when a directory is selected in the tree
+func (fd *FileDialog) onTreeChange(evname string, ev interface{}) {
+
+	sel := fd.tree.Selected()
+	node, ok := sel.(*TreeNode)
+	if !ok {
+		return
+	}
+	if dir, ok := fd.nodes[node]; ok && dir != fd.dir {
+		fd.setDir(dir, false)
+	}
+}
+
+// onListChange is called when an item of the file list is selected
+func (fd *FileDialog) onListChange(evname string, ev interface{}) {
+
+	sel := fd.list.Selected()
+	if len(sel) == 0 {
+		return
+	}
+	info := fd.entries[sel[0].(*ImageLabel)]
+	if info != nil && !info.IsDir() {
+		fd.name.SetText(info.Name())
+	}
+}
+
+// onItemMouse is called when a mouse button is pressed over the specified
+// item of the file list. Double clicking a directory changes to it and double clicking
+// a file accepts the dialog.
+func (fd *FileDialog) onItemMouse(item *ImageLabel, mev *window.MouseEvent) {
+
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	now := time.Now()
+	double := item == fd.lastItem && now.Sub(fd.lastClick) < fileDialogDoubleClick*time.Millisecond
+	fd.lastItem = item
+	fd.lastClick = now
+	if !double {
+		return
+	}
+	fd.lastItem = nil
+	info := fd.entries[item]
+	if info.IsDir() {
+		fd.setDir(filepath.Join(fd.dir, info.Name()), true)
+		return
+	}
+	fd.name.SetText(info.Name())
+	fd.Accept()
+}
+
+// selectFilter selects the item of the filters drop down at the specified
+// position deselecting the others, which dispatches OnChange
+func (fd *FileDialog) selectFilter(pos int) {
+
+	fd.filterDD.list.setSelection(fd.filterDD.list.items[pos].(*ListItem), true, true, true)
+}
+
+// setStatus sets the text of the status label
+func (fd *FileDialog) setStatus(text string) {
+
+	fd.status.SetText(text)
+	fd.recalc()
+}
+
+// recalc recalculates the sizes and positions of the internal panels
+func (fd *FileDialog) recalc() {
+
+	if fd.cancel == nil {
+		return
+	}
+	width := fd.client.ContentWidth()
+	height := fd.client.ContentHeight()
+	sp := float32(fileDialogSpacing)
+
+	// Toolbar
+	fd.up.SetPosition(0, 0)
+	fd.mkdir.SetPosition(fd.up.Width()+sp, 0)
+	tbHeight := fd.up.Height()
+	fd.path.SetPosition(fd.mkdir.Position().X+fd.mkdir.Width()+sp, (tbHeight-fd.path.Height())/2)
+
+	// Bottom rows
+	btHeight := fd.ok.Height()
+	fd.cancel.SetPosition(width-fd.cancel.Width(), height-btHeight)
+	fd.ok.SetPosition(fd.cancel.Position().X-sp-fd.ok.Width(), height-btHeight)
+	fd.status.SetPosition(0, height-btHeight+(btHeight-fd.status.Height())/2)
+	nameY := height - btHeight - sp - fd.filterDD.Height()
+	fd.nameLabel.SetPosition(0, nameY+(fd.filterDD.Height()-fd.nameLabel.Height())/2)
+	var ddWidth float32
+	if fd.filterDD.Visible() {
+		ddWidth = width * fileDialogTreeWidth
+		fd.filterDD.SetWidth(ddWidth)
+		fd.filterDD.SetPosition(width-ddWidth, nameY)
+		ddWidth += sp
+	}
+	nameX := fd.nameLabel.Width() + sp
+	editWidth := width - nameX - ddWidth
+	if editWidth < 0 {
+		editWidth = 0
+	}
+	fd.name.width = int(editWidth)
+	fd.name.redraw(fd.name.focus)
+	fd.name.SetPosition(nameX, nameY+(fd.filterDD.Height()-fd.name.Height())/2)
+
+	// Tree and list fill the remaining space
+	top := tbHeight + sp
+	listHeight := nameY - sp - top
+	if listHeight < 0 {
+		listHeight = 0
+	}
+	treeWidth := width * fileDialogTreeWidth
+	fd.tree.SetPosition(0, top)
+	fd.tree.SetSize(treeWidth, listHeight)
+	fd.list.SetPosition(treeWidth+sp, top)
+	fd.list.SetSize(width-treeWidth-sp, listHeight)
+}
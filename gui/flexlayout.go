@@ -0,0 +1,354 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+// FlexLayout is a layout which places the children of a panel along a main
+// axis (horizontal for rows and vertical for columns) similar to the CSS
+// flexible box layout. Children may grow to fill the free space or shrink
+// when there is not enough space, and may be wrapped in several lines.
+type FlexLayout struct {
+	pan          IPanel
+	direction    FlexDirection       // main axis direction
+	wrap         bool                // wrap children in several lines
+	justify      FlexJustify         // distribution of the free space along the main axis
+	alignItems   FlexAlign           // default alignment of the children in the cross axis
+	alignContent FlexAlign           // alignment of the lines in the cross axis when wrapping
+	spacing      float32             // spacing between children along the main axis in pixels
+	lineSpacing  float32             // spacing between lines in pixels
+	items        map[*Panel]flexItem // sizes of the children
+}
+
+// FlexLayoutParams are the parameters for individual children
+type FlexLayoutParams struct {
+	Grow      float32   // item grow factor when there is free space (0 - does not grow)
+	Shrink    float32   // item shrink factor when there is not enough space (0 - does not shrink)
+	Basis     float32   // item initial size in the main axis (0 - use the panel size)
+	MinSize   float32   // item minimum size in the main axis
+	MaxSize   float32   // item maximum size in the main axis (0 - no maximum)
+	AlignSelf FlexAlign // item alignment in the cross axis (FlexAlignAuto - use the layout alignment)
+}
+
+// FlexDirection specifies the main axis of a flex layout
+type FlexDirection int
+
+// FlexJustify specifies how the free space is distributed along the main axis
+type FlexJustify int
+
+// FlexAlign specifies how items or lines are aligned in the cross axis
+type FlexAlign int
+
+// flexItem keeps the natural size of a child panel and the size set by the layout
+// so that changes of the child size made outside the layout are detected.
+type flexItem struct {
+	natural [2]float32 // natural width and height
+	current [2]float32 // width and height set by the layout
+}
+
+const (
+	FlexRow           = FlexDirection(iota) // Children are placed from left to right
+	FlexRowReverse                          // Children are placed from right to left
+	FlexColumn                              // Children are placed from top to bottom
+	FlexColumnReverse                       // Children are placed from bottom to top
+)
+
+const (
+	FlexStart        = FlexJustify(iota) // Children packed at the start of the line
+	FlexEnd                              // Children packed at the end of the line
+	FlexCenter                           // Children packed at the center of the line
+	FlexSpaceBetween                     // Free space distributed between the children
+	FlexSpaceAround                      // Free space distributed around the children
+	FlexSpaceEvenly                      // Free space distributed evenly between and around the children
+)
+
+const (
+	FlexAlignAuto    = FlexAlign(iota) // Use the alignment of the layout (only for children)
+	FlexAlignStart                     // Align at the start of the cross axis
+	FlexAlignEnd                       // Align at the end of the cross axis
+	FlexAlignCenter                    // Align at the center of the cross axis
+	FlexAlignStretch                   // Stretch to fill the cross axis
+)
+
+// NewFlexLayout creates and returns a pointer to a new flex layout
+// with the specified main axis direction
+func NewFlexLayout(direction FlexDirection) *FlexLayout {
+
+	fl := new(FlexLayout)
+	fl.direction = direction
+	fl.justify = FlexStart
+	fl.alignItems = FlexAlignStretch
+	fl.alignContent = FlexAlignStart
+	fl.items = make(map[*Panel]flexItem)
+	return fl
+}
+
+// SetDirection sets the main axis direction and updates the layout if possible
+func (fl *FlexLayout) SetDirection(direction FlexDirection) {
+
+	fl.direction = direction
+	fl.Recalc(fl.pan)
+}
+
+// Direction returns the main axis direction
+func (fl *FlexLayout) Direction() FlexDirection {
+
+	return fl.direction
+}
+
+// SetWrap sets if the children are wrapped in several lines when they
+// don't fit in the main axis and updates the layout if possible
+func (fl *FlexLayout) SetWrap(wrap bool) {
+
+	fl.wrap = wrap
+	fl.Recalc(fl.pan)
+}
+
+// Wrap returns if the children are wrapped in several lines
+func (fl *FlexLayout) Wrap() bool {
+
+	return fl.wrap
+}
+
+// SetJustify sets how the free space of each line is distributed
+// along the main axis and updates the layout if possible
+func (fl *FlexLayout) SetJustify(justify FlexJustify) {
+
+	fl.justify = justify
+	fl.Recalc(fl.pan)
+}
+
+// SetAlignItems sets the default alignment of the children in the
+// cross axis and updates the layout if possible
+func (fl *FlexLayout) SetAlignItems(align FlexAlign) {
+
+	fl.alignItems = align
+	fl.Recalc(fl.pan)
+}
+
+// SetAlignContent sets the alignment of the lines in the cross axis
+// when wrapping and updates the layout if possible
+func (fl *FlexLayout) SetAlignContent(align FlexAlign) {
+
+	fl.alignContent = align
+	fl.Recalc(fl.pan)
+}
+
+// SetSpacing sets the spacing between the children along the main axis
+// in pixels and updates the layout if possible
+func (fl *FlexLayout) SetSpacing(spacing float32) {
+
+	fl.spacing = spacing
+	fl.Recalc(fl.pan)
+}
+
+// SetLineSpacing sets the spacing between lines in pixels
+// and updates the layout if possible
+func (fl *FlexLayout) SetLineSpacing(spacing float32) {
+
+	fl.lineSpacing = spacing
+	fl.Recalc(fl.pan)
+}
+
+// Recalc recalculates and sets the position and sizes of all children
+func (fl *FlexLayout) Recalc(ipan IPanel) {
+
+	// Saves the received panel
+	fl.pan = ipan
+	if fl.pan == nil {
+		return
+	}
+	parent := ipan.GetPanel()
+
+	// Main axis index: 0 for rows and 1 for columns
+	main := 0
+	if fl.direction == FlexColumn || fl.direction == FlexColumnReverse {
+		main = 1
+	}
+	cross := 1 - main
+	content := [2]float32{parent.ContentWidth(), parent.ContentHeight()}
+
+	// Collects the visible children with their natural sizes.
+	// If the size of a child was changed outside the layout it is its new natural size.
+	type element struct {
+		pan    *Panel
+		params FlexLayoutParams
+		size   [2]float32
+	}
+	items := make(map[*Panel]flexItem)
+	elems := []element{}
+	paramsDef := FlexLayoutParams{Shrink: 1}
+	for _, obj := range parent.Children() {
+		pan := obj.(IPanel).GetPanel()
+		if !pan.Visible() {
+			continue
+		}
+		params := paramsDef
+		if pan.layoutParams != nil {
+			params = *pan.layoutParams.(*FlexLayoutParams)
+		}
+		size := [2]float32{pan.Width(), pan.Height()}
+		item, ok := fl.items[pan]
+		for i := 0; i < 2; i++ {
+			if !ok || size[i] != item.current[i] {
+				item.natural[i] = size[i]
+			}
+		}
+		items[pan] = item
+		size = item.natural
+		if params.Basis > 0 {
+			size[main] = params.Basis
+		}
+		size[main] = fl.clamp(size[main], &params)
+		elems = append(elems, element{pan, params, size})
+	}
+	fl.items = items
+	if len(elems) == 0 {
+		return
+	}
+
+	// Breaks the children in lines
+	lines := [][]element{}
+	var start int
+	var used float32
+	for i := range elems {
+		if fl.wrap && i > start && used+fl.spacing+elems[i].size[main] > content[main] {
+			lines = append(lines, elems[start:i])
+			start = i
+		}
+		if i > start {
+			used += fl.spacing
+		} else {
+			used = 0
+		}
+		used += elems[i].size[main]
+	}
+	lines = append(lines, elems[start:])
+
+	// Calculates the main axis sizes of the children of each line and the line cross sizes
+	lsizes := make([]float32, len(lines))
+	var lused float32
+	for l, line := range lines {
+		free := content[main] - fl.spacing*float32(len(line)-1)
+		var tgrow, tshrink float32
+		for _, e := range line {
+			free -= e.size[main]
+			tgrow += e.params.Grow
+			tshrink += e.params.Shrink * e.size[main]
+		}
+		for i := range line {
+			e := &line[i]
+			if free > 0 && tgrow > 0 {
+				e.size[main] = fl.clamp(e.size[main]+free*e.params.Grow/tgrow, &e.params)
+			} else if free < 0 && tshrink > 0 {
+				e.size[main] = fl.clamp(e.size[main]+free*e.params.Shrink*e.size[main]/tshrink, &e.params)
+			}
+			if e.size[cross] > lsizes[l] {
+				lsizes[l] = e.size[cross]
+			}
+		}
+		lused += lsizes[l]
+	}
+	if !fl.wrap {
+		lsizes[0] = content[cross]
+		lused = content[cross]
+	}
+
+	// Calculates the cross position of the first line and the extra size of each line
+	lused += fl.lineSpacing * float32(len(lines)-1)
+	var lpos, lextra float32
+	if fl.wrap && content[cross] > lused {
+		switch fl.alignContent {
+		case FlexAlignEnd:
+			lpos = content[cross] - lused
+		case FlexAlignCenter:
+			lpos = (content[cross] - lused) / 2
+		case FlexAlignStretch:
+			lextra = (content[cross] - lused) / float32(len(lines))
+		}
+	}
+
+	// Sets the positions and sizes of the children of each line
+	for l, line := range lines {
+		lsize := lsizes[l] + lextra
+		free := content[main] - fl.spacing*float32(len(line)-1)
+		for _, e := range line {
+			free -= e.size[main]
+		}
+		pos, space := fl.justifyLine(free, len(line))
+		for _, e := range line {
+			// Cross axis alignment
+			align := e.params.AlignSelf
+			if align == FlexAlignAuto {
+				align = fl.alignItems
+			}
+			var cpos float32
+			switch align {
+			case FlexAlignEnd:
+				cpos = lsize - e.size[cross]
+			case FlexAlignCenter:
+				cpos = (lsize - e.size[cross]) / 2
+			case FlexAlignStretch:
+				e.size[cross] = lsize
+			}
+			// Main axis position considering reverse directions
+			mpos := pos
+			if fl.direction == FlexRowReverse || fl.direction == FlexColumnReverse {
+				mpos = content[main] - pos - e.size[main]
+			}
+			var p [2]float32
+			p[main] = mpos
+			p[cross] = lpos + cpos
+			e.pan.SetSize(e.size[0], e.size[1])
+			e.pan.SetPosition(p[0], p[1])
+			item := fl.items[e.pan]
+			item.current = [2]float32{e.pan.Width(), e.pan.Height()}
+			fl.items[e.pan] = item
+			pos += e.size[main] + space
+		}
+		lpos += lsize + fl.lineSpacing
+	}
+}
+
+// justifyLine returns the main axis position of the first child of a line
+// and the spacing between its children for the specified free space
+func (fl *FlexLayout) justifyLine(free float32, count int) (float32, float32) {
+
+	if free <= 0 {
+		return 0, fl.spacing
+	}
+	switch fl.justify {
+	case FlexEnd:
+		return free, fl.spacing
+	case FlexCenter:
+		return free / 2, fl.spacing
+	case FlexSpaceBetween:
+		if count > 1 {
+			return 0, fl.spacing + free/float32(count-1)
+		}
+		return 0, fl.spacing
+	case FlexSpaceAround:
+		gap := free / float32(count)
+		return gap / 2, fl.spacing + gap
+	case FlexSpaceEvenly:
+		gap := free / float32(count+1)
+		return gap, fl.spacing + gap
+	}
+	return 0, fl.spacing
+}
+
+// clamp returns the specified main axis size limited by the minimum and
+// maximum sizes of the specified child parameters
+func (fl *FlexLayout) clamp(size float32, params *FlexLayoutParams) float32 {
+
+	if params.MaxSize > 0 && size > params.MaxSize {
+		size = params.MaxSize
+	}
+	if size < params.MinSize {
+		size = params.MinSize
+	}
+	if size < 0 {
+		size = 0
+	}
+	return size
+}
@@ -0,0 +1,180 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// FocusRingStyle describes the style of the focus ring, the frame shown
+// around the widget with the key focus after it was focused by the keyboard
+type FocusRingStyle struct {
+	Border      BorderSizes
+	BorderColor math32.Color4
+	Margin      float32 // distance from the focused widget
+}
+
+// SetFocusOrder sets the position of this panel in the key focus chain
+// traversed with Tab and Shift+Tab. Panels with positive orders come first
+// in ascending order, followed by the panels with the default order of zero
+// in the order of the panel hierarchy. A negative order removes the panel
+// from the chain. Panels which are not focusable widgets are only included
+// in the chain with a positive order.
+func (p *Panel) SetFocusOrder(order int) {
+
+	p.focusOrder = order
+}
+
+// FocusOrder returns the position of this panel in the key focus chain
+func (p *Panel) FocusOrder() int {
+
+	return p.focusOrder
+}
+
+// SetTabNavigation sets if the Tab and Shift+Tab keys not used by the
+// focused widget move the key focus along the focus chain (default true)
+func (r *Root) SetTabNavigation(state bool) {
+
+	r.noTabNav = !state
+}
+
+// TabNavigation returns if the Tab keys move the key focus
+func (r *Root) TabNavigation() bool {
+
+	return !r.noTabNav
+}
+
+// FocusNext moves the key focus to the next or previous widget of the focus
+// chain, starting with the first or last if none is focused, and shows the
+// focus ring around it. While a modal dialog is shown the focus chain only
+// contains the dialog widgets. It returns if the key focus was moved.
+func (r *Root) FocusNext(reverse bool) bool {
+
+	list := r.focusList()
+	if len(list) == 0 {
+		return false
+	}
+	pos := -1
+	for i, ipan := range list {
+		if r.HasKeyFocus(ipan) {
+			pos = i
+			break
+		}
+	}
+	if reverse {
+		if pos <= 0 {
+			pos = len(list)
+		}
+		pos--
+	} else {
+		pos = (pos + 1) % len(list)
+	}
+	setKeyFocus(r, list[pos])
+	r.showFocusRing()
+	return true
+}
+
+// showFocusRing shows the focus ring over the other panels
+// until a mouse button is pressed
+func (r *Root) showFocusRing() {
+
+	if r.focusRing.Parent() == nil {
+		r.Add(&r.focusRing)
+	}
+	r.focusRing.SetForeground()
+	r.updateFocusRing()
+}
+
+// hideFocusRing hides the focus ring
+func (r *Root) hideFocusRing() {
+
+	if r.focusRing.Parent() != nil {
+		r.Panel.Remove(&r.focusRing)
+	}
+}
+
+// updateFocusRing sets the position and size of the focus ring around the
+// panel with the key focus or hides it if the panel is not visible.
+// It is called after the panel positions are updated.
+func (r *Root) updateFocusRing() {
+
+	if r.focusRing.Parent() == nil {
+		return
+	}
+	if r.keyFocus == nil || !visibleInRoot(r.keyFocus) {
+		r.focusRing.SetVisible(false)
+		return
+	}
+	s := &StyleDefault.FocusRing
+	pan := r.keyFocus.GetPanel()
+	r.focusRing.SetBordersFrom(&s.Border)
+	r.focusRing.SetBordersColor4(&s.BorderColor)
+	r.focusRing.SetPosition(pan.pospix.X-r.pospix.X-s.Margin-s.Border.Left, pan.pospix.Y-r.pospix.Y-s.Margin-s.Border.Top)
+	r.focusRing.SetSize(pan.width+2*s.Margin+s.Border.Left+s.Border.Right, pan.height+2*s.Margin+s.Border.Top+s.Border.Bottom)
+	r.focusRing.SetVisible(true)
+	r.focusRing.UpdateMatrixWorld()
+}
+
+// focusList returns the focus chain of the top modal dialog
+// if one is shown or else of this root panel
+func (r *Root) focusList() []IPanel {
+
+	if m := r.TopModal(); m != nil {
+		return focusChain(m.dialog)
+	}
+	return focusChain(r)
+}
+
+// focusChain returns the widgets of the specified panel hierarchy which
+// accept the key focus in the order they are traversed
+func focusChain(ipan IPanel) []IPanel {
+
+	var list []IPanel
+	var find func(ipan IPanel)
+	find = func(ipan IPanel) {
+		pan := ipan.GetPanel()
+		if !pan.Visible() || !pan.Enabled() {
+			return
+		}
+		widget := focusable(ipan)
+		if pan.focusOrder > 0 || (widget && pan.focusOrder == 0) {
+			list = append(list, ipan)
+		}
+		if widget {
+			return
+		}
+		for _, child := range pan.Children() {
+			find(child.(IPanel))
+		}
+	}
+	find(ipan)
+	sort.SliceStable(list, func(i, j int) bool {
+		oi := list[i].GetPanel().focusOrder
+		oj := list[j].GetPanel().focusOrder
+		return oi > 0 && (oj == 0 || oi < oj)
+	})
+	return list
+}
+
+// visibleInRoot returns if the specified panel and all its ancestors are visible
+func visibleInRoot(ipan IPanel) bool {
+
+	node := ipan.GetNode()
+	for node != nil {
+		if !node.Visible() {
+			return false
+		}
+		par := node.Parent()
+		if par == nil {
+			return false
+		}
+		if _, ok := par.(*Root); ok {
+			return true
+		}
+		node = par.GetNode()
+	}
+	return false
+}
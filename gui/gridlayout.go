@@ -4,34 +4,128 @@
 
 package gui
 
+// GridLayout is a layout which places the children of a panel in the cells
+// of a grid. Rows and columns may be auto sized to fit their children,
+// have a fixed size or share the free space of the panel in proportion to
+// their weights. Children may span several rows and columns.
 type GridLayout struct {
-	columns int
+	pan        IPanel
+	colSizes   map[int]gridSize    // sizes of the columns which are not auto sized
+	rowSizes   map[int]gridSize    // sizes of the rows which are not auto sized
+	colSpacing float32             // horizontal spacing between columns in pixels
+	rowSpacing float32             // vertical spacing between rows in pixels
+	items      map[*Panel]gridItem // sizes of the children
 }
 
+// GridLayoutParams are the parameters for individual children
 type GridLayoutParams struct {
 	Row     int   // grid layout row number from 0
 	Col     int   // grid layout column number from 0
 	ColSpan int   // number of additional columns to ocuppy to the right
-	AlignH  Align // vertical alignment
-	AlignV  Align // horizontal alignment
+	RowSpan int   // number of additional rows to occupy below
+	AlignH  Align // horizontal alignment (AlignWidth resizes the child to fill the cell width)
+	AlignV  Align // vertical alignment (AlignHeight resizes the child to fill the cell height)
+}
+
+// gridSize is the size of a fixed or weighted row or column
+type gridSize struct {
+	weighted bool    // size is a weight of the free space
+	value    float32 // size in pixels or weight
+}
+
+// gridItem keeps the natural size of a child panel and the size set by the layout
+// so that changes of the child size made outside the layout are detected.
+type gridItem struct {
+	natural   [2]float32 // natural width and height
+	current   [2]float32 // width and height set by the layout
+	stretched [2]bool    // width and height stretched to the cell by the layout
 }
 
 // NewGridLayout creates and returns a pointer of a new grid layout
 func NewGridLayout() *GridLayout {
 
 	g := new(GridLayout)
+	g.colSizes = make(map[int]gridSize)
+	g.rowSizes = make(map[int]gridSize)
+	g.items = make(map[*Panel]gridItem)
 	return g
 }
 
+// SetColumnAuto sets the specified column to be auto sized to the width
+// of its widest child and updates the layout if possible. This is the default.
+func (g *GridLayout) SetColumnAuto(col int) {
+
+	delete(g.colSizes, col)
+	g.Recalc(g.pan)
+}
+
+// SetColumnFixed sets the width in pixels of the specified column
+// and updates the layout if possible
+func (g *GridLayout) SetColumnFixed(col int, width float32) {
+
+	g.colSizes[col] = gridSize{false, width}
+	g.Recalc(g.pan)
+}
+
+// SetColumnWeight sets the weight of the specified column, which receives
+// this proportion of the free width of the panel, and updates the layout if possible
+func (g *GridLayout) SetColumnWeight(col int, weight float32) {
+
+	g.colSizes[col] = gridSize{true, weight}
+	g.Recalc(g.pan)
+}
+
+// SetRowAuto sets the specified row to be auto sized to the height
+// of its highest child and updates the layout if possible. This is the default.
+func (g *GridLayout) SetRowAuto(row int) {
+
+	delete(g.rowSizes, row)
+	g.Recalc(g.pan)
+}
+
+// SetRowFixed sets the height in pixels of the specified row
+// and updates the layout if possible
+func (g *GridLayout) SetRowFixed(row int, height float32) {
+
+	g.rowSizes[row] = gridSize{false, height}
+	g.Recalc(g.pan)
+}
+
+// SetRowWeight sets the weight of the specified row, which receives
+// this proportion of the free height of the panel, and updates the layout if possible
+func (g *GridLayout) SetRowWeight(row int, weight float32) {
+
+	g.rowSizes[row] = gridSize{true, weight}
+	g.Recalc(g.pan)
+}
+
+// SetSpacing sets the horizontal spacing between columns and the vertical
+// spacing between rows in pixels and updates the layout if possible
+func (g *GridLayout) SetSpacing(colSpacing, rowSpacing float32) {
+
+	g.colSpacing = colSpacing
+	g.rowSpacing = rowSpacing
+	g.Recalc(g.pan)
+}
+
+// Recalc recalculates and sets the position and sizes of all children
 func (g *GridLayout) Recalc(ipan IPanel) {
 
+	// Saves the received panel
+	g.pan = ipan
+	if g.pan == nil {
+		return
+	}
+
 	type element struct {
 		panel  *Panel
 		params *GridLayoutParams
+		size   [2]float32
 	}
 	rows := 0
 	cols := 0
-	items := []element{}
+	elems := []element{}
+	items := make(map[*Panel]gridItem)
 
 	pan := ipan.GetPanel()
 	for _, obj := range pan.Children() {
@@ -50,14 +144,24 @@ func (g *GridLayout) Recalc(ipan IPanel) {
 		if !ok {
 			panic("layoutParams is not GridLayoutParams")
 		}
-		if params.Row >= rows {
-			rows = params.Row + 1
+		if params.Row+params.RowSpan >= rows {
+			rows = params.Row + params.RowSpan + 1
+		}
+		if params.Col+params.ColSpan >= cols {
+			cols = params.Col + params.ColSpan + 1
 		}
-		if params.Col >= cols {
-			cols = params.Col + 1
+		// If the size of the child was changed outside the layout it is its new natural size
+		size := [2]float32{child.Width(), child.Height()}
+		item, ok := g.items[child]
+		for i := 0; i < 2; i++ {
+			if !ok || size[i] != item.current[i] {
+				item.natural[i] = size[i]
+			}
 		}
-		items = append(items, element{child, params})
+		items[child] = item
+		elems = append(elems, element{child, params, item.natural})
 	}
+	g.items = items
 	// Check limits
 	if rows > 100 {
 		panic("Element row outsize limits")
@@ -66,55 +170,54 @@ func (g *GridLayout) Recalc(ipan IPanel) {
 		panic("Element column outsize limits")
 	}
 
-	// Determine row and column maximum sizes
-	colSizes := make([]int, cols)
-	rowSizes := make([]int, rows)
-	for _, el := range items {
-		width := el.panel.Width()
-		height := el.panel.Height()
-		if int(width) > colSizes[el.params.Col] {
-			colSizes[el.params.Col] = int(width)
+	// Determine row and column sizes
+	colSizes := g.trackSizes(cols, g.colSizes, g.colSpacing, pan.ContentWidth(), func(add func(start, span int, size float32)) {
+		for _, el := range elems {
+			add(el.params.Col, el.params.ColSpan, el.size[0])
 		}
-		if int(height) > rowSizes[el.params.Row] {
-			rowSizes[el.params.Row] = int(height)
+	})
+	rowSizes := g.trackSizes(rows, g.rowSizes, g.rowSpacing, pan.ContentHeight(), func(add func(start, span int, size float32)) {
+		for _, el := range elems {
+			add(el.params.Row, el.params.RowSpan, el.size[1])
 		}
-	}
+	})
 
 	// Determine row and column starting positions
-	colStart := make([]int, cols)
-	rowStart := make([]int, rows)
+	colStart := make([]float32, cols)
+	rowStart := make([]float32, rows)
 	for i := 1; i < len(colSizes); i++ {
-		colStart[i] = colStart[i-1] + colSizes[i-1]
+		colStart[i] = colStart[i-1] + colSizes[i-1] + g.colSpacing
 	}
 	for i := 1; i < len(rowSizes); i++ {
-		rowStart[i] = rowStart[i-1] + rowSizes[i-1]
+		rowStart[i] = rowStart[i-1] + rowSizes[i-1] + g.rowSpacing
 	}
 
 	// Position the elements
-	for _, el := range items {
+	for _, el := range elems {
 		row := el.params.Row
 		col := el.params.Col
+		// Current cell width and height including the spanned columns and rows
+		cellWidth := colSizes[col]
+		for c := 1; c <= el.params.ColSpan; c++ {
+			cellWidth += g.colSpacing + colSizes[col+c]
+		}
 		cellHeight := rowSizes[row]
-		// Current cell width
-		cellWidth := 0
-		for c := 0; c <= el.params.ColSpan; c++ {
-			pos := col + c
-			if pos >= len(colSizes) {
-				break
-			}
-			cellWidth += colSizes[pos]
+		for r := 1; r <= el.params.RowSpan; r++ {
+			cellHeight += g.rowSpacing + rowSizes[row+r]
 		}
-		rstart := float32(rowStart[row])
-		cstart := float32(colStart[col])
+		width := el.size[0]
+		height := el.size[1]
 		// Horizontal alignment
 		var dx float32 = 0
 		switch el.params.AlignH {
 		case AlignNone:
 		case AlignLeft:
 		case AlignRight:
-			dx = float32(cellWidth) - el.panel.width
+			dx = cellWidth - width
 		case AlignCenter:
-			dx = (float32(cellWidth) - el.panel.width) / 2
+			dx = (cellWidth - width) / 2
+		case AlignWidth:
+			width = cellWidth
 		default:
 			panic("Invalid horizontal alignment")
 		}
@@ -124,12 +227,101 @@ func (g *GridLayout) Recalc(ipan IPanel) {
 		case AlignNone:
 		case AlignTop:
 		case AlignBottom:
-			dy = float32(cellHeight) - el.panel.height
+			dy = cellHeight - height
 		case AlignCenter:
-			dy = (float32(cellHeight) - el.panel.height) / 2
+			dy = (cellHeight - height) / 2
+		case AlignHeight:
+			height = cellHeight
 		default:
 			panic("Invalid vertical alignment")
 		}
-		el.panel.SetPosition(cstart+dx, rstart+dy)
+		// Only the children aligned with AlignWidth or AlignHeight are resized
+		// to fill their cells. The children which no longer fill their cells
+		// recover their natural size and the others keep their own size.
+		item := g.items[el.panel]
+		fill := [2]bool{el.params.AlignH == AlignWidth, el.params.AlignV == AlignHeight}
+		if fill[0] || item.stretched[0] {
+			el.panel.SetWidth(width)
+		}
+		if fill[1] || item.stretched[1] {
+			el.panel.SetHeight(height)
+		}
+		el.panel.SetPosition(colStart[col]+dx, rowStart[row]+dy)
+		item.current = [2]float32{el.panel.Width(), el.panel.Height()}
+		item.stretched = fill
+		g.items[el.panel] = item
+	}
+}
+
+// trackSizes calculates and returns the sizes of the specified number of
+// columns or rows given their fixed and weighted sizes, the spacing between
+// them and the total available size. The specified function must call add()
+// for each child with its first track, number of additional tracks spanned
+// and natural size.
+func (g *GridLayout) trackSizes(count int, fixed map[int]gridSize, spacing, total float32, children func(add func(start, span int, size float32))) []float32 {
+
+	sizes := make([]float32, count)
+	isAuto := func(i int) bool {
+		_, ok := fixed[i]
+		return !ok
+	}
+
+	// Auto sized tracks fit the children which don't span other tracks
+	children(func(start, span int, size float32) {
+		if span == 0 && isAuto(start) && size > sizes[start] {
+			sizes[start] = size
+		}
+	})
+	for i := range sizes {
+		if gs, ok := fixed[i]; ok && !gs.weighted {
+			sizes[i] = gs.value
+		}
+	}
+
+	// The missing size of children spanning several tracks is
+	// distributed evenly between the auto sized tracks they span
+	children(func(start, span int, size float32) {
+		if span == 0 {
+			return
+		}
+		var current float32
+		autos := 0
+		for i := start; i <= start+span; i++ {
+			current += sizes[i]
+			if isAuto(i) {
+				autos++
+			}
+		}
+		current += spacing * float32(span)
+		if size <= current || autos == 0 {
+			return
+		}
+		extra := (size - current) / float32(autos)
+		for i := start; i <= start+span; i++ {
+			if isAuto(i) {
+				sizes[i] += extra
+			}
+		}
+	})
+
+	// Weighted tracks share the free size
+	var used, tweight float32
+	for i := range sizes {
+		used += sizes[i]
+		if gs, ok := fixed[i]; ok && gs.weighted {
+			tweight += gs.value
+		}
+	}
+	if count > 1 {
+		used += spacing * float32(count-1)
+	}
+	free := total - used
+	if tweight > 0 && free > 0 {
+		for i := range sizes {
+			if gs, ok := fixed[i]; ok && gs.weighted {
+				sizes[i] = free * gs.value / tweight
+			}
+		}
 	}
+	return sizes
 }
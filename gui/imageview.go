@@ -0,0 +1,229 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"image"
+)
+
+// ImageView is a panel which shows a texture which may be updated while it
+// is shown, as the frames of a video or webcam streamed with SetFrame or the
+// color texture of a render target set with SetTexture. The texture is fitted
+// to the panel content area by the fit mode keeping its aspect ratio and its
+// colors may be adjusted by the shader. The panel color is shown in the
+// content area not covered by the texture.
+type ImageView struct {
+	Panel                        // Embedded panel
+	image     Panel              // panel which shows the texture
+	mat       imageViewMaterial  // material of the image panel
+	tex       *texture.Texture2D // texture shown or nil
+	fit       ImageFit           // fit mode
+	adjust    ImageAdjust        // color adjustments
+	flipY     bool               // flips the texture vertically
+	texWidth  int                // width of the texture at the last layout
+	texHeight int                // height of the texture at the last layout
+}
+
+// ImageFit is the mode used by an ImageView to fit its texture
+type ImageFit int
+
+// Image fit modes
+const (
+	ImageFitContain ImageFit = iota // scales the texture to be inside the panel keeping its aspect ratio
+	ImageFitCover                   // scales the texture to cover the panel keeping its aspect ratio, cropping it
+	ImageFitStretch                 // stretches the texture to the panel size
+	ImageFitNone                    // shows the texture with its size in pixels centered in the panel
+)
+
+// ImageAdjust describes the color adjustments of the texture of an ImageView
+type ImageAdjust struct {
+	Brightness float32       // value added to the color components (default 0)
+	Contrast   float32       // factor of the distance of the components to the middle gray (default 1)
+	Saturation float32       // factor of the color saturation, 0 shows grayscale (default 1)
+	Tint       math32.Color4 // color multiplied by the adjusted color (default white)
+}
+
+// imageViewMaterial is the material of the image panel of an ImageView
+// which transfers the color adjustments
+type imageViewMaterial struct {
+	material.Material               // Embedded material
+	adjustUni         gls.Uniform4f // brightness, contrast, saturation and flip
+	tintUni           gls.Uniform4f // tint color
+}
+
+// NewImageView creates and returns a pointer to a new image view
+// with the specified size and no texture
+func NewImageView(width, height float32) *ImageView {
+
+	iv := new(ImageView)
+	iv.Panel.Initialize(width, height)
+	iv.mat.Init()
+	iv.mat.SetShader("shaderImageView")
+	iv.mat.adjustUni.Init("ImageAdjust")
+	iv.mat.tintUni.Init("ImageTint")
+	iv.image.initialize(0, 0, &iv.mat)
+	iv.image.SetVisible(false)
+	iv.Panel.Add(&iv.image)
+	iv.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { iv.recalc() })
+	iv.SetAdjust(&ImageAdjust{Contrast: 1, Saturation: 1, Tint: math32.Color4{1, 1, 1, 1}})
+	return iv
+}
+
+// SetTexture sets the texture shown by this image view, which keeps its
+// own reference to it, or removes the current texture if nil.
+// The texture may be changed by its owner while it is shown.
+func (iv *ImageView) SetTexture(tex *texture.Texture2D) {
+
+	if tex == iv.tex {
+		return
+	}
+	if iv.tex != nil {
+		iv.mat.RemoveTexture(iv.tex)
+		iv.tex.Dispose()
+	}
+	iv.tex = tex
+	if tex != nil {
+		iv.mat.AddTexture(tex.Incref())
+	}
+	iv.recalc()
+}
+
+// Texture returns the texture shown by this image view or nil
+func (iv *ImageView) Texture() *texture.Texture2D {
+
+	return iv.tex
+}
+
+// SetFrame sets the specified image as the next frame shown by this
+// image view, updating the texture created by the first frame
+func (iv *ImageView) SetFrame(rgba *image.RGBA) {
+
+	size := rgba.Rect.Size()
+	iv.SetFrameData(size.X, size.Y, gls.RGBA, gls.UNSIGNED_BYTE, gls.RGBA8, rgba.Pix)
+}
+
+// SetFrameData sets the specified pixel data as the next frame shown by
+// this image view, updating the texture created by the first frame.
+// The parameters are the same of texture.NewTexture2DFromData.
+func (iv *ImageView) SetFrameData(width, height int, format int, formatType, iformat int, data interface{}) {
+
+	if iv.tex != nil {
+		iv.tex.SetData(width, height, format, formatType, iformat, data)
+		iv.recalc()
+		return
+	}
+	// Frames are not mipmapped as they are replaced every few frames
+	tex := texture.NewTexture2DFromData(width, height, format, formatType, iformat, data)
+	tex.SetGenMipmap(false)
+	iv.SetTexture(tex)
+	tex.Dispose()
+}
+
+// SetFitMode sets the mode used to fit the texture in this image view
+func (iv *ImageView) SetFitMode(fit ImageFit) {
+
+	iv.fit = fit
+	iv.recalc()
+}
+
+// FitMode returns the mode used to fit the texture in this image view
+func (iv *ImageView) FitMode() ImageFit {
+
+	return iv.fit
+}
+
+// SetFlipY sets if the texture is flipped vertically. The textures of
+// render targets, whose FlipY state is false, are already shown upright.
+func (iv *ImageView) SetFlipY(state bool) {
+
+	iv.flipY = state
+	iv.updateAdjust()
+}
+
+// FlipY returns if the texture is flipped vertically
+func (iv *ImageView) FlipY() bool {
+
+	return iv.flipY
+}
+
+// SetAdjust sets the color adjustments of the texture
+func (iv *ImageView) SetAdjust(adjust *ImageAdjust) {
+
+	iv.adjust = *adjust
+	iv.updateAdjust()
+}
+
+// Adjust returns the color adjustments of the texture
+func (iv *ImageView) Adjust() ImageAdjust {
+
+	return iv.adjust
+}
+
+// UpdateMatrixWorld overrides the Panel version to fit
+// the texture again if its size changed
+func (iv *ImageView) UpdateMatrixWorld() {
+
+	if iv.tex != nil && (iv.tex.Width() != iv.texWidth || iv.tex.Height() != iv.texHeight) {
+		iv.recalc()
+	}
+	iv.Panel.UpdateMatrixWorld()
+}
+
+// updateAdjust sets the uniforms of the color adjustments
+func (iv *ImageView) updateAdjust() {
+
+	a := &iv.adjust
+	flip := float32(0)
+	if iv.flipY {
+		flip = 1
+	}
+	iv.mat.adjustUni.Set(a.Brightness, a.Contrast, a.Saturation, flip)
+	iv.mat.tintUni.Set(a.Tint.R, a.Tint.G, a.Tint.B, a.Tint.A)
+}
+
+// recalc sets the position and size of the image panel
+// in the content area by the fit mode
+func (iv *ImageView) recalc() {
+
+	if iv.tex == nil || iv.tex.Width() == 0 || iv.tex.Height() == 0 {
+		iv.image.SetVisible(false)
+		iv.texWidth, iv.texHeight = 0, 0
+		return
+	}
+	iv.texWidth = iv.tex.Width()
+	iv.texHeight = iv.tex.Height()
+	cwidth := iv.ContentWidth()
+	cheight := iv.ContentHeight()
+	width := float32(iv.texWidth)
+	height := float32(iv.texHeight)
+	switch iv.fit {
+	case ImageFitContain, ImageFitCover:
+		scale := math32.Min(cwidth/width, cheight/height)
+		if iv.fit == ImageFitCover {
+			scale = math32.Max(cwidth/width, cheight/height)
+		}
+		width *= scale
+		height *= scale
+	case ImageFitStretch:
+		width = cwidth
+		height = cheight
+	}
+	iv.image.SetPosition((cwidth-width)/2, (cheight-height)/2)
+	iv.image.SetSize(width, height)
+	iv.image.SetVisible(true)
+}
+
+// RenderSetup overrides the Material version to transfer
+// the uniforms of the color adjustments
+func (m *imageViewMaterial) RenderSetup(gs gls.IDevice) {
+
+	m.Material.RenderSetup(gs)
+	m.adjustUni.Transfer(gs)
+	m.tintUni.Transfer(gs)
+}
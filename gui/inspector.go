@@ -0,0 +1,690 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Inspector is a panel which shows editors for the exported fields of a
+// struct and, if the target is a core.INode, for the node name, visibility,
+// position, rotation and scale. When the user changes a value it is set
+// in the target and the inspector dispatches OnChange with an InspectorEvent.
+//
+// Fields may be configured with the "inspect" struct tag with comma
+// separated options:
+//
+//	inspect:"-"                  field is not shown
+//	inspect:"label=Speed"        label shown for the field
+//	inspect:"min=0,max=10"       numeric field edited with a slider
+//	inspect:"enum=Low|Mid|High"  int field edited with a drop down of names (or string field of values)
+//	inspect:"readonly"           field is shown but can't be edited
+type Inspector struct {
+	Panel                      // Embedded panel
+	styles    *InspectorStyle  // pointer to current style
+	target    interface{}      // inspected value
+	props     []*inspectorProp // properties shown
+	updating  bool             // editors being updated from the target values
+	recalcing bool             // sizes being recalculated
+	undo      *core.UndoStack  // records the changes or nil
+}
+
+// InspectorEvent is the parameter of the OnChange event of the Inspector
+type InspectorEvent struct {
+	Name  string      // path of the changed field (ex: "Light.Color")
+	Value interface{} // new value
+}
+
+// InspectorStyle describes the style of the Inspector
+type InspectorStyle struct {
+	LabelColor  math32.Color4 // field labels text color
+	HeaderColor math32.Color4 // nested struct labels text color
+	Spacing     float32       // horizontal spacing between labels and editors
+	RowSpacing  float32       // vertical spacing between rows
+	Indent      float32       // indentation of the fields of nested structs
+}
+
+// inspectorProp is a property of the inspected value with its editors
+type inspectorProp struct {
+	name    string                // path of the property
+	level   int                   // nesting level
+	label   *Label                // property label
+	header  bool                  // nested struct header without editor
+	tag     inspectorTag          // options from the struct tag
+	typ     reflect.Type          // property type
+	get     func() reflect.Value  // returns the current value
+	set     func(v reflect.Value) // sets a new value
+	editor  IPanel                // editor panel or nil
+	refresh func(v reflect.Value) // updates the editors from the value
+	resize  func(width float32)   // sets the width of the editors
+}
+
+// inspectorTag contains the options of an "inspect" struct tag
+type inspectorTag struct {
+	skip     bool     // field is not shown
+	label    string   // field label
+	readonly bool     // field can't be edited
+	hasRange bool     // min and max specified
+	min      float64  // minimum value
+	max      float64  // maximum value
+	enum     []string // enumeration names or values
+}
+
+const inspectorMaxDepth = 4 // maximum nesting level of structs
+
+var (
+	typeVector2 = reflect.TypeOf(math32.Vector2{})
+	typeVector3 = reflect.TypeOf(math32.Vector3{})
+	typeVector4 = reflect.TypeOf(math32.Vector4{})
+	typeColor   = reflect.TypeOf(math32.Color{})
+	typeColor4  = reflect.TypeOf(math32.Color4{})
+)
+
+// NewInspector creates and returns a pointer to a new inspector with the
+// specified width. Its height is adjusted to fit the editors.
+func NewInspector(width float32) *Inspector {
+
+	ins := new(Inspector)
+	ins.Panel.Initialize(width, 0)
+	ins.styles = &StyleDefault.Inspector
+	ins.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { ins.recalc() })
+	return ins
+}
+
+// SetStyles sets the style of this inspector
+func (ins *Inspector) SetStyles(s *InspectorStyle) {
+
+	ins.styles = s
+	ins.update()
+	ins.recalc()
+}
+
+// SetTarget sets the value to inspect, which must be a pointer to a
+// struct or a core.INode, and creates the editors for its properties.
+// Passing nil removes all the editors.
+func (ins *Inspector) SetTarget(target interface{}) error {
+
+	ins.DisposeChildren(true)
+	ins.props = nil
+	ins.target = nil
+	if target == nil {
+		ins.recalc()
+		return nil
+	}
+	v := reflect.ValueOf(target)
+	inode, isNode := target.(core.INode)
+	if !isNode && (v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct) {
+		ins.recalc()
+		return fmt.Errorf("inspector target must be a pointer to a struct or a core.INode")
+	}
+	ins.target = target
+	if isNode {
+		ins.addNodeProps(inode.GetNode())
+	}
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+		ins.addFields(v.Elem(), "", 0, isNode)
+	}
+	for _, p := range ins.props {
+		ins.createEditor(p)
+	}
+	ins.update()
+	ins.Refresh()
+	return nil
+}
+
+// Target returns the inspected value
+func (ins *Inspector) Target() interface{} {
+
+	return ins.target
+}
+
+// SetUndoStack sets the undo stack which records the changes of the values done
+// with this inspector, so they can be undone and redone. The consecutive changes
+// of the same property are merged. Passing nil stops recording the changes.
+func (ins *Inspector) SetUndoStack(us *core.UndoStack) {
+
+	ins.undo = us
+}
+
+// UndoStack returns the undo stack which records the changes
+// done with this inspector or nil
+func (ins *Inspector) UndoStack() *core.UndoStack {
+
+	return ins.undo
+}
+
+// Refresh updates the editors with the current values of the target,
+// which is necessary when the target is changed outside the inspector
+func (ins *Inspector) Refresh() {
+
+	ins.updating = true
+	for _, p := range ins.props {
+		if p.refresh != nil {
+			p.refresh(p.get())
+		}
+	}
+	ins.updating = false
+	ins.recalc()
+}
+
+// addNodeProps adds the properties of the specified node
+func (ins *Inspector) addNodeProps(n *core.Node) {
+
+	ins.addProp("Name", 0, inspectorTag{}, reflect.TypeOf(""),
+		func() reflect.Value { return reflect.ValueOf(n.Name()) },
+		func(v reflect.Value) { n.SetName(v.String()) })
+	ins.addProp("Visible", 0, inspectorTag{}, reflect.TypeOf(true),
+		func() reflect.Value { return reflect.ValueOf(n.Visible()) },
+		func(v reflect.Value) { n.SetVisible(v.Bool()) })
+	ins.addProp("Position", 0, inspectorTag{}, typeVector3,
+		func() reflect.Value { return reflect.ValueOf(n.Position()) },
+		func(v reflect.Value) { vec := v.Interface().(math32.Vector3); n.SetPositionVec(&vec) })
+	ins.addProp("Rotation", 0, inspectorTag{}, typeVector3,
+		func() reflect.Value { return reflect.ValueOf(n.Rotation()) },
+		func(v reflect.Value) { vec := v.Interface().(math32.Vector3); n.SetRotation(vec.X, vec.Y, vec.Z) })
+	ins.addProp("Scale", 0, inspectorTag{}, typeVector3,
+		func() reflect.Value { return reflect.ValueOf(n.Scale()) },
+		func(v reflect.Value) { vec := v.Interface().(math32.Vector3); n.SetScaleVec(&vec) })
+}
+
+// addFields adds the properties of the exported fields of the specified struct
+// value. The fields of embedded structs are added as fields of the struct,
+// unless skipEmbedded is set.
+func (ins *Inspector) addFields(sv reflect.Value, prefix string, level int, skipEmbedded bool) {
+
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		fv := sv.Field(i)
+		if sf.Anonymous {
+			if !skipEmbedded && fv.Kind() == reflect.Struct && level < inspectorMaxDepth {
+				ins.addFields(fv, prefix, level, false)
+			}
+			continue
+		}
+		tag := parseInspectorTag(sf.Tag.Get("inspect"))
+		if tag.skip {
+			continue
+		}
+		name := prefix + sf.Name
+		if fv.Kind() == reflect.Struct && !isInspectorValue(fv.Type()) {
+			if level >= inspectorMaxDepth {
+				continue
+			}
+			p := ins.addProp(name, level, tag, fv.Type(), nil, nil)
+			p.header = true
+			ins.addFields(fv, name+".", level+1, false)
+			continue
+		}
+		ins.addProp(name, level, tag, fv.Type(),
+			func() reflect.Value { return fv },
+			func(v reflect.Value) { fv.Set(v) })
+	}
+}
+
+// addProp adds a new property and returns a pointer to it
+func (ins *Inspector) addProp(name string, level int, tag inspectorTag, typ reflect.Type,
+	get func() reflect.Value, set func(reflect.Value)) *inspectorProp {
+
+	p := &inspectorProp{name: name, level: level, tag: tag, typ: typ, get: get, set: set}
+	text := tag.label
+	if text == "" {
+		text = name[strings.LastIndex(name, ".")+1:]
+	}
+	p.label = NewLabel(text)
+	ins.Panel.Add(p.label)
+	ins.props = append(ins.props, p)
+	return p
+}
+
+// changed sets the specified value in the target and dispatches OnChange
+func (ins *Inspector) changed(p *inspectorProp, v reflect.Value) {
+
+	if ins.updating || p.tag.readonly {
+		return
+	}
+	if ins.undo != nil {
+		old := reflect.New(p.typ).Elem()
+		old.Set(p.get())
+		target := ins.target
+		set := func(value interface{}) {
+			p.set(reflect.ValueOf(value))
+			if ins.target == target {
+				ins.Refresh()
+				ins.Dispatch(OnChange, &InspectorEvent{Name: p.name, Value: value})
+			}
+		}
+		p.set(v)
+		ins.undo.Record(core.NewValueCommand("Set "+p.name, target, set, old.Interface(), v.Interface()))
+	} else {
+		p.set(v)
+	}
+	ins.Dispatch(OnChange, &InspectorEvent{Name: p.name, Value: v.Interface()})
+}
+
+// createEditor creates the editors of the specified property
+func (ins *Inspector) createEditor(p *inspectorProp) {
+
+	if p.header {
+		return
+	}
+	kind := p.typ.Kind()
+	switch {
+	case kind == reflect.Bool:
+		cb := NewCheckBox("")
+		cb.Subscribe(OnChange, func(evname string, ev interface{}) { ins.changed(p, reflect.ValueOf(cb.Value())) })
+		p.editor = cb
+		p.refresh = func(v reflect.Value) { cb.SetValue(v.Bool()) }
+	case len(p.tag.enum) > 0 && (isInspectorInt(kind) || kind == reflect.String):
+		ins.createEnumEditor(p)
+	case p.tag.hasRange && (isInspectorInt(kind) || kind == reflect.Float32 || kind == reflect.Float64):
+		ins.createSliderEditor(p)
+	case isInspectorInt(kind) || kind == reflect.Float32 || kind == reflect.Float64 || kind == reflect.String:
+		ed := ins.newEdit()
+		ed.Subscribe(OnChange, func(evname string, ev interface{}) {
+			if v, ok := parseInspectorValue(ed.Text(), p.typ); ok {
+				ins.changed(p, v)
+			}
+		})
+		p.editor = ed
+		p.refresh = func(v reflect.Value) { ed.SetText(formatInspectorValue(v)) }
+		p.resize = func(width float32) { setEditWidth(ed, width) }
+	case p.typ == typeVector2 || p.typ == typeVector3 || p.typ == typeVector4:
+		ins.createVectorEditor(p)
+	case p.typ == typeColor || p.typ == typeColor4:
+		ins.createColorEditor(p)
+	default:
+		l := NewLabel(p.typ.String())
+		p.editor = l
+		p.tag.readonly = true
+	}
+	if p.editor != nil {
+		ins.Panel.Add(p.editor)
+		if p.tag.readonly {
+			setInspectorEnabled(p.editor, false)
+		}
+	}
+}
+
+// createEnumEditor creates a drop down editor for the specified property
+// with the names (for ints) or values (for strings) of its enumeration
+func (ins *Inspector) createEnumEditor(p *inspectorProp) {
+
+	dd := NewDropDown(0, NewImageLabel(""))
+	for _, name := range p.tag.enum {
+		dd.Add(NewImageLabel(name))
+	}
+	dd.Subscribe(OnChange, func(evname string, ev interface{}) {
+		pos := dd.list.ItemPosition(dd.Selected())
+		if pos < 0 {
+			return
+		}
+		v := reflect.New(p.typ).Elem()
+		if p.typ.Kind() == reflect.String {
+			v.SetString(p.tag.enum[pos])
+		} else {
+			setInspectorInt(v, int64(pos))
+		}
+		ins.changed(p, v)
+	})
+	p.editor = dd
+	p.refresh = func(v reflect.Value) {
+		pos := -1
+		if v.Kind() == reflect.String {
+			for i, name := range p.tag.enum {
+				if name == v.String() {
+					pos = i
+				}
+			}
+		} else {
+			pos = int(inspectorInt(v))
+		}
+		if pos >= 0 && pos < dd.list.Len() {
+			dd.list.setSelection(dd.list.items[pos].(*ListItem), true, true, true)
+		}
+	}
+	p.resize = func(width float32) { dd.SetWidth(width) }
+}
+
+// createSliderEditor creates a slider editor for the specified numeric
+// property with a range
+func (ins *Inspector) createSliderEditor(p *inspectorProp) {
+
+	s := NewHSlider(0, 0)
+	value := func() reflect.Value {
+		f := p.tag.min + float64(s.Value())*(p.tag.max-p.tag.min)
+		v := reflect.New(p.typ).Elem()
+		if isInspectorInt(p.typ.Kind()) {
+			setInspectorInt(v, int64(math32.Round(float32(f))))
+		} else {
+			v.SetFloat(f)
+		}
+		return v
+	}
+	s.Subscribe(OnChange, func(evname string, ev interface{}) {
+		v := value()
+		s.SetText(formatInspectorValue(v))
+		ins.changed(p, v)
+	})
+	p.editor = s
+	p.refresh = func(v reflect.Value) {
+		var f float64
+		if isInspectorInt(v.Kind()) {
+			f = float64(inspectorInt(v))
+		} else {
+			f = v.Float()
+		}
+		if p.tag.max > p.tag.min {
+			s.SetValue(float32((f - p.tag.min) / (p.tag.max - p.tag.min)))
+		}
+		s.SetText(formatInspectorValue(v))
+	}
+	p.resize = func(width float32) { s.SetSize(width, editorHeight(p.label)) }
+}
+
+// createVectorEditor creates an edit for each component of the specified vector property
+func (ins *Inspector) createVectorEditor(p *inspectorProp) {
+
+	row := NewPanel(0, 0)
+	count := p.typ.NumField()
+	edits := make([]*Edit, count)
+	for i := range edits {
+		i := i
+		ed := ins.newEdit()
+		ed.Subscribe(OnChange, func(evname string, ev interface{}) {
+			f, err := strconv.ParseFloat(strings.TrimSpace(ed.Text()), 32)
+			if err != nil {
+				return
+			}
+			v := reflect.New(p.typ).Elem()
+			v.Set(p.get())
+			v.Field(i).SetFloat(f)
+			ins.changed(p, v)
+		})
+		row.Add(ed)
+		edits[i] = ed
+	}
+	p.editor = row
+	p.refresh = func(v reflect.Value) {
+		for i, ed := range edits {
+			ed.SetText(formatInspectorValue(v.Field(i)))
+		}
+	}
+	p.resize = func(width float32) {
+		sp := ins.styles.Spacing
+		w := (width - sp*float32(count-1)) / float32(count)
+		for i, ed := range edits {
+			setEditWidth(ed, w)
+			ed.SetPosition(float32(i)*(w+sp), 0)
+		}
+		row.SetSize(width, edits[0].Height())
+	}
+}
+
+// createColorEditor creates an hexadecimal edit and a color
+// swatch for the specified color property
+func (ins *Inspector) createColorEditor(p *inspectorProp) {
+
+	row := NewPanel(0, 0)
+	ed := ins.newEdit()
+	swatch := NewPanel(0, 0)
+	swatch.SetBorders(1, 1, 1, 1)
+	swatch.SetBordersColor(&math32.Color{0.5, 0.5, 0.5})
+	row.Add(ed)
+	row.Add(swatch)
+	setSwatch := func(c math32.Color4) { swatch.SetColor4(&c) }
+	ed.Subscribe(OnChange, func(evname string, ev interface{}) {
+		c, err := parseThemeColor(strings.TrimSpace(ed.Text()), nil)
+		if err != nil {
+			return
+		}
+		setSwatch(c)
+		if p.typ == typeColor {
+			ins.changed(p, reflect.ValueOf(math32.Color{c.R, c.G, c.B}))
+		} else {
+			ins.changed(p, reflect.ValueOf(c))
+		}
+	})
+	p.editor = row
+	p.refresh = func(v reflect.Value) {
+		var c math32.Color4
+		if p.typ == typeColor {
+			col := v.Interface().(math32.Color)
+			c.FromColor(&col, 1)
+		} else {
+			c = v.Interface().(math32.Color4)
+		}
+		setSwatch(c)
+		text := fmt.Sprintf("#%02x%02x%02x", colorByte(c.R), colorByte(c.G), colorByte(c.B))
+		if p.typ == typeColor4 {
+			text += fmt.Sprintf("%02x", colorByte(c.A))
+		}
+		ed.SetText(text)
+	}
+	p.resize = func(width float32) {
+		h := ed.Height()
+		setEditWidth(ed, width-h-ins.styles.Spacing)
+		swatch.SetSize(h, h)
+		swatch.SetPosition(width-h, 0)
+		row.SetSize(width, h)
+	}
+}
+
+// newEdit creates and returns a pointer to a new edit for a property
+func (ins *Inspector) newEdit() *Edit {
+
+	ed := NewEdit(0, "")
+	ed.MaxLength = 256
+	return ed
+}
+
+// update updates the visual state of the labels
+func (ins *Inspector) update() {
+
+	for _, p := range ins.props {
+		if p.header {
+			setLabelColor(p.label, &ins.styles.HeaderColor)
+		} else {
+			setLabelColor(p.label, &ins.styles.LabelColor)
+		}
+	}
+}
+
+// recalc recalculates the positions and sizes of the labels and editors
+// and adjusts the height of the inspector to fit them
+func (ins *Inspector) recalc() {
+
+	if ins.recalcing {
+		return
+	}
+	ins.recalcing = true
+	defer func() { ins.recalcing = false }()
+
+	s := ins.styles
+	var labelWidth float32
+	for _, p := range ins.props {
+		if !p.header {
+			labelWidth = math32.Max(labelWidth, p.label.Width()+s.Indent*float32(p.level))
+		}
+	}
+	edX := labelWidth + s.Spacing
+	edWidth := math32.Max(ins.ContentWidth()-edX, 0)
+	var py float32
+	for i, p := range ins.props {
+		if i > 0 {
+			py += s.RowSpacing
+		}
+		height := p.label.Height()
+		if p.editor != nil {
+			if p.resize != nil {
+				p.resize(edWidth)
+			}
+			height = math32.Max(height, p.editor.GetPanel().Height())
+			p.editor.GetPanel().SetPosition(edX, py+(height-p.editor.GetPanel().Height())/2)
+		}
+		p.label.SetPosition(s.Indent*float32(p.level), py+(height-p.label.Height())/2)
+		py += height
+	}
+	ins.SetContentHeight(py)
+}
+
+// parseInspectorTag parses the options of an "inspect" struct tag
+func parseInspectorTag(tag string) inspectorTag {
+
+	var t inspectorTag
+	if tag == "-" {
+		t.skip = true
+		return t
+	}
+	var hasMin, hasMax bool
+	for _, opt := range strings.Split(tag, ",") {
+		kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+		switch kv[0] {
+		case "readonly":
+			t.readonly = true
+		}
+		if len(kv) < 2 {
+			continue
+		}
+		switch kv[0] {
+		case "label":
+			t.label = kv[1]
+		case "min":
+			t.min, _ = strconv.ParseFloat(kv[1], 64)
+			hasMin = true
+		case "max":
+			t.max, _ = strconv.ParseFloat(kv[1], 64)
+			hasMax = true
+		case "enum":
+			t.enum = strings.Split(kv[1], "|")
+		}
+	}
+	t.hasRange = hasMin && hasMax && t.max > t.min
+	return t
+}
+
+// isInspectorValue returns if the specified struct type is edited as a single value
+func isInspectorValue(typ reflect.Type) bool {
+
+	return typ == typeVector2 || typ == typeVector3 || typ == typeVector4 || typ == typeColor || typ == typeColor4
+}
+
+// isInspectorInt returns if the specified kind is a signed or unsigned integer
+func isInspectorInt(kind reflect.Kind) bool {
+
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// inspectorInt returns the value of the specified signed or unsigned integer
+func inspectorInt(v reflect.Value) int64 {
+
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	}
+	return v.Int()
+}
+
+// setInspectorInt sets the value of the specified signed or unsigned integer
+func setInspectorInt(v reflect.Value, i int64) {
+
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i < 0 {
+			i = 0
+		}
+		v.SetUint(uint64(i))
+	default:
+		v.SetInt(i)
+	}
+}
+
+// formatInspectorValue returns the text of the specified number or string
+func formatInspectorValue(v reflect.Value) string {
+
+	switch v.Kind() {
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', 6, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', 10, 64)
+	case reflect.String:
+		return v.String()
+	}
+	if isInspectorInt(v.Kind()) {
+		return strconv.FormatInt(inspectorInt(v), 10)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// parseInspectorValue parses the specified text as a value of the specified
+// number or string type and returns it and if it is valid
+func parseInspectorValue(text string, typ reflect.Type) (reflect.Value, bool) {
+
+	v := reflect.New(typ).Elem()
+	text = strings.TrimSpace(text)
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, typ.Bits())
+		if err != nil {
+			return v, false
+		}
+		v.SetFloat(f)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, typ.Bits())
+		if err != nil {
+			return v, false
+		}
+		v.SetUint(u)
+	default:
+		i, err := strconv.ParseInt(text, 10, typ.Bits())
+		if err != nil {
+			return v, false
+		}
+		v.SetInt(i)
+	}
+	return v, true
+}
+
+// setInspectorEnabled sets the enabled state of the specified editor and its children
+func setInspectorEnabled(ipan IPanel, state bool) {
+
+	walkPanels(ipan, func(ipan IPanel) { ipan.GetPanel().SetEnabled(state) })
+}
+
+// setEditWidth sets the width in pixels of the specified edit
+func setEditWidth(ed *Edit, width float32) {
+
+	ed.width = int(math32.Max(width, 0))
+	ed.redraw(ed.focus && ed.caretOn)
+}
+
+// editorHeight returns the height of an edit with the default
+// style and the font of the specified label
+func editorHeight(l *Label) float32 {
+
+	s := &StyleDefault.Edit.Normal
+	return l.Height() + s.Border.Top + s.Border.Bottom + s.Paddings.Top + s.Paddings.Bottom
+}
+
+// colorByte converts the specified color component from 0 to 1 to a byte
+func colorByte(c float32) uint8 {
+
+	return uint8(math32.Clamp(c, 0, 1)*255 + 0.5)
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/text"
 	"github.com/g3n/engine/texture"
+	"image"
+	"image/draw"
+	"math"
+	"strings"
 )
 
 type Label struct {
@@ -53,6 +57,7 @@ func (l *Label) initialize(msg string, font *text.Font) {
 	l.bgColor = math32.Color4{0, 0, 0, 0}
 	l.fgColor = math32.Black4
 	l.SetText(msg)
+	l.Subscribe(OnScale, func(evname string, ev interface{}) { l.SetText(l.currentText) })
 }
 
 // SetText draws the label text using the current font
@@ -65,8 +70,9 @@ func (l *Label) SetText(msg string) {
 	}
 
 	// Set font properties
+	// The text is rasterized at the scaled resolution
 	l.font.SetSize(l.fontSize)
-	l.font.SetDPI(l.fontDPI)
+	l.font.SetDPI(l.fontDPI * float64(l.scale))
 	l.font.SetLineSpacing(l.lineSpacing)
 	l.font.SetBgColor4(&l.bgColor)
 	l.font.SetFgColor4(&l.fgColor)
@@ -90,7 +96,7 @@ func (l *Label) SetText(msg string) {
 	}
 
 	// Updates label panel dimensions
-	l.Panel.SetContentSize(float32(width), float32(height))
+	l.Panel.SetContentSize(float32(width)/l.scale, float32(height)/l.scale)
 	l.currentText = str
 }
 
@@ -162,22 +168,63 @@ func (l *Label) FontSize() float64 {
 	return l.fontSize
 }
 
+// labelMark marks the characters of the first line of a label
+// from column start up to end (exclusive) with a background color
+// or an underline in the text color.
+type labelMark struct {
+	start int
+	end   int
+	under bool
+	color math32.Color4
+}
+
 // setTextCaret sets the label text and draws a caret at the
-// specified line and column.
+// specified line and column and the specified marks.
 // It is normally used by the Edit widget.
-func (l *Label) setTextCaret(msg string, mx, width, line, col int) {
+func (l *Label) setTextCaret(msg string, mx, width, line, col int, marks ...labelMark) {
 
 	// Set font properties
+	scale := float64(l.scale)
+	mx = int(float64(mx) * scale)
 	l.font.SetSize(l.fontSize)
-	l.font.SetDPI(l.fontDPI)
+	l.font.SetDPI(l.fontDPI * scale)
 	l.font.SetLineSpacing(l.lineSpacing)
 	l.font.SetBgColor4(&l.bgColor)
 	l.font.SetFgColor4(&l.fgColor)
 
-	// Create canvas and draw text
+	// Returns the horizontal pixel range of a mark
+	first := strings.SplitN(msg, "\n", 2)[0]
+	markRange := func(m *labelMark) (int, int) {
+		x1, _ := l.font.MeasureText(text.StrPrefix(first, m.start))
+		x2, _ := l.font.MeasureText(text.StrPrefix(first, m.end))
+		return mx + x1, mx + x2
+	}
+
+	// Create canvas and draw marks backgrounds, text and underlines
 	_, height := l.font.MeasureText(msg)
-	canvas := text.NewCanvas(width, height, &l.bgColor)
+	canvas := text.NewCanvas(int(float64(width)*scale), height, &l.bgColor)
+	dy := int(math.Ceil(l.fontSize * l.lineSpacing * l.fontDPI * scale / 72))
+	for i := 0; i < len(marks); i++ {
+		m := &marks[i]
+		if m.under || m.end <= m.start {
+			continue
+		}
+		x1, x2 := markRange(m)
+		bg := image.NewUniform(text.Color4NRGBA(&m.color))
+		draw.Draw(canvas.RGBA, image.Rect(x1, 0, x2, dy), bg, image.ZP, draw.Over)
+	}
 	canvas.DrawTextCaret(mx, 0, msg, l.font, line, col)
+	under := image.NewUniform(text.Color4NRGBA(&l.fgColor))
+	y := int(math.Ceil(l.fontSize*l.fontDPI*scale/72)) + 1
+	thick := int(math.Max(1, scale))
+	for i := 0; i < len(marks); i++ {
+		m := &marks[i]
+		if !m.under || m.end <= m.start {
+			continue
+		}
+		x1, x2 := markRange(m)
+		draw.Draw(canvas.RGBA, image.Rect(x1, y, x2, y+thick), under, image.ZP, draw.Over)
+	}
 
 	// Creates texture if if doesnt exist.
 	if l.tex == nil {
@@ -192,6 +239,16 @@ func (l *Label) setTextCaret(msg string, mx, width, line, col int) {
 	l.tex.SetMinFilter(gls.NEAREST)
 
 	// Updates label panel dimensions
-	l.Panel.SetContentSize(float32(width), float32(height))
+	l.Panel.SetContentSize(float32(width), float32(height)/l.scale)
 	l.currentText = msg
 }
+
+// textWidth returns the width in pixels of the specified text
+// drawn with this label font. It is normally used by the Edit widget.
+func (l *Label) textWidth(msg string) float32 {
+
+	l.font.SetSize(l.fontSize)
+	l.font.SetDPI(l.fontDPI * float64(l.scale))
+	width, _ := l.font.MeasureText(msg)
+	return float32(width) / l.scale
+}
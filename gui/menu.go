@@ -0,0 +1,623 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gui/assets"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ Menu
+ +-------------------------------+
+ | [icon] Item text     Ctrl+X   |
+ | [icon] Item text              |
+ |-------------------------------| separator
+ |        Submenu text         > |
+ +-------------------------------+
+
+****************************************/
+
+// Menu is a popup menu which may be used as a context menu
+// of panels or as a submenu of another menu item.
+// When an item is activated it dispatches OnClick and the menu
+// which was popped up dispatches OnClick with the item as parameter.
+type Menu struct {
+	Panel              // Embedded panel
+	styles *MenuStyle  // pointer to current style
+	items  []*MenuItem // menu items
+	active int         // index of the highlighted item or -1
+	parent *MenuItem   // item which opened this menu as submenu or nil
+	sub    *Menu       // opened submenu or nil
+}
+
+// MenuItem is an item of a menu which may be a separator
+type MenuItem struct {
+	Panel                 // Embedded panel
+	menu      *Menu       // menu which contains this item
+	icon      *Label      // optional icon label
+	label     *Label      // text label
+	shortcut  *Label      // optional shortcut label
+	arrow     *Label      // submenu indicator
+	submenu   *Menu       // optional submenu
+	separator bool        // item is a separator
+	Value     interface{} // application defined value
+}
+
+// MenuStyle describes the style of menus
+type MenuStyle struct {
+	Border          BorderSizes
+	Paddings        BorderSizes
+	BorderColor     math32.Color4
+	BgColor         math32.Color4
+	ItemPaddings    BorderSizes
+	FgColor         math32.Color4
+	OverBgColor     math32.Color4 // background color of the highlighted item
+	OverFgColor     math32.Color4 // text color of the highlighted item
+	DisabledColor   math32.Color4 // text color of disabled items
+	ShortcutColor   math32.Color4 // text color of the shortcuts
+	SeparatorColor  math32.Color4
+	SeparatorHeight float32
+	Spacing         float32 // horizontal spacing between the item icon, text and shortcut
+}
+
+var contextMenus = map[*Panel]*Menu{} // context menus of the panels
+
+// NewMenu creates and returns a pointer to a new empty menu
+func NewMenu() *Menu {
+
+	m := new(Menu)
+	m.Panel.Initialize(0, 0)
+	m.styles = &StyleDefault.Menu
+	m.active = -1
+	m.Panel.Subscribe(OnMouseOut, m.onMouseOut)
+	m.Panel.Subscribe(OnKeyDown, m.onKey)
+	m.Panel.Subscribe(OnCursor, m.stopEvent)
+	m.Panel.Subscribe(OnMouseDown, m.stopEvent)
+	m.Panel.Subscribe(OnMouseUp, m.stopEvent)
+	m.update()
+	return m
+}
+
+// SetStyles sets the style of this menu and its submenus
+func (m *Menu) SetStyles(ms *MenuStyle) {
+
+	m.styles = ms
+	for _, mi := range m.items {
+		if mi.submenu != nil {
+			mi.submenu.SetStyles(ms)
+		}
+	}
+	m.update()
+	m.recalc()
+}
+
+// AddItem adds a new item with the specified text at the end of
+// this menu and returns a pointer to the new item
+func (m *Menu) AddItem(text string) *MenuItem {
+
+	mi := newMenuItem(m, text, false)
+	m.items = append(m.items, mi)
+	m.Panel.Add(mi)
+	m.update()
+	m.recalc()
+	return mi
+}
+
+// AddSeparator adds a separator at the end of this menu
+func (m *Menu) AddSeparator() *MenuItem {
+
+	mi := newMenuItem(m, "", true)
+	m.items = append(m.items, mi)
+	m.Panel.Add(mi)
+	m.update()
+	m.recalc()
+	return mi
+}
+
+// AddMenu adds a new item with the specified text and submenu
+// at the end of this menu and returns a pointer to the new item
+func (m *Menu) AddMenu(text string, sub *Menu) *MenuItem {
+
+	mi := m.AddItem(text)
+	mi.SetSubmenu(sub)
+	return mi
+}
+
+// RemoveItem removes the specified item from this menu
+func (m *Menu) RemoveItem(mi *MenuItem) {
+
+	for pos, curr := range m.items {
+		if curr == mi {
+			m.closeSub()
+			copy(m.items[pos:], m.items[pos+1:])
+			m.items[len(m.items)-1] = nil
+			m.items = m.items[:len(m.items)-1]
+			m.Panel.Remove(mi)
+			m.active = -1
+			m.update()
+			m.recalc()
+			return
+		}
+	}
+}
+
+// Len returns the number of items of this menu including separators
+func (m *Menu) Len() int {
+
+	return len(m.items)
+}
+
+// ItemAt returns the item at the specified position or nil
+func (m *Menu) ItemAt(pos int) *MenuItem {
+
+	if pos < 0 || pos >= len(m.items) {
+		return nil
+	}
+	return m.items[pos]
+}
+
+// SetContextMenu sets the menu which pops up when the right mouse
+// button is pressed over the specified panel. Passing a nil menu
+// removes the context menu of the panel.
+func SetContextMenu(ipan IPanel, m *Menu) {
+
+	pan := ipan.GetPanel()
+	_, subscribed := contextMenus[pan]
+	contextMenus[pan] = m
+	if subscribed {
+		return
+	}
+	pan.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		m := contextMenus[pan]
+		if m == nil || mev.Button != window.MouseButtonRight || pan.root == nil {
+			return
+		}
+		pan.root.PopupMenu(m, mev.Xpos, mev.Ypos)
+		// Only the innermost panel with a context menu pops it up
+		pan.root.StopPropagation(StopAll)
+	})
+}
+
+// SetContextMenu sets the menu which pops up when the right mouse button
+// is pressed over the empty space of this root panel, such as the 3D scene.
+// Passing nil removes the menu.
+func (r *Root) SetContextMenu(m *Menu) {
+
+	r.contextMenu = m
+}
+
+// PopupMenu shows the specified menu at the specified screen position,
+// closing any other menu currently opened, and sets its key focus
+func (r *Root) PopupMenu(m *Menu, x, y float32) {
+
+	r.CloseMenu()
+	r.hideTooltip()
+	m.parent = nil
+	m.active = -1
+	m.update()
+	r.menu = m
+	r.Add(m)
+	m.SetRoot(r)
+	m.moveInside(x, y, x)
+	m.SetForeground()
+	r.SetKeyFocus(m)
+}
+
+// CloseMenu closes the menu currently popped up and its submenus if any
+func (r *Root) CloseMenu() {
+
+	m := r.menu
+	if m == nil {
+		return
+	}
+	r.menu = nil
+	m.closeSub()
+	r.Panel.Remove(m)
+	if r.keyFocus == IPanel(m) {
+		r.ClearKeyFocus()
+	}
+}
+
+// SetIcon sets the icon of this menu item
+func (mi *MenuItem) SetIcon(icode int) *MenuItem {
+
+	if mi.icon == nil {
+		mi.icon = NewIconLabel("")
+		mi.Panel.Add(mi.icon)
+	}
+	mi.icon.SetText(string(icode))
+	mi.icon.SetFontSize(mi.label.FontSize() * 1.2)
+	mi.menu.update()
+	mi.menu.recalc()
+	return mi
+}
+
+// SetText sets the text of this menu item
+func (mi *MenuItem) SetText(text string) *MenuItem {
+
+	mi.label.SetText(text)
+	mi.menu.recalc()
+	return mi
+}
+
+// Text returns the text of this menu item
+func (mi *MenuItem) Text() string {
+
+	return mi.label.Text()
+}
+
+// SetShortcut sets the text which shows the keyboard shortcut of
+// this menu item (ex: "Ctrl+C"). The shortcut is only displayed,
+// SetAccelerator also registers it to activate the item.
+func (mi *MenuItem) SetShortcut(text string) *MenuItem {
+
+	if mi.shortcut == nil {
+		mi.shortcut = NewLabel("")
+		mi.Panel.Add(mi.shortcut)
+	}
+	mi.shortcut.SetText(text)
+	mi.menu.update()
+	mi.menu.recalc()
+	return mi
+}
+
+// SetSubmenu sets the submenu opened by this menu item or removes it if nil
+func (mi *MenuItem) SetSubmenu(sub *Menu) *MenuItem {
+
+	mi.submenu = sub
+	if sub != nil && mi.arrow == nil {
+		mi.arrow = NewIconLabel(string(assets.ChevronRight))
+		mi.Panel.Add(mi.arrow)
+	}
+	if sub == nil && mi.arrow != nil {
+		mi.Panel.Remove(mi.arrow)
+		mi.arrow = nil
+	}
+	mi.menu.update()
+	mi.menu.recalc()
+	return mi
+}
+
+// Submenu returns the submenu of this item or nil
+func (mi *MenuItem) Submenu() *Menu {
+
+	return mi.submenu
+}
+
+// SetEnabled sets the enabled state of this menu item
+func (mi *MenuItem) SetEnabled(state bool) {
+
+	mi.Panel.SetEnabled(state)
+	mi.menu.update()
+}
+
+// IsSeparator returns if this menu item is a separator
+func (mi *MenuItem) IsSeparator() bool {
+
+	return mi.separator
+}
+
+// newMenuItem creates and returns a pointer to a new menu
+// item or separator with the specified text
+func newMenuItem(m *Menu, text string, separator bool) *MenuItem {
+
+	mi := new(MenuItem)
+	mi.Panel.Initialize(0, 0)
+	mi.menu = m
+	mi.separator = separator
+	mi.label = NewLabel(text)
+	if !separator {
+		mi.Panel.Add(mi.label)
+		mi.Panel.Subscribe(OnCursorEnter, func(evname string, ev interface{}) { m.setActive(m.indexOf(mi), false) })
+		mi.Panel.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+			if ev.(*window.MouseEvent).Button == window.MouseButtonLeft {
+				mi.activate()
+			}
+		})
+		mi.Panel.Subscribe(OnShortcut, func(evname string, ev interface{}) { mi.activate() })
+	}
+	return mi
+}
+
+// activate opens the submenu of this item or, if it has no submenu,
+// closes the popped up menu and dispatches OnClick
+func (mi *MenuItem) activate() {
+
+	if mi.separator || !mi.Enabled() {
+		return
+	}
+	m := mi.menu
+	if mi.submenu != nil {
+		m.setActive(m.indexOf(mi), true)
+		return
+	}
+	top := m
+	for top.parent != nil {
+		top = top.parent.menu
+	}
+	if m.root != nil {
+		m.root.CloseMenu()
+	}
+	mi.Dispatch(OnClick, nil)
+	top.Dispatch(OnClick, mi)
+}
+
+// indexOf returns the index of the specified item or -1
+func (m *Menu) indexOf(mi *MenuItem) int {
+
+	for pos, curr := range m.items {
+		if curr == mi {
+			return pos
+		}
+	}
+	return -1
+}
+
+// setActive highlights the item at the specified index, closing the
+// submenu of the previous active item and opening the submenu of the
+// new one. If focusSub is set the key focus is moved to the submenu.
+func (m *Menu) setActive(pos int, focusSub bool) {
+
+	if pos != m.active {
+		m.closeSub()
+		m.active = pos
+		m.update()
+	}
+	if pos < 0 || m.root == nil {
+		return
+	}
+	mi := m.items[pos]
+	if mi.submenu == nil || !mi.Enabled() {
+		return
+	}
+	if m.sub == nil {
+		sub := mi.submenu
+		sub.parent = mi
+		sub.active = -1
+		sub.update()
+		m.sub = sub
+		m.root.Add(sub)
+		sub.SetRoot(m.root)
+		// Aligns the first submenu item with this item
+		pos := m.Position()
+		y := pos.Y + m.marginSizes.Top + m.borderSizes.Top + m.paddingSizes.Top + mi.Position().Y -
+			sub.marginSizes.Top - sub.borderSizes.Top - sub.paddingSizes.Top
+		sub.moveInside(pos.X+m.Width(), y, pos.X)
+		sub.SetForeground()
+	}
+	if focusSub {
+		m.sub.setActive(m.sub.nextItem(-1, 1), false)
+		m.root.SetKeyFocus(m.sub)
+	}
+}
+
+// closeSub closes the opened submenu of this menu and its submenus
+func (m *Menu) closeSub() {
+
+	sub := m.sub
+	if sub == nil {
+		return
+	}
+	sub.closeSub()
+	m.sub = nil
+	sub.active = -1
+	if m.root != nil {
+		m.root.Panel.Remove(sub)
+		if m.root.keyFocus == IPanel(sub) {
+			m.root.SetKeyFocus(m)
+		}
+	}
+}
+
+// nextItem returns the index of the next enabled item in the specified
+// direction from the specified index or -1 if there are no enabled items
+func (m *Menu) nextItem(pos, dir int) int {
+
+	count := len(m.items)
+	for i := 0; i < count; i++ {
+		pos += dir
+		if pos < 0 {
+			pos = count - 1
+		} else if pos >= count {
+			pos = 0
+		}
+		mi := m.items[pos]
+		if !mi.separator && mi.Enabled() {
+			return pos
+		}
+	}
+	return -1
+}
+
+// moveInside sets the position of this menu to the specified screen
+// position keeping it inside the window. If the menu doesn't fit at
+// the right of x it is placed at the left of altX.
+func (m *Menu) moveInside(x, y, altX float32) {
+
+	m.recalc()
+	width, height := m.root.windowSize()
+	if x+m.Width() > width {
+		x = altX - m.Width()
+	}
+	if y+m.Height() > height {
+		y = height - m.Height()
+	}
+	m.SetPosition(math32.Max(x, 0), math32.Max(y, 0))
+}
+
+// onKey receives key events for keyboard navigation
+func (m *Menu) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	switch kev.Keycode {
+	case window.KeyDown:
+		m.setActive(m.nextItem(m.active, 1), false)
+	case window.KeyUp:
+		m.setActive(m.nextItem(m.active, -1), false)
+	case window.KeyRight:
+		if m.active >= 0 && m.items[m.active].submenu != nil {
+			m.setActive(m.active, true)
+		}
+	case window.KeyLeft:
+		if m.parent != nil {
+			m.parent.menu.closeSub()
+		}
+	case window.KeyEnter, window.KeyKPEnter:
+		if m.active >= 0 {
+			mi := m.items[m.active]
+			if mi.submenu != nil {
+				m.setActive(m.active, true)
+			} else {
+				mi.activate()
+			}
+		}
+	case window.KeyEscape:
+		if m.parent != nil {
+			m.parent.menu.closeSub()
+		} else if m.root != nil {
+			m.root.CloseMenu()
+		}
+	default:
+		return
+	}
+	if m.root != nil {
+		m.root.StopPropagation(Stop3D)
+	}
+}
+
+// onMouseOut is called when a mouse button is pressed outside of this
+// menu and closes the popped up menu if it is outside all opened menus
+func (m *Menu) onMouseOut(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	r := m.root
+	if r == nil || r.menu == nil {
+		return
+	}
+	for curr := r.menu; curr != nil; curr = curr.sub {
+		if curr.ContainsPosition(mev.Xpos, mev.Ypos) {
+			return
+		}
+	}
+	r.CloseMenu()
+}
+
+// stopEvent stops the propagation of mouse events over this menu
+// to the panels below it and to the 3D scene
+func (m *Menu) stopEvent(evname string, ev interface{}) {
+
+	if m.root != nil {
+		m.root.StopPropagation(StopAll)
+	}
+}
+
+// LostKeyFocus satisfies the IPanel interface. The menu is not
+// closed because the key focus moves between submenus.
+func (m *Menu) LostKeyFocus() {
+}
+
+// update updates the visual state of this menu and its items
+func (m *Menu) update() {
+
+	s := m.styles
+	m.SetBordersFrom(&s.Border)
+	m.SetBordersColor4(&s.BorderColor)
+	m.SetPaddingsFrom(&s.Paddings)
+	m.SetColor4(&s.BgColor)
+	for pos, mi := range m.items {
+		if mi.separator {
+			mi.SetColor4(&s.SeparatorColor)
+			continue
+		}
+		mi.SetPaddingsFrom(&s.ItemPaddings)
+		bg := &s.BgColor
+		fg := &s.FgColor
+		if !mi.Enabled() {
+			fg = &s.DisabledColor
+		} else if pos == m.active {
+			bg = &s.OverBgColor
+			fg = &s.OverFgColor
+		}
+		mi.SetColor4(bg)
+		setLabelColor(mi.label, fg)
+		setLabelColor(mi.icon, fg)
+		setLabelColor(mi.arrow, fg)
+		if mi.shortcut != nil {
+			if mi.Enabled() && pos != m.active {
+				fg = &s.ShortcutColor
+			}
+			setLabelColor(mi.shortcut, fg)
+		}
+	}
+}
+
+// setLabelColor sets the text color of the specified label if it is
+// not nil and its color is different, avoiding redrawing its text
+func setLabelColor(l *Label, color *math32.Color4) {
+
+	if l != nil && l.Color() != *color {
+		l.SetColor4(color)
+	}
+}
+
+// recalc recalculates the sizes and positions of the menu items
+func (m *Menu) recalc() {
+
+	s := m.styles
+	// Calculates the widths of the icons, texts and shortcuts columns
+	var iconWidth, textWidth, shortWidth, arrowWidth float32
+	for _, mi := range m.items {
+		if mi.separator {
+			continue
+		}
+		if mi.icon != nil {
+			iconWidth = math32.Max(iconWidth, mi.icon.Width()+s.Spacing)
+		}
+		textWidth = math32.Max(textWidth, mi.label.Width())
+		if mi.shortcut != nil {
+			shortWidth = math32.Max(shortWidth, mi.shortcut.Width()+2*s.Spacing)
+		}
+		if mi.arrow != nil {
+			arrowWidth = math32.Max(arrowWidth, mi.arrow.Width()+s.Spacing)
+		}
+	}
+	width := iconWidth + textWidth + shortWidth + arrowWidth
+
+	// Sets the items positions and sizes
+	var py float32
+	for _, mi := range m.items {
+		if mi.separator {
+			mi.SetPosition(0, py+s.SeparatorHeight)
+			mi.SetSize(width+s.ItemPaddings.Left+s.ItemPaddings.Right, 1)
+			py += 2*s.SeparatorHeight + 1
+			continue
+		}
+		height := mi.label.Height()
+		if mi.icon != nil {
+			height = math32.Max(height, mi.icon.Height())
+		}
+		if mi.arrow != nil {
+			height = math32.Max(height, mi.arrow.Height())
+		}
+		mi.SetContentSize(width, height)
+		mi.SetPosition(0, py)
+		if mi.icon != nil {
+			mi.icon.SetPosition(0, (height-mi.icon.Height())/2)
+		}
+		mi.label.SetPosition(iconWidth, (height-mi.label.Height())/2)
+		if mi.shortcut != nil {
+			mi.shortcut.SetPosition(iconWidth+textWidth+2*s.Spacing, (height-mi.shortcut.Height())/2)
+		}
+		if mi.arrow != nil {
+			mi.arrow.SetPosition(width-mi.arrow.Width(), (height-mi.arrow.Height())/2)
+		}
+		py += mi.Height()
+	}
+	m.SetContentSize(width+s.ItemPaddings.Left+s.ItemPaddings.Right, py)
+}
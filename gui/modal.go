@@ -0,0 +1,357 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// Modal is a dialog panel shown by a gui root panel over an overlay which
+// covers the window and blocks the input to the panels below it until the
+// dialog is closed. The key focus is kept inside the dialog and the focus
+// chain traversed by Tab and Shift+Tab only contains the dialog widgets.
+// The keys not used by the focused widget activate the default button with
+// Enter and the cancel button with Escape. Modals may be stacked and only
+// the top one receives the input. The panels added to the root panel after
+// a modal, as menus and tooltips, are shown over it and also receive input.
+type Modal struct {
+	overlay   Panel   // overlay covering the window which contains the dialog
+	dialog    IPanel  // dialog panel
+	root      *Root   // root panel which shows this modal
+	focus     IPanel  // key focus panel before this modal was shown
+	defBtn    *Button // button clicked by Enter or nil
+	cancelBtn *Button // button clicked by Escape or nil
+	closed    bool    // closed flag
+}
+
+// ModalStyle describes the style of the modal dialogs overlay
+type ModalStyle struct {
+	OverlayColor math32.Color4
+}
+
+const modalSpacing = 8 // spacing between the panels of the canned dialogs
+
+// ShowModal shows the specified dialog panel centered in the window over
+// the other panels of this root panel, blocking their input until the
+// returned modal is closed. The key focus is set to the first dialog widget
+// which accepts it.
+func (r *Root) ShowModal(dialog IPanel) *Modal {
+
+	m := new(Modal)
+	m.root = r
+	m.dialog = dialog
+	m.focus = r.keyFocus
+	width, height := r.windowSize()
+	m.overlay.Initialize(width, height)
+	m.overlay.SetColor4(&StyleDefault.Modal.OverlayColor)
+	m.overlay.Subscribe(OnMouseDown, m.stopEvent)
+	m.overlay.Subscribe(OnMouseUp, m.stopEvent)
+	m.overlay.Subscribe(OnCursor, m.stopEvent)
+	m.overlay.Subscribe(window.OnWindowSize, func(evname string, ev interface{}) {
+		m.overlay.SetSize(r.windowSize())
+	})
+	pan := dialog.GetPanel()
+	pan.SetPosition((width-pan.Width())/2, (height-pan.Height())/2)
+	m.overlay.Add(dialog)
+
+	r.SetMouseFocus(nil)
+	r.SetScrollFocus(nil)
+	r.modals = append(r.modals, m)
+	r.Add(&m.overlay)
+	m.overlay.SetForeground()
+	m.FocusNext(false)
+	return m
+}
+
+// TopModal returns the modal which currently receives the input or nil
+func (r *Root) TopModal() *Modal {
+
+	if len(r.modals) == 0 {
+		return nil
+	}
+	return r.modals[len(r.modals)-1]
+}
+
+// modalAllows returns if the specified panel may receive the input
+// with the modals currently shown by this root panel
+func (r *Root) modalAllows(ipan IPanel) bool {
+
+	m := r.TopModal()
+	if m == nil {
+		return true
+	}
+	// Finds the child of the root panel which contains the panel
+	node := ipan.GetNode()
+	for node.Parent() != nil && node.Parent() != r && node.Parent() != &r.Panel {
+		node = node.Parent().GetNode()
+	}
+	if node.Parent() == nil {
+		return true
+	}
+	// Only the top modal and the children added after it are allowed
+	for _, child := range r.Children() {
+		if child == &m.overlay {
+			return true
+		}
+		if child.GetNode() == node {
+			return false
+		}
+	}
+	return true
+}
+
+// modalChildren returns the children of this root panel which may
+// receive the mouse input with the modals currently shown
+func (r *Root) modalChildren() []core.INode {
+
+	children := r.Children()
+	m := r.TopModal()
+	if m == nil {
+		return children
+	}
+	for i, child := range children {
+		if child == &m.overlay {
+			return children[i:]
+		}
+	}
+	return children
+}
+
+// Dialog returns the dialog panel of this modal
+func (m *Modal) Dialog() IPanel {
+
+	return m.dialog
+}
+
+// SetDefaultButton sets the button clicked when Enter is pressed
+// and not used by the focused widget
+func (m *Modal) SetDefaultButton(b *Button) {
+
+	m.defBtn = b
+}
+
+// SetCancelButton sets the button clicked when Escape is pressed
+// and not used by the focused widget
+func (m *Modal) SetCancelButton(b *Button) {
+
+	m.cancelBtn = b
+}
+
+// Close closes this modal removing its dialog from the root panel
+// and restores the key focus the root panel had before it was shown
+func (m *Modal) Close() {
+
+	if m.closed {
+		return
+	}
+	m.closed = true
+	r := m.root
+	top := r.TopModal() == m
+	for i, curr := range r.modals {
+		if curr == m {
+			r.modals = append(r.modals[:i], r.modals[i+1:]...)
+			break
+		}
+	}
+	r.SetMouseFocus(nil)
+	r.SetScrollFocus(nil)
+	r.Panel.Remove(&m.overlay)
+	m.overlay.Remove(m.dialog)
+	if top {
+		focus := m.focus
+		if focus != nil && !r.modalAllows(focus) {
+			focus = nil
+		}
+		if focus == nil {
+			r.SetKeyFocus(nil)
+		} else {
+			setKeyFocus(r, focus)
+		}
+	}
+}
+
+// Closed returns if this modal was closed
+func (m *Modal) Closed() bool {
+
+	return m.closed
+}
+
+// FocusNext moves the key focus to the next or previous widget of the dialog
+// which accepts it, starting with the first or last if none is focused
+func (m *Modal) FocusNext(reverse bool) {
+
+	if m.root.TopModal() == m {
+		m.root.FocusNext(reverse)
+	}
+}
+
+// onKey receives the key events not used by the focused widget
+// while this is the top modal
+func (m *Modal) onKey(evname string, ev interface{}) {
+
+	if evname != OnKeyDown {
+		return
+	}
+	kev := ev.(*window.KeyEvent)
+	switch kev.Keycode {
+	case window.KeyEnter, window.KeyKPEnter:
+		if m.defBtn != nil && m.defBtn.Enabled() {
+			m.defBtn.Dispatch(OnClick, nil)
+		}
+	case window.KeyEscape:
+		if m.cancelBtn != nil && m.cancelBtn.Enabled() {
+			m.cancelBtn.Dispatch(OnClick, nil)
+		} else if m.cancelBtn == nil {
+			m.dialog.GetPanel().Dispatch(OnCancel, nil)
+			m.Close()
+		}
+	}
+}
+
+// stopEvent stops the propagation of the mouse events over the overlay
+func (m *Modal) stopEvent(evname string, ev interface{}) {
+
+	m.root.StopPropagation(StopAll)
+}
+
+// focusable returns if the specified panel is a widget which accepts the key focus
+func focusable(ipan IPanel) bool {
+
+	switch ipan.(type) {
+	case *Edit, *TextEdit, *Button, *CheckRadio, *Slider, *List, *Table, *VirtualList, *VirtualTree:
+		return true
+	}
+	return false
+}
+
+// setKeyFocus sets the key focus of the specified root panel to the specified
+// panel, which gains the visual state of the focused widgets if it has one
+func setKeyFocus(r *Root, ipan IPanel) {
+
+	ipan.SetRoot(r)
+	switch w := ipan.(type) {
+	case *Edit:
+		w.focusIn()
+	case *TextEdit:
+		w.focusIn()
+	default:
+		r.SetKeyFocus(ipan)
+	}
+}
+
+// MessageBox shows a modal dialog with the specified title and message and
+// an OK button which closes it and calls the specified function if not nil
+func MessageBox(r *Root, title, msg string, cb func()) *Modal {
+
+	d, m := newModalDialog(r, title, msg, nil, "OK")
+	m.defBtn.Subscribe(OnClick, func(evname string, ev interface{}) {
+		m.Close()
+		if cb != nil {
+			cb()
+		}
+	})
+	d.Subscribe(OnCancel, func(evname string, ev interface{}) {
+		if cb != nil {
+			cb()
+		}
+	})
+	return m
+}
+
+// Confirm shows a modal dialog with the specified title and message and
+// OK and Cancel buttons which close it and call the specified function
+// informing if it was accepted
+func Confirm(r *Root, title, msg string, cb func(ok bool)) *Modal {
+
+	d, m := newModalDialog(r, title, msg, nil, "OK", "Cancel")
+	m.defBtn.Subscribe(OnClick, func(evname string, ev interface{}) {
+		m.Close()
+		cb(true)
+	})
+	m.cancelBtn.Subscribe(OnClick, func(evname string, ev interface{}) {
+		m.Close()
+		cb(false)
+	})
+	d.Subscribe(OnCancel, func(evname string, ev interface{}) { cb(false) })
+	return m
+}
+
+// Prompt shows a modal dialog with the specified title and message and an
+// edit with the specified initial value, with OK and Cancel buttons which
+// close it and call the specified function with the edit text and informing
+// if it was accepted
+func Prompt(r *Root, title, msg, value string, cb func(value string, ok bool)) *Modal {
+
+	ed := NewEdit(240, "")
+	ed.SetText(value)
+	d, m := newModalDialog(r, title, msg, ed, "OK", "Cancel")
+	ed.SelectAll()
+	m.defBtn.Subscribe(OnClick, func(evname string, ev interface{}) {
+		m.Close()
+		cb(ed.Text(), true)
+	})
+	m.cancelBtn.Subscribe(OnClick, func(evname string, ev interface{}) {
+		m.Close()
+		cb(ed.Text(), false)
+	})
+	d.Subscribe(OnCancel, func(evname string, ev interface{}) { cb(ed.Text(), false) })
+	return m
+}
+
+// newModalDialog creates a dialog window with the specified title, message,
+// optional extra panel and buttons and shows it as a modal of the specified
+// root panel. The first button is the default button and the second,
+// if specified, is the cancel button.
+func newModalDialog(r *Root, title, msg string, extra IPanel, buttons ...string) (*Window, *Modal) {
+
+	w := NewWindow(0, 0)
+	w.SetTitle(title)
+	label := NewLabel(msg)
+	w.Add(label)
+	width := label.Width()
+	y := label.Height() + modalSpacing
+	if extra != nil {
+		pan := extra.GetPanel()
+		pan.SetPosition(0, y)
+		w.Add(extra)
+		width = math32.Max(width, pan.Width())
+		y += pan.Height() + modalSpacing
+	}
+
+	// Buttons aligned to the right with the same width
+	btns := make([]*Button, len(buttons))
+	bwidth := float32(0)
+	for i, text := range buttons {
+		btns[i] = NewButton(text)
+		bwidth = math32.Max(bwidth, btns[i].Width())
+	}
+	bwidth = math32.Max(bwidth, 72)
+	total := float32(len(btns))*(bwidth+modalSpacing) - modalSpacing
+	width = math32.Max(width, total)
+	x := width - total
+	bheight := float32(0)
+	for _, b := range btns {
+		b.SetWidth(bwidth)
+		b.SetPosition(x, y)
+		w.Add(b)
+		x += bwidth + modalSpacing
+		bheight = b.Height()
+	}
+	height := y + bheight + w.title.height
+	w.SetContentSize(width+2*modalSpacing, height+2*modalSpacing)
+	w.client.SetPaddings(modalSpacing, modalSpacing, modalSpacing, modalSpacing)
+
+	m := r.ShowModal(w)
+	m.defBtn = btns[0]
+	if len(btns) > 1 {
+		m.cancelBtn = btns[1]
+	}
+	if extra == nil {
+		setKeyFocus(r, m.defBtn)
+	}
+	return w, m
+}
@@ -0,0 +1,237 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/window"
+)
+
+// NavAction is an action of the keyboard or gamepad navigation of a gui
+type NavAction int
+
+// Navigation actions
+const (
+	NavNone     NavAction = iota // no action
+	NavUp                        // moves the key focus up
+	NavDown                      // moves the key focus down
+	NavLeft                      // moves the key focus left
+	NavRight                     // moves the key focus right
+	NavActivate                  // activates the focused widget as the Enter key
+	NavCancel                    // cancels as the Escape key
+	NavNext                      // moves the key focus to the next widget as the Tab key
+	NavPrev                      // moves the key focus to the previous widget as Shift+Tab
+)
+
+// navActionKeys are the keys sent to the focused widget by the navigation actions
+var navActionKeys = map[NavAction]Shortcut{
+	NavUp:       {window.KeyUp, 0},
+	NavDown:     {window.KeyDown, 0},
+	NavLeft:     {window.KeyLeft, 0},
+	NavRight:    {window.KeyRight, 0},
+	NavActivate: {window.KeyEnter, 0},
+	NavCancel:   {window.KeyEscape, 0},
+	NavNext:     {window.KeyTab, 0},
+	NavPrev:     {window.KeyTab, window.ModShift},
+}
+
+// SetNavigation sets the directional navigation mode used to drive the gui
+// without a mouse (default false). In this mode the navigation keys which are
+// not used by the focused widget perform their actions, as the arrow keys
+// which move the key focus to the nearest widget in their direction and show
+// the focus ring around it. Other keys may be mapped to actions with
+// SetNavigationKey and gamepad input may be mapped to actions with Navigate.
+func (r *Root) SetNavigation(state bool) {
+
+	r.navigation = state
+}
+
+// Navigation returns if the directional navigation mode is set
+func (r *Root) Navigation() bool {
+
+	return r.navigation
+}
+
+// SetNavigationKey maps the specified key combination to the specified
+// action in the navigation mode. NavNone removes the mapping.
+// The arrow keys are mapped to the directions by default.
+func (r *Root) SetNavigationKey(sc Shortcut, action NavAction) {
+
+	sc.Mods &= shortcutMods
+	if action == NavNone {
+		delete(r.navKeys, sc)
+		return
+	}
+	r.navKeys[sc] = action
+}
+
+// Navigate performs the specified navigation action, as mapped from the
+// buttons of a gamepad, whether or not the navigation mode is set.
+// The key of the action is first sent to the focused widget, so the
+// directions may change the value of a slider or the selection of a list,
+// and the directions not used by it move the key focus.
+// It returns if the action was used or blocked by a modal dialog.
+func (r *Root) Navigate(action NavAction) bool {
+
+	sc, ok := navActionKeys[action]
+	if !ok {
+		return false
+	}
+	kev := window.KeyEvent{Keycode: sc.Key, Mods: sc.Mods}
+	used := r.processKey(OnKeyDown, &kev, action)
+	r.processKey(OnKeyUp, &kev, action)
+	return used
+}
+
+// FocusDirection moves the key focus to the widget which is the neighbor of
+// the focused widget in the specified direction, as set by SetFocusNeighbor,
+// or to the nearest widget in that direction, and shows the focus ring around
+// it. The first widget of the focus chain is focused if none is focused.
+// It returns if the key focus was moved.
+func (r *Root) FocusDirection(dir NavAction) bool {
+
+	list := r.focusList()
+	if len(list) == 0 {
+		return false
+	}
+	var cur IPanel
+	for _, ipan := range list {
+		if r.HasKeyFocus(ipan) {
+			cur = ipan
+			break
+		}
+	}
+	var next IPanel
+	if cur == nil {
+		next = list[0]
+	} else {
+		if dir >= NavUp && dir <= NavRight {
+			next = cur.GetPanel().focusNeighbors[dir-NavUp]
+		}
+		if next == nil || !containsPanel(list, next) {
+			next = nearestInDirection(cur, list, dir)
+		}
+	}
+	if next == nil {
+		return false
+	}
+	setKeyFocus(r, next)
+	r.showFocusRing()
+	return true
+}
+
+// SetFocusNeighbor sets the panel which receives the key focus when it is
+// moved from this panel in the specified direction, overriding the nearest
+// widget in that direction. The neighbor is skipped while it is not in the
+// focus chain. A nil panel removes the neighbor.
+// The direction must be NavUp, NavDown, NavLeft or NavRight.
+func (p *Panel) SetFocusNeighbor(dir NavAction, ipan IPanel) {
+
+	if dir >= NavUp && dir <= NavRight {
+		p.focusNeighbors[dir-NavUp] = ipan
+	}
+}
+
+// FocusNeighbor returns the neighbor of this panel in the specified direction or nil
+func (p *Panel) FocusNeighbor(dir NavAction) IPanel {
+
+	if dir >= NavUp && dir <= NavRight {
+		return p.focusNeighbors[dir-NavUp]
+	}
+	return nil
+}
+
+// navigateKey performs the navigation action mapped to the specified
+// key event not used by the focused widget and returns if it was used
+func (r *Root) navigateKey(kev *window.KeyEvent) bool {
+
+	sc := Shortcut{kev.Keycode, kev.Mods & shortcutMods}
+	action, ok := r.navKeys[sc]
+	if !ok {
+		return false
+	}
+	// Other keys are translated to the key of the action
+	if navActionKeys[action] != sc {
+		return r.Navigate(action)
+	}
+	switch action {
+	case NavUp, NavDown, NavLeft, NavRight:
+		return r.FocusDirection(action)
+	}
+	return false
+}
+
+// nearestInDirection returns the panel of the specified list nearest to the
+// specified panel in the specified direction or nil if there is none.
+// The distance between the centers along the direction is increased by the
+// gap between the panels across it, preferring the panels in the same row or
+// column.
+func nearestInDirection(cur IPanel, list []IPanel, dir NavAction) IPanel {
+
+	c := panelRect(cur)
+	var best IPanel
+	var bestScore float32
+	for _, ipan := range list {
+		if ipan == cur {
+			continue
+		}
+		o := panelRect(ipan)
+		var dist, gap float32
+		switch dir {
+		case NavRight:
+			dist = (o.X + o.Width/2) - (c.X + c.Width/2)
+			gap = rangeGap(c.Y, c.Y+c.Height, o.Y, o.Y+o.Height)
+		case NavLeft:
+			dist = (c.X + c.Width/2) - (o.X + o.Width/2)
+			gap = rangeGap(c.Y, c.Y+c.Height, o.Y, o.Y+o.Height)
+		case NavDown:
+			dist = (o.Y + o.Height/2) - (c.Y + c.Height/2)
+			gap = rangeGap(c.X, c.X+c.Width, o.X, o.X+o.Width)
+		case NavUp:
+			dist = (c.Y + c.Height/2) - (o.Y + o.Height/2)
+			gap = rangeGap(c.X, c.X+c.Width, o.X, o.X+o.Width)
+		default:
+			return nil
+		}
+		if dist <= 0 {
+			continue
+		}
+		score := dist + 2*gap
+		if best == nil || score < bestScore {
+			best = ipan
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// panelRect returns the rectangle of the specified panel in absolute pixels
+func panelRect(ipan IPanel) Rect {
+
+	pan := ipan.GetPanel()
+	return Rect{X: pan.pospix.X, Y: pan.pospix.Y, Width: pan.width, Height: pan.height}
+}
+
+// rangeGap returns the distance between the specified ranges or 0 if they overlap
+func rangeGap(min1, max1, min2, max2 float32) float32 {
+
+	if min2 > max1 {
+		return min2 - max1
+	}
+	if min1 > max2 {
+		return min1 - max2
+	}
+	return 0
+}
+
+// containsPanel returns if the specified list contains the specified panel
+func containsPanel(list []IPanel, ipan IPanel) bool {
+
+	for _, item := range list {
+		if item == ipan {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// nineSlice is the nine-slice background of a panel
+type nineSlice struct {
+	tex      *texture.Texture2D // sliced texture
+	insets   BorderSizes        // sizes in pixels of the texture borders
+	texUni   gls.Uniform4f      // insets in texture coordinates
+	boxUni   gls.Uniform4f      // insets relative to the area inside the margins
+	colorUni gls.Uniform4f      // color multiplied by the texture color
+}
+
+// SetNineSlice sets the specified texture as the nine-slice background of
+// this panel, which is drawn in the area inside the margins instead of the
+// border, padding and content colors. The insets are the sizes in pixels of
+// the texture borders. The texture corners are drawn with their size, its
+// edges are stretched along the panel sides and its center is stretched to
+// fill the remaining area, so skins are scaled without distorting corners.
+// The panel keeps its own reference to the texture.
+// A nil texture removes the nine-slice background.
+// Panels whose material has other textures, as images, can not be sliced.
+func (p *Panel) SetNineSlice(tex *texture.Texture2D, insets *BorderSizes) {
+
+	if p.slice != nil {
+		p.mat.RemoveTexture(p.slice.tex)
+		p.slice.tex.Dispose()
+	}
+	if tex == nil {
+		if p.slice != nil {
+			p.slice = nil
+			p.mat.SetShader("shaderPanel")
+		}
+		return
+	}
+	if p.slice == nil {
+		p.slice = new(nineSlice)
+		p.slice.texUni.Init("NineSlice")
+		p.slice.boxUni.Init("NineSliceBox")
+		p.slice.colorUni.Init("NineSliceColor")
+		p.slice.colorUni.Set(1, 1, 1, 1)
+		p.mat.SetShader("shaderPanelNineSlice")
+	}
+	p.slice.tex = tex
+	p.slice.insets = *insets
+	p.mat.AddTexture(tex.Incref())
+}
+
+// NineSlice returns the nine-slice background texture of this
+// panel and the sizes of its borders or nil if not set
+func (p *Panel) NineSlice() (*texture.Texture2D, BorderSizes) {
+
+	if p.slice == nil {
+		return nil, BorderSizes{}
+	}
+	return p.slice.tex, p.slice.insets
+}
+
+// SetNineSliceColor sets the color multiplied by the colors of the
+// nine-slice background texture to tint it (default white)
+func (p *Panel) SetNineSliceColor(color *math32.Color4) {
+
+	if p.slice != nil {
+		p.slice.colorUni.SetColor4(color)
+	}
+}
+
+// NineSliceColor returns the color which tints the nine-slice background
+func (p *Panel) NineSliceColor() math32.Color4 {
+
+	if p.slice == nil {
+		return math32.Color4{1, 1, 1, 1}
+	}
+	return p.slice.colorUni.GetColor4()
+}
+
+// update sets the insets uniforms from the current sizes
+// of the specified panel and of the texture
+func (s *nineSlice) update(p *Panel) {
+
+	twidth := float32(s.tex.Width())
+	theight := float32(s.tex.Height())
+	if twidth > 0 && theight > 0 {
+		s.texUni.Set(s.insets.Left/twidth, s.insets.Top/theight, s.insets.Right/twidth, s.insets.Bottom/theight)
+	}
+
+	// The borders are reduced proportionally if the panel is smaller than them
+	width := p.width - p.marginSizes.Left - p.marginSizes.Right
+	height := p.height - p.marginSizes.Top - p.marginSizes.Bottom
+	left, right := sliceInsets(s.insets.Left, s.insets.Right, width)
+	top, bottom := sliceInsets(s.insets.Top, s.insets.Bottom, height)
+	s.boxUni.Set(left, top, right, bottom)
+}
+
+// transfer transfers the uniforms of the nine-slice background
+func (s *nineSlice) transfer(gs gls.IDevice) {
+
+	s.texUni.Transfer(gs)
+	s.boxUni.Transfer(gs)
+	s.colorUni.Transfer(gs)
+}
+
+// sliceInsets returns the specified insets in pixels relative to the
+// specified size reduced proportionally to fit inside it
+func sliceInsets(min, max, size float32) (float32, float32) {
+
+	if size <= 0 {
+		return 0, 0
+	}
+	if min+max > size {
+		scale := size / (min + max)
+		min *= scale
+		max *= scale
+	}
+	return min / size, max / size
+}
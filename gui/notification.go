@@ -0,0 +1,443 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+	"time"
+)
+
+// Notifier shows notifications, as in game messages or the results of editor
+// operations, stacked in a corner of the window of a gui root panel.
+// Each notification is closed when its timeout expires, which is paused while
+// the cursor is over it, or when it is clicked after calling its action.
+// Notifications beyond the maximum number shown wait until others are closed.
+type Notifier struct {
+	root       *Root              // root panel where the notifications are shown
+	styles     *NotificationStyle // pointer to current style
+	corner     NotifierCorner     // window corner of the notifications
+	maxVisible int                // maximum number of notifications shown
+	shown      []*Notification    // notifications shown from the corner
+	queue      []*Notification    // notifications waiting to be shown
+}
+
+// Notification is a notification panel shown by a Notifier
+type Notification struct {
+	Panel                          // Embedded panel
+	notifier *Notifier             // notifier which shows this notification
+	level    NotificationLevel     // severity level
+	icon     *Label                // level icon
+	title    *Label                // title label or nil
+	text     *Label                // text label
+	bar      Panel                 // progress bar background
+	fill     Panel                 // progress bar filled part
+	progress float32               // progress from 0 to 1 or negative if not shown
+	timeout  time.Duration         // time shown or 0 to show until closed
+	timer    int                   // id of the timeout timer
+	action   func(n *Notification) // function called when clicked or nil
+	closed   bool                  // closed flag
+}
+
+// NotificationLevel is the severity level of a notification
+type NotificationLevel int
+
+// Notification severity levels
+const (
+	NotificationInfo NotificationLevel = iota
+	NotificationSuccess
+	NotificationWarning
+	NotificationError
+)
+
+// NotifierCorner is the window corner where a Notifier stacks its notifications
+type NotifierCorner int
+
+// Notifier corners
+const (
+	NotifyTopRight NotifierCorner = iota
+	NotifyTopLeft
+	NotifyBottomRight
+	NotifyBottomLeft
+)
+
+// NotificationStyle describes the style of the notifications.
+// The colors and icons of the levels are indexed by NotificationLevel
+// and the level color is used for the border, icon and progress bar.
+type NotificationStyle struct {
+	Border         BorderSizes
+	Paddings       BorderSizes
+	BgColor        math32.Color4
+	FgColor        math32.Color4
+	TitleColor     math32.Color4
+	ProgressColor  math32.Color4 // background of the progress bar
+	LevelColors    [4]math32.Color4
+	Icons          [4]int
+	Width          float32 // width of the notifications
+	Margin         float32 // distance from the window borders
+	Spacing        float32 // distance between notifications
+	ProgressHeight float32
+}
+
+const notificationGap = 4 // gap between the parts of a notification
+
+// DefaultNotificationTimeout is the time notifications are shown by default
+var DefaultNotificationTimeout = 5 * time.Second
+
+// NewNotifier creates and returns a pointer to a new notifier which
+// shows notifications in the top right corner of the window of the
+// specified gui root panel
+func NewNotifier(root *Root) *Notifier {
+
+	nt := new(Notifier)
+	nt.root = root
+	nt.styles = &StyleDefault.Notification
+	nt.maxVisible = 5
+	return nt
+}
+
+// SetStyles sets the style of the notifications overriding the default style
+func (nt *Notifier) SetStyles(s *NotificationStyle) {
+
+	nt.styles = s
+	for _, n := range nt.shown {
+		n.update()
+	}
+	for _, n := range nt.queue {
+		n.update()
+	}
+	nt.recalc()
+}
+
+// SetCorner sets the window corner where the notifications are stacked
+func (nt *Notifier) SetCorner(corner NotifierCorner) {
+
+	nt.corner = corner
+	nt.recalc()
+}
+
+// Corner returns the window corner where the notifications are stacked
+func (nt *Notifier) Corner() NotifierCorner {
+
+	return nt.corner
+}
+
+// SetMaxVisible sets the maximum number of notifications shown at the same time
+func (nt *Notifier) SetMaxVisible(max int) {
+
+	if max < 1 {
+		max = 1
+	}
+	nt.maxVisible = max
+	nt.showQueued()
+	nt.recalc()
+}
+
+// Notify shows a new notification with the specified level, title and text
+// and returns its pointer. The title may be empty.
+// The notification is closed after the default timeout.
+func (nt *Notifier) Notify(level NotificationLevel, title, text string) *Notification {
+
+	n := new(Notification)
+	n.notifier = nt
+	n.level = level
+	n.progress = -1
+	n.timeout = DefaultNotificationTimeout
+	n.Panel.Initialize(0, 0)
+	n.icon = NewIconLabel(" ")
+	n.icon.SetFontSize(20)
+	n.Panel.Add(n.icon)
+	if title != "" {
+		n.title = new(Label)
+		n.title.initialize(title, StyleDefault.FontBold)
+		n.Panel.Add(n.title)
+	}
+	n.text = NewLabel(text)
+	n.Panel.Add(n.text)
+	n.bar.Initialize(0, 0)
+	n.bar.SetVisible(false)
+	n.Panel.Add(&n.bar)
+	n.fill.Initialize(0, 0)
+	n.bar.Add(&n.fill)
+
+	n.Panel.Subscribe(OnMouseDown, n.onMouse)
+	n.Panel.Subscribe(OnCursorEnter, n.onCursor)
+	n.Panel.Subscribe(OnCursorLeave, n.onCursor)
+	n.Panel.Subscribe(window.OnWindowSize, func(evname string, ev interface{}) { nt.recalc() })
+	n.update()
+
+	nt.queue = append(nt.queue, n)
+	nt.showQueued()
+	nt.recalc()
+	return n
+}
+
+// Info shows a new information notification with the specified text
+func (nt *Notifier) Info(text string) *Notification {
+
+	return nt.Notify(NotificationInfo, "", text)
+}
+
+// Success shows a new success notification with the specified text
+func (nt *Notifier) Success(text string) *Notification {
+
+	return nt.Notify(NotificationSuccess, "", text)
+}
+
+// Warning shows a new warning notification with the specified text
+func (nt *Notifier) Warning(text string) *Notification {
+
+	return nt.Notify(NotificationWarning, "", text)
+}
+
+// Error shows a new error notification with the specified text
+func (nt *Notifier) Error(text string) *Notification {
+
+	return nt.Notify(NotificationError, "", text)
+}
+
+// Len returns the number of notifications shown or waiting
+func (nt *Notifier) Len() int {
+
+	return len(nt.shown) + len(nt.queue)
+}
+
+// Clear closes all the notifications
+func (nt *Notifier) Clear() {
+
+	for _, n := range nt.queue {
+		n.closed = true
+	}
+	nt.queue = nil
+	for len(nt.shown) > 0 {
+		nt.shown[0].Close()
+	}
+}
+
+// showQueued shows the waiting notifications which fit
+func (nt *Notifier) showQueued() {
+
+	for len(nt.queue) > 0 && len(nt.shown) < nt.maxVisible {
+		n := nt.queue[0]
+		nt.queue = nt.queue[1:]
+		nt.shown = append(nt.shown, n)
+		nt.root.Add(n)
+		n.SetForeground()
+		n.startTimer()
+	}
+}
+
+// recalc sets the positions of the notifications shown
+func (nt *Notifier) recalc() {
+
+	s := nt.styles
+	width, height := nt.root.windowSize()
+	y := s.Margin
+	for _, n := range nt.shown {
+		x := s.Margin
+		if nt.corner == NotifyTopRight || nt.corner == NotifyBottomRight {
+			x = width - s.Margin - n.Width()
+		}
+		if nt.corner == NotifyTopRight || nt.corner == NotifyTopLeft {
+			n.SetPosition(x, y)
+		} else {
+			n.SetPosition(x, height-y-n.Height())
+		}
+		y += n.Height() + s.Spacing
+	}
+}
+
+// remove removes the specified notification from the notifier
+func (nt *Notifier) remove(n *Notification) {
+
+	for i, curr := range nt.shown {
+		if curr == n {
+			copy(nt.shown[i:], nt.shown[i+1:])
+			nt.shown[len(nt.shown)-1] = nil
+			nt.shown = nt.shown[:len(nt.shown)-1]
+			nt.root.Panel.Remove(n)
+			break
+		}
+	}
+	for i, curr := range nt.queue {
+		if curr == n {
+			nt.queue = append(nt.queue[:i], nt.queue[i+1:]...)
+			break
+		}
+	}
+	nt.showQueued()
+	nt.recalc()
+}
+
+//
+// Notification methods
+//
+
+// Level returns the severity level of this notification
+func (n *Notification) Level() NotificationLevel {
+
+	return n.level
+}
+
+// SetText sets the text of this notification
+func (n *Notification) SetText(text string) {
+
+	n.text.SetText(text)
+	n.recalc()
+	n.notifier.recalc()
+}
+
+// Text returns the text of this notification
+func (n *Notification) Text() string {
+
+	return n.text.Text()
+}
+
+// SetProgress sets the progress shown by the progress bar of this
+// notification from 0 to 1. A negative value hides the progress bar.
+func (n *Notification) SetProgress(progress float32) {
+
+	n.progress = math32.Min(progress, 1)
+	n.recalc()
+	n.notifier.recalc()
+}
+
+// Progress returns the progress shown by this notification
+// or a negative value if the progress bar is hidden
+func (n *Notification) Progress() float32 {
+
+	return n.progress
+}
+
+// SetTimeout sets the time this notification is shown after it appears
+// or after the cursor leaves it. Zero shows it until it is clicked or closed.
+// The timeout is restarted if the notification is already shown.
+func (n *Notification) SetTimeout(timeout time.Duration) {
+
+	n.timeout = timeout
+	if n.timer != 0 || n.Parent() != nil {
+		n.startTimer()
+	}
+}
+
+// SetAction sets the function called when this notification is clicked,
+// before it is closed
+func (n *Notification) SetAction(action func(n *Notification)) {
+
+	n.action = action
+}
+
+// Close closes this notification
+func (n *Notification) Close() {
+
+	if n.closed {
+		return
+	}
+	n.closed = true
+	n.stopTimer()
+	n.notifier.remove(n)
+}
+
+// Closed returns if this notification was closed
+func (n *Notification) Closed() bool {
+
+	return n.closed
+}
+
+// startTimer starts or restarts the timeout timer
+func (n *Notification) startTimer() {
+
+	n.stopTimer()
+	if n.timeout <= 0 || n.closed {
+		return
+	}
+	n.timer = n.notifier.root.SetTimeout(n.timeout, nil, func(arg interface{}) {
+		n.timer = 0
+		n.Close()
+	})
+}
+
+// stopTimer stops the timeout timer
+func (n *Notification) stopTimer() {
+
+	if n.timer != 0 {
+		n.notifier.root.ClearTimeout(n.timer)
+		n.timer = 0
+	}
+}
+
+// onMouse receives subscribed mouse button events
+func (n *Notification) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	if n.action != nil {
+		n.action(n)
+	}
+	n.Close()
+	n.notifier.root.StopPropagation(StopAll)
+}
+
+// onCursor receives subscribed cursor events and
+// pauses the timeout while the cursor is over the notification
+func (n *Notification) onCursor(evname string, ev interface{}) {
+
+	if evname == OnCursorEnter {
+		n.stopTimer()
+	} else {
+		n.startTimer()
+	}
+	n.notifier.root.StopPropagation(Stop3D)
+}
+
+// update applies the notifier style to this notification
+func (n *Notification) update() {
+
+	s := n.notifier.styles
+	color := s.LevelColors[n.level]
+	n.SetBordersFrom(&s.Border)
+	n.SetBordersColor4(&color)
+	n.SetPaddingsFrom(&s.Paddings)
+	n.SetColor4(&s.BgColor)
+	n.icon.SetText(string(s.Icons[n.level]))
+	n.icon.SetColor4(&color)
+	if n.title != nil {
+		n.title.SetColor4(&s.TitleColor)
+	}
+	n.text.SetColor4(&s.FgColor)
+	n.bar.SetColor4(&s.ProgressColor)
+	n.fill.SetColor4(&color)
+	n.recalc()
+}
+
+// recalc sets the positions of the internal panels and the notification height
+func (n *Notification) recalc() {
+
+	s := n.notifier.styles
+	n.SetWidth(s.Width)
+	n.icon.SetPosition(0, 0)
+	x := n.icon.Width() + notificationGap
+	y := float32(0)
+	if n.title != nil {
+		n.title.SetPosition(x, y)
+		y += n.title.Height() + notificationGap
+	}
+	n.text.SetPosition(x, y)
+	y += n.text.Height()
+	if n.progress >= 0 {
+		y += notificationGap
+		width := math32.Max(n.ContentWidth()-x, 0)
+		n.bar.SetPosition(x, y)
+		n.bar.SetSize(width, s.ProgressHeight)
+		n.fill.SetSize(width*n.progress, s.ProgressHeight)
+		n.bar.SetVisible(true)
+		y += s.ProgressHeight
+	} else {
+		n.bar.SetVisible(false)
+	}
+	n.SetContentHeight(math32.Max(y, n.icon.Height()))
+}
@@ -63,6 +63,7 @@ type Panel struct {
 	borderUni       gls.Uniform4f       // pointer to border uniform (texture coordinates)
 	paddingUni      gls.Uniform4f       // pointer to padding uniform (texture coordinates)
 	contentUni      gls.Uniform4f       // pointer to content uniform (texture coordinates)
+	opacityUni      gls.Uniform1f       // pointer to effective opacity uniform
 	pospix          math32.Vector3      // absolute position in pixels
 	xmin            float32             // minimum absolute x this panel can use
 	xmax            float32             // maximum absolute x this panel can use
@@ -74,6 +75,11 @@ type Panel struct {
 	cursorEnter     bool                // mouse enter dispatched
 	layout          ILayout             // current layout for children
 	layoutParams    interface{}         // current layout parameters used by container panel
+	scale           float32             // effective scale factor from pixels to screen pixels
+	focusOrder      int                 // position in the key focus chain
+	opacity         float32             // opacity of this panel and its children
+	slice           *nineSlice          // nine-slice background or nil
+	focusNeighbors  [4]IPanel           // panels focused from this one from NavUp to NavRight
 }
 
 const (
@@ -92,6 +98,13 @@ func NewPanel(width, height float32) *Panel {
 // Initialize initializes this panel and is normally used by other types which embed a panel.
 func (p *Panel) Initialize(width, height float32) {
 
+	p.initialize(width, height, nil)
+}
+
+// initialize initializes this panel rendered with the specified material,
+// which sets its own shader, or with a new panel material if nil
+func (p *Panel) initialize(width, height float32, imat material.IMaterial) {
+
 	p.width = width
 	p.height = height
 	p.nextChildZ = deltaZ
@@ -118,12 +131,19 @@ func (p *Panel) Initialize(width, height float32) {
 	)
 
 	// Initialize material
-	p.mat = material.NewMaterial()
-	p.mat.SetShader("shaderPanel")
+	if imat == nil {
+		p.mat = material.NewMaterial()
+		p.mat.SetShader("shaderPanel")
+		imat = p.mat
+	} else {
+		p.mat = imat.GetMaterial()
+	}
 
 	// Initialize graphic
+	// Panels are positioned by their shader and must not be frustum culled
 	p.Graphic.Init(geom, gls.TRIANGLES)
-	p.AddMaterial(p, p.mat, 0, 0)
+	p.SetNeverCull(true)
+	p.AddMaterial(p, imat, 0, 0)
 
 	// Creates and adds uniform
 	p.modelMatrixUni.Init("ModelMatrix")
@@ -134,11 +154,15 @@ func (p *Panel) Initialize(width, height float32) {
 	p.borderUni.Init("Border")
 	p.paddingUni.Init("Padding")
 	p.contentUni.Init("Content")
+	p.opacityUni.Init("Opacity")
 
 	// Set defaults
 	p.borderColorUni.Set(0, 0, 0, 1)
 	p.bounded = true
 	p.enabled = true
+	p.scale = 1
+	p.opacity = 1
+	p.opacityUni.Set(1)
 
 	p.resize(width, height)
 }
@@ -423,6 +447,20 @@ func (p *Panel) Color4() math32.Color4 {
 	return p.contentColorUni.GetColor4()
 }
 
+// SetOpacity sets the opacity of this panel and its children from 0
+// (transparent) to 1 (opaque, the default), which multiplies the
+// opacity of their colors and images
+func (p *Panel) SetOpacity(opacity float32) {
+
+	p.opacity = math32.Clamp(opacity, 0, 1)
+}
+
+// Opacity returns the opacity of this panel
+func (p *Panel) Opacity() float32 {
+
+	return p.opacity
+}
+
 // SetContentSize sets this panel content size to the specified dimensions.
 // The external size of the panel may increase or decrease to acomodate
 // the new content size.
@@ -514,16 +552,19 @@ func (p *Panel) SetBounded(bounded bool) {
 // the Engine before rendering the frame.
 func (p *Panel) UpdateMatrixWorld() {
 
-	par := p.Parent()
-	if par == nil {
+	switch par := p.Parent().(type) {
+	case *Panel:
+		p.setScale(par.scale)
+		p.updateBounds(par)
+		p.opacityUni.Set(par.opacityUni.Get() * p.opacity)
+	case *Root:
+		p.setScale(par.Scale())
 		p.updateBounds(nil)
-	} else {
-		par, ok := par.(*Panel)
-		if ok {
-			p.updateBounds(par)
-		} else {
-			p.updateBounds(nil)
-		}
+		p.opacityUni.Set(par.opacityUni.Get() * p.opacity)
+	default:
+		p.setScale(uiScale)
+		p.updateBounds(nil)
+		p.opacityUni.Set(p.opacity)
 	}
 	// Update this panel children
 	for _, ichild := range p.Children() {
@@ -531,6 +572,25 @@ func (p *Panel) UpdateMatrixWorld() {
 	}
 }
 
+// Scale returns the factor which converts this panel pixels to screen
+// pixels when rendering. It is the scale of its root panel or the global
+// gui scale and is updated before each frame is rendered.
+func (p *Panel) Scale() float32 {
+
+	return p.scale
+}
+
+// setScale sets the scale factor of this panel and
+// dispatches OnScale if it changed
+func (p *Panel) setScale(scale float32) {
+
+	if scale == p.scale {
+		return
+	}
+	p.scale = scale
+	p.Dispatch(OnScale, nil)
+}
+
 // ContainsPosition returns indication if this panel contains
 // the specified screen position in pixels.
 func (p *Panel) ContainsPosition(x, y float32) bool {
@@ -766,7 +826,7 @@ func (p *Panel) resize(width, height float32) {
 }
 
 // RenderSetup is called by the Engine before drawing the object
-func (p *Panel) RenderSetup(gl *gls.GLS, rinfo *core.RenderInfo) {
+func (p *Panel) RenderSetup(gl gls.IDevice, rinfo *core.RenderInfo) {
 
 	// Get the current viewport width and height
 	_, _, width, height := gl.GetViewport()
@@ -774,16 +834,16 @@ func (p *Panel) RenderSetup(gl *gls.GLS, rinfo *core.RenderInfo) {
 	fheight := float32(height)
 
 	// Scale the quad for the viewport so it has fixed dimensions in pixels.
-	fw := float32(p.width) / fwidth
-	fh := float32(p.height) / fheight
+	fw := p.width * p.scale / fwidth
+	fh := p.height * p.scale / fheight
 	var scale math32.Vector3
 	scale.Set(2*fw, 2*fh, 1)
 
 	// Convert absolute position in pixel coordinates from the top/left to
 	// standard OpenGL clip coordinates of the quad center
 	var posclip math32.Vector3
-	posclip.X = (p.pospix.X - fwidth/2) / (fwidth / 2)
-	posclip.Y = -(p.pospix.Y - fheight/2) / (fheight / 2)
+	posclip.X = (p.pospix.X*p.scale - fwidth/2) / (fwidth / 2)
+	posclip.Y = -(p.pospix.Y*p.scale - fheight/2) / (fheight / 2)
 	posclip.Z = p.pospix.Z
 	//log.Debug("panel posclip:%v\n", posclip)
 
@@ -802,5 +862,10 @@ func (p *Panel) RenderSetup(gl *gls.GLS, rinfo *core.RenderInfo) {
 	p.borderUni.Transfer(gl)
 	p.paddingUni.Transfer(gl)
 	p.contentUni.Transfer(gl)
+	p.opacityUni.Transfer(gl)
 	p.modelMatrixUni.Transfer(gl)
+	if p.slice != nil {
+		p.slice.update(p)
+		p.slice.transfer(gl)
+	}
 }
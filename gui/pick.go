@@ -0,0 +1,66 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// PanelAt returns the panel which receives the mouse events at the specified
+// position in gui pixels, which is the panel with the mouse focus if any or
+// the most foreground visible and enabled panel which contains the position.
+// Returns nil if the position is not over any panel.
+func (r *Root) PanelAt(x, y float32) IPanel {
+
+	if r.mouseFocus != nil {
+		return r.mouseFocus
+	}
+	var found IPanel
+	var checkPanel func(ipan IPanel)
+	checkPanel = func(ipan IPanel) {
+		pan := ipan.GetPanel()
+		if !pan.Visible() || !pan.Enabled() {
+			return
+		}
+		if pan.ContainsPosition(x, y) && (found == nil || pan.pospix.Z < found.GetPanel().pospix.Z) {
+			found = ipan
+		}
+		for _, child := range pan.Children() {
+			checkPanel(child.(IPanel))
+		}
+	}
+	for _, iobj := range r.modalChildren() {
+		if ipan, ok := iobj.(IPanel); ok {
+			checkPanel(ipan)
+		}
+	}
+	return found
+}
+
+// Pick hit-tests the gui and the 3D scene at the specified window position in
+// screen pixels, as the position of the mouse events, with the same priority of
+// the mouse events: if a panel receives the mouse events at the position it is
+// returned and the scene is not tested. Otherwise returns the intersections of
+// the specified node and its children with the ray from the specified camera
+// through the position, sorted by distance, closest first.
+// If the raycaster is nil, a new raycaster with the default limits is used.
+func (r *Root) Pick(x, y float32, cam camera.ICamera, rc *core.Raycaster, inode core.INode) (IPanel, []core.Intersect) {
+
+	s := r.Scale()
+	if ipan := r.PanelAt(x/s, y/s); ipan != nil {
+		return ipan, nil
+	}
+	width, height := r.win.GetSize()
+	if width == 0 || height == 0 {
+		return nil, nil
+	}
+	if rc == nil {
+		rc = core.NewRaycaster(&math32.Vector3{}, &math32.Vector3{})
+	}
+	cam.SetRaycaster(rc, 2*x/float32(width)-1, 1-2*y/float32(height))
+	return nil, rc.IntersectObject(inode, true)
+}
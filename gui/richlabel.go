@@ -0,0 +1,610 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/engine/window"
+	"image"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RichLabel is a panel which shows text with inline markup.
+// The markup tags are enclosed in square brackets:
+//
+//	[b]bold[/b]
+//	[i]italic[/i]
+//	[u]underlined[/u]
+//	[color=#ff8000]colored[/color]
+//	[url=target]link[/url]   dispatches OnLink with "target" when clicked
+//	[icon=e88e]              icon of the icon font with the hexadecimal code point
+//	[img=name]               image set with SetImage()
+//	[[                       a literal '['
+//
+// Tags may be nested and unknown tags are shown as text.
+// If word wrap is enabled the text is wrapped to the width of the label
+// and its height is adjusted, otherwise the label is resized to its text.
+type RichLabel struct {
+	Panel                            // Embedded panel
+	styles    *RichLabelStyle        // pointer to current style
+	markup    string                 // current text with markup
+	items     []richItem             // parsed and positioned text items
+	images    map[string]image.Image // images referenced by the markup
+	wrap      bool                   // wrap text to the label width
+	over      string                 // target of the link under the cursor
+	recalcing bool                   // label size being adjusted
+	tex       *texture.Texture2D     // texture with the drawn text
+}
+
+// RichLabelStyle describes the style of the RichLabel
+type RichLabelStyle struct {
+	BgColor       math32.Color4 // background color
+	FgColor       math32.Color4 // default text color
+	LinkColor     math32.Color4 // links text color
+	LinkOverColor math32.Color4 // text color of the link under the cursor
+	FontSize      float64       // text font size
+	LineSpacing   float64       // distance between lines relative to the font size
+}
+
+// Kinds of rich label items
+const (
+	richText = iota
+	richSpace
+	richBreak
+	richIcon
+	richImage
+)
+
+// richStyle is the text style of a rich label item
+type richStyle struct {
+	bold      bool
+	italic    bool
+	underline bool
+	colored   bool          // color is set
+	color     math32.Color4 // text color
+	link      string        // link target or empty
+}
+
+// richItem is a word, space, line break, icon or image of a rich label
+type richItem struct {
+	kind     int         // item kind
+	text     string      // text of words and icons
+	img      image.Image // image of image items
+	style    richStyle   // item style
+	width    int         // width in pixels
+	ascent   int         // height above the baseline in pixels
+	x        int         // left position in the content area
+	baseline int         // baseline position in the content area
+	hidden   bool        // space at the start or end of a wrapped line
+}
+
+const richItalicShear = 0.2 // horizontal displacement of italic text per pixel above the baseline
+
+// NewRichLabel creates and returns a pointer to a new rich label with
+// the specified text with markup. If width is greater than zero the text is
+// wrapped to this width, otherwise the label is resized to fit its text.
+func NewRichLabel(markup string, width float32) *RichLabel {
+
+	rl := new(RichLabel)
+	rl.Panel.Initialize(width, 0)
+	rl.styles = &StyleDefault.RichLabel
+	rl.images = make(map[string]image.Image)
+	rl.wrap = width > 0
+	rl.Panel.Subscribe(OnResize, func(evname string, ev interface{}) {
+		if rl.wrap && !rl.recalcing {
+			rl.recalc()
+		}
+	})
+	rl.Panel.Subscribe(OnCursor, rl.onCursor)
+	rl.Panel.Subscribe(OnCursorLeave, rl.onCursor)
+	rl.Panel.Subscribe(OnMouseDown, rl.onMouse)
+	rl.SetText(markup)
+	return rl
+}
+
+// SetStyles sets the style of this rich label
+func (rl *RichLabel) SetStyles(s *RichLabelStyle) {
+
+	rl.styles = s
+	rl.recalc()
+}
+
+// SetText sets the text with markup of this rich label
+func (rl *RichLabel) SetText(markup string) {
+
+	rl.markup = markup
+	rl.over = ""
+	rl.parse()
+	rl.recalc()
+}
+
+// Text returns the text with markup of this rich label
+func (rl *RichLabel) Text() string {
+
+	return rl.markup
+}
+
+// PlainText returns the text of this rich label without markup
+func (rl *RichLabel) PlainText() string {
+
+	var sb strings.Builder
+	for _, it := range rl.items {
+		switch it.kind {
+		case richText, richSpace:
+			sb.WriteString(it.text)
+		case richBreak:
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// SetImage sets the image with the specified name which is shown
+// by the [img=name] tags. A nil image removes the named image.
+func (rl *RichLabel) SetImage(name string, img image.Image) {
+
+	if img == nil {
+		delete(rl.images, name)
+	} else {
+		rl.images[name] = img
+	}
+	rl.parse()
+	rl.recalc()
+}
+
+// SetWordWrap sets if the text is wrapped to the current width of the
+// label. If not, lines are only broken by new line characters and the
+// label is resized to fit its text.
+func (rl *RichLabel) SetWordWrap(state bool) {
+
+	rl.wrap = state
+	rl.recalc()
+}
+
+// WordWrap returns if the text is wrapped to the width of the label
+func (rl *RichLabel) WordWrap() bool {
+
+	return rl.wrap
+}
+
+// LinkAt returns the target of the link at the specified
+// window position or an empty string if there is none
+func (rl *RichLabel) LinkAt(x, y float32) string {
+
+	cx := int(x - rl.pospix.X - rl.content.X)
+	cy := int(y - rl.pospix.Y - rl.content.Y)
+	descent := rl.descent()
+	for _, it := range rl.items {
+		if it.style.link == "" || it.hidden || it.kind == richBreak {
+			continue
+		}
+		if cx >= it.x && cx < it.x+it.width && cy >= it.baseline-it.ascent && cy < it.baseline+descent {
+			return it.style.link
+		}
+	}
+	return ""
+}
+
+// onCursor process subscribed cursor events
+func (rl *RichLabel) onCursor(evname string, ev interface{}) {
+
+	over := ""
+	if evname == OnCursor {
+		cev := ev.(*window.CursorEvent)
+		over = rl.LinkAt(cev.Xpos, cev.Ypos)
+	}
+	if over == rl.over {
+		return
+	}
+	rl.over = over
+	rl.redraw()
+	if rl.root == nil {
+		return
+	}
+	if over != "" {
+		rl.root.SetCursorDrag()
+	} else {
+		rl.root.SetCursorNormal()
+	}
+}
+
+// onMouse process subscribed mouse events
+func (rl *RichLabel) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	link := rl.LinkAt(mev.Xpos, mev.Ypos)
+	if link == "" {
+		return
+	}
+	rl.Dispatch(OnLink, link)
+	if rl.root != nil {
+		rl.root.StopPropagation(Stop3D)
+	}
+}
+
+// parse parses the current markup into items
+func (rl *RichLabel) parse() {
+
+	type saved struct {
+		tag   string
+		style richStyle
+	}
+	rl.items = rl.items[:0]
+	var style richStyle
+	stack := []saved{}
+	m := rl.markup
+
+	// tag applies the specified tag and returns false if it is not valid
+	tag := func(tag string) bool {
+		name := tag
+		arg := ""
+		if pos := strings.IndexByte(tag, '='); pos >= 0 {
+			name = tag[:pos]
+			arg = strings.TrimSpace(tag[pos+1:])
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		// Closing tags restore the style before the last opening tag
+		if strings.HasPrefix(name, "/") {
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].tag == name[1:] {
+					style = stack[i].style
+					stack = stack[:i]
+					return true
+				}
+			}
+			return false
+		}
+		next := style
+		switch name {
+		case "b":
+			next.bold = true
+		case "i":
+			next.italic = true
+		case "u":
+			next.underline = true
+		case "color":
+			color, err := parseThemeColor(arg, nil)
+			if err != nil {
+				return false
+			}
+			next.colored = true
+			next.color = color
+		case "url":
+			if arg == "" {
+				return false
+			}
+			next.link = arg
+		case "icon":
+			code, err := strconv.ParseUint(arg, 16, 32)
+			if err != nil {
+				return false
+			}
+			rl.items = append(rl.items, richItem{kind: richIcon, text: string(rune(code)), style: style})
+			return true
+		case "img":
+			if img := rl.images[arg]; img != nil {
+				rl.items = append(rl.items, richItem{kind: richImage, img: img, style: style})
+			}
+			return true
+		default:
+			return false
+		}
+		stack = append(stack, saved{name, style})
+		style = next
+		return true
+	}
+
+	for len(m) > 0 {
+		pos := strings.IndexByte(m, '[')
+		if pos < 0 {
+			rl.addText(m, style)
+			break
+		}
+		rl.addText(m[:pos], style)
+		m = m[pos:]
+		if strings.HasPrefix(m, "[[") {
+			rl.addText("[", style)
+			m = m[2:]
+			continue
+		}
+		end := strings.IndexByte(m, ']')
+		if end < 0 {
+			rl.addText(m, style)
+			break
+		}
+		if !tag(m[1:end]) {
+			rl.addText(m[:end+1], style)
+		}
+		m = m[end+1:]
+	}
+}
+
+// addText appends the words, spaces and line breaks
+// of the specified text with the specified style
+func (rl *RichLabel) addText(s string, style richStyle) {
+
+	start := 0
+	for i, r := range s {
+		if r != ' ' && r != '\t' && r != '\n' {
+			continue
+		}
+		if i > start {
+			rl.items = append(rl.items, richItem{kind: richText, text: s[start:i], style: style})
+		}
+		if r == '\n' {
+			rl.items = append(rl.items, richItem{kind: richBreak, style: style})
+		} else {
+			rl.items = append(rl.items, richItem{kind: richSpace, text: " ", style: style})
+		}
+		start = i + 1
+	}
+	if start < len(s) {
+		rl.items = append(rl.items, richItem{kind: richText, text: s[start:], style: style})
+	}
+}
+
+// font returns the font for the specified item style
+// with the size and color of the current style
+func (rl *RichLabel) font(kind int, style *richStyle) *text.Font {
+
+	font := StyleDefault.Font
+	if kind == richIcon {
+		font = StyleDefault.FontIcon
+	} else if style.bold {
+		font = StyleDefault.FontBold
+	}
+	font.SetSize(rl.styles.FontSize)
+	font.SetDPI(72)
+	font.SetLineSpacing(1.0)
+	font.SetBgColor4(&math32.Color4{0, 0, 0, 0})
+	font.SetFgColor4(rl.color(style))
+	return font
+}
+
+// color returns the text color for the specified item style
+func (rl *RichLabel) color(style *richStyle) *math32.Color4 {
+
+	switch {
+	case style.link != "" && style.link == rl.over:
+		return &rl.styles.LinkOverColor
+	case style.link != "":
+		return &rl.styles.LinkColor
+	case style.colored:
+		return &style.color
+	}
+	return &rl.styles.FgColor
+}
+
+// ascent returns the height of the text above the baseline in pixels
+func (rl *RichLabel) ascent() int {
+
+	return int(math.Ceil(rl.styles.FontSize))
+}
+
+// descent returns the height of the text lines below the baseline in pixels
+func (rl *RichLabel) descent() int {
+
+	lineHeight := int(math.Ceil(rl.styles.FontSize * rl.styles.LineSpacing))
+	min := int(math.Ceil(rl.styles.FontSize / 4))
+	if lineHeight-rl.ascent() < min {
+		return min
+	}
+	return lineHeight - rl.ascent()
+}
+
+// recalc measures and positions the items, adjusts
+// the size of the label and redraws it
+func (rl *RichLabel) recalc() {
+
+	ascent := rl.ascent()
+	descent := rl.descent()
+	maxWidth := 0
+	if rl.wrap {
+		maxWidth = int(rl.ContentWidth())
+	}
+
+	// Measures the items, splitting words which are wider than the label
+	for i := 0; i < len(rl.items); i++ {
+		it := &rl.items[i]
+		it.hidden = false
+		it.ascent = ascent
+		switch it.kind {
+		case richText, richSpace, richIcon:
+			it.width, _ = rl.font(it.kind, &it.style).MeasureText(it.text)
+		case richImage:
+			it.width = it.img.Bounds().Dx()
+			it.ascent = it.img.Bounds().Dy()
+		default:
+			it.width = 0
+		}
+		if it.kind == richText && maxWidth > 0 && it.width > maxWidth {
+			rl.splitWord(i, maxWidth)
+		}
+	}
+
+	// Positions the items in lines
+	width := 0
+	top := 0
+	lineStart := 0
+	x := 0
+	wrapped := false
+	// endLine ends the line with the items from lineStart to end (exclusive)
+	// and starts the next line at the specified item
+	endLine := func(end, next int) {
+		// Hides the spaces at the end of the line
+		for i := end - 1; i >= lineStart && rl.items[i].kind == richSpace; i-- {
+			if !rl.items[i].hidden {
+				rl.items[i].hidden = true
+				x -= rl.items[i].width
+			}
+		}
+		lineAscent := ascent
+		for i := lineStart; i < end; i++ {
+			if !rl.items[i].hidden && rl.items[i].ascent > lineAscent {
+				lineAscent = rl.items[i].ascent
+			}
+		}
+		for i := lineStart; i < next; i++ {
+			rl.items[i].baseline = top + lineAscent
+		}
+		if x > width {
+			width = x
+		}
+		top += lineAscent + descent
+		lineStart = next
+		x = 0
+	}
+	for i := 0; i < len(rl.items); i++ {
+		it := &rl.items[i]
+		switch it.kind {
+		case richBreak:
+			it.x = x
+			endLine(i, i+1)
+			wrapped = false
+			continue
+		case richSpace:
+			// Spaces at the start of wrapped lines are hidden
+			if x == 0 && wrapped {
+				it.hidden = true
+				continue
+			}
+		default:
+			// Words which don't fit are moved to the next line together
+			// with the following items not separated from them by spaces
+			if maxWidth > 0 && x > 0 {
+				fits := x+it.width <= maxWidth
+				if fits && rl.items[i-1].kind == richSpace {
+					groupWidth := 0
+					for j := i; j < len(rl.items) && rl.items[j].kind != richSpace && rl.items[j].kind != richBreak; j++ {
+						groupWidth += rl.items[j].width
+					}
+					fits = x+groupWidth <= maxWidth
+				}
+				if !fits {
+					endLine(i, i)
+					wrapped = true
+				}
+			}
+		}
+		it.x = x
+		x += it.width
+	}
+	endLine(len(rl.items), len(rl.items))
+
+	// Adjusts the label size
+	rl.recalcing = true
+	if rl.wrap {
+		rl.SetContentHeight(float32(top))
+	} else {
+		rl.SetContentSize(float32(width), float32(top))
+	}
+	rl.recalcing = false
+	rl.redraw()
+}
+
+// splitWord splits the word item at the specified position
+// in items which are not wider than the specified width
+func (rl *RichLabel) splitWord(pos int, maxWidth int) {
+
+	it := rl.items[pos]
+	font := rl.font(it.kind, &it.style)
+	runes := []rune(it.text)
+	end := 1
+	for end < len(runes) {
+		w, _ := font.MeasureText(string(runes[:end+1]))
+		if w > maxWidth {
+			break
+		}
+		end++
+	}
+	if end >= len(runes) {
+		return
+	}
+	rest := it
+	rest.text = string(runes[end:])
+	rl.items[pos].text = string(runes[:end])
+	rl.items[pos].width, _ = font.MeasureText(rl.items[pos].text)
+	rl.items = append(rl.items, richItem{})
+	copy(rl.items[pos+2:], rl.items[pos+1:])
+	rl.items[pos+1] = rest
+}
+
+// redraw draws the positioned items into the label texture
+func (rl *RichLabel) redraw() {
+
+	width := int(rl.ContentWidth())
+	height := int(rl.ContentHeight())
+	if width <= 0 {
+		width = 1
+	}
+	if height <= 0 {
+		height = 1
+	}
+	s := rl.styles
+	canvas := text.NewCanvas(width, height, &s.BgColor)
+	descent := rl.descent()
+	for i := range rl.items {
+		it := &rl.items[i]
+		if it.hidden || it.kind == richBreak {
+			continue
+		}
+		switch it.kind {
+		case richText, richIcon:
+			font := rl.font(it.kind, &it.style)
+			if it.style.italic {
+				rl.drawItalic(canvas, it, font, descent)
+			} else {
+				canvas.DrawText(it.x, it.baseline-it.ascent, it.text, font)
+			}
+		case richImage:
+			b := it.img.Bounds()
+			dst := image.Rect(it.x, it.baseline-b.Dy(), it.x+b.Dx(), it.baseline)
+			draw.Draw(canvas.RGBA, dst, it.img, b.Min, draw.Over)
+		}
+		// Underlines links and underlined text and spaces
+		if it.kind != richImage && (it.style.underline || it.style.link != "") {
+			color := image.NewUniform(text.Color4NRGBA(rl.color(&it.style)))
+			line := image.Rect(it.x, it.baseline+1, it.x+it.width, it.baseline+2)
+			draw.Draw(canvas.RGBA, line, color, image.ZP, draw.Over)
+		}
+	}
+
+	// Creates texture if it doesn't exist
+	if rl.tex == nil {
+		rl.tex = texture.NewTexture2DFromRGBA(canvas.RGBA)
+		rl.tex.SetMagFilter(gls.NEAREST)
+		rl.tex.SetMinFilter(gls.NEAREST)
+		rl.Panel.Material().AddTexture(rl.tex)
+		// Otherwise update texture with new image
+	} else {
+		rl.tex.SetFromRGBA(canvas.RGBA)
+	}
+}
+
+// drawItalic draws the text of the specified item slanted, as
+// there is no italic font, by drawing it in a separate canvas
+// and copying its rows displaced proportionally to their height
+func (rl *RichLabel) drawItalic(canvas *text.Canvas, it *richItem, font *text.Font, descent int) {
+
+	height := it.ascent + descent
+	extra := int(math.Ceil(float64(height) * richItalicShear))
+	tmp := text.NewCanvas(it.width+extra, height, &math32.Color4{0, 0, 0, 0})
+	tmp.DrawText(0, 0, it.text, font)
+	top := it.baseline - it.ascent
+	for y := 0; y < height; y++ {
+		dx := int(math.Floor(float64(it.ascent-y) * richItalicShear))
+		dst := image.Rect(it.x+dx, top+y, it.x+dx+it.width+extra, top+y+1)
+		draw.Draw(canvas.RGBA, dst, tmp.RGBA, image.Pt(0, y), draw.Over)
+	}
+}
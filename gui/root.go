@@ -7,20 +7,43 @@ package gui
 import (
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/window"
 	"sort"
+	"time"
 )
 
 type Root struct {
-	Panel                            // embedded panel
-	core.TimerManager                // embedded TimerManager
-	gs                *gls.GLS       // OpenGL state
-	win               window.IWindow // Window
-	stopPropagation   int            // stop event propagation bitmask
-	keyFocus          IPanel         // current child panel with key focus
-	mouseFocus        IPanel         // current child panel with mouse focus
-	scrollFocus       IPanel         // current child panel with scroll focus
-	targets           listPanelZ     // preallocated list of target panels
+	Panel                                           // embedded panel
+	core.TimerManager                               // embedded TimerManager
+	gs                gls.IDevice                   // graphics device
+	win               window.IWindow                // Window
+	stopPropagation   int                           // stop event propagation bitmask
+	keyFocus          IPanel                        // current child panel with key focus
+	mouseFocus        IPanel                        // current child panel with mouse focus
+	scrollFocus       IPanel                        // current child panel with scroll focus
+	targets           listPanelZ                    // preallocated list of target panels
+	drag              *dragOp                       // current drag and drop operation or nil
+	tipOwner          *Panel                        // panel whose tooltip is pending or shown
+	tipPanel          IPanel                        // tooltip panel being shown
+	tipTimer          int                           // id of the timer which shows the tooltip
+	menu              *Menu                         // menu currently popped up or nil
+	contextMenu       *Menu                         // context menu of the empty space or nil
+	scale             float32                       // scale factor of this root or 0 to use the window scale
+	lastScale         float32                       // effective scale factor of the last frame
+	mouseEv           window.MouseEvent             // mouse event converted to gui pixels
+	cursorEv          window.CursorEvent            // cursor event converted to gui pixels
+	modals            []*Modal                      // modal dialogs shown from bottom to top
+	focusRing         Panel                         // frame shown around the key focus panel
+	noTabNav          bool                          // Tab keys do not move the key focus
+	shortcuts         map[Shortcut]*shortcutBinding // registered keyboard shortcuts
+	tweens            []*Tween                      // tweens being advanced
+	lastFrame         time.Time                     // time of the last frame
+	navigation        bool                          // directional navigation mode
+	navKeys           map[Shortcut]NavAction        // keys of the navigation actions
+	touchID           int                           // id of the touch which emulates the mouse or -1
+	gestureEv         window.GestureEvent           // gesture event converted to gui pixels
+	panScroll         float32                       // pan motion not yet converted to scroll steps
 }
 
 const (
@@ -30,7 +53,7 @@ const (
 )
 
 // NewRoot creates and returns a pointer to a gui root panel for the specified window
-func NewRoot(gs *gls.GLS, win window.IWindow) *Root {
+func NewRoot(gs gls.IDevice, win window.IWindow) *Root {
 
 	r := new(Root)
 	r.gs = gs
@@ -42,22 +65,93 @@ func NewRoot(gs *gls.GLS, win window.IWindow) *Root {
 	r.SetRenderable(false)
 	// Subscribe to window events
 	r.SubscribeWin()
+	setWindowClipboard(win)
 	r.targets = []IPanel{}
+	r.lastScale = r.Scale()
+	r.focusRing.Initialize(0, 0)
+	r.focusRing.SetEnabled(false)
+	r.focusRing.SetColor4(&math32.Color4{0, 0, 0, 0})
+	r.shortcuts = make(map[Shortcut]*shortcutBinding)
+	r.touchID = -1
+	r.navKeys = make(map[Shortcut]NavAction)
+	for _, action := range []NavAction{NavUp, NavDown, NavLeft, NavRight} {
+		r.navKeys[navActionKeys[action]] = action
+	}
 	return r
 }
 
+// SetScale sets the scale factor of this root panel gui which multiplies
+// the global gui scale. The default value of 0 uses the scale of the
+// monitor where the window is, which is updated when the window moves.
+// The size of the root panel is changed to keep its size in the window.
+func (r *Root) SetScale(scale float32) {
+
+	if scale >= 0 {
+		r.scale = scale
+	}
+}
+
+// Scale returns the effective scale factor of this root panel gui which
+// converts its pixels to window pixels.
+func (r *Root) Scale() float32 {
+
+	scale := r.scale
+	if scale == 0 {
+		scale = r.win.GetScale()
+	}
+	return uiScale * scale
+}
+
+// FitWindow sets the size of this root panel to cover its window.
+// As the root panel size is in gui pixels it is normally called when
+// the window size changes instead of setting the window size.
+func (r *Root) FitWindow() {
+
+	width, height := r.windowSize()
+	r.SetSize(width, height)
+}
+
+// UpdateMatrixWorld overrides the Panel version to update the scale of the
+// root panel before its children's. It is called before rendering the frame.
+func (r *Root) UpdateMatrixWorld() {
+
+	// Keeps the root size in the window if the scale changed
+	scale := r.Scale()
+	if scale != r.lastScale {
+		r.SetSize(r.Width()*r.lastScale/scale, r.Height()*r.lastScale/scale)
+		r.lastScale = scale
+	}
+	r.setScale(scale)
+	r.updateBounds(nil)
+	r.opacityUni.Set(r.opacity)
+	for _, ichild := range r.Children() {
+		ichild.UpdateMatrixWorld()
+	}
+	r.updateFocusRing()
+}
+
 // SubscribeWin subscribes this root panel to window events
 func (r *Root) SubscribeWin() {
 
 	r.win.Subscribe(window.OnKeyUp, r.onKey)
 	r.win.Subscribe(window.OnKeyDown, r.onKey)
 	r.win.Subscribe(window.OnChar, r.onChar)
+	r.win.Subscribe(window.OnText, r.onChar)
+	r.win.Subscribe(window.OnPreedit, r.onChar)
 	r.win.Subscribe(window.OnMouseUp, r.onMouse)
 	r.win.Subscribe(window.OnMouseDown, r.onMouse)
 	r.win.Subscribe(window.OnCursor, r.onCursor)
 	r.win.Subscribe(window.OnScroll, r.onScroll)
 	r.win.Subscribe(window.OnWindowSize, r.onWindowSize)
 	r.win.Subscribe(window.OnFrame, r.onFrame)
+	r.win.Subscribe(window.OnTouchDown, r.onTouch)
+	r.win.Subscribe(window.OnTouchMove, r.onTouch)
+	r.win.Subscribe(window.OnTouchUp, r.onTouch)
+	r.win.Subscribe(window.OnTap, r.onGesture)
+	r.win.Subscribe(window.OnLongPress, r.onGesture)
+	r.win.Subscribe(window.OnPan, r.onGesture)
+	r.win.Subscribe(window.OnPinch, r.onGesture)
+	r.win.Subscribe(window.OnFileDrop, r.onFileDrop)
 }
 
 // Add adds the specified panel to the root container list of children
@@ -71,6 +165,10 @@ func (r *Root) Add(ipan IPanel) {
 // Passing nil will remove the focus (if any)
 func (r *Root) SetKeyFocus(ipan IPanel) {
 
+	// The key focus is kept inside the top modal dialog
+	if ipan != nil && !r.modalAllows(ipan) {
+		return
+	}
 	if r.keyFocus != nil {
 		// If this panel is already in focus, nothing to do
 		if ipan != nil {
@@ -101,6 +199,9 @@ func (r *Root) SetMouseFocus(ipan IPanel) {
 // Passing nil will restore the default event processing
 func (r *Root) SetScrollFocus(ipan IPanel) {
 
+	if ipan != nil && !r.modalAllows(ipan) {
+		return
+	}
 	r.scrollFocus = ipan
 }
 
@@ -163,30 +264,80 @@ func (r *Root) SetCursorVResize() {
 	r.win.SetStandardCursor(window.VResizeCursor)
 }
 
+// SetIMECursorRect informs the window, if it supports input method
+// composition, of the position and size in pixels of the text cursor
+// so that the input method candidates window is shown near it.
+// It is normally called by the text widgets with the key focus.
+func (r *Root) SetIMECursorRect(x, y, width, height float32) {
+
+	ime, ok := r.win.(window.IIME)
+	if !ok {
+		return
+	}
+	s := r.Scale()
+	ime.SetIMECursorRect(int(x*s), int(y*s), int(width*s), int(height*s))
+}
+
 // onKey is called when key events are received
 func (r *Root) onKey(evname string, ev interface{}) {
 
-	// If no panel has the key focus, nothing to do
-	if r.keyFocus == nil {
-		return
+	// If requested, stop propagation of event outside the root gui
+	if r.processKey(evname, ev.(*window.KeyEvent), NavNone) {
+		r.win.CancelDispatch()
 	}
+}
+
+// processKey dispatches the specified key event to the focused panel and
+// processes the keys not used by it. The action is the navigation action
+// which sent the key or NavNone for the keys received from the window.
+// It returns if the propagation of the event outside the root gui must be stopped.
+func (r *Root) processKey(evname string, kev *window.KeyEvent, action NavAction) bool {
+
 	// Dispatch window.KeyEvent to focused panel subscribers
+	m := r.TopModal()
 	r.stopPropagation = 0
-	r.keyFocus.GetPanel().Dispatch(evname, ev)
-	// If requested, stop propagation of event outside the root gui
-	if (r.stopPropagation & Stop3D) != 0 {
-		r.win.CancelDispatch()
+	if r.keyFocus != nil {
+		r.keyFocus.GetPanel().Dispatch(evname, kev)
 	}
+	// The keys not used by the focused panel may be shortcuts
+	// or move the key focus
+	if (r.stopPropagation&Stop3D) == 0 && evname == OnKeyDown {
+		if r.dispatchShortcut(kev) {
+			r.stopPropagation |= Stop3D
+		} else if kev.Keycode == window.KeyTab && !r.noTabNav && kev.Mods&(window.ModControl|window.ModAlt|window.ModSuper) == 0 {
+			if r.FocusNext(kev.Mods&window.ModShift != 0) {
+				r.stopPropagation |= Stop3D
+			}
+		} else if action != NavNone {
+			if action >= NavUp && action <= NavRight && r.FocusDirection(action) {
+				r.stopPropagation |= Stop3D
+			}
+		} else if r.navigation && r.navigateKey(kev) {
+			r.stopPropagation |= Stop3D
+		}
+	}
+	// The keys not used by the focused panel are used by the top modal
+	// dialog which blocks all keys to outside the root gui
+	if m != nil {
+		if (r.stopPropagation & Stop3D) == 0 {
+			m.onKey(evname, kev)
+		}
+		r.stopPropagation |= Stop3D
+	}
+	return (r.stopPropagation & Stop3D) != 0
 }
 
-// onChar is called when char events are received
+// onChar is called when char and input method composition events are received
 func (r *Root) onChar(evname string, ev interface{}) {
 
 	// If no panel has the key focus, nothing to do
 	if r.keyFocus == nil {
+		if r.TopModal() != nil {
+			r.win.CancelDispatch()
+		}
 		return
 	}
-	// Dispatch window.CharEvent to focused panel subscribers
+	// Dispatch window.CharEvent, window.TextEvent or window.PreeditEvent to focused panel subscribers
 	r.stopPropagation = 0
 	r.keyFocus.GetPanel().Dispatch(evname, ev)
 	// If requested, stopj propagation of event outside the root gui
@@ -198,15 +349,26 @@ func (r *Root) onChar(evname string, ev interface{}) {
 // onMouse is called when mouse button events are received
 func (r *Root) onMouse(evname string, ev interface{}) {
 
-	mev := ev.(*window.MouseEvent)
-	r.sendPanels(mev.Xpos, mev.Ypos, evname, ev)
+	// Converts the window position to gui pixels
+	r.mouseEv = *ev.(*window.MouseEvent)
+	if evname == OnMouseDown {
+		r.hideFocusRing()
+	}
+	s := r.Scale()
+	r.mouseEv.Xpos /= s
+	r.mouseEv.Ypos /= s
+	r.sendPanels(r.mouseEv.Xpos, r.mouseEv.Ypos, evname, &r.mouseEv)
 }
 
 // onCursor is called when (mouse) cursor events are received
 func (r *Root) onCursor(evname string, ev interface{}) {
 
-	cev := ev.(*window.CursorEvent)
-	r.sendPanels(cev.Xpos, cev.Ypos, evname, ev)
+	// Converts the window position to gui pixels
+	r.cursorEv = *ev.(*window.CursorEvent)
+	s := r.Scale()
+	r.cursorEv.Xpos /= s
+	r.cursorEv.Ypos /= s
+	r.sendPanels(r.cursorEv.Xpos, r.cursorEv.Ypos, evname, &r.cursorEv)
 }
 
 // sendPanel sends mouse or cursor event to focused panel or panels
@@ -257,8 +419,8 @@ func (r *Root) sendPanels(x, y float32, evname string, ev interface{}) {
 		}
 	}
 
-	// Checks all children of this root node
-	for _, iobj := range r.Node.Children() {
+	// Checks all children of this root node which may receive input
+	for _, iobj := range r.modalChildren() {
 		ipan, ok := iobj.(IPanel)
 		if !ok {
 			continue
@@ -271,6 +433,12 @@ func (r *Root) sendPanels(x, y float32, evname string, ev interface{}) {
 		// If event is mouse click, removes the keyboard focus
 		if evname == OnMouseDown {
 			r.SetKeyFocus(nil)
+			// Pops up the context menu of the empty space
+			mev := ev.(*window.MouseEvent)
+			if r.contextMenu != nil && mev.Button == window.MouseButtonRight {
+				r.PopupMenu(r.contextMenu, x, y)
+				r.win.CancelDispatch()
+			}
 		}
 		return
 	}
@@ -312,6 +480,9 @@ func (r *Root) onScroll(evname string, ev interface{}) {
 
 	// If no panel with the scroll focus, nothing to do
 	if r.scrollFocus == nil {
+		if r.TopModal() != nil {
+			r.win.CancelDispatch()
+		}
 		return
 	}
 	// Dispatch event to panel with scroll focus
@@ -323,6 +494,18 @@ func (r *Root) onScroll(evname string, ev interface{}) {
 	}
 }
 
+// onFileDrop is called when files are dropped onto the window and
+// dispatches them, converted to gui pixels, to the panels which
+// contain the drop position
+func (r *Root) onFileDrop(evname string, ev interface{}) {
+
+	fev := *ev.(*window.FileDropEvent)
+	s := r.Scale()
+	fev.Xpos /= s
+	fev.Ypos /= s
+	r.sendPanels(fev.Xpos, fev.Ypos, evname, &fev)
+}
+
 // onSize is called when window size events are received
 func (r *Root) onWindowSize(evname string, ev interface{}) {
 
@@ -332,10 +515,24 @@ func (r *Root) onWindowSize(evname string, ev interface{}) {
 	}
 }
 
+// windowSize returns the size of the window in gui pixels
+func (r *Root) windowSize() (float32, float32) {
+
+	width, height := r.win.GetSize()
+	s := r.Scale()
+	return float32(width) / s, float32(height) / s
+}
+
 // onFrame is called when window finished swapping frame buffers
+// and processes the timers and advances the tweens
 func (r *Root) onFrame(evname string, ev interface{}) {
 
 	r.TimerManager.ProcessTimers()
+	now := time.Now()
+	if !r.lastFrame.IsZero() {
+		r.updateTweens(now.Sub(r.lastFrame))
+	}
+	r.lastFrame = now
 }
 
 // For sorting panels by Z coordinate
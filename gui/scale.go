@@ -0,0 +1,25 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+var uiScale float32 = 1 // global gui scale factor
+
+// SetScale sets the global scale factor of the gui, which multiplies
+// the scale of each root panel. The sizes and positions of all panels,
+// including the font sizes, are in gui pixels which are multiplied by
+// the scale to get the screen pixels where they are rendered.
+// The texts are rasterized at the scaled font size so they stay crisp.
+func SetScale(scale float32) {
+
+	if scale > 0 {
+		uiScale = scale
+	}
+}
+
+// Scale returns the global scale factor of the gui
+func Scale() float32 {
+
+	return uiScale
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/window"
+	"strconv"
+	"strings"
+)
+
+// Shortcut is a key combination, as Ctrl+S, registered in a gui root panel
+// to call a global handler or to dispatch OnShortcut to a widget when it is
+// pressed and not used by the widget with the key focus
+type Shortcut struct {
+	Key  window.Key
+	Mods window.ModifierKey // combination of ModShift, ModControl, ModAlt and ModSuper
+}
+
+// shortcutBinding is the handler or panel of a registered shortcut
+type shortcutBinding struct {
+	cb   func(sc Shortcut) // global handler or nil
+	ipan IPanel            // panel which receives OnShortcut or nil
+}
+
+// shortcutMods are the modifiers which are part of a shortcut
+const shortcutMods = window.ModShift | window.ModControl | window.ModAlt | window.ModSuper
+
+// shortcutModNames are the names of the shortcut modifiers in the order they are shown
+var shortcutModNames = []struct {
+	name string
+	mod  window.ModifierKey
+}{
+	{"Ctrl", window.ModControl},
+	{"Alt", window.ModAlt},
+	{"Shift", window.ModShift},
+	{"Super", window.ModSuper},
+}
+
+// shortcutKeyNames are the names of the keys other than letters,
+// digits and function keys used in shortcuts
+var shortcutKeyNames = []struct {
+	name string
+	key  window.Key
+}{
+	{"Space", window.KeySpace},
+	{"Enter", window.KeyEnter},
+	{"Escape", window.KeyEscape},
+	{"Tab", window.KeyTab},
+	{"Backspace", window.KeyBackspace},
+	{"Insert", window.KeyInsert},
+	{"Delete", window.KeyDelete},
+	{"Home", window.KeyHome},
+	{"End", window.KeyEnd},
+	{"PageUp", window.KeyPageUp},
+	{"PageDown", window.KeyPageDown},
+	{"Up", window.KeyUp},
+	{"Down", window.KeyDown},
+	{"Left", window.KeyLeft},
+	{"Right", window.KeyRight},
+	{"-", window.KeyMinus},
+	{"=", window.KeyEqual},
+	{",", window.KeyComma},
+	{".", window.KeyPeriod},
+	{"/", window.KeySlash},
+	{";", window.KeySemicolon},
+	{"'", window.KeyApostrophe},
+	{"[", window.KeyLeftBracket},
+	{"]", window.KeyRightBracket},
+	{"\\", window.KeyBackslash},
+	{"`", window.KeyGraveAccent},
+}
+
+// ParseShortcut parses a shortcut from its text, as "Ctrl+S", "Ctrl+Shift+Z"
+// or "F5", with the modifiers Ctrl, Alt, Shift and Super before the key name,
+// which is not case sensitive
+func ParseShortcut(text string) (Shortcut, error) {
+
+	var sc Shortcut
+	parts := strings.Split(strings.TrimSpace(text), "+")
+	for _, part := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "ctrl", "control", "cmd":
+			sc.Mods |= window.ModControl
+		case "alt", "option":
+			sc.Mods |= window.ModAlt
+		case "shift":
+			sc.Mods |= window.ModShift
+		case "super", "meta", "win":
+			sc.Mods |= window.ModSuper
+		default:
+			return Shortcut{}, fmt.Errorf("invalid shortcut modifier %q in %q", part, text)
+		}
+	}
+	key, ok := shortcutKey(strings.TrimSpace(parts[len(parts)-1]))
+	if !ok {
+		return Shortcut{}, fmt.Errorf("invalid shortcut key in %q", text)
+	}
+	sc.Key = key
+	return sc, nil
+}
+
+// shortcutKey returns the key with the specified name and if it is valid
+func shortcutKey(name string) (window.Key, bool) {
+
+	upper := strings.ToUpper(name)
+	if len(upper) == 1 && upper[0] >= 'A' && upper[0] <= 'Z' {
+		return window.KeyA + window.Key(upper[0]-'A'), true
+	}
+	if len(upper) == 1 && upper[0] >= '0' && upper[0] <= '9' {
+		return window.Key0 + window.Key(upper[0]-'0'), true
+	}
+	if len(upper) > 1 && upper[0] == 'F' {
+		n, err := strconv.Atoi(upper[1:])
+		if err == nil && n >= 1 && n <= 25 {
+			return window.KeyF1 + window.Key(n-1), true
+		}
+	}
+	for _, kn := range shortcutKeyNames {
+		if strings.ToUpper(kn.name) == upper {
+			return kn.key, true
+		}
+	}
+	switch upper {
+	case "ESC":
+		return window.KeyEscape, true
+	case "DEL":
+		return window.KeyDelete, true
+	case "INS":
+		return window.KeyInsert, true
+	case "RETURN":
+		return window.KeyEnter, true
+	}
+	return window.KeyUnknown, false
+}
+
+// String returns the text of this shortcut as shown in menus and
+// accepted by ParseShortcut
+func (sc Shortcut) String() string {
+
+	var parts []string
+	for _, mn := range shortcutModNames {
+		if sc.Mods&mn.mod != 0 {
+			parts = append(parts, mn.name)
+		}
+	}
+	var name string
+	switch {
+	case sc.Key >= window.KeyA && sc.Key <= window.KeyZ:
+		name = string(rune('A' + sc.Key - window.KeyA))
+	case sc.Key >= window.Key0 && sc.Key <= window.Key0+9:
+		name = string(rune('0' + sc.Key - window.Key0))
+	case sc.Key >= window.KeyF1 && sc.Key <= window.KeyF25:
+		name = fmt.Sprintf("F%d", sc.Key-window.KeyF1+1)
+	default:
+		name = fmt.Sprintf("Key%d", sc.Key)
+		for _, kn := range shortcutKeyNames {
+			if kn.key == sc.Key {
+				name = kn.name
+				break
+			}
+		}
+	}
+	return strings.Join(append(parts, name), "+")
+}
+
+// SetShortcut registers the specified shortcut to call the specified global
+// handler, replacing any previous registration of the shortcut.
+// A nil handler removes the shortcut.
+func (r *Root) SetShortcut(sc Shortcut, cb func(sc Shortcut)) {
+
+	sc.Mods &= shortcutMods
+	if cb == nil {
+		delete(r.shortcuts, sc)
+		return
+	}
+	r.shortcuts[sc] = &shortcutBinding{cb: cb}
+}
+
+// SetShortcutPanel registers the specified shortcut to dispatch OnShortcut
+// to the specified panel, replacing any previous registration of the shortcut.
+// Buttons and check boxes are clicked and menu items activated by OnShortcut.
+// The event is not dispatched while the panel or one of its parents is
+// disabled or hidden. A nil panel removes the shortcut.
+func (r *Root) SetShortcutPanel(sc Shortcut, ipan IPanel) {
+
+	sc.Mods &= shortcutMods
+	if ipan == nil {
+		delete(r.shortcuts, sc)
+		return
+	}
+	r.shortcuts[sc] = &shortcutBinding{ipan: ipan}
+}
+
+// RemoveShortcut removes the registration of the specified shortcut
+func (r *Root) RemoveShortcut(sc Shortcut) {
+
+	sc.Mods &= shortcutMods
+	delete(r.shortcuts, sc)
+}
+
+// dispatchShortcut calls the handler or dispatches OnShortcut to the panel
+// registered for the specified key event and returns if it was used.
+// While a modal dialog is shown only the shortcuts of its panels are used.
+func (r *Root) dispatchShortcut(kev *window.KeyEvent) bool {
+
+	sc := Shortcut{window.LayoutKey(kev.W, kev.Keycode, kev.Scancode), kev.Mods & shortcutMods}
+	b := r.shortcuts[sc]
+	if b == nil {
+		return false
+	}
+	if b.ipan == nil {
+		if r.TopModal() != nil {
+			return false
+		}
+		b.cb(sc)
+		return true
+	}
+	// The panel and all its parents must be enabled and visible
+	var inode core.INode = b.ipan
+	for inode != nil {
+		if ipan, ok := inode.(IPanel); ok && !ipan.GetPanel().Enabled() {
+			return false
+		}
+		if !inode.GetNode().Visible() {
+			return false
+		}
+		inode = inode.GetNode().Parent()
+	}
+	if r.TopModal() != nil && (!visibleInRoot(b.ipan) || !r.modalAllows(b.ipan)) {
+		return false
+	}
+	b.ipan.SetRoot(r)
+	b.ipan.GetPanel().Dispatch(OnShortcut, sc)
+	return true
+}
+
+// SetAccelerator registers the specified shortcut in the specified root
+// panel to activate this menu item and shows it in the item
+func (mi *MenuItem) SetAccelerator(r *Root, sc Shortcut) *MenuItem {
+
+	r.SetShortcutPanel(sc, mi)
+	return mi.SetShortcut(sc.String())
+}
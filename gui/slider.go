@@ -7,33 +7,42 @@ package gui
 import (
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/window"
+	"math"
 )
 
 /***************************************
 
  Slider
- +--------------------------------+
- |  +--------------------------+  |
- |  |      +----------+        |  |
- |  |      |          |        |  |
- |  |      |          |        |  |
- |  |      +----------+        |  |
- |  +--------------------------+  |
- +--------------------------------+
+ +--------------------------------------------+
+ |  +--------------------------+  +--------+  |
+ |  |      +----------+        |  |        |  |
+ |  |      |          |        |  | value  |  |
+ |  |      |          |        |  | field  |  |
+ |  |      +----------+        |  |        |  |
+ |  +--------------------------+  +--------+  |
+ +--------------------------------------------+
 
 **/
 
+// Slider is a widget to choose a value in a range by dragging a bar.
+// It may show an edit field with its value at the end of the bar which may be
+// edited to set the value, and the value may change linearly or
+// logarithmically with the bar position.
 type Slider struct {
 	Panel                     // Embedded panel
 	slider      Panel         // embedded slider panel
 	label       *Label        // optional label
+	field       *Edit         // optional value edit field
+	decimals    int           // number of decimals shown by the value field
 	horiz       bool          // orientation
 	styles      *SliderStyles // pointer to styles
 	pos         float32       // current slider position
 	posLast     float32       // last position of the mouse cursor when dragging
 	pressed     bool          // mouse button is pressed and dragging
 	cursorOver  bool          // mouse is over slider
-	scaleFactor float32       // scale factor (default = 1.0)
+	min         float32       // value of the start position
+	max         float32       // value of the end position
+	logarithmic bool          // value changes logarithmically with the position
 }
 
 // SliderStyle
@@ -53,6 +62,8 @@ type SliderStyles struct {
 	Disabled SliderStyle
 }
 
+const sliderFieldSpacing = 4 // spacing between the slider bar and the value field
+
 // NewHSlider creates and returns a pointer to a new horizontal slider
 // with the specified initial dimensions.
 func NewHSlider(width, height float32) *Slider {
@@ -74,7 +85,7 @@ func newSlider(horiz bool, width, height float32) *Slider {
 	s := new(Slider)
 	s.horiz = horiz
 	s.styles = &StyleDefault.Slider
-	s.scaleFactor = 1.0
+	s.max = 1.0
 
 	// Initialize main panel
 	s.Panel.Initialize(width, height)
@@ -116,30 +127,154 @@ func (s *Slider) SetText(text string) {
 	s.recalc()
 }
 
-// SetValue sets the value of the slider considering the current scale factor
+// SetValue sets the value of the slider considering its current range
 // and updates its visual appearance.
 func (s *Slider) SetValue(value float32) {
 
-	pos := value / s.scaleFactor
-	s.setPos(pos)
+	s.setPos(s.posOf(value))
 }
 
-// Value returns the current value of the slider considering the current scale factor
+// Value returns the current value of the slider considering its current range
 func (s *Slider) Value() float32 {
 
-	return s.pos * s.scaleFactor
+	return s.valueAt(s.pos)
 }
 
-// SetScaleFactor set the slider scale factor (default = 1.0)
+// SetScaleFactor sets the slider range from 0 to the specified factor (default = 1.0)
 func (s *Slider) SetScaleFactor(factor float32) {
 
-	s.scaleFactor = factor
+	s.SetRange(0, factor)
 }
 
-// ScaleFactor returns  the slider current scale factor (default = 1.0)
+// ScaleFactor returns the size of the slider current range (default = 1.0)
 func (s *Slider) ScaleFactor() float32 {
 
-	return s.scaleFactor
+	return s.max - s.min
+}
+
+// SetRange sets the values of the start and end positions of the slider,
+// keeping its current position
+func (s *Slider) SetRange(min, max float32) {
+
+	s.min = min
+	s.max = max
+	s.updateField()
+}
+
+// Range returns the values of the start and end positions of the slider
+func (s *Slider) Range() (float32, float32) {
+
+	return s.min, s.max
+}
+
+// SetLogarithmic sets if the value changes logarithmically with the
+// slider position, which is useful for ranges of several orders of
+// magnitude. It is only used if both range values are positive.
+func (s *Slider) SetLogarithmic(state bool) {
+
+	s.logarithmic = state
+	s.updateField()
+}
+
+// Logarithmic returns if the value changes logarithmically with the slider position
+func (s *Slider) Logarithmic() bool {
+
+	return s.logarithmic
+}
+
+// SetValueField shows an edit field with the specified width at the end of
+// the slider with its value formatted with the specified decimals, which may be
+// edited to set the value. A zero width removes the field.
+func (s *Slider) SetValueField(width float32, decimals int) {
+
+	if width <= 0 {
+		if s.field != nil {
+			s.Panel.Remove(s.field)
+			s.field = nil
+		}
+		s.recalc()
+		return
+	}
+	if s.field != nil {
+		s.Panel.Remove(s.field)
+	}
+	s.field = NewEdit(int(width), "")
+	s.field.Subscribe(OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if kev.Keycode == window.KeyEnter || kev.Keycode == window.KeyKPEnter {
+			s.acceptField()
+		}
+	})
+	s.field.Subscribe(OnFocusLost, func(evname string, ev interface{}) { s.acceptField() })
+	s.Panel.Add(s.field)
+	s.decimals = decimals
+	s.updateField()
+	s.recalc()
+}
+
+// ValueField returns the value edit field of the slider or nil
+func (s *Slider) ValueField() *Edit {
+
+	return s.field
+}
+
+// valueAt returns the value of the specified slider position
+func (s *Slider) valueAt(pos float32) float32 {
+
+	if s.logarithmic && s.min > 0 && s.max > 0 {
+		return s.min * math32.Pow(s.max/s.min, pos)
+	}
+	return s.min + pos*(s.max-s.min)
+}
+
+// posOf returns the slider position of the specified value
+func (s *Slider) posOf(value float32) float32 {
+
+	if s.logarithmic && s.min > 0 && s.max > 0 && s.max != s.min {
+		if value <= 0 {
+			return 0
+		}
+		return float32(math.Log(float64(value/s.min)) / math.Log(float64(s.max/s.min)))
+	}
+	if s.max == s.min {
+		return 0
+	}
+	return (value - s.min) / (s.max - s.min)
+}
+
+// acceptField sets the value from the value field text if it is
+// a valid number and shows the resulting value in the field
+func (s *Slider) acceptField() {
+
+	if value, ok := parseNumber(s.field.Text()); ok {
+		s.SetValue(value)
+	}
+	s.updateField()
+}
+
+// updateField shows the current value in the value field
+func (s *Slider) updateField() {
+
+	if s.field != nil {
+		s.field.SetText(formatNumber(s.Value(), s.decimals))
+	}
+}
+
+// trackSize returns the size of the area of the slider bar along its orientation
+func (s *Slider) trackSize() float32 {
+
+	if s.horiz {
+		size := s.Panel.ContentWidth()
+		if s.field != nil {
+			size -= s.field.Width() + sliderFieldSpacing
+		}
+		return math32.Max(size, 0)
+	}
+	size := s.Panel.ContentHeight()
+	if s.field != nil {
+		size -= s.field.Height() + sliderFieldSpacing
+	}
+	return math32.Max(size, 0)
 }
 
 // setPos sets the slider position from 0.0 to 1.0
@@ -157,6 +292,7 @@ func (s *Slider) setPos(pos float32) {
 	}
 	s.pos = pos
 	s.recalc()
+	s.updateField()
 	s.Dispatch(OnChange, nil)
 }
 
@@ -164,6 +300,10 @@ func (s *Slider) setPos(pos float32) {
 func (s *Slider) onMouse(evname string, ev interface{}) {
 
 	mev := ev.(*window.MouseEvent)
+	// Mouse events over the value field are used by the field
+	if s.field != nil && !s.pressed && s.field.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
 	switch evname {
 	case OnMouseDown:
 		s.pressed = true
@@ -213,12 +353,12 @@ func (s *Slider) onCursor(evname string, ev interface{}) {
 			delta := cev.Xpos - s.posLast
 			s.posLast = cev.Xpos
 			newpos := s.slider.Width() + delta
-			pos = newpos / s.Panel.ContentWidth()
+			pos = newpos / s.trackSize()
 		} else {
 			delta := cev.Ypos - s.posLast
 			s.posLast = cev.Ypos
 			newpos := s.slider.Height() - delta
-			pos = newpos / s.Panel.ContentHeight()
+			pos = newpos / s.trackSize()
 		}
 		s.setPos(pos)
 	}
@@ -297,16 +437,24 @@ func (s *Slider) applyStyle(ss *SliderStyle) {
 // recalc recalculates the dimensions and positions of the internal panels.
 func (s *Slider) recalc() {
 
+	track := s.trackSize()
 	if s.horiz {
 		if s.label != nil {
-			lx := (s.Panel.ContentWidth() - s.label.Width()) / 2
+			lx := (track - s.label.Width()) / 2
 			if s.Panel.ContentHeight() < s.label.Height() {
 				s.Panel.SetContentHeight(s.label.Height())
 			}
 			ly := (s.Panel.ContentHeight() - s.label.Height()) / 2
 			s.label.SetPosition(lx, ly)
 		}
-		width := s.Panel.ContentWidth() * s.pos
+		if s.field != nil {
+			if s.Panel.ContentHeight() < s.field.Height() {
+				s.Panel.SetContentHeight(s.field.Height())
+			}
+			fy := (s.Panel.ContentHeight() - s.field.Height()) / 2
+			s.field.SetPosition(track+sliderFieldSpacing, fy)
+		}
+		width := track * s.pos
 		s.slider.SetSize(width, s.Panel.ContentHeight())
 	} else {
 		if s.label != nil {
@@ -314,11 +462,18 @@ func (s *Slider) recalc() {
 				s.Panel.SetContentWidth(s.label.Width())
 			}
 			lx := (s.Panel.ContentWidth() - s.label.Width()) / 2
-			ly := (s.Panel.ContentHeight() - s.label.Height()) / 2
+			ly := (track - s.label.Height()) / 2
 			s.label.SetPosition(lx, ly)
 		}
-		height := s.Panel.ContentHeight() * s.pos
-		s.slider.SetPositionY(s.Panel.ContentHeight() - height)
+		if s.field != nil {
+			if s.Panel.ContentWidth() < s.field.Width() {
+				s.Panel.SetContentWidth(s.field.Width())
+			}
+			fx := (s.Panel.ContentWidth() - s.field.Width()) / 2
+			s.field.SetPosition(fx, track+sliderFieldSpacing)
+		}
+		height := track * s.pos
+		s.slider.SetPositionY(track - height)
 		s.slider.SetSize(s.Panel.ContentWidth(), height)
 	}
 }
@@ -0,0 +1,376 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/***************************************
+
+ SpinBox
+ +-----------------------+---+
+ |                       | ^ |
+ |  value edit           +---+
+ |                       | v |
+ +-----------------------+---+
+
+****************************************/
+
+// SpinBox is a widget to edit an integer or float number value, which may be
+// typed in its edit field, changed by steps with its arrow buttons, the
+// Up and Down keys and the mouse wheel, or changed continuously by dragging
+// its arrow buttons up and down. It dispatches OnChange when the value changes.
+type SpinBox struct {
+	Panel                    // Embedded panel
+	styles     *SpinBoxStyle // pointer to current style
+	edit       *Edit         // value edit field
+	buttons    [2]Panel      // increment and decrement buttons
+	icons      [2]Label      // icons of the buttons
+	value      float32       // current value
+	min        float32       // minimum value
+	max        float32       // maximum value
+	step       float32       // value increment of the buttons and keys
+	decimals   int           // number of decimals or 0 for integer values
+	pressed    int           // index of the pressed button or -1
+	pressY     float32       // cursor vertical position when the button was pressed
+	pressValue float32       // value when the button was pressed
+	dragging   bool          // value is being changed by dragging
+	cursorOver int           // index of the button under the cursor or -1
+}
+
+// SpinBoxStyle describes the style of the spin box arrow buttons.
+// The edit field uses the current edit style.
+type SpinBoxStyle struct {
+	ButtonBorder      BorderSizes
+	ButtonBorderColor math32.Color4
+	ButtonBgColor     math32.Color4
+	ButtonOverColor   math32.Color4
+	ButtonFgColor     math32.Color4
+	ButtonWidth       float32
+	ButtonIcons       [2]int  // icons of the increment and decrement buttons
+	DragPixels        float32 // cursor distance in pixels which changes the value by a step
+}
+
+// NewSpinBox creates and returns a pointer to a new integer spin box with the
+// specified width and initial value, without limits and with steps of 1
+func NewSpinBox(width, value float32) *SpinBox {
+
+	sb := new(SpinBox)
+	sb.styles = &StyleDefault.SpinBox
+	sb.min = math32.Inf(-1)
+	sb.max = math32.Inf(1)
+	sb.step = 1
+	sb.pressed = -1
+	sb.cursorOver = -1
+
+	sb.Panel.Initialize(0, 0)
+	sb.edit = NewEdit(0, "")
+	sb.edit.width = int(width - sb.styles.ButtonWidth - sb.edit.MinWidth())
+	sb.edit.update()
+	sb.edit.Subscribe(OnKeyDown, sb.onKey)
+	sb.edit.Subscribe(OnChange, sb.onEdit)
+	sb.edit.Subscribe(OnFocusLost, func(evname string, ev interface{}) { sb.updateText() })
+	sb.Panel.Add(sb.edit)
+
+	for i := range sb.buttons {
+		b := &sb.buttons[i]
+		b.Initialize(0, 0)
+		sb.icons[i].initialize("", StyleDefault.FontIcon)
+		b.Add(&sb.icons[i])
+		b.Subscribe(OnMouseDown, sb.onMouse)
+		b.Subscribe(OnMouseUp, sb.onMouse)
+		b.Subscribe(OnCursor, sb.onCursor)
+		pos := i
+		b.Subscribe(OnCursorEnter, func(evname string, ev interface{}) {
+			sb.cursorOver = pos
+			sb.update()
+		})
+		b.Subscribe(OnCursorLeave, func(evname string, ev interface{}) {
+			sb.cursorOver = -1
+			sb.update()
+		})
+		sb.Panel.Add(b)
+	}
+	sb.Panel.Subscribe(OnScroll, sb.onScroll)
+	sb.Panel.Subscribe(OnCursorEnter, func(evname string, ev interface{}) { sb.root.SetScrollFocus(sb) })
+	sb.Panel.Subscribe(OnCursorLeave, func(evname string, ev interface{}) { sb.root.SetScrollFocus(nil) })
+	sb.Panel.Subscribe(OnEnable, func(evname string, ev interface{}) {
+		sb.edit.SetEnabled(sb.Enabled())
+		sb.update()
+	})
+
+	sb.value = sb.clamp(value)
+	sb.updateText()
+	sb.update()
+	sb.recalc()
+	return sb
+}
+
+// SetStyles sets the spin box style overriding the default style
+func (sb *SpinBox) SetStyles(s *SpinBoxStyle) {
+
+	sb.styles = s
+	sb.update()
+	sb.recalc()
+}
+
+// SetValue sets the value of this spin box limited to its range
+// and rounded to its decimals, dispatching OnChange if it changed
+func (sb *SpinBox) SetValue(value float32) {
+
+	value = sb.clamp(value)
+	changed := value != sb.value
+	sb.value = value
+	sb.updateText()
+	if changed {
+		sb.Dispatch(OnChange, nil)
+	}
+}
+
+// Value returns the current value of this spin box
+func (sb *SpinBox) Value() float32 {
+
+	return sb.value
+}
+
+// IntValue returns the current value of this spin box rounded to an integer
+func (sb *SpinBox) IntValue() int {
+
+	return int(math32.Round(sb.value))
+}
+
+// SetRange sets the minimum and maximum values of this spin box,
+// which may be infinite, and limits the current value to them
+func (sb *SpinBox) SetRange(min, max float32) {
+
+	sb.min = min
+	sb.max = math32.Max(min, max)
+	sb.SetValue(sb.value)
+}
+
+// Range returns the minimum and maximum values of this spin box
+func (sb *SpinBox) Range() (float32, float32) {
+
+	return sb.min, sb.max
+}
+
+// SetStep sets the value increment of the arrow buttons and keys
+func (sb *SpinBox) SetStep(step float32) {
+
+	sb.step = math32.Abs(step)
+}
+
+// Step returns the value increment of the arrow buttons and keys
+func (sb *SpinBox) Step() float32 {
+
+	return sb.step
+}
+
+// SetDecimals sets the number of decimals of the value.
+// Zero, the default, makes this an integer spin box.
+func (sb *SpinBox) SetDecimals(decimals int) {
+
+	if decimals < 0 {
+		decimals = 0
+	}
+	sb.decimals = decimals
+	sb.SetValue(sb.value)
+}
+
+// Decimals returns the number of decimals of the value
+func (sb *SpinBox) Decimals() int {
+
+	return sb.decimals
+}
+
+// Edit returns the edit field of this spin box
+func (sb *SpinBox) Edit() *Edit {
+
+	return sb.edit
+}
+
+// clamp returns the specified value rounded to the decimals
+// and limited to the range of this spin box
+func (sb *SpinBox) clamp(value float32) float32 {
+
+	if math32.IsNaN(value) {
+		return sb.value
+	}
+	scale := math32.Pow(10, float32(sb.decimals))
+	value = math32.Round(value*scale) / scale
+	return math32.Max(sb.min, math32.Min(sb.max, value))
+}
+
+// updateText sets the text of the edit field from the current value
+func (sb *SpinBox) updateText() {
+
+	sb.edit.SetText(formatNumber(sb.value, sb.decimals))
+}
+
+// onEdit receives changes of the edit field text and
+// sets the value if the text is a valid number
+func (sb *SpinBox) onEdit(evname string, ev interface{}) {
+
+	value, ok := parseNumber(sb.edit.Text())
+	if !ok {
+		return
+	}
+	value = sb.clamp(value)
+	if value != sb.value {
+		sb.value = value
+		sb.Dispatch(OnChange, nil)
+	}
+}
+
+// onKey receives key events of the edit field
+func (sb *SpinBox) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	switch kev.Keycode {
+	case window.KeyUp:
+		sb.SetValue(sb.value + sb.step)
+	case window.KeyDown:
+		sb.SetValue(sb.value - sb.step)
+	case window.KeyPageUp:
+		sb.SetValue(sb.value + 10*sb.step)
+	case window.KeyPageDown:
+		sb.SetValue(sb.value - 10*sb.step)
+	case window.KeyEnter, window.KeyKPEnter:
+		// Shows the value of the text without using the key
+		sb.updateText()
+		return
+	default:
+		return
+	}
+	sb.edit.root.StopPropagation(Stop3D)
+}
+
+// onMouse receives mouse button events over the arrow buttons
+func (sb *SpinBox) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft || !sb.Enabled() {
+		return
+	}
+	switch evname {
+	case OnMouseDown:
+		sb.pressed = 0
+		if sb.buttons[1].ContainsPosition(mev.Xpos, mev.Ypos) {
+			sb.pressed = 1
+		}
+		sb.SetRoot(sb.buttons[sb.pressed].root)
+		sb.pressY = mev.Ypos
+		sb.pressValue = sb.value
+		sb.dragging = false
+		sb.root.SetMouseFocus(&sb.buttons[sb.pressed])
+		setKeyFocus(sb.root, sb.edit)
+	case OnMouseUp:
+		if sb.pressed < 0 {
+			return
+		}
+		if !sb.dragging {
+			sb.SetValue(sb.value + sb.step*float32(1-2*sb.pressed))
+		}
+		sb.pressed = -1
+		sb.dragging = false
+		sb.root.SetMouseFocus(nil)
+		sb.root.SetCursorNormal()
+	}
+	sb.update()
+	sb.root.StopPropagation(StopAll)
+}
+
+// onCursor receives cursor events over the arrow buttons
+// and changes the value while they are dragged
+func (sb *SpinBox) onCursor(evname string, ev interface{}) {
+
+	if sb.pressed < 0 {
+		return
+	}
+	cev := ev.(*window.CursorEvent)
+	steps := float32(int((sb.pressY - cev.Ypos) / sb.styles.DragPixels))
+	if !sb.dragging && steps == 0 {
+		return
+	}
+	if !sb.dragging {
+		sb.dragging = true
+		sb.root.SetCursorVResize()
+	}
+	sb.SetValue(sb.pressValue + steps*sb.step)
+	sb.root.StopPropagation(Stop3D)
+}
+
+// onScroll receives scroll events and changes the value by steps
+func (sb *SpinBox) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	if !sb.Enabled() {
+		return
+	}
+	if sev.Yoffset > 0 {
+		sb.SetValue(sb.value + sb.step)
+	} else if sev.Yoffset < 0 {
+		sb.SetValue(sb.value - sb.step)
+	}
+	sb.root.StopPropagation(StopAll)
+}
+
+// update updates the visual state of the arrow buttons
+func (sb *SpinBox) update() {
+
+	s := sb.styles
+	for i := range sb.buttons {
+		b := &sb.buttons[i]
+		b.SetBordersFrom(&s.ButtonBorder)
+		b.SetBordersColor4(&s.ButtonBorderColor)
+		if i == sb.pressed || (i == sb.cursorOver && sb.Enabled()) {
+			b.SetColor4(&s.ButtonOverColor)
+		} else {
+			b.SetColor4(&s.ButtonBgColor)
+		}
+		sb.icons[i].SetText(string(s.ButtonIcons[i]))
+		sb.icons[i].SetColor4(&s.ButtonFgColor)
+	}
+}
+
+// recalc sets the positions and sizes of the edit field and arrow buttons
+func (sb *SpinBox) recalc() {
+
+	s := sb.styles
+	width := sb.edit.Width()
+	height := sb.edit.Height()
+	for i := range sb.buttons {
+		b := &sb.buttons[i]
+		b.SetPosition(width, float32(i)*height/2)
+		b.SetSize(s.ButtonWidth, height/2)
+		icon := &sb.icons[i]
+		icon.SetFontSize(float64(height) * 0.6)
+		icon.SetPosition((b.ContentWidth()-icon.Width())/2, (b.ContentHeight()-icon.Height())/2)
+	}
+	sb.SetContentSize(width+s.ButtonWidth, height)
+}
+
+// formatNumber returns the specified number formatted with the specified decimals
+func formatNumber(value float32, decimals int) string {
+
+	return strconv.FormatFloat(float64(value), 'f', decimals, 32)
+}
+
+// parseNumber returns the number of the specified text, which may
+// use a comma as decimal separator, and if it is valid
+func parseNumber(text string) (float32, bool) {
+
+	text = strings.Replace(strings.TrimSpace(text), ",", ".", 1)
+	value, err := strconv.ParseFloat(text, 32)
+	if err != nil || math.IsNaN(value) {
+		return 0, false
+	}
+	return float32(value), true
+}
@@ -13,6 +13,7 @@ import (
 func init() {
 
 	setupDefaultStyle()
+	registerDefaultThemes()
 }
 
 // Pointer to default style
@@ -20,8 +21,9 @@ var StyleDefault *Style
 
 // All styles
 type Style struct {
-	Font          *text.Font
-	FontIcon      *text.Font
+	Font          *text.Font `json:"-"`
+	FontBold      *text.Font `json:"-"`
+	FontIcon      *text.Font `json:"-"`
 	Button        ButtonStyles
 	CheckRadio    CheckRadioStyles
 	Edit          EditStyles
@@ -35,6 +37,20 @@ type Style struct {
 	Folder        FolderStyles
 	Tree          TreeStyles
 	ControlFolder ControlFolderStyles
+	Table         TableStyles
+	TextEdit      TextEditStyles
+	Docker        DockerStyle
+	Drag          DragStyle
+	Tooltip       TooltipStyle
+	Menu          MenuStyle
+	Inspector     InspectorStyle
+	Chart         ChartStyle
+	RichLabel     RichLabelStyle
+	Notification  NotificationStyle
+	Modal         ModalStyle
+	TabBar        TabBarStyle
+	SpinBox       SpinBoxStyle
+	FocusRing     FocusRingStyle
 }
 
 const (
@@ -69,6 +85,19 @@ func setupDefaultStyle() {
 	font.SetBgColor4(&math32.Color4{1, 1, 1, 0})
 	StyleDefault.Font = font
 
+	// Creates Bold Font
+	fontBoldData := assets.MustAsset(defaultFontBold)
+	fontBold, err := text.NewFontFromData(fontBoldData)
+	if err != nil {
+		panic(err)
+	}
+	fontBold.SetLineSpacing(1.0)
+	fontBold.SetSize(14)
+	fontBold.SetDPI(72)
+	fontBold.SetFgColor4(&math32.Color4{0, 0, 0, 1})
+	fontBold.SetBgColor4(&math32.Color4{1, 1, 1, 0})
+	StyleDefault.FontBold = fontBold
+
 	// Creates Icon Font
 	fontIconData := assets.MustAsset(defaultFontIcon)
 	fontIcon, err := text.NewFontFromData(fontIconData)
@@ -177,6 +206,7 @@ func setupDefaultStyle() {
 			BgAlpha:     1.0,
 			FgColor:     fgColor,
 			HolderColor: math32.Color{0.4, 0.4, 0.4},
+			SelColor:    math32.Color{0.6, 0.75, 1},
 		},
 		Over: EditStyle{
 			Border:      BorderSizes{1, 1, 1, 1},
@@ -186,6 +216,7 @@ func setupDefaultStyle() {
 			BgAlpha:     1.0,
 			FgColor:     fgColor,
 			HolderColor: math32.Color{0.4, 0.4, 0.4},
+			SelColor:    math32.Color{0.6, 0.75, 1},
 		},
 		Focus: EditStyle{
 			Border:      BorderSizes{1, 1, 1, 1},
@@ -195,6 +226,7 @@ func setupDefaultStyle() {
 			BgAlpha:     1.0,
 			FgColor:     fgColor,
 			HolderColor: math32.Color{0.4, 0.4, 0.4},
+			SelColor:    math32.Color{0.6, 0.75, 1},
 		},
 		Disabled: EditStyle{
 			Border:      BorderSizes{1, 1, 1, 1},
@@ -204,6 +236,7 @@ func setupDefaultStyle() {
 			BgAlpha:     1.0,
 			FgColor:     fgColorDis,
 			HolderColor: math32.Color{0.4, 0.4, 0.4},
+			SelColor:    math32.Color{0.6, 0.75, 1},
 		},
 	}
 
@@ -707,4 +740,246 @@ func setupDefaultStyle() {
 		},
 	}
 
+	// Table styles
+	StyleDefault.Table = TableStyles{
+		Table: TableStyle{
+			Border:      BorderSizes{1, 1, 1, 1},
+			Paddings:    BorderSizes{0, 0, 0, 0},
+			BorderColor: borderColor,
+			BgColor:     math32.Color{1, 1, 1},
+		},
+		Header: TableCellStyle{
+			Border:      BorderSizes{0, 1, 1, 0},
+			Paddings:    BorderSizes{2, 4, 2, 4},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.7, 0.7, 0.7, 1},
+			FgColor:     fgColor,
+		},
+		Row: TableCellStyle{
+			Border:      BorderSizes{0, 1, 1, 0},
+			Paddings:    BorderSizes{2, 4, 2, 4},
+			BorderColor: math32.Color4{0.8, 0.8, 0.8, 1},
+			BgColor:     math32.Color4{1, 1, 1, 1},
+			FgColor:     fgColor,
+		},
+		Selected: TableCellStyle{
+			Border:      BorderSizes{0, 1, 1, 0},
+			Paddings:    BorderSizes{2, 4, 2, 4},
+			BorderColor: math32.Color4{0.8, 0.8, 0.8, 1},
+			BgColor:     bgColor4Sel,
+			FgColor:     fgColorSel,
+		},
+	}
+
+	// TextEdit styles
+	StyleDefault.TextEdit = TextEditStyles{
+		Normal: TextEditStyle{
+			Border:      BorderSizes{1, 1, 1, 1},
+			Paddings:    BorderSizes{2, 0, 2, 0},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.9, 0.9, 0.9, 1},
+			FgColor:     math32.Color4{0, 0, 0, 1},
+			SelColor:    math32.Color4{0.6, 0.75, 1, 1},
+			CaretColor:  math32.Color4{0, 0, 0, 1},
+		},
+		Focus: TextEditStyle{
+			Border:      BorderSizes{1, 1, 1, 1},
+			Paddings:    BorderSizes{2, 0, 2, 0},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{1, 1, 1, 1},
+			FgColor:     math32.Color4{0, 0, 0, 1},
+			SelColor:    math32.Color4{0.6, 0.75, 1, 1},
+			CaretColor:  math32.Color4{0, 0, 0, 1},
+		},
+		Disabled: TextEditStyle{
+			Border:      BorderSizes{1, 1, 1, 1},
+			Paddings:    BorderSizes{2, 0, 2, 0},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.9, 0.9, 0.9, 1},
+			FgColor:     math32.Color4{0.4, 0.4, 0.4, 1},
+			SelColor:    math32.Color4{0.8, 0.8, 0.8, 1},
+			CaretColor:  math32.Color4{0, 0, 0, 1},
+		},
+	}
+
+	// Docker style
+	StyleDefault.Docker = DockerStyle{
+		SpacerSize:        6,
+		SpacerColor:       math32.Color4{0.7, 0.7, 0.7, 1},
+		SpacerBorderColor: math32.Color4{0.5, 0.5, 0.5, 1},
+		TabBorder:         BorderSizes{1, 1, 0, 1},
+		TabPaddings:       BorderSizes{2, 6, 2, 6},
+		TabBorderColor:    borderColor,
+		TabBgColor:        math32.Color4{0.7, 0.7, 0.7, 1},
+		TabActiveColor:    math32.Color4{0.9, 0.9, 0.9, 1},
+		TabFgColor:        math32.Color4{0, 0, 0, 1},
+		PreviewColor:      math32.Color4{0, 0.5, 1, 0.3},
+	}
+
+	// Drag ghost style
+	StyleDefault.Drag = DragStyle{
+		Border:      BorderSizes{1, 1, 1, 1},
+		Paddings:    BorderSizes{2, 4, 2, 4},
+		BorderColor: borderColor,
+		AcceptColor: math32.Color4{0, 0.6, 0, 1},
+		RejectColor: math32.Color4{0.8, 0, 0, 1},
+		BgColor:     math32.Color4{1, 1, 0.9, 0.9},
+		FgColor:     math32.Color4{0, 0, 0, 1},
+	}
+
+	// Tooltip style
+	StyleDefault.Tooltip = TooltipStyle{
+		Border:      BorderSizes{1, 1, 1, 1},
+		Paddings:    BorderSizes{2, 4, 2, 4},
+		BorderColor: borderColor,
+		BgColor:     math32.Color4{1, 1, 0.88, 1},
+		FgColor:     math32.Color4{0, 0, 0, 1},
+	}
+
+	// Menu style
+	StyleDefault.Menu = MenuStyle{
+		Border:          BorderSizes{1, 1, 1, 1},
+		Paddings:        BorderSizes{2, 0, 2, 0},
+		BorderColor:     borderColor,
+		BgColor:         math32.Color4{0.95, 0.95, 0.95, 1},
+		ItemPaddings:    BorderSizes{2, 8, 2, 8},
+		FgColor:         math32.Color4{0, 0, 0, 1},
+		OverBgColor:     math32.Color4{0.6, 0.75, 1, 1},
+		OverFgColor:     math32.Color4{0, 0, 0, 1},
+		DisabledColor:   math32.Color4{0.6, 0.6, 0.6, 1},
+		ShortcutColor:   math32.Color4{0.4, 0.4, 0.4, 1},
+		SeparatorColor:  math32.Color4{0.75, 0.75, 0.75, 1},
+		SeparatorHeight: 3,
+		Spacing:         6,
+	}
+
+	// Inspector style
+	StyleDefault.Inspector = InspectorStyle{
+		LabelColor:  math32.Color4{0, 0, 0, 1},
+		HeaderColor: math32.Color4{0.2, 0.2, 0.5, 1},
+		Spacing:     6,
+		RowSpacing:  2,
+		Indent:      12,
+	}
+
+	// Chart style
+	StyleDefault.Chart = ChartStyle{
+		Border:      BorderSizes{1, 1, 1, 1},
+		Paddings:    BorderSizes{4, 4, 4, 4},
+		BorderColor: borderColor,
+		BgColor:     math32.Color4{0.95, 0.95, 0.95, 1},
+		PlotColor:   math32.Color4{1, 1, 1, 1},
+		AxisColor:   math32.Color4{0.3, 0.3, 0.3, 1},
+		GridColor:   math32.Color4{0.88, 0.88, 0.88, 1},
+		FgColor:     math32.Color4{0, 0, 0, 1},
+		FontSize:    12,
+		Spacing:     6,
+		TickSize:    4,
+		LineWidth:   1.5,
+		PointSize:   5,
+		BarWidth:    0.8,
+		Colors: []math32.Color4{
+			{0.12, 0.47, 0.71, 1},
+			{1, 0.5, 0.05, 1},
+			{0.17, 0.63, 0.17, 1},
+			{0.84, 0.15, 0.16, 1},
+			{0.58, 0.4, 0.74, 1},
+			{0.55, 0.34, 0.29, 1},
+		},
+	}
+
+	// RichLabel style
+	StyleDefault.RichLabel = RichLabelStyle{
+		BgColor:       math32.Color4{0, 0, 0, 0},
+		FgColor:       math32.Color4{0, 0, 0, 1},
+		LinkColor:     math32.Color4{0, 0.3, 0.8, 1},
+		LinkOverColor: math32.Color4{0.2, 0.5, 1, 1},
+		FontSize:      14,
+		LineSpacing:   1.2,
+	}
+
+	// Notification style
+	StyleDefault.Notification = NotificationStyle{
+		Border:        BorderSizes{1, 1, 1, 4},
+		Paddings:      BorderSizes{6, 8, 6, 6},
+		BgColor:       math32.Color4{0.98, 0.98, 0.98, 0.95},
+		FgColor:       math32.Color4{0.2, 0.2, 0.2, 1},
+		TitleColor:    math32.Color4{0, 0, 0, 1},
+		ProgressColor: math32.Color4{0.85, 0.85, 0.85, 1},
+		LevelColors: [4]math32.Color4{
+			{0.2, 0.5, 0.85, 1},
+			{0.2, 0.65, 0.3, 1},
+			{0.95, 0.6, 0.1, 1},
+			{0.85, 0.2, 0.2, 1},
+		},
+		Icons:          [4]int{assets.Info, assets.CheckCircle, assets.Warning, assets.Error},
+		Width:          280,
+		Margin:         10,
+		Spacing:        6,
+		ProgressHeight: 4,
+	}
+
+	// Modal style
+	StyleDefault.Modal = ModalStyle{
+		OverlayColor: math32.Color4{0, 0, 0, 0.3},
+	}
+
+	// TabBar style
+	StyleDefault.TabBar = TabBarStyle{
+		Border:      BorderSizes{0, 0, 0, 0},
+		Paddings:    BorderSizes{0, 0, 0, 0},
+		BorderColor: borderColor,
+		BgColor:     math32.Color4{0.8, 0.8, 0.8, 1},
+		Normal: TabStyle{
+			Border:      BorderSizes{1, 1, 0, 1},
+			Paddings:    BorderSizes{2, 6, 2, 6},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.7, 0.7, 0.7, 1},
+			FgColor:     math32.Color4{0, 0, 0, 1},
+		},
+		Over: TabStyle{
+			Border:      BorderSizes{1, 1, 0, 1},
+			Paddings:    BorderSizes{2, 6, 2, 6},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.8, 0.8, 0.8, 1},
+			FgColor:     math32.Color4{0, 0, 0, 1},
+		},
+		Selected: TabStyle{
+			Border:      BorderSizes{1, 1, 0, 1},
+			Paddings:    BorderSizes{4, 6, 2, 6},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.95, 0.95, 0.95, 1},
+			FgColor:     math32.Color4{0, 0, 0, 1},
+		},
+		Disabled: TabStyle{
+			Border:      BorderSizes{1, 1, 0, 1},
+			Paddings:    BorderSizes{2, 6, 2, 6},
+			BorderColor: borderColor,
+			BgColor:     math32.Color4{0.7, 0.7, 0.7, 1},
+			FgColor:     math32.Color4{0.5, 0.5, 0.5, 1},
+		},
+		Spacing:   4,
+		CloseIcon: assets.Close,
+		PinIcon:   assets.PinDrop,
+		ListIcon:  assets.ArrowDropDown,
+	}
+
+	// SpinBox style
+	StyleDefault.SpinBox = SpinBoxStyle{
+		ButtonBorder:      BorderSizes{1, 1, 1, 0},
+		ButtonBorderColor: borderColor,
+		ButtonBgColor:     math32.Color4{0.85, 0.85, 0.85, 1},
+		ButtonOverColor:   math32.Color4{0.95, 0.95, 0.95, 1},
+		ButtonFgColor:     math32.Color4{0, 0, 0, 1},
+		ButtonWidth:       16,
+		ButtonIcons:       [2]int{assets.ArrowDropUp, assets.ArrowDropDown},
+		DragPixels:        4,
+	}
+
+	// Focus ring style
+	StyleDefault.FocusRing = FocusRingStyle{
+		Border:      BorderSizes{2, 2, 2, 2},
+		BorderColor: math32.Color4{0.2, 0.45, 0.85, 1},
+		Margin:      1,
+	}
 }
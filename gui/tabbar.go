@@ -0,0 +1,740 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ TabBar
+ +--------+--------------+----------+-----+
+ | [p] A  | Tab B    [x] | Tab C [x]| [v] |
+ +--------+--------------+----------+-----+
+ |                                        |
+ |  content panel of the selected tab     |
+ |                                        |
+ +----------------------------------------+
+
+****************************************/
+
+// TabBar is a panel which shows a bar of tabs and the content panel of the
+// selected tab below it. Tabs may be closed by their close buttons, which
+// dispatch OnTabClose, reordered by dragging them and pinned, which keeps
+// them at the start of the bar without close buttons. When the tabs don't fit
+// in the bar the unpinned tabs are scrolled with the mouse wheel and a list
+// button at the end of the bar shows a menu with all the tabs.
+// The tab bar dispatches OnChange when the selected tab changes and
+// OnTabMove with the moved tab when a tab is dragged to another position.
+type TabBar struct {
+	Panel                 // Embedded panel
+	styles   *TabBarStyle // pointer to current style
+	header   Panel        // panel which contains the tab headers
+	list     *Label       // list button shown when the tabs overflow
+	menu     *Menu        // menu shown by the list button
+	tabs     []*Tab       // tabs with the pinned tabs first
+	selected int          // index of the selected tab or -1
+	first    int          // index of the first unpinned tab shown
+	drag     *Tab         // tab being pressed or dragged or nil
+	dragX    float32      // cursor position where the drag started
+	dragging bool         // tab moved since it was pressed
+}
+
+// Tab is a tab of a TabBar whose panel is the tab header
+type Tab struct {
+	Panel              // Embedded panel
+	bar        *TabBar // tab bar which contains this tab
+	pin        *Label  // pin icon of pinned tabs
+	label      Label   // text label
+	close      *Label  // close button or nil
+	content    IPanel  // content panel or nil
+	closable   bool    // tab has a close button
+	pinned     bool    // tab is pinned
+	cursorOver bool    // cursor is over the tab header
+}
+
+// TabCloseEvent is the parameter of the OnTabClose event dispatched by a tab bar
+// before closing a tab. Setting Cancel keeps the tab open.
+type TabCloseEvent struct {
+	Tab    *Tab
+	Cancel bool
+}
+
+// TabStyle describes the style of the tab headers
+type TabStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color4
+	FgColor     math32.Color4
+}
+
+// TabBarStyle describes the style of the tab bar and its tabs
+type TabBarStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color4 // background of the tab bar
+	Normal      TabStyle
+	Over        TabStyle
+	Selected    TabStyle
+	Disabled    TabStyle
+	Spacing     float32 // spacing between the icons and the text of the tabs
+	CloseIcon   int
+	PinIcon     int
+	ListIcon    int
+}
+
+const tabBarDragMin = 4 // cursor distance in pixels which starts dragging a tab
+
+// NewTabBar creates and returns a pointer to a new tab bar with the specified dimensions
+func NewTabBar(width, height float32) *TabBar {
+
+	tb := new(TabBar)
+	tb.Initialize(width, height)
+	return tb
+}
+
+// Initialize initializes this tab bar with the specified dimensions.
+// It is normally used when the tab bar is embedded in another object.
+func (tb *TabBar) Initialize(width, height float32) {
+
+	tb.Panel.Initialize(width, height)
+	tb.styles = &StyleDefault.TabBar
+	tb.selected = -1
+
+	tb.header.Initialize(0, 0)
+	tb.header.Subscribe(OnCursorEnter, tb.onCursor)
+	tb.header.Subscribe(OnCursorLeave, tb.onCursor)
+	tb.header.Subscribe(OnScroll, tb.onScroll)
+	tb.Panel.Add(&tb.header)
+
+	tb.list = NewIconLabel(string(tb.styles.ListIcon))
+	tb.list.SetVisible(false)
+	tb.list.Subscribe(OnMouseDown, tb.onList)
+	tb.header.Add(tb.list)
+
+	tb.menu = NewMenu()
+	tb.menu.Subscribe(OnClick, func(evname string, ev interface{}) {
+		tb.SetSelected(tb.TabPosition(ev.(*MenuItem).Value.(*Tab)))
+	})
+
+	tb.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { tb.recalc() })
+	tb.update()
+	tb.recalc()
+}
+
+// SetStyles sets the tab bar style overriding the default style
+func (tb *TabBar) SetStyles(s *TabBarStyle) {
+
+	tb.styles = s
+	tb.update()
+	tb.recalc()
+}
+
+// AddTab creates a new tab with the specified text at the end of the tab bar
+// and returns its pointer. The first tab added is selected.
+func (tb *TabBar) AddTab(text string) *Tab {
+
+	return tb.InsertTab(text, len(tb.tabs))
+}
+
+// InsertTab creates a new tab with the specified text at the specified position
+// of the tab bar, after the pinned tabs, and returns its pointer
+func (tb *TabBar) InsertTab(text string, pos int) *Tab {
+
+	tab := new(Tab)
+	tab.bar = tb
+	tab.Panel.Initialize(0, 0)
+	tab.label.initialize(text, StyleDefault.Font)
+	tab.Panel.Add(&tab.label)
+	tab.Panel.Subscribe(OnMouseDown, tab.onMouse)
+	tab.Panel.Subscribe(OnMouseUp, tab.onMouse)
+	tab.Panel.Subscribe(OnCursor, tab.onCursor)
+	tab.Panel.Subscribe(OnCursorEnter, tab.onCursor)
+	tab.Panel.Subscribe(OnCursorLeave, tab.onCursor)
+	tab.SetClosable(true)
+	tb.header.Add(tab)
+
+	pos = tb.clampPosition(pos, false)
+	tb.tabs = append(tb.tabs, nil)
+	copy(tb.tabs[pos+1:], tb.tabs[pos:])
+	tb.tabs[pos] = tab
+	if tb.selected >= pos {
+		tb.selected++
+	}
+	if tb.selected < 0 {
+		tb.selected = pos
+	}
+	tb.update()
+	tb.recalc()
+	if tb.selected == pos {
+		tb.Dispatch(OnChange, nil)
+	}
+	return tab
+}
+
+// RemoveTab removes the specified tab from this tab bar without dispatching
+// OnTabClose. If it was selected the next tab is selected.
+func (tb *TabBar) RemoveTab(tab *Tab) {
+
+	pos := tb.TabPosition(tab)
+	if pos < 0 {
+		return
+	}
+	if tb.drag == tab {
+		tb.drag = nil
+		tb.dragging = false
+		if tab.root != nil {
+			tab.root.SetMouseFocus(nil)
+		}
+	}
+	copy(tb.tabs[pos:], tb.tabs[pos+1:])
+	tb.tabs[len(tb.tabs)-1] = nil
+	tb.tabs = tb.tabs[:len(tb.tabs)-1]
+	tb.header.Remove(tab)
+	if tab.content != nil {
+		tb.Panel.Remove(tab.content)
+	}
+	tab.bar = nil
+
+	changed := tb.selected == pos
+	if tb.selected > pos || tb.selected >= len(tb.tabs) {
+		tb.selected--
+	}
+	tb.update()
+	tb.recalc()
+	if changed {
+		tb.Dispatch(OnChange, nil)
+	}
+}
+
+// CloseTab dispatches OnTabClose for the specified tab and removes it
+// unless a subscriber cancels it. It is called by the tab close buttons.
+func (tb *TabBar) CloseTab(tab *Tab) {
+
+	ev := &TabCloseEvent{Tab: tab}
+	tb.Dispatch(OnTabClose, ev)
+	if !ev.Cancel {
+		tb.RemoveTab(tab)
+	}
+}
+
+// MoveTab moves the specified tab to the specified position, which is kept
+// inside the pinned tabs if the tab is pinned or after them if not
+func (tb *TabBar) MoveTab(tab *Tab, pos int) {
+
+	from := tb.TabPosition(tab)
+	if from < 0 {
+		return
+	}
+	sel := tb.SelectedTab()
+	copy(tb.tabs[from:], tb.tabs[from+1:])
+	tb.tabs = tb.tabs[:len(tb.tabs)-1]
+	pos = tb.clampPosition(pos, tab.pinned)
+	tb.tabs = append(tb.tabs, nil)
+	copy(tb.tabs[pos+1:], tb.tabs[pos:])
+	tb.tabs[pos] = tab
+	if sel != nil {
+		tb.selected = tb.TabPosition(sel)
+	}
+	tb.recalc()
+}
+
+// TabCount returns the number of tabs
+func (tb *TabBar) TabCount() int {
+
+	return len(tb.tabs)
+}
+
+// TabAt returns the tab at the specified position or nil if the position is invalid
+func (tb *TabBar) TabAt(pos int) *Tab {
+
+	if pos < 0 || pos >= len(tb.tabs) {
+		return nil
+	}
+	return tb.tabs[pos]
+}
+
+// TabPosition returns the position of the specified tab or -1 if not found
+func (tb *TabBar) TabPosition(tab *Tab) int {
+
+	for i, curr := range tb.tabs {
+		if curr == tab {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetSelected selects the tab at the specified position, showing its content
+// panel and scrolling it into view, and dispatches OnChange if it changed
+func (tb *TabBar) SetSelected(pos int) {
+
+	if pos < 0 || pos >= len(tb.tabs) {
+		return
+	}
+	changed := pos != tb.selected
+	tb.selected = pos
+	tb.update()
+	tb.recalc()
+	if changed {
+		tb.Dispatch(OnChange, nil)
+	}
+}
+
+// Selected returns the position of the selected tab or -1 if there are no tabs
+func (tb *TabBar) Selected() int {
+
+	return tb.selected
+}
+
+// SelectedTab returns the selected tab or nil if there are no tabs
+func (tb *TabBar) SelectedTab() *Tab {
+
+	return tb.TabAt(tb.selected)
+}
+
+// clampPosition returns the specified position limited to the
+// positions of the pinned or unpinned tabs
+func (tb *TabBar) clampPosition(pos int, pinned bool) int {
+
+	npinned := tb.pinnedCount()
+	if pinned {
+		return int(math32.Clamp(float32(pos), 0, float32(npinned)))
+	}
+	return int(math32.Clamp(float32(pos), float32(npinned), float32(len(tb.tabs))))
+}
+
+// pinnedCount returns the number of pinned tabs
+func (tb *TabBar) pinnedCount() int {
+
+	count := 0
+	for count < len(tb.tabs) && tb.tabs[count].pinned {
+		count++
+	}
+	return count
+}
+
+// onList receives mouse button events over the list button
+// and pops up the menu with all the tabs
+func (tb *TabBar) onList(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	r := tb.list.root
+	if mev.Button != window.MouseButtonLeft || r == nil {
+		return
+	}
+	for tb.menu.Len() > 0 {
+		tb.menu.RemoveItem(tb.menu.ItemAt(0))
+	}
+	for _, tab := range tb.tabs {
+		mi := tb.menu.AddItem(tab.Text())
+		mi.Value = tab
+		if tab.pinned {
+			mi.SetIcon(tb.styles.PinIcon)
+		}
+		mi.SetEnabled(tab.Enabled())
+	}
+	r.PopupMenu(tb.menu, tb.list.pospix.X, tb.list.pospix.Y+tb.list.Height())
+	r.StopPropagation(StopAll)
+}
+
+// onCursor receives cursor events over the header and sets the scroll focus
+func (tb *TabBar) onCursor(evname string, ev interface{}) {
+
+	if tb.header.root == nil {
+		return
+	}
+	if evname == OnCursorEnter {
+		tb.header.root.SetScrollFocus(&tb.header)
+	} else {
+		tb.header.root.SetScrollFocus(nil)
+	}
+}
+
+// onScroll receives scroll events over the header and scrolls the unpinned tabs
+func (tb *TabBar) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	if !tb.list.Visible() {
+		return
+	}
+	if sev.Yoffset > 0 && tb.first > tb.pinnedCount() {
+		tb.first--
+		tb.recalc()
+	} else if sev.Yoffset < 0 && !tb.tabs[len(tb.tabs)-1].Visible() {
+		tb.first++
+		tb.recalc()
+	}
+	if tb.header.root != nil {
+		tb.header.root.StopPropagation(StopAll)
+	}
+}
+
+// update updates the visual state of the tab bar and its tabs
+func (tb *TabBar) update() {
+
+	s := tb.styles
+	tb.SetBordersFrom(&s.Border)
+	tb.SetBordersColor4(&s.BorderColor)
+	tb.SetPaddingsFrom(&s.Paddings)
+	tb.header.SetColor4(&s.BgColor)
+	tb.list.SetText(string(s.ListIcon))
+	tb.list.SetColor4(&s.Normal.FgColor)
+	for _, tab := range tb.tabs {
+		tab.update()
+	}
+}
+
+// recalc sets the positions of the tab headers, showing the tabs which fit in
+// the bar, and the size of the content panel of the selected tab
+func (tb *TabBar) recalc() {
+
+	width := tb.ContentWidth()
+	height := tb.list.Height()
+	for _, tab := range tb.tabs {
+		tab.recalc()
+		height = math32.Max(height, tab.Height())
+	}
+	tb.header.SetSize(width, height)
+
+	// Pinned tabs are always shown
+	npinned := tb.pinnedCount()
+	x := float32(0)
+	for _, tab := range tb.tabs[:npinned] {
+		tab.SetPosition(x, height-tab.Height())
+		tab.SetVisible(true)
+		x += tab.Width()
+	}
+
+	// Checks if the unpinned tabs overflow the bar
+	total := x
+	for _, tab := range tb.tabs[npinned:] {
+		total += tab.Width()
+	}
+	avail := width
+	overflow := total > width
+	tb.list.SetVisible(overflow)
+	if overflow {
+		avail -= tb.list.Width()
+		tb.list.SetPosition(avail, (height-tb.list.Height())/2)
+	}
+
+	// Adjusts the first unpinned tab shown to show the selected tab
+	// and to fill the bar
+	tb.first = int(math32.Clamp(float32(tb.first), float32(npinned), float32(len(tb.tabs))))
+	if !overflow {
+		tb.first = npinned
+	}
+	if tb.selected >= npinned && tb.selected < tb.first {
+		tb.first = tb.selected
+	}
+	for tb.selected >= npinned && tb.first < tb.selected && tb.selected > tb.lastShown(tb.first, avail-x) {
+		tb.first++
+	}
+	for tb.first > npinned && tb.lastShown(tb.first-1, avail-x) == len(tb.tabs)-1 {
+		tb.first--
+	}
+	last := tb.lastShown(tb.first, avail-x)
+	for i, tab := range tb.tabs[npinned:] {
+		pos := npinned + i
+		if pos < tb.first || pos > last {
+			tab.SetVisible(false)
+			continue
+		}
+		tab.SetPosition(x, height-tab.Height())
+		tab.SetVisible(true)
+		x += tab.Width()
+	}
+
+	// Content panel of the selected tab
+	for i, tab := range tb.tabs {
+		if tab.content == nil {
+			continue
+		}
+		pan := tab.content.GetPanel()
+		pan.SetVisible(i == tb.selected)
+		if i == tb.selected {
+			pan.SetPosition(0, height)
+			pan.SetSize(width, math32.Max(tb.ContentHeight()-height, 0))
+		}
+	}
+}
+
+// lastShown returns the position of the last unpinned tab shown in the specified
+// width starting at the specified position. At least the first tab is shown.
+func (tb *TabBar) lastShown(first int, width float32) int {
+
+	last := first
+	x := float32(0)
+	for pos := first; pos < len(tb.tabs); pos++ {
+		x += tb.tabs[pos].Width()
+		if x > width && pos > first {
+			break
+		}
+		last = pos
+	}
+	return last
+}
+
+// dragTo moves the tab being dragged to the position under the specified
+// screen x coordinate
+func (tb *TabBar) dragTo(x float32) {
+
+	tab := tb.drag
+	from := tb.TabPosition(tab)
+	for pos, curr := range tb.tabs {
+		if curr == tab || !curr.Visible() || curr.pinned != tab.pinned {
+			continue
+		}
+		left := curr.pospix.X
+		right := left + curr.Width()
+		// Moves the tab when the cursor passes the middle of the other tab
+		// to avoid swapping back tabs of different widths
+		if (pos < from && x >= left && x < left+curr.Width()/2) ||
+			(pos > from && x >= left+curr.Width()/2 && x < right) {
+			tb.MoveTab(tab, pos)
+			return
+		}
+	}
+}
+
+//
+// Tab methods
+//
+
+// SetText sets the text of this tab
+func (tab *Tab) SetText(text string) {
+
+	tab.label.SetText(text)
+	tab.changed()
+}
+
+// Text returns the text of this tab
+func (tab *Tab) Text() string {
+
+	return tab.label.Text()
+}
+
+// SetContent sets the content panel shown by the tab bar when this tab is
+// selected, removing the previous content panel if any
+func (tab *Tab) SetContent(ipan IPanel) {
+
+	if tab.bar != nil && tab.content != nil {
+		tab.bar.Panel.Remove(tab.content)
+	}
+	tab.content = ipan
+	if tab.bar != nil && ipan != nil {
+		tab.bar.Panel.Add(ipan)
+		tab.bar.recalc()
+	}
+}
+
+// Content returns the content panel of this tab or nil
+func (tab *Tab) Content() IPanel {
+
+	return tab.content
+}
+
+// SetClosable sets if this tab has a close button, which is not shown while it is pinned
+func (tab *Tab) SetClosable(state bool) {
+
+	tab.closable = state
+	tab.changed()
+}
+
+// Closable returns if this tab has a close button
+func (tab *Tab) Closable() bool {
+
+	return tab.closable
+}
+
+// SetPinned sets if this tab is pinned. Pinned tabs are moved to the
+// start of the tab bar, are always shown and have no close button.
+func (tab *Tab) SetPinned(state bool) {
+
+	if tab.pinned == state {
+		return
+	}
+	tab.pinned = state
+	if tab.bar != nil {
+		// Moves the tab to the end of the pinned tabs or the start of the unpinned
+		tab.bar.MoveTab(tab, tab.bar.pinnedCount())
+	}
+	tab.changed()
+}
+
+// Pinned returns if this tab is pinned
+func (tab *Tab) Pinned() bool {
+
+	return tab.pinned
+}
+
+// TabBar returns the tab bar which contains this tab or nil if it was removed
+func (tab *Tab) TabBar() *TabBar {
+
+	return tab.bar
+}
+
+// changed updates the icons and the tab bar after a tab change
+func (tab *Tab) changed() {
+
+	if tab.bar == nil {
+		return
+	}
+	s := tab.bar.styles
+	if tab.pinned && tab.pin == nil {
+		tab.pin = NewIconLabel(string(s.PinIcon))
+		tab.Panel.Add(tab.pin)
+	} else if !tab.pinned && tab.pin != nil {
+		tab.Panel.Remove(tab.pin)
+		tab.pin = nil
+	}
+	if tab.closable && !tab.pinned && tab.close == nil {
+		tab.close = NewIconLabel(string(s.CloseIcon))
+		tab.close.Subscribe(OnMouseDown, tab.onClose)
+		tab.Panel.Add(tab.close)
+	} else if (!tab.closable || tab.pinned) && tab.close != nil {
+		tab.Panel.Remove(tab.close)
+		tab.close = nil
+	}
+	tab.update()
+	tab.bar.recalc()
+}
+
+// onMouse receives subscribed mouse button events over the tab header
+func (tab *Tab) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	tb := tab.bar
+	if tb == nil {
+		return
+	}
+	switch evname {
+	case OnMouseDown:
+		if mev.Button == window.MouseButtonMiddle && tab.close != nil {
+			tb.CloseTab(tab)
+			break
+		}
+		if mev.Button != window.MouseButtonLeft {
+			return
+		}
+		tb.SetSelected(tb.TabPosition(tab))
+		tb.drag = tab
+		tb.dragX = mev.Xpos
+		tb.dragging = false
+		tab.root.SetMouseFocus(tab)
+	case OnMouseUp:
+		if tb.drag != tab {
+			return
+		}
+		tab.root.SetMouseFocus(nil)
+		if tb.dragging {
+			tb.Dispatch(OnTabMove, tab)
+		}
+		tb.drag = nil
+		tb.dragging = false
+	}
+	tab.root.StopPropagation(StopAll)
+}
+
+// onCursor receives subscribed cursor events over the tab header
+func (tab *Tab) onCursor(evname string, ev interface{}) {
+
+	switch evname {
+	case OnCursorEnter:
+		tab.cursorOver = true
+		tab.update()
+	case OnCursorLeave:
+		tab.cursorOver = false
+		tab.update()
+	case OnCursor:
+		tb := tab.bar
+		if tb == nil || tb.drag != tab {
+			return
+		}
+		cev := ev.(*window.CursorEvent)
+		if !tb.dragging && math32.Abs(cev.Xpos-tb.dragX) < tabBarDragMin {
+			return
+		}
+		tb.dragging = true
+		tb.dragTo(cev.Xpos)
+	}
+	tab.root.StopPropagation(Stop3D)
+}
+
+// onClose receives mouse button events over the close button
+func (tab *Tab) onClose(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft || tab.bar == nil {
+		return
+	}
+	tab.close.root.StopPropagation(StopAll)
+	tab.bar.CloseTab(tab)
+}
+
+// update updates the visual state of this tab header
+func (tab *Tab) update() {
+
+	if tab.bar == nil {
+		return
+	}
+	s := tab.bar.styles
+	if !tab.Enabled() {
+		tab.applyStyle(&s.Disabled)
+		return
+	}
+	if tab.bar.SelectedTab() == tab {
+		tab.applyStyle(&s.Selected)
+		return
+	}
+	if tab.cursorOver {
+		tab.applyStyle(&s.Over)
+		return
+	}
+	tab.applyStyle(&s.Normal)
+}
+
+// applyStyle applies the specified tab style
+func (tab *Tab) applyStyle(s *TabStyle) {
+
+	tab.SetBordersFrom(&s.Border)
+	tab.SetBordersColor4(&s.BorderColor)
+	tab.SetPaddingsFrom(&s.Paddings)
+	tab.SetColor4(&s.BgColor)
+	tab.label.SetColor4(&s.FgColor)
+	if tab.pin != nil {
+		tab.pin.SetColor4(&s.FgColor)
+	}
+	if tab.close != nil {
+		tab.close.SetColor4(&s.FgColor)
+	}
+}
+
+// recalc sets the positions of the icons and label and the size of this tab header
+func (tab *Tab) recalc() {
+
+	spacing := tab.bar.styles.Spacing
+	height := tab.label.Height()
+	x := float32(0)
+	if tab.pin != nil {
+		height = math32.Max(height, tab.pin.Height())
+		tab.pin.SetPosition(x, (height-tab.pin.Height())/2)
+		x += tab.pin.Width() + spacing
+	}
+	tab.label.SetPosition(x, (height-tab.label.Height())/2)
+	x += tab.label.Width()
+	if tab.close != nil {
+		x += spacing
+		height = math32.Max(height, tab.close.Height())
+		tab.close.SetPosition(x, (height-tab.close.Height())/2)
+		x += tab.close.Width()
+	}
+	tab.SetContentSize(x, height)
+}
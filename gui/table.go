@@ -0,0 +1,1378 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"github.com/g3n/engine/gui/assets"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Table is a widget which shows the rows of a TableModel as cells of columns
+// below a header with the column titles. The rows may be sorted by clicking
+// the header of a sortable column and the columns may be resized and
+// reordered by dragging the edges and the headers of the columns.
+// Rows or cells are selected by the mouse or the keyboard according to the
+// selection mode and the cells of editable columns are edited in place by
+// clicking them when selected or by pressing Enter or F2.
+type Table struct {
+	Panel                         // Embedded panel
+	styles         *TableStyles   // pointer to current styles
+	model          TableModel     // rows of the table
+	cols           []*tableColumn // columns in display order
+	header         Panel          // panel with the column headers
+	body           Panel          // panel with the visible rows
+	vscroll        *ScrollBar     // vertical scroll bar
+	rows           []*tableRow    // panels of the visible rows
+	order          []int          // model row of each table row in display order
+	first          int            // first visible table row
+	rowHeight      float32        // height of the header and of the rows
+	selMode        TableSelMode   // selection mode
+	selected       map[int]bool   // selected model rows
+	selCol         string         // id of the column of the selected cell (cell selection mode)
+	cur            int            // current table row of the keyboard cursor
+	curCol         int            // current column of the keyboard cursor
+	anchor         int            // table row of the start of range selections
+	sortCol        *tableColumn   // column used to sort the rows or nil
+	sortAsc        bool           // ascending sort order
+	drag           int            // current header drag operation
+	dragCol        int            // column being resized or moved
+	dragX          float32        // last cursor position of the drag
+	dragMoved      bool           // the column was moved by the drag
+	resizeCursor   bool           // cursor is over the edge of a column header
+	editor         TableEditor    // editor of the cell being edited
+	editRow        int            // model row of the cell being edited
+	editCol        *tableColumn   // column of the cell being edited
+	edit           *Edit          // text cell editor
+	dropdown       *DropDown      // drop down cell editor
+	dropdownItem   *ImageLabel    // item shown by the drop down cell editor
+	scrollBarEvent bool           // recalc caused by the scroll bar
+}
+
+// TableColumn describes a column of a table
+type TableColumn struct {
+	Id        string                                 // Identifier of the column used to get and set the values of the model
+	Header    string                                 // Header text
+	Width     float32                                // Width in pixels (default 100)
+	MinWidth  float32                                // Minimum width in pixels when resized (default 16)
+	Format    string                                 // Format of the values for fmt.Sprintf (default "%v")
+	Sortable  bool                                   // Rows may be sorted by clicking the header of the column
+	Resizable bool                                   // Column may be resized by dragging the right edge of its header
+	Less      func(a, b interface{}) bool            // Optional function which compares two values to sort the rows
+	Editor    TableEditor                            // Cell editor (default TableEditNone)
+	Options   []string                               // Options of the TableEditDropDown editor
+	Parse     func(text string) (interface{}, error) // Optional function which converts the edited text to a value
+}
+
+// TableModel is the interface of the rows shown by a table.
+// Rows are identified by their position in the model and columns by their ids.
+type TableModel interface {
+	RowCount() int
+	Value(row int, col string) interface{}
+	SetValue(row int, col string, value interface{})
+}
+
+// TableRows is a TableModel with the values of each row in a map by column id
+type TableRows []map[string]interface{}
+
+// TableSelMode is the selection mode of a table
+type TableSelMode int
+
+// Selection modes of tables
+const (
+	TableSelSingleRow  TableSelMode = iota // One row may be selected
+	TableSelMultiRow                       // Several rows may be selected with Ctrl and Shift clicks
+	TableSelSingleCell                     // One cell may be selected
+)
+
+// TableEditor is the kind of editor of the cells of a column
+type TableEditor int
+
+// Cell editors
+const (
+	TableEditNone     TableEditor = iota // Cells are not editable
+	TableEditText                        // Cells are edited with an Edit widget
+	TableEditCheck                       // Cells show a check box which toggles boolean values
+	TableEditDropDown                    // Cells are edited by choosing one of the column options with a DropDown widget
+)
+
+// TableEditEvent is the event dispatched with OnTableEdit when the
+// value of a cell is changed by its editor
+type TableEditEvent struct {
+	Row   int         // Model row of the cell
+	Col   string      // Column id of the cell
+	Old   interface{} // Previous value
+	Value interface{} // New value
+}
+
+// TableStyle is the style of the outer panel of a table
+type TableStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color
+}
+
+// TableCellStyle is the style of the header cells and of the cells of the rows
+type TableCellStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color4
+	FgColor     math32.Color
+}
+
+// TableStyles contains the styles of a table
+type TableStyles struct {
+	Table    TableStyle
+	Header   TableCellStyle
+	Row      TableCellStyle
+	Selected TableCellStyle
+}
+
+// tableColumn is a column of a table with its header cell
+type tableColumn struct {
+	TableColumn        // column description
+	header      Panel  // header cell
+	label       *Label // header text
+	icon        *Label // sort order icon
+}
+
+// tableRow is the panel of a visible row of a table
+type tableRow struct {
+	Panel              // Embedded panel
+	cells []*tableCell // cells in display order
+}
+
+// tableCell is the panel of a cell of a row, which clips its value
+type tableCell struct {
+	Panel        // Embedded panel
+	label *Label // cell value
+}
+
+// Header drag operations
+const (
+	tableDragNone = iota
+	tableDragResize
+	tableDragMove
+)
+
+const (
+	tableResizeZone  = 4  // width in pixels of the resize area at the right of the column headers
+	tableScrollWidth = 20 // width of the vertical scroll bar
+)
+
+// NewTable creates and returns a pointer to a new table widget with the
+// specified dimensions and columns and without rows.
+func NewTable(width, height float32, cols []TableColumn) *Table {
+
+	t := new(Table)
+	t.Panel.Initialize(width, height)
+	t.styles = &StyleDefault.Table
+	t.model = TableRows{}
+	t.selected = make(map[int]bool)
+	t.sortAsc = true
+	t.anchor = -1
+
+	t.header.Initialize(0, 0)
+	t.Panel.Add(&t.header)
+	t.body.Initialize(0, 0)
+	t.body.SetColor4(&math32.Color4{0, 0, 0, 0})
+	t.Panel.Add(&t.body)
+
+	t.Panel.Subscribe(OnMouseDown, t.onMouse)
+	t.Panel.Subscribe(OnMouseUp, t.onMouse)
+	t.Panel.Subscribe(OnCursor, t.onCursor)
+	t.Panel.Subscribe(OnCursorEnter, t.onCursor)
+	t.Panel.Subscribe(OnCursorLeave, t.onCursor)
+	t.Panel.Subscribe(OnScroll, t.onScroll)
+	t.Panel.Subscribe(OnKeyDown, t.onKey)
+	t.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { t.recalc() })
+
+	t.SetColumns(cols)
+	return t
+}
+
+// SetStyles sets the table styles overriding the default style
+func (t *Table) SetStyles(ts *TableStyles) {
+
+	t.styles = ts
+	t.applyStyle()
+}
+
+// SetColumns sets the columns of this table, removing the current ones
+func (t *Table) SetColumns(cols []TableColumn) {
+
+	t.cancelEdit()
+	for _, c := range t.cols {
+		t.header.Remove(&c.header)
+	}
+	t.cols = t.cols[0:0]
+	t.sortCol = nil
+	t.selCol = ""
+	for i := range cols {
+		c := new(tableColumn)
+		c.TableColumn = cols[i]
+		if c.Width <= 0 {
+			c.Width = 100
+		}
+		if c.MinWidth <= 0 {
+			c.MinWidth = 16
+		}
+		c.header.Initialize(0, 0)
+		c.label = NewLabel(c.Header)
+		c.header.Add(c.label)
+		c.icon = NewIconLabel(" ")
+		c.icon.SetVisible(false)
+		c.header.Add(c.icon)
+		t.header.Add(&c.header)
+		t.cols = append(t.cols, c)
+	}
+	t.curCol = 0
+	t.rows = nil
+	t.body.DisposeChildren(true)
+	t.edit = nil
+	t.dropdown = nil
+	t.applyStyle()
+}
+
+// Columns returns the descriptions of the columns of this
+// table in display order with their current widths
+func (t *Table) Columns() []TableColumn {
+
+	cols := make([]TableColumn, 0, len(t.cols))
+	for _, c := range t.cols {
+		cols = append(cols, c.TableColumn)
+	}
+	return cols
+}
+
+// SetColumnWidth sets the width in pixels of the column with the specified id
+func (t *Table) SetColumnWidth(id string, width float32) {
+
+	c := t.column(id)
+	if c == nil {
+		return
+	}
+	c.Width = math32.Max(width, c.MinWidth)
+	t.recalc()
+}
+
+// MoveColumn moves the column at the specified display position to another position
+func (t *Table) MoveColumn(from, to int) {
+
+	if from < 0 || from >= len(t.cols) || to < 0 || to >= len(t.cols) || from == to {
+		return
+	}
+	t.cancelEdit()
+	c := t.cols[from]
+	if from < to {
+		copy(t.cols[from:], t.cols[from+1:to+1])
+	} else {
+		copy(t.cols[to+1:], t.cols[to:from])
+	}
+	t.cols[to] = c
+	if t.curCol == from {
+		t.curCol = to
+	}
+
+	// The cells of the rows are rebuilt in the new order
+	t.rows = nil
+	t.body.DisposeChildren(true)
+	t.edit = nil
+	t.dropdown = nil
+	t.recalc()
+}
+
+// SetModel sets the rows shown by this table and clears the selection
+func (t *Table) SetModel(model TableModel) {
+
+	t.cancelEdit()
+	t.model = model
+	t.selected = make(map[int]bool)
+	t.cur = 0
+	t.first = 0
+	t.anchor = -1
+	t.sortRows()
+	t.recalc()
+	t.Dispatch(OnChange, nil)
+}
+
+// SetRows sets the rows shown by this table from maps of values by column id
+func (t *Table) SetRows(rows []map[string]interface{}) {
+
+	t.SetModel(TableRows(rows))
+}
+
+// Model returns the rows shown by this table
+func (t *Table) Model() TableModel {
+
+	return t.model
+}
+
+// Refresh updates this table after the rows of its model were changed,
+// sorting them again if necessary. Selected rows which were removed
+// from the model are unselected.
+func (t *Table) Refresh() {
+
+	count := t.model.RowCount()
+	for row := range t.selected {
+		if row >= count {
+			delete(t.selected, row)
+		}
+	}
+	t.sortRows()
+	t.recalc()
+}
+
+// RowCount returns the number of rows of this table
+func (t *Table) RowCount() int {
+
+	return len(t.order)
+}
+
+// RowAt returns the model row shown at the specified position of the
+// table, which differs from the position if the rows are sorted.
+func (t *Table) RowAt(pos int) int {
+
+	return t.order[pos]
+}
+
+// SetSelectionMode sets the selection mode and clears the selection
+func (t *Table) SetSelectionMode(mode TableSelMode) {
+
+	t.selMode = mode
+	t.ClearSelection()
+}
+
+// SelectionMode returns the selection mode
+func (t *Table) SelectionMode() TableSelMode {
+
+	return t.selMode
+}
+
+// SelectedRows returns the model rows which are selected in ascending order
+func (t *Table) SelectedRows() []int {
+
+	rows := make([]int, 0, len(t.selected))
+	for row := range t.selected {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+// SelectedCell returns the model row and the column id of the selected
+// cell in cell selection mode and if a cell is selected
+func (t *Table) SelectedCell() (int, string, bool) {
+
+	if t.selMode != TableSelSingleCell || t.selCol == "" {
+		return 0, "", false
+	}
+	for row := range t.selected {
+		return row, t.selCol, true
+	}
+	return 0, "", false
+}
+
+// SetSelectedRow selects or unselects the specified model row.
+// In the single selection modes the other rows are unselected.
+func (t *Table) SetSelectedRow(row int, state bool) {
+
+	if row < 0 || row >= t.model.RowCount() {
+		return
+	}
+	if state && t.selMode != TableSelMultiRow {
+		t.selected = make(map[int]bool)
+	}
+	if state {
+		t.selected[row] = true
+	} else {
+		delete(t.selected, row)
+	}
+	if pos := t.position(row); pos >= 0 {
+		t.cur = pos
+		t.anchor = pos
+	}
+	t.updateRows()
+	t.Dispatch(OnChange, nil)
+}
+
+// SetSelectedCell selects the cell of the specified model row and column
+// in cell selection mode
+func (t *Table) SetSelectedCell(row int, col string) {
+
+	idx := t.columnIndex(col)
+	pos := t.position(row)
+	if t.selMode != TableSelSingleCell || idx < 0 || pos < 0 {
+		return
+	}
+	t.selectAt(pos, idx, 0)
+}
+
+// ClearSelection unselects all the rows and cells
+func (t *Table) ClearSelection() {
+
+	t.selected = make(map[int]bool)
+	t.selCol = ""
+	t.anchor = -1
+	t.updateRows()
+	t.Dispatch(OnChange, nil)
+}
+
+// SortColumn sorts the rows by the values of the column with the specified
+// id in ascending or descending order. An empty id restores the model order.
+func (t *Table) SortColumn(id string, asc bool) {
+
+	t.cancelEdit()
+	t.sortCol = t.column(id)
+	t.sortAsc = asc
+	t.sortRows()
+	t.recalc()
+	t.Dispatch(OnTableSort, nil)
+}
+
+// SortedBy returns the id of the column which sorts the rows, which
+// is empty if they are not sorted, and if the order is ascending
+func (t *Table) SortedBy() (string, bool) {
+
+	if t.sortCol == nil {
+		return "", t.sortAsc
+	}
+	return t.sortCol.Id, t.sortAsc
+}
+
+// ScrollTo scrolls the rows if necessary so the row at the specified position is visible
+func (t *Table) ScrollTo(pos int) {
+
+	visible := t.visibleRows()
+	if pos < t.first {
+		t.setFirst(pos)
+	} else if pos >= t.first+visible {
+		t.setFirst(pos - visible + 1)
+	}
+}
+
+// EditCell starts editing the cell of the specified model row and column
+// with the editor of the column. Check box cells are toggled.
+func (t *Table) EditCell(row int, col string) {
+
+	t.cancelEdit()
+	c := t.column(col)
+	pos := t.position(row)
+	if c == nil || pos < 0 {
+		return
+	}
+	switch c.Editor {
+	case TableEditCheck:
+		v, _ := t.model.Value(row, c.Id).(bool)
+		t.setValue(row, c, !v)
+		return
+	case TableEditText, TableEditDropDown:
+		if t.root == nil {
+			return
+		}
+	default:
+		return
+	}
+	t.ScrollTo(pos)
+	t.recalc()
+
+	// Position of the cell in the body panel
+	var x float32
+	for _, curr := range t.cols {
+		if curr == c {
+			break
+		}
+		x += curr.Width
+	}
+	y := float32(pos-t.first) * t.rowHeight
+	value := t.model.Value(row, c.Id)
+	t.editor = c.Editor
+	t.editRow = row
+	t.editCol = c
+
+	// Text editor
+	if c.Editor == TableEditText {
+		if t.edit == nil {
+			t.edit = NewEdit(int(c.Width), "")
+			t.edit.MaxLength = 1024
+			t.edit.Subscribe(OnKeyDown, t.onEditKey)
+			t.edit.Subscribe(OnMouseOut, func(evname string, ev interface{}) { t.endEdit(true) })
+			t.body.Add(t.edit)
+		}
+		ed := t.edit
+		ed.SetRoot(t.root)
+		ed.width = int(c.Width)
+		ed.SetText(c.format(value))
+		ed.SetPosition(x, y)
+		ed.SetVisible(true)
+		t.body.SetTopChild(ed)
+		t.root.SetKeyFocus(ed)
+		ed.focus = true
+		ed.blinkID = t.root.SetInterval(750*time.Millisecond, nil, ed.blink)
+		ed.CursorEnd()
+		ed.update()
+		return
+	}
+
+	// Drop down editor
+	if t.dropdown == nil {
+		t.dropdownItem = NewImageLabel("")
+		t.dropdown = NewDropDown(c.Width, t.dropdownItem)
+		t.dropdown.Subscribe(OnChange, t.onDropDownChange)
+		t.dropdown.list.Subscribe(OnMouseDown, t.onDropDownList)
+		t.dropdown.list.Subscribe(OnMouseOut, t.onDropDownList)
+		t.dropdown.list.Subscribe(OnKeyDown, t.onDropDownList)
+		t.body.Add(t.dropdown)
+	}
+	dd := t.dropdown
+	for dd.list.Len() > 0 {
+		dd.list.RemoveAt(0)
+	}
+	for _, opt := range c.Options {
+		dd.Add(NewImageLabel(opt))
+	}
+	t.dropdownItem.SetText(c.format(value))
+	dd.SetRoot(t.root)
+	dd.list.SetRoot(t.root)
+	dd.SetWidth(c.Width)
+	dd.recalc()
+	dd.SetPosition(x, y)
+	dd.SetVisible(true)
+	t.body.SetTopChild(dd)
+	dd.list.SetVisible(true)
+	t.root.SetKeyFocus(dd.list)
+}
+
+// Editing returns if a cell is being edited
+func (t *Table) Editing() bool {
+
+	return t.editor != TableEditNone
+}
+
+// RowCount satisfies the TableModel interface
+func (rows TableRows) RowCount() int {
+
+	return len(rows)
+}
+
+// Value satisfies the TableModel interface
+func (rows TableRows) Value(row int, col string) interface{} {
+
+	return rows[row][col]
+}
+
+// SetValue satisfies the TableModel interface
+func (rows TableRows) SetValue(row int, col string, value interface{}) {
+
+	if rows[row] == nil {
+		rows[row] = make(map[string]interface{})
+	}
+	rows[row][col] = value
+}
+
+// column returns the column with the specified id or nil
+func (t *Table) column(id string) *tableColumn {
+
+	if idx := t.columnIndex(id); idx >= 0 {
+		return t.cols[idx]
+	}
+	return nil
+}
+
+// columnIndex returns the display position of the column with the specified id or -1
+func (t *Table) columnIndex(id string) int {
+
+	for i, c := range t.cols {
+		if c.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the table position of the specified model row or -1
+func (t *Table) position(row int) int {
+
+	for pos, r := range t.order {
+		if r == row {
+			return pos
+		}
+	}
+	return -1
+}
+
+// sortRows rebuilds the display order of the model rows
+func (t *Table) sortRows() {
+
+	count := t.model.RowCount()
+	t.order = t.order[0:0]
+	for row := 0; row < count; row++ {
+		t.order = append(t.order, row)
+	}
+	if t.sortCol != nil {
+		c := t.sortCol
+		less := c.Less
+		if less == nil {
+			less = tableLess
+		}
+		sort.SliceStable(t.order, func(i, j int) bool {
+			a := t.model.Value(t.order[i], c.Id)
+			b := t.model.Value(t.order[j], c.Id)
+			if t.sortAsc {
+				return less(a, b)
+			}
+			return less(b, a)
+		})
+	}
+	if t.cur >= len(t.order) {
+		t.cur = len(t.order) - 1
+	}
+	if t.cur < 0 {
+		t.cur = 0
+	}
+}
+
+// visibleRows returns the number of rows which fit completely in the body
+func (t *Table) visibleRows() int {
+
+	if t.rowHeight <= 0 {
+		return 0
+	}
+	return int(t.body.ContentHeight() / t.rowHeight)
+}
+
+// maxFirst returns the maximum position of the first visible row
+func (t *Table) maxFirst() int {
+
+	max := len(t.order) - t.visibleRows()
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// setFirst sets the first visible row
+func (t *Table) setFirst(pos int) {
+
+	if pos > t.maxFirst() {
+		pos = t.maxFirst()
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if pos == t.first {
+		return
+	}
+	t.endEdit(true)
+	t.first = pos
+	t.recalc()
+}
+
+// cellAt returns the table row and the display column at the specified
+// screen position of the body or -1 if there is no row or column there
+func (t *Table) cellAt(x, y float32) (int, int) {
+
+	pos := t.first + int((y-t.body.pospix.Y)/t.rowHeight)
+	if pos < t.first || pos >= len(t.order) {
+		return -1, -1
+	}
+	return pos, t.columnAt(x - t.body.pospix.X)
+}
+
+// columnAt returns the display column at the specified horizontal
+// position relative to the header or the body or -1
+func (t *Table) columnAt(x float32) int {
+
+	var right float32
+	for i, c := range t.cols {
+		right += c.Width
+		if x < right {
+			return i
+		}
+	}
+	return -1
+}
+
+// isSelected returns if the cell at the specified table row and column is selected
+func (t *Table) isSelected(pos, col int) bool {
+
+	if !t.selected[t.order[pos]] {
+		return false
+	}
+	if t.selMode == TableSelSingleCell {
+		return col >= 0 && t.cols[col].Id == t.selCol
+	}
+	return true
+}
+
+// selectAt selects the row or the cell at the specified table row and
+// column according to the selection mode and the specified modifier keys
+func (t *Table) selectAt(pos, col int, mods window.ModifierKey) {
+
+	row := t.order[pos]
+	switch t.selMode {
+	case TableSelMultiRow:
+		if mods&window.ModShift != 0 && t.anchor >= 0 && t.anchor < len(t.order) {
+			if mods&window.ModControl == 0 {
+				t.selected = make(map[int]bool)
+			}
+			from, to := t.anchor, pos
+			if from > to {
+				from, to = to, from
+			}
+			for p := from; p <= to; p++ {
+				t.selected[t.order[p]] = true
+			}
+		} else if mods&window.ModControl != 0 {
+			if t.selected[row] {
+				delete(t.selected, row)
+			} else {
+				t.selected[row] = true
+			}
+			t.anchor = pos
+		} else {
+			t.selected = map[int]bool{row: true}
+			t.anchor = pos
+		}
+	case TableSelSingleCell:
+		if col < 0 {
+			return
+		}
+		t.selected = map[int]bool{row: true}
+		t.selCol = t.cols[col].Id
+		t.anchor = pos
+	default:
+		t.selected = map[int]bool{row: true}
+		t.anchor = pos
+	}
+	t.cur = pos
+	if col >= 0 {
+		t.curCol = col
+	}
+	t.ScrollTo(pos)
+	t.updateRows()
+	t.Dispatch(OnChange, nil)
+}
+
+// setValue sets the value of the specified model row and column
+// and dispatches OnTableEdit
+func (t *Table) setValue(row int, c *tableColumn, value interface{}) {
+
+	old := t.model.Value(row, c.Id)
+	t.model.SetValue(row, c.Id, value)
+	t.updateRows()
+	t.Dispatch(OnTableEdit, &TableEditEvent{Row: row, Col: c.Id, Old: old, Value: value})
+}
+
+// endEdit finishes the current cell edition, setting the edited
+// value of the text editor if commit is true
+func (t *Table) endEdit(commit bool) {
+
+	if t.editor == TableEditNone {
+		return
+	}
+	editor := t.editor
+	t.editor = TableEditNone
+	if editor == TableEditText {
+		t.edit.SetVisible(false)
+		if commit {
+			text := t.edit.Text()
+			old := t.model.Value(t.editRow, t.editCol.Id)
+			if value, err := t.editCol.parse(text, old); err == nil {
+				t.setValue(t.editRow, t.editCol, value)
+			}
+		}
+	} else {
+		t.dropdown.list.SetVisible(false)
+		t.dropdown.SetVisible(false)
+	}
+	t.editCol = nil
+	if t.root != nil {
+		t.root.SetKeyFocus(t)
+	}
+}
+
+// cancelEdit finishes the current cell edition discarding the edited value
+func (t *Table) cancelEdit() {
+
+	t.endEdit(false)
+}
+
+// onEditKey receives key events of the text cell editor
+func (t *Table) onEditKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	switch kev.Keycode {
+	case window.KeyEnter:
+		t.endEdit(true)
+	case window.KeyEscape:
+		t.endEdit(false)
+	default:
+		return
+	}
+	t.root.StopPropagation(Stop3D)
+}
+
+// onDropDownChange receives the change events of the drop down cell editor
+func (t *Table) onDropDownChange(evname string, ev interface{}) {
+
+	if t.editor != TableEditDropDown || t.dropdown.Selected() == nil {
+		return
+	}
+	t.setValue(t.editRow, t.editCol, t.dropdown.Selected().Text())
+}
+
+// onDropDownList receives the events of the list of the drop down
+// cell editor and finishes the edition when the list is closed
+func (t *Table) onDropDownList(evname string, ev interface{}) {
+
+	if t.editor == TableEditDropDown && !t.dropdown.list.Visible() {
+		t.endEdit(false)
+	}
+}
+
+// onMouse receives subscribed mouse button events over the table
+func (t *Table) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if evname == OnMouseUp {
+		if t.drag == tableDragNone {
+			return
+		}
+		// A click on a sortable header without moving it sorts the rows
+		if t.drag == tableDragMove && !t.dragMoved {
+			c := t.cols[t.dragCol]
+			if c.Sortable {
+				asc := true
+				if t.sortCol == c {
+					asc = !t.sortAsc
+				}
+				t.SortColumn(c.Id, asc)
+			}
+		}
+		t.drag = tableDragNone
+		t.root.SetMouseFocus(nil)
+		t.root.SetCursorNormal()
+		t.resizeCursor = false
+		t.root.StopPropagation(Stop3D)
+		return
+	}
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+
+	// Ignores clicks over the cell editors and the scroll bar
+	if t.editor == TableEditText && t.edit.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
+	if t.editor == TableEditDropDown && t.dropdown.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
+	if t.vscroll != nil && t.vscroll.Visible() && t.vscroll.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
+	t.root.SetKeyFocus(t)
+	t.root.StopPropagation(Stop3D)
+
+	// Header clicked: starts resizing or moving a column
+	if t.header.ContainsPosition(mev.Xpos, mev.Ypos) {
+		x := mev.Xpos - t.header.pospix.X
+		col, resize := t.headerAt(x)
+		if col < 0 {
+			return
+		}
+		t.drag = tableDragMove
+		if resize {
+			t.drag = tableDragResize
+		}
+		t.dragCol = col
+		t.dragX = mev.Xpos
+		t.dragMoved = false
+		t.root.SetMouseFocus(t)
+		return
+	}
+
+	// Body clicked: selects and edits cells
+	pos, col := t.cellAt(mev.Xpos, mev.Ypos)
+	if pos < 0 {
+		return
+	}
+	row := t.order[pos]
+	if col >= 0 && t.cols[col].Editor == TableEditCheck {
+		t.selectAt(pos, col, mev.Mods)
+		t.EditCell(row, t.cols[col].Id)
+		return
+	}
+	edit := col >= 0 && t.cols[col].Editor != TableEditNone && mev.Mods == 0 && t.isSelected(pos, col)
+	t.selectAt(pos, col, mev.Mods)
+	if edit {
+		t.EditCell(row, t.cols[col].Id)
+	}
+}
+
+// onCursor receives subscribed cursor events over the table
+func (t *Table) onCursor(evname string, ev interface{}) {
+
+	switch evname {
+	case OnCursorEnter:
+		t.root.SetScrollFocus(t)
+		return
+	case OnCursorLeave:
+		t.root.SetScrollFocus(nil)
+		if t.resizeCursor && t.drag == tableDragNone {
+			t.root.SetCursorNormal()
+			t.resizeCursor = false
+		}
+		return
+	}
+	cev := ev.(*window.CursorEvent)
+
+	// Shows the resize cursor over the edges of resizable columns
+	if t.drag == tableDragNone {
+		resize := false
+		if t.header.ContainsPosition(cev.Xpos, cev.Ypos) {
+			_, resize = t.headerAt(cev.Xpos - t.header.pospix.X)
+		}
+		if resize != t.resizeCursor {
+			if resize {
+				t.root.SetCursorHResize()
+			} else {
+				t.root.SetCursorNormal()
+			}
+			t.resizeCursor = resize
+		}
+		return
+	}
+
+	// Resizes the dragged column
+	delta := cev.Xpos - t.dragX
+	if t.drag == tableDragResize {
+		c := t.cols[t.dragCol]
+		width := math32.Max(c.Width+delta, c.MinWidth)
+		t.dragX += width - c.Width
+		c.Width = width
+		t.recalc()
+		t.root.StopPropagation(Stop3D)
+		return
+	}
+
+	// Moves the dragged column over the column under the cursor
+	if !t.dragMoved && math32.Abs(delta) < 4 {
+		return
+	}
+	if !t.dragMoved {
+		t.dragMoved = true
+		t.root.SetCursorDrag()
+	}
+	target := t.columnAt(cev.Xpos - t.header.pospix.X)
+	if target < 0 {
+		target = len(t.cols) - 1
+	}
+	if target != t.dragCol {
+		t.MoveColumn(t.dragCol, target)
+		t.dragCol = target
+	}
+	t.dragX = cev.Xpos
+	t.root.StopPropagation(Stop3D)
+}
+
+// headerAt returns the column whose header is at the specified horizontal
+// position relative to the header panel, or -1, and if the position is over
+// the resize area of the column.
+func (t *Table) headerAt(x float32) (int, bool) {
+
+	var right float32
+	for i, c := range t.cols {
+		right += c.Width
+		if x < right-tableResizeZone {
+			return i, false
+		}
+		if x < right+tableResizeZone {
+			return i, c.Resizable
+		}
+	}
+	return -1, false
+}
+
+// onScroll receives subscribed scroll events when the table has the scroll focus
+func (t *Table) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	if sev.Yoffset > 0 {
+		t.setFirst(t.first - 1)
+	} else if sev.Yoffset < 0 {
+		t.setFirst(t.first + 1)
+	}
+	t.root.StopPropagation(Stop3D)
+}
+
+// onScrollBar receives the change events of the vertical scroll bar
+func (t *Table) onScrollBar(evname string, ev interface{}) {
+
+	first := int(math.Floor(float64(t.maxFirst())*t.vscroll.Value() + 0.5))
+	if first == t.first {
+		return
+	}
+	t.scrollBarEvent = true
+	t.setFirst(first)
+	t.scrollBarEvent = false
+}
+
+// onKey receives subscribed key events when the table has the key focus
+func (t *Table) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	if len(t.order) == 0 {
+		return
+	}
+	pos := t.cur
+	col := t.curCol
+	switch kev.Keycode {
+	case window.KeyUp:
+		pos--
+	case window.KeyDown:
+		pos++
+	case window.KeyPageUp:
+		pos -= t.visibleRows()
+	case window.KeyPageDown:
+		pos += t.visibleRows()
+	case window.KeyHome:
+		pos = 0
+	case window.KeyEnd:
+		pos = len(t.order) - 1
+	case window.KeyLeft:
+		if t.selMode != TableSelSingleCell || col == 0 {
+			return
+		}
+		col--
+	case window.KeyRight:
+		if t.selMode != TableSelSingleCell || col >= len(t.cols)-1 {
+			return
+		}
+		col++
+	case window.KeyEnter, window.KeyF2, window.KeySpace:
+		c := t.editableColumn()
+		if c == nil || kev.Keycode == window.KeySpace && c.Editor != TableEditCheck {
+			return
+		}
+		t.EditCell(t.order[t.cur], c.Id)
+		t.root.StopPropagation(Stop3D)
+		return
+	default:
+		return
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if pos >= len(t.order) {
+		pos = len(t.order) - 1
+	}
+	t.selectAt(pos, col, kev.Mods&window.ModShift)
+	t.root.StopPropagation(Stop3D)
+}
+
+// editableColumn returns the column edited by the keyboard, which is the
+// current column in cell selection mode or the first editable column
+func (t *Table) editableColumn() *tableColumn {
+
+	if t.selMode == TableSelSingleCell {
+		if t.curCol < len(t.cols) && t.cols[t.curCol].Editor != TableEditNone {
+			return t.cols[t.curCol]
+		}
+		return nil
+	}
+	for _, c := range t.cols {
+		if c.Editor != TableEditNone {
+			return c
+		}
+	}
+	return nil
+}
+
+// recalc recalculates the positions and sizes of the header, the
+// scroll bar and the rows and updates the visible rows
+func (t *Table) recalc() {
+
+	width := t.ContentWidth()
+	height := t.ContentHeight()
+
+	// Header cells
+	t.header.SetPosition(0, 0)
+	t.header.SetSize(width, t.rowHeight)
+	var x float32
+	for _, c := range t.cols {
+		c.header.SetPosition(x, 0)
+		c.header.SetSize(c.Width, t.rowHeight)
+		c.label.SetPosition(0, 0)
+		if t.sortCol == c {
+			if t.sortAsc {
+				c.icon.SetText(string(assets.ArrowDropUp))
+			} else {
+				c.icon.SetText(string(assets.ArrowDropDown))
+			}
+			c.icon.SetPosition(c.header.ContentWidth()-c.icon.Width(), 0)
+			c.icon.SetVisible(true)
+		} else {
+			c.icon.SetVisible(false)
+		}
+		x += c.Width
+	}
+
+	// Body and vertical scroll bar
+	bodyHeight := math32.Max(height-t.rowHeight, 0)
+	t.body.SetPosition(0, t.rowHeight)
+	t.body.SetSize(width, bodyHeight)
+	scroll := len(t.order) > t.visibleRows()
+	if scroll {
+		if t.vscroll == nil {
+			t.vscroll = NewVScrollBar(0, 0)
+			t.vscroll.SetBorders(0, 0, 0, 1)
+			t.vscroll.Subscribe(OnChange, t.onScrollBar)
+			t.Panel.Add(t.vscroll)
+		}
+		t.vscroll.SetSize(tableScrollWidth, bodyHeight)
+		t.vscroll.SetPosition(width-tableScrollWidth, t.rowHeight)
+		t.vscroll.recalc()
+		t.vscroll.SetVisible(true)
+		t.Panel.SetTopChild(t.vscroll)
+		t.body.SetWidth(math32.Max(width-tableScrollWidth, 0))
+	} else if t.vscroll != nil {
+		t.vscroll.SetVisible(false)
+	}
+	if t.first > t.maxFirst() {
+		t.first = t.maxFirst()
+	}
+
+	// Rebuilds the row panels if the number of visible rows changed
+	count := t.visibleRows() + 1
+	if len(t.rows) != count {
+		t.buildRows(count)
+	}
+	t.updateRows()
+	if scroll && !t.scrollBarEvent && t.maxFirst() > 0 {
+		t.vscroll.SetValue(float32(t.first) / float32(t.maxFirst()))
+	}
+}
+
+// buildRows creates the specified number of row panels with
+// cells for the current columns
+func (t *Table) buildRows(count int) {
+
+	for _, row := range t.rows {
+		t.body.Remove(row)
+	}
+	t.rows = t.rows[0:0]
+	for i := 0; i < count; i++ {
+		row := new(tableRow)
+		row.Panel.Initialize(0, t.rowHeight)
+		row.Panel.SetColor4(&math32.Color4{0, 0, 0, 0})
+		for _, c := range t.cols {
+			cell := new(tableCell)
+			cell.Panel.Initialize(0, 0)
+			if c.Editor == TableEditCheck {
+				cell.label = NewIconLabel(" ")
+			} else {
+				cell.label = NewLabel(" ")
+			}
+			cell.Panel.Add(cell.label)
+			row.Panel.Add(cell)
+			row.cells = append(row.cells, cell)
+		}
+		t.body.Add(row)
+		t.rows = append(t.rows, row)
+	}
+}
+
+// updateRows updates the values and the styles of the visible rows
+func (t *Table) updateRows() {
+
+	width := t.body.ContentWidth()
+	for i, row := range t.rows {
+		pos := t.first + i
+		if pos >= len(t.order) {
+			row.SetVisible(false)
+			continue
+		}
+		mrow := t.order[pos]
+		row.SetVisible(true)
+		row.SetPosition(0, float32(i)*t.rowHeight)
+		row.SetSize(width, t.rowHeight)
+		var x float32
+		for c, cell := range row.cells {
+			col := t.cols[c]
+			style := &t.styles.Row
+			if t.isSelected(pos, c) {
+				style = &t.styles.Selected
+			}
+			cell.SetPosition(x, 0)
+			cell.applyStyle(style)
+			cell.SetSize(col.Width, t.rowHeight)
+			value := t.model.Value(mrow, col.Id)
+			if col.Editor == TableEditCheck {
+				icon := ""
+				if v, ok := value.(bool); ok {
+					icon = checkOFF
+					if v {
+						icon = checkON
+					}
+				}
+				cell.setText(icon)
+			} else {
+				cell.setText(col.format(value))
+			}
+			x += col.Width
+		}
+	}
+}
+
+// applyStyle applies the current styles to the table and its headers
+func (t *Table) applyStyle() {
+
+	s := &t.styles.Table
+	t.SetBordersFrom(&s.Border)
+	t.SetBordersColor4(&s.BorderColor)
+	t.SetPaddingsFrom(&s.Paddings)
+	t.SetColor(&s.BgColor)
+
+	hs := &t.styles.Header
+	t.header.SetColor4(&hs.BgColor)
+	for _, c := range t.cols {
+		c.header.SetBordersFrom(&hs.Border)
+		c.header.SetBordersColor4(&hs.BorderColor)
+		c.header.SetPaddingsFrom(&hs.Paddings)
+		c.header.SetColor4(&hs.BgColor)
+		c.label.SetColor(&hs.FgColor)
+		c.icon.SetColor(&hs.FgColor)
+	}
+
+	// The row height is the height of the text with the row cell borders and paddings
+	rs := &t.styles.Row
+	height := NewLabel(" ").Height() + rs.Border.Top + rs.Border.Bottom + rs.Paddings.Top + rs.Paddings.Bottom
+	if height != t.rowHeight {
+		t.rowHeight = height
+		t.rows = nil
+		t.body.DisposeChildren(true)
+		t.edit = nil
+		t.dropdown = nil
+	}
+	t.recalc()
+}
+
+// applyStyle applies the specified style to this cell
+func (cell *tableCell) applyStyle(s *TableCellStyle) {
+
+	cell.SetBordersFrom(&s.Border)
+	cell.SetBordersColor4(&s.BorderColor)
+	cell.SetPaddingsFrom(&s.Paddings)
+	cell.SetColor4(&s.BgColor)
+	var fg math32.Color4
+	fg.FromColor(&s.FgColor, 1)
+	if cell.label.Color() != fg {
+		cell.label.SetColor4(&fg)
+	}
+}
+
+// setText sets the text of this cell if it changed
+func (cell *tableCell) setText(text string) {
+
+	if text == "" {
+		text = " "
+	}
+	if cell.label.Text() != text {
+		cell.label.SetText(text)
+	}
+}
+
+// format returns the text of the specified value of this column
+func (c *tableColumn) format(value interface{}) string {
+
+	if value == nil {
+		return ""
+	}
+	if c.Format != "" {
+		return fmt.Sprintf(c.Format, value)
+	}
+	return fmt.Sprint(value)
+}
+
+// parse converts the specified edited text to a value of this column,
+// using the Parse function of the column or the type of the previous value
+func (c *tableColumn) parse(text string, old interface{}) (interface{}, error) {
+
+	if c.Parse != nil {
+		return c.Parse(text)
+	}
+	switch old.(type) {
+	case int:
+		return strconv.Atoi(text)
+	case int64:
+		return strconv.ParseInt(text, 10, 64)
+	case float32:
+		v, err := strconv.ParseFloat(text, 32)
+		return float32(v), err
+	case float64:
+		return strconv.ParseFloat(text, 64)
+	case bool:
+		return strconv.ParseBool(text)
+	}
+	return text, nil
+}
+
+// tableLess is the default comparison of the values of a column used to
+// sort the rows. Numbers, strings and booleans are compared by value and
+// other values by their default format. Nil values are the smallest.
+func tableLess(a, b interface{}) bool {
+
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	fa, oka := tableNumber(a)
+	fb, okb := tableNumber(b)
+	if oka && okb {
+		return fa < fb
+	}
+	sa, oka := a.(string)
+	sb, okb := b.(string)
+	if oka && okb {
+		return sa < sb
+	}
+	ba, oka := a.(bool)
+	bb, okb := b.(bool)
+	if oka && okb {
+		return !ba && bb
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// tableNumber returns the specified value as a float64 and if it is a number
+func tableNumber(v interface{}) (float64, bool) {
+
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,1204 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/engine/window"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TextEdit is a multi-line text editor widget with optional word wrap,
+// vertical scrolling, mouse and keyboard selection, cut/copy/paste and
+// undo/redo. An optional highlighter function may color parts of the lines,
+// as for the syntax highlight of a script editor.
+// OnChange is dispatched when the text is changed.
+type TextEdit struct {
+	Panel                          // Embedded panel
+	MaxUndo     int                // Maximum number of undo states
+	TabSize     int                // Number of spaces inserted by the Tab key
+	styles      *TextEditStyles    // pointer to current styles
+	area        Panel              // panel which shows the text image
+	caret       Panel              // caret panel
+	tex         *texture.Texture2D // texture with the image of the text
+	vscroll     *ScrollBar         // vertical scroll bar
+	lines       [][]rune           // lines of text
+	visual      []textVisual       // visual lines after word wrap
+	layout      bool               // visual lines must be recalculated
+	wrap        bool               // word wrap flag
+	readOnly    bool               // read only flag
+	highlighter TextHighlighter    // optional highlighter function
+	line        int                // line of the cursor
+	col         int                // column of the cursor
+	goalX       int                // horizontal position kept by vertical cursor moves (-1 = none)
+	selLine     int                // line of the selection anchor
+	selCol      int                // column of the selection anchor
+	selecting   bool               // selection anchor is set
+	dragging    bool               // mouse is selecting text
+	first       int                // first visible visual line
+	scrollX     int                // horizontal scroll in pixels when not wrapping
+	focus       bool               // key focus flag
+	blinkID     int                // caret blink timer id
+	caretOn     bool               // caret visibility in the blink cycle
+	undo        []textEditState    // undo states
+	redo        []textEditState    // redo states
+	typing      bool               // last change was typed text which is merged in one undo state
+	scrollEvent bool               // recalc caused by the scroll bar
+	preedit     []rune             // input method composition text
+	preCaret    int                // caret position in the composition text
+}
+
+// TextSpan is a part of a line of a TextEdit drawn with a specific color.
+// Start and End are the columns in characters of the first character of the
+// span and of the character after its last character.
+type TextSpan struct {
+	Start int
+	End   int
+	Color math32.Color4
+}
+
+// TextHighlighter is the type of the functions which return the colored spans
+// of the specified line of a TextEdit. Parts of the line not covered by spans
+// are drawn with the foreground color of the style.
+type TextHighlighter func(line int, text string) []TextSpan
+
+// TextEditStyle describes the style of a TextEdit
+type TextEditStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color4
+	FgColor     math32.Color4
+	SelColor    math32.Color4
+	CaretColor  math32.Color4
+}
+
+// TextEditStyles describes the styles of a TextEdit for each state
+type TextEditStyles struct {
+	Normal   TextEditStyle
+	Focus    TextEditStyle
+	Disabled TextEditStyle
+}
+
+// textVisual is a visual line of a TextEdit, which is the
+// whole text line or a part of it when wrapping words
+type textVisual struct {
+	line  int // text line
+	start int // column of the first character
+	end   int // column after the last character
+}
+
+// textEditState is a state of a TextEdit saved for undo and redo
+type textEditState struct {
+	text string
+	line int
+	col  int
+}
+
+const (
+	textEditMarginX    = 4
+	textEditScrollSize = 16
+)
+
+// NewTextEdit creates and returns a pointer to a new empty text editor
+// with the specified dimensions.
+func NewTextEdit(width, height float32) *TextEdit {
+
+	te := new(TextEdit)
+	te.Panel.Initialize(width, height)
+	te.styles = &StyleDefault.TextEdit
+	te.MaxUndo = 100
+	te.TabSize = 4
+	te.lines = [][]rune{{}}
+	te.layout = true
+	te.goalX = -1
+
+	te.area.Initialize(0, 0)
+	te.Panel.Add(&te.area)
+	te.caret.Initialize(1, 0)
+	te.caret.SetVisible(false)
+	te.area.Add(&te.caret)
+
+	te.Panel.Subscribe(OnMouseDown, te.onMouse)
+	te.Panel.Subscribe(OnMouseUp, te.onMouse)
+	te.Panel.Subscribe(OnCursor, te.onCursor)
+	te.Panel.Subscribe(OnCursorEnter, te.onCursor)
+	te.Panel.Subscribe(OnCursorLeave, te.onCursor)
+	te.Panel.Subscribe(OnScroll, te.onScroll)
+	te.Panel.Subscribe(OnKeyDown, te.onKey)
+	te.Panel.Subscribe(OnText, te.onText)
+	te.Panel.Subscribe(OnPreedit, te.onPreedit)
+	te.Panel.Subscribe(OnEnable, func(evname string, ev interface{}) { te.update() })
+	te.Panel.Subscribe(OnResize, func(evname string, ev interface{}) {
+		te.layout = true
+		te.recalc()
+	})
+
+	te.update()
+	return te
+}
+
+// SetText sets the text of this editor, moves the cursor to its
+// beginning and clears the undo history
+func (te *TextEdit) SetText(s string) {
+
+	te.setText(s)
+	te.line = 0
+	te.col = 0
+	te.first = 0
+	te.selecting = false
+	te.undo = te.undo[0:0]
+	te.redo = te.redo[0:0]
+	te.typing = false
+	te.recalc()
+	te.Dispatch(OnChange, nil)
+}
+
+// Text returns the text of this editor with lines separated by new lines
+func (te *TextEdit) Text() string {
+
+	lines := make([]string, len(te.lines))
+	for i, l := range te.lines {
+		lines[i] = string(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LineCount returns the number of lines of the text
+func (te *TextEdit) LineCount() int {
+
+	return len(te.lines)
+}
+
+// Line returns the text of the specified line
+func (te *TextEdit) Line(line int) string {
+
+	return string(te.lines[line])
+}
+
+// Append appends the specified text at the end of the text, moves the
+// cursor after it and scrolls to show it, as for the output of a console.
+func (te *TextEdit) Append(s string) {
+
+	te.selecting = false
+	te.line = len(te.lines) - 1
+	te.col = len(te.lines[te.line])
+	te.insert(s, false)
+}
+
+// Insert inserts the specified text at the cursor position,
+// replacing the selected text if any.
+func (te *TextEdit) Insert(s string) {
+
+	te.insert(s, false)
+}
+
+// SetWordWrap sets if long lines are wrapped at word boundaries
+func (te *TextEdit) SetWordWrap(state bool) {
+
+	te.wrap = state
+	te.scrollX = 0
+	te.layout = true
+	te.recalc()
+}
+
+// WordWrap returns if long lines are wrapped at word boundaries
+func (te *TextEdit) WordWrap() bool {
+
+	return te.wrap
+}
+
+// SetReadOnly sets if the text can be changed by the user
+func (te *TextEdit) SetReadOnly(state bool) {
+
+	te.readOnly = state
+}
+
+// ReadOnly returns if the text can be changed by the user
+func (te *TextEdit) ReadOnly() bool {
+
+	return te.readOnly
+}
+
+// SetHighlighter sets the function which returns the colored spans of the
+// lines of the text or nil to draw all the text with the foreground color.
+func (te *TextEdit) SetHighlighter(h TextHighlighter) {
+
+	te.highlighter = h
+	te.redraw()
+}
+
+// SetStyles sets the text editor styles overriding the default style
+func (te *TextEdit) SetStyles(tes *TextEditStyles) {
+
+	te.styles = tes
+	te.update()
+}
+
+// SetCursorPos sets the cursor at the specified line and column,
+// clearing the selection
+func (te *TextEdit) SetCursorPos(line, col int) {
+
+	te.selecting = false
+	te.moveCursor(line, col, false)
+}
+
+// CursorPos returns the line and column of the cursor
+func (te *TextEdit) CursorPos() (int, int) {
+
+	return te.line, te.col
+}
+
+// Select selects the text between the specified positions,
+// leaving the cursor at the second position
+func (te *TextEdit) Select(line1, col1, line2, col2 int) {
+
+	te.selecting = false
+	te.moveCursor(line1, col1, false)
+	te.moveCursor(line2, col2, true)
+}
+
+// SelectAll selects all the text
+func (te *TextEdit) SelectAll() {
+
+	last := len(te.lines) - 1
+	te.Select(0, 0, last, len(te.lines[last]))
+}
+
+// SelectedText returns the selected text or an empty string
+func (te *TextEdit) SelectedText() string {
+
+	if !te.hasSelection() {
+		return ""
+	}
+	l1, c1, l2, c2 := te.selection()
+	if l1 == l2 {
+		return string(te.lines[l1][c1:c2])
+	}
+	parts := []string{string(te.lines[l1][c1:])}
+	for l := l1 + 1; l < l2; l++ {
+		parts = append(parts, string(te.lines[l]))
+	}
+	parts = append(parts, string(te.lines[l2][:c2]))
+	return strings.Join(parts, "\n")
+}
+
+// Copy copies the selected text to the clipboard
+func (te *TextEdit) Copy() {
+
+	if te.hasSelection() {
+		SetClipboardText(te.SelectedText())
+	}
+}
+
+// Cut copies the selected text to the clipboard and removes it
+func (te *TextEdit) Cut() {
+
+	if !te.hasSelection() || te.readOnly {
+		return
+	}
+	te.Copy()
+	te.saveUndo(false)
+	te.deleteSelection()
+	te.changed()
+}
+
+// Paste inserts the text of the clipboard at the cursor position,
+// replacing the selected text if any.
+func (te *TextEdit) Paste() {
+
+	if te.readOnly {
+		return
+	}
+	s := GetClipboardText()
+	if s == "" {
+		return
+	}
+	te.insert(s, false)
+}
+
+// CanUndo returns if there are changes which can be undone
+func (te *TextEdit) CanUndo() bool {
+
+	return len(te.undo) > 0
+}
+
+// CanRedo returns if there are undone changes which can be redone
+func (te *TextEdit) CanRedo() bool {
+
+	return len(te.redo) > 0
+}
+
+// Undo restores the text before the last change
+func (te *TextEdit) Undo() {
+
+	if len(te.undo) == 0 {
+		return
+	}
+	te.redo = append(te.redo, te.state())
+	te.restore(te.undo[len(te.undo)-1])
+	te.undo = te.undo[:len(te.undo)-1]
+}
+
+// Redo restores the text before the last undo
+func (te *TextEdit) Redo() {
+
+	if len(te.redo) == 0 {
+		return
+	}
+	te.undo = append(te.undo, te.state())
+	te.restore(te.redo[len(te.redo)-1])
+	te.redo = te.redo[:len(te.redo)-1]
+}
+
+// LostKeyFocus satisfies the IPanel interface and is called by gui root
+// container when the panel loses the key focus
+func (te *TextEdit) LostKeyFocus() {
+
+	te.focus = false
+	te.preedit = nil
+	te.root.ClearTimeout(te.blinkID)
+	te.update()
+}
+
+// setText sets the lines of text from the specified string
+func (te *TextEdit) setText(s string) {
+
+	parts := strings.Split(strings.Replace(s, "\r", "", -1), "\n")
+	te.lines = make([][]rune, len(parts))
+	for i, p := range parts {
+		te.lines[i] = []rune(p)
+	}
+	te.layout = true
+}
+
+// state returns the current state of the text for undo and redo
+func (te *TextEdit) state() textEditState {
+
+	return textEditState{te.Text(), te.line, te.col}
+}
+
+// restore restores the specified state of the text
+func (te *TextEdit) restore(st textEditState) {
+
+	te.setText(st.text)
+	te.selecting = false
+	te.typing = false
+	te.line = st.line
+	te.col = st.col
+	te.goalX = -1
+	te.recalc()
+	te.scrollToCursor()
+	te.Dispatch(OnChange, nil)
+}
+
+// saveUndo saves the current state of the text before a change.
+// Consecutive typed characters are merged in one state.
+func (te *TextEdit) saveUndo(typing bool) {
+
+	if typing && te.typing {
+		return
+	}
+	te.typing = typing
+	te.undo = append(te.undo, te.state())
+	if len(te.undo) > te.MaxUndo {
+		te.undo = te.undo[len(te.undo)-te.MaxUndo:]
+	}
+	te.redo = te.redo[0:0]
+}
+
+// changed updates the editor after a change of the text
+func (te *TextEdit) changed() {
+
+	te.layout = true
+	te.goalX = -1
+	te.recalc()
+	te.scrollToCursor()
+	te.Dispatch(OnChange, nil)
+}
+
+// hasSelection returns if there is selected text
+func (te *TextEdit) hasSelection() bool {
+
+	return te.selecting && (te.selLine != te.line || te.selCol != te.col)
+}
+
+// selection returns the start and end positions of the selection in text order
+func (te *TextEdit) selection() (int, int, int, int) {
+
+	if te.selLine < te.line || te.selLine == te.line && te.selCol < te.col {
+		return te.selLine, te.selCol, te.line, te.col
+	}
+	return te.line, te.col, te.selLine, te.selCol
+}
+
+// deleteSelection removes the selected text and moves the cursor to its start
+func (te *TextEdit) deleteSelection() {
+
+	l1, c1, l2, c2 := te.selection()
+	line := append(append([]rune{}, te.lines[l1][:c1]...), te.lines[l2][c2:]...)
+	te.lines = append(te.lines[:l1+1], te.lines[l2+1:]...)
+	te.lines[l1] = line
+	te.line = l1
+	te.col = c1
+	te.selecting = false
+}
+
+// insert inserts the specified text at the cursor position replacing
+// the selected text and moves the cursor after the inserted text
+func (te *TextEdit) insert(s string, typing bool) {
+
+	te.saveUndo(typing)
+	if te.hasSelection() {
+		te.deleteSelection()
+	}
+	te.selecting = false
+	parts := strings.Split(strings.Replace(s, "\r", "", -1), "\n")
+	cur := te.lines[te.line]
+	tail := append([]rune{}, cur[te.col:]...)
+	head := append(cur[:te.col:te.col], []rune(parts[0])...)
+	if len(parts) == 1 {
+		te.lines[te.line] = append(head, tail...)
+		te.col = len(head)
+	} else {
+		added := make([][]rune, len(parts)-1)
+		for i := 1; i < len(parts); i++ {
+			added[i-1] = []rune(parts[i])
+		}
+		last := len(added) - 1
+		te.col = len(added[last])
+		added[last] = append(added[last], tail...)
+		te.lines[te.line] = head
+		rest := append(added, te.lines[te.line+1:]...)
+		te.lines = append(te.lines[:te.line+1], rest...)
+		te.line += len(added)
+	}
+	te.changed()
+}
+
+// deleteChars deletes the selected text or the character before
+// (back is true) or after the cursor, joining lines if necessary.
+func (te *TextEdit) deleteChars(back bool) {
+
+	if te.hasSelection() {
+		te.saveUndo(false)
+		te.deleteSelection()
+		te.changed()
+		return
+	}
+	te.selecting = false
+	l1, c1, l2, c2 := te.line, te.col, te.line, te.col
+	if back {
+		if c1 > 0 {
+			c1--
+		} else if l1 > 0 {
+			l1--
+			c1 = len(te.lines[l1])
+		} else {
+			return
+		}
+	} else {
+		if c2 < len(te.lines[l2]) {
+			c2++
+		} else if l2 < len(te.lines)-1 {
+			l2++
+			c2 = 0
+		} else {
+			return
+		}
+	}
+	te.saveUndo(false)
+	te.selLine, te.selCol, te.line, te.col = l1, c1, l2, c2
+	te.selecting = true
+	te.deleteSelection()
+	te.changed()
+}
+
+// moveCursor moves the cursor to the specified position, which is clamped
+// to the text, extending the selection if select is true.
+func (te *TextEdit) moveCursor(line, col int, sel bool) {
+
+	if sel && !te.selecting {
+		te.selecting = true
+		te.selLine = te.line
+		te.selCol = te.col
+	} else if !sel {
+		te.selecting = false
+	}
+	if line < 0 {
+		line, col = 0, 0
+	}
+	if line >= len(te.lines) {
+		line = len(te.lines) - 1
+		col = len(te.lines[line])
+	}
+	if col < 0 {
+		col = 0
+	}
+	if col > len(te.lines[line]) {
+		col = len(te.lines[line])
+	}
+	te.line = line
+	te.col = col
+	te.typing = false
+	te.scrollToCursor()
+	te.redraw()
+}
+
+// moveVertical moves the cursor the specified number of visual lines
+// keeping its horizontal position
+func (te *TextEdit) moveVertical(delta int, sel bool) {
+
+	te.updateLayout()
+	v := te.visualOf(te.line, te.col)
+	if te.goalX < 0 {
+		te.goalX = te.measure(te.lines[te.line][te.visual[v].start:te.col])
+	}
+	goalX := te.goalX
+	v += delta
+	if v < 0 {
+		te.moveCursor(0, 0, sel)
+	} else if v >= len(te.visual) {
+		last := len(te.lines) - 1
+		te.moveCursor(last, len(te.lines[last]), sel)
+	} else {
+		te.moveCursor(te.visual[v].line, te.columnAt(v, goalX), sel)
+	}
+	te.goalX = goalX
+}
+
+// wordLeft returns the column of the start of the word before the cursor
+func (te *TextEdit) wordLeft() int {
+
+	line := te.lines[te.line]
+	col := te.col
+	for col > 0 && line[col-1] == ' ' {
+		col--
+	}
+	for col > 0 && line[col-1] != ' ' {
+		col--
+	}
+	return col
+}
+
+// wordRight returns the column of the end of the word after the cursor
+func (te *TextEdit) wordRight() int {
+
+	line := te.lines[te.line]
+	col := te.col
+	for col < len(line) && line[col] == ' ' {
+		col++
+	}
+	for col < len(line) && line[col] != ' ' {
+		col++
+	}
+	return col
+}
+
+// onKey receives subscribed key events
+func (te *TextEdit) onKey(evname string, ev interface{}) {
+
+	// Keys are handled by the input method while composing
+	if len(te.preedit) > 0 {
+		return
+	}
+	kev := ev.(*window.KeyEvent)
+	sel := kev.Mods&window.ModShift != 0
+	ctrl := kev.Mods&window.ModControl != 0
+	if ctrl {
+		switch window.LayoutKey(kev.W, kev.Keycode, kev.Scancode) {
+		case window.KeyA:
+			te.SelectAll()
+		case window.KeyC:
+			te.Copy()
+		case window.KeyX:
+			te.Cut()
+		case window.KeyV:
+			te.Paste()
+		case window.KeyZ:
+			if te.readOnly {
+				break
+			}
+			if sel {
+				te.Redo()
+			} else {
+				te.Undo()
+			}
+		case window.KeyY:
+			if !te.readOnly {
+				te.Redo()
+			}
+		case window.KeyHome:
+			te.moveCursor(0, 0, sel)
+		case window.KeyEnd:
+			last := len(te.lines) - 1
+			te.moveCursor(last, len(te.lines[last]), sel)
+		case window.KeyLeft:
+			te.moveCursor(te.line, te.wordLeft(), sel)
+		case window.KeyRight:
+			te.moveCursor(te.line, te.wordRight(), sel)
+		default:
+			return
+		}
+		te.root.StopPropagation(Stop3D)
+		return
+	}
+
+	switch kev.Keycode {
+	case window.KeyLeft:
+		if te.col == 0 && te.line > 0 {
+			te.moveCursor(te.line-1, len(te.lines[te.line-1]), sel)
+		} else {
+			te.moveCursor(te.line, te.col-1, sel)
+		}
+	case window.KeyRight:
+		if te.col == len(te.lines[te.line]) && te.line < len(te.lines)-1 {
+			te.moveCursor(te.line+1, 0, sel)
+		} else {
+			te.moveCursor(te.line, te.col+1, sel)
+		}
+	case window.KeyUp:
+		te.moveVertical(-1, sel)
+	case window.KeyDown:
+		te.moveVertical(1, sel)
+	case window.KeyPageUp:
+		te.moveVertical(-te.visibleLines(), sel)
+	case window.KeyPageDown:
+		te.moveVertical(te.visibleLines(), sel)
+	case window.KeyHome:
+		te.updateLayout()
+		te.moveCursor(te.line, te.visual[te.visualOf(te.line, te.col)].start, sel)
+	case window.KeyEnd:
+		te.updateLayout()
+		te.moveCursor(te.line, te.visual[te.visualOf(te.line, te.col)].end, sel)
+	case window.KeyBackspace:
+		if !te.readOnly {
+			te.deleteChars(true)
+		}
+	case window.KeyDelete:
+		if !te.readOnly {
+			te.deleteChars(false)
+		}
+	case window.KeyEnter, window.KeyKPEnter:
+		if !te.readOnly {
+			te.insert("\n", false)
+		}
+	case window.KeyTab:
+		if !te.readOnly {
+			te.insert(strings.Repeat(" ", te.TabSize), true)
+		}
+	default:
+		return
+	}
+	if kev.Keycode != window.KeyUp && kev.Keycode != window.KeyDown &&
+		kev.Keycode != window.KeyPageUp && kev.Keycode != window.KeyPageDown {
+		te.goalX = -1
+	}
+	te.root.StopPropagation(Stop3D)
+}
+
+// onText receives subscribed text input events
+func (te *TextEdit) onText(evname string, ev interface{}) {
+
+	if te.readOnly {
+		return
+	}
+	tev := ev.(*window.TextEvent)
+	te.insert(tev.Text, true)
+	te.root.StopPropagation(Stop3D)
+}
+
+// onPreedit receives subscribed input method composition events
+func (te *TextEdit) onPreedit(evname string, ev interface{}) {
+
+	if te.readOnly {
+		return
+	}
+	pev := ev.(*window.PreeditEvent)
+	te.preedit = []rune(pev.Text)
+	te.preCaret = pev.Caret
+	if te.preCaret < 0 || te.preCaret > len(te.preedit) {
+		te.preCaret = len(te.preedit)
+	}
+	te.redraw()
+	te.root.StopPropagation(Stop3D)
+}
+
+// onMouse receives subscribed mouse button events
+func (te *TextEdit) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if evname == OnMouseUp {
+		if te.dragging {
+			te.dragging = false
+			te.root.SetMouseFocus(nil)
+		}
+		return
+	}
+	if mev.Button != window.MouseButtonLeft || !te.Enabled() {
+		return
+	}
+	if te.vscroll != nil && te.vscroll.Visible() && te.vscroll.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
+
+	te.focusIn()
+	line, col := te.positionAt(mev.Xpos, mev.Ypos)
+	te.moveCursor(line, col, mev.Mods&window.ModShift != 0)
+	te.goalX = -1
+	te.dragging = true
+	te.root.SetMouseFocus(te)
+	te.root.StopPropagation(Stop3D)
+}
+
+// focusIn sets the key focus to this text edit and starts blinking the caret
+func (te *TextEdit) focusIn() {
+
+	te.root.SetKeyFocus(te)
+	if !te.focus && te.root.HasKeyFocus(te) {
+		te.focus = true
+		te.blinkID = te.root.SetInterval(500*time.Millisecond, nil, te.blink)
+		te.update()
+	}
+}
+
+// onCursor receives subscribed cursor events
+func (te *TextEdit) onCursor(evname string, ev interface{}) {
+
+	switch evname {
+	case OnCursorEnter:
+		te.root.SetScrollFocus(te)
+	case OnCursorLeave:
+		te.root.SetScrollFocus(nil)
+	case OnCursor:
+		if !te.dragging {
+			return
+		}
+		cev := ev.(*window.CursorEvent)
+		line, col := te.positionAt(cev.Xpos, cev.Ypos)
+		te.moveCursor(line, col, true)
+	}
+	te.root.StopPropagation(Stop3D)
+}
+
+// onScroll receives subscribed scroll events
+func (te *TextEdit) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	te.setFirst(te.first - int(sev.Yoffset)*3)
+	te.root.StopPropagation(Stop3D)
+}
+
+// onScrollBar receives the change events of the vertical scroll bar
+func (te *TextEdit) onScrollBar(evname string, ev interface{}) {
+
+	first := int(math.Floor(float64(te.maxFirst())*te.vscroll.Value() + 0.5))
+	te.scrollEvent = true
+	te.setFirst(first)
+	te.scrollEvent = false
+}
+
+// blink blinks the caret
+func (te *TextEdit) blink(arg interface{}) {
+
+	if !te.focus {
+		return
+	}
+	te.caretOn = !te.caretOn
+	te.updateCaret()
+}
+
+// positionAt returns the line and column of the character at the specified
+// screen position, clamped to the visible lines
+func (te *TextEdit) positionAt(x, y float32) (int, int) {
+
+	te.updateLayout()
+	v := te.first + int(math.Floor(float64((y-te.area.pospix.Y)/te.lineHeight())))
+	if v < 0 {
+		v = 0
+	}
+	if v >= len(te.visual) {
+		v = len(te.visual) - 1
+	}
+	px := int(x-te.area.pospix.X) - textEditMarginX + te.scrollX
+	return te.visual[v].line, te.columnAt(v, px)
+}
+
+// columnAt returns the column of the specified visual line
+// nearest to the specified horizontal position in pixels
+func (te *TextEdit) columnAt(v, x int) int {
+
+	vl := te.visual[v]
+	line := te.lines[vl.line]
+	prev := 0
+	for col := vl.start; col < vl.end; col++ {
+		w := te.measure(line[vl.start : col+1])
+		if x < (prev+w)/2 {
+			return col
+		}
+		prev = w
+	}
+	// A wrapped visual line ends before its last character,
+	// which is the first of the next visual line.
+	if vl.end < len(line) && vl.end > vl.start {
+		return vl.end - 1
+	}
+	return vl.end
+}
+
+// visualOf returns the visual line which contains the specified position
+func (te *TextEdit) visualOf(line, col int) int {
+
+	idx := sort.Search(len(te.visual), func(i int) bool {
+		v := te.visual[i]
+		return v.line > line || v.line == line && v.start > col
+	})
+	if idx > 0 {
+		idx--
+	}
+	return idx
+}
+
+// measure returns the width in pixels of the specified characters
+func (te *TextEdit) measure(r []rune) int {
+
+	if len(r) == 0 {
+		return 0
+	}
+	width, _ := te.font().MeasureText(string(r))
+	return width
+}
+
+// lineHeight returns the height in pixels of the lines of text
+func (te *TextEdit) lineHeight() float32 {
+
+	font := te.font()
+	return float32(math.Ceil(font.Size() * font.DPI() / 72))
+}
+
+// font sets the properties of the shared default font used
+// to measure and draw the text and returns it
+func (te *TextEdit) font() *text.Font {
+
+	font := StyleDefault.Font
+	font.SetSize(14)
+	font.SetDPI(72)
+	font.SetLineSpacing(1.0)
+	return font
+}
+
+// visibleLines returns the number of lines which fit completely in the editor
+func (te *TextEdit) visibleLines() int {
+
+	n := int(te.area.ContentHeight() / te.lineHeight())
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// maxFirst returns the maximum first visible visual line
+func (te *TextEdit) maxFirst() int {
+
+	max := len(te.visual) - te.visibleLines()
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// setFirst sets the first visible visual line
+func (te *TextEdit) setFirst(first int) {
+
+	if first > te.maxFirst() {
+		first = te.maxFirst()
+	}
+	if first < 0 {
+		first = 0
+	}
+	if first == te.first {
+		return
+	}
+	te.first = first
+	te.recalc()
+}
+
+// scrollToCursor scrolls the text if necessary so the cursor is visible
+func (te *TextEdit) scrollToCursor() {
+
+	te.updateLayout()
+	v := te.visualOf(te.line, te.col)
+	if v < te.first {
+		te.setFirst(v)
+	} else if v >= te.first+te.visibleLines() {
+		te.setFirst(v - te.visibleLines() + 1)
+	}
+	if te.wrap {
+		return
+	}
+	x := te.measure(te.lines[te.line][:te.col])
+	width := int(te.area.ContentWidth()) - 2*textEditMarginX
+	if x < te.scrollX {
+		te.scrollX = x
+	} else if x > te.scrollX+width {
+		te.scrollX = x - width
+	}
+}
+
+// updateLayout recalculates the visual lines if necessary
+func (te *TextEdit) updateLayout() {
+
+	if !te.layout {
+		return
+	}
+	te.layout = false
+	te.visual = te.visual[0:0]
+	width := int(te.area.ContentWidth()) - 2*textEditMarginX
+	for l, line := range te.lines {
+		if !te.wrap || width <= 0 {
+			te.visual = append(te.visual, textVisual{l, 0, len(line)})
+			continue
+		}
+		te.wrapLine(l, line, width)
+	}
+}
+
+// wrapLine appends the visual lines of the specified text line wrapped
+// at word boundaries to fit the specified width
+func (te *TextEdit) wrapLine(l int, line []rune, width int) {
+
+	if len(line) == 0 {
+		te.visual = append(te.visual, textVisual{l, 0, 0})
+		return
+	}
+	start := 0
+	for start < len(line) {
+		end := start
+		space := -1
+		for end < len(line) {
+			if end > start && te.measure(line[start:end+1]) > width {
+				break
+			}
+			if line[end] == ' ' {
+				space = end
+			}
+			end++
+		}
+		// Breaks after the last space which fits if a word does not fit
+		if end < len(line) && space >= start && line[end] != ' ' {
+			end = space + 1
+		}
+		te.visual = append(te.visual, textVisual{l, start, end})
+		start = end
+	}
+}
+
+// recalc recalculates the positions and sizes of the internal panels
+// and redraws the text
+func (te *TextEdit) recalc() {
+
+	width := te.ContentWidth()
+	height := te.ContentHeight()
+	te.area.SetPosition(0, 0)
+	te.area.SetSize(width, height)
+	te.updateLayout()
+
+	// Shows the scroll bar if the lines do not fit and recalculates the
+	// visual lines if the width of the text area changed
+	scroll := len(te.visual) > te.visibleLines()
+	if scroll {
+		if te.vscroll == nil {
+			te.vscroll = NewVScrollBar(0, 0)
+			te.vscroll.SetBorders(0, 0, 0, 1)
+			te.vscroll.Subscribe(OnChange, te.onScrollBar)
+			te.Panel.Add(te.vscroll)
+		}
+		te.vscroll.SetSize(textEditScrollSize, height)
+		te.vscroll.SetPosition(width-textEditScrollSize, 0)
+		te.vscroll.recalc()
+		te.vscroll.SetVisible(true)
+		te.area.SetWidth(math32.Max(width-textEditScrollSize, 0))
+		if te.wrap {
+			te.layout = true
+			te.updateLayout()
+		}
+	} else if te.vscroll != nil {
+		te.vscroll.SetVisible(false)
+	}
+	if te.first > te.maxFirst() {
+		te.first = te.maxFirst()
+	}
+	if scroll && !te.scrollEvent && te.maxFirst() > 0 {
+		te.vscroll.SetValue(float32(te.first) / float32(te.maxFirst()))
+	}
+	te.redraw()
+}
+
+// redraw draws the visible lines of text with the selection into the
+// texture of the text area and updates the caret
+func (te *TextEdit) redraw() {
+
+	te.updateLayout()
+	width := int(te.area.ContentWidth())
+	height := int(te.area.ContentHeight())
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s := te.style()
+	canvas := text.NewCanvas(width, height, &s.BgColor)
+	font := te.font()
+
+	lh := int(te.lineHeight())
+	sl1, sc1, sl2, sc2 := te.selection()
+	selected := te.hasSelection()
+	selColor := image.NewUniform(text.Color4NRGBA(&s.SelColor))
+	var spans []TextSpan
+	spansLine := -1
+	// The input method composition text is shown underlined
+	// at the cursor shifting the rest of its line
+	preV := -1
+	if len(te.preedit) > 0 {
+		preV = te.visualOf(te.line, te.col)
+	}
+	for v := te.first; v < len(te.visual); v++ {
+		y := (v - te.first) * lh
+		if y >= height {
+			break
+		}
+		vl := te.visual[v]
+		line := te.lines[vl.line]
+		x0 := textEditMarginX - te.scrollX
+
+		// Draws the selection background
+		if selected && vl.line >= sl1 && vl.line <= sl2 {
+			a, b := vl.start, vl.end
+			if vl.line == sl1 && sc1 > a {
+				a = sc1
+			}
+			if vl.line == sl2 && sc2 < b {
+				b = sc2
+			}
+			if a <= b {
+				xa := x0 + te.measure(line[vl.start:a])
+				xb := x0 + te.measure(line[vl.start:b])
+				// The new line at the end of a selected line is shown as a space
+				if vl.line < sl2 && b == len(line) {
+					xb += te.measure([]rune{' '})
+				}
+				draw.Draw(canvas.RGBA, image.Rect(xa, y, xb, y+lh), selColor, image.ZP, draw.Over)
+			}
+		}
+
+		// Draws the text spans
+		if te.highlighter != nil && spansLine != vl.line {
+			spans = te.highlighter(vl.line, string(line))
+			sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+			spansLine = vl.line
+		}
+		shift := 0
+		col := vl.start
+		for col < vl.end || v == preV && shift == 0 {
+			if v == preV && col == te.col && shift == 0 {
+				x := x0 + te.measure(line[vl.start:col])
+				shift = te.measure(te.preedit)
+				font.SetFgColor4(&s.FgColor)
+				canvas.DrawText(x, y, string(te.preedit), font)
+				under := image.NewUniform(text.Color4NRGBA(&s.FgColor))
+				base := int(math.Ceil(font.Size()*font.DPI()/72)) + 1
+				draw.Draw(canvas.RGBA, image.Rect(x, y+base, x+shift, y+base+1), under, image.ZP, draw.Over)
+				continue
+			}
+			end := vl.end
+			if v == preV && col < te.col && end > te.col {
+				end = te.col
+			}
+			color := &s.FgColor
+			for i := range spans {
+				sp := &spans[i]
+				if sp.End <= col || sp.Start >= sp.End {
+					continue
+				}
+				if sp.Start > col {
+					if sp.Start < end {
+						end = sp.Start
+					}
+					break
+				}
+				color = &sp.Color
+				if sp.End < end {
+					end = sp.End
+				}
+				break
+			}
+			font.SetFgColor4(color)
+			canvas.DrawText(x0+shift+te.measure(line[vl.start:col]), y, string(line[col:end]), font)
+			col = end
+		}
+	}
+
+	if te.tex == nil {
+		te.tex = texture.NewTexture2DFromRGBA(canvas.RGBA)
+		te.tex.SetMagFilter(gls.NEAREST)
+		te.tex.SetMinFilter(gls.NEAREST)
+		te.area.Material().AddTexture(te.tex)
+	} else {
+		te.tex.SetFromRGBA(canvas.RGBA)
+	}
+	te.caretOn = true
+	te.updateCaret()
+}
+
+// updateCaret updates the position and the visibility of the caret
+func (te *TextEdit) updateCaret() {
+
+	v := te.visualOf(te.line, te.col)
+	visible := te.focus && te.caretOn && v >= te.first && v < te.first+te.visibleLines()
+	te.caret.SetVisible(visible)
+	if !visible {
+		return
+	}
+	vl := te.visual[v]
+	x := textEditMarginX - te.scrollX + te.measure(te.lines[te.line][vl.start:te.col]) + te.measure(te.preedit[:te.preCaret])
+	lh := te.lineHeight()
+	te.caret.SetPosition(float32(x), float32(v-te.first)*lh)
+	te.caret.SetSize(1, lh)
+
+	// Informs the window of the cursor position for the input method candidates
+	if te.root != nil {
+		te.root.SetIMECursorRect(te.area.pospix.X+float32(x), te.area.pospix.Y+float32(v-te.first)*lh, 1, lh)
+	}
+}
+
+// style returns the style for the current state
+func (te *TextEdit) style() *TextEditStyle {
+
+	if !te.Enabled() {
+		return &te.styles.Disabled
+	}
+	if te.focus {
+		return &te.styles.Focus
+	}
+	return &te.styles.Normal
+}
+
+// update updates the visual state
+func (te *TextEdit) update() {
+
+	s := te.style()
+	te.SetBordersFrom(&s.Border)
+	te.SetBordersColor4(&s.BorderColor)
+	te.SetPaddingsFrom(&s.Paddings)
+	te.SetColor4(&s.BgColor)
+	te.caret.SetColor4(&s.CaretColor)
+	te.recalc()
+}
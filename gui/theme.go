@@ -0,0 +1,598 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/g3n/engine/gui/assets"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Theme is a set of widget styles which may be loaded from an external file
+// and used as the default style or applied to a hierarchy of panels.
+// A theme inherits the styles, fonts and colors of its base theme and
+// overrides only the values it specifies. Theme files are JSON documents
+// with the exported fields of the theme, for example:
+//
+//	{
+//	  "Name": "blue",
+//	  "Base": "dark",
+//	  "Colors": {"accent": "#2060c0"},
+//	  "Font": {"File": "fonts/FreeSans.ttf", "Size": 14},
+//	  "Styles": {
+//	    "Button": {
+//	      "Normal": {"BgColor": "$accent", "Border": {"Top": 1, "Right": 1, "Bottom": 1, "Left": 1}},
+//	      "Over":   {"BgColor": "#3070d0"}
+//	    }
+//	  }
+//	}
+//
+// The keys of "Styles" are the fields of the Style struct with the fields
+// of the widget style types. Colors may be specified as objects with the
+// R, G, B and A fields, as "#rgb", "#rrggbb" or "#rrggbbaa" strings or as
+// "$name" references to the named colors of the theme or its bases.
+type Theme struct {
+	Name     string            // Name used to register and select the theme
+	Base     string            // Name of the registered theme inherited by this one (default "light")
+	Colors   map[string]string // Named colors which may be referenced by the styles
+	Font     *ThemeFont        // Optional text font
+	FontBold *ThemeFont        // Optional bold text font
+	FontIcon *ThemeFont        // Optional icon font
+	Styles   json.RawMessage   // Widget styles which override the styles of the base theme
+	style    *Style            // resolved styles
+	palette  map[string]string // resolved named colors
+}
+
+// ThemeFont describes a font of a theme
+type ThemeFont struct {
+	File        string  // Path of a TrueType font file or name of an embedded font asset
+	Size        float64 // Font size in points (default 14)
+	DPI         float64 // Font resolution (default 72)
+	LineSpacing float64 // Line spacing (default 1.0)
+}
+
+// ThemeDecoder is the type of the functions which decode theme files of
+// other formats into a Theme, as a TOML decoder.
+type ThemeDecoder func(data []byte, theme *Theme) error
+
+// Name of the built-in themes
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+var themes = map[string]*Theme{}              // registered themes by name
+var themeDecoders = map[string]ThemeDecoder{} // theme decoders by file extension
+var themeCurrent = ThemeLight                 // name of the current default theme
+
+// registerDefaultThemes registers the built-in themes, using the
+// hardcoded default style as the light theme
+func registerDefaultThemes() {
+
+	light := &Theme{Name: ThemeLight}
+	light.style = copyStyle(StyleDefault)
+	light.palette = map[string]string{}
+	themes[ThemeLight] = light
+
+	dark, err := NewThemeFromData([]byte(themeDarkData), ".json")
+	if err == nil {
+		err = RegisterTheme(dark)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegisterThemeDecoder registers a decoder for theme files with the
+// specified extension, such as ".toml". JSON decoding is built-in.
+func RegisterThemeDecoder(ext string, decoder ThemeDecoder) {
+
+	themeDecoders[strings.ToLower(ext)] = decoder
+}
+
+// NewThemeFromFile reads and returns a pointer to a theme from the specified
+// file whose format is determined by its extension.
+// The theme must be registered to be used.
+func NewThemeFromFile(path string) (*Theme, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewThemeFromData(data, filepath.Ext(path))
+}
+
+// NewThemeFromData decodes and returns a pointer to a theme from the
+// specified data in the format of files with the specified extension.
+// The theme must be registered to be used.
+func NewThemeFromData(data []byte, ext string) (*Theme, error) {
+
+	t := new(Theme)
+	ext = strings.ToLower(ext)
+	if ext == ".json" || ext == "" {
+		err := json.Unmarshal(data, t)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	decoder := themeDecoders[ext]
+	if decoder == nil {
+		return nil, fmt.Errorf("no theme decoder for %q files", ext)
+	}
+	err := decoder(data, t)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RegisterTheme resolves the styles of the specified theme from its base
+// theme and registers it by its name, replacing a theme with the same name.
+func RegisterTheme(t *Theme) error {
+
+	if t.Name == "" {
+		return fmt.Errorf("theme without name")
+	}
+	baseName := t.Base
+	if baseName == "" {
+		baseName = ThemeLight
+	}
+	base := themes[baseName]
+	if base == nil || baseName == t.Name {
+		return fmt.Errorf("invalid base theme %q of theme %q", baseName, t.Name)
+	}
+
+	// Named colors
+	palette := make(map[string]string)
+	for name, color := range base.palette {
+		palette[name] = color
+	}
+	for name, color := range t.Colors {
+		palette[name] = color
+	}
+
+	// Fonts
+	style := copyStyle(base.style)
+	var err error
+	if t.Font != nil {
+		style.Font, err = t.Font.load(style.Font)
+		if err != nil {
+			return err
+		}
+	}
+	if t.FontBold != nil {
+		style.FontBold, err = t.FontBold.load(style.FontBold)
+		if err != nil {
+			return err
+		}
+	}
+	if t.FontIcon != nil {
+		style.FontIcon, err = t.FontIcon.load(style.FontIcon)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Styles overridden by the theme
+	if len(t.Styles) > 0 {
+		var tree interface{}
+		err = json.Unmarshal(t.Styles, &tree)
+		if err != nil {
+			return err
+		}
+		tree, err = resolveThemeColors(tree, palette)
+		if err != nil {
+			return fmt.Errorf("theme %q: %v", t.Name, err)
+		}
+		data, err := json.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(data, style)
+		if err != nil {
+			return fmt.Errorf("theme %q: %v", t.Name, err)
+		}
+	}
+	t.style = style
+	t.palette = palette
+	themes[t.Name] = t
+	return nil
+}
+
+// ThemeByName returns the registered theme with the specified name or nil
+func ThemeByName(name string) *Theme {
+
+	return themes[name]
+}
+
+// ThemeNames returns the sorted names of the registered themes
+func ThemeNames() []string {
+
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CurrentTheme returns the name of the theme used as the default style
+func CurrentTheme() string {
+
+	return themeCurrent
+}
+
+// Style returns the resolved styles of this registered theme
+func (t *Theme) Style() *Style {
+
+	return t.style
+}
+
+// Color returns the named color of this registered theme
+func (t *Theme) Color(name string) (math32.Color4, bool) {
+
+	var c math32.Color4
+	value, ok := t.palette[name]
+	if !ok {
+		return c, false
+	}
+	c, err := parseThemeColor(value, t.palette)
+	return c, err == nil
+}
+
+// SetTheme sets the styles of the registered theme with the specified name as
+// the default styles, which are used by the widgets created afterwards and by
+// the existing widgets with default styles when they update their visual
+// state or RefreshStyles is called for them.
+func SetTheme(name string) error {
+
+	t := themes[name]
+	if t == nil {
+		return fmt.Errorf("theme %q not registered", name)
+	}
+	// Decodes the theme styles into the current default styles, which keeps
+	// the addresses of the styles referenced by the existing widgets.
+	data, err := json.Marshal(t.style)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, StyleDefault)
+	if err != nil {
+		return err
+	}
+	StyleDefault.Font = t.style.Font
+	StyleDefault.FontBold = t.style.FontBold
+	StyleDefault.FontIcon = t.style.FontIcon
+	themeCurrent = name
+	return nil
+}
+
+// ApplyTheme sets the styles of the registered theme with the specified name
+// to the specified panel and all its descendants, so a theme cascades down a
+// panel hierarchy. Widgets added later to the hierarchy keep the default styles.
+func ApplyTheme(ipan IPanel, name string) error {
+
+	t := themes[name]
+	if t == nil {
+		return fmt.Errorf("theme %q not registered", name)
+	}
+	s := t.style
+	walkPanels(ipan, func(ipan IPanel) {
+		switch w := ipan.(type) {
+		case *Button:
+			w.SetStyles(&s.Button)
+		case *CheckRadio:
+			w.SetStyles(&s.CheckRadio)
+		case *Edit:
+			w.SetStyles(&s.Edit)
+		case *ScrollBar:
+			w.style = &s.ScrollBar
+			w.update()
+		case *Slider:
+			w.SetStyles(&s.Slider)
+		case *Splitter:
+			w.styles = &s.Splitter
+			w.update()
+		case *Window:
+			w.styles = &s.Window
+			w.update()
+		case *List:
+			w.SetStyles(&s.List)
+		case *VirtualList:
+			w.SetStyles(&s.List)
+		case *Scroller:
+			w.SetStyles(&s.Scroller)
+		case *DropDown:
+			w.styles = &s.DropDown
+			w.update()
+		case *Folder:
+			w.SetStyles(&s.Folder)
+		case *Tree:
+			w.SetStyles(&s.Tree)
+		case *VirtualTree:
+			w.SetStyles(&s.Tree)
+		case *Table:
+			w.SetStyles(&s.Table)
+		case *TextEdit:
+			w.SetStyles(&s.TextEdit)
+		case *Docker:
+			w.SetStyles(&s.Docker)
+		case *Menu:
+			w.SetStyles(&s.Menu)
+		case *Inspector:
+			w.SetStyles(&s.Inspector)
+		case *Chart:
+			w.SetStyles(&s.Chart)
+		case *RichLabel:
+			w.SetStyles(&s.RichLabel)
+		case *TabBar:
+			w.SetStyles(&s.TabBar)
+		case *SpinBox:
+			w.SetStyles(&s.SpinBox)
+		}
+	})
+	return nil
+}
+
+// RefreshStyles updates the visual state of the specified panel and all its
+// descendants, which is necessary after SetTheme for existing widgets.
+func RefreshStyles(ipan IPanel) {
+
+	walkPanels(ipan, func(ipan IPanel) {
+		switch w := ipan.(type) {
+		case *Table:
+			w.applyStyle()
+		case interface {
+			update()
+		}:
+			w.update()
+		}
+	})
+}
+
+// walkPanels calls the specified function for the specified panel
+// and all its descendant panels
+func walkPanels(ipan IPanel, cb func(IPanel)) {
+
+	cb(ipan)
+	for _, iobj := range ipan.GetPanel().Children() {
+		if child, ok := iobj.(IPanel); ok {
+			walkPanels(child, cb)
+		}
+	}
+}
+
+// copyStyle returns a deep copy of the specified styles sharing its fonts
+func copyStyle(src *Style) *Style {
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		panic(err)
+	}
+	dst := new(Style)
+	err = json.Unmarshal(data, dst)
+	if err != nil {
+		panic(err)
+	}
+	dst.Font = src.Font
+	dst.FontBold = src.FontBold
+	dst.FontIcon = src.FontIcon
+	return dst
+}
+
+// load loads and returns the font described by this theme font or
+// a copy of the specified font if no file is specified
+func (tf *ThemeFont) load(font *text.Font) (*text.Font, error) {
+
+	var err error
+	if tf.File != "" {
+		data, aerr := assets.Asset(tf.File)
+		if aerr == nil {
+			font, err = text.NewFontFromData(data)
+		} else {
+			font, err = text.NewFont(tf.File)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		fcopy := *font
+		font = &fcopy
+	}
+	size, dpi, spacing := tf.Size, tf.DPI, tf.LineSpacing
+	if size <= 0 {
+		size = 14
+	}
+	if dpi <= 0 {
+		dpi = 72
+	}
+	if spacing <= 0 {
+		spacing = 1.0
+	}
+	font.SetSize(size)
+	font.SetDPI(dpi)
+	font.SetLineSpacing(spacing)
+	font.SetFgColor4(&math32.Color4{0, 0, 0, 1})
+	font.SetBgColor4(&math32.Color4{1, 1, 1, 0})
+	return font, nil
+}
+
+// resolveThemeColors returns the specified decoded JSON value with the
+// color strings replaced by objects with the color components
+func resolveThemeColors(v interface{}, palette map[string]string) (interface{}, error) {
+
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		for key, child := range tv {
+			res, err := resolveThemeColors(child, palette)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			tv[key] = res
+		}
+	case []interface{}:
+		for i, child := range tv {
+			res, err := resolveThemeColors(child, palette)
+			if err != nil {
+				return nil, err
+			}
+			tv[i] = res
+		}
+	case string:
+		c, err := parseThemeColor(tv, palette)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"R": c.R, "G": c.G, "B": c.B, "A": c.A}, nil
+	}
+	return v, nil
+}
+
+// parseThemeColor parses the specified color string which may be a
+// reference to a named color of the specified palette
+func parseThemeColor(s string, palette map[string]string) (math32.Color4, error) {
+
+	// Resolves named colors, which may reference other named colors
+	for depth := 0; strings.HasPrefix(s, "$"); depth++ {
+		value, ok := palette[s[1:]]
+		if !ok || depth > len(palette) {
+			return math32.Color4{}, fmt.Errorf("invalid color reference %q", s)
+		}
+		s = value
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == len(s) || (len(hex) != 3 && len(hex) != 6 && len(hex) != 8) {
+		return math32.Color4{}, fmt.Errorf("invalid color %q", s)
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return math32.Color4{}, fmt.Errorf("invalid color %q", s)
+	}
+	return math32.Color4{
+		R: float32(v>>24&0xFF) / 255,
+		G: float32(v>>16&0xFF) / 255,
+		B: float32(v>>8&0xFF) / 255,
+		A: float32(v&0xFF) / 255,
+	}, nil
+}
+
+// themeDarkData is the built-in dark theme
+const themeDarkData = `{
+  "Name": "dark",
+  "Colors": {
+    "border": "#101010",
+    "bg": "#2b2b2b",
+    "bgOver": "#3c3f41",
+    "bgSel": "#4b6eaf",
+    "fg": "#d0d0d0",
+    "fgSel": "#ffffff",
+    "fgDis": "#707070",
+    "header": "#3c3f41",
+    "accent": "#4b6eaf"
+  },
+  "Styles": {
+    "Button": {
+      "Normal":   {"BorderColor": "$border", "BgColor": "$bgOver", "FgColor": "$fg"},
+      "Over":     {"BorderColor": "$border", "BgColor": "#4e5254", "FgColor": "$fgSel"},
+      "Focus":    {"BorderColor": "$border", "BgColor": "#4e5254", "FgColor": "$fgSel"},
+      "Pressed":  {"BorderColor": "$border", "BgColor": "$accent", "FgColor": "$fgSel"},
+      "Disabled": {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fgDis"}
+    },
+    "CheckRadio": {
+      "Normal":   {"BgColor": "#00000000", "FgColor": "$fg"},
+      "Over":     {"BgColor": "$bgOver", "FgColor": "$fgSel"},
+      "Focus":    {"BgColor": "$bgOver", "FgColor": "$fgSel"},
+      "Disabled": {"BgColor": "#00000000", "FgColor": "$fgDis"}
+    },
+    "Edit": {
+      "Normal":   {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fg", "HolderColor": "#808080", "SelColor": "#214283"},
+      "Over":     {"BorderColor": "$border", "BgColor": "$bgOver", "FgColor": "$fg", "HolderColor": "#808080", "SelColor": "#214283"},
+      "Focus":    {"BorderColor": "$accent", "BgColor": "$bgOver", "FgColor": "$fgSel", "HolderColor": "#808080", "SelColor": "#214283"},
+      "Disabled": {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fgDis", "HolderColor": "#606060", "SelColor": "#3a3a3a"}
+    },
+    "ScrollBar": {
+      "BordersColor": "$border", "Color": "$bg",
+      "Button": {"BordersColor": "$border", "Color": "#5a5d5f"}
+    },
+    "Slider": {
+      "Normal":   {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$accent"},
+      "Over":     {"BorderColor": "$border", "BgColor": "$bgOver", "FgColor": "$accent"},
+      "Focus":    {"BorderColor": "$border", "BgColor": "$bgOver", "FgColor": "$accent"},
+      "Disabled": {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fgDis"}
+    },
+    "Splitter": {
+      "Normal": {"SpacerBorderColor": "$border", "SpacerColor": "$bgOver"},
+      "Over":   {"SpacerBorderColor": "$border", "SpacerColor": "#4e5254"},
+      "Drag":   {"SpacerBorderColor": "$border", "SpacerColor": "$accent"}
+    },
+    "Window": {
+      "Normal":   {"BorderColor": "$border", "TitleBorderColor": "$border", "TitleBgColor": "$header", "TitleFgColor": "$fg"},
+      "Over":     {"BorderColor": "$border", "TitleBorderColor": "$border", "TitleBgColor": "$header", "TitleFgColor": "$fgSel"},
+      "Focus":    {"BorderColor": "$accent", "TitleBorderColor": "$border", "TitleBgColor": "$accent", "TitleFgColor": "$fgSel"},
+      "Disabled": {"BorderColor": "$border", "TitleBorderColor": "$border", "TitleBgColor": "$bg", "TitleFgColor": "$fgDis"}
+    },
+    "Scroller": {
+      "Normal":   {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fg"},
+      "Over":     {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fg"},
+      "Focus":    {"BorderColor": "$accent", "BgColor": "$bg", "FgColor": "$fg"},
+      "Disabled": {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fgDis"}
+    },
+    "Table": {
+      "Table":    {"BorderColor": "$border", "BgColor": "$bg"},
+      "Header":   {"BorderColor": "$border", "BgColor": "$header", "FgColor": "$fg"},
+      "Row":      {"BorderColor": "#3a3a3a", "BgColor": "$bg", "FgColor": "$fg"},
+      "Selected": {"BorderColor": "#3a3a3a", "BgColor": "$bgSel", "FgColor": "$fgSel"}
+    },
+    "TextEdit": {
+      "Normal":   {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fg", "SelColor": "#214283", "CaretColor": "$fgSel"},
+      "Focus":    {"BorderColor": "$accent", "BgColor": "$bg", "FgColor": "$fg", "SelColor": "#214283", "CaretColor": "$fgSel"},
+      "Disabled": {"BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fgDis", "SelColor": "#3a3a3a", "CaretColor": "$fgDis"}
+    },
+    "Docker": {
+      "SpacerColor": "$bgOver", "SpacerBorderColor": "$border", "TabBorderColor": "$border",
+      "TabBgColor": "$bg", "TabActiveColor": "$bgOver", "TabFgColor": "$fg", "PreviewColor": "#4b6eaf60"
+    },
+    "Drag": {"BorderColor": "$border", "AcceptColor": "#499c54", "RejectColor": "#c75450", "BgColor": "$bgOver", "FgColor": "$fg"},
+    "Tooltip": {"BorderColor": "$border", "BgColor": "$bgOver", "FgColor": "$fg"},
+    "Menu": {
+      "BorderColor": "$border", "BgColor": "$bg", "FgColor": "$fg", "OverBgColor": "$bgSel", "OverFgColor": "$fgSel",
+      "DisabledColor": "$fgDis", "ShortcutColor": "$fgDis", "SeparatorColor": "$border"
+    },
+    "Inspector": {"LabelColor": "$fg", "HeaderColor": "$fgSel"},
+    "Chart": {
+      "BorderColor": "$border", "BgColor": "$bg", "PlotColor": "#313335", "AxisColor": "$fgDis", "GridColor": "#3c3f41", "FgColor": "$fg",
+      "Colors": ["#4b9fe0", "#ff9f40", "#5cb85c", "#e05252", "#b48ede", "#c49c94"]
+    },
+    "RichLabel": {"FgColor": "$fg", "LinkColor": "#589df6", "LinkOverColor": "#8ab8ff"},
+    "Notification": {
+      "BgColor": "$bgOver", "FgColor": "$fg", "TitleColor": "$fgSel", "ProgressColor": "$border",
+      "LevelColors": ["#589df6", "#5cb85c", "#e0a040", "#e05252"]
+    },
+    "Modal": {"OverlayColor": "#00000080"},
+    "TabBar": {
+      "BorderColor": "$border", "BgColor": "$bg",
+      "Normal":   {"BorderColor": "$border", "BgColor": "#313335", "FgColor": "$fg"},
+      "Over":     {"BorderColor": "$border", "BgColor": "$bgOver", "FgColor": "$fgSel"},
+      "Selected": {"BorderColor": "$border", "BgColor": "#4e5254", "FgColor": "$fgSel"},
+      "Disabled": {"BorderColor": "$border", "BgColor": "#313335", "FgColor": "$fgDis"}
+    },
+    "SpinBox": {"ButtonBorderColor": "$border", "ButtonBgColor": "$bgOver", "ButtonOverColor": "#4e5254", "ButtonFgColor": "$fg"},
+    "FocusRing": {"BorderColor": "$accent"}
+  }
+}`
@@ -0,0 +1,162 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+	"time"
+)
+
+// TooltipStyle describes the style of the default tooltip panels
+type TooltipStyle struct {
+	Border      BorderSizes
+	Paddings    BorderSizes
+	BorderColor math32.Color4
+	BgColor     math32.Color4
+	FgColor     math32.Color4
+}
+
+// tooltip is the tooltip of a panel
+type tooltip struct {
+	text   string     // tooltip text for the default tooltip label
+	panel  IPanel     // custom tooltip panel or nil
+	label  *Label     // default tooltip label created when first shown
+	cursor [2]float32 // last cursor position over the panel
+}
+
+// TooltipDelay is the time the cursor must rest over a panel before its tooltip is shown
+var TooltipDelay = 600 * time.Millisecond
+
+const (
+	tooltipOffsetX = 12 // position of the tooltip relative to the cursor
+	tooltipOffsetY = 20
+	tooltipGap     = 4 // gap between the cursor and a tooltip shown above it
+)
+
+var tooltips = map[*Panel]*tooltip{} // tooltips of the panels
+
+// SetTooltip sets the text of the tooltip shown when the
+// cursor rests over the specified panel
+func SetTooltip(ipan IPanel, text string) {
+
+	tip := setTooltip(ipan)
+	tip.text = text
+	tip.panel = nil
+	if tip.label != nil {
+		tip.label.SetText(text)
+	}
+}
+
+// SetTooltipPanel sets a custom panel as the tooltip shown
+// when the cursor rests over the specified panel
+func SetTooltipPanel(ipan IPanel, tpan IPanel) {
+
+	tip := setTooltip(ipan)
+	tip.panel = tpan
+}
+
+// ClearTooltip removes the tooltip of the specified panel
+func ClearTooltip(ipan IPanel) {
+
+	pan := ipan.GetPanel()
+	if pan.root != nil && pan.root.tipOwner == pan {
+		pan.root.hideTooltip()
+	}
+	delete(tooltips, pan)
+}
+
+// setTooltip returns the tooltip of the specified panel, creating it and
+// subscribing to the panel events if necessary
+func setTooltip(ipan IPanel) *tooltip {
+
+	pan := ipan.GetPanel()
+	if tip := tooltips[pan]; tip != nil {
+		return tip
+	}
+	tip := new(tooltip)
+	tooltips[pan] = tip
+	pan.Subscribe(OnCursor, func(evname string, ev interface{}) {
+		cev := ev.(*window.CursorEvent)
+		tip.cursor = [2]float32{cev.Xpos, cev.Ypos}
+		r := pan.root
+		if tooltips[pan] != tip || r == nil || r.tipOwner == pan || r.Dragging() {
+			return
+		}
+		// The innermost panel with a tooltip receives the cursor event first
+		if r.tipOwner != nil && r.tipOwner.ContainsPosition(cev.Xpos, cev.Ypos) && r.tipOwner.pospix.Z <= pan.pospix.Z {
+			return
+		}
+		r.hideTooltip()
+		r.tipOwner = pan
+		r.tipTimer = r.SetTimeout(TooltipDelay, nil, func(arg interface{}) {
+			r.tipTimer = 0
+			if r.tipOwner == pan && tooltips[pan] == tip {
+				r.showTooltip(tip)
+			}
+		})
+	})
+	hide := func(evname string, ev interface{}) {
+		if pan.root != nil && pan.root.tipOwner == pan {
+			pan.root.hideTooltip()
+		}
+	}
+	pan.Subscribe(OnCursorLeave, hide)
+	pan.Subscribe(OnMouseDown, hide)
+	pan.Subscribe(OnScroll, hide)
+	return tip
+}
+
+// showTooltip shows the specified tooltip near the cursor
+// keeping it inside the window
+func (r *Root) showTooltip(tip *tooltip) {
+
+	tpan := tip.panel
+	if tpan == nil {
+		if tip.text == "" {
+			return
+		}
+		if tip.label == nil {
+			tip.label = NewLabel(tip.text)
+		}
+		s := &StyleDefault.Tooltip
+		tip.label.SetBordersFrom(&s.Border)
+		tip.label.SetPaddingsFrom(&s.Paddings)
+		tip.label.SetBordersColor4(&s.BorderColor)
+		tip.label.SetColor4(&s.FgColor)
+		tip.label.Panel.SetColor4(&s.BgColor)
+		tpan = tip.label
+	}
+	pan := tpan.GetPanel()
+	width, height := r.windowSize()
+	x := tip.cursor[0] + tooltipOffsetX
+	y := tip.cursor[1] + tooltipOffsetY
+	if x+pan.Width() > width {
+		x = width - pan.Width()
+	}
+	if y+pan.Height() > height {
+		y = tip.cursor[1] - tooltipGap - pan.Height()
+	}
+	x = math32.Max(x, 0)
+	y = math32.Max(y, 0)
+	r.Add(tpan)
+	pan.SetPosition(x, y)
+	pan.SetForeground()
+	r.tipPanel = tpan
+}
+
+// hideTooltip hides the current tooltip and cancels a pending one
+func (r *Root) hideTooltip() {
+
+	if r.tipTimer != 0 {
+		r.ClearTimeout(r.tipTimer)
+		r.tipTimer = 0
+	}
+	if r.tipPanel != nil {
+		r.Panel.Remove(r.tipPanel)
+		r.tipPanel = nil
+	}
+	r.tipOwner = nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/window"
+)
+
+// touchScrollStep is the pan motion in gui pixels of each scroll step
+// sent to the panel with the scroll focus
+const touchScrollStep = 20
+
+// onTouch is called when touch events are received.
+// The first touch pressed emulates the left mouse button and the cursor,
+// which leaves the panels when the touch is released, so the panels which
+// only process mouse events can be used on touch screens.
+func (r *Root) onTouch(evname string, ev interface{}) {
+
+	tev := ev.(*window.TouchEvent)
+	switch evname {
+	case window.OnTouchDown:
+		if r.touchID >= 0 {
+			return
+		}
+		r.touchID = tev.ID
+		r.panScroll = 0
+		r.onCursor(OnCursor, &window.CursorEvent{W: tev.W, Xpos: tev.Xpos, Ypos: tev.Ypos})
+		r.onMouse(OnMouseDown, &window.MouseEvent{W: tev.W, Xpos: tev.Xpos, Ypos: tev.Ypos, Button: window.MouseButtonLeft, Action: window.Press})
+	case window.OnTouchMove:
+		if tev.ID != r.touchID {
+			return
+		}
+		r.onCursor(OnCursor, &window.CursorEvent{W: tev.W, Xpos: tev.Xpos, Ypos: tev.Ypos})
+	case window.OnTouchUp:
+		if tev.ID != r.touchID {
+			return
+		}
+		r.touchID = -1
+		r.onMouse(OnMouseUp, &window.MouseEvent{W: tev.W, Xpos: tev.Xpos, Ypos: tev.Ypos, Button: window.MouseButtonLeft, Action: window.Release})
+		r.onCursor(OnCursor, &window.CursorEvent{W: tev.W, Xpos: -1, Ypos: -1})
+	}
+}
+
+// onGesture is called when gesture events are received and dispatches
+// them, converted to gui pixels, to the panels which contain their position.
+// The pans not stopped by the panels scroll the panel with the scroll focus.
+func (r *Root) onGesture(evname string, ev interface{}) {
+
+	r.gestureEv = *ev.(*window.GestureEvent)
+	s := r.Scale()
+	r.gestureEv.Xpos /= s
+	r.gestureEv.Ypos /= s
+	r.gestureEv.DeltaX /= s
+	r.gestureEv.DeltaY /= s
+	r.stopPropagation = 0
+	r.sendPanels(r.gestureEv.Xpos, r.gestureEv.Ypos, evname, &r.gestureEv)
+	if evname != OnPan || r.mouseFocus != nil || (r.stopPropagation&StopGUI) != 0 {
+		return
+	}
+
+	// Converts the vertical pan motion to scroll steps,
+	// moving the content with the touch
+	r.panScroll += r.gestureEv.DeltaY
+	for r.panScroll >= touchScrollStep || r.panScroll <= -touchScrollStep {
+		step := float32(1)
+		if r.panScroll < 0 {
+			step = -1
+		}
+		r.panScroll -= step * touchScrollStep
+		r.onScroll(OnScroll, &window.ScrollEvent{W: r.gestureEv.W, Yoffset: step})
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/math32/easing"
+	"time"
+)
+
+// Tween is a transition which changes the position, size, opacity and color
+// of a panel from their current values to target values during a time using
+// an easing curve. Tweens are created by a gui root panel and advanced when
+// its window dispatches OnFrame, as its timers. Tweens may be chained to
+// start when another finishes. Starting a tween stops the transitions of
+// the same properties of the panel by other tweens.
+type Tween struct {
+	root     *Root         // root panel which advances this tween
+	target   IPanel        // panel whose properties are changed
+	duration time.Duration // duration of the transition
+	delay    time.Duration // time before the transition starts
+	elapsed  time.Duration // time since this tween was scheduled
+	easing   easing.Func   // easing curve
+	values   []tweenValue  // properties changed
+	next     []*Tween      // tweens scheduled when this one finishes
+	onDone   func(*Tween)  // function called when this tween finishes or nil
+	finalize func()        // internal function called when this tween finishes or nil
+	started  bool          // start values were read from the panel
+	done     bool          // finished or stopped
+}
+
+// tweenProp identifies a panel property changed by a tween
+type tweenProp int
+
+const (
+	tweenPosition tweenProp = iota
+	tweenSize
+	tweenOpacity
+	tweenColor
+)
+
+// tweenValue is the start and end values of a panel property
+type tweenValue struct {
+	prop tweenProp
+	from [4]float32
+	to   [4]float32
+}
+
+// NewTween creates and returns a pointer to a new tween of the specified
+// panel with the specified duration, which starts at the next frame.
+// The properties changed are set by its MoveTo, ResizeTo, FadeTo and
+// ColorTo methods.
+func (r *Root) NewTween(ipan IPanel, duration time.Duration) *Tween {
+
+	tw := newTween(r, ipan, duration)
+	r.tweens = append(r.tweens, tw)
+	return tw
+}
+
+// StopTweens stops all the tweens of the specified panel, leaving its
+// properties with their current values
+func (r *Root) StopTweens(ipan IPanel) {
+
+	for _, tw := range append([]*Tween(nil), r.tweens...) {
+		if tw.target.GetPanel() == ipan.GetPanel() {
+			tw.Stop()
+		}
+	}
+}
+
+// FadeIn shows the specified panel fading its opacity from 0 to 1
+// during the specified time and returns the tween
+func (r *Root) FadeIn(ipan IPanel, duration time.Duration) *Tween {
+
+	pan := ipan.GetPanel()
+	pan.SetOpacity(0)
+	pan.SetVisible(true)
+	tw := r.NewTween(ipan, duration).FadeTo(1)
+	tw.start()
+	return tw
+}
+
+// FadeOut fades the opacity of the specified panel to 0 during the specified
+// time and then hides the panel, restoring its opacity, and returns the tween
+func (r *Root) FadeOut(ipan IPanel, duration time.Duration) *Tween {
+
+	pan := ipan.GetPanel()
+	opacity := pan.Opacity()
+	tw := r.NewTween(ipan, duration).FadeTo(0)
+	tw.finalize = func() {
+		pan.SetVisible(false)
+		pan.SetOpacity(opacity)
+	}
+	return tw
+}
+
+// SlideIn shows the specified panel moving it to its current position from
+// the specified offset during the specified time and returns the tween
+func (r *Root) SlideIn(ipan IPanel, dx, dy float32, duration time.Duration) *Tween {
+
+	pan := ipan.GetPanel()
+	pos := pan.Position()
+	pan.SetPosition(pos.X+dx, pos.Y+dy)
+	pan.SetVisible(true)
+	tw := r.NewTween(ipan, duration).MoveTo(pos.X, pos.Y).SetEasing(easing.OutCubic)
+	tw.start()
+	return tw
+}
+
+// updateTweens advances the tweens by the specified time
+func (r *Root) updateTweens(dt time.Duration) {
+
+	if len(r.tweens) == 0 {
+		return
+	}
+	// Iterates over a copy as finished tweens schedule the chained ones
+	for _, tw := range append([]*Tween(nil), r.tweens...) {
+		if !tw.done {
+			tw.advance(dt)
+		}
+	}
+}
+
+// removeTween removes the specified tween from the tweens being advanced
+func (r *Root) removeTween(tw *Tween) {
+
+	for i, curr := range r.tweens {
+		if curr == tw {
+			copy(r.tweens[i:], r.tweens[i+1:])
+			r.tweens[len(r.tweens)-1] = nil
+			r.tweens = r.tweens[:len(r.tweens)-1]
+			return
+		}
+	}
+}
+
+// newTween creates and returns a pointer to a new tween which is not scheduled
+func newTween(r *Root, ipan IPanel, duration time.Duration) *Tween {
+
+	tw := new(Tween)
+	tw.root = r
+	tw.target = ipan
+	tw.duration = duration
+	tw.easing = easing.InOutQuad
+	return tw
+}
+
+// MoveTo sets the position the panel is moved to
+func (tw *Tween) MoveTo(x, y float32) *Tween {
+
+	return tw.setValue(tweenPosition, [4]float32{x, y})
+}
+
+// ResizeTo sets the external size the panel is resized to
+func (tw *Tween) ResizeTo(width, height float32) *Tween {
+
+	return tw.setValue(tweenSize, [4]float32{width, height})
+}
+
+// FadeTo sets the opacity the panel is faded to
+func (tw *Tween) FadeTo(opacity float32) *Tween {
+
+	return tw.setValue(tweenOpacity, [4]float32{opacity})
+}
+
+// ColorTo sets the color the panel content and paddings are changed to.
+// The widgets which set their colors from their styles may override it
+// when their visual state changes.
+func (tw *Tween) ColorTo(color *math32.Color4) *Tween {
+
+	return tw.setValue(tweenColor, [4]float32{color.R, color.G, color.B, color.A})
+}
+
+// SetEasing sets the easing curve of this tween (default easing.InOutQuad)
+func (tw *Tween) SetEasing(ease easing.Func) *Tween {
+
+	tw.easing = ease
+	return tw
+}
+
+// SetDelay sets the time from when this tween is scheduled
+// until its transition starts
+func (tw *Tween) SetDelay(delay time.Duration) *Tween {
+
+	tw.delay = delay
+	return tw
+}
+
+// SetOnDone sets the function called when this tween finishes
+func (tw *Tween) SetOnDone(cb func(tw *Tween)) *Tween {
+
+	tw.onDone = cb
+	return tw
+}
+
+// Then creates and returns a pointer to a new tween of the same panel
+// with the specified duration which starts when this tween finishes
+func (tw *Tween) Then(duration time.Duration) *Tween {
+
+	return tw.ThenPanel(tw.target, duration)
+}
+
+// ThenPanel creates and returns a pointer to a new tween of the specified
+// panel with the specified duration which starts when this tween finishes
+func (tw *Tween) ThenPanel(ipan IPanel, duration time.Duration) *Tween {
+
+	next := newTween(tw.root, ipan, duration)
+	tw.next = append(tw.next, next)
+	return next
+}
+
+// Target returns the panel of this tween
+func (tw *Tween) Target() IPanel {
+
+	return tw.target
+}
+
+// Done returns if this tween finished or was stopped
+func (tw *Tween) Done() bool {
+
+	return tw.done
+}
+
+// Stop stops this tween leaving the panel properties with their current
+// values. The tweens chained to it are not started.
+func (tw *Tween) Stop() {
+
+	if tw.done {
+		return
+	}
+	tw.done = true
+	tw.root.removeTween(tw)
+}
+
+// Finish sets the panel properties to their final values and
+// finishes this tween, starting the tweens chained to it
+func (tw *Tween) Finish() {
+
+	if tw.done {
+		return
+	}
+	if !tw.started {
+		tw.start()
+	}
+	tw.apply(1)
+	tw.finish()
+}
+
+// setValue sets the target value of the specified property
+func (tw *Tween) setValue(prop tweenProp, to [4]float32) *Tween {
+
+	for i := range tw.values {
+		if tw.values[i].prop == prop {
+			tw.values[i].to = to
+			return tw
+		}
+	}
+	tw.values = append(tw.values, tweenValue{prop: prop, to: to})
+	// The start value of a property added to a started tween is read now
+	if tw.started {
+		tw.readValue(&tw.values[len(tw.values)-1])
+	}
+	return tw
+}
+
+// advance advances this tween by the specified time
+func (tw *Tween) advance(dt time.Duration) {
+
+	tw.elapsed += dt
+	if tw.elapsed < tw.delay {
+		return
+	}
+	if !tw.started {
+		tw.start()
+	}
+	t := float32(1)
+	if tw.duration > 0 {
+		t = math32.Min(float32(tw.elapsed-tw.delay)/float32(tw.duration), 1)
+	}
+	if t < 1 {
+		tw.apply(tw.easing(t))
+		return
+	}
+	tw.apply(1)
+	tw.finish()
+}
+
+// start reads the start values of the properties from the panel and
+// removes the same properties from the other tweens of the panel
+func (tw *Tween) start() {
+
+	tw.started = true
+	pan := tw.target.GetPanel()
+	for i := range tw.values {
+		tw.readValue(&tw.values[i])
+	}
+	for _, other := range append([]*Tween(nil), tw.root.tweens...) {
+		if other == tw || !other.started || other.target.GetPanel() != pan {
+			continue
+		}
+		values := other.values[:0]
+		for _, ov := range other.values {
+			if !tw.hasProp(ov.prop) {
+				values = append(values, ov)
+			}
+		}
+		other.values = values
+		if len(values) == 0 {
+			other.Stop()
+		}
+	}
+}
+
+// readValue reads the start value of the specified property from the panel
+func (tw *Tween) readValue(v *tweenValue) {
+
+	pan := tw.target.GetPanel()
+	switch v.prop {
+	case tweenPosition:
+		pos := pan.Position()
+		v.from = [4]float32{pos.X, pos.Y}
+	case tweenSize:
+		v.from = [4]float32{pan.Width(), pan.Height()}
+	case tweenOpacity:
+		v.from = [4]float32{pan.Opacity()}
+	case tweenColor:
+		c := pan.Color4()
+		v.from = [4]float32{c.R, c.G, c.B, c.A}
+	}
+}
+
+// hasProp returns if this tween changes the specified property
+func (tw *Tween) hasProp(prop tweenProp) bool {
+
+	for _, v := range tw.values {
+		if v.prop == prop {
+			return true
+		}
+	}
+	return false
+}
+
+// apply sets the panel properties interpolated by the specified fraction
+func (tw *Tween) apply(f float32) {
+
+	pan := tw.target.GetPanel()
+	for _, v := range tw.values {
+		var cur [4]float32
+		for i := range cur {
+			cur[i] = v.from[i] + (v.to[i]-v.from[i])*f
+		}
+		switch v.prop {
+		case tweenPosition:
+			pan.SetPosition(cur[0], cur[1])
+		case tweenSize:
+			pan.SetSize(math32.Max(cur[0], 0), math32.Max(cur[1], 0))
+		case tweenOpacity:
+			pan.SetOpacity(cur[0])
+		case tweenColor:
+			pan.SetColor4(&math32.Color4{cur[0], cur[1], cur[2], cur[3]})
+		}
+	}
+}
+
+// finish finishes this tween and schedules the tweens chained to it
+func (tw *Tween) finish() {
+
+	tw.done = true
+	tw.root.removeTween(tw)
+	if tw.finalize != nil {
+		tw.finalize()
+	}
+	if tw.onDone != nil {
+		tw.onDone(tw)
+	}
+	tw.root.tweens = append(tw.root.tweens, tw.next...)
+}
@@ -0,0 +1,498 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+	"math"
+)
+
+// VirtualList is a list widget for very large datasets, as logs or asset
+// browsers with hundreds of thousands of items. The items are kept by a
+// model and only the rows visible in the list window have panels, which
+// are created by the model and bound again to other items when scrolling.
+// All the rows have the same height.
+// OnChange is dispatched when the selected item changes.
+// It uses the same styles of the List widget.
+type VirtualList struct {
+	Panel                         // Embedded panel
+	styles      *ListStyles       // pointer to current styles
+	model       ListModel         // model with the list items
+	pool        []*virtualListRow // row panels of the list window
+	vscroll     *ScrollBar        // vertical scroll bar
+	first       int               // first visible item
+	rowHeight   float32           // height of the rows in pixels (0 = calculate)
+	autoHeight  bool              // row height calculated from the model rows
+	selected    int               // selected item or -1
+	scrollEvent bool              // recalc caused by the scroll bar
+}
+
+// ListModel is the interface of the models which keep the items of a
+// VirtualList and create and update the panels of the visible rows
+type ListModel interface {
+	Len() int                      // returns the number of items
+	NewRow() IPanel                // creates a new row panel
+	BindRow(row IPanel, index int) // shows the item at the specified index in the row panel
+}
+
+// TextListModel is a list model of text items shown by labels.
+// The items may be changed directly followed by VirtualList.Refresh.
+type TextListModel struct {
+	Items []string
+}
+
+// virtualListRow is a row panel of the VirtualList window which
+// contains the model row panel and shows the selection state
+type virtualListRow struct {
+	Panel                // Embedded panel
+	item  IPanel         // model row panel
+	index int            // index of the bound item (-1 = none)
+	style *ListItemStyle // style applied
+}
+
+const virtualListScrollSize = 16
+
+// NewVirtualList creates and returns a pointer to a new virtual
+// list with the specified dimensions showing the items of the
+// specified model
+func NewVirtualList(width, height float32, model ListModel) *VirtualList {
+
+	vl := new(VirtualList)
+	vl.Panel.Initialize(width, height)
+	vl.styles = &StyleDefault.List
+	vl.model = model
+	vl.selected = -1
+	vl.autoHeight = true
+
+	vl.Panel.Subscribe(OnMouseDown, vl.onMouse)
+	vl.Panel.Subscribe(OnKeyDown, vl.onKey)
+	vl.Panel.Subscribe(OnCursorEnter, vl.onCursor)
+	vl.Panel.Subscribe(OnCursorLeave, vl.onCursor)
+	vl.Panel.Subscribe(OnScroll, vl.onScroll)
+	vl.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { vl.recalc() })
+	vl.update()
+	return vl
+}
+
+// SetStyles sets the list styles overriding the default style
+func (vl *VirtualList) SetStyles(s *ListStyles) {
+
+	vl.styles = s
+	for _, row := range vl.pool {
+		row.style = nil
+	}
+	if vl.autoHeight {
+		vl.rowHeight = 0
+	}
+	vl.update()
+}
+
+// SetModel sets the model with the items of the list.
+// The current row panels are discarded.
+func (vl *VirtualList) SetModel(model ListModel) {
+
+	for _, row := range vl.pool {
+		vl.Panel.Remove(row)
+	}
+	vl.pool = nil
+	vl.model = model
+	vl.first = 0
+	vl.selected = -1
+	if vl.autoHeight {
+		vl.rowHeight = 0
+	}
+	vl.recalc()
+}
+
+// Model returns the model with the items of the list
+func (vl *VirtualList) Model() ListModel {
+
+	return vl.model
+}
+
+// SetRowHeight sets the height of the rows in pixels.
+// The default value of 0 uses the height of the first row created
+// by the model with the borders and paddings of the item style.
+func (vl *VirtualList) SetRowHeight(height float32) {
+
+	vl.rowHeight = height
+	vl.autoHeight = height <= 0
+	vl.recalc()
+}
+
+// RowHeight returns the height of the rows in pixels
+func (vl *VirtualList) RowHeight() float32 {
+
+	vl.updateRowHeight()
+	return vl.rowHeight
+}
+
+// Len returns the number of items of the list model
+func (vl *VirtualList) Len() int {
+
+	if vl.model == nil {
+		return 0
+	}
+	return vl.model.Len()
+}
+
+// Refresh must be called when the items of the model changed.
+// It binds again the visible rows to their items.
+func (vl *VirtualList) Refresh() {
+
+	for _, row := range vl.pool {
+		row.index = -1
+	}
+	if vl.selected >= vl.Len() {
+		vl.selected = -1
+		vl.Dispatch(OnChange, nil)
+	}
+	vl.recalc()
+}
+
+// Selected returns the index of the selected item or -1
+func (vl *VirtualList) Selected() int {
+
+	return vl.selected
+}
+
+// SetSelected selects the item at the specified index and scrolls
+// the list to show it. An invalid index clears the selection.
+func (vl *VirtualList) SetSelected(index int) {
+
+	if index < 0 || index >= vl.Len() {
+		index = -1
+	}
+	if index == vl.selected {
+		return
+	}
+	vl.selected = index
+	if index >= 0 {
+		vl.ScrollTo(index)
+	}
+	vl.recalc()
+	vl.Dispatch(OnChange, nil)
+}
+
+// ScrollTo scrolls the list if necessary to show the item at the specified index
+func (vl *VirtualList) ScrollTo(index int) {
+
+	if index < 0 || index >= vl.Len() {
+		return
+	}
+	if index < vl.first {
+		vl.first = index
+	} else if visible := vl.visibleRows(); index >= vl.first+visible {
+		vl.first = index - visible + 1
+	}
+	vl.recalc()
+}
+
+// ScrollToEnd scrolls the list to show its last item,
+// as the last lines of a log
+func (vl *VirtualList) ScrollToEnd() {
+
+	vl.ScrollTo(vl.Len() - 1)
+}
+
+// First returns the index of the first visible item
+func (vl *VirtualList) First() int {
+
+	return vl.first
+}
+
+// visibleRows returns the number of rows which fit completely in the list window
+func (vl *VirtualList) visibleRows() int {
+
+	vl.updateRowHeight()
+	n := int(vl.ContentHeight() / vl.rowHeight)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// maxFirst returns the maximum first visible item
+func (vl *VirtualList) maxFirst() int {
+
+	max := vl.Len() - vl.visibleRows()
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// updateRowHeight calculates the height of the rows
+// from the first row panel created if necessary
+func (vl *VirtualList) updateRowHeight() {
+
+	if vl.rowHeight > 0 {
+		return
+	}
+	s := &vl.styles.Item.Normal
+	vl.rowHeight = s.Border.Top + s.Border.Bottom + s.Paddings.Top + s.Paddings.Bottom
+	if vl.model != nil {
+		row := vl.newRow()
+		if vl.model.Len() > 0 {
+			vl.model.BindRow(row.item, 0)
+			row.index = 0
+		}
+		vl.rowHeight += row.item.GetPanel().Height()
+		vl.Panel.Add(row)
+		row.SetVisible(false)
+		vl.pool = append(vl.pool, row)
+	}
+	vl.rowHeight = math32.Max(vl.rowHeight, 1)
+}
+
+// newRow creates and returns a new row panel which is not added to the list
+func (vl *VirtualList) newRow() *virtualListRow {
+
+	row := new(virtualListRow)
+	row.Panel.Initialize(0, 0)
+	row.item = vl.model.NewRow()
+	row.index = -1
+	row.Panel.Add(row.item)
+	return row
+}
+
+// recalc updates the list window rows, the scroll bar
+// and binds the row panels to the visible items
+func (vl *VirtualList) recalc() {
+
+	if vl.model == nil {
+		return
+	}
+	width := vl.ContentWidth()
+	height := vl.ContentHeight()
+	visible := vl.visibleRows()
+	count := vl.model.Len()
+
+	// Shows the scroll bar if the items do not fit
+	scroll := count > visible
+	if scroll {
+		if vl.vscroll == nil {
+			vl.vscroll = NewVScrollBar(0, 0)
+			vl.vscroll.SetBorders(0, 0, 0, 1)
+			vl.vscroll.Subscribe(OnChange, vl.onScrollBar)
+			vl.Panel.Add(vl.vscroll)
+		}
+		vl.vscroll.SetSize(virtualListScrollSize, height)
+		vl.vscroll.SetPosition(width-virtualListScrollSize, 0)
+		vl.vscroll.recalc()
+		vl.vscroll.SetVisible(true)
+		width = math32.Max(width-virtualListScrollSize, 0)
+	} else if vl.vscroll != nil {
+		vl.vscroll.SetVisible(false)
+	}
+	if vl.first > vl.maxFirst() {
+		vl.first = vl.maxFirst()
+	}
+	if scroll && !vl.scrollEvent {
+		vl.vscroll.SetValue(float32(vl.first) / float32(vl.maxFirst()))
+	}
+
+	// Creates the row panels of the window including the partially visible row
+	rows := int(math.Ceil(float64(height / vl.rowHeight)))
+	for len(vl.pool) < rows {
+		row := vl.newRow()
+		vl.Panel.Add(row)
+		vl.pool = append(vl.pool, row)
+	}
+
+	// Binds the row panels to the visible items keeping the
+	// rows already bound to visible items to avoid updating them
+	free := make([]*virtualListRow, 0, len(vl.pool))
+	bound := make(map[int]*virtualListRow)
+	for _, row := range vl.pool {
+		if row.index >= vl.first && row.index < vl.first+rows && row.index < count {
+			bound[row.index] = row
+		} else {
+			free = append(free, row)
+		}
+	}
+	for i := 0; i < rows && vl.first+i < count; i++ {
+		index := vl.first + i
+		row := bound[index]
+		if row == nil {
+			row = free[len(free)-1]
+			free = free[:len(free)-1]
+			vl.model.BindRow(row.item, index)
+			row.index = index
+		}
+		row.SetVisible(true)
+		row.SetPosition(0, float32(i)*vl.rowHeight)
+		row.SetSize(width, vl.rowHeight)
+		vl.applyRowStyle(row)
+	}
+	for _, row := range free {
+		row.index = -1
+		row.SetVisible(false)
+	}
+}
+
+// applyRowStyle applies the item style to the specified row
+// if it changed considering its selection state
+func (vl *VirtualList) applyRowStyle(row *virtualListRow) {
+
+	s := &vl.styles.Item.Normal
+	if row.index == vl.selected {
+		s = &vl.styles.Item.Selected
+	}
+	if row.style == s {
+		return
+	}
+	row.SetBordersFrom(&s.Border)
+	row.SetBordersColor4(&s.BorderColor)
+	row.SetPaddingsFrom(&s.Paddings)
+	row.SetColor4(&s.BgColor)
+	if label, ok := row.item.(*Label); ok {
+		label.SetColor(&s.FgColor)
+	}
+	row.style = s
+}
+
+// indexAt returns the index of the item at the specified
+// screen position or -1 if there is no item
+func (vl *VirtualList) indexAt(x, y float32) int {
+
+	y -= vl.pospix.Y + vl.content.Y
+	if y < 0 || vl.rowHeight <= 0 {
+		return -1
+	}
+	index := vl.first + int(y/vl.rowHeight)
+	if index >= vl.Len() {
+		return -1
+	}
+	return index
+}
+
+// onMouse receives subscribed mouse button events
+func (vl *VirtualList) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft || !vl.Enabled() {
+		return
+	}
+	if vl.vscroll != nil && vl.vscroll.Visible() && vl.vscroll.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
+	vl.root.SetKeyFocus(vl)
+	if index := vl.indexAt(mev.Xpos, mev.Ypos); index >= 0 {
+		vl.SetSelected(index)
+	}
+	vl.root.StopPropagation(Stop3D)
+}
+
+// onKey receives subscribed key events
+func (vl *VirtualList) onKey(evname string, ev interface{}) {
+
+	count := vl.Len()
+	if count == 0 {
+		return
+	}
+	index := vl.selected
+	kev := ev.(*window.KeyEvent)
+	switch kev.Keycode {
+	case window.KeyUp:
+		index--
+	case window.KeyDown:
+		index++
+	case window.KeyPageUp:
+		index -= vl.visibleRows()
+	case window.KeyPageDown:
+		index += vl.visibleRows()
+	case window.KeyHome:
+		index = 0
+	case window.KeyEnd:
+		index = count - 1
+	default:
+		return
+	}
+	if index < 0 {
+		index = 0
+	}
+	if index >= count {
+		index = count - 1
+	}
+	vl.SetSelected(index)
+	vl.ScrollTo(index)
+	vl.root.StopPropagation(Stop3D)
+}
+
+// onCursor receives subscribed cursor events
+func (vl *VirtualList) onCursor(evname string, ev interface{}) {
+
+	switch evname {
+	case OnCursorEnter:
+		vl.root.SetScrollFocus(vl)
+	case OnCursorLeave:
+		vl.root.SetScrollFocus(nil)
+	}
+	vl.root.StopPropagation(Stop3D)
+}
+
+// onScroll receives subscribed scroll events
+func (vl *VirtualList) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	vl.first -= int(sev.Yoffset) * 3
+	if vl.first < 0 {
+		vl.first = 0
+	}
+	vl.recalc()
+	vl.root.StopPropagation(Stop3D)
+}
+
+// onScrollBar receives the change events of the vertical scroll bar
+func (vl *VirtualList) onScrollBar(evname string, ev interface{}) {
+
+	vl.first = int(math.Floor(float64(vl.maxFirst())*vl.vscroll.Value() + 0.5))
+	vl.scrollEvent = true
+	vl.recalc()
+	vl.scrollEvent = false
+}
+
+// update applies the style of the list window
+func (vl *VirtualList) update() {
+
+	s := &vl.styles.Scroller.Normal
+	vl.SetBordersFrom(&s.Border)
+	vl.SetBordersColor4(&s.BorderColor)
+	vl.SetPaddingsFrom(&s.Paddings)
+	vl.SetColor(&s.BgColor)
+	vl.recalc()
+}
+
+//
+// TextListModel methods
+//
+
+// NewTextListModel creates and returns a pointer to a
+// new text list model with the specified items
+func NewTextListModel(items ...string) *TextListModel {
+
+	return &TextListModel{Items: items}
+}
+
+// Len satisfies the ListModel interface and returns the number of items
+func (m *TextListModel) Len() int {
+
+	return len(m.Items)
+}
+
+// NewRow satisfies the ListModel interface and returns a new label
+func (m *TextListModel) NewRow() IPanel {
+
+	return NewLabel("")
+}
+
+// BindRow satisfies the ListModel interface and sets
+// the text of the row label to the specified item
+func (m *TextListModel) BindRow(row IPanel, index int) {
+
+	row.(*Label).SetText(m.Items[index])
+}
@@ -0,0 +1,732 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+	"math"
+)
+
+// VirtualTree is a tree widget for very large hierarchies, as scene graphs
+// with tens of thousands of nodes. Its items are plain data and only the
+// rows visible in the tree window have panels, which are reused when
+// scrolling. The children of lazy items are only created by the tree
+// loader function when the item is first expanded.
+// OnChange is dispatched when the selected item changes.
+// It uses the same styles of the Tree widget.
+type VirtualTree struct {
+	Panel                          // Embedded panel
+	styles      *TreeStyles        // pointer to current styles
+	top         VirtualTreeItem    // invisible item with the top level items
+	loader      VirtualTreeLoader  // function which loads the children of lazy items
+	rows        []*VirtualTreeItem // items of all the rows of the expanded tree
+	dirty       bool               // rows must be rebuilt
+	pool        []*virtualTreeRow  // row panels of the tree window
+	vscroll     *ScrollBar         // vertical scroll bar
+	first       int                // first visible row
+	rowHeight   float32            // height of the rows in pixels
+	selected    *VirtualTreeItem   // selected item or nil
+	updating    int                // BeginUpdate() nesting level
+	scrollEvent bool               // recalc caused by the scroll bar
+}
+
+// VirtualTreeItem is an item of a VirtualTree
+type VirtualTreeItem struct {
+	tree     *VirtualTree       // tree of this item
+	parent   *VirtualTreeItem   // parent item or nil for top level items
+	children []*VirtualTreeItem // child items
+	text     string             // item text
+	data     interface{}        // user data
+	level    int                // depth of the item (top level items have 0)
+	lazy     bool               // children are loaded when first expanded
+	loaded   bool               // children of the lazy item were loaded
+	expanded bool               // item expanded flag
+}
+
+// VirtualTreeLoader is the type of the functions which add the children of
+// a lazy item of a VirtualTree when it is expanded for the first time
+type VirtualTreeLoader func(item *VirtualTreeItem)
+
+// virtualTreeRow is a row panel of the VirtualTree window which
+// shows the item of the row it is bound to
+type virtualTreeRow struct {
+	Panel                  // Embedded panel
+	icon  Label            // expand icon
+	label Label            // item text
+	item  *VirtualTreeItem // bound item
+	icode int              // icon code shown (0 = none)
+	style *ListItemStyle   // style applied
+}
+
+const virtualTreeScrollSize = 16
+
+// NewVirtualTree creates and returns a pointer to a new empty
+// virtual tree with the specified dimensions
+func NewVirtualTree(width, height float32) *VirtualTree {
+
+	vt := new(VirtualTree)
+	vt.Panel.Initialize(width, height)
+	vt.top.tree = vt
+	vt.top.level = -1
+	vt.top.expanded = true
+	vt.styles = &StyleDefault.Tree
+
+	vt.Panel.Subscribe(OnMouseDown, vt.onMouse)
+	vt.Panel.Subscribe(OnKeyDown, vt.onKey)
+	vt.Panel.Subscribe(OnCursorEnter, vt.onCursor)
+	vt.Panel.Subscribe(OnCursorLeave, vt.onCursor)
+	vt.Panel.Subscribe(OnScroll, vt.onScroll)
+	vt.Panel.Subscribe(OnResize, func(evname string, ev interface{}) { vt.recalc() })
+	vt.update()
+	return vt
+}
+
+// SetStyles sets the tree styles overriding the default style
+func (vt *VirtualTree) SetStyles(s *TreeStyles) {
+
+	vt.styles = s
+	for _, row := range vt.pool {
+		row.style = nil
+	}
+	vt.rowHeight = 0
+	vt.update()
+}
+
+// SetLoader sets the function which adds the children of the
+// lazy items when they are expanded for the first time
+func (vt *VirtualTree) SetLoader(loader VirtualTreeLoader) {
+
+	vt.loader = loader
+}
+
+// AddItem adds a new top level item with the specified
+// text and user data and returns its pointer
+func (vt *VirtualTree) AddItem(text string, data interface{}) *VirtualTreeItem {
+
+	return vt.top.AddItem(text, data)
+}
+
+// Len returns the number of top level items
+func (vt *VirtualTree) Len() int {
+
+	return len(vt.top.children)
+}
+
+// ItemAt returns the top level item at the specified position
+func (vt *VirtualTree) ItemAt(pos int) *VirtualTreeItem {
+
+	return vt.top.ItemAt(pos)
+}
+
+// Clear removes all the items of the tree
+func (vt *VirtualTree) Clear() {
+
+	vt.top.Clear()
+}
+
+// RowCount returns the number of rows of the expanded tree,
+// which is the number of items with all their parents expanded
+func (vt *VirtualTree) RowCount() int {
+
+	vt.buildRows()
+	return len(vt.rows)
+}
+
+// Selected returns the selected item or nil
+func (vt *VirtualTree) Selected() *VirtualTreeItem {
+
+	return vt.selected
+}
+
+// SetSelected selects the specified item, expanding its parents and
+// scrolling the tree to show it. A nil item clears the selection.
+func (vt *VirtualTree) SetSelected(item *VirtualTreeItem) {
+
+	if item == vt.selected {
+		return
+	}
+	vt.selected = item
+	if item != nil {
+		vt.BeginUpdate()
+		for par := item.parent; par != nil; par = par.parent {
+			par.SetExpanded(true)
+		}
+		vt.EndUpdate()
+		vt.ScrollTo(item)
+	}
+	vt.recalc()
+	vt.Dispatch(OnChange, nil)
+}
+
+// ScrollTo scrolls the tree if necessary to show the
+// row of the specified item if it is visible
+func (vt *VirtualTree) ScrollTo(item *VirtualTreeItem) {
+
+	row := vt.rowOf(item)
+	if row < 0 {
+		return
+	}
+	if row < vt.first {
+		vt.first = row
+	} else if visible := vt.visibleRows(); row >= vt.first+visible {
+		vt.first = row - visible + 1
+	}
+	vt.recalc()
+}
+
+// BeginUpdate suspends the updating of the tree rows until EndUpdate
+// is called, so that adding many items is not slowed down by the
+// tree window updates. Calls may be nested.
+// The loader function is always called inside an update.
+func (vt *VirtualTree) BeginUpdate() {
+
+	vt.updating++
+}
+
+// EndUpdate ends an update started by BeginUpdate and updates
+// the tree rows if this is the outermost update
+func (vt *VirtualTree) EndUpdate() {
+
+	if vt.updating == 0 {
+		return
+	}
+	vt.updating--
+	if vt.updating == 0 {
+		vt.recalc()
+	}
+}
+
+// changed is called when the specified item or its children changed
+func (vt *VirtualTree) changed(item *VirtualTreeItem) {
+
+	// Changes of items not visible don't change the rows
+	for par := item.parent; par != nil; par = par.parent {
+		if !par.expanded {
+			return
+		}
+	}
+	if item.expanded {
+		vt.dirty = true
+	}
+	vt.recalc()
+}
+
+// buildRows rebuilds the list of rows of the expanded tree if necessary
+func (vt *VirtualTree) buildRows() {
+
+	if !vt.dirty {
+		return
+	}
+	vt.rows = vt.rows[:0]
+	var add func(item *VirtualTreeItem)
+	add = func(item *VirtualTreeItem) {
+		for _, child := range item.children {
+			vt.rows = append(vt.rows, child)
+			if child.expanded {
+				add(child)
+			}
+		}
+	}
+	add(&vt.top)
+	vt.dirty = false
+}
+
+// rowOf returns the row of the specified item or -1 if it is not visible
+func (vt *VirtualTree) rowOf(item *VirtualTreeItem) int {
+
+	vt.buildRows()
+	for i, it := range vt.rows {
+		if it == item {
+			return i
+		}
+	}
+	return -1
+}
+
+// visibleRows returns the number of rows which fit completely in the tree window
+func (vt *VirtualTree) visibleRows() int {
+
+	vt.updateRowHeight()
+	n := int(vt.ContentHeight() / vt.rowHeight)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// maxFirst returns the maximum first visible row
+func (vt *VirtualTree) maxFirst() int {
+
+	max := len(vt.rows) - vt.visibleRows()
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// updateRowHeight calculates the height of the rows from the
+// height of the item text and icon if necessary
+func (vt *VirtualTree) updateRowHeight() {
+
+	if vt.rowHeight > 0 {
+		return
+	}
+	row := vt.newRow()
+	s := &vt.styles.List.Item.Normal
+	vt.rowHeight = math32.Max(row.label.Height(), row.icon.Height()) +
+		s.Border.Top + s.Border.Bottom + s.Paddings.Top + s.Paddings.Bottom
+}
+
+// newRow creates and returns a new row panel which is not added to the tree
+func (vt *VirtualTree) newRow() *virtualTreeRow {
+
+	row := new(virtualTreeRow)
+	row.Panel.Initialize(0, 0)
+	row.label.initialize("", StyleDefault.Font)
+	row.Panel.Add(&row.label)
+	row.icon.initialize(string(vt.styles.Node.Normal.Icons[0]), StyleDefault.FontIcon)
+	row.icon.SetFontSize(row.label.FontSize() * 1.3)
+	row.Panel.Add(&row.icon)
+	return row
+}
+
+// recalc updates the tree window rows, the scroll bar
+// and binds the row panels to the visible items
+func (vt *VirtualTree) recalc() {
+
+	if vt.updating > 0 {
+		return
+	}
+	vt.buildRows()
+	width := vt.ContentWidth()
+	height := vt.ContentHeight()
+	visible := vt.visibleRows()
+
+	// Shows the scroll bar if the rows do not fit
+	scroll := len(vt.rows) > visible
+	if scroll {
+		if vt.vscroll == nil {
+			vt.vscroll = NewVScrollBar(0, 0)
+			vt.vscroll.SetBorders(0, 0, 0, 1)
+			vt.vscroll.Subscribe(OnChange, vt.onScrollBar)
+			vt.Panel.Add(vt.vscroll)
+		}
+		vt.vscroll.SetSize(virtualTreeScrollSize, height)
+		vt.vscroll.SetPosition(width-virtualTreeScrollSize, 0)
+		vt.vscroll.recalc()
+		vt.vscroll.SetVisible(true)
+		width = math32.Max(width-virtualTreeScrollSize, 0)
+	} else if vt.vscroll != nil {
+		vt.vscroll.SetVisible(false)
+	}
+	if vt.first > vt.maxFirst() {
+		vt.first = vt.maxFirst()
+	}
+	if scroll && !vt.scrollEvent {
+		vt.vscroll.SetValue(float32(vt.first) / float32(vt.maxFirst()))
+	}
+
+	// Creates the row panels of the window including the partially visible row
+	count := int(math.Ceil(float64(height / vt.rowHeight)))
+	for len(vt.pool) < count {
+		row := vt.newRow()
+		vt.Panel.Add(row)
+		vt.pool = append(vt.pool, row)
+	}
+
+	// Binds the row panels to the items of the visible rows
+	for i, row := range vt.pool {
+		pos := vt.first + i
+		if i >= count || pos >= len(vt.rows) {
+			row.item = nil
+			row.SetVisible(false)
+			continue
+		}
+		row.SetVisible(true)
+		row.SetPosition(0, float32(i)*vt.rowHeight)
+		row.SetSize(width, vt.rowHeight)
+		vt.bind(row, vt.rows[pos])
+	}
+}
+
+// bind shows the specified item in the specified row panel
+func (vt *VirtualTree) bind(row *virtualTreeRow, item *VirtualTreeItem) {
+
+	// Applies the row style
+	s := &vt.styles.List.Item.Normal
+	if item == vt.selected {
+		s = &vt.styles.List.Item.Selected
+	}
+	if row.item != item || row.style != s {
+		row.SetBordersFrom(&s.Border)
+		row.SetBordersColor4(&s.BorderColor)
+		pads := s.Paddings
+		pads.Left += vt.styles.Padlevel * float32(item.level)
+		row.SetPaddingsFrom(&pads)
+		row.SetColor4(&s.BgColor)
+		if row.style == nil || row.style.FgColor != s.FgColor {
+			row.label.SetColor(&s.FgColor)
+			row.icon.SetColor(&s.FgColor)
+		}
+		row.style = s
+	}
+
+	// Shows the expand icon of items with children
+	icode := 0
+	if item.expandable() {
+		icode = vt.styles.Node.Normal.Icons[0]
+		if item.expanded {
+			icode = vt.styles.Node.Normal.Icons[1]
+		}
+	}
+	if icode != row.icode {
+		if icode != 0 {
+			row.icon.SetText(string(icode))
+		}
+		row.icode = icode
+	}
+	row.icon.SetVisible(icode != 0)
+	if row.label.Text() != item.text {
+		row.label.SetText(item.text)
+	}
+	row.icon.SetPosition(0, 0)
+	row.label.SetPosition(row.icon.Width()+4, 0)
+	row.item = item
+}
+
+// itemAt returns the item at the specified screen position, and if
+// the position is over its expand icon, or nil if there is no item
+func (vt *VirtualTree) itemAt(x, y float32) (*VirtualTreeItem, bool) {
+
+	y -= vt.pospix.Y + vt.content.Y
+	if y < 0 || vt.rowHeight <= 0 {
+		return nil, false
+	}
+	pos := vt.first + int(y/vt.rowHeight)
+	if pos >= len(vt.rows) {
+		return nil, false
+	}
+	item := vt.rows[pos]
+	s := &vt.styles.List.Item.Normal
+	x -= vt.pospix.X + vt.content.X + s.Border.Left + s.Paddings.Left + vt.styles.Padlevel*float32(item.level)
+	icon := false
+	if len(vt.pool) > 0 {
+		icon = x >= 0 && x < vt.pool[0].icon.Width()
+	}
+	return item, icon
+}
+
+// onMouse receives subscribed mouse button events
+func (vt *VirtualTree) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft || !vt.Enabled() {
+		return
+	}
+	if vt.vscroll != nil && vt.vscroll.Visible() && vt.vscroll.ContainsPosition(mev.Xpos, mev.Ypos) {
+		return
+	}
+	vt.root.SetKeyFocus(vt)
+	item, icon := vt.itemAt(mev.Xpos, mev.Ypos)
+	if item != nil {
+		if icon && item.expandable() {
+			item.SetExpanded(!item.expanded)
+		} else {
+			vt.SetSelected(item)
+		}
+	}
+	vt.root.StopPropagation(Stop3D)
+}
+
+// onKey receives subscribed key events
+func (vt *VirtualTree) onKey(evname string, ev interface{}) {
+
+	vt.buildRows()
+	if len(vt.rows) == 0 {
+		return
+	}
+	row := vt.rowOf(vt.selected)
+	item := vt.selected
+	kev := ev.(*window.KeyEvent)
+	switch kev.Keycode {
+	case window.KeyUp:
+		row--
+	case window.KeyDown:
+		row++
+	case window.KeyPageUp:
+		row -= vt.visibleRows()
+	case window.KeyPageDown:
+		row += vt.visibleRows()
+	case window.KeyHome:
+		row = 0
+	case window.KeyEnd:
+		row = len(vt.rows) - 1
+	case window.KeyLeft:
+		// Collapses the selected item or selects its parent
+		if item == nil {
+			return
+		}
+		if item.expanded && item.expandable() {
+			item.SetExpanded(false)
+		} else if item.parent != &vt.top {
+			row = vt.rowOf(item.parent)
+		}
+	case window.KeyRight:
+		// Expands the selected item or selects its first child
+		if item == nil || !item.expandable() {
+			return
+		}
+		if !item.expanded {
+			item.SetExpanded(true)
+		} else {
+			row++
+		}
+	case window.KeyEnter, window.KeyKPEnter:
+		if item == nil || !item.expandable() {
+			return
+		}
+		item.SetExpanded(!item.expanded)
+	default:
+		return
+	}
+	if row < 0 {
+		row = 0
+	}
+	if row >= len(vt.rows) {
+		row = len(vt.rows) - 1
+	}
+	vt.SetSelected(vt.rows[row])
+	vt.ScrollTo(vt.rows[row])
+	vt.root.StopPropagation(Stop3D)
+}
+
+// onCursor receives subscribed cursor events
+func (vt *VirtualTree) onCursor(evname string, ev interface{}) {
+
+	switch evname {
+	case OnCursorEnter:
+		vt.root.SetScrollFocus(vt)
+	case OnCursorLeave:
+		vt.root.SetScrollFocus(nil)
+	}
+	vt.root.StopPropagation(Stop3D)
+}
+
+// onScroll receives subscribed scroll events
+func (vt *VirtualTree) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	vt.first -= int(sev.Yoffset) * 3
+	if vt.first < 0 {
+		vt.first = 0
+	}
+	vt.recalc()
+	vt.root.StopPropagation(Stop3D)
+}
+
+// onScrollBar receives the change events of the vertical scroll bar
+func (vt *VirtualTree) onScrollBar(evname string, ev interface{}) {
+
+	vt.first = int(math.Floor(float64(vt.maxFirst())*vt.vscroll.Value() + 0.5))
+	vt.scrollEvent = true
+	vt.recalc()
+	vt.scrollEvent = false
+}
+
+// update applies the style of the tree window
+func (vt *VirtualTree) update() {
+
+	s := &vt.styles.List.Scroller.Normal
+	vt.SetBordersFrom(&s.Border)
+	vt.SetBordersColor4(&s.BorderColor)
+	vt.SetPaddingsFrom(&s.Paddings)
+	vt.SetColor(&s.BgColor)
+	vt.recalc()
+}
+
+//
+// VirtualTreeItem methods
+//
+
+// Tree returns the tree of this item
+func (it *VirtualTreeItem) Tree() *VirtualTree {
+
+	return it.tree
+}
+
+// Parent returns the parent item of this item or nil if it is a top level item
+func (it *VirtualTreeItem) Parent() *VirtualTreeItem {
+
+	if it.parent == &it.tree.top {
+		return nil
+	}
+	return it.parent
+}
+
+// Level returns the depth of this item in the tree.
+// Top level items have level 0.
+func (it *VirtualTreeItem) Level() int {
+
+	return it.level
+}
+
+// Text returns the text of this item
+func (it *VirtualTreeItem) Text() string {
+
+	return it.text
+}
+
+// SetText sets the text of this item
+func (it *VirtualTreeItem) SetText(text string) {
+
+	it.text = text
+	it.tree.recalc()
+}
+
+// Data returns the user data of this item
+func (it *VirtualTreeItem) Data() interface{} {
+
+	return it.data
+}
+
+// SetData sets the user data of this item
+func (it *VirtualTreeItem) SetData(data interface{}) {
+
+	it.data = data
+}
+
+// SetLazy sets if the children of this item are added by the tree loader
+// function when it is expanded for the first time. Lazy items show the
+// expand icon before their children are loaded.
+func (it *VirtualTreeItem) SetLazy(state bool) {
+
+	it.lazy = state
+	it.tree.recalc()
+}
+
+// Lazy returns if the children of this item are added by the tree loader
+func (it *VirtualTreeItem) Lazy() bool {
+
+	return it.lazy
+}
+
+// AddItem adds a new child item with the specified text
+// and user data to this item and returns its pointer
+func (it *VirtualTreeItem) AddItem(text string, data interface{}) *VirtualTreeItem {
+
+	child := &VirtualTreeItem{tree: it.tree, parent: it, text: text, data: data, level: it.level + 1}
+	it.children = append(it.children, child)
+	it.tree.changed(it)
+	return child
+}
+
+// Len returns the number of children of this item
+func (it *VirtualTreeItem) Len() int {
+
+	return len(it.children)
+}
+
+// ItemAt returns the child item at the specified position
+func (it *VirtualTreeItem) ItemAt(pos int) *VirtualTreeItem {
+
+	if pos < 0 || pos >= len(it.children) {
+		return nil
+	}
+	return it.children[pos]
+}
+
+// Remove removes this item and its children from the tree
+func (it *VirtualTreeItem) Remove() {
+
+	par := it.parent
+	if par == nil {
+		return
+	}
+	for pos, child := range par.children {
+		if child == it {
+			copy(par.children[pos:], par.children[pos+1:])
+			par.children[len(par.children)-1] = nil
+			par.children = par.children[:len(par.children)-1]
+			break
+		}
+	}
+	it.parent = nil
+	it.tree.unselect(it)
+	it.tree.changed(par)
+}
+
+// Clear removes all the children of this item.
+// The children of lazy items are loaded again when expanded.
+func (it *VirtualTreeItem) Clear() {
+
+	for _, child := range it.children {
+		child.parent = nil
+		it.tree.unselect(child)
+	}
+	it.children = nil
+	it.loaded = false
+	if it.lazy && it != &it.tree.top {
+		it.expanded = false
+	}
+	it.tree.changed(it)
+}
+
+// Expanded returns the expanded state of this item
+func (it *VirtualTreeItem) Expanded() bool {
+
+	return it.expanded
+}
+
+// SetExpanded sets the expanded state of this item.
+// Lazy items load their children when expanded for the first time.
+func (it *VirtualTreeItem) SetExpanded(state bool) {
+
+	if state == it.expanded {
+		return
+	}
+	tree := it.tree
+	if state && it.lazy && !it.loaded && tree.loader != nil {
+		it.loaded = true
+		tree.BeginUpdate()
+		tree.loader(it)
+		tree.EndUpdate()
+	}
+	it.expanded = state
+	// Selects the collapsed item if it contains the selected item
+	if !state && tree.selected != nil && it.contains(tree.selected) {
+		tree.selected = it
+		tree.Dispatch(OnChange, nil)
+	}
+	tree.dirty = true
+	tree.recalc()
+}
+
+// expandable returns if this item has or may have children
+func (it *VirtualTreeItem) expandable() bool {
+
+	return len(it.children) > 0 || (it.lazy && !it.loaded && it.tree.loader != nil)
+}
+
+// contains returns if the specified item is a descendant of this item
+func (it *VirtualTreeItem) contains(item *VirtualTreeItem) bool {
+
+	for par := item.parent; par != nil; par = par.parent {
+		if par == it {
+			return true
+		}
+	}
+	return false
+}
+
+// unselect clears the selection if the selected item is the
+// specified item or one of its descendants
+func (vt *VirtualTree) unselect(item *VirtualTreeItem) {
+
+	if vt.selected != nil && (vt.selected == item || item.contains(vt.selected)) {
+		vt.selected = nil
+		vt.Dispatch(OnChange, nil)
+	}
+}
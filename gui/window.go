@@ -39,6 +39,7 @@ type Window struct {
 	drag       bool
 	mouseX     float32
 	mouseY     float32
+	docker     *Docker // docker which manages this window or nil
 }
 
 type WindowStyle struct {
@@ -74,6 +75,14 @@ const (
 func NewWindow(width, height float32) *Window {
 
 	w := new(Window)
+	w.Initialize(width, height)
+	return w
+}
+
+// Initialize initializes the window with the specified dimensions
+// It is normally used when the window is embedded in another object
+func (w *Window) Initialize(width, height float32) {
+
 	w.styles = &StyleDefault.Window
 
 	w.Panel.Initialize(width, height)
@@ -89,7 +98,6 @@ func NewWindow(width, height float32) *Window {
 
 	w.recalc()
 	w.update()
-	return w
 }
 
 // SetResizable set the borders which are resizable
@@ -303,9 +311,15 @@ func (wt *WindowTitle) onMouse(evname string, ev interface{}) {
 		wt.mouseX = mev.Xpos
 		wt.mouseY = mev.Ypos
 		wt.win.root.SetMouseFocus(wt)
+		if wt.win.docker != nil {
+			wt.win.docker.startDrag(wt.win, mev.Xpos, mev.Ypos)
+		}
 	case OnMouseUp:
 		wt.pressed = false
 		wt.win.root.SetMouseFocus(nil)
+		if wt.win.docker != nil {
+			wt.win.docker.endDrag(mev.Xpos, mev.Ypos)
+		}
 	default:
 		return
 	}
@@ -325,6 +339,14 @@ func (wt *WindowTitle) onCursor(evname string, ev interface{}) {
 			return
 		}
 		cev := ev.(*window.CursorEvent)
+		// Docked windows are not moved but may be dragged to other dock areas
+		if wt.win.docker != nil {
+			wt.win.docker.moveDrag(cev.Xpos, cev.Ypos)
+			if wt.win.docker.nodes[wt.win] != nil {
+				wt.win.root.StopPropagation(Stop3D)
+				return
+			}
+		}
 		dy := wt.mouseY - cev.Ypos
 		dx := wt.mouseX - cev.Xpos
 		wt.mouseX = cev.Xpos
@@ -4,10 +4,15 @@
 
 // This is a minimum G3N application showing how to create a window,
 // a scene, add some 3D objects to the scene and render it.
+// With the -top option it also shows the scene from the top in a second
+// window, which shares the OpenGL objects with the first one.
+// With the -shot option it renders one frame in a headless window, with no
+// display needed, and saves it to a PNG file (requires the "egl" build tag).
 // For more complete demos please see: https://github.com/g3n/g3nd
 package main
 
 import (
+	"flag"
 	"github.com/g3n/engine/camera"
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/geometry"
@@ -18,14 +23,46 @@ import (
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/renderer"
 	"github.com/g3n/engine/window"
+	"image"
+	"image/png"
 	"math"
+	"os"
 	"runtime"
 )
 
+var topView = flag.Bool("top", false, "shows the scene from the top in a second window")
+var shotFile = flag.String("shot", "", "renders one frame in a headless window and saves it to the specified PNG file")
+
+// spinner is a component which rotates its node around the Y axis (up)
+type spinner struct {
+	core.Component
+	speed float32 // radians per second
+}
+
+// Update rotates the node of this spinner for the elapsed time
+func (s *spinner) Update(delta float32) {
+
+	s.Node().AddRotationY(s.speed * delta)
+}
+
+// view is a window with its own OpenGL state, renderer and camera
+type view struct {
+	win  window.IWindow
+	gs   *gls.GLS
+	rend *renderer.Renderer
+	cam  camera.ICamera
+}
+
 func main() {
 
+	flag.Parse()
+
 	// Creates window and OpenGL context
-	win, err := window.New("glfw", 800, 600, "Hello G3N", false)
+	wtype := window.Backend
+	if *shotFile != "" {
+		wtype = "headless"
+	}
+	win, err := window.New(wtype, 800, 600, "Hello G3N", false)
 	if err != nil {
 		panic(err)
 	}
@@ -42,6 +79,7 @@ func main() {
 
 	// Creates scene for 3D objects
 	scene := core.NewNode()
+	scene.SetSceneRoot(true)
 
 	// Adds white ambient light to the scene
 	ambLight := light.NewAmbient(&math32.Color{1.0, 1.0, 1.0}, 0.5)
@@ -50,8 +88,8 @@ func main() {
 	// Adds a perspective camera to the scene
 	width, height := win.GetSize()
 	aspect := float32(width) / float32(height)
-	camera := camera.NewPerspective(65, aspect, 0.01, 1000)
-	camera.SetPosition(0, 0, 5)
+	cam := camera.NewPerspective(65, aspect, 0.01, 1000)
+	cam.SetPosition(0, 0, 5)
 
 	// Add an axis helper
 	axis := graphic.NewAxisHelper(2)
@@ -63,6 +101,7 @@ func main() {
 	mat.SetSide(material.SideDouble)
 	mat.SetWireframe(true)
 	sphere := graphic.NewMesh(geom, mat)
+	sphere.AddComponent(&spinner{speed: 0.3})
 	scene.Add(sphere)
 
 	// Creates a renderer and adds default shaders
@@ -74,21 +113,106 @@ func main() {
 
 	// Sets window background color
 	gs.ClearColor(0, 0, 0, 1.0)
+	views := []*view{{win, gs, rend, cam}}
+
+	// Creates the second window, whose OpenGL context shares the objects
+	// of the scene and needs its own OpenGL state and renderer
+	if *topView {
+		win2, err := window.NewShared(400, 400, "Hello G3N - Top", win)
+		if err != nil {
+			panic(err)
+		}
+		gs2, err := gls.New()
+		if err != nil {
+			panic(err)
+		}
+		rend2 := renderer.NewRenderer(gs2)
+		err = rend2.AddDefaultShaders()
+		if err != nil {
+			panic(err)
+		}
+		gs2.ClearColor(0.1, 0.1, 0.1, 1.0)
+		top := camera.NewPerspective(65, 1, 0.01, 1000)
+		top.SetPosition(0, 8, 0.01)
+		top.LookAt(&math32.Vector3{0, 0, 0})
+		views = append(views, &view{win2, gs2, rend2, top})
+	}
 
-	// Render loop
-	for !win.ShouldClose() {
-
-		// Clear buffers
+	// Renders one frame and saves it
+	if *shotFile != "" {
 		gs.Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
+		rend.Render(scene, cam)
+		err = saveFrame(gs, width, height, *shotFile)
+		if err != nil {
+			panic(err)
+		}
+		win.Destroy()
+		return
+	}
 
-		// Rotates the sphere a bit around the Z axis (up)
-		sphere.AddRotationY(0.005)
+	// Updates the components of the scene at a fixed rate for the
+	// fixed updates and then the scheduler and the components for each frame
+	loop := core.NewLoop(60)
+	loop.SetFixedUpdate(scene.FixedUpdateComponents)
+	loop.SetUpdate(func(delta float32) {
+		core.UpdateScheduler(delta)
+		scene.UpdateComponents(delta)
+	})
 
-		// Render the scene using the specified camera
-		rend.Render(scene, camera)
+	// Render loop
+	for !win.ShouldClose() {
 
-		// Update window and checks for I/O events
-		win.SwapBuffers()
+		// Dispatches the events posted by other goroutines
+		// and updates the scene for the elapsed time
+		core.DispatchEvents()
+		loop.Tick()
+
+		// Renders the scene in each window with its context current
+		for i := 0; i < len(views); i++ {
+			v := views[i]
+			if i > 0 && v.win.ShouldClose() {
+				v.win.Destroy()
+				views = append(views[:i], views[i+1:]...)
+				i--
+				continue
+			}
+			v.win.MakeContextCurrent()
+
+			// Clear buffers
+			v.gs.Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
+
+			// Render the scene using the specified camera
+			v.rend.Render(scene, v.cam)
+
+			// Update window
+			v.win.SwapBuffers()
+		}
+
+		// Checks for I/O events of all windows
 		win.PollEvents()
 	}
 }
+
+// saveFrame reads the pixels of the default framebuffer with the specified
+// size and saves them to the specified PNG file
+func saveFrame(gs *gls.GLS, width, height int, filename string) error {
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	gs.ReadPixels(0, 0, int32(width), int32(height), gls.RGBA, gls.UNSIGNED_BYTE, img.Pix)
+
+	// The framebuffer rows start from the bottom
+	row := make([]byte, img.Stride)
+	for y := 0; y < height/2; y++ {
+		top := img.Pix[y*img.Stride : (y+1)*img.Stride]
+		bottom := img.Pix[(height-1-y)*img.Stride : (height-y)*img.Stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
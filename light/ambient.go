@@ -6,15 +6,14 @@ package light
 
 import (
 	"github.com/g3n/engine/core"
-	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/math32"
 )
 
 type Ambient struct {
-	core.Node               // Embedded node
-	color     math32.Color  // Light color
-	intensity float32       // Light intensity
-	uColor    gls.Uniform3f // Light color uniform (color * intensity)
+	core.Node              // Embedded node
+	color     math32.Color // Light color
+	intensity float32      // Light intensity
+	cullMask  core.Layers  // Layers of the cameras which use this light
 }
 
 // NewAmbient returns a pointer to a new ambient color with the specified
@@ -26,8 +25,7 @@ func NewAmbient(color *math32.Color, intensity float32) *Ambient {
 
 	la.color = *color
 	la.intensity = intensity
-	la.uColor.Init("AmbientLightColor")
-	la.SetColor(color)
+	la.cullMask = core.AllLayers
 	return la
 }
 
@@ -35,9 +33,6 @@ func NewAmbient(color *math32.Color, intensity float32) *Ambient {
 func (la *Ambient) SetColor(color *math32.Color) {
 
 	la.color = *color
-	tmpColor := la.color
-	tmpColor.MultiplyScalar(la.intensity)
-	la.uColor.SetColor(&tmpColor)
 }
 
 // Color returns the current color of this light
@@ -50,9 +45,6 @@ func (la *Ambient) Color() math32.Color {
 func (la *Ambient) SetIntensity(intensity float32) {
 
 	la.intensity = intensity
-	tmpColor := la.color
-	tmpColor.MultiplyScalar(la.intensity)
-	la.uColor.SetColor(&tmpColor)
 }
 
 // Intensity returns the current intensity of this light
@@ -61,8 +53,25 @@ func (la *Ambient) Intensity() float32 {
 	return la.intensity
 }
 
+// SetCullMask sets the layers of the cameras which use this light.
+// The light is used to render the scene for the cameras whose cull masks
+// have any of these layers. By default the light is used by all the cameras.
+func (la *Ambient) SetCullMask(mask core.Layers) {
+
+	la.cullMask = mask
+}
+
+// CullMask returns the layers of the cameras which use this light
+func (la *Ambient) CullMask() core.Layers {
+
+	return la.cullMask
+}
+
 // RenderSetup is called by the engine before rendering the scene
-func (la *Ambient) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
+// to append the parameters of this light to the lights block.
+func (la *Ambient) RenderSetup(block *Block, rinfo *core.RenderInfo) {
 
-	la.uColor.TransferIdx(gs, idx)
+	color := la.color
+	color.MultiplyScalar(la.intensity)
+	block.AmbientLightColor = append(block.AmbientLightColor, color)
 }
@@ -6,16 +6,14 @@ package light
 
 import (
 	"github.com/g3n/engine/core"
-	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/math32"
 )
 
 type Directional struct {
-	core.Node                // Embedded node
-	color      math32.Color  // Light color
-	intensity  float32       // Light intensity
-	uColor     gls.Uniform3f // Light color uniform (color * intensity)
-	uDirection gls.Uniform3f // Light direction uniform
+	core.Node              // Embedded node
+	color     math32.Color // Light color
+	intensity float32      // Light intensity
+	cullMask  core.Layers  // Layers of the cameras which use this light
 }
 
 func NewDirectional(color *math32.Color, intensity float32) *Directional {
@@ -25,9 +23,7 @@ func NewDirectional(color *math32.Color, intensity float32) *Directional {
 
 	ld.color = *color
 	ld.intensity = intensity
-	ld.uColor.Init("DirLightColor")
-	ld.uDirection.Init("DirLightPosition")
-	ld.SetColor(color)
+	ld.cullMask = core.AllLayers
 	return ld
 }
 
@@ -35,9 +31,6 @@ func NewDirectional(color *math32.Color, intensity float32) *Directional {
 func (ld *Directional) SetColor(color *math32.Color) {
 
 	ld.color = *color
-	tmpColor := ld.color
-	tmpColor.MultiplyScalar(ld.intensity)
-	ld.uColor.SetColor(&tmpColor)
 }
 
 // Color returns the current color of this light
@@ -50,9 +43,6 @@ func (ld *Directional) Color() math32.Color {
 func (ld *Directional) SetIntensity(intensity float32) {
 
 	ld.intensity = intensity
-	tmpColor := ld.color
-	tmpColor.MultiplyScalar(ld.intensity)
-	ld.uColor.SetColor(&tmpColor)
 }
 
 // Intensity returns the current intensity of this light
@@ -61,17 +51,33 @@ func (ld *Directional) Intensity() float32 {
 	return ld.intensity
 }
 
+// SetCullMask sets the layers of the cameras which use this light.
+// The light is used to render the scene for the cameras whose cull masks
+// have any of these layers. By default the light is used by all the cameras.
+func (ld *Directional) SetCullMask(mask core.Layers) {
+
+	ld.cullMask = mask
+}
+
+// CullMask returns the layers of the cameras which use this light
+func (ld *Directional) CullMask() core.Layers {
+
+	return ld.cullMask
+}
+
 // RenderSetup is called by the engine before rendering the scene
-func (ld *Directional) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
+// to append the parameters of this light to the lights block.
+func (ld *Directional) RenderSetup(block *Block, rinfo *core.RenderInfo) {
 
 	// Sets color
-	ld.uColor.TransferIdx(gs, idx)
+	color := ld.color
+	color.MultiplyScalar(ld.intensity)
+	block.DirLightColor = append(block.DirLightColor, color)
 
-	// Calculates and updates light direction uniform in camera coordinates
+	// Calculates light direction in camera coordinates
 	var pos math32.Vector3
 	ld.WorldPosition(&pos)
 	pos4 := math32.Vector4{pos.X, pos.Y, pos.Z, 0.0}
 	pos4.ApplyMatrix4(&rinfo.ViewMatrix)
-	ld.uDirection.SetVector3(&math32.Vector3{pos4.X, pos4.Y, pos4.Z})
-	ld.uDirection.TransferIdx(gs, idx)
+	block.DirLightPosition = append(block.DirLightPosition, math32.Vector3{pos4.X, pos4.Y, pos4.Z})
 }
@@ -6,10 +6,52 @@ package light
 
 import (
 	"github.com/g3n/engine/core"
-	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
 )
 
 // ILight is the interface that must be implemented for all light types.
 type ILight interface {
-	RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int)
+	RenderSetup(block *Block, rinfo *core.RenderInfo)
+	CullMask() core.Layers
+}
+
+// Block contains the parameters of all the lights of a scene in camera
+// coordinates. Its fields are declared in the same order as the members of
+// the "Lights" uniform block of the shaders and it is transferred once per
+// frame by the renderer in a uniform buffer shared by all shader programs.
+// Each light appends its parameters to the arrays of its type.
+type Block struct {
+	AmbientLightColor        []math32.Color
+	DirLightColor            []math32.Color
+	DirLightPosition         []math32.Vector3
+	PointLightColor          []math32.Color
+	PointLightPosition       []math32.Vector3
+	PointLightLinearDecay    []float32
+	PointLightQuadraticDecay []float32
+	SpotLightColor           []math32.Color
+	SpotLightPosition        []math32.Vector3
+	SpotLightDirection       []math32.Vector3
+	SpotLightAngularDecay    []float32
+	SpotLightCutoffAngle     []float32
+	SpotLightLinearDecay     []float32
+	SpotLightQuadraticDecay  []float32
+}
+
+// Reset removes the parameters of all lights from this block
+func (b *Block) Reset() {
+
+	b.AmbientLightColor = b.AmbientLightColor[0:0]
+	b.DirLightColor = b.DirLightColor[0:0]
+	b.DirLightPosition = b.DirLightPosition[0:0]
+	b.PointLightColor = b.PointLightColor[0:0]
+	b.PointLightPosition = b.PointLightPosition[0:0]
+	b.PointLightLinearDecay = b.PointLightLinearDecay[0:0]
+	b.PointLightQuadraticDecay = b.PointLightQuadraticDecay[0:0]
+	b.SpotLightColor = b.SpotLightColor[0:0]
+	b.SpotLightPosition = b.SpotLightPosition[0:0]
+	b.SpotLightDirection = b.SpotLightDirection[0:0]
+	b.SpotLightAngularDecay = b.SpotLightAngularDecay[0:0]
+	b.SpotLightCutoffAngle = b.SpotLightCutoffAngle[0:0]
+	b.SpotLightLinearDecay = b.SpotLightLinearDecay[0:0]
+	b.SpotLightQuadraticDecay = b.SpotLightQuadraticDecay[0:0]
 }
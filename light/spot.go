@@ -6,22 +6,19 @@ package light
 
 import (
 	"github.com/g3n/engine/core"
-	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/math32"
 )
 
 type Spot struct {
-	core.Node                      // Embedded node
-	color           math32.Color   // Light color
-	intensity       float32        // Light intensity
-	direction       math32.Vector3 // Direction in world coordinates
-	uColor          gls.Uniform3f  // Uniform for light color
-	uPosition       gls.Uniform3f  // Uniform for position in camera coordinates
-	uDirection      gls.Uniform3f  // Uniform for direction in camera coordinates
-	uAngularDecay   gls.Uniform1f  // Uniform for angular attenuation exponent
-	uCutoffAngle    gls.Uniform1f  // Uniform for cutoff angle from 0 to 90 degrees
-	uLinearDecay    gls.Uniform1f  // Uniform for linear distance decay
-	uQuadraticDecay gls.Uniform1f  // Uniform for quadratic distance decay
+	core.Node                     // Embedded node
+	color          math32.Color   // Light color
+	intensity      float32        // Light intensity
+	cullMask       core.Layers    // Layers of the cameras which use this light
+	direction      math32.Vector3 // Direction in world coordinates
+	angularDecay   float32        // Angular attenuation exponent
+	cutoffAngle    float32        // Cutoff angle from 0 to 90 degrees
+	linearDecay    float32        // Linear distance decay
+	quadraticDecay float32        // Quadratic distance decay
 }
 
 // NewSpot creates and returns a spot light with the specified color and intensity
@@ -32,23 +29,13 @@ func NewSpot(color *math32.Color, intensity float32) *Spot {
 
 	sp.color = *color
 	sp.intensity = intensity
-
-	// Creates uniforms
-	sp.uColor.Init("SpotLightColor")
-	sp.uPosition.Init("SpotLightPosition")
-	sp.uDirection.Init("SpotLightDirection")
-	sp.uAngularDecay.Init("SpotLightAngularDecay")
-	sp.uCutoffAngle.Init("SpotLightCutoffAngle")
-	sp.uLinearDecay.Init("SpotLightLinearDecay")
-	sp.uQuadraticDecay.Init("SpotQuadraticDecay")
+	sp.cullMask = core.AllLayers
 
 	// Set initial values
-	sp.intensity = intensity
-	sp.SetColor(color)
-	sp.uAngularDecay.Set(15.0)
-	sp.uCutoffAngle.Set(45.0)
-	sp.uLinearDecay.Set(1.0)
-	sp.uQuadraticDecay.Set(1.0)
+	sp.angularDecay = 15.0
+	sp.cutoffAngle = 45.0
+	sp.linearDecay = 1.0
+	sp.quadraticDecay = 1.0
 	return sp
 }
 
@@ -56,9 +43,6 @@ func NewSpot(color *math32.Color, intensity float32) *Spot {
 func (sl *Spot) SetColor(color *math32.Color) {
 
 	sl.color = *color
-	tmpColor := sl.color
-	tmpColor.MultiplyScalar(sl.intensity)
-	sl.uColor.SetColor(&tmpColor)
 }
 
 // Color returns the current color of this light
@@ -71,9 +55,6 @@ func (sl *Spot) Color() math32.Color {
 func (sl *Spot) SetIntensity(intensity float32) {
 
 	sl.intensity = intensity
-	tmpColor := sl.color
-	tmpColor.MultiplyScalar(sl.intensity)
-	sl.uColor.SetColor(&tmpColor)
 }
 
 // Intensity returns the current intensity of this light
@@ -82,6 +63,20 @@ func (sl *Spot) Intensity() float32 {
 	return sl.intensity
 }
 
+// SetCullMask sets the layers of the cameras which use this light.
+// The light is used to render the scene for the cameras whose cull masks
+// have any of these layers. By default the light is used by all the cameras.
+func (sl *Spot) SetCullMask(mask core.Layers) {
+
+	sl.cullMask = mask
+}
+
+// CullMask returns the layers of the cameras which use this light
+func (sl *Spot) CullMask() core.Layers {
+
+	return sl.cullMask
+}
+
 // SetDirection sets the direction of the spot light in world coordinates
 func (sp *Spot) SetDirection(direction *math32.Vector3) {
 
@@ -97,73 +92,74 @@ func (sp *Spot) Direction(direction *math32.Vector3) math32.Vector3 {
 // SetCutoffAngle sets the cutoff angle in degrees from 0 to 90
 func (sl *Spot) SetCutoffAngle(angle float32) {
 
-	sl.uCutoffAngle.Set(angle)
+	sl.cutoffAngle = angle
 }
 
 // CutoffAngle returns the current cutoff angle in degrees from 0 to 90
 func (sl *Spot) CutoffAngle() float32 {
 
-	return sl.uCutoffAngle.Get()
+	return sl.cutoffAngle
 }
 
 // SetAngularDecay sets the angular decay exponent
 func (sl *Spot) SetAngularDecay(decay float32) {
 
-	sl.uAngularDecay.Set(decay)
+	sl.angularDecay = decay
 }
 
 // AngularDecay returns the current angular decay exponent
 func (sl *Spot) AngularDecay() float32 {
 
-	return sl.uAngularDecay.Get()
+	return sl.angularDecay
 }
 
 // SetLinearDecay sets the linear decay factor as a function of the distance
 func (sl *Spot) SetLinearDecay(decay float32) {
 
-	sl.uLinearDecay.Set(decay)
+	sl.linearDecay = decay
 }
 
 // LinearDecay returns the current linear decay factor
 func (sl *Spot) LinearDecay() float32 {
 
-	return sl.uLinearDecay.Get()
+	return sl.linearDecay
 }
 
 // SetQuadraticDecay sets the quadratic decay factor as a function of the distance
 func (sl *Spot) SetQuadraticDecay(decay float32) {
 
-	sl.uQuadraticDecay.Set(decay)
+	sl.quadraticDecay = decay
 }
 
 // QuadraticDecay returns the current quadratic decay factor
 func (sl *Spot) QuadraticDecay() float32 {
 
-	return sl.uQuadraticDecay.Get()
+	return sl.quadraticDecay
 }
 
 // RenderSetup is called by the engine before rendering the scene
-func (sl *Spot) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo, idx int) {
-
-	sl.uColor.TransferIdx(gs, idx)
-	sl.uAngularDecay.TransferIdx(gs, idx)
-	sl.uCutoffAngle.TransferIdx(gs, idx)
-	sl.uLinearDecay.TransferIdx(gs, idx)
-	sl.uQuadraticDecay.TransferIdx(gs, idx)
-
-	// Calculates and updates light position uniform in camera coordinates
+// to append the parameters of this light to the lights block.
+func (sl *Spot) RenderSetup(block *Block, rinfo *core.RenderInfo) {
+
+	color := sl.color
+	color.MultiplyScalar(sl.intensity)
+	block.SpotLightColor = append(block.SpotLightColor, color)
+	block.SpotLightAngularDecay = append(block.SpotLightAngularDecay, sl.angularDecay)
+	block.SpotLightCutoffAngle = append(block.SpotLightCutoffAngle, sl.cutoffAngle)
+	block.SpotLightLinearDecay = append(block.SpotLightLinearDecay, sl.linearDecay)
+	block.SpotLightQuadraticDecay = append(block.SpotLightQuadraticDecay, sl.quadraticDecay)
+
+	// Calculates light position in camera coordinates
 	var pos math32.Vector3
 	sl.WorldPosition(&pos)
 	var pos4 math32.Vector4
 	pos4.SetVector3(&pos, 1.0)
 	pos4.ApplyMatrix4(&rinfo.ViewMatrix)
-	sl.uPosition.SetVector3(&math32.Vector3{pos4.X, pos4.Y, pos4.Z})
-	sl.uPosition.TransferIdx(gs, idx)
+	block.SpotLightPosition = append(block.SpotLightPosition, math32.Vector3{pos4.X, pos4.Y, pos4.Z})
 
-	// Calculates and updates light direction uniform in camera coordinates
+	// Calculates light direction in camera coordinates
 	pos4.SetVector3(&sl.direction, 0.0)
 	pos4.ApplyMatrix4(&rinfo.ViewMatrix)
 	// Normalize here ??
-	sl.uDirection.SetVector3(&math32.Vector3{pos4.X, pos4.Y, pos4.Z})
-	sl.uDirection.TransferIdx(gs, idx)
+	block.SpotLightDirection = append(block.SpotLightDirection, math32.Vector3{pos4.X, pos4.Y, pos4.Z})
 }
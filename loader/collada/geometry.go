@@ -57,7 +57,16 @@ func (d *Decoder) NewGeometry(id string) (geometry.IGeometry, uint32, error) {
 	// Collada mesh category includes points, lines, linestrips, triangles,
 	// triangle fans, triangle strips and polygons.
 	case *Mesh:
-		return newMesh(gt)
+		geom, mode, err := newMesh(gt)
+		if err != nil {
+			return nil, 0, err
+		}
+		if geo.Name != "" {
+			geom.SetName(geo.Name)
+		} else {
+			geom.SetName(id)
+		}
+		return geom, mode, nil
 		// B-Spline
 		// Bezier
 		// NURBS
@@ -0,0 +1,215 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Component types of the accessors
+const (
+	componentByte   = 5120
+	componentUbyte  = 5121
+	componentShort  = 5122
+	componentUshort = 5123
+	componentUint   = 5125
+	componentFloat  = 5126
+)
+
+// typeSizes are the number of components of the accessor types
+var typeSizes = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+// componentSize returns the number of bytes of the specified component type or 0 if invalid
+func componentSize(ctype int) int {
+
+	switch ctype {
+	case componentByte, componentUbyte:
+		return 1
+	case componentShort, componentUshort:
+		return 2
+	case componentUint, componentFloat:
+		return 4
+	}
+	return 0
+}
+
+// floats returns the components of the elements of the accessor with the
+// specified index converted to float32, normalizing the normalized integers,
+// and the number of components of each element
+func (d *Decoder) floats(idx int) ([]float32, int, error) {
+
+	if idx < 0 || idx >= len(d.Doc.Accessors) {
+		return nil, 0, fmt.Errorf("invalid accessor index: %d", idx)
+	}
+	acc := d.Doc.Accessors[idx]
+	size := typeSizes[acc.Type]
+	csize := componentSize(acc.ComponentType)
+	if size == 0 || csize == 0 || acc.Count < 0 {
+		return nil, 0, fmt.Errorf("invalid type of accessor %d", idx)
+	}
+	values := make([]float32, acc.Count*size)
+
+	// The accessors without buffer view are zeros, replaced by their sparse elements
+	if acc.BufferView != nil {
+		data, stride, err := d.accessorData(idx, acc.ByteOffset, acc.Count, size*csize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := 0; i < acc.Count; i++ {
+			readFloats(data[i*stride:], acc.ComponentType, acc.Normalized, values[i*size:(i+1)*size])
+		}
+	}
+	if acc.Sparse != nil {
+		sp := acc.Sparse
+		indices, err := d.sparseIndices(idx)
+		if err != nil {
+			return nil, 0, err
+		}
+		data, err := d.bufferView(sp.Values.BufferView)
+		if err != nil {
+			return nil, 0, err
+		}
+		esize := size * csize
+		if sp.Values.ByteOffset < 0 || sp.Values.ByteOffset+sp.Count*esize > len(data) {
+			return nil, 0, fmt.Errorf("sparse values of accessor %d outside of their buffer view", idx)
+		}
+		data = data[sp.Values.ByteOffset:]
+		for i, vi := range indices {
+			readFloats(data[i*esize:], acc.ComponentType, acc.Normalized, values[vi*size:(vi+1)*size])
+		}
+	}
+	return values, size, nil
+}
+
+// indices returns the elements of the scalar integer accessor with the specified index
+func (d *Decoder) indices(idx int) ([]uint32, error) {
+
+	if idx < 0 || idx >= len(d.Doc.Accessors) {
+		return nil, fmt.Errorf("invalid accessor index: %d", idx)
+	}
+	acc := d.Doc.Accessors[idx]
+	csize := componentSize(acc.ComponentType)
+	if acc.Type != "SCALAR" || acc.BufferView == nil || acc.Sparse != nil || acc.ComponentType == componentFloat || csize == 0 {
+		return nil, fmt.Errorf("invalid indices accessor %d", idx)
+	}
+	data, stride, err := d.accessorData(idx, acc.ByteOffset, acc.Count, csize)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]uint32, acc.Count)
+	for i := range indices {
+		indices[i] = readUint(data[i*stride:], acc.ComponentType)
+	}
+	return indices, nil
+}
+
+// sparseIndices returns the indices of the sparse elements of the accessor
+// with the specified index, checking they are valid elements
+func (d *Decoder) sparseIndices(idx int) ([]int, error) {
+
+	acc := d.Doc.Accessors[idx]
+	sp := acc.Sparse
+	csize := componentSize(sp.Indices.ComponentType)
+	if csize < 1 || sp.Indices.ComponentType == componentFloat || sp.Count < 0 {
+		return nil, fmt.Errorf("invalid sparse indices of accessor %d", idx)
+	}
+	data, err := d.bufferView(sp.Indices.BufferView)
+	if err != nil {
+		return nil, err
+	}
+	if sp.Indices.ByteOffset < 0 || sp.Indices.ByteOffset+sp.Count*csize > len(data) {
+		return nil, fmt.Errorf("sparse indices of accessor %d outside of their buffer view", idx)
+	}
+	data = data[sp.Indices.ByteOffset:]
+	indices := make([]int, sp.Count)
+	for i := range indices {
+		indices[i] = int(readUint(data[i*csize:], sp.Indices.ComponentType))
+		if indices[i] >= acc.Count {
+			return nil, fmt.Errorf("invalid sparse index of accessor %d", idx)
+		}
+	}
+	return indices, nil
+}
+
+// accessorData returns the bytes of the buffer view of the accessor with the
+// specified index from the specified offset and the stride of its elements,
+// checking they contain the specified number of elements of the specified size
+func (d *Decoder) accessorData(idx, offset, count, esize int) ([]byte, int, error) {
+
+	acc := d.Doc.Accessors[idx]
+	data, err := d.bufferView(*acc.BufferView)
+	if err != nil {
+		return nil, 0, err
+	}
+	stride := d.Doc.BufferViews[*acc.BufferView].ByteStride
+	if stride == 0 {
+		stride = esize
+	}
+	if offset < 0 || stride < esize || (count > 0 && offset+(count-1)*stride+esize > len(data)) {
+		return nil, 0, fmt.Errorf("accessor %d outside of its buffer view", idx)
+	}
+	return data[offset:], stride, nil
+}
+
+// readFloats reads the components of an element from the specified
+// bytes and stores them in the specified slice as float32
+func readFloats(data []byte, ctype int, normalized bool, out []float32) {
+
+	for i := range out {
+		switch ctype {
+		case componentFloat:
+			out[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+		case componentByte:
+			v := float32(int8(data[i]))
+			if normalized {
+				v = float32(math.Max(float64(v)/127, -1))
+			}
+			out[i] = v
+		case componentUbyte:
+			v := float32(data[i])
+			if normalized {
+				v /= 255
+			}
+			out[i] = v
+		case componentShort:
+			v := float32(int16(binary.LittleEndian.Uint16(data[2*i:])))
+			if normalized {
+				v = float32(math.Max(float64(v)/32767, -1))
+			}
+			out[i] = v
+		case componentUshort:
+			v := float32(binary.LittleEndian.Uint16(data[2*i:]))
+			if normalized {
+				v /= 65535
+			}
+			out[i] = v
+		case componentUint:
+			out[i] = float32(binary.LittleEndian.Uint32(data[4*i:]))
+		}
+	}
+}
+
+// readUint reads an unsigned integer of the specified component type from the specified bytes
+func readUint(data []byte, ctype int) uint32 {
+
+	switch ctype {
+	case componentUbyte, componentByte:
+		return uint32(data[0])
+	case componentUshort, componentShort:
+		return uint32(binary.LittleEndian.Uint16(data))
+	default:
+		return binary.LittleEndian.Uint32(data)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"fmt"
+	"github.com/g3n/engine/animation"
+)
+
+// NewClips creates and returns the animation clips of the animations of the
+// document, to be played by an animation.Mixer attached to the node returned
+// by NewScene. The weights channels animate the graphic.MorphMesh of each
+// primitive of the mesh of their nodes.
+func (d *Decoder) NewClips() ([]*animation.Clip, error) {
+
+	clips := make([]*animation.Clip, 0, len(d.Doc.Animations))
+	for i := range d.Doc.Animations {
+		clip, err := d.NewClip(i)
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, clip)
+	}
+	return clips, nil
+}
+
+// NewClip creates and returns the animation clip of the animation with
+// the specified index, named as the animation or "animation" followed
+// by its index if it has no name
+func (d *Decoder) NewClip(idx int) (*animation.Clip, error) {
+
+	if idx < 0 || idx >= len(d.Doc.Animations) {
+		return nil, fmt.Errorf("invalid animation index: %d", idx)
+	}
+	anim := d.Doc.Animations[idx]
+	name := anim.Name
+	if name == "" {
+		name = fmt.Sprintf("animation%d", idx)
+	}
+	clip := animation.NewClip(name)
+	for ci, ch := range anim.Channels {
+		if ch.Target.Node == nil {
+			continue
+		}
+		ni := *ch.Target.Node
+		if ni < 0 || ni >= len(d.Doc.Nodes) || ch.Sampler < 0 || ch.Sampler >= len(anim.Samplers) {
+			return nil, fmt.Errorf("invalid channel %d of animation %d", ci, idx)
+		}
+		s := anim.Samplers[ch.Sampler]
+		var interp animation.Interpolation
+		switch s.Interpolation {
+		case "", "LINEAR":
+			interp = animation.Linear
+		case "STEP":
+			interp = animation.Step
+		case "CUBICSPLINE":
+			interp = animation.Cubic
+		default:
+			return nil, fmt.Errorf("invalid interpolation of animation %d: %s", idx, s.Interpolation)
+		}
+		times, size, err := d.floats(s.Input)
+		if err != nil {
+			return nil, err
+		}
+		if size != 1 {
+			return nil, fmt.Errorf("invalid input of sampler %d of animation %d", ch.Sampler, idx)
+		}
+		values, _, err := d.floats(s.Output)
+		if err != nil {
+			return nil, err
+		}
+
+		// The tracks are relative to the node of the scene, so their targets
+		// are paths even for the root nodes
+		target := "./" + d.NodePath(ni)
+		var prop animation.Property
+		size = 3
+		switch ch.Target.Path {
+		case "translation":
+			prop = animation.Position
+		case "rotation":
+			prop = animation.Rotation
+			size = 4
+		case "scale":
+			prop = animation.Scale
+		case "weights":
+			err = d.addWeightsTracks(clip, ni, target, interp, times, values)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			log.Warn("channel %d of animation %d ignored: path %q not supported", ci, idx, ch.Target.Path)
+			continue
+		}
+		if !validValues(interp, times, values, size) {
+			return nil, fmt.Errorf("invalid output of sampler %d of animation %d", ch.Sampler, idx)
+		}
+		clip.AddTrack(animation.NewTrack(target, prop, interp, times, values))
+	}
+	return clip, nil
+}
+
+// addWeightsTracks adds to the specified clip a track of the specified weights
+// for the morph mesh of each primitive with morph targets of the mesh of the
+// node with the specified index and path
+func (d *Decoder) addWeightsTracks(clip *animation.Clip, ni int, path string, interp animation.Interpolation, times, values []float32) error {
+
+	n := d.Doc.Nodes[ni]
+	if n.Mesh == nil || *n.Mesh < 0 || *n.Mesh >= len(d.Doc.Meshes) {
+		return fmt.Errorf("weights animation of node %d without mesh", ni)
+	}
+	mi := *n.Mesh
+	for pi, p := range d.Doc.Meshes[mi].Primitives {
+		if len(p.Targets) == 0 {
+			continue
+		}
+		if !validValues(interp, times, values, len(p.Targets)) {
+			return fmt.Errorf("weights animation of node %d doesn't match the morph targets", ni)
+		}
+		clip.AddTrack(animation.NewWeightsTrack(path+"/"+d.primitiveName(mi, pi), interp, times, values))
+	}
+	return nil
+}
+
+// validValues returns if the number of the specified values is the number of
+// values with the specified size of the keyframes with the specified times
+func validValues(interp animation.Interpolation, times, values []float32, size int) bool {
+
+	per := len(times)
+	if interp == animation.Cubic {
+		per *= 3
+	}
+	return len(values) == per*size
+}
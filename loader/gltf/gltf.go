@@ -0,0 +1,371 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gltf implements the loader of glTF 2.0 files, in the JSON
+// (.gltf) and binary (.glb) formats.
+//
+// The loader creates the nodes of the scenes with their meshes and the
+// base color, emissive color and base color texture of their materials,
+// and creates the animation clips of the animations. The meshes with
+// morph targets are graphic.MorphMesh with the default weights of the
+// glTF mesh, or of the node, and the animations of the weights are
+// animation Weights tracks. Skins, cameras and extensions are not imported.
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GLTF is the top level object of a glTF document
+type GLTF struct {
+	Asset       Asset        `json:"asset"`
+	Scene       *int         `json:"scene"`
+	Scenes      []Scene      `json:"scenes"`
+	Nodes       []Node       `json:"nodes"`
+	Meshes      []Mesh       `json:"meshes"`
+	Materials   []Material   `json:"materials"`
+	Textures    []Texture    `json:"textures"`
+	Images      []Image      `json:"images"`
+	Accessors   []Accessor   `json:"accessors"`
+	BufferViews []BufferView `json:"bufferViews"`
+	Buffers     []Buffer     `json:"buffers"`
+	Animations  []Animation  `json:"animations"`
+}
+
+// Asset is the metadata of a glTF document
+type Asset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+// Scene is a set of root nodes
+type Scene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+// Node is a node of the hierarchy with its transform, mesh and children
+type Node struct {
+	Name        string    `json:"name"`
+	Children    []int     `json:"children"`
+	Mesh        *int      `json:"mesh"`
+	Matrix      []float32 `json:"matrix"`      // column major matrix or nil
+	Translation []float32 `json:"translation"` // translation or nil
+	Rotation    []float32 `json:"rotation"`    // rotation quaternion as x, y, z, w or nil
+	Scale       []float32 `json:"scale"`       // scale or nil
+	Weights     []float32 `json:"weights"`     // weights of the morph targets overriding the mesh weights
+}
+
+// Mesh is a set of primitives with the default weights of their morph targets
+type Mesh struct {
+	Name       string      `json:"name"`
+	Primitives []Primitive `json:"primitives"`
+	Weights    []float32   `json:"weights"`
+	Extras     struct {
+		TargetNames []string `json:"targetNames"` // names of the morph targets
+	} `json:"extras"`
+}
+
+// Primitive is the geometry of a mesh with a material.
+// The attributes and the morph targets map their semantic
+// names, as "POSITION", to the indices of their accessors.
+type Primitive struct {
+	Attributes map[string]int   `json:"attributes"`
+	Indices    *int             `json:"indices"`
+	Material   *int             `json:"material"`
+	Mode       *int             `json:"mode"`
+	Targets    []map[string]int `json:"targets"`
+}
+
+// Material is a metallic-roughness material
+type Material struct {
+	Name                 string `json:"name"`
+	PbrMetallicRoughness *struct {
+		BaseColorFactor  []float32   `json:"baseColorFactor"`
+		BaseColorTexture *TextureRef `json:"baseColorTexture"`
+	} `json:"pbrMetallicRoughness"`
+	EmissiveFactor []float32 `json:"emissiveFactor"`
+	AlphaMode      string    `json:"alphaMode"`
+	DoubleSided    bool      `json:"doubleSided"`
+}
+
+// TextureRef references a texture from a material
+type TextureRef struct {
+	Index    int `json:"index"`
+	TexCoord int `json:"texCoord"`
+}
+
+// Texture is an image with a sampler
+type Texture struct {
+	Source *int `json:"source"`
+}
+
+// Image is an image in a file, a data URI or a buffer view
+type Image struct {
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+// Accessor is a typed view of the elements of a buffer view
+type Accessor struct {
+	BufferView    *int    `json:"bufferView"`
+	ByteOffset    int     `json:"byteOffset"`
+	ComponentType int     `json:"componentType"`
+	Normalized    bool    `json:"normalized"`
+	Count         int     `json:"count"`
+	Type          string  `json:"type"`
+	Sparse        *Sparse `json:"sparse"`
+}
+
+// Sparse are the elements of an accessor which differ from
+// the elements of its buffer view, or from zero
+type Sparse struct {
+	Count   int `json:"count"`
+	Indices struct {
+		BufferView    int `json:"bufferView"`
+		ByteOffset    int `json:"byteOffset"`
+		ComponentType int `json:"componentType"`
+	} `json:"indices"`
+	Values struct {
+		BufferView int `json:"bufferView"`
+		ByteOffset int `json:"byteOffset"`
+	} `json:"values"`
+}
+
+// BufferView is a range of bytes of a buffer
+type BufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+// Buffer is binary data in a file, a data URI or the binary chunk of a .glb file
+type Buffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+// Animation is a set of channels which animate the properties of nodes
+type Animation struct {
+	Name     string             `json:"name"`
+	Channels []AnimationChannel `json:"channels"`
+	Samplers []AnimationSampler `json:"samplers"`
+}
+
+// AnimationChannel animates a property of a node, as "translation",
+// "rotation", "scale" or "weights", with a sampler
+type AnimationChannel struct {
+	Sampler int `json:"sampler"`
+	Target  struct {
+		Node *int   `json:"node"`
+		Path string `json:"path"`
+	} `json:"target"`
+}
+
+// AnimationSampler has the accessors of the times and values of the keyframes
+type AnimationSampler struct {
+	Input         int    `json:"input"`
+	Output        int    `json:"output"`
+	Interpolation string `json:"interpolation"` // "LINEAR" (default), "STEP" or "CUBICSPLINE"
+}
+
+// Decoder contains the decoded glTF document and its buffers
+type Decoder struct {
+	Doc     GLTF     // decoded document
+	dir     string   // directory of the external files
+	bin     []byte   // binary chunk of a .glb file or nil
+	buffers [][]byte // loaded buffers by index
+	parents []int    // parent of each node or -1
+}
+
+// Binary glTF constants
+const (
+	glbMagic     = 0x46546C67 // "glTF"
+	glbChunkJSON = 0x4E4F534A // "JSON"
+	glbChunkBIN  = 0x004E4942 // "BIN"
+)
+
+// Decode decodes the specified .gltf or .glb file returning
+// a decoder object and an error.
+func Decode(fpath string) (*Decoder, error) {
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeReader(f, filepath.Dir(fpath))
+}
+
+// DecodeReader decodes the glTF document, in the JSON or binary format, from
+// the specified reader returning a decoder object and an error. The URIs of
+// the external buffers and images are relative to the specified directory.
+func DecodeReader(r io.Reader, dir string) (*Decoder, error) {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d := new(Decoder)
+	d.dir = dir
+
+	// Splits the chunks of binary glTF
+	if len(data) >= 12 && binary.LittleEndian.Uint32(data) == glbMagic {
+		data, err = d.splitGLB(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = json.Unmarshal(data, &d.Doc)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(d.Doc.Asset.Version, "2") {
+		return nil, fmt.Errorf("unsupported glTF version: %q", d.Doc.Asset.Version)
+	}
+
+	// Sets the parents of the nodes
+	d.parents = make([]int, len(d.Doc.Nodes))
+	for i := range d.parents {
+		d.parents[i] = -1
+	}
+	for i, n := range d.Doc.Nodes {
+		for _, c := range n.Children {
+			if c < 0 || c >= len(d.parents) || d.parents[c] != -1 {
+				return nil, fmt.Errorf("invalid child %d of node %d", c, i)
+			}
+			d.parents[c] = i
+		}
+	}
+	d.buffers = make([][]byte, len(d.Doc.Buffers))
+	return d, nil
+}
+
+// splitGLB returns the JSON chunk of the specified binary glTF
+// data and keeps its binary chunk
+func (d *Decoder) splitGLB(data []byte) ([]byte, error) {
+
+	if binary.LittleEndian.Uint32(data[4:]) != 2 {
+		return nil, errors.New("unsupported binary glTF version")
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, errors.New("truncated binary glTF")
+	}
+	var jsonChunk []byte
+	for pos := 12; pos+8 <= length; {
+		size := int(binary.LittleEndian.Uint32(data[pos:]))
+		ctype := binary.LittleEndian.Uint32(data[pos+4:])
+		pos += 8
+		if pos+size > length {
+			return nil, errors.New("truncated binary glTF chunk")
+		}
+		switch ctype {
+		case glbChunkJSON:
+			jsonChunk = data[pos : pos+size]
+		case glbChunkBIN:
+			d.bin = data[pos : pos+size]
+		}
+		pos += size
+	}
+	if jsonChunk == nil {
+		return nil, errors.New("binary glTF without JSON chunk")
+	}
+	return jsonChunk, nil
+}
+
+// buffer returns the data of the buffer with the specified index,
+// loading it the first time
+func (d *Decoder) buffer(idx int) ([]byte, error) {
+
+	if idx < 0 || idx >= len(d.Doc.Buffers) {
+		return nil, fmt.Errorf("invalid buffer index: %d", idx)
+	}
+	if d.buffers[idx] != nil {
+		return d.buffers[idx], nil
+	}
+	b := d.Doc.Buffers[idx]
+	var data []byte
+	var err error
+	if b.URI == "" {
+		if idx != 0 || d.bin == nil {
+			return nil, fmt.Errorf("buffer %d without uri", idx)
+		}
+		data = d.bin
+	} else {
+		data, err = d.loadURI(b.URI)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(data) < b.ByteLength {
+		return nil, fmt.Errorf("buffer %d shorter than its length", idx)
+	}
+	d.buffers[idx] = data
+	return data, nil
+}
+
+// bufferView returns the bytes of the buffer view with the specified index
+func (d *Decoder) bufferView(idx int) ([]byte, error) {
+
+	if idx < 0 || idx >= len(d.Doc.BufferViews) {
+		return nil, fmt.Errorf("invalid buffer view index: %d", idx)
+	}
+	bv := d.Doc.BufferViews[idx]
+	data, err := d.buffer(bv.Buffer)
+	if err != nil {
+		return nil, err
+	}
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(data) {
+		return nil, fmt.Errorf("buffer view %d outside of its buffer", idx)
+	}
+	return data[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], nil
+}
+
+// loadURI returns the data of the specified data URI or file
+// relative to the directory of the document
+func (d *Decoder) loadURI(uri string) ([]byte, error) {
+
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, errors.New("unsupported data uri")
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+	return ioutil.ReadFile(filepath.Join(d.dir, filepath.FromSlash(uri)))
+}
+
+// imageData returns the encoded data of the image with the specified index
+func (d *Decoder) imageData(idx int) (io.Reader, error) {
+
+	if idx < 0 || idx >= len(d.Doc.Images) {
+		return nil, fmt.Errorf("invalid image index: %d", idx)
+	}
+	img := d.Doc.Images[idx]
+	var data []byte
+	var err error
+	if img.BufferView != nil {
+		data, err = d.bufferView(*img.BufferView)
+	} else {
+		data, err = d.loadURI(img.URI)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,337 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // registers the decoder of the JPEG images
+	_ "image/png"  // registers the decoder of the PNG images
+)
+
+// Primitive modes
+const modeTriangles = 4
+
+// NewScene creates and returns a node with the root nodes of the default
+// scene of the document, or of its first scene, as children. The tracks
+// of the clips returned by NewClips find their nodes from this node.
+func (d *Decoder) NewScene() (*core.Node, error) {
+
+	idx := 0
+	if d.Doc.Scene != nil {
+		idx = *d.Doc.Scene
+	}
+	if idx < 0 || idx >= len(d.Doc.Scenes) {
+		return nil, fmt.Errorf("invalid scene index: %d", idx)
+	}
+	s := d.Doc.Scenes[idx]
+	materials := make(map[int]material.IMaterial)
+	root := core.NewNode()
+	root.SetName(s.Name)
+	for _, ni := range s.Nodes {
+		if ni < 0 || ni >= len(d.Doc.Nodes) || d.parents[ni] != -1 {
+			return nil, fmt.Errorf("invalid root node: %d", ni)
+		}
+		inode, err := d.newNode(ni, materials)
+		if err != nil {
+			return nil, err
+		}
+		root.Add(inode)
+	}
+	return root, nil
+}
+
+// NodeName returns the name of the engine node of the glTF node with the
+// specified index, which is the name of the glTF node or "node" followed
+// by its index if it has no name
+func (d *Decoder) NodeName(idx int) string {
+
+	if name := d.Doc.Nodes[idx].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("node%d", idx)
+}
+
+// NodePath returns the path of the engine node of the glTF node with
+// the specified index from the node returned by NewScene
+func (d *Decoder) NodePath(idx int) string {
+
+	path := d.NodeName(idx)
+	for p := d.parents[idx]; p != -1; p = d.parents[p] {
+		path = d.NodeName(p) + "/" + path
+	}
+	return path
+}
+
+// primitiveName returns the name of the mesh of the primitive
+// with the specified index of the specified mesh
+func (d *Decoder) primitiveName(mi, pi int) string {
+
+	return fmt.Sprintf("mesh%d.%d", mi, pi)
+}
+
+// newNode creates and returns the node of the glTF node with the specified
+// index and its children, using the specified map of the created materials
+func (d *Decoder) newNode(idx int, materials map[int]material.IMaterial) (*core.Node, error) {
+
+	n := d.Doc.Nodes[idx]
+	node := core.NewNode()
+	node.SetName(d.NodeName(idx))
+	node.SetLoaderID(fmt.Sprint(idx))
+
+	// Sets the transform, decomposing the matrix so it can be animated
+	if len(n.Matrix) == 16 {
+		var m math32.Matrix4
+		var pos, scale math32.Vector3
+		var quat math32.Quaternion
+		copy(m[:], n.Matrix)
+		m.Decompose(&pos, &quat, &scale)
+		node.SetPositionVec(&pos)
+		node.SetQuaternionQuat(&quat)
+		node.SetScaleVec(&scale)
+	}
+	if len(n.Translation) == 3 {
+		node.SetPosition(n.Translation[0], n.Translation[1], n.Translation[2])
+	}
+	if len(n.Rotation) == 4 {
+		node.SetQuaternion(n.Rotation[0], n.Rotation[1], n.Rotation[2], n.Rotation[3])
+	}
+	if len(n.Scale) == 3 {
+		node.SetScale(n.Scale[0], n.Scale[1], n.Scale[2])
+	}
+
+	// Adds a mesh for each primitive of the mesh
+	if n.Mesh != nil {
+		mi := *n.Mesh
+		if mi < 0 || mi >= len(d.Doc.Meshes) {
+			return nil, fmt.Errorf("invalid mesh index of node %d: %d", idx, mi)
+		}
+		weights := d.Doc.Meshes[mi].Weights
+		if n.Weights != nil {
+			weights = n.Weights
+		}
+		for pi := range d.Doc.Meshes[mi].Primitives {
+			mesh, err := d.newPrimitive(mi, pi, weights, materials)
+			if err != nil {
+				return nil, err
+			}
+			if mesh != nil {
+				node.Add(mesh)
+			}
+		}
+	}
+
+	for _, ci := range n.Children {
+		child, err := d.newNode(ci, materials)
+		if err != nil {
+			return nil, err
+		}
+		node.Add(child)
+	}
+	return node, nil
+}
+
+// newPrimitive creates and returns the mesh of the primitive with the specified
+// index of the specified mesh, as a graphic.MorphMesh with the specified weights
+// if it has morph targets. Returns nil if the primitive is not of triangles.
+func (d *Decoder) newPrimitive(mi, pi int, weights []float32, materials map[int]material.IMaterial) (core.INode, error) {
+
+	m := d.Doc.Meshes[mi]
+	p := m.Primitives[pi]
+	if p.Mode != nil && *p.Mode != modeTriangles {
+		log.Warn("primitive %d of mesh %d ignored: mode %d not supported", pi, mi, *p.Mode)
+		return nil, nil
+	}
+	geom, count, err := d.newGeometry(mi, pi)
+	if err != nil {
+		return nil, err
+	}
+	mat, err := d.newMaterial(p.Material, materials)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.Targets) == 0 {
+		mesh := graphic.NewMesh(geom, mat)
+		mesh.SetName(d.primitiveName(mi, pi))
+		return mesh, nil
+	}
+
+	// Adds the morph targets with their displacements of the positions and normals
+	mesh := graphic.NewMorphMesh(geom, mat)
+	mesh.SetName(d.primitiveName(mi, pi))
+	for ti, target := range p.Targets {
+		name := fmt.Sprintf("target%d", ti)
+		if ti < len(m.Extras.TargetNames) {
+			name = m.Extras.TargetNames[ti]
+		}
+		var positions, normals math32.ArrayF32
+		if ai, ok := target["POSITION"]; ok {
+			positions, err = d.vec3s(ai, count)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			positions = math32.NewArrayF32(3*count, 3*count)
+		}
+		if ai, ok := target["NORMAL"]; ok {
+			normals, err = d.vec3s(ai, count)
+			if err != nil {
+				return nil, err
+			}
+		}
+		mesh.AddMorphTarget(name, positions, normals)
+	}
+	if len(weights) > 0 {
+		mesh.SetWeights(weights)
+	}
+	return mesh, nil
+}
+
+// newGeometry creates and returns the geometry of the primitive with the
+// specified index of the specified mesh and its number of vertices
+func (d *Decoder) newGeometry(mi, pi int) (*geometry.Geometry, int, error) {
+
+	p := d.Doc.Meshes[mi].Primitives[pi]
+	ai, ok := p.Attributes["POSITION"]
+	if !ok {
+		return nil, 0, fmt.Errorf("primitive %d of mesh %d without positions", pi, mi)
+	}
+	geom := geometry.NewGeometry()
+	geom.SetName(d.Doc.Meshes[mi].Name)
+	positions, size, err := d.floats(ai)
+	if err != nil {
+		return nil, 0, err
+	}
+	if size != 3 {
+		return nil, 0, fmt.Errorf("invalid positions of primitive %d of mesh %d", pi, mi)
+	}
+	count := len(positions) / 3
+	geom.AddVBO(gls.NewVBO().AddAttrib("VertexPosition", 3).SetBuffer(positions))
+
+	if ai, ok := p.Attributes["NORMAL"]; ok {
+		normals, err := d.vec3s(ai, count)
+		if err != nil {
+			return nil, 0, err
+		}
+		geom.AddVBO(gls.NewVBO().AddAttrib("VertexNormal", 3).SetBuffer(normals))
+	}
+	if ai, ok := p.Attributes["TEXCOORD_0"]; ok {
+		uvs, size, err := d.floats(ai)
+		if err != nil {
+			return nil, 0, err
+		}
+		if size != 2 || len(uvs) != 2*count {
+			return nil, 0, fmt.Errorf("invalid texture coordinates of primitive %d of mesh %d", pi, mi)
+		}
+		geom.AddVBO(gls.NewVBO().AddAttrib("VertexTexcoord", 2).SetBuffer(uvs))
+	}
+	if p.Indices != nil {
+		indices, err := d.indices(*p.Indices)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, i := range indices {
+			if int(i) >= count {
+				return nil, 0, fmt.Errorf("invalid index of primitive %d of mesh %d", pi, mi)
+			}
+		}
+		geom.SetIndices(math32.ArrayU32(indices))
+	}
+	return geom, count, nil
+}
+
+// vec3s returns the 3D vectors of the accessor with the
+// specified index, checking it has the specified count
+func (d *Decoder) vec3s(ai, count int) (math32.ArrayF32, error) {
+
+	values, size, err := d.floats(ai)
+	if err != nil {
+		return nil, err
+	}
+	if size != 3 || len(values) != 3*count {
+		return nil, fmt.Errorf("accessor %d is not %d 3D vectors", ai, count)
+	}
+	return values, nil
+}
+
+// newMaterial returns the material with the specified index, or a default
+// material if nil, creating it the first time in the specified map
+func (d *Decoder) newMaterial(idx *int, materials map[int]material.IMaterial) (material.IMaterial, error) {
+
+	mi := -1
+	if idx != nil {
+		mi = *idx
+		if mi < 0 || mi >= len(d.Doc.Materials) {
+			return nil, fmt.Errorf("invalid material index: %d", mi)
+		}
+	}
+	if imat, ok := materials[mi]; ok {
+		imat.GetMaterial().Incref()
+		return imat, nil
+	}
+	mat := material.NewStandard(math32.NewColor(1, 1, 1))
+	materials[mi] = mat
+	if mi < 0 {
+		return mat, nil
+	}
+
+	gm := d.Doc.Materials[mi]
+	if pbr := gm.PbrMetallicRoughness; pbr != nil {
+		if len(pbr.BaseColorFactor) == 4 {
+			c := pbr.BaseColorFactor
+			mat.SetColor(math32.NewColor(c[0], c[1], c[2]))
+			mat.SetOpacity(c[3])
+		}
+		if pbr.BaseColorTexture != nil {
+			tex, err := d.newTexture(pbr.BaseColorTexture.Index)
+			if err != nil {
+				return nil, err
+			}
+			mat.AddTexture(tex)
+		}
+	}
+	if len(gm.EmissiveFactor) == 3 {
+		e := gm.EmissiveFactor
+		mat.SetEmissiveColor(math32.NewColor(e[0], e[1], e[2]))
+	}
+	if gm.AlphaMode == "BLEND" {
+		mat.SetTransparent(true)
+	}
+	if gm.DoubleSided {
+		mat.SetSide(material.SideDouble)
+	}
+	return mat, nil
+}
+
+// newTexture creates and returns the texture with the specified index
+func (d *Decoder) newTexture(idx int) (*texture.Texture2D, error) {
+
+	if idx < 0 || idx >= len(d.Doc.Textures) || d.Doc.Textures[idx].Source == nil {
+		return nil, fmt.Errorf("invalid texture index: %d", idx)
+	}
+	r, err := d.imageData(*d.Doc.Textures[idx].Source)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	tex := texture.NewTexture2DFromRGBA(rgba)
+	// The origin of the texture coordinates of glTF is the top left corner
+	tex.SetFlipY(false)
+	return tex, nil
+}
@@ -192,6 +192,7 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 
 	geom := geometry.NewGeometry()
+	geom.SetName(obj.Name)
 
 	// Create buffers
 	positions := math32.NewArrayF32(0, 0)
@@ -8,6 +8,7 @@ package material
 
 import (
 	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/texture"
 )
 
@@ -46,7 +47,7 @@ const (
 // Interface for all materials
 type IMaterial interface {
 	GetMaterial() *Material
-	RenderSetup(gs *gls.GLS)
+	RenderSetup(gs gls.IDevice)
 	Dispose()
 }
 
@@ -54,25 +55,44 @@ type IMaterial interface {
 // Base Material
 //
 type Material struct {
-	refcount         int                  // Current number of references
-	shader           string               // Shader name
-	uselights        UseLights            // Use lights bit mask
-	sidevis          Side                 // sides visible
-	wireframe        bool                 // show as wirefrme
-	depthMask        bool                 // Enable writing into the depth buffer
-	depthTest        bool                 // Enable depth buffer test
-	depthFunc        uint32               // Actvie depth test function
-	blending         Blending             // blending mode
-	blendRGB         uint32               // separate blend equation for RGB
-	blendAlpha       uint32               // separate blend equation for Alpha
-	blendSrcRGB      uint32               // separate blend func source RGB
-	blendDstRGB      uint32               // separate blend func dest RGB
-	blendSrcAlpha    uint32               // separate blend func source Alpha
-	blendDstAlpha    uint32               // separate blend func dest Alpha
-	lineWidth        float32              // line width for lines and mesh wireframe
-	polyOffsetFactor float32              // polygon offset factor
-	polyOffsetUnits  float32              // polygon offset units
-	textures         []*texture.Texture2D // List of textures
+	refcount         int                     // Current number of references
+	shader           string                  // Shader name
+	uselights        UseLights               // Use lights bit mask
+	sidevis          Side                    // sides visible
+	wireframe        bool                    // show as wirefrme
+	depthMask        bool                    // Enable writing into the depth buffer
+	depthTest        bool                    // Enable depth buffer test
+	depthFunc        uint32                  // Actvie depth test function
+	blending         Blending                // blending mode
+	transparent      bool                    // rendered in the transparency pass
+	blendRGB         uint32                  // separate blend equation for RGB
+	blendAlpha       uint32                  // separate blend equation for Alpha
+	blendSrcRGB      uint32                  // separate blend func source RGB
+	blendDstRGB      uint32                  // separate blend func dest RGB
+	blendSrcAlpha    uint32                  // separate blend func source Alpha
+	blendDstAlpha    uint32                  // separate blend func dest Alpha
+	lineWidth        float32                 // line width for lines and mesh wireframe
+	polyOffsetFactor float32                 // polygon offset factor
+	polyOffsetUnits  float32                 // polygon offset units
+	textures         []*texture.Texture2D    // List of textures
+	samplers         []*gls.SamplerState     // Optional sampling states of the textures
+	textureArrays    []*texture.TextureArray // List of texture arrays
+	textures3D       []*texture.Texture3D    // List of 3D textures
+	pstate           gls.PipelineState       // pipeline state sent to the device
+	block            materialBlock           // material uniform block data
+	blockUpdate      bool                    // material uniform block data changed
+	ubo              gls.UBO                 // material uniform buffer
+}
+
+// materialBlock contains the colors of a material declared in the same
+// order as the members of the "Material" uniform block of the shaders.
+type materialBlock struct {
+	ambientColor  math32.Color
+	diffuseColor  math32.Color
+	specularColor math32.Color
+	shininess     float32
+	emissiveColor math32.Color
+	opacity       float32
 }
 
 // NewMaterial returns a pointer to a new material
@@ -96,6 +116,12 @@ func (mat *Material) Init() *Material {
 	mat.polyOffsetFactor = 0
 	mat.polyOffsetUnits = 0
 	mat.textures = make([]*texture.Texture2D, 0)
+	mat.samplers = make([]*gls.SamplerState, 0)
+	mat.textureArrays = make([]*texture.TextureArray, 0)
+	mat.textures3D = make([]*texture.Texture3D, 0)
+	mat.block = materialBlock{opacity: 1.0}
+	mat.blockUpdate = true
+	mat.ubo.Init("Material", gls.MaterialBlockBinding)
 
 	return mat
 }
@@ -128,6 +154,13 @@ func (mat *Material) Dispose() {
     for i := 0; i < len(mat.textures); i++ {
         mat.textures[i].Dispose()
     }
+	for _, tex := range mat.textureArrays {
+		tex.Dispose()
+	}
+	for _, tex := range mat.textures3D {
+		tex.Dispose()
+	}
+	mat.ubo.Dispose()
 	mat.Init()
 }
 
@@ -187,92 +220,166 @@ func (mat *Material) SetBlending(blending Blending) {
 	mat.blending = blending
 }
 
+// Blending returns the current blending mode of this material
+func (mat *Material) Blending() Blending {
+
+	return mat.blending
+}
+
+// SetTransparent sets if this material is transparent.
+// Transparent materials are rendered after all opaque materials,
+// either sorted back to front or using order independent transparency
+// if it was enabled in the renderer for the scene.
+func (mat *Material) SetTransparent(state bool) {
+
+	mat.transparent = state
+}
+
+// Transparent returns the transparent state of this material
+func (mat *Material) Transparent() bool {
+
+	return mat.transparent
+}
+
 func (mat *Material) SetLineWidth(width float32) {
 
 	mat.lineWidth = width
 }
 
+// LineWidth returns the current line width of this material
+func (mat *Material) LineWidth() float32 {
+
+	return mat.lineWidth
+}
+
 func (mat *Material) SetPolygonOffset(factor, units float32) {
 
 	mat.polyOffsetFactor = factor
 	mat.polyOffsetUnits = units
 }
 
-func (mat *Material) RenderSetup(gs *gls.GLS) {
+// PolygonOffset returns the current polygon offset factor and units of this material
+func (mat *Material) PolygonOffset() (factor, units float32) {
 
-	// Sets triangle side view mode
-	switch mat.sidevis {
-	case SideFront:
-		gs.Enable(gls.CULL_FACE)
-		gs.FrontFace(gls.CCW)
-	case SideBack:
-		gs.Enable(gls.CULL_FACE)
-		gs.FrontFace(gls.CW)
-	case SideDouble:
-		gs.Disable(gls.CULL_FACE)
-		gs.FrontFace(gls.CCW)
+	return mat.polyOffsetFactor, mat.polyOffsetUnits
+}
+
+// DepthMask returns if this material writes into the depth buffer
+func (mat *Material) DepthMask() bool {
+
+	return mat.depthMask
+}
+
+// DepthTest returns if this material uses the depth buffer test
+func (mat *Material) DepthTest() bool {
+
+	return mat.depthTest
+}
+
+// Wireframe returns if this material is rendered as wireframe
+func (mat *Material) Wireframe() bool {
+
+	return mat.wireframe
+}
+
+// RenderSetup is called by the renderer before drawing the graphics which use
+// this material. It sets the pipeline state of this material and its textures.
+func (mat *Material) RenderSetup(gs gls.IDevice) {
+
+	mat.PipelineState(&mat.pstate)
+	gs.SetPipelineState(&mat.pstate)
+
+	// Transfer the material uniform block if changed and binds it
+	if mat.blockUpdate {
+		mat.ubo.Set(&mat.block)
+		mat.blockUpdate = false
+	}
+	mat.ubo.Transfer(gs)
+
+	// Render textures
+	for idx, tex := range mat.textures {
+		tex.RenderSetup(gs, idx)
+		if state := mat.samplers[idx]; state != nil {
+			gs.BindSampler(uint32(idx), gs.Sampler(state))
+		}
 	}
 
-	if mat.depthTest {
-		gs.Enable(gls.DEPTH_TEST)
-	} else {
-		gs.Disable(gls.DEPTH_TEST)
+	// Render texture arrays using the texture units after the textures
+	for idx, tex := range mat.textureArrays {
+		tex.RenderSetup(gs, len(mat.textures)+idx, idx)
 	}
-	gs.DepthMask(mat.depthMask)
-	gs.DepthFunc(mat.depthFunc)
 
-	if mat.wireframe {
-		gs.PolygonMode(gls.FRONT_AND_BACK, gls.LINE)
-	} else {
-		gs.PolygonMode(gls.FRONT_AND_BACK, gls.FILL)
+	// Render 3D textures using the texture units after the texture arrays
+	for idx, tex := range mat.textures3D {
+		tex.RenderSetup(gs, len(mat.textures)+len(mat.textureArrays)+idx, idx)
 	}
+}
+
+// DepthRenderSetup sets the pipeline state of this material without its textures.
+// It is used by the renderer for depth only passes.
+func (mat *Material) DepthRenderSetup(gs gls.IDevice) {
 
-	// Set polygon offset if requested
-	gs.PolygonOffset(mat.polyOffsetFactor, mat.polyOffsetUnits)
+	mat.PipelineState(&mat.pstate)
+	gs.SetPipelineState(&mat.pstate)
+}
 
-	// Sets line width
-	gs.LineWidth(mat.lineWidth)
+// PipelineState fills the specified pipeline state from the
+// side, depth, polygon, line and blending properties of this material.
+func (mat *Material) PipelineState(ps *gls.PipelineState) {
+
+	// Sets triangle side view mode
+	switch mat.sidevis {
+	case SideFront:
+		ps.CullFace = true
+		ps.FrontFace = gls.CCW
+	case SideBack:
+		ps.CullFace = true
+		ps.FrontFace = gls.CW
+	case SideDouble:
+		ps.CullFace = false
+		ps.FrontFace = gls.CCW
+	}
+
+	ps.DepthTest = mat.depthTest
+	ps.DepthMask = mat.depthMask
+	ps.DepthFunc = mat.depthFunc
+	ps.Wireframe = mat.wireframe
+	ps.PolygonOffsetFactor = mat.polyOffsetFactor
+	ps.PolygonOffsetUnits = mat.polyOffsetUnits
+	ps.LineWidth = mat.lineWidth
 
 	// Sets blending
+	ps.Blend = true
+	ps.BlendEquationRGB = gls.FUNC_ADD
+	ps.BlendEquationAlpha = gls.FUNC_ADD
 	switch mat.blending {
 	case BlendingNone:
-		gs.Disable(gls.BLEND)
+		ps.Blend = false
 	case BlendingNormal:
-		gs.Enable(gls.BLEND)
-		gs.BlendEquationSeparate(gls.FUNC_ADD, gls.FUNC_ADD)
-		gs.BlendFunc(gls.SRC_ALPHA, gls.ONE_MINUS_SRC_ALPHA)
+		ps.SetBlendFunc(gls.SRC_ALPHA, gls.ONE_MINUS_SRC_ALPHA)
 	case BlendingAdditive:
-		gs.Enable(gls.BLEND)
-		gs.BlendEquation(gls.FUNC_ADD)
-		gs.BlendFunc(gls.SRC_ALPHA, gls.ONE)
+		ps.SetBlendFunc(gls.SRC_ALPHA, gls.ONE)
 	case BlendingSubtractive:
-		gs.Enable(gls.BLEND)
-		gs.BlendEquation(gls.FUNC_ADD)
-		gs.BlendFunc(gls.ZERO, gls.ONE_MINUS_SRC_COLOR)
-		break
+		ps.SetBlendFunc(gls.ZERO, gls.ONE_MINUS_SRC_COLOR)
 	case BlendingMultiply:
-		gs.Enable(gls.BLEND)
-		gs.BlendEquation(gls.FUNC_ADD)
-		gs.BlendFunc(gls.ZERO, gls.SRC_COLOR)
-		break
+		ps.SetBlendFunc(gls.ZERO, gls.SRC_COLOR)
 	case BlendingCustom:
-		gs.BlendEquationSeparate(mat.blendRGB, mat.blendAlpha)
-		gs.BlendFuncSeparate(mat.blendSrcRGB, mat.blendDstRGB, mat.blendSrcAlpha, mat.blendDstAlpha)
-		break
+		ps.BlendEquationRGB = mat.blendRGB
+		ps.BlendEquationAlpha = mat.blendAlpha
+		ps.BlendSrcRGB = mat.blendSrcRGB
+		ps.BlendDstRGB = mat.blendDstRGB
+		ps.BlendSrcAlpha = mat.blendSrcAlpha
+		ps.BlendDstAlpha = mat.blendDstAlpha
 	default:
 		panic("Invalid blending")
 	}
-
-	// Render textures
-	for idx, tex := range mat.textures {
-		tex.RenderSetup(gs, idx)
-	}
 }
 
 // AddTexture adds the specified Texture2d to the material
 func (mat *Material) AddTexture(tex *texture.Texture2D) {
 
 	mat.textures = append(mat.textures, tex)
+	mat.samplers = append(mat.samplers, nil)
 }
 
 // RemoveTexture removes the specified Texture2d from the material
@@ -283,6 +390,9 @@ func (mat *Material) RemoveTexture(tex *texture.Texture2D) {
 			copy(mat.textures[pos:], mat.textures[pos+1:])
 			mat.textures[len(mat.textures)-1] = nil
 			mat.textures = mat.textures[:len(mat.textures)-1]
+			copy(mat.samplers[pos:], mat.samplers[pos+1:])
+			mat.samplers[len(mat.samplers)-1] = nil
+			mat.samplers = mat.samplers[:len(mat.samplers)-1]
 			break
 		}
 	}
@@ -305,3 +415,88 @@ func (mat *Material) TextureCount() int {
 
 	return len(mat.textures)
 }
+
+// TextureAt returns the texture at the specified index
+func (mat *Material) TextureAt(idx int) *texture.Texture2D {
+
+	return mat.textures[idx]
+}
+
+// SetTextureSampler sets the sampling state of the texture at the specified
+// index, overriding the filters and wrap modes of the texture, so the same
+// texture may be sampled differently by different materials or texture slots.
+// A nil state samples the texture with its own parameters.
+func (mat *Material) SetTextureSampler(idx int, state *gls.SamplerState) {
+
+	mat.samplers[idx] = state
+}
+
+// TextureSampler returns the sampling state of the texture at the specified index or nil
+func (mat *Material) TextureSampler(idx int) *gls.SamplerState {
+
+	return mat.samplers[idx]
+}
+
+// AddTextureArray adds the specified texture array to the material.
+// The texture arrays are declared in the shaders as the MatTexArray samplers.
+func (mat *Material) AddTextureArray(tex *texture.TextureArray) {
+
+	mat.textureArrays = append(mat.textureArrays, tex)
+}
+
+// RemoveTextureArray removes the specified texture array from the material
+func (mat *Material) RemoveTextureArray(tex *texture.TextureArray) {
+
+	for pos, curr := range mat.textureArrays {
+		if curr == tex {
+			copy(mat.textureArrays[pos:], mat.textureArrays[pos+1:])
+			mat.textureArrays[len(mat.textureArrays)-1] = nil
+			mat.textureArrays = mat.textureArrays[:len(mat.textureArrays)-1]
+			break
+		}
+	}
+}
+
+// TextureArrayCount returns the current number of texture arrays
+func (mat *Material) TextureArrayCount() int {
+
+	return len(mat.textureArrays)
+}
+
+// TextureArrayAt returns the texture array at the specified index
+func (mat *Material) TextureArrayAt(idx int) *texture.TextureArray {
+
+	return mat.textureArrays[idx]
+}
+
+// AddTexture3D adds the specified 3D texture to the material.
+// The 3D textures are declared in the shaders as the MatTex3D samplers.
+func (mat *Material) AddTexture3D(tex *texture.Texture3D) {
+
+	mat.textures3D = append(mat.textures3D, tex)
+}
+
+// RemoveTexture3D removes the specified 3D texture from the material
+func (mat *Material) RemoveTexture3D(tex *texture.Texture3D) {
+
+	for pos, curr := range mat.textures3D {
+		if curr == tex {
+			copy(mat.textures3D[pos:], mat.textures3D[pos+1:])
+			mat.textures3D[len(mat.textures3D)-1] = nil
+			mat.textures3D = mat.textures3D[:len(mat.textures3D)-1]
+			break
+		}
+	}
+}
+
+// Texture3DCount returns the current number of 3D textures
+func (mat *Material) Texture3DCount() int {
+
+	return len(mat.textures3D)
+}
+
+// Texture3DAt returns the 3D texture at the specified index
+func (mat *Material) Texture3DAt(idx int) *texture.Texture3D {
+
+	return mat.textures3D[idx]
+}
@@ -11,9 +11,7 @@ import (
 
 type Point struct {
 	Material                // Embedded base material
-	emissive  gls.Uniform3f // point emissive uniform
 	size      gls.Uniform1f // point size uniform
-	opacity   gls.Uniform1f // point opacity uniform
 	rotationZ gls.Uniform1f // point z rotation
 }
 
@@ -24,18 +22,15 @@ func NewPoint(color *math32.Color) *Point {
 	pm.Material.Init()
 	pm.SetShader("shaderPoint")
 
-	// Creates color uniform
-	pm.emissive.Init("MatEmissiveColor")
-	pm.emissive.SetColor(color)
+	// Sets color and opacity of the material uniform block
+	pm.block.emissiveColor = *color
+	pm.block.opacity = 1.0
+	pm.blockUpdate = true
 
 	// Creates point size uniform
 	pm.size.Init("PointSize")
 	pm.size.Set(1.0)
 
-	// Creates point opacity uniform
-	pm.opacity.Init("MatOpacity")
-	pm.opacity.Set(1.0)
-
 	// Creates point rotation Z uniform
 	pm.rotationZ.Init("RotationZ")
 	pm.rotationZ.Set(0)
@@ -47,13 +42,14 @@ func NewPoint(color *math32.Color) *Point {
 // The default is {0,0,0}
 func (pm *Point) SetEmissiveColor(color *math32.Color) {
 
-	pm.emissive.SetColor(color)
+	pm.block.emissiveColor = *color
+	pm.blockUpdate = true
 }
 
 // EmissiveColor returns the material current emissive color
 func (pm *Point) EmissiveColor() math32.Color {
 
-	return pm.emissive.GetColor()
+	return pm.block.emissiveColor
 }
 
 func (pm *Point) SetSize(size float32) {
@@ -61,9 +57,22 @@ func (pm *Point) SetSize(size float32) {
 	pm.size.Set(size)
 }
 
+// Size returns the current point size
+func (pm *Point) Size() float32 {
+
+	return pm.size.Get()
+}
+
 func (pm *Point) SetOpacity(opacity float32) {
 
-	pm.opacity.Set(opacity)
+	pm.block.opacity = opacity
+	pm.blockUpdate = true
+}
+
+// Opacity returns the current opacity (alpha) of the points
+func (pm *Point) Opacity() float32 {
+
+	return pm.block.opacity
 }
 
 func (pm *Point) SetRotationZ(rot float32) {
@@ -71,12 +80,16 @@ func (pm *Point) SetRotationZ(rot float32) {
 	pm.rotationZ.Set(rot)
 }
 
-func (pm *Point) RenderSetup(gs *gls.GLS) {
+// RotationZ returns the current z rotation of the points
+func (pm *Point) RotationZ() float32 {
+
+	return pm.rotationZ.Get()
+}
+
+func (pm *Point) RenderSetup(gs gls.IDevice) {
 
 	pm.Material.RenderSetup(gs)
 
-	pm.emissive.Transfer(gs)
 	pm.size.Transfer(gs)
-	pm.opacity.Transfer(gs)
 	pm.rotationZ.Transfer(gs)
 }
@@ -0,0 +1,99 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// The batch operations below apply the same operation to slices of vectors and
+// matrices, as done every frame for skinning, particles and culling. They are
+// implemented with SIMD instructions on amd64 and arm64, unless built with the
+// "purego" tag, and in Go on the other architectures.
+
+// TransformPoints transforms the specified points by this affine matrix,
+// storing the results in dst, which must be at least as long as src
+// and may be src itself to transform the points in place.
+func (m *Matrix4) TransformPoints(dst, src []Vector3) {
+
+	if len(dst) < len(src) {
+		panic("math32: TransformPoints destination shorter than source")
+	}
+	if len(src) == 0 {
+		return
+	}
+	transformVectors(m, dst, src, 1)
+}
+
+// TransformDirections transforms the specified directions by this affine
+// matrix, ignoring its translation, storing the results in dst, which must be
+// at least as long as src and may be src itself to transform them in place.
+// The directions are not normalized.
+func (m *Matrix4) TransformDirections(dst, src []Vector3) {
+
+	if len(dst) < len(src) {
+		panic("math32: TransformDirections destination shorter than source")
+	}
+	if len(src) == 0 {
+		return
+	}
+	transformVectors(m, dst, src, 0)
+}
+
+// MultiplyMatrices sets each matrix of dst to the product of the matrices
+// with the same index in a and b, as the joint matrices of a skeleton from
+// their world and inverse bind matrices. The slice dst must be at least as
+// long as b, a must be as long as b and dst may be a or b itself.
+func MultiplyMatrices(dst, a, b []Matrix4) {
+
+	if len(a) != len(b) || len(dst) < len(b) {
+		panic("math32: MultiplyMatrices slices lengths mismatch")
+	}
+	if len(b) == 0 {
+		return
+	}
+	multiplyMatrices(dst, a, b, true)
+}
+
+// MultiplyMatrixSlice sets each matrix of dst to the product of the matrix a
+// and the matrix with the same index in b, as the model-view-projection matrices
+// of several objects. The slice dst must be at least as long as b and may be b itself.
+func MultiplyMatrixSlice(dst []Matrix4, a *Matrix4, b []Matrix4) {
+
+	if len(dst) < len(b) {
+		panic("math32: MultiplyMatrixSlice destination shorter than source")
+	}
+	if len(b) == 0 {
+		return
+	}
+	left := [1]Matrix4{*a}
+	multiplyMatrices(dst, left[:], b, false)
+}
+
+// transformVectorsGeneric stores in dst the vectors of src transformed by
+// the specified matrix with the specified homogeneous coordinate w
+func transformVectorsGeneric(m *Matrix4, dst, src []Vector3, w float32) {
+
+	tx := m[12] * w
+	ty := m[13] * w
+	tz := m[14] * w
+	for i := range src {
+		x := src[i].X
+		y := src[i].Y
+		z := src[i].Z
+		dst[i].X = m[0]*x + m[4]*y + m[8]*z + tx
+		dst[i].Y = m[1]*x + m[5]*y + m[9]*z + ty
+		dst[i].Z = m[2]*x + m[6]*y + m[10]*z + tz
+	}
+}
+
+// multiplyMatricesGeneric stores in dst the products of the matrices of a and b,
+// using only the first matrix of a if each is false
+func multiplyMatricesGeneric(dst, a, b []Matrix4, each bool) {
+
+	for i := range b {
+		ai := &a[0]
+		if each {
+			ai = &a[i]
+		}
+		dst[i].MultiplyMatrices(ai, &b[i])
+	}
+}
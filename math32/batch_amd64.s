@@ -0,0 +1,101 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// The matrices are stored by columns, so each column is loaded in a
+// register and multiplied by the broadcast vector component.
+
+// func transformVectorsAsm(m *Matrix4, dst, src *Vector3, n int, w float32)
+TEXT ·transformVectorsAsm(SB), NOSPLIT, $0-36
+	MOVQ   m+0(FP), AX
+	MOVQ   dst+8(FP), DI
+	MOVQ   src+16(FP), SI
+	MOVQ   n+24(FP), CX
+	MOVUPS 0(AX), X0
+	MOVUPS 16(AX), X1
+	MOVUPS 32(AX), X2
+	MOVUPS 48(AX), X3
+	MOVSS  w+32(FP), X4
+	SHUFPS $0x00, X4, X4
+	MULPS  X4, X3
+	TESTQ  CX, CX
+	JEQ    done
+
+loop:
+	MOVSS  0(SI), X4
+	MOVSS  4(SI), X5
+	MOVSS  8(SI), X6
+	SHUFPS $0x00, X4, X4
+	SHUFPS $0x00, X5, X5
+	SHUFPS $0x00, X6, X6
+	MULPS  X0, X4
+	MULPS  X1, X5
+	MULPS  X2, X6
+	ADDPS  X5, X4
+	ADDPS  X3, X6
+	ADDPS  X6, X4
+
+	// Stores the x and y components and then the z component
+	MOVLPS  X4, 0(DI)
+	MOVHLPS X4, X4
+	MOVSS   X4, 8(DI)
+	ADDQ    $12, SI
+	ADDQ    $12, DI
+	DECQ    CX
+	JNE     loop
+
+done:
+	RET
+
+// COLUMN stores at the specified offset of DI the column of the product of the
+// matrix whose columns are in X0-X3 and the column at the same offset of SI
+#define COLUMN(off) \
+	MOVSS  off(SI), X4; \
+	MOVSS  off+4(SI), X5; \
+	MOVSS  off+8(SI), X6; \
+	MOVSS  off+12(SI), X7; \
+	SHUFPS $0x00, X4, X4; \
+	SHUFPS $0x00, X5, X5; \
+	SHUFPS $0x00, X6, X6; \
+	SHUFPS $0x00, X7, X7; \
+	MULPS  X0, X4; \
+	MULPS  X1, X5; \
+	MULPS  X2, X6; \
+	MULPS  X3, X7; \
+	ADDPS  X5, X4; \
+	ADDPS  X7, X6; \
+	ADDPS  X6, X4; \
+	MOVUPS X4, off(DI)
+
+// func multiplyMatricesAsm(dst, a, b *Matrix4, n int, strideA int)
+TEXT ·multiplyMatricesAsm(SB), NOSPLIT, $0-40
+	MOVQ  dst+0(FP), DI
+	MOVQ  a+8(FP), AX
+	MOVQ  b+16(FP), SI
+	MOVQ  n+24(FP), CX
+	MOVQ  strideA+32(FP), DX
+	TESTQ CX, CX
+	JEQ   done
+
+loop:
+	MOVUPS 0(AX), X0
+	MOVUPS 16(AX), X1
+	MOVUPS 32(AX), X2
+	MOVUPS 48(AX), X3
+	COLUMN(0)
+	COLUMN(16)
+	COLUMN(32)
+	COLUMN(48)
+	ADDQ   DX, AX
+	ADDQ   $64, SI
+	ADDQ   $64, DI
+	DECQ   CX
+	JNE    loop
+
+done:
+	RET
@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// The matrices are stored by columns, so each column is loaded in a
+// register and multiplied by the broadcast vector component.
+
+// func transformVectorsAsm(m *Matrix4, dst, src *Vector3, n int, w float32)
+TEXT ·transformVectorsAsm(SB), NOSPLIT, $0-36
+	MOVD  m+0(FP), R0
+	MOVD  dst+8(FP), R1
+	MOVD  src+16(FP), R2
+	MOVD  n+24(FP), R3
+	VLD1  (R0), [V0.S4, V1.S4, V2.S4, V3.S4]
+	FMOVS w+32(FP), F4
+	VDUP  V4.S[0], V4.S4
+	VEOR  V9.B16, V9.B16, V9.B16
+	VFMLA V3.S4, V4.S4, V9.S4
+	CBZ   R3, done
+
+loop:
+	FMOVS 0(R2), F5
+	FMOVS 4(R2), F6
+	FMOVS 8(R2), F7
+	VDUP  V5.S[0], V5.S4
+	VDUP  V6.S[0], V6.S4
+	VDUP  V7.S[0], V7.S4
+	VORR  V9.B16, V9.B16, V8.B16
+	VFMLA V0.S4, V5.S4, V8.S4
+	VFMLA V1.S4, V6.S4, V8.S4
+	VFMLA V2.S4, V7.S4, V8.S4
+
+	// Stores the x component and then the y and z components
+	FMOVS F8, 0(R1)
+	VMOV  V8.S[1], R4
+	MOVW  R4, 4(R1)
+	VMOV  V8.S[2], R4
+	MOVW  R4, 8(R1)
+	ADD   $12, R1
+	ADD   $12, R2
+	SUB   $1, R3
+	CBNZ  R3, loop
+
+done:
+	RET
+
+// COLUMN stores in the register r the column of the product of the matrix
+// whose columns are in V0-V3 and the column in the register b
+#define COLUMN(b, r) \
+	VEOR  r.B16, r.B16, r.B16; \
+	VDUP  b.S[0], V16.S4; \
+	VDUP  b.S[1], V17.S4; \
+	VDUP  b.S[2], V18.S4; \
+	VDUP  b.S[3], V19.S4; \
+	VFMLA V0.S4, V16.S4, r.S4; \
+	VFMLA V1.S4, V17.S4, r.S4; \
+	VFMLA V2.S4, V18.S4, r.S4; \
+	VFMLA V3.S4, V19.S4, r.S4
+
+// func multiplyMatricesAsm(dst, a, b *Matrix4, n int, strideA int)
+TEXT ·multiplyMatricesAsm(SB), NOSPLIT, $0-40
+	MOVD dst+0(FP), R0
+	MOVD a+8(FP), R1
+	MOVD b+16(FP), R2
+	MOVD n+24(FP), R3
+	MOVD strideA+32(FP), R4
+	CBZ  R3, done
+
+loop:
+	VLD1 (R1), [V0.S4, V1.S4, V2.S4, V3.S4]
+	VLD1 (R2), [V4.S4, V5.S4, V6.S4, V7.S4]
+	COLUMN(V4, V20)
+	COLUMN(V5, V21)
+	COLUMN(V6, V22)
+	COLUMN(V7, V23)
+	VST1 [V20.S4, V21.S4, V22.S4, V23.S4], (R0)
+	ADD  R4, R1
+	ADD  $64, R2
+	ADD  $64, R0
+	SUB  $1, R3
+	CBNZ R3, loop
+
+done:
+	RET
@@ -0,0 +1,39 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (amd64 || arm64) && !purego
+// +build amd64 arm64
+// +build !purego
+
+package math32
+
+// transformVectorsAsm stores in dst the n vectors of src transformed by
+// the specified matrix with the specified homogeneous coordinate w
+//
+//go:noescape
+func transformVectorsAsm(m *Matrix4, dst, src *Vector3, n int, w float32)
+
+// multiplyMatricesAsm stores in dst the products of the n matrices of a and b,
+// advancing a by the specified number of bytes for each matrix
+//
+//go:noescape
+func multiplyMatricesAsm(dst, a, b *Matrix4, n int, strideA int)
+
+// transformVectors stores in dst the vectors of src transformed by
+// the specified matrix with the specified homogeneous coordinate w
+func transformVectors(m *Matrix4, dst, src []Vector3, w float32) {
+
+	transformVectorsAsm(m, &dst[0], &src[0], len(src), w)
+}
+
+// multiplyMatrices stores in dst the products of the matrices of a and b,
+// using only the first matrix of a if each is false
+func multiplyMatrices(dst, a, b []Matrix4, each bool) {
+
+	stride := 0
+	if each {
+		stride = 64
+	}
+	multiplyMatricesAsm(&dst[0], &a[0], &b[0], len(b), stride)
+}
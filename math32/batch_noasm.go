@@ -0,0 +1,22 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (!amd64 && !arm64) || purego
+// +build !amd64,!arm64 purego
+
+package math32
+
+// transformVectors stores in dst the vectors of src transformed by
+// the specified matrix with the specified homogeneous coordinate w
+func transformVectors(m *Matrix4, dst, src []Vector3, w float32) {
+
+	transformVectorsGeneric(m, dst, src, w)
+}
+
+// multiplyMatrices stores in dst the products of the matrices of a and b,
+// using only the first matrix of a if each is false
+func multiplyMatrices(dst, a, b []Matrix4, each bool) {
+
+	multiplyMatricesGeneric(dst, a, b, each)
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// The batch operations are checked against their Go implementations, so the
+// SIMD implementations are exercised on amd64 and arm64, as under qemu with
+// GOARCH=arm64. The results may differ by rounding as the SIMD instructions
+// fuse the multiplications and additions.
+
+// batchEpsilon is the relative tolerance of the batch results
+const batchEpsilon = 1e-5
+
+// batchLengths are the slice lengths checked, including the empty slice
+var batchLengths = []int{0, 1, 2, 3, 4, 5, 7, 16, 33}
+
+// TestTransformPoints checks TransformPoints against transformVectorsGeneric
+func TestTransformPoints(t *testing.T) {
+
+	checkTransformVectors(t, "TransformPoints", 1, (*Matrix4).TransformPoints)
+}
+
+// TestTransformDirections checks TransformDirections against transformVectorsGeneric
+func TestTransformDirections(t *testing.T) {
+
+	checkTransformVectors(t, "TransformDirections", 0, (*Matrix4).TransformDirections)
+}
+
+// TestMultiplyMatrices checks MultiplyMatrices against multiplyMatricesGeneric
+func TestMultiplyMatrices(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range batchLengths {
+		a := randomMatrices(rnd, n)
+		b := randomMatrices(rnd, n)
+		want := make([]Matrix4, n)
+		multiplyMatricesGeneric(want, a, b, true)
+
+		dst := make([]Matrix4, n+1)
+		MultiplyMatrices(dst, a, b)
+		checkMatrices(t, "MultiplyMatrices", n, dst[:n], want)
+		if dst[n] != (Matrix4{}) {
+			t.Errorf("MultiplyMatrices n=%d wrote past the source length", n)
+		}
+
+		// In place in a and in b
+		inA := append([]Matrix4(nil), a...)
+		MultiplyMatrices(inA, inA, b)
+		checkMatrices(t, "MultiplyMatrices in a", n, inA, want)
+		inB := append([]Matrix4(nil), b...)
+		MultiplyMatrices(inB, a, inB)
+		checkMatrices(t, "MultiplyMatrices in b", n, inB, want)
+	}
+}
+
+// TestMultiplyMatrixSlice checks MultiplyMatrixSlice against multiplyMatricesGeneric
+func TestMultiplyMatrixSlice(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(2))
+	for _, n := range batchLengths {
+		a := randomMatrices(rnd, 1)
+		b := randomMatrices(rnd, n)
+		want := make([]Matrix4, n)
+		multiplyMatricesGeneric(want, a, b, false)
+
+		dst := make([]Matrix4, n)
+		MultiplyMatrixSlice(dst, &a[0], b)
+		checkMatrices(t, "MultiplyMatrixSlice", n, dst, want)
+
+		// In place in b
+		MultiplyMatrixSlice(b, &a[0], b)
+		checkMatrices(t, "MultiplyMatrixSlice in place", n, b, want)
+	}
+}
+
+// checkTransformVectors checks the specified batch transform of vectors with
+// the specified homogeneous coordinate against transformVectorsGeneric
+func checkTransformVectors(t *testing.T, name string, w float32, transform func(*Matrix4, []Vector3, []Vector3)) {
+
+	rnd := rand.New(rand.NewSource(int64(w) + 3))
+	for _, n := range batchLengths {
+		m := randomMatrices(rnd, 1)[0]
+		src := make([]Vector3, n)
+		for i := range src {
+			src[i] = Vector3{randomFloat(rnd), randomFloat(rnd), randomFloat(rnd)}
+		}
+		want := make([]Vector3, n)
+		transformVectorsGeneric(&m, want, src, w)
+
+		dst := make([]Vector3, n+1)
+		transform(&m, dst, src)
+		checkVectors(t, name, n, dst[:n], want)
+		if dst[n] != (Vector3{}) {
+			t.Errorf("%s n=%d wrote past the source length", name, n)
+		}
+
+		// In place
+		transform(&m, src, src)
+		checkVectors(t, name+" in place", n, src, want)
+	}
+}
+
+// checkVectors reports the vectors of got which differ from want
+func checkVectors(t *testing.T, name string, n int, got, want []Vector3) {
+
+	for i := range want {
+		if !nearlyEqual(got[i].X, want[i].X) || !nearlyEqual(got[i].Y, want[i].Y) || !nearlyEqual(got[i].Z, want[i].Z) {
+			t.Errorf("%s n=%d [%d]: got %v want %v", name, n, i, got[i], want[i])
+		}
+	}
+}
+
+// checkMatrices reports the matrices of got which differ from want
+func checkMatrices(t *testing.T, name string, n int, got, want []Matrix4) {
+
+	for i := range want {
+		for j := range want[i] {
+			if !nearlyEqual(got[i][j], want[i][j]) {
+				t.Errorf("%s n=%d [%d]: got %v want %v", name, n, i, got[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+// nearlyEqual returns if the specified values are equal within batchEpsilon
+func nearlyEqual(a, b float32) bool {
+
+	return Abs(a-b) <= batchEpsilon*Max(1, Max(Abs(a), Abs(b)))
+}
+
+// randomMatrices returns the specified number of matrices with random elements
+func randomMatrices(rnd *rand.Rand, n int) []Matrix4 {
+
+	ms := make([]Matrix4, n)
+	for i := range ms {
+		for j := range ms[i] {
+			ms[i][j] = randomFloat(rnd)
+		}
+	}
+	return ms
+}
+
+// randomFloat returns a random value from -10 to 10
+func randomFloat(rnd *rand.Rand) float32 {
+
+	return rnd.Float32()*20 - 10
+}
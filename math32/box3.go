@@ -98,7 +98,7 @@ func (this *Box3) Size(optionalTarget *Vector3) *Vector3 {
 	} else {
 		result = optionalTarget
 	}
-	return result.SubVectors(&this.Min, &this.Max)
+	return result.SubVectors(&this.Max, &this.Min)
 }
 
 func (this *Box3) ExpandByPoint(point *Vector3) *Box3 {
@@ -134,7 +134,7 @@ func (this *Box3) ContainsPoint(point *Vector3) bool {
 
 func (this *Box3) ContainsBox(box *Box3) bool {
 
-	if (this.Min.X <= box.Max.X) && (box.Max.X <= this.Max.X) &&
+	if (this.Min.X <= box.Min.X) && (box.Max.X <= this.Max.X) &&
 		(this.Min.Y <= box.Min.Y) && (box.Max.Y <= this.Max.Y) &&
 		(this.Min.Z <= box.Min.Z) && (box.Max.Z <= this.Max.Z) {
 		return true
@@ -0,0 +1,264 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// SRGBToLinear converts the specified sRGB encoded color component
+// to the linear color space, where the lighting is computed
+func SRGBToLinear(v float32) float32 {
+
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToSRGB converts the specified linear color component
+// to the sRGB encoding, as displayed by monitors
+func LinearToSRGB(v float32) float32 {
+
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*Pow(v, 1/2.4) - 0.055
+}
+
+// ConvertSRGBToLinear converts this color from the sRGB encoding to the linear color space.
+// Returns pointer to this updated color.
+func (c *Color) ConvertSRGBToLinear() *Color {
+
+	c.R = SRGBToLinear(c.R)
+	c.G = SRGBToLinear(c.G)
+	c.B = SRGBToLinear(c.B)
+	return c
+}
+
+// ConvertLinearToSRGB converts this color from the linear color space to the sRGB encoding.
+// Returns pointer to this updated color.
+func (c *Color) ConvertLinearToSRGB() *Color {
+
+	c.R = LinearToSRGB(c.R)
+	c.G = LinearToSRGB(c.G)
+	c.B = LinearToSRGB(c.B)
+	return c
+}
+
+// ConvertSRGBToLinear converts the RGB components of this color from the
+// sRGB encoding to the linear color space, keeping its alpha.
+// Returns pointer to this updated color.
+func (c *Color4) ConvertSRGBToLinear() *Color4 {
+
+	c.R = SRGBToLinear(c.R)
+	c.G = SRGBToLinear(c.G)
+	c.B = SRGBToLinear(c.B)
+	return c
+}
+
+// ConvertLinearToSRGB converts the RGB components of this color from the
+// linear color space to the sRGB encoding, keeping its alpha.
+// Returns pointer to this updated color.
+func (c *Color4) ConvertLinearToSRGB() *Color4 {
+
+	c.R = LinearToSRGB(c.R)
+	c.G = LinearToSRGB(c.G)
+	c.B = LinearToSRGB(c.B)
+	return c
+}
+
+// SetHSL sets this color from the specified hue, saturation and lightness,
+// all from 0 to 1, where the hue 0 is red, 1/3 green and 2/3 blue.
+// Returns pointer to this updated color.
+func (c *Color) SetHSL(h, s, l float32) *Color {
+
+	h = h - Floor(h)
+	s = Clamp(s, 0, 1)
+	l = Clamp(l, 0, 1)
+	if s == 0 {
+		return c.Set(l, l, l)
+	}
+	var p float32
+	if l <= 0.5 {
+		p = l * (1 + s)
+	} else {
+		p = l + s - l*s
+	}
+	q := 2*l - p
+	c.R = hueToRGB(q, p, h+1.0/3)
+	c.G = hueToRGB(q, p, h)
+	c.B = hueToRGB(q, p, h-1.0/3)
+	return c
+}
+
+// HSL returns the hue, saturation and lightness of this color, all from 0 to 1
+func (c *Color) HSL() (h, s, l float32) {
+
+	max := Max(c.R, Max(c.G, c.B))
+	min := Min(c.R, Min(c.G, c.B))
+	l = (min + max) / 2
+	if max == min {
+		return 0, 0, l
+	}
+	delta := max - min
+	if l <= 0.5 {
+		s = delta / (max + min)
+	} else {
+		s = delta / (2 - max - min)
+	}
+	return c.hue(max, delta), s, l
+}
+
+// SetHSV sets this color from the specified hue, saturation and value,
+// all from 0 to 1, where the hue 0 is red, 1/3 green and 2/3 blue.
+// Returns pointer to this updated color.
+func (c *Color) SetHSV(h, s, v float32) *Color {
+
+	h = (h - Floor(h)) * 6
+	s = Clamp(s, 0, 1)
+	v = Clamp(v, 0, 1)
+	sector := int(h) % 6
+	f := h - Floor(h)
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+	switch sector {
+	case 0:
+		return c.Set(v, t, p)
+	case 1:
+		return c.Set(q, v, p)
+	case 2:
+		return c.Set(p, v, t)
+	case 3:
+		return c.Set(p, q, v)
+	case 4:
+		return c.Set(t, p, v)
+	default:
+		return c.Set(v, p, q)
+	}
+}
+
+// HSV returns the hue, saturation and value of this color, all from 0 to 1
+func (c *Color) HSV() (h, s, v float32) {
+
+	max := Max(c.R, Max(c.G, c.B))
+	min := Min(c.R, Min(c.G, c.B))
+	if max == min {
+		return 0, 0, max
+	}
+	delta := max - min
+	return c.hue(max, delta), delta / max, max
+}
+
+// hue returns the hue of this color from 0 to 1
+// for its specified maximum component and range
+func (c *Color) hue(max, delta float32) float32 {
+
+	var h float32
+	switch max {
+	case c.R:
+		h = (c.G - c.B) / delta
+		if h < 0 {
+			h += 6
+		}
+	case c.G:
+		h = (c.B-c.R)/delta + 2
+	default:
+		h = (c.R-c.G)/delta + 4
+	}
+	return h / 6
+}
+
+// hueToRGB returns the RGB component for the specified hue offset
+// between the specified minimum and maximum component values
+func hueToRGB(p, q, t float32) float32 {
+
+	if t < 0 {
+		t++
+	}
+	if t > 1 {
+		t--
+	}
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 0.5:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*6*(2.0/3-t)
+	}
+	return p
+}
+
+// SetKelvin sets this color to the sRGB encoded color of the light emitted by
+// a black body at the specified temperature in Kelvin, from 1000K (red) through
+// 6600K (white) to 40000K (blue), using an approximation of the Planckian locus.
+// Convert it to the linear color space for the lights.
+// Returns pointer to this updated color.
+func (c *Color) SetKelvin(kelvin float32) *Color {
+
+	t := Clamp(kelvin, 1000, 40000) / 100
+	var r, g, b float32
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * Pow(t-60, -0.0755148492)
+	}
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*Log(t-10) - 305.0447927307
+	}
+	return c.Set(Clamp(r, 0, 255)/255, Clamp(g, 0, 255)/255, Clamp(b, 0, 255)/255)
+}
+
+// Luminance returns the relative luminance of this color in
+// the linear color space, with the Rec. 709 primaries of sRGB
+func (c *Color) Luminance() float32 {
+
+	return 0.2126*c.R + 0.7152*c.G + 0.0722*c.B
+}
+
+// LerpPerceptual sets this color to the interpolation from this color to the
+// specified color at alpha in the Oklab color space, whose steps are perceived
+// as uniform and keep the intermediate colors from becoming dull.
+// Both colors must be in the linear color space.
+// Returns pointer to this updated color.
+func (c *Color) LerpPerceptual(color *Color, alpha float32) *Color {
+
+	l1, a1, b1 := c.oklab()
+	l2, a2, b2 := color.oklab()
+	return c.setOklab(l1+(l2-l1)*alpha, a1+(a2-a1)*alpha, b1+(b2-b1)*alpha)
+}
+
+// oklab returns the Oklab coordinates of this linear color
+func (c *Color) oklab() (L, a, b float32) {
+
+	l := Cbrt(0.4122214708*c.R + 0.5363325363*c.G + 0.0514459929*c.B)
+	m := Cbrt(0.2119034982*c.R + 0.6806995451*c.G + 0.1073969566*c.B)
+	s := Cbrt(0.0883024619*c.R + 0.2817188376*c.G + 0.6299787005*c.B)
+	L = 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	a = 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	b = 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+	return L, a, b
+}
+
+// setOklab sets this color to the linear color with the specified Oklab coordinates.
+// Returns pointer to this updated color.
+func (c *Color) setOklab(L, a, b float32) *Color {
+
+	l := L + 0.3963377774*a + 0.2158037573*b
+	m := L - 0.1055613458*a - 0.0638541728*b
+	s := L - 0.0894841775*a - 1.2914855480*b
+	l = l * l * l
+	m = m * m * m
+	s = s * s * s
+	c.R = 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	c.G = -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	c.B = -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+	return c
+}
@@ -0,0 +1,112 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// ArcLength is the arc length parameterization of a curve, which maps
+// distances along the curve to the curve parameters, to move along the
+// curve at constant speed or to place objects at regular distances.
+// It approximates the curve by a polyline with the specified number of
+// segments, so it must be recreated when the curve changes.
+type ArcLength struct {
+	curve   Curve
+	lengths []float32 // lengths of the curve at uniformly distributed parameters
+}
+
+// NewArcLength creates and returns a pointer to a new arc length
+// parameterization of the specified curve, sampled with the specified
+// number of segments, or 200 if not positive
+func NewArcLength(c Curve, segments int) *ArcLength {
+
+	if segments <= 0 {
+		segments = 200
+	}
+	al := new(ArcLength)
+	al.curve = c
+	al.lengths = make([]float32, segments+1)
+	prev := c.Position(0)
+	for i := 1; i <= segments; i++ {
+		p := c.Position(float32(i) / float32(segments))
+		al.lengths[i] = al.lengths[i-1] + p.DistanceTo(&prev)
+		prev = p
+	}
+	return al
+}
+
+// Curve returns the curve of this parameterization
+func (al *ArcLength) Curve() Curve {
+
+	return al.curve
+}
+
+// Length returns the total length of the curve
+func (al *ArcLength) Length() float32 {
+
+	return al.lengths[len(al.lengths)-1]
+}
+
+// LengthAt returns the length of the curve from its start
+// to the point at the specified parameter
+func (al *ArcLength) LengthAt(t float32) float32 {
+
+	segs := len(al.lengths) - 1
+	i, u := segmentParam(t, segs)
+	return al.lengths[i] + (al.lengths[i+1]-al.lengths[i])*u
+}
+
+// Param returns the parameter of the point of the curve
+// at the specified distance from its start
+func (al *ArcLength) Param(dist float32) float32 {
+
+	segs := len(al.lengths) - 1
+	dist = math32.Clamp(dist, 0, al.Length())
+	// Index of the first sample farther than the distance
+	i := sort.Search(len(al.lengths), func(i int) bool { return al.lengths[i] > dist })
+	if i == 0 {
+		return 0
+	}
+	if i > segs {
+		return 1
+	}
+	span := al.lengths[i] - al.lengths[i-1]
+	u := float32(0)
+	if span > 0 {
+		u = (dist - al.lengths[i-1]) / span
+	}
+	return (float32(i-1) + u) / float32(segs)
+}
+
+// Position returns the position of the curve
+// at the specified distance from its start
+func (al *ArcLength) Position(dist float32) math32.Vector3 {
+
+	return al.curve.Position(al.Param(dist))
+}
+
+// Tangent returns the normalized tangent of the curve
+// at the specified distance from its start
+func (al *ArcLength) Tangent(dist float32) math32.Vector3 {
+
+	return Tangent(al.curve, al.Param(dist))
+}
+
+// Points returns the specified number of points of the
+// curve at equal distances along it, including its ends
+func (al *ArcLength) Points(count int) []math32.Vector3 {
+
+	if count < 2 {
+		count = 2
+	}
+	points := make([]math32.Vector3, count)
+	length := al.Length()
+	for i := range points {
+		points[i] = al.Position(length * float32(i) / float32(count-1))
+	}
+	return points
+}
@@ -0,0 +1,112 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Bezier is a Bezier curve of any degree defined by its control points.
+// It passes through the first and last points.
+type Bezier struct {
+	points []math32.Vector3 // control points
+	deriv  []math32.Vector3 // control points of the derivative
+}
+
+// NewBezier creates and returns a pointer to a new Bezier curve with the
+// specified control points, whose number is the degree of the curve plus one
+func NewBezier(points ...math32.Vector3) *Bezier {
+
+	b := new(Bezier)
+	b.SetPoints(points)
+	return b
+}
+
+// NewCubicBezier creates and returns a pointer to a new cubic Bezier curve
+// from p0 to p3 with the control points p1 and p2
+func NewCubicBezier(p0, p1, p2, p3 *math32.Vector3) *Bezier {
+
+	return NewBezier(*p0, *p1, *p2, *p3)
+}
+
+// SetPoints sets the control points of this curve
+func (b *Bezier) SetPoints(points []math32.Vector3) {
+
+	b.points = append(b.points[:0], points...)
+	b.deriv = b.deriv[:0]
+	n := float32(len(points) - 1)
+	for i := 1; i < len(points); i++ {
+		var d math32.Vector3
+		d.SubVectors(&points[i], &points[i-1]).MultiplyScalar(n)
+		b.deriv = append(b.deriv, d)
+	}
+}
+
+// Points returns the control points of this curve
+func (b *Bezier) Points() []math32.Vector3 {
+
+	return b.points
+}
+
+// Position returns the position of this curve at the specified parameter
+func (b *Bezier) Position(t float32) math32.Vector3 {
+
+	return casteljau(b.points, t)
+}
+
+// Derivative returns the derivative of the position
+// of this curve at the specified parameter
+func (b *Bezier) Derivative(t float32) math32.Vector3 {
+
+	return casteljau(b.deriv, t)
+}
+
+// Split returns the two Bezier curves which are the parts of this curve
+// before and after the specified parameter
+func (b *Bezier) Split(t float32) (*Bezier, *Bezier) {
+
+	n := len(b.points)
+	if n == 0 {
+		return NewBezier(), NewBezier()
+	}
+	work := append([]math32.Vector3(nil), b.points...)
+	first := make([]math32.Vector3, n)
+	second := make([]math32.Vector3, n)
+	for r := 0; r < n; r++ {
+		first[r] = work[0]
+		second[n-1-r] = work[n-1-r]
+		for i := 0; i < n-1-r; i++ {
+			work[i].Lerp(&work[i+1], t)
+		}
+	}
+	return NewBezier(first...), NewBezier(second...)
+}
+
+// casteljau returns the position at the specified parameter of the
+// Bezier curve with the specified control points
+func casteljau(points []math32.Vector3, t float32) math32.Vector3 {
+
+	n := len(points)
+	switch n {
+	case 0:
+		return math32.Vector3{}
+	case 1:
+		return points[0]
+	}
+	var buf [8]math32.Vector3
+	var work []math32.Vector3
+	if n <= len(buf) {
+		work = buf[:n]
+	} else {
+		work = make([]math32.Vector3, n)
+	}
+	copy(work, points)
+	for r := n - 1; r > 0; r-- {
+		for i := 0; i < r; i++ {
+			work[i].Lerp(&work[i+1], t)
+		}
+	}
+	return work[0]
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// BSpline is a B-spline curve defined by its degree, control points and
+// knots. The curve is smooth and stays near its control points, without
+// passing through them except at the ends of the clamped knots.
+type BSpline struct {
+	degree int
+	points []math32.Vector3 // control points
+	knots  []float32        // knot vector with len(points)+degree+1 knots
+	deriv  *BSpline         // derivative curve or nil if not computed
+}
+
+// NewBSpline creates and returns a pointer to a new B-spline with the
+// specified degree and control points and uniform knots clamped at the ends,
+// so the curve starts at the first point and ends at the last point.
+// The degree is reduced to the number of points less one if needed.
+func NewBSpline(degree int, points []math32.Vector3) *BSpline {
+
+	if degree > len(points)-1 {
+		degree = len(points) - 1
+	}
+	if degree < 0 {
+		degree = 0
+	}
+	n := len(points)
+	knots := make([]float32, n+degree+1)
+	inner := n - degree
+	for i := range knots {
+		switch {
+		case i <= degree:
+			knots[i] = 0
+		case i >= n:
+			knots[i] = 1
+		default:
+			knots[i] = float32(i-degree) / float32(inner)
+		}
+	}
+	return NewBSplineKnots(degree, points, knots)
+}
+
+// NewBSplineKnots creates and returns a pointer to a new B-spline with
+// the specified degree, control points and non decreasing knots, whose
+// number must be the number of points plus the degree plus one
+func NewBSplineKnots(degree int, points []math32.Vector3, knots []float32) *BSpline {
+
+	if len(knots) != len(points)+degree+1 {
+		panic("invalid number of B-spline knots")
+	}
+	b := new(BSpline)
+	b.degree = degree
+	b.points = append([]math32.Vector3(nil), points...)
+	b.knots = append([]float32(nil), knots...)
+	return b
+}
+
+// Degree returns the degree of this curve
+func (b *BSpline) Degree() int {
+
+	return b.degree
+}
+
+// Points returns the control points of this curve
+func (b *BSpline) Points() []math32.Vector3 {
+
+	return b.points
+}
+
+// Knots returns the knot vector of this curve
+func (b *BSpline) Knots() []float32 {
+
+	return b.knots
+}
+
+// Position returns the position of this curve at the specified parameter,
+// which is mapped to the domain of the knots of the curve
+func (b *BSpline) Position(t float32) math32.Vector3 {
+
+	if len(b.points) == 0 {
+		return math32.Vector3{}
+	}
+	return b.deBoor(b.knot(t))
+}
+
+// Derivative returns the derivative of the position
+// of this curve at the specified parameter
+func (b *BSpline) Derivative(t float32) math32.Vector3 {
+
+	if b.degree == 0 || len(b.points) < 2 {
+		return math32.Vector3{}
+	}
+	if b.deriv == nil {
+		b.deriv = b.derivative()
+	}
+	lo, hi := b.domain()
+	d := b.deriv.deBoor(b.knot(t))
+	d.MultiplyScalar(hi - lo)
+	return d
+}
+
+// domain returns the first and last knots of the domain of this curve
+func (b *BSpline) domain() (float32, float32) {
+
+	return b.knots[b.degree], b.knots[len(b.points)]
+}
+
+// knot returns the knot of the domain of this curve
+// which corresponds to the specified parameter
+func (b *BSpline) knot(t float32) float32 {
+
+	lo, hi := b.domain()
+	return lo + math32.Clamp(t, 0, 1)*(hi-lo)
+}
+
+// deBoor returns the position of this curve at the specified knot
+func (b *BSpline) deBoor(x float32) math32.Vector3 {
+
+	p := b.degree
+	// Finds the span of the knot, using the last span for the last knot
+	k := p
+	for k < len(b.points)-1 && x >= b.knots[k+1] {
+		k++
+	}
+	d := make([]math32.Vector3, p+1)
+	copy(d, b.points[k-p:k+1])
+	for r := 1; r <= p; r++ {
+		for j := p; j >= r; j-- {
+			lo := b.knots[j+k-p]
+			hi := b.knots[j+1+k-r]
+			alpha := float32(0)
+			if hi > lo {
+				alpha = (x - lo) / (hi - lo)
+			}
+			prev := d[j-1]
+			d[j] = *prev.Lerp(&d[j], alpha)
+		}
+	}
+	return d[p]
+}
+
+// derivative returns the B-spline which is the derivative of this curve
+// with respect to its knots
+func (b *BSpline) derivative() *BSpline {
+
+	p := b.degree
+	points := make([]math32.Vector3, len(b.points)-1)
+	for i := range points {
+		dk := b.knots[i+p+1] - b.knots[i+1]
+		if dk > 0 {
+			points[i].SubVectors(&b.points[i+1], &b.points[i]).MultiplyScalar(float32(p) / dk)
+		}
+	}
+	return NewBSplineKnots(p-1, points, b.knots[1:len(b.knots)-1])
+}
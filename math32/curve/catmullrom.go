@@ -0,0 +1,169 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Parameterizations of the Catmull-Rom splines, which are the exponents
+// of the distances between the points used as the intervals of the knots
+const (
+	Uniform     = 0   // may form cusps and loops between close points
+	Centripetal = 0.5 // no cusps nor loops (default)
+	Chordal     = 1   // smoother turns far from the points
+)
+
+// CatmullRom is a Catmull-Rom spline, which passes through all its points.
+// Each segment between two points is a cubic curve, whose tangents depend on
+// the previous and next points. The parameter is divided equally between the
+// segments.
+type CatmullRom struct {
+	points []math32.Vector3
+	closed bool    // the last point is joined to the first
+	alpha  float32 // exponent of the parameterization
+}
+
+// NewCatmullRom creates and returns a pointer to a new centripetal
+// Catmull-Rom spline through the specified points, which is closed
+// if requested
+func NewCatmullRom(points []math32.Vector3, closed bool) *CatmullRom {
+
+	c := new(CatmullRom)
+	c.points = append([]math32.Vector3(nil), points...)
+	c.closed = closed
+	c.alpha = Centripetal
+	return c
+}
+
+// SetAlpha sets the parameterization of this spline:
+// Uniform, Centripetal, Chordal or any value between 0 and 1
+func (c *CatmullRom) SetAlpha(alpha float32) *CatmullRom {
+
+	c.alpha = alpha
+	return c
+}
+
+// Points returns the points of this spline
+func (c *CatmullRom) Points() []math32.Vector3 {
+
+	return c.points
+}
+
+// Closed returns if this spline is closed
+func (c *CatmullRom) Closed() bool {
+
+	return c.closed
+}
+
+// Position returns the position of this spline at the specified parameter
+func (c *CatmullRom) Position(t float32) math32.Vector3 {
+
+	if len(c.points) < 2 {
+		return c.single()
+	}
+	i, u := segmentParam(t, c.segments())
+	c0, c1, c2, c3 := c.coefficients(i)
+	// c0 + c1*u + c2*u^2 + c3*u^3
+	c3.MultiplyScalar(u).Add(&c2).MultiplyScalar(u).Add(&c1).MultiplyScalar(u).Add(&c0)
+	return c3
+}
+
+// Derivative returns the derivative of the position
+// of this spline at the specified parameter
+func (c *CatmullRom) Derivative(t float32) math32.Vector3 {
+
+	if len(c.points) < 2 {
+		return math32.Vector3{}
+	}
+	segs := c.segments()
+	i, u := segmentParam(t, segs)
+	_, c1, c2, c3 := c.coefficients(i)
+	// (c1 + 2*c2*u + 3*c3*u^2) * segments
+	c3.MultiplyScalar(3 * u)
+	c2.MultiplyScalar(2)
+	c3.Add(&c2).MultiplyScalar(u).Add(&c1).MultiplyScalar(float32(segs))
+	return c3
+}
+
+// single returns the position of the spline with less than two points
+func (c *CatmullRom) single() math32.Vector3 {
+
+	if len(c.points) == 0 {
+		return math32.Vector3{}
+	}
+	return c.points[0]
+}
+
+// segments returns the number of segments of this spline
+func (c *CatmullRom) segments() int {
+
+	if c.closed {
+		return len(c.points)
+	}
+	return len(c.points) - 1
+}
+
+// point returns the point of this spline with the specified index,
+// which may be outside of the points: the closed splines wrap around
+// and the open splines extrapolate their first and last segments
+func (c *CatmullRom) point(i int) math32.Vector3 {
+
+	n := len(c.points)
+	if c.closed {
+		return c.points[((i%n)+n)%n]
+	}
+	var p math32.Vector3
+	switch {
+	case i < 0:
+		p.SubVectors(&c.points[0], &c.points[1]).Add(&c.points[0])
+	case i >= n:
+		p.SubVectors(&c.points[n-1], &c.points[n-2]).Add(&c.points[n-1])
+	default:
+		p = c.points[i]
+	}
+	return p
+}
+
+// coefficients returns the coefficients of the cubic polynomial
+// of the segment of this spline with the specified index
+func (c *CatmullRom) coefficients(i int) (c0, c1, c2, c3 math32.Vector3) {
+
+	p0, p1, p2, p3 := c.point(i-1), c.point(i), c.point(i+1), c.point(i+2)
+
+	// Intervals of the knots, avoiding zero intervals between repeated points
+	dt0 := math32.Pow(p0.DistanceTo(&p1), c.alpha)
+	dt1 := math32.Pow(p1.DistanceTo(&p2), c.alpha)
+	dt2 := math32.Pow(p2.DistanceTo(&p3), c.alpha)
+	if dt1 < 1e-4 {
+		dt1 = 1
+	}
+	if dt0 < 1e-4 {
+		dt0 = dt1
+	}
+	if dt2 < 1e-4 {
+		dt2 = dt1
+	}
+
+	// Tangents at p1 and p2 of the non uniform spline scaled to the segment
+	tangent := func(a, b, c *math32.Vector3, d0, d1 float32) math32.Vector3 {
+		var t, v math32.Vector3
+		t.SubVectors(b, a).DivideScalar(d0)
+		v.SubVectors(c, a).DivideScalar(d0 + d1)
+		t.Sub(&v)
+		v.SubVectors(c, b).DivideScalar(d1)
+		return *t.Add(&v).MultiplyScalar(dt1)
+	}
+	m1 := tangent(&p0, &p1, &p2, dt0, dt1)
+	m2 := tangent(&p1, &p2, &p3, dt1, dt2)
+
+	// Hermite curve from p1 to p2 with the tangents m1 and m2
+	var v math32.Vector3
+	c0 = p1
+	c1 = m1
+	c2.SubVectors(&p2, &p1).MultiplyScalar(3).Sub(v.Copy(&m1).MultiplyScalar(2)).Sub(&m2)
+	c3.SubVectors(&p1, &p2).MultiplyScalar(2).Add(&m1).Add(&m2)
+	return
+}
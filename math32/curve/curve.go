@@ -0,0 +1,59 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package curve implements parametric curves, as Bezier curves,
+// Catmull-Rom splines and B-splines, their arc length parameterization
+// and their adaptive tessellation into polylines, used for camera paths,
+// the meshes of roads and rivers and animation easing.
+//
+// The curves are evaluated for the parameter t from 0 at their start to 1
+// at their end. The parameter is not proportional to the distance along the
+// curve, which is obtained with the arc length parameterization.
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Curve is the interface of the parametric curves
+type Curve interface {
+	Position(t float32) math32.Vector3   // position of the curve at t
+	Derivative(t float32) math32.Vector3 // derivative of the position at t
+}
+
+// Tangent returns the normalized tangent of the specified curve
+// at the specified parameter
+func Tangent(c Curve, t float32) math32.Vector3 {
+
+	d := c.Derivative(t)
+	d.Normalize()
+	return d
+}
+
+// Points returns the positions of the specified curve at the specified
+// number of parameters uniformly distributed from 0 to 1
+func Points(c Curve, count int) []math32.Vector3 {
+
+	if count < 2 {
+		count = 2
+	}
+	points := make([]math32.Vector3, count)
+	for i := range points {
+		points[i] = c.Position(float32(i) / float32(count-1))
+	}
+	return points
+}
+
+// segmentParam returns the index of the segment of a curve composed of the
+// specified number of segments and the local parameter in this segment
+// which correspond to the specified parameter of the curve
+func segmentParam(t float32, segments int) (int, float32) {
+
+	t = math32.Clamp(t, 0, 1) * float32(segments)
+	i := int(t)
+	if i >= segments {
+		i = segments - 1
+	}
+	return i, t - float32(i)
+}
@@ -0,0 +1,98 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Easing is a timing function defined by a cubic Bezier curve from (0,0)
+// to (1,1), as the CSS cubic-bezier timing functions, which maps the
+// fraction of the time of an animation to the fraction of its progress
+type Easing struct {
+	x1, y1 float32 // first control point
+	x2, y2 float32 // second control point
+}
+
+// Standard easings
+var (
+	EaseLinear    = NewEasing(0, 0, 1, 1)
+	Ease          = NewEasing(0.25, 0.1, 0.25, 1)
+	EaseIn        = NewEasing(0.42, 0, 1, 1)
+	EaseOut       = NewEasing(0, 0, 0.58, 1)
+	EaseInOut     = NewEasing(0.42, 0, 0.58, 1)
+	EaseInBack    = NewEasing(0.36, 0, 0.66, -0.56)
+	EaseOutBack   = NewEasing(0.34, 1.56, 0.64, 1)
+	EaseInOutBack = NewEasing(0.68, -0.6, 0.32, 1.6)
+)
+
+// NewEasing creates and returns a pointer to a new easing with the
+// specified control points, whose x coordinates are clamped between 0 and 1
+// so the time fraction corresponds to a single progress
+func NewEasing(x1, y1, x2, y2 float32) *Easing {
+
+	return &Easing{math32.Clamp(x1, 0, 1), y1, math32.Clamp(x2, 0, 1), y2}
+}
+
+// Value returns the fraction of the progress at the specified fraction of the time
+func (e *Easing) Value(x float32) float32 {
+
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	return bezier1(e.y1, e.y2, e.param(x))
+}
+
+// param returns the parameter of the curve whose x coordinate is the
+// specified value, using Newton's method and then bisection if it fails
+func (e *Easing) param(x float32) float32 {
+
+	t := x
+	for i := 0; i < 8; i++ {
+		err := bezier1(e.x1, e.x2, t) - x
+		if math32.Abs(err) < 1e-6 {
+			return t
+		}
+		d := bezier1Deriv(e.x1, e.x2, t)
+		if math32.Abs(d) < 1e-6 {
+			break
+		}
+		t -= err / d
+	}
+	lo, hi := float32(0), float32(1)
+	t = x
+	for i := 0; i < 32; i++ {
+		v := bezier1(e.x1, e.x2, t)
+		if math32.Abs(v-x) < 1e-6 {
+			break
+		}
+		if v < x {
+			lo = t
+		} else {
+			hi = t
+		}
+		t = (lo + hi) / 2
+	}
+	return t
+}
+
+// bezier1 returns the value at t of the one dimensional
+// cubic Bezier curve with control values 0, a, b and 1
+func bezier1(a, b, t float32) float32 {
+
+	u := 1 - t
+	return 3*u*u*t*a + 3*u*t*t*b + t*t*t
+}
+
+// bezier1Deriv returns the derivative at t of the one
+// dimensional cubic Bezier curve with control values 0, a, b and 1
+func bezier1Deriv(a, b, t float32) float32 {
+
+	u := 1 - t
+	return 3*u*u*a + 6*u*t*(b-a) + 3*t*t*(1-b)
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curve
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Tessellation limits
+const (
+	tessInitial  = 8  // initial number of segments, to find small features
+	tessMaxDepth = 12 // maximum number of subdivisions of the initial segments
+)
+
+// Tessellate returns the points of a polyline which approximates the
+// specified curve, whose distance from the curve is less than the specified
+// tolerance. The segments are subdivided only where the curve bends, so the
+// straight parts of the curve use few points.
+func Tessellate(c Curve, tolerance float32) []math32.Vector3 {
+
+	return TessellateParams(c, tolerance, nil)
+}
+
+// TessellateParams is like Tessellate but also appends to the specified
+// slice, if not nil, the curve parameters of the returned points, as
+// needed to compute their tangents or texture coordinates
+func TessellateParams(c Curve, tolerance float32, params *[]float32) []math32.Vector3 {
+
+	if tolerance <= 0 {
+		tolerance = 1e-3
+	}
+	p0 := c.Position(0)
+	points := []math32.Vector3{p0}
+	if params != nil {
+		*params = append(*params, 0)
+	}
+	for i := 0; i < tessInitial; i++ {
+		t0 := float32(i) / tessInitial
+		t1 := float32(i+1) / tessInitial
+		p1 := c.Position(t1)
+		points = subdivide(c, t0, t1, &p0, &p1, tolerance*tolerance, tessMaxDepth, points, params)
+		p0 = p1
+	}
+	return points
+}
+
+// subdivide appends to the specified points the points of the polyline which
+// approximates the curve from parameter t0 at p0 (not appended) to parameter
+// t1 at p1 (appended), subdividing it while its middle is farther from the
+// chord than the tolerance, and returns the points
+func subdivide(c Curve, t0, t1 float32, p0, p1 *math32.Vector3, tolSq float32, depth int, points []math32.Vector3, params *[]float32) []math32.Vector3 {
+
+	tm := (t0 + t1) / 2
+	pm := c.Position(tm)
+	if depth > 0 && chordDistanceSq(&pm, p0, p1) > tolSq {
+		points = subdivide(c, t0, tm, p0, &pm, tolSq, depth-1, points, params)
+		return subdivide(c, tm, t1, &pm, p1, tolSq, depth-1, points, params)
+	}
+	if params != nil {
+		*params = append(*params, t1)
+	}
+	return append(points, *p1)
+}
+
+// chordDistanceSq returns the squared distance from
+// point p to the segment from a to b
+func chordDistanceSq(p, a, b *math32.Vector3) float32 {
+
+	var ab, ap math32.Vector3
+	ab.SubVectors(b, a)
+	ap.SubVectors(p, a)
+	t := float32(0)
+	if lsq := ab.LengthSq(); lsq > 0 {
+		t = math32.Clamp(ap.Dot(&ab)/lsq, 0, 1)
+	}
+	ab.MultiplyScalar(t).Add(a)
+	return ab.DistanceToSquared(p)
+}
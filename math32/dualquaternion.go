@@ -0,0 +1,200 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// DualQuaternion represents a rigid transformation, a rotation followed by a
+// translation, as a dual quaternion. Blending dual quaternions keeps the
+// transformations rigid, which avoids the loss of volume (candy-wrapper
+// artifacts) of blending skinning matrices.
+type DualQuaternion struct {
+	Real Quaternion // rotation
+	Dual Quaternion // half the translation multiplied by the rotation
+}
+
+// NewDualQuaternion creates and returns a pointer to a new identity dual quaternion
+func NewDualQuaternion() *DualQuaternion {
+
+	dq := new(DualQuaternion)
+	return dq.SetIdentity()
+}
+
+// SetIdentity sets this dual quaternion to the identity transformation.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) SetIdentity() *DualQuaternion {
+
+	dq.Real.Set(0, 0, 0, 1)
+	dq.Dual.Set(0, 0, 0, 0)
+	return dq
+}
+
+// Copy copies the specified dual quaternion into this one.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) Copy(other *DualQuaternion) *DualQuaternion {
+
+	*dq = *other
+	return dq
+}
+
+// SetFromRotationTranslation sets this dual quaternion to the transformation which
+// applies the specified unit rotation quaternion followed by the specified translation.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) SetFromRotationTranslation(rotation *Quaternion, translation *Vector3) *DualQuaternion {
+
+	dq.Real = *rotation
+	t := Quaternion{translation.X * 0.5, translation.Y * 0.5, translation.Z * 0.5, 0}
+	dq.Dual.MultiplyQuaternions(&t, rotation)
+	return dq
+}
+
+// SetFromTRS sets this dual quaternion to the transformation with the specified
+// position and unit rotation quaternion. Dual quaternions cannot represent scales,
+// so the scale must be applied separately, before the transformation.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) SetFromTRS(position *Vector3, rotation *Quaternion) *DualQuaternion {
+
+	return dq.SetFromRotationTranslation(rotation, position)
+}
+
+// SetFromMatrix sets this dual quaternion to the rotation and translation
+// of the specified matrix, ignoring its scale.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) SetFromMatrix(m *Matrix4) *DualQuaternion {
+
+	var position, scale Vector3
+	var rotation Quaternion
+	m.Decompose(&position, &rotation, &scale)
+	return dq.SetFromRotationTranslation(&rotation, &position)
+}
+
+// Rotation returns the rotation quaternion of this dual quaternion
+func (dq *DualQuaternion) Rotation() Quaternion {
+
+	return dq.Real
+}
+
+// Translation returns the translation of this dual quaternion
+func (dq *DualQuaternion) Translation() Vector3 {
+
+	// t = 2 * dual * conjugate(real)
+	conj := dq.Real
+	conj.Conjugate()
+	var t Quaternion
+	t.MultiplyQuaternions(&dq.Dual, &conj)
+	return Vector3{2 * t.x, 2 * t.y, 2 * t.z}
+}
+
+// ToMatrix4 returns the transformation matrix of this dual quaternion
+func (dq *DualQuaternion) ToMatrix4() Matrix4 {
+
+	var m Matrix4
+	t := dq.Translation()
+	m.Compose(&t, &dq.Real, &Vector3{1, 1, 1})
+	return m
+}
+
+// Multiply multiplies this dual quaternion by the specified one, so the
+// transformation of the specified one is applied first.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) Multiply(other *DualQuaternion) *DualQuaternion {
+
+	return dq.MultiplyDualQuaternions(dq, other)
+}
+
+// MultiplyDualQuaternions sets this dual quaternion to the product of a and b,
+// so the transformation of b is applied first.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) MultiplyDualQuaternions(a, b *DualQuaternion) *DualQuaternion {
+
+	var real, d1, d2 Quaternion
+	real.MultiplyQuaternions(&a.Real, &b.Real)
+	d1.MultiplyQuaternions(&a.Real, &b.Dual)
+	d2.MultiplyQuaternions(&a.Dual, &b.Real)
+	dq.Real = real
+	dq.Dual.Set(d1.x+d2.x, d1.y+d2.y, d1.z+d2.z, d1.w+d2.w)
+	return dq
+}
+
+// Conjugate sets this unit dual quaternion to its conjugate,
+// which is the inverse transformation.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) Conjugate() *DualQuaternion {
+
+	dq.Real.Conjugate()
+	dq.Dual.Conjugate()
+	return dq
+}
+
+// Normalize normalizes this dual quaternion so it represents a rigid
+// transformation, with a unit real part orthogonal to the dual part.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) Normalize() *DualQuaternion {
+
+	l := dq.Real.Length()
+	if l == 0 {
+		return dq.SetIdentity()
+	}
+	inv := 1 / l
+	r := &dq.Real
+	d := &dq.Dual
+	r.Set(r.x*inv, r.y*inv, r.z*inv, r.w*inv)
+	d.Set(d.x*inv, d.y*inv, d.z*inv, d.w*inv)
+	// Removes the component of the dual part along the real part
+	dot := r.Dot(d)
+	d.Set(d.x-r.x*dot, d.y-r.y*dot, d.z-r.z*dot, d.w-r.w*dot)
+	return dq
+}
+
+// Blend sets this dual quaternion to the dual quaternion linear blending (DLB)
+// of the specified dual quaternions with the specified weights, as the influences
+// of the joints on a skinned vertex. The dual quaternions are negated as needed
+// so they are in the same hemisphere as the first one, which blends the rotations
+// along the shortest paths.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) Blend(dqs []DualQuaternion, weights []float32) *DualQuaternion {
+
+	var r, d Quaternion
+	r.Set(0, 0, 0, 0)
+	for i := range dqs {
+		if i >= len(weights) {
+			break
+		}
+		w := weights[i]
+		if i > 0 && dqs[0].Real.Dot(&dqs[i].Real) < 0 {
+			w = -w
+		}
+		ri := &dqs[i].Real
+		di := &dqs[i].Dual
+		r.Set(r.x+ri.x*w, r.y+ri.y*w, r.z+ri.z*w, r.w+ri.w*w)
+		d.Set(d.x+di.x*w, d.y+di.y*w, d.z+di.z*w, d.w+di.w*w)
+	}
+	dq.Real = r
+	dq.Dual = d
+	return dq.Normalize()
+}
+
+// Lerp sets this dual quaternion to the normalized linear interpolation from
+// this dual quaternion to the specified one at t.
+// Returns pointer to this updated dual quaternion.
+func (dq *DualQuaternion) Lerp(other *DualQuaternion, t float32) *DualQuaternion {
+
+	dqs := [2]DualQuaternion{*dq, *other}
+	return dq.Blend(dqs[:], []float32{1 - t, t})
+}
+
+// TransformPoint transforms the specified point by this unit dual quaternion.
+// Returns pointer to the updated point.
+func (dq *DualQuaternion) TransformPoint(point *Vector3) *Vector3 {
+
+	t := dq.Translation()
+	return point.ApplyQuaternion(&dq.Real).Add(&t)
+}
+
+// TransformVector rotates the specified direction by this unit dual quaternion,
+// ignoring its translation.
+// Returns pointer to the updated direction.
+func (dq *DualQuaternion) TransformVector(v *Vector3) *Vector3 {
+
+	return v.ApplyQuaternion(&dq.Real)
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package easing implements the easing functions of the tweens of the gui
+// and animation packages, which map the fraction of the duration of a
+// transition, from 0 to 1, to the fraction of the change of its value,
+// which may overshoot 0 and 1. The cubic Bezier easings of the math32/curve
+// package, as the CSS timing functions, are used through their Value method.
+package easing
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Func is the type of the easing functions
+type Func func(t float32) float32
+
+// Linear changes the value at a constant rate
+func Linear(t float32) float32 {
+
+	return t
+}
+
+// InQuad starts slowly and accelerates
+func InQuad(t float32) float32 {
+
+	return t * t
+}
+
+// OutQuad starts quickly and decelerates
+func OutQuad(t float32) float32 {
+
+	return t * (2 - t)
+}
+
+// InOutQuad accelerates until the middle and then decelerates
+func InOutQuad(t float32) float32 {
+
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// InCubic starts slowly and accelerates more than InQuad
+func InCubic(t float32) float32 {
+
+	return t * t * t
+}
+
+// OutCubic starts quickly and decelerates more than OutQuad
+func OutCubic(t float32) float32 {
+
+	t--
+	return t*t*t + 1
+}
+
+// InOutCubic accelerates until the middle and then decelerates
+// more than InOutQuad
+func InOutCubic(t float32) float32 {
+
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// InOutSine follows a sine curve
+func InOutSine(t float32) float32 {
+
+	return (1 - math32.Cos(math32.Pi*t)) / 2
+}
+
+// OutBack overshoots the final value and comes back to it
+func OutBack(t float32) float32 {
+
+	const s = 1.70158
+	t--
+	return t*t*((s+1)*t+s) + 1
+}
+
+// OutBounce bounces at the final value as a dropped ball
+func OutBounce(t float32) float32 {
+
+	switch {
+	case t < 1/2.75:
+		return 7.5625 * t * t
+	case t < 2/2.75:
+		t -= 1.5 / 2.75
+		return 7.5625*t*t + 0.75
+	case t < 2.5/2.75:
+		t -= 2.25 / 2.75
+		return 7.5625*t*t + 0.9375
+	default:
+		t -= 2.625 / 2.75
+		return 7.5625*t*t + 0.984375
+	}
+}
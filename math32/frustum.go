@@ -4,10 +4,33 @@
 
 package math32
 
+// Frustum represents the volume bounded by six planes, as the volume seen by
+// a camera, whose normals point inside it, in the order right, left, bottom,
+// top, far and near as set by SetFromMatrix.
 type Frustum struct {
 	planes []Plane
 }
 
+// Containment is the result of the classification of a volume against a frustum
+type Containment int
+
+// Frustum classification results
+const (
+	Outside    Containment = iota // entirely outside the frustum
+	Intersects                    // partially inside the frustum
+	Inside                        // entirely inside the frustum
+)
+
+// Indices of the frustum planes
+const (
+	FrustumRight = iota
+	FrustumLeft
+	FrustumBottom
+	FrustumTop
+	FrustumFar
+	FrustumNear
+)
+
 func NewFrustum(p0, p1, p2, p3, p4, p5 *Plane) *Frustum {
 
 	this := new(Frustum)
@@ -64,6 +87,18 @@ func (this *Frustum) Copy(frustum *Frustum) *Frustum {
 	return this
 }
 
+// NewFrustumFromMatrix creates and returns a pointer to a new frustum
+// with the planes extracted from the specified projection or view-projection matrix
+func NewFrustumFromMatrix(m *Matrix4) *Frustum {
+
+	return NewFrustum(nil, nil, nil, nil, nil, nil).SetFromMatrix(m)
+}
+
+// SetFromMatrix sets the planes of this frustum to the planes extracted from the
+// specified projection or view-projection matrix, perspective or orthographic,
+// in the space transformed by the matrix. A plane at infinity, as the far plane
+// of an infinite projection, contains all the points.
+// Returns pointer to this updated frustum.
 func (this *Frustum) SetFromMatrix(m *Matrix4) *Frustum {
 
 	planes := this.planes
@@ -84,12 +119,19 @@ func (this *Frustum) SetFromMatrix(m *Matrix4) *Frustum {
 	me14 := m[14]
 	me15 := m[15]
 
-	planes[0].SetComponents(me3-me0, me7-me4, me11-me8, me15-me12).Normalize()
-	planes[1].SetComponents(me3+me0, me7+me4, me11+me8, me15+me12).Normalize()
-	planes[2].SetComponents(me3+me1, me7+me5, me11+me9, me15+me13).Normalize()
-	planes[3].SetComponents(me3-me1, me7-me5, me11-me9, me15-me13).Normalize()
-	planes[4].SetComponents(me3-me2, me7-me6, me11-me10, me15-me14).Normalize()
-	planes[5].SetComponents(me3+me2, me7+me6, me11+me10, me15+me14).Normalize()
+	planes[0].SetComponents(me3-me0, me7-me4, me11-me8, me15-me12)
+	planes[1].SetComponents(me3+me0, me7+me4, me11+me8, me15+me12)
+	planes[2].SetComponents(me3+me1, me7+me5, me11+me9, me15+me13)
+	planes[3].SetComponents(me3-me1, me7-me5, me11-me9, me15-me13)
+	planes[4].SetComponents(me3-me2, me7-me6, me11-me10, me15-me14)
+	planes[5].SetComponents(me3+me2, me7+me6, me11+me10, me15+me14)
+	for i := range planes {
+		if planes[i].normal.LengthSq() > 0 {
+			planes[i].Normalize()
+		} else {
+			planes[i].constant = 0
+		}
+	}
 
 	return this
 }
@@ -127,32 +169,23 @@ func (this *Frustum) IntersectsBox(box *Box3) bool {
 		plane := &this.planes[i]
 		if plane.normal.X > 0 {
 			p1.X = box.Min.X
-		} else {
-			p1.X = box.Max.X
-		}
-		if plane.normal.X > 0 {
 			p2.X = box.Max.X
 		} else {
+			p1.X = box.Max.X
 			p2.X = box.Min.X
 		}
 		if plane.normal.Y > 0 {
-			p2.Y = box.Min.Y
-		} else {
-			p2.Y = box.Max.Y
-		}
-		if plane.normal.Y > 0 {
+			p1.Y = box.Min.Y
 			p2.Y = box.Max.Y
 		} else {
+			p1.Y = box.Max.Y
 			p2.Y = box.Min.Y
 		}
 		if plane.normal.Z > 0 {
 			p1.Z = box.Min.Z
+			p2.Z = box.Max.Z
 		} else {
 			p1.Z = box.Max.Z
-		}
-		if plane.normal.Z > 0 {
-			p1.Z = box.Max.Z
-		} else {
 			p2.Z = box.Min.Z
 		}
 
@@ -179,6 +212,113 @@ func (this *Frustum) ContainsPoint(point *Vector3) bool {
 	return true
 }
 
+// Plane returns a pointer to the plane of this frustum with the specified index
+func (this *Frustum) Plane(i int) *Plane {
+
+	return &this.planes[i]
+}
+
+// ClassifySphere returns whether the specified sphere is
+// outside, intersects or is inside this frustum
+func (this *Frustum) ClassifySphere(sphere *Sphere) Containment {
+
+	res := Inside
+	for i := 0; i < 6; i++ {
+		distance := this.planes[i].DistanceToPoint(&sphere.Center)
+		if distance < -sphere.Radius {
+			return Outside
+		}
+		if distance < sphere.Radius {
+			res = Intersects
+		}
+	}
+	return res
+}
+
+// ClassifyBox returns whether the specified axis aligned box is
+// outside, intersects or is inside this frustum
+func (this *Frustum) ClassifyBox(box *Box3) Containment {
+
+	var pos, neg Vector3
+	res := Inside
+	for i := 0; i < 6; i++ {
+		plane := &this.planes[i]
+		// Corners of the box farthest along and against the normal of the plane
+		pos = box.Max
+		neg = box.Min
+		if plane.normal.X < 0 {
+			pos.X, neg.X = box.Min.X, box.Max.X
+		}
+		if plane.normal.Y < 0 {
+			pos.Y, neg.Y = box.Min.Y, box.Max.Y
+		}
+		if plane.normal.Z < 0 {
+			pos.Z, neg.Z = box.Min.Z, box.Max.Z
+		}
+		if plane.DistanceToPoint(&pos) < 0 {
+			return Outside
+		}
+		if plane.DistanceToPoint(&neg) < 0 {
+			res = Intersects
+		}
+	}
+	return res
+}
+
+// Corners returns the eight corners of this frustum, the near corners first,
+// each in the order left bottom, right bottom, right top and left top,
+// followed by the far corners in the same order.
+// The frustum must have near and far planes at finite distances.
+func (this *Frustum) Corners() [8]Vector3 {
+
+	return this.SplitCorners(0, 1)
+}
+
+// SplitCorners returns the eight corners of the slice of this frustum between
+// the specified fractions of the distance from its near plane to its far plane,
+// in the same order as Corners, as the splits of cascaded shadow maps.
+// For a perspective frustum the fraction of a view distance d is (d-near)/(far-near).
+func (this *Frustum) SplitCorners(near, far float32) [8]Vector3 {
+
+	var corners [8]Vector3
+	sides := [4][2]int{
+		{FrustumLeft, FrustumBottom},
+		{FrustumRight, FrustumBottom},
+		{FrustumRight, FrustumTop},
+		{FrustumLeft, FrustumTop},
+	}
+	for i, side := range sides {
+		n := this.intersection(side[0], side[1], FrustumNear)
+		f := this.intersection(side[0], side[1], FrustumFar)
+		corners[i] = n
+		corners[i].Lerp(&f, near)
+		corners[i+4] = n
+		corners[i+4].Lerp(&f, far)
+	}
+	return corners
+}
+
+// intersection returns the point where the three planes
+// of this frustum with the specified indices intersect
+func (this *Frustum) intersection(i, j, k int) Vector3 {
+
+	p1 := &this.planes[i]
+	p2 := &this.planes[j]
+	p3 := &this.planes[k]
+	var c23, c31, c12 Vector3
+	c23.CrossVectors(&p2.normal, &p3.normal)
+	c31.CrossVectors(&p3.normal, &p1.normal)
+	c12.CrossVectors(&p1.normal, &p2.normal)
+	det := p1.normal.Dot(&c23)
+	if det == 0 {
+		return Vector3{}
+	}
+	c23.MultiplyScalar(-p1.constant)
+	c31.MultiplyScalar(-p2.constant)
+	c12.MultiplyScalar(-p3.constant)
+	return *c23.Add(&c31).Add(&c12).MultiplyScalar(1 / det)
+}
+
 func (this *Frustum) Clone() *Frustum {
 
 	return NewFrustum(nil, nil, nil, nil, nil, nil).Copy(this)
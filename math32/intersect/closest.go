@@ -0,0 +1,177 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intersect
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// ClosestPointAABB returns the point of the specified axis aligned box,
+// including its interior, closest to the specified point
+func ClosestPointAABB(p *math32.Vector3, box *math32.Box3) math32.Vector3 {
+
+	return math32.Vector3{
+		X: math32.Clamp(p.X, box.Min.X, box.Max.X),
+		Y: math32.Clamp(p.Y, box.Min.Y, box.Max.Y),
+		Z: math32.Clamp(p.Z, box.Min.Z, box.Max.Z),
+	}
+}
+
+// ClosestPointOBB returns the point of the specified oriented box,
+// including its interior, closest to the specified point
+func ClosestPointOBB(p *math32.Vector3, obb *OBB) math32.Vector3 {
+
+	var d, axis math32.Vector3
+	d.SubVectors(p, &obb.Center)
+	res := obb.Center
+	for i := 0; i < 3; i++ {
+		h := obb.HalfSize.Component(i)
+		dist := math32.Clamp(d.Dot(&obb.Axes[i]), -h, h)
+		axis.Copy(&obb.Axes[i]).MultiplyScalar(dist)
+		res.Add(&axis)
+	}
+	return res
+}
+
+// ClosestPointSphere returns the point of the specified sphere,
+// including its interior, closest to the specified point
+func ClosestPointSphere(p *math32.Vector3, s *math32.Sphere) math32.Vector3 {
+
+	var d math32.Vector3
+	d.SubVectors(p, &s.Center)
+	dist := d.Length()
+	if dist <= s.Radius {
+		return *p
+	}
+	d.MultiplyScalar(s.Radius / dist).Add(&s.Center)
+	return d
+}
+
+// ClosestPointPlane returns the point of the specified plane
+// closest to the specified point
+func ClosestPointPlane(p *math32.Vector3, plane *math32.Plane) math32.Vector3 {
+
+	normal := plane.Normal()
+	normal.MultiplyScalar(-plane.DistanceToPoint(p)).Add(p)
+	return normal
+}
+
+// ClosestPointSegment returns the point of the segment from a to b closest
+// to the specified point and its parameter, from 0 at a to 1 at b
+func ClosestPointSegment(p, a, b *math32.Vector3) (math32.Vector3, float32) {
+
+	var ab, ap math32.Vector3
+	ab.SubVectors(b, a)
+	ap.SubVectors(p, a)
+	t := float32(0)
+	if lsq := ab.LengthSq(); lsq > epsilon {
+		t = math32.Clamp(ap.Dot(&ab)/lsq, 0, 1)
+	}
+	ab.MultiplyScalar(t).Add(a)
+	return ab, t
+}
+
+// ClosestPointTriangle returns the point of the triangle with the specified
+// vertices, including its interior, closest to the specified point
+func ClosestPointTriangle(p, a, b, c *math32.Vector3) math32.Vector3 {
+
+	var ab, ac, ap, bp, cp math32.Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+
+	// Vertex region of a
+	ap.SubVectors(p, a)
+	d1 := ab.Dot(&ap)
+	d2 := ac.Dot(&ap)
+	if d1 <= 0 && d2 <= 0 {
+		return *a
+	}
+	// Vertex region of b
+	bp.SubVectors(p, b)
+	d3 := ab.Dot(&bp)
+	d4 := ac.Dot(&bp)
+	if d3 >= 0 && d4 <= d3 {
+		return *b
+	}
+	// Edge region of ab
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		v := d1 / (d1 - d3)
+		return *ab.MultiplyScalar(v).Add(a)
+	}
+	// Vertex region of c
+	cp.SubVectors(p, c)
+	d5 := ab.Dot(&cp)
+	d6 := ac.Dot(&cp)
+	if d6 >= 0 && d5 <= d6 {
+		return *c
+	}
+	// Edge region of ac
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		w := d2 / (d2 - d6)
+		return *ac.MultiplyScalar(w).Add(a)
+	}
+	// Edge region of bc
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		var bc math32.Vector3
+		bc.SubVectors(c, b)
+		return *bc.MultiplyScalar(w).Add(b)
+	}
+	// Face region, using the barycentric coordinates
+	denom := 1 / (va + vb + vc)
+	ab.MultiplyScalar(vb * denom)
+	ac.MultiplyScalar(vc * denom)
+	return *ab.Add(&ac).Add(a)
+}
+
+// ClosestPointsSegments returns the closest points of the segments from p1
+// to q1 and from p2 to q2 and their parameters s and t along the segments,
+// from 0 at their starts to 1 at their ends
+func ClosestPointsSegments(p1, q1, p2, q2 *math32.Vector3) (c1, c2 math32.Vector3, s, t float32) {
+
+	var d1, d2, r math32.Vector3
+	d1.SubVectors(q1, p1)
+	d2.SubVectors(q2, p2)
+	r.SubVectors(p1, p2)
+	a := d1.Dot(&d1)
+	e := d2.Dot(&d2)
+	f := d2.Dot(&r)
+	switch {
+	case a <= epsilon && e <= epsilon:
+		// Both segments are points
+	case a <= epsilon:
+		// The first segment is a point
+		t = math32.Clamp(f/e, 0, 1)
+	default:
+		c := d1.Dot(&r)
+		if e <= epsilon {
+			// The second segment is a point
+			s = math32.Clamp(-c/a, 0, 1)
+			break
+		}
+		// Point of the first segment closest to the line of the second,
+		// or its start if the lines are parallel, and then the point of
+		// the second segment closest to it, clamping both to the segments
+		b := d1.Dot(&d2)
+		denom := a*e - b*b
+		if denom > epsilon {
+			s = math32.Clamp((b*f-c*e)/denom, 0, 1)
+		}
+		t = (b*s + f) / e
+		if t < 0 {
+			t = 0
+			s = math32.Clamp(-c/a, 0, 1)
+		} else if t > 1 {
+			t = 1
+			s = math32.Clamp((b-c)/a, 0, 1)
+		}
+	}
+	c1 = *d1.MultiplyScalar(s).Add(p1)
+	c2 = *d2.MultiplyScalar(t).Add(p2)
+	return c1, c2, s, t
+}
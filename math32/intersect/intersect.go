@@ -0,0 +1,172 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package intersect implements the intersection tests between rays,
+// segments and shapes, the sweeps of moving shapes and the closest point
+// queries used by the raycaster, physics and gameplay code.
+//
+// The ray tests return the parameter t of the first intersection, which is
+// the point origin + t * direction, so it is the distance from the origin
+// when the direction is normalized. The segment tests return t between 0
+// at the start and 1 at the end of the segment. Rays and segments which
+// start inside a shape intersect it at t = 0.
+package intersect
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// epsilon is the tolerance of the tests of parallel directions
+// and degenerate shapes
+const epsilon = 1e-7
+
+// OBB is an oriented bounding box
+type OBB struct {
+	Center   math32.Vector3    // center of the box
+	Axes     [3]math32.Vector3 // normalized local axes of the box
+	HalfSize math32.Vector3    // half of the size of the box along each axis
+}
+
+// Capsule is the volume within a radius of a segment
+type Capsule struct {
+	A      math32.Vector3 // start of the segment
+	B      math32.Vector3 // end of the segment
+	Radius float32        // radius of the capsule
+}
+
+// NewOBB creates and returns a pointer to a new oriented bounding box
+// which is the specified axis aligned box transformed by the specified
+// matrix, as the bounding box of a mesh transformed by its world matrix
+func NewOBB(box *math32.Box3, matrix *math32.Matrix4) *OBB {
+
+	obb := new(OBB)
+	box.Center(&obb.Center)
+	obb.Center.ApplyMatrix4(matrix)
+	var size math32.Vector3
+	box.Size(&size)
+	for i := 0; i < 3; i++ {
+		axis := &obb.Axes[i]
+		axis.SetFromMatrixColumn(i, matrix)
+		scale := axis.Length()
+		if scale > epsilon {
+			axis.DivideScalar(scale)
+		}
+		obb.HalfSize.SetComponent(i, size.Component(i)*scale/2)
+	}
+	return obb
+}
+
+// NewCapsule creates and returns a pointer to a new capsule
+// around the specified segment with the specified radius
+func NewCapsule(a, b *math32.Vector3, radius float32) *Capsule {
+
+	return &Capsule{A: *a, B: *b, Radius: radius}
+}
+
+// AABBAABB returns if the specified axis aligned boxes overlap
+func AABBAABB(a, b *math32.Box3) bool {
+
+	return a.Min.X <= b.Max.X && a.Max.X >= b.Min.X &&
+		a.Min.Y <= b.Max.Y && a.Max.Y >= b.Min.Y &&
+		a.Min.Z <= b.Max.Z && a.Max.Z >= b.Min.Z
+}
+
+// SphereSphere returns if the specified spheres overlap
+func SphereSphere(a, b *math32.Sphere) bool {
+
+	r := a.Radius + b.Radius
+	return a.Center.DistanceToSquared(&b.Center) <= r*r
+}
+
+// SphereAABB returns if the specified sphere and axis aligned box overlap
+func SphereAABB(s *math32.Sphere, box *math32.Box3) bool {
+
+	p := ClosestPointAABB(&s.Center, box)
+	return p.DistanceToSquared(&s.Center) <= s.Radius*s.Radius
+}
+
+// SphereOBB returns if the specified sphere and oriented box overlap
+func SphereOBB(s *math32.Sphere, obb *OBB) bool {
+
+	p := ClosestPointOBB(&s.Center, obb)
+	return p.DistanceToSquared(&s.Center) <= s.Radius*s.Radius
+}
+
+// SpherePlane returns if the specified sphere intersects the specified plane
+func SpherePlane(s *math32.Sphere, plane *math32.Plane) bool {
+
+	return math32.Abs(plane.DistanceToPoint(&s.Center)) <= s.Radius
+}
+
+// SphereTriangle returns if the specified sphere and triangle overlap
+func SphereTriangle(s *math32.Sphere, a, b, c *math32.Vector3) bool {
+
+	p := ClosestPointTriangle(&s.Center, a, b, c)
+	return p.DistanceToSquared(&s.Center) <= s.Radius*s.Radius
+}
+
+// CapsuleSphere returns if the specified capsule and sphere overlap
+func CapsuleSphere(c *Capsule, s *math32.Sphere) bool {
+
+	p, _ := ClosestPointSegment(&s.Center, &c.A, &c.B)
+	r := c.Radius + s.Radius
+	return p.DistanceToSquared(&s.Center) <= r*r
+}
+
+// CapsuleCapsule returns if the specified capsules overlap
+func CapsuleCapsule(a, b *Capsule) bool {
+
+	pa, pb, _, _ := ClosestPointsSegments(&a.A, &a.B, &b.A, &b.B)
+	r := a.Radius + b.Radius
+	return pa.DistanceToSquared(&pb) <= r*r
+}
+
+// OBBOBB returns if the specified oriented boxes overlap,
+// testing their separating axes
+func OBBOBB(a, b *OBB) bool {
+
+	// Rotation of b in the frame of a and its absolute values, increased by
+	// epsilon to handle the cross products of nearly parallel edges
+	var rot, abs [3][3]float32
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			rot[i][j] = a.Axes[i].Dot(&b.Axes[j])
+			abs[i][j] = math32.Abs(rot[i][j]) + epsilon
+		}
+	}
+	// Translation from a to b in the frame of a
+	var d math32.Vector3
+	d.SubVectors(&b.Center, &a.Center)
+	t := [3]float32{d.Dot(&a.Axes[0]), d.Dot(&a.Axes[1]), d.Dot(&a.Axes[2])}
+	ea := [3]float32{a.HalfSize.X, a.HalfSize.Y, a.HalfSize.Z}
+	eb := [3]float32{b.HalfSize.X, b.HalfSize.Y, b.HalfSize.Z}
+
+	// Axes of a
+	for i := 0; i < 3; i++ {
+		rb := eb[0]*abs[i][0] + eb[1]*abs[i][1] + eb[2]*abs[i][2]
+		if math32.Abs(t[i]) > ea[i]+rb {
+			return false
+		}
+	}
+	// Axes of b
+	for j := 0; j < 3; j++ {
+		ra := ea[0]*abs[0][j] + ea[1]*abs[1][j] + ea[2]*abs[2][j]
+		if math32.Abs(t[0]*rot[0][j]+t[1]*rot[1][j]+t[2]*rot[2][j]) > ra+eb[j] {
+			return false
+		}
+	}
+	// Cross products of the axes of a and b
+	for i := 0; i < 3; i++ {
+		i1, i2 := (i+1)%3, (i+2)%3
+		for j := 0; j < 3; j++ {
+			j1, j2 := (j+1)%3, (j+2)%3
+			ra := ea[i1]*abs[i2][j] + ea[i2]*abs[i1][j]
+			rb := eb[j1]*abs[i][j2] + eb[j2]*abs[i][j1]
+			if math32.Abs(t[i2]*rot[i1][j]-t[i1]*rot[i2][j]) > ra+rb {
+				return false
+			}
+		}
+	}
+	return true
+}
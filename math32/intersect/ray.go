@@ -0,0 +1,290 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intersect
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// RayAABB returns the parameter of the first intersection of the
+// specified ray with the specified axis aligned box and if they intersect
+func RayAABB(ray *math32.Ray, box *math32.Box3) (float32, bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	return slabs(&origin, &dir, &box.Min, &box.Max)
+}
+
+// RaySphere returns the parameter of the first intersection of the
+// specified ray with the specified sphere and if they intersect
+func RaySphere(ray *math32.Ray, s *math32.Sphere) (float32, bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	return raySphere(&origin, &dir, &s.Center, s.Radius)
+}
+
+// RayPlane returns the parameter of the intersection of the specified ray
+// with the specified plane and if they intersect. A ray in the plane
+// intersects it at its origin.
+func RayPlane(ray *math32.Ray, plane *math32.Plane) (float32, bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	normal := plane.Normal()
+	dist := plane.DistanceToPoint(&origin)
+	denom := normal.Dot(&dir)
+	if math32.Abs(denom) < epsilon {
+		return 0, math32.Abs(dist) < epsilon
+	}
+	t := -dist / denom
+	if t < 0 {
+		return 0, false
+	}
+	return t, true
+}
+
+// RayTriangle returns the parameter of the intersection of the specified
+// ray with the triangle with the specified vertices, the barycentric
+// coordinates u and v of the intersection, which is a + u*(b-a) + v*(c-a),
+// and if they intersect. If cullBack is true the back face of the triangle,
+// whose vertices are seen clockwise, is not intersected.
+func RayTriangle(ray *math32.Ray, a, b, c *math32.Vector3, cullBack bool) (t, u, v float32, ok bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	var e1, e2, p, s, q math32.Vector3
+	e1.SubVectors(b, a)
+	e2.SubVectors(c, a)
+	p.CrossVectors(&dir, &e2)
+	det := e1.Dot(&p)
+	if det < epsilon && (cullBack || det > -epsilon) {
+		return 0, 0, 0, false
+	}
+	inv := 1 / det
+	s.SubVectors(&origin, a)
+	u = s.Dot(&p) * inv
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+	q.CrossVectors(&s, &e1)
+	v = dir.Dot(&q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+	t = e2.Dot(&q) * inv
+	if t < 0 {
+		return 0, 0, 0, false
+	}
+	return t, u, v, true
+}
+
+// RayOBB returns the parameter of the first intersection of the
+// specified ray with the specified oriented box and if they intersect
+func RayOBB(ray *math32.Ray, obb *OBB) (float32, bool) {
+
+	// Intersects the ray transformed to the frame of the box
+	origin := ray.Origin()
+	dir := ray.Direction()
+	var rel, lorigin, ldir math32.Vector3
+	rel.SubVectors(&origin, &obb.Center)
+	for i := 0; i < 3; i++ {
+		lorigin.SetComponent(i, rel.Dot(&obb.Axes[i]))
+		ldir.SetComponent(i, dir.Dot(&obb.Axes[i]))
+	}
+	min := obb.HalfSize
+	min.Negate()
+	return slabs(&lorigin, &ldir, &min, &obb.HalfSize)
+}
+
+// RayCapsule returns the parameter of the first intersection of the
+// specified ray with the specified capsule and if they intersect
+func RayCapsule(ray *math32.Ray, c *Capsule) (float32, bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	return rayCapsule(&origin, &dir, c)
+}
+
+// SegmentAABB returns the parameter of the first intersection of the
+// segment from p0 to p1 with the specified axis aligned box and
+// if they intersect
+func SegmentAABB(p0, p1 *math32.Vector3, box *math32.Box3) (float32, bool) {
+
+	var dir math32.Vector3
+	dir.SubVectors(p1, p0)
+	return inSegment(slabs(p0, &dir, &box.Min, &box.Max))
+}
+
+// SegmentSphere returns the parameter of the first intersection of the
+// segment from p0 to p1 with the specified sphere and if they intersect
+func SegmentSphere(p0, p1 *math32.Vector3, s *math32.Sphere) (float32, bool) {
+
+	var dir math32.Vector3
+	dir.SubVectors(p1, p0)
+	return inSegment(raySphere(p0, &dir, &s.Center, s.Radius))
+}
+
+// SegmentPlane returns the parameter of the intersection of the segment
+// from p0 to p1 with the specified plane and if they intersect
+func SegmentPlane(p0, p1 *math32.Vector3, plane *math32.Plane) (float32, bool) {
+
+	var dir math32.Vector3
+	dir.SubVectors(p1, p0)
+	return inSegment(RayPlane(math32.NewRay(p0, &dir), plane))
+}
+
+// SegmentTriangle returns the parameter of the intersection of the segment
+// from p0 to p1 with the triangle with the specified vertices, the
+// barycentric coordinates of the intersection and if they intersect
+func SegmentTriangle(p0, p1, a, b, c *math32.Vector3, cullBack bool) (t, u, v float32, ok bool) {
+
+	var dir math32.Vector3
+	dir.SubVectors(p1, p0)
+	t, u, v, ok = RayTriangle(math32.NewRay(p0, &dir), a, b, c, cullBack)
+	if !ok || t > 1 {
+		return 0, 0, 0, false
+	}
+	return t, u, v, true
+}
+
+// SegmentOBB returns the parameter of the first intersection of the
+// segment from p0 to p1 with the specified oriented box and if they intersect
+func SegmentOBB(p0, p1 *math32.Vector3, obb *OBB) (float32, bool) {
+
+	var dir math32.Vector3
+	dir.SubVectors(p1, p0)
+	return inSegment(RayOBB(math32.NewRay(p0, &dir), obb))
+}
+
+// SegmentCapsule returns the parameter of the first intersection of the
+// segment from p0 to p1 with the specified capsule and if they intersect
+func SegmentCapsule(p0, p1 *math32.Vector3, c *Capsule) (float32, bool) {
+
+	var dir math32.Vector3
+	dir.SubVectors(p1, p0)
+	return inSegment(rayCapsule(p0, &dir, c))
+}
+
+// inSegment returns the specified ray intersection
+// if its parameter is inside of the segment
+func inSegment(t float32, ok bool) (float32, bool) {
+
+	if !ok || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// slabs returns the parameter of the first intersection of the ray with
+// the specified origin and direction with the box with the specified
+// minimum and maximum corners and if they intersect
+func slabs(origin, dir, min, max *math32.Vector3) (float32, bool) {
+
+	tmin := float32(0)
+	tmax := math32.Inf(1)
+	for i := 0; i < 3; i++ {
+		o := origin.Component(i)
+		d := dir.Component(i)
+		lo := min.Component(i)
+		hi := max.Component(i)
+		// The ray parallel to the slab must start inside of it
+		if math32.Abs(d) < epsilon {
+			if o < lo || o > hi {
+				return 0, false
+			}
+			continue
+		}
+		t1 := (lo - o) / d
+		t2 := (hi - o) / d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tmin {
+			tmin = t1
+		}
+		if t2 < tmax {
+			tmax = t2
+		}
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+// raySphere returns the parameter of the first intersection of the ray with
+// the specified origin and direction with the sphere with the specified
+// center and radius and if they intersect
+func raySphere(origin, dir, center *math32.Vector3, radius float32) (float32, bool) {
+
+	var oc math32.Vector3
+	oc.SubVectors(origin, center)
+	a := dir.Dot(dir)
+	b := oc.Dot(dir)
+	c := oc.Dot(&oc) - radius*radius
+	// The origin is inside of the sphere
+	if c <= 0 {
+		return 0, true
+	}
+	// The origin is outside of the sphere and the ray points away from it
+	if b > 0 || a < epsilon {
+		return 0, false
+	}
+	disc := b*b - a*c
+	if disc < 0 {
+		return 0, false
+	}
+	return (-b - math32.Sqrt(disc)) / a, true
+}
+
+// rayCapsule returns the parameter of the first intersection of the ray
+// with the specified origin and direction with the specified capsule
+// and if they intersect
+func rayCapsule(origin, dir *math32.Vector3, c *Capsule) (float32, bool) {
+
+	p, _ := ClosestPointSegment(origin, &c.A, &c.B)
+	if p.DistanceToSquared(origin) <= c.Radius*c.Radius {
+		return 0, true
+	}
+
+	// Intersects the cylinder around the segment, accepting the
+	// intersections between the planes of the ends of the segment
+	var ba, oa math32.Vector3
+	ba.SubVectors(&c.B, &c.A)
+	oa.SubVectors(origin, &c.A)
+	baba := ba.Dot(&ba)
+	bad := ba.Dot(dir)
+	baoa := ba.Dot(&oa)
+	a := baba*dir.Dot(dir) - bad*bad
+	b := baba*dir.Dot(&oa) - baoa*bad
+	cc := baba*oa.Dot(&oa) - baoa*baoa - c.Radius*c.Radius*baba
+	tmin := math32.Inf(1)
+	found := false
+	if a > epsilon {
+		h := b*b - a*cc
+		if h >= 0 {
+			t := (-b - math32.Sqrt(h)) / a
+			y := baoa + t*bad
+			if t >= 0 && y > 0 && y < baba {
+				tmin = t
+				found = true
+			}
+		}
+	}
+
+	// Intersects the spheres of the ends, whose intersections inside of
+	// the cylinder are farther than the intersection with the capsule
+	for _, end := range []*math32.Vector3{&c.A, &c.B} {
+		if t, ok := raySphere(origin, dir, end, c.Radius); ok && t < tmin {
+			tmin = t
+			found = true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	return tmin, true
+}
@@ -0,0 +1,155 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intersect
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// SweepAABB returns the time of the first contact of the specified axis
+// aligned boxes moving with the specified displacements during a time step,
+// from 0 at its start to 1 at its end, the normal of the face of box b which
+// is touched and if they touch during the step. Boxes which overlap at the
+// start of the step touch at time 0 with a zero normal.
+func SweepAABB(a *math32.Box3, va *math32.Vector3, b *math32.Box3, vb *math32.Vector3) (float32, math32.Vector3, bool) {
+
+	var normal math32.Vector3
+	if AABBAABB(a, b) {
+		return 0, normal, true
+	}
+
+	// Moves box a relative to box b
+	var v math32.Vector3
+	v.SubVectors(va, vb)
+	tenter := math32.Inf(-1)
+	texit := math32.Inf(1)
+	axis := -1
+	for i := 0; i < 3; i++ {
+		amin, amax := a.Min.Component(i), a.Max.Component(i)
+		bmin, bmax := b.Min.Component(i), b.Max.Component(i)
+		d := v.Component(i)
+		// Without motion along the axis the boxes must overlap on it
+		if math32.Abs(d) < epsilon {
+			if amax < bmin || amin > bmax {
+				return 0, normal, false
+			}
+			continue
+		}
+		var t1, t2 float32
+		if d > 0 {
+			t1 = (bmin - amax) / d
+			t2 = (bmax - amin) / d
+		} else {
+			t1 = (bmax - amin) / d
+			t2 = (bmin - amax) / d
+		}
+		if t1 > tenter {
+			tenter = t1
+			axis = i
+		}
+		if t2 < texit {
+			texit = t2
+		}
+	}
+	if axis < 0 || tenter > texit || tenter < 0 || tenter > 1 {
+		return 0, normal, false
+	}
+	if v.Component(axis) > 0 {
+		normal.SetComponent(axis, -1)
+	} else {
+		normal.SetComponent(axis, 1)
+	}
+	return tenter, normal, true
+}
+
+// SweepSphere returns the time of the first contact of the specified spheres
+// moving with the specified displacements during a time step, from 0 at its
+// start to 1 at its end, and if they touch during the step.
+// Spheres which overlap at the start of the step touch at time 0.
+func SweepSphere(a *math32.Sphere, va *math32.Vector3, b *math32.Sphere, vb *math32.Vector3) (float32, bool) {
+
+	// Intersects the segment of the motion of the center of a relative to b
+	// with the sphere of the sum of the radii around the center of b
+	var end math32.Vector3
+	end.SubVectors(va, vb).Add(&a.Center)
+	s := math32.Sphere{Center: b.Center, Radius: a.Radius + b.Radius}
+	return SegmentSphere(&a.Center, &end, &s)
+}
+
+// SweepSphereAABB returns the time of the first contact of the specified
+// sphere moving with the specified displacement during a time step with the
+// specified static axis aligned box and if they touch during the step.
+// A sphere which overlaps the box at the start of the step touches it at time 0.
+func SweepSphereAABB(s *math32.Sphere, v *math32.Vector3, box *math32.Box3) (float32, bool) {
+
+	if SphereAABB(s, box) {
+		return 0, true
+	}
+
+	// Intersects the motion of the center with the box expanded by the radius,
+	// which is exact except near its edges and corners, where the contact is
+	// with the capsules of the rounded box edges
+	var end math32.Vector3
+	end.AddVectors(&s.Center, v)
+	expanded := *box
+	expanded.ExpandByScalar(s.Radius)
+	t, ok := SegmentAABB(&s.Center, &end, &expanded)
+	if !ok {
+		return 0, false
+	}
+	var p math32.Vector3
+	p.Copy(v).MultiplyScalar(t).Add(&s.Center)
+	outside := 0
+	for i := 0; i < 3; i++ {
+		if c := p.Component(i); c < box.Min.Component(i) || c > box.Max.Component(i) {
+			outside++
+		}
+	}
+	if outside < 2 {
+		return t, true
+	}
+
+	// Near an edge or corner intersects the capsules of the box edges
+	tmin := math32.Inf(1)
+	corners := boxCorners(box)
+	for _, e := range boxEdges {
+		c := Capsule{A: corners[e[0]], B: corners[e[1]], Radius: s.Radius}
+		if t, ok := SegmentCapsule(&s.Center, &end, &c); ok && t < tmin {
+			tmin = t
+		}
+	}
+	if tmin > 1 {
+		return 0, false
+	}
+	return tmin, true
+}
+
+// boxEdges are the indices of the corners returned
+// by boxCorners at the ends of the edges of a box
+var boxEdges = [12][2]int{
+	{0, 1}, {2, 3}, {4, 5}, {6, 7},
+	{0, 2}, {1, 3}, {4, 6}, {5, 7},
+	{0, 4}, {1, 5}, {2, 6}, {3, 7},
+}
+
+// boxCorners returns the corners of the specified box, the bits 0, 1 and 2
+// of whose indices select the maximum x, y and z coordinates
+func boxCorners(box *math32.Box3) [8]math32.Vector3 {
+
+	var corners [8]math32.Vector3
+	for i := range corners {
+		corners[i] = box.Min
+		if i&1 != 0 {
+			corners[i].X = box.Max.X
+		}
+		if i&2 != 0 {
+			corners[i].Y = box.Max.Y
+		}
+		if i&4 != 0 {
+			corners[i].Z = box.Max.Z
+		}
+	}
+	return corners
+}
@@ -80,6 +80,10 @@ func Atan2(y, x float32) float32 {
 	return float32(math.Atan2(float64(y), float64(x)))
 }
 
+func Cbrt(v float32) float32 {
+	return float32(math.Cbrt(float64(v)))
+}
+
 func Ceil(v float32) float32 {
 	return float32(math.Ceil(float64(v)))
 }
@@ -112,6 +116,10 @@ func Sqrt(v float32) float32 {
 	return float32(math.Sqrt(float64(v)))
 }
 
+func Log(v float32) float32 {
+	return float32(math.Log(float64(v)))
+}
+
 func Max(a, b float32) float32 {
 	return float32(math.Max(float64(a), float64(b)))
 }
@@ -557,6 +557,9 @@ func (m *Matrix4) MakeScale(x, y, z float32) *Matrix4 {
 	return m
 }
 
+// Compose sets this matrix to the transformation composed of the specified
+// scale, rotation quaternion and position, applied in this order.
+// Returns pointer to this updated matrix.
 func (m *Matrix4) Compose(position *Vector3, quaternion *Quaternion, scale *Vector3) *Matrix4 {
 
 	m.MakeRotationFromQuaternion(quaternion)
@@ -565,6 +568,10 @@ func (m *Matrix4) Compose(position *Vector3, quaternion *Quaternion, scale *Vect
 	return m
 }
 
+// Decompose decomposes this matrix into the position, rotation quaternion and
+// scale which compose it. If the matrix mirrors the space, the X scale is negative.
+// A zero scale leaves the rotation of its axis undefined.
+// Returns pointer to this unchanged matrix.
 func (m *Matrix4) Decompose(position *Vector3, quaternion *Quaternion, scale *Vector3) *Matrix4 {
 
 	var vector Vector3
@@ -585,9 +592,9 @@ func (m *Matrix4) Decompose(position *Vector3, quaternion *Quaternion, scale *Ve
 	position.Z = m[14]
 
 	// Scale the rotation part
-	invSX := 1 / sx
-	invSY := 1 / sy
-	invSZ := 1 / sz
+	invSX := inverseScale(sx)
+	invSY := inverseScale(sy)
+	invSZ := inverseScale(sz)
 
 	matrix[0] *= invSX
 	matrix[1] *= invSX
@@ -601,7 +608,7 @@ func (m *Matrix4) Decompose(position *Vector3, quaternion *Quaternion, scale *Ve
 	matrix[9] *= invSZ
 	matrix[10] *= invSZ
 
-	quaternion.SetFromRotationMatrix(&matrix)
+	quaternion.SetFromRotationMatrix(&matrix).Normalize()
 
 	scale.X = sx
 	scale.Y = sy
@@ -609,6 +616,59 @@ func (m *Matrix4) Decompose(position *Vector3, quaternion *Quaternion, scale *Ve
 	return m
 }
 
+// inverseScale returns the inverse of the specified scale, or 1 if it is zero
+func inverseScale(s float32) float32 {
+
+	if s == 0 {
+		return 1
+	}
+	return 1 / s
+}
+
+// LookRotation sets this matrix to the rotation which turns the Z axis to the
+// specified forward direction and the Y axis as close as possible to the specified
+// up direction, as used to orient objects towards a target. The directions need not
+// be normalized. If they are parallel another up direction is used.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) LookRotation(forward, up *Vector3) *Matrix4 {
+
+	var x, y, z Vector3
+	z = *forward
+	if z.LengthSq() == 0 {
+		return m.Identity()
+	}
+	z.Normalize()
+	x.CrossVectors(up, &z)
+	if x.LengthSq() < 1e-12 {
+		// Uses the axis least aligned with the forward direction as up
+		if Abs(z.X) < 0.9 {
+			x.CrossVectors(&Vector3{1, 0, 0}, &z)
+		} else {
+			x.CrossVectors(&Vector3{0, 1, 0}, &z)
+		}
+	}
+	x.Normalize()
+	y.CrossVectors(&z, &x)
+	return m.MakeBasis(&x, &y, &z)
+}
+
+// Interpolate sets this matrix to the interpolation from the transformation
+// of matrix a to the one of matrix b at t, decomposing them so their positions
+// and scales are interpolated linearly and their rotations spherically, which
+// keeps the intermediate matrices rigid unlike interpolating their elements.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Interpolate(a, b *Matrix4, t float32) *Matrix4 {
+
+	var pa, pb, sa, sb Vector3
+	var qa, qb Quaternion
+	a.Decompose(&pa, &qa, &sa)
+	b.Decompose(&pb, &qb, &sb)
+	pa.Lerp(&pb, t)
+	sa.Lerp(&sb, t)
+	qa.Slerp(&qb, t)
+	return m.Compose(&pa, &qa, &sa)
+}
+
 func (m *Matrix4) MakeFrustum(left, right, bottom, top, near, far float32) *Matrix4 {
 
 	m[0] = 2 * near / (right - left)
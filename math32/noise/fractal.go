@@ -0,0 +1,118 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// FractalType is the way the octaves of a fractal noise are combined
+type FractalType int
+
+// Fractal noise types
+const (
+	FBm    FractalType = iota // fractional Brownian motion, the sum of the octaves
+	Ridged                    // sum of the inverted absolute values, with sharp ridges as mountain ranges
+	Billow                    // sum of the absolute values, with round bumps as clouds
+)
+
+// Fractal is the noise which sums several octaves of a source noise, each
+// with a higher frequency and a lower amplitude than the previous one, adding
+// details of decreasing size to the features of the source
+type Fractal struct {
+	Source     Noise       // source noise of the octaves
+	Type       FractalType // way the octaves are combined
+	Octaves    int         // number of octaves
+	Frequency  float32     // frequency of the first octave (default 1)
+	Lacunarity float32     // frequency multiplier of each octave (default 2)
+	Gain       float32     // amplitude multiplier of each octave (default 0.5)
+}
+
+// NewFractal creates and returns a pointer to a new fractal noise
+// of the specified type with the specified source and number of octaves
+func NewFractal(src Noise, typ FractalType, octaves int) *Fractal {
+
+	f := new(Fractal)
+	f.Source = src
+	f.Type = typ
+	f.Octaves = octaves
+	f.Frequency = 1
+	f.Lacunarity = 2
+	f.Gain = 0.5
+	return f
+}
+
+// NewFBm creates and returns a pointer to a new fractional Brownian
+// motion noise with the specified source and number of octaves
+func NewFBm(src Noise, octaves int) *Fractal {
+
+	return NewFractal(src, FBm, octaves)
+}
+
+// NewRidged creates and returns a pointer to a new ridged
+// noise with the specified source and number of octaves
+func NewRidged(src Noise, octaves int) *Fractal {
+
+	return NewFractal(src, Ridged, octaves)
+}
+
+// NewBillow creates and returns a pointer to a new billow
+// noise with the specified source and number of octaves
+func NewBillow(src Noise, octaves int) *Fractal {
+
+	return NewFractal(src, Billow, octaves)
+}
+
+// Noise1 returns the value of the noise at the specified coordinate
+func (f *Fractal) Noise1(x float32) float32 {
+
+	return f.sum(func(freq float32) float32 {
+		return f.Source.Noise1(x * freq)
+	})
+}
+
+// Noise2 returns the value of the noise at the specified coordinates
+func (f *Fractal) Noise2(x, y float32) float32 {
+
+	return f.sum(func(freq float32) float32 {
+		return f.Source.Noise2(x*freq, y*freq)
+	})
+}
+
+// Noise3 returns the value of the noise at the specified coordinates
+func (f *Fractal) Noise3(x, y, z float32) float32 {
+
+	return f.sum(func(freq float32) float32 {
+		return f.Source.Noise3(x*freq, y*freq, z*freq)
+	})
+}
+
+// sum returns the sum of the octaves of the source noise returned by the
+// specified function for each frequency, normalized by the sum of their
+// amplitudes so the result remains between -1 and 1
+func (f *Fractal) sum(octave func(freq float32) float32) float32 {
+
+	var sum, total float32
+	freq := f.Frequency
+	amp := float32(1)
+	for i := 0; i < f.Octaves; i++ {
+		n := octave(freq)
+		switch f.Type {
+		case Ridged:
+			n = 1 - math32.Abs(n)
+			n = n*n*2 - 1
+		case Billow:
+			n = math32.Abs(n)*2 - 1
+		}
+		sum += n * amp
+		total += amp
+		freq *= f.Lacunarity
+		amp *= f.Gain
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package noise implements seeded coherent noise functions in one, two and
+// three dimensions: Perlin and Simplex gradient noise, Worley cellular noise
+// and their fractal combinations, as fBm, ridged and billow noise, used for
+// procedural terrain, clouds and textures baked for the shaders.
+//
+// The noise sources with the same seed always return the same values, so
+// the generated content can be recreated from the seed.
+package noise
+
+import (
+	"github.com/g3n/engine/math32"
+	"image"
+	"math/rand"
+)
+
+// Noise is the interface of the noise sources. The gradient noises return
+// values between -1 and 1 which vary smoothly with the coordinates, with
+// features about one unit apart.
+type Noise interface {
+	Noise1(x float32) float32
+	Noise2(x, y float32) float32
+	Noise3(x, y, z float32) float32
+}
+
+// permutation returns the doubled random permutation
+// of the numbers from 0 to 255 for the specified seed
+func permutation(seed int64) [512]uint8 {
+
+	var perm [512]uint8
+	rnd := rand.New(rand.NewSource(seed))
+	for i, v := range rnd.Perm(256) {
+		perm[i] = uint8(v)
+		perm[i+256] = uint8(v)
+	}
+	return perm
+}
+
+// floor returns the largest integer less than or equal to x
+func floor(x float32) int {
+
+	i := int(x)
+	if float32(i) > x {
+		i--
+	}
+	return i
+}
+
+// Bake returns the values of the specified noise in the plane z = 0 for
+// a grid with the specified width and height, scaled by the specified
+// frequency, stored by rows, as for the data of a float texture
+func Bake(n Noise, width, height int, frequency float32) []float32 {
+
+	data := make([]float32, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			data[y*width+x] = n.Noise2(float32(x)*frequency, float32(y)*frequency)
+		}
+	}
+	return data
+}
+
+// BakeImage returns a gray image with the specified size with the values of
+// the specified noise scaled by the specified frequency, mapping the values
+// from -1 to 1 to the gray levels from black to white, as the lookup table
+// (LUT) textures sampled by the shaders
+func BakeImage(n Noise, width, height int, frequency float32) *image.Gray {
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i, v := range Bake(n, width, height, frequency) {
+		img.Pix[(i/width)*img.Stride+i%width] = uint8(math32.Clamp((v+1)*127.5, 0, 255))
+	}
+	return img
+}
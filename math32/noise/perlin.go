@@ -0,0 +1,153 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+// Perlin is the improved Perlin gradient noise, which interpolates the
+// random gradients at the corners of the integer lattice cells
+type Perlin struct {
+	perm [512]uint8 // permutation used to hash the lattice points
+}
+
+// NewPerlin creates and returns a pointer to a new Perlin noise
+// with the specified seed
+func NewPerlin(seed int64) *Perlin {
+
+	p := new(Perlin)
+	p.perm = permutation(seed)
+	return p
+}
+
+// Noise1 returns the value of the noise at the specified coordinate
+func (p *Perlin) Noise1(x float32) float32 {
+
+	xi := floor(x)
+	x -= float32(xi)
+	xi &= 255
+	u := fade(x)
+	n0 := grad1(p.perm[xi], x)
+	n1 := grad1(p.perm[xi+1], x-1)
+	// The gradients are at most 8, whose contributions are at most 4
+	return lerp(u, n0, n1) * 0.25
+}
+
+// Noise2 returns the value of the noise at the specified coordinates
+func (p *Perlin) Noise2(x, y float32) float32 {
+
+	xi := floor(x)
+	yi := floor(y)
+	x -= float32(xi)
+	y -= float32(yi)
+	xi &= 255
+	yi &= 255
+	u := fade(x)
+	v := fade(y)
+	a := int(p.perm[xi]) + yi
+	b := int(p.perm[xi+1]) + yi
+	return lerp(v,
+		lerp(u, grad2(p.perm[a], x, y), grad2(p.perm[b], x-1, y)),
+		lerp(u, grad2(p.perm[a+1], x, y-1), grad2(p.perm[b+1], x-1, y-1)))
+}
+
+// Noise3 returns the value of the noise at the specified coordinates
+func (p *Perlin) Noise3(x, y, z float32) float32 {
+
+	xi := floor(x)
+	yi := floor(y)
+	zi := floor(z)
+	x -= float32(xi)
+	y -= float32(yi)
+	z -= float32(zi)
+	xi &= 255
+	yi &= 255
+	zi &= 255
+	u := fade(x)
+	v := fade(y)
+	w := fade(z)
+	a := int(p.perm[xi]) + yi
+	aa := int(p.perm[a]) + zi
+	ab := int(p.perm[a+1]) + zi
+	b := int(p.perm[xi+1]) + yi
+	ba := int(p.perm[b]) + zi
+	bb := int(p.perm[b+1]) + zi
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad3(p.perm[aa], x, y, z), grad3(p.perm[ba], x-1, y, z)),
+			lerp(u, grad3(p.perm[ab], x, y-1, z), grad3(p.perm[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad3(p.perm[aa+1], x, y, z-1), grad3(p.perm[ba+1], x-1, y, z-1)),
+			lerp(u, grad3(p.perm[ab+1], x, y-1, z-1), grad3(p.perm[bb+1], x-1, y-1, z-1))))
+}
+
+// fade returns the value of the quintic interpolation curve 6t^5-15t^4+10t^3,
+// whose first and second derivatives are zero at 0 and 1
+func fade(t float32) float32 {
+
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp returns the linear interpolation from a to b at t
+func lerp(t, a, b float32) float32 {
+
+	return a + t*(b-a)
+}
+
+// grad1 returns the dot product of the offset with
+// one of 16 gradients from -8 to 8 selected by the hash
+func grad1(hash uint8, x float32) float32 {
+
+	g := float32(hash&7) + 1
+	if hash&8 != 0 {
+		g = -g
+	}
+	return g * x
+}
+
+// grad2 returns the dot product of the offset
+// with one of 8 gradients selected by the hash
+func grad2(hash uint8, x, y float32) float32 {
+
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// grad3 returns the dot product of the offset with one of the 12 gradients
+// to the edges of a cube selected by the hash
+func grad3(hash uint8, x, y, z float32) float32 {
+
+	h := hash & 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+// Simplex is the simplex gradient noise, which sums the contributions of
+// the random gradients at the corners of the simplex (interval, triangle or
+// tetrahedron) containing the point. It is faster than Perlin noise in three
+// dimensions and has no visible alignment with the axes.
+type Simplex struct {
+	perm [512]uint8 // permutation used to hash the lattice points
+}
+
+// Skewing factors of the simplex grids
+const (
+	skew2   = 0.36602540378 // (sqrt(3)-1)/2
+	unskew2 = 0.2113248654  // (3-sqrt(3))/6
+	skew3   = 1.0 / 3
+	unskew3 = 1.0 / 6
+)
+
+// grads3 are the gradients to the edges of a cube
+var grads3 = [12][3]float32{
+	{1, 1, 0}, {-1, 1, 0}, {1, -1, 0}, {-1, -1, 0},
+	{1, 0, 1}, {-1, 0, 1}, {1, 0, -1}, {-1, 0, -1},
+	{0, 1, 1}, {0, -1, 1}, {0, 1, -1}, {0, -1, -1},
+}
+
+// NewSimplex creates and returns a pointer to a new simplex noise
+// with the specified seed
+func NewSimplex(seed int64) *Simplex {
+
+	s := new(Simplex)
+	s.perm = permutation(seed)
+	return s
+}
+
+// Noise1 returns the value of the noise at the specified coordinate
+func (s *Simplex) Noise1(x float32) float32 {
+
+	i0 := floor(x)
+	x0 := x - float32(i0)
+	x1 := x0 - 1
+	i0 &= 255
+	n := float32(0)
+	if t := 1 - x0*x0; t > 0 {
+		t *= t
+		n += t * t * grad1(s.perm[i0], x0)
+	}
+	if t := 1 - x1*x1; t > 0 {
+		t *= t
+		n += t * t * grad1(s.perm[i0+1], x1)
+	}
+	return n * 0.395
+}
+
+// Noise2 returns the value of the noise at the specified coordinates
+func (s *Simplex) Noise2(x, y float32) float32 {
+
+	// Cell of the skewed grid and offset from its origin
+	sk := (x + y) * skew2
+	i := floor(x + sk)
+	j := floor(y + sk)
+	t := float32(i+j) * unskew2
+	x0 := x - (float32(i) - t)
+	y0 := y - (float32(j) - t)
+
+	// Middle corner of the triangle containing the point
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+	x1 := x0 - float32(i1) + unskew2
+	y1 := y0 - float32(j1) + unskew2
+	x2 := x0 - 1 + 2*unskew2
+	y2 := y0 - 1 + 2*unskew2
+
+	ii := i & 255
+	jj := j & 255
+	n := corner2(s.perm[ii+int(s.perm[jj])], x0, y0)
+	n += corner2(s.perm[ii+i1+int(s.perm[jj+j1])], x1, y1)
+	n += corner2(s.perm[ii+1+int(s.perm[jj+1])], x2, y2)
+	return 70 * n
+}
+
+// Noise3 returns the value of the noise at the specified coordinates
+func (s *Simplex) Noise3(x, y, z float32) float32 {
+
+	// Cell of the skewed grid and offset from its origin
+	sk := (x + y + z) * skew3
+	i := floor(x + sk)
+	j := floor(y + sk)
+	k := floor(z + sk)
+	t := float32(i+j+k) * unskew3
+	x0 := x - (float32(i) - t)
+	y0 := y - (float32(j) - t)
+	z0 := z - (float32(k) - t)
+
+	// Second and third corners of the tetrahedron containing the point
+	var i1, j1, k1, i2, j2, k2 int
+	if x0 >= y0 {
+		switch {
+		case y0 >= z0:
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+		case x0 >= z0:
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+		default:
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+		}
+	} else {
+		switch {
+		case y0 < z0:
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+		case x0 < z0:
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+		default:
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+		}
+	}
+	x1 := x0 - float32(i1) + unskew3
+	y1 := y0 - float32(j1) + unskew3
+	z1 := z0 - float32(k1) + unskew3
+	x2 := x0 - float32(i2) + 2*unskew3
+	y2 := y0 - float32(j2) + 2*unskew3
+	z2 := z0 - float32(k2) + 2*unskew3
+	x3 := x0 - 1 + 3*unskew3
+	y3 := y0 - 1 + 3*unskew3
+	z3 := z0 - 1 + 3*unskew3
+
+	ii := i & 255
+	jj := j & 255
+	kk := k & 255
+	p := &s.perm
+	n := corner3(p[ii+int(p[jj+int(p[kk])])], x0, y0, z0)
+	n += corner3(p[ii+i1+int(p[jj+j1+int(p[kk+k1])])], x1, y1, z1)
+	n += corner3(p[ii+i2+int(p[jj+j2+int(p[kk+k2])])], x2, y2, z2)
+	n += corner3(p[ii+1+int(p[jj+1+int(p[kk+1])])], x3, y3, z3)
+	return 32 * n
+}
+
+// corner2 returns the contribution of the gradient selected by
+// the hash at a corner of a triangle at the specified offset
+func corner2(hash uint8, x, y float32) float32 {
+
+	t := 0.5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+	g := &grads3[hash%12]
+	t *= t
+	return t * t * (g[0]*x + g[1]*y)
+}
+
+// corner3 returns the contribution of the gradient selected by
+// the hash at a corner of a tetrahedron at the specified offset
+func corner3(hash uint8, x, y, z float32) float32 {
+
+	t := 0.6 - x*x - y*y - z*z
+	if t < 0 {
+		return 0
+	}
+	g := &grads3[hash%12]
+	t *= t
+	return t * t * (g[0]*x + g[1]*y + g[2]*z)
+}
@@ -0,0 +1,195 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Worley is the cellular noise whose values are the distances from the
+// point to the nearest random feature points, one in each integer lattice
+// cell, which form Voronoi cells as used for stones, scales and cracks
+type Worley struct {
+	seed     uint32
+	Metric   Metric      // distance function (default Euclidean)
+	Function WorleyValue // value returned by the Noise methods (default F1)
+}
+
+// Metric is the distance function of the Worley noise
+type Metric int
+
+// Worley noise distance functions
+const (
+	Euclidean Metric = iota // straight line distance, round cells
+	Manhattan               // sum of the distances along the axes, diamond cells
+	Chebyshev               // maximum distance along the axes, square cells
+)
+
+// WorleyValue is the value returned by the Noise methods of the Worley noise
+type WorleyValue int
+
+// Worley noise values
+const (
+	F1        WorleyValue = iota // distance to the nearest feature point
+	F2                           // distance to the second nearest feature point
+	F2MinusF1                    // difference of the distances, which is zero at the cell borders
+)
+
+// NewWorley creates and returns a pointer to a new Worley noise
+// with the specified seed
+func NewWorley(seed int64) *Worley {
+
+	w := new(Worley)
+	w.seed = uint32(seed) ^ uint32(seed>>32)
+	return w
+}
+
+// Noise1 returns the value of the noise at the specified coordinate,
+// as selected by the Function field, mapped from 0 to 1 to -1 to 1
+func (w *Worley) Noise1(x float32) float32 {
+
+	f1, f2 := w.Cells1(x)
+	return w.value(f1, f2)
+}
+
+// Noise2 returns the value of the noise at the specified coordinates,
+// as selected by the Function field, mapped from 0 to 1 to -1 to 1
+func (w *Worley) Noise2(x, y float32) float32 {
+
+	f1, f2 := w.Cells2(x, y)
+	return w.value(f1, f2)
+}
+
+// Noise3 returns the value of the noise at the specified coordinates,
+// as selected by the Function field, mapped from 0 to 1 to -1 to 1
+func (w *Worley) Noise3(x, y, z float32) float32 {
+
+	f1, f2 := w.Cells3(x, y, z)
+	return w.value(f1, f2)
+}
+
+// Cells1 returns the distances from the specified coordinate
+// to the nearest and second nearest feature points
+func (w *Worley) Cells1(x float32) (f1, f2 float32) {
+
+	xi := floor(x)
+	f1, f2 = math32.Inf(1), math32.Inf(1)
+	for i := xi - 1; i <= xi+1; i++ {
+		h := w.hash(i, 0, 0)
+		d := math32.Abs(float32(i) + unit(h) - x)
+		f1, f2 = nearest(d, f1, f2)
+	}
+	return f1, f2
+}
+
+// Cells2 returns the distances from the specified coordinates
+// to the nearest and second nearest feature points
+func (w *Worley) Cells2(x, y float32) (f1, f2 float32) {
+
+	xi := floor(x)
+	yi := floor(y)
+	f1, f2 = math32.Inf(1), math32.Inf(1)
+	for j := yi - 1; j <= yi+1; j++ {
+		for i := xi - 1; i <= xi+1; i++ {
+			h := w.hash(i, j, 0)
+			dx := float32(i) + unit(h) - x
+			h = mix(h)
+			dy := float32(j) + unit(h) - y
+			f1, f2 = nearest(w.distance(dx, dy, 0), f1, f2)
+		}
+	}
+	return f1, f2
+}
+
+// Cells3 returns the distances from the specified coordinates
+// to the nearest and second nearest feature points
+func (w *Worley) Cells3(x, y, z float32) (f1, f2 float32) {
+
+	xi := floor(x)
+	yi := floor(y)
+	zi := floor(z)
+	f1, f2 = math32.Inf(1), math32.Inf(1)
+	for k := zi - 1; k <= zi+1; k++ {
+		for j := yi - 1; j <= yi+1; j++ {
+			for i := xi - 1; i <= xi+1; i++ {
+				h := w.hash(i, j, k)
+				dx := float32(i) + unit(h) - x
+				h = mix(h)
+				dy := float32(j) + unit(h) - y
+				h = mix(h)
+				dz := float32(k) + unit(h) - z
+				f1, f2 = nearest(w.distance(dx, dy, dz), f1, f2)
+			}
+		}
+	}
+	return f1, f2
+}
+
+// value returns the noise value selected by the Function field
+// for the specified distances, mapped to -1 to 1
+func (w *Worley) value(f1, f2 float32) float32 {
+
+	var v float32
+	switch w.Function {
+	case F2:
+		v = f2
+	case F2MinusF1:
+		v = f2 - f1
+	default:
+		v = f1
+	}
+	return math32.Clamp(v, 0, 1)*2 - 1
+}
+
+// distance returns the distance for the specified offset
+// using the metric of this noise
+func (w *Worley) distance(dx, dy, dz float32) float32 {
+
+	switch w.Metric {
+	case Manhattan:
+		return math32.Abs(dx) + math32.Abs(dy) + math32.Abs(dz)
+	case Chebyshev:
+		return math32.Max(math32.Abs(dx), math32.Max(math32.Abs(dy), math32.Abs(dz)))
+	default:
+		return math32.Sqrt(dx*dx + dy*dy + dz*dz)
+	}
+}
+
+// hash returns the hash of the specified lattice cell for the seed of this noise
+func (w *Worley) hash(i, j, k int) uint32 {
+
+	h := w.seed ^ uint32(i)*0x8da6b343 ^ uint32(j)*0xd8163841 ^ uint32(k)*0xcb1ab31f
+	return mix(h)
+}
+
+// mix returns the specified hash with its bits mixed
+func mix(h uint32) uint32 {
+
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+	h *= 0x846ca68b
+	h ^= h >> 16
+	return h
+}
+
+// unit returns the specified hash mapped to a number from 0 to 1
+func unit(h uint32) float32 {
+
+	return float32(h>>8) / (1 << 24)
+}
+
+// nearest returns the two smallest of the specified distance
+// and the smallest and second smallest distances
+func nearest(d, f1, f2 float32) (float32, float32) {
+
+	if d < f1 {
+		return d, f1
+	}
+	if d < f2 {
+		return f1, d
+	}
+	return f1, f2
+}
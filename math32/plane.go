@@ -76,6 +76,19 @@ func (this *Plane) Negate() *Plane {
 	return this
 }
 
+// Normal returns a copy of the normal of this plane
+func (this *Plane) Normal() Vector3 {
+
+	return this.normal
+}
+
+// Constant returns the constant of this plane, which is the negated
+// distance from the origin to the plane along its normal
+func (this *Plane) Constant() float32 {
+
+	return this.constant
+}
+
 func (this *Plane) DistanceToPoint(point *Vector3) float32 {
 
 	return this.normal.Dot(point) + this.constant
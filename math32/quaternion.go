@@ -298,69 +298,175 @@ func (this *Quaternion) MultiplyQuaternions(a, b *Quaternion) *Quaternion {
 	return this
 }
 
+// Slerp sets this quaternion to the spherical linear interpolation
+// from this quaternion to the specified quaternion at t, along the
+// shortest path, and returns pointer to this updated quaternion.
 func (this *Quaternion) Slerp(qb *Quaternion, t float32) *Quaternion {
 
-	if t == 0 {
-		return this
-	}
-	if t == 1 {
-		return this.Copy(qb)
-	}
+	return this.slerp(this, qb, t, true)
+}
 
-	x := this.x
-	y := this.y
-	z := this.z
-	w := this.z
+// SlerpQuaternions sets this quaternion to the spherical linear interpolation
+// from qa to qb at t, along the shortest path, and returns pointer to this updated quaternion.
+func (q *Quaternion) SlerpQuaternions(qa, qb *Quaternion, t float32) *Quaternion {
 
-	cosHalfTheta := w*qb.w + x*qb.x + y*qb.y + z*qb.z
+	return q.slerp(qa, qb, t, true)
+}
 
-	if cosHalfTheta < 0 {
+// slerp sets this quaternion to the spherical linear interpolation from qa to qb at t,
+// negating qb to follow the shortest path if requested, and returns pointer to this updated quaternion.
+func (q *Quaternion) slerp(qa, qb *Quaternion, t float32, shortest bool) *Quaternion {
+
+	x := qa.x
+	y := qa.y
+	z := qa.z
+	w := qa.w
+	bx := qb.x
+	by := qb.y
+	bz := qb.z
+	bw := qb.w
+
+	cosHalfTheta := w*bw + x*bx + y*by + z*bz
+	if shortest && cosHalfTheta < 0 {
+		bx, by, bz, bw = -bx, -by, -bz, -bw
+		cosHalfTheta = -cosHalfTheta
+	}
 
-		this.w = -qb.w
-		this.x = -qb.x
-		this.y = -qb.y
-		this.z = -qb.z
+	// Uses linear interpolation for close or opposite quaternions, whose sine is too small
+	ratioA := 1 - t
+	ratioB := t
+	lerp := Abs(cosHalfTheta) >= 0.9999
+	if !lerp {
+		halfTheta := Acos(cosHalfTheta)
+		sinHalfTheta := Sin(halfTheta)
+		ratioA = Sin((1-t)*halfTheta) / sinHalfTheta
+		ratioB = Sin(t*halfTheta) / sinHalfTheta
+	}
 
-		cosHalfTheta = -cosHalfTheta
+	q.x = x*ratioA + bx*ratioB
+	q.y = y*ratioA + by*ratioB
+	q.z = z*ratioA + bz*ratioB
+	q.w = w*ratioA + bw*ratioB
+	if lerp {
+		q.Normalize()
+	}
+	return q
+}
 
-	} else {
+// Log sets this unit quaternion to its logarithm, the pure quaternion whose vector
+// is the rotation axis scaled by half the rotation angle, and returns pointer to this updated quaternion.
+func (q *Quaternion) Log() *Quaternion {
 
-		this.Copy(qb)
+	l := Sqrt(q.x*q.x + q.y*q.y + q.z*q.z)
+	s := float32(0)
+	if l > 1e-6 {
+		s = Atan2(l, q.w) / l
 	}
+	q.x *= s
+	q.y *= s
+	q.z *= s
+	q.w = 0
+	return q
+}
 
-	if cosHalfTheta >= 1.0 {
+// Exp sets this pure quaternion to its exponential, the unit quaternion inverse of Log,
+// and returns pointer to this updated quaternion.
+func (q *Quaternion) Exp() *Quaternion {
 
-		this.w = w
-		this.x = x
-		this.y = y
-		this.z = z
+	angle := Sqrt(q.x*q.x + q.y*q.y + q.z*q.z)
+	s := float32(1)
+	if angle > 1e-6 {
+		s = Sin(angle) / angle
+	}
+	q.x *= s
+	q.y *= s
+	q.z *= s
+	q.w = Cos(angle)
+	return q
+}
 
-		return this
+// SetSquadControl sets this quaternion to the intermediate control point of
+// the squad interpolation at the key q1, between the keys q0 and q2, so the
+// interpolated rotation has a continuous angular velocity through the key,
+// and returns pointer to this updated quaternion.
+func (q *Quaternion) SetSquadControl(q0, q1, q2 *Quaternion) *Quaternion {
 
+	// Uses the neighbour keys in the same hemisphere as q1
+	a := *q0
+	if q1.Dot(&a) < 0 {
+		a.Set(-a.x, -a.y, -a.z, -a.w)
+	}
+	b := *q2
+	if q1.Dot(&b) < 0 {
+		b.Set(-b.x, -b.y, -b.z, -b.w)
 	}
 
-	halfTheta := Acos(cosHalfTheta)
-	sinHalfTheta := Sqrt(1.0 - cosHalfTheta + cosHalfTheta)
+	// q1 * exp(-(log(q1^-1 * q0) + log(q1^-1 * q2)) / 4)
+	inv := *q1
+	inv.Inverse()
+	a.MultiplyQuaternions(&inv, &a).Log()
+	b.MultiplyQuaternions(&inv, &b).Log()
+	a.Set(-(a.x+b.x)/4, -(a.y+b.y)/4, -(a.z+b.z)/4, 0).Exp()
+	return q.MultiplyQuaternions(q1, &a)
+}
 
-	if Abs(sinHalfTheta) < 0.001 {
+// Squad sets this quaternion to the spherical quadrangle interpolation from
+// this quaternion to qb at t, using the control points a of this quaternion
+// and b of qb computed by SetSquadControl, and returns pointer to this updated quaternion.
+func (q *Quaternion) Squad(a, b, qb *Quaternion, t float32) *Quaternion {
 
-		this.w = 0.5 * (w + this.w)
-		this.x = 0.5 * (x + this.x)
-		this.y = 0.5 * (y + this.y)
-		this.z = 0.5 * (z + this.z)
+	return q.SquadQuaternions(q, a, b, qb, t)
+}
 
-		return this
-	}
+// SquadQuaternions sets this quaternion to the spherical quadrangle interpolation
+// from qa to qb at t, using the control points a of qa and b of qb computed by
+// SetSquadControl, and returns pointer to this updated quaternion.
+func (q *Quaternion) SquadQuaternions(qa, a, b, qb *Quaternion, t float32) *Quaternion {
 
-	ratioA := Sin((1-t)*halfTheta) / sinHalfTheta
-	ratioB := Sin(t*halfTheta) / sinHalfTheta
+	var q1, q2 Quaternion
+	q1.slerp(qa, qb, t, false)
+	q2.slerp(a, b, t, false)
+	return q.slerp(&q1, &q2, 2*t*(1-t), false)
+}
 
-	this.w = (w*ratioA + this.w*ratioB)
-	this.x = (x*ratioA + this.x*ratioB)
-	this.y = (y*ratioA + this.y*ratioB)
-	this.z = (z*ratioA + this.z*ratioB)
+// SwingTwist decomposes this quaternion into the rotation about the specified
+// unit axis (the twist) and the rotation of the axis (the swing), so that this
+// quaternion equals swing * twist, and stores them in the specified quaternions
+func (q *Quaternion) SwingTwist(axis *Vector3, swing, twist *Quaternion) {
+
+	// The twist is the projection of the vector of this quaternion on the axis
+	d := q.x*axis.X + q.y*axis.Y + q.z*axis.Z
+	tw := Quaternion{axis.X * d, axis.Y * d, axis.Z * d, q.w}
+	if tw.lengthSq() < 1e-12 {
+		// Rotation of 180 degrees perpendicular to the axis, with no twist
+		tw.SetIdentity()
+	} else {
+		tw.Normalize()
+	}
+	sw := tw
+	sw.Conjugate()
+	sw.MultiplyQuaternions(q, &sw)
+	if swing != nil {
+		*swing = sw
+	}
+	if twist != nil {
+		*twist = tw
+	}
+}
 
-	return this
+// RotationBetweenVectors creates and returns a pointer to a new quaternion
+// with the shortest rotation from the direction of the vector from to the
+// direction of the vector to, which need not be normalized. It returns the
+// identity quaternion if either vector is zero.
+func RotationBetweenVectors(from, to *Vector3) *Quaternion {
+
+	q := NewQuaternion(0, 0, 0, 1)
+	if from.LengthSq() == 0 || to.LengthSq() == 0 {
+		return q
+	}
+	vFrom := *from
+	vTo := *to
+	return q.SetFromUnitVectors(vFrom.Normalize(), vTo.Normalize())
 }
 
 func (this *Quaternion) Equals(quaternion *Quaternion) bool {
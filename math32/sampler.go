@@ -0,0 +1,181 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+)
+
+// Sampler generates uniformly distributed random numbers, directions and points
+// on common domains, as used by particle emitters, ambient occlusion kernels and
+// light sampling. The samplers created with the same seed generate the same
+// sequence of samples. A Sampler is not safe for concurrent use.
+type Sampler struct {
+	rnd *rand.Rand
+}
+
+// NewSampler creates and returns a pointer to a new sampler with the specified seed
+func NewSampler(seed int64) *Sampler {
+
+	s := new(Sampler)
+	s.rnd = rand.New(rand.NewSource(seed))
+	return s
+}
+
+// Seed resets the sequence of this sampler to the one of the specified seed
+func (s *Sampler) Seed(seed int64) {
+
+	s.rnd.Seed(seed)
+}
+
+// Float32 returns a random number in the interval [0,1)
+func (s *Sampler) Float32() float32 {
+
+	return s.rnd.Float32()
+}
+
+// Range returns a random number in the interval [min,max)
+func (s *Sampler) Range(min, max float32) float32 {
+
+	return min + (max-min)*s.rnd.Float32()
+}
+
+// Disk returns a random point uniformly distributed in the disk of unit radius
+func (s *Sampler) Disk(optionalTarget *Vector2) *Vector2 {
+
+	result := sampleTarget2(optionalTarget)
+	r := Sqrt(s.rnd.Float32())
+	phi := 2 * Pi * s.rnd.Float32()
+	return result.Set(r*Cos(phi), r*Sin(phi))
+}
+
+// UnitSphere returns a random direction uniformly distributed on the sphere of unit radius
+func (s *Sampler) UnitSphere(optionalTarget *Vector3) *Vector3 {
+
+	result := sampleTarget3(optionalTarget)
+	z := 1 - 2*s.rnd.Float32()
+	r := Sqrt(Max(0, 1-z*z))
+	phi := 2 * Pi * s.rnd.Float32()
+	return result.Set(r*Cos(phi), r*Sin(phi), z)
+}
+
+// InSphere returns a random point uniformly distributed in the ball of unit radius
+func (s *Sampler) InSphere(optionalTarget *Vector3) *Vector3 {
+
+	result := s.UnitSphere(optionalTarget)
+	return result.MultiplyScalar(Pow(s.rnd.Float32(), 1.0/3))
+}
+
+// Hemisphere returns a random direction uniformly distributed on the
+// hemisphere of unit radius around the specified unit normal
+func (s *Sampler) Hemisphere(normal *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	result := sampleTarget3(optionalTarget)
+	z := s.rnd.Float32()
+	r := Sqrt(Max(0, 1-z*z))
+	phi := 2 * Pi * s.rnd.Float32()
+	return alignToNormal(result.Set(r*Cos(phi), r*Sin(phi), z), normal)
+}
+
+// CosineHemisphere returns a random direction on the hemisphere of unit radius
+// around the specified unit normal, distributed proportionally to the cosine of
+// its angle with the normal, as used to sample diffuse lighting
+func (s *Sampler) CosineHemisphere(normal *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	result := sampleTarget3(optionalTarget)
+	u := s.rnd.Float32()
+	r := Sqrt(u)
+	phi := 2 * Pi * s.rnd.Float32()
+	return alignToNormal(result.Set(r*Cos(phi), r*Sin(phi), Sqrt(1-u)), normal)
+}
+
+// Cone returns a random direction uniformly distributed in the cone around
+// the specified unit direction with the specified half angle in radians
+func (s *Sampler) Cone(direction *Vector3, angle float32, optionalTarget *Vector3) *Vector3 {
+
+	result := sampleTarget3(optionalTarget)
+	z := 1 - s.rnd.Float32()*(1-Cos(angle))
+	r := Sqrt(Max(0, 1-z*z))
+	phi := 2 * Pi * s.rnd.Float32()
+	return alignToNormal(result.Set(r*Cos(phi), r*Sin(phi), z), direction)
+}
+
+// Barycentric returns random barycentric coordinates, whose components are
+// positive and sum to 1, uniformly distributed over a triangle
+func (s *Sampler) Barycentric(optionalTarget *Vector3) *Vector3 {
+
+	result := sampleTarget3(optionalTarget)
+	u := s.rnd.Float32()
+	v := s.rnd.Float32()
+	if u+v > 1 {
+		u = 1 - u
+		v = 1 - v
+	}
+	return result.Set(1-u-v, u, v)
+}
+
+// Triangle returns a random point uniformly distributed
+// in the triangle with the specified vertices
+func (s *Sampler) Triangle(a, b, c *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	var bary Vector3
+	s.Barycentric(&bary)
+	result := sampleTarget3(optionalTarget)
+	return result.Set(
+		a.X*bary.X+b.X*bary.Y+c.X*bary.Z,
+		a.Y*bary.X+b.Y*bary.Y+c.Y*bary.Z,
+		a.Z*bary.X+b.Z*bary.Y+c.Z*bary.Z,
+	)
+}
+
+// Box returns a random point uniformly distributed in the specified box
+func (s *Sampler) Box(box *Box3, optionalTarget *Vector3) *Vector3 {
+
+	result := sampleTarget3(optionalTarget)
+	return result.Set(
+		s.Range(box.Min.X, box.Max.X),
+		s.Range(box.Min.Y, box.Max.Y),
+		s.Range(box.Min.Z, box.Max.Z),
+	)
+}
+
+// alignToNormal transforms the specified vector from the space whose Z axis is
+// the specified unit normal to the world space, in place, and returns it
+func alignToNormal(v, normal *Vector3) *Vector3 {
+
+	// Orthonormal basis from the normal without branching on its direction
+	// (Duff et al, Building an Orthonormal Basis, Revisited)
+	sign := float32(1)
+	if normal.Z < 0 {
+		sign = -1
+	}
+	a := -1 / (sign + normal.Z)
+	b := normal.X * normal.Y * a
+	tx, ty, tz := 1+sign*normal.X*normal.X*a, sign*b, -sign*normal.X
+	bx, by, bz := b, sign+normal.Y*normal.Y*a, -normal.Y
+	return v.Set(
+		v.X*tx+v.Y*bx+v.Z*normal.X,
+		v.X*ty+v.Y*by+v.Z*normal.Y,
+		v.X*tz+v.Y*bz+v.Z*normal.Z,
+	)
+}
+
+// sampleTarget2 returns the specified target or a new vector if it is nil
+func sampleTarget2(optionalTarget *Vector2) *Vector2 {
+
+	if optionalTarget != nil {
+		return optionalTarget
+	}
+	return NewVector2(0, 0)
+}
+
+// sampleTarget3 returns the specified target or a new vector if it is nil
+func sampleTarget3(optionalTarget *Vector3) *Vector3 {
+
+	if optionalTarget != nil {
+		return optionalTarget
+	}
+	return NewVector3(0, 0, 0)
+}
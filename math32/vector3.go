@@ -71,6 +71,22 @@ func (v *Vector3) SetComponent(index int, value float32) {
 	}
 }
 
+// Component returns the value of this vector component
+// specified by its index: X=0, Y=1, Z=2
+func (v *Vector3) Component(index int) float32 {
+
+	switch index {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	case 2:
+		return v.Z
+	default:
+		panic("index is out of range")
+	}
+}
+
 // SetByName sets the value of this vector component
 // specified by its name: "x|Z", "y|Y", or "z|Z".
 func (v *Vector3) SetByName(name string, value float32) {
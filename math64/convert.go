@@ -0,0 +1,61 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// The conversions from math32 are exact. The conversions to math32 round the
+// components to the nearest float32, so the positions far from the origin should
+// be converted relative to the camera with RelativeTo32 or a FloatingOrigin.
+
+// SetFrom32 sets this vector from the specified float32 vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) SetFrom32(src *math32.Vector3) *Vector3 {
+
+	return v.Set(float64(src.X), float64(src.Y), float64(src.Z))
+}
+
+// To32 returns this vector converted to float32
+func (v *Vector3) To32() math32.Vector3 {
+
+	return math32.Vector3{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z)}
+}
+
+// SetFrom32 sets this quaternion from the specified float32 quaternion.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) SetFrom32(src *math32.Quaternion) *Quaternion {
+
+	return q.Set(float64(src.X()), float64(src.Y()), float64(src.Z()), float64(src.W()))
+}
+
+// To32 returns this quaternion converted to float32
+func (q *Quaternion) To32() math32.Quaternion {
+
+	var r math32.Quaternion
+	r.Set(float32(q.x), float32(q.y), float32(q.z), float32(q.w))
+	return r
+}
+
+// SetFrom32 sets this matrix from the specified float32 matrix.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) SetFrom32(src *math32.Matrix4) *Matrix4 {
+
+	for i := range src {
+		m[i] = float64(src[i])
+	}
+	return m
+}
+
+// To32 returns this matrix converted to float32
+func (m *Matrix4) To32() math32.Matrix4 {
+
+	var r math32.Matrix4
+	for i := range m {
+		r[i] = float32(m[i])
+	}
+	return r
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package math64 mirrors the vectors, quaternions and matrices of the math32
+// package in double precision, for the positions of large worlds, such as
+// planets or geographic data, whose float32 coordinates far from the origin
+// are too coarse and make the rendered objects jitter.
+//
+// The world positions are kept in float64 and converted to float32 relative
+// to the camera (camera-relative rendering) just before rendering, so the
+// coordinates near the camera, where the precision matters, remain small.
+package math64
+
+import (
+	"math"
+)
+
+const Pi = math.Pi
+
+// DegToRad converts a number from degrees to radians
+func DegToRad(degrees float64) float64 {
+
+	return degrees * Pi / 180
+}
+
+// RadToDeg converts a number from radians to degrees
+func RadToDeg(radians float64) float64 {
+
+	return radians * 180 / Pi
+}
+
+// Clamp clamps x to the interval from a to b
+func Clamp(x, a, b float64) float64 {
+
+	if x < a {
+		return a
+	}
+	if x > b {
+		return b
+	}
+	return x
+}
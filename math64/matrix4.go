@@ -0,0 +1,281 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"math"
+)
+
+// Matrix4 is a 4x4 matrix in double precision
+// stored by columns, as math32.Matrix4
+type Matrix4 [16]float64
+
+// NewMatrix4 creates and returns a pointer to a new identity matrix
+func NewMatrix4() *Matrix4 {
+
+	var m Matrix4
+	return m.Identity()
+}
+
+// Set sets the elements of this matrix, specified by rows.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Set(n11, n12, n13, n14, n21, n22, n23, n24, n31, n32, n33, n34, n41, n42, n43, n44 float64) *Matrix4 {
+
+	m[0], m[4], m[8], m[12] = n11, n12, n13, n14
+	m[1], m[5], m[9], m[13] = n21, n22, n23, n24
+	m[2], m[6], m[10], m[14] = n31, n32, n33, n34
+	m[3], m[7], m[11], m[15] = n41, n42, n43, n44
+	return m
+}
+
+// Identity sets this matrix to the identity matrix.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Identity() *Matrix4 {
+
+	*m = Matrix4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+	return m
+}
+
+// Copy copies the specified matrix into this one.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Copy(src *Matrix4) *Matrix4 {
+
+	*m = *src
+	return m
+}
+
+// SetPosition sets the translation of this matrix.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) SetPosition(v *Vector3) *Matrix4 {
+
+	m[12] = v.X
+	m[13] = v.Y
+	m[14] = v.Z
+	return m
+}
+
+// Position returns the translation of this matrix
+func (m *Matrix4) Position() Vector3 {
+
+	return Vector3{m[12], m[13], m[14]}
+}
+
+// MakeTranslation sets this matrix to the specified translation.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) MakeTranslation(x, y, z float64) *Matrix4 {
+
+	return m.Set(
+		1, 0, 0, x,
+		0, 1, 0, y,
+		0, 0, 1, z,
+		0, 0, 0, 1,
+	)
+}
+
+// MakeScale sets this matrix to the specified scale.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) MakeScale(x, y, z float64) *Matrix4 {
+
+	return m.Set(
+		x, 0, 0, 0,
+		0, y, 0, 0,
+		0, 0, z, 0,
+		0, 0, 0, 1,
+	)
+}
+
+// MakeRotationFromQuaternion sets this matrix to the rotation of the specified quaternion.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) MakeRotationFromQuaternion(q *Quaternion) *Matrix4 {
+
+	x2 := q.x + q.x
+	y2 := q.y + q.y
+	z2 := q.z + q.z
+	xx := q.x * x2
+	xy := q.x * y2
+	xz := q.x * z2
+	yy := q.y * y2
+	yz := q.y * z2
+	zz := q.z * z2
+	wx := q.w * x2
+	wy := q.w * y2
+	wz := q.w * z2
+
+	return m.Set(
+		1-(yy+zz), xy-wz, xz+wy, 0,
+		xy+wz, 1-(xx+zz), yz-wx, 0,
+		xz-wy, yz+wx, 1-(xx+yy), 0,
+		0, 0, 0, 1,
+	)
+}
+
+// Scale multiplies the first, second and third columns of this matrix
+// by the X, Y and Z components of the specified vector.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Scale(v *Vector3) *Matrix4 {
+
+	for i := 0; i < 4; i++ {
+		m[i] *= v.X
+		m[4+i] *= v.Y
+		m[8+i] *= v.Z
+	}
+	return m
+}
+
+// Multiply multiplies this matrix by the specified one.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Multiply(other *Matrix4) *Matrix4 {
+
+	return m.MultiplyMatrices(m, other)
+}
+
+// MultiplyMatrices sets this matrix to the product of a and b.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) MultiplyMatrices(a, b *Matrix4) *Matrix4 {
+
+	var r Matrix4
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			r[col*4+row] = a[row]*b[col*4] + a[4+row]*b[col*4+1] + a[8+row]*b[col*4+2] + a[12+row]*b[col*4+3]
+		}
+	}
+	*m = r
+	return m
+}
+
+// Determinant returns the determinant of this matrix
+func (m *Matrix4) Determinant() float64 {
+
+	n11, n12, n13, n14 := m[0], m[4], m[8], m[12]
+	n21, n22, n23, n24 := m[1], m[5], m[9], m[13]
+	n31, n32, n33, n34 := m[2], m[6], m[10], m[14]
+	n41, n42, n43, n44 := m[3], m[7], m[11], m[15]
+
+	return n41*(+n14*n23*n32-n13*n24*n32-n14*n22*n33+n12*n24*n33+n13*n22*n34-n12*n23*n34) +
+		n42*(+n11*n23*n34-n11*n24*n33+n14*n21*n33-n13*n21*n34+n13*n24*n31-n14*n23*n31) +
+		n43*(+n11*n24*n32-n11*n22*n34-n14*n21*n32+n12*n21*n34+n14*n22*n31-n12*n24*n31) +
+		n44*(-n13*n22*n31-n11*n23*n32+n11*n22*n33+n13*n21*n32-n12*n21*n33+n12*n23*n31)
+}
+
+// GetInverse sets this matrix to the inverse of the specified matrix.
+// If the matrix is not invertible it panics if throwOnInvertible is true
+// or sets this matrix to the identity.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) GetInverse(src *Matrix4, throwOnInvertible bool) *Matrix4 {
+
+	n11, n12, n13, n14 := src[0], src[4], src[8], src[12]
+	n21, n22, n23, n24 := src[1], src[5], src[9], src[13]
+	n31, n32, n33, n34 := src[2], src[6], src[10], src[14]
+	n41, n42, n43, n44 := src[3], src[7], src[11], src[15]
+
+	var r Matrix4
+	r[0] = n23*n34*n42 - n24*n33*n42 + n24*n32*n43 - n22*n34*n43 - n23*n32*n44 + n22*n33*n44
+	r[4] = n14*n33*n42 - n13*n34*n42 - n14*n32*n43 + n12*n34*n43 + n13*n32*n44 - n12*n33*n44
+	r[8] = n13*n24*n42 - n14*n23*n42 + n14*n22*n43 - n12*n24*n43 - n13*n22*n44 + n12*n23*n44
+	r[12] = n14*n23*n32 - n13*n24*n32 - n14*n22*n33 + n12*n24*n33 + n13*n22*n34 - n12*n23*n34
+	r[1] = n24*n33*n41 - n23*n34*n41 - n24*n31*n43 + n21*n34*n43 + n23*n31*n44 - n21*n33*n44
+	r[5] = n13*n34*n41 - n14*n33*n41 + n14*n31*n43 - n11*n34*n43 - n13*n31*n44 + n11*n33*n44
+	r[9] = n14*n23*n41 - n13*n24*n41 - n14*n21*n43 + n11*n24*n43 + n13*n21*n44 - n11*n23*n44
+	r[13] = n13*n24*n31 - n14*n23*n31 + n14*n21*n33 - n11*n24*n33 - n13*n21*n34 + n11*n23*n34
+	r[2] = n22*n34*n41 - n24*n32*n41 + n24*n31*n42 - n21*n34*n42 - n22*n31*n44 + n21*n32*n44
+	r[6] = n14*n32*n41 - n12*n34*n41 - n14*n31*n42 + n11*n34*n42 + n12*n31*n44 - n11*n32*n44
+	r[10] = n12*n24*n41 - n14*n22*n41 + n14*n21*n42 - n11*n24*n42 - n12*n21*n44 + n11*n22*n44
+	r[14] = n14*n22*n31 - n12*n24*n31 - n14*n21*n32 + n11*n24*n32 + n12*n21*n34 - n11*n22*n34
+	r[3] = n23*n32*n41 - n22*n33*n41 - n23*n31*n42 + n21*n33*n42 + n22*n31*n43 - n21*n32*n43
+	r[7] = n12*n33*n41 - n13*n32*n41 + n13*n31*n42 - n11*n33*n42 - n12*n31*n43 + n11*n32*n43
+	r[11] = n13*n22*n41 - n12*n23*n41 - n13*n21*n42 + n11*n23*n42 + n12*n21*n43 - n11*n22*n43
+	r[15] = n12*n23*n31 - n13*n22*n31 + n13*n21*n32 - n11*n23*n32 - n12*n21*n33 + n11*n22*n33
+
+	det := n11*r[0] + n21*r[4] + n31*r[8] + n41*r[12]
+	if det == 0 {
+		if throwOnInvertible {
+			panic("Matrix4.GetInverse(): can't invert matrix, determinant is 0")
+		}
+		return m.Identity()
+	}
+	det = 1 / det
+	for i := range r {
+		m[i] = r[i] * det
+	}
+	return m
+}
+
+// Transpose transposes this matrix.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Transpose() *Matrix4 {
+
+	m[1], m[4] = m[4], m[1]
+	m[2], m[8] = m[8], m[2]
+	m[3], m[12] = m[12], m[3]
+	m[6], m[9] = m[9], m[6]
+	m[7], m[13] = m[13], m[7]
+	m[11], m[14] = m[14], m[11]
+	return m
+}
+
+// LookAt sets this matrix to the view matrix of a camera at the
+// specified eye position looking at the target with the specified up direction.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) LookAt(eye, target, up *Vector3) *Matrix4 {
+
+	var f, s, u Vector3
+	f.SubVectors(target, eye).Normalize()
+	s.CrossVectors(&f, up).Normalize()
+	u.CrossVectors(&s, &f)
+
+	return m.Set(
+		s.X, s.Y, s.Z, -s.Dot(eye),
+		u.X, u.Y, u.Z, -u.Dot(eye),
+		-f.X, -f.Y, -f.Z, f.Dot(eye),
+		0, 0, 0, 1,
+	)
+}
+
+// Compose sets this matrix to the transformation composed of the specified
+// scale, rotation quaternion and position, applied in this order.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Compose(position *Vector3, quaternion *Quaternion, scale *Vector3) *Matrix4 {
+
+	m.MakeRotationFromQuaternion(quaternion)
+	m.Scale(scale)
+	return m.SetPosition(position)
+}
+
+// Decompose decomposes this matrix into the position, rotation quaternion and
+// scale which compose it. If the matrix mirrors the space, the X scale is negative.
+// Returns pointer to this unchanged matrix.
+func (m *Matrix4) Decompose(position *Vector3, quaternion *Quaternion, scale *Vector3) *Matrix4 {
+
+	sx := math.Sqrt(m[0]*m[0] + m[1]*m[1] + m[2]*m[2])
+	sy := math.Sqrt(m[4]*m[4] + m[5]*m[5] + m[6]*m[6])
+	sz := math.Sqrt(m[8]*m[8] + m[9]*m[9] + m[10]*m[10])
+	if m.Determinant() < 0 {
+		sx = -sx
+	}
+	*position = m.Position()
+
+	rot := *m
+	inv := [3]float64{sx, sy, sz}
+	for i := range inv {
+		if inv[i] != 0 {
+			inv[i] = 1 / inv[i]
+		} else {
+			inv[i] = 1
+		}
+	}
+	for i := 0; i < 3; i++ {
+		rot[i] *= inv[0]
+		rot[4+i] *= inv[1]
+		rot[8+i] *= inv[2]
+	}
+	quaternion.SetFromRotationMatrix(&rot).Normalize()
+	scale.Set(sx, sy, sz)
+	return m
+}
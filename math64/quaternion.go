@@ -0,0 +1,244 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"math"
+)
+
+// Quaternion is a rotation quaternion in double precision
+type Quaternion struct {
+	x float64
+	y float64
+	z float64
+	w float64
+}
+
+// NewQuaternion creates and returns a pointer to a new quaternion
+// with the specified components
+func NewQuaternion(x, y, z, w float64) *Quaternion {
+
+	return &Quaternion{x: x, y: y, z: z, w: w}
+}
+
+// X returns the x component of this quaternion
+func (q *Quaternion) X() float64 {
+
+	return q.x
+}
+
+// Y returns the y component of this quaternion
+func (q *Quaternion) Y() float64 {
+
+	return q.y
+}
+
+// Z returns the z component of this quaternion
+func (q *Quaternion) Z() float64 {
+
+	return q.z
+}
+
+// W returns the w component of this quaternion
+func (q *Quaternion) W() float64 {
+
+	return q.w
+}
+
+// Set sets the components of this quaternion.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Set(x, y, z, w float64) *Quaternion {
+
+	q.x = x
+	q.y = y
+	q.z = z
+	q.w = w
+	return q
+}
+
+// SetIdentity sets this quaternion to the identity rotation.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) SetIdentity() *Quaternion {
+
+	return q.Set(0, 0, 0, 1)
+}
+
+// IsIdentity returns if this quaternion is the identity rotation
+func (q *Quaternion) IsIdentity() bool {
+
+	return q.x == 0 && q.y == 0 && q.z == 0 && q.w == 1
+}
+
+// Copy copies the specified quaternion into this one.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Copy(other *Quaternion) *Quaternion {
+
+	*q = *other
+	return q
+}
+
+// SetFromEuler sets this quaternion from the specified vector with
+// euler angles for each axis, as math32.Quaternion.SetFromEuler.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) SetFromEuler(euler *Vector3) *Quaternion {
+
+	c1 := math.Cos(euler.X / 2)
+	c2 := math.Cos(euler.Y / 2)
+	c3 := math.Cos(euler.Z / 2)
+	s1 := math.Sin(euler.X / 2)
+	s2 := math.Sin(euler.Y / 2)
+	s3 := math.Sin(euler.Z / 2)
+
+	q.x = s1*c2*c3 - c1*s2*s3
+	q.y = c1*s2*c3 + s1*c2*s3
+	q.z = c1*c2*s3 - s1*s2*c3
+	q.w = c1*c2*c3 + s1*s2*s3
+	return q
+}
+
+// SetFromAxisAngle sets this quaternion to the rotation by the
+// specified angle in radians about the specified unit axis.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) SetFromAxisAngle(axis *Vector3, angle float64) *Quaternion {
+
+	s := math.Sin(angle / 2)
+	return q.Set(axis.X*s, axis.Y*s, axis.Z*s, math.Cos(angle/2))
+}
+
+// SetFromRotationMatrix sets this quaternion from the rotation
+// of the specified matrix, which must not be scaled.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) SetFromRotationMatrix(m *Matrix4) *Quaternion {
+
+	m11, m12, m13 := m[0], m[4], m[8]
+	m21, m22, m23 := m[1], m[5], m[9]
+	m31, m32, m33 := m[2], m[6], m[10]
+	trace := m11 + m22 + m33
+
+	if trace > 0 {
+		s := 0.5 / math.Sqrt(trace+1)
+		return q.Set((m32-m23)*s, (m13-m31)*s, (m21-m12)*s, 0.25/s)
+	}
+	if m11 > m22 && m11 > m33 {
+		s := 2 * math.Sqrt(1+m11-m22-m33)
+		return q.Set(0.25*s, (m12+m21)/s, (m13+m31)/s, (m32-m23)/s)
+	}
+	if m22 > m33 {
+		s := 2 * math.Sqrt(1+m22-m11-m33)
+		return q.Set((m12+m21)/s, 0.25*s, (m23+m32)/s, (m13-m31)/s)
+	}
+	s := 2 * math.Sqrt(1+m33-m11-m22)
+	return q.Set((m13+m31)/s, (m23+m32)/s, 0.25*s, (m21-m12)/s)
+}
+
+// SetFromUnitVectors sets this quaternion to the shortest rotation
+// from the unit vector vFrom to the unit vector vTo.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) SetFromUnitVectors(vFrom, vTo *Vector3) *Quaternion {
+
+	var v Vector3
+	r := vFrom.Dot(vTo) + 1
+	if r < 1e-12 {
+		r = 0
+		if math.Abs(vFrom.X) > math.Abs(vFrom.Z) {
+			v.Set(-vFrom.Y, vFrom.X, 0)
+		} else {
+			v.Set(0, -vFrom.Z, vFrom.Y)
+		}
+	} else {
+		v.CrossVectors(vFrom, vTo)
+	}
+	return q.Set(v.X, v.Y, v.Z, r).Normalize()
+}
+
+// Conjugate sets this quaternion to its conjugate.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Conjugate() *Quaternion {
+
+	q.x = -q.x
+	q.y = -q.y
+	q.z = -q.z
+	return q
+}
+
+// Inverse sets this quaternion to the inverse rotation.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Inverse() *Quaternion {
+
+	return q.Conjugate().Normalize()
+}
+
+// Dot returns the dot product of this quaternion with the specified one
+func (q *Quaternion) Dot(other *Quaternion) float64 {
+
+	return q.x*other.x + q.y*other.y + q.z*other.z + q.w*other.w
+}
+
+// Length returns the length of this quaternion
+func (q *Quaternion) Length() float64 {
+
+	return math.Sqrt(q.Dot(q))
+}
+
+// Normalize normalizes this quaternion, or sets it to the identity if its length is zero.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Normalize() *Quaternion {
+
+	l := q.Length()
+	if l == 0 {
+		return q.SetIdentity()
+	}
+	l = 1 / l
+	return q.Set(q.x*l, q.y*l, q.z*l, q.w*l)
+}
+
+// Multiply multiplies this quaternion by the specified one.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Multiply(other *Quaternion) *Quaternion {
+
+	return q.MultiplyQuaternions(q, other)
+}
+
+// MultiplyQuaternions sets this quaternion to the product of a and b,
+// the rotation b followed by the rotation a.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) MultiplyQuaternions(a, b *Quaternion) *Quaternion {
+
+	return q.Set(
+		a.x*b.w+a.w*b.x+a.y*b.z-a.z*b.y,
+		a.y*b.w+a.w*b.y+a.z*b.x-a.x*b.z,
+		a.z*b.w+a.w*b.z+a.x*b.y-a.y*b.x,
+		a.w*b.w-a.x*b.x-a.y*b.y-a.z*b.z,
+	)
+}
+
+// Slerp sets this quaternion to the spherical linear interpolation from
+// this quaternion to the specified one at t, along the shortest path.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) Slerp(other *Quaternion, t float64) *Quaternion {
+
+	b := *other
+	cosHalfTheta := q.Dot(&b)
+	if cosHalfTheta < 0 {
+		b.Set(-b.x, -b.y, -b.z, -b.w)
+		cosHalfTheta = -cosHalfTheta
+	}
+	ratioA := 1 - t
+	ratioB := t
+	if cosHalfTheta < 0.999999 {
+		halfTheta := math.Acos(cosHalfTheta)
+		sinHalfTheta := math.Sin(halfTheta)
+		ratioA = math.Sin((1-t)*halfTheta) / sinHalfTheta
+		ratioB = math.Sin(t*halfTheta) / sinHalfTheta
+	}
+	q.Set(q.x*ratioA+b.x*ratioB, q.y*ratioA+b.y*ratioB, q.z*ratioA+b.z*ratioB, q.w*ratioA+b.w*ratioB)
+	return q.Normalize()
+}
+
+// Equals returns if this quaternion is equal to the specified one
+func (q *Quaternion) Equals(other *Quaternion) bool {
+
+	return *q == *other
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// RelativeTo32 returns this position relative to the specified origin,
+// as the camera position, converted to float32. The subtraction is done in
+// double precision so the positions near the origin keep their precision.
+func (v *Vector3) RelativeTo32(origin *Vector3) math32.Vector3 {
+
+	var r Vector3
+	return r.SubVectors(v, origin).To32()
+}
+
+// RelativeTo32 returns this affine transformation matrix with its translation
+// relative to the specified origin, as the camera position, converted to float32.
+// It is the model matrix to use with the view matrix of the camera at the origin.
+func (m *Matrix4) RelativeTo32(origin *Vector3) math32.Matrix4 {
+
+	r := *m
+	r[12] -= origin.X
+	r[13] -= origin.Y
+	r[14] -= origin.Z
+	return r.To32()
+}
+
+// FloatingOrigin keeps the origin of the float32 coordinates of a scene near
+// the camera, for the worlds too large for float32 coordinates. The world
+// positions are kept in float64 and the scene positions are relative to the
+// origin, which is moved to the camera when it goes too far from it.
+type FloatingOrigin struct {
+	Origin    Vector3 // world position of the origin of the scene coordinates
+	Threshold float64 // distance from the origin of the camera which moves the origin
+}
+
+// NewFloatingOrigin creates and returns a pointer to a new floating origin at the
+// world origin, which is moved when the camera goes farther than the specified distance
+func NewFloatingOrigin(threshold float64) *FloatingOrigin {
+
+	fo := new(FloatingOrigin)
+	fo.Threshold = threshold
+	return fo
+}
+
+// Update moves the origin to the specified world position of the camera if it
+// is farther than the threshold from the origin. It returns the offset to add to
+// the scene positions, as the positions of the root nodes, and if the origin moved.
+func (fo *FloatingOrigin) Update(camera *Vector3) (math32.Vector3, bool) {
+
+	if camera.DistanceToSquared(&fo.Origin) <= fo.Threshold*fo.Threshold {
+		return math32.Vector3{}, false
+	}
+	offset := fo.Origin.RelativeTo32(camera)
+	fo.Origin = *camera
+	return offset, true
+}
+
+// ToScene returns the scene position of the specified world position
+func (fo *FloatingOrigin) ToScene(world *Vector3) math32.Vector3 {
+
+	return world.RelativeTo32(&fo.Origin)
+}
+
+// ToWorld returns the world position of the specified scene position
+func (fo *FloatingOrigin) ToWorld(scene *math32.Vector3) Vector3 {
+
+	var r Vector3
+	return *r.SetFrom32(scene).Add(&fo.Origin)
+}
+
+// SceneMatrix returns the scene transformation matrix
+// of the specified world transformation matrix
+func (fo *FloatingOrigin) SceneMatrix(world *Matrix4) math32.Matrix4 {
+
+	return world.RelativeTo32(&fo.Origin)
+}
@@ -0,0 +1,296 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math64
+
+import (
+	"math"
+)
+
+// Vector3 is a three dimensional vector in double precision
+type Vector3 struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// NewVector3 creates and returns a pointer to a new Vector3
+func NewVector3(x, y, z float64) *Vector3 {
+
+	return &Vector3{X: x, Y: y, Z: z}
+}
+
+// Set sets the components of this vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) Set(x, y, z float64) *Vector3 {
+
+	v.X = x
+	v.Y = y
+	v.Z = z
+	return v
+}
+
+// Copy copies the specified vector into this one.
+// Returns pointer to this updated vector.
+func (v *Vector3) Copy(other *Vector3) *Vector3 {
+
+	*v = *other
+	return v
+}
+
+// Add adds the specified vector to this one.
+// Returns pointer to this updated vector.
+func (v *Vector3) Add(other *Vector3) *Vector3 {
+
+	v.X += other.X
+	v.Y += other.Y
+	v.Z += other.Z
+	return v
+}
+
+// AddScalar adds the specified value to each component of this vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) AddScalar(s float64) *Vector3 {
+
+	v.X += s
+	v.Y += s
+	v.Z += s
+	return v
+}
+
+// AddVectors sets this vector to a + b.
+// Returns pointer to this updated vector.
+func (v *Vector3) AddVectors(a, b *Vector3) *Vector3 {
+
+	v.X = a.X + b.X
+	v.Y = a.Y + b.Y
+	v.Z = a.Z + b.Z
+	return v
+}
+
+// Sub subtracts the specified vector from this one.
+// Returns pointer to this updated vector.
+func (v *Vector3) Sub(other *Vector3) *Vector3 {
+
+	v.X -= other.X
+	v.Y -= other.Y
+	v.Z -= other.Z
+	return v
+}
+
+// SubVectors sets this vector to a - b.
+// Returns pointer to this updated vector.
+func (v *Vector3) SubVectors(a, b *Vector3) *Vector3 {
+
+	v.X = a.X - b.X
+	v.Y = a.Y - b.Y
+	v.Z = a.Z - b.Z
+	return v
+}
+
+// Multiply multiplies each component of this vector by the
+// corresponding component of the specified vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) Multiply(other *Vector3) *Vector3 {
+
+	v.X *= other.X
+	v.Y *= other.Y
+	v.Z *= other.Z
+	return v
+}
+
+// MultiplyScalar multiplies each component of this vector by the specified value.
+// Returns pointer to this updated vector.
+func (v *Vector3) MultiplyScalar(s float64) *Vector3 {
+
+	v.X *= s
+	v.Y *= s
+	v.Z *= s
+	return v
+}
+
+// DivideScalar divides each component of this vector by the specified value,
+// or sets it to zero if the value is zero.
+// Returns pointer to this updated vector.
+func (v *Vector3) DivideScalar(s float64) *Vector3 {
+
+	if s == 0 {
+		return v.Set(0, 0, 0)
+	}
+	return v.MultiplyScalar(1 / s)
+}
+
+// Negate negates each component of this vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) Negate() *Vector3 {
+
+	v.X = -v.X
+	v.Y = -v.Y
+	v.Z = -v.Z
+	return v
+}
+
+// Min sets each component of this vector to the minimum of
+// its value and the value of the specified vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) Min(other *Vector3) *Vector3 {
+
+	v.X = math.Min(v.X, other.X)
+	v.Y = math.Min(v.Y, other.Y)
+	v.Z = math.Min(v.Z, other.Z)
+	return v
+}
+
+// Max sets each component of this vector to the maximum of
+// its value and the value of the specified vector.
+// Returns pointer to this updated vector.
+func (v *Vector3) Max(other *Vector3) *Vector3 {
+
+	v.X = math.Max(v.X, other.X)
+	v.Y = math.Max(v.Y, other.Y)
+	v.Z = math.Max(v.Z, other.Z)
+	return v
+}
+
+// Dot returns the dot product of this vector with the specified one
+func (v *Vector3) Dot(other *Vector3) float64 {
+
+	return v.X*other.X + v.Y*other.Y + v.Z*other.Z
+}
+
+// Cross sets this vector to the cross product of this vector and the specified one.
+// Returns pointer to this updated vector.
+func (v *Vector3) Cross(other *Vector3) *Vector3 {
+
+	return v.CrossVectors(v, other)
+}
+
+// CrossVectors sets this vector to the cross product of a and b.
+// Returns pointer to this updated vector.
+func (v *Vector3) CrossVectors(a, b *Vector3) *Vector3 {
+
+	x := a.Y*b.Z - a.Z*b.Y
+	y := a.Z*b.X - a.X*b.Z
+	z := a.X*b.Y - a.Y*b.X
+	return v.Set(x, y, z)
+}
+
+// LengthSq returns the squared length of this vector
+func (v *Vector3) LengthSq() float64 {
+
+	return v.X*v.X + v.Y*v.Y + v.Z*v.Z
+}
+
+// Length returns the length of this vector
+func (v *Vector3) Length() float64 {
+
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+// Normalize normalizes this vector so its length is 1.
+// Returns pointer to this updated vector.
+func (v *Vector3) Normalize() *Vector3 {
+
+	return v.DivideScalar(v.Length())
+}
+
+// SetLength sets the length of this vector, keeping its direction.
+// Returns pointer to this updated vector.
+func (v *Vector3) SetLength(l float64) *Vector3 {
+
+	return v.Normalize().MultiplyScalar(l)
+}
+
+// DistanceTo returns the distance from this point to the specified one
+func (v *Vector3) DistanceTo(other *Vector3) float64 {
+
+	return math.Sqrt(v.DistanceToSquared(other))
+}
+
+// DistanceToSquared returns the squared distance from this point to the specified one
+func (v *Vector3) DistanceToSquared(other *Vector3) float64 {
+
+	dx := v.X - other.X
+	dy := v.Y - other.Y
+	dz := v.Z - other.Z
+	return dx*dx + dy*dy + dz*dz
+}
+
+// Lerp sets this vector to the linear interpolation from this vector
+// to the specified one at alpha.
+// Returns pointer to this updated vector.
+func (v *Vector3) Lerp(other *Vector3, alpha float64) *Vector3 {
+
+	v.X += (other.X - v.X) * alpha
+	v.Y += (other.Y - v.Y) * alpha
+	v.Z += (other.Z - v.Z) * alpha
+	return v
+}
+
+// LerpVectors sets this vector to the linear interpolation from a to b at alpha.
+// Returns pointer to this updated vector.
+func (v *Vector3) LerpVectors(a, b *Vector3, alpha float64) *Vector3 {
+
+	return v.Copy(a).Lerp(b, alpha)
+}
+
+// ApplyMatrix4 transforms this point by the specified affine matrix.
+// Returns pointer to this updated vector.
+func (v *Vector3) ApplyMatrix4(m *Matrix4) *Vector3 {
+
+	x := v.X
+	y := v.Y
+	z := v.Z
+	v.X = m[0]*x + m[4]*y + m[8]*z + m[12]
+	v.Y = m[1]*x + m[5]*y + m[9]*z + m[13]
+	v.Z = m[2]*x + m[6]*y + m[10]*z + m[14]
+	return v
+}
+
+// ApplyQuaternion rotates this vector by the specified quaternion.
+// Returns pointer to this updated vector.
+func (v *Vector3) ApplyQuaternion(q *Quaternion) *Vector3 {
+
+	x := v.X
+	y := v.Y
+	z := v.Z
+
+	// q * v
+	ix := q.w*x + q.y*z - q.z*y
+	iy := q.w*y + q.z*x - q.x*z
+	iz := q.w*z + q.x*y - q.y*x
+	iw := -q.x*x - q.y*y - q.z*z
+
+	// q * v * inverse(q)
+	v.X = ix*q.w - iw*q.x - iy*q.z + iz*q.y
+	v.Y = iy*q.w - iw*q.y - iz*q.x + ix*q.z
+	v.Z = iz*q.w - iw*q.z - ix*q.y + iy*q.x
+	return v
+}
+
+// TransformDirection transforms this direction by the specified affine matrix,
+// ignoring its translation, and normalizes it.
+// Returns pointer to this updated vector.
+func (v *Vector3) TransformDirection(m *Matrix4) *Vector3 {
+
+	x := v.X
+	y := v.Y
+	z := v.Z
+	v.X = m[0]*x + m[4]*y + m[8]*z
+	v.Y = m[1]*x + m[5]*y + m[9]*z
+	v.Z = m[2]*x + m[6]*y + m[10]*z
+	return v.Normalize()
+}
+
+// Equals returns if this vector is equal to the specified one
+func (v *Vector3) Equals(other *Vector3) bool {
+
+	return *v == *other
+}
+
+// Clone returns a pointer to a copy of this vector
+func (v *Vector3) Clone() *Vector3 {
+
+	return NewVector3(v.X, v.Y, v.Z)
+}
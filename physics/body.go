@@ -0,0 +1,448 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// BodyType is the type of the motion of a body
+type BodyType int
+
+// Types of bodies
+const (
+	Static    = BodyType(iota) // never moves and has infinite mass
+	Kinematic                  // moved by its node and has infinite mass
+	Dynamic                    // moved by the simulation and moves its node
+)
+
+// Body is a component which makes its node a rigid body of the World attached
+// to the node or to one of its parents. The body has the world position and
+// rotation of its node when it enters the world. The position and rotation of
+// the nodes of the dynamic bodies are set after each step of the world, in the
+// space of their parents, and the dynamic bodies whose nodes are moved by the
+// application are moved to their new transforms, as when they are teleported.
+type Body struct {
+	core.Component                   // Embedded component
+	world          *World            // world of this body or nil
+	shape          IShape            // collision shape
+	typ            BodyType          // type of motion
+	mass           float32           // mass of a dynamic body
+	invMass        float32           // inverse mass or 0 for infinite mass
+	invInertia     math32.Vector3    // inverse of the diagonal of the inertia tensor in local space
+	pos            math32.Vector3    // world position
+	rot            math32.Quaternion // world rotation
+	vel            math32.Vector3    // linear velocity
+	angVel         math32.Vector3    // angular velocity
+	force          math32.Vector3    // force accumulated for the next step
+	torque         math32.Vector3    // torque accumulated for the next step
+	friction       float32           // friction coefficient
+	restitution    float32           // restitution coefficient
+	linDamping     float32           // linear damping
+	angDamping     float32           // angular damping
+	canSleep       bool              // body can be put to sleep
+	sleeping       bool              // body is sleeping
+	sleepTime      float32           // time the body has been still
+	localPos       math32.Vector3    // local position last set to the node
+	localRot       math32.Quaternion // local rotation last set to the node
+	index          int               // index of the body in its world in the last step
+	island         int               // index of the island of the body in the last step
+	bounds         math32.Box3       // world bounding box in the last step
+}
+
+// NewBody creates and returns a pointer to a new body with the specified shape
+// and type. Dynamic bodies have a mass of 1. Panics if the shape is a plane
+// and the type is Dynamic.
+func NewBody(shape IShape, typ BodyType) *Body {
+
+	b := new(Body)
+	b.shape = shape
+	b.rot.SetIdentity()
+	b.localRot.SetIdentity()
+	b.friction = 0.5
+	b.linDamping = 0.01
+	b.angDamping = 0.05
+	b.canSleep = true
+	b.SetType(typ)
+	return b
+}
+
+// Shape returns the collision shape of this body
+func (b *Body) Shape() IShape {
+
+	return b.shape
+}
+
+// World returns the world of this body or nil if it is not in a world
+func (b *Body) World() *World {
+
+	return b.world
+}
+
+// SetType sets the type of the motion of this body.
+// Panics if the shape of the body is a plane and the type is Dynamic.
+func (b *Body) SetType(typ BodyType) {
+
+	if _, ok := b.shape.(*Plane); ok && typ == Dynamic {
+		panic("physics.Body: a plane can not be the shape of a dynamic body")
+	}
+	b.typ = typ
+	if b.mass == 0 {
+		b.mass = 1
+	}
+	b.SetMass(b.mass)
+	if typ != Dynamic {
+		b.vel.Set(0, 0, 0)
+		b.angVel.Set(0, 0, 0)
+	}
+	b.Wake()
+}
+
+// Type returns the type of the motion of this body
+func (b *Body) Type() BodyType {
+
+	return b.typ
+}
+
+// SetMass sets the mass of this body, which is only used by dynamic bodies,
+// and computes its inertia from its shape
+func (b *Body) SetMass(mass float32) {
+
+	b.mass = mass
+	b.invMass = 0
+	b.invInertia.Set(0, 0, 0)
+	if b.typ != Dynamic || mass <= 0 {
+		return
+	}
+	b.invMass = 1 / mass
+	inertia := b.shape.Inertia(mass)
+	for i := 0; i < 3; i++ {
+		if c := inertia.Component(i); c > 0 {
+			b.invInertia.SetComponent(i, 1/c)
+		}
+	}
+}
+
+// Mass returns the mass of this body
+func (b *Body) Mass() float32 {
+
+	return b.mass
+}
+
+// SetFriction sets the friction coefficient of this body. The default is 0.5.
+// The friction of a contact is the geometric mean of the frictions of its bodies.
+func (b *Body) SetFriction(friction float32) {
+
+	b.friction = friction
+}
+
+// Friction returns the friction coefficient of this body
+func (b *Body) Friction() float32 {
+
+	return b.friction
+}
+
+// SetRestitution sets the restitution coefficient of this body, from 0 for
+// no bounce to 1 for elastic collisions. The default is 0. The restitution
+// of a contact is the largest restitution of its bodies.
+func (b *Body) SetRestitution(restitution float32) {
+
+	b.restitution = restitution
+}
+
+// Restitution returns the restitution coefficient of this body
+func (b *Body) Restitution() float32 {
+
+	return b.restitution
+}
+
+// SetDamping sets the fractions of the linear and angular velocities of this body
+// lost each second, as by the resistance of the air. The defaults are 0.01 and 0.05.
+func (b *Body) SetDamping(linear, angular float32) {
+
+	b.linDamping = linear
+	b.angDamping = angular
+}
+
+// Damping returns the linear and angular damping of this body
+func (b *Body) Damping() (float32, float32) {
+
+	return b.linDamping, b.angDamping
+}
+
+// SetPosition sets the world position of this body and of its node
+func (b *Body) SetPosition(pos *math32.Vector3) {
+
+	b.pos = *pos
+	b.syncNode()
+	b.Wake()
+}
+
+// Position returns the world position of this body
+func (b *Body) Position() math32.Vector3 {
+
+	return b.pos
+}
+
+// SetQuaternion sets the world rotation of this body and of its node
+func (b *Body) SetQuaternion(rot *math32.Quaternion) {
+
+	b.rot = *rot
+	b.rot.Normalize()
+	b.syncNode()
+	b.Wake()
+}
+
+// Quaternion returns the world rotation of this body
+func (b *Body) Quaternion() math32.Quaternion {
+
+	return b.rot
+}
+
+// SetVelocity sets the linear velocity of this dynamic body and wakes it
+func (b *Body) SetVelocity(vel *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	b.vel = *vel
+	b.Wake()
+}
+
+// Velocity returns the linear velocity of this body. The velocity of
+// a kinematic body is computed from the motion of its node.
+func (b *Body) Velocity() math32.Vector3 {
+
+	return b.vel
+}
+
+// SetAngularVelocity sets the angular velocity of this dynamic body,
+// in radians per second about its axis, and wakes it
+func (b *Body) SetAngularVelocity(vel *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	b.angVel = *vel
+	b.Wake()
+}
+
+// AngularVelocity returns the angular velocity of this body
+func (b *Body) AngularVelocity() math32.Vector3 {
+
+	return b.angVel
+}
+
+// ApplyForce applies the specified world force to the center of this
+// dynamic body during the next step and wakes it
+func (b *Body) ApplyForce(force *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	b.force.Add(force)
+	b.Wake()
+}
+
+// ApplyForceAt applies the specified world force at the specified world
+// point of this dynamic body during the next step and wakes it
+func (b *Body) ApplyForceAt(force, point *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	var r math32.Vector3
+	r.SubVectors(point, &b.pos).Cross(force)
+	b.force.Add(force)
+	b.torque.Add(&r)
+	b.Wake()
+}
+
+// ApplyTorque applies the specified world torque to this
+// dynamic body during the next step and wakes it
+func (b *Body) ApplyTorque(torque *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	b.torque.Add(torque)
+	b.Wake()
+}
+
+// ApplyImpulse applies the specified world impulse to the center of
+// this dynamic body, changing its velocity at once, and wakes it
+func (b *Body) ApplyImpulse(impulse *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	var dv math32.Vector3
+	dv.Copy(impulse).MultiplyScalar(b.invMass)
+	b.vel.Add(&dv)
+	b.Wake()
+}
+
+// ApplyImpulseAt applies the specified world impulse at the specified world
+// point of this dynamic body, changing its velocities at once, and wakes it
+func (b *Body) ApplyImpulseAt(impulse, point *math32.Vector3) {
+
+	if b.typ != Dynamic {
+		return
+	}
+	var r math32.Vector3
+	r.SubVectors(point, &b.pos)
+	b.applyImpulse(impulse, &r)
+	b.Wake()
+}
+
+// SetCanSleep sets if this body can be put to sleep when it stays still.
+// The default is true.
+func (b *Body) SetCanSleep(state bool) {
+
+	b.canSleep = state
+	if !state {
+		b.Wake()
+	}
+}
+
+// CanSleep returns if this body can be put to sleep
+func (b *Body) CanSleep() bool {
+
+	return b.canSleep
+}
+
+// Sleeping returns if this body is sleeping
+func (b *Body) Sleeping() bool {
+
+	return b.sleeping
+}
+
+// Wake wakes this body, which is simulated again from the next step
+func (b *Body) Wake() {
+
+	b.sleeping = false
+	b.sleepTime = 0
+}
+
+// Sleep puts this dynamic body to sleep, stopping it until it is touched or woken
+func (b *Body) Sleep() {
+
+	if b.typ != Dynamic {
+		return
+	}
+	b.sleeping = true
+	b.vel.Set(0, 0, 0)
+	b.angVel.Set(0, 0, 0)
+	b.force.Set(0, 0, 0)
+	b.torque.Set(0, 0, 0)
+}
+
+// OnAttach satisfies the IComponent interface and
+// adds this body to the world of its node
+func (b *Body) OnAttach() {
+
+	for n := b.Node(); n != nil; {
+		for _, ic := range n.Components() {
+			if w, ok := ic.(*World); ok {
+				w.addBody(b)
+				return
+			}
+		}
+		if n.Parent() == nil {
+			break
+		}
+		n = n.Parent().GetNode()
+	}
+}
+
+// OnDetach satisfies the IComponent interface and
+// removes this body from its world
+func (b *Body) OnDetach() {
+
+	if b.world != nil {
+		b.world.removeBody(b)
+	}
+}
+
+// active returns if this body moves in the next step
+func (b *Body) active() bool {
+
+	switch b.typ {
+	case Dynamic:
+		return !b.sleeping
+	case Kinematic:
+		return b.vel.LengthSq() > 0 || b.angVel.LengthSq() > 0
+	}
+	return false
+}
+
+// readNode sets the world position and rotation of this body
+// from the world transform of its node
+func (b *Body) readNode() {
+
+	n := b.Node()
+	n.WorldPosition(&b.pos)
+	n.WorldQuaternion(&b.rot)
+	b.localPos = n.Position()
+	b.localRot = n.Quaternion()
+}
+
+// moved returns if the local transform of the node of this body
+// was changed since it was last read or set by this body
+func (b *Body) moved() bool {
+
+	n := b.Node()
+	pos := n.Position()
+	rot := n.Quaternion()
+	return pos != b.localPos || rot != b.localRot
+}
+
+// syncNode sets the local transform of the node of this body
+// from the world position and rotation of this body
+func (b *Body) syncNode() {
+
+	n := b.Node()
+	if n == nil {
+		return
+	}
+	b.localPos = b.pos
+	b.localRot = b.rot
+	if n.Parent() != nil {
+		parent := n.Parent().GetNode()
+		parent.UpdateWorldMatrix()
+		var inv math32.Matrix4
+		pm := parent.MatrixWorld()
+		inv.GetInverse(&pm, false)
+		b.localPos.ApplyMatrix4(&inv)
+		var prot math32.Quaternion
+		parent.WorldQuaternion(&prot)
+		prot.Inverse()
+		b.localRot.MultiplyQuaternions(&prot, &b.rot)
+	}
+	n.SetPositionVec(&b.localPos)
+	n.SetQuaternionQuat(&b.localRot)
+}
+
+// applyImpulse changes the velocities of this body by the
+// specified impulse applied at the specified relative point
+func (b *Body) applyImpulse(impulse, r *math32.Vector3) {
+
+	var dv, dw math32.Vector3
+	dv.Copy(impulse).MultiplyScalar(b.invMass)
+	b.vel.Add(&dv)
+	dw.CrossVectors(r, impulse)
+	b.applyInvInertia(&dw)
+	b.angVel.Add(&dw)
+}
+
+// applyInvInertia multiplies the specified world vector
+// by the inverse inertia tensor of this body in world space
+func (b *Body) applyInvInertia(v *math32.Vector3) {
+
+	inv := b.rot
+	inv.Inverse()
+	v.ApplyQuaternion(&inv).Multiply(&b.invInertia).ApplyQuaternion(&b.rot)
+}
@@ -0,0 +1,416 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/math32/intersect"
+)
+
+// margin is the distance below which the shapes are in contact, so the
+// contacts of the bodies resting on others do not flicker between steps
+const margin = 0.02
+
+// contact is a contact point between two bodies
+type contact struct {
+	point    math32.Vector3 // world point in the middle of the penetration
+	normal   math32.Vector3 // world normal from the first body to the second
+	depth    float32        // penetration depth, negative for a gap up to the margin
+	rA, rB   math32.Vector3 // point relative to the centers of the bodies
+	tangents [2]math32.Vector3
+	massN    float32    // effective mass along the normal
+	massT    [2]float32 // effective masses along the tangents
+	bias     float32    // target velocity along the normal
+	impN     float32    // accumulated normal impulse
+	impT     [2]float32 // accumulated tangent impulses
+}
+
+// manifold is the set of contacts between two bodies
+type manifold struct {
+	a, b     *Body     // bodies in contact
+	contacts []contact // contact points
+}
+
+// collide appends to the specified contacts the contacts between the specified
+// bodies closer than the margin, with normals from a to b, and returns them
+func collide(a, b *Body, contacts []contact) []contact {
+
+	start := len(contacts)
+	swapped := false
+	switch sa := a.shape.(type) {
+	case *Sphere:
+		switch sb := b.shape.(type) {
+		case *Sphere:
+			contacts = collideSpheres(a, sa, b, sb, contacts)
+		case *Box:
+			contacts = collideBoxSphere(b, sb, a, sa, contacts)
+			swapped = true
+		case *Plane:
+			contacts = collidePlaneSphere(b, sb, a, sa, contacts)
+			swapped = true
+		}
+	case *Box:
+		switch sb := b.shape.(type) {
+		case *Sphere:
+			contacts = collideBoxSphere(a, sa, b, sb, contacts)
+		case *Box:
+			contacts = collideBoxes(a, sa, b, sb, contacts)
+		case *Plane:
+			contacts = collidePlaneBox(b, sb, a, sa, contacts)
+			swapped = true
+		}
+	case *Plane:
+		switch sb := b.shape.(type) {
+		case *Sphere:
+			contacts = collidePlaneSphere(a, sa, b, sb, contacts)
+		case *Box:
+			contacts = collidePlaneBox(a, sa, b, sb, contacts)
+		}
+	}
+	if swapped {
+		for i := start; i < len(contacts); i++ {
+			contacts[i].normal.Negate()
+		}
+	}
+	return contacts
+}
+
+// collideSpheres appends the contact between two spheres
+func collideSpheres(a *Body, sa *Sphere, b *Body, sb *Sphere, contacts []contact) []contact {
+
+	var d math32.Vector3
+	d.SubVectors(&b.pos, &a.pos)
+	dist := d.Length()
+	depth := sa.radius + sb.radius - dist
+	if depth < -margin {
+		return contacts
+	}
+	if dist > 1e-6 {
+		d.DivideScalar(dist)
+	} else {
+		d.Set(0, 1, 0)
+	}
+	var c contact
+	c.normal = d
+	c.depth = depth
+	c.point = d
+	c.point.MultiplyScalar(sa.radius - depth/2).Add(&a.pos)
+	return append(contacts, c)
+}
+
+// collideBoxSphere appends the contact between a box and a sphere
+func collideBoxSphere(a *Body, box *Box, b *Body, s *Sphere, contacts []contact) []contact {
+
+	// Center of the sphere in the space of the box
+	inv := a.rot
+	inv.Inverse()
+	var local math32.Vector3
+	local.SubVectors(&b.pos, &a.pos).ApplyQuaternion(&inv)
+	closest := local
+	closest.Clamp(box.half.Clone().Negate(), &box.half)
+
+	var normal math32.Vector3
+	var depth float32
+	if closest != local {
+		normal.SubVectors(&local, &closest)
+		dist := normal.Length()
+		depth = s.radius - dist
+		if depth < -margin {
+			return contacts
+		}
+		normal.DivideScalar(dist)
+	} else {
+		// Center inside the box: pushed out through the nearest face
+		axis := 0
+		min := math32.Infinity
+		for i := 0; i < 3; i++ {
+			if d := box.half.Component(i) - math32.Abs(local.Component(i)); d < min {
+				min = d
+				axis = i
+			}
+		}
+		sign := float32(1)
+		if local.Component(axis) < 0 {
+			sign = -1
+		}
+		normal.SetComponent(axis, sign)
+		closest.SetComponent(axis, sign*box.half.Component(axis))
+		depth = s.radius + min
+	}
+	var c contact
+	c.normal = normal
+	c.normal.ApplyQuaternion(&a.rot)
+	c.depth = depth
+	c.point = c.normal
+	c.point.MultiplyScalar(-depth / 2)
+	closest.ApplyQuaternion(&a.rot).Add(&a.pos)
+	c.point.Add(&closest)
+	return append(contacts, c)
+}
+
+// collidePlaneSphere appends the contact between a plane and a sphere
+func collidePlaneSphere(a *Body, p *Plane, b *Body, s *Sphere, contacts []contact) []contact {
+
+	normal := p.normal
+	normal.ApplyQuaternion(&a.rot)
+	var d math32.Vector3
+	dist := d.SubVectors(&b.pos, &a.pos).Dot(&normal)
+	depth := s.radius - dist
+	if depth < -margin {
+		return contacts
+	}
+	var c contact
+	c.normal = normal
+	c.depth = depth
+	c.point = normal
+	c.point.MultiplyScalar(-dist - depth/2).Add(&b.pos)
+	return append(contacts, c)
+}
+
+// collidePlaneBox appends the contacts between a plane and the vertices of a box
+func collidePlaneBox(a *Body, p *Plane, b *Body, box *Box, contacts []contact) []contact {
+
+	normal := p.normal
+	normal.ApplyQuaternion(&a.rot)
+	for _, v := range boxVertices(b, box) {
+		var d math32.Vector3
+		dist := d.SubVectors(&v, &a.pos).Dot(&normal)
+		if dist > margin {
+			continue
+		}
+		var c contact
+		c.normal = normal
+		c.depth = -dist
+		c.point = normal
+		c.point.MultiplyScalar(-dist / 2).Add(&v)
+		contacts = append(contacts, c)
+	}
+	return contacts
+}
+
+// collideBoxes appends the contacts between two boxes, from the separating
+// axis of least penetration: the points of the face of a box most opposed
+// to the normal clipped by the sides of the face of the other box or the
+// closest points of two edges
+func collideBoxes(a *Body, ba *Box, b *Body, bb *Box, contacts []contact) []contact {
+
+	var axesA, axesB [3]math32.Vector3
+	for i := 0; i < 3; i++ {
+		axesA[i] = boxAxis(&a.rot, i)
+		axesB[i] = boxAxis(&b.rot, i)
+	}
+	var d math32.Vector3
+	d.SubVectors(&b.pos, &a.pos)
+
+	// Finds the axis of least penetration, preferring the faces to the edges
+	best := -1
+	bestDepth := math32.Infinity
+	var bestAxis math32.Vector3
+	test := func(axis math32.Vector3, idx int) bool {
+		l := axis.Length()
+		if l < 1e-6 {
+			return true
+		}
+		axis.DivideScalar(l)
+		var ra, rb float32
+		for i := 0; i < 3; i++ {
+			ra += ba.half.Component(i) * math32.Abs(axesA[i].Dot(&axis))
+			rb += bb.half.Component(i) * math32.Abs(axesB[i].Dot(&axis))
+		}
+		dist := d.Dot(&axis)
+		depth := ra + rb - math32.Abs(dist)
+		if depth < -margin {
+			return false
+		}
+		if idx >= 6 {
+			depth = depth*1.05 + 0.001
+		}
+		if depth < bestDepth {
+			if dist < 0 {
+				axis.Negate()
+			}
+			best = idx
+			bestDepth = depth
+			bestAxis = axis
+		}
+		return true
+	}
+	for i := 0; i < 3; i++ {
+		if !test(axesA[i], i) || !test(axesB[i], 3+i) {
+			return contacts
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			var axis math32.Vector3
+			axis.CrossVectors(&axesA[i], &axesB[j])
+			if !test(axis, 6+i*3+j) {
+				return contacts
+			}
+		}
+	}
+
+	switch {
+	case best < 3:
+		return clipFaces(a, ba, &axesA, b, bb, &axesB, best, &bestAxis, false, contacts)
+	case best < 6:
+		normal := bestAxis
+		normal.Negate()
+		return clipFaces(b, bb, &axesB, a, ba, &axesA, best-3, &normal, true, contacts)
+	}
+
+	// Edge against edge
+	i := (best - 6) / 3
+	j := (best - 6) % 3
+	ea := boxEdge(a, ba, &axesA, i, &bestAxis)
+	var opposite math32.Vector3
+	opposite.Copy(&bestAxis).Negate()
+	eb := boxEdge(b, bb, &axesB, j, &opposite)
+	ca, cb, _, _ := intersect.ClosestPointsSegments(&ea[0], &ea[1], &eb[0], &eb[1])
+	var c contact
+	c.normal = bestAxis
+	c.point.AddVectors(&ca, &cb).MultiplyScalar(0.5)
+	c.depth = ca.Sub(&cb).Dot(&bestAxis)
+	if c.depth < -margin {
+		return contacts
+	}
+	return append(contacts, c)
+}
+
+// clipFaces appends the contacts between the specified face of the reference
+// box, with the specified normal towards the incident box, and the face of the
+// incident box most opposed to it. The normals of the contacts are negated
+// if flip is set, as when the reference box is the second box of the pair.
+func clipFaces(ref *Body, rbox *Box, raxes *[3]math32.Vector3, inc *Body, ibox *Box, iaxes *[3]math32.Vector3,
+	face int, normal *math32.Vector3, flip bool, contacts []contact) []contact {
+
+	// Face of the incident box most opposed to the normal
+	iface := 0
+	min := math32.Infinity
+	for i := 0; i < 3; i++ {
+		if dot := -math32.Abs(iaxes[i].Dot(normal)); dot < min {
+			min = dot
+			iface = i
+		}
+	}
+	sign := float32(1)
+	if iaxes[iface].Dot(normal) > 0 {
+		sign = -1
+	}
+	var center, u, v math32.Vector3
+	center.Copy(&iaxes[iface]).MultiplyScalar(sign * ibox.half.Component(iface)).Add(&inc.pos)
+	u1 := (iface + 1) % 3
+	u2 := (iface + 2) % 3
+	u.Copy(&iaxes[u1]).MultiplyScalar(ibox.half.Component(u1))
+	v.Copy(&iaxes[u2]).MultiplyScalar(ibox.half.Component(u2))
+	poly := make([]math32.Vector3, 0, 8)
+	for _, s := range [4][2]float32{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}} {
+		var p, pv math32.Vector3
+		p.Copy(&u).MultiplyScalar(s[0])
+		pv.Copy(&v).MultiplyScalar(s[1])
+		p.Add(&pv).Add(&center)
+		poly = append(poly, p)
+	}
+
+	// Clips the incident face by the side planes of the reference face
+	for k := 1; k <= 2; k++ {
+		axis := (face + k) % 3
+		h := rbox.half.Component(axis)
+		off := raxes[axis].Dot(&ref.pos)
+		poly = clipPolygon(poly, &raxes[axis], off+h)
+		var neg math32.Vector3
+		neg.Copy(&raxes[axis]).Negate()
+		poly = clipPolygon(poly, &neg, -off+h)
+	}
+
+	// Keeps the points below the reference face
+	plane := normal.Dot(&ref.pos) + rbox.half.Component(face)
+	for _, p := range poly {
+		depth := plane - p.Dot(normal)
+		if depth < -margin {
+			continue
+		}
+		var c contact
+		c.normal = *normal
+		if flip {
+			c.normal.Negate()
+		}
+		c.depth = depth
+		c.point.Copy(normal).MultiplyScalar(depth / 2).Add(&p)
+		contacts = append(contacts, c)
+	}
+	return contacts
+}
+
+// clipPolygon returns the specified polygon clipped by the plane with the specified
+// normal and distance from the origin, keeping the points where dot(normal, p) <= dist
+func clipPolygon(poly []math32.Vector3, normal *math32.Vector3, dist float32) []math32.Vector3 {
+
+	if len(poly) == 0 {
+		return poly
+	}
+	out := make([]math32.Vector3, 0, len(poly)+1)
+	prev := poly[len(poly)-1]
+	dprev := prev.Dot(normal) - dist
+	for _, p := range poly {
+		dp := p.Dot(normal) - dist
+		if (dprev <= 0) != (dp <= 0) {
+			q := prev
+			q.Lerp(&p, dprev/(dprev-dp))
+			out = append(out, q)
+		}
+		if dp <= 0 {
+			out = append(out, p)
+		}
+		prev = p
+		dprev = dp
+	}
+	return out
+}
+
+// boxEdge returns the ends of the edge of the specified box parallel to
+// its specified axis which is the farthest along the specified direction
+func boxEdge(b *Body, box *Box, axes *[3]math32.Vector3, axis int, dir *math32.Vector3) [2]math32.Vector3 {
+
+	mid := b.pos
+	for i := 0; i < 3; i++ {
+		if i == axis {
+			continue
+		}
+		h := box.half.Component(i)
+		if axes[i].Dot(dir) < 0 {
+			h = -h
+		}
+		var off math32.Vector3
+		off.Copy(&axes[i]).MultiplyScalar(h)
+		mid.Add(&off)
+	}
+	var half math32.Vector3
+	half.Copy(&axes[axis]).MultiplyScalar(box.half.Component(axis))
+	var e [2]math32.Vector3
+	e[0].SubVectors(&mid, &half)
+	e[1].AddVectors(&mid, &half)
+	return e
+}
+
+// boxVertices returns the world vertices of the specified box body
+func boxVertices(b *Body, box *Box) [8]math32.Vector3 {
+
+	var vs [8]math32.Vector3
+	for i := range vs {
+		v := box.half
+		if i&1 != 0 {
+			v.X = -v.X
+		}
+		if i&2 != 0 {
+			v.Y = -v.Y
+		}
+		if i&4 != 0 {
+			v.Z = -v.Z
+		}
+		vs[i] = *v.ApplyQuaternion(&b.rot).Add(&b.pos)
+	}
+	return vs
+}
@@ -0,0 +1,24 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package physics implements the rigid body dynamics of the nodes of a scene.
+//
+// A World is a component attached to the root node of a scene, or of a part
+// of it, which simulates the rigid bodies attached to the nodes below it.
+// It is stepped by its FixedUpdate method, so it must be updated by the
+// fixed updates of a core.Loop, which give the same results for any frame rate.
+//
+// A Body is a component which makes its node a rigid body with a shape:
+// a Sphere, a Box or a Plane. Static bodies never move, kinematic bodies are
+// moved by their nodes, as by an animation, and push the dynamic bodies, and
+// dynamic bodies are moved by the gravity, forces and impulses applied to
+// them and by their collisions, and then move their nodes.
+//
+// At each step the world integrates the forces of the dynamic bodies,
+// finds the contacts of the bodies whose bounding boxes overlap and solves
+// them with sequential impulses, with friction, restitution and the impulses
+// of the previous step as the initial guess, so stacks of bodies are stable.
+// The groups of touching bodies which stay still for a while are put to
+// sleep and are not simulated until they are touched or woken.
+package physics
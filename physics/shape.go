@@ -0,0 +1,140 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// IShape is the interface of the collision shapes of the bodies.
+// The shapes are centered at the origin of their bodies, in their
+// local space, and are not affected by the scale of their nodes.
+type IShape interface {
+	// BoundingBox sets the specified box with the world bounding box of
+	// this shape at the specified world position and rotation
+	BoundingBox(pos *math32.Vector3, rot *math32.Quaternion, result *math32.Box3)
+	// Inertia returns the diagonal of the inertia tensor in local space
+	// of a body with this shape and the specified mass
+	Inertia(mass float32) math32.Vector3
+}
+
+// Sphere is a sphere shape
+type Sphere struct {
+	radius float32 // radius of the sphere
+}
+
+// Box is a box shape
+type Box struct {
+	half math32.Vector3 // half of the size of the box along each axis
+}
+
+// Plane is an infinite plane shape which passes through the origin of its
+// body, as the ground, which can only be the shape of static or kinematic
+// bodies. The bodies collide with the side of its normal.
+type Plane struct {
+	normal math32.Vector3 // normal of the plane in local space
+}
+
+// NewSphere creates and returns a pointer to a new sphere shape with the specified radius
+func NewSphere(radius float32) *Sphere {
+
+	return &Sphere{radius: radius}
+}
+
+// Radius returns the radius of this sphere
+func (s *Sphere) Radius() float32 {
+
+	return s.radius
+}
+
+// BoundingBox satisfies the IShape interface
+func (s *Sphere) BoundingBox(pos *math32.Vector3, rot *math32.Quaternion, result *math32.Box3) {
+
+	result.Min.Set(pos.X-s.radius, pos.Y-s.radius, pos.Z-s.radius)
+	result.Max.Set(pos.X+s.radius, pos.Y+s.radius, pos.Z+s.radius)
+}
+
+// Inertia satisfies the IShape interface
+func (s *Sphere) Inertia(mass float32) math32.Vector3 {
+
+	i := 0.4 * mass * s.radius * s.radius
+	return math32.Vector3{i, i, i}
+}
+
+// NewBox creates and returns a pointer to a new box shape with the specified sizes
+func NewBox(width, height, depth float32) *Box {
+
+	return &Box{half: math32.Vector3{width / 2, height / 2, depth / 2}}
+}
+
+// HalfSize returns the half of the size of this box along each axis
+func (b *Box) HalfSize() math32.Vector3 {
+
+	return b.half
+}
+
+// BoundingBox satisfies the IShape interface
+func (b *Box) BoundingBox(pos *math32.Vector3, rot *math32.Quaternion, result *math32.Box3) {
+
+	var ext math32.Vector3
+	for i := 0; i < 3; i++ {
+		axis := boxAxis(rot, i)
+		h := b.half.Component(i)
+		ext.X += math32.Abs(axis.X) * h
+		ext.Y += math32.Abs(axis.Y) * h
+		ext.Z += math32.Abs(axis.Z) * h
+	}
+	result.Min.SubVectors(pos, &ext)
+	result.Max.AddVectors(pos, &ext)
+}
+
+// Inertia satisfies the IShape interface
+func (b *Box) Inertia(mass float32) math32.Vector3 {
+
+	x2 := 4 * b.half.X * b.half.X
+	y2 := 4 * b.half.Y * b.half.Y
+	z2 := 4 * b.half.Z * b.half.Z
+	return math32.Vector3{mass * (y2 + z2) / 12, mass * (x2 + z2) / 12, mass * (x2 + y2) / 12}
+}
+
+// NewPlane creates and returns a pointer to a new plane shape with the specified normal in local space
+func NewPlane(normal *math32.Vector3) *Plane {
+
+	p := new(Plane)
+	p.normal = *normal
+	p.normal.Normalize()
+	return p
+}
+
+// Normal returns the normal of this plane in local space
+func (p *Plane) Normal() math32.Vector3 {
+
+	return p.normal
+}
+
+// BoundingBox satisfies the IShape interface and sets the
+// specified box with an infinite box
+func (p *Plane) BoundingBox(pos *math32.Vector3, rot *math32.Quaternion, result *math32.Box3) {
+
+	inf := math32.Infinity
+	result.Min.Set(-inf, -inf, -inf)
+	result.Max.Set(inf, inf, inf)
+}
+
+// Inertia satisfies the IShape interface and returns
+// zero as a plane can not be the shape of a dynamic body
+func (p *Plane) Inertia(mass float32) math32.Vector3 {
+
+	return math32.Vector3{}
+}
+
+// boxAxis returns the specified local axis rotated by the specified rotation
+func boxAxis(rot *math32.Quaternion, i int) math32.Vector3 {
+
+	var axis math32.Vector3
+	axis.SetComponent(i, 1)
+	axis.ApplyQuaternion(rot)
+	return axis
+}
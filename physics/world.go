@@ -0,0 +1,607 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// Parameters of the contact solver
+const (
+	baumgarte        = 0.2   // fraction of the penetration corrected at each step
+	slop             = 0.005 // penetration allowed to keep the contacts between steps
+	bounceThreshold  = 1     // minimum approach velocity of the bounces
+	matchDistance    = 0.05  // maximum distance of the contacts of consecutive steps which are matched
+	defaultSleepLin  = 0.05  // default linear velocity below which the bodies are still
+	defaultSleepAng  = 0.05  // default angular velocity below which the bodies are still
+	defaultSleepTime = 0.5   // default time the bodies must be still to sleep
+)
+
+// World is a component which simulates the rigid bodies attached to its node
+// and to the nodes below it, normally the root node of a scene. The world is
+// advanced by a step at each call of its FixedUpdate method, by the fixed
+// updates of a core.Loop, and moves the nodes of the dynamic bodies.
+type World struct {
+	core.Component                        // Embedded component
+	bodies         []*Body                // bodies in this world
+	gravity        math32.Vector3         // gravity acceleration
+	iterations     int                    // iterations of the contact solver
+	sleepLin       float32                // linear velocity below which the bodies are still
+	sleepAng       float32                // angular velocity below which the bodies are still
+	sleepTime      float32                // time the bodies must be still to sleep
+	manifolds      map[bodyPair]*manifold // contacts of the last step by pair of bodies
+	order          []int                  // indices of the bodies sorted by the minimum X of their bounds
+}
+
+// bodyPair is the key of the manifold of two bodies
+type bodyPair struct {
+	a, b *Body
+}
+
+// NewWorld creates and returns a pointer to a new world with a gravity
+// of 9.8 down the Y axis and 10 iterations of the contact solver
+func NewWorld() *World {
+
+	w := new(World)
+	w.gravity.Set(0, -9.8, 0)
+	w.iterations = 10
+	w.sleepLin = defaultSleepLin
+	w.sleepAng = defaultSleepAng
+	w.sleepTime = defaultSleepTime
+	w.manifolds = make(map[bodyPair]*manifold)
+	return w
+}
+
+// SetGravity sets the gravity acceleration of the dynamic bodies
+func (w *World) SetGravity(gravity *math32.Vector3) {
+
+	w.gravity = *gravity
+	w.WakeAll()
+}
+
+// Gravity returns the gravity acceleration of the dynamic bodies
+func (w *World) Gravity() math32.Vector3 {
+
+	return w.gravity
+}
+
+// SetIterations sets the number of iterations of the contact solver at each step.
+// More iterations make the stacks of bodies more stable. The default is 10.
+func (w *World) SetIterations(iterations int) {
+
+	w.iterations = iterations
+}
+
+// Iterations returns the number of iterations of the contact solver
+func (w *World) Iterations() int {
+
+	return w.iterations
+}
+
+// SetSleepThresholds sets the linear and angular velocities below which the
+// bodies are still and the time the bodies of a group of touching bodies must
+// be still for the group to be put to sleep. The defaults are 0.05, 0.05 and
+// 0.5 seconds. A time of 0 disables the sleeping.
+func (w *World) SetSleepThresholds(linear, angular, time float32) {
+
+	w.sleepLin = linear
+	w.sleepAng = angular
+	w.sleepTime = time
+}
+
+// SleepThresholds returns the linear and angular velocities below which the
+// bodies are still and the time they must be still to sleep
+func (w *World) SleepThresholds() (float32, float32, float32) {
+
+	return w.sleepLin, w.sleepAng, w.sleepTime
+}
+
+// Bodies returns the list of the bodies in this world
+func (w *World) Bodies() []*Body {
+
+	return w.bodies
+}
+
+// WakeAll wakes all the bodies of this world
+func (w *World) WakeAll() {
+
+	for _, b := range w.bodies {
+		b.Wake()
+	}
+}
+
+// OnAttach satisfies the IComponent interface and adds to
+// this world the bodies attached to its node and to its children
+func (w *World) OnAttach() {
+
+	w.Node().Traverse(func(inode core.INode) bool {
+		n := inode.GetNode()
+		if !n.Attached() {
+			return true
+		}
+		for _, ic := range n.Components() {
+			if b, ok := ic.(*Body); ok && b.world == nil {
+				w.addBody(b)
+			}
+		}
+		return true
+	})
+}
+
+// OnDetach satisfies the IComponent interface and removes all the bodies
+func (w *World) OnDetach() {
+
+	for len(w.bodies) > 0 {
+		w.removeBody(w.bodies[len(w.bodies)-1])
+	}
+}
+
+// FixedUpdate satisfies the core.IFixedUpdater interface and advances
+// this world by a step of the specified time in seconds
+func (w *World) FixedUpdate(step float32) {
+
+	w.Step(step)
+}
+
+// Step advances the simulation of this world by the specified time in seconds
+// and sets the transforms of the nodes of the dynamic bodies which moved
+func (w *World) Step(dt float32) {
+
+	if dt <= 0 {
+		return
+	}
+	for i, b := range w.bodies {
+		b.index = i
+	}
+	w.readNodes(dt)
+	w.integrateForces(dt)
+	w.findContacts()
+	w.buildIslands()
+	w.solve(dt)
+	w.integrateVelocities(dt)
+	w.updateSleep(dt)
+	for _, b := range w.bodies {
+		if b.typ == Dynamic && !b.sleeping {
+			b.syncNode()
+		}
+	}
+}
+
+// addBody adds the specified body to this world
+func (w *World) addBody(b *Body) {
+
+	if b.world != nil {
+		if b.world == w {
+			return
+		}
+		b.world.removeBody(b)
+	}
+	b.world = w
+	b.readNode()
+	b.Wake()
+	w.bodies = append(w.bodies, b)
+}
+
+// removeBody removes the specified body and its contacts from this world
+func (w *World) removeBody(b *Body) {
+
+	for i, cur := range w.bodies {
+		if cur == b {
+			copy(w.bodies[i:], w.bodies[i+1:])
+			w.bodies[len(w.bodies)-1] = nil
+			w.bodies = w.bodies[:len(w.bodies)-1]
+			break
+		}
+	}
+	for key, m := range w.manifolds {
+		if m.a == b || m.b == b {
+			m.a.Wake()
+			m.b.Wake()
+			delete(w.manifolds, key)
+		}
+	}
+	b.world = nil
+}
+
+// readNodes sets the transforms of the static and kinematic bodies and of the
+// dynamic bodies moved by the application from their nodes. The velocities of
+// the kinematic bodies are computed from the motion of their nodes.
+func (w *World) readNodes(dt float32) {
+
+	for _, b := range w.bodies {
+		switch b.typ {
+		case Static:
+			if b.moved() {
+				b.readNode()
+				w.wakeTouching(b)
+			}
+		case Kinematic:
+			prevPos := b.pos
+			prevRot := b.rot
+			b.readNode()
+			b.vel.SubVectors(&b.pos, &prevPos).DivideScalar(dt)
+			// Angular velocity from the rotation since the previous step
+			prevRot.Inverse()
+			dq := b.rot
+			dq.Multiply(&prevRot)
+			if dq.W() < 0 {
+				dq.Set(-dq.X(), -dq.Y(), -dq.Z(), -dq.W())
+			}
+			axis := math32.Vector3{dq.X(), dq.Y(), dq.Z()}
+			sin := axis.Length()
+			b.angVel.Set(0, 0, 0)
+			if sin > 1e-6 {
+				angle := 2 * math32.Atan2(sin, dq.W())
+				b.angVel = *axis.MultiplyScalar(angle / (sin * dt))
+			}
+		case Dynamic:
+			if b.moved() {
+				b.readNode()
+				b.Wake()
+			}
+		}
+	}
+}
+
+// wakeTouching wakes the bodies in contact with the specified body
+func (w *World) wakeTouching(b *Body) {
+
+	for _, m := range w.manifolds {
+		if m.a == b || m.b == b {
+			m.a.Wake()
+			m.b.Wake()
+		}
+	}
+}
+
+// integrateForces updates the velocities of the awake dynamic
+// bodies from the gravity and the forces applied to them
+func (w *World) integrateForces(dt float32) {
+
+	for _, b := range w.bodies {
+		if b.typ != Dynamic || b.sleeping {
+			continue
+		}
+		var acc, dw math32.Vector3
+		acc.Copy(&b.force).MultiplyScalar(b.invMass).Add(&w.gravity).MultiplyScalar(dt)
+		b.vel.Add(&acc)
+		dw.Copy(&b.torque)
+		b.applyInvInertia(&dw)
+		b.angVel.Add(dw.MultiplyScalar(dt))
+		b.vel.MultiplyScalar(1 / (1 + dt*b.linDamping))
+		b.angVel.MultiplyScalar(1 / (1 + dt*b.angDamping))
+		b.force.Set(0, 0, 0)
+		b.torque.Set(0, 0, 0)
+	}
+}
+
+// findContacts finds the contacts of the pairs of bodies whose bounding boxes
+// overlap and at least one of which moves, sweeping the bounding boxes sorted
+// along the X axis, keeping the impulses of the contacts of the previous step
+func (w *World) findContacts() {
+
+	for _, b := range w.bodies {
+		b.shape.BoundingBox(&b.pos, &b.rot, &b.bounds)
+	}
+	w.order = w.order[:0]
+	for i := range w.bodies {
+		w.order = append(w.order, i)
+	}
+	sort.Slice(w.order, func(i, j int) bool {
+		return w.bodies[w.order[i]].bounds.Min.X < w.bodies[w.order[j]].bounds.Min.X
+	})
+
+	manifolds := make(map[bodyPair]*manifold, len(w.manifolds))
+	for i, ia := range w.order {
+		a := w.bodies[ia]
+		for _, ib := range w.order[i+1:] {
+			b := w.bodies[ib]
+			if b.bounds.Min.X > a.bounds.Max.X+margin {
+				break
+			}
+			if a.invMass == 0 && b.invMass == 0 {
+				continue
+			}
+			if !a.active() && !b.active() {
+				// Keeps the contacts of the sleeping bodies
+				if m, ok := w.manifolds[pairOf(a, b)]; ok {
+					manifolds[pairOf(a, b)] = m
+				}
+				continue
+			}
+			if !overlap(&a.bounds, &b.bounds) {
+				continue
+			}
+			key := pairOf(a, b)
+			contacts := collide(key.a, key.b, nil)
+			if len(contacts) == 0 {
+				continue
+			}
+			// Warm starts the contacts near the contacts of the previous step
+			if prev, ok := w.manifolds[key]; ok {
+				for k := range contacts {
+					c := &contacts[k]
+					min := float32(matchDistance * matchDistance)
+					for _, pc := range prev.contacts {
+						if d := pc.point.DistanceToSquared(&c.point); d < min {
+							min = d
+							c.impN = pc.impN
+							c.impT = pc.impT
+						}
+					}
+				}
+			}
+			manifolds[key] = &manifold{a: key.a, b: key.b, contacts: contacts}
+		}
+	}
+	w.manifolds = manifolds
+}
+
+// overlap returns if the specified bounding boxes are closer than the margin
+func overlap(a, b *math32.Box3) bool {
+
+	return a.Min.X <= b.Max.X+margin && b.Min.X <= a.Max.X+margin &&
+		a.Min.Y <= b.Max.Y+margin && b.Min.Y <= a.Max.Y+margin &&
+		a.Min.Z <= b.Max.Z+margin && b.Min.Z <= a.Max.Z+margin
+}
+
+// pairOf returns the key of the specified bodies in the order of the world
+func pairOf(a, b *Body) bodyPair {
+
+	if a.index < b.index {
+		return bodyPair{a, b}
+	}
+	return bodyPair{b, a}
+}
+
+// buildIslands groups the dynamic bodies in contact in islands and wakes the
+// islands with an awake body, as when a body falls on a sleeping stack
+func (w *World) buildIslands() {
+
+	for i, b := range w.bodies {
+		b.island = i
+	}
+	for _, m := range w.manifolds {
+		if m.a.typ == Dynamic && m.b.typ == Dynamic {
+			w.union(m.a.index, m.b.index)
+		}
+	}
+	for _, m := range w.manifolds {
+		// Sleeping bodies touched by moving kinematic bodies
+		if m.a.typ == Kinematic && m.a.active() {
+			m.b.Wake()
+		} else if m.b.typ == Kinematic && m.b.active() {
+			m.a.Wake()
+		}
+	}
+	awake := make(map[int]bool)
+	for i, b := range w.bodies {
+		b.island = w.find(i)
+		if b.typ == Dynamic && !b.sleeping {
+			awake[b.island] = true
+		}
+	}
+	for _, b := range w.bodies {
+		if b.typ == Dynamic && b.sleeping && awake[b.island] {
+			b.Wake()
+		}
+	}
+}
+
+// union merges the islands of the bodies with the specified indices
+func (w *World) union(i, j int) {
+
+	ri := w.find(i)
+	rj := w.find(j)
+	if ri != rj {
+		w.bodies[ri].island = rj
+	}
+}
+
+// find returns the index of the root body of the island of the body with the
+// specified index, compressing the path from the body to the root
+func (w *World) find(i int) int {
+
+	root := i
+	for w.bodies[root].island != root {
+		root = w.bodies[root].island
+	}
+	for w.bodies[i].island != root {
+		next := w.bodies[i].island
+		w.bodies[i].island = root
+		i = next
+	}
+	return root
+}
+
+// solve solves the contacts of the awake bodies with sequential impulses
+func (w *World) solve(dt float32) {
+
+	active := make([]*manifold, 0, len(w.manifolds))
+	for _, m := range w.manifolds {
+		if m.a.active() || m.b.active() {
+			active = append(active, m)
+		}
+	}
+	// Deterministic order of the manifolds
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].a != active[j].a {
+			return active[i].a.index < active[j].a.index
+		}
+		return active[i].b.index < active[j].b.index
+	})
+
+	for _, m := range active {
+		a, b := m.a, m.b
+		friction := math32.Sqrt(a.friction * b.friction)
+		restitution := math32.Max(a.restitution, b.restitution)
+		for k := range m.contacts {
+			c := &m.contacts[k]
+			c.rA.SubVectors(&c.point, &a.pos)
+			c.rB.SubVectors(&c.point, &b.pos)
+			c.massN = effectiveMass(a, b, &c.rA, &c.rB, &c.normal)
+			tangents(&c.normal, &c.tangents[0], &c.tangents[1])
+			for t := 0; t < 2; t++ {
+				c.massT[t] = effectiveMass(a, b, &c.rA, &c.rB, &c.tangents[t])
+			}
+			if c.depth < 0 {
+				// Speculative contact: the bodies may approach until they touch
+				c.bias = c.depth / dt
+			} else {
+				c.bias = baumgarte / dt * math32.Max(c.depth-slop, 0)
+			}
+			if vn := relativeVelocity(a, b, &c.rA, &c.rB, &c.normal); vn < -bounceThreshold {
+				c.bias = math32.Max(c.bias, -restitution*vn)
+			}
+			// Warm starting, within the friction limits
+			limit := friction * c.impN
+			var p, pt math32.Vector3
+			p.Copy(&c.normal).MultiplyScalar(c.impN)
+			for t := 0; t < 2; t++ {
+				c.impT[t] = math32.Clamp(c.impT[t], -limit, limit)
+				pt.Copy(&c.tangents[t]).MultiplyScalar(c.impT[t])
+				p.Add(&pt)
+			}
+			applyImpulses(a, b, &c.rA, &c.rB, &p)
+		}
+	}
+
+	for it := 0; it < w.iterations; it++ {
+		for _, m := range active {
+			a, b := m.a, m.b
+			friction := math32.Sqrt(a.friction * b.friction)
+			for k := range m.contacts {
+				c := &m.contacts[k]
+				var p math32.Vector3
+
+				// Friction
+				for t := 0; t < 2; t++ {
+					vt := relativeVelocity(a, b, &c.rA, &c.rB, &c.tangents[t])
+					limit := friction * c.impN
+					imp := math32.Clamp(c.impT[t]-vt*c.massT[t], -limit, limit)
+					p.Copy(&c.tangents[t]).MultiplyScalar(imp - c.impT[t])
+					c.impT[t] = imp
+					applyImpulses(a, b, &c.rA, &c.rB, &p)
+				}
+
+				// Normal
+				vn := relativeVelocity(a, b, &c.rA, &c.rB, &c.normal)
+				imp := math32.Max(c.impN+(c.bias-vn)*c.massN, 0)
+				p.Copy(&c.normal).MultiplyScalar(imp - c.impN)
+				c.impN = imp
+				applyImpulses(a, b, &c.rA, &c.rB, &p)
+			}
+		}
+	}
+}
+
+// integrateVelocities moves the awake dynamic bodies by their velocities
+func (w *World) integrateVelocities(dt float32) {
+
+	for _, b := range w.bodies {
+		if b.typ != Dynamic || b.sleeping {
+			continue
+		}
+		var dp math32.Vector3
+		dp.Copy(&b.vel).MultiplyScalar(dt)
+		b.pos.Add(&dp)
+		// q' = q + 0.5 * w * q * dt
+		var spin math32.Quaternion
+		spin.Set(b.angVel.X*dt/2, b.angVel.Y*dt/2, b.angVel.Z*dt/2, 0)
+		spin.Multiply(&b.rot)
+		b.rot.Set(b.rot.X()+spin.X(), b.rot.Y()+spin.Y(), b.rot.Z()+spin.Z(), b.rot.W()+spin.W())
+		b.rot.Normalize()
+	}
+}
+
+// updateSleep updates the time the dynamic bodies have been still and
+// puts to sleep the islands whose bodies have all been still long enough
+func (w *World) updateSleep(dt float32) {
+
+	if w.sleepTime <= 0 {
+		return
+	}
+	ready := make(map[int]bool)
+	for _, b := range w.bodies {
+		if b.typ != Dynamic || b.sleeping {
+			continue
+		}
+		if !b.canSleep || b.vel.LengthSq() > w.sleepLin*w.sleepLin || b.angVel.LengthSq() > w.sleepAng*w.sleepAng {
+			b.sleepTime = 0
+		} else {
+			b.sleepTime += dt
+		}
+		still := b.sleepTime >= w.sleepTime
+		if r, ok := ready[b.island]; ok {
+			still = still && r
+		}
+		ready[b.island] = still
+	}
+	for _, b := range w.bodies {
+		if b.typ == Dynamic && !b.sleeping && ready[b.island] {
+			b.Sleep()
+			b.syncNode()
+		}
+	}
+}
+
+// effectiveMass returns the inverse of the mass of the specified bodies
+// along the specified direction at the specified relative points
+func effectiveMass(a, b *Body, rA, rB, dir *math32.Vector3) float32 {
+
+	k := a.invMass + b.invMass
+	var t math32.Vector3
+	t.CrossVectors(rA, dir)
+	a.applyInvInertia(&t)
+	t.Cross(rA)
+	k += t.Dot(dir)
+	t.CrossVectors(rB, dir)
+	b.applyInvInertia(&t)
+	t.Cross(rB)
+	k += t.Dot(dir)
+	if k <= 0 {
+		return 0
+	}
+	return 1 / k
+}
+
+// relativeVelocity returns the velocity along the specified direction of the second
+// body relative to the first body at the contact with the specified relative points
+func relativeVelocity(a, b *Body, rA, rB, dir *math32.Vector3) float32 {
+
+	var va, vb math32.Vector3
+	va.CrossVectors(&a.angVel, rA).Add(&a.vel)
+	vb.CrossVectors(&b.angVel, rB).Add(&b.vel)
+	return vb.Sub(&va).Dot(dir)
+}
+
+// applyImpulses applies the specified impulse to the second body
+// and its opposite to the first body at the specified relative points
+func applyImpulses(a, b *Body, rA, rB, p *math32.Vector3) {
+
+	var neg math32.Vector3
+	neg.Copy(p).Negate()
+	if a.invMass > 0 {
+		a.applyImpulse(&neg, rA)
+	}
+	if b.invMass > 0 {
+		b.applyImpulse(p, rB)
+	}
+}
+
+// tangents sets the specified vectors with two unit vectors
+// perpendicular to each other and to the specified normal
+func tangents(n, t1, t2 *math32.Vector3) {
+
+	if math32.Abs(n.X) >= 0.57735 {
+		t1.Set(n.Y, -n.X, 0)
+	} else {
+		t1.Set(0, n.Z, -n.Y)
+	}
+	t1.Normalize()
+	t2.CrossVectors(n, t1)
+}
@@ -0,0 +1,419 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// batcher contains the state of the dynamic batching of small meshes.
+// Visible opaque meshes with the same material and vertex layout and with at
+// most maxVerts vertices are merged in a single mesh whose vertices are
+// transformed to world coordinates, so they are rendered with one draw call.
+// A batch is only rebuilt when its meshes or their world matrices change.
+// In multi-draw indirect mode the indexed batches keep the meshes which are
+// not visible in the current frame and only draw the index ranges of the
+// visible meshes, so batches of static scenes are rarely rebuilt.
+type batcher struct {
+	maxVerts int                     // maximum number of vertices of a batched mesh (0 = disabled)
+	indirect bool                    // multi-draw indirect mode
+	frame    int                     // current frame number
+	scene    *core.Node              // scene rendered in the current frame
+	batches  map[batchKey]*meshBatch // batches by material and layout
+	groups   map[batchKey][]int      // indices of the batchable graphic materials in the current frame
+	keys     []batchKey              // batch keys in order of first use in the current frame
+	layout   bytes.Buffer            // preallocated buffer to build layout strings
+}
+
+// batchKey identifies the meshes which may be merged in the same batch
+type batchKey struct {
+	imat    material.IMaterial // shared material
+	layout  string             // vertex attributes names and sizes
+	indexed bool               // geometries are indexed
+}
+
+// meshBatch is a mesh built from several meshes
+type meshBatch struct {
+	mesh     *graphic.Mesh         // mesh with the merged geometry
+	geom     *geometry.Geometry    // merged geometry
+	members  []*graphic.Mesh       // meshes merged in the last build
+	matrices []math32.Matrix4      // world matrices of the meshes in the last build
+	ranges   []batchRange          // index ranges of the meshes in the last build
+	index    map[*graphic.Mesh]int // index of each member (multi-draw indirect mode)
+	visible  []int                 // indices of the visible members in the current frame
+	cmds     gls.IndirectBuffer    // draw commands of the visible members (multi-draw indirect mode)
+	frame    int                   // last frame the batch was used
+}
+
+// batchRange is the range of indices of a member of a batch
+type batchRange struct {
+	first int // index of the first index
+	count int // number of indices
+}
+
+// batchExpire is the number of frames after which an unused batch is released
+const batchExpire = 120
+
+// SetBatching sets the maximum number of vertices of the opaque meshes which
+// may be merged with other meshes with the same material in a single draw call.
+// It should only be used for static geometries, as changes of the geometries of
+// batched meshes are not detected. Set to 0 to disable batching (the default).
+func (r *Renderer) SetBatching(maxVertices int) {
+
+	r.batch.maxVerts = maxVertices
+	if maxVertices <= 0 {
+		r.releaseBatches(0)
+	}
+}
+
+// Batching returns the maximum number of vertices of batched meshes
+func (r *Renderer) Batching() int {
+
+	return r.batch.maxVerts
+}
+
+// SetMultiDrawIndirect sets the multi-draw indirect mode of batching.
+// In this mode the indexed batches contain all the meshes batched in previous
+// frames, also the ones which are not visible, and draw the index ranges of
+// the visible meshes with a single glMultiDrawElementsIndirect call, so they
+// are only rebuilt when a new mesh appears or a world matrix changes.
+// It is used for large static scenes. If the OpenGL context does not support
+// multi-draw indirect rendering the ranges are drawn one by one.
+func (r *Renderer) SetMultiDrawIndirect(state bool) {
+
+	if state != r.batch.indirect {
+		r.batch.indirect = state
+		r.releaseBatches(0)
+	}
+}
+
+// MultiDrawIndirect returns the multi-draw indirect mode of batching
+func (r *Renderer) MultiDrawIndirect() bool {
+
+	return r.batch.indirect
+}
+
+// batchOpaque merges the batchable opaque graphic materials of the current
+// frame of the specified scene, replacing them by the graphic material of
+// their batch, which is placed at the position of the first merged graphic material.
+func (r *Renderer) batchOpaque(scene *core.Node) {
+
+	b := &r.batch
+	if b.maxVerts <= 0 {
+		return
+	}
+	b.frame++
+	b.scene = scene
+	if b.batches == nil {
+		b.batches = make(map[batchKey]*meshBatch)
+		b.groups = make(map[batchKey][]int)
+	}
+	for _, key := range b.keys {
+		b.groups[key] = b.groups[key][0:0]
+	}
+	b.keys = b.keys[0:0]
+
+	// Groups the batchable graphic materials
+	for i, grmat := range r.grmats {
+		key, ok := b.batchable(grmat)
+		if !ok {
+			continue
+		}
+		group := b.groups[key]
+		if len(group) == 0 {
+			b.keys = append(b.keys, key)
+		}
+		b.groups[key] = append(group, i)
+	}
+
+	// Builds the batches and replaces the merged graphic materials
+	merged := false
+	for _, key := range b.keys {
+		group := b.groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		mb := b.batches[key]
+		if mb == nil {
+			mb = newMeshBatch(key.imat, b.indirect && key.indexed)
+			b.batches[key] = mb
+		}
+		if mb.index != nil {
+			mb.updateIndirect(b, key, r.grmats, group)
+		} else {
+			mb.update(r.grmats, group)
+		}
+		mb.frame = b.frame
+		r.grmats[group[0]] = &mb.mesh.Materials()[0]
+		for _, idx := range group[1:] {
+			r.grmats[idx] = nil
+		}
+		r.Stats.Batched += len(group)
+		merged = true
+	}
+	if merged {
+		count := 0
+		for _, grmat := range r.grmats {
+			if grmat != nil {
+				r.grmats[count] = grmat
+				count++
+			}
+		}
+		r.grmats = r.grmats[:count]
+	}
+	r.releaseBatches(batchExpire)
+}
+
+// releaseBatches releases the batches not used in the specified number of frames
+func (r *Renderer) releaseBatches(frames int) {
+
+	b := &r.batch
+	for key, mb := range b.batches {
+		if b.frame-mb.frame >= frames {
+			mb.geom.Dispose()
+			mb.cmds.Dispose()
+			delete(b.batches, key)
+		}
+	}
+}
+
+// batchable returns the batch key of the specified graphic material
+// and if it may be batched.
+func (b *batcher) batchable(grmat *graphic.GraphicMaterial) (batchKey, bool) {
+
+	mesh, ok := grmat.GetGraphic().(*graphic.Mesh)
+	if !ok || len(mesh.Materials()) != 1 {
+		return batchKey{}, false
+	}
+	if _, count := grmat.Range(); count != 0 {
+		return batchKey{}, false
+	}
+	geom := mesh.GetGeometry()
+	if geom.Items() > b.maxVerts {
+		return batchKey{}, false
+	}
+	b.layout.Reset()
+	for i := 0; ; i++ {
+		vbo := geom.VBOAt(i)
+		if vbo == nil {
+			break
+		}
+		if vbo.AttribCount() != 1 {
+			return batchKey{}, false
+		}
+		attr := vbo.AttribAt(0)
+		fmt.Fprintf(&b.layout, "%s:%d;", attr.Name, attr.ItemSize)
+	}
+	if b.layout.Len() == 0 {
+		return batchKey{}, false
+	}
+	indices := geom.Indices()
+	return batchKey{grmat.GetMaterial(), b.layout.String(), indices.Size() > 0}, true
+}
+
+// retainable returns if the specified mesh, which is not visible in the
+// current frame, may be kept in the batch with the specified key: it must
+// still be in the scene and be batchable with the same key, which excludes
+// the meshes whose geometry or material was disposed or changed.
+func (b *batcher) retainable(mesh *graphic.Mesh, key batchKey) bool {
+
+	node := mesh.GetNode()
+	for node.Parent() != nil {
+		node = node.Parent().GetNode()
+	}
+	if node != b.scene {
+		return false
+	}
+	materials := mesh.Materials()
+	if len(materials) != 1 {
+		return false
+	}
+	mkey, ok := b.batchable(&materials[0])
+	return ok && mkey == key
+}
+
+// newMeshBatch creates and returns a pointer to a new empty batch with the
+// specified material, which draws the visible members with indirect draw
+// commands if indirect is true.
+func newMeshBatch(imat material.IMaterial, indirect bool) *meshBatch {
+
+	mb := new(meshBatch)
+	mb.geom = geometry.NewGeometry()
+	mb.mesh = graphic.NewMesh(mb.geom, imat)
+	mb.members = make([]*graphic.Mesh, 0)
+	mb.matrices = make([]math32.Matrix4, 0)
+	mb.ranges = make([]batchRange, 0)
+	mb.cmds.Init()
+	if indirect {
+		mb.index = make(map[*graphic.Mesh]int)
+		mb.mesh.SetIndirect(&mb.cmds)
+	}
+	return mb
+}
+
+// update rebuilds the merged geometry of this batch from the meshes of the
+// graphic materials with the specified indices, if they changed since the last build.
+func (mb *meshBatch) update(grmats []*graphic.GraphicMaterial, group []int) {
+
+	changed := len(group) != len(mb.members)
+	for i, idx := range group {
+		if changed {
+			break
+		}
+		mesh := grmats[idx].GetGraphic().(*graphic.Mesh)
+		changed = mesh != mb.members[i] || mesh.MatrixWorld() != mb.matrices[i]
+	}
+	if !changed {
+		return
+	}
+	mb.members = mb.members[0:0]
+	mb.matrices = mb.matrices[0:0]
+	for _, idx := range group {
+		mesh := grmats[idx].GetGraphic().(*graphic.Mesh)
+		mb.members = append(mb.members, mesh)
+		mb.matrices = append(mb.matrices, mesh.MatrixWorld())
+	}
+	mb.build()
+}
+
+// updateIndirect updates this batch in multi-draw indirect mode from the
+// meshes of the graphic materials with the specified indices. The merged
+// geometry is only rebuilt if a mesh is not a member or its world matrix
+// changed, keeping the members which are not visible unless they are more
+// than the visible ones or are no longer retainable with the specified key.
+// The draw commands are rebuilt from the index ranges of the visible members,
+// merging the contiguous ranges.
+func (mb *meshBatch) updateIndirect(b *batcher, key batchKey, grmats []*graphic.GraphicMaterial, group []int) {
+
+	changed := false
+	for _, idx := range group {
+		mesh := grmats[idx].GetGraphic().(*graphic.Mesh)
+		i, ok := mb.index[mesh]
+		if !ok || mesh.MatrixWorld() != mb.matrices[i] {
+			changed = true
+			break
+		}
+	}
+	if changed {
+		old := append([]*graphic.Mesh(nil), mb.members...)
+		mb.members = mb.members[0:0]
+		for k := range mb.index {
+			delete(mb.index, k)
+		}
+		for _, idx := range group {
+			mesh := grmats[idx].GetGraphic().(*graphic.Mesh)
+			mb.index[mesh] = len(mb.members)
+			mb.members = append(mb.members, mesh)
+		}
+		if len(old) <= 2*len(group) {
+			for _, mesh := range old {
+				if _, ok := mb.index[mesh]; !ok && b.retainable(mesh, key) {
+					mb.index[mesh] = len(mb.members)
+					mb.members = append(mb.members, mesh)
+				}
+			}
+		}
+		mb.matrices = mb.matrices[0:0]
+		for _, mesh := range mb.members {
+			mb.matrices = append(mb.matrices, mesh.MatrixWorld())
+		}
+		mb.build()
+	}
+
+	// Builds the draw commands of the visible members in index order
+	mb.visible = mb.visible[0:0]
+	for _, idx := range group {
+		mb.visible = append(mb.visible, mb.index[grmats[idx].GetGraphic().(*graphic.Mesh)])
+	}
+	sort.Ints(mb.visible)
+	mb.cmds.Reset()
+	for _, i := range mb.visible {
+		mb.cmds.AddRange(mb.ranges[i].first, mb.ranges[i].count)
+	}
+}
+
+// build merges the geometries of the current members of this batch
+func (mb *meshBatch) build() {
+
+	// Creates the VBOs of the merged geometry from the layout of the first member
+	first := mb.members[0].GetGeometry()
+	if mb.geom.VBOAt(0) == nil {
+		for i := 0; ; i++ {
+			vbo := first.VBOAt(i)
+			if vbo == nil {
+				break
+			}
+			attr := vbo.AttribAt(0)
+			mb.geom.AddVBO(gls.NewVBO().AddAttrib(attr.Name, attr.ItemSize).SetBuffer(math32.NewArrayF32(0, 0)))
+		}
+	}
+	for i := 0; ; i++ {
+		vbo := mb.geom.VBOAt(i)
+		if vbo == nil {
+			break
+		}
+		buf := vbo.Buffer()
+		*buf = (*buf)[0:0]
+	}
+	indices := mb.geom.Indices()
+	indices = indices[0:0]
+
+	// Appends the vertices of each member transformed to world coordinates
+	var vec math32.Vector3
+	var nm math32.Matrix3
+	base := uint32(0)
+	mb.ranges = mb.ranges[0:0]
+	for m, mesh := range mb.members {
+		geom := mesh.GetGeometry()
+		mw := &mb.matrices[m]
+		nm.GetNormalMatrix(mw)
+		for i := 0; ; i++ {
+			src := geom.VBOAt(i)
+			if src == nil {
+				break
+			}
+			dst := mb.geom.VBOAt(i)
+			sbuf := src.Buffer()
+			dbuf := dst.Buffer()
+			switch src.AttribAt(0).Name {
+			case "VertexPosition":
+				for j := 0; j+2 < sbuf.Size(); j += 3 {
+					sbuf.GetVector3(j, &vec)
+					vec.ApplyMatrix4(mw)
+					dbuf.AppendVector3(&vec)
+				}
+			case "VertexNormal":
+				for j := 0; j+2 < sbuf.Size(); j += 3 {
+					sbuf.GetVector3(j, &vec)
+					vec.ApplyMatrix3(&nm).Normalize()
+					dbuf.AppendVector3(&vec)
+				}
+			default:
+				dbuf.Append((*sbuf)...)
+			}
+		}
+		mb.ranges = append(mb.ranges, batchRange{indices.Size(), len(geom.Indices())})
+		for _, idx := range geom.Indices() {
+			indices.Append(base + idx)
+		}
+		base += uint32(geom.Items())
+	}
+	for i := 0; ; i++ {
+		vbo := mb.geom.VBOAt(i)
+		if vbo == nil {
+			break
+		}
+		vbo.Update()
+	}
+	mb.geom.SetIndices(indices)
+}
@@ -0,0 +1,209 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// occlusionCuller contains the state of the hardware occlusion query culling.
+// The world bounding box of each candidate node is rendered after the opaque
+// objects with an occlusion query and the result is read in a later frame,
+// so the rendering is never stalled waiting for query results.
+type occlusionCuller struct {
+	enabled bool                           // occlusion culling enabled flag
+	frames  int                            // number of consecutive occluded results before culling
+	frame   int                            // current frame number
+	states  map[*core.Node]*occlusionState // occlusion state for each tested node
+	nodes   []*core.Node                   // nodes to be tested in the current frame
+	vao     uint32                         // empty VAO used to draw the boxes
+	specs   ShaderSpecs                    // specs of the box shader program
+	state   gls.PipelineState              // pipeline state of the boxes
+	uniMVP  gls.UniformMatrix4f            // projection * view matrix uniform
+	uniMin  gls.Uniform3f                  // box minimum corner uniform
+	uniSize gls.Uniform3f                  // box size uniform
+}
+
+// occlusionState contains the occlusion query state of a node
+type occlusionState struct {
+	query    uint32 // query object handle
+	pending  bool   // query was issued and its result was not read yet
+	occluded int    // number of consecutive frames the node was reported occluded
+	frame    int    // last frame the node was tested
+}
+
+// occlusionMargin is the fraction of the box size added to each side of
+// the tested boxes so the test is conservative.
+const occlusionMargin = 0.05
+
+// occlusionExpire is the number of frames after which the state of a node
+// which is no longer tested is released.
+const occlusionExpire = 120
+
+// SetOcclusionCulling sets if graphics fully occluded by other objects, as reported
+// by hardware occlusion queries of their bounding boxes, should be culled.
+// Query results are read one or more frames later, so newly visible objects may
+// appear one frame late. It is disabled by default.
+func (r *Renderer) SetOcclusionCulling(state bool) {
+
+	r.occl.enabled = state
+	if !state {
+		r.releaseOcclusion()
+	}
+}
+
+// OcclusionCulling returns the state of the occlusion culling flag
+func (r *Renderer) OcclusionCulling() bool {
+
+	return r.occl.enabled
+}
+
+// SetOcclusionHysteresis sets the number of consecutive frames a graphic must be
+// reported occluded before it is culled, avoiding popping of objects at the edges
+// of occluders. The default value is 3.
+func (r *Renderer) SetOcclusionHysteresis(frames int) {
+
+	if frames < 1 {
+		frames = 1
+	}
+	r.occl.frames = frames
+}
+
+// occluded returns if the specified node should be culled because it was
+// reported occluded in previous frames, and schedules its next occlusion test.
+func (r *Renderer) occluded(node *core.Node) bool {
+
+	oc := &r.occl
+	if !oc.enabled || node.NeverCull() {
+		return false
+	}
+	sphere, ok := node.WorldBoundingSphere()
+	if !ok {
+		return false
+	}
+
+	// Gets or creates the occlusion state of the node
+	st := oc.states[node]
+	if st == nil {
+		st = new(occlusionState)
+		st.query = r.dev.CreateQuery()
+		oc.states[node] = st
+	}
+	st.frame = oc.frame
+
+	// Reads the result of the previous query if available
+	if st.pending && r.dev.QueryAvailable(st.query) {
+		st.pending = false
+		if r.dev.QueryResult(st.query) != 0 {
+			st.occluded = 0
+		} else {
+			st.occluded++
+		}
+	}
+
+	// The box test is not valid if the camera is inside the tested box
+	var box math32.Box3
+	occlusionBox(&sphere, &box)
+	if box.ContainsPoint(&r.camPos) {
+		st.occluded = 0
+		return false
+	}
+	if !st.pending {
+		oc.nodes = append(oc.nodes, node)
+	}
+	return st.occluded >= oc.frames
+}
+
+// queryOcclusion renders the bounding boxes of the nodes scheduled for occlusion
+// tests in the current frame. It must be called after the opaque objects are rendered.
+func (r *Renderer) queryOcclusion() error {
+
+	oc := &r.occl
+	if !oc.enabled {
+		return nil
+	}
+	r.expireOcclusion()
+	if len(oc.nodes) == 0 {
+		return nil
+	}
+	if oc.vao == 0 {
+		oc.vao = r.dev.CreateVertexArray()
+		oc.specs.Name = "shaderOcclusionBox"
+		// Tests the depth of both faces of the boxes without writing it
+		oc.state.FrontFace = gls.CCW
+		oc.state.DepthTest = true
+		oc.state.DepthFunc = gls.LEQUAL
+		oc.state.LineWidth = 1
+		oc.uniMVP.Init("MVP")
+		oc.uniMin.Init("BoxMin")
+		oc.uniSize.Init("BoxSize")
+	}
+	_, err := r.shaman.SetProgram(&oc.specs)
+	if err != nil {
+		return err
+	}
+
+	// Renders the boxes only testing the depth buffer
+	r.dev.ColorMask(false, false, false, false)
+	r.dev.SetPipelineState(&oc.state)
+	r.dev.BindVertexArray(oc.vao)
+	oc.uniMVP.SetMatrix4(&r.projView)
+	oc.uniMVP.Transfer(r.dev)
+
+	var box math32.Box3
+	var size math32.Vector3
+	for _, node := range oc.nodes {
+		st := oc.states[node]
+		sphere, _ := node.WorldBoundingSphere()
+		occlusionBox(&sphere, &box)
+		box.Size(&size)
+		oc.uniMin.SetVector3(&box.Min)
+		oc.uniMin.Transfer(r.dev)
+		oc.uniSize.SetVector3(&size)
+		oc.uniSize.Transfer(r.dev)
+		r.dev.BeginQuery(gls.ANY_SAMPLES_PASSED, st.query)
+		r.dev.Draw(gls.TRIANGLE_STRIP, 0, 14)
+		r.dev.EndQuery(gls.ANY_SAMPLES_PASSED)
+		st.pending = true
+	}
+	r.dev.ColorMask(true, true, true, true)
+	r.dev.DepthMask(true)
+	return nil
+}
+
+// occlusionBox sets the specified box with the box tested for the occlusion
+// of the specified world bounding sphere, enlarged by occlusionMargin
+func occlusionBox(sphere *math32.Sphere, box *math32.Box3) {
+
+	var size math32.Vector3
+	sphere.GetBoundingBox(box)
+	box.Size(&size)
+	box.ExpandByScalar(math32.Max(size.X, math32.Max(size.Y, size.Z)) * occlusionMargin)
+}
+
+// expireOcclusion releases the occlusion states of nodes not tested recently
+func (r *Renderer) expireOcclusion() {
+
+	oc := &r.occl
+	for node, st := range oc.states {
+		if oc.frame-st.frame > occlusionExpire {
+			r.dev.DestroyQuery(st.query)
+			delete(oc.states, node)
+		}
+	}
+}
+
+// releaseOcclusion releases all the occlusion states
+func (r *Renderer) releaseOcclusion() {
+
+	oc := &r.occl
+	for node, st := range oc.states {
+		r.dev.DestroyQuery(st.query)
+		delete(oc.states, node)
+	}
+	oc.nodes = oc.nodes[0:0]
+}
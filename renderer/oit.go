@@ -0,0 +1,164 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"sort"
+)
+
+// oitPass contains the state of the weighted blended
+// order independent transparency pass.
+type oitPass struct {
+	target    *RenderTarget     // accumulation (RGBA16F) and weight (R16F) targets
+	vao       uint32            // empty VAO used to draw the full screen triangle
+	specs     ShaderSpecs       // specs of the composite shader program
+	state     gls.PipelineState // pipeline state of the composite
+	supported bool              // false if OIT is not supported by the device
+}
+
+// SetOIT sets if the specified scene should render its transparent materials
+// using weighted blended order independent transparency (OIT), which does not
+// depend on the drawing order and handles intersecting transparent meshes.
+// If OIT is not supported, transparent materials are sorted back to front.
+func (r *Renderer) SetOIT(iscene core.INode, state bool) {
+
+	if state {
+		r.oitScenes[iscene.GetNode()] = true
+	} else {
+		delete(r.oitScenes, iscene.GetNode())
+	}
+}
+
+// OIT returns if order independent transparency is enabled for the specified scene
+func (r *Renderer) OIT(iscene core.INode) bool {
+
+	return r.oitScenes[iscene.GetNode()]
+}
+
+// renderOIT renders the current transparent graphic materials into the OIT
+// accumulation targets and composites the result over the current framebuffer.
+// Returns false if OIT is not supported and the materials were not rendered.
+func (r *Renderer) renderOIT() (bool, error) {
+
+	// Creates the OIT pass on first use checking if it is supported
+	if r.oit == nil {
+		r.oit = new(oitPass)
+		r.oit.supported = r.dev.GetInteger(gls.MAX_DRAW_BUFFERS) >= 2
+		if !r.oit.supported {
+			log.Warn("Order independent transparency not supported: using sorted blending")
+			return false, nil
+		}
+		r.oit.target = NewRenderTarget(1, 1)
+		r.oit.target.SetFormat(gls.RGBA16F, gls.RGBA, gls.HALF_FLOAT)
+		r.oit.target.AddColorAttachment(gls.R16F, gls.RED, gls.HALF_FLOAT)
+		r.oit.vao = r.dev.CreateVertexArray()
+		r.oit.specs.Name = "shaderOITComposite"
+		// Blends the composite over the opaque scene without depth test
+		r.oit.state.FrontFace = gls.CCW
+		r.oit.state.DepthFunc = gls.LEQUAL
+		r.oit.state.LineWidth = 1
+		r.oit.state.Blend = true
+		r.oit.state.BlendEquationRGB = gls.FUNC_ADD
+		r.oit.state.BlendEquationAlpha = gls.FUNC_ADD
+		r.oit.state.SetBlendFunc(gls.SRC_ALPHA, gls.ONE_MINUS_SRC_ALPHA)
+	}
+	if !r.oit.supported {
+		return false, nil
+	}
+	oit := r.oit
+
+	// Saves the current framebuffer and viewport
+	fbo := uint32(r.dev.GetInteger(gls.DRAW_FRAMEBUFFER_BINDING))
+	vx, vy, vw, vh := r.dev.GetViewport()
+
+	// Binds the OIT targets with the size of the current viewport
+	oit.target.SetSize(int(vw), int(vh))
+	err := oit.target.Bind(r.dev)
+	if err != nil {
+		log.Warn("Order independent transparency not supported (%v): using sorted blending", err)
+		oit.supported = false
+		r.dev.BindFramebuffer(gls.FRAMEBUFFER, fbo)
+		r.dev.Viewport(vx, vy, vw, vh)
+		return false, nil
+	}
+
+	// Copies the depth buffer of the opaque scene so transparent
+	// fragments behind opaque objects are discarded.
+	r.dev.BindFramebuffer(gls.READ_FRAMEBUFFER, fbo)
+	r.dev.BlitFramebuffer(vx, vy, vx+vw, vy+vh, 0, 0, vw, vh, gls.DEPTH_BUFFER_BIT, gls.NEAREST)
+	r.dev.BindFramebuffer(gls.FRAMEBUFFER, oit.target.fbo)
+
+	// Clears accumulation to zero and revealage to one
+	r.dev.ClearBuffer(gls.COLOR, 0, 0, 0, 0, 1)
+	r.dev.ClearBuffer(gls.COLOR, 1, 0, 0, 0, 0)
+
+	// Accumulates all transparent materials
+	r.rinfo.OIT = true
+	for _, grmat := range r.transp {
+		err = r.renderGraphicMaterial(grmat)
+		if err != nil {
+			break
+		}
+	}
+	r.rinfo.OIT = false
+	r.dev.BindFramebuffer(gls.FRAMEBUFFER, fbo)
+	r.dev.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		return true, err
+	}
+
+	// Composites the accumulated colors over the opaque scene
+	_, err = r.shaman.SetProgram(&oit.specs)
+	if err != nil {
+		return true, err
+	}
+	r.dev.SetPipelineState(&oit.state)
+	for i := 0; i < 2; i++ {
+		r.dev.BindTextureUnit(i, oit.target.ColorTextureAt(i).TexName(r.dev))
+	}
+	r.dev.Uniform1i(r.dev.UniformLocation("AccumColor"), 0)
+	r.dev.Uniform1i(r.dev.UniformLocation("AccumWeight"), 1)
+	r.dev.BindVertexArray(oit.vao)
+	r.dev.Draw(gls.TRIANGLES, 0, 3)
+	r.dev.DepthMask(true)
+	return true, nil
+}
+
+// sortTransparent sorts the current transparent graphic materials
+// from back to front relative to the camera.
+func (r *Renderer) sortTransparent() {
+
+	sorter := transpSorter{grmats: r.transp, depths: make([]float32, len(r.transp))}
+	for i, grmat := range r.transp {
+		sorter.depths[i] = -r.viewDepth(grmat)
+	}
+	sort.Stable(&sorter)
+}
+
+// transpSorter implements sort.Interface for graphic materials by view depth
+type transpSorter struct {
+	grmats []*graphic.GraphicMaterial
+	depths []float32
+}
+
+func (ts *transpSorter) Len() int {
+
+	return len(ts.grmats)
+}
+
+func (ts *transpSorter) Less(i, j int) bool {
+
+	// The camera looks down the negative Z axis so farther objects have lower Z
+	return ts.depths[i] < ts.depths[j]
+}
+
+func (ts *transpSorter) Swap(i, j int) {
+
+	ts.grmats[i], ts.grmats[j] = ts.grmats[j], ts.grmats[i]
+	ts.depths[i], ts.depths[j] = ts.depths[j], ts.depths[i]
+}
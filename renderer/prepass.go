@@ -0,0 +1,123 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// depthPrepass contains the state of the depth pre-pass
+type depthPrepass struct {
+	nodes  map[*core.Node]bool // scenes and cameras with the pre-pass enabled
+	done   []bool              // opaque graphic materials rendered in the pre-pass in the current frame
+	specs  ShaderSpecs         // specs of the depth only shader program
+	uniMVP gls.UniformMatrix4f // model view projection matrix uniform
+}
+
+// depthPrepassShaders are the shaders which calculate the vertex position
+// exactly as the depth only shader and may be used after the pre-pass
+var depthPrepassShaders = map[string]bool{
+	"shaderStandard": true,
+	"shaderPhong":    true,
+	"shaderBasic":    true,
+}
+
+// SetDepthPrepass sets if the opaque graphics should be rendered first into
+// the depth buffer only, when rendering the specified scene or when rendering
+// with the specified camera. The shaded pass which follows only executes the
+// fragment shaders of the visible fragments, avoiding the cost of overdraw with
+// heavy fragment shaders at the cost of drawing the geometry twice.
+// Only meshes whose materials use the standard, phong or basic shaders and
+// write into the depth buffer are rendered in the pre-pass.
+func (r *Renderer) SetDepthPrepass(inode core.INode, state bool) {
+
+	if r.prepass.nodes == nil {
+		r.prepass.nodes = make(map[*core.Node]bool)
+	}
+	if state {
+		r.prepass.nodes[inode.GetNode()] = true
+	} else {
+		delete(r.prepass.nodes, inode.GetNode())
+	}
+}
+
+// DepthPrepass returns if the depth pre-pass is enabled for the specified scene or camera
+func (r *Renderer) DepthPrepass(inode core.INode) bool {
+
+	return r.prepass.nodes[inode.GetNode()]
+}
+
+// useDepthPrepass returns if the depth pre-pass should be used
+// for the specified scene and camera
+func (r *Renderer) useDepthPrepass(scene *core.Node, icam camera.ICamera) bool {
+
+	if len(r.prepass.nodes) == 0 {
+		return false
+	}
+	return r.prepass.nodes[scene] || r.prepass.nodes[icam.GetCamera().GetNode()]
+}
+
+// renderDepthPrepass renders the depth of the current opaque graphic materials
+// which support the pre-pass and marks them as done.
+func (r *Renderer) renderDepthPrepass() error {
+
+	pp := &r.prepass
+	pp.done = pp.done[0:0]
+	count := 0
+	for _, grmat := range r.grmats {
+		ok := depthPrepassable(grmat)
+		pp.done = append(pp.done, ok)
+		if ok {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	if pp.specs.Name == "" {
+		pp.specs.Name = "shaderDepth"
+		pp.uniMVP.Init("MVP")
+	}
+	_, err := r.shaman.SetProgram(&pp.specs)
+	if err != nil {
+		return err
+	}
+
+	// Renders only into the depth buffer
+	r.dev.ColorMask(false, false, false, false)
+	var mvm, mvpm math32.Matrix4
+	for i, grmat := range r.grmats {
+		if !pp.done[i] {
+			continue
+		}
+		grmat.GetMaterial().GetMaterial().DepthRenderSetup(r.dev)
+		// Same operations as the mesh so the resulting depths are the same
+		mw := grmat.GetGraphic().GetNode().MatrixWorld()
+		mvm.MultiplyMatrices(&r.rinfo.ViewMatrix, &mw)
+		mvpm.MultiplyMatrices(&r.rinfo.ProjMatrix, &mvm)
+		pp.uniMVP.SetMatrix4(&mvpm)
+		pp.uniMVP.Transfer(r.dev)
+		grmat.RenderGeometry(r.dev)
+	}
+	r.dev.ColorMask(true, true, true, true)
+	return nil
+}
+
+// depthPrepassable returns if the specified graphic material may be rendered in the depth pre-pass
+func depthPrepassable(grmat *graphic.GraphicMaterial) bool {
+
+	if _, ok := grmat.GetGraphic().(*graphic.Mesh); !ok {
+		return false
+	}
+	if grmat.GetGraphic().GetNode().NeverCull() {
+		return false
+	}
+	mat := grmat.GetMaterial().GetMaterial()
+	return depthPrepassShaders[mat.Shader()] && mat.DepthTest() && mat.DepthMask() && !mat.Wireframe()
+}
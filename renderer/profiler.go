@@ -0,0 +1,262 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/g3n/engine/gls"
+	"time"
+)
+
+// Names of the render passes measured by the profiler.
+// The depth, opaque, occlusion and transparent passes are measured by the renderer.
+// The other names are used by applications which render these passes.
+const (
+	PassFrame       = "frame"
+	PassShadows     = "shadows"
+	PassDepth       = "depth"
+	PassOpaque      = "opaque"
+	PassOcclusion   = "occlusion"
+	PassTransparent = "transparent"
+	PassPost        = "post"
+	PassGUI         = "gui"
+)
+
+// profilerFrames is the number of frames buffered by the profiler.
+// The GPU timestamps of a frame are read this number of frames later,
+// so the CPU is never stalled waiting for the GPU.
+const profilerFrames = 4
+
+// PassTime contains the last measured times of a render pass
+type PassTime struct {
+	Name  string        // Pass name
+	GPU   time.Duration // Time spent by the GPU executing the pass commands
+	CPU   time.Duration // Time spent by the CPU submitting the pass commands
+	Frame int           // Number of the frame in which the times were measured
+}
+
+// Profiler measures the CPU and GPU time of each render pass of a frame
+// using the timestamp queries of a device. Passes are identified by name and may be nested.
+// If the GPU time of the frame is much larger than its CPU time the
+// application is GPU bound.
+type Profiler struct {
+	dev     gls.IDevice                   // Device of the queries
+	enabled bool                          // Enabled state
+	slots   [profilerFrames]profilerFrame // Ring buffer of frames being measured
+	current int                           // Index of the current frame slot
+	frame   int                           // Current frame number
+	names   []string                      // Pass names in order of first use
+	results map[string]*PassTime          // Last results for each pass name
+}
+
+// profilerFrame contains the measurements of a frame
+type profilerFrame struct {
+	frame   int            // frame number
+	pending bool           // frame has measurements not read yet
+	queries []uint32       // pool of query objects
+	next    int            // index of next free query in the pool
+	marks   []profilerMark // measured passes
+}
+
+// profilerMark contains the measurements of one pass in a frame
+type profilerMark struct {
+	name     string        // pass name
+	begin    uint32        // begin timestamp query
+	end      uint32        // end timestamp query
+	cpuStart time.Time     // CPU time at the beginning of the pass
+	cpu      time.Duration // CPU time of the pass
+	open     bool          // pass was begun but not ended yet
+}
+
+// NewProfiler creates and returns a pointer to a new enabled profiler
+func NewProfiler(dev gls.IDevice) *Profiler {
+
+	p := new(Profiler)
+	p.dev = dev
+	p.enabled = true
+	p.current = -1
+	p.names = make([]string, 0)
+	p.results = make(map[string]*PassTime)
+	return p
+}
+
+// SetEnabled sets the enabled state of this profiler.
+// When disabled no queries are issued.
+func (p *Profiler) SetEnabled(state bool) {
+
+	p.enabled = state
+}
+
+// Enabled returns the enabled state of this profiler
+func (p *Profiler) Enabled() bool {
+
+	return p.enabled
+}
+
+// BeginFrame must be called at the beginning of each frame before any pass.
+// It reads the available results of previous frames.
+func (p *Profiler) BeginFrame() {
+
+	if !p.enabled {
+		return
+	}
+	p.frame++
+	p.current = (p.current + 1) % profilerFrames
+	slot := &p.slots[p.current]
+	p.collect(slot)
+	slot.frame = p.frame
+	slot.next = 0
+	slot.marks = slot.marks[0:0]
+	p.Begin(PassFrame)
+}
+
+// EndFrame must be called at the end of each frame after all passes
+func (p *Profiler) EndFrame() {
+
+	if !p.enabled || p.current < 0 {
+		return
+	}
+	p.End(PassFrame)
+	p.slots[p.current].pending = true
+}
+
+// Begin starts the measurement of the pass with the specified name
+func (p *Profiler) Begin(name string) {
+
+	if !p.enabled || p.current < 0 {
+		return
+	}
+	slot := &p.slots[p.current]
+	var mark profilerMark
+	mark.name = name
+	mark.begin = p.query(slot)
+	mark.end = p.query(slot)
+	mark.open = true
+	p.dev.QueryCounter(mark.begin, gls.TIMESTAMP)
+	mark.cpuStart = time.Now()
+	slot.marks = append(slot.marks, mark)
+}
+
+// End ends the measurement of the last begun pass with the specified name
+func (p *Profiler) End(name string) {
+
+	if !p.enabled || p.current < 0 {
+		return
+	}
+	slot := &p.slots[p.current]
+	for i := len(slot.marks) - 1; i >= 0; i-- {
+		mark := &slot.marks[i]
+		if mark.open && mark.name == name {
+			p.dev.QueryCounter(mark.end, gls.TIMESTAMP)
+			mark.cpu = time.Since(mark.cpuStart)
+			mark.open = false
+			return
+		}
+	}
+	log.Warn("Profiler: pass:%s not begun", name)
+}
+
+// Result returns the last results of the pass with the specified name
+// and false if there are no results for this pass.
+func (p *Profiler) Result(name string) (PassTime, bool) {
+
+	res, ok := p.results[name]
+	if !ok {
+		return PassTime{}, false
+	}
+	return *res, true
+}
+
+// Results returns the last results of all passes in order of first use
+func (p *Profiler) Results() []PassTime {
+
+	results := make([]PassTime, 0, len(p.names))
+	for _, name := range p.names {
+		results = append(results, *p.results[name])
+	}
+	return results
+}
+
+// Report returns a text table with the last results of all passes
+func (p *Profiler) Report() string {
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%-12s %10s %10s\n", "pass", "gpu(ms)", "cpu(ms)")
+	for _, res := range p.Results() {
+		fmt.Fprintf(&buf, "%-12s %10.3f %10.3f\n", res.Name, durationMs(res.GPU), durationMs(res.CPU))
+	}
+	return buf.String()
+}
+
+// Dispose releases the query objects of this profiler
+func (p *Profiler) Dispose() {
+
+	for i := range p.slots {
+		slot := &p.slots[i]
+		for _, q := range slot.queries {
+			p.dev.DestroyQuery(q)
+		}
+		slot.queries = nil
+		slot.marks = nil
+		slot.pending = false
+	}
+}
+
+// query returns the next free query object of the specified frame slot
+func (p *Profiler) query(slot *profilerFrame) uint32 {
+
+	if slot.next >= len(slot.queries) {
+		slot.queries = append(slot.queries, p.dev.CreateQuery())
+	}
+	q := slot.queries[slot.next]
+	slot.next++
+	return q
+}
+
+// collect reads the results of the specified frame slot if they are available.
+// If the GPU is more than profilerFrames behind, the results are discarded.
+func (p *Profiler) collect(slot *profilerFrame) {
+
+	if !slot.pending {
+		return
+	}
+	slot.pending = false
+	if len(slot.marks) == 0 {
+		return
+	}
+	last := slot.marks[0].end
+	if !p.dev.QueryAvailable(last) {
+		return
+	}
+	for i := range slot.marks {
+		mark := &slot.marks[i]
+		if mark.open {
+			continue
+		}
+		begin := p.dev.QueryResult(mark.begin)
+		end := p.dev.QueryResult(mark.end)
+		res, ok := p.results[mark.name]
+		if !ok {
+			res = &PassTime{Name: mark.name}
+			p.results[mark.name] = res
+			p.names = append(p.names, mark.name)
+		}
+		// Accumulates passes with the same name in the same frame
+		if res.Frame != slot.frame {
+			res.GPU = 0
+			res.CPU = 0
+			res.Frame = slot.frame
+		}
+		res.GPU += time.Duration(end - begin)
+		res.CPU += mark.cpu
+	}
+}
+
+// durationMs returns the specified duration in milliseconds
+func durationMs(d time.Duration) float64 {
+
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,178 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"sort"
+)
+
+// Sort modes of the opaque render queue
+const (
+	SortNone        = 0 // Opaque graphic materials are rendered in scene order
+	SortState       = 1 // Sorted by program, material, texture and then front to back
+	SortFrontToBack = 2 // Sorted front to back and then by program, material and texture
+)
+
+// renderQueue contains the state used to sort the opaque render queue.
+// Each graphic material has a 64 bit sort key built from 16 bit identifiers
+// of its shader program, material and first texture and its quantized depth.
+// Transparent graphic materials are always sorted back to front.
+type renderQueue struct {
+	mode    int                           // sort mode
+	progIDs map[queueProgram]uint64       // identifiers of the programs
+	matIDs  map[*material.Material]uint64 // identifiers of the materials
+	texIDs  map[*texture.Texture2D]uint64 // identifiers of the textures
+	items   []queueItem                   // preallocated items to sort
+	depths  []float32                     // preallocated depths of the items
+}
+
+// queueProgram identifies the shader program used by a material
+type queueProgram struct {
+	name     string
+	lights   material.UseLights
+	textures int
+}
+
+// queueItem is an item of the render queue with its sort key
+type queueItem struct {
+	key   uint64
+	grmat *graphic.GraphicMaterial
+}
+
+// queueItems implements sort.Interface for render queue items
+type queueItems []queueItem
+
+func (qi queueItems) Len() int {
+
+	return len(qi)
+}
+
+func (qi queueItems) Less(i, j int) bool {
+
+	return qi[i].key < qi[j].key
+}
+
+func (qi queueItems) Swap(i, j int) {
+
+	qi[i], qi[j] = qi[j], qi[i]
+}
+
+// queueMaxID is the maximum identifier which fits in a sort key field
+const queueMaxID = 0xFFFF
+
+// SetSortMode sets the sort mode of the opaque render queue: SortNone, SortState
+// or SortFrontToBack. Sorting by state minimizes the changes of programs, materials
+// and textures, while sorting front to back minimizes overdraw with early depth tests.
+// A function set with SetSort() has precedence over the sort mode.
+// The default mode is SortNone, as some scenes such as GUIs depend on the scene order.
+func (r *Renderer) SetSortMode(mode int) {
+
+	r.queue.mode = mode
+}
+
+// SortMode returns the current sort mode of the opaque render queue
+func (r *Renderer) SortMode() int {
+
+	return r.queue.mode
+}
+
+// sortOpaque sorts the opaque graphic materials using the sort function
+// if set, or else by their sort keys.
+func (r *Renderer) sortOpaque() {
+
+	if r.less != nil {
+		sort.Stable(&grmatSorter{r.grmats, r.less})
+		return
+	}
+	q := &r.queue
+	if q.mode == SortNone || len(r.grmats) < 2 {
+		return
+	}
+	if q.progIDs == nil || len(q.progIDs) > queueMaxID || len(q.matIDs) > queueMaxID || len(q.texIDs) > queueMaxID {
+		q.progIDs = make(map[queueProgram]uint64)
+		q.matIDs = make(map[*material.Material]uint64)
+		q.texIDs = make(map[*texture.Texture2D]uint64)
+	}
+
+	// Calculates the view depths and their range
+	q.depths = q.depths[0:0]
+	minDepth := math32.Inf(1)
+	maxDepth := math32.Inf(-1)
+	for _, grmat := range r.grmats {
+		d := r.viewDepth(grmat)
+		q.depths = append(q.depths, d)
+		minDepth = math32.Min(minDepth, d)
+		maxDepth = math32.Max(maxDepth, d)
+	}
+	scale := float32(0)
+	if maxDepth > minDepth {
+		scale = queueMaxID / (maxDepth - minDepth)
+	}
+
+	// Builds the sort keys
+	q.items = q.items[0:0]
+	for i, grmat := range r.grmats {
+		mat := grmat.GetMaterial().GetMaterial()
+		prog := q.id(q.progIDs, queueProgram{mat.Shader(), mat.UseLights(), mat.TextureCount()})
+		mid, ok := q.matIDs[mat]
+		if !ok {
+			mid = uint64(len(q.matIDs) + 1)
+			q.matIDs[mat] = mid
+		}
+		var tid uint64
+		if mat.TextureCount() > 0 {
+			tex := mat.TextureAt(0)
+			tid, ok = q.texIDs[tex]
+			if !ok {
+				tid = uint64(len(q.texIDs) + 1)
+				q.texIDs[tex] = tid
+			}
+		}
+		depth := uint64((q.depths[i] - minDepth) * scale)
+		var key uint64
+		if q.mode == SortFrontToBack {
+			key = depth<<48 | prog<<32 | mid<<16 | tid
+		} else {
+			key = prog<<48 | mid<<32 | tid<<16 | depth
+		}
+		q.items = append(q.items, queueItem{key, grmat})
+	}
+	sort.Stable(queueItems(q.items))
+	for i := range q.items {
+		r.grmats[i] = q.items[i].grmat
+		q.items[i].grmat = nil
+	}
+}
+
+// id returns the identifier of the specified program, assigning a new one if necessary
+func (q *renderQueue) id(ids map[queueProgram]uint64, prog queueProgram) uint64 {
+
+	id, ok := ids[prog]
+	if !ok {
+		id = uint64(len(ids) + 1)
+		ids[prog] = id
+	}
+	return id
+}
+
+// viewDepth returns the distance along the camera view direction to the
+// center of the bounds of the graphic of the specified graphic material
+func (r *Renderer) viewDepth(grmat *graphic.GraphicMaterial) float32 {
+
+	node := grmat.GetGraphic().GetNode()
+	var pos math32.Vector3
+	sphere, ok := node.WorldBoundingSphere()
+	if ok {
+		pos = sphere.Center
+	} else {
+		node.WorldPosition(&pos)
+	}
+	pos.ApplyMatrix4(&r.rinfo.ViewMatrix)
+	return -pos.Z
+}
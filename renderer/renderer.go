@@ -10,10 +10,19 @@ import (
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/graphic"
 	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
 )
 
 type Renderer struct {
-	gs          *gls.GLS
+	// Statistics of the last rendered scene
+	Stats struct {
+		Graphics int // Number of visible graphics
+		Culled   int // Number of graphics culled by the camera frustum
+		Occluded int // Number of graphics culled by occlusion queries
+		Drawn    int // Number of graphic materials drawn
+		Batched  int // Number of graphic materials merged into batches
+	}
+	dev         gls.IDevice                // Graphics device used to draw the scenes
 	shaman      Shaman                     // Internal shader manager
 	ambLights   []*light.Ambient           // Array of ambient lights for last scene
 	dirLights   []*light.Directional       // Array of directional lights for last scene
@@ -21,15 +30,74 @@ type Renderer struct {
 	spotLights  []*light.Spot              // Array of spot lights for the scene
 	others      []core.INode               // Other nodes (audio, players, etc)
 	grmats      []*graphic.GraphicMaterial // Array of all graphic materials for scene
+	transp      []*graphic.GraphicMaterial // Array of transparent graphic materials for scene
 	rinfo       core.RenderInfo            // Preallocated Render info
 	specs       ShaderSpecs                // Preallocated Shader specs
+	oitScenes   map[*core.Node]bool        // Scenes with order independent transparency enabled
+	oit         *oitPass                   // Order independent transparency pass (created on demand)
+	culling     bool                       // Frustum culling enabled flag
+	frustum     *math32.Frustum            // Preallocated camera frustum
+	projView    math32.Matrix4             // Preallocated projection * view matrix
+	camPos      math32.Vector3             // Camera world position for the current scene
+	occl        occlusionCuller            // Occlusion query culling state
+	prof        *Profiler                  // Optional render passes profiler
+	filter      GraphicMaterialFilter      // Optional filter of graphic materials to render
+	less        GraphicMaterialLess        // Optional sort order of opaque graphic materials
+	stereo      *stereoPass                // Virtual reality views framebuffers (created on demand)
+	queue       renderQueue                // Opaque render queue sort state
+	batch       batcher                    // Dynamic batching state
+	prepass     depthPrepass               // Depth pre-pass state
+	camBlock    cameraBlock                // Camera uniform block data
+	camUBO      gls.UBO                    // Camera uniform buffer shared by all programs
+	lights      light.Block                // Lights uniform block data
+	lightsUBO   gls.UBO                    // Lights uniform buffer shared by all programs
+}
+
+// cameraBlock contains the camera parameters declared in the same
+// order as the members of the "Camera" uniform block of the shaders.
+type cameraBlock struct {
+	viewMatrix math32.Matrix4
+	projMatrix math32.Matrix4
+	position   math32.Vector3
 }
 
-func NewRenderer(gs *gls.GLS) *Renderer {
+// GraphicMaterialFilter is the type of functions which select
+// the graphic materials of a scene to be rendered
+type GraphicMaterialFilter func(grmat *graphic.GraphicMaterial) bool
+
+// GraphicMaterialLess is the type of functions which specify the
+// order of rendering of graphic materials
+type GraphicMaterialLess func(a, b *graphic.GraphicMaterial) bool
+
+// grmatSorter implements sort.Interface for graphic materials with a less function
+type grmatSorter struct {
+	grmats []*graphic.GraphicMaterial
+	less   GraphicMaterialLess
+}
+
+func (gs *grmatSorter) Len() int {
+
+	return len(gs.grmats)
+}
+
+func (gs *grmatSorter) Less(i, j int) bool {
+
+	return gs.less(gs.grmats[i], gs.grmats[j])
+}
+
+func (gs *grmatSorter) Swap(i, j int) {
+
+	gs.grmats[i], gs.grmats[j] = gs.grmats[j], gs.grmats[i]
+}
+
+// NewRenderer creates and returns a pointer to a new renderer which draws
+// the scenes, creates its shader programs and runs its optional passes with
+// the specified device.
+func NewRenderer(dev gls.IDevice) *Renderer {
 
 	r := new(Renderer)
-	r.gs = gs
-	r.shaman.Init(gs)
+	r.dev = dev
+	r.shaman.Init(dev)
 
 	r.ambLights = make([]*light.Ambient, 0)
 	r.dirLights = make([]*light.Directional, 0)
@@ -37,10 +105,58 @@ func NewRenderer(gs *gls.GLS) *Renderer {
 	r.spotLights = make([]*light.Spot, 0)
 	r.others = make([]core.INode, 0)
 	r.grmats = make([]*graphic.GraphicMaterial, 0)
+	r.transp = make([]*graphic.GraphicMaterial, 0)
+	r.oitScenes = make(map[*core.Node]bool)
+	r.culling = true
+	r.frustum = math32.NewFrustum(nil, nil, nil, nil, nil, nil)
+	r.occl.states = make(map[*core.Node]*occlusionState)
+	r.occl.frames = 3
+	r.camUBO.Init("Camera", gls.CameraBlockBinding)
+	r.lightsUBO.Init("Lights", gls.LightsBlockBinding)
 
 	return r
 }
 
+// SetFrustumCulling sets if graphics outside of the camera frustum
+// should be culled. It is enabled by default.
+func (r *Renderer) SetFrustumCulling(state bool) {
+
+	r.culling = state
+}
+
+// SetFilter sets an optional function which selects the graphic materials
+// of the scene to be rendered. Set to nil to render all graphic materials.
+func (r *Renderer) SetFilter(filter GraphicMaterialFilter) {
+
+	r.filter = filter
+}
+
+// SetSort sets an optional function which specifies the rendering order of
+// the opaque graphic materials. Set to nil to render in scene order.
+func (r *Renderer) SetSort(less GraphicMaterialLess) {
+
+	r.less = less
+}
+
+// SetProfiler sets the profiler used to measure the render passes
+// of this renderer or nil to disable profiling.
+func (r *Renderer) SetProfiler(p *Profiler) {
+
+	r.prof = p
+}
+
+// Profiler returns the current profiler of this renderer or nil
+func (r *Renderer) Profiler() *Profiler {
+
+	return r.prof
+}
+
+// FrustumCulling returns the state of the frustum culling flag
+func (r *Renderer) FrustumCulling() bool {
+
+	return r.culling
+}
+
 func (r *Renderer) AddDefaultShaders() error {
 
 	return r.shaman.AddDefaultShaders()
@@ -71,6 +187,21 @@ func (r *Renderer) Render(iscene core.INode, icam camera.ICamera) error {
 	icam.ViewMatrix(&r.rinfo.ViewMatrix)
 	icam.ProjMatrix(&r.rinfo.ProjMatrix)
 
+	// Updates the nodes world bounds and the camera frustum used for culling
+	if r.culling || r.occl.enabled || r.queue.mode != SortNone {
+		core.UpdateWorldBounds(iscene)
+		r.projView.MultiplyMatrices(&r.rinfo.ProjMatrix, &r.rinfo.ViewMatrix)
+		r.frustum.SetFromMatrix(&r.projView)
+	}
+	r.Stats.Graphics = 0
+	r.Stats.Culled = 0
+	r.Stats.Occluded = 0
+	r.Stats.Drawn = 0
+	r.Stats.Batched = 0
+	icam.GetCamera().WorldPosition(&r.camPos)
+	r.occl.frame++
+	r.occl.nodes = r.occl.nodes[0:0]
+
 	// Clear scene arrays
 	r.ambLights = r.ambLights[0:0]
 	r.dirLights = r.dirLights[0:0]
@@ -78,8 +209,11 @@ func (r *Renderer) Render(iscene core.INode, icam camera.ICamera) error {
 	r.spotLights = r.spotLights[0:0]
 	r.others = r.others[0:0]
 	r.grmats = r.grmats[0:0]
+	r.transp = r.transp[0:0]
 
-	// Internal function to classify a node and its children
+	// Internal function to classify a node and its children.
+	// Only the nodes in the layers of the camera cull mask are rendered.
+	mask := icam.GetCamera().CullMask()
 	var classifyNode func(inode core.INode)
 	classifyNode = func(inode core.INode) {
 
@@ -89,22 +223,46 @@ func (r *Renderer) Render(iscene core.INode, icam camera.ICamera) error {
 			return
 		}
 
+		// If the whole subtree is outside the camera frustum, ignore
+		if r.culling {
+			box, cullable := node.SubtreeBoundingBox()
+			if cullable && !box.Empty() && !r.frustum.IntersectsBox(&box) {
+				r.Stats.Culled += node.SubtreeBoundedCount()
+				return
+			}
+		}
+
 		// Checks if node is a Graphic
 		igr, ok := inode.(graphic.IGraphic)
 		if ok {
-			if igr.Renderable() {
+			rendered := igr.Renderable() && node.Layers().Has(mask)
+			if rendered && r.culled(node) {
+				r.Stats.Culled++
+			} else if rendered && r.occluded(node) {
+				r.Stats.Occluded++
+			} else if rendered {
+				r.Stats.Graphics++
 				// Appends to list each graphic material for this graphic
 				gr := igr.GetGraphic()
 				materials := gr.Materials()
 				for i := 0; i < len(materials); i++ {
-					r.grmats = append(r.grmats, &materials[i])
+					if r.filter != nil && !r.filter(&materials[i]) {
+						continue
+					}
+					if materials[i].GetMaterial().GetMaterial().Transparent() {
+						r.transp = append(r.transp, &materials[i])
+					} else {
+						r.grmats = append(r.grmats, &materials[i])
+					}
 				}
 			}
 			// Node is not a Graphic
 		} else {
 			// Checks if node is a Light
 			il, ok := inode.(light.ILight)
-			if ok {
+			if ok && !il.CullMask().Has(mask) {
+				// Light not used by this camera
+			} else if ok {
 				switch l := il.(type) {
 				case *light.Ambient:
 					r.ambLights = append(r.ambLights, l)
@@ -118,7 +276,7 @@ func (r *Renderer) Render(iscene core.INode, icam camera.ICamera) error {
 					panic("Invalid light type")
 				}
 				// Other nodes
-			} else {
+			} else if node.Layers().Has(mask) {
 				r.others = append(r.others, inode)
 			}
 		}
@@ -131,6 +289,8 @@ func (r *Renderer) Render(iscene core.INode, icam camera.ICamera) error {
 
 	// Classify all scene nodes
 	classifyNode(scene)
+	r.sortOpaque()
+	r.batchOpaque(scene)
 
 	// Sets lights count in shader specs
 	r.specs.AmbientLightsMax = len(r.ambLights)
@@ -138,45 +298,158 @@ func (r *Renderer) Render(iscene core.INode, icam camera.ICamera) error {
 	r.specs.PointLightsMax = len(r.pointLights)
 	r.specs.SpotLightsMax = len(r.spotLights)
 
+	// Transfers the camera and lights uniform blocks shared by all programs
+	r.camBlock.viewMatrix = r.rinfo.ViewMatrix
+	r.camBlock.projMatrix = r.rinfo.ProjMatrix
+	r.camBlock.position = r.camPos
+	r.camUBO.Set(&r.camBlock)
+	r.camUBO.Transfer(r.dev)
+	r.lights.Reset()
+	for _, l := range r.ambLights {
+		l.RenderSetup(&r.lights, &r.rinfo)
+	}
+	for _, l := range r.dirLights {
+		l.RenderSetup(&r.lights, &r.rinfo)
+	}
+	for _, l := range r.pointLights {
+		l.RenderSetup(&r.lights, &r.rinfo)
+	}
+	for _, l := range r.spotLights {
+		l.RenderSetup(&r.lights, &r.rinfo)
+	}
+	r.lightsUBO.Set(&r.lights)
+	r.lightsUBO.Transfer(r.dev)
+
 	// Render other nodes (audio players, etc)
 	for i := 0; i < len(r.others); i++ {
 		inode := r.others[i]
 		if !inode.GetNode().Visible() {
 			continue
 		}
-		r.others[i].Render(r.gs)
+		r.others[i].Render(r.dev)
 	}
 
-	// For each *GraphicMaterial
-	for _, grmat := range r.grmats {
-		//log.Debug("grmat:%v", grmat)
-		mat := grmat.GetMaterial().GetMaterial()
-
-		// Sets the shader specs for this material and sets shader program
-		r.specs.Name = mat.Shader()
-		r.specs.UseLights = mat.UseLights()
-		r.specs.MatTexturesMax = mat.TextureCount()
-		_, err := r.shaman.SetProgram(&r.specs)
+	// Render the depth of the opaque graphic materials if requested
+	prepass := r.useDepthPrepass(scene, icam)
+	if prepass {
+		r.profBegin(PassDepth)
+		err := r.renderDepthPrepass()
+		r.profEnd(PassDepth)
 		if err != nil {
 			return err
 		}
+	}
+
+	// Render opaque graphic materials
+	err := r.renderOpaque(prepass)
+	if err != nil {
+		return err
+	}
 
-		// Setup lights (transfer lights uniforms)
-		for idx, l := range r.ambLights {
-			l.RenderSetup(r.gs, &r.rinfo, idx)
+	// Tests occlusion of the bounding boxes against the opaque objects
+	if r.occl.enabled {
+		r.profBegin(PassOcclusion)
+		err = r.queryOcclusion()
+		r.profEnd(PassOcclusion)
+		if err != nil {
+			return err
 		}
-		for idx, l := range r.dirLights {
-			l.RenderSetup(r.gs, &r.rinfo, idx)
+	}
+
+	// Render transparent graphic materials
+	if len(r.transp) == 0 {
+		return nil
+	}
+	r.profBegin(PassTransparent)
+	defer r.profEnd(PassTransparent)
+	if r.oitScenes[scene] {
+		done, err := r.renderOIT()
+		if done || err != nil {
+			return err
 		}
-		for idx, l := range r.pointLights {
-			l.RenderSetup(r.gs, &r.rinfo, idx)
+	}
+	r.sortTransparent()
+	for _, grmat := range r.transp {
+		err := r.renderGraphicMaterial(grmat)
+		if err != nil {
+			return err
 		}
-		for idx, l := range r.spotLights {
-			l.RenderSetup(r.gs, &r.rinfo, idx)
+	}
+	return nil
+}
+
+// renderOpaque renders the opaque graphic materials, skipping the shading
+// of the fragments hidden by the depth pre-pass if it was rendered
+func (r *Renderer) renderOpaque(prepass bool) error {
+
+	r.profBegin(PassOpaque)
+	defer r.profEnd(PassOpaque)
+	for i, grmat := range r.grmats {
+		r.rinfo.DepthPrepass = prepass && r.prepass.done[i]
+		err := r.renderGraphicMaterial(grmat)
+		if err != nil {
+			r.rinfo.DepthPrepass = false
+			return err
 		}
+	}
+	r.rinfo.DepthPrepass = false
+	return nil
+}
+
+// profBegin begins the measurement of the specified pass if a profiler is set
+// and starts a debug group named by the pass for graphics debuggers
+func (r *Renderer) profBegin(pass string) {
+
+	r.dev.PushDebugGroup(pass)
+	if r.prof != nil {
+		r.prof.Begin(pass)
+	}
+}
+
+// profEnd ends the measurement of the specified pass if a profiler is set
+// and ends its debug group
+func (r *Renderer) profEnd(pass string) {
+
+	if r.prof != nil {
+		r.prof.End(pass)
+	}
+	r.dev.PopDebugGroup()
+}
+
+// renderGraphicMaterial sets the shader program for the
+// specified graphic material and renders it.
+func (r *Renderer) renderGraphicMaterial(grmat *graphic.GraphicMaterial) error {
 
-		// Render this graphic material
-		grmat.Render(r.gs, &r.rinfo)
+	//log.Debug("grmat:%v", grmat)
+	mat := grmat.GetMaterial().GetMaterial()
+
+	// Sets the shader specs for this material and sets shader program
+	r.specs.Name = mat.Shader()
+	r.specs.UseLights = mat.UseLights()
+	r.specs.MatTexturesMax = mat.TextureCount()
+	r.specs.MatTextureArraysMax = mat.TextureArrayCount()
+	r.specs.MatTextures3DMax = mat.Texture3DCount()
+	r.specs.OIT = r.rinfo.OIT
+	_, err := r.shaman.SetProgram(&r.specs)
+	if err != nil {
+		return err
 	}
+
+	// Render this graphic material
+	grmat.Render(r.dev, &r.rinfo)
+	r.Stats.Drawn++
 	return nil
 }
+
+// culled returns if the specified node is outside the camera frustum
+func (r *Renderer) culled(node *core.Node) bool {
+
+	if !r.culling || node.NeverCull() {
+		return false
+	}
+	sphere, ok := node.WorldBoundingSphere()
+	if !ok {
+		return false
+	}
+	return !r.frustum.IntersectsSphere(&sphere)
+}
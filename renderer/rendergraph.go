@@ -0,0 +1,413 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/texture"
+)
+
+// TargetDesc describes a transient render target of a render graph.
+// If Width or Height is zero, the size of the target is the size of the
+// current viewport multiplied by Scale (or 1 if Scale is zero).
+type TargetDesc struct {
+	Width       int         // Width in pixels (0 = relative to viewport)
+	Height      int         // Height in pixels (0 = relative to viewport)
+	Scale       float32     // Scale relative to the viewport size
+	Attachments []ColorDesc // Color attachments (default is one RGBA8 attachment)
+	NoDepth     bool        // Target without depth attachment
+	Samples     int         // Number of samples for multisampling
+}
+
+// ColorDesc describes the format of a color attachment of a transient render target
+type ColorDesc struct {
+	IFormat    int // Internal format, for example: gls.RGBA16F
+	Format     int // Data format, for example: gls.RGBA
+	FormatType int // Data type, for example: gls.FLOAT
+}
+
+// RenderPass is a pass of a render graph.
+// A pass reads the color textures of its input targets and renders into its
+// output target, or into the current framebuffer if it has no output.
+// If Execute is nil the pass renders its Scene with its Camera, selecting the
+// graphic materials with Filter and ordering the opaque ones with Sort.
+type RenderPass struct {
+	Name    string                       // Pass name
+	Inputs  []string                     // Names of the targets read by this pass
+	Output  string                       // Name of the target written by this pass ("" = current framebuffer)
+	Clear   bool                         // Clears the output target before executing the pass
+	Scene   core.INode                   // Scene rendered by the default execution
+	Camera  camera.ICamera               // Camera used by the default execution
+	Filter  GraphicMaterialFilter        // Optional graphic materials filter
+	Sort    GraphicMaterialLess          // Optional opaque graphic materials order
+	Execute func(ctx *PassContext) error // Optional execution callback
+}
+
+// PassContext is supplied to the execution callback of a render pass
+type PassContext struct {
+	Renderer *Renderer     // Renderer executing the graph
+	Graph    *RenderGraph  // Render graph being executed
+	Pass     *RenderPass   // Pass being executed
+	Target   *RenderTarget // Output target of the pass or nil
+}
+
+// Input returns the first color texture of the specified input target
+func (ctx *PassContext) Input(name string) *texture.Texture2D {
+
+	return ctx.InputAt(name, 0)
+}
+
+// InputAt returns the color texture at the specified attachment index of the specified input target
+func (ctx *PassContext) InputAt(name string, idx int) *texture.Texture2D {
+
+	rt := ctx.Graph.Target(name)
+	if rt == nil {
+		return nil
+	}
+	return rt.ColorTextureAt(idx)
+}
+
+// RenderScene renders the pass scene with the pass camera,
+// filter and sort into the pass output.
+func (ctx *PassContext) RenderScene() error {
+
+	if ctx.Pass.Scene == nil || ctx.Pass.Camera == nil {
+		return fmt.Errorf("RenderPass:%s has no scene or camera", ctx.Pass.Name)
+	}
+	r := ctx.Renderer
+	filter, less := r.filter, r.less
+	r.filter, r.less = ctx.Pass.Filter, ctx.Pass.Sort
+	err := r.Render(ctx.Pass.Scene, ctx.Pass.Camera)
+	r.filter, r.less = filter, less
+	return err
+}
+
+// RenderGraph schedules and executes a set of render passes.
+// Passes are executed in dependency order: a pass is executed after the
+// passes which write the targets it reads. Passes whose outputs are not
+// used by any pass which renders to the current framebuffer are skipped.
+// Transient targets are allocated on demand and reused between passes
+// when their contents are no longer needed.
+type RenderGraph struct {
+	r       *Renderer                // Renderer used to execute the graph
+	passes  []*RenderPass            // Passes in order of declaration
+	descs   map[string]*TargetDesc   // Transient targets descriptions
+	order   []*RenderPass            // Scheduled passes
+	targets map[string]*RenderTarget // Targets assigned to names in the last execution
+	pool    []*graphTarget           // Allocated targets
+	update  bool                     // Passes must be scheduled again
+}
+
+// graphTarget is an allocated render target of a render graph
+type graphTarget struct {
+	rt      *RenderTarget // allocated render target
+	desc    TargetDesc    // description used to allocate the target
+	lastUse int           // index of the last scheduled pass which uses the target
+}
+
+// NewRenderGraph creates and returns a pointer to a new empty render graph
+// executed by the specified renderer.
+func NewRenderGraph(r *Renderer) *RenderGraph {
+
+	g := new(RenderGraph)
+	g.r = r
+	g.passes = make([]*RenderPass, 0)
+	g.descs = make(map[string]*TargetDesc)
+	g.targets = make(map[string]*RenderTarget)
+	g.pool = make([]*graphTarget, 0)
+	return g
+}
+
+// AddTarget declares a transient target with the specified name and description
+func (g *RenderGraph) AddTarget(name string, desc TargetDesc) {
+
+	g.descs[name] = &desc
+	g.update = true
+}
+
+// AddPass adds the specified pass to this graph
+func (g *RenderGraph) AddPass(pass *RenderPass) {
+
+	g.passes = append(g.passes, pass)
+	g.update = true
+}
+
+// RemovePass removes the pass with the specified name from this graph.
+// Returns false if the pass was not found.
+func (g *RenderGraph) RemovePass(name string) bool {
+
+	for i, pass := range g.passes {
+		if pass.Name == name {
+			copy(g.passes[i:], g.passes[i+1:])
+			g.passes[len(g.passes)-1] = nil
+			g.passes = g.passes[:len(g.passes)-1]
+			g.update = true
+			return true
+		}
+	}
+	return false
+}
+
+// Target returns the render target currently assigned to the specified name
+// or nil if not assigned. Targets are assigned during Execute().
+func (g *RenderGraph) Target(name string) *RenderTarget {
+
+	return g.targets[name]
+}
+
+// Passes returns the list of passes scheduled for execution
+func (g *RenderGraph) Passes() ([]*RenderPass, error) {
+
+	err := g.schedule()
+	if err != nil {
+		return nil, err
+	}
+	return g.order, nil
+}
+
+// Execute schedules the passes if necessary, allocates the transient
+// targets and executes the passes in order.
+func (g *RenderGraph) Execute() error {
+
+	err := g.schedule()
+	if err != nil {
+		return err
+	}
+	dev := g.r.dev
+	vx, vy, vw, vh := dev.GetViewport()
+
+	// Releases the assignments of the previous execution
+	for name := range g.targets {
+		delete(g.targets, name)
+	}
+	for _, gt := range g.pool {
+		gt.lastUse = -1
+	}
+
+	for idx, pass := range g.order {
+		ctx := PassContext{Renderer: g.r, Graph: g, Pass: pass}
+		if pass.Output != "" {
+			ctx.Target = g.allocate(pass.Output, idx, int(vw), int(vh))
+			err = ctx.Target.Bind(dev)
+			if err != nil {
+				return fmt.Errorf("RenderPass:%s: %v", pass.Name, err)
+			}
+			if pass.Clear {
+				ctx.Target.Clear(dev)
+			}
+		}
+		if pass.Execute != nil {
+			err = pass.Execute(&ctx)
+		} else {
+			err = ctx.RenderScene()
+		}
+		if ctx.Target != nil {
+			ctx.Target.Resolve(dev)
+			dev.BindFramebuffer(gls.FRAMEBUFFER, 0)
+			dev.Viewport(vx, vy, vw, vh)
+		}
+		if err != nil {
+			return fmt.Errorf("RenderPass:%s: %v", pass.Name, err)
+		}
+	}
+	return nil
+}
+
+// Dispose releases all the targets allocated by this graph
+func (g *RenderGraph) Dispose() {
+
+	for _, gt := range g.pool {
+		gt.rt.Dispose()
+	}
+	g.pool = g.pool[0:0]
+	for name := range g.targets {
+		delete(g.targets, name)
+	}
+}
+
+// schedule orders the passes by their dependencies
+func (g *RenderGraph) schedule() error {
+
+	if !g.update {
+		return nil
+	}
+
+	// Maps each target to the passes which write it
+	writers := make(map[string][]int)
+	for i, pass := range g.passes {
+		if pass.Output == "" {
+			continue
+		}
+		if _, ok := g.descs[pass.Output]; !ok {
+			return fmt.Errorf("RenderPass:%s output target:%s not declared", pass.Name, pass.Output)
+		}
+		writers[pass.Output] = append(writers[pass.Output], i)
+	}
+
+	// Depth first visit from the passes which render to the framebuffer.
+	// A pass depends on the writers of its inputs declared before it,
+	// or on all writers if none is declared before it.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.passes))
+	order := make([]*RenderPass, 0, len(g.passes))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("RenderGraph: dependency cycle at pass:%s", g.passes[i].Name)
+		}
+		state[i] = visiting
+		pass := g.passes[i]
+		for _, input := range pass.Inputs {
+			ws, ok := writers[input]
+			if !ok {
+				return fmt.Errorf("RenderPass:%s input target:%s is not written by any pass", pass.Name, input)
+			}
+			deps := make([]int, 0, len(ws))
+			for _, w := range ws {
+				if w < i {
+					deps = append(deps, w)
+				}
+			}
+			if len(deps) == 0 {
+				deps = ws
+			}
+			for _, w := range deps {
+				err := visit(w)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// Passes which write the same target are executed in declaration order
+		if pass.Output != "" {
+			for _, w := range writers[pass.Output] {
+				if w >= i {
+					break
+				}
+				err := visit(w)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = visited
+		order = append(order, pass)
+		return nil
+	}
+	for i, pass := range g.passes {
+		if pass.Output == "" {
+			err := visit(i)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	g.order = order
+	g.update = false
+	return nil
+}
+
+// allocate assigns a render target to the specified name for the pass at the
+// specified index, reusing a pooled target whose contents are no longer needed.
+func (g *RenderGraph) allocate(name string, idx int, vw, vh int) *RenderTarget {
+
+	// Calculates the last scheduled pass which uses this target
+	last := idx
+	for i := idx; i < len(g.order); i++ {
+		pass := g.order[i]
+		if pass.Output == name {
+			last = i
+		}
+		for _, input := range pass.Inputs {
+			if input == name {
+				last = i
+			}
+		}
+	}
+
+	// If already assigned, updates its lifetime
+	if rt, ok := g.targets[name]; ok {
+		for _, gt := range g.pool {
+			if gt.rt == rt && gt.lastUse < last {
+				gt.lastUse = last
+			}
+		}
+		return rt
+	}
+
+	// Calculates target size
+	desc := *g.descs[name]
+	if desc.Width == 0 || desc.Height == 0 {
+		scale := desc.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		desc.Width = int(float32(vw) * scale)
+		desc.Height = int(float32(vh) * scale)
+	}
+
+	// Tries to reuse a free pooled target with the same format
+	var found *graphTarget
+	for _, gt := range g.pool {
+		if gt.lastUse < idx && gt.desc.compatible(&desc) {
+			found = gt
+			break
+		}
+	}
+	if found == nil {
+		found = &graphTarget{rt: newGraphTarget(&desc), desc: desc}
+		g.pool = append(g.pool, found)
+	}
+	found.rt.SetSize(desc.Width, desc.Height)
+	found.desc.Width = desc.Width
+	found.desc.Height = desc.Height
+	found.lastUse = last
+	g.targets[name] = found.rt
+	return found.rt
+}
+
+// compatible returns if a target allocated with this description can be
+// used for the other description (possibly after being resized)
+func (td *TargetDesc) compatible(other *TargetDesc) bool {
+
+	if td.NoDepth != other.NoDepth || td.Samples != other.Samples {
+		return false
+	}
+	if len(td.Attachments) != len(other.Attachments) {
+		return false
+	}
+	for i := range td.Attachments {
+		if td.Attachments[i] != other.Attachments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newGraphTarget creates a new render target from the specified description
+func newGraphTarget(desc *TargetDesc) *RenderTarget {
+
+	rt := NewRenderTarget(desc.Width, desc.Height)
+	for i, cd := range desc.Attachments {
+		if i == 0 {
+			rt.SetFormat(cd.IFormat, cd.Format, cd.FormatType)
+		} else {
+			rt.AddColorAttachment(cd.IFormat, cd.Format, cd.FormatType)
+		}
+	}
+	rt.SetDepth(!desc.NoDepth)
+	rt.SetSamples(desc.Samples)
+	return rt
+}
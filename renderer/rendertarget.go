@@ -0,0 +1,450 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// RenderTarget is an offscreen framebuffer which can be used as the
+// destination of a scene rendering instead of the window framebuffer.
+// Its color attachments are Texture2D objects which can be used by materials,
+// allowing the implementation of mirrors, portals, minimaps and post processing.
+// Additional color attachments can be added for multiple render targets (MRT)
+// as used by deferred shading. Multisampled render targets are antialiased
+// and are resolved into the color textures after rendering.
+type RenderTarget struct {
+	dev        gls.IDevice       // device of the buffers. Valid after first use
+	width      int               // width in pixels
+	height     int               // height in pixels
+	colors     []colorAttachment // color attachments
+	depth      bool              // depth attachment flag
+	samples    int               // number of samples for multisampling (0 = no MSAA)
+	msTextures bool              // multisample color attachments are textures instead of renderbuffers
+	clearColor math32.Color4     // color used to clear the target
+	fbo        uint32            // handle of framebuffer with the color textures
+	depthRbo   uint32            // handle of depth renderbuffer of fbo
+	msFbo      uint32            // handle of multisample framebuffer
+	msDepthRbo uint32            // handle of multisample depth renderbuffer
+	update     bool              // device buffers must be (re)created
+}
+
+// colorAttachment describes one color attachment of a render target
+type colorAttachment struct {
+	tex        *texture.Texture2D // color texture
+	iformat    int                // internal format of the texture
+	format     int                // format of the texture data
+	formatType int                // type of the texture data
+	msRbo      uint32             // handle of multisample color renderbuffer
+	msTex      uint32             // handle of multisample color texture
+}
+
+// NewRenderTarget creates and returns a pointer to a new render target
+// with the specified size in pixels, an RGBA8 color texture and a depth attachment.
+func NewRenderTarget(width, height int) *RenderTarget {
+
+	rt := new(RenderTarget)
+	rt.width = width
+	rt.height = height
+	rt.depth = true
+	rt.samples = 0
+	rt.clearColor = math32.Color4{R: 0, G: 0, B: 0, A: 1}
+	rt.AddColorAttachment(gls.RGBA8, gls.RGBA, gls.UNSIGNED_BYTE)
+	return rt
+}
+
+// SetSize sets the size in pixels of this render target.
+// The color texture and other attachments are reallocated.
+func (rt *RenderTarget) SetSize(width, height int) {
+
+	if rt.width == width && rt.height == height {
+		return
+	}
+	rt.width = width
+	rt.height = height
+	for i := range rt.colors {
+		ca := &rt.colors[i]
+		ca.tex.SetData(width, height, ca.format, ca.formatType, ca.iformat, nil)
+	}
+	rt.update = true
+}
+
+// Size returns the current size in pixels of this render target
+func (rt *RenderTarget) Size() (width, height int) {
+
+	return rt.width, rt.height
+}
+
+// SetFormat sets the internal format, the data format and the data type
+// of the first color texture, for example: gls.RGBA16F, gls.RGBA, gls.FLOAT.
+// The default is gls.RGBA8, gls.RGBA, gls.UNSIGNED_BYTE.
+func (rt *RenderTarget) SetFormat(iformat, format, formatType int) {
+
+	rt.SetAttachmentFormat(0, iformat, format, formatType)
+}
+
+// SetAttachmentFormat sets the internal format, the data format and the data type
+// of the color texture at the specified attachment index.
+func (rt *RenderTarget) SetAttachmentFormat(idx int, iformat, format, formatType int) {
+
+	ca := &rt.colors[idx]
+	ca.iformat = iformat
+	ca.format = format
+	ca.formatType = formatType
+	ca.tex.SetData(rt.width, rt.height, format, formatType, iformat, nil)
+	rt.update = true
+}
+
+// AddColorAttachment adds a new color texture with the specified internal format,
+// data format and data type to this render target and returns its attachment index.
+// Fragment shader outputs are written to the attachments in index order.
+func (rt *RenderTarget) AddColorAttachment(iformat, format, formatType int) int {
+
+	tex := texture.NewTexture2DFromData(rt.width, rt.height, format, formatType, iformat, nil)
+	tex.SetFlipY(false)
+	tex.SetGenMipmap(false)
+	rt.colors = append(rt.colors, colorAttachment{tex: tex, iformat: iformat, format: format, formatType: formatType})
+	rt.update = true
+	return len(rt.colors) - 1
+}
+
+// ColorAttachmentCount returns the number of color attachments of this render target
+func (rt *RenderTarget) ColorAttachmentCount() int {
+
+	return len(rt.colors)
+}
+
+// SetDepth sets if this render target has a depth attachment.
+// The default is true.
+func (rt *RenderTarget) SetDepth(state bool) {
+
+	rt.depth = state
+	rt.update = true
+}
+
+// SetSamples sets the number of samples used for multisample antialiasing.
+// If greater than zero, the scene is rendered into multisampled buffers which
+// are resolved into the color texture after rendering. The default is 0.
+func (rt *RenderTarget) SetSamples(samples int) {
+
+	rt.samples = samples
+	rt.update = true
+}
+
+// Samples returns the current number of samples used for antialiasing
+func (rt *RenderTarget) Samples() int {
+
+	return rt.samples
+}
+
+// SetMultisampleTextures sets if the multisampled color attachments are
+// multisample textures instead of renderbuffers, so they can be read by the
+// shaders as sampler2DMS with custom resolves, as done by deferred shading.
+// Renderbuffers are used if multisample textures are not supported.
+// The default is false.
+func (rt *RenderTarget) SetMultisampleTextures(state bool) {
+
+	rt.msTextures = state
+	rt.update = true
+}
+
+// MultisampleTextures returns if the multisampled color attachments are textures
+func (rt *RenderTarget) MultisampleTextures() bool {
+
+	return rt.msTextures
+}
+
+// MultisampleTextureAt returns the handle of the multisample texture of the
+// color attachment at the specified index, which is bound to
+// TEXTURE_2D_MULTISAMPLE to be read, or 0 if there is no multisample texture.
+func (rt *RenderTarget) MultisampleTextureAt(idx int) uint32 {
+
+	return rt.colors[idx].msTex
+}
+
+// SetClearColor sets the color used to clear this render target before rendering
+func (rt *RenderTarget) SetClearColor(color *math32.Color4) {
+
+	rt.clearColor = *color
+}
+
+// ColorTexture returns the texture of the first color attachment
+func (rt *RenderTarget) ColorTexture() *texture.Texture2D {
+
+	return rt.colors[0].tex
+}
+
+// ColorTextureAt returns the texture of the color attachment at the specified index
+func (rt *RenderTarget) ColorTextureAt(idx int) *texture.Texture2D {
+
+	return rt.colors[idx].tex
+}
+
+// Dispose releases the device resources associated with this render target
+// including its color textures.
+func (rt *RenderTarget) Dispose() {
+
+	rt.deleteBuffers()
+	for i := range rt.colors {
+		rt.colors[i].tex.Dispose()
+	}
+	rt.dev = nil
+}
+
+// Bind binds this render target framebuffer as the current
+// draw framebuffer and sets the viewport to its size.
+// The device objects are created or updated if necessary.
+func (rt *RenderTarget) Bind(dev gls.IDevice) error {
+
+	if rt.update || rt.dev == nil {
+		err := rt.setup(dev)
+		if err != nil {
+			return err
+		}
+	}
+	if rt.samples > 0 {
+		dev.BindFramebuffer(gls.FRAMEBUFFER, rt.msFbo)
+	} else {
+		dev.BindFramebuffer(gls.FRAMEBUFFER, rt.fbo)
+	}
+	dev.Viewport(0, 0, int32(rt.width), int32(rt.height))
+	return nil
+}
+
+// Clear clears the color, depth and stencil buffers of this render target
+// which must be currently bound. The previous clear color is preserved.
+func (rt *RenderTarget) Clear(dev gls.IDevice) {
+
+	cr, cg, cb, ca := dev.GetClearColor()
+	cc := rt.clearColor
+	dev.ClearColor(cc.R, cc.G, cc.B, cc.A)
+	dev.Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
+	dev.ClearColor(cr, cg, cb, ca)
+}
+
+// Resolve copies the multisampled buffers to the color textures.
+// It is called automatically by Renderer.RenderToTarget() and
+// does nothing if this target is not multisampled.
+func (rt *RenderTarget) Resolve(dev gls.IDevice) {
+
+	if rt.samples == 0 || rt.dev == nil {
+		return
+	}
+	dev.BindFramebuffer(gls.READ_FRAMEBUFFER, rt.msFbo)
+	dev.BindFramebuffer(gls.DRAW_FRAMEBUFFER, rt.fbo)
+	w := int32(rt.width)
+	h := int32(rt.height)
+	for i := range rt.colors {
+		att := uint32(gls.COLOR_ATTACHMENT0 + i)
+		dev.ReadBuffer(att)
+		dev.DrawBuffers(att)
+		dev.BlitFramebuffer(0, 0, w, h, 0, 0, w, h, gls.COLOR_BUFFER_BIT, gls.NEAREST)
+	}
+	// Restores all draw buffers of the target framebuffer
+	dev.ReadBuffer(gls.COLOR_ATTACHMENT0)
+	dev.DrawBuffers(rt.drawBuffers()...)
+}
+
+// ResolveTo copies the color textures of this render target into the color
+// textures with the same attachment indices of the specified target, or its
+// first color texture into the current viewport of the default framebuffer
+// if the target is nil. Multisampled buffers are resolved before the copy and
+// the images are scaled with linear filtering if the sizes are different.
+// It leaves the framebuffers of the copy bound.
+func (rt *RenderTarget) ResolveTo(dev gls.IDevice, dst *RenderTarget) error {
+
+	if rt.dev == nil {
+		return nil
+	}
+	rt.Resolve(dev)
+
+	// Sets the destination framebuffer and rectangle
+	var dx, dy, dw, dh int32
+	var dfbo uint32
+	count := 1
+	if dst != nil {
+		if dst.update || dst.dev == nil {
+			err := dst.setup(dev)
+			if err != nil {
+				return err
+			}
+		}
+		dfbo = dst.fbo
+		dw = int32(dst.width)
+		dh = int32(dst.height)
+		if len(dst.colors) < len(rt.colors) {
+			count = len(dst.colors)
+		} else {
+			count = len(rt.colors)
+		}
+	} else {
+		dx, dy, dw, dh = dev.GetViewport()
+	}
+	w := int32(rt.width)
+	h := int32(rt.height)
+	filter := uint32(gls.NEAREST)
+	if w != dw || h != dh {
+		filter = gls.LINEAR
+	}
+
+	dev.BindFramebuffer(gls.READ_FRAMEBUFFER, rt.fbo)
+	dev.BindFramebuffer(gls.DRAW_FRAMEBUFFER, dfbo)
+	for i := 0; i < count; i++ {
+		dev.ReadBuffer(uint32(gls.COLOR_ATTACHMENT0 + i))
+		if dst != nil {
+			dev.DrawBuffers(uint32(gls.COLOR_ATTACHMENT0 + i))
+		} else {
+			dev.DrawBuffers(gls.BACK)
+		}
+		dev.BlitFramebuffer(0, 0, w, h, dx, dy, dx+dw, dy+dh, gls.COLOR_BUFFER_BIT, filter)
+	}
+	// Restores the read and draw buffers of the framebuffers
+	dev.ReadBuffer(gls.COLOR_ATTACHMENT0)
+	if dst != nil {
+		dev.DrawBuffers(dst.drawBuffers()...)
+	}
+	return nil
+}
+
+// drawBuffers returns the list of color attachment points of this target
+func (rt *RenderTarget) drawBuffers() []uint32 {
+
+	bufs := make([]uint32, len(rt.colors))
+	for i := range rt.colors {
+		bufs[i] = uint32(gls.COLOR_ATTACHMENT0 + i)
+	}
+	return bufs
+}
+
+// setup creates the framebuffers and attachments of this render target
+func (rt *RenderTarget) setup(dev gls.IDevice) error {
+
+	rt.deleteBuffers()
+	rt.dev = dev
+	w := int32(rt.width)
+	h := int32(rt.height)
+	bufs := rt.drawBuffers()
+
+	// Creates framebuffer with the color textures attached
+	rt.fbo = dev.CreateFramebuffer()
+	dev.BindFramebuffer(gls.FRAMEBUFFER, rt.fbo)
+	for i := range rt.colors {
+		texname := rt.colors[i].tex.TexName(dev)
+		dev.FramebufferTexture2D(gls.FRAMEBUFFER, bufs[i], gls.TEXTURE_2D, texname, 0)
+	}
+	dev.DrawBuffers(bufs...)
+	if rt.depth && rt.samples == 0 {
+		rt.depthRbo = dev.CreateRenderbuffer(gls.DEPTH24_STENCIL8, w, h, 0)
+		dev.FramebufferRenderbuffer(gls.FRAMEBUFFER, gls.DEPTH_STENCIL_ATTACHMENT, rt.depthRbo)
+	}
+	err := rt.checkStatus(dev)
+	if err != nil {
+		return err
+	}
+
+	// Creates multisample framebuffer with color textures or renderbuffers and depth renderbuffer
+	if rt.samples > 0 {
+		rt.msFbo = dev.CreateFramebuffer()
+		dev.BindFramebuffer(gls.FRAMEBUFFER, rt.msFbo)
+		samples := int32(rt.samples)
+		for i := range rt.colors {
+			ca := &rt.colors[i]
+			// Uses a renderbuffer if multisample textures are not supported
+			if rt.msTextures {
+				ca.msTex = dev.CreateMultisampleTexture(uint32(ca.iformat), w, h, samples)
+				if ca.msTex != 0 {
+					dev.FramebufferTexture2D(gls.FRAMEBUFFER, bufs[i], gls.TEXTURE_2D_MULTISAMPLE, ca.msTex, 0)
+					continue
+				}
+			}
+			ca.msRbo = dev.CreateRenderbuffer(uint32(ca.iformat), w, h, samples)
+			dev.FramebufferRenderbuffer(gls.FRAMEBUFFER, bufs[i], ca.msRbo)
+		}
+		dev.DrawBuffers(bufs...)
+		if rt.depth {
+			rt.msDepthRbo = dev.CreateRenderbuffer(gls.DEPTH24_STENCIL8, w, h, samples)
+			dev.FramebufferRenderbuffer(gls.FRAMEBUFFER, gls.DEPTH_STENCIL_ATTACHMENT, rt.msDepthRbo)
+		}
+		err = rt.checkStatus(dev)
+		if err != nil {
+			return err
+		}
+	}
+	rt.update = false
+	return nil
+}
+
+// checkStatus checks the completeness of the currently bound framebuffer
+func (rt *RenderTarget) checkStatus(dev gls.IDevice) error {
+
+	status := dev.CheckFramebufferStatus(gls.FRAMEBUFFER)
+	if status != gls.FRAMEBUFFER_COMPLETE {
+		dev.BindFramebuffer(gls.FRAMEBUFFER, 0)
+		return fmt.Errorf("RenderTarget: framebuffer incomplete: 0x%X", status)
+	}
+	return nil
+}
+
+// deleteBuffers releases the framebuffers and renderbuffers of this target
+func (rt *RenderTarget) deleteBuffers() {
+
+	if rt.dev == nil {
+		return
+	}
+	rbos := []*uint32{&rt.depthRbo, &rt.msDepthRbo}
+	for i := range rt.colors {
+		rbos = append(rbos, &rt.colors[i].msRbo)
+	}
+	for _, rbo := range rbos {
+		if *rbo != 0 {
+			rt.dev.DestroyRenderbuffer(*rbo)
+			*rbo = 0
+		}
+	}
+	for i := range rt.colors {
+		ca := &rt.colors[i]
+		if ca.msTex != 0 {
+			rt.dev.DestroyTexture(ca.msTex)
+			ca.msTex = 0
+		}
+	}
+	for _, fbo := range []*uint32{&rt.fbo, &rt.msFbo} {
+		if *fbo != 0 {
+			rt.dev.DestroyFramebuffer(*fbo)
+			*fbo = 0
+		}
+	}
+}
+
+// RenderToTarget renders the specified scene using the specified camera
+// into the specified render target instead of the window framebuffer.
+// The target is cleared before rendering and the previous viewport and
+// the default framebuffer are restored afterwards.
+func (r *Renderer) RenderToTarget(iscene core.INode, icam camera.ICamera, target *RenderTarget) error {
+
+	// Saves current viewport
+	vx, vy, vw, vh := r.dev.GetViewport()
+
+	// Binds target and clears it
+	err := target.Bind(r.dev)
+	if err != nil {
+		return err
+	}
+	target.Clear(r.dev)
+
+	// Renders the scene and resolves multisampling if necessary
+	err = r.Render(iscene, icam)
+	target.Resolve(r.dev)
+
+	// Restores default framebuffer and previous viewport
+	r.dev.BindFramebuffer(gls.FRAMEBUFFER, 0)
+	r.dev.Viewport(vx, vy, vw, vh)
+	return err
+}
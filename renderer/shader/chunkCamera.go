@@ -0,0 +1,19 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddChunk("camera", chunkCamera)
+}
+
+// chunkCamera declares the Camera uniform block, which is shared by
+// all programs and transferred once per frame by the renderer.
+const chunkCamera = `
+layout(std140) uniform Camera {
+    mat4 ViewMatrix;       // Camera view matrix
+    mat4 ProjMatrix;       // Camera projection matrix
+    vec3 CameraPosition;   // Camera position in world coordinates
+};
+`
@@ -0,0 +1,36 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddChunk("frag_output", chunkFragOutput)
+}
+
+// chunkFragOutput declares the fragment shader outputs and the function
+// writeFragColor() used to write the final fragment color.
+// For the order independent transparency pass the color is written
+// as weighted blended accumulation and revealage values.
+const chunkFragOutput = `
+{{if .OIT}}
+// Weighted blended order independent transparency outputs
+layout(location = 0) out vec4 AccumColor;    // rgb: sum of weighted premultiplied colors, a: revealage
+layout(location = 1) out vec4 AccumWeight;   // r: sum of weighted alphas
+
+void writeFragColor(vec4 color) {
+
+    float a = color.a;
+    float w = clamp(pow(min(1.0, a * 10.0) + 0.01, 3.0) * 1e8 * pow(1.0 - gl_FragCoord.z * 0.9, 3.0), 1e-2, 3e3);
+    AccumColor = vec4(color.rgb * a * w, a);
+    AccumWeight = vec4(a * w);
+}
+{{else}}
+out vec4 FragColor;
+
+void writeFragColor(vec4 color) {
+
+    FragColor = color;
+}
+{{end}}
+`
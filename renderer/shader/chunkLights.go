@@ -8,34 +8,38 @@ func init() {
 	AddChunk("lights", chunkLights)
 }
 
+// chunkLights declares the Lights uniform block, which is shared by all
+// programs and transferred once per frame by the renderer.
+// The members are declared in the same order as the fields of light.Block.
 const chunkLights = `
+{{if or .AmbientLightsMax .DirLightsMax .PointLightsMax .SpotLightsMax}}
+layout(std140) uniform Lights {
 {{if .AmbientLightsMax}}
-// Ambient lights uniforms
-uniform vec3 AmbientLightColor[{{.AmbientLightsMax}}];
+    // Ambient lights
+    vec3  AmbientLightColor[{{.AmbientLightsMax}}];
 {{end}}
-
 {{if .DirLightsMax}}
-// Directional lights uniforms
-uniform vec3  DirLightColor[{{.DirLightsMax}}];
-uniform vec3  DirLightPosition[{{.DirLightsMax}}];
+    // Directional lights
+    vec3  DirLightColor[{{.DirLightsMax}}];
+    vec3  DirLightPosition[{{.DirLightsMax}}];
 {{end}}
-
 {{if .PointLightsMax}}
-// Point lights uniforms
-uniform vec3  PointLightColor[{{.PointLightsMax}}];
-uniform vec3  PointLightPosition[{{.PointLightsMax}}];
-uniform float PointLightLinearDecay[{{.PointLightsMax}}];
-uniform float PointLightQuadraticDecay[{{.PointLightsMax}}];
+    // Point lights
+    vec3  PointLightColor[{{.PointLightsMax}}];
+    vec3  PointLightPosition[{{.PointLightsMax}}];
+    float PointLightLinearDecay[{{.PointLightsMax}}];
+    float PointLightQuadraticDecay[{{.PointLightsMax}}];
 {{end}}
-
 {{if .SpotLightsMax}}
-// Spot lights uniforms
-uniform vec3  SpotLightColor[{{.SpotLightsMax}}];
-uniform vec3  SpotLightPosition[{{.SpotLightsMax}}];
-uniform vec3  SpotLightDirection[{{.SpotLightsMax}}];
-uniform float SpotLightAngularDecay[{{.SpotLightsMax}}];
-uniform float SpotLightCutoffAngle[{{.SpotLightsMax}}];
-uniform float SpotLightLinearDecay[{{.SpotLightsMax}}];
-uniform float SpotLightQuadraticDecay[{{.SpotLightsMax}}];
+    // Spot lights
+    vec3  SpotLightColor[{{.SpotLightsMax}}];
+    vec3  SpotLightPosition[{{.SpotLightsMax}}];
+    vec3  SpotLightDirection[{{.SpotLightsMax}}];
+    float SpotLightAngularDecay[{{.SpotLightsMax}}];
+    float SpotLightCutoffAngle[{{.SpotLightsMax}}];
+    float SpotLightLinearDecay[{{.SpotLightsMax}}];
+    float SpotLightQuadraticDecay[{{.SpotLightsMax}}];
+{{end}}
+};
 {{end}}
 `
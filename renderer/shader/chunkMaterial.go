@@ -8,15 +8,22 @@ func init() {
 	AddChunk("material", chunkMaterial)
 }
 
+// chunkMaterial declares the Material uniform block, which is transferred
+// by the material, and the uniforms of the material textures, texture arrays
+// and 3D textures. The layer of a texture array is the third coordinate of
+// its lookups, for example: texture(MatTexArray[0], vec3(FragTexcoord, layer)).
 const chunkMaterial = `
-// Material uniforms
-uniform vec3      MatAmbientColor;
-uniform vec3      MatDiffuseColor;
-uniform vec3      MatSpecularColor;
-uniform float     MatShininess;
-uniform vec3      MatEmissiveColor;
-uniform float     MatOpacity;
+// Material uniform block
+layout(std140) uniform Material {
+    vec3  MatAmbientColor;
+    vec3  MatDiffuseColor;
+    vec3  MatSpecularColor;
+    float MatShininess;
+    vec3  MatEmissiveColor;
+    float MatOpacity;
+};
 
+// Material textures uniforms
 {{if .MatTexturesMax}}
 uniform sampler2D MatTexture[{{.MatTexturesMax}}];
 uniform vec2      MatTexRepeat[{{.MatTexturesMax}}];
@@ -24,4 +31,10 @@ uniform vec2      MatTexOffset[{{.MatTexturesMax}}];
 uniform int       MatTexFlipY[{{.MatTexturesMax}}];
 uniform bool      MatTexVisible[{{.MatTexturesMax}}];
 {{ end }}
+{{if .MatTextureArraysMax}}
+uniform sampler2DArray MatTexArray[{{.MatTextureArraysMax}}];
+{{ end }}
+{{if .MatTextures3DMax}}
+uniform sampler3D MatTex3D[{{.MatTextures3DMax}}];
+{{ end }}
 `
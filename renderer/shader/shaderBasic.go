@@ -26,6 +26,9 @@ uniform mat4 MVP;
 // Final output color for fragment shader
 out vec3 Color;
 
+// Same depth as the depth pre-pass
+invariant gl_Position;
+
 void main() {
 
     Color = VertexColor;
@@ -0,0 +1,43 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddShader("shaderDepthVertex", shaderDepthVertex)
+	AddShader("shaderDepthFrag", shaderDepthFrag)
+	AddProgram("shaderDepth", "shaderDepthVertex", "shaderDepthFrag")
+}
+
+//
+// Vertex Shader template
+// Used by the depth pre-pass. The vertex position must be calculated
+// exactly as in the shaders of the materials rendered after the pre-pass.
+//
+const shaderDepthVertex = `
+#version {{.Version}}
+
+{{template "attributes" .}}
+
+// Model uniforms
+uniform mat4 MVP;
+
+invariant gl_Position;
+
+void main() {
+
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+//
+// Fragment Shader template
+// Only the depth buffer is written.
+//
+const shaderDepthFrag = `
+#version {{.Version}}
+
+void main() {
+}
+`
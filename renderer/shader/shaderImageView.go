@@ -0,0 +1,70 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddShader("shaderImageViewFrag", shaderImageViewFrag)
+	AddProgram("shaderImageView", "shaderPanelVertex", "shaderImageViewFrag")
+}
+
+//
+// Fragment Shader template of the gui image views, which show the
+// whole panel with its texture with optional color adjustments
+//
+const shaderImageViewFrag = `
+#version {{.Version}}
+
+{{template "material" .}}
+
+// Inputs from vertex shader
+in vec2 FragTexcoord;
+
+// Input uniforms
+uniform vec4 Bounds;
+uniform float Opacity;
+// ImageAdjust[0] - brightness added to the color components
+// ImageAdjust[1] - contrast factor around the middle gray
+// ImageAdjust[2] - saturation factor (0 = grayscale)
+// ImageAdjust[3] - flips the vertical texture coordinate again if not zero
+uniform vec4 ImageAdjust;
+uniform vec4 ImageTint;
+
+// Output
+out vec4 FragColor;
+
+
+void main() {
+
+    // Discard fragment outside of received bounds
+    if (FragTexcoord.x <= Bounds[0] || FragTexcoord.x >= Bounds[2]) {
+        discard;
+    }
+    if (FragTexcoord.y <= Bounds[1] || FragTexcoord.y >= Bounds[3]) {
+        discard;
+    }
+
+    vec4 color = vec4(0);
+    {{ if .MatTexturesMax }}
+        // Panel texture coordinates start at the top, so the textures
+        // which are not flipped, as the render targets, are flipped here.
+        vec2 texcoord = FragTexcoord;
+        if ((MatTexFlipY[0] == 0) != (ImageAdjust[3] != 0.0)) {
+            texcoord.y = 1.0 - texcoord.y;
+        }
+        color = texture(MatTexture[0], texcoord * MatTexRepeat[0] + MatTexOffset[0]);
+    {{ end }}
+
+    // Color adjustments
+    vec3 rgb = color.rgb + ImageAdjust[0];
+    rgb = (rgb - 0.5) * ImageAdjust[1] + 0.5;
+    float luma = dot(rgb, vec3(0.2126, 0.7152, 0.0722));
+    rgb = mix(vec3(luma), rgb, ImageAdjust[2]);
+    color = vec4(clamp(rgb, 0.0, 1.0), color.a) * ImageTint;
+    if (color.a == 0.0) {
+        discard;
+    }
+    FragColor = vec4(color.rgb, color.a * Opacity);
+}
+`
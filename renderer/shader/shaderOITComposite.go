@@ -0,0 +1,54 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddShader("shaderOITCompositeVertex", shaderOITCompositeVertex)
+	AddShader("shaderOITCompositeFrag", shaderOITCompositeFrag)
+	AddProgram("shaderOITComposite", "shaderOITCompositeVertex", "shaderOITCompositeFrag")
+}
+
+//
+// Vertex Shader template
+// Generates a full screen triangle from the vertex index without vertex buffers.
+//
+const shaderOITCompositeVertex = `
+#version {{.Version}}
+
+out vec2 FragTexcoord;
+
+void main() {
+
+    vec2 pos = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    FragTexcoord = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+//
+// Fragment Shader template
+// Composites the accumulated transparent colors over the opaque scene.
+//
+const shaderOITCompositeFrag = `
+#version {{.Version}}
+
+uniform sampler2D AccumColor;
+uniform sampler2D AccumWeight;
+
+in vec2 FragTexcoord;
+out vec4 FragColor;
+
+void main() {
+
+    vec4 accum = texture(AccumColor, FragTexcoord);
+    float revealage = accum.a;
+    if (revealage >= 1.0) {
+        discard;
+    }
+    float weight = texture(AccumWeight, FragTexcoord).r;
+    vec3 color = accum.rgb / max(weight, 1e-5);
+    FragColor = vec4(color, 1.0 - revealage);
+}
+`
@@ -0,0 +1,46 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddShader("shaderOcclusionBoxVertex", shaderOcclusionBoxVertex)
+	AddShader("shaderOcclusionBoxFrag", shaderOcclusionBoxFrag)
+	AddProgram("shaderOcclusionBox", "shaderOcclusionBoxVertex", "shaderOcclusionBoxFrag")
+}
+
+//
+// Vertex Shader template
+// Generates a box as a 14 vertices triangle strip from the vertex index
+// without vertex buffers. The box is specified in world coordinates.
+//
+const shaderOcclusionBoxVertex = `
+#version {{.Version}}
+
+uniform mat4 MVP;
+uniform vec3 BoxMin;
+uniform vec3 BoxSize;
+
+void main() {
+
+    int b = 1 << gl_VertexID;
+    vec3 pos = vec3((0x287a & b) != 0, (0x02af & b) != 0, (0x31e3 & b) != 0);
+    gl_Position = MVP * vec4(BoxMin + pos * BoxSize, 1.0);
+}
+`
+
+//
+// Fragment Shader template
+// Color writes are disabled while rendering the occlusion boxes.
+//
+const shaderOcclusionBoxFrag = `
+#version {{.Version}}
+
+out vec4 FragColor;
+
+void main() {
+
+    FragColor = vec4(1.0);
+}
+`
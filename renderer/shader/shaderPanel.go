@@ -27,7 +27,7 @@ void main() {
 
     // Always flip texture coordinates
     vec2 texcoord = VertexTexcoord;
-    texcoord.y = 1 - texcoord.y;
+    texcoord.y = 1.0 - texcoord.y;
     FragTexcoord = texcoord;
 
     // Set position
@@ -55,6 +55,7 @@ uniform vec4 Content;
 uniform vec4 BorderColor;
 uniform vec4 PaddingColor;
 uniform vec4 ContentColor;
+uniform float Opacity;
 
 // Output
 out vec4 FragColor;
@@ -107,26 +108,26 @@ void main() {
         {{ if .MatTexturesMax }}
             // Adjust texture coordinates to fit texture inside the content area
             vec2 offset = vec2(-Content[0], -Content[1]);
-            vec2 factor = vec2(1/Content[2], 1/Content[3]);
+            vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
             vec2 texcoord = (FragTexcoord + offset) * factor;
             color = texture(MatTexture[0], texcoord * MatTexRepeat[0] + MatTexOffset[0]);
         {{ end }}
-        if (color.a == 0) {
+        if (color.a == 0.0) {
             discard;
         }
-        FragColor = color;
+        FragColor = vec4(color.rgb, color.a * Opacity);
         return;
     }
 
     // Checks if fragment is inside paddings area
     if (checkRect(Padding)) {
-        FragColor = PaddingColor;
+        FragColor = vec4(PaddingColor.rgb, PaddingColor.a * Opacity);
         return;
     }
 
     // Checks if fragment is inside borders area
     if (checkRect(Border)) {
-        FragColor = BorderColor;
+        FragColor = vec4(BorderColor.rgb, BorderColor.a * Opacity);
         return;
     }
 
@@ -0,0 +1,87 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shader
+
+func init() {
+	AddShader("shaderPanelNineSliceFrag", shaderPanelNineSliceFrag)
+	AddProgram("shaderPanelNineSlice", "shaderPanelVertex", "shaderPanelNineSliceFrag")
+}
+
+//
+// Fragment Shader template of the panels whose background is a nine-slice
+// texture which fills the area inside the margins. The texture corners keep
+// their size, its edges are stretched along them and its center is stretched
+// to fill the remaining area.
+//
+const shaderPanelNineSliceFrag = `
+#version {{.Version}}
+
+{{template "material" .}}
+
+// Inputs from vertex shader
+in vec2 FragTexcoord;
+
+// Input uniforms
+uniform vec4 Bounds;
+uniform vec4 Border;
+uniform float Opacity;
+// Texture insets in texture coordinates (left, top, right, bottom)
+uniform vec4 NineSlice;
+// Texture insets relative to the area inside the margins (left, top, right, bottom)
+uniform vec4 NineSliceBox;
+// Color multiplied by the texture color
+uniform vec4 NineSliceColor;
+
+// Output
+out vec4 FragColor;
+
+
+/***
+* Maps a coordinate relative to the area inside the margins to the texture
+* coordinate of the slice which contains it.
+* bmin, bmax - insets relative to the area
+* tmin, tmax - insets in texture coordinates
+*/
+float sliceCoord(float u, float bmin, float bmax, float tmin, float tmax) {
+
+    if (u < bmin) {
+        return u / bmin * tmin;
+    }
+    if (u > 1.0 - bmax) {
+        return 1.0 - (1.0 - u) / bmax * tmax;
+    }
+    return tmin + (u - bmin) / max(1.0 - bmin - bmax, 0.000001) * (1.0 - tmin - tmax);
+}
+
+
+void main() {
+
+    // Discard fragment outside of received bounds
+    if (FragTexcoord.x <= Bounds[0] || FragTexcoord.x >= Bounds[2]) {
+        discard;
+    }
+    if (FragTexcoord.y <= Bounds[1] || FragTexcoord.y >= Bounds[3]) {
+        discard;
+    }
+
+    // Discard fragment in the margins area
+    vec2 pos = (FragTexcoord - vec2(Border[0], Border[1])) / vec2(Border[2], Border[3]);
+    if (pos.x < 0.0 || pos.x > 1.0 || pos.y < 0.0 || pos.y > 1.0) {
+        discard;
+    }
+
+    vec4 color = NineSliceColor;
+    {{ if .MatTexturesMax }}
+        vec2 texcoord;
+        texcoord.x = sliceCoord(pos.x, NineSliceBox[0], NineSliceBox[2], NineSlice[0], NineSlice[2]);
+        texcoord.y = sliceCoord(pos.y, NineSliceBox[1], NineSliceBox[3], NineSlice[1], NineSlice[3]);
+        color *= texture(MatTexture[0], texcoord);
+    {{ end }}
+    if (color.a == 0.0) {
+        discard;
+    }
+    FragColor = vec4(color.rgb, color.a * Opacity);
+}
+`
@@ -31,6 +31,9 @@ out vec3 Normal;
 out vec3 CamDir;
 out vec2 FragTexcoord;
 
+// Same depth as the depth pre-pass
+invariant gl_Position;
+
 void main() {
 
     // Transform this vertex position to camera coordinates.
@@ -47,7 +50,7 @@ void main() {
     vec2 texcoord = VertexTexcoord;
     {{ if .MatTexturesMax }}
     if (MatTexFlipY[0] > 0) {
-        texcoord.y = 1 - texcoord.y;
+        texcoord.y = 1.0 - texcoord.y;
     }
     {{ end }}
     FragTexcoord = texcoord;
@@ -73,7 +76,7 @@ in vec2 FragTexcoord;
 {{template "phong_model" .}}
 
 // Final fragment color
-out vec4 FragColor;
+{{template "frag_output" .}}
 
 void main() {
 
@@ -108,7 +111,7 @@ void main() {
     phongModel(Position, fragNormal, CamDir, vec3(matAmbient), vec3(matDiffuse), Ambdiff, Spec);
 
     // Final fragment color
-    FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+    writeFragColor(min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0)));
 }
 
 `
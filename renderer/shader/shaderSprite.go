@@ -30,7 +30,7 @@ void main() {
     vec2 texcoord = VertexTexcoord;
     {{if .MatTexturesMax}}
     if (MatTexFlipY[0] > 0) {
-        texcoord.y = 1 - texcoord.y;
+        texcoord.y = 1.0 - texcoord.y;
     }
     {{ end }}
     FragTexcoord = texcoord;
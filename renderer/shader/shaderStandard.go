@@ -35,6 +35,9 @@ out vec3 ColorBackAmbdiff;
 out vec3 ColorBackSpec;
 out vec2 FragTexcoord;
 
+// Same depth as the depth pre-pass
+invariant gl_Position;
+
 void main() {
 
     // Transform this vertex normal to camera coordinates.
@@ -56,7 +59,7 @@ void main() {
     {{if .MatTexturesMax }}
     // Flips texture coordinate Y if requested.
     if (MatTexFlipY[0] > 0) {
-        texcoord.y = 1 - texcoord.y;
+        texcoord.y = 1.0 - texcoord.y;
     }
     {{ end }}
     FragTexcoord = texcoord;
@@ -81,7 +84,7 @@ in vec3 ColorBackSpec;
 in vec2 FragTexcoord;
 
 // Output
-out vec4 FragColor;
+{{template "frag_output" .}}
 
 
 void main() {
@@ -111,7 +114,7 @@ void main() {
         colorAmbDiff = vec4(ColorBackAmbdiff, MatOpacity);
         colorSpec = vec4(ColorBackSpec, 0);
     }
-    FragColor = min(colorAmbDiff * texCombined + colorSpec, vec4(1));
+    writeFragColor(min(colorAmbDiff * texCombined + colorSpec, vec4(1)));
 }
 
 `
@@ -7,8 +7,9 @@ package shader
 import ()
 
 type ProgramInfo struct {
-	Vertex string // Vertex shader name
-	Frag   string // Fragment shader name
+	Vertex  string   // Vertex shader name
+	Frag    string   // Fragment shader name
+	Outputs []string // Fragment shader output names in draw buffer order (optional)
 }
 
 var chunks = map[string]string{}
@@ -42,5 +43,15 @@ func AddShader(name, source string) {
 
 func AddProgram(name, vertexName, fragName string) {
 
-	programs[name] = ProgramInfo{vertexName, fragName}
+	programs[name] = ProgramInfo{vertexName, fragName, nil}
+}
+
+// SetProgramOutputs sets the names of the fragment shader output variables
+// of the specified program, in the order of the draw buffers they should be
+// written to. Used by programs which render to multiple render targets.
+func SetProgramOutputs(name string, outputs ...string) {
+
+	pinfo := programs[name]
+	pinfo.Outputs = outputs
+	programs[name] = pinfo
 }
@@ -14,23 +14,26 @@ import (
 )
 
 type ShaderSpecs struct {
-	Name             string // Shader name
-	Version          string // GLSL version
-	UseLights        material.UseLights
-	AmbientLightsMax int // Current number of ambient lights
-	DirLightsMax     int // Current Number of directional lights
-	PointLightsMax   int // Current Number of point lights
-	SpotLightsMax    int // Current Number of spot lights
-	MatTexturesMax   int // Current Number of material textures
+	Name                string // Shader name
+	Version             string // GLSL version
+	UseLights           material.UseLights
+	AmbientLightsMax    int  // Current number of ambient lights
+	DirLightsMax        int  // Current Number of directional lights
+	PointLightsMax      int  // Current Number of point lights
+	SpotLightsMax       int  // Current Number of spot lights
+	MatTexturesMax      int  // Current Number of material textures
+	MatTextureArraysMax int  // Current Number of material texture arrays
+	MatTextures3DMax    int  // Current Number of material 3D textures
+	OIT                 bool // Order independent transparency outputs
 }
 
 type ProgSpecs struct {
-	program *gls.Program // program object
-	specs   ShaderSpecs  // associated specs
+	program uint32      // program handle
+	specs   ShaderSpecs // associated specs
 }
 
 type Shaman struct {
-	gs       *gls.GLS
+	dev      gls.IDevice
 	chunks   *template.Template            // template with all chunks
 	shaders  map[string]*template.Template // maps shader name to its template
 	proginfo map[string]shader.ProgramInfo // maps name of the program to ProgramInfo
@@ -39,16 +42,16 @@ type Shaman struct {
 }
 
 // NewShaman creates and returns a pointer to a new shader manager
-func NewShaman(gs *gls.GLS) *Shaman {
+func NewShaman(dev gls.IDevice) *Shaman {
 
 	sm := new(Shaman)
-	sm.Init(gs)
+	sm.Init(dev)
 	return sm
 }
 
-func (sm *Shaman) Init(gs *gls.GLS) {
+func (sm *Shaman) Init(dev gls.IDevice) {
 
-	sm.gs = gs
+	sm.dev = dev
 	sm.chunks = template.New("_chunks_")
 	sm.shaders = make(map[string]*template.Template)
 	sm.proginfo = make(map[string]shader.ProgramInfo)
@@ -105,7 +108,22 @@ func (sm *Shaman) AddShader(name, source string) error {
 
 func (sm *Shaman) AddProgram(name, vertexName, fragName string) error {
 
-	sm.proginfo[name] = shader.ProgramInfo{vertexName, fragName}
+	sm.proginfo[name] = shader.ProgramInfo{vertexName, fragName, nil}
+	return nil
+}
+
+// SetProgramOutputs sets the names of the fragment shader output variables
+// of the specified program in draw buffer order. The outputs are bound to
+// their color numbers when the program is generated, allowing the program
+// to render to multiple color attachments of a RenderTarget.
+func (sm *Shaman) SetProgramOutputs(name string, outputs ...string) error {
+
+	pinfo, ok := sm.proginfo[name]
+	if !ok {
+		return fmt.Errorf("Program:%s not found", name)
+	}
+	pinfo.Outputs = outputs
+	sm.proginfo[name] = pinfo
 	return nil
 }
 
@@ -121,7 +139,7 @@ func (sm *Shaman) SetProgram(specs *ShaderSpecs) (bool, error) {
 	// Search for compiled program with the specified specs
 	for _, pinfo := range sm.programs {
 		if pinfo.specs.Compare(specs) {
-			sm.gs.UseProgram(pinfo.program)
+			sm.dev.BindProgram(pinfo.program)
 			sm.specs = *specs
 			return true, nil
 		}
@@ -138,55 +156,63 @@ func (sm *Shaman) SetProgram(specs *ShaderSpecs) (bool, error) {
 	// and actives program
 	sm.specs = *specs
 	sm.programs = append(sm.programs, ProgSpecs{prog, *specs})
-	sm.gs.UseProgram(prog)
+	sm.dev.BindProgram(prog)
 	return true, nil
 }
 
 // Generates shader program from the specified specs
-func (sm *Shaman) GenProgram(specs *ShaderSpecs) (*gls.Program, error) {
+func (sm *Shaman) GenProgram(specs *ShaderSpecs) (uint32, error) {
 
 	// Get info for the specified shader program
 	progInfo, ok := sm.proginfo[specs.Name]
 	if !ok {
-		return nil, fmt.Errorf("Program:%s not found", specs.Name)
+		return 0, fmt.Errorf("Program:%s not found", specs.Name)
 	}
 
-	// Sets the GLSL version string
-	specs.Version = "330 core"
+	// Sets the GLSL version string of the current OpenGL backend
+	specs.Version = gls.GLSLVersion
 
 	// Get vertex shader compiled template
 	vtempl, ok := sm.shaders[progInfo.Vertex]
 	if !ok {
-		return nil, fmt.Errorf("Shader:%s template not found", progInfo.Vertex)
+		return 0, fmt.Errorf("Shader:%s template not found", progInfo.Vertex)
 	}
 	// Generates vertex shader source from template
 	var sourceVertex bytes.Buffer
 	err := vtempl.Execute(&sourceVertex, specs)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	// Get fragment shader compiled template
 	fragTempl, ok := sm.shaders[progInfo.Frag]
 	if !ok {
-		return nil, fmt.Errorf("Shader:%s template not found", progInfo.Frag)
+		return 0, fmt.Errorf("Shader:%s template not found", progInfo.Frag)
 	}
 	// Generates fragment shader source from template
 	var sourceFrag bytes.Buffer
 	err = fragTempl.Execute(&sourceFrag, specs)
 	if err != nil {
-		return nil, err
-	}
-
-	// Creates shader program
-	prog := sm.gs.NewProgram()
-	prog.AddShader(gls.VERTEX_SHADER, sourceVertex.String(), nil)
-	prog.AddShader(gls.FRAGMENT_SHADER, sourceFrag.String(), nil)
-	err = prog.Build()
-	if err != nil {
-		return nil, err
-	}
-	return prog, nil
+		return 0, err
+	}
+
+	// Creates shader program, labeled with its shader name for graphics debuggers,
+	// with the binding points of the uniform blocks shared by the programs
+	outputs := progInfo.Outputs
+	if specs.OIT {
+		outputs = []string{"AccumColor", "AccumWeight"}
+	}
+	return sm.dev.CreateProgram(&gls.ProgramDesc{
+		Name:     specs.Name,
+		Vertex:   sourceVertex.String(),
+		Fragment: sourceFrag.String(),
+		Outputs:  outputs,
+		UniformBlocks: map[string]uint32{
+			"Camera":   gls.CameraBlockBinding,
+			"Lights":   gls.LightsBlockBinding,
+			"Material": gls.MaterialBlockBinding,
+		},
+	})
 }
 
 func (ss *ShaderSpecs) Compare(other *ShaderSpecs) bool {
@@ -196,7 +222,10 @@ func (ss *ShaderSpecs) Compare(other *ShaderSpecs) bool {
 		ss.DirLightsMax == other.DirLightsMax &&
 		ss.PointLightsMax == other.PointLightsMax &&
 		ss.SpotLightsMax == other.SpotLightsMax &&
-		ss.MatTexturesMax == other.MatTexturesMax {
+		ss.MatTexturesMax == other.MatTexturesMax &&
+		ss.MatTextureArraysMax == other.MatTextureArraysMax &&
+		ss.MatTextures3DMax == other.MatTextures3DMax &&
+		ss.OIT == other.OIT {
 		return true
 	}
 	return false
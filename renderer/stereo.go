@@ -0,0 +1,153 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/xr"
+)
+
+// stereoPass contains the framebuffers used to render the views of a virtual reality session
+type stereoPass struct {
+	views []stereoView // framebuffer for each view
+	cam   eyeCamera    // camera with the matrices of the view being rendered
+}
+
+// stereoView contains the framebuffer of one view
+type stereoView struct {
+	fbo    uint32 // framebuffer object
+	depth  uint32 // depth and stencil renderbuffer
+	width  int    // renderbuffer width
+	height int    // renderbuffer height
+}
+
+// eyeCamera is a camera whose view and projection matrices are
+// supplied by a virtual reality session instead of being calculated.
+type eyeCamera struct {
+	camera.Camera                // Embedded camera
+	view          math32.Matrix4 // view matrix
+	proj          math32.Matrix4 // projection matrix
+}
+
+// ViewMatrix satisfies the ICamera interface
+func (ec *eyeCamera) ViewMatrix(m *math32.Matrix4) {
+
+	*m = ec.view
+}
+
+// ProjMatrix satisfies the ICamera interface
+func (ec *eyeCamera) ProjMatrix(m *math32.Matrix4) {
+
+	*m = ec.proj
+}
+
+// RenderStereo renders the specified scene for each view (eye) of the specified
+// virtual reality session into the session swapchain images, using the view and
+// projection matrices supplied by the session runtime for the current frame.
+// The views are rendered sequentially, as multiview rendering is not available
+// in the OpenGL 3.3 core profile. The pose events of the session are dispatched
+// before the views are rendered, so the scene may be updated from the tracked devices.
+// The current framebuffer and viewport are restored after rendering.
+func (r *Renderer) RenderStereo(iscene core.INode, sess xr.ISession) error {
+
+	render, err := sess.BeginFrame()
+	if err != nil {
+		return err
+	}
+	if !render {
+		return sess.EndFrame()
+	}
+	if r.stereo == nil {
+		r.stereo = new(stereoPass)
+		r.stereo.cam.Initialize()
+	}
+	sp := r.stereo
+	for len(sp.views) < sess.ViewCount() {
+		sp.views = append(sp.views, stereoView{})
+	}
+
+	dev := r.dev
+	vx, vy, vw, vh := dev.GetViewport()
+	for i := 0; i < sess.ViewCount(); i++ {
+		err = r.renderView(iscene, sess, i)
+		if err != nil {
+			break
+		}
+	}
+	dev.BindFramebuffer(gls.FRAMEBUFFER, 0)
+	dev.Viewport(vx, vy, vw, vh)
+	if err != nil {
+		sess.EndFrame()
+		return err
+	}
+	return sess.EndFrame()
+}
+
+// DisposeStereo releases the framebuffers used by RenderStereo()
+func (r *Renderer) DisposeStereo() {
+
+	if r.stereo == nil {
+		return
+	}
+	for i := range r.stereo.views {
+		r.stereo.views[i].delete(r.dev)
+	}
+	r.stereo = nil
+}
+
+// renderView renders the scene for the view with the specified index
+func (r *Renderer) renderView(iscene core.INode, sess xr.ISession, idx int) error {
+
+	dev := r.dev
+	texname, width, height := sess.ViewTarget(idx)
+	if texname == 0 {
+		return fmt.Errorf("Renderer: view:%d has no target", idx)
+	}
+
+	// Creates or resizes the view framebuffer depth buffer
+	sv := &r.stereo.views[idx]
+	if sv.fbo == 0 || sv.width != width || sv.height != height {
+		sv.delete(dev)
+		sv.fbo = dev.CreateFramebuffer()
+		sv.depth = dev.CreateRenderbuffer(gls.DEPTH24_STENCIL8, int32(width), int32(height), 0)
+		sv.width = width
+		sv.height = height
+	}
+
+	// Attaches the swapchain image, which may change every frame
+	dev.BindFramebuffer(gls.FRAMEBUFFER, sv.fbo)
+	dev.FramebufferTexture2D(gls.FRAMEBUFFER, gls.COLOR_ATTACHMENT0, gls.TEXTURE_2D, texname, 0)
+	dev.FramebufferRenderbuffer(gls.FRAMEBUFFER, gls.DEPTH_STENCIL_ATTACHMENT, sv.depth)
+	status := dev.CheckFramebufferStatus(gls.FRAMEBUFFER)
+	if status != gls.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("Renderer: view:%d framebuffer incomplete: 0x%X", idx, status)
+	}
+	dev.Viewport(0, 0, int32(width), int32(height))
+	dev.Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
+
+	// Sets the eye camera from the view
+	view := sess.View(idx)
+	cam := &r.stereo.cam
+	cam.view = view.ViewMatrix
+	cam.proj = view.ProjMatrix
+	cam.Node.SetPositionVec(&view.Pose.Position)
+	cam.Node.SetQuaternionQuat(&view.Pose.Orientation)
+	cam.UpdateMatrixWorld()
+	return r.Render(iscene, cam)
+}
+
+// delete releases the framebuffer and renderbuffer of this view
+func (sv *stereoView) delete(dev gls.IDevice) {
+
+	if sv.fbo != 0 {
+		dev.DestroyFramebuffer(sv.fbo)
+		dev.DestroyRenderbuffer(sv.depth)
+	}
+	*sv = stereoView{}
+}
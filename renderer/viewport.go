@@ -0,0 +1,153 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Viewport is a rectangle of the current framebuffer where a scene is
+// rendered with a camera. The rectangle is specified relative to the size
+// of the framebuffer viewport, from 0 to 1, with the origin at the top left corner.
+// Several viewports may be rendered in the same frame with RenderViewports(),
+// for example for split screen or for editor views.
+type Viewport struct {
+	X          float32               // Left position relative to the framebuffer width
+	Y          float32               // Top position relative to the framebuffer height
+	Width      float32               // Width relative to the framebuffer width
+	Height     float32               // Height relative to the framebuffer height
+	Scene      core.INode            // Scene to render
+	Camera     camera.ICamera        // Camera used to render the scene
+	Clear      bool                  // Clears the viewport rectangle before rendering
+	ClearColor math32.Color4         // Clear color
+	AutoAspect bool                  // Sets the camera aspect ratio from the viewport size
+	Visible    bool                  // Viewport is rendered
+	Filter     GraphicMaterialFilter // Optional graphic materials filter
+	Sort       GraphicMaterialLess   // Optional opaque graphic materials order
+}
+
+// NewViewport creates and returns a pointer to a new visible viewport with the
+// specified relative rectangle, scene and camera, which is cleared before rendering
+// and sets the aspect ratio of its camera.
+func NewViewport(x, y, width, height float32, scene core.INode, cam camera.ICamera) *Viewport {
+
+	vp := new(Viewport)
+	vp.SetRect(x, y, width, height)
+	vp.Scene = scene
+	vp.Camera = cam
+	vp.Clear = true
+	vp.AutoAspect = true
+	vp.Visible = true
+	return vp
+}
+
+// NewViewportGrid creates and returns the viewports of a grid with the specified
+// number of columns and rows, in row order starting at the top left corner.
+// All viewports render the specified scene and their cameras must be set.
+// A grid of 2x1 is a vertical split screen and 2x2 is the usual editor layout.
+func NewViewportGrid(cols, rows int, scene core.INode) []*Viewport {
+
+	vps := make([]*Viewport, 0, cols*rows)
+	w := 1 / float32(cols)
+	h := 1 / float32(rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			vps = append(vps, NewViewport(float32(col)*w, float32(row)*h, w, h, scene, nil))
+		}
+	}
+	return vps
+}
+
+// SetRect sets the relative rectangle of this viewport
+func (vp *Viewport) SetRect(x, y, width, height float32) {
+
+	vp.X = x
+	vp.Y = y
+	vp.Width = width
+	vp.Height = height
+}
+
+// PixelRect returns the rectangle of this viewport in pixels, in OpenGL coordinates
+// (origin at the bottom left), for a framebuffer viewport with the specified rectangle.
+func (vp *Viewport) PixelRect(fx, fy, fwidth, fheight int32) (x, y, width, height int32) {
+
+	x = fx + int32(vp.X*float32(fwidth))
+	width = fx + int32((vp.X+vp.Width)*float32(fwidth)) - x
+	top := int32(vp.Y * float32(fheight))
+	bottom := int32((vp.Y + vp.Height) * float32(fheight))
+	y = fy + fheight - bottom
+	height = bottom - top
+	return
+}
+
+// Contains returns if the specified window position, in pixels with the origin
+// at the top left, is inside this viewport for a window with the specified size.
+func (vp *Viewport) Contains(px, py float32, wwidth, wheight int) bool {
+
+	rx := px / float32(wwidth)
+	ry := py / float32(wheight)
+	return rx >= vp.X && rx < vp.X+vp.Width && ry >= vp.Y && ry < vp.Y+vp.Height
+}
+
+// NormalizedCoords converts the specified window position, in pixels with the origin
+// at the top left, to normalized device coordinates of this viewport (-1 to 1),
+// as used by the camera SetRaycaster() method.
+func (vp *Viewport) NormalizedCoords(px, py float32, wwidth, wheight int) (float32, float32) {
+
+	rx := (px/float32(wwidth) - vp.X) / vp.Width
+	ry := (py/float32(wheight) - vp.Y) / vp.Height
+	return 2*rx - 1, 1 - 2*ry
+}
+
+// RenderViewports renders the specified viewports in order into the current
+// framebuffer viewport. Each viewport is clipped to its rectangle with the
+// scissor test, so clearing a viewport does not affect the others.
+// The framebuffer viewport is restored after rendering.
+func (r *Renderer) RenderViewports(vps []*Viewport) error {
+
+	dev := r.dev
+	fx, fy, fw, fh := dev.GetViewport()
+	filter, less := r.filter, r.less
+	defer func() {
+		dev.SetScissor(false, 0, 0, 0, 0)
+		dev.Viewport(fx, fy, fw, fh)
+		r.filter, r.less = filter, less
+	}()
+
+	for _, vp := range vps {
+		if !vp.Visible || vp.Scene == nil || vp.Camera == nil {
+			continue
+		}
+		x, y, w, h := vp.PixelRect(fx, fy, fw, fh)
+		if w <= 0 || h <= 0 {
+			continue
+		}
+		dev.Viewport(x, y, w, h)
+		dev.SetScissor(true, x, y, w, h)
+		if vp.Clear {
+			cr, cg, cb, ca := dev.GetClearColor()
+			cc := vp.ClearColor
+			dev.ClearColor(cc.R, cc.G, cc.B, cc.A)
+			dev.Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
+			dev.ClearColor(cr, cg, cb, ca)
+		}
+		if vp.AutoAspect {
+			if ac, ok := vp.Camera.(interface {
+				SetAspect(float32)
+			}); ok {
+				ac.SetAspect(float32(w) / float32(h))
+			}
+		}
+		r.filter, r.less = vp.Filter, vp.Sort
+		err := r.Render(vp.Scene, vp.Camera)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
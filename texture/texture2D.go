@@ -16,8 +16,9 @@ import (
 )
 
 type Texture2D struct {
-	gs           *gls.GLS      // Pointer to OpenGL state
+	gs           gls.IDevice   // Graphics device
 	refcount     int           // Current number of references
+	name         string        // Optional name used to label the OpenGL texture
 	texname      uint32        // Texture handle
 	magFilter    uint32        // magnification filter
 	minFilter    uint32        // minification filter
@@ -80,6 +81,7 @@ func NewTexture2DFromImage(imgfile string) (*Texture2D, error) {
 
 	t := newTexture2D()
 	t.SetFromRGBA(rgba)
+	t.name = imgfile
 	return t, nil
 }
 
@@ -99,6 +101,20 @@ func NewTexture2DFromData(width, height int, format int, formatType, iformat int
 	return t
 }
 
+// SetName sets the name of this texture, which is used as the label of its
+// OpenGL texture shown by graphics debuggers. Textures created from image
+// files are named by their file path.
+func (t *Texture2D) SetName(name string) {
+
+	t.name = name
+}
+
+// Name returns the name of this texture
+func (t *Texture2D) Name() string {
+
+	return t.name
+}
+
 // Incref increments the reference count for this texture
 // and returns a pointer to the geometry.
 // It should be used when this texture is shared by another
@@ -119,7 +135,7 @@ func (t *Texture2D) Dispose() {
 		return
 	}
 	if t.gs != nil {
-		t.gs.DeleteTextures(t.texname)
+		t.gs.DestroyTexture(t.texname)
 		t.gs = nil
 	}
 }
@@ -213,6 +229,13 @@ func (t *Texture2D) SetWrapT(wrapT uint32) {
 	t.updateParams = true
 }
 
+// SetGenMipmap sets if mipmaps should be generated when the
+// texture data is transferred to OpenGL. The default value is true.
+func (t *Texture2D) SetGenMipmap(state bool) {
+
+	t.genMipmap = state
+}
+
 // SetRepeat set the repeat factor
 func (t *Texture2D) SetRepeat(x, y float32) {
 
@@ -285,52 +308,47 @@ func DecodeImage(imgfile string) (*image.RGBA, error) {
 	return rgba, nil
 }
 
-// Called by material render setup
-func (t *Texture2D) RenderSetup(gs *gls.GLS, idx int) {
+// TexName returns the OpenGL handle of this texture, creating the
+// texture object and transferring its data and parameters if necessary.
+// It is normally used by render targets which need the texture storage
+// allocated before attaching it to a framebuffer.
+func (t *Texture2D) TexName(gs gls.IDevice) uint32 {
 
-	// One time initialization
-	if t.gs == nil {
-		t.texname = gs.GenTexture()
+	first := t.gs == nil
+	if first {
+		t.texname = gs.CreateTexture()
 		t.gs = gs
 	}
+	if t.updateData || t.updateParams {
+		t.transfer(gs)
+	}
+	if first {
+		// Labels the texture for graphics debuggers once it is created by its first binding
+		gs.ObjectLabel(gls.TEXTURE, t.texname, t.name)
+	}
+	return t.texname
+}
 
-	// Transfer texture data to OpenGL if necessary
-	if t.updateData {
-		// Sets the texture unit for this texture
-		gs.ActiveTexture(uint32(gls.TEXTURE0 + idx))
-		gs.BindTexture(gls.TEXTURE_2D, t.texname)
-		gs.TexImage2D(
-			gls.TEXTURE_2D, // texture type
-			0,              // level of detail
-			t.iformat,      // internal format
-			t.width,        // width in texels
-			t.height,       // height in texels
-			0,              // border must be 0
-			t.format,       // format of supplied texture data
-			t.formatType,   // type of external format color component
-			t.data,         // image data
-		)
-		// Generates mipmaps if requested
-		if t.genMipmap {
-			gs.GenerateMipmap(gls.TEXTURE_2D)
-		}
-		// No data to send
-		t.updateData = false
+// Called by material render setup
+func (t *Texture2D) RenderSetup(gs gls.IDevice, idx int) {
+
+	// One time initialization
+	first := t.gs == nil
+	if first {
+		t.texname = gs.CreateTexture()
+		t.gs = gs
 	}
 
 	// Sets the texture unit for this texture
-	gs.ActiveTexture(uint32(gls.TEXTURE0 + idx))
-	gs.BindTexture(gls.TEXTURE_2D, t.texname)
-
-	// Sets texture parameters if needed
-	if t.updateParams {
-		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, int32(t.magFilter))
-		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, int32(t.minFilter))
-		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_S, int32(t.wrapS))
-		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, int32(t.wrapT))
-		t.updateParams = false
+	gs.BindTextureUnit(idx, t.texname)
+	if first {
+		// Labels the texture for graphics debuggers once it is created by its first binding
+		gs.ObjectLabel(gls.TEXTURE, t.texname, t.name)
 	}
 
+	// Transfer texture data and parameters to OpenGL if necessary
+	t.transfer(gs)
+
 	// Transfer uniforms
 	t.uTexture.Set(int32(idx))
 	t.uTexture.TransferIdx(gs, idx)
@@ -339,3 +357,36 @@ func (t *Texture2D) RenderSetup(gs *gls.GLS, idx int) {
 	t.uOffset.TransferIdx(gs, idx)
 	t.uRepeat.TransferIdx(gs, idx)
 }
+
+// transfer sends the texture data and parameters to the device if necessary.
+// The texture is bound to the current texture unit.
+func (t *Texture2D) transfer(gs gls.IDevice) {
+
+	if !t.updateData && !t.updateParams {
+		return
+	}
+	desc := gls.TextureDesc{
+		Width:          t.width,
+		Height:         t.height,
+		InternalFormat: t.iformat,
+		Format:         t.format,
+		Type:           t.formatType,
+		Mipmaps:        t.genMipmap,
+		MagFilter:      t.magFilter,
+		MinFilter:      t.minFilter,
+		WrapS:          t.wrapS,
+		WrapT:          t.wrapT,
+	}
+
+	// Transfer texture data if necessary
+	if t.updateData {
+		gs.UploadTexture(t.texname, &desc, t.data)
+		t.updateData = false
+	}
+
+	// Sets texture parameters if needed
+	if t.updateParams {
+		gs.SetTextureSampler(t.texname, &desc)
+		t.updateParams = false
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"fmt"
+	"github.com/g3n/engine/gls"
+	"image"
+)
+
+// Texture3D is a three-dimensional texture sampled in the shaders as a
+// sampler3D with three texture coordinates. It is used for volume rendering,
+// color grading lookup tables and noise volumes.
+type Texture3D struct {
+	gs           gls.IDevice         // Graphics device
+	refcount     int                 // Current number of references
+	name         string              // Optional name used to label the OpenGL texture
+	texname      uint32              // Texture handle
+	magFilter    uint32              // magnification filter
+	minFilter    uint32              // minification filter
+	wrapS        uint32              // wrap mode for s coordinate
+	wrapT        uint32              // wrap mode for t coordinate
+	wrapR        uint32              // wrap mode for r coordinate
+	iformat      int32               // internal format
+	width        int32               // texture width in pixels
+	height       int32               // texture height in pixels
+	depth        int32               // texture depth in pixels
+	format       uint32              // format of the pixel data
+	formatType   uint32              // type of the pixel data
+	updateData   bool                // texture data needs to be sent
+	updateParams bool                // texture parameters needs to be sent
+	genMipmap    bool                // generate mipmaps flag
+	data         interface{}         // array with the data of all the slices
+	sliceData    map[int]interface{} // data of the slices to update
+	uTexture     gls.Uniform1i       // Texture unit uniform
+}
+
+// NewTexture3D creates and returns a pointer to a new Texture3D with the
+// specified size and format and the specified data of all its slices,
+// ordered by slice, row and column. The data may be nil to only allocate the storage.
+func NewTexture3D(width, height, depth int, format int, formatType, iformat int, data interface{}) *Texture3D {
+
+	t := newTexture3D()
+	t.SetData(width, height, depth, format, formatType, iformat, data)
+	return t
+}
+
+// NewTexture3DFromLUTImage creates and returns a pointer to a new Texture3D
+// with a color lookup table for color grading, from an image file with the
+// N slices of size NxN of the table side by side, such as a 256x16 image
+// for a table of size 16. The red component of the table increases along
+// the columns of each slice, the green component along the rows and the
+// blue component along the slices.
+func NewTexture3DFromLUTImage(imgfile string) (*Texture3D, error) {
+
+	rgba, err := DecodeImage(imgfile)
+	if err != nil {
+		return nil, err
+	}
+	t, err := NewTexture3DFromLUT(rgba)
+	if err != nil {
+		return nil, err
+	}
+	t.name = imgfile
+	return t, nil
+}
+
+// NewTexture3DFromLUT creates and returns a pointer to a new Texture3D with a
+// color lookup table from an image with the N slices of size NxN of the table
+// side by side. See NewTexture3DFromLUTImage().
+func NewTexture3DFromLUT(rgba *image.RGBA) (*Texture3D, error) {
+
+	size := rgba.Rect.Size()
+	n := size.Y
+	if n == 0 || size.X != n*n {
+		return nil, fmt.Errorf("invalid color lookup table image size")
+	}
+	data := make([]byte, 0, 4*n*n*n)
+	for z := 0; z < n; z++ {
+		for y := 0; y < n; y++ {
+			start := y*rgba.Stride + z*n*4
+			data = append(data, rgba.Pix[start:start+n*4]...)
+		}
+	}
+	t := newTexture3D()
+	t.SetData(n, n, n, gls.RGBA, gls.UNSIGNED_BYTE, gls.RGBA8, data)
+	t.genMipmap = false
+	return t, nil
+}
+
+func newTexture3D() *Texture3D {
+
+	t := new(Texture3D)
+	t.gs = nil
+	t.refcount = 1
+	t.texname = 0
+	t.magFilter = gls.LINEAR
+	t.minFilter = gls.LINEAR
+	t.wrapS = gls.CLAMP_TO_EDGE
+	t.wrapT = gls.CLAMP_TO_EDGE
+	t.wrapR = gls.CLAMP_TO_EDGE
+	t.updateData = false
+	t.updateParams = true
+	t.genMipmap = true
+	t.sliceData = make(map[int]interface{})
+	t.uTexture.Init("MatTex3D")
+	return t
+}
+
+// SetName sets the name of this texture, which is used as the label of its
+// OpenGL texture shown by graphics debuggers.
+func (t *Texture3D) SetName(name string) {
+
+	t.name = name
+}
+
+// Name returns the name of this texture
+func (t *Texture3D) Name() string {
+
+	return t.name
+}
+
+// Incref increments the reference count for this texture
+// and returns a pointer to the texture.
+func (t *Texture3D) Incref() *Texture3D {
+
+	t.refcount++
+	return t
+}
+
+// Dispose decrements this texture reference count and
+// if necessary releases OpenGL resources associated with this texture.
+func (t *Texture3D) Dispose() {
+
+	if t.refcount > 1 {
+		t.refcount--
+		return
+	}
+	if t.gs != nil {
+		t.gs.DestroyTexture(t.texname)
+		t.gs = nil
+	}
+}
+
+// SetData sets the size and format of this texture and the data of all its
+// slices, which may be nil to only allocate the storage.
+func (t *Texture3D) SetData(width, height, depth int, format int, formatType, iformat int, data interface{}) {
+
+	t.width = int32(width)
+	t.height = int32(height)
+	t.depth = int32(depth)
+	t.format = uint32(format)
+	t.formatType = uint32(formatType)
+	t.iformat = int32(iformat)
+	t.data = data
+	t.sliceData = make(map[int]interface{})
+	t.updateData = true
+}
+
+// SetSliceData sets the data of the specified slice, which must have
+// the width, height and format of this texture.
+func (t *Texture3D) SetSliceData(slice int, data interface{}) {
+
+	if slice < 0 || slice >= int(t.depth) {
+		panic("Texture3D.SetSliceData() invalid slice")
+	}
+	t.sliceData[slice] = data
+}
+
+// SetMagFilter sets the filter to be applied when the texture element
+// covers more than on pixel. The default value is gls.Linear.
+func (t *Texture3D) SetMagFilter(magFilter uint32) {
+
+	t.magFilter = magFilter
+	t.updateParams = true
+}
+
+// SetMinFilter sets the filter to be applied when the texture element
+// covers less than on pixel. The default value is gls.Linear.
+func (t *Texture3D) SetMinFilter(minFilter uint32) {
+
+	t.minFilter = minFilter
+	t.updateParams = true
+}
+
+// SetWrapS set the wrapping mode for texture S coordinate
+// The default value is GL_CLAMP_TO_EDGE;
+func (t *Texture3D) SetWrapS(wrapS uint32) {
+
+	t.wrapS = wrapS
+	t.updateParams = true
+}
+
+// SetWrapT set the wrapping mode for texture T coordinate
+// The default value is GL_CLAMP_TO_EDGE;
+func (t *Texture3D) SetWrapT(wrapT uint32) {
+
+	t.wrapT = wrapT
+	t.updateParams = true
+}
+
+// SetWrapR set the wrapping mode for texture R coordinate
+// The default value is GL_CLAMP_TO_EDGE;
+func (t *Texture3D) SetWrapR(wrapR uint32) {
+
+	t.wrapR = wrapR
+	t.updateParams = true
+}
+
+// SetGenMipmap sets if mipmaps should be generated when the
+// texture data is transferred to OpenGL. The default value is true.
+func (t *Texture3D) SetGenMipmap(state bool) {
+
+	t.genMipmap = state
+}
+
+// Width returns the texture width in pixels
+func (t *Texture3D) Width() int {
+
+	return int(t.width)
+}
+
+// Height returns the texture height in pixels
+func (t *Texture3D) Height() int {
+
+	return int(t.height)
+}
+
+// Depth returns the texture depth in pixels
+func (t *Texture3D) Depth() int {
+
+	return int(t.depth)
+}
+
+// TexName returns the OpenGL handle of this texture, creating the
+// texture object and transferring its data and parameters if necessary.
+func (t *Texture3D) TexName(gs gls.IDevice) uint32 {
+
+	first := t.gs == nil
+	if first {
+		t.texname = gs.CreateTexture()
+		t.gs = gs
+	}
+	t.transfer(gs)
+	if first {
+		gs.ObjectLabel(gls.TEXTURE, t.texname, t.name)
+	}
+	return t.texname
+}
+
+// RenderSetup is called by the material render setup. It binds this texture
+// to the specified texture unit and sets the unit of the sampler of the
+// specified index of the MatTex3D uniform.
+func (t *Texture3D) RenderSetup(gs gls.IDevice, unit, idx int) {
+
+	// One time initialization
+	first := t.gs == nil
+	if first {
+		t.texname = gs.CreateTexture()
+		t.gs = gs
+	}
+
+	// Sets the texture unit for this texture
+	gs.BindTextureUnitTarget(unit, gls.TEXTURE_3D, t.texname)
+	if first {
+		gs.ObjectLabel(gls.TEXTURE, t.texname, t.name)
+	}
+
+	// Transfer texture data and parameters to OpenGL if necessary
+	t.transfer(gs)
+
+	// Transfer uniforms
+	t.uTexture.Set(int32(unit))
+	t.uTexture.TransferIdx(gs, idx)
+}
+
+// transfer sends the texture data, the changed slices and the parameters
+// to the device if necessary. The texture is bound to the current texture unit.
+func (t *Texture3D) transfer(gs gls.IDevice) {
+
+	if !t.updateData && !t.updateParams && len(t.sliceData) == 0 {
+		return
+	}
+	desc := gls.TextureDesc{
+		Target:         gls.TEXTURE_3D,
+		Width:          t.width,
+		Height:         t.height,
+		Depth:          t.depth,
+		InternalFormat: t.iformat,
+		Format:         t.format,
+		Type:           t.formatType,
+		Mipmaps:        t.genMipmap,
+		MagFilter:      t.magFilter,
+		MinFilter:      t.minFilter,
+		WrapS:          t.wrapS,
+		WrapT:          t.wrapT,
+		WrapR:          t.wrapR,
+	}
+
+	// Transfer texture data if necessary
+	if t.updateData {
+		gs.UploadTexture(t.texname, &desc, t.data)
+		t.updateData = false
+	}
+
+	// Transfer the changed slices, generating the mipmaps only once
+	if len(t.sliceData) > 0 {
+		desc.Mipmaps = false
+		for slice, data := range t.sliceData {
+			gs.UploadTextureLayer(t.texname, &desc, slice, data)
+		}
+		t.sliceData = make(map[int]interface{})
+		if t.genMipmap {
+			gs.GenerateMipmap(gls.TEXTURE_3D)
+		}
+	}
+
+	// Sets texture parameters if needed
+	if t.updateParams {
+		gs.SetTextureSampler(t.texname, &desc)
+		t.updateParams = false
+	}
+}
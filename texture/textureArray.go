@@ -0,0 +1,339 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"fmt"
+	"github.com/g3n/engine/gls"
+	"image"
+)
+
+// TextureArray is an array of 2D textures with the same size and format,
+// which is sampled in the shaders as a single sampler2DArray with the layer
+// as the third texture coordinate. It is used for terrain splat layers,
+// shadow map cascades and sprite atlases whose images must not bleed into
+// each other when filtered.
+type TextureArray struct {
+	gs           gls.IDevice         // Graphics device
+	refcount     int                 // Current number of references
+	name         string              // Optional name used to label the OpenGL texture
+	texname      uint32              // Texture handle
+	magFilter    uint32              // magnification filter
+	minFilter    uint32              // minification filter
+	wrapS        uint32              // wrap mode for s coordinate
+	wrapT        uint32              // wrap mode for t coordinate
+	iformat      int32               // internal format
+	width        int32               // texture width in pixels
+	height       int32               // texture height in pixels
+	layers       int32               // number of layers
+	format       uint32              // format of the pixel data
+	formatType   uint32              // type of the pixel data
+	updateData   bool                // texture data needs to be sent
+	updateParams bool                // texture parameters needs to be sent
+	genMipmap    bool                // generate mipmaps flag
+	data         interface{}         // array with the data of all the layers
+	layerData    map[int]interface{} // data of the layers to update
+	uTexture     gls.Uniform1i       // Texture unit uniform
+}
+
+// NewTextureArray creates and returns a pointer to a new TextureArray
+// with the specified size, number of layers and format without data.
+// The data of the layers may be set later by SetLayerData() or SetLayerRGBA().
+func NewTextureArray(width, height, layers int, format int, formatType, iformat int) *TextureArray {
+
+	t := newTextureArray()
+	t.SetData(width, height, layers, format, formatType, iformat, nil)
+	return t
+}
+
+// NewTextureArrayFromImages creates and returns a pointer to a new TextureArray
+// with one layer for each of the specified image files, which must have the same size.
+// Supported image formats are: PNG, JPEG and GIF.
+func NewTextureArrayFromImages(imgfiles ...string) (*TextureArray, error) {
+
+	images := make([]*image.RGBA, 0, len(imgfiles))
+	for _, imgfile := range imgfiles {
+		rgba, err := DecodeImage(imgfile)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, rgba)
+	}
+	t, err := NewTextureArrayFromRGBA(images...)
+	if err != nil {
+		return nil, err
+	}
+	if len(imgfiles) > 0 {
+		t.name = imgfiles[0]
+	}
+	return t, nil
+}
+
+// NewTextureArrayFromRGBA creates and returns a pointer to a new TextureArray
+// with one layer for each of the specified images, which must have the same size.
+func NewTextureArrayFromRGBA(images ...*image.RGBA) (*TextureArray, error) {
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("texture array without images")
+	}
+	size := images[0].Rect.Size()
+	data := make([]byte, 0, len(images)*len(images[0].Pix))
+	for _, rgba := range images {
+		if rgba.Rect.Size() != size {
+			return nil, fmt.Errorf("texture array images with different sizes")
+		}
+		data = append(data, rgba.Pix...)
+	}
+	t := newTextureArray()
+	t.SetData(size.X, size.Y, len(images), gls.RGBA, gls.UNSIGNED_BYTE, gls.RGBA8, data)
+	return t, nil
+}
+
+func newTextureArray() *TextureArray {
+
+	t := new(TextureArray)
+	t.gs = nil
+	t.refcount = 1
+	t.texname = 0
+	t.magFilter = gls.LINEAR
+	t.minFilter = gls.LINEAR
+	t.wrapS = gls.CLAMP_TO_EDGE
+	t.wrapT = gls.CLAMP_TO_EDGE
+	t.updateData = false
+	t.updateParams = true
+	t.genMipmap = true
+	t.layerData = make(map[int]interface{})
+	t.uTexture.Init("MatTexArray")
+	return t
+}
+
+// SetName sets the name of this texture, which is used as the label of its
+// OpenGL texture shown by graphics debuggers.
+func (t *TextureArray) SetName(name string) {
+
+	t.name = name
+}
+
+// Name returns the name of this texture
+func (t *TextureArray) Name() string {
+
+	return t.name
+}
+
+// Incref increments the reference count for this texture
+// and returns a pointer to the texture.
+func (t *TextureArray) Incref() *TextureArray {
+
+	t.refcount++
+	return t
+}
+
+// Dispose decrements this texture reference count and
+// if necessary releases OpenGL resources associated with this texture.
+func (t *TextureArray) Dispose() {
+
+	if t.refcount > 1 {
+		t.refcount--
+		return
+	}
+	if t.gs != nil {
+		t.gs.DestroyTexture(t.texname)
+		t.gs = nil
+	}
+}
+
+// SetData sets the size, number of layers and format of this texture and
+// the data of all its layers, which may be nil to only allocate the storage.
+func (t *TextureArray) SetData(width, height, layers int, format int, formatType, iformat int, data interface{}) {
+
+	t.width = int32(width)
+	t.height = int32(height)
+	t.layers = int32(layers)
+	t.format = uint32(format)
+	t.formatType = uint32(formatType)
+	t.iformat = int32(iformat)
+	t.data = data
+	t.layerData = make(map[int]interface{})
+	t.updateData = true
+}
+
+// SetLayerData sets the data of the specified layer, which must have
+// the size and format of this texture.
+func (t *TextureArray) SetLayerData(layer int, data interface{}) {
+
+	if layer < 0 || layer >= int(t.layers) {
+		panic("TextureArray.SetLayerData() invalid layer")
+	}
+	t.layerData[layer] = data
+}
+
+// SetLayerRGBA sets the data of the specified layer from the specified image,
+// which must have the size of this texture. The texture format must be RGBA.
+func (t *TextureArray) SetLayerRGBA(layer int, rgba *image.RGBA) error {
+
+	size := rgba.Rect.Size()
+	if size.X != int(t.width) || size.Y != int(t.height) {
+		return fmt.Errorf("image size different from texture array size")
+	}
+	t.SetLayerData(layer, rgba.Pix)
+	return nil
+}
+
+// SetLayerImage sets the data of the specified layer from the specified image
+// file, which must have the size of this texture. The texture format must be RGBA.
+func (t *TextureArray) SetLayerImage(layer int, imgfile string) error {
+
+	rgba, err := DecodeImage(imgfile)
+	if err != nil {
+		return err
+	}
+	return t.SetLayerRGBA(layer, rgba)
+}
+
+// SetMagFilter sets the filter to be applied when the texture element
+// covers more than on pixel. The default value is gls.Linear.
+func (t *TextureArray) SetMagFilter(magFilter uint32) {
+
+	t.magFilter = magFilter
+	t.updateParams = true
+}
+
+// SetMinFilter sets the filter to be applied when the texture element
+// covers less than on pixel. The default value is gls.Linear.
+func (t *TextureArray) SetMinFilter(minFilter uint32) {
+
+	t.minFilter = minFilter
+	t.updateParams = true
+}
+
+// SetWrapS set the wrapping mode for texture S coordinate
+// The default value is GL_CLAMP_TO_EDGE;
+func (t *TextureArray) SetWrapS(wrapS uint32) {
+
+	t.wrapS = wrapS
+	t.updateParams = true
+}
+
+// SetWrapT set the wrapping mode for texture T coordinate
+// The default value is GL_CLAMP_TO_EDGE;
+func (t *TextureArray) SetWrapT(wrapT uint32) {
+
+	t.wrapT = wrapT
+	t.updateParams = true
+}
+
+// SetGenMipmap sets if mipmaps should be generated when the
+// texture data is transferred to OpenGL. The default value is true.
+func (t *TextureArray) SetGenMipmap(state bool) {
+
+	t.genMipmap = state
+}
+
+// Width returns the texture width in pixels
+func (t *TextureArray) Width() int {
+
+	return int(t.width)
+}
+
+// Height returns the texture height in pixels
+func (t *TextureArray) Height() int {
+
+	return int(t.height)
+}
+
+// Layers returns the number of layers of this texture
+func (t *TextureArray) Layers() int {
+
+	return int(t.layers)
+}
+
+// TexName returns the OpenGL handle of this texture, creating the
+// texture object and transferring its data and parameters if necessary.
+func (t *TextureArray) TexName(gs gls.IDevice) uint32 {
+
+	first := t.gs == nil
+	if first {
+		t.texname = gs.CreateTexture()
+		t.gs = gs
+	}
+	t.transfer(gs)
+	if first {
+		gs.ObjectLabel(gls.TEXTURE, t.texname, t.name)
+	}
+	return t.texname
+}
+
+// RenderSetup is called by the material render setup. It binds this texture
+// to the specified texture unit and sets the unit of the sampler of the
+// specified index of the MatTexArray uniform.
+func (t *TextureArray) RenderSetup(gs gls.IDevice, unit, idx int) {
+
+	// One time initialization
+	first := t.gs == nil
+	if first {
+		t.texname = gs.CreateTexture()
+		t.gs = gs
+	}
+
+	// Sets the texture unit for this texture
+	gs.BindTextureUnitTarget(unit, gls.TEXTURE_2D_ARRAY, t.texname)
+	if first {
+		gs.ObjectLabel(gls.TEXTURE, t.texname, t.name)
+	}
+
+	// Transfer texture data and parameters to OpenGL if necessary
+	t.transfer(gs)
+
+	// Transfer uniforms
+	t.uTexture.Set(int32(unit))
+	t.uTexture.TransferIdx(gs, idx)
+}
+
+// transfer sends the texture data, the changed layers and the parameters
+// to the device if necessary. The texture is bound to the current texture unit.
+func (t *TextureArray) transfer(gs gls.IDevice) {
+
+	if !t.updateData && !t.updateParams && len(t.layerData) == 0 {
+		return
+	}
+	desc := gls.TextureDesc{
+		Target:         gls.TEXTURE_2D_ARRAY,
+		Width:          t.width,
+		Height:         t.height,
+		Depth:          t.layers,
+		InternalFormat: t.iformat,
+		Format:         t.format,
+		Type:           t.formatType,
+		Mipmaps:        t.genMipmap,
+		MagFilter:      t.magFilter,
+		MinFilter:      t.minFilter,
+		WrapS:          t.wrapS,
+		WrapT:          t.wrapT,
+	}
+
+	// Transfer texture data if necessary
+	if t.updateData {
+		gs.UploadTexture(t.texname, &desc, t.data)
+		t.updateData = false
+	}
+
+	// Transfer the changed layers, generating the mipmaps only once
+	if len(t.layerData) > 0 {
+		desc.Mipmaps = false
+		for layer, data := range t.layerData {
+			gs.UploadTextureLayer(t.texname, &desc, layer, data)
+		}
+		t.layerData = make(map[int]interface{})
+		if t.genMipmap {
+			gs.GenerateMipmap(gls.TEXTURE_2D_ARRAY)
+		}
+	}
+
+	// Sets texture parameters if needed
+	if t.updateParams {
+		gs.SetTextureSampler(t.texname, &desc)
+		t.updateParams = false
+	}
+}
@@ -0,0 +1,613 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js
+// +build js
+
+package window
+
+import (
+	"errors"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls/gl"
+	"strconv"
+	"sync"
+	"syscall/js"
+	"unicode/utf8"
+)
+
+// Backend is the name of the window manager selected at build time
+const Backend = "canvas"
+
+// Canvas is the window of the HTML canvas element of the page when the engine
+// is compiled to WebAssembly. The DOM key, mouse, wheel, focus and resize
+// events of the canvas are queued when they occur and dispatched by
+// PollEvents, as the events of the other windows. SwapBuffers waits for the
+// next animation frame of the browser, which presents the rendered frame
+// and runs the event handlers. The size of the drawing buffer is the size of
+// the canvas in the page multiplied by the device pixel ratio, which is the
+// scale of the window, and the positions of the mouse events are in pixels
+// of the drawing buffer.
+type Canvas struct {
+	core.Dispatcher
+	canvas       js.Value // HTML canvas element
+	keyEv        KeyEvent
+	charEv       CharEvent
+	textEv       TextEvent
+	mouseEv      MouseEvent
+	sizeEv       SizeEvent
+	cursorEv     CursorEvent
+	motionEv     MouseMotionEvent
+	scrollEv     ScrollEvent
+	scaleEv      ScaleEvent
+	width        int     // width of the drawing buffer in pixels
+	height       int     // height of the drawing buffer in pixels
+	scale        float32 // device pixel ratio
+	start        float64 // time of creation in milliseconds
+	shouldClose  bool
+	swapInterval int
+	limiter      FrameLimiter     // frame rate limiter applied by SwapBuffers
+	clipboard    string           // text of the clipboard set or pasted in the page
+	mutex        sync.Mutex       // protects the queued events and the clipboard
+	events       []func()         // DOM events queued for PollEvents
+	frame        chan struct{}    // signaled by the animation frame callback
+	frameFunc    js.Func          // animation frame callback
+	listeners    []canvasListener // DOM event listeners removed by Destroy
+}
+
+// canvasListener is a DOM event listener of a canvas window
+type canvasListener struct {
+	target js.Value
+	name   string
+	fn     js.Func
+}
+
+// canvasKeys maps the codes of the DOM keyboard events,
+// which identify the physical keys, to the engine keys
+var canvasKeys = map[string]Key{
+	"Space":          KeySpace,
+	"Quote":          KeyApostrophe,
+	"Comma":          KeyComma,
+	"Minus":          KeyMinus,
+	"Period":         KeyPeriod,
+	"Slash":          KeySlash,
+	"Semicolon":      KeySemicolon,
+	"Equal":          KeyEqual,
+	"BracketLeft":    KeyLeftBracket,
+	"Backslash":      KeyBackslash,
+	"BracketRight":   KeyRightBracket,
+	"Backquote":      KeyGraveAccent,
+	"IntlBackslash":  KeyWorld1,
+	"Escape":         KeyEscape,
+	"Enter":          KeyEnter,
+	"Tab":            KeyTab,
+	"Backspace":      KeyBackspace,
+	"Insert":         KeyInsert,
+	"Delete":         KeyDelete,
+	"ArrowRight":     KeyRight,
+	"ArrowLeft":      KeyLeft,
+	"ArrowDown":      KeyDown,
+	"ArrowUp":        KeyUp,
+	"PageUp":         KeyPageUp,
+	"PageDown":       KeyPageDown,
+	"Home":           KeyHome,
+	"End":            KeyEnd,
+	"CapsLock":       KeyCapsLock,
+	"ScrollLock":     KeyScrollLock,
+	"NumLock":        KeyNumLock,
+	"PrintScreen":    KeyPrintScreen,
+	"Pause":          KeyPause,
+	"NumpadDecimal":  KeyKPDecimal,
+	"NumpadDivide":   KeyKPDivide,
+	"NumpadMultiply": KeyKPMultiply,
+	"NumpadSubtract": KeyKPSubtract,
+	"NumpadAdd":      KeyKPAdd,
+	"NumpadEnter":    KeyKPEnter,
+	"NumpadEqual":    KeyKPEqual,
+	"ShiftLeft":      KeyLeftShift,
+	"ControlLeft":    KeyLeftControl,
+	"AltLeft":        KeyLeftAlt,
+	"MetaLeft":       KeyLeftSuper,
+	"ShiftRight":     KeyRightShift,
+	"ControlRight":   KeyRightControl,
+	"AltRight":       KeyRightAlt,
+	"MetaRight":      KeyRightSuper,
+	"ContextMenu":    KeyMenu,
+}
+
+// canvasCursors maps the standard cursors to CSS cursors
+var canvasCursors = map[StandardCursor]string{
+	ArrowCursor:     "default",
+	IBeamCursor:     "text",
+	CrosshairCursor: "crosshair",
+	HandCursor:      "pointer",
+	HResizeCursor:   "ew-resize",
+	VResizeCursor:   "ns-resize",
+}
+
+func init() {
+
+	for i := 0; i < 26; i++ {
+		canvasKeys["Key"+string(rune('A'+i))] = KeyA + Key(i)
+	}
+	for i := 0; i < 10; i++ {
+		canvasKeys["Digit"+string(rune('0'+i))] = Key0 + Key(i)
+		canvasKeys["Numpad"+string(rune('0'+i))] = KeyKP0 + Key(i)
+	}
+	for i := 0; i < 25; i++ {
+		canvasKeys["F"+strconv.Itoa(i+1)] = KeyF1 + Key(i)
+	}
+}
+
+// newWindow creates and returns a new window for the first canvas of the
+// document, creating the canvas if necessary, and sets its WebGL2 context
+// as the context used by the gl package. The canvas windows can't share
+// their contexts, as the gl package has only one context.
+func newWindow(width, height int, title string, full bool, share IWindow) (IWindow, error) {
+
+	if share != nil {
+		return nil, errors.New("canvas windows can not share their contexts")
+	}
+	doc := js.Global().Get("document")
+	if !doc.Truthy() {
+		return nil, errors.New("the canvas window requires a browser document")
+	}
+	canvas := doc.Call("querySelector", "canvas")
+	if !canvas.Truthy() {
+		canvas = doc.Call("createElement", "canvas")
+		doc.Get("body").Call("appendChild", canvas)
+	}
+	// The alpha of the framebuffer is blended with the page only if requested
+	attribs := map[string]interface{}{"antialias": true, "stencil": true, "alpha": hints.Transparent}
+	ctx := canvas.Call("getContext", "webgl2", attribs)
+	if !ctx.Truthy() {
+		return nil, errors.New("WebGL2 not supported")
+	}
+	gl.SetContext(ctx)
+
+	w := new(Canvas)
+	w.canvas = canvas
+	w.Dispatcher.Initialize()
+	w.start = js.Global().Get("performance").Call("now").Float()
+	w.frame = make(chan struct{}, 1)
+	w.frameFunc = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		select {
+		case w.frame <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	doc.Set("title", title)
+
+	// Sets the size of the canvas in the page, filling it if full screen
+	// was requested, as the browsers only enter the full screen mode
+	// from the handlers of user input events.
+	style := canvas.Get("style")
+	w.scale = float32(js.Global().Get("devicePixelRatio").Float())
+	if full {
+		style.Set("position", "fixed")
+		style.Set("left", "0")
+		style.Set("top", "0")
+		style.Set("width", "100vw")
+		style.Set("height", "100vh")
+	} else {
+		w.SetSize(width, height)
+	}
+	w.resize()
+
+	// The canvas receives the key events when it has the focus
+	canvas.Set("tabIndex", 0)
+	style.Set("outline", "none")
+	canvas.Call("focus")
+
+	w.listen(canvas, "keydown", false, func(ev js.Value) {
+
+		w.onKey(ev, Press)
+	})
+	w.listen(canvas, "keyup", false, func(ev js.Value) {
+
+		w.onKey(ev, Release)
+	})
+	w.listen(canvas, "mousedown", false, func(ev js.Value) {
+
+		canvas.Call("focus")
+		w.onMouse(ev, Press)
+	})
+	w.listen(canvas, "mouseup", false, func(ev js.Value) {
+
+		w.onMouse(ev, Release)
+	})
+	w.listen(canvas, "mousemove", false, w.onMouseMove)
+	w.listen(canvas, "wheel", true, w.onWheel)
+	w.listen(canvas, "contextmenu", true, func(ev js.Value) {})
+	w.listen(canvas, "focus", false, func(ev js.Value) {
+
+		w.queue(func() { w.Dispatch(OnWindowFocus, &FocusEvent{W: w, Focused: true}) })
+	})
+	w.listen(canvas, "blur", false, func(ev js.Value) {
+
+		w.queue(func() { w.Dispatch(OnWindowFocus, &FocusEvent{W: w, Focused: false}) })
+	})
+	w.listen(js.Global(), "resize", false, func(ev js.Value) {
+
+		w.queue(w.resize)
+	})
+	w.listen(doc, "visibilitychange", false, func(ev js.Value) {
+
+		hidden := doc.Get("hidden").Bool()
+		w.queue(func() { w.Dispatch(OnWindowIconify, &IconifyEvent{W: w, Iconified: hidden}) })
+	})
+	w.listen(doc, "paste", false, func(ev js.Value) {
+
+		text := ev.Get("clipboardData").Call("getData", "text").String()
+		w.mutex.Lock()
+		w.clipboard = text
+		w.mutex.Unlock()
+	})
+	return w, nil
+}
+
+// SwapInterval satisfies the IWindow interface. The browsers always present
+// the frames at the animation frames, synchronized with the display.
+func (w *Canvas) SwapInterval(interval int) {
+
+	w.swapInterval = interval
+}
+
+// GetSwapInterval returns the swap interval set by SwapInterval
+func (w *Canvas) GetSwapInterval() int {
+
+	return w.swapInterval
+}
+
+// SetFrameRateLimit sets the maximum frames per second, which SwapBuffers
+// keeps by waiting for the next frame period, or 0 for no limit
+func (w *Canvas) SetFrameRateLimit(fps float64) {
+
+	w.limiter.SetLimit(fps)
+}
+
+// GetFrameRateLimit returns the maximum frames per second or 0 if not limited
+func (w *Canvas) GetFrameRateLimit() float64 {
+
+	return w.limiter.Limit()
+}
+
+// MakeContextCurrent satisfies the IWindow interface.
+// The WebGL2 context of the canvas is always current.
+func (w *Canvas) MakeContextCurrent() {
+
+}
+
+// GetSize returns the size of the drawing buffer of the canvas in pixels
+func (w *Canvas) GetSize() (width int, height int) {
+
+	return w.width, w.height
+}
+
+// SetSize sets the size of the canvas in the page
+// to the specified size of its drawing buffer in pixels
+func (w *Canvas) SetSize(width int, height int) {
+
+	style := w.canvas.Get("style")
+	style.Set("width", strconv.FormatFloat(float64(float32(width)/w.scale), 'f', -1, 32)+"px")
+	style.Set("height", strconv.FormatFloat(float64(float32(height)/w.scale), 'f', -1, 32)+"px")
+	w.queue(w.resize)
+}
+
+// GetPos returns the position of the canvas in the viewport of the page
+func (w *Canvas) GetPos() (xpos, ypos int) {
+
+	rect := w.canvas.Call("getBoundingClientRect")
+	return rect.Get("left").Int(), rect.Get("top").Int()
+}
+
+// SetPos satisfies the IWindow interface.
+// The position of the canvas is set by the layout of the page.
+func (w *Canvas) SetPos(xpos, ypos int) {
+
+}
+
+// SetTitle sets the title of the document
+func (w *Canvas) SetTitle(title string) {
+
+	js.Global().Get("document").Set("title", title)
+}
+
+// SetStandardCursor sets the CSS cursor of the canvas
+func (w *Canvas) SetStandardCursor(cursor StandardCursor) {
+
+	name, ok := canvasCursors[cursor]
+	if !ok {
+		return
+	}
+	w.canvas.Get("style").Set("cursor", name)
+}
+
+// SetClipboardString writes the specified text to the system clipboard,
+// if the page is allowed to, and keeps it for GetClipboardString
+func (w *Canvas) SetClipboardString(text string) {
+
+	w.mutex.Lock()
+	w.clipboard = text
+	w.mutex.Unlock()
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if clipboard.Truthy() {
+		clipboard.Call("writeText", text)
+	}
+}
+
+// GetClipboardString returns the text last set by SetClipboardString or
+// pasted in the page, as the browsers only let the pages read the system
+// clipboard asynchronously
+func (w *Canvas) GetClipboardString() (string, error) {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.clipboard, nil
+}
+
+// GetScale returns the device pixel ratio of the page
+func (w *Canvas) GetScale() float32 {
+
+	return w.scale
+}
+
+func (w *Canvas) ShouldClose() bool {
+
+	return w.shouldClose
+}
+
+func (w *Canvas) SetShouldClose(v bool) {
+
+	w.shouldClose = v
+}
+
+// SwapBuffers waits for the next animation frame of the browser, which
+// presents the rendered frame, and for the next frame period if the frame
+// rate is limited. The browser runs the handlers of the DOM events meanwhile.
+func (w *Canvas) SwapBuffers() {
+
+	js.Global().Call("requestAnimationFrame", w.frameFunc)
+	<-w.frame
+	w.limiter.Wait()
+}
+
+// PollEvents dispatches the DOM events queued since the last call
+func (w *Canvas) PollEvents() {
+
+	w.mutex.Lock()
+	events := w.events
+	w.events = nil
+	w.mutex.Unlock()
+	for _, dispatch := range events {
+		dispatch()
+	}
+}
+
+// GetTime returns the time in seconds since the window was created
+func (w *Canvas) GetTime() float64 {
+
+	return (js.Global().Get("performance").Call("now").Float() - w.start) / 1000
+}
+
+// Destroy removes the DOM event listeners of this window.
+// The canvas is kept in the page.
+func (w *Canvas) Destroy() {
+
+	for _, l := range w.listeners {
+		l.target.Call("removeEventListener", l.name, l.fn)
+		l.fn.Release()
+	}
+	w.listeners = nil
+	w.frameFunc.Release()
+}
+
+// listen adds a listener of the specified DOM event of the specified target
+// which calls the specified function, preventing the default action of the
+// browser if requested
+func (w *Canvas) listen(target js.Value, name string, prevent bool, cb func(ev js.Value)) {
+
+	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+
+		ev := args[0]
+		if prevent {
+			ev.Call("preventDefault")
+		}
+		cb(ev)
+		return nil
+	})
+	opts := map[string]interface{}{"passive": !prevent}
+	target.Call("addEventListener", name, fn, opts)
+	w.listeners = append(w.listeners, canvasListener{target, name, fn})
+}
+
+// queue appends the specified dispatch of a DOM event to the
+// events dispatched by the next call of PollEvents
+func (w *Canvas) queue(dispatch func()) {
+
+	w.mutex.Lock()
+	w.events = append(w.events, dispatch)
+	w.mutex.Unlock()
+}
+
+// onKey queues the key events of the specified DOM keyboard event and, for
+// the keys pressed which type a character, its char and text events.
+// The default actions of the browser are prevented, as scrolling the page,
+// except for the shortcuts with the Control and Meta keys, as copy and paste.
+func (w *Canvas) onKey(ev js.Value, action Action) {
+
+	key, ok := canvasKeys[ev.Get("code").String()]
+	if !ok {
+		key = KeyUnknown
+	}
+	if action == Press && ev.Get("repeat").Bool() {
+		action = Repeat
+	}
+	scancode := ev.Get("keyCode").Int()
+	mods := canvasMods(ev)
+	if mods&(ModControl|ModSuper) == 0 {
+		ev.Call("preventDefault")
+	}
+	text := ev.Get("key").String()
+	typed := action != Release && utf8.RuneCountInString(text) == 1 && mods&(ModControl|ModSuper) == 0
+	w.queue(func() {
+
+		w.keyEv.W = w
+		w.keyEv.Keycode = key
+		w.keyEv.Scancode = scancode
+		w.keyEv.Action = action
+		w.keyEv.Mods = mods
+		if action == Release {
+			w.Dispatch(OnKeyUp, &w.keyEv)
+		} else {
+			w.Dispatch(OnKeyDown, &w.keyEv)
+		}
+		if !typed {
+			return
+		}
+		r, _ := utf8.DecodeRuneInString(text)
+		w.charEv.W = w
+		w.charEv.Char = r
+		w.charEv.Mods = mods
+		w.Dispatch(OnChar, &w.charEv)
+		w.textEv.W = w
+		w.textEv.Text = text
+		w.Dispatch(OnText, &w.textEv)
+	})
+}
+
+// onMouse queues the mouse button event of the specified DOM mouse event
+func (w *Canvas) onMouse(ev js.Value, action Action) {
+
+	var button MouseButton
+	switch ev.Get("button").Int() {
+	case 0:
+		button = MouseButtonLeft
+	case 1:
+		button = MouseButtonMiddle
+	case 2:
+		button = MouseButtonRight
+	case 3:
+		button = MouseButton4
+	case 4:
+		button = MouseButton5
+	default:
+		return
+	}
+	xpos, ypos := w.mousePos(ev)
+	mods := canvasMods(ev)
+	w.queue(func() {
+
+		w.mouseEv.W = w
+		w.mouseEv.Button = button
+		w.mouseEv.Action = action
+		w.mouseEv.Mods = mods
+		w.mouseEv.Xpos = xpos
+		w.mouseEv.Ypos = ypos
+		if action == Press {
+			w.Dispatch(OnMouseDown, &w.mouseEv)
+		} else {
+			w.Dispatch(OnMouseUp, &w.mouseEv)
+		}
+	})
+}
+
+// onMouseMove queues the cursor and motion events of the specified DOM mouse event
+func (w *Canvas) onMouseMove(ev js.Value) {
+
+	xpos, ypos := w.mousePos(ev)
+	dx := float32(ev.Get("movementX").Float()) * w.scale
+	dy := float32(ev.Get("movementY").Float()) * w.scale
+	w.queue(func() {
+
+		w.cursorEv.W = w
+		w.cursorEv.Xpos = xpos
+		w.cursorEv.Ypos = ypos
+		w.Dispatch(OnCursor, &w.cursorEv)
+		w.motionEv.W = w
+		w.motionEv.DeltaX = dx
+		w.motionEv.DeltaY = dy
+		w.Dispatch(OnMouseMotion, &w.motionEv)
+	})
+}
+
+// onWheel queues the scroll event of the specified DOM wheel event.
+// The offsets are in lines, positive to the left and up, as for GLFW,
+// and the pixel and page offsets of the wheel events are converted to lines.
+func (w *Canvas) onWheel(ev js.Value) {
+
+	xoff := float32(ev.Get("deltaX").Float())
+	yoff := float32(ev.Get("deltaY").Float())
+	var lines float32
+	switch ev.Get("deltaMode").Int() {
+	case 0: // pixels
+		lines = -1.0 / 100
+	case 1: // lines
+		lines = -1.0 / 3
+	default: // pages
+		lines = -1
+	}
+	w.queue(func() {
+
+		w.scrollEv.W = w
+		w.scrollEv.Xoffset = xoff * lines
+		w.scrollEv.Yoffset = yoff * lines
+		w.Dispatch(OnScroll, &w.scrollEv)
+	})
+}
+
+// mousePos returns the position of the specified DOM mouse
+// event in pixels of the drawing buffer of the canvas
+func (w *Canvas) mousePos(ev js.Value) (float32, float32) {
+
+	return float32(ev.Get("offsetX").Float()) * w.scale, float32(ev.Get("offsetY").Float()) * w.scale
+}
+
+// resize sets the size of the drawing buffer of the canvas from its size
+// in the page and the device pixel ratio and dispatches the size and scale
+// events if they changed
+func (w *Canvas) resize() {
+
+	scale := float32(js.Global().Get("devicePixelRatio").Float())
+	width := int(float32(w.canvas.Get("clientWidth").Float())*scale + 0.5)
+	height := int(float32(w.canvas.Get("clientHeight").Float())*scale + 0.5)
+	if scale != w.scale {
+		w.scale = scale
+		w.scaleEv.W = w
+		w.scaleEv.Scale = scale
+		w.Dispatch(OnScale, &w.scaleEv)
+	}
+	if width == w.width && height == w.height {
+		return
+	}
+	w.width = width
+	w.height = height
+	w.canvas.Set("width", width)
+	w.canvas.Set("height", height)
+	w.sizeEv.W = w
+	w.sizeEv.Width = width
+	w.sizeEv.Height = height
+	w.Dispatch(OnWindowSize, &w.sizeEv)
+}
+
+// canvasMods returns the modifier keys of the specified DOM keyboard or mouse event
+func canvasMods(ev js.Value) ModifierKey {
+
+	var mods ModifierKey
+	if ev.Get("shiftKey").Bool() {
+		mods |= ModShift
+	}
+	if ev.Get("ctrlKey").Bool() {
+		mods |= ModControl
+	}
+	if ev.Get("altKey").Bool() {
+		mods |= ModAlt
+	}
+	if ev.Get("metaKey").Bool() {
+		mods |= ModSuper
+	}
+	return mods
+}
@@ -0,0 +1,23 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"errors"
+	"image"
+)
+
+// IClipboardImage is the interface of the windows whose clipboard keeps
+// images besides the text set by SetClipboardString. The clipboard keeps
+// either a text or an image, so setting one of them clears the other.
+// The GLFW and SDL windows don't implement it as their libraries only
+// exchange text with the system clipboard.
+type IClipboardImage interface {
+	SetClipboardImage(img image.Image) error
+	GetClipboardImage() (image.Image, error)
+}
+
+// ErrClipboardNoImage is returned by GetClipboardImage when the clipboard has no image
+var ErrClipboardNoImage = errors.New("clipboard has no image")
@@ -0,0 +1,36 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"image"
+)
+
+// ICursor is the interface of the windows which support custom cursors
+// created from images and hiding and capturing the cursor.
+// In the CursorDisabled mode the cursor is hidden and captured by the window
+// and the OnMouseMotion events report unbounded motion, as needed by first
+// person camera controls, using the raw mouse input where available.
+type ICursor interface {
+	CreateCursor(img image.Image, xhot, yhot int) (CustomCursor, error)
+	SetCustomCursor(cursor CustomCursor)
+	DisposeCursor(cursor CustomCursor)
+	SetCursorMode(mode int)
+	GetCursorMode() int
+}
+
+// CustomCursor is the identifier of a custom cursor created by a window
+type CustomCursor int
+
+// OnMouseMotion is the name of the events of the relative mouse motion,
+// which are dispatched with the cursor position events
+const OnMouseMotion = "win.OnMouseMotion"
+
+// Relative mouse motion in window pixels
+type MouseMotionEvent struct {
+	W      IWindow
+	DeltaX float32
+	DeltaY float32
+}
@@ -0,0 +1,294 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"math"
+	"runtime"
+)
+
+// IGamepad is the interface of the windows which support joysticks and
+// gamepads. The joysticks are polled by PollEvents, which dispatches
+// OnJoystickConnect and OnJoystickDisconnect events when they are connected
+// and disconnected and OnGamepadButtonDown, OnGamepadButtonUp and
+// OnGamepadAxis events when the state of their standard gamepad buttons and
+// axes changes. The state may also be read every frame with GamepadState.
+type IGamepad interface {
+	JoystickPresent(joy Joystick) bool
+	JoystickName(joy Joystick) string
+	GamepadState(joy Joystick) (GamepadState, bool)
+	SetGamepadDeadZone(dz float32)
+}
+
+// Joystick is the number of a joystick from 0 to JoystickLast
+type Joystick int
+
+// JoystickLast is the number of the last joystick
+const JoystickLast = Joystick(15)
+
+// GamepadButton is a button of the standard gamepad layout,
+// whose names are the ones of an Xbox controller
+type GamepadButton int
+
+// Standard gamepad buttons
+const (
+	GamepadButtonA GamepadButton = iota
+	GamepadButtonB
+	GamepadButtonX
+	GamepadButtonY
+	GamepadButtonLeftBumper
+	GamepadButtonRightBumper
+	GamepadButtonBack
+	GamepadButtonStart
+	GamepadButtonGuide
+	GamepadButtonLeftThumb
+	GamepadButtonRightThumb
+	GamepadButtonDpadUp
+	GamepadButtonDpadRight
+	GamepadButtonDpadDown
+	GamepadButtonDpadLeft
+	GamepadButtonLast = GamepadButtonDpadLeft
+)
+
+// GamepadAxis is an axis of the standard gamepad layout
+type GamepadAxis int
+
+// Standard gamepad axes. The stick axes range from -1 to 1, with the
+// negative values up and left, and the trigger axes range from 0 to 1.
+const (
+	GamepadAxisLeftX GamepadAxis = iota
+	GamepadAxisLeftY
+	GamepadAxisRightX
+	GamepadAxisRightY
+	GamepadAxisLeftTrigger
+	GamepadAxisRightTrigger
+	GamepadAxisLast = GamepadAxisRightTrigger
+)
+
+//
+// Joystick and gamepad event names
+//
+const (
+	OnJoystickConnect    = "win.OnJoystickConnect"
+	OnJoystickDisconnect = "win.OnJoystickDisconnect"
+	OnGamepadButtonDown  = "win.OnGamepadButtonDown"
+	OnGamepadButtonUp    = "win.OnGamepadButtonUp"
+	OnGamepadAxis        = "win.OnGamepadAxis"
+)
+
+// Joystick connected or disconnected
+type JoystickEvent struct {
+	W    IWindow
+	Joy  Joystick
+	Name string
+}
+
+// Standard gamepad button pressed or released
+type GamepadButtonEvent struct {
+	W      IWindow
+	Joy    Joystick
+	Button GamepadButton
+}
+
+// Standard gamepad axis value changed
+type GamepadAxisEvent struct {
+	W     IWindow
+	Joy   Joystick
+	Axis  GamepadAxis
+	Value float32
+}
+
+// GamepadState is the state of the standard gamepad buttons and axes of a
+// joystick with its dead zones applied
+type GamepadState struct {
+	Buttons [GamepadButtonLast + 1]bool
+	Axes    [GamepadAxisLast + 1]float32
+}
+
+// GamepadMapping maps the buttons and axes of joysticks, which are different
+// for each device and platform, to the standard gamepad layout
+type GamepadMapping struct {
+	Buttons [GamepadButtonLast + 1]GamepadInput
+	Axes    [GamepadAxisLast + 1]GamepadInput
+}
+
+// GamepadInput is the joystick button or axis mapped to a standard gamepad
+// button or axis. The value of a standard axis is the joystick axis value
+// multiplied by Scale plus Offset. A standard button mapped from a joystick
+// axis, as the directional pad of some devices, is pressed when this value
+// is greater than 0.5.
+type GamepadInput struct {
+	Index  int  // index of the joystick button or axis or -1 if not mapped
+	Axis   bool // the joystick input is an axis
+	Scale  float32
+	Offset float32
+}
+
+// gamepadMappings are the mappings registered by joystick name
+var gamepadMappings = map[string]*GamepadMapping{}
+
+// gamepadMappingDefault is the mapping of the joysticks with no registered mapping
+var gamepadMappingDefault = defaultGamepadMapping()
+
+// SetGamepadMapping registers the mapping used for the joysticks with the
+// specified name, as returned by JoystickName. An empty name sets the
+// mapping of the joysticks whose names have no registered mapping, which by
+// default is the layout of the Xbox controllers in the current platform.
+// A nil mapping removes the registration.
+// The mapping is used for the joysticks connected after it is registered.
+func SetGamepadMapping(name string, m *GamepadMapping) {
+
+	if name == "" {
+		if m == nil {
+			m = defaultGamepadMapping()
+		}
+		gamepadMappingDefault = m
+		return
+	}
+	if m == nil {
+		delete(gamepadMappings, name)
+		return
+	}
+	gamepadMappings[name] = m
+}
+
+// gamepadMappingFor returns the mapping for the joystick with the specified name
+func gamepadMappingFor(name string) *GamepadMapping {
+
+	if m := gamepadMappings[name]; m != nil {
+		return m
+	}
+	return gamepadMappingDefault
+}
+
+// defaultGamepadMapping returns the layout reported for the Xbox
+// controllers in the current platform
+func defaultGamepadMapping() *GamepadMapping {
+
+	button := func(idx int) GamepadInput { return GamepadInput{Index: idx, Scale: 1} }
+	axis := func(idx int, scale, offset float32) GamepadInput {
+		return GamepadInput{Index: idx, Axis: true, Scale: scale, Offset: offset}
+	}
+	none := GamepadInput{Index: -1}
+	m := new(GamepadMapping)
+	m.Buttons[GamepadButtonA] = button(0)
+	m.Buttons[GamepadButtonB] = button(1)
+	m.Buttons[GamepadButtonX] = button(2)
+	m.Buttons[GamepadButtonY] = button(3)
+	m.Buttons[GamepadButtonLeftBumper] = button(4)
+	m.Buttons[GamepadButtonRightBumper] = button(5)
+	m.Buttons[GamepadButtonBack] = button(6)
+	m.Buttons[GamepadButtonStart] = button(7)
+	m.Axes[GamepadAxisLeftX] = axis(0, 1, 0)
+	if runtime.GOOS == "linux" {
+		// Linux xpad driver: the directional pad is reported by the hat axes
+		m.Buttons[GamepadButtonGuide] = button(8)
+		m.Buttons[GamepadButtonLeftThumb] = button(9)
+		m.Buttons[GamepadButtonRightThumb] = button(10)
+		m.Buttons[GamepadButtonDpadUp] = axis(7, -1, 0)
+		m.Buttons[GamepadButtonDpadRight] = axis(6, 1, 0)
+		m.Buttons[GamepadButtonDpadDown] = axis(7, 1, 0)
+		m.Buttons[GamepadButtonDpadLeft] = axis(6, -1, 0)
+		m.Axes[GamepadAxisLeftY] = axis(1, 1, 0)
+		m.Axes[GamepadAxisRightX] = axis(3, 1, 0)
+		m.Axes[GamepadAxisRightY] = axis(4, 1, 0)
+		m.Axes[GamepadAxisLeftTrigger] = axis(2, 0.5, 0.5)
+		m.Axes[GamepadAxisRightTrigger] = axis(5, 0.5, 0.5)
+		return m
+	}
+	// XInput: the guide button is not reported and the Y axes are up positive
+	m.Buttons[GamepadButtonGuide] = none
+	m.Buttons[GamepadButtonLeftThumb] = button(8)
+	m.Buttons[GamepadButtonRightThumb] = button(9)
+	m.Buttons[GamepadButtonDpadUp] = button(10)
+	m.Buttons[GamepadButtonDpadRight] = button(11)
+	m.Buttons[GamepadButtonDpadDown] = button(12)
+	m.Buttons[GamepadButtonDpadLeft] = button(13)
+	m.Axes[GamepadAxisLeftY] = axis(1, -1, 0)
+	m.Axes[GamepadAxisRightX] = axis(2, 1, 0)
+	m.Axes[GamepadAxisRightY] = axis(3, -1, 0)
+	m.Axes[GamepadAxisLeftTrigger] = axis(4, 0.5, 0.5)
+	m.Axes[GamepadAxisRightTrigger] = axis(5, 0.5, 0.5)
+	return m
+}
+
+// State sets the specified standard gamepad state from the specified
+// joystick axes and buttons values, with the specified dead zone applied
+// to the sticks, as a radius, and to the triggers
+func (m *GamepadMapping) State(axes []float32, buttons []byte, deadZone float32, state *GamepadState) {
+
+	for i, in := range m.Buttons {
+		state.Buttons[i] = false
+		if in.Index < 0 {
+			continue
+		}
+		if in.Axis {
+			state.Buttons[i] = in.Index < len(axes) && axes[in.Index]*in.Scale+in.Offset > 0.5
+		} else {
+			state.Buttons[i] = in.Index < len(buttons) && buttons[in.Index] != 0
+		}
+	}
+	for i, in := range m.Axes {
+		state.Axes[i] = 0
+		if in.Index < 0 || !in.Axis || in.Index >= len(axes) {
+			continue
+		}
+		state.Axes[i] = axes[in.Index]*in.Scale + in.Offset
+	}
+	deadZoneStick(&state.Axes[GamepadAxisLeftX], &state.Axes[GamepadAxisLeftY], deadZone)
+	deadZoneStick(&state.Axes[GamepadAxisRightX], &state.Axes[GamepadAxisRightY], deadZone)
+	deadZoneTrigger(&state.Axes[GamepadAxisLeftTrigger], deadZone)
+	deadZoneTrigger(&state.Axes[GamepadAxisRightTrigger], deadZone)
+}
+
+// deadZoneStick zeroes the specified stick axes inside the specified dead
+// zone radius and rescales the values outside it to start from zero
+func deadZoneStick(x, y *float32, dz float32) {
+
+	mag := float32(math.Sqrt(float64(*x**x + *y**y)))
+	if mag <= dz {
+		*x, *y = 0, 0
+		return
+	}
+	scale := (mag - dz) / (1 - dz) / mag
+	if mag > 1 {
+		scale = 1 / mag
+	}
+	*x *= scale
+	*y *= scale
+}
+
+// deadZoneTrigger zeroes the specified trigger axis inside the specified
+// dead zone and rescales the values outside it to start from zero
+func deadZoneTrigger(v *float32, dz float32) {
+
+	if *v <= dz {
+		*v = 0
+		return
+	}
+	*v = float32(math.Min(1, float64((*v-dz)/(1-dz))))
+}
+
+// dispatchGamepad dispatches the events of the changes from the specified
+// previous state to the specified current state of the specified joystick
+func dispatchGamepad(w IWindow, joy Joystick, prev, curr *GamepadState) {
+
+	for i := range curr.Buttons {
+		if curr.Buttons[i] == prev.Buttons[i] {
+			continue
+		}
+		ev := &GamepadButtonEvent{W: w, Joy: joy, Button: GamepadButton(i)}
+		if curr.Buttons[i] {
+			w.Dispatch(OnGamepadButtonDown, ev)
+		} else {
+			w.Dispatch(OnGamepadButtonUp, ev)
+		}
+	}
+	for i := range curr.Axes {
+		if curr.Axes[i] != prev.Axes[i] {
+			w.Dispatch(OnGamepadAxis, &GamepadAxisEvent{W: w, Joy: joy, Axis: GamepadAxis(i), Value: curr.Axes[i]})
+		}
+	}
+}
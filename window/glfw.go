@@ -2,36 +2,94 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build !sdl && !js
+// +build !sdl,!js
+
 package window
 
 import (
+	"fmt"
 	"github.com/g3n/engine/core"
 	"github.com/go-gl/glfw/v3.2/glfw"
+	"image"
+	"math"
 )
 
+// Backend is the name of the window manager selected at build time
+const Backend = "glfw"
+
+// GLFW is the window of the GLFW window manager.
+// It doesn't implement IIME, as GLFW 3.2 doesn't report the composition
+// text of the input method, so it never dispatches OnPreedit events.
 type GLFW struct {
 	core.Dispatcher
 	win             *glfw.Window
 	keyEv           KeyEvent
 	charEv          CharEvent
+	textEv          TextEvent
 	mouseEv         MouseEvent
 	posEv           PosEvent
 	sizeEv          SizeEvent
 	cursorEv        CursorEvent
 	scrollEv        ScrollEvent
+	scaleEv         ScaleEvent
+	scale           float32 // content scale of the window monitor
 	arrowCursor     *glfw.Cursor
 	ibeamCursor     *glfw.Cursor
 	crosshairCursor *glfw.Cursor
 	handCursor      *glfw.Cursor
 	hresizeCursor   *glfw.Cursor
 	vresizeCursor   *glfw.Cursor
+	cursors         map[CustomCursor]*glfw.Cursor // custom cursors
+	lastCursor      CustomCursor                  // identifier of the last custom cursor created
+	motionEv        MouseMotionEvent
+	motionX         float64 // cursor position of the last motion event
+	motionY         float64 // cursor position of the last motion event
+	motionValid     bool    // cursor position of the last motion event is valid
+	fullscreen      FullscreenMode
+	fullMode        VideoMode  // video mode of the Exclusive mode
+	windowed        windowRect // windowed position and size saved when full screen
+	swapInterval    int
+	limiter         FrameLimiter // frame rate limiter applied by SwapBuffers
+	joysticks       [JoystickLast + 1]glfwJoystick
+	deadZone        float32 // dead zone of the gamepad sticks and triggers
+}
+
+// glfwJoystick is the state of a joystick
+type glfwJoystick struct {
+	present bool
+	name    string
+	mapping *GamepadMapping
+	state   GamepadState
 }
 
 // Global GLFW initialization flag
 // is initialized when the first window is created
 var initialized bool = false
 
-func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
+// Windows not destroyed, which receive the joystick events
+var glfwWindows []*GLFW
+
+// newWindow creates and returns a new GLFW window which
+// shares the OpenGL objects of the specified window if not nil
+func newWindow(width, height int, title string, full bool, share IWindow) (IWindow, error) {
+
+	var sw *GLFW
+	if share != nil {
+		var ok bool
+		sw, ok = share.(*GLFW)
+		if !ok {
+			panic("Unsupported window type")
+		}
+	}
+	w, err := newGLFW(width, height, title, full, sw)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func newGLFW(width, height int, title string, full bool, share *GLFW) (*GLFW, error) {
 
 	// Initialize GLFW once before the first window is created
 	if !initialized {
@@ -40,9 +98,7 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 			return nil, err
 		}
 		// Sets window hints
-		glfw.WindowHint(glfw.ContextVersionMajor, 3)
-		glfw.WindowHint(glfw.ContextVersionMinor, 3)
-		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+		setContextHints()
 		glfw.WindowHint(glfw.Samples, 8)
 		initialized = true
 	}
@@ -56,8 +112,19 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 		height = vmode.Height
 	}
 
+	// Sets the hints of the window options
+	glfw.WindowHint(glfw.Decorated, glfwBool(!hints.Undecorated))
+	glfw.WindowHint(glfw.Floating, glfwBool(hints.Floating))
+	if hints.Transparent {
+		log.Warn("GLFW 3.2 has no transparent framebuffer: the window is opaque")
+	}
+
 	// Creates window and sets it as the current context
-	win, err := glfw.CreateWindow(width, height, title, mon, nil)
+	var shareWin *glfw.Window
+	if share != nil {
+		shareWin = share.win
+	}
+	win, err := glfw.CreateWindow(width, height, title, mon, shareWin)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +134,13 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 	w := new(GLFW)
 	w.win = win
 	w.Dispatcher.Initialize()
+	w.scale = w.monitorScale()
+	if full {
+		// The window returns to a centered window when windowed
+		mx, my := mon.GetPos()
+		w.fullscreen = Borderless
+		w.windowed = windowRect{mx + width/8, my + height/8, width * 3 / 4, height * 3 / 4}
+	}
 
 	// Set key callback to dispatch event
 	win.SetKeyCallback(func(x *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
@@ -95,6 +169,14 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 		w.Dispatch(OnChar, &w.charEv)
 	})
 
+	// Set text input callback
+	win.SetCharCallback(func(x *glfw.Window, char rune) {
+
+		w.textEv.W = w
+		w.textEv.Text = string(char)
+		w.Dispatch(OnText, &w.textEv)
+	})
+
 	// Set mouse button callback to dispatch event
 	win.SetMouseButtonCallback(func(x *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
 
@@ -122,6 +204,7 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 		w.sizeEv.Width = width
 		w.sizeEv.Height = height
 		w.Dispatch(OnWindowSize, &w.sizeEv)
+		w.updateScale()
 	})
 
 	// Set window position event callback to dispatch event
@@ -131,6 +214,7 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 		w.posEv.Xpos = xpos
 		w.posEv.Ypos = ypos
 		w.Dispatch(OnWindowPos, &w.posEv)
+		w.updateScale()
 	})
 
 	// Set window cursor position event callback to dispatch event
@@ -140,6 +224,22 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 		w.cursorEv.Xpos = float32(xpos)
 		w.cursorEv.Ypos = float32(ypos)
 		w.Dispatch(OnCursor, &w.cursorEv)
+		// The relative motion is the change of the cursor position,
+		// which is not bounded by the window in the CursorDisabled mode
+		if w.motionValid {
+			w.motionEv.W = w
+			w.motionEv.DeltaX = float32(xpos - w.motionX)
+			w.motionEv.DeltaY = float32(ypos - w.motionY)
+			w.Dispatch(OnMouseMotion, &w.motionEv)
+		}
+		w.motionX, w.motionY = xpos, ypos
+		w.motionValid = true
+	})
+
+	// The motion restarts when the cursor enters the window
+	win.SetCursorEnterCallback(func(x *glfw.Window, entered bool) {
+
+		w.motionValid = false
 	})
 
 	// Set mouse wheel scroll event callback to dispatch event
@@ -151,6 +251,59 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 		w.Dispatch(OnScroll, &w.scrollEv)
 	})
 
+	// Set focus callback to dispatch event
+	win.SetFocusCallback(func(x *glfw.Window, focused bool) {
+
+		w.Dispatch(OnWindowFocus, &FocusEvent{W: w, Focused: focused})
+	})
+
+	// Set iconify callback to dispatch event
+	win.SetIconifyCallback(func(x *glfw.Window, iconified bool) {
+
+		w.Dispatch(OnWindowIconify, &IconifyEvent{W: w, Iconified: iconified})
+	})
+
+	// Set close callback to dispatch event, which may be vetoed
+	win.SetCloseCallback(func(x *glfw.Window) {
+
+		if w.Dispatch(OnWindowClose, &CloseEvent{W: w}) {
+			x.SetShouldClose(false)
+		}
+	})
+
+	// Set drop callback to dispatch the files dropped onto the window
+	win.SetDropCallback(func(x *glfw.Window, names []string) {
+
+		xpos, ypos := x.GetCursorPos()
+		w.Dispatch(OnFileDrop, &FileDropEvent{W: w, Xpos: float32(xpos), Ypos: float32(ypos), Files: names})
+	})
+
+	// Set joystick callback to dispatch connection events.
+	// The joystick states are polled by PollEvents.
+	w.deadZone = 0.15
+	for joy := Joystick(0); joy <= JoystickLast; joy++ {
+		if glfw.JoystickPresent(glfw.Joystick1 + glfw.Joystick(joy)) {
+			w.connectJoystick(joy)
+		}
+	}
+	// The callback is shared by all windows
+	glfwWindows = append(glfwWindows, w)
+	glfw.SetJoystickCallback(func(joy, event int) {
+
+		j := Joystick(joy - int(glfw.Joystick1))
+		if j < 0 || j > JoystickLast {
+			return
+		}
+		for _, w := range glfwWindows {
+			if event == int(glfw.Connected) {
+				w.connectJoystick(j)
+				w.Dispatch(OnJoystickConnect, &JoystickEvent{W: w, Joy: j, Name: w.joysticks[j].name})
+			} else {
+				w.disconnectJoystick(j)
+			}
+		}
+	})
+
 	// Preallocate standard cursors
 	w.arrowCursor = glfw.CreateStandardCursor(int(glfw.ArrowCursor))
 	w.ibeamCursor = glfw.CreateStandardCursor(int(glfw.IBeamCursor))
@@ -158,13 +311,37 @@ func newGLFW(width, height int, title string, full bool) (*GLFW, error) {
 	w.handCursor = glfw.CreateStandardCursor(int(glfw.HandCursor))
 	w.hresizeCursor = glfw.CreateStandardCursor(int(glfw.HResizeCursor))
 	w.vresizeCursor = glfw.CreateStandardCursor(int(glfw.VResizeCursor))
+	w.cursors = make(map[CustomCursor]*glfw.Cursor)
 
 	return w, nil
 }
 
+// SwapInterval sets the number of monitor refreshes to wait for before
+// swapping the buffers of the current context: 0 disables the vertical
+// synchronization (vsync) and 1 enables it
 func (w *GLFW) SwapInterval(interval int) {
 
 	glfw.SwapInterval(interval)
+	w.swapInterval = interval
+}
+
+// GetSwapInterval returns the swap interval set by SwapInterval
+func (w *GLFW) GetSwapInterval() int {
+
+	return w.swapInterval
+}
+
+// SetFrameRateLimit sets the maximum frames per second, which SwapBuffers
+// keeps by waiting, or 0 for no limit (default)
+func (w *GLFW) SetFrameRateLimit(fps float64) {
+
+	w.limiter.SetLimit(fps)
+}
+
+// GetFrameRateLimit returns the maximum frames per second or 0 for no limit
+func (w *GLFW) GetFrameRateLimit() float64 {
+
+	return w.limiter.Limit()
 }
 
 func (w *GLFW) MakeContextCurrent() {
@@ -217,6 +394,232 @@ func (w *GLFW) SetStandardCursor(cursor StandardCursor) {
 	}
 }
 
+// CreateCursor creates a custom cursor from the specified image, whose
+// hot spot, the point of the cursor position, is at the specified position
+// from the top left corner of the image
+func (w *GLFW) CreateCursor(img image.Image, xhot, yhot int) (CustomCursor, error) {
+
+	c := glfw.CreateCursor(img, xhot, yhot)
+	if c == nil {
+		return 0, fmt.Errorf("Error creating cursor")
+	}
+	w.lastCursor++
+	w.cursors[w.lastCursor] = c
+	return w.lastCursor, nil
+}
+
+// SetCustomCursor sets the cursor of this window to the specified custom cursor
+func (w *GLFW) SetCustomCursor(cursor CustomCursor) {
+
+	c, ok := w.cursors[cursor]
+	if !ok {
+		panic("Invalid cursor")
+	}
+	w.win.SetCursor(c)
+}
+
+// DisposeCursor destroys the specified custom cursor,
+// which must not be the current cursor
+func (w *GLFW) DisposeCursor(cursor CustomCursor) {
+
+	c, ok := w.cursors[cursor]
+	if !ok {
+		return
+	}
+	c.Destroy()
+	delete(w.cursors, cursor)
+}
+
+// SetCursorMode sets the cursor mode to CursorNormal,
+// CursorHidden or CursorDisabled
+func (w *GLFW) SetCursorMode(mode int) {
+
+	w.win.SetInputMode(glfw.CursorMode, mode)
+	w.motionValid = false
+}
+
+// GetCursorMode returns the current cursor mode
+func (w *GLFW) GetCursorMode() int {
+
+	return w.win.GetInputMode(glfw.CursorMode)
+}
+
+// Monitors returns the monitors connected to the system
+// starting with the primary monitor
+func (w *GLFW) Monitors() []Monitor {
+
+	var monitors []Monitor
+	for _, m := range glfw.GetMonitors() {
+		mon := Monitor{Name: m.GetName()}
+		mon.Xpos, mon.Ypos = m.GetPos()
+		mon.WidthMM, mon.HeightMM = m.GetPhysicalSize()
+		mon.Mode = glfwVideoMode(m.GetVideoMode())
+		// The modes which differ only by the color bits are the same mode
+		for _, vm := range m.GetVideoModes() {
+			mode := glfwVideoMode(vm)
+			if modeIndex(mon.Modes, mode) < 0 {
+				mon.Modes = append(mon.Modes, mode)
+			}
+		}
+		monitors = append(monitors, mon)
+	}
+	return monitors
+}
+
+// GetMonitor returns the index of the monitor where the window is
+func (w *GLFW) GetMonitor() int {
+
+	mon := w.currentMonitor()
+	for i, m := range glfw.GetMonitors() {
+		if m == mon {
+			return i
+		}
+	}
+	return 0
+}
+
+// SetFullscreen sets the full screen mode of the window on the monitor
+// with the specified index or, if invalid as -1, on the monitor where the
+// window is. The Exclusive mode changes the video mode of the monitor to
+// the specified mode, which must be one of its modes, or keeps its current
+// mode if nil.
+func (w *GLFW) SetFullscreen(mode FullscreenMode, monitor int, vmode *VideoMode) error {
+
+	monitors := glfw.GetMonitors()
+	if monitor < 0 || monitor >= len(monitors) {
+		monitor = w.GetMonitor()
+	}
+	if w.fullscreen == Windowed && mode != Windowed {
+		w.windowed.xpos, w.windowed.ypos = w.win.GetPos()
+		w.windowed.width, w.windowed.height = w.win.GetSize()
+	}
+	switch mode {
+	case Windowed:
+		if w.fullscreen != Windowed {
+			r := visibleRect(w.Monitors(), w.windowed)
+			w.win.SetMonitor(nil, r.xpos, r.ypos, r.width, r.height, 0)
+		}
+	case Borderless:
+		// The monitor must be left first to restore its video mode
+		if w.fullscreen == Exclusive {
+			w.win.SetMonitor(nil, w.windowed.xpos, w.windowed.ypos, w.windowed.width, w.windowed.height, 0)
+		}
+		// Uses the current video mode, which is not changed
+		m := monitors[monitor]
+		vm := m.GetVideoMode()
+		w.win.SetMonitor(m, 0, 0, vm.Width, vm.Height, vm.RefreshRate)
+	case Exclusive:
+		m := monitors[monitor]
+		w.fullMode = glfwVideoMode(m.GetVideoMode())
+		if vmode != nil {
+			mon := w.Monitors()[monitor]
+			if modeIndex(mon.Modes, *vmode) < 0 {
+				return fmt.Errorf("Unsupported video mode: %dx%d %dHz", vmode.Width, vmode.Height, vmode.RefreshRate)
+			}
+			w.fullMode = *vmode
+		}
+		w.win.SetMonitor(m, 0, 0, w.fullMode.Width, w.fullMode.Height, w.fullMode.RefreshRate)
+	default:
+		panic("Invalid full screen mode")
+	}
+	w.fullscreen = mode
+	w.updateScale()
+	return nil
+}
+
+// GetFullscreen returns the current full screen mode
+func (w *GLFW) GetFullscreen() FullscreenMode {
+
+	return w.fullscreen
+}
+
+// Geometry returns the current geometry of the window
+func (w *GLFW) Geometry() WindowGeometry {
+
+	g := WindowGeometry{Fullscreen: w.fullscreen, Monitor: w.GetMonitor(), Mode: w.fullMode}
+	if w.fullscreen == Windowed {
+		g.Xpos, g.Ypos = w.win.GetPos()
+		g.Width, g.Height = w.win.GetSize()
+	} else {
+		g.Xpos, g.Ypos = w.windowed.xpos, w.windowed.ypos
+		g.Width, g.Height = w.windowed.width, w.windowed.height
+	}
+	return g
+}
+
+// SetGeometry sets the geometry of the window, moving it into the
+// first monitor if it is not in the current monitors
+func (w *GLFW) SetGeometry(g WindowGeometry) error {
+
+	r := visibleRect(w.Monitors(), windowRect{g.Xpos, g.Ypos, g.Width, g.Height})
+	if w.fullscreen == Windowed {
+		w.win.SetPos(r.xpos, r.ypos)
+		w.win.SetSize(r.width, r.height)
+	}
+	w.windowed = r
+	var vmode *VideoMode
+	if g.Fullscreen == Exclusive {
+		vmode = &g.Mode
+	}
+	err := w.SetFullscreen(g.Fullscreen, g.Monitor, vmode)
+	w.windowed = r
+	return err
+}
+
+// KeyName returns the name of the specified printable key in the current
+// keyboard layout, as "a", or an empty string for the other keys
+func (w *GLFW) KeyName(key Key, scancode int) string {
+
+	return glfw.GetKeyName(glfw.Key(key), scancode)
+}
+
+func (w *GLFW) SetClipboardString(text string) {
+
+	w.win.SetClipboardString(text)
+}
+
+func (w *GLFW) GetClipboardString() (string, error) {
+
+	return w.win.GetClipboardString()
+}
+
+// GetScale returns the content scale of the monitor where the window is,
+// which is the ratio between its resolution and the standard 96 DPI.
+func (w *GLFW) GetScale() float32 {
+
+	return w.scale
+}
+
+// SetDecorated logs a warning as GLFW 3.2 only sets if the window has
+// border and title bar when it is created, from the window hints
+func (w *GLFW) SetDecorated(decorated bool) {
+
+	log.Warn("GLFW 3.2 can't change the window decorations after it is created")
+}
+
+// SetFloating logs a warning as GLFW 3.2 only sets if the window is always
+// on top of the other windows when it is created, from the window hints
+func (w *GLFW) SetFloating(floating bool) {
+
+	log.Warn("GLFW 3.2 can't change the window stacking after it is created")
+}
+
+// SetOpacity returns ErrOpacityUnsupported, unless the opacity is 1,
+// as GLFW 3.2 has no window opacity
+func (w *GLFW) SetOpacity(opacity float32) error {
+
+	if opacity >= 1 {
+		return nil
+	}
+	return ErrOpacityUnsupported
+}
+
+// GetOpacity returns the opacity of the window, which is always 1
+func (w *GLFW) GetOpacity() float32 {
+
+	return 1
+}
+
 func (w *GLFW) ShouldClose() bool {
 
 	return w.win.ShouldClose()
@@ -227,17 +630,182 @@ func (w *GLFW) SetShouldClose(v bool) {
 	w.win.SetShouldClose(v)
 }
 
+// SwapBuffers swaps the front and back buffers of the window and
+// waits for the next frame period if the frame rate is limited
 func (w *GLFW) SwapBuffers() {
 
 	w.win.SwapBuffers()
+	w.limiter.Wait()
 }
 
+// PollEvents processes the pending window events and polls
+// the state of the connected joysticks
 func (w *GLFW) PollEvents() {
 
 	glfw.PollEvents()
+	w.pollJoysticks()
+}
+
+// JoystickPresent returns if the specified joystick is connected
+func (w *GLFW) JoystickPresent(joy Joystick) bool {
+
+	return joy >= 0 && joy <= JoystickLast && w.joysticks[joy].present
+}
+
+// JoystickName returns the name of the specified joystick or
+// an empty string if it is not connected
+func (w *GLFW) JoystickName(joy Joystick) string {
+
+	if !w.JoystickPresent(joy) {
+		return ""
+	}
+	return w.joysticks[joy].name
+}
+
+// GamepadState returns the state of the standard gamepad buttons and
+// axes of the specified joystick at the last PollEvents and if it is connected
+func (w *GLFW) GamepadState(joy Joystick) (GamepadState, bool) {
+
+	if !w.JoystickPresent(joy) {
+		return GamepadState{}, false
+	}
+	return w.joysticks[joy].state, true
+}
+
+// SetGamepadDeadZone sets the dead zone of the gamepad sticks, as a radius,
+// and triggers, inside which their values are zero (default 0.15)
+func (w *GLFW) SetGamepadDeadZone(dz float32) {
+
+	w.deadZone = float32(math.Max(0, math.Min(float64(dz), 0.99)))
 }
 
 func (w *GLFW) GetTime() float64 {
 
 	return glfw.GetTime()
 }
+
+// Destroy destroys this window and its OpenGL context, which must not be
+// used anymore. The objects shared with the contexts of other windows
+// are kept while they exist.
+func (w *GLFW) Destroy() {
+
+	for i, win := range glfwWindows {
+		if win == w {
+			glfwWindows = append(glfwWindows[:i], glfwWindows[i+1:]...)
+			break
+		}
+	}
+	for _, c := range w.cursors {
+		c.Destroy()
+	}
+	w.win.Destroy()
+}
+
+// connectJoystick sets the specified joystick as connected
+func (w *GLFW) connectJoystick(joy Joystick) {
+
+	js := &w.joysticks[joy]
+	js.present = true
+	js.name = glfw.GetJoystickName(glfw.Joystick1 + glfw.Joystick(joy))
+	js.mapping = gamepadMappingFor(js.name)
+	js.state = GamepadState{}
+}
+
+// disconnectJoystick sets the specified joystick as disconnected, releasing
+// its pressed buttons, and dispatches the disconnection event
+func (w *GLFW) disconnectJoystick(joy Joystick) {
+
+	js := &w.joysticks[joy]
+	if !js.present {
+		return
+	}
+	prev := js.state
+	js.present = false
+	js.state = GamepadState{}
+	dispatchGamepad(w, joy, &prev, &js.state)
+	w.Dispatch(OnJoystickDisconnect, &JoystickEvent{W: w, Joy: joy, Name: js.name})
+}
+
+// pollJoysticks updates the state of the connected joysticks
+// and dispatches the events of their changes
+func (w *GLFW) pollJoysticks() {
+
+	for joy := range w.joysticks {
+		js := &w.joysticks[joy]
+		if !js.present {
+			continue
+		}
+		gjoy := glfw.Joystick1 + glfw.Joystick(joy)
+		prev := js.state
+		js.mapping.State(glfw.GetJoystickAxes(gjoy), glfw.GetJoystickButtons(gjoy), w.deadZone, &js.state)
+		dispatchGamepad(w, Joystick(joy), &prev, &js.state)
+	}
+}
+
+// updateScale updates the window content scale and dispatches
+// an OnScale event if it changed
+func (w *GLFW) updateScale() {
+
+	scale := w.monitorScale()
+	if scale == w.scale {
+		return
+	}
+	w.scale = scale
+	w.scaleEv.W = w
+	w.scaleEv.Scale = scale
+	w.Dispatch(OnScale, &w.scaleEv)
+}
+
+// currentMonitor returns the full screen monitor of the window or the
+// monitor which contains its center or the primary monitor
+func (w *GLFW) currentMonitor() *glfw.Monitor {
+
+	if mon := w.win.GetMonitor(); mon != nil {
+		return mon
+	}
+	xpos, ypos := w.win.GetPos()
+	width, height := w.win.GetSize()
+	cx := xpos + width/2
+	cy := ypos + height/2
+	for _, m := range glfw.GetMonitors() {
+		mx, my := m.GetPos()
+		vmode := m.GetVideoMode()
+		if cx >= mx && cx < mx+vmode.Width && cy >= my && cy < my+vmode.Height {
+			return m
+		}
+	}
+	return glfw.GetPrimaryMonitor()
+}
+
+// monitorScale returns the content scale of the monitor where the window is
+// rounded to quarters.
+// Monitors which don't report their physical size have scale 1.
+func (w *GLFW) monitorScale() float32 {
+
+	mon := w.currentMonitor()
+	if mon == nil {
+		return 1
+	}
+	mmWidth, _ := mon.GetPhysicalSize()
+	if mmWidth <= 0 {
+		return 1
+	}
+	dpi := float64(mon.GetVideoMode().Width) * 25.4 / float64(mmWidth)
+	scale := math.Floor(dpi/96*4+0.5) / 4
+	return float32(math.Max(1, math.Min(scale, 4)))
+}
+
+// glfwVideoMode returns the video mode of the specified GLFW video mode
+func glfwVideoMode(vm *glfw.VidMode) VideoMode {
+
+	return VideoMode{Width: vm.Width, Height: vm.Height, RefreshRate: vm.RefreshRate}
+}
+
+// glfwBool returns the GLFW hint value of the specified boolean
+func glfwBool(v bool) int {
+
+	if v {
+		return glfw.True
+	}
+	return glfw.False
+}
@@ -0,0 +1,323 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build egl
+// +build egl
+
+package window
+
+/*
+#cgo linux LDFLAGS: -lEGL
+
+#include <stdlib.h>
+#include <string.h>
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+
+#ifndef EGL_PLATFORM_SURFACELESS_MESA
+#define EGL_PLATFORM_SURFACELESS_MESA 0x31DD
+#endif
+
+typedef struct {
+	EGLDisplay dpy;
+	EGLConfig  config;
+	EGLContext ctx;
+	EGLSurface surf;
+} g3negl;
+
+// g3negl_display returns the display of the Mesa surfaceless platform, which
+// needs neither a display server nor a GPU, if available or the default display.
+static EGLDisplay g3negl_display() {
+
+	const char* exts = eglQueryString(EGL_NO_DISPLAY, EGL_EXTENSIONS);
+	PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+		(PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+	if (exts != NULL && strstr(exts, "EGL_MESA_platform_surfaceless") != NULL && getPlatformDisplay != NULL) {
+		EGLDisplay dpy = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+		if (dpy != EGL_NO_DISPLAY) {
+			return dpy;
+		}
+	}
+	return eglGetDisplay(EGL_DEFAULT_DISPLAY);
+}
+
+// g3negl_create initializes the display and creates an OpenGL 3.3 core or
+// OpenGL ES 3.0 context, sharing the objects of the specified context if not
+// EGL_NO_CONTEXT, and a pbuffer surface with the specified size, and makes
+// them current. Returns EGL_SUCCESS or the error code.
+static EGLint g3negl_create(g3negl* e, EGLContext share, int width, int height, int gles) {
+
+	memset(e, 0, sizeof(*e));
+	e->dpy = g3negl_display();
+	if (e->dpy == EGL_NO_DISPLAY) return EGL_BAD_DISPLAY;
+	if (!eglInitialize(e->dpy, NULL, NULL)) return eglGetError();
+
+	EGLint cfgAttribs[] = {
+		EGL_SURFACE_TYPE, EGL_PBUFFER_BIT,
+		EGL_RENDERABLE_TYPE, gles ? EGL_OPENGL_ES3_BIT : EGL_OPENGL_BIT,
+		EGL_RED_SIZE, 8, EGL_GREEN_SIZE, 8, EGL_BLUE_SIZE, 8, EGL_ALPHA_SIZE, 8,
+		EGL_DEPTH_SIZE, 24, EGL_STENCIL_SIZE, 8,
+		EGL_NONE
+	};
+	EGLint count = 0;
+	if (!eglChooseConfig(e->dpy, cfgAttribs, &e->config, 1, &count)) return eglGetError();
+	if (count < 1) return EGL_BAD_CONFIG;
+	if (!eglBindAPI(gles ? EGL_OPENGL_ES_API : EGL_OPENGL_API)) return eglGetError();
+
+	EGLint ctxAttribs[] = {
+		EGL_CONTEXT_MAJOR_VERSION, 3,
+		EGL_CONTEXT_MINOR_VERSION, gles ? 0 : 3,
+		gles ? EGL_NONE : EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT,
+		EGL_NONE
+	};
+	e->ctx = eglCreateContext(e->dpy, e->config, share, ctxAttribs);
+	if (e->ctx == EGL_NO_CONTEXT) return eglGetError();
+
+	EGLint surfAttribs[] = { EGL_WIDTH, width, EGL_HEIGHT, height, EGL_NONE };
+	e->surf = eglCreatePbufferSurface(e->dpy, e->config, surfAttribs);
+	if (e->surf == EGL_NO_SURFACE) return eglGetError();
+	if (!eglMakeCurrent(e->dpy, e->surf, e->surf, e->ctx)) return eglGetError();
+	return EGL_SUCCESS;
+}
+
+// g3negl_resize replaces the pbuffer surface by one with the specified size
+// and makes it current. Returns EGL_SUCCESS or the error code.
+static EGLint g3negl_resize(g3negl* e, int width, int height) {
+
+	EGLint surfAttribs[] = { EGL_WIDTH, width, EGL_HEIGHT, height, EGL_NONE };
+	EGLSurface surf = eglCreatePbufferSurface(e->dpy, e->config, surfAttribs);
+	if (surf == EGL_NO_SURFACE) return eglGetError();
+	if (!eglMakeCurrent(e->dpy, surf, surf, e->ctx)) {
+		EGLint err = eglGetError();
+		eglDestroySurface(e->dpy, surf);
+		return err;
+	}
+	eglDestroySurface(e->dpy, e->surf);
+	e->surf = surf;
+	return EGL_SUCCESS;
+}
+
+// g3negl_destroy releases the context and surface
+static void g3negl_destroy(g3negl* e) {
+
+	if (e->dpy == EGL_NO_DISPLAY) return;
+	if (eglGetCurrentContext() == e->ctx) {
+		eglMakeCurrent(e->dpy, EGL_NO_SURFACE, EGL_NO_SURFACE, EGL_NO_CONTEXT);
+	}
+	if (e->surf != EGL_NO_SURFACE) eglDestroySurface(e->dpy, e->surf);
+	if (e->ctx != EGL_NO_CONTEXT) eglDestroyContext(e->dpy, e->ctx);
+}
+
+// g3negl_make_current makes the context and surface current
+static EGLBoolean g3negl_make_current(g3negl* e) {
+
+	return eglMakeCurrent(e->dpy, e->surf, e->surf, e->ctx);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"github.com/g3n/engine/core"
+	"image"
+	"time"
+	"unsafe"
+)
+
+// Headless is a window with no visible window, whose OpenGL context is
+// created with EGL and renders into a pbuffer surface of the window size.
+// It needs no display server when the Mesa surfaceless platform is available.
+// The rendered images are read from the default framebuffer with
+// gls.ReadPixels. PollEvents does nothing but the application may dispatch
+// events to simulate input.
+type Headless struct {
+	core.Dispatcher              // Embedded event dispatcher
+	cx              *C.g3negl    // C state allocated in C memory
+	width           int          // Width of the pbuffer surface
+	height          int          // Height of the pbuffer surface
+	shouldClose     bool         // Close requested flag
+	clipboard       string       // Text of the clipboard of this window
+	clipboardImage  image.Image  // Image of the clipboard of this window
+	start           time.Time    // Creation time
+	sizeEv          SizeEvent    // Preallocated size event
+	limiter         FrameLimiter // Frame rate limiter applied by SwapBuffers
+}
+
+// newHeadless creates and returns a new headless window with the specified
+// size whose context shares the OpenGL objects of the specified headless
+// window if not nil
+func newHeadless(width, height int, share IWindow) (IWindow, error) {
+
+	shareCtx := C.EGLContext(C.EGL_NO_CONTEXT)
+	if share != nil {
+		shareCtx = share.(*Headless).cx.ctx
+	}
+	gles := C.int(0)
+	if eglGLES {
+		gles = 1
+	}
+	w := new(Headless)
+	w.Dispatcher.Initialize()
+	w.cx = (*C.g3negl)(C.malloc(C.size_t(unsafe.Sizeof(C.g3negl{}))))
+	res := C.g3negl_create(w.cx, shareCtx, C.int(width), C.int(height), gles)
+	if res != C.EGL_SUCCESS {
+		C.g3negl_destroy(w.cx)
+		C.free(unsafe.Pointer(w.cx))
+		return nil, fmt.Errorf("EGL headless context creation error: 0x%X", int(res))
+	}
+	w.width = width
+	w.height = height
+	w.start = time.Now()
+	return w, nil
+}
+
+// isHeadless returns if the specified window is a headless window
+func isHeadless(win IWindow) bool {
+
+	_, ok := win.(*Headless)
+	return ok
+}
+
+func (w *Headless) SwapInterval(interval int) {
+
+}
+
+// GetSwapInterval returns 0 as there is no monitor to synchronize with
+func (w *Headless) GetSwapInterval() int {
+
+	return 0
+}
+
+// SetFrameRateLimit sets the maximum frames per second, which SwapBuffers
+// keeps by waiting, or 0 for no limit (default)
+func (w *Headless) SetFrameRateLimit(fps float64) {
+
+	w.limiter.SetLimit(fps)
+}
+
+// GetFrameRateLimit returns the maximum frames per second or 0 for no limit
+func (w *Headless) GetFrameRateLimit() float64 {
+
+	return w.limiter.Limit()
+}
+
+func (w *Headless) MakeContextCurrent() {
+
+	C.g3negl_make_current(w.cx)
+}
+
+func (w *Headless) GetSize() (width int, height int) {
+
+	return w.width, w.height
+}
+
+// SetSize replaces the pbuffer surface by one with the specified size,
+// which becomes current, and dispatches an OnWindowSize event
+func (w *Headless) SetSize(width int, height int) {
+
+	res := C.g3negl_resize(w.cx, C.int(width), C.int(height))
+	if res != C.EGL_SUCCESS {
+		log.Error("EGL headless surface resize error: 0x%X", int(res))
+		return
+	}
+	w.width = width
+	w.height = height
+	w.sizeEv.W = w
+	w.sizeEv.Width = width
+	w.sizeEv.Height = height
+	w.Dispatch(OnWindowSize, &w.sizeEv)
+}
+
+func (w *Headless) GetPos() (xpos, ypos int) {
+
+	return 0, 0
+}
+
+func (w *Headless) SetPos(xpos, ypos int) {
+
+}
+
+func (w *Headless) SetTitle(title string) {
+
+}
+
+func (w *Headless) SetStandardCursor(cursor StandardCursor) {
+
+}
+
+// SetClipboardString sets the text of the clipboard of this window,
+// which is not shared with other applications
+func (w *Headless) SetClipboardString(text string) {
+
+	w.clipboard = text
+	w.clipboardImage = nil
+}
+
+func (w *Headless) GetClipboardString() (string, error) {
+
+	return w.clipboard, nil
+}
+
+// SetClipboardImage sets the image of the clipboard of this window,
+// which is not shared with other applications, and clears its text
+func (w *Headless) SetClipboardImage(img image.Image) error {
+
+	w.clipboard = ""
+	w.clipboardImage = img
+	return nil
+}
+
+// GetClipboardImage returns the image of the clipboard of this window
+func (w *Headless) GetClipboardImage() (image.Image, error) {
+
+	if w.clipboardImage == nil {
+		return nil, ErrClipboardNoImage
+	}
+	return w.clipboardImage, nil
+}
+
+// GetScale returns 1 as there is no monitor
+func (w *Headless) GetScale() float32 {
+
+	return 1
+}
+
+// SwapBuffers only waits for the next frame period if the frame rate is
+// limited as the pbuffer surface has only one buffer
+func (w *Headless) SwapBuffers() {
+
+	w.limiter.Wait()
+}
+
+func (w *Headless) ShouldClose() bool {
+
+	return w.shouldClose
+}
+
+func (w *Headless) SetShouldClose(v bool) {
+
+	w.shouldClose = v
+}
+
+// PollEvents does nothing as there are no input devices
+func (w *Headless) PollEvents() {
+
+}
+
+func (w *Headless) GetTime() float64 {
+
+	return time.Since(w.start).Seconds()
+}
+
+// Destroy destroys the OpenGL context and the pbuffer surface,
+// which must not be used anymore
+func (w *Headless) Destroy() {
+
+	if w.cx == nil {
+		return
+	}
+	C.g3negl_destroy(w.cx)
+	C.free(unsafe.Pointer(w.cx))
+	w.cx = nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !egl
+// +build !egl
+
+package window
+
+import (
+	"fmt"
+)
+
+// newHeadless returns an error as the engine was built without the "egl" build tag
+func newHeadless(width, height int, share IWindow) (IWindow, error) {
+
+	return nil, fmt.Errorf("headless window support not compiled: build with -tags egl")
+}
+
+// isHeadless returns false as there are no headless windows
+func isHeadless(win IWindow) bool {
+
+	return false
+}
@@ -0,0 +1,20 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !sdl && !gles3 && !js
+// +build !sdl,!gles3,!js
+
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// setContextHints sets the window hints to create an OpenGL 3.3 core profile context
+func setContextHints() {
+
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+}
@@ -0,0 +1,11 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build egl && !gles3
+// +build egl,!gles3
+
+package window
+
+// eglGLES is set when the headless windows create an OpenGL ES context
+const eglGLES = false
@@ -0,0 +1,20 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build sdl && !gles3
+// +build sdl,!gles3
+
+package window
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// setContextHints sets the attributes to create an OpenGL 3.3 core profile context
+func setContextHints() {
+
+	sdl.GLSetAttribute(sdl.GL_CONTEXT_MAJOR_VERSION, 3)
+	sdl.GLSetAttribute(sdl.GL_CONTEXT_MINOR_VERSION, 3)
+	sdl.GLSetAttribute(sdl.GL_CONTEXT_PROFILE_MASK, sdl.GL_CONTEXT_PROFILE_CORE)
+}
@@ -0,0 +1,41 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// IKeyName is the interface of the windows which return the names of the
+// printable keys in the current keyboard layout. The Keycode of the key
+// events identifies the physical key, named after its position in the US
+// layout, so on a French layout the key reported as KeyQ types an 'a'.
+type IKeyName interface {
+	KeyName(key Key, scancode int) string
+}
+
+// LayoutKey returns the letter or digit key which corresponds to the
+// character typed by the specified physical key in the current keyboard
+// layout of the specified window, or the specified key if the window doesn't
+// implement IKeyName or the key types neither a letter nor a digit.
+// It is used to match shortcuts, as Ctrl+Z, with the keys of the layout.
+func LayoutKey(win IWindow, key Key, scancode int) Key {
+
+	kn, ok := win.(IKeyName)
+	if !ok {
+		return key
+	}
+	name := kn.KeyName(key, scancode)
+	r, size := utf8.DecodeRuneInString(name)
+	if size == 0 || size != len(name) || r > unicode.MaxASCII {
+		return key
+	}
+	r = unicode.ToUpper(r)
+	if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+		return Key(r)
+	}
+	return key
+}
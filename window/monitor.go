@@ -0,0 +1,109 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+// IMonitor is the interface of the windows which can list the monitors and
+// their video modes and switch between the windowed and full screen modes
+// at runtime. The windowed position and size are remembered while the
+// window is full screen and restored when it returns to the windowed mode.
+type IMonitor interface {
+	Monitors() []Monitor
+	GetMonitor() int
+	SetFullscreen(mode FullscreenMode, monitor int, vmode *VideoMode) error
+	GetFullscreen() FullscreenMode
+	Geometry() WindowGeometry
+	SetGeometry(g WindowGeometry) error
+}
+
+// Monitor describes a monitor connected to the system
+type Monitor struct {
+	Name     string
+	Xpos     int         // horizontal position of the monitor in the desktop
+	Ypos     int         // vertical position of the monitor in the desktop
+	WidthMM  int         // physical width in millimeters or 0 if unknown
+	HeightMM int         // physical height in millimeters or 0 if unknown
+	Mode     VideoMode   // current video mode
+	Modes    []VideoMode // supported video modes
+}
+
+// VideoMode is a video mode of a monitor
+type VideoMode struct {
+	Width       int
+	Height      int
+	RefreshRate int // refresh rate in Hz
+}
+
+// FullscreenMode is the full screen mode of a window
+type FullscreenMode int
+
+// Full screen modes
+const (
+	Windowed   FullscreenMode = iota // Window with decorations in the desktop
+	Borderless                       // Window covering a monitor using its current video mode
+	Exclusive                        // Window covering a monitor whose video mode is changed
+)
+
+// WindowGeometry is the windowed position and size and the full screen mode
+// of a window, which may be saved to restore the window when the application
+// is started again
+type WindowGeometry struct {
+	Xpos       int            // horizontal position of the window when windowed
+	Ypos       int            // vertical position of the window when windowed
+	Width      int            // width of the window when windowed
+	Height     int            // height of the window when windowed
+	Fullscreen FullscreenMode // full screen mode
+	Monitor    int            // index of the monitor where the window is
+	Mode       VideoMode      // video mode of the Exclusive mode
+}
+
+// windowRect is the windowed position and size of a window
+type windowRect struct {
+	xpos, ypos    int
+	width, height int
+}
+
+// monitorAt returns the index of the monitor of the specified list which
+// contains the specified position or -1 if no monitor contains it
+func monitorAt(monitors []Monitor, x, y int) int {
+
+	for i, m := range monitors {
+		if x >= m.Xpos && x < m.Xpos+m.Mode.Width && y >= m.Ypos && y < m.Ypos+m.Mode.Height {
+			return i
+		}
+	}
+	return -1
+}
+
+// visibleRect returns the specified windowed position and size changed,
+// if the monitors were changed since it was saved, to keep the window
+// inside the first of the specified monitors
+func visibleRect(monitors []Monitor, r windowRect) windowRect {
+
+	if len(monitors) == 0 || monitorAt(monitors, r.xpos+r.width/2, r.ypos+r.height/2) >= 0 {
+		return r
+	}
+	m := monitors[0]
+	if r.width > m.Mode.Width {
+		r.width = m.Mode.Width
+	}
+	if r.height > m.Mode.Height {
+		r.height = m.Mode.Height
+	}
+	r.xpos = m.Xpos + (m.Mode.Width-r.width)/2
+	r.ypos = m.Ypos + (m.Mode.Height-r.height)/2
+	return r
+}
+
+// modeIndex returns the index of the specified video mode in the specified
+// list or -1 if not found
+func modeIndex(modes []VideoMode, vmode VideoMode) int {
+
+	for i, m := range modes {
+		if m == vmode {
+			return i
+		}
+	}
+	return -1
+}
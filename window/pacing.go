@@ -0,0 +1,83 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"runtime"
+	"time"
+)
+
+// IFramePacing is the interface of the windows which control the frame
+// rate. The swap interval set by SwapInterval synchronizes the buffer swaps
+// with the monitor refresh (vsync) and the frame rate limit, for when it is
+// off, makes SwapBuffers wait for the start of the next frame period.
+type IFramePacing interface {
+	GetSwapInterval() int
+	SetFrameRateLimit(fps float64)
+	GetFrameRateLimit() float64
+}
+
+// FrameLimiter limits the rate of the frames of a render loop
+// by waiting for the start of the next frame period
+type FrameLimiter struct {
+	fps    float64       // maximum frames per second or 0 for no limit
+	period time.Duration // duration of the frames
+	next   time.Time     // start of the next frame period
+}
+
+// frameSpin is the time before the start of the frame period which is
+// waited yielding the processor instead of sleeping, for precision
+const frameSpin = time.Millisecond
+
+// NewFrameLimiter creates and returns a pointer to a new frame limiter
+// with the specified maximum frames per second or 0 for no limit
+func NewFrameLimiter(fps float64) *FrameLimiter {
+
+	fl := new(FrameLimiter)
+	fl.SetLimit(fps)
+	return fl
+}
+
+// SetLimit sets the maximum frames per second or 0 for no limit
+func (fl *FrameLimiter) SetLimit(fps float64) {
+
+	if fps < 0 {
+		fps = 0
+	}
+	fl.fps = fps
+	fl.period = 0
+	if fps > 0 {
+		fl.period = time.Duration(float64(time.Second) / fps)
+	}
+	fl.next = time.Time{}
+}
+
+// Limit returns the maximum frames per second or 0 for no limit
+func (fl *FrameLimiter) Limit() float64 {
+
+	return fl.fps
+}
+
+// Wait waits for the start of the next frame period. The frames which took
+// longer than the period restart the periods instead of making the
+// following frames shorter.
+func (fl *FrameLimiter) Wait() {
+
+	if fl.period == 0 {
+		return
+	}
+	now := time.Now()
+	if fl.next.IsZero() || now.Sub(fl.next) > fl.period {
+		fl.next = now.Add(fl.period)
+		return
+	}
+	if wait := fl.next.Sub(now); wait > frameSpin {
+		time.Sleep(wait - frameSpin)
+	}
+	for time.Now().Before(fl.next) {
+		runtime.Gosched()
+	}
+	fl.next = fl.next.Add(fl.period)
+}
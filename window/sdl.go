@@ -0,0 +1,977 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build sdl
+// +build sdl
+
+package window
+
+import (
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/veandco/go-sdl2/sdl"
+	"image"
+	"image/draw"
+	"math"
+	"time"
+	"unicode"
+	"unsafe"
+)
+
+// Backend is the name of the window manager selected at build time
+const Backend = "sdl"
+
+// SDL is the window of the SDL2 window manager.
+// It dispatches touch events, with the mouse events which SDL synthesizes
+// from them ignored, and supports the on the spot IME composition.
+type SDL struct {
+	core.Dispatcher
+	win             *sdl.Window
+	ctx             sdl.GLContext
+	id              uint32 // window id of the events of this window
+	shouldClose     bool
+	iconified       bool
+	keyEv           KeyEvent
+	charEv          CharEvent
+	textEv          TextEvent
+	preeditEv       PreeditEvent
+	mouseEv         MouseEvent
+	posEv           PosEvent
+	sizeEv          SizeEvent
+	cursorEv        CursorEvent
+	scrollEv        ScrollEvent
+	scaleEv         ScaleEvent
+	touchEv         TouchEvent
+	scale           float32 // content scale of the window display
+	arrowCursor     *sdl.Cursor
+	ibeamCursor     *sdl.Cursor
+	crosshairCursor *sdl.Cursor
+	handCursor      *sdl.Cursor
+	hresizeCursor   *sdl.Cursor
+	vresizeCursor   *sdl.Cursor
+	cursors         map[CustomCursor]*sdl.Cursor // custom cursors
+	lastCursor      CustomCursor                 // identifier of the last custom cursor created
+	cursorMode      int                          // current cursor mode
+	motionEv        MouseMotionEvent
+	dropFiles       []string // files of the drop operation in progress
+	dropping        bool     // drop operation in progress
+	fullscreen      FullscreenMode
+	fullMode        VideoMode  // video mode of the Exclusive mode
+	windowed        windowRect // windowed position and size saved when full screen
+	swapInterval    int
+	limiter         FrameLimiter // frame rate limiter applied by SwapBuffers
+}
+
+// Global SDL initialization flag
+// is initialized when the first window is created
+var sdlInitialized bool = false
+
+// Time when SDL was initialized
+var sdlStart time.Time
+
+// Windows not destroyed by id, which receive the events
+var sdlWindows = map[uint32]*SDL{}
+
+// sdlKeys maps the SDL scancodes to the keys
+var sdlKeys = map[sdl.Scancode]Key{
+	sdl.SCANCODE_SPACE:          KeySpace,
+	sdl.SCANCODE_APOSTROPHE:     KeyApostrophe,
+	sdl.SCANCODE_COMMA:          KeyComma,
+	sdl.SCANCODE_MINUS:          KeyMinus,
+	sdl.SCANCODE_PERIOD:         KeyPeriod,
+	sdl.SCANCODE_SLASH:          KeySlash,
+	sdl.SCANCODE_0:              Key0,
+	sdl.SCANCODE_1:              Key1,
+	sdl.SCANCODE_2:              Key2,
+	sdl.SCANCODE_3:              Key3,
+	sdl.SCANCODE_4:              Key4,
+	sdl.SCANCODE_5:              Key5,
+	sdl.SCANCODE_6:              Key6,
+	sdl.SCANCODE_7:              Key7,
+	sdl.SCANCODE_8:              Key8,
+	sdl.SCANCODE_9:              Key9,
+	sdl.SCANCODE_SEMICOLON:      KeySemicolon,
+	sdl.SCANCODE_EQUALS:         KeyEqual,
+	sdl.SCANCODE_A:              KeyA,
+	sdl.SCANCODE_B:              KeyB,
+	sdl.SCANCODE_C:              KeyC,
+	sdl.SCANCODE_D:              KeyD,
+	sdl.SCANCODE_E:              KeyE,
+	sdl.SCANCODE_F:              KeyF,
+	sdl.SCANCODE_G:              KeyG,
+	sdl.SCANCODE_H:              KeyH,
+	sdl.SCANCODE_I:              KeyI,
+	sdl.SCANCODE_J:              KeyJ,
+	sdl.SCANCODE_K:              KeyK,
+	sdl.SCANCODE_L:              KeyL,
+	sdl.SCANCODE_M:              KeyM,
+	sdl.SCANCODE_N:              KeyN,
+	sdl.SCANCODE_O:              KeyO,
+	sdl.SCANCODE_P:              KeyP,
+	sdl.SCANCODE_Q:              KeyQ,
+	sdl.SCANCODE_R:              KeyR,
+	sdl.SCANCODE_S:              KeyS,
+	sdl.SCANCODE_T:              KeyT,
+	sdl.SCANCODE_U:              KeyU,
+	sdl.SCANCODE_V:              KeyV,
+	sdl.SCANCODE_W:              KeyW,
+	sdl.SCANCODE_X:              KeyX,
+	sdl.SCANCODE_Y:              KeyY,
+	sdl.SCANCODE_Z:              KeyZ,
+	sdl.SCANCODE_LEFTBRACKET:    KeyLeftBracket,
+	sdl.SCANCODE_BACKSLASH:      KeyBackslash,
+	sdl.SCANCODE_RIGHTBRACKET:   KeyRightBracket,
+	sdl.SCANCODE_GRAVE:          KeyGraveAccent,
+	sdl.SCANCODE_NONUSBACKSLASH: KeyWorld1,
+	sdl.SCANCODE_NONUSHASH:      KeyWorld2,
+	sdl.SCANCODE_ESCAPE:         KeyEscape,
+	sdl.SCANCODE_RETURN:         KeyEnter,
+	sdl.SCANCODE_TAB:            KeyTab,
+	sdl.SCANCODE_BACKSPACE:      KeyBackspace,
+	sdl.SCANCODE_INSERT:         KeyInsert,
+	sdl.SCANCODE_DELETE:         KeyDelete,
+	sdl.SCANCODE_RIGHT:          KeyRight,
+	sdl.SCANCODE_LEFT:           KeyLeft,
+	sdl.SCANCODE_DOWN:           KeyDown,
+	sdl.SCANCODE_UP:             KeyUp,
+	sdl.SCANCODE_PAGEUP:         KeyPageUp,
+	sdl.SCANCODE_PAGEDOWN:       KeyPageDown,
+	sdl.SCANCODE_HOME:           KeyHome,
+	sdl.SCANCODE_END:            KeyEnd,
+	sdl.SCANCODE_CAPSLOCK:       KeyCapsLock,
+	sdl.SCANCODE_SCROLLLOCK:     KeyScrollLock,
+	sdl.SCANCODE_NUMLOCKCLEAR:   KeyNumLock,
+	sdl.SCANCODE_PRINTSCREEN:    KeyPrintScreen,
+	sdl.SCANCODE_PAUSE:          KeyPause,
+	sdl.SCANCODE_F1:             KeyF1,
+	sdl.SCANCODE_F2:             KeyF2,
+	sdl.SCANCODE_F3:             KeyF3,
+	sdl.SCANCODE_F4:             KeyF4,
+	sdl.SCANCODE_F5:             KeyF5,
+	sdl.SCANCODE_F6:             KeyF6,
+	sdl.SCANCODE_F7:             KeyF7,
+	sdl.SCANCODE_F8:             KeyF8,
+	sdl.SCANCODE_F9:             KeyF9,
+	sdl.SCANCODE_F10:            KeyF10,
+	sdl.SCANCODE_F11:            KeyF11,
+	sdl.SCANCODE_F12:            KeyF12,
+	sdl.SCANCODE_F13:            KeyF13,
+	sdl.SCANCODE_F14:            KeyF14,
+	sdl.SCANCODE_F15:            KeyF15,
+	sdl.SCANCODE_F16:            KeyF16,
+	sdl.SCANCODE_F17:            KeyF17,
+	sdl.SCANCODE_F18:            KeyF18,
+	sdl.SCANCODE_F19:            KeyF19,
+	sdl.SCANCODE_F20:            KeyF20,
+	sdl.SCANCODE_F21:            KeyF21,
+	sdl.SCANCODE_F22:            KeyF22,
+	sdl.SCANCODE_F23:            KeyF23,
+	sdl.SCANCODE_F24:            KeyF24,
+	sdl.SCANCODE_KP_0:           KeyKP0,
+	sdl.SCANCODE_KP_1:           KeyKP1,
+	sdl.SCANCODE_KP_2:           KeyKP2,
+	sdl.SCANCODE_KP_3:           KeyKP3,
+	sdl.SCANCODE_KP_4:           KeyKP4,
+	sdl.SCANCODE_KP_5:           KeyKP5,
+	sdl.SCANCODE_KP_6:           KeyKP6,
+	sdl.SCANCODE_KP_7:           KeyKP7,
+	sdl.SCANCODE_KP_8:           KeyKP8,
+	sdl.SCANCODE_KP_9:           KeyKP9,
+	sdl.SCANCODE_KP_PERIOD:      KeyKPDecimal,
+	sdl.SCANCODE_KP_DIVIDE:      KeyKPDivide,
+	sdl.SCANCODE_KP_MULTIPLY:    KeyKPMultiply,
+	sdl.SCANCODE_KP_MINUS:       KeyKPSubtract,
+	sdl.SCANCODE_KP_PLUS:        KeyKPAdd,
+	sdl.SCANCODE_KP_ENTER:       KeyKPEnter,
+	sdl.SCANCODE_KP_EQUALS:      KeyKPEqual,
+	sdl.SCANCODE_LSHIFT:         KeyLeftShift,
+	sdl.SCANCODE_LCTRL:          KeyLeftControl,
+	sdl.SCANCODE_LALT:           KeyLeftAlt,
+	sdl.SCANCODE_LGUI:           KeyLeftSuper,
+	sdl.SCANCODE_RSHIFT:         KeyRightShift,
+	sdl.SCANCODE_RCTRL:          KeyRightControl,
+	sdl.SCANCODE_RALT:           KeyRightAlt,
+	sdl.SCANCODE_RGUI:           KeyRightSuper,
+	sdl.SCANCODE_APPLICATION:    KeyMenu,
+}
+
+// sdlButtons maps the SDL mouse buttons to the mouse buttons
+var sdlButtons = map[uint8]MouseButton{
+	sdl.BUTTON_LEFT:   MouseButtonLeft,
+	sdl.BUTTON_RIGHT:  MouseButtonRight,
+	sdl.BUTTON_MIDDLE: MouseButtonMiddle,
+	sdl.BUTTON_X1:     MouseButton4,
+	sdl.BUTTON_X2:     MouseButton5,
+}
+
+// newWindow creates and returns a new SDL window which
+// shares the OpenGL objects of the specified window if not nil
+func newWindow(width, height int, title string, full bool, share IWindow) (IWindow, error) {
+
+	var sw *SDL
+	if share != nil {
+		var ok bool
+		sw, ok = share.(*SDL)
+		if !ok {
+			panic("Unsupported window type")
+		}
+	}
+	w, err := newSDL(width, height, title, full, sw)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func newSDL(width, height int, title string, full bool, share *SDL) (*SDL, error) {
+
+	// Initialize SDL once before the first window is created
+	if !sdlInitialized {
+		err := sdl.Init(sdl.INIT_VIDEO)
+		if err != nil {
+			return nil, err
+		}
+		// Sets context attributes
+		setContextHints()
+		sdl.GLSetAttribute(sdl.GL_DOUBLEBUFFER, 1)
+		sdl.GLSetAttribute(sdl.GL_DEPTH_SIZE, 24)
+		sdl.GLSetAttribute(sdl.GL_STENCIL_SIZE, 8)
+		sdl.GLSetAttribute(sdl.GL_MULTISAMPLEBUFFERS, 1)
+		sdl.GLSetAttribute(sdl.GL_MULTISAMPLESAMPLES, 8)
+		sdl.StartTextInput()
+		sdlStart = time.Now()
+		sdlInitialized = true
+	}
+
+	// Creates window, using the desktop resolution if full screen requested
+	var flags uint32 = sdl.WINDOW_OPENGL | sdl.WINDOW_RESIZABLE | sdl.WINDOW_SHOWN
+	if full {
+		flags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	if hints.Undecorated {
+		flags |= sdl.WINDOW_BORDERLESS
+	}
+	if hints.Floating {
+		flags |= sdl.WINDOW_ALWAYS_ON_TOP
+	}
+	if hints.Transparent {
+		log.Warn("SDL2 has no transparent framebuffer: the window is opaque, use SetOpacity")
+	}
+	win, err := sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, int32(width), int32(height), flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creates the OpenGL context, sharing the objects of the context of the
+	// specified window, and sets it as the current context
+	if share != nil {
+		share.MakeContextCurrent()
+		sdl.GLSetAttribute(sdl.GL_SHARE_WITH_CURRENT_CONTEXT, 1)
+	}
+	ctx, err := win.GLCreateContext()
+	sdl.GLSetAttribute(sdl.GL_SHARE_WITH_CURRENT_CONTEXT, 0)
+	if err != nil {
+		win.Destroy()
+		return nil, err
+	}
+	win.GLMakeCurrent(ctx)
+	id, err := win.GetID()
+	if err != nil {
+		sdl.GLDeleteContext(ctx)
+		win.Destroy()
+		return nil, err
+	}
+
+	// Create wrapper window with dispacher
+	w := new(SDL)
+	w.win = win
+	w.ctx = ctx
+	w.id = id
+	w.Dispatcher.Initialize()
+	w.scale = w.displayScale()
+	if full {
+		// The window returns to a centered window when windowed
+		width, height := w.GetSize()
+		bounds, _ := sdl.GetDisplayBounds(w.GetMonitor())
+		w.fullscreen = Borderless
+		w.windowed = windowRect{int(bounds.X) + width/8, int(bounds.Y) + height/8, width * 3 / 4, height * 3 / 4}
+	}
+	sdlWindows[id] = w
+
+	// Preallocate standard cursors
+	w.arrowCursor = sdl.CreateSystemCursor(sdl.SYSTEM_CURSOR_ARROW)
+	w.ibeamCursor = sdl.CreateSystemCursor(sdl.SYSTEM_CURSOR_IBEAM)
+	w.crosshairCursor = sdl.CreateSystemCursor(sdl.SYSTEM_CURSOR_CROSSHAIR)
+	w.handCursor = sdl.CreateSystemCursor(sdl.SYSTEM_CURSOR_HAND)
+	w.hresizeCursor = sdl.CreateSystemCursor(sdl.SYSTEM_CURSOR_SIZEWE)
+	w.vresizeCursor = sdl.CreateSystemCursor(sdl.SYSTEM_CURSOR_SIZENS)
+	w.cursors = make(map[CustomCursor]*sdl.Cursor)
+	w.cursorMode = CursorNormal
+
+	return w, nil
+}
+
+// SwapInterval sets the number of monitor refreshes to wait for before
+// swapping the buffers of the current context: 0 disables the vertical
+// synchronization (vsync) and 1 enables it
+func (w *SDL) SwapInterval(interval int) {
+
+	sdl.GLSetSwapInterval(interval)
+	w.swapInterval = interval
+}
+
+// GetSwapInterval returns the swap interval set by SwapInterval
+func (w *SDL) GetSwapInterval() int {
+
+	return w.swapInterval
+}
+
+// SetFrameRateLimit sets the maximum frames per second, which SwapBuffers
+// keeps by waiting, or 0 for no limit (default)
+func (w *SDL) SetFrameRateLimit(fps float64) {
+
+	w.limiter.SetLimit(fps)
+}
+
+// GetFrameRateLimit returns the maximum frames per second or 0 for no limit
+func (w *SDL) GetFrameRateLimit() float64 {
+
+	return w.limiter.Limit()
+}
+
+func (w *SDL) MakeContextCurrent() {
+
+	w.win.GLMakeCurrent(w.ctx)
+}
+
+func (w *SDL) GetSize() (width int, height int) {
+
+	wi, he := w.win.GetSize()
+	return int(wi), int(he)
+}
+
+func (w *SDL) SetSize(width int, height int) {
+
+	w.win.SetSize(int32(width), int32(height))
+}
+
+func (w *SDL) GetPos() (xpos, ypos int) {
+
+	x, y := w.win.GetPosition()
+	return int(x), int(y)
+}
+
+func (w *SDL) SetPos(xpos, ypos int) {
+
+	w.win.SetPosition(int32(xpos), int32(ypos))
+}
+
+func (w *SDL) SetTitle(title string) {
+
+	w.win.SetTitle(title)
+}
+
+// SetStandardCursor sets the cursor of all SDL windows, as SDL has
+// only one cursor, and must be called when the cursor is over this window
+func (w *SDL) SetStandardCursor(cursor StandardCursor) {
+
+	switch cursor {
+	case ArrowCursor:
+		sdl.SetCursor(w.arrowCursor)
+	case IBeamCursor:
+		sdl.SetCursor(w.ibeamCursor)
+	case CrosshairCursor:
+		sdl.SetCursor(w.crosshairCursor)
+	case HandCursor:
+		sdl.SetCursor(w.handCursor)
+	case HResizeCursor:
+		sdl.SetCursor(w.hresizeCursor)
+	case VResizeCursor:
+		sdl.SetCursor(w.vresizeCursor)
+	default:
+		panic("Invalid cursor")
+	}
+}
+
+// CreateCursor creates a custom cursor from the specified image, whose
+// hot spot, the point of the cursor position, is at the specified position
+// from the top left corner of the image
+func (w *SDL) CreateCursor(img image.Image, xhot, yhot int) (CustomCursor, error) {
+
+	// Converts the image to non premultiplied RGBA pixels
+	bounds := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+	if len(nrgba.Pix) == 0 {
+		return 0, fmt.Errorf("Empty cursor image")
+	}
+	// The ABGR8888 format has the RGBA byte order in little endian machines
+	surface, err := sdl.CreateRGBSurfaceWithFormatFrom(unsafe.Pointer(&nrgba.Pix[0]),
+		int32(bounds.Dx()), int32(bounds.Dy()), 32, int32(nrgba.Stride), sdl.PIXELFORMAT_ABGR8888)
+	if err != nil {
+		return 0, err
+	}
+	defer surface.Free()
+	c := sdl.CreateColorCursor(surface, int32(xhot), int32(yhot))
+	if c == nil {
+		return 0, fmt.Errorf("Error creating cursor")
+	}
+	w.lastCursor++
+	w.cursors[w.lastCursor] = c
+	return w.lastCursor, nil
+}
+
+// SetCustomCursor sets the cursor of all SDL windows to the specified custom cursor
+func (w *SDL) SetCustomCursor(cursor CustomCursor) {
+
+	c, ok := w.cursors[cursor]
+	if !ok {
+		panic("Invalid cursor")
+	}
+	sdl.SetCursor(c)
+}
+
+// DisposeCursor destroys the specified custom cursor,
+// which must not be the current cursor
+func (w *SDL) DisposeCursor(cursor CustomCursor) {
+
+	c, ok := w.cursors[cursor]
+	if !ok {
+		return
+	}
+	sdl.FreeCursor(c)
+	delete(w.cursors, cursor)
+}
+
+// SetCursorMode sets the cursor mode to CursorNormal, CursorHidden or
+// CursorDisabled, which uses the SDL relative mouse mode
+func (w *SDL) SetCursorMode(mode int) {
+
+	switch mode {
+	case CursorNormal:
+		sdl.SetRelativeMouseMode(false)
+		sdl.ShowCursor(sdl.ENABLE)
+	case CursorHidden:
+		sdl.SetRelativeMouseMode(false)
+		sdl.ShowCursor(sdl.DISABLE)
+	case CursorDisabled:
+		sdl.SetRelativeMouseMode(true)
+	default:
+		panic("Invalid cursor mode")
+	}
+	w.cursorMode = mode
+}
+
+// GetCursorMode returns the current cursor mode
+func (w *SDL) GetCursorMode() int {
+
+	return w.cursorMode
+}
+
+// Monitors returns the displays connected to the system
+func (w *SDL) Monitors() []Monitor {
+
+	var monitors []Monitor
+	count, _ := sdl.GetNumVideoDisplays()
+	for i := 0; i < count; i++ {
+		var mon Monitor
+		mon.Name, _ = sdl.GetDisplayName(i)
+		bounds, _ := sdl.GetDisplayBounds(i)
+		mon.Xpos, mon.Ypos = int(bounds.X), int(bounds.Y)
+		dm, _ := sdl.GetDesktopDisplayMode(i)
+		mon.Mode = sdlVideoMode(&dm)
+		// SDL reports the resolution instead of the physical size
+		_, hdpi, vdpi, err := sdl.GetDisplayDPI(i)
+		if err == nil && hdpi > 0 && vdpi > 0 {
+			mon.WidthMM = int(float32(mon.Mode.Width) / hdpi * 25.4)
+			mon.HeightMM = int(float32(mon.Mode.Height) / vdpi * 25.4)
+		}
+		// The modes which differ only by the pixel format are the same mode
+		nmodes, _ := sdl.GetNumDisplayModes(i)
+		for j := 0; j < nmodes; j++ {
+			dm, err := sdl.GetDisplayMode(i, j)
+			if err != nil {
+				continue
+			}
+			mode := sdlVideoMode(&dm)
+			if modeIndex(mon.Modes, mode) < 0 {
+				mon.Modes = append(mon.Modes, mode)
+			}
+		}
+		monitors = append(monitors, mon)
+	}
+	return monitors
+}
+
+// GetMonitor returns the index of the display where the window is
+func (w *SDL) GetMonitor() int {
+
+	idx, err := w.win.GetDisplayIndex()
+	if err != nil {
+		return 0
+	}
+	return idx
+}
+
+// SetFullscreen sets the full screen mode of the window on the display
+// with the specified index or, if invalid as -1, on the display where the
+// window is. The Exclusive mode changes the video mode of the display to
+// the specified mode, which must be one of its modes, or keeps its current
+// mode if nil.
+func (w *SDL) SetFullscreen(mode FullscreenMode, monitor int, vmode *VideoMode) error {
+
+	monitors := w.Monitors()
+	if monitor < 0 || monitor >= len(monitors) {
+		monitor = w.GetMonitor()
+	}
+	if w.fullscreen == Windowed && mode != Windowed {
+		w.windowed.xpos, w.windowed.ypos = w.GetPos()
+		w.windowed.width, w.windowed.height = w.GetSize()
+	}
+	// The window leaves the full screen mode before being moved
+	if w.fullscreen != Windowed {
+		w.win.SetFullscreen(0)
+	}
+	switch mode {
+	case Windowed:
+		r := visibleRect(monitors, w.windowed)
+		w.win.SetPosition(int32(r.xpos), int32(r.ypos))
+		w.win.SetSize(int32(r.width), int32(r.height))
+	case Borderless:
+		m := monitors[monitor]
+		w.win.SetPosition(int32(m.Xpos), int32(m.Ypos))
+		w.win.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
+	case Exclusive:
+		m := monitors[monitor]
+		w.fullMode = m.Mode
+		if vmode != nil {
+			if modeIndex(m.Modes, *vmode) < 0 {
+				return fmt.Errorf("Unsupported video mode: %dx%d %dHz", vmode.Width, vmode.Height, vmode.RefreshRate)
+			}
+			w.fullMode = *vmode
+		}
+		w.win.SetPosition(int32(m.Xpos), int32(m.Ypos))
+		dm := sdl.DisplayMode{W: int32(w.fullMode.Width), H: int32(w.fullMode.Height), RefreshRate: int32(w.fullMode.RefreshRate)}
+		w.win.SetDisplayMode(&dm)
+		w.win.SetFullscreen(sdl.WINDOW_FULLSCREEN)
+	default:
+		panic("Invalid full screen mode")
+	}
+	w.fullscreen = mode
+	w.updateScale()
+	return nil
+}
+
+// GetFullscreen returns the current full screen mode
+func (w *SDL) GetFullscreen() FullscreenMode {
+
+	return w.fullscreen
+}
+
+// Geometry returns the current geometry of the window
+func (w *SDL) Geometry() WindowGeometry {
+
+	g := WindowGeometry{Fullscreen: w.fullscreen, Monitor: w.GetMonitor(), Mode: w.fullMode}
+	if w.fullscreen == Windowed {
+		g.Xpos, g.Ypos = w.GetPos()
+		g.Width, g.Height = w.GetSize()
+	} else {
+		g.Xpos, g.Ypos = w.windowed.xpos, w.windowed.ypos
+		g.Width, g.Height = w.windowed.width, w.windowed.height
+	}
+	return g
+}
+
+// SetGeometry sets the geometry of the window, moving it into the
+// first display if it is not in the current displays
+func (w *SDL) SetGeometry(g WindowGeometry) error {
+
+	r := visibleRect(w.Monitors(), windowRect{g.Xpos, g.Ypos, g.Width, g.Height})
+	if w.fullscreen == Windowed {
+		w.SetPos(r.xpos, r.ypos)
+		w.SetSize(r.width, r.height)
+	}
+	w.windowed = r
+	var vmode *VideoMode
+	if g.Fullscreen == Exclusive {
+		vmode = &g.Mode
+	}
+	err := w.SetFullscreen(g.Fullscreen, g.Monitor, vmode)
+	w.windowed = r
+	return err
+}
+
+// KeyName returns the name of the specified printable key in the current
+// keyboard layout, as "a", or an empty string for the other keys
+func (w *SDL) KeyName(key Key, scancode int) string {
+
+	// The keycodes of the printable keys are their characters while the
+	// others are outside of the unicode range
+	kc := sdl.GetKeyFromScancode(sdl.Scancode(scancode))
+	if kc == ' ' || !unicode.IsPrint(rune(kc)) {
+		return ""
+	}
+	return string(rune(kc))
+}
+
+func (w *SDL) SetClipboardString(text string) {
+
+	sdl.SetClipboardText(text)
+}
+
+func (w *SDL) GetClipboardString() (string, error) {
+
+	return sdl.GetClipboardText()
+}
+
+// SetDecorated sets if the window has border and title bar
+func (w *SDL) SetDecorated(decorated bool) {
+
+	w.win.SetBordered(decorated)
+}
+
+// SetFloating sets if the window is always on top of the other windows
+func (w *SDL) SetFloating(floating bool) {
+
+	w.win.SetAlwaysOnTop(floating)
+}
+
+// SetOpacity sets the opacity of the window from 0 (transparent) to 1 (opaque)
+func (w *SDL) SetOpacity(opacity float32) error {
+
+	return w.win.SetWindowOpacity(opacity)
+}
+
+// GetOpacity returns the opacity of the window, which is 1 if not supported
+func (w *SDL) GetOpacity() float32 {
+
+	opacity, err := w.win.GetWindowOpacity()
+	if err != nil {
+		return 1
+	}
+	return opacity
+}
+
+// SetIMECursorRect informs the position in window pixels of the text
+// cursor, near which the IME shows its candidates window
+func (w *SDL) SetIMECursorRect(x, y, width, height int) {
+
+	sdl.SetTextInputRect(&sdl.Rect{X: int32(x), Y: int32(y), W: int32(width), H: int32(height)})
+}
+
+// GetScale returns the content scale of the display where the window is,
+// which is the ratio between its resolution and the standard 96 DPI.
+func (w *SDL) GetScale() float32 {
+
+	return w.scale
+}
+
+func (w *SDL) ShouldClose() bool {
+
+	return w.shouldClose
+}
+
+func (w *SDL) SetShouldClose(v bool) {
+
+	w.shouldClose = v
+}
+
+// SwapBuffers swaps the front and back buffers of the window and
+// waits for the next frame period if the frame rate is limited
+func (w *SDL) SwapBuffers() {
+
+	w.win.GLSwap()
+	w.limiter.Wait()
+}
+
+// PollEvents processes the pending events of all SDL windows, as SDL has
+// only one event queue, and dispatches them to the windows where they occurred
+func (w *SDL) PollEvents() {
+
+	for ev := sdl.PollEvent(); ev != nil; ev = sdl.PollEvent() {
+		switch e := ev.(type) {
+		case *sdl.QuitEvent:
+			for _, w := range sdlWindows {
+				w.requestClose()
+			}
+		case *sdl.WindowEvent:
+			if w := sdlWindows[e.WindowID]; w != nil {
+				w.onWindow(e)
+			}
+		case *sdl.KeyboardEvent:
+			if w := sdlWindows[e.WindowID]; w != nil {
+				w.onKey(e)
+			}
+		case *sdl.TextInputEvent:
+			if w := sdlWindows[e.WindowID]; w != nil {
+				w.charEv.W = w
+				w.charEv.Mods = sdlMods(uint16(sdl.GetModState()))
+				for _, char := range e.GetText() {
+					w.charEv.Char = char
+					w.Dispatch(OnChar, &w.charEv)
+				}
+				w.textEv.W = w
+				w.textEv.Text = e.GetText()
+				w.Dispatch(OnText, &w.textEv)
+			}
+		case *sdl.TextEditingEvent:
+			if w := sdlWindows[e.WindowID]; w != nil {
+				w.preeditEv.W = w
+				w.preeditEv.Text = e.GetText()
+				w.preeditEv.Caret = int(e.Start)
+				w.Dispatch(OnPreedit, &w.preeditEv)
+			}
+		case *sdl.MouseMotionEvent:
+			if w := sdlWindows[e.WindowID]; w != nil && e.Which != sdl.TOUCH_MOUSEID {
+				w.cursorEv.W = w
+				w.cursorEv.Xpos = float32(e.X)
+				w.cursorEv.Ypos = float32(e.Y)
+				w.Dispatch(OnCursor, &w.cursorEv)
+				w.motionEv.W = w
+				w.motionEv.DeltaX = float32(e.XRel)
+				w.motionEv.DeltaY = float32(e.YRel)
+				w.Dispatch(OnMouseMotion, &w.motionEv)
+			}
+		case *sdl.MouseButtonEvent:
+			if w := sdlWindows[e.WindowID]; w != nil && e.Which != sdl.TOUCH_MOUSEID {
+				w.onMouse(e)
+			}
+		case *sdl.MouseWheelEvent:
+			if w := sdlWindows[e.WindowID]; w != nil && e.Which != sdl.TOUCH_MOUSEID {
+				w.scrollEv.W = w
+				w.scrollEv.Xoffset = float32(e.X)
+				w.scrollEv.Yoffset = float32(e.Y)
+				if e.Direction == sdl.MOUSEWHEEL_FLIPPED {
+					w.scrollEv.Xoffset = -w.scrollEv.Xoffset
+					w.scrollEv.Yoffset = -w.scrollEv.Yoffset
+				}
+				w.Dispatch(OnScroll, &w.scrollEv)
+			}
+		case *sdl.DropEvent:
+			if w := sdlWindows[e.WindowID]; w != nil {
+				w.onDrop(e)
+			}
+		case *sdl.TouchFingerEvent:
+			// The touch events are dispatched to the window with the keyboard focus
+			for _, w := range sdlWindows {
+				if w.win == sdl.GetKeyboardFocus() {
+					w.onTouch(e)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (w *SDL) GetTime() float64 {
+
+	return time.Since(sdlStart).Seconds()
+}
+
+// Destroy destroys this window and its OpenGL context, which must not be
+// used anymore. The objects shared with the contexts of other windows
+// are kept while they exist.
+func (w *SDL) Destroy() {
+
+	delete(sdlWindows, w.id)
+	for _, c := range w.cursors {
+		sdl.FreeCursor(c)
+	}
+	sdl.GLDeleteContext(w.ctx)
+	w.win.Destroy()
+}
+
+// onWindow dispatches the events of the specified window event
+func (w *SDL) onWindow(e *sdl.WindowEvent) {
+
+	switch e.Event {
+	case sdl.WINDOWEVENT_CLOSE:
+		w.requestClose()
+	case sdl.WINDOWEVENT_FOCUS_GAINED, sdl.WINDOWEVENT_FOCUS_LOST:
+		w.Dispatch(OnWindowFocus, &FocusEvent{W: w, Focused: e.Event == sdl.WINDOWEVENT_FOCUS_GAINED})
+	case sdl.WINDOWEVENT_MINIMIZED:
+		w.iconified = true
+		w.Dispatch(OnWindowIconify, &IconifyEvent{W: w, Iconified: true})
+	case sdl.WINDOWEVENT_RESTORED, sdl.WINDOWEVENT_MAXIMIZED:
+		if w.iconified {
+			w.iconified = false
+			w.Dispatch(OnWindowIconify, &IconifyEvent{W: w, Iconified: false})
+		}
+	case sdl.WINDOWEVENT_SIZE_CHANGED:
+		w.sizeEv.W = w
+		w.sizeEv.Width = int(e.Data1)
+		w.sizeEv.Height = int(e.Data2)
+		w.Dispatch(OnWindowSize, &w.sizeEv)
+		w.updateScale()
+	case sdl.WINDOWEVENT_MOVED:
+		w.posEv.W = w
+		w.posEv.Xpos = int(e.Data1)
+		w.posEv.Ypos = int(e.Data2)
+		w.Dispatch(OnWindowPos, &w.posEv)
+		w.updateScale()
+	}
+}
+
+// requestClose dispatches the close request event and sets
+// the window to be closed if it was not vetoed
+func (w *SDL) requestClose() {
+
+	if w.shouldClose {
+		return
+	}
+	if !w.Dispatch(OnWindowClose, &CloseEvent{W: w}) {
+		w.shouldClose = true
+	}
+}
+
+// onKey dispatches the events of the specified keyboard event.
+// The keys with no corresponding key are dispatched as KeyUnknown
+// with their scancode.
+func (w *SDL) onKey(e *sdl.KeyboardEvent) {
+
+	key, ok := sdlKeys[e.Keysym.Scancode]
+	if !ok {
+		key = KeyUnknown
+	}
+	w.keyEv.W = w
+	w.keyEv.Keycode = key
+	w.keyEv.Scancode = int(e.Keysym.Scancode)
+	w.keyEv.Mods = sdlMods(e.Keysym.Mod)
+	// The key repeats are not dispatched, as in the GLFW window
+	if e.Repeat != 0 {
+		return
+	}
+	if e.Type == sdl.KEYDOWN {
+		w.keyEv.Action = Press
+		w.Dispatch(OnKeyDown, &w.keyEv)
+		return
+	}
+	w.keyEv.Action = Release
+	w.Dispatch(OnKeyUp, &w.keyEv)
+}
+
+// onMouse dispatches the events of the specified mouse button event
+func (w *SDL) onMouse(e *sdl.MouseButtonEvent) {
+
+	button, ok := sdlButtons[e.Button]
+	if !ok {
+		return
+	}
+	w.mouseEv.W = w
+	w.mouseEv.Button = button
+	w.mouseEv.Mods = sdlMods(uint16(sdl.GetModState()))
+	w.mouseEv.Xpos = float32(e.X)
+	w.mouseEv.Ypos = float32(e.Y)
+	if e.Type == sdl.MOUSEBUTTONDOWN {
+		w.mouseEv.Action = Press
+		w.Dispatch(OnMouseDown, &w.mouseEv)
+		return
+	}
+	w.mouseEv.Action = Release
+	w.Dispatch(OnMouseUp, &w.mouseEv)
+}
+
+// onDrop collects the files of the specified drop event and dispatches
+// them when the drop operation is complete. SDL versions which don't report
+// the drop operations have the files dispatched as they are dropped.
+func (w *SDL) onDrop(e *sdl.DropEvent) {
+
+	switch e.Type {
+	case sdl.DROPBEGIN:
+		w.dropping = true
+		w.dropFiles = nil
+		return
+	case sdl.DROPFILE:
+		w.dropFiles = append(w.dropFiles, e.File)
+		if w.dropping {
+			return
+		}
+	case sdl.DROPCOMPLETE:
+		w.dropping = false
+	default:
+		return
+	}
+	if len(w.dropFiles) == 0 {
+		return
+	}
+	x, y, _ := sdl.GetMouseState()
+	files := w.dropFiles
+	w.dropFiles = nil
+	w.Dispatch(OnFileDrop, &FileDropEvent{W: w, Xpos: float32(x), Ypos: float32(y), Files: files})
+}
+
+// onTouch dispatches the events of the specified touch event,
+// whose position is normalized to the window size
+func (w *SDL) onTouch(e *sdl.TouchFingerEvent) {
+
+	width, height := w.GetSize()
+	w.touchEv.W = w
+	w.touchEv.ID = int(e.FingerID)
+	w.touchEv.Xpos = e.X * float32(width)
+	w.touchEv.Ypos = e.Y * float32(height)
+	switch e.Type {
+	case sdl.FINGERDOWN:
+		w.Dispatch(OnTouchDown, &w.touchEv)
+	case sdl.FINGERMOTION:
+		w.Dispatch(OnTouchMove, &w.touchEv)
+	case sdl.FINGERUP:
+		w.Dispatch(OnTouchUp, &w.touchEv)
+	}
+}
+
+// updateScale updates the window content scale and dispatches
+// an OnScale event if it changed
+func (w *SDL) updateScale() {
+
+	scale := w.displayScale()
+	if scale == w.scale {
+		return
+	}
+	w.scale = scale
+	w.scaleEv.W = w
+	w.scaleEv.Scale = scale
+	w.Dispatch(OnScale, &w.scaleEv)
+}
+
+// displayScale returns the content scale of the display
+// where the window is rounded to quarters.
+// Displays which don't report their resolution have scale 1.
+func (w *SDL) displayScale() float32 {
+
+	idx, err := w.win.GetDisplayIndex()
+	if err != nil {
+		return 1
+	}
+	_, hdpi, _, err := sdl.GetDisplayDPI(idx)
+	if err != nil || hdpi <= 0 {
+		return 1
+	}
+	scale := math.Floor(float64(hdpi)/96*4+0.5) / 4
+	return float32(math.Max(1, math.Min(scale, 4)))
+}
+
+// sdlVideoMode returns the video mode of the specified SDL display mode
+func sdlVideoMode(dm *sdl.DisplayMode) VideoMode {
+
+	return VideoMode{Width: int(dm.W), Height: int(dm.H), RefreshRate: int(dm.RefreshRate)}
+}
+
+// sdlMods returns the modifier keys of the specified SDL key modifiers
+func sdlMods(mod uint16) ModifierKey {
+
+	var mods ModifierKey
+	if mod&sdl.KMOD_SHIFT != 0 {
+		mods |= ModShift
+	}
+	if mod&sdl.KMOD_CTRL != 0 {
+		mods |= ModControl
+	}
+	if mod&sdl.KMOD_ALT != 0 {
+		mods |= ModAlt
+	}
+	if mod&sdl.KMOD_GUI != 0 {
+		mods |= ModSuper
+	}
+	return mods
+}
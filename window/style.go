@@ -0,0 +1,48 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"errors"
+)
+
+// WindowHints are the options of the windows created by New and NewShared,
+// which enable overlays and splash screens built with the engine.
+// The zero value is a decorated window which is not always on top.
+type WindowHints struct {
+	Undecorated bool // window without border and title bar (borderless)
+	Floating    bool // window always on top of the other windows
+	Transparent bool // window framebuffer whose alpha is blended with the desktop
+}
+
+// ErrOpacityUnsupported is returned by SetOpacity when the window manager
+// can't change the opacity of the window
+var ErrOpacityUnsupported = errors.New("window opacity not supported")
+
+// IWindowStyle is the interface of the windows whose decorations, stacking
+// and opacity are changed at runtime. GLFW 3.2 only sets the decorations and
+// stacking when the window is created and has no window opacity, so the GLFW
+// window logs a warning for the changes it can't make and its SetOpacity
+// returns ErrOpacityUnsupported.
+type IWindowStyle interface {
+	SetDecorated(decorated bool)
+	SetFloating(floating bool)
+	SetOpacity(opacity float32) error
+	GetOpacity() float32
+}
+
+var hints WindowHints // hints of the windows created next
+
+// SetWindowHints sets the options of the windows created afterwards
+func SetWindowHints(h WindowHints) {
+
+	hints = h
+}
+
+// GetWindowHints returns the options of the windows created afterwards
+func GetWindowHints() WindowHints {
+
+	return hints
+}
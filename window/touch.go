@@ -0,0 +1,245 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"math"
+	"time"
+)
+
+// Touch and gesture event names.
+// The touch events are dispatched by the windows of the backends which
+// support touch screens and the gesture events are dispatched by a
+// GestureRecognizer from the touch events of its window.
+// The GLFW 3.2 windows do not dispatch touch events: touch screens
+// emulate the mouse for them.
+const (
+	OnTouchDown = "win.OnTouchDown"
+	OnTouchMove = "win.OnTouchMove"
+	OnTouchUp   = "win.OnTouchUp"
+	OnTap       = "win.OnTap"
+	OnLongPress = "win.OnLongPress"
+	OnPan       = "win.OnPan"
+	OnPinch     = "win.OnPinch"
+)
+
+// Touch point pressed, moved or released
+type TouchEvent struct {
+	W    IWindow
+	ID   int // identifier of the touch point while it is pressed
+	Xpos float32
+	Ypos float32
+}
+
+// GestureState is the state of a continuous gesture, as pan and pinch
+type GestureState int
+
+// Gesture states
+const (
+	GestureBegin GestureState = iota
+	GestureChange
+	GestureEnd
+)
+
+// Gesture recognized from touch events
+type GestureEvent struct {
+	W       IWindow
+	Xpos    float32      // position of the touch or center of the touches
+	Ypos    float32      // position of the touch or center of the touches
+	State   GestureState // state of pan and pinch gestures
+	DeltaX  float32      // motion of the position since the previous pan or pinch event
+	DeltaY  float32      // motion of the position since the previous pan or pinch event
+	Scale   float32      // distance between the touches relative to the start of the pinch
+	Touches int          // number of touches
+}
+
+// GestureRecognizer recognizes gestures from the touch events of a window
+// and dispatches them to it: taps and long presses of one touch point, pans
+// moving one touch point and pinches moving two touch points, which also
+// pan by the motion of their center. The long presses are recognized when
+// the window dispatches OnFrame.
+type GestureRecognizer struct {
+	TapDistance   float32       // maximum distance moved by taps and long presses (default 10)
+	LongPressTime time.Duration // minimum time of long presses (default 500ms)
+	win           IWindow       // window whose touch events are recognized
+	touches       []touchPoint  // pressed touch points
+	panning       bool          // pan gesture started
+	pinching      bool          // pinch gesture started
+	longPressed   bool          // long press dispatched for the current touch
+	canceled      bool          // the current touch can not be a tap or long press
+	pinchDist     float32       // distance between the touches at the start of the pinch
+	lastX         float32       // position of the previous pan or pinch event
+	lastY         float32       // position of the previous pan or pinch event
+}
+
+// touchPoint is the state of a pressed touch point
+type touchPoint struct {
+	id     int
+	x, y   float32
+	x0, y0 float32
+	start  time.Time
+}
+
+// NewGestureRecognizer creates and returns a pointer to a new gesture
+// recognizer of the touch events of the specified window
+func NewGestureRecognizer(win IWindow) *GestureRecognizer {
+
+	gr := new(GestureRecognizer)
+	gr.TapDistance = 10
+	gr.LongPressTime = 500 * time.Millisecond
+	gr.win = win
+	win.Subscribe(OnTouchDown, gr.onTouch)
+	win.Subscribe(OnTouchMove, gr.onTouch)
+	win.Subscribe(OnTouchUp, gr.onTouch)
+	win.Subscribe(OnFrame, gr.onFrame)
+	return gr
+}
+
+// onTouch processes the touch events of the window
+func (gr *GestureRecognizer) onTouch(evname string, ev interface{}) {
+
+	tev := ev.(*TouchEvent)
+	switch evname {
+	case OnTouchDown:
+		if len(gr.touches) > 0 {
+			// Other touches end the one touch gestures
+			gr.canceled = true
+			gr.endPan()
+		} else {
+			gr.canceled = false
+			gr.longPressed = false
+		}
+		gr.touches = append(gr.touches, touchPoint{tev.ID, tev.Xpos, tev.Ypos, tev.Xpos, tev.Ypos, time.Now()})
+		if len(gr.touches) == 2 {
+			gr.pinching = true
+			gr.pinchDist = gr.distance()
+			gr.lastX, gr.lastY = gr.center()
+			gr.dispatch(OnPinch, GestureBegin, 0, 0)
+		}
+	case OnTouchMove:
+		tp := gr.touch(tev.ID)
+		if tp == nil {
+			return
+		}
+		tp.x, tp.y = tev.Xpos, tev.Ypos
+		if gr.pinching {
+			x, y := gr.center()
+			gr.dispatch(OnPinch, GestureChange, x-gr.lastX, y-gr.lastY)
+			gr.lastX, gr.lastY = x, y
+			return
+		}
+		if len(gr.touches) != 1 {
+			return
+		}
+		if !gr.panning {
+			if gr.canceled || distance(tp.x0, tp.y0, tp.x, tp.y) <= gr.TapDistance {
+				return
+			}
+			// The pan starts where the touch started
+			gr.canceled = true
+			gr.panning = true
+			gr.lastX, gr.lastY = tp.x0, tp.y0
+			gr.dispatch(OnPan, GestureBegin, 0, 0)
+		}
+		gr.dispatch(OnPan, GestureChange, tp.x-gr.lastX, tp.y-gr.lastY)
+		gr.lastX, gr.lastY = tp.x, tp.y
+	case OnTouchUp:
+		tp := gr.touch(tev.ID)
+		if tp == nil {
+			return
+		}
+		tp.x, tp.y = tev.Xpos, tev.Ypos
+		if gr.pinching {
+			gr.dispatch(OnPinch, GestureEnd, 0, 0)
+			gr.pinching = false
+		}
+		gr.endPan()
+		if len(gr.touches) == 1 && !gr.canceled && !gr.longPressed {
+			gr.dispatch(OnTap, GestureEnd, 0, 0)
+		}
+		for i := range gr.touches {
+			if gr.touches[i].id == tev.ID {
+				gr.touches = append(gr.touches[:i], gr.touches[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// onFrame dispatches the long press of a touch point
+// pressed for the long press time without moving
+func (gr *GestureRecognizer) onFrame(evname string, ev interface{}) {
+
+	if len(gr.touches) != 1 || gr.canceled || gr.longPressed {
+		return
+	}
+	tp := &gr.touches[0]
+	if time.Since(tp.start) < gr.LongPressTime {
+		return
+	}
+	gr.longPressed = true
+	gr.dispatch(OnLongPress, GestureEnd, 0, 0)
+}
+
+// endPan dispatches the end of the current pan gesture
+func (gr *GestureRecognizer) endPan() {
+
+	if gr.panning {
+		gr.panning = false
+		gr.dispatch(OnPan, GestureEnd, 0, 0)
+	}
+}
+
+// dispatch dispatches the specified gesture event to the window
+// at the center of the current touches
+func (gr *GestureRecognizer) dispatch(evname string, state GestureState, dx, dy float32) {
+
+	x, y := gr.center()
+	ev := &GestureEvent{W: gr.win, Xpos: x, Ypos: y, State: state, DeltaX: dx, DeltaY: dy, Scale: 1, Touches: len(gr.touches)}
+	if gr.pinching && gr.pinchDist > 0 {
+		ev.Scale = gr.distance() / gr.pinchDist
+	}
+	gr.win.Dispatch(evname, ev)
+}
+
+// touch returns the pressed touch point with the specified id or nil
+func (gr *GestureRecognizer) touch(id int) *touchPoint {
+
+	for i := range gr.touches {
+		if gr.touches[i].id == id {
+			return &gr.touches[i]
+		}
+	}
+	return nil
+}
+
+// center returns the center of the first two touch points
+func (gr *GestureRecognizer) center() (float32, float32) {
+
+	if len(gr.touches) == 0 {
+		return 0, 0
+	}
+	if len(gr.touches) == 1 {
+		return gr.touches[0].x, gr.touches[0].y
+	}
+	t0, t1 := &gr.touches[0], &gr.touches[1]
+	return (t0.x + t1.x) / 2, (t0.y + t1.y) / 2
+}
+
+// distance returns the distance between the first two touch points
+func (gr *GestureRecognizer) distance() float32 {
+
+	if len(gr.touches) < 2 {
+		return 0
+	}
+	t0, t1 := &gr.touches[0], &gr.touches[1]
+	return distance(t0.x, t0.y, t1.x, t1.y)
+}
+
+// distance returns the distance between the specified points
+func distance(x0, y0, x1, y1 float32) float32 {
+
+	return float32(math.Hypot(float64(x1-x0), float64(y1-y0)))
+}
@@ -4,13 +4,15 @@
 
 /*
  Package window abstracts the OpenGL Window manager
- Currently only glfw is supported
+ The window manager is selected at build time: GLFW is used by default
+ and SDL2 is used when building with the "sdl" tag, for the platforms
+ which only have SDL. When compiling to WebAssembly (GOOS=js) the window
+ is the HTML canvas of the page, which is drawn with WebGL 2.0.
 */
 package window
 
 import (
 	"github.com/g3n/engine/core"
-	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
 //
@@ -26,142 +28,160 @@ type IWindow interface {
 	SetPos(xpos, ypos int)
 	SetTitle(title string)
 	SetStandardCursor(cursor StandardCursor)
+	SetClipboardString(text string)
+	GetClipboardString() (string, error)
+	GetScale() float32
 	SwapBuffers()
 	ShouldClose() bool
 	SetShouldClose(bool)
 	PollEvents()
 	GetTime() float64
+	Destroy()
+}
+
+// IIME is the interface of the windows which support on the spot
+// composition of the input method editor (IME) used to type, for example,
+// Chinese, Japanese and Korean text. These windows dispatch OnPreedit events
+// with the text being composed, which is shown by the text widget with the
+// key focus, and show the IME candidates window near the informed text cursor.
+// Windows which don't implement it let the IME show the composition in its
+// own window and only dispatch the committed text as OnChar and OnText events.
+// The GLFW window doesn't implement it and never dispatches OnPreedit events,
+// as GLFW 3.2 has no callback for the composition text of the input method.
+// Only the SDL window (built with the sdl tag) implements it.
+type IIME interface {
+	SetIMECursorRect(x, y, width, height int)
 }
 
 // Key corresponds to a keyboard key.
 type Key int
 
 //
-// Keycodes (from glfw)
+// Keycodes (the values of GLFW, to which the other window managers translate)
 //
 const (
-	KeyUnknown      = Key(glfw.KeyUnknown)
-	KeySpace        = Key(glfw.KeySpace)
-	KeyApostrophe   = Key(glfw.KeyApostrophe)
-	KeyComma        = Key(glfw.KeyComma)
-	KeyMinus        = Key(glfw.KeyMinus)
-	KeyPeriod       = Key(glfw.KeyPeriod)
-	KeySlash        = Key(glfw.KeySlash)
-	Key0            = Key(glfw.Key0)
-	Key1            = Key(glfw.Key1)
-	Key2            = Key(glfw.Key2)
-	Key3            = Key(glfw.Key3)
-	Key4            = Key(glfw.Key4)
-	Key5            = Key(glfw.Key5)
-	Key6            = Key(glfw.Key6)
-	Key7            = Key(glfw.Key7)
-	Key8            = Key(glfw.Key8)
-	Key9            = Key(glfw.Key8)
-	KeySemicolon    = Key(glfw.KeySemicolon)
-	KeyEqual        = Key(glfw.KeyEqual)
-	KeyA            = Key(glfw.KeyA)
-	KeyB            = Key(glfw.KeyB)
-	KeyC            = Key(glfw.KeyC)
-	KeyD            = Key(glfw.KeyD)
-	KeyE            = Key(glfw.KeyE)
-	KeyF            = Key(glfw.KeyF)
-	KeyG            = Key(glfw.KeyG)
-	KeyH            = Key(glfw.KeyH)
-	KeyI            = Key(glfw.KeyI)
-	KeyJ            = Key(glfw.KeyJ)
-	KeyK            = Key(glfw.KeyK)
-	KeyL            = Key(glfw.KeyL)
-	KeyM            = Key(glfw.KeyM)
-	KeyN            = Key(glfw.KeyN)
-	KeyO            = Key(glfw.KeyO)
-	KeyP            = Key(glfw.KeyP)
-	KeyQ            = Key(glfw.KeyQ)
-	KeyR            = Key(glfw.KeyR)
-	KeyS            = Key(glfw.KeyS)
-	KeyT            = Key(glfw.KeyT)
-	KeyU            = Key(glfw.KeyU)
-	KeyV            = Key(glfw.KeyV)
-	KeyW            = Key(glfw.KeyW)
-	KeyX            = Key(glfw.KeyX)
-	KeyY            = Key(glfw.KeyY)
-	KeyZ            = Key(glfw.KeyZ)
-	KeyLeftBracket  = Key(glfw.KeyLeftBracket)
-	KeyBackslash    = Key(glfw.KeyBackslash)
-	KeyRightBracket = Key(glfw.KeyRightBracket)
-	KeyGraveAccent  = Key(glfw.KeyGraveAccent)
-	KeyWorld1       = Key(glfw.KeyWorld1)
-	KeyWorld2       = Key(glfw.KeyWorld2)
-	KeyEscape       = Key(glfw.KeyEscape)
-	KeyEnter        = Key(glfw.KeyEnter)
-	KeyTab          = Key(glfw.KeyTab)
-	KeyBackspace    = Key(glfw.KeyBackspace)
-	KeyInsert       = Key(glfw.KeyInsert)
-	KeyDelete       = Key(glfw.KeyDelete)
-	KeyRight        = Key(glfw.KeyRight)
-	KeyLeft         = Key(glfw.KeyLeft)
-	KeyDown         = Key(glfw.KeyDown)
-	KeyUp           = Key(glfw.KeyUp)
-	KeyPageUp       = Key(glfw.KeyPageUp)
-	KeyPageDown     = Key(glfw.KeyPageDown)
-	KeyHome         = Key(glfw.KeyHome)
-	KeyEnd          = Key(glfw.KeyEnd)
-	KeyCapsLock     = Key(glfw.KeyCapsLock)
-	KeyScrollLock   = Key(glfw.KeyScrollLock)
-	KeyNumLock      = Key(glfw.KeyNumLock)
-	KeyPrintScreen  = Key(glfw.KeyPrintScreen)
-	KeyPause        = Key(glfw.KeyPause)
-	KeyF1           = Key(glfw.KeyF1)
-	KeyF2           = Key(glfw.KeyF2)
-	KeyF3           = Key(glfw.KeyF3)
-	KeyF4           = Key(glfw.KeyF4)
-	KeyF5           = Key(glfw.KeyF5)
-	KeyF6           = Key(glfw.KeyF6)
-	KeyF7           = Key(glfw.KeyF7)
-	KeyF8           = Key(glfw.KeyF8)
-	KeyF9           = Key(glfw.KeyF9)
-	KeyF10          = Key(glfw.KeyF10)
-	KeyF11          = Key(glfw.KeyF11)
-	KeyF12          = Key(glfw.KeyF12)
-	KeyF13          = Key(glfw.KeyF13)
-	KeyF14          = Key(glfw.KeyF14)
-	KeyF15          = Key(glfw.KeyF15)
-	KeyF16          = Key(glfw.KeyF16)
-	KeyF17          = Key(glfw.KeyF17)
-	KeyF18          = Key(glfw.KeyF18)
-	KeyF19          = Key(glfw.KeyF19)
-	KeyF20          = Key(glfw.KeyF20)
-	KeyF21          = Key(glfw.KeyF21)
-	KeyF22          = Key(glfw.KeyF22)
-	KeyF23          = Key(glfw.KeyF23)
-	KeyF24          = Key(glfw.KeyF24)
-	KeyF25          = Key(glfw.KeyF25)
-	KeyKP0          = Key(glfw.KeyKP0)
-	KeyKP1          = Key(glfw.KeyKP1)
-	KeyKP2          = Key(glfw.KeyKP2)
-	KeyKP3          = Key(glfw.KeyKP3)
-	KeyKP4          = Key(glfw.KeyKP4)
-	KeyKP5          = Key(glfw.KeyKP5)
-	KeyKP6          = Key(glfw.KeyKP6)
-	KeyKP7          = Key(glfw.KeyKP7)
-	KeyKP8          = Key(glfw.KeyKP8)
-	KeyKP9          = Key(glfw.KeyKP9)
-	KeyKPDecimal    = Key(glfw.KeyKPDecimal)
-	KeyKPDivide     = Key(glfw.KeyKPDivide)
-	KeyKPMultiply   = Key(glfw.KeyKPMultiply)
-	KeyKPSubtract   = Key(glfw.KeyKPSubtract)
-	KeyKPAdd        = Key(glfw.KeyKPAdd)
-	KeyKPEnter      = Key(glfw.KeyKPEnter)
-	KeyKPEqual      = Key(glfw.KeyKPEqual)
-	KeyLeftShift    = Key(glfw.KeyLeftShift)
-	KeyLeftControl  = Key(glfw.KeyLeftControl)
-	KeyLeftAlt      = Key(glfw.KeyLeftAlt)
-	KeyLeftSuper    = Key(glfw.KeyLeftSuper)
-	KeyRightShift   = Key(glfw.KeyRightShift)
-	KeyRightControl = Key(glfw.KeyRightControl)
-	KeyRightAlt     = Key(glfw.KeyRightAlt)
-	KeyRightSuper   = Key(glfw.KeyRightSuper)
-	KeyMenu         = Key(glfw.KeyMenu)
-	KeyLast         = Key(glfw.KeyLast)
+	KeyUnknown      = Key(-1)
+	KeySpace        = Key(32)
+	KeyApostrophe   = Key(39)
+	KeyComma        = Key(44)
+	KeyMinus        = Key(45)
+	KeyPeriod       = Key(46)
+	KeySlash        = Key(47)
+	Key0            = Key(48)
+	Key1            = Key(49)
+	Key2            = Key(50)
+	Key3            = Key(51)
+	Key4            = Key(52)
+	Key5            = Key(53)
+	Key6            = Key(54)
+	Key7            = Key(55)
+	Key8            = Key(56)
+	Key9            = Key(57)
+	KeySemicolon    = Key(59)
+	KeyEqual        = Key(61)
+	KeyA            = Key(65)
+	KeyB            = Key(66)
+	KeyC            = Key(67)
+	KeyD            = Key(68)
+	KeyE            = Key(69)
+	KeyF            = Key(70)
+	KeyG            = Key(71)
+	KeyH            = Key(72)
+	KeyI            = Key(73)
+	KeyJ            = Key(74)
+	KeyK            = Key(75)
+	KeyL            = Key(76)
+	KeyM            = Key(77)
+	KeyN            = Key(78)
+	KeyO            = Key(79)
+	KeyP            = Key(80)
+	KeyQ            = Key(81)
+	KeyR            = Key(82)
+	KeyS            = Key(83)
+	KeyT            = Key(84)
+	KeyU            = Key(85)
+	KeyV            = Key(86)
+	KeyW            = Key(87)
+	KeyX            = Key(88)
+	KeyY            = Key(89)
+	KeyZ            = Key(90)
+	KeyLeftBracket  = Key(91)
+	KeyBackslash    = Key(92)
+	KeyRightBracket = Key(93)
+	KeyGraveAccent  = Key(96)
+	KeyWorld1       = Key(161)
+	KeyWorld2       = Key(162)
+	KeyEscape       = Key(256)
+	KeyEnter        = Key(257)
+	KeyTab          = Key(258)
+	KeyBackspace    = Key(259)
+	KeyInsert       = Key(260)
+	KeyDelete       = Key(261)
+	KeyRight        = Key(262)
+	KeyLeft         = Key(263)
+	KeyDown         = Key(264)
+	KeyUp           = Key(265)
+	KeyPageUp       = Key(266)
+	KeyPageDown     = Key(267)
+	KeyHome         = Key(268)
+	KeyEnd          = Key(269)
+	KeyCapsLock     = Key(280)
+	KeyScrollLock   = Key(281)
+	KeyNumLock      = Key(282)
+	KeyPrintScreen  = Key(283)
+	KeyPause        = Key(284)
+	KeyF1           = Key(290)
+	KeyF2           = Key(291)
+	KeyF3           = Key(292)
+	KeyF4           = Key(293)
+	KeyF5           = Key(294)
+	KeyF6           = Key(295)
+	KeyF7           = Key(296)
+	KeyF8           = Key(297)
+	KeyF9           = Key(298)
+	KeyF10          = Key(299)
+	KeyF11          = Key(300)
+	KeyF12          = Key(301)
+	KeyF13          = Key(302)
+	KeyF14          = Key(303)
+	KeyF15          = Key(304)
+	KeyF16          = Key(305)
+	KeyF17          = Key(306)
+	KeyF18          = Key(307)
+	KeyF19          = Key(308)
+	KeyF20          = Key(309)
+	KeyF21          = Key(310)
+	KeyF22          = Key(311)
+	KeyF23          = Key(312)
+	KeyF24          = Key(313)
+	KeyF25          = Key(314)
+	KeyKP0          = Key(320)
+	KeyKP1          = Key(321)
+	KeyKP2          = Key(322)
+	KeyKP3          = Key(323)
+	KeyKP4          = Key(324)
+	KeyKP5          = Key(325)
+	KeyKP6          = Key(326)
+	KeyKP7          = Key(327)
+	KeyKP8          = Key(328)
+	KeyKP9          = Key(329)
+	KeyKPDecimal    = Key(330)
+	KeyKPDivide     = Key(331)
+	KeyKPMultiply   = Key(332)
+	KeyKPSubtract   = Key(333)
+	KeyKPAdd        = Key(334)
+	KeyKPEnter      = Key(335)
+	KeyKPEqual      = Key(336)
+	KeyLeftShift    = Key(340)
+	KeyLeftControl  = Key(341)
+	KeyLeftAlt      = Key(342)
+	KeyLeftSuper    = Key(343)
+	KeyRightShift   = Key(344)
+	KeyRightControl = Key(345)
+	KeyRightAlt     = Key(346)
+	KeyRightSuper   = Key(347)
+	KeyMenu         = Key(348)
+	KeyLast         = Key(348)
 )
 
 // ModifierKey corresponds to a modifier key.
@@ -169,10 +189,10 @@ type ModifierKey int
 
 // Modifier keys
 const (
-	ModShift   = ModifierKey(glfw.ModShift)
-	ModControl = ModifierKey(glfw.ModControl)
-	ModAlt     = ModifierKey(glfw.ModAlt)
-	ModSuper   = ModifierKey(glfw.ModSuper)
+	ModShift   = ModifierKey(1)
+	ModControl = ModifierKey(2)
+	ModAlt     = ModifierKey(4)
+	ModSuper   = ModifierKey(8)
 )
 
 // MouseButton corresponds to a mouse button.
@@ -180,18 +200,18 @@ type MouseButton int
 
 // Mouse buttons
 const (
-	MouseButton1      = MouseButton(glfw.MouseButton1)
-	MouseButton2      = MouseButton(glfw.MouseButton2)
-	MouseButton3      = MouseButton(glfw.MouseButton3)
-	MouseButton4      = MouseButton(glfw.MouseButton4)
-	MouseButton5      = MouseButton(glfw.MouseButton5)
-	MouseButton6      = MouseButton(glfw.MouseButton6)
-	MouseButton7      = MouseButton(glfw.MouseButton7)
-	MouseButton8      = MouseButton(glfw.MouseButton8)
-	MouseButtonLast   = MouseButton(glfw.MouseButtonLast)
-	MouseButtonLeft   = MouseButton(glfw.MouseButtonLeft)
-	MouseButtonRight  = MouseButton(glfw.MouseButtonRight)
-	MouseButtonMiddle = MouseButton(glfw.MouseButtonMiddle)
+	MouseButton1      = MouseButton(0)
+	MouseButton2      = MouseButton(1)
+	MouseButton3      = MouseButton(2)
+	MouseButton4      = MouseButton(3)
+	MouseButton5      = MouseButton(4)
+	MouseButton6      = MouseButton(5)
+	MouseButton7      = MouseButton(6)
+	MouseButton8      = MouseButton(7)
+	MouseButtonLast   = MouseButton(7)
+	MouseButtonLeft   = MouseButton(0)
+	MouseButtonRight  = MouseButton(1)
+	MouseButtonMiddle = MouseButton(2)
 )
 
 // StandardCursor corresponds to a standard cursor icon.
@@ -199,21 +219,21 @@ type StandardCursor int
 
 // Standard cursors
 const (
-	ArrowCursor     = StandardCursor(glfw.ArrowCursor)
-	IBeamCursor     = StandardCursor(glfw.IBeamCursor)
-	CrosshairCursor = StandardCursor(glfw.CrosshairCursor)
-	HandCursor      = StandardCursor(glfw.HandCursor)
-	HResizeCursor   = StandardCursor(glfw.HResizeCursor)
-	VResizeCursor   = StandardCursor(glfw.VResizeCursor)
+	ArrowCursor     = StandardCursor(0x00036001)
+	IBeamCursor     = StandardCursor(0x00036002)
+	CrosshairCursor = StandardCursor(0x00036003)
+	HandCursor      = StandardCursor(0x00036004)
+	HResizeCursor   = StandardCursor(0x00036005)
+	VResizeCursor   = StandardCursor(0x00036006)
 )
 
 // Action corresponds to a key or button action.
 type Action int
 
 const (
-	Release = Action(glfw.Release) // The key or button was released.
-	Press   = Action(glfw.Press)   // The key or button was pressed.
-	Repeat  = Action(glfw.Repeat)  // The key was held down until it repeated.
+	Release = Action(0) // The key or button was released.
+	Press   = Action(1) // The key or button was pressed.
+	Repeat  = Action(2) // The key was held down until it repeated.
 )
 
 // InputMode corresponds to an input mode.
@@ -221,32 +241,39 @@ type InputMode int
 
 // Input modes
 const (
-	CursorMode             = InputMode(glfw.CursorMode)             // See Cursor mode values
-	StickyKeysMode         = InputMode(glfw.StickyKeysMode)         // Value can be either 1 or 0
-	StickyMouseButtonsMode = InputMode(glfw.StickyMouseButtonsMode) // Value can be either 1 or 0
+	CursorMode             = InputMode(0x00033001) // See Cursor mode values
+	StickyKeysMode         = InputMode(0x00033002) // Value can be either 1 or 0
+	StickyMouseButtonsMode = InputMode(0x00033003) // Value can be either 1 or 0
 )
 
 // Cursor mode values
 const (
-	CursorNormal   = glfw.CursorNormal
-	CursorHidden   = glfw.CursorHidden
-	CursorDisabled = glfw.CursorDisabled
+	CursorNormal   = 0x00034001 // Cursor visible
+	CursorHidden   = 0x00034002 // Cursor hidden over the window
+	CursorDisabled = 0x00034003 // Cursor hidden and captured with unbounded motion
 )
 
 //
 // Window event names using for dispatch and subscribe
 //
 const (
-	OnWindowPos  = "win.OnWindowPos"
-	OnWindowSize = "win.OnWindowSize"
-	OnKeyUp      = "win.OnKeyUp"
-	OnKeyDown    = "win.OnKeyDown"
-	OnChar       = "win.OnChar"
-	OnCursor     = "win.OnCursor"
-	OnMouseUp    = "win.OnMouseUp"
-	OnMouseDown  = "win.OnMouseDown"
-	OnScroll     = "win.OnScroll"
-	OnFrame      = "win.OnFrame"
+	OnWindowPos     = "win.OnWindowPos"
+	OnWindowSize    = "win.OnWindowSize"
+	OnKeyUp         = "win.OnKeyUp"
+	OnKeyDown       = "win.OnKeyDown"
+	OnChar          = "win.OnChar"
+	OnText          = "win.OnText"
+	OnCursor        = "win.OnCursor"
+	OnMouseUp       = "win.OnMouseUp"
+	OnMouseDown     = "win.OnMouseDown"
+	OnScroll        = "win.OnScroll"
+	OnFrame         = "win.OnFrame"
+	OnPreedit       = "win.OnPreedit"
+	OnScale         = "win.OnScale"
+	OnFileDrop      = "win.OnFileDrop"
+	OnWindowFocus   = "win.OnWindowFocus"
+	OnWindowIconify = "win.OnWindowIconify"
+	OnWindowClose   = "win.OnWindowClose"
 )
 
 // Window position changed event
@@ -263,7 +290,17 @@ type SizeEvent struct {
 	Height int
 }
 
-// Key pressed in window
+// Window content scale changed as when the window
+// is moved to a monitor with a different resolution
+type ScaleEvent struct {
+	W     IWindow
+	Scale float32
+}
+
+// Key pressed in window.
+// Keycode identifies the physical key, named after its position in the
+// US keyboard layout, and Scancode is its platform specific code.
+// The text typed by the keys is dispatched by OnText events.
 type KeyEvent struct {
 	W        IWindow
 	Keycode  Key
@@ -279,6 +316,22 @@ type CharEvent struct {
 	Mods ModifierKey
 }
 
+// Text typed in window, as produced by the keyboard layout and committed
+// by the input method, independently of the physical keys pressed
+type TextEvent struct {
+	W    IWindow
+	Text string
+}
+
+// Input method composition text changed.
+// The text committed by the input method is dispatched as OnChar and OnText events.
+// It is only dispatched by the windows which implement IIME.
+type PreeditEvent struct {
+	W     IWindow
+	Text  string // text being composed or empty when the composition ended
+	Caret int    // position of the caret in the composed text in characters
+}
+
 // Mouse button event
 type MouseEvent struct {
 	W      IWindow
@@ -303,10 +356,61 @@ type ScrollEvent struct {
 	Yoffset float32
 }
 
+// Window gained or lost the input focus
+type FocusEvent struct {
+	W       IWindow
+	Focused bool
+}
+
+// Window minimized (iconified) or restored
+type IconifyEvent struct {
+	W         IWindow
+	Iconified bool
+}
+
+// Window close requested by the user, as by clicking the window close
+// button. A subscriber vetoes the close by canceling the dispatch of the
+// event with CancelDispatch, which keeps ShouldClose false.
+type CloseEvent struct {
+	W IWindow
+}
+
+// Files dropped from the system onto the window
+type FileDropEvent struct {
+	W     IWindow
+	Xpos  float32  // cursor position where the files were dropped
+	Ypos  float32  // cursor position where the files were dropped
+	Files []string // paths of the dropped files and directories
+}
+
+// New creates and returns a new window of the specified type, which must be
+// the Backend selected at build time, with an OpenGL context current.
+// The "headless" type creates a window with no visible window, for rendering
+// on machines without a display, and needs the "egl" build tag.
 func New(wtype string, width, height int, title string, full bool) (IWindow, error) {
 
-	if wtype != "glfw" {
+	if wtype == "headless" {
+		return newHeadless(width, height, nil)
+	}
+	if wtype != Backend {
 		panic("Unsupported window type")
 	}
-	return newGLFW(width, height, title, full)
+	return newWindow(width, height, title, full, nil)
+}
+
+// NewShared creates and returns an additional window of the same type of the
+// specified window whose OpenGL context shares its objects, as textures,
+// buffers and shader programs, with the context of the specified window.
+// Each window has its own event dispatcher and must be rendered with its own
+// gls.GLS state and renderer while its context is current. The objects which
+// are not shared, as vertex arrays and framebuffers, are created by the
+// geometries for each context and the render targets must be rendered in
+// the context where they were created. The context of the new window is
+// current when it returns.
+func NewShared(width, height int, title string, share IWindow) (IWindow, error) {
+
+	if isHeadless(share) {
+		return newHeadless(width, height, share)
+	}
+	return newWindow(width, height, title, false, share)
 }
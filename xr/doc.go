@@ -0,0 +1,11 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xr implements support for virtual reality head mounted displays.
+// A session supplies the view and projection matrices of each eye and the
+// images into which the eyes must be rendered, and dispatches the poses of
+// the head and of the hand controllers as events.
+// The OpenXR backend is only compiled with the "openxr" build tag and
+// requires the OpenXR headers and loader library to be installed.
+package xr
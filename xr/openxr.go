@@ -0,0 +1,549 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build openxr
+// +build openxr
+
+package xr
+
+/*
+#cgo linux   CFLAGS:  -DXR_USE_PLATFORM_XLIB -DXR_USE_GRAPHICS_API_OPENGL
+#cgo linux   LDFLAGS: -lopenxr_loader -lGL -lX11
+#cgo windows CFLAGS:  -DXR_USE_PLATFORM_WIN32 -DXR_USE_GRAPHICS_API_OPENGL
+#cgo windows LDFLAGS: -lopenxr_loader -lopengl32
+
+#include <stdlib.h>
+#include <string.h>
+
+#ifdef XR_USE_PLATFORM_XLIB
+#include <X11/Xlib.h>
+#include <GL/glx.h>
+#endif
+#ifdef XR_USE_PLATFORM_WIN32
+#include <windows.h>
+#endif
+#include <GL/gl.h>
+#include <openxr/openxr.h>
+#include <openxr/openxr_platform.h>
+
+#define G3NXR_MAX_VIEWS 2
+#define G3NXR_MAX_IMAGES 8
+
+typedef struct {
+	XrInstance      instance;
+	XrSystemId      system;
+	XrSession       session;
+	XrSpace         appSpace;
+	XrSpace         viewSpace;
+	XrSessionState  state;
+	int             running;
+	uint32_t        viewCount;
+	XrViewConfigurationView configViews[G3NXR_MAX_VIEWS];
+	XrSwapchain     swapchains[G3NXR_MAX_VIEWS];
+	uint32_t        imageCount[G3NXR_MAX_VIEWS];
+	XrSwapchainImageOpenGLKHR images[G3NXR_MAX_VIEWS][G3NXR_MAX_IMAGES];
+	uint32_t        imageIndex[G3NXR_MAX_VIEWS];
+	XrView          views[G3NXR_MAX_VIEWS];
+	XrCompositionLayerProjectionView layerViews[G3NXR_MAX_VIEWS];
+	XrFrameState    frameState;
+	XrActionSet     actionSet;
+	XrAction        poseAction;
+	XrPath          handPaths[2];
+	XrSpace         handSpaces[2];
+	XrSpaceLocation headLocation;
+	XrSpaceLocation handLocations[2];
+} g3nxr;
+
+// g3nxr_create creates the OpenXR instance, session, spaces, swapchains and actions
+// using the OpenGL context which is current in the calling thread.
+static XrResult g3nxr_create(g3nxr* x, const char* appName) {
+
+	XrResult res;
+	memset(x, 0, sizeof(*x));
+
+	// Creates instance with the OpenGL extension
+	const char* extensions[] = { XR_KHR_OPENGL_ENABLE_EXTENSION_NAME };
+	XrInstanceCreateInfo ici = { XR_TYPE_INSTANCE_CREATE_INFO };
+	strncpy(ici.applicationInfo.applicationName, appName, XR_MAX_APPLICATION_NAME_SIZE - 1);
+	strncpy(ici.applicationInfo.engineName, "g3n", XR_MAX_ENGINE_NAME_SIZE - 1);
+	ici.applicationInfo.apiVersion = XR_CURRENT_API_VERSION;
+	ici.enabledExtensionCount = 1;
+	ici.enabledExtensionNames = extensions;
+	res = xrCreateInstance(&ici, &x->instance);
+	if (XR_FAILED(res)) return res;
+
+	// Gets head mounted display system
+	XrSystemGetInfo sgi = { XR_TYPE_SYSTEM_GET_INFO };
+	sgi.formFactor = XR_FORM_FACTOR_HEAD_MOUNTED_DISPLAY;
+	res = xrGetSystem(x->instance, &sgi, &x->system);
+	if (XR_FAILED(res)) return res;
+
+	// The graphics requirements must be queried before creating the session
+	PFN_xrGetOpenGLGraphicsRequirementsKHR getReqs = NULL;
+	res = xrGetInstanceProcAddr(x->instance, "xrGetOpenGLGraphicsRequirementsKHR", (PFN_xrVoidFunction*)&getReqs);
+	if (XR_FAILED(res)) return res;
+	XrGraphicsRequirementsOpenGLKHR reqs = { XR_TYPE_GRAPHICS_REQUIREMENTS_OPENGL_KHR };
+	res = getReqs(x->instance, x->system, &reqs);
+	if (XR_FAILED(res)) return res;
+
+	// Creates session bound to the current OpenGL context
+#ifdef XR_USE_PLATFORM_XLIB
+	XrGraphicsBindingOpenGLXlibKHR binding = { XR_TYPE_GRAPHICS_BINDING_OPENGL_XLIB_KHR };
+	binding.xDisplay = glXGetCurrentDisplay();
+	binding.glxContext = glXGetCurrentContext();
+	binding.glxDrawable = glXGetCurrentDrawable();
+	int fbconfigID = 0;
+	glXQueryContext(binding.xDisplay, binding.glxContext, GLX_FBCONFIG_ID, &fbconfigID);
+	int attribs[] = { GLX_FBCONFIG_ID, fbconfigID, None };
+	int count = 0;
+	GLXFBConfig* configs = glXChooseFBConfig(binding.xDisplay, DefaultScreen(binding.xDisplay), attribs, &count);
+	if (configs != NULL && count > 0) {
+		binding.glxFBConfig = configs[0];
+		XVisualInfo* vi = glXGetVisualFromFBConfig(binding.xDisplay, configs[0]);
+		if (vi != NULL) {
+			binding.visualid = (uint32_t)vi->visualid;
+			XFree(vi);
+		}
+		XFree(configs);
+	}
+#endif
+#ifdef XR_USE_PLATFORM_WIN32
+	XrGraphicsBindingOpenGLWin32KHR binding = { XR_TYPE_GRAPHICS_BINDING_OPENGL_WIN32_KHR };
+	binding.hDC = wglGetCurrentDC();
+	binding.hGLRC = wglGetCurrentContext();
+#endif
+	XrSessionCreateInfo sci = { XR_TYPE_SESSION_CREATE_INFO };
+	sci.next = &binding;
+	sci.systemId = x->system;
+	res = xrCreateSession(x->instance, &sci, &x->session);
+	if (XR_FAILED(res)) return res;
+
+	// Creates the application (local) and head (view) reference spaces
+	XrReferenceSpaceCreateInfo rsci = { XR_TYPE_REFERENCE_SPACE_CREATE_INFO };
+	rsci.referenceSpaceType = XR_REFERENCE_SPACE_TYPE_LOCAL;
+	rsci.poseInReferenceSpace.orientation.w = 1;
+	res = xrCreateReferenceSpace(x->session, &rsci, &x->appSpace);
+	if (XR_FAILED(res)) return res;
+	rsci.referenceSpaceType = XR_REFERENCE_SPACE_TYPE_VIEW;
+	res = xrCreateReferenceSpace(x->session, &rsci, &x->viewSpace);
+	if (XR_FAILED(res)) return res;
+
+	// Gets the stereo view configuration
+	for (int i = 0; i < G3NXR_MAX_VIEWS; i++) {
+		x->configViews[i].type = XR_TYPE_VIEW_CONFIGURATION_VIEW;
+		x->views[i].type = XR_TYPE_VIEW;
+	}
+	res = xrEnumerateViewConfigurationViews(x->instance, x->system, XR_VIEW_CONFIGURATION_TYPE_PRIMARY_STEREO,
+		G3NXR_MAX_VIEWS, &x->viewCount, x->configViews);
+	if (XR_FAILED(res)) return res;
+
+	// Chooses the swapchain format
+	int64_t formats[64];
+	uint32_t formatCount = 0;
+	res = xrEnumerateSwapchainFormats(x->session, 64, &formatCount, formats);
+	if (XR_FAILED(res)) return res;
+	int64_t format = formats[0];
+	for (uint32_t i = 0; i < formatCount; i++) {
+		if (formats[i] == GL_SRGB8_ALPHA8 || formats[i] == GL_RGBA8) {
+			format = formats[i];
+			break;
+		}
+	}
+
+	// Creates one swapchain for each view
+	for (uint32_t i = 0; i < x->viewCount; i++) {
+		XrSwapchainCreateInfo swci = { XR_TYPE_SWAPCHAIN_CREATE_INFO };
+		swci.usageFlags = XR_SWAPCHAIN_USAGE_COLOR_ATTACHMENT_BIT | XR_SWAPCHAIN_USAGE_SAMPLED_BIT;
+		swci.format = format;
+		swci.sampleCount = 1;
+		swci.width = x->configViews[i].recommendedImageRectWidth;
+		swci.height = x->configViews[i].recommendedImageRectHeight;
+		swci.faceCount = 1;
+		swci.arraySize = 1;
+		swci.mipCount = 1;
+		res = xrCreateSwapchain(x->session, &swci, &x->swapchains[i]);
+		if (XR_FAILED(res)) return res;
+		for (int j = 0; j < G3NXR_MAX_IMAGES; j++) {
+			x->images[i][j].type = XR_TYPE_SWAPCHAIN_IMAGE_OPENGL_KHR;
+		}
+		res = xrEnumerateSwapchainImages(x->swapchains[i], G3NXR_MAX_IMAGES, &x->imageCount[i],
+			(XrSwapchainImageBaseHeader*)x->images[i]);
+		if (XR_FAILED(res)) return res;
+	}
+
+	// Creates the hand pose action
+	XrActionSetCreateInfo asci = { XR_TYPE_ACTION_SET_CREATE_INFO };
+	strcpy(asci.actionSetName, "g3n");
+	strcpy(asci.localizedActionSetName, "g3n");
+	res = xrCreateActionSet(x->instance, &asci, &x->actionSet);
+	if (XR_FAILED(res)) return res;
+	xrStringToPath(x->instance, "/user/hand/left", &x->handPaths[0]);
+	xrStringToPath(x->instance, "/user/hand/right", &x->handPaths[1]);
+	XrActionCreateInfo aci = { XR_TYPE_ACTION_CREATE_INFO };
+	aci.actionType = XR_ACTION_TYPE_POSE_INPUT;
+	strcpy(aci.actionName, "hand_pose");
+	strcpy(aci.localizedActionName, "Hand pose");
+	aci.countSubactionPaths = 2;
+	aci.subactionPaths = x->handPaths;
+	res = xrCreateAction(x->actionSet, &aci, &x->poseAction);
+	if (XR_FAILED(res)) return res;
+
+	// Suggests bindings for the generic simple controller profile
+	XrPath profile, gripPaths[2];
+	xrStringToPath(x->instance, "/interaction_profiles/khr/simple_controller", &profile);
+	xrStringToPath(x->instance, "/user/hand/left/input/grip/pose", &gripPaths[0]);
+	xrStringToPath(x->instance, "/user/hand/right/input/grip/pose", &gripPaths[1]);
+	XrActionSuggestedBinding bindings[2] = { { x->poseAction, gripPaths[0] }, { x->poseAction, gripPaths[1] } };
+	XrInteractionProfileSuggestedBinding sb = { XR_TYPE_INTERACTION_PROFILE_SUGGESTED_BINDING };
+	sb.interactionProfile = profile;
+	sb.countSuggestedBindings = 2;
+	sb.suggestedBindings = bindings;
+	res = xrSuggestInteractionProfileBindings(x->instance, &sb);
+	if (XR_FAILED(res)) return res;
+
+	// Creates one space for each hand pose
+	for (int i = 0; i < 2; i++) {
+		XrActionSpaceCreateInfo ascri = { XR_TYPE_ACTION_SPACE_CREATE_INFO };
+		ascri.action = x->poseAction;
+		ascri.subactionPath = x->handPaths[i];
+		ascri.poseInActionSpace.orientation.w = 1;
+		res = xrCreateActionSpace(x->session, &ascri, &x->handSpaces[i]);
+		if (XR_FAILED(res)) return res;
+	}
+	XrSessionActionSetsAttachInfo sasai = { XR_TYPE_SESSION_ACTION_SETS_ATTACH_INFO };
+	sasai.countActionSets = 1;
+	sasai.actionSets = &x->actionSet;
+	return xrAttachSessionActionSets(x->session, &sasai);
+}
+
+// g3nxr_poll processes the pending runtime events, beginning or ending
+// the session when requested by the runtime.
+static XrResult g3nxr_poll(g3nxr* x) {
+
+	XrEventDataBuffer ev;
+	for (;;) {
+		memset(&ev, 0, sizeof(ev));
+		ev.type = XR_TYPE_EVENT_DATA_BUFFER;
+		XrResult res = xrPollEvent(x->instance, &ev);
+		if (res != XR_SUCCESS) {
+			return XR_FAILED(res) ? res : XR_SUCCESS;
+		}
+		if (ev.type != XR_TYPE_EVENT_DATA_SESSION_STATE_CHANGED) {
+			continue;
+		}
+		XrEventDataSessionStateChanged* sc = (XrEventDataSessionStateChanged*)&ev;
+		x->state = sc->state;
+		if (sc->state == XR_SESSION_STATE_READY) {
+			XrSessionBeginInfo sbi = { XR_TYPE_SESSION_BEGIN_INFO };
+			sbi.primaryViewConfigurationType = XR_VIEW_CONFIGURATION_TYPE_PRIMARY_STEREO;
+			res = xrBeginSession(x->session, &sbi);
+			if (XR_FAILED(res)) return res;
+			x->running = 1;
+		} else if (sc->state == XR_SESSION_STATE_STOPPING) {
+			xrEndSession(x->session);
+			x->running = 0;
+		}
+	}
+}
+
+// g3nxr_begin_frame waits for and begins a new frame, locates the views
+// and the tracked devices. Sets shouldRender if the views must be rendered.
+static XrResult g3nxr_begin_frame(g3nxr* x, int* shouldRender) {
+
+	*shouldRender = 0;
+	XrFrameWaitInfo fwi = { XR_TYPE_FRAME_WAIT_INFO };
+	x->frameState.type = XR_TYPE_FRAME_STATE;
+	XrResult res = xrWaitFrame(x->session, &fwi, &x->frameState);
+	if (XR_FAILED(res)) return res;
+	XrFrameBeginInfo fbi = { XR_TYPE_FRAME_BEGIN_INFO };
+	res = xrBeginFrame(x->session, &fbi);
+	if (XR_FAILED(res)) return res;
+	if (!x->frameState.shouldRender) {
+		return XR_SUCCESS;
+	}
+
+	// Locates the views
+	XrViewLocateInfo vli = { XR_TYPE_VIEW_LOCATE_INFO };
+	vli.viewConfigurationType = XR_VIEW_CONFIGURATION_TYPE_PRIMARY_STEREO;
+	vli.displayTime = x->frameState.predictedDisplayTime;
+	vli.space = x->appSpace;
+	XrViewState vs = { XR_TYPE_VIEW_STATE };
+	uint32_t count = 0;
+	res = xrLocateViews(x->session, &vli, &vs, G3NXR_MAX_VIEWS, &count, x->views);
+	if (XR_FAILED(res)) return res;
+
+	// Syncs actions and locates the head and hands
+	XrActiveActionSet active = { x->actionSet, XR_NULL_PATH };
+	XrActionsSyncInfo asi = { XR_TYPE_ACTIONS_SYNC_INFO };
+	asi.countActiveActionSets = 1;
+	asi.activeActionSets = &active;
+	xrSyncActions(x->session, &asi);
+	x->headLocation.type = XR_TYPE_SPACE_LOCATION;
+	xrLocateSpace(x->viewSpace, x->appSpace, vli.displayTime, &x->headLocation);
+	for (int i = 0; i < 2; i++) {
+		x->handLocations[i].type = XR_TYPE_SPACE_LOCATION;
+		xrLocateSpace(x->handSpaces[i], x->appSpace, vli.displayTime, &x->handLocations[i]);
+	}
+	*shouldRender = 1;
+	return XR_SUCCESS;
+}
+
+// g3nxr_acquire acquires the next swapchain image of the specified view and returns its texture
+static XrResult g3nxr_acquire(g3nxr* x, int view, uint32_t* texname) {
+
+	XrSwapchainImageAcquireInfo ai = { XR_TYPE_SWAPCHAIN_IMAGE_ACQUIRE_INFO };
+	XrResult res = xrAcquireSwapchainImage(x->swapchains[view], &ai, &x->imageIndex[view]);
+	if (XR_FAILED(res)) return res;
+	XrSwapchainImageWaitInfo wi = { XR_TYPE_SWAPCHAIN_IMAGE_WAIT_INFO };
+	wi.timeout = XR_INFINITE_DURATION;
+	res = xrWaitSwapchainImage(x->swapchains[view], &wi);
+	if (XR_FAILED(res)) return res;
+	*texname = x->images[view][x->imageIndex[view]].image;
+	return XR_SUCCESS;
+}
+
+// g3nxr_end_frame releases the acquired swapchain images and submits the frame
+static XrResult g3nxr_end_frame(g3nxr* x, int rendered) {
+
+	XrCompositionLayerProjection layer = { XR_TYPE_COMPOSITION_LAYER_PROJECTION };
+	const XrCompositionLayerBaseHeader* layers[1] = { (XrCompositionLayerBaseHeader*)&layer };
+	XrFrameEndInfo fei = { XR_TYPE_FRAME_END_INFO };
+	fei.displayTime = x->frameState.predictedDisplayTime;
+	fei.environmentBlendMode = XR_ENVIRONMENT_BLEND_MODE_OPAQUE;
+	if (rendered) {
+		for (uint32_t i = 0; i < x->viewCount; i++) {
+			XrSwapchainImageReleaseInfo ri = { XR_TYPE_SWAPCHAIN_IMAGE_RELEASE_INFO };
+			xrReleaseSwapchainImage(x->swapchains[i], &ri);
+			XrCompositionLayerProjectionView* lv = &x->layerViews[i];
+			memset(lv, 0, sizeof(*lv));
+			lv->type = XR_TYPE_COMPOSITION_LAYER_PROJECTION_VIEW;
+			lv->pose = x->views[i].pose;
+			lv->fov = x->views[i].fov;
+			lv->subImage.swapchain = x->swapchains[i];
+			lv->subImage.imageRect.extent.width = x->configViews[i].recommendedImageRectWidth;
+			lv->subImage.imageRect.extent.height = x->configViews[i].recommendedImageRectHeight;
+		}
+		layer.space = x->appSpace;
+		layer.viewCount = x->viewCount;
+		layer.views = x->layerViews;
+		fei.layerCount = 1;
+		fei.layers = layers;
+	}
+	return xrEndFrame(x->session, &fei);
+}
+
+// g3nxr_destroy releases all OpenXR objects
+static void g3nxr_destroy(g3nxr* x) {
+
+	for (uint32_t i = 0; i < x->viewCount; i++) {
+		if (x->swapchains[i] != XR_NULL_HANDLE) xrDestroySwapchain(x->swapchains[i]);
+	}
+	for (int i = 0; i < 2; i++) {
+		if (x->handSpaces[i] != XR_NULL_HANDLE) xrDestroySpace(x->handSpaces[i]);
+	}
+	if (x->viewSpace != XR_NULL_HANDLE) xrDestroySpace(x->viewSpace);
+	if (x->appSpace != XR_NULL_HANDLE) xrDestroySpace(x->appSpace);
+	if (x->actionSet != XR_NULL_HANDLE) xrDestroyActionSet(x->actionSet);
+	if (x->session != XR_NULL_HANDLE) xrDestroySession(x->session);
+	if (x->instance != XR_NULL_HANDLE) xrDestroyInstance(x->instance);
+	memset(x, 0, sizeof(*x));
+}
+
+static XrView* g3nxr_view(g3nxr* x, int i) { return &x->views[i]; }
+static XrSpaceLocation* g3nxr_hand(g3nxr* x, int i) { return &x->handLocations[i]; }
+static uint32_t g3nxr_width(g3nxr* x, int i) { return x->configViews[i].recommendedImageRectWidth; }
+static uint32_t g3nxr_height(g3nxr* x, int i) { return x->configViews[i].recommendedImageRectHeight; }
+*/
+import "C"
+
+import (
+	"fmt"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+	"unsafe"
+)
+
+// OpenXR is a virtual reality session implemented with an OpenXR runtime.
+// It renders the stereo view configuration of a head mounted display and
+// tracks the head and the grip pose of the left and right hand controllers.
+type OpenXR struct {
+	core.Dispatcher            // Embedded event dispatcher
+	cx              *C.g3nxr   // C state allocated in C memory
+	views           []View     // Views of the current frame
+	near            float32    // Near clip plane distance
+	far             float32    // Far clip plane distance
+	state           int        // Current session state
+	rendering       bool       // Frame being rendered flag
+	poseEv          PoseEvent  // Preallocated pose event
+	stateEv         StateEvent // Preallocated state event
+}
+
+// NewOpenXR creates and returns a new OpenXR session with the specified application name.
+// The OpenGL context to be used for rendering must be current in the calling thread.
+func NewOpenXR(appName string) (ISession, error) {
+
+	x := new(OpenXR)
+	x.Dispatcher.Initialize()
+	x.near = 0.05
+	x.far = 1000
+	x.cx = (*C.g3nxr)(C.malloc(C.size_t(unsafe.Sizeof(C.g3nxr{}))))
+	cname := C.CString(appName)
+	defer C.free(unsafe.Pointer(cname))
+	res := C.g3nxr_create(x.cx, cname)
+	if res < 0 {
+		C.g3nxr_destroy(x.cx)
+		C.free(unsafe.Pointer(x.cx))
+		return nil, fmt.Errorf("OpenXR session creation error: %d", int(res))
+	}
+	x.views = make([]View, int(x.cx.viewCount))
+	log.Info("OpenXR session created with %d views", len(x.views))
+	return x, nil
+}
+
+// SetClipPlanes sets the near and far clip planes distances used
+// to build the projection matrices of the views
+func (x *OpenXR) SetClipPlanes(near, far float32) {
+
+	x.near = near
+	x.far = far
+}
+
+// State returns the current session state
+func (x *OpenXR) State() int {
+
+	return x.state
+}
+
+// BeginFrame processes the runtime events, waits for the next frame and
+// updates the views. Returns true if the views must be rendered.
+func (x *OpenXR) BeginFrame() (bool, error) {
+
+	res := C.g3nxr_poll(x.cx)
+	if res < 0 {
+		return false, fmt.Errorf("OpenXR poll events error: %d", int(res))
+	}
+	x.updateState()
+	if x.cx.running == 0 {
+		return false, nil
+	}
+	var render C.int
+	res = C.g3nxr_begin_frame(x.cx, &render)
+	if res < 0 {
+		return false, fmt.Errorf("OpenXR begin frame error: %d", int(res))
+	}
+	x.rendering = render != 0
+	if !x.rendering {
+		return false, nil
+	}
+
+	// Updates views
+	for i := range x.views {
+		cv := C.g3nxr_view(x.cx, C.int(i))
+		v := &x.views[i]
+		setPose(&v.Pose, &cv.pose, true)
+		v.SetViewFromPose()
+		SetProjectionFromFov(&v.ProjMatrix,
+			math32.Tan(float32(cv.fov.angleLeft)), math32.Tan(float32(cv.fov.angleRight)),
+			math32.Tan(float32(cv.fov.angleUp)), math32.Tan(float32(cv.fov.angleDown)), x.near, x.far)
+	}
+
+	// Dispatches poses of the tracked devices
+	x.dispatchPose(DeviceHead, &x.cx.headLocation)
+	x.dispatchPose(DeviceLeftHand, C.g3nxr_hand(x.cx, 0))
+	x.dispatchPose(DeviceRightHand, C.g3nxr_hand(x.cx, 1))
+	return true, nil
+}
+
+// ViewCount returns the number of views to render
+func (x *OpenXR) ViewCount() int {
+
+	return len(x.views)
+}
+
+// View returns the view with the specified index for the current frame
+func (x *OpenXR) View(idx int) *View {
+
+	return &x.views[idx]
+}
+
+// ViewTarget acquires the swapchain image of the specified view and returns
+// its OpenGL texture name and size. It must be called once per view and frame.
+func (x *OpenXR) ViewTarget(idx int) (uint32, int, int) {
+
+	var texname C.uint32_t
+	res := C.g3nxr_acquire(x.cx, C.int(idx), &texname)
+	if res < 0 {
+		log.Error("OpenXR acquire swapchain image error: %d", int(res))
+		return 0, 0, 0
+	}
+	return uint32(texname), int(C.g3nxr_width(x.cx, C.int(idx))), int(C.g3nxr_height(x.cx, C.int(idx)))
+}
+
+// EndFrame releases the swapchain images and submits the frame to the runtime
+func (x *OpenXR) EndFrame() error {
+
+	if x.cx.running == 0 {
+		return nil
+	}
+	rendered := C.int(0)
+	if x.rendering {
+		rendered = 1
+	}
+	x.rendering = false
+	res := C.g3nxr_end_frame(x.cx, rendered)
+	if res < 0 {
+		return fmt.Errorf("OpenXR end frame error: %d", int(res))
+	}
+	return nil
+}
+
+// Dispose releases the OpenXR session and instance
+func (x *OpenXR) Dispose() {
+
+	if x.cx == nil {
+		return
+	}
+	C.g3nxr_destroy(x.cx)
+	C.free(unsafe.Pointer(x.cx))
+	x.cx = nil
+}
+
+// updateState converts the OpenXR session state and dispatches state changes
+func (x *OpenXR) updateState() {
+
+	state := StateIdle
+	switch x.cx.state {
+	case C.XR_SESSION_STATE_READY, C.XR_SESSION_STATE_SYNCHRONIZED, C.XR_SESSION_STATE_VISIBLE:
+		state = StateRunning
+	case C.XR_SESSION_STATE_FOCUSED:
+		state = StateFocused
+	case C.XR_SESSION_STATE_STOPPING:
+		state = StateStopping
+	case C.XR_SESSION_STATE_EXITING, C.XR_SESSION_STATE_LOSS_PENDING:
+		state = StateExiting
+	}
+	if state != x.state {
+		x.state = state
+		x.stateEv.State = state
+		x.Dispatch(OnSessionState, &x.stateEv)
+	}
+}
+
+// dispatchPose dispatches the pose of the specified device
+func (x *OpenXR) dispatchPose(device int, loc *C.XrSpaceLocation) {
+
+	valid := (loc.locationFlags&C.XR_SPACE_LOCATION_POSITION_VALID_BIT) != 0 &&
+		(loc.locationFlags&C.XR_SPACE_LOCATION_ORIENTATION_VALID_BIT) != 0
+	x.poseEv.Device = device
+	setPose(&x.poseEv.Pose, &loc.pose, valid)
+	x.Dispatch(OnPose, &x.poseEv)
+}
+
+// setPose sets the specified pose from an OpenXR pose
+func setPose(p *Pose, xp *C.XrPosef, valid bool) {
+
+	p.Position.Set(float32(xp.position.x), float32(xp.position.y), float32(xp.position.z))
+	p.Orientation.Set(float32(xp.orientation.x), float32(xp.orientation.y), float32(xp.orientation.z), float32(xp.orientation.w))
+	p.Valid = valid
+}
@@ -0,0 +1,18 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !openxr
+// +build !openxr
+
+package xr
+
+import (
+	"fmt"
+)
+
+// NewOpenXR returns an error as the engine was built without the "openxr" build tag
+func NewOpenXR(appName string) (ISession, error) {
+
+	return nil, fmt.Errorf("OpenXR support not compiled: build with -tags openxr")
+}
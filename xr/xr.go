@@ -0,0 +1,90 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xr
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// Events dispatched by sessions
+const (
+	OnPose         = "xr.OnPose"         // Pose of a tracked device (PoseEvent)
+	OnSessionState = "xr.OnSessionState" // Session state changed (StateEvent)
+)
+
+// Tracked devices
+const (
+	DeviceHead      = 0
+	DeviceLeftHand  = 1
+	DeviceRightHand = 2
+)
+
+// Session states
+const (
+	StateIdle     = 0 // Session created but not running
+	StateRunning  = 1 // Session running: frames should be rendered
+	StateFocused  = 2 // Session running and receiving input
+	StateStopping = 3 // Session is stopping
+	StateExiting  = 4 // Application should exit
+)
+
+// Pose is the position and orientation of a tracked device in the session space
+type Pose struct {
+	Position    math32.Vector3    // Position in meters
+	Orientation math32.Quaternion // Orientation
+	Valid       bool              // Position and orientation are valid
+}
+
+// PoseEvent is dispatched with OnPose once per frame for each tracked device
+type PoseEvent struct {
+	Device int  // Tracked device: DeviceHead, DeviceLeftHand or DeviceRightHand
+	Pose   Pose // Current pose
+}
+
+// StateEvent is dispatched with OnSessionState when the session state changes
+type StateEvent struct {
+	State int // New session state
+}
+
+// View contains the matrices used to render one eye in the current frame
+type View struct {
+	Pose       Pose           // Eye pose in the session space
+	ViewMatrix math32.Matrix4 // View matrix (inverse of the eye pose)
+	ProjMatrix math32.Matrix4 // Projection matrix
+}
+
+// ISession is the interface for virtual reality sessions.
+// For each frame, BeginFrame() must be called, then if it returns true each
+// view must be rendered into the texture returned by ViewTarget(), and finally
+// EndFrame() must be called. Renderer.RenderStereo() implements this sequence.
+type ISession interface {
+	core.IDispatcher
+	BeginFrame() (bool, error)
+	ViewCount() int
+	View(idx int) *View
+	ViewTarget(idx int) (texname uint32, width, height int)
+	EndFrame() error
+	SetClipPlanes(near, far float32)
+	State() int
+	Dispose()
+}
+
+// SetProjectionFromFov sets the specified matrix to the projection matrix of an
+// asymmetric field of view specified by the tangents of its angles, as supplied
+// by virtual reality runtimes. The left and down tangents are normally negative.
+func SetProjectionFromFov(m *math32.Matrix4, tanLeft, tanRight, tanUp, tanDown, near, far float32) {
+
+	m.MakeFrustum(tanLeft*near, tanRight*near, tanDown*near, tanUp*near, near, far)
+}
+
+// SetViewFromPose sets the view matrix of the specified view from its pose
+func (v *View) SetViewFromPose() {
+
+	var world math32.Matrix4
+	scale := math32.Vector3{1, 1, 1}
+	world.Compose(&v.Pose.Position, &v.Pose.Orientation, &scale)
+	v.ViewMatrix.GetInverse(&world, false)
+}